@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/jobadmin"
+)
+
+// ParseJobID parses a positional job ID argument.
+func ParseJobID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid job ID %q", arg)
+	}
+	return id, nil
+}
+
+// ParseList splits a comma-separated list, dropping empty entries.
+func ParseList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// FormatState renders the River state, noting snoozes of scheduled jobs.
+func FormatState(job jobadmin.Job) string {
+	if job.State == "scheduled" && job.Snoozes > 0 {
+		return fmt.Sprintf("scheduled (snoozed %dx)", job.Snoozes)
+	}
+	return job.State
+}
+
+// FormatTime renders t as RFC 3339, or "-" for the zero time.
+func FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// SortedKeys returns the keys of m in order, for stable output.
+func SortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// Truncate shortens s to max runes on a single line.
+func Truncate(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
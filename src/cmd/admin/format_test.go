@@ -0,0 +1,55 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/jobadmin"
+)
+
+func TestParseJobID(t *testing.T) {
+	if id, err := ParseJobID("1042"); err != nil || id != 1042 {
+		t.Errorf("ParseJobID(1042) = %d, %v", id, err)
+	}
+	for _, arg := range []string{"", "0", "-3", "abc"} {
+		if _, err := ParseJobID(arg); err == nil {
+			t.Errorf("ParseJobID(%q) should fail", arg)
+		}
+	}
+}
+
+func TestParseList(t *testing.T) {
+	if got := ParseList(" running, ,snoozed,"); !slices.Equal(got, []string{"running", "snoozed"}) {
+		t.Errorf("ParseList = %v", got)
+	}
+	if got := ParseList(""); got != nil {
+		t.Errorf("ParseList(\"\") = %v, want nil", got)
+	}
+}
+
+func TestFormatState(t *testing.T) {
+	tests := []struct {
+		job  jobadmin.Job
+		want string
+	}{
+		{jobadmin.Job{State: "running"}, "running"},
+		{jobadmin.Job{State: "scheduled"}, "scheduled"},
+		{jobadmin.Job{State: "scheduled", Snoozes: 3}, "scheduled (snoozed 3x)"},
+	}
+	for _, tt := range tests {
+		if got := FormatState(tt.job); got != tt.want {
+			t.Errorf("FormatState(%+v) = %q, want %q", tt.job, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	if got := FormatTime(time.Time{}); got != "-" {
+		t.Errorf("FormatTime(zero) = %q", got)
+	}
+	at := time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+	if got := FormatTime(at); got != "2026-10-14T09:30:00Z" {
+		t.Errorf("FormatTime = %q", got)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/domain/jobadmin"
+	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/queue"
+)
+
+const maxErrorDisplay = 80
+
+func main() {
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	states := flag.String("state", "running", "Comma-separated job states, or snoozed (list only)")
+	kinds := flag.String("kind", "", "Comma-separated job kinds (list only, default: any)")
+	queues := flag.String("queue", "", "Comma-separated queues (list only, default: any)")
+	limit := flag.Int("limit", jobadmin.DefaultLimit, "Maximum number of jobs (list only)")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		os.Exit(1)
+	}
+
+	filter := jobadmin.Filter{
+		Kinds:  ParseList(*kinds),
+		Limit:  *limit,
+		Queues: ParseList(*queues),
+		States: ParseList(*states),
+	}
+
+	if err := run(*databaseURL, flag.Arg(0), flag.Args()[1:], filter); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: admin [flags] <list|show|cancel|retry|priority> [job-id] [priority]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Inspects and changes River jobs.")
+	fmt.Fprintln(os.Stderr, "  list      list jobs by state, kind and queue, newest first")
+	fmt.Fprintln(os.Stderr, "  show      print a job with its args, metadata and attempt errors")
+	fmt.Fprintln(os.Stderr, "  cancel    cancel a job; a running job stops once its worker is notified")
+	fmt.Fprintln(os.Stderr, "  retry     make a job available to run now, extending exhausted attempts")
+	fmt.Fprintln(os.Stderr, "  priority  set the priority (1 highest, 4 lowest) of a job not started yet")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  admin list")
+	fmt.Fprintln(os.Stderr, "  admin -state snoozed,discarded -kind specview:generate list")
+	fmt.Fprintln(os.Stderr, "  admin show 1042")
+	fmt.Fprintln(os.Stderr, "  admin cancel 1042")
+	fmt.Fprintln(os.Stderr, "  admin retry 1057")
+	fmt.Fprintln(os.Stderr, "  admin priority 1057 1")
+}
+
+func run(databaseURL, command string, args []string, filter jobadmin.Filter) error {
+	ctx := context.Background()
+
+	switch command {
+	case "list", "show", "cancel", "retry", "priority":
+	default:
+		printUsage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	repo := postgres.NewJobAdminRepository(pool)
+
+	if command == "list" {
+		filter, err := filter.Normalize()
+		if err != nil {
+			return err
+		}
+		jobs, err := repo.ListJobs(ctx, filter)
+		if err != nil {
+			return err
+		}
+		printJobs(os.Stdout, jobs)
+		return nil
+	}
+
+	wantArgs := 1
+	if command == "priority" {
+		wantArgs = 2
+	}
+	if len(args) != wantArgs {
+		return fmt.Errorf("%s requires %d arguments", command, wantArgs)
+	}
+	jobID, err := ParseJobID(args[0])
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "show":
+		job, err := repo.GetJob(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		printJob(os.Stdout, job)
+		return nil
+	case "priority":
+		priority, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid priority %q", args[1])
+		}
+		if err := jobadmin.ValidatePriority(priority); err != nil {
+			return err
+		}
+		if err := repo.SetPriority(ctx, jobID, priority); err != nil {
+			return err
+		}
+		fmt.Printf("job %d now has priority %d\n", jobID, priority)
+		return nil
+	}
+
+	client, err := queue.NewClient(ctx, pool)
+	if err != nil {
+		return fmt.Errorf("create queue client: %w", err)
+	}
+	defer client.Close()
+
+	if command == "cancel" {
+		state, err := client.CancelJob(ctx, jobID)
+		if err != nil {
+			return notFound(jobID, err)
+		}
+		if state == "running" {
+			fmt.Printf("job %d is running, cancellation requested\n", jobID)
+			return nil
+		}
+		fmt.Printf("job %d is %s\n", jobID, state)
+		return nil
+	}
+
+	if err := client.RequeueJob(ctx, jobID); err != nil {
+		return notFound(jobID, err)
+	}
+	fmt.Printf("job %d is available to run\n", jobID)
+	return nil
+}
+
+func notFound(jobID int64, err error) error {
+	if errors.Is(err, rivertype.ErrNotFound) {
+		return fmt.Errorf("job %d: %w", jobID, jobadmin.ErrNotFound)
+	}
+	return err
+}
+
+func printJobs(out io.Writer, jobs []jobadmin.Job) {
+	if len(jobs) == 0 {
+		fmt.Fprintln(out, "no jobs")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tKIND\tQUEUE\tSTATE\tPRIORITY\tATTEMPTS\tSCHEDULED\tLAST ERROR")
+	for _, job := range jobs {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%d/%d\t%s\t%s\n",
+			job.ID,
+			job.Kind,
+			job.Queue,
+			FormatState(job),
+			job.Priority,
+			job.Attempt, job.MaxAttempts,
+			FormatTime(job.ScheduledAt),
+			Truncate(job.LastError(), maxErrorDisplay),
+		)
+	}
+	w.Flush()
+}
+
+func printJob(out io.Writer, job *jobadmin.Job) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID\t%d\n", job.ID)
+	fmt.Fprintf(w, "Kind\t%s\n", job.Kind)
+	fmt.Fprintf(w, "Queue\t%s\n", job.Queue)
+	fmt.Fprintf(w, "State\t%s\n", FormatState(*job))
+	fmt.Fprintf(w, "Priority\t%d\n", job.Priority)
+	fmt.Fprintf(w, "Attempts\t%d/%d\n", job.Attempt, job.MaxAttempts)
+	fmt.Fprintf(w, "Created\t%s\n", FormatTime(job.CreatedAt))
+	fmt.Fprintf(w, "Scheduled\t%s\n", FormatTime(job.ScheduledAt))
+	fmt.Fprintf(w, "Attempted\t%s\n", FormatTime(job.AttemptedAt))
+	fmt.Fprintf(w, "Finalized\t%s\n", FormatTime(job.FinalizedAt))
+	if len(job.AttemptedBy) > 0 {
+		fmt.Fprintf(w, "Attempted by\t%s\n", job.AttemptedBy[len(job.AttemptedBy)-1])
+	}
+	if len(job.Tags) > 0 {
+		fmt.Fprintf(w, "Tags\t%v\n", job.Tags)
+	}
+	fmt.Fprintf(w, "Args\t%s\n", job.Args)
+	for _, key := range SortedKeys(job.Metadata) {
+		fmt.Fprintf(w, "Metadata %s\t%s\n", key, job.Metadata[key])
+	}
+	w.Flush()
+
+	for _, e := range job.Errors {
+		fmt.Fprintf(out, "\nattempt %d failed at %s:\n%s\n", e.Attempt, e.At.Format(time.RFC3339), e.Error)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+const dateLayout = "2006-01-02"
+
+func main() {
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	since := flag.String("since", time.Now().UTC().AddDate(0, 0, -30).Format(dateLayout), "First day to report (YYYY-MM-DD, UTC)")
+	until := flag.String("until", "", "Day after the last day to report (YYYY-MM-DD, UTC; default tomorrow)")
+	limit := flag.Int("limit", 20, "Maximum users to list (users only)")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		os.Exit(1)
+	}
+
+	if err := run(*databaseURL, flag.Arg(0), *since, *until, *limit); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: ai-usage [flags] <daily|users>")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Reports the AI tokens spent by spec generation, recorded per job and phase")
+	fmt.Fprintln(os.Stderr, "in ai_usage.")
+	fmt.Fprintln(os.Stderr, "  daily  tokens by day, model and phase")
+	fmt.Fprintln(os.Stderr, "  users  the users spending the most tokens")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  ai-usage daily -since 2026-09-01 -until 2026-10-01")
+	fmt.Fprintln(os.Stderr, "  ai-usage users -limit 50")
+}
+
+func run(databaseURL, command, since, until string, limit int) error {
+	from, err := time.Parse(dateLayout, since)
+	if err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	to := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if until != "" {
+		if to, err = time.Parse(dateLayout, until); err != nil {
+			return fmt.Errorf("invalid -until: %w", err)
+		}
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("-since must be before -until")
+	}
+
+	ctx := context.Background()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	repo := postgres.NewAIUsageRepository(pool)
+
+	switch command {
+	case "daily":
+		totals, err := repo.DailyAIUsage(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		printDaily(os.Stdout, totals)
+		return nil
+	case "users":
+		totals, err := repo.AIUsageByUser(ctx, from, to, limit)
+		if err != nil {
+			return err
+		}
+		printUsers(os.Stdout, totals)
+		return nil
+	default:
+		printUsage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func printDaily(out io.Writer, totals []specview.AIUsageTotals) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DAY\tMODEL\tPHASE\tJOBS\tPROMPT\tCANDIDATES\tTOTAL")
+	var sum int64
+	for _, t := range totals {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\n",
+			t.Day.Format(dateLayout), t.Model, t.Phase, t.Jobs, t.PromptTokens, t.CandidatesTokens, t.TotalTokens)
+		sum += t.TotalTokens
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\n%d tokens\n", sum)
+}
+
+func printUsers(out io.Writer, totals []specview.AIUsageTotals) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USER\tJOBS\tPROMPT\tCANDIDATES\tTOTAL")
+	for _, t := range totals {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", t.UserID, t.Jobs, t.PromptTokens, t.CandidatesTokens, t.TotalTokens)
+	}
+	w.Flush()
+}
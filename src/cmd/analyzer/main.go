@@ -1,16 +1,29 @@
 package main
 
 import (
+	"flag"
 	"log/slog"
 	"os"
 
 	"github.com/specvital/worker/internal/app/bootstrap"
+	"github.com/specvital/worker/internal/infra/buildinfo"
 	"github.com/specvital/worker/internal/infra/config"
 
 	_ "github.com/specvital/core/pkg/parser/strategies/all"
 )
 
 func main() {
+	showVersion := flag.Bool("version", false, "Print build info, features and job kinds as JSON and exit")
+	flag.Parse()
+
+	if *showVersion {
+		report := bootstrap.AnalyzerBuildReport(analyzerConfig(config.LoadSettings()), buildinfo.CurrentIdentity())
+		if err := bootstrap.PrintBuildReport(os.Stdout, report); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
 
@@ -20,15 +33,32 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := bootstrap.StartAnalyzer(bootstrap.AnalyzerConfig{
-		ServiceName:   "analyzer",
-		DatabaseURL:   cfg.DatabaseURL,
-		EncryptionKey: cfg.EncryptionKey,
-		Fairness:      cfg.Fairness,
-		QueueWorkers:  cfg.Queue.Analyzer,
-		Streaming:     cfg.Streaming,
-	}); err != nil {
+	if err := bootstrap.StartAnalyzer(analyzerConfig(cfg)); err != nil {
 		slog.Error("analyzer failed", "error", err)
 		os.Exit(1)
 	}
 }
+
+func analyzerConfig(cfg *config.Config) bootstrap.AnalyzerConfig {
+	return bootstrap.AnalyzerConfig{
+		ServiceName:     "analyzer",
+		DatabaseURL:     cfg.DatabaseURL,
+		DrainTimeout:    cfg.DrainTimeout,
+		EncryptionKey:   cfg.EncryptionKey,
+		Fairness:        cfg.Fairness,
+		GitHubApp:       cfg.GitHubApp,
+		HostPools:       cfg.HostPools,
+		HTTPAddr:        cfg.HTTPAddr,
+		MinHelperRefs:   cfg.MinHelperRefs,
+		MinTestVariants: cfg.MinTestVariants,
+		Notifications:   cfg.Notifications,
+		QueueWorkers:    cfg.Queue.Analyzer,
+		Region:          cfg.Region,
+		Retry:           cfg.Retry,
+		SLATargets:      cfg.SLATargets,
+		Streaming:       cfg.Streaming,
+		TokenKeys:       cfg.TokenKeys,
+		Tracing:         cfg.Tracing,
+		Workspace:       cfg.Workspace,
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/specvital/worker/internal/app/bootstrap"
+)
+
+func main() {
+	timeout := flag.Duration("timeout", bootstrap.DefaultVerifyCheckTimeout, "Time limit of each check")
+	verbose := flag.Bool("verbose", false, "Print service logs to stderr")
+	flag.Parse()
+
+	if flag.NArg() != 1 || flag.Arg(0) != "verify" {
+		printUsage()
+		os.Exit(1)
+	}
+
+	// Providers log while they are built; keep the report readable.
+	var logOutput io.Writer = io.Discard
+	if *verbose {
+		logOutput = os.Stderr
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(logOutput, nil)))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report := bootstrap.VerifyConfig(ctx, *timeout)
+	if err := bootstrap.PrintVerifyReport(os.Stdout, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !report.Ready() {
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: config [flags] verify")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Checks a deployment's environment before the services start: loads the")
+	fmt.Fprintln(os.Stderr, "configuration, connects to the database, reads the River queues, validates")
+	fmt.Fprintln(os.Stderr, "the encryption key and pings the AI provider (or builds the mock).")
+	fmt.Fprintln(os.Stderr, "Prints one line per check and exits non-zero when any check fails.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  config verify")
+	fmt.Fprintln(os.Stderr, "  MOCK_MODE=true config -timeout 5s verify")
+}
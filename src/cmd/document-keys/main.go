@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/specvital/worker/internal/app"
+	"github.com/specvital/worker/internal/infra/config"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+func main() {
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	masterKey := flag.String("key", os.Getenv("DOCUMENT_ENCRYPTION_KEY"), "Base64 master key wrapping tenant keys")
+	previousKey := flag.String("previous-key", os.Getenv("DOCUMENT_ENCRYPTION_KEY_PREVIOUS"), "Base64 master key in use before the current one (rewrap)")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		os.Exit(1)
+	}
+	if *masterKey == "" {
+		fmt.Fprintln(os.Stderr, "Error: Master key is required (use -key flag or set DOCUMENT_ENCRYPTION_KEY)")
+		os.Exit(1)
+	}
+
+	cfg := config.DocumentEncryptionConfig{MasterKey: *masterKey, PreviousMasterKey: *previousKey}
+	if err := run(*databaseURL, cfg, flag.Arg(0), flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: document-keys [flags] <enable|disable|rotate|reseal> <tenant-id>")
+	fmt.Fprintln(os.Stderr, "       document-keys [flags] rewrap")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Manages per-tenant encryption of generated spec documents.")
+	fmt.Fprintln(os.Stderr, "  enable   encrypt new documents of the tenant and seal its existing ones")
+	fmt.Fprintln(os.Stderr, "  disable  store new documents in plaintext; sealed ones stay sealed")
+	fmt.Fprintln(os.Stderr, "  rotate   replace the tenant key and reseal every document with the new one")
+	fmt.Fprintln(os.Stderr, "  reseal   resume an interrupted enable or rotate")
+	fmt.Fprintln(os.Stderr, "  rewrap   rewrap tenant keys wrapped with -previous-key with -key")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  document-keys enable 0b6f4c1e-8d9a-4b3e-9a57-2f1d6c0e7a11")
+	fmt.Fprintln(os.Stderr, "  document-keys rotate 0b6f4c1e-8d9a-4b3e-9a57-2f1d6c0e7a11")
+	fmt.Fprintln(os.Stderr, "  DOCUMENT_ENCRYPTION_KEY_PREVIOUS=... document-keys rewrap")
+}
+
+func run(databaseURL string, cfg config.DocumentEncryptionConfig, command string, args []string) error {
+	ctx := context.Background()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	keyring, err := app.NewDocumentKeyring(pool, cfg)
+	if err != nil {
+		return err
+	}
+	defer keyring.Close()
+
+	if command == "rewrap" {
+		n, err := keyring.Rewrap(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("rewrapped %d tenant keys\n", n)
+		return nil
+	}
+
+	if len(args) != 1 {
+		printUsage()
+		return fmt.Errorf("%s requires a tenant ID", command)
+	}
+	tenantID := args[0]
+
+	switch command {
+	case "enable":
+		if err := keyring.SetTenantEncryption(ctx, tenantID, true); err != nil {
+			return err
+		}
+		n, err := keyring.Reseal(ctx, tenantID)
+		if err != nil {
+			return fmt.Errorf("encryption enabled, sealed %d documents, run reseal to resume: %w", n, err)
+		}
+		fmt.Printf("enabled document encryption for %s, sealed %d documents\n", tenantID, n)
+		return nil
+	case "disable":
+		if err := keyring.SetTenantEncryption(ctx, tenantID, false); err != nil {
+			return err
+		}
+		fmt.Printf("disabled document encryption for %s\n", tenantID)
+		return nil
+	case "rotate":
+		n, err := keyring.RotateTenantKey(ctx, tenantID)
+		if err != nil {
+			return fmt.Errorf("resealed %d documents, run reseal to resume: %w", n, err)
+		}
+		fmt.Printf("rotated key of %s, resealed %d documents\n", tenantID, n)
+		return nil
+	case "reseal":
+		n, err := keyring.Reseal(ctx, tenantID)
+		if err != nil {
+			return fmt.Errorf("resealed %d documents: %w", n, err)
+		}
+		fmt.Printf("resealed %d documents of %s\n", n, tenantID)
+		return nil
+	default:
+		printUsage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
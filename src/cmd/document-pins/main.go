@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+func main() {
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	pinnedBy := flag.String("by", currentUser(), "Operator recorded on the pin (pin only)")
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		os.Exit(1)
+	}
+
+	if err := run(*databaseURL, *pinnedBy, flag.Arg(0), flag.Arg(1), flag.Args()[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: document-pins [flags] pin <document-id> <release-tag>")
+	fmt.Fprintln(os.Stderr, "       document-pins [flags] <unpin|show> <document-id>")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Pins spec document versions to releases. A pinned version is kept by")
+	fmt.Fprintln(os.Stderr, "retention cleanup, with its analysis and team slices, and is skipped by")
+	fmt.Fprintln(os.Stderr, "regeneration campaigns.")
+	fmt.Fprintln(os.Stderr, "  pin    pin the document to the release tag")
+	fmt.Fprintln(os.Stderr, "  unpin  remove the pin, making the document subject to retention again")
+	fmt.Fprintln(os.Stderr, "  show   print the pin of the document")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  document-pins pin 7c9e6679-7425-40de-944b-e07fc1f90ae7 v1.4.0")
+	fmt.Fprintln(os.Stderr, "  document-pins show 7c9e6679-7425-40de-944b-e07fc1f90ae7")
+}
+
+func run(databaseURL, pinnedBy, command, documentID string, args []string) error {
+	ctx := context.Background()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	repo := postgres.NewDocumentPinRepository(pool)
+
+	switch command {
+	case "pin":
+		if len(args) != 1 {
+			printUsage()
+			return fmt.Errorf("pin requires a release tag")
+		}
+		pin, err := repo.PinDocument(ctx, documentID, args[0], pinnedBy)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("pinned %s to %s\n", pin.DocumentID, pin.ReleaseTag)
+		return nil
+	case "unpin":
+		if err := repo.UnpinDocument(ctx, documentID); err != nil {
+			return err
+		}
+		fmt.Printf("unpinned %s\n", documentID)
+		return nil
+	case "show":
+		pin, err := repo.GetDocumentPin(ctx, documentID)
+		if err != nil {
+			return err
+		}
+		if pin == nil {
+			fmt.Printf("%s is not pinned\n", documentID)
+			return nil
+		}
+		by := pin.PinnedBy
+		if by == "" {
+			by = "-"
+		}
+		fmt.Printf("%s pinned to %s by %s at %s\n", pin.DocumentID, pin.ReleaseTag, by, pin.PinnedAt.Format(time.RFC3339))
+		return nil
+	default:
+		printUsage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
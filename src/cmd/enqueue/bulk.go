@@ -0,0 +1,316 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/specvital/worker/internal/adapter/vcs"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/hostpool"
+	"github.com/specvital/worker/internal/infra/config"
+	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/queue"
+)
+
+const defaultHost = "github.com"
+
+const (
+	statusDuplicate    = "duplicate"
+	statusEnqueued     = "enqueued"
+	statusFailed       = "failed"
+	statusInvalid      = "invalid"
+	statusSkipped      = "skipped"
+	statusWouldEnqueue = "would-enqueue"
+)
+
+// bulkEntry is one repository of a bulk file. row is the CSV line or the
+// 1-based position in the JSON array, for the status output.
+type bulkEntry struct {
+	branch      string
+	duplicateOf int // row of the earlier entry for the same repository
+	err         error
+	host        string // empty for github.com
+	owner       string
+	repo        string
+	row         int
+	target      string
+}
+
+func (e bulkEntry) label() string {
+	label := fmt.Sprintf("%s/%s/%s", cmp.Or(e.host, defaultHost), e.owner, e.repo)
+	if e.owner == "" {
+		label = e.target
+	}
+	if e.branch != "" {
+		label += "@" + e.branch
+	}
+	return label
+}
+
+// bulkTarget is what bulk mode needs of GitHub, the database and the queue.
+type bulkTarget interface {
+	HeadCommit(ctx context.Context, host, owner, repo, branch string) (string, error)
+	HasCompletedAnalysis(ctx context.Context, host, owner, repo, commitSHA string) (bool, error)
+	Enqueue(ctx context.Context, host, owner, repo, branch, commitSHA string) error
+}
+
+// readBulkFile reads a .json file as a JSON array and anything else as CSV.
+// Entries that fail validation or repeat an earlier one are returned marked
+// as such, so they show up in the status output.
+func readBulkFile(path, defaultEntryHost, defaultBranch string) ([]bulkEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open bulk file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []bulkEntry
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		entries, err = parseBulkJSON(f, defaultEntryHost, defaultBranch)
+	} else {
+		entries, err = parseBulkCSV(f, defaultEntryHost, defaultBranch)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read bulk file %s: %w", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("bulk file %s lists no repositories", path)
+	}
+	markDuplicates(entries)
+	return entries, nil
+}
+
+func parseBulkCSV(r io.Reader, defaultEntryHost, defaultBranch string) ([]bulkEntry, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var entries []bulkEntry
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first && strings.EqualFold(strings.TrimSpace(record[0]), "url") {
+			continue
+		}
+		row, _ := reader.FieldPos(0)
+		field := func(i int) string {
+			if i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		entries = append(entries, newBulkEntry(row, field(0), cmp.Or(field(1), defaultBranch), cmp.Or(field(2), defaultEntryHost)))
+	}
+}
+
+func parseBulkJSON(r io.Reader, defaultEntryHost, defaultBranch string) ([]bulkEntry, error) {
+	var items []struct {
+		Branch string `json:"branch"`
+		Host   string `json:"host"`
+		URL    string `json:"url"`
+	}
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, err
+	}
+
+	entries := make([]bulkEntry, len(items))
+	for i, item := range items {
+		entries[i] = newBulkEntry(i+1, strings.TrimSpace(item.URL), cmp.Or(item.Branch, defaultBranch), cmp.Or(item.Host, defaultEntryHost))
+	}
+	return entries, nil
+}
+
+func newBulkEntry(row int, target, branch, host string) bulkEntry {
+	if host == defaultHost {
+		host = ""
+	}
+	e := bulkEntry{branch: branch, host: host, row: row, target: target}
+	if host != "" {
+		if err := hostpool.ValidateHost(host); err != nil {
+			e.err = err
+			return e
+		}
+	}
+	if branch != "" && !analysis.IsValidBranchName(branch) {
+		e.err = fmt.Errorf("invalid branch name %q", branch)
+		return e
+	}
+	e.owner, e.repo, e.err = parseTarget(target, host)
+	return e
+}
+
+func markDuplicates(entries []bulkEntry) {
+	seen := make(map[string]int, len(entries))
+	for i, e := range entries {
+		if e.err != nil {
+			continue
+		}
+		key := strings.ToLower(e.label())
+		if row, ok := seen[key]; ok {
+			entries[i].duplicateOf = row
+			continue
+		}
+		seen[key] = e.row
+	}
+}
+
+// bulkSummary counts the entries of a bulk run by status.
+type bulkSummary map[string]int
+
+func (s bulkSummary) String() string {
+	parts := make([]string, 0, 6)
+	for _, status := range []string{statusEnqueued, statusWouldEnqueue, statusSkipped, statusDuplicate, statusInvalid, statusFailed} {
+		if s[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", s[status], status))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// runBulk processes up to concurrency entries at once and writes one status
+// line per entry as it finishes.
+func runBulk(ctx context.Context, out io.Writer, entries []bulkEntry, concurrency int, dryRun bool, target bulkTarget) bulkSummary {
+	summary := make(bulkSummary)
+	var mu sync.Mutex
+	report := func(e bulkEntry, status, detail string) {
+		mu.Lock()
+		defer mu.Unlock()
+		summary[status]++
+		fmt.Fprintf(out, "row %-5d %-13s %s", e.row, status, e.label())
+		if detail != "" {
+			fmt.Fprintf(out, "  %s", detail)
+		}
+		fmt.Fprintln(out)
+	}
+
+	var g errgroup.Group
+	g.SetLimit(concurrency)
+	for _, e := range entries {
+		if e.err != nil {
+			report(e, statusInvalid, e.err.Error())
+			continue
+		}
+		if e.duplicateOf > 0 {
+			report(e, statusDuplicate, fmt.Sprintf("same repository as row %d", e.duplicateOf))
+			continue
+		}
+		g.Go(func() error {
+			status, detail := processBulkEntry(ctx, e, dryRun, target)
+			report(e, status, detail)
+			return nil
+		})
+	}
+	g.Wait()
+	return summary
+}
+
+func processBulkEntry(ctx context.Context, e bulkEntry, dryRun bool, target bulkTarget) (status, detail string) {
+	sha, err := target.HeadCommit(ctx, e.host, e.owner, e.repo, e.branch)
+	if err != nil {
+		return statusFailed, fmt.Sprintf("get head commit: %v", err)
+	}
+
+	done, err := target.HasCompletedAnalysis(ctx, e.host, e.owner, e.repo, sha)
+	if err != nil {
+		return statusFailed, fmt.Sprintf("check analysis: %v", err)
+	}
+	if done {
+		return statusSkipped, fmt.Sprintf("%s already analyzed", shortSHA(sha))
+	}
+
+	if dryRun {
+		return statusWouldEnqueue, shortSHA(sha)
+	}
+	if err := target.Enqueue(ctx, e.host, e.owner, e.repo, e.branch, sha); err != nil {
+		return statusFailed, fmt.Sprintf("enqueue: %v", err)
+	}
+	return statusEnqueued, shortSHA(sha)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
+func enqueueBulk(databaseURL, regionName string, entries []bulkEntry, concurrency int, dryRun bool) error {
+	ctx := context.Background()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	target := &liveTarget{git: vcs.NewGitVCS(), queries: db.New(pool)}
+	if !dryRun {
+		client, err := queue.NewClient(ctx, pool,
+			queue.WithHostPools(config.LoadSettings().HostPools),
+			queue.WithRegion(regionName),
+		)
+		if err != nil {
+			return fmt.Errorf("create queue client: %w", err)
+		}
+		defer client.Close()
+		target.client = client
+	}
+
+	summary := runBulk(ctx, os.Stdout, entries, concurrency, dryRun, target)
+	fmt.Println(summary)
+
+	if failed := summary[statusFailed] + summary[statusInvalid]; failed > 0 {
+		return fmt.Errorf("%d of %d repositories were not enqueued", failed, len(entries))
+	}
+	return nil
+}
+
+type liveTarget struct {
+	client  *queue.Client
+	git     *vcs.GitVCS
+	queries *db.Queries
+}
+
+func (t *liveTarget) HeadCommit(ctx context.Context, host, owner, repo, branch string) (string, error) {
+	repoURL := fmt.Sprintf("https://%s/%s/%s", cmp.Or(host, defaultHost), owner, repo)
+	commit, err := t.git.GetHeadCommit(ctx, repoURL, nil, branch)
+	if err != nil {
+		return "", err
+	}
+	return commit.SHA, nil
+}
+
+func (t *liveTarget) HasCompletedAnalysis(ctx context.Context, host, owner, repo, commitSHA string) (bool, error) {
+	_, err := t.queries.FindCompletedAnalysisByCommit(ctx, db.FindCompletedAnalysisByCommitParams{
+		Host:      cmp.Or(host, defaultHost),
+		Owner:     owner,
+		Name:      repo,
+		CommitSha: commitSHA,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (t *liveTarget) Enqueue(ctx context.Context, host, owner, repo, branch, commitSHA string) error {
+	return t.client.EnqueueHostAnalysis(ctx, host, owner, repo, branch, commitSHA)
+}
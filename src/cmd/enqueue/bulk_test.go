@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func writeBulkFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadBulkFile_CSV(t *testing.T) {
+	path := writeBulkFile(t, "repos.csv", `url,branch,host
+# staging repositories
+github.com/octocat/Hello-World
+https://github.com/owner/repo.git, release/v2
+github.example.com/team/svc,,github.example.com
+not-a-url
+github.com/OctoCat/hello-world
+`)
+
+	entries, err := readBulkFile(path, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("expected 5 entries, got %d", len(entries))
+	}
+
+	if e := entries[0]; e.owner != "octocat" || e.repo != "Hello-World" || e.row != 3 {
+		t.Errorf("entry 0 = %+v", e)
+	}
+	if e := entries[1]; e.repo != "repo" || e.branch != "release/v2" {
+		t.Errorf("entry 1 = %+v", e)
+	}
+	if e := entries[2]; e.host != "github.example.com" || e.owner != "team" || e.repo != "svc" {
+		t.Errorf("entry 2 = %+v", e)
+	}
+	if entries[3].err == nil {
+		t.Error("expected an invalid entry for a URL without a repo")
+	}
+	if entries[4].duplicateOf != 3 {
+		t.Errorf("expected the last entry to duplicate row 3, got %d", entries[4].duplicateOf)
+	}
+}
+
+func TestReadBulkFile_JSON(t *testing.T) {
+	path := writeBulkFile(t, "repos.json", `[
+		{"url": "github.com/owner/a"},
+		{"url": "github.com/owner/b", "branch": "main"},
+		{"url": "github.com/owner/c", "branch": "bad..branch"}
+	]`)
+
+	entries, err := readBulkFile(path, "", "develop")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].branch != "develop" || entries[1].branch != "main" {
+		t.Errorf("expected -branch to apply only to entries without one, got %q and %q", entries[0].branch, entries[1].branch)
+	}
+	if entries[2].err == nil {
+		t.Error("expected an invalid branch to be reported")
+	}
+
+	if _, err := readBulkFile(writeBulkFile(t, "empty.json", `[]`), "", ""); err == nil {
+		t.Error("expected an error for a file without repositories")
+	}
+}
+
+type fakeBulkTarget struct {
+	analyzed map[string]bool
+	failHead map[string]bool
+	mu       sync.Mutex
+	enqueued []string
+}
+
+func (f *fakeBulkTarget) HeadCommit(_ context.Context, _, _, repo, _ string) (string, error) {
+	if f.failHead[repo] {
+		return "", errors.New("repository not found")
+	}
+	return "sha-" + repo, nil
+}
+
+func (f *fakeBulkTarget) HasCompletedAnalysis(_ context.Context, _, _, _, commitSHA string) (bool, error) {
+	return f.analyzed[commitSHA], nil
+}
+
+func (f *fakeBulkTarget) Enqueue(_ context.Context, _, _, repo, _, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.enqueued = append(f.enqueued, repo)
+	return nil
+}
+
+func TestRunBulk(t *testing.T) {
+	entries := []bulkEntry{
+		newBulkEntry(1, "github.com/owner/new", "", ""),
+		newBulkEntry(2, "github.com/owner/done", "", ""),
+		newBulkEntry(3, "github.com/owner/gone", "", ""),
+		newBulkEntry(4, "owner-only", "", ""),
+		newBulkEntry(5, "github.com/owner/new", "", ""),
+	}
+	markDuplicates(entries)
+
+	t.Run("enqueues what is not analyzed yet", func(t *testing.T) {
+		target := &fakeBulkTarget{analyzed: map[string]bool{"sha-done": true}, failHead: map[string]bool{"gone": true}}
+		var out bytes.Buffer
+
+		summary := runBulk(context.Background(), &out, entries, 2, false, target)
+
+		if summary[statusEnqueued] != 1 || summary[statusSkipped] != 1 || summary[statusFailed] != 1 ||
+			summary[statusInvalid] != 1 || summary[statusDuplicate] != 1 {
+			t.Errorf("summary = %v", summary)
+		}
+		if len(target.enqueued) != 1 || target.enqueued[0] != "new" {
+			t.Errorf("enqueued = %v", target.enqueued)
+		}
+		if lines := strings.Count(out.String(), "\n"); lines != len(entries) {
+			t.Errorf("expected one status line per entry, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("dry run enqueues nothing", func(t *testing.T) {
+		target := &fakeBulkTarget{}
+		var out bytes.Buffer
+
+		summary := runBulk(context.Background(), &out, entries[:1], 1, true, target)
+
+		if summary[statusWouldEnqueue] != 1 || len(target.enqueued) != 0 {
+			t.Errorf("summary = %v, enqueued = %v", summary, target.enqueued)
+		}
+	})
+}
@@ -6,17 +6,28 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/specvital/worker/internal/adapter/vcs"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/hostpool"
+	"github.com/specvital/worker/internal/domain/region"
+	"github.com/specvital/worker/internal/infra/config"
 	"github.com/specvital/worker/internal/infra/db"
 	"github.com/specvital/worker/internal/infra/queue"
 )
 
 func main() {
 	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	regionName := flag.String("region", os.Getenv("WORKER_REGION"), "Data-residency region of the target workers (empty for default)")
+	branch := flag.String("branch", "", "Branch to analyze (empty for the default branch)")
+	host := flag.String("host", "", "GitHub Enterprise host of the repository (empty for github.com)")
+	file := flag.String("file", "", "CSV or JSON file of repositories to enqueue in bulk")
+	concurrency := flag.Int("concurrency", 4, "Repositories resolved and enqueued at once in bulk mode")
+	dryRun := flag.Bool("dry-run", false, "Resolve and check the repositories of a bulk file without enqueuing")
 	flag.Parse()
 
-	if flag.NArg() < 1 {
+	if (*file == "") == (flag.NArg() < 1) {
 		printUsage()
 		os.Exit(1)
 	}
@@ -26,13 +37,47 @@ func main() {
 		os.Exit(1)
 	}
 
-	owner, repo, err := ParseGitHubURL(flag.Arg(0))
+	if err := region.Validate(*regionName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *branch != "" && !analysis.IsValidBranchName(*branch) {
+		fmt.Fprintf(os.Stderr, "Error: invalid branch name %q\n", *branch)
+		os.Exit(1)
+	}
+
+	if *host != "" {
+		if err := hostpool.ValidateHost(*host); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *file != "" {
+		if *concurrency < 1 {
+			fmt.Fprintln(os.Stderr, "Error: -concurrency must be at least 1")
+			os.Exit(1)
+		}
+		entries, err := readBulkFile(*file, *host, *branch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := enqueueBulk(*databaseURL, *regionName, entries, *concurrency, *dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	owner, repo, err := parseTarget(flag.Arg(0), *host)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if err := enqueue(*databaseURL, owner, repo); err != nil {
+	if err := enqueue(*databaseURL, *regionName, *host, owner, repo, *branch); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: failed to enqueue task: %v\n", err)
 		os.Exit(1)
 	}
@@ -40,10 +85,16 @@ func main() {
 
 func printUsage() {
 	fmt.Fprintln(os.Stderr, "Usage: enqueue [flags] <github-url>")
+	fmt.Fprintln(os.Stderr, "       enqueue [flags] -file <repos.csv|repos.json>")
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "Arguments:")
 	fmt.Fprintln(os.Stderr, "  <github-url>  GitHub repository URL (e.g., github.com/owner/repo)")
 	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Bulk files list one repository per CSV row (url[,branch[,host]], optional")
+	fmt.Fprintln(os.Stderr, "header row) or per element of a JSON array ({\"url\",\"branch\",\"host\"}).")
+	fmt.Fprintln(os.Stderr, "-branch and -host apply to entries without their own. Repositories whose")
+	fmt.Fprintln(os.Stderr, "HEAD already has a completed analysis are skipped.")
+	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "Flags:")
 	flag.PrintDefaults()
 	fmt.Fprintln(os.Stderr, "")
@@ -51,9 +102,23 @@ func printUsage() {
 	fmt.Fprintln(os.Stderr, "  enqueue github.com/octocat/Hello-World")
 	fmt.Fprintln(os.Stderr, "  enqueue -database postgres://localhost/mydb github.com/owner/repo")
 	fmt.Fprintln(os.Stderr, "  enqueue https://github.com/owner/repo.git")
+	fmt.Fprintln(os.Stderr, "  enqueue -region eu github.com/owner/repo")
+	fmt.Fprintln(os.Stderr, "  enqueue -branch release/v2 github.com/owner/repo")
+	fmt.Fprintln(os.Stderr, "  enqueue -host github.example.com github.example.com/owner/repo")
+	fmt.Fprintln(os.Stderr, "  enqueue -file repos.csv -concurrency 8")
+	fmt.Fprintln(os.Stderr, "  enqueue -file repos.json -dry-run")
+}
+
+// parseTarget extracts owner and repo from a repository URL, which may carry
+// the GitHub Enterprise host instead of github.com.
+func parseTarget(target, host string) (owner, repo string, err error) {
+	if host != "" {
+		target = strings.TrimPrefix(strings.TrimPrefix(target, "https://"), host+"/")
+	}
+	return ParseGitHubURL(target)
 }
 
-func enqueue(databaseURL, owner, repo string) error {
+func enqueue(databaseURL, regionName, host, owner, repo, branch string) error {
 	ctx := context.Background()
 
 	pool, err := db.NewPool(ctx, databaseURL)
@@ -62,27 +127,37 @@ func enqueue(databaseURL, owner, repo string) error {
 	}
 	defer pool.Close()
 
-	client, err := queue.NewClient(ctx, pool)
+	client, err := queue.NewClient(ctx, pool,
+		queue.WithHostPools(config.LoadSettings().HostPools),
+		queue.WithRegion(regionName),
+	)
 	if err != nil {
 		return fmt.Errorf("create queue client: %w", err)
 	}
 	defer client.Close()
 
 	gitVCS := vcs.NewGitVCS()
-	repoURL := fmt.Sprintf("https://github.com/%s/%s", owner, repo)
-	commitInfo, err := gitVCS.GetHeadCommit(ctx, repoURL, nil)
+	repoHost := host
+	if repoHost == "" {
+		repoHost = defaultHost
+	}
+	repoURL := fmt.Sprintf("https://%s/%s/%s", repoHost, owner, repo)
+	commitInfo, err := gitVCS.GetHeadCommit(ctx, repoURL, nil, branch)
 	if err != nil {
 		return fmt.Errorf("get head commit for %s/%s: %w", owner, repo, err)
 	}
 
-	if err := client.EnqueueAnalysis(ctx, owner, repo, commitInfo.SHA); err != nil {
+	if err := client.EnqueueHostAnalysis(ctx, host, owner, repo, branch, commitInfo.SHA); err != nil {
 		return fmt.Errorf("enqueue task: %w", err)
 	}
 
 	slog.Info("task enqueued",
 		"owner", owner,
 		"repo", repo,
+		"branch", branch,
 		"commit", commitInfo.SHA,
+		"host", repoHost,
+		"region", regionName,
 	)
 	return nil
 }
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/queue/fairness"
+	"github.com/specvital/worker/internal/infra/config"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+const dateLayout = "2006-01-02"
+
+func main() {
+	settings := config.LoadSettings()
+	current := settings.Fairness
+	proposed := current
+
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	days := flag.Int("days", 7, "Days of enqueue history to replay")
+	service := flag.String("service", "analyzer", "Service whose queues are replayed (analyzer or spec-generator)")
+	flag.BoolVar(&proposed.Enabled, "fairness", current.Enabled, "Enforce per-user limits")
+	flag.IntVar(&proposed.FreeConcurrentLimit, "free-limit", current.FreeConcurrentLimit, "Concurrent jobs per Free user")
+	flag.IntVar(&proposed.ProConcurrentLimit, "pro-limit", current.ProConcurrentLimit, "Concurrent jobs per Pro and Pro Plus user")
+	flag.IntVar(&proposed.EnterpriseConcurrentLimit, "enterprise-limit", current.EnterpriseConcurrentLimit, "Concurrent jobs per Enterprise user")
+	flag.DurationVar(&proposed.SnoozeDuration, "snooze", current.SnoozeDuration, "Snooze of jobs over the concurrent limit")
+	flag.DurationVar(&proposed.SnoozeJitter, "snooze-jitter", current.SnoozeJitter, "Random jitter added to snoozes")
+	flag.BoolVar(&proposed.RateLimitEnabled, "rate-limit", current.RateLimitEnabled, "Enforce the token bucket start rates")
+	flag.IntVar(&proposed.UserRatePerMinute, "user-rate", current.UserRatePerMinute, "Job starts per user per minute")
+	flag.IntVar(&proposed.UserBurst, "user-burst", current.UserBurst, "Job start burst per user")
+	flag.IntVar(&proposed.CodebaseRatePerMinute, "codebase-rate", current.CodebaseRatePerMinute, "Job starts per codebase per minute")
+	flag.IntVar(&proposed.CodebaseBurst, "codebase-burst", current.CodebaseBurst, "Job start burst per codebase")
+	priorityWorkers := flag.Int("priority-workers", 0, "Workers of the priority queue (default: current)")
+	defaultWorkers := flag.Int("default-workers", 0, "Workers of the default queue (default: current)")
+	scheduledWorkers := flag.Int("scheduled-workers", 0, "Workers of the scheduled queue (default: current)")
+	flag.Usage = printUsage
+	flag.Parse()
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		os.Exit(1)
+	}
+
+	set, err := ParseService(*service)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	currentWorkers := settings.Queue.Analyzer
+	if *service == "spec-generator" {
+		currentWorkers = settings.Queue.Specgen
+	}
+	proposedWorkers := currentWorkers
+	for _, w := range []struct {
+		flag   int
+		target *int
+	}{
+		{*priorityWorkers, &proposedWorkers.Priority},
+		{*defaultWorkers, &proposedWorkers.Default},
+		{*scheduledWorkers, &proposedWorkers.Scheduled},
+	} {
+		if w.flag > 0 {
+			*w.target = w.flag
+		}
+	}
+
+	if *days <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: -days must be positive")
+		os.Exit(1)
+	}
+	if err := ValidatePolicy(proposed, proposedWorkers); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: proposed policy: %v\n", err)
+		os.Exit(1)
+	}
+
+	sim := simulation{
+		current:         current,
+		currentWorkers:  currentWorkers,
+		days:            *days,
+		proposed:        proposed,
+		proposedWorkers: proposedWorkers,
+		service:         *service,
+		set:             set,
+	}
+	if err := run(*databaseURL, sim); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: fairness-sim [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Replays the jobs enqueued in the last days, recorded in job_results, under")
+	fmt.Fprintln(os.Stderr, "the current fairness settings (read from the environment like the workers")
+	fmt.Fprintln(os.Stderr, "do) and under the proposed ones, and reports the wait between enqueue and")
+	fmt.Fprintln(os.Stderr, "start per user tier. Policy flags default to the current settings.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  fairness-sim -days 14 -free-limit 2")
+	fmt.Fprintln(os.Stderr, "  fairness-sim -service spec-generator -rate-limit -user-rate 5 -priority-workers 30")
+}
+
+type simulation struct {
+	current         config.FairnessConfig
+	currentWorkers  config.QueueWorkers
+	days            int
+	proposed        config.FairnessConfig
+	proposedWorkers config.QueueWorkers
+	service         string
+	set             queueSet
+}
+
+func run(databaseURL string, sim simulation) error {
+	ctx := context.Background()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	until := time.Now().UTC()
+	since := until.AddDate(0, 0, -sim.days)
+	history, err := fairness.NewDBHistorySource(db.New(pool)).ListJobs(ctx, since, until)
+	if err != nil {
+		return err
+	}
+	jobs := sim.set.SelectJobs(history)
+	if len(jobs) == 0 {
+		fmt.Printf("No %s jobs enqueued since %s\n", sim.service, since.Format(dateLayout))
+		return nil
+	}
+
+	fmt.Printf("Replaying %d %s jobs enqueued %s to %s\n\n", len(jobs), sim.service, since.Format(dateLayout), until.Format(dateLayout))
+	printWaits(os.Stdout, []policyWaits{
+		{name: "observed", waits: fairness.ObservedWaits(jobs)},
+		{name: "current", simulated: true, waits: fairness.Simulate(jobs, NewPolicy(sim.current, sim.currentWorkers, sim.set, jobs))},
+		{name: "proposed", simulated: true, waits: fairness.Simulate(jobs, NewPolicy(sim.proposed, sim.proposedWorkers, sim.set, jobs))},
+	})
+	return nil
+}
+
+type policyWaits struct {
+	name      string
+	simulated bool // observed waits have no snooze count
+	waits     []fairness.TierWaits
+}
+
+func printWaits(out io.Writer, policies []policyWaits) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "POLICY\tTIER\tJOBS\tSNOOZES\tP50\tP90\tP99\tMAX")
+	for _, p := range policies {
+		for _, t := range p.waits {
+			tier := string(t.Tier)
+			if tier == "" {
+				tier = "system"
+			}
+			snoozes := "-"
+			if p.simulated {
+				snoozes = fmt.Sprint(t.Snoozes)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+				p.name, tier, t.Jobs, snoozes, roundWait(t.P50), roundWait(t.P90), roundWait(t.P99), roundWait(t.Max))
+		}
+	}
+	w.Flush()
+}
+
+func roundWait(d time.Duration) time.Duration {
+	return d.Round(time.Second)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/specvital/worker/internal/adapter/queue/analyze"
+	"github.com/specvital/worker/internal/adapter/queue/fairness"
+	"github.com/specvital/worker/internal/adapter/queue/specview"
+	"github.com/specvital/worker/internal/infra/config"
+)
+
+// queueSet names the base queues a service works, without region suffix.
+type queueSet struct {
+	Default   string
+	Priority  string
+	Scheduled string
+}
+
+var services = map[string]queueSet{
+	"analyzer":       {Default: analyze.QueueDefault, Priority: analyze.QueuePriority, Scheduled: analyze.QueueScheduled},
+	"spec-generator": {Default: specview.QueueDefault, Priority: specview.QueuePriority, Scheduled: specview.QueueScheduled},
+}
+
+// ParseService returns the queues of a service name.
+func ParseService(name string) (queueSet, error) {
+	set, ok := services[name]
+	if !ok {
+		return queueSet{}, fmt.Errorf("unknown service %q (want analyzer or spec-generator)", name)
+	}
+	return set, nil
+}
+
+// Workers returns the MaxWorkers of queue, a base queue of the set or one of
+// its regional copies; ok is false for queues of other services.
+func (s queueSet) Workers(queue string, workers config.QueueWorkers) (n int, ok bool) {
+	for _, q := range []struct {
+		base    string
+		workers int
+	}{
+		{s.Priority, workers.Priority},
+		{s.Default, workers.Default},
+		{s.Scheduled, workers.Scheduled},
+	} {
+		if queue == q.base || strings.HasPrefix(queue, q.base+"_") {
+			return q.workers, true
+		}
+	}
+	return 0, false
+}
+
+// SelectJobs returns the jobs of history run by the service.
+func (s queueSet) SelectJobs(history []fairness.HistoryJob) []fairness.HistoryJob {
+	var jobs []fairness.HistoryJob
+	for _, job := range history {
+		if _, ok := s.Workers(job.Queue, config.QueueWorkers{}); ok {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs
+}
+
+// ValidatePolicy rejects settings the workers would refuse to start with.
+func ValidatePolicy(cfg config.FairnessConfig, workers config.QueueWorkers) error {
+	if workers.Priority <= 0 || workers.Default <= 0 || workers.Scheduled <= 0 {
+		return fmt.Errorf("queue workers must be positive, got %+v", workers)
+	}
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.FreeConcurrentLimit <= 0 || cfg.ProConcurrentLimit <= 0 || cfg.EnterpriseConcurrentLimit <= 0 {
+		return fmt.Errorf("concurrent limits must be positive")
+	}
+	if cfg.SnoozeDuration <= 0 {
+		return fmt.Errorf("snooze must be positive, got %v", cfg.SnoozeDuration)
+	}
+	if cfg.SnoozeJitter < 0 {
+		return fmt.Errorf("snooze jitter must be non-negative, got %v", cfg.SnoozeJitter)
+	}
+	return nil
+}
+
+// NewPolicy builds the simulated policy of a fairness configuration and
+// queue worker counts, as the service containers build their middleware.
+func NewPolicy(cfg config.FairnessConfig, workers config.QueueWorkers, set queueSet, jobs []fairness.HistoryJob) fairness.Policy {
+	policy := fairness.Policy{Workers: make(map[string]int)}
+	for _, job := range jobs {
+		if n, ok := set.Workers(job.Queue, workers); ok {
+			policy.Workers[job.Queue] = n
+		}
+	}
+	if !cfg.Enabled {
+		return policy
+	}
+
+	policy.Concurrency = &fairness.Config{
+		FreeConcurrentLimit:       cfg.FreeConcurrentLimit,
+		ProConcurrentLimit:        cfg.ProConcurrentLimit,
+		EnterpriseConcurrentLimit: cfg.EnterpriseConcurrentLimit,
+		SnoozeDuration:            cfg.SnoozeDuration,
+		SnoozeJitter:              cfg.SnoozeJitter,
+	}
+	if cfg.RateLimitEnabled {
+		policy.RateLimits = &fairness.RateLimitConfig{
+			Codebase:     fairness.RateLimit{Burst: cfg.CodebaseBurst, PerMinute: cfg.CodebaseRatePerMinute},
+			SnoozeJitter: cfg.SnoozeJitter,
+			User:         fairness.RateLimit{Burst: cfg.UserBurst, PerMinute: cfg.UserRatePerMinute},
+		}
+	}
+	return policy
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/queue/fairness"
+	"github.com/specvital/worker/internal/infra/config"
+)
+
+func TestQueueSet_Workers(t *testing.T) {
+	set, err := ParseService("analyzer")
+	if err != nil {
+		t.Fatalf("ParseService() error = %v", err)
+	}
+	workers := config.QueueWorkers{Priority: 30, Default: 20, Scheduled: 5}
+
+	tests := []struct {
+		queue  string
+		want   int
+		wantOK bool
+	}{
+		{"analysis_priority", 30, true},
+		{"analysis_default_eu", 20, true},
+		{"analysis_scheduled", 5, true},
+		{"specview_default", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := set.Workers(tt.queue, workers); got != tt.want || ok != tt.wantOK {
+			t.Errorf("Workers(%q) = %d, %v; want %d, %v", tt.queue, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	if _, err := ParseService("webhookd"); err == nil {
+		t.Error("expected an error for an unknown service")
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	set, _ := ParseService("spec-generator")
+	workers := config.QueueWorkers{Priority: 4, Default: 2, Scheduled: 1}
+	jobs := []fairness.HistoryJob{{Queue: "specview_default"}, {Queue: "specview_priority_eu"}}
+	cfg := config.FairnessConfig{
+		Enabled:             true,
+		FreeConcurrentLimit: 1,
+		SnoozeDuration:      time.Minute,
+		UserBurst:           3,
+		UserRatePerMinute:   6,
+	}
+
+	policy := NewPolicy(cfg, workers, set, jobs)
+	if policy.Workers["specview_default"] != 2 || policy.Workers["specview_priority_eu"] != 4 || len(policy.Workers) != 2 {
+		t.Errorf("unexpected workers %v", policy.Workers)
+	}
+	if policy.Concurrency == nil || policy.Concurrency.SnoozeDuration != time.Minute {
+		t.Errorf("unexpected concurrency %+v", policy.Concurrency)
+	}
+	if policy.RateLimits != nil {
+		t.Error("expected no rate limits while they are disabled")
+	}
+
+	cfg.RateLimitEnabled = true
+	if policy := NewPolicy(cfg, workers, set, jobs); policy.RateLimits == nil || policy.RateLimits.User.Burst != 3 {
+		t.Errorf("unexpected rate limits %+v", policy.RateLimits)
+	}
+
+	cfg.Enabled = false
+	if policy := NewPolicy(cfg, workers, set, jobs); policy.Concurrency != nil || policy.RateLimits != nil {
+		t.Error("expected no limits while fairness is disabled")
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	workers := config.QueueWorkers{Priority: 1, Default: 1, Scheduled: 1}
+	cfg := config.FairnessConfig{Enabled: true, FreeConcurrentLimit: 1, ProConcurrentLimit: 1, EnterpriseConcurrentLimit: 1, SnoozeDuration: time.Second}
+
+	if err := ValidatePolicy(cfg, workers); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := ValidatePolicy(cfg, config.QueueWorkers{Priority: 1, Default: 0, Scheduled: 1}); err == nil {
+		t.Error("expected an error for a queue without workers")
+	}
+	cfg.FreeConcurrentLimit = 0
+	if err := ValidatePolicy(cfg, workers); err == nil {
+		t.Error("expected an error for a zero limit")
+	}
+	cfg.Enabled = false
+	if err := ValidatePolicy(cfg, workers); err != nil {
+		t.Errorf("limits are not checked while fairness is disabled, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/domain/notification"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+func main() {
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	codebaseID := flag.String("codebase", "", "Codebase ID the channel belongs to (add, list)")
+	userID := flag.String("user", "", "User ID the channel belongs to (add, list)")
+	kind := flag.String("kind", string(notification.ChannelSlack), "Channel kind: slack, discord or webhook (add only)")
+	on := flag.String("on", "all", "Outcomes sent to the channel: all, success or failure (add only)")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		os.Exit(1)
+	}
+
+	channel := notification.Channel{
+		CodebaseID: *codebaseID,
+		Kind:       notification.ChannelKind(*kind),
+		UserID:     *userID,
+	}
+	switch *on {
+	case "all":
+		channel.OnSuccess, channel.OnFailure = true, true
+	case "success":
+		channel.OnSuccess = true
+	case "failure":
+		channel.OnFailure = true
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown -on value %q (want all, success or failure)\n", *on)
+		os.Exit(1)
+	}
+
+	if err := run(*databaseURL, flag.Arg(0), flag.Args()[1:], channel); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: notification-channels [flags] add <url>")
+	fmt.Fprintln(os.Stderr, "       notification-channels [flags] list")
+	fmt.Fprintln(os.Stderr, "       notification-channels [flags] remove <channel-id...>")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Manages the channels told about finished analysis and spec document jobs")
+	fmt.Fprintln(os.Stderr, "of a codebase or a user. Workers send to them only with NOTIFICATIONS_ENABLED=true.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  notification-channels -codebase <id> -kind slack add https://hooks.slack.com/services/T000/B000/XXXX")
+	fmt.Fprintln(os.Stderr, "  notification-channels -user <id> -kind discord -on failure add https://discord.com/api/webhooks/1/abc")
+	fmt.Fprintln(os.Stderr, "  notification-channels -codebase <id> list")
+	fmt.Fprintln(os.Stderr, "  notification-channels remove 0b6f4c1e-8d9a-4b3e-9a57-2f1d6c0e7a11")
+}
+
+func run(databaseURL, command string, args []string, channel notification.Channel) error {
+	ctx := context.Background()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	repo := postgres.NewNotificationRepository(pool)
+
+	switch command {
+	case "add":
+		if len(args) != 1 {
+			printUsage()
+			return fmt.Errorf("add requires a channel URL")
+		}
+		channel.URL = args[0]
+		created, err := repo.CreateChannel(ctx, channel)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("added %s channel %s\n", created.Kind, created.ID)
+		return nil
+	case "list":
+		channels, err := repo.ListChannels(ctx, channel.CodebaseID, channel.UserID)
+		if err != nil {
+			return err
+		}
+		printChannels(os.Stdout, channels)
+		return nil
+	case "remove":
+		if len(args) == 0 {
+			return fmt.Errorf("remove requires at least one channel ID")
+		}
+		for _, id := range args {
+			if err := repo.DeleteChannel(ctx, id); err != nil {
+				return fmt.Errorf("remove %s: %w", id, err)
+			}
+			fmt.Printf("removed %s\n", id)
+		}
+		return nil
+	default:
+		printUsage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func printChannels(out io.Writer, channels []notification.Channel) {
+	if len(channels) == 0 {
+		fmt.Fprintln(out, "no notification channels")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tOWNER\tKIND\tON\tURL\tCREATED")
+	for _, c := range channels {
+		owner := "codebase " + c.CodebaseID
+		if c.UserID != "" {
+			owner = "user " + c.UserID
+		}
+		on := "all"
+		switch {
+		case !c.OnFailure:
+			on = "success"
+		case !c.OnSuccess:
+			on = "failure"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.ID,
+			owner,
+			c.Kind,
+			on,
+			redactURL(c.URL),
+			c.CreatedAt.Format(time.RFC3339),
+		)
+	}
+	w.Flush()
+}
+
+// redactURL keeps the host of a channel URL; webhook paths carry their secret.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "-"
+	}
+	return u.Scheme + "://" + u.Host + "/..."
+}
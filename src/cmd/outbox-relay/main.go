@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/specvital/worker/internal/app/bootstrap"
+	"github.com/specvital/worker/internal/infra/config"
+)
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg := bootstrap.OutboxRelayConfig{
+		DatabaseURL: os.Getenv("DATABASE_URL"),
+		Outbox:      config.LoadSettings().Outbox,
+		ServiceName: "outbox-relay",
+	}
+
+	if err := bootstrap.RunOutboxRelay(ctx, cfg); err != nil {
+		slog.Error("outbox relay failed", "error", err)
+		os.Exit(1)
+	}
+}
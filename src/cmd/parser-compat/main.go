@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	_ "github.com/specvital/core/pkg/parser/strategies/all"
+
+	"github.com/specvital/worker/internal/adapter/parser"
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/adapter/vcs"
+	"github.com/specvital/worker/internal/domain/parsercompat"
+	"github.com/specvital/worker/internal/infra/buildinfo"
+	"github.com/specvital/worker/internal/infra/db"
+	parsercompatuc "github.com/specvital/worker/internal/usecase/parsercompat"
+)
+
+// exitIncompatible signals a failed gate so CI can block the core bump.
+const exitIncompatible = 2
+
+func main() {
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	sample := flag.Int("sample", parsercompatuc.DefaultSampleSize, "Number of repositories to re-parse")
+	flag.Parse()
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		printUsage()
+		os.Exit(1)
+	}
+
+	report, err := run(*databaseURL, *sample)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	printReport(os.Stdout, report)
+	if !report.Compatible() {
+		os.Exit(exitIncompatible)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: parser-compat [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Re-parses a sample of stored analyses with the core version linked into")
+	fmt.Fprintln(os.Stderr, "this binary, compares test counts and stores a compatibility report.")
+	fmt.Fprintln(os.Stderr, "Exits with status 2 when any sample regressed.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+}
+
+func run(databaseURL string, sampleSize int) (*parsercompat.Report, error) {
+	ctx := context.Background()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	validateUC := parsercompatuc.NewValidateUseCase(
+		postgres.NewParserCompatRepository(pool),
+		vcs.NewGitVCS(),
+		parser.NewCoreParser(),
+	)
+	return validateUC.Execute(ctx, parsercompatuc.ValidateRequest{
+		CandidateVersion: buildinfo.ExtractCoreVersion(),
+		SampleSize:       sampleSize,
+	})
+}
+
+func printReport(out io.Writer, report *parsercompat.Report) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPO\tCOMMIT\tBASELINE\tSTATUS\tTESTS\tDETAIL")
+	for _, res := range report.Results {
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\t%s\t%d -> %d\t%s\n",
+			res.Sample.Owner, res.Sample.Repo,
+			shortSHA(res.Sample.CommitSHA),
+			res.Sample.ParserVersion,
+			res.Status,
+			res.BaselineTests, res.CandidateTests,
+			detail(res),
+		)
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "\nreport %s for core %s: %d match, %d mismatch, %d skipped, %d failed\n",
+		report.ID.String(),
+		report.CandidateVersion,
+		report.Count(parsercompat.StatusMatch),
+		report.Count(parsercompat.StatusMismatch),
+		report.Count(parsercompat.StatusSkipped),
+		report.Count(parsercompat.StatusFailed),
+	)
+	if report.Compatible() {
+		fmt.Fprintln(out, "compatible")
+	} else {
+		fmt.Fprintln(out, "NOT compatible")
+	}
+}
+
+func detail(res parsercompat.Result) string {
+	if res.Reason != "" {
+		return res.Reason
+	}
+	if len(res.FileDiffs) == 0 {
+		return ""
+	}
+	first := res.FileDiffs[0]
+	s := fmt.Sprintf("%s %d -> %d", first.Path, first.Baseline, first.Candidate)
+	if len(res.FileDiffs) > 1 {
+		s += fmt.Sprintf(" (+%d files)", len(res.FileDiffs)-1)
+	}
+	return s
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/domain/regen"
+	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/queue"
+	regenuc "github.com/specvital/worker/internal/usecase/regen"
+)
+
+const maxFailuresDisplay = 20
+
+func main() {
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	regionName := flag.String("region", os.Getenv("WORKER_REGION"), "Data-residency region of the target workers (empty for default)")
+	name := flag.String("name", "", "Campaign name, e.g. the prompt change (create only)")
+	model := flag.String("model", "", "Only documents generated by this model (create only)")
+	language := flag.String("language", "", "Only documents in this language (create only)")
+	before := flag.String("before", "", "Only documents created before this RFC 3339 time (create only, default: now)")
+	limit := flag.Int("limit", regen.DefaultLimit, "Maximum number of documents (create only)")
+	rate := flag.Int("rate", regen.DefaultRatePerMinute, "Jobs enqueued per minute (create only)")
+	maxInFlight := flag.Int("max-in-flight", regen.DefaultMaxInFlight, "Maximum unfinished jobs (create only)")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		os.Exit(1)
+	}
+
+	selection := regen.Selection{Language: *language, Limit: *limit, ModelID: *model}
+	if *before != "" {
+		t, err := time.Parse(time.RFC3339, *before)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -before: %v\n", err)
+			os.Exit(1)
+		}
+		selection.CreatedBefore = t
+	}
+	throttle := regen.Throttle{MaxInFlight: *maxInFlight, RatePerMinute: *rate}
+
+	if err := run(*databaseURL, *regionName, flag.Arg(0), flag.Args()[1:], *name, selection, throttle); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: regen [flags] <create|list|status|run|pause|resume|cancel> [campaign-id]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Regenerates spec documents in bulk, e.g. after a prompt fix.")
+	fmt.Fprintln(os.Stderr, "create selects the documents; run enqueues them at the campaign's rate")
+	fmt.Fprintln(os.Stderr, "and tracks their jobs until every document is done. pause, resume and")
+	fmt.Fprintln(os.Stderr, "cancel take effect on a running run within a minute.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  regen -name phase2-prompt-fix -model gemini-2.5-flash -before 2026-05-01T00:00:00Z create")
+	fmt.Fprintln(os.Stderr, "  regen list")
+	fmt.Fprintln(os.Stderr, "  regen run 3f6c1e2a-5b7d-4c8e-9a10-1b2c3d4e5f60")
+	fmt.Fprintln(os.Stderr, "  regen pause 3f6c1e2a-5b7d-4c8e-9a10-1b2c3d4e5f60")
+}
+
+func run(databaseURL, regionName, command string, args []string, name string, selection regen.Selection, throttle regen.Throttle) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	client, err := queue.NewClient(ctx, pool, queue.WithRegion(regionName))
+	if err != nil {
+		return fmt.Errorf("create queue client: %w", err)
+	}
+	defer client.Close()
+
+	campaignUC := regenuc.NewCampaignUseCase(postgres.NewRegenCampaignRepository(pool), client)
+
+	if command == "create" {
+		campaign, err := campaignUC.Create(ctx, name, selection, throttle)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created %s (%s) with %d documents\n", campaign.ID, campaign.Name, campaign.Progress.Total())
+		return nil
+	}
+	if command == "list" {
+		campaigns, err := campaignUC.List(ctx)
+		if err != nil {
+			return err
+		}
+		printCampaigns(os.Stdout, campaigns)
+		return nil
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("%s requires one campaign ID", command)
+	}
+	id := args[0]
+
+	switch command {
+	case "status":
+		campaign, err := campaignUC.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		failures, err := campaignUC.Failures(ctx, id, maxFailuresDisplay)
+		if err != nil {
+			return err
+		}
+		printCampaigns(os.Stdout, []regen.Campaign{*campaign})
+		printFailures(os.Stdout, failures)
+		return nil
+	case "run":
+		err := campaignUC.Run(ctx, id, func(result *regenuc.DispatchResult) {
+			p := result.Campaign.Progress
+			fmt.Printf("%s %s: %s, enqueued %d this round\n",
+				time.Now().Format(time.RFC3339), result.Campaign.Status, formatProgress(p), result.Enqueued)
+		})
+		if errors.Is(err, context.Canceled) {
+			fmt.Fprintln(os.Stderr, "stopped; run again to continue")
+			return nil
+		}
+		return err
+	case "pause":
+		return report(campaignUC.Pause(ctx, id), "paused", id)
+	case "resume":
+		return report(campaignUC.Resume(ctx, id), "resumed", id)
+	case "cancel":
+		return report(campaignUC.Cancel(ctx, id), "cancelled", id)
+	default:
+		printUsage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func report(err error, verb, id string) error {
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s\n", verb, id)
+	return nil
+}
+
+func printCampaigns(out io.Writer, campaigns []regen.Campaign) {
+	if len(campaigns) == 0 {
+		fmt.Fprintln(out, "no campaigns")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSTATUS\tPROGRESS\tRATE\tCREATED")
+	for _, c := range campaigns {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d/min, %d in flight\t%s\n",
+			c.ID,
+			c.Name,
+			c.Status,
+			formatProgress(c.Progress),
+			c.Throttle.RatePerMinute, c.Throttle.MaxInFlight,
+			c.CreatedAt.Format(time.RFC3339),
+		)
+	}
+	w.Flush()
+}
+
+func printFailures(out io.Writer, targets []regen.Target) {
+	if len(targets) == 0 {
+		return
+	}
+
+	fmt.Fprintln(out, "")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FAILED DOCUMENT\tJOB\tERROR")
+	for _, t := range targets {
+		job := "-"
+		if t.JobID != 0 {
+			job = fmt.Sprintf("%d", t.JobID)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", t.DocumentID, job, t.Error)
+	}
+	w.Flush()
+}
+
+func formatProgress(p regen.Progress) string {
+	return fmt.Sprintf("%d/%d done (%d failed, %d in flight)", p.Succeeded+p.Failed, p.Total(), p.Failed, p.Enqueued)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/deadletter"
+)
+
+// ParseJobIDs parses positional job ID arguments.
+func ParseJobIDs(args []string) ([]int64, error) {
+	ids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid job ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ParseKinds splits a comma-separated kind list, dropping empty entries.
+func ParseKinds(s string) []string {
+	var kinds []string
+	for _, kind := range strings.Split(s, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// ShortSHA returns the first 7 characters of a commit SHA.
+func ShortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// FormatAnalysis renders the joined analysis as "<id> (<status>)" or "-" when none matched.
+func FormatAnalysis(job deadletter.Job) string {
+	if job.AnalysisID == "" {
+		return "-"
+	}
+	return job.AnalysisID + " (" + job.AnalysisStatus + ")"
+}
+
+// Truncate shortens s to max runes on a single line.
+func Truncate(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseJobIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []int64
+		wantErr bool
+	}{
+		{name: "no arguments", args: nil, want: []int64{}},
+		{name: "multiple IDs", args: []string{"12", "34"}, want: []int64{12, 34}},
+		{name: "non-numeric", args: []string{"abc"}, wantErr: true},
+		{name: "zero", args: []string{"0"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJobIDs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseJobIDs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("ParseJobIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKinds(t *testing.T) {
+	got := ParseKinds(" analysis:analyze, ,specview:generate ")
+	want := []string{"analysis:analyze", "specview:generate"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ParseKinds() = %v, want %v", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		max  int
+		want string
+	}{
+		{name: "short", in: "error", max: 10, want: "error"},
+		{name: "collapses whitespace", in: "line one\n  line two", max: 40, want: "line one line two"},
+		{name: "truncates", in: "0123456789abc", max: 10, want: "0123456..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Truncate(tt.in, tt.max); got != tt.want {
+				t.Errorf("Truncate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
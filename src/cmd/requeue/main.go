@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/domain/deadletter"
+	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/queue"
+	deadletteruc "github.com/specvital/worker/internal/usecase/deadletter"
+)
+
+const (
+	defaultKinds    = "analysis:analyze,specview:generate"
+	defaultSince    = 7 * 24 * time.Hour
+	maxErrorDisplay = 80
+)
+
+func main() {
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	kinds := flag.String("kind", defaultKinds, "Comma-separated job kinds")
+	since := flag.Duration("since", defaultSince, "Only jobs discarded within this window (0 for no limit)")
+	limit := flag.Int("limit", deadletter.DefaultLimit, "Maximum number of jobs")
+	all := flag.Bool("all", false, "Requeue every listed job")
+	flag.Parse()
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		os.Exit(1)
+	}
+
+	jobIDs, err := ParseJobIDs(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		printUsage()
+		os.Exit(1)
+	}
+
+	filter := deadletter.Filter{
+		JobIDs: jobIDs,
+		Kinds:  ParseKinds(*kinds),
+		Limit:  *limit,
+	}
+	if *since > 0 {
+		filter.DiscardedAfter = time.Now().Add(-*since)
+	}
+
+	requeue := *all || len(jobIDs) > 0
+	if err := run(*databaseURL, filter, requeue); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: requeue [flags] [job-id...]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Lists discarded jobs. With job IDs or -all, re-enqueues them.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  requeue")
+	fmt.Fprintln(os.Stderr, "  requeue -kind specview:generate -since 24h")
+	fmt.Fprintln(os.Stderr, "  requeue 1042 1057")
+	fmt.Fprintln(os.Stderr, "  requeue -kind analysis:analyze -since 1h -all")
+}
+
+func run(databaseURL string, filter deadletter.Filter, requeue bool) error {
+	ctx := context.Background()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	client, err := queue.NewClient(ctx, pool)
+	if err != nil {
+		return fmt.Errorf("create queue client: %w", err)
+	}
+	defer client.Close()
+
+	requeueUC := deadletteruc.NewRequeueUseCase(postgres.NewDeadLetterRepository(pool), client)
+
+	if !requeue {
+		jobs, err := requeueUC.List(ctx, filter)
+		if err != nil {
+			return err
+		}
+		printJobs(os.Stdout, jobs)
+		return nil
+	}
+
+	result, err := requeueUC.Requeue(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, job := range result.Requeued {
+		fmt.Printf("requeued %d (%s %s/%s)\n", job.ID, job.Kind, job.Owner, job.Repo)
+	}
+	for id, err := range result.Failed {
+		fmt.Fprintf(os.Stderr, "failed %d: %v\n", id, err)
+	}
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("%d of %d jobs failed to requeue", len(result.Failed), len(result.Failed)+len(result.Requeued))
+	}
+	return nil
+}
+
+func printJobs(out io.Writer, jobs []deadletter.Job) {
+	if len(jobs) == 0 {
+		fmt.Fprintln(out, "no discarded jobs")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tKIND\tATTEMPTS\tDISCARDED\tREPO\tCOMMIT\tANALYSIS\tLAST ERROR")
+	for _, job := range jobs {
+		fmt.Fprintf(w, "%d\t%s\t%d/%d\t%s\t%s/%s\t%s\t%s\t%s\n",
+			job.ID,
+			job.Kind,
+			job.Attempt, job.MaxAttempts,
+			job.DiscardedAt.Format(time.RFC3339),
+			job.Owner, job.Repo,
+			ShortSHA(job.CommitSHA),
+			FormatAnalysis(job),
+			Truncate(job.LastError, maxErrorDisplay),
+		)
+	}
+	w.Flush()
+}
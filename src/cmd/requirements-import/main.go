@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/domain/region"
+	"github.com/specvital/worker/internal/domain/requirement"
+	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/queue"
+	requirementuc "github.com/specvital/worker/internal/usecase/requirement"
+)
+
+func main() {
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	codebaseID := flag.String("codebase", "", "Codebase ID the requirements belong to (required)")
+	documentID := flag.String("document", "", "Spec document ID to match against (optional, enqueues matching)")
+	name := flag.String("name", "", "Requirement set name (defaults to file name)")
+	format := flag.String("format", "", "Input format: csv or json (defaults to file extension)")
+	regionName := flag.String("region", os.Getenv("WORKER_REGION"), "Data-residency region of the target workers (empty for default)")
+	flag.Parse()
+
+	if flag.NArg() < 1 || *codebaseID == "" {
+		printUsage()
+		os.Exit(1)
+	}
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		os.Exit(1)
+	}
+
+	if err := region.Validate(*regionName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	path := flag.Arg(0)
+	if *name == "" {
+		*name = filepath.Base(path)
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	if err := run(*databaseURL, *regionName, path, *codebaseID, *documentID, *name, requirement.Format(*format)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: requirements-import -codebase <id> [flags] <file>")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Arguments:")
+	fmt.Fprintln(os.Stderr, "  <file>  CSV (key,title[,description]) or JSON ([{key,title,description}])")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  requirements-import -codebase <uuid> prd.csv")
+	fmt.Fprintln(os.Stderr, "  requirements-import -codebase <uuid> -document <uuid> -name \"Q3 PRD\" reqs.json")
+}
+
+func run(databaseURL, regionName, path, codebaseID, documentID, name string, format requirement.Format) error {
+	ctx := context.Background()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open requirements file: %w", err)
+	}
+	defer file.Close()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	importUC := requirementuc.NewImportRequirementsUseCase(postgres.NewRequirementRepository(pool))
+	set, err := importUC.Execute(ctx, requirementuc.ImportRequest{
+		CodebaseID: codebaseID,
+		Format:     format,
+		Name:       name,
+		Source:     file,
+	})
+	if err != nil {
+		return fmt.Errorf("import requirements: %w", err)
+	}
+
+	slog.Info("requirements imported",
+		"requirement_set_id", set.ID,
+		"codebase_id", codebaseID,
+		"count", len(set.Requirements),
+	)
+
+	if documentID == "" {
+		return nil
+	}
+
+	client, err := queue.NewClient(ctx, pool, queue.WithRegion(regionName))
+	if err != nil {
+		return fmt.Errorf("create queue client: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.EnqueueRequirementMatch(ctx, set.ID, documentID); err != nil {
+		return fmt.Errorf("enqueue requirement match: %w", err)
+	}
+
+	slog.Info("requirement match enqueued",
+		"requirement_set_id", set.ID,
+		"document_id", documentID,
+	)
+	return nil
+}
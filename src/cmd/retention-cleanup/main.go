@@ -14,11 +14,14 @@ func main() {
 	slog.SetDefault(logger)
 
 	cfg := bootstrap.RetentionConfig{
-		BatchSize:   getEnvInt("RETENTION_BATCH_SIZE", 0),
-		BatchSleep:  getEnvDuration("RETENTION_BATCH_SLEEP", 0),
-		DatabaseURL: os.Getenv("DATABASE_URL"),
-		ServiceName: "retention-cleanup",
-		Timeout:     getEnvDuration("RETENTION_TIMEOUT", 0),
+		BatchSize:            getEnvInt("RETENTION_BATCH_SIZE", 0),
+		BatchSleep:           getEnvDuration("RETENTION_BATCH_SLEEP", 0),
+		DatabaseURL:          os.Getenv("DATABASE_URL"),
+		DeletedDocumentGrace: getEnvDuration("SPEC_DOCUMENT_DELETE_GRACE", 0),
+		KeepVersions:         getEnvInt("SPEC_DOCUMENT_KEEP_VERSIONS", 0),
+		ServiceName:          "retention-cleanup",
+		Timeout:              getEnvDuration("RETENTION_TIMEOUT", 0),
+		VersionRetentionDays: getEnvInt("SPEC_DOCUMENT_VERSION_RETENTION_DAYS", 0),
 	}
 
 	if _, err := bootstrap.RunRetentionCleanup(cfg); err != nil {
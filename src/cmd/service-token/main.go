@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/domain/servicetoken"
+	"github.com/specvital/worker/internal/infra/db"
+	servicetokenuc "github.com/specvital/worker/internal/usecase/servicetoken"
+)
+
+func main() {
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	name := flag.String("name", "", "Token name, e.g. the calling system (issue only)")
+	scopes := flag.String("scopes", string(servicetoken.ScopeEnqueue), "Comma-separated scopes: enqueue, read-status, admin (issue only)")
+	ttl := flag.Duration("ttl", 0, "Token lifetime (0 for no expiry, issue only)")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		os.Exit(1)
+	}
+
+	parsedScopes, err := servicetoken.ParseScopes(*scopes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	params := servicetoken.IssueParams{Name: *name, Scopes: parsedScopes}
+	if *ttl > 0 {
+		expiresAt := time.Now().Add(*ttl)
+		params.ExpiresAt = &expiresAt
+	}
+
+	if err := run(*databaseURL, flag.Arg(0), flag.Args()[1:], params); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: service-token [flags] <issue|list|revoke> [token-id...]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Manages bearer tokens for machine callers of the worker API.")
+	fmt.Fprintln(os.Stderr, "The plaintext is printed once on issue and cannot be recovered.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  service-token -name ci -scopes enqueue,read-status issue")
+	fmt.Fprintln(os.Stderr, "  service-token -name ops -scopes admin -ttl 720h issue")
+	fmt.Fprintln(os.Stderr, "  service-token list")
+	fmt.Fprintln(os.Stderr, "  service-token revoke 0b6f4c1e-8d9a-4b3e-9a57-2f1d6c0e7a11")
+}
+
+func run(databaseURL, command string, args []string, params servicetoken.IssueParams) error {
+	ctx := context.Background()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	tokenUC := servicetokenuc.NewServiceTokenUseCase(postgres.NewServiceTokenRepository(pool))
+
+	switch command {
+	case "issue":
+		issued, err := tokenUC.Issue(ctx, params)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "issued %s (%s) with scopes %s\n", issued.Token.ID, issued.Token.Name, formatScopes(issued.Token.Scopes))
+		fmt.Println(issued.Plaintext)
+		return nil
+	case "list":
+		tokens, err := tokenUC.List(ctx)
+		if err != nil {
+			return err
+		}
+		printTokens(os.Stdout, tokens)
+		return nil
+	case "revoke":
+		if len(args) == 0 {
+			return fmt.Errorf("revoke requires at least one token ID")
+		}
+		for _, id := range args {
+			if err := tokenUC.Revoke(ctx, id); err != nil {
+				return fmt.Errorf("revoke %s: %w", id, err)
+			}
+			fmt.Printf("revoked %s\n", id)
+		}
+		return nil
+	default:
+		printUsage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func printTokens(out io.Writer, tokens []servicetoken.Token) {
+	if len(tokens) == 0 {
+		fmt.Fprintln(out, "no service tokens")
+		return
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tSTATE\tCREATED\tLAST USED")
+	for _, t := range tokens {
+		state := "active"
+		switch {
+		case t.RevokedAt != nil:
+			state = "revoked"
+		case !t.ActiveAt(now):
+			state = "expired"
+		}
+		lastUsed := "-"
+		if t.LastUsedAt != nil {
+			lastUsed = t.LastUsedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.ID,
+			t.Name,
+			t.Prefix,
+			formatScopes(t.Scopes),
+			state,
+			t.CreatedAt.Format(time.RFC3339),
+			lastUsed,
+		)
+	}
+	w.Flush()
+}
+
+func formatScopes(scopes []servicetoken.Scope) string {
+	parts := make([]string, len(scopes))
+	for i, s := range scopes {
+		parts[i] = string(s)
+	}
+	return strings.Join(parts, ",")
+}
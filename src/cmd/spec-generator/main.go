@@ -1,14 +1,27 @@
 package main
 
 import (
+	"flag"
 	"log/slog"
 	"os"
 
 	"github.com/specvital/worker/internal/app/bootstrap"
+	"github.com/specvital/worker/internal/infra/buildinfo"
 	"github.com/specvital/worker/internal/infra/config"
 )
 
 func main() {
+	showVersion := flag.Bool("version", false, "Print build info, features and job kinds as JSON and exit")
+	flag.Parse()
+
+	if *showVersion {
+		report := bootstrap.SpecGeneratorBuildReport(specGeneratorConfig(config.LoadSettings()), buildinfo.CurrentIdentity())
+		if err := bootstrap.PrintBuildReport(os.Stdout, report); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
 
@@ -18,8 +31,8 @@ func main() {
 		os.Exit(1)
 	}
 
-	if !cfg.MockMode && cfg.GeminiAPIKey == "" {
-		slog.Error("GEMINI_API_KEY is required for spec-generator (set MOCK_MODE=true to skip)")
+	if !cfg.MockMode && cfg.AI.APIKey == "" {
+		slog.Error("AI_API_KEY is required for spec-generator (set MOCK_MODE=true to skip)", "provider", cfg.AI.Provider)
 		os.Exit(1)
 	}
 
@@ -27,17 +40,40 @@ func main() {
 		slog.Info("starting in mock mode - AI calls will be simulated")
 	}
 
-	if err := bootstrap.StartSpecGenerator(bootstrap.SpecGeneratorConfig{
-		ServiceName:       "spec-generator",
-		DatabaseURL:       cfg.DatabaseURL,
-		Fairness:          cfg.Fairness,
-		GeminiAPIKey:      cfg.GeminiAPIKey,
-		GeminiPhase1Model: cfg.GeminiPhase1Model,
-		GeminiPhase2Model: cfg.GeminiPhase2Model,
-		MockMode:          cfg.MockMode,
-		QueueWorkers:      cfg.Queue.Specgen,
-	}); err != nil {
+	if err := bootstrap.StartSpecGenerator(specGeneratorConfig(cfg)); err != nil {
 		slog.Error("spec-generator failed", "error", err)
 		os.Exit(1)
 	}
 }
+
+func specGeneratorConfig(cfg *config.Config) bootstrap.SpecGeneratorConfig {
+	return bootstrap.SpecGeneratorConfig{
+		ServiceName:        "spec-generator",
+		AI:                 cfg.AI,
+		CacheKeyHash:       cfg.CacheKeyHash,
+		CacheScope:         cfg.CacheScope,
+		DatabaseURL:        cfg.DatabaseURL,
+		DedupSimilarity:    cfg.DedupSimilarity,
+		DrainTimeout:       cfg.DrainTimeout,
+		DocumentEncryption: cfg.DocumentEncryption,
+		DocumentSharing:    cfg.DocumentSharing,
+		Fairness:           cfg.Fairness,
+		FanOut:             cfg.FanOut,
+		GeneratorMode:      cfg.GeneratorMode,
+		HTTPAddr:           cfg.HTTPAddr,
+		Idle:               cfg.Idle,
+		InFlightWait:       cfg.InFlightWait,
+		MockMode:           cfg.MockMode,
+		Notifications:      cfg.Notifications,
+		PromptExperiment:   cfg.PromptExperiment,
+		Quality:            cfg.Quality,
+		QueueWorkers:       cfg.Queue.Specgen,
+		QuotaWarnings:      cfg.QuotaWarnings,
+		Region:             cfg.Region,
+		Retry:              cfg.Retry,
+		SemanticCache:      cfg.SemanticCache,
+		SLATargets:         cfg.SLATargets,
+		Takeout:            cfg.Takeout,
+		Tracing:            cfg.Tracing,
+	}
+}
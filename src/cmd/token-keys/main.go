@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/app"
+	"github.com/specvital/worker/internal/infra/config"
+	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/tokenkeys"
+)
+
+func main() {
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	legacyKey := flag.String("legacy-key", os.Getenv("ENCRYPTION_KEY"), "Base64 key of tokens without a key ID")
+	webReadsEnvelope := flag.Bool("web-reads-envelope", false, "Confirm every reader of oauth_accounts, the Web app included, opens sk1: envelopes (required by reencrypt)")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		os.Exit(1)
+	}
+
+	if err := run(*databaseURL, *legacyKey, config.LoadSettings().TokenKeys, flag.Arg(0), *webReadsEnvelope); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: token-keys [flags] <status|reencrypt>")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Manages the keys encrypting stored OAuth tokens. Versioned keys are read")
+	fmt.Fprintln(os.Stderr, "like the analyzer reads them: ENCRYPTION_KEYS, ENCRYPTION_KEYS_FILE or")
+	fmt.Fprintln(os.Stderr, "ENCRYPTION_KEYS_VAULT_PATH, current key first.")
+	fmt.Fprintln(os.Stderr, "  status     count stored tokens by the key that sealed them")
+	fmt.Fprintln(os.Stderr, "  reencrypt  seal every token not sealed with the current key with it;")
+	fmt.Fprintln(os.Stderr, "             requires -web-reads-envelope, since the rows are shared with the")
+	fmt.Fprintln(os.Stderr, "             Web app, which only reads tokens sealed with ENCRYPTION_KEY")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Examples:")
+	fmt.Fprintln(os.Stderr, "  ENCRYPTION_KEYS=2026-10=...,2025-01=... token-keys status")
+	fmt.Fprintln(os.Stderr, "  ENCRYPTION_KEYS_FILE=/run/secrets/token-keys token-keys -web-reads-envelope reencrypt")
+}
+
+func run(databaseURL, legacyKey string, keys config.TokenKeysConfig, command string, webReadsEnvelope bool) error {
+	ctx := context.Background()
+
+	if command != "status" && command != "reencrypt" {
+		printUsage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+	if command == "reencrypt" && !webReadsEnvelope {
+		return errors.New("reencrypt rewrites oauth_accounts tokens to sk1: envelopes, which the Web app cannot open; " +
+			"pass -web-reads-envelope once every reader of the table supports them")
+	}
+
+	keyring, err := app.NewTokenKeyring(ctx, legacyKey, keys)
+	if err != nil {
+		return err
+	}
+	defer keyring.Close()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	rotation := postgres.NewTokenKeyRotation(pool, keyring)
+
+	if command == "status" {
+		usage, err := rotation.KeyUsage(ctx)
+		if err != nil {
+			return err
+		}
+		printUsageByKey(usage, keyring.CurrentKeyID())
+		return nil
+	}
+
+	result, err := rotation.Reencrypt(ctx)
+	if err != nil {
+		return fmt.Errorf("re-encrypted %d tokens, run reencrypt to resume: %w", result.Reencrypted, err)
+	}
+	fmt.Printf("re-encrypted %d tokens with %s, %d changed meanwhile, %d failed to open\n",
+		result.Reencrypted, keyLabel(keyring.CurrentKeyID()), result.Skipped, result.Failed)
+	if result.Failed > 0 {
+		return fmt.Errorf("%d tokens are sealed with keys missing from the keyring", result.Failed)
+	}
+	return nil
+}
+
+func printUsageByKey(usage map[string]int, current string) {
+	ids := make([]string, 0, len(usage))
+	for id := range usage {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	if len(ids) == 0 {
+		fmt.Println("no stored tokens")
+	}
+	for _, id := range ids {
+		marker := ""
+		if id == current {
+			marker = " (current)"
+		}
+		fmt.Printf("%-20s %d%s\n", keyLabel(id), usage[id], marker)
+	}
+}
+
+func keyLabel(id string) string {
+	if id == tokenkeys.LegacyKeyID {
+		return "legacy"
+	}
+	return id
+}
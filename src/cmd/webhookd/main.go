@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/specvital/worker/internal/adapter/ai/registry"
+	"github.com/specvital/worker/internal/adapter/api"
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/adapter/webhook"
+	"github.com/specvital/worker/internal/domain/region"
+	"github.com/specvital/worker/internal/infra/buildinfo"
+	"github.com/specvital/worker/internal/infra/config"
+	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/httpserver"
+	"github.com/specvital/worker/internal/infra/metrics"
+	"github.com/specvital/worker/internal/infra/queue"
+	servicetokenuc "github.com/specvital/worker/internal/usecase/servicetoken"
+	specviewuc "github.com/specvital/worker/internal/usecase/specview"
+	webhookuc "github.com/specvital/worker/internal/usecase/webhook"
+)
+
+const (
+	defaultAddr = ":8080"
+	githubPath  = "/webhooks/github"
+)
+
+func main() {
+	databaseURL := flag.String("database", os.Getenv("DATABASE_URL"), "Database URL")
+	addr := flag.String("addr", defaultListenAddr(), "Listen address (default: HTTP_ADDR, PORT or :8080)")
+	regionName := flag.String("region", os.Getenv("WORKER_REGION"), "Data-residency region of the target workers (empty for default)")
+	flag.Parse()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: Database URL is required (use -database flag or set DATABASE_URL)")
+		os.Exit(1)
+	}
+
+	secret := os.Getenv("GITHUB_WEBHOOK_SECRET")
+	if secret == "" {
+		fmt.Fprintln(os.Stderr, "Error: GITHUB_WEBHOOK_SECRET is required")
+		os.Exit(1)
+	}
+
+	if err := region.Validate(*regionName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := run(*databaseURL, *addr, *regionName, []byte(secret)); err != nil {
+		slog.Error("webhookd failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// defaultListenAddr returns HTTP_ADDR, falling back to the platform-injected PORT.
+func defaultListenAddr() string {
+	if addr := os.Getenv("HTTP_ADDR"); addr != "" {
+		return addr
+	}
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return defaultAddr
+}
+
+func run(databaseURL, addr, regionName string, secret []byte) error {
+	ctx := context.Background()
+
+	pool, err := db.NewPool(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	settings := config.LoadSettings()
+	client, err := queue.NewClient(ctx, pool, queue.WithHostPools(settings.HostPools), queue.WithRegion(regionName))
+	if err != nil {
+		return fmt.Errorf("create queue client: %w", err)
+	}
+	defer client.Close()
+
+	webhookRepo := postgres.NewWebhookRepository(pool)
+	triggerUC := webhookuc.NewTriggerUseCase(webhookRepo, client, webhookuc.WithDeliveryStore(webhookRepo))
+	tokenUC := servicetokenuc.NewServiceTokenUseCase(postgres.NewServiceTokenRepository(pool))
+
+	// Estimates probe the generator's caches, so they need its model ID, key hash,
+	// cache scope and prompt experiment.
+	providerName := settings.AI.Provider
+	if settings.MockMode {
+		providerName = registry.ProviderMock
+	}
+	modelID, err := registry.Default().ModelID(providerName, settings.AI.Phase1Model)
+	if err != nil {
+		return fmt.Errorf("resolve spec-view model: %w", err)
+	}
+	estimateUC := specviewuc.NewEstimateSpecViewUseCase(
+		postgres.NewSpecDocumentRepository(pool),
+		modelID,
+		specviewuc.WithCacheKeyHash(settings.CacheKeyHash.Algorithm, settings.CacheKeyHash.Legacy),
+		specviewuc.WithCacheScope(settings.CacheScope),
+		specviewuc.WithPromptExperiment(settings.PromptExperiment.Candidate, settings.PromptExperiment.Percent),
+	)
+
+	report := buildinfo.NewReport("webhookd", buildinfo.CurrentIdentity(), nil, map[string]bool{"github_webhooks": true, "service_api": true, "specview_estimate": true})
+	report.Region = regionName
+
+	if err := metrics.RegisterQueueDepth(metrics.Default, db.New(pool)); err != nil {
+		slog.Warn("failed to register queue depth metric (non-critical)", "error", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /version", httpserver.VersionHandler(report))
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.Handle("POST "+githubPath, webhook.NewGitHubHandler(secret, triggerUC))
+	api.Register(mux, tokenUC, client, postgres.NewAnalysisStatusRepository(pool), estimateUC, postgres.NewDocumentPinRepository(pool), postgres.NewDocumentLifecycleRepository(pool))
+
+	srv, err := httpserver.NewServer(addr, mux)
+	if err != nil {
+		return fmt.Errorf("http server: %w", err)
+	}
+	srv.Start()
+	slog.Info("webhookd ready", "addr", srv.Addr(), "path", githubPath, "region", regionName)
+
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, syscall.SIGTERM, syscall.SIGINT)
+	sig := <-shutdown
+	slog.Info("shutdown signal received", "signal", sig.String())
+
+	if err := srv.Stop(ctx); err != nil {
+		slog.Error("http server stop error", "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/ai/llm"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+const (
+	DefaultBaseURL     = "https://api.anthropic.com/v1"
+	DefaultAPIVersion  = "2023-06-01"
+	DefaultPhase1Model = "claude-sonnet-4-5"
+	DefaultPhase2Model = "claude-haiku-4-5"
+
+	maxOutputTokens = 32000
+	requestTimeout  = 10 * time.Minute
+	stopMaxTokens   = "max_tokens"
+	stopRefusal     = "refusal"
+)
+
+// Config holds configuration for the Anthropic backend.
+type Config struct {
+	APIKey     string
+	APIVersion string // anthropic-version header (default: DefaultAPIVersion)
+	BaseURL    string // default: DefaultBaseURL
+}
+
+// Validate validates the configuration.
+func (c *Config) Validate() error {
+	if c.APIKey == "" {
+		return errors.New("anthropic API key is required")
+	}
+	return nil
+}
+
+var (
+	_ llm.Backend = (*Backend)(nil)
+	_ llm.Pinger  = (*Backend)(nil)
+)
+
+// Backend sends completions to the Anthropic Messages API.
+type Backend struct {
+	client *http.Client
+	config Config
+}
+
+// NewBackend creates a new Anthropic backend.
+func NewBackend(config Config) (*Backend, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	if config.APIVersion == "" {
+		config.APIVersion = DefaultAPIVersion
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+
+	return &Backend{
+		client: &http.Client{Timeout: requestTimeout},
+		config: config,
+	}, nil
+}
+
+type message struct {
+	Content string `json:"content"`
+	Role    string `json:"role"`
+}
+
+type messagesRequest struct {
+	MaxTokens   int       `json:"max_tokens"`
+	Messages    []message `json:"messages"`
+	Model       string    `json:"model"`
+	System      string    `json:"system"`
+	Temperature float64   `json:"temperature"`
+}
+
+type messagesResponse struct {
+	Content []struct {
+		Text string `json:"text"`
+		Type string `json:"type"`
+	} `json:"content"`
+	Model      string `json:"model"`
+	StopReason string `json:"stop_reason"`
+	Usage      *struct {
+		InputTokens  int32 `json:"input_tokens"`
+		OutputTokens int32 `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (b *Backend) Name() string { return "anthropic" }
+
+// Ping lists a single model, which authenticates without generating content.
+func (b *Backend) Ping(ctx context.Context) error {
+	body, err := llm.Get(ctx, b.client, b.config.BaseURL+"/models?limit=1", b.headers())
+	if err != nil {
+		return fmt.Errorf("anthropic models: %w", err)
+	}
+	return body.Close()
+}
+
+func (b *Backend) headers() map[string]string {
+	return map[string]string{
+		"anthropic-version": b.config.APIVersion,
+		"x-api-key":         b.config.APIKey,
+	}
+}
+
+func (b *Backend) Complete(ctx context.Context, req llm.Request) (string, *specview.TokenUsage, error) {
+	body := messagesRequest{
+		MaxTokens:   maxOutputTokens,
+		Messages:    []message{{Role: "user", Content: req.UserPrompt}},
+		Model:       req.Model,
+		System:      req.SystemPrompt,
+		Temperature: 0,
+	}
+	var resp messagesResponse
+	if err := llm.PostJSON(ctx, b.client, b.config.BaseURL+"/messages", b.headers(), body, &resp); err != nil {
+		return "", nil, fmt.Errorf("anthropic messages: %w", err)
+	}
+
+	switch resp.StopReason {
+	case stopMaxTokens:
+		return "", nil, fmt.Errorf("%w: reduce input size or split into chunks", specview.ErrOutputTruncated)
+	case stopRefusal:
+		return "", nil, fmt.Errorf("%w: content blocked (%s)", specview.ErrInvalidInput, resp.StopReason)
+	}
+
+	var sb strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+
+	var usage *specview.TokenUsage
+	if resp.Usage != nil {
+		usage = &specview.TokenUsage{
+			CandidatesTokens: resp.Usage.OutputTokens,
+			Model:            req.Model,
+			PromptTokens:     resp.Usage.InputTokens,
+			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+		}
+	}
+
+	// The Messages API has no JSON response mode, so fenced output must be unwrapped.
+	return llm.StripCodeFence(sb.String()), usage, nil
+}
@@ -0,0 +1,85 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/specvital/worker/internal/adapter/ai/llm"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	if err := (&Config{}).Validate(); err == nil {
+		t.Error("expected error for empty API key")
+	}
+	if err := (&Config{APIKey: "k"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBackend_Complete(t *testing.T) {
+	t.Run("sends system prompt and unwraps fenced JSON", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/messages" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			if r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") != DefaultAPIVersion {
+				t.Error("expected auth and version headers")
+			}
+			var req messagesRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.System != "sys" || req.Model != "claude-test" || len(req.Messages) != 1 {
+				t.Errorf("unexpected request: %+v", req)
+			}
+			w.Write([]byte(`{"content":[{"type":"text","text":"` + "```json\\n{\\\"a\\\":1}\\n```" + `"}],"stop_reason":"end_turn","usage":{"input_tokens":4,"output_tokens":2}}`))
+		}))
+		defer srv.Close()
+
+		b, _ := NewBackend(Config{APIKey: "key", BaseURL: srv.URL})
+		text, usage, err := b.Complete(context.Background(), llm.Request{Model: "claude-test", SystemPrompt: "sys", UserPrompt: "u"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text != `{"a":1}` {
+			t.Errorf("unexpected text %q", text)
+		}
+		if usage == nil || usage.TotalTokens != 6 {
+			t.Errorf("unexpected usage %+v", usage)
+		}
+	})
+
+	t.Run("maps max_tokens to truncation", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"content":[{"type":"text","text":"{"}],"stop_reason":"max_tokens"}`))
+		}))
+		defer srv.Close()
+
+		b, _ := NewBackend(Config{APIKey: "key", BaseURL: srv.URL})
+		_, _, err := b.Complete(context.Background(), llm.Request{Model: "m"})
+		if !errors.Is(err, specview.ErrOutputTruncated) {
+			t.Errorf("expected ErrOutputTruncated, got %v", err)
+		}
+	})
+}
+
+func TestBackend_Ping(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/models" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "key" {
+			t.Error("expected auth header")
+		}
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer srv.Close()
+
+	b, _ := NewBackend(Config{APIKey: "key", BaseURL: srv.URL})
+	if err := b.Ping(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
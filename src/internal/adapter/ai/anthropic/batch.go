@@ -0,0 +1,287 @@
+package anthropic
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/ai/llm"
+	"github.com/specvital/worker/internal/adapter/ai/prompt"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+const (
+	// ClassificationCustomID identifies the single request in a classification batch.
+	ClassificationCustomID = "phase1"
+
+	batchStatusEnded     = "ended"
+	batchStatusCanceling = "canceling"
+
+	// maxResultLineBytes bounds one JSONL result line (Phase 1 output can be large).
+	maxResultLineBytes = 16 * 1024 * 1024
+)
+
+var _ specview.BatchProvider = (*BatchProvider)(nil)
+
+// BatchProvider implements specview.BatchProvider using the Message Batches API.
+type BatchProvider struct {
+	client      *http.Client
+	config      Config
+	phase1Model string
+}
+
+// NewBatchProvider creates a batch provider. phase1Model defaults to DefaultPhase1Model.
+func NewBatchProvider(config Config, phase1Model string) (*BatchProvider, error) {
+	backend, err := NewBackend(config)
+	if err != nil {
+		return nil, err
+	}
+	if phase1Model == "" {
+		phase1Model = DefaultPhase1Model
+	}
+	return &BatchProvider{
+		client:      backend.client,
+		config:      backend.config,
+		phase1Model: phase1Model,
+	}, nil
+}
+
+type batchRequestItem struct {
+	CustomID string          `json:"custom_id"`
+	Params   messagesRequest `json:"params"`
+}
+
+type batchCreateRequest struct {
+	Requests []batchRequestItem `json:"requests"`
+}
+
+type batchResponse struct {
+	EndedAt          *time.Time `json:"ended_at"`
+	ID               string     `json:"id"`
+	ProcessingStatus string     `json:"processing_status"`
+	RequestCounts    struct {
+		Canceled   int `json:"canceled"`
+		Errored    int `json:"errored"`
+		Expired    int `json:"expired"`
+		Processing int `json:"processing"`
+		Succeeded  int `json:"succeeded"`
+	} `json:"request_counts"`
+	ResultsURL string `json:"results_url"`
+}
+
+type batchResultLine struct {
+	CustomID string `json:"custom_id"`
+	Result   struct {
+		Error *struct {
+			Error struct {
+				Message string `json:"message"`
+				Type    string `json:"type"`
+			} `json:"error"`
+		} `json:"error"`
+		Message *messagesResponse `json:"message"`
+		Type    string            `json:"type"`
+	} `json:"result"`
+}
+
+// CreateClassificationJob submits the full Phase 1 prompt without chunking;
+// the batch API has no per-minute quota, so large inputs are accepted as-is.
+func (p *BatchProvider) CreateClassificationJob(ctx context.Context, input specview.Phase1Input) (string, error) {
+	if len(input.Files) == 0 {
+		return "", fmt.Errorf("%w: no files to classify", specview.ErrInvalidInput)
+	}
+	userPrompt := prompt.BuildPhase1UserPrompt(input, input.Language)
+	if len(input.Taxonomy) > 0 {
+		userPrompt = prompt.BuildPhase1UserPromptWithAnchors(input, input.Language, input.Taxonomy)
+	}
+	return p.CreateJob(ctx, []specview.BatchRequest{{
+		CustomID:     ClassificationCustomID,
+		Model:        p.phase1Model,
+		SystemPrompt: prompt.SystemPrompt(prompt.Phase1, specview.PromptVersion(ctx)),
+		UserPrompt:   userPrompt,
+	}})
+}
+
+func (p *BatchProvider) CreateJob(ctx context.Context, requests []specview.BatchRequest) (string, error) {
+	if len(requests) == 0 {
+		return "", fmt.Errorf("%w: batch has no requests", specview.ErrInvalidInput)
+	}
+
+	items := make([]batchRequestItem, len(requests))
+	for i, r := range requests {
+		if r.CustomID == "" || r.Model == "" {
+			return "", fmt.Errorf("%w: request %d missing custom ID or model", specview.ErrInvalidInput, i)
+		}
+		items[i] = batchRequestItem{
+			CustomID: r.CustomID,
+			Params: messagesRequest{
+				MaxTokens:   maxOutputTokens,
+				Messages:    []message{{Role: "user", Content: r.UserPrompt}},
+				Model:       r.Model,
+				System:      r.SystemPrompt,
+				Temperature: 0,
+			},
+		}
+	}
+
+	var resp batchResponse
+	if err := llm.PostJSON(ctx, p.client, p.config.BaseURL+"/messages/batches", p.headers(), batchCreateRequest{Requests: items}, &resp); err != nil {
+		return "", fmt.Errorf("anthropic create batch: %w", err)
+	}
+	if resp.ID == "" {
+		return "", errors.New("anthropic create batch: empty batch ID")
+	}
+	return resp.ID, nil
+}
+
+func (p *BatchProvider) GetJobStatus(ctx context.Context, jobID string) (*specview.BatchJobStatus, error) {
+	if jobID == "" {
+		return nil, fmt.Errorf("%w: job ID is required", specview.ErrInvalidInput)
+	}
+
+	var resp batchResponse
+	endpoint := p.config.BaseURL + "/messages/batches/" + url.PathEscape(jobID)
+	if err := llm.GetJSON(ctx, p.client, endpoint, p.headers(), &resp); err != nil {
+		return nil, fmt.Errorf("anthropic get batch: %w", err)
+	}
+
+	status := &specview.BatchJobStatus{
+		CompletedAt: resp.EndedAt,
+		JobID:       resp.ID,
+		State:       mapBatchState(resp),
+	}
+
+	if resp.ProcessingStatus == batchStatusEnded && resp.ResultsURL != "" {
+		results, err := p.fetchResults(ctx, resp.ResultsURL)
+		if err != nil {
+			return nil, err
+		}
+		status.Results = results
+	}
+
+	return status, nil
+}
+
+// CancelJob asks Anthropic to stop the batch. Requests already processing
+// finish and are billed; the rest end canceled.
+func (p *BatchProvider) CancelJob(ctx context.Context, jobID string) error {
+	if jobID == "" {
+		return fmt.Errorf("%w: job ID is required", specview.ErrInvalidInput)
+	}
+
+	var resp batchResponse
+	endpoint := p.config.BaseURL + "/messages/batches/" + url.PathEscape(jobID) + "/cancel"
+	if err := llm.PostJSON(ctx, p.client, endpoint, p.headers(), struct{}{}, &resp); err != nil {
+		return fmt.Errorf("anthropic cancel batch: %w", err)
+	}
+	return nil
+}
+
+// mapBatchState collapses Anthropic's processing status and request counts.
+// An ended batch counts as succeeded when at least one request succeeded or
+// errored, since both produce results; per-request failures are reported
+// through BatchResult.Err. Only batches whose requests all expired or were
+// canceled end expired or cancelled.
+func mapBatchState(resp batchResponse) specview.BatchJobState {
+	switch resp.ProcessingStatus {
+	case batchStatusEnded:
+		counts := resp.RequestCounts
+		switch {
+		case counts.Succeeded > 0 || counts.Errored > 0:
+			return specview.BatchJobStateSucceeded
+		case counts.Expired > 0:
+			return specview.BatchJobStateExpired
+		default:
+			return specview.BatchJobStateCancelled
+		}
+	case batchStatusCanceling:
+		return specview.BatchJobStateRunning
+	default:
+		if resp.RequestCounts.Succeeded+resp.RequestCounts.Errored > 0 {
+			return specview.BatchJobStateRunning
+		}
+		return specview.BatchJobStatePending
+	}
+}
+
+func (p *BatchProvider) fetchResults(ctx context.Context, resultsURL string) ([]specview.BatchResult, error) {
+	body, err := llm.Get(ctx, p.client, resultsURL, p.headers())
+	if err != nil {
+		return nil, fmt.Errorf("anthropic batch results: %w", err)
+	}
+	defer body.Close()
+
+	var results []specview.BatchResult
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxResultLineBytes)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var parsed batchResultLine
+		if err := json.Unmarshal([]byte(line), &parsed); err != nil {
+			return nil, fmt.Errorf("decode batch result: %w", err)
+		}
+		results = append(results, toBatchResult(parsed))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read batch results: %w", err)
+	}
+	return results, nil
+}
+
+func toBatchResult(line batchResultLine) specview.BatchResult {
+	result := specview.BatchResult{CustomID: line.CustomID}
+
+	switch line.Result.Type {
+	case "succeeded":
+		msg := line.Result.Message
+		if msg == nil {
+			result.Err = errors.New("succeeded result without message")
+			return result
+		}
+		if msg.StopReason == stopMaxTokens {
+			result.Err = fmt.Errorf("%w: reduce input size or split into chunks", specview.ErrOutputTruncated)
+			return result
+		}
+		var sb strings.Builder
+		for _, block := range msg.Content {
+			if block.Type == "text" {
+				sb.WriteString(block.Text)
+			}
+		}
+		result.Text = llm.StripCodeFence(sb.String())
+		if msg.Usage != nil {
+			result.Usage = &specview.TokenUsage{
+				CandidatesTokens: msg.Usage.OutputTokens,
+				Model:            msg.Model,
+				PromptTokens:     msg.Usage.InputTokens,
+				TotalTokens:      msg.Usage.InputTokens + msg.Usage.OutputTokens,
+			}
+		}
+	case "errored":
+		if line.Result.Error != nil {
+			result.Err = fmt.Errorf("batch request errored: %s: %s",
+				line.Result.Error.Error.Type, line.Result.Error.Error.Message)
+		} else {
+			result.Err = errors.New("batch request errored")
+		}
+	default:
+		result.Err = fmt.Errorf("batch request %s", line.Result.Type)
+	}
+
+	return result
+}
+
+func (p *BatchProvider) headers() map[string]string {
+	return map[string]string{
+		"anthropic-version": p.config.APIVersion,
+		"x-api-key":         p.config.APIKey,
+	}
+}
@@ -0,0 +1,149 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestBatchProvider_CreateClassificationJob(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/messages/batches" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		var req batchCreateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Requests) != 1 || req.Requests[0].CustomID != ClassificationCustomID {
+			t.Errorf("unexpected batch request: %+v", req)
+		}
+		if req.Requests[0].Params.Model != "claude-batch" || req.Requests[0].Params.System == "" {
+			t.Errorf("expected model and system prompt, got %+v", req.Requests[0].Params)
+		}
+		w.Write([]byte(`{"id":"msgbatch_1","processing_status":"in_progress"}`))
+	}))
+	defer srv.Close()
+
+	p, err := NewBatchProvider(Config{APIKey: "key", BaseURL: srv.URL}, "claude-batch")
+	if err != nil {
+		t.Fatalf("NewBatchProvider failed: %v", err)
+	}
+
+	input := specview.Phase1Input{
+		Files:    []specview.FileInfo{{Path: "a_test.go", Tests: []specview.TestInfo{{Index: 0, Name: "TestA"}}}},
+		Language: "English",
+	}
+	jobID, err := p.CreateClassificationJob(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if jobID != "msgbatch_1" {
+		t.Errorf("jobID = %q, want msgbatch_1", jobID)
+	}
+
+	if _, err := p.CreateClassificationJob(context.Background(), specview.Phase1Input{}); !errors.Is(err, specview.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for empty input, got %v", err)
+	}
+}
+
+func TestBatchProvider_GetJobStatus(t *testing.T) {
+	t.Run("pending batch has no results", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"id":"b1","processing_status":"in_progress","request_counts":{"processing":1}}`))
+		}))
+		defer srv.Close()
+
+		p, _ := NewBatchProvider(Config{APIKey: "key", BaseURL: srv.URL}, "")
+		status, err := p.GetJobStatus(context.Background(), "b1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if status.State != specview.BatchJobStatePending || status.State.IsTerminal() {
+			t.Errorf("unexpected state %s", status.State)
+		}
+		if len(status.Results) != 0 {
+			t.Errorf("expected no results, got %d", len(status.Results))
+		}
+	})
+
+	t.Run("ended batch collects per-request results", func(t *testing.T) {
+		var srv *httptest.Server
+		srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/messages/batches/b1":
+				w.Write([]byte(`{"id":"b1","processing_status":"ended","ended_at":"2025-01-01T00:00:00Z","request_counts":{"succeeded":1,"errored":1},"results_url":"` + srv.URL + `/results/b1"}`))
+			case "/results/b1":
+				w.Write([]byte(strings.Join([]string{
+					`{"custom_id":"phase1","result":{"type":"succeeded","message":{"model":"claude-batch","content":[{"type":"text","text":"{\"domains\":[]}"}],"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":5}}}}`,
+					`{"custom_id":"other","result":{"type":"errored","error":{"error":{"type":"invalid_request_error","message":"too long"}}}}`,
+				}, "\n")))
+			default:
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+		}))
+		defer srv.Close()
+
+		p, _ := NewBatchProvider(Config{APIKey: "key", BaseURL: srv.URL}, "")
+		status, err := p.GetJobStatus(context.Background(), "b1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if status.State != specview.BatchJobStateSucceeded || status.CompletedAt == nil {
+			t.Errorf("unexpected status %+v", status)
+		}
+		if len(status.Results) != 2 {
+			t.Fatalf("expected 2 results, got %d", len(status.Results))
+		}
+		if status.Results[0].Text != `{"domains":[]}` || status.Results[0].Usage.TotalTokens != 15 {
+			t.Errorf("unexpected success result %+v", status.Results[0])
+		}
+		if status.Results[1].Err == nil {
+			t.Error("expected errored result to carry an error")
+		}
+	})
+
+	t.Run("expired batch", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"id":"b1","processing_status":"ended","request_counts":{"expired":1}}`))
+		}))
+		defer srv.Close()
+
+		p, _ := NewBatchProvider(Config{APIKey: "key", BaseURL: srv.URL}, "")
+		status, err := p.GetJobStatus(context.Background(), "b1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if status.State != specview.BatchJobStateExpired {
+			t.Errorf("State = %s, want expired", status.State)
+		}
+	})
+}
+
+func TestBatchProvider_CancelJob(t *testing.T) {
+	var cancels int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/messages/batches/b1/cancel" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		cancels++
+		w.Write([]byte(`{"id":"b1","processing_status":"canceling"}`))
+	}))
+	defer srv.Close()
+
+	p, _ := NewBatchProvider(Config{APIKey: "key", BaseURL: srv.URL}, "")
+	if err := p.CancelJob(context.Background(), "b1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cancels != 1 {
+		t.Errorf("cancels = %d, want 1", cancels)
+	}
+
+	if err := p.CancelJob(context.Background(), ""); !errors.Is(err, specview.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for empty job ID, got %v", err)
+	}
+}
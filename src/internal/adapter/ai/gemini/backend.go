@@ -0,0 +1,104 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"google.golang.org/genai"
+
+	"github.com/specvital/worker/internal/adapter/ai/llm"
+	"github.com/specvital/worker/internal/adapter/ai/reliability"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+var _ llm.Backend = (*genaiBackend)(nil)
+
+// genaiBackend sends completions to the Gemini API.
+type genaiBackend struct {
+	client *genai.Client
+}
+
+func (b *genaiBackend) Name() string { return "gemini" }
+
+// Ping lists a single model, which authenticates without generating content.
+func (b *genaiBackend) Ping(ctx context.Context) error {
+	_, err := b.client.Models.List(ctx, &genai.ListModelsConfig{PageSize: 1})
+	return err
+}
+
+func (b *genaiBackend) Complete(ctx context.Context, req llm.Request) (string, *specview.TokenUsage, error) {
+	config := &genai.GenerateContentConfig{
+		Temperature:      genai.Ptr(float32(0.0)), // Deterministic output
+		Seed:             genai.Ptr(defaultSeed),
+		MaxOutputTokens:  maxOutputTokens,
+		ResponseMIMEType: "application/json",
+		SystemInstruction: &genai.Content{
+			Parts: []*genai.Part{{Text: req.SystemPrompt}},
+		},
+		// Disable thinking to reduce processing time for large inputs.
+		// gemini-2.5-flash has thinking enabled by default (dynamic budget).
+		// This significantly reduces timeout risk for Phase 1 classification.
+		ThinkingConfig: &genai.ThinkingConfig{
+			ThinkingBudget: genai.Ptr(int32(0)),
+		},
+	}
+
+	result, err := b.client.Models.GenerateContent(ctx, req.Model, genai.Text(req.UserPrompt), config)
+	if err != nil {
+		err = markQuotaExhausted(err)
+		// Only wrap as retryable if it's a server-side or transient error
+		if reliability.IsRetryable(err) {
+			return "", nil, &reliability.RetryableError{Err: err}
+		}
+		return "", nil, err
+	}
+
+	// Check FinishReason before extracting text.
+	// MAX_TOKENS indicates output was truncated - not retryable, requires input reduction.
+	if len(result.Candidates) > 0 {
+		candidate := result.Candidates[0]
+		switch candidate.FinishReason {
+		case genai.FinishReasonMaxTokens:
+			slog.WarnContext(ctx, "gemini output truncated due to token limit",
+				"model", req.Model,
+				"finish_reason", candidate.FinishReason,
+				"finish_message", candidate.FinishMessage,
+			)
+			return "", nil, fmt.Errorf("%w: reduce input size or split into chunks", specview.ErrOutputTruncated)
+		case genai.FinishReasonSafety, genai.FinishReasonRecitation, genai.FinishReasonBlocklist, genai.FinishReasonProhibitedContent, genai.FinishReasonSPII:
+			slog.WarnContext(ctx, "gemini output blocked by safety filters",
+				"model", req.Model,
+				"finish_reason", candidate.FinishReason,
+				"finish_message", candidate.FinishMessage,
+			)
+			return "", nil, fmt.Errorf("%w: content blocked (%s)", specview.ErrInvalidInput, candidate.FinishReason)
+		}
+	}
+
+	// Extract token usage from response metadata
+	var usage *specview.TokenUsage
+	if result.UsageMetadata != nil {
+		usage = &specview.TokenUsage{
+			CandidatesTokens: result.UsageMetadata.CandidatesTokenCount,
+			Model:            req.Model,
+			PromptTokens:     result.UsageMetadata.PromptTokenCount,
+			TotalTokens:      result.UsageMetadata.TotalTokenCount,
+		}
+	}
+
+	return result.Text(), usage, nil
+}
+
+// markQuotaExhausted wraps quota and rate limit errors (HTTP 429,
+// RESOURCE_EXHAUSTED) in specview.ErrRateLimited, so callers can route the
+// work elsewhere.
+func markQuotaExhausted(err error) error {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %w", specview.ErrRateLimited, err)
+	}
+	return err
+}
@@ -0,0 +1,21 @@
+package gemini
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestMarkQuotaExhausted(t *testing.T) {
+	quota := genai.APIError{Code: http.StatusTooManyRequests, Status: "RESOURCE_EXHAUSTED"}
+	if err := markQuotaExhausted(quota); !errors.Is(err, specview.ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited for a quota error, got %v", err)
+	}
+	if err := markQuotaExhausted(genai.APIError{Code: http.StatusInternalServerError}); errors.Is(err, specview.ErrRateLimited) {
+		t.Errorf("expected a server error to stay unmarked, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/genai"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// DefaultEmbeddingModel is the embedding model of the semantic behavior cache.
+const DefaultEmbeddingModel = "gemini-embedding-001"
+
+// embedBatchSize is the most texts the Gemini API embeds in one request.
+const embedBatchSize = 100
+
+var _ specview.Embedder = (*Embedder)(nil)
+
+type embedContentFunc func(ctx context.Context, model string, contents []*genai.Content, config *genai.EmbedContentConfig) (*genai.EmbedContentResponse, error)
+
+// Embedder embeds test names with a Gemini embedding model for the semantic
+// behavior cache.
+type Embedder struct {
+	embed embedContentFunc
+	model string
+}
+
+// NewEmbedder creates an Embedder using model, or DefaultEmbeddingModel when
+// model is empty.
+func NewEmbedder(ctx context.Context, apiKey, model string) (*Embedder, error) {
+	if apiKey == "" {
+		return nil, errors.New("gemini API key is required")
+	}
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:  apiKey,
+		Backend: genai.BackendGeminiAPI,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+	return newEmbedder(client.Models.EmbedContent, model), nil
+}
+
+func newEmbedder(embed embedContentFunc, model string) *Embedder {
+	if model == "" {
+		model = DefaultEmbeddingModel
+	}
+	return &Embedder{embed: embed, model: model}
+}
+
+// Embed returns an embedding of specview.EmbeddingDimensions values per text.
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	dimensions := int32(specview.EmbeddingDimensions)
+	config := &genai.EmbedContentConfig{
+		OutputDimensionality: &dimensions,
+		TaskType:             "SEMANTIC_SIMILARITY",
+	}
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embedBatchSize {
+		batch := texts[start:min(start+embedBatchSize, len(texts))]
+		contents := make([]*genai.Content, len(batch))
+		for i, text := range batch {
+			contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+		}
+		resp, err := e.embed(ctx, e.model, contents, config)
+		if err != nil {
+			return nil, fmt.Errorf("embed content: %w", err)
+		}
+		if len(resp.Embeddings) != len(batch) {
+			return nil, fmt.Errorf("embed content: got %d embeddings for %d texts", len(resp.Embeddings), len(batch))
+		}
+		for _, embedding := range resp.Embeddings {
+			if len(embedding.Values) != specview.EmbeddingDimensions {
+				return nil, fmt.Errorf("embed content: got %d dimensions, want %d", len(embedding.Values), specview.EmbeddingDimensions)
+			}
+			embeddings = append(embeddings, embedding.Values)
+		}
+	}
+	return embeddings, nil
+}
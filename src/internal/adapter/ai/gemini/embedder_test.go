@@ -0,0 +1,56 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestEmbedder_Embed(t *testing.T) {
+	var batches []int
+	var model, taskType string
+	embedder := newEmbedder(func(ctx context.Context, m string, contents []*genai.Content, config *genai.EmbedContentConfig) (*genai.EmbedContentResponse, error) {
+		model, taskType = m, config.TaskType
+		if config.OutputDimensionality == nil || *config.OutputDimensionality != specview.EmbeddingDimensions {
+			t.Errorf("expected %d output dimensions, got %v", specview.EmbeddingDimensions, config.OutputDimensionality)
+		}
+		batches = append(batches, len(contents))
+		resp := &genai.EmbedContentResponse{}
+		for range contents {
+			resp.Embeddings = append(resp.Embeddings, &genai.ContentEmbedding{Values: make([]float32, specview.EmbeddingDimensions)})
+		}
+		return resp, nil
+	}, "")
+
+	texts := make([]string, 250)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("Suite > test %d", i)
+	}
+	embeddings, err := embedder.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(embeddings) != len(texts) {
+		t.Errorf("expected %d embeddings, got %d", len(texts), len(embeddings))
+	}
+	if fmt.Sprint(batches) != "[100 100 50]" {
+		t.Errorf("expected batches of 100, got %v", batches)
+	}
+	if model != DefaultEmbeddingModel || taskType != "SEMANTIC_SIMILARITY" {
+		t.Errorf("unexpected model %q or task type %q", model, taskType)
+	}
+}
+
+func TestEmbedder_EmbedRejectsWrongDimensions(t *testing.T) {
+	embedder := newEmbedder(func(ctx context.Context, m string, contents []*genai.Content, config *genai.EmbedContentConfig) (*genai.EmbedContentResponse, error) {
+		return &genai.EmbedContentResponse{Embeddings: []*genai.ContentEmbedding{{Values: make([]float32, 3072)}}}, nil
+	}, "gemini-embedding-001")
+
+	if _, err := embedder.Embed(context.Background(), []string{"test"}); err == nil {
+		t.Error("expected an error for embeddings of the wrong size")
+	}
+}
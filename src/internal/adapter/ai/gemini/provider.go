@@ -0,0 +1,82 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/genai"
+
+	"github.com/specvital/worker/internal/adapter/ai/pipeline"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+const (
+	defaultPhase1Model = "gemini-2.5-flash"
+	defaultPhase2Model = "gemini-2.5-flash-lite"
+	defaultSeed        = int32(42) // Fixed seed for deterministic output
+
+	// maxOutputTokens is the maximum output tokens for Gemini API.
+	// Gemini 2.5 models support up to 65,536 output tokens.
+	// Required for Phase 1 with large test sets (thousands of test indices in JSON).
+	maxOutputTokens = int32(65536)
+)
+
+// Config holds configuration for the Gemini provider.
+type Config struct {
+	APIKey      string
+	Phase1Model string                    // Model for domain classification (default: gemini-2.5-flash)
+	Phase2Model string                    // Model for test conversion (default: gemini-2.5-flash-lite)
+	Quarantine  specview.OutputQuarantine // Stores unparseable responses (optional)
+
+	// Models tried in order when the phase model is rate limited or
+	// unavailable, e.g. gemini-2.5-flash then gemini-2.5-flash-lite.
+	// Phase 3 and placement follow the Phase 1 chain.
+	Phase1Fallbacks []string
+	Phase2Fallbacks []string
+
+	ResponseCacheMaxEntries int           // Bound on cached responses (default: 10000)
+	ResponseCacheTTL        time.Duration // How long identical prompts reuse a response; zero disables the cache
+}
+
+// Validate validates the configuration.
+func (c *Config) Validate() error {
+	if c.APIKey == "" {
+		return errors.New("gemini API key is required")
+	}
+	return nil
+}
+
+// NewProvider creates the phase pipeline backed by the Gemini API.
+func NewProvider(ctx context.Context, config Config) (*pipeline.Provider, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:  config.APIKey,
+		Backend: genai.BackendGeminiAPI,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+
+	phase1Model := config.Phase1Model
+	if phase1Model == "" {
+		phase1Model = defaultPhase1Model
+	}
+
+	phase2Model := config.Phase2Model
+	if phase2Model == "" {
+		phase2Model = defaultPhase2Model
+	}
+
+	provider := pipeline.NewProvider(&genaiBackend{client: client}, phase1Model, phase2Model)
+	if config.ResponseCacheTTL > 0 {
+		provider.SetResponseCache(pipeline.NewResponseCache(config.ResponseCacheTTL, config.ResponseCacheMaxEntries))
+	}
+	provider.SetQuarantine(config.Quarantine)
+	provider.SetFallbackModels(config.Phase1Fallbacks, config.Phase2Fallbacks)
+	return provider, nil
+}
@@ -1,8 +1,6 @@
 package gemini
 
-import (
-	"testing"
-)
+import "testing"
 
 func TestConfig_Validate(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,295 @@
+package heuristic
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// confidence is what heuristic output claims: lower than a model's, so the
+// documents read as drafts, but above the default low-confidence threshold so
+// every behavior is not flagged.
+const confidence = 0.6
+
+// genericDirs are directory names that say nothing about the domain of the
+// tests below them, so domains are named after the nearest other directory.
+var genericDirs = map[string]bool{
+	"__tests__": true, "e2e": true, "integration": true, "internal": true, "lib": true,
+	"pkg": true, "spec": true, "specs": true, "src": true, "test": true, "tests": true, "unit": true,
+}
+
+// testPrefixes are stripped from test names before they are made readable.
+var testPrefixes = []string{"Test", "test_", "test ", "it_", "it "}
+
+// abbreviations are spelled out in behavior descriptions.
+var abbreviations = map[string]string{
+	"cfg":  "config",
+	"ctx":  "context",
+	"db":   "database",
+	"err":  "error",
+	"errs": "errors",
+	"msg":  "message",
+	"req":  "request",
+	"resp": "response",
+}
+
+// Provider implements specview.AIProvider with rules instead of a model, for
+// dry runs and for generating while the AI provider is unavailable. Domains
+// follow test directories, features follow the top-level suite or the file
+// name, and behaviors are the test names made readable. Output is
+// deterministic, immediate and costs no tokens.
+type Provider struct{}
+
+// NewProvider creates a new heuristic provider.
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// ClassifyDomains groups tests into a domain per directory, ordered by path,
+// and a feature per top-level suite or test file.
+func (p *Provider) ClassifyDomains(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+	byDir := make(map[string][]specview.FileInfo)
+	for _, file := range input.Files {
+		dir := path.Dir(file.Path)
+		byDir[dir] = append(byDir[dir], file)
+	}
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	slices.Sort(dirs)
+
+	output := &specview.Phase1Output{Domains: []specview.DomainGroup{}}
+	for _, dir := range dirs {
+		domain := findOrAddDomain(output, domainName(dir))
+		if domain.Description == "" {
+			domain.Description = fmt.Sprintf("Tests under %s", dir)
+			if dir == "." {
+				domain.Description = "Tests at the repository root"
+			}
+		}
+		for _, file := range byDir[dir] {
+			for _, test := range file.Tests {
+				feature := findOrAddFeature(domain, featureName(file.Path, test.SuitePath))
+				feature.TestIndices = append(feature.TestIndices, test.Index)
+			}
+		}
+	}
+	return output, nil, nil
+}
+
+// ConvertTestNames describes each test by its readable name.
+func (p *Provider) ConvertTestNames(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+	behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+	for i, test := range input.Tests {
+		behaviors[i] = specview.BehaviorSpec{
+			Confidence:  confidence,
+			Description: sentence(test.Name),
+			TestIndex:   test.Index,
+		}
+	}
+	return &specview.Phase2Output{Behaviors: behaviors}, nil, nil
+}
+
+// PlaceNewTests places a new test into the existing feature named like its
+// top-level suite. Tests without a suite or matching no feature are left
+// unplaced.
+func (p *Provider) PlaceNewTests(ctx context.Context, input specview.PlacementInput) (*specview.PlacementOutput, *specview.TokenUsage, error) {
+	output := &specview.PlacementOutput{Placements: make([]specview.TestPlacement, 0)}
+	if input.ExistingStructure == nil {
+		return output, nil, nil
+	}
+	for _, test := range input.NewTests {
+		if test.SuitePath == "" {
+			continue
+		}
+		name := featureName("", test.SuitePath)
+		for _, d := range input.ExistingStructure.Domains {
+			if i := slices.IndexFunc(d.Features, func(f specview.FeatureGroup) bool { return f.Name == name }); i >= 0 {
+				output.Placements = append(output.Placements, specview.TestPlacement{
+					DomainName:  d.Name,
+					FeatureName: d.Features[i].Name,
+					TestIndex:   test.Index,
+				})
+				break
+			}
+		}
+	}
+	return output, nil, nil
+}
+
+// GenerateSummary lists the domains of the document.
+func (p *Provider) GenerateSummary(ctx context.Context, input specview.Phase3Input) (*specview.Phase3Output, *specview.TokenUsage, error) {
+	names := make([]string, len(input.Domains))
+	behaviors := 0
+	for i, d := range input.Domains {
+		names[i] = d.Name
+		for _, f := range d.Features {
+			behaviors += len(f.Behaviors)
+		}
+	}
+	return &specview.Phase3Output{
+		Summary: fmt.Sprintf("Outline of %d domains (%s) with %d behaviors, derived from test names without AI.",
+			len(input.Domains), strings.Join(names, ", "), behaviors),
+	}, nil, nil
+}
+
+// TranslateTexts returns the texts unchanged.
+func (p *Provider) TranslateTexts(ctx context.Context, input specview.TranslationInput) (*specview.TranslationOutput, *specview.TokenUsage, error) {
+	return &specview.TranslationOutput{Texts: slices.Clone(input.Texts)}, nil, nil
+}
+
+// Close releases resources (no-op for the heuristic provider).
+func (p *Provider) Close() error {
+	return nil
+}
+
+// domainName names a domain after the nearest directory of dir that is not
+// generic, e.g. "Payments" for "src/payments/__tests__".
+func domainName(dir string) string {
+	parts := strings.Split(dir, "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] != "" && parts[i] != "." && !genericDirs[strings.ToLower(parts[i])] {
+			return title(parts[i])
+		}
+	}
+	return "Core"
+}
+
+// featureName names a feature after the top-level suite of a test, or its
+// file without test suffixes, e.g. "Refund" for "refund.test.ts".
+func featureName(filePath, suitePath string) string {
+	if suite, _, _ := strings.Cut(suitePath, " > "); strings.TrimSpace(suite) != "" {
+		return title(suite)
+	}
+	name := path.Base(filePath)
+	if i := strings.Index(name, "."); i > 0 {
+		name = name[:i]
+	}
+	for _, suffix := range []string{"_test", "_spec", "Test", "Spec", "Tests"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	name = strings.TrimPrefix(name, "test_")
+	if name == "" || name == "." || name == "/" {
+		return "General"
+	}
+	return title(name)
+}
+
+// title makes a name readable and capitalizes its first letter, e.g.
+// "Payment Methods" for "payment_methods".
+func title(s string) string {
+	words := splitWords(s)
+	for i, w := range words {
+		words[i] = capitalize(w)
+	}
+	return strings.Join(words, " ")
+}
+
+// sentence makes a test name a readable sentence, e.g. "Login fails with
+// invalid password" for "TestLogin_FailsWithInvalidPassword". Test prefixes
+// are dropped, abbreviations spelled out and acronyms keep their case; the
+// cases of a Go subtest, e.g. "TestLogin/empty_password", are separated by a
+// comma.
+func sentence(name string) string {
+	var clauses []string
+	for _, part := range strings.Split(name, "/") {
+		words := splitWords(trimTestPrefix(part))
+		for i, w := range words {
+			if full, ok := abbreviations[strings.ToLower(w)]; ok {
+				words[i] = full
+			} else if strings.ToUpper(w) != w {
+				words[i] = strings.ToLower(w[:1]) + w[1:]
+			}
+		}
+		if len(words) > 0 {
+			clauses = append(clauses, strings.Join(words, " "))
+		}
+	}
+	if len(clauses) == 0 {
+		return name
+	}
+	return capitalize(strings.Join(clauses, ", "))
+}
+
+// trimTestPrefix drops the test prefix of name, e.g. "Test" of "TestLogin"
+// but not of "Testimonials render".
+func trimTestPrefix(name string) string {
+	for _, prefix := range testPrefixes {
+		after, ok := strings.CutPrefix(name, prefix)
+		if !ok || after == "" {
+			continue
+		}
+		if prefix == "Test" && unicode.IsLower([]rune(after)[0]) {
+			continue
+		}
+		return after
+	}
+	return name
+}
+
+// splitWords splits s at spaces, underscores, dashes and camel case, keeping
+// acronyms whole: "ParseURLQuery" is "Parse", "URL", "Query".
+func splitWords(s string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(s)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	for i, r := range runes {
+		if unicode.IsSpace(r) || r == '_' || r == '-' {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := word[len(word)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
+
+func capitalize(s string) string {
+	for i, r := range s {
+		return string(unicode.ToUpper(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
+
+func findOrAddDomain(output *specview.Phase1Output, name string) *specview.DomainGroup {
+	for i := range output.Domains {
+		if output.Domains[i].Name == name {
+			return &output.Domains[i]
+		}
+	}
+	output.Domains = append(output.Domains, specview.DomainGroup{Confidence: confidence, Name: name})
+	return &output.Domains[len(output.Domains)-1]
+}
+
+func findOrAddFeature(domain *specview.DomainGroup, name string) *specview.FeatureGroup {
+	for i := range domain.Features {
+		if domain.Features[i].Name == name {
+			return &domain.Features[i]
+		}
+	}
+	domain.Features = append(domain.Features, specview.FeatureGroup{
+		Confidence:  confidence,
+		Description: fmt.Sprintf("Tests of %s", name),
+		Name:        name,
+	})
+	return &domain.Features[len(domain.Features)-1]
+}
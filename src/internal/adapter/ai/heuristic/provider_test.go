@@ -0,0 +1,107 @@
+package heuristic
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestProvider_ClassifyDomains(t *testing.T) {
+	input := specview.Phase1Input{
+		Files: []specview.FileInfo{
+			{
+				Path: "src/payments/__tests__/refund.test.ts",
+				Tests: []specview.TestInfo{
+					{Index: 0, Name: "refunds a charge", SuitePath: "RefundService > refund"},
+					{Index: 1, Name: "rejects partial refunds"},
+				},
+			},
+			{
+				Path:  "internal/auth/login_test.go",
+				Tests: []specview.TestInfo{{Index: 2, Name: "TestLogin_InvalidPassword"}},
+			},
+		},
+		Language: "English",
+	}
+
+	provider := NewProvider()
+	output, usage, err := provider.ClassifyDomains(context.Background(), input)
+	if err != nil || usage != nil {
+		t.Fatalf("expected output without usage, got %v, %v", usage, err)
+	}
+	again, _, _ := provider.ClassifyDomains(context.Background(), input)
+	if !reflect.DeepEqual(output, again) {
+		t.Error("expected deterministic output")
+	}
+
+	if len(output.Domains) != 2 || output.Domains[0].Name != "Auth" || output.Domains[1].Name != "Payments" {
+		t.Fatalf("expected Auth and Payments ordered by path, got %+v", output.Domains)
+	}
+	var features []string
+	for _, f := range output.Domains[1].Features {
+		features = append(features, f.Name)
+	}
+	if want := []string{"Refund Service", "Refund"}; !reflect.DeepEqual(features, want) {
+		t.Errorf("features = %v, want %v", features, want)
+	}
+	if login := output.Domains[0].Features; len(login) != 1 || login[0].Name != "Login" {
+		t.Errorf("expected a Login feature from the file name, got %+v", login)
+	}
+}
+
+func TestProvider_ConvertTestNames(t *testing.T) {
+	input := specview.Phase2Input{
+		Tests: []specview.TestForConversion{
+			{Index: 0, Name: "TestLogin_FailsWithInvalidPassword"},
+			{Index: 1, Name: "should refund a charge"},
+			{Index: 2, Name: "TestParseURL"},
+			{Index: 3, Name: "TestLoad/returns_err_for_missing_cfg"},
+			{Index: 4, Name: "Testimonials render"},
+			{Index: 5, Name: "it_saves_the_msg"},
+		},
+	}
+
+	output, usage, err := NewProvider().ConvertTestNames(context.Background(), input)
+	if err != nil || usage != nil {
+		t.Fatalf("expected output without usage, got %v, %v", usage, err)
+	}
+	want := []string{
+		"Login fails with invalid password",
+		"Should refund a charge",
+		"Parse URL",
+		"Load, returns error for missing config",
+		"Testimonials render",
+		"Saves the message",
+	}
+	if len(output.Behaviors) != len(want) {
+		t.Fatalf("expected %d behaviors, got %d", len(want), len(output.Behaviors))
+	}
+	for i, b := range output.Behaviors {
+		if b.Description != want[i] || b.TestIndex != i {
+			t.Errorf("behavior %d = %+v, want %q", i, b, want[i])
+		}
+	}
+}
+
+func TestProvider_PlaceNewTests(t *testing.T) {
+	input := specview.PlacementInput{
+		ExistingStructure: &specview.Phase1Output{Domains: []specview.DomainGroup{
+			{Name: "Payments", Features: []specview.FeatureGroup{{Name: "Refund Service"}}},
+		}},
+		NewTests: []specview.TestInfo{
+			{Index: 0, Name: "refunds twice", SuitePath: "RefundService"},
+			{Index: 1, Name: "TestMisc"},
+		},
+	}
+
+	output, _, err := NewProvider().PlaceNewTests(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []specview.TestPlacement{{DomainName: "Payments", FeatureName: "Refund Service", TestIndex: 0}}
+	if !reflect.DeepEqual(output.Placements, want) {
+		t.Errorf("placements = %+v, want %+v", output.Placements, want)
+	}
+}
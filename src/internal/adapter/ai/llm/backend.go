@@ -0,0 +1,48 @@
+package llm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// Request is a single JSON-mode completion call.
+type Request struct {
+	Model        string
+	SystemPrompt string
+	UserPrompt   string
+}
+
+// Backend sends completions to one model vendor.
+//
+// The phase pipeline (chunking, prompts, parsing, retries) is vendor-neutral, so a
+// backend only has to translate one request. Implementations must:
+//   - return specview.ErrOutputTruncated when the model stopped at its token limit
+//   - return specview.ErrInvalidInput when content was blocked by safety filters
+//   - wrap transient failures (5xx, 429, network) in reliability.RetryableError
+type Backend interface {
+	Complete(ctx context.Context, req Request) (string, *specview.TokenUsage, error)
+	Name() string
+}
+
+// Pinger is an optional Backend capability for a cheap authenticated request,
+// used to warm up TLS and connection pools before the first completion.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// StripCodeFence removes a surrounding markdown code fence.
+// Vendors without a strict JSON mode sometimes wrap JSON output in ```json blocks.
+func StripCodeFence(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "```") {
+		return text
+	}
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	if idx := strings.IndexByte(trimmed, '\n'); idx >= 0 {
+		trimmed = trimmed[idx+1:]
+	}
+	trimmed = strings.TrimSuffix(strings.TrimSpace(trimmed), "```")
+	return strings.TrimSpace(trimmed)
+}
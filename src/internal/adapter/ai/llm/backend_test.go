@@ -0,0 +1,24 @@
+package llm
+
+import "testing"
+
+func TestStripCodeFence(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain JSON unchanged", `{"a":1}`, `{"a":1}`},
+		{"json fence", "```json\n{\"a\":1}\n```", `{"a":1}`},
+		{"bare fence", "```\n{\"a\":1}\n```", `{"a":1}`},
+		{"surrounding whitespace", "  ```json\n{\"a\":1}\n```  \n", `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripCodeFence(tt.input); got != tt.want {
+				t.Errorf("StripCodeFence() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
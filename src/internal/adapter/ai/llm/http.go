@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/specvital/worker/internal/adapter/ai/reliability"
+)
+
+// maxErrorBodyBytes bounds how much of an error response is kept for logs.
+const maxErrorBodyBytes = 2048
+
+// PostJSON sends body as JSON and decodes a successful response into out.
+// Retryable HTTP statuses and transport failures are wrapped in reliability.RetryableError.
+func PostJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	respBody, err := send(ctx, client, http.MethodPost, url, headers, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer respBody.Close()
+
+	return decode(respBody, out)
+}
+
+// GetJSON decodes a successful GET response into out.
+func GetJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, out any) error {
+	respBody, err := Get(ctx, client, url, headers)
+	if err != nil {
+		return err
+	}
+	defer respBody.Close()
+
+	return decode(respBody, out)
+}
+
+// Get returns the body of a successful GET response for streaming reads.
+// The caller must close it.
+func Get(ctx context.Context, client *http.Client, url string, headers map[string]string) (io.ReadCloser, error) {
+	return send(ctx, client, http.MethodGet, url, headers, nil)
+}
+
+func send(ctx context.Context, client *http.Client, method, url string, headers map[string]string, body io.Reader) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &reliability.RetryableError{Err: fmt.Errorf("send request: %w", err)}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		statusErr := fmt.Errorf("status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+		if reliability.IsRetryableStatusCode(resp.StatusCode) {
+			return nil, &reliability.RetryableError{Err: statusErr}
+		}
+		return nil, statusErr
+	}
+
+	return resp.Body, nil
+}
+
+func decode(body io.Reader, out any) error {
+	if err := json.NewDecoder(body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/specvital/worker/internal/adapter/ai/reliability"
+)
+
+func TestPostJSON(t *testing.T) {
+	t.Run("decodes success response and sends headers", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Key") != "secret" {
+				t.Errorf("expected header to be forwarded")
+			}
+			w.Write([]byte(`{"ok":true}`))
+		}))
+		defer srv.Close()
+
+		var out struct {
+			OK bool `json:"ok"`
+		}
+		err := PostJSON(context.Background(), srv.Client(), srv.URL, map[string]string{"X-Key": "secret"}, map[string]string{}, &out)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !out.OK {
+			t.Error("expected decoded response")
+		}
+	})
+
+	t.Run("wraps retryable status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer srv.Close()
+
+		err := PostJSON(context.Background(), srv.Client(), srv.URL, nil, map[string]string{}, &struct{}{})
+		var retryable *reliability.RetryableError
+		if !errors.As(err, &retryable) {
+			t.Errorf("expected RetryableError, got %v", err)
+		}
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("bad key"))
+		}))
+		defer srv.Close()
+
+		err := PostJSON(context.Background(), srv.Client(), srv.URL, nil, map[string]string{}, &struct{}{})
+		var retryable *reliability.RetryableError
+		if err == nil || errors.As(err, &retryable) {
+			t.Errorf("expected non-retryable error, got %v", err)
+		}
+	})
+}
+
+func TestGetJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("expected GET, got %s", r.Method)
+		}
+		if r.Header.Get("Content-Type") != "" {
+			t.Error("GET without body should not set Content-Type")
+		}
+		w.Write([]byte(`{"id":"x"}`))
+	}))
+	defer srv.Close()
+
+	var out struct {
+		ID string `json:"id"`
+	}
+	if err := GetJSON(context.Background(), srv.Client(), srv.URL, nil, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.ID != "x" {
+		t.Errorf("ID = %q, want x", out.ID)
+	}
+}
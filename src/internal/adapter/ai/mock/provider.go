@@ -90,6 +90,19 @@ func (p *Provider) GenerateSummary(ctx context.Context, input specview.Phase3Inp
 	}, nil, nil
 }
 
+// TranslateTexts returns the texts prefixed with the target language.
+func (p *Provider) TranslateTexts(ctx context.Context, input specview.TranslationInput) (*specview.TranslationOutput, *specview.TokenUsage, error) {
+	if err := p.simulateDelay(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	texts := make([]string, len(input.Texts))
+	for i, text := range input.Texts {
+		texts[i] = fmt.Sprintf("[Mock %s] %s", input.TargetLanguage, text)
+	}
+	return &specview.TranslationOutput{Texts: texts}, nil, nil
+}
+
 // simulateDelay waits for the configured delay duration, respecting context cancellation.
 func (p *Provider) simulateDelay(ctx context.Context) error {
 	if p.delay <= 0 {
@@ -0,0 +1,191 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/ai/llm"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+const (
+	DefaultBaseURL     = "https://api.openai.com/v1"
+	DefaultAPIVersion  = "2024-10-21" // Azure OpenAI GA API version
+	DefaultPhase1Model = "gpt-4.1-mini"
+	DefaultPhase2Model = "gpt-4.1-nano"
+
+	defaultSeed     = 42
+	maxOutputTokens = 32768
+	requestTimeout  = 10 * time.Minute
+	finishLength    = "length"
+	finishFilter    = "content_filter"
+)
+
+// Config holds configuration for an OpenAI-compatible backend.
+type Config struct {
+	APIKey     string
+	APIVersion string // Azure only (default: DefaultAPIVersion)
+	Azure      bool   // Azure routes by deployment name and authenticates with api-key
+	BaseURL    string // OpenAI: API root; Azure: resource endpoint (https://<name>.openai.azure.com)
+}
+
+// Validate validates the configuration.
+func (c *Config) Validate() error {
+	if c.APIKey == "" {
+		return errors.New("openai API key is required")
+	}
+	if c.Azure && c.BaseURL == "" {
+		return errors.New("azure openai endpoint is required")
+	}
+	return nil
+}
+
+var (
+	_ llm.Backend = (*Backend)(nil)
+	_ llm.Pinger  = (*Backend)(nil)
+)
+
+// Backend sends completions to the OpenAI Chat Completions API (or Azure OpenAI).
+type Backend struct {
+	client *http.Client
+	config Config
+}
+
+// NewBackend creates a new OpenAI-compatible backend.
+func NewBackend(config Config) (*Backend, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	if config.Azure && config.APIVersion == "" {
+		config.APIVersion = DefaultAPIVersion
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+
+	return &Backend{
+		client: &http.Client{Timeout: requestTimeout},
+		config: config,
+	}, nil
+}
+
+type chatMessage struct {
+	Content string `json:"content"`
+	Role    string `json:"role"`
+}
+
+type responseFormat struct {
+	Type string `json:"type"`
+}
+
+type chatRequest struct {
+	MaxCompletionTokens int            `json:"max_completion_tokens"`
+	Messages            []chatMessage  `json:"messages"`
+	Model               string         `json:"model,omitempty"`
+	ResponseFormat      responseFormat `json:"response_format"`
+	Seed                int            `json:"seed"`
+	Temperature         float64        `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		FinishReason string      `json:"finish_reason"`
+		Message      chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage *struct {
+		CompletionTokens int32 `json:"completion_tokens"`
+		PromptTokens     int32 `json:"prompt_tokens"`
+		TotalTokens      int32 `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+func (b *Backend) Name() string {
+	if b.config.Azure {
+		return "azure-openai"
+	}
+	return "openai"
+}
+
+func (b *Backend) Complete(ctx context.Context, req llm.Request) (string, *specview.TokenUsage, error) {
+	body := chatRequest{
+		MaxCompletionTokens: maxOutputTokens,
+		Messages: []chatMessage{
+			{Role: "system", Content: req.SystemPrompt},
+			{Role: "user", Content: req.UserPrompt},
+		},
+		ResponseFormat: responseFormat{Type: "json_object"},
+		Seed:           defaultSeed,
+		Temperature:    0,
+	}
+
+	endpoint, headers := b.route(req.Model)
+	if !b.config.Azure {
+		body.Model = req.Model
+	}
+
+	var resp chatResponse
+	if err := llm.PostJSON(ctx, b.client, endpoint, headers, body, &resp); err != nil {
+		return "", nil, fmt.Errorf("%s chat completion: %w", b.Name(), err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", nil, fmt.Errorf("%s returned no choices", b.Name())
+	}
+
+	choice := resp.Choices[0]
+	switch choice.FinishReason {
+	case finishLength:
+		return "", nil, fmt.Errorf("%w: reduce input size or split into chunks", specview.ErrOutputTruncated)
+	case finishFilter:
+		return "", nil, fmt.Errorf("%w: content blocked (%s)", specview.ErrInvalidInput, choice.FinishReason)
+	}
+
+	var usage *specview.TokenUsage
+	if resp.Usage != nil {
+		usage = &specview.TokenUsage{
+			CandidatesTokens: resp.Usage.CompletionTokens,
+			Model:            req.Model,
+			PromptTokens:     resp.Usage.PromptTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		}
+	}
+
+	return choice.Message.Content, usage, nil
+}
+
+// route returns the endpoint and auth headers. Azure addresses models by deployment,
+// so the model name becomes part of the URL instead of the body.
+func (b *Backend) route(model string) (string, map[string]string) {
+	if b.config.Azure {
+		endpoint := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+			b.config.BaseURL, url.PathEscape(model), url.QueryEscape(b.config.APIVersion))
+		return endpoint, b.authHeaders()
+	}
+	return b.config.BaseURL + "/chat/completions", b.authHeaders()
+}
+
+// Ping lists the available models, which authenticates without generating content.
+func (b *Backend) Ping(ctx context.Context) error {
+	endpoint := b.config.BaseURL + "/models"
+	if b.config.Azure {
+		endpoint = fmt.Sprintf("%s/openai/models?api-version=%s", b.config.BaseURL, url.QueryEscape(b.config.APIVersion))
+	}
+	body, err := llm.Get(ctx, b.client, endpoint, b.authHeaders())
+	if err != nil {
+		return fmt.Errorf("openai models: %w", err)
+	}
+	return body.Close()
+}
+
+func (b *Backend) authHeaders() map[string]string {
+	if b.config.Azure {
+		return map[string]string{"api-key": b.config.APIKey}
+	}
+	return map[string]string{"Authorization": "Bearer " + b.config.APIKey}
+}
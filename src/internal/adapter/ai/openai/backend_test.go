@@ -0,0 +1,161 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/specvital/worker/internal/adapter/ai/llm"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"empty API key", Config{}, true},
+		{"openai with key", Config{APIKey: "k"}, false},
+		{"azure without endpoint", Config{APIKey: "k", Azure: true}, true},
+		{"azure with endpoint", Config{APIKey: "k", Azure: true, BaseURL: "https://x.openai.azure.com"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBackend_Complete(t *testing.T) {
+	t.Run("openai request shape and usage", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/chat/completions" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			if r.Header.Get("Authorization") != "Bearer key" {
+				t.Errorf("expected bearer auth, got %q", r.Header.Get("Authorization"))
+			}
+			var req chatRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Model != "gpt-test" || req.ResponseFormat.Type != "json_object" || len(req.Messages) != 2 {
+				t.Errorf("unexpected request: %+v", req)
+			}
+			w.Write([]byte(`{"choices":[{"finish_reason":"stop","message":{"role":"assistant","content":"{\"a\":1}"}}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`))
+		}))
+		defer srv.Close()
+
+		b, _ := NewBackend(Config{APIKey: "key", BaseURL: srv.URL})
+		text, usage, err := b.Complete(context.Background(), llm.Request{Model: "gpt-test", SystemPrompt: "s", UserPrompt: "u"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text != `{"a":1}` {
+			t.Errorf("unexpected text %q", text)
+		}
+		if usage == nil || usage.TotalTokens != 5 || usage.Model != "gpt-test" {
+			t.Errorf("unexpected usage %+v", usage)
+		}
+	})
+
+	t.Run("azure routes by deployment", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/openai/deployments/my-deploy/chat/completions" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			if r.URL.Query().Get("api-version") != DefaultAPIVersion {
+				t.Errorf("unexpected api-version %q", r.URL.Query().Get("api-version"))
+			}
+			if r.Header.Get("api-key") != "key" {
+				t.Error("expected api-key header")
+			}
+			w.Write([]byte(`{"choices":[{"finish_reason":"stop","message":{"content":"{}"}}]}`))
+		}))
+		defer srv.Close()
+
+		b, _ := NewBackend(Config{APIKey: "key", Azure: true, BaseURL: srv.URL})
+		if b.Name() != "azure-openai" {
+			t.Errorf("unexpected name %s", b.Name())
+		}
+		if _, _, err := b.Complete(context.Background(), llm.Request{Model: "my-deploy"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("maps finish reasons", func(t *testing.T) {
+		tests := []struct {
+			reason string
+			want   error
+		}{
+			{"length", specview.ErrOutputTruncated},
+			{"content_filter", specview.ErrInvalidInput},
+		}
+		for _, tt := range tests {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"choices":[{"finish_reason":"` + tt.reason + `","message":{"content":"{"}}]}`))
+			}))
+			b, _ := NewBackend(Config{APIKey: "key", BaseURL: srv.URL})
+			_, _, err := b.Complete(context.Background(), llm.Request{Model: "m"})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("finish_reason %s: expected %v, got %v", tt.reason, tt.want, err)
+			}
+			srv.Close()
+		}
+	})
+}
+
+func TestBackend_Ping(t *testing.T) {
+	t.Run("openai lists models", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.URL.Path != "/models" {
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+			if r.Header.Get("Authorization") != "Bearer key" {
+				t.Errorf("expected bearer auth, got %q", r.Header.Get("Authorization"))
+			}
+			w.Write([]byte(`{"data":[]}`))
+		}))
+		defer srv.Close()
+
+		b, _ := NewBackend(Config{APIKey: "key", BaseURL: srv.URL})
+		if err := b.Ping(context.Background()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("azure uses api-key and api-version", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/openai/models" || r.URL.Query().Get("api-version") != DefaultAPIVersion {
+				t.Errorf("unexpected request %s", r.URL)
+			}
+			if r.Header.Get("api-key") != "key" {
+				t.Error("expected api-key header")
+			}
+			w.Write([]byte(`{"data":[]}`))
+		}))
+		defer srv.Close()
+
+		b, _ := NewBackend(Config{APIKey: "key", Azure: true, BaseURL: srv.URL})
+		if err := b.Ping(context.Background()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unauthorized fails", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer srv.Close()
+
+		b, _ := NewBackend(Config{APIKey: "bad", BaseURL: srv.URL})
+		if err := b.Ping(context.Background()); err == nil {
+			t.Error("expected error for unauthorized response")
+		}
+	})
+}
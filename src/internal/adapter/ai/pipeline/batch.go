@@ -0,0 +1,176 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/ai/prompt"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+const (
+	defaultBatchPollInterval = 30 * time.Second
+	defaultBatchMaxWait      = 30 * time.Minute
+
+	// batchCancelTimeout bounds the cancel request of an abandoned batch,
+	// which is sent even when the job's context is done.
+	batchCancelTimeout = 30 * time.Second
+)
+
+// batchClassifier sends large Phase 1 inputs through a batch API.
+type batchClassifier struct {
+	maxWait       time.Duration
+	minTests      int
+	pollInterval  time.Duration
+	provider      specview.BatchProvider
+	quotaFallback bool // used only once synchronous calls hit a quota or rate limit
+}
+
+// SetBatchClassifier classifies inputs of at least minTests tests as one
+// unchunked batch request instead of chunked synchronous calls. The batch
+// API has no per-minute quota, so such inputs need no chunking. A batch
+// that fails or is not done within maxWait (default 30m) falls back to the
+// synchronous path. A nil provider or minTests <= 0 disables batching.
+func (p *Provider) SetBatchClassifier(provider specview.BatchProvider, minTests int, maxWait time.Duration) {
+	if provider == nil || minTests <= 0 {
+		p.batch = nil
+		return
+	}
+	if maxWait <= 0 {
+		maxWait = defaultBatchMaxWait
+	}
+	p.batch = &batchClassifier{
+		maxWait:      maxWait,
+		minTests:     minTests,
+		pollInterval: defaultBatchPollInterval,
+		provider:     provider,
+	}
+}
+
+// SetBatchFallback classifies inputs of at least minTests tests through
+// provider when the synchronous calls fail with a quota or rate limit error,
+// typically the batch API of another vendor. A batch that fails or is not
+// done within maxWait (default 30m) is cancelled and the quota error
+// returned. A nil provider or minTests <= 0 disables the fallback.
+func (p *Provider) SetBatchFallback(provider specview.BatchProvider, minTests int, maxWait time.Duration) {
+	p.SetBatchClassifier(provider, minTests, maxWait)
+	if p.batch != nil {
+		p.batch.quotaFallback = true
+	}
+}
+
+// usesBatch reports whether input is classified through the batch API. The
+// batch runs the configured Phase 1 model, so jobs selecting another model
+// or downgraded by a budget stay synchronous.
+func (p *Provider) usesBatch(ctx context.Context, input specview.Phase1Input) bool {
+	if p.batch == nil || countTests(input.Files) < p.batch.minTests {
+		return false
+	}
+	if _, ok := specview.ModelOverride(ctx); ok {
+		return false
+	}
+	return p.phaseModels(ctx).Phase1 == p.phase1Model
+}
+
+// classifyDomainsBatch submits input as a single batch request and polls it
+// until it ends. The response is parsed, repaired and validated like a
+// synchronous one; a rejected response is quarantined and returned as an
+// error, since a batch cannot be re-asked within the wait.
+func (p *Provider) classifyDomainsBatch(ctx context.Context, input specview.Phase1Input, lang specview.Language) (*specview.Phase1Output, *specview.TokenUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.batch.maxWait)
+	defer cancel()
+
+	input.Language = lang
+	jobID, err := p.batch.provider.CreateClassificationJob(ctx, input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("submit phase 1 batch: %w", err)
+	}
+	slog.InfoContext(ctx, "phase 1 submitted as batch",
+		"batch_id", jobID,
+		"test_count", countTests(input.Files),
+	)
+
+	status, err := p.waitForBatch(ctx, jobID)
+	if err != nil {
+		p.cancelBatch(ctx, jobID)
+		return nil, nil, err
+	}
+	if status.State != specview.BatchJobStateSucceeded {
+		return nil, nil, fmt.Errorf("phase 1 batch %s ended %s", jobID, status.State)
+	}
+	if len(status.Results) != 1 {
+		return nil, nil, fmt.Errorf("phase 1 batch %s returned %d results, want 1", jobID, len(status.Results))
+	}
+	result := status.Results[0]
+	if result.Err != nil {
+		return nil, nil, fmt.Errorf("phase 1 batch %s: %w", jobID, result.Err)
+	}
+
+	model := p.phase1Model
+	systemPrompt := prompt.SystemPrompt(prompt.Phase1, specview.PromptVersion(ctx))
+	userPrompt := prompt.BuildPhase1UserPrompt(input, lang)
+	if len(input.Taxonomy) > 0 {
+		userPrompt = prompt.BuildPhase1UserPromptWithAnchors(input, lang, input.Taxonomy)
+	}
+	parsed, err := parsePhase1Response(ctx, prompt.Phase1SchemaVersion, result.Text)
+	if err != nil {
+		err = &specview.OutputValidationError{Phase: "phase1", Problems: []string{err.Error()}}
+		p.quarantineOutput(ctx, "phase1", prompt.Phase1SchemaVersion, model, systemPrompt, userPrompt, result.Text, err)
+		return nil, nil, err
+	}
+	output, report, err := repairPhase1Output(parsed, input)
+	report.record(ctx, "phase1")
+	if err != nil {
+		p.quarantineOutput(ctx, "phase1", prompt.Phase1SchemaVersion, model, systemPrompt, userPrompt, result.Text, err)
+		return nil, nil, err
+	}
+	if err := validatePhase1Output(ctx, output, input); err != nil {
+		return nil, nil, fmt.Errorf("phase 1 output validation failed: %w", err)
+	}
+
+	return output, result.Usage, nil
+}
+
+// waitForBatch polls the batch until it reaches a terminal state.
+func (p *Provider) waitForBatch(ctx context.Context, jobID string) (*specview.BatchJobStatus, error) {
+	ticker := time.NewTicker(p.batch.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := p.batch.provider.GetJobStatus(ctx, jobID)
+		if err != nil {
+			return nil, fmt.Errorf("poll phase 1 batch %s: %w", jobID, err)
+		}
+		if status.State.IsTerminal() {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("phase 1 batch %s not done within %s", jobID, p.batch.maxWait)
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// cancelBatch stops a batch whose result will not be read, so it is not
+// left running and billed.
+func (p *Provider) cancelBatch(ctx context.Context, jobID string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), batchCancelTimeout)
+	defer cancel()
+
+	if err := p.batch.provider.CancelJob(ctx, jobID); err != nil {
+		slog.WarnContext(ctx, "failed to cancel phase 1 batch (non-critical)",
+			"batch_id", jobID,
+			"error", err,
+		)
+		return
+	}
+	slog.InfoContext(ctx, "phase 1 batch cancelled", "batch_id", jobID)
+}
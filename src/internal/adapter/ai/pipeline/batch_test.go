@@ -0,0 +1,219 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/ai/reliability"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type fakeBatchProvider struct {
+	cancelled []string
+	createErr error
+	inputs    []specview.Phase1Input
+	polls     int
+	statuses  []specview.BatchJobStatus
+}
+
+func (f *fakeBatchProvider) CreateClassificationJob(_ context.Context, input specview.Phase1Input) (string, error) {
+	f.inputs = append(f.inputs, input)
+	return "batch-1", f.createErr
+}
+
+func (f *fakeBatchProvider) CreateJob(context.Context, []specview.BatchRequest) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (f *fakeBatchProvider) GetJobStatus(context.Context, string) (*specview.BatchJobStatus, error) {
+	status := f.statuses[min(f.polls, len(f.statuses)-1)]
+	f.polls++
+	return &status, nil
+}
+
+func (f *fakeBatchProvider) CancelJob(_ context.Context, jobID string) error {
+	f.cancelled = append(f.cancelled, jobID)
+	return nil
+}
+
+func TestClassifyDomains_Batch(t *testing.T) {
+	const batchOutput = `{"domains": [{"name": "Auth", "description": "Authentication", "confidence": 0.9,
+		"features": [{"name": "Login", "description": "Login", "confidence": 0.9, "test_indices": [0, 1]}]}]}`
+	const syncOutput = `{"domains": [{"name": "Sync", "description": "Synchronous", "confidence": 0.9,
+		"features": [{"name": "Calls", "description": "Calls", "confidence": 0.9, "test_indices": [0, 1]}]}]}`
+
+	input := specview.Phase1Input{Files: []specview.FileInfo{{
+		Path:  "auth_test.go",
+		Tests: []specview.TestInfo{{Index: 0, Name: "TestLogin"}, {Index: 1, Name: "TestLoginFails"}},
+	}}}
+	succeeded := specview.BatchJobStatus{
+		State:   specview.BatchJobStateSucceeded,
+		Results: []specview.BatchResult{{CustomID: "phase1", Text: batchOutput, Usage: &specview.TokenUsage{TotalTokens: 7}}},
+	}
+	newProvider := func(batch *fakeBatchProvider, minTests int) *Provider {
+		p := NewProvider(&fakeBackend{text: syncOutput}, "m1", "m2")
+		p.SetBatchClassifier(batch, minTests, time.Minute)
+		if p.batch != nil {
+			p.batch.pollInterval = time.Millisecond
+		}
+		return p
+	}
+
+	t.Run("large inputs are classified through the batch", func(t *testing.T) {
+		batch := &fakeBatchProvider{statuses: []specview.BatchJobStatus{{State: specview.BatchJobStateRunning}, succeeded}}
+		output, usage, err := newProvider(batch, 2).classifyDomains(context.Background(), input, "English")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if output.Domains[0].Name != "Auth" || usage.TotalTokens != 7 {
+			t.Errorf("expected the batch output, got %+v, %+v", output.Domains, usage)
+		}
+		if batch.polls != 2 || len(batch.inputs) != 1 || batch.inputs[0].Language != "English" {
+			t.Errorf("unexpected batch calls: %d polls, inputs %+v", batch.polls, batch.inputs)
+		}
+	})
+
+	t.Run("inputs below the threshold stay synchronous", func(t *testing.T) {
+		batch := &fakeBatchProvider{statuses: []specview.BatchJobStatus{succeeded}}
+		output, _, err := newProvider(batch, 3).classifyDomains(context.Background(), input, "English")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if output.Domains[0].Name != "Sync" || len(batch.inputs) != 0 {
+			t.Errorf("expected a synchronous classification, got %+v after %d batches", output.Domains, len(batch.inputs))
+		}
+	})
+
+	t.Run("jobs selecting a phase 1 model stay synchronous", func(t *testing.T) {
+		batch := &fakeBatchProvider{statuses: []specview.BatchJobStatus{succeeded}}
+		ctx := specview.WithPhaseModels(context.Background(), specview.PhaseModels{Phase1: "m3"})
+		if _, _, err := newProvider(batch, 2).classifyDomains(ctx, input, "English"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(batch.inputs) != 0 {
+			t.Errorf("expected no batch, got %d", len(batch.inputs))
+		}
+	})
+
+	t.Run("failed batches fall back to synchronous calls", func(t *testing.T) {
+		for name, batch := range map[string]*fakeBatchProvider{
+			"submission fails": {createErr: errors.New("overloaded"), statuses: []specview.BatchJobStatus{succeeded}},
+			"batch expires":    {statuses: []specview.BatchJobStatus{{State: specview.BatchJobStateExpired}}},
+			"request errors": {statuses: []specview.BatchJobStatus{{
+				State:   specview.BatchJobStateSucceeded,
+				Results: []specview.BatchResult{{CustomID: "phase1", Err: errors.New("overloaded")}},
+			}}},
+			"response unparseable": {statuses: []specview.BatchJobStatus{{
+				State:   specview.BatchJobStateSucceeded,
+				Results: []specview.BatchResult{{CustomID: "phase1", Text: "not json"}},
+			}}},
+		} {
+			output, _, err := newProvider(batch, 2).classifyDomains(context.Background(), input, "English")
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, err)
+			}
+			if output.Domains[0].Name != "Sync" {
+				t.Errorf("%s: expected the synchronous output, got %+v", name, output.Domains)
+			}
+		}
+	})
+
+	t.Run("batches not done within the wait fall back", func(t *testing.T) {
+		batch := &fakeBatchProvider{statuses: []specview.BatchJobStatus{{State: specview.BatchJobStateRunning}}}
+		p := newProvider(batch, 2)
+		p.batch.maxWait = 20 * time.Millisecond
+		output, _, err := p.classifyDomains(context.Background(), input, "English")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if output.Domains[0].Name != "Sync" {
+			t.Errorf("expected the synchronous output, got %+v", output.Domains)
+		}
+		if len(batch.cancelled) != 1 || batch.cancelled[0] != "batch-1" {
+			t.Errorf("expected the batch cancelled, got %v", batch.cancelled)
+		}
+	})
+
+	t.Run("disabled without a provider or threshold", func(t *testing.T) {
+		p := NewProvider(&fakeBackend{}, "m1", "m2")
+		if p.SetBatchClassifier(nil, 2, 0); p.batch != nil {
+			t.Error("expected batching disabled without a provider")
+		}
+		if p := newProvider(&fakeBatchProvider{}, 0); p.batch != nil {
+			t.Error("expected batching disabled without a threshold")
+		}
+	})
+}
+
+func TestClassifyDomains_BatchFallback(t *testing.T) {
+	const batchOutput = `{"domains": [{"name": "Auth", "description": "Authentication", "confidence": 0.9,
+		"features": [{"name": "Login", "description": "Login", "confidence": 0.9, "test_indices": [0, 1]}]}]}`
+	const syncOutput = `{"domains": [{"name": "Sync", "description": "Synchronous", "confidence": 0.9,
+		"features": [{"name": "Calls", "description": "Calls", "confidence": 0.9, "test_indices": [0, 1]}]}]}`
+
+	input := specview.Phase1Input{Files: []specview.FileInfo{{
+		Path:  "auth_test.go",
+		Tests: []specview.TestInfo{{Index: 0, Name: "TestLogin"}, {Index: 1, Name: "TestLoginFails"}},
+	}}}
+	succeeded := specview.BatchJobStatus{
+		State:   specview.BatchJobStateSucceeded,
+		Results: []specview.BatchResult{{CustomID: "phase1", Text: batchOutput, Usage: &specview.TokenUsage{TotalTokens: 7}}},
+	}
+	quotaErr := fmt.Errorf("%w: RESOURCE_EXHAUSTED", specview.ErrRateLimited)
+	newProvider := func(backend *fakeBackend, batch *fakeBatchProvider) *Provider {
+		p := NewProvider(backend, "m1", "m2")
+		p.phase1Retry = reliability.NewRetryer(reliability.RetryConfig{MaxAttempts: 1})
+		p.SetBatchFallback(batch, 2, time.Minute)
+		p.batch.pollInterval = time.Millisecond
+		return p
+	}
+
+	t.Run("synchronous calls come first", func(t *testing.T) {
+		batch := &fakeBatchProvider{statuses: []specview.BatchJobStatus{succeeded}}
+		output, _, err := newProvider(&fakeBackend{text: syncOutput}, batch).classifyDomains(context.Background(), input, "English")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if output.Domains[0].Name != "Sync" || len(batch.inputs) != 0 {
+			t.Errorf("expected a synchronous classification, got %+v after %d batches", output.Domains, len(batch.inputs))
+		}
+	})
+
+	t.Run("rate limited inputs are classified through the batch", func(t *testing.T) {
+		batch := &fakeBatchProvider{statuses: []specview.BatchJobStatus{succeeded}}
+		output, usage, err := newProvider(&fakeBackend{err: quotaErr}, batch).classifyDomains(context.Background(), input, "English")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if output.Domains[0].Name != "Auth" || usage.TotalTokens != 7 {
+			t.Errorf("expected the batch output, got %+v, %+v", output.Domains, usage)
+		}
+	})
+
+	t.Run("other errors do not use the batch", func(t *testing.T) {
+		batch := &fakeBatchProvider{statuses: []specview.BatchJobStatus{succeeded}}
+		_, _, err := newProvider(&fakeBackend{err: errors.New("bad request")}, batch).classifyDomains(context.Background(), input, "English")
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if len(batch.inputs) != 0 {
+			t.Errorf("expected no batch, got %d", len(batch.inputs))
+		}
+	})
+
+	t.Run("batches not done within the wait are cancelled", func(t *testing.T) {
+		batch := &fakeBatchProvider{statuses: []specview.BatchJobStatus{{State: specview.BatchJobStateRunning}}}
+		p := newProvider(&fakeBackend{err: quotaErr}, batch)
+		p.batch.maxWait = 20 * time.Millisecond
+		_, _, err := p.classifyDomains(context.Background(), input, "English")
+		if !errors.Is(err, specview.ErrRateLimited) {
+			t.Fatalf("expected the rate limit error, got %v", err)
+		}
+		if len(batch.cancelled) != 1 || batch.cancelled[0] != "batch-1" {
+			t.Errorf("expected the batch cancelled, got %v", batch.cancelled)
+		}
+	})
+}
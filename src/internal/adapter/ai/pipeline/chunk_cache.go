@@ -1,4 +1,4 @@
-package gemini
+package pipeline
 
 import (
 	"log/slog"
@@ -1,4 +1,4 @@
-package gemini
+package pipeline
 
 import (
 	"github.com/specvital/worker/internal/domain/specview"
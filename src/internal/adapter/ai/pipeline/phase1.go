@@ -1,8 +1,8 @@
-package gemini
+package pipeline
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -17,7 +17,14 @@ const (
 	interChunkDelay = 5 * time.Second
 )
 
-// phase1Response represents the expected JSON response from Phase 1.
+// phase1Decoders maps each Phase 1 response schema version to its decoder.
+// Responses are parsed with the decoder of the prompt version that asked for them.
+var phase1Decoders = map[string]func(ctx context.Context, text string) (*specview.Phase1Output, error){
+	"phase1.v1": decodePhase1V1,
+}
+
+// phase1Response represents the expected JSON response from Phase 1
+// under schema phase1.v1.
 type phase1Response struct {
 	Domains []phase1Domain `json:"domains"`
 }
@@ -37,7 +44,8 @@ type phase1Feature struct {
 }
 
 // classifyDomains performs Phase 1: domain and feature classification.
-// Uses chunked processing for large inputs to handle token limits.
+// Uses the batch API or chunked processing for large inputs to handle token
+// limits.
 func (p *Provider) classifyDomains(ctx context.Context, input specview.Phase1Input, lang specview.Language) (*specview.Phase1Output, *specview.TokenUsage, error) {
 	if len(input.Files) == 0 {
 		return nil, nil, fmt.Errorf("%w: no files to classify", specview.ErrInvalidInput)
@@ -48,19 +56,52 @@ func (p *Provider) classifyDomains(ctx context.Context, input specview.Phase1Inp
 		"test_count", countTests(input.Files),
 	)
 
+	useBatch := p.usesBatch(ctx, input)
+	if useBatch && !p.batch.quotaFallback {
+		output, usage, err := p.classifyDomainsBatch(ctx, input, lang)
+		if err == nil || ctx.Err() != nil {
+			return output, usage, err
+		}
+		slog.WarnContext(ctx, "phase 1 batch failed, classifying synchronously",
+			"error", err,
+		)
+	}
+
+	output, usage, err := p.classifyDomainsSync(ctx, input, lang)
+	if err == nil || !useBatch || !p.batch.quotaFallback || !errors.Is(err, specview.ErrRateLimited) || ctx.Err() != nil {
+		return output, usage, err
+	}
+
+	slog.WarnContext(ctx, "phase 1 rate limited, classifying through the batch fallback",
+		"error", err,
+	)
+	output, usage, batchErr := p.classifyDomainsBatch(ctx, input, lang)
+	if batchErr != nil {
+		slog.WarnContext(ctx, "phase 1 batch fallback failed",
+			"error", batchErr,
+		)
+		return nil, nil, err
+	}
+	return output, usage, nil
+}
+
+// classifyDomainsSync classifies input with synchronous calls, chunked when
+// it exceeds the chunk limits.
+func (p *Provider) classifyDomainsSync(ctx context.Context, input specview.Phase1Input, lang specview.Language) (*specview.Phase1Output, *specview.TokenUsage, error) {
 	config := DefaultChunkConfig()
 	if NeedsChunking(input.Files, config) {
 		return p.classifyDomainsChunked(ctx, input, lang, config)
 	}
 
-	return p.classifyDomainsSingle(ctx, input, lang, nil)
+	return p.classifyDomainsSingle(ctx, input, lang, input.Taxonomy)
 }
 
 // classifyDomainsSingle performs Phase 1 classification for a single chunk.
-// anchorDomains is optional context from previous chunks.
-// Retries on both API errors and JSON parsing errors.
+// anchorDomains is optional context from the repository taxonomy and previous chunks.
+// Retries on both API errors and rejected responses; a rejected response is
+// re-asked with a correction prompt naming its problem.
 func (p *Provider) classifyDomainsSingle(ctx context.Context, input specview.Phase1Input, lang specview.Language, anchorDomains []specview.DomainGroup) (*specview.Phase1Output, *specview.TokenUsage, error) {
-	systemPrompt := prompt.Phase1SystemPrompt
+	systemPrompt := prompt.SystemPrompt(prompt.Phase1, specview.PromptVersion(ctx))
 	var userPrompt string
 	if len(anchorDomains) > 0 {
 		userPrompt = prompt.BuildPhase1UserPromptWithAnchors(input, lang, anchorDomains)
@@ -68,29 +109,45 @@ func (p *Provider) classifyDomainsSingle(ctx context.Context, input specview.Pha
 		userPrompt = prompt.BuildPhase1UserPrompt(input, lang)
 	}
 
+	model := p.phaseModels(ctx).Phase1
 	var output *specview.Phase1Output
 	var usage *specview.TokenUsage
+	requestPrompt := userPrompt
 
 	err := p.phase1Retry.Do(ctx, func() error {
 		// API call
-		result, innerUsage, innerErr := p.generateContent(ctx, p.phase1Model, systemPrompt, userPrompt, p.phase1CB)
+		result, innerUsage, innerErr := p.generateContent(ctx, model, systemPrompt, requestPrompt, p.phase1CB)
 		if innerErr != nil {
 			return innerErr
 		}
 		usage = innerUsage
 
-		// Parse response - parsing errors are also retryable
-		var parseErr error
-		output, parseErr = parsePhase1Response(result)
+		// Parse and repair the response - rejected responses are also retryable
+		parsed, parseErr := parsePhase1Response(ctx, prompt.Phase1SchemaVersion, result)
 		if parseErr != nil {
+			parseErr = &specview.OutputValidationError{Phase: "phase1", Problems: []string{parseErr.Error()}}
 			slog.WarnContext(ctx, "failed to parse phase 1 response, will retry",
 				"error", parseErr,
 				"response", truncateForLog(result, 500),
 			)
+			p.quarantineOutput(ctx, "phase1", prompt.Phase1SchemaVersion, model, systemPrompt, requestPrompt, result, parseErr)
+			requestPrompt = prompt.AppendCorrection(userPrompt, parseErr.Error())
 			// Wrap as RetryableError so retry logic will attempt again
 			return &reliability.RetryableError{Err: parseErr}
 		}
 
+		repaired, report, repairErr := repairPhase1Output(parsed, input)
+		report.record(ctx, "phase1")
+		if repairErr != nil {
+			slog.WarnContext(ctx, "phase 1 response rejected, will retry",
+				"error", repairErr,
+			)
+			p.quarantineOutput(ctx, "phase1", prompt.Phase1SchemaVersion, model, systemPrompt, requestPrompt, result, repairErr)
+			requestPrompt = prompt.AppendCorrection(userPrompt, repairErr.Error())
+			return &reliability.RetryableError{Err: repairErr}
+		}
+		output = repaired
+
 		return nil
 	})
 	if err != nil {
@@ -113,11 +170,17 @@ func (p *Provider) classifyDomainsSingle(ctx context.Context, input specview.Pha
 func (p *Provider) classifyDomainsChunked(ctx context.Context, input specview.Phase1Input, lang specview.Language, config ChunkConfig) (*specview.Phase1Output, *specview.TokenUsage, error) {
 	chunks := SplitIntoChunks(input.Files, config)
 
+	// Chunks classified by another model must not be resumed
+	model := p.phaseModels(ctx).Phase1
+	if override, ok := specview.ModelOverride(ctx); ok {
+		model = override
+	}
+
 	// Generate cache key from analysisID (more reliable than content hash)
 	cacheKey := ChunkCacheKey{
 		ContentHash: input.AnalysisID, // Using analysisID for reliable cache key
 		Language:    lang,
-		ModelID:     p.phase1Model,
+		ModelID:     model,
 	}
 
 	// Check for cached progress from previous attempt
@@ -126,7 +189,7 @@ func (p *Provider) classifyDomainsChunked(ctx context.Context, input specview.Ph
 
 	var allOutputs []*specview.Phase1Output
 	var anchorDomains []specview.DomainGroup
-	totalUsage := &specview.TokenUsage{Model: p.phase1Model}
+	totalUsage := &specview.TokenUsage{Model: model}
 	startChunk := 0
 
 	// Resume from cached progress if available and valid
@@ -142,6 +205,8 @@ func (p *Provider) classifyDomainsChunked(ctx context.Context, input specview.Ph
 			"remaining_chunks", len(chunks)-startChunk,
 		)
 	} else {
+		// Seed anchors with the repository taxonomy so every chunk reuses it.
+		anchorDomains = input.Taxonomy
 		slog.InfoContext(ctx, "processing phase 1 in chunks",
 			"total_chunks", len(chunks),
 			"total_tests", countTests(input.Files),
@@ -160,8 +225,9 @@ func (p *Provider) classifyDomainsChunked(ctx context.Context, input specview.Ph
 		// Reindex tests within chunk to start from 0
 		reindexedFiles, indexMap := ReindexTests(chunk.Files)
 		chunkInput := specview.Phase1Input{
-			Files:    reindexedFiles,
-			Language: lang,
+			Constrained: input.Constrained,
+			Files:       reindexedFiles,
+			Language:    lang,
 		}
 
 		// Process chunk
@@ -229,11 +295,20 @@ func (p *Provider) classifyDomainsChunked(ctx context.Context, input specview.Ph
 	return mergedOutput, totalUsage, nil
 }
 
-// parsePhase1Response parses the JSON response into Phase1Output.
-func parsePhase1Response(jsonStr string) (*specview.Phase1Output, error) {
-	var resp phase1Response
-	if err := json.Unmarshal([]byte(jsonStr), &resp); err != nil {
-		return nil, fmt.Errorf("json unmarshal: %w", err)
+// parsePhase1Response parses the JSON response into Phase1Output using the
+// decoder of the given schema version.
+func parsePhase1Response(ctx context.Context, schemaVersion, jsonStr string) (*specview.Phase1Output, error) {
+	decode, ok := phase1Decoders[schemaVersion]
+	if !ok {
+		return nil, fmt.Errorf("unknown phase 1 schema version %q", schemaVersion)
+	}
+	return decode(ctx, jsonStr)
+}
+
+func decodePhase1V1(ctx context.Context, jsonStr string) (*specview.Phase1Output, error) {
+	resp, err := decodeResponse[phase1Response](ctx, "phase1", "phase1.v1", jsonStr)
+	if err != nil {
+		return nil, err
 	}
 
 	output := &specview.Phase1Output{
@@ -1,9 +1,10 @@
-package gemini
+package pipeline
 
 import (
 	"context"
 	"testing"
 
+	"github.com/specvital/worker/internal/adapter/ai/prompt"
 	"github.com/specvital/worker/internal/domain/specview"
 )
 
@@ -32,7 +33,7 @@ func TestParsePhase1Response_ValidJSON(t *testing.T) {
 		]
 	}`
 
-	output, err := parsePhase1Response(jsonStr)
+	output, err := parsePhase1Response(context.Background(), prompt.Phase1SchemaVersion, jsonStr)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -62,7 +63,7 @@ func TestParsePhase1Response_ValidJSON(t *testing.T) {
 }
 
 func TestParsePhase1Response_InvalidJSON(t *testing.T) {
-	_, err := parsePhase1Response("not json")
+	_, err := parsePhase1Response(context.Background(), prompt.Phase1SchemaVersion, "not json")
 	if err == nil {
 		t.Error("expected error for invalid JSON")
 	}
@@ -71,7 +72,7 @@ func TestParsePhase1Response_InvalidJSON(t *testing.T) {
 func TestParsePhase1Response_EmptyDomains(t *testing.T) {
 	jsonStr := `{"domains": []}`
 
-	output, err := parsePhase1Response(jsonStr)
+	output, err := parsePhase1Response(context.Background(), prompt.Phase1SchemaVersion, jsonStr)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -1,16 +1,17 @@
-package gemini
+package pipeline
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 
 	"github.com/specvital/worker/internal/adapter/ai/prompt"
+	"github.com/specvital/worker/internal/adapter/ai/reliability"
 	"github.com/specvital/worker/internal/domain/specview"
 )
 
-// phase2Response represents the expected JSON response from Phase 2.
+// phase2Response represents the expected JSON response from Phase 2
+// under schema phase2.v1.
 type phase2Response struct {
 	Conversions []phase2Conversion `json:"conversions"`
 }
@@ -28,32 +29,53 @@ func (p *Provider) convertTestNames(ctx context.Context, input specview.Phase2In
 		return nil, nil, fmt.Errorf("%w: no tests to convert", specview.ErrInvalidInput)
 	}
 
-	systemPrompt := prompt.Phase2SystemPrompt
+	systemPrompt := prompt.SystemPrompt(prompt.Phase2, specview.PromptVersion(ctx))
 	userPrompt, indexMapping := prompt.BuildPhase2UserPrompt(input, lang)
+	model := p.phaseModels(ctx).Phase2
 
-	var result string
+	var output *specview.Phase2Output
 	var usage *specview.TokenUsage
+	requestPrompt := userPrompt
 
-	// Retry logic
+	// Retry logic - rejected responses are re-asked with a correction prompt
 	err := p.phase2Retry.Do(ctx, func() error {
-		var innerErr error
-		result, usage, innerErr = p.generateContent(ctx, p.phase2Model, systemPrompt, userPrompt, p.phase2CB)
-		return innerErr
+		result, innerUsage, innerErr := p.generateContent(ctx, model, systemPrompt, requestPrompt, p.phase2CB)
+		if innerErr != nil {
+			return innerErr
+		}
+		usage = innerUsage
+
+		// Parse JSON response and map 0-based indices back to original
+		parsed, parseErr := parsePhase2Response(ctx, result, indexMapping)
+		if parseErr != nil {
+			parseErr = &specview.OutputValidationError{Phase: "phase2", Problems: []string{parseErr.Error()}}
+			slog.WarnContext(ctx, "failed to parse phase 2 response, will retry",
+				"error", parseErr,
+				"response", truncateForLog(result, 500),
+			)
+			p.quarantineOutput(ctx, "phase2", prompt.Phase2SchemaVersion, model, systemPrompt, requestPrompt, result, parseErr)
+			requestPrompt = prompt.AppendCorrection(userPrompt, parseErr.Error())
+			return &reliability.RetryableError{Err: parseErr}
+		}
+
+		repaired, report, repairErr := repairPhase2Output(parsed, input)
+		report.record(ctx, "phase2")
+		if repairErr != nil {
+			slog.WarnContext(ctx, "phase 2 response rejected, will retry",
+				"error", repairErr,
+			)
+			p.quarantineOutput(ctx, "phase2", prompt.Phase2SchemaVersion, model, systemPrompt, requestPrompt, result, repairErr)
+			requestPrompt = prompt.AppendCorrection(userPrompt, repairErr.Error())
+			return &reliability.RetryableError{Err: repairErr}
+		}
+		output = repaired
+
+		return nil
 	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("phase 2 conversion failed: %w", err)
 	}
 
-	// Parse JSON response and map 0-based indices back to original
-	output, err := parsePhase2Response(result, indexMapping)
-	if err != nil {
-		slog.WarnContext(ctx, "failed to parse phase 2 response",
-			"error", err,
-			"response", truncateForLog(result, 500),
-		)
-		return nil, nil, fmt.Errorf("failed to parse phase 2 response: %w", err)
-	}
-
 	// Validate output
 	if err := validatePhase2Output(ctx, output, input); err != nil {
 		slog.WarnContext(ctx, "phase 2 output validation failed",
@@ -67,10 +89,10 @@ func (p *Provider) convertTestNames(ctx context.Context, input specview.Phase2In
 
 // parsePhase2Response parses the JSON response into Phase2Output.
 // indexMapping converts 0-based AI response indices to original test indices.
-func parsePhase2Response(jsonStr string, indexMapping []int) (*specview.Phase2Output, error) {
-	var resp phase2Response
-	if err := json.Unmarshal([]byte(jsonStr), &resp); err != nil {
-		return nil, fmt.Errorf("json unmarshal: %w", err)
+func parsePhase2Response(ctx context.Context, jsonStr string, indexMapping []int) (*specview.Phase2Output, error) {
+	resp, err := decodeResponse[phase2Response](ctx, "phase2", prompt.Phase2SchemaVersion, jsonStr)
+	if err != nil {
+		return nil, err
 	}
 
 	output := &specview.Phase2Output{
@@ -78,8 +100,10 @@ func parsePhase2Response(jsonStr string, indexMapping []int) (*specview.Phase2Ou
 	}
 
 	for _, c := range resp.Conversions {
-		// Map 0-based index from AI response to original test index
-		originalIndex := c.Index
+		// Map 0-based index from AI response to original test index.
+		// Out-of-range indices map to -1, which no test has, so validation
+		// drops them instead of attributing them to an unrelated test.
+		originalIndex := -1
 		if c.Index >= 0 && c.Index < len(indexMapping) {
 			originalIndex = indexMapping[c.Index]
 		}
@@ -1,4 +1,4 @@
-package gemini
+package pipeline
 
 import (
 	"context"
@@ -26,7 +26,7 @@ func TestParsePhase2Response_ValidJSON(t *testing.T) {
 	// Index mapping: 0→5, 1→7 (simulates original test indices)
 	indexMapping := []int{5, 7}
 
-	output, err := parsePhase2Response(jsonStr, indexMapping)
+	output, err := parsePhase2Response(context.Background(), jsonStr, indexMapping)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -54,7 +54,7 @@ func TestParsePhase2Response_ValidJSON(t *testing.T) {
 }
 
 func TestParsePhase2Response_InvalidJSON(t *testing.T) {
-	_, err := parsePhase2Response("not json", nil)
+	_, err := parsePhase2Response(context.Background(), "not json", nil)
 	if err == nil {
 		t.Error("expected error for invalid JSON")
 	}
@@ -63,7 +63,7 @@ func TestParsePhase2Response_InvalidJSON(t *testing.T) {
 func TestParsePhase2Response_EmptyConversions(t *testing.T) {
 	jsonStr := `{"conversions": []}`
 
-	output, err := parsePhase2Response(jsonStr, nil)
+	output, err := parsePhase2Response(context.Background(), jsonStr, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -1,8 +1,7 @@
-package gemini
+package pipeline
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 
@@ -11,7 +10,8 @@ import (
 	"github.com/specvital/worker/internal/domain/specview"
 )
 
-// phase3Response represents the expected JSON response from Phase 3.
+// phase3Response represents the expected JSON response from Phase 3
+// under schema phase3.v1.
 type phase3Response struct {
 	Summary string `json:"summary"`
 }
@@ -23,25 +23,27 @@ func (p *Provider) generateSummary(ctx context.Context, input specview.Phase3Inp
 		return nil, nil, fmt.Errorf("%w: no domains to summarize", specview.ErrInvalidInput)
 	}
 
-	systemPrompt := prompt.Phase3SystemPrompt
+	systemPrompt := prompt.SystemPrompt(prompt.Phase3, specview.PromptVersion(ctx))
 	userPrompt := prompt.BuildPhase3UserPrompt(input)
 
 	var output *specview.Phase3Output
 	var usage *specview.TokenUsage
 
+	model := p.phaseModels(ctx).Phase3
 	err := p.phase1Retry.Do(ctx, func() error {
-		result, innerUsage, innerErr := p.generateContent(ctx, p.phase1Model, systemPrompt, userPrompt, p.phase1CB)
+		result, innerUsage, innerErr := p.generateContent(ctx, model, systemPrompt, userPrompt, p.phase1CB)
 		if innerErr != nil {
 			return innerErr
 		}
 		usage = innerUsage
 
-		parsed, parseErr := parsePhase3Response(result)
+		parsed, parseErr := parsePhase3Response(ctx, result)
 		if parseErr != nil {
 			slog.WarnContext(ctx, "failed to parse phase 3 response, will retry",
 				"error", parseErr,
 				"response", truncateForLog(result, 500),
 			)
+			p.quarantineOutput(ctx, "phase3", prompt.Phase3SchemaVersion, model, systemPrompt, userPrompt, result, parseErr)
 			return &reliability.RetryableError{Err: parseErr}
 		}
 
@@ -56,9 +58,9 @@ func (p *Provider) generateSummary(ctx context.Context, input specview.Phase3Inp
 }
 
 // parsePhase3Response parses the Phase 3 JSON response.
-func parsePhase3Response(text string) (*specview.Phase3Output, error) {
-	var resp phase3Response
-	if err := json.Unmarshal([]byte(text), &resp); err != nil {
+func parsePhase3Response(ctx context.Context, text string) (*specview.Phase3Output, error) {
+	resp, err := decodeResponse[phase3Response](ctx, "phase3", prompt.Phase3SchemaVersion, text)
+	if err != nil {
 		return nil, fmt.Errorf("unmarshal phase 3 response: %w", err)
 	}
 
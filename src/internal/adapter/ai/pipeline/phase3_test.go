@@ -1,6 +1,7 @@
-package gemini
+package pipeline
 
 import (
+	"context"
 	"testing"
 )
 
@@ -8,7 +9,7 @@ func TestParsePhase3Response(t *testing.T) {
 	t.Run("should parse valid JSON response", func(t *testing.T) {
 		text := `{"summary": "This project covers authentication and payment domains."}`
 
-		output, err := parsePhase3Response(text)
+		output, err := parsePhase3Response(context.Background(), text)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -20,7 +21,7 @@ func TestParsePhase3Response(t *testing.T) {
 	t.Run("should return error for empty summary", func(t *testing.T) {
 		text := `{"summary": ""}`
 
-		_, err := parsePhase3Response(text)
+		_, err := parsePhase3Response(context.Background(), text)
 		if err == nil {
 			t.Fatal("expected error for empty summary")
 		}
@@ -29,7 +30,7 @@ func TestParsePhase3Response(t *testing.T) {
 	t.Run("should return error for invalid JSON", func(t *testing.T) {
 		text := `not json at all`
 
-		_, err := parsePhase3Response(text)
+		_, err := parsePhase3Response(context.Background(), text)
 		if err == nil {
 			t.Fatal("expected error for invalid JSON")
 		}
@@ -38,7 +39,7 @@ func TestParsePhase3Response(t *testing.T) {
 	t.Run("should return error for missing summary field", func(t *testing.T) {
 		text := `{"other": "field"}`
 
-		_, err := parsePhase3Response(text)
+		_, err := parsePhase3Response(context.Background(), text)
 		if err == nil {
 			t.Fatal("expected error for missing summary field")
 		}
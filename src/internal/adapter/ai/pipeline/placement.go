@@ -1,8 +1,7 @@
-package gemini
+package pipeline
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 
@@ -11,7 +10,8 @@ import (
 	"github.com/specvital/worker/internal/domain/specview"
 )
 
-// placementResponse represents the expected JSON response from placement API call.
+// placementResponse represents the expected JSON response from placement API call
+// under schema placement.v1.
 type placementResponse struct {
 	Placements []placementItem `json:"placements"`
 }
@@ -33,26 +33,28 @@ func (p *Provider) placeNewTests(ctx context.Context, input specview.PlacementIn
 		return nil, nil, fmt.Errorf("%w: existing structure is required for placement", specview.ErrInvalidInput)
 	}
 
-	systemPrompt := prompt.PlacementSystemPrompt
+	systemPrompt := prompt.SystemPrompt(prompt.Placement, specview.PromptVersion(ctx))
 	userPrompt := prompt.BuildPlacementUserPrompt(input)
 
 	var output *specview.PlacementOutput
 	var usage *specview.TokenUsage
 
+	model := p.phaseModels(ctx).Phase1
 	err := p.phase1Retry.Do(ctx, func() error {
-		result, innerUsage, innerErr := p.generateContent(ctx, p.phase1Model, systemPrompt, userPrompt, p.phase1CB)
+		result, innerUsage, innerErr := p.generateContent(ctx, model, systemPrompt, userPrompt, p.phase1CB)
 		if innerErr != nil {
 			return innerErr
 		}
 		usage = innerUsage
 
 		var parseErr error
-		output, parseErr = parsePlacementResponse(result, len(input.NewTests))
+		output, parseErr = parsePlacementResponse(ctx, result, len(input.NewTests))
 		if parseErr != nil {
 			slog.WarnContext(ctx, "failed to parse placement response, will retry",
 				"error", parseErr,
 				"response", truncateForLog(result, 500),
 			)
+			p.quarantineOutput(ctx, "placement", prompt.PlacementSchemaVersion, model, systemPrompt, userPrompt, result, parseErr)
 			return &reliability.RetryableError{Err: parseErr}
 		}
 
@@ -66,10 +68,10 @@ func (p *Provider) placeNewTests(ctx context.Context, input specview.PlacementIn
 }
 
 // parsePlacementResponse parses the JSON response into PlacementOutput.
-func parsePlacementResponse(jsonStr string, expectedCount int) (*specview.PlacementOutput, error) {
-	var resp placementResponse
-	if err := json.Unmarshal([]byte(jsonStr), &resp); err != nil {
-		return nil, fmt.Errorf("json unmarshal: %w", err)
+func parsePlacementResponse(ctx context.Context, jsonStr string, expectedCount int) (*specview.PlacementOutput, error) {
+	resp, err := decodeResponse[placementResponse](ctx, "placement", prompt.PlacementSchemaVersion, jsonStr)
+	if err != nil {
+		return nil, err
 	}
 
 	output := &specview.PlacementOutput{
@@ -1,6 +1,7 @@
-package gemini
+package pipeline
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -158,7 +159,7 @@ func TestParsePlacementResponse(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			output, err := parsePlacementResponse(tc.json, tc.expectedCount)
+			output, err := parsePlacementResponse(context.Background(), tc.json, tc.expectedCount)
 
 			if tc.wantErr {
 				if err == nil {
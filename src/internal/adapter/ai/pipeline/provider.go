@@ -0,0 +1,289 @@
+package pipeline
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/specvital/worker/internal/adapter/ai/llm"
+	"github.com/specvital/worker/internal/adapter/ai/reliability"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/metrics"
+	"github.com/specvital/worker/internal/infra/tracing"
+)
+
+// Provider implements specview.AIProvider.
+// The phase pipeline is vendor-neutral; each vendor plugs in as an llm.Backend.
+type Provider struct {
+	backend        llm.Backend
+	phase1Model    string
+	phase2Model    string
+	fallbackModels map[string][]string // fallback chain by phase model
+
+	rateLimiter *reliability.RateLimiter
+	phase1CB    *reliability.CircuitBreaker
+	phase2CB    *reliability.CircuitBreaker
+	phase1Retry *reliability.Retryer
+	phase2Retry *reliability.Retryer
+
+	batch         *batchClassifier          // nil classifies every input synchronously
+	quarantine    specview.OutputQuarantine // nil discards unparseable responses
+	responseCache *ResponseCache            // nil disables response caching
+}
+
+// SetFallbackModels sets the models each phase falls back to, in order, when
+// its model answers with a rate limit or server error. Fallbacks equal to the
+// phase model are ignored; when both phases use the same model, the Phase 1
+// chain applies to both.
+func (p *Provider) SetFallbackModels(phase1, phase2 []string) {
+	p.fallbackModels = make(map[string][]string, 2)
+	for _, phase := range []struct {
+		model     string
+		fallbacks []string
+	}{{p.phase2Model, phase2}, {p.phase1Model, phase1}} {
+		var chain []string
+		for _, model := range phase.fallbacks {
+			if model != "" && model != phase.model && !slices.Contains(chain, model) {
+				chain = append(chain, model)
+			}
+		}
+		p.fallbackModels[phase.model] = chain
+	}
+}
+
+// SetResponseCache reuses responses to identical prompts made within the same
+// response cache scope (see specview.WithResponseCacheScope). A nil cache
+// disables caching.
+func (p *Provider) SetResponseCache(cache *ResponseCache) {
+	p.responseCache = cache
+}
+
+// NewProvider creates a provider that runs the phase pipeline against backend.
+// Models are passed through unchanged, so callers must resolve vendor defaults.
+// Calls are rate limited per backend name, so vendors never share a quota.
+func NewProvider(backend llm.Backend, phase1Model, phase2Model string) *Provider {
+	return &Provider{
+		backend:     backend,
+		phase1Model: phase1Model,
+		phase2Model: phase2Model,
+		rateLimiter: reliability.RateLimiterFor(backend.Name()),
+		phase1CB:    reliability.NewCircuitBreaker(reliability.DefaultPhase1CircuitConfig()),
+		phase2CB:    reliability.NewCircuitBreaker(reliability.DefaultPhase2CircuitConfig()),
+		phase1Retry: reliability.NewRetryer(reliability.DefaultPhase1RetryConfig()),
+		phase2Retry: reliability.NewRetryer(reliability.DefaultPhase2RetryConfig()),
+	}
+}
+
+// ClassifyDomains performs Phase 1: domain and feature classification.
+func (p *Provider) ClassifyDomains(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+	return p.classifyDomains(ctx, input, input.Language)
+}
+
+// ConvertTestNames performs Phase 2: test name to behavior conversion.
+func (p *Provider) ConvertTestNames(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+	return p.convertTestNames(ctx, input, input.Language)
+}
+
+// PlaceNewTests places new tests into an existing domain/feature structure.
+// Used for incremental caching: when tests are added, only placement is needed.
+func (p *Provider) PlaceNewTests(ctx context.Context, input specview.PlacementInput) (*specview.PlacementOutput, *specview.TokenUsage, error) {
+	return p.placeNewTests(ctx, input)
+}
+
+// GenerateSummary performs Phase 3: executive summary generation.
+func (p *Provider) GenerateSummary(ctx context.Context, input specview.Phase3Input) (*specview.Phase3Output, *specview.TokenUsage, error) {
+	return p.generateSummary(ctx, input)
+}
+
+// TranslateTexts translates the texts of a generated document into another language.
+func (p *Provider) TranslateTexts(ctx context.Context, input specview.TranslationInput) (*specview.TranslationOutput, *specview.TokenUsage, error) {
+	return p.translateTexts(ctx, input)
+}
+
+// Warmup pings the backend when it supports it, so the first job does not pay
+// for TLS handshakes and connection setup.
+func (p *Provider) Warmup(ctx context.Context) error {
+	pinger, ok := p.backend.(llm.Pinger)
+	if !ok {
+		return nil
+	}
+	if err := pinger.Ping(ctx); err != nil {
+		return fmt.Errorf("ping %s: %w", p.backend.Name(), err)
+	}
+	return nil
+}
+
+// Available reports whether both circuit breakers let calls through. An open
+// breaker fails calls immediately until its reset timeout lets a probe in.
+func (p *Provider) Available() bool {
+	return p.phase1CB.State() != reliability.CircuitOpen && p.phase2CB.State() != reliability.CircuitOpen
+}
+
+// Close releases resources held by the provider.
+func (p *Provider) Close() error {
+	// Backends hold no resources that need an explicit close
+	return nil
+}
+
+// phaseModels returns the model of each phase for calls made with ctx: the
+// models the request selected, else the configured ones. Phase 3 runs on the
+// Phase 1 model unless the request selects another.
+func (p *Provider) phaseModels(ctx context.Context) specview.PhaseModels {
+	requested := specview.RequestedPhaseModels(ctx)
+	return specview.PhaseModels{
+		Phase1: cmp.Or(requested.Phase1, p.phase1Model),
+		Phase2: cmp.Or(requested.Phase2, p.phase2Model),
+		Phase3: cmp.Or(requested.Phase3, p.phase1Model),
+	}
+}
+
+var tracer = otel.Tracer("github.com/specvital/worker/internal/adapter/ai/pipeline")
+
+// generateContent calls the backend with rate limiting and circuit breaker.
+// Returns the response text and token usage metadata. A model override in ctx,
+// set when a budget downgrades the job, replaces model and its fallback chain:
+// the budget already chose the model to use.
+func (p *Provider) generateContent(ctx context.Context, model, systemPrompt, userPrompt string, cb *reliability.CircuitBreaker) (string, *specview.TokenUsage, error) {
+	models := append([]string{model}, p.fallbackModels[model]...)
+	if override, ok := specview.ModelOverride(ctx); ok {
+		model = override
+		models = []string{override}
+	}
+	ctx, span := tracer.Start(ctx, "ai.generate", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("ai.backend", p.backend.Name()),
+		attribute.String("ai.model", model),
+	))
+
+	cacheKey, cacheable := p.responseCacheKey(ctx, model, systemPrompt, userPrompt)
+	if cacheable {
+		if text, ok := p.responseCache.Get(cacheKey); ok {
+			metrics.CacheLookups.WithLabelValues(metrics.CacheResponse, metrics.ResultHit).Inc()
+			span.SetAttributes(attribute.Bool("ai.cache_hit", true))
+			span.End()
+			return text, &specview.TokenUsage{}, nil
+		}
+		metrics.CacheLookups.WithLabelValues(metrics.CacheResponse, metrics.ResultMiss).Inc()
+	}
+
+	text, usage, err := p.complete(ctx, models, systemPrompt, userPrompt, cb)
+	// Responses that are not JSON always fail parsing; caching them would
+	// replay the failure to every retry until the entry expires.
+	if err == nil && cacheable && json.Valid([]byte(llm.StripCodeFence(text))) {
+		p.responseCache.Put(cacheKey, text)
+	}
+	if usage != nil {
+		span.SetAttributes(
+			attribute.Int("ai.usage.candidates_tokens", int(usage.CandidatesTokens)),
+			attribute.Int("ai.usage.prompt_tokens", int(usage.PromptTokens)),
+		)
+		if usage.FallbackFrom != "" {
+			span.SetAttributes(attribute.String("ai.fallback_model", usage.Model))
+		}
+	}
+	tracing.EndSpan(span, err)
+	return text, usage, err
+}
+
+// responseCacheKey returns the cache key of a prompt, or false when the
+// response must not be cached: caching is disabled or ctx has no scope.
+func (p *Provider) responseCacheKey(ctx context.Context, model, systemPrompt, userPrompt string) (string, bool) {
+	if p.responseCache == nil {
+		return "", false
+	}
+	scope, ok := specview.ResponseCacheScope(ctx)
+	if !ok {
+		return "", false
+	}
+	return responseCacheKey(scope, p.backend.Name(), model, systemPrompt, userPrompt), true
+}
+
+// complete calls the first of models, moving down the chain while calls fail
+// with retryable errors: rate limits and outages are usually per model, so a
+// smaller model often still answers. The breaker records the outcome of the
+// whole chain.
+func (p *Provider) complete(ctx context.Context, models []string, systemPrompt, userPrompt string, cb *reliability.CircuitBreaker) (string, *specview.TokenUsage, error) {
+	// Check circuit breaker
+	if !cb.Allow() {
+		return "", nil, fmt.Errorf("%w: circuit breaker open", specview.ErrAIUnavailable)
+	}
+
+	var err error
+	for i, model := range models {
+		if i > 0 {
+			if !reliability.IsRetryable(err) {
+				break
+			}
+			slog.WarnContext(ctx, "AI model unavailable, falling back",
+				"backend", p.backend.Name(),
+				"model", models[i-1],
+				"fallback", model,
+			)
+		}
+
+		// Wait for rate limiter
+		if err := p.rateLimiter.Wait(ctx); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+				return "", nil, err
+			}
+			return "", nil, fmt.Errorf("%w: %v", specview.ErrRateLimited, err)
+		}
+
+		var text string
+		var usage *specview.TokenUsage
+		text, usage, err = p.backend.Complete(ctx, llm.Request{
+			Model:        model,
+			SystemPrompt: systemPrompt,
+			UserPrompt:   userPrompt,
+		})
+		if err != nil {
+			// Truncation and safety blocks mean the API worked correctly; they must not trip the breaker.
+			if errors.Is(err, specview.ErrOutputTruncated) || errors.Is(err, specview.ErrInvalidInput) {
+				cb.RecordSuccess()
+				return "", nil, err
+			}
+			slog.WarnContext(ctx, "AI API call failed",
+				"backend", p.backend.Name(),
+				"model", model,
+				"error", err,
+			)
+			continue
+		}
+		recordTokenUsage(p.backend.Name(), model, usage)
+
+		if text == "" {
+			cb.RecordFailure()
+			return "", nil, fmt.Errorf("empty response from %s", p.backend.Name())
+		}
+
+		cb.RecordSuccess()
+		if i > 0 {
+			metrics.AIModelFallbacks.WithLabelValues(p.backend.Name(), models[0], model).Inc()
+			if usage == nil {
+				usage = &specview.TokenUsage{}
+			}
+			usage.FallbackFrom = models[0]
+			usage.Model = model
+		}
+		return text, usage, nil
+	}
+
+	cb.RecordFailure()
+	return "", nil, err
+}
+
+// recordTokenUsage counts billed tokens, including those of empty responses.
+func recordTokenUsage(backend, model string, usage *specview.TokenUsage) {
+	if usage == nil {
+		return
+	}
+	metrics.AITokens.WithLabelValues(backend, model, "prompt").Add(float64(usage.PromptTokens))
+	metrics.AITokens.WithLabelValues(backend, model, "candidates").Add(float64(usage.CandidatesTokens))
+}
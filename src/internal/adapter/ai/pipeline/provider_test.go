@@ -0,0 +1,282 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/ai/llm"
+	"github.com/specvital/worker/internal/adapter/ai/reliability"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type fakeBackend struct {
+	err  error
+	text string
+}
+
+func (f *fakeBackend) Name() string { return "fake" }
+
+func (f *fakeBackend) Complete(_ context.Context, _ llm.Request) (string, *specview.TokenUsage, error) {
+	return f.text, &specview.TokenUsage{TotalTokens: 10}, f.err
+}
+
+type pingingBackend struct {
+	fakeBackend
+	err   error
+	pings int
+}
+
+func (b *pingingBackend) Ping(_ context.Context) error {
+	b.pings++
+	return b.err
+}
+
+func TestProvider_Warmup(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pings backends that support it", func(t *testing.T) {
+		backend := &pingingBackend{}
+		p := NewProvider(backend, "m1", "m2")
+
+		if err := p.Warmup(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if backend.pings != 1 {
+			t.Errorf("pings = %d, want 1", backend.pings)
+		}
+	})
+
+	t.Run("returns ping errors", func(t *testing.T) {
+		p := NewProvider(&pingingBackend{err: errors.New("unauthorized")}, "m1", "m2")
+
+		if err := p.Warmup(ctx); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("no-op for backends without ping", func(t *testing.T) {
+		p := NewProvider(&fakeBackend{}, "m1", "m2")
+
+		if err := p.Warmup(ctx); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestProvider_Available(t *testing.T) {
+	p := NewProvider(&fakeBackend{err: errors.New("service unavailable")}, "m1", "m2")
+	if !p.Available() {
+		t.Fatal("expected a new provider to be available")
+	}
+
+	for range 10 {
+		_, _, _ = p.generateContent(context.Background(), "m1", "sys", "user", p.phase2CB)
+	}
+	if p.phase2CB.State() != reliability.CircuitOpen || p.Available() {
+		t.Errorf("expected the provider unavailable with the phase 2 breaker %s", p.phase2CB.State())
+	}
+}
+
+func TestProvider_GenerateContent_Backend(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns backend text", func(t *testing.T) {
+		p := NewProvider(&fakeBackend{text: `{"ok":true}`}, "m1", "m2")
+
+		text, usage, err := p.generateContent(ctx, "m1", "sys", "user", p.phase1CB)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text != `{"ok":true}` || usage == nil {
+			t.Errorf("unexpected result: %q %+v", text, usage)
+		}
+	})
+
+	t.Run("truncation does not trip circuit breaker", func(t *testing.T) {
+		p := NewProvider(&fakeBackend{err: specview.ErrOutputTruncated}, "m1", "m2")
+
+		for range 10 {
+			_, _, err := p.generateContent(ctx, "m1", "sys", "user", p.phase1CB)
+			if !errors.Is(err, specview.ErrOutputTruncated) {
+				t.Fatalf("expected ErrOutputTruncated, got %v", err)
+			}
+		}
+		if p.phase1CB.State() != reliability.CircuitClosed {
+			t.Errorf("expected circuit to stay closed, got %s", p.phase1CB.State())
+		}
+	})
+
+	t.Run("model override replaces the phase model", func(t *testing.T) {
+		backend := &countingBackend{fakeBackend: fakeBackend{text: `{"ok":true}`}}
+		p := NewProvider(backend, "m1", "m2")
+
+		if _, _, err := p.generateContent(specview.WithModelOverride(ctx, "small"), "m1", "sys", "user", p.phase1CB); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(backend.models) != 1 || backend.models[0] != "small" {
+			t.Errorf("backend models = %v, want [small]", backend.models)
+		}
+	})
+
+	t.Run("empty response is an error", func(t *testing.T) {
+		p := NewProvider(&fakeBackend{}, "m1", "m2")
+
+		_, _, err := p.generateContent(ctx, "m1", "sys", "user", p.phase1CB)
+		if err == nil {
+			t.Error("expected error for empty response")
+		}
+	})
+}
+
+type countingBackend struct {
+	fakeBackend
+	calls  int
+	models []string
+}
+
+func (b *countingBackend) Complete(ctx context.Context, req llm.Request) (string, *specview.TokenUsage, error) {
+	b.calls++
+	b.models = append(b.models, req.Model)
+	return b.fakeBackend.Complete(ctx, req)
+}
+
+func TestProvider_GenerateContent_ResponseCache(t *testing.T) {
+	publicCtx := specview.WithResponseCacheScope(context.Background(), "public")
+	privateCtx := specview.WithResponseCacheScope(context.Background(), "user:u1")
+
+	tests := []struct {
+		name      string
+		first     context.Context
+		second    context.Context
+		text      string
+		wantCalls int
+	}{
+		{"identical prompt in the same scope", publicCtx, publicCtx, `{"ok":true}`, 1},
+		{"other scope", publicCtx, privateCtx, `{"ok":true}`, 2},
+		{"no scope", context.Background(), context.Background(), `{"ok":true}`, 2},
+		{"response that is not JSON", publicCtx, publicCtx, "oops", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &countingBackend{fakeBackend: fakeBackend{text: tt.text}}
+			p := NewProvider(backend, "m1", "m2")
+			p.SetResponseCache(NewResponseCache(time.Hour, 0))
+
+			for _, ctx := range []context.Context{tt.first, tt.second} {
+				text, usage, err := p.generateContent(ctx, "m1", "sys", "user", p.phase1CB)
+				if err != nil || text != tt.text || usage == nil {
+					t.Fatalf("unexpected result: %q %+v %v", text, usage, err)
+				}
+			}
+			if backend.calls != tt.wantCalls {
+				t.Errorf("backend calls = %d, want %d", backend.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// modelBackend fails calls to the models in errs and answers the others.
+type modelBackend struct {
+	errs   map[string]error
+	models []string
+}
+
+func (b *modelBackend) Name() string { return "fake" }
+
+func (b *modelBackend) Complete(_ context.Context, req llm.Request) (string, *specview.TokenUsage, error) {
+	b.models = append(b.models, req.Model)
+	if err := b.errs[req.Model]; err != nil {
+		return "", nil, err
+	}
+	return `{"ok":true}`, &specview.TokenUsage{Model: req.Model, TotalTokens: 10}, nil
+}
+
+func TestProvider_GenerateContent_Fallback(t *testing.T) {
+	ctx := context.Background()
+	rateLimited := &reliability.RetryableError{Err: errors.New("429 too many requests")}
+	unavailable := &reliability.RetryableError{Err: errors.New("503 service unavailable")}
+
+	t.Run("falls back down the chain on rate limits and outages", func(t *testing.T) {
+		backend := &modelBackend{errs: map[string]error{"pro": rateLimited, "flash": unavailable}}
+		p := NewProvider(backend, "pro", "lite")
+		p.SetFallbackModels([]string{"flash", "lite"}, nil)
+
+		_, usage, err := p.generateContent(ctx, "pro", "sys", "user", p.phase1CB)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(backend.models, []string{"pro", "flash", "lite"}) {
+			t.Errorf("backend models = %v", backend.models)
+		}
+		if usage.Model != "lite" || usage.FallbackFrom != "pro" {
+			t.Errorf("usage = %+v, want lite falling back from pro", usage)
+		}
+	})
+
+	t.Run("does not fall back on permanent errors", func(t *testing.T) {
+		backend := &modelBackend{errs: map[string]error{"pro": specview.ErrInvalidInput}}
+		p := NewProvider(backend, "pro", "lite")
+		p.SetFallbackModels([]string{"flash"}, nil)
+
+		if _, _, err := p.generateContent(ctx, "pro", "sys", "user", p.phase1CB); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Fatalf("expected ErrInvalidInput, got %v", err)
+		}
+		if !slices.Equal(backend.models, []string{"pro"}) {
+			t.Errorf("backend models = %v, want [pro]", backend.models)
+		}
+	})
+
+	t.Run("returns the last error when the whole chain fails", func(t *testing.T) {
+		backend := &modelBackend{errs: map[string]error{"pro": rateLimited, "flash": unavailable}}
+		p := NewProvider(backend, "pro", "lite")
+		p.SetFallbackModels([]string{"flash"}, nil)
+
+		if _, _, err := p.generateContent(ctx, "pro", "sys", "user", p.phase1CB); !errors.Is(err, unavailable) {
+			t.Fatalf("expected the fallback's error, got %v", err)
+		}
+	})
+
+	t.Run("each phase uses its own chain", func(t *testing.T) {
+		backend := &modelBackend{errs: map[string]error{"lite": rateLimited}}
+		p := NewProvider(backend, "pro", "lite")
+		p.SetFallbackModels([]string{"flash"}, []string{"lite", "nano"})
+
+		_, usage, err := p.generateContent(ctx, "lite", "sys", "user", p.phase2CB)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(backend.models, []string{"lite", "nano"}) || usage.FallbackFrom != "lite" {
+			t.Errorf("backend models = %v, usage = %+v", backend.models, usage)
+		}
+	})
+
+	t.Run("model override disables the chain", func(t *testing.T) {
+		backend := &modelBackend{errs: map[string]error{"small": rateLimited}}
+		p := NewProvider(backend, "pro", "lite")
+		p.SetFallbackModels([]string{"flash"}, nil)
+
+		if _, _, err := p.generateContent(specview.WithModelOverride(ctx, "small"), "pro", "sys", "user", p.phase1CB); err == nil {
+			t.Fatal("expected the override's error")
+		}
+		if !slices.Equal(backend.models, []string{"small"}) {
+			t.Errorf("backend models = %v, want [small]", backend.models)
+		}
+	})
+}
+
+func TestProvider_PhaseModels(t *testing.T) {
+	p := NewProvider(&modelBackend{}, "pro", "lite")
+
+	if got, want := p.phaseModels(context.Background()), (specview.PhaseModels{Phase1: "pro", Phase2: "lite", Phase3: "pro"}); got != want {
+		t.Errorf("configured phaseModels() = %+v, want %+v", got, want)
+	}
+
+	ctx := specview.WithPhaseModels(context.Background(), specview.PhaseModels{Phase2: "flash", Phase3: "lite"})
+	if got, want := p.phaseModels(ctx), (specview.PhaseModels{Phase1: "pro", Phase2: "flash", Phase3: "lite"}); got != want {
+		t.Errorf("selected phaseModels() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,189 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/metrics"
+)
+
+// Issue label values for metrics.AIOutputRepairs.
+const (
+	issueConfidenceRange  = "confidence_range"
+	issueDuplicateIndex   = "duplicate_index"
+	issueEmptyDescription = "empty_description"
+	issueEmptyDomain      = "empty_domain"
+	issueEmptyFeature     = "empty_feature"
+	issueInvalidIndex     = "invalid_index"
+)
+
+// maxDroppedRatio is the share of test entries a repair may drop before the
+// response is rejected instead: past it the model most likely misread the
+// indices, so dropping would leave little of the answer.
+const maxDroppedRatio = 0.5
+
+// repairReport counts the entries a repair dropped or fixed, by issue.
+type repairReport map[string]int
+
+func (r repairReport) add(issue string) {
+	r[issue]++
+}
+
+// record logs the repairs and counts them in metrics.AIOutputRepairs.
+func (r repairReport) record(ctx context.Context, phase string) {
+	if len(r) == 0 {
+		return
+	}
+	attrs := []any{"phase", phase}
+	for _, issue := range slices.Sorted(maps.Keys(r)) {
+		metrics.AIOutputRepairs.WithLabelValues(phase, issue).Add(float64(r[issue]))
+		attrs = append(attrs, issue, r[issue])
+	}
+	slog.WarnContext(ctx, "repaired AI output", attrs...)
+}
+
+func (r repairReport) String() string {
+	parts := make([]string, 0, len(r))
+	for _, issue := range slices.Sorted(maps.Keys(r)) {
+		parts = append(parts, fmt.Sprintf("%d %s", r[issue], strings.ReplaceAll(issue, "_", " ")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// dropped counts the test entries the repair removed.
+func (r repairReport) dropped() int {
+	return r[issueInvalidIndex] + r[issueDuplicateIndex] + r[issueEmptyDescription]
+}
+
+// repairPhase1Output drops test indices not in input and the repeated
+// placements of a test, keeping its first one, along with unnamed features
+// and domains and those left without tests. Confidences are clamped to
+// [0, 1]. It returns an OutputValidationError when nothing usable is left or
+// more than maxDroppedRatio of the placements were dropped.
+func repairPhase1Output(output *specview.Phase1Output, input specview.Phase1Input) (*specview.Phase1Output, repairReport, error) {
+	report := repairReport{}
+	if output == nil || len(output.Domains) == 0 {
+		return nil, report, &specview.OutputValidationError{Phase: "phase1", Problems: []string{"no domains in output"}}
+	}
+
+	expected := make(map[int]bool)
+	for _, file := range input.Files {
+		for _, test := range file.Tests {
+			expected[test.Index] = true
+		}
+	}
+
+	placements := 0
+	placed := make(map[int]bool, len(expected))
+	repaired := &specview.Phase1Output{Domains: make([]specview.DomainGroup, 0, len(output.Domains))}
+	for _, domain := range output.Domains {
+		if strings.TrimSpace(domain.Name) == "" {
+			report.add(issueEmptyDomain)
+			for _, feature := range domain.Features {
+				placements += len(feature.TestIndices)
+			}
+			continue
+		}
+
+		features := make([]specview.FeatureGroup, 0, len(domain.Features))
+		for _, feature := range domain.Features {
+			placements += len(feature.TestIndices)
+			if strings.TrimSpace(feature.Name) == "" {
+				report.add(issueEmptyFeature)
+				continue
+			}
+			indices := make([]int, 0, len(feature.TestIndices))
+			for _, idx := range feature.TestIndices {
+				switch {
+				case !expected[idx]:
+					report.add(issueInvalidIndex)
+				case placed[idx]:
+					report.add(issueDuplicateIndex)
+				default:
+					placed[idx] = true
+					indices = append(indices, idx)
+				}
+			}
+			if len(indices) == 0 && len(feature.TestIndices) > 0 {
+				continue
+			}
+			feature.Confidence = clampConfidence(feature.Confidence, report)
+			feature.TestIndices = indices
+			features = append(features, feature)
+		}
+		if len(features) == 0 {
+			report.add(issueEmptyDomain)
+			continue
+		}
+		domain.Confidence = clampConfidence(domain.Confidence, report)
+		domain.Features = features
+		repaired.Domains = append(repaired.Domains, domain)
+	}
+
+	if len(repaired.Domains) == 0 {
+		return nil, report, &specview.OutputValidationError{Phase: "phase1", Problems: []string{"no domains with valid tests", report.String()}}
+	}
+	if dropped := placements - len(placed); placements > 0 && float64(dropped) > float64(placements)*maxDroppedRatio {
+		return nil, report, &specview.OutputValidationError{
+			Phase:    "phase1",
+			Problems: []string{fmt.Sprintf("%d of %d test placements invalid", dropped, placements), report.String()},
+		}
+	}
+	return repaired, report, nil
+}
+
+// repairPhase2Output drops behaviors for tests not in input, repeated
+// behaviors of a test, keeping the first one, and behaviors without a
+// description. Confidences are clamped to [0, 1]. It returns an
+// OutputValidationError when no behavior is left or more than
+// maxDroppedRatio of them were dropped.
+func repairPhase2Output(output *specview.Phase2Output, input specview.Phase2Input) (*specview.Phase2Output, repairReport, error) {
+	report := repairReport{}
+	if output == nil || len(output.Behaviors) == 0 {
+		return nil, report, &specview.OutputValidationError{Phase: "phase2", Problems: []string{"no behaviors in output"}}
+	}
+
+	expected := make(map[int]bool, len(input.Tests))
+	for _, test := range input.Tests {
+		expected[test.Index] = true
+	}
+
+	seen := make(map[int]bool, len(output.Behaviors))
+	repaired := &specview.Phase2Output{Behaviors: make([]specview.BehaviorSpec, 0, len(output.Behaviors))}
+	for _, behavior := range output.Behaviors {
+		switch {
+		case !expected[behavior.TestIndex]:
+			report.add(issueInvalidIndex)
+		case seen[behavior.TestIndex]:
+			report.add(issueDuplicateIndex)
+		case strings.TrimSpace(behavior.Description) == "":
+			report.add(issueEmptyDescription)
+		default:
+			seen[behavior.TestIndex] = true
+			behavior.Confidence = clampConfidence(behavior.Confidence, report)
+			repaired.Behaviors = append(repaired.Behaviors, behavior)
+		}
+	}
+
+	total := len(output.Behaviors)
+	if len(repaired.Behaviors) == 0 || float64(report.dropped()) > float64(total)*maxDroppedRatio {
+		return nil, report, &specview.OutputValidationError{
+			Phase:    "phase2",
+			Problems: []string{fmt.Sprintf("%d of %d behaviors invalid", report.dropped(), total), report.String()},
+		}
+	}
+	return repaired, report, nil
+}
+
+func clampConfidence(confidence float64, report repairReport) float64 {
+	if confidence >= 0 && confidence <= 1 {
+		return confidence
+	}
+	report.add(issueConfidenceRange)
+	return min(max(confidence, 0), 1)
+}
@@ -0,0 +1,187 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/specvital/worker/internal/adapter/ai/llm"
+	"github.com/specvital/worker/internal/adapter/ai/reliability"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func repairPhase1Input() specview.Phase1Input {
+	return specview.Phase1Input{Files: []specview.FileInfo{
+		{Path: "auth_test.go", Tests: []specview.TestInfo{{Index: 0, Name: "TestLogin"}, {Index: 1, Name: "TestLogout"}}},
+		{Path: "cart_test.go", Tests: []specview.TestInfo{{Index: 2, Name: "TestAdd"}, {Index: 3, Name: "TestRemove"}}},
+	}}
+}
+
+func TestRepairPhase1Output(t *testing.T) {
+	t.Run("drops invalid and duplicate indices", func(t *testing.T) {
+		output := &specview.Phase1Output{Domains: []specview.DomainGroup{
+			{Name: "Auth", Confidence: 1.2, Features: []specview.FeatureGroup{
+				{Name: "Login", Confidence: 0.9, TestIndices: []int{0, 1, 7}},
+			}},
+			{Name: "Cart", Confidence: 0.8, Features: []specview.FeatureGroup{
+				{Name: "Items", Confidence: 0.8, TestIndices: []int{1, 2, 3}},
+			}},
+		}}
+
+		repaired, report, err := repairPhase1Output(output, repairPhase1Input())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := repaired.Domains[0].Features[0].TestIndices; !slices.Equal(got, []int{0, 1}) {
+			t.Errorf("Auth/Login indices = %v, want [0 1]", got)
+		}
+		if got := repaired.Domains[1].Features[0].TestIndices; !slices.Equal(got, []int{2, 3}) {
+			t.Errorf("Cart/Items indices = %v, want [2 3]", got)
+		}
+		if repaired.Domains[0].Confidence != 1 {
+			t.Errorf("expected confidence clamped to 1, got %v", repaired.Domains[0].Confidence)
+		}
+		if report[issueInvalidIndex] != 1 || report[issueDuplicateIndex] != 1 || report[issueConfidenceRange] != 1 {
+			t.Errorf("unexpected report %v", report)
+		}
+	})
+
+	t.Run("drops unnamed and emptied groups", func(t *testing.T) {
+		output := &specview.Phase1Output{Domains: []specview.DomainGroup{
+			{Name: "Auth", Features: []specview.FeatureGroup{
+				{Name: "Login", TestIndices: []int{0, 1, 2}},
+				{Name: "", TestIndices: []int{3}},
+			}},
+			{Name: " ", Features: []specview.FeatureGroup{{Name: "Items", TestIndices: []int{3}}}},
+			{Name: "Ghost", Features: []specview.FeatureGroup{{Name: "Nothing", TestIndices: []int{9}}}},
+		}}
+
+		repaired, report, err := repairPhase1Output(output, repairPhase1Input())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repaired.Domains) != 1 || len(repaired.Domains[0].Features) != 1 {
+			t.Fatalf("expected only Auth/Login kept, got %+v", repaired.Domains)
+		}
+		if report[issueEmptyFeature] != 1 || report[issueEmptyDomain] != 2 {
+			t.Errorf("unexpected report %v", report)
+		}
+	})
+
+	t.Run("rejects output with mostly invalid placements", func(t *testing.T) {
+		output := &specview.Phase1Output{Domains: []specview.DomainGroup{
+			{Name: "Auth", Features: []specview.FeatureGroup{{Name: "Login", TestIndices: []int{0, 10, 11, 12}}}},
+		}}
+
+		_, _, err := repairPhase1Output(output, repairPhase1Input())
+		var validationErr *specview.OutputValidationError
+		if !errors.As(err, &validationErr) || validationErr.Phase != "phase1" {
+			t.Fatalf("expected phase 1 OutputValidationError, got %v", err)
+		}
+		if !errors.Is(err, specview.ErrInvalidOutput) {
+			t.Error("expected the error to match ErrInvalidOutput")
+		}
+	})
+
+	t.Run("rejects output without domains", func(t *testing.T) {
+		if _, _, err := repairPhase1Output(&specview.Phase1Output{}, repairPhase1Input()); !errors.Is(err, specview.ErrInvalidOutput) {
+			t.Errorf("expected ErrInvalidOutput, got %v", err)
+		}
+	})
+}
+
+func TestRepairPhase2Output(t *testing.T) {
+	input := specview.Phase2Input{Tests: []specview.TestForConversion{{Index: 4, Name: "TestA"}, {Index: 5, Name: "TestB"}, {Index: 6, Name: "TestC"}}}
+
+	t.Run("drops invalid entries", func(t *testing.T) {
+		output := &specview.Phase2Output{Behaviors: []specview.BehaviorSpec{
+			{TestIndex: 4, Description: "does A", Confidence: 0.9},
+			{TestIndex: 4, Description: "does A again", Confidence: 0.9},
+			{TestIndex: 5, Description: "does B", Confidence: -1},
+			{TestIndex: 6, Description: "does C", Confidence: 0.7},
+		}}
+
+		repaired, report, err := repairPhase2Output(output, input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repaired.Behaviors) != 3 || repaired.Behaviors[0].Description != "does A" {
+			t.Errorf("unexpected behaviors %+v", repaired.Behaviors)
+		}
+		if repaired.Behaviors[1].Confidence != 0 {
+			t.Errorf("expected confidence clamped to 0, got %v", repaired.Behaviors[1].Confidence)
+		}
+		if report[issueDuplicateIndex] != 1 || report[issueConfidenceRange] != 1 {
+			t.Errorf("unexpected report %v", report)
+		}
+	})
+
+	t.Run("rejects output with mostly invalid entries", func(t *testing.T) {
+		output := &specview.Phase2Output{Behaviors: []specview.BehaviorSpec{
+			{TestIndex: 4, Description: "does A"},
+			{TestIndex: -1, Description: "unknown"},
+			{TestIndex: 5, Description: ""},
+		}}
+
+		if _, _, err := repairPhase2Output(output, input); !errors.Is(err, specview.ErrInvalidOutput) {
+			t.Errorf("expected ErrInvalidOutput, got %v", err)
+		}
+	})
+}
+
+// scriptedBackend answers with texts in order and records the user prompts.
+type scriptedBackend struct {
+	prompts []string
+	texts   []string
+}
+
+func (b *scriptedBackend) Name() string { return "scripted" }
+
+func (b *scriptedBackend) Complete(_ context.Context, req llm.Request) (string, *specview.TokenUsage, error) {
+	b.prompts = append(b.prompts, req.UserPrompt)
+	text := b.texts[min(len(b.prompts), len(b.texts))-1]
+	return text, &specview.TokenUsage{TotalTokens: 10}, nil
+}
+
+func TestProvider_ReasksRejectedOutput(t *testing.T) {
+	ctx := context.Background()
+	retry := reliability.NewRetryer(reliability.RetryConfig{MaxAttempts: 2, InitialBackoff: 1, MaxBackoff: 1})
+
+	t.Run("phase 1 retries with a correction prompt", func(t *testing.T) {
+		backend := &scriptedBackend{texts: []string{
+			`{"domains": [{"name": "Auth", "features": [{"name": "Login", "test_indices": [40, 41, 42]}]}]}`,
+			`{"domains": [{"name": "Auth", "confidence": 0.9, "features": [{"name": "Login", "confidence": 0.9, "test_indices": [0, 1, 2, 3]}]}]}`,
+		}}
+		p := NewProvider(backend, "m1", "m2")
+		p.phase1Retry = retry
+
+		output, _, err := p.classifyDomainsSingle(ctx, repairPhase1Input(), "English", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := output.Domains[0].Features[0].TestIndices; !slices.Equal(got, []int{0, 1, 2, 3}) {
+			t.Errorf("unexpected indices %v", got)
+		}
+		if len(backend.prompts) != 2 || strings.Contains(backend.prompts[0], "## Correction") || !strings.Contains(backend.prompts[1], "## Correction") {
+			t.Errorf("expected the second call only to carry a correction, got %d calls", len(backend.prompts))
+		}
+	})
+
+	t.Run("phase 2 fails with a typed error once retries run out", func(t *testing.T) {
+		backend := &scriptedBackend{texts: []string{`{"conversions": [{"index": 9, "description": "x"}]}`}}
+		p := NewProvider(backend, "m1", "m2")
+		p.phase2Retry = retry
+
+		input := specview.Phase2Input{Tests: []specview.TestForConversion{{Index: 4, Name: "TestA"}}}
+		_, _, err := p.convertTestNames(ctx, input, "English")
+		var validationErr *specview.OutputValidationError
+		if !errors.As(err, &validationErr) || validationErr.Phase != "phase2" {
+			t.Fatalf("expected phase 2 OutputValidationError, got %v", err)
+		}
+		if len(backend.prompts) != 2 {
+			t.Errorf("expected one re-ask, got %d calls", len(backend.prompts))
+		}
+	})
+}
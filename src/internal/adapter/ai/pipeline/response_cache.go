@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// DefaultResponseCacheMaxEntries bounds the memory held by a response cache.
+const DefaultResponseCacheMaxEntries = 10000
+
+// ResponseCache holds AI responses for a short time, keyed by a hash of the
+// prompt, so identical prompts recurring across jobs skip the API. Unlike the
+// behavior cache it matches whole prompts and lives in process memory only.
+type ResponseCache struct {
+	entries    map[string]responseEntry
+	maxEntries int
+	mu         sync.Mutex
+	now        func() time.Time
+	ttl        time.Duration
+}
+
+type responseEntry struct {
+	expiresAt time.Time
+	text      string
+}
+
+// NewResponseCache creates a cache keeping responses for ttl. A maxEntries
+// <= 0 uses DefaultResponseCacheMaxEntries.
+func NewResponseCache(ttl time.Duration, maxEntries int) *ResponseCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultResponseCacheMaxEntries
+	}
+	return &ResponseCache{
+		entries:    make(map[string]responseEntry),
+		maxEntries: maxEntries,
+		now:        time.Now,
+		ttl:        ttl,
+	}
+}
+
+// responseCacheKey hashes everything that determines a response. The scope
+// keeps responses of one privacy scope from being served to another.
+func responseCacheKey(scope, backend, model, systemPrompt, userPrompt string) string {
+	h := sha256.New()
+	for _, part := range []string{scope, backend, model, systemPrompt, userPrompt} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the unexpired response stored under key.
+func (c *ResponseCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.text, true
+}
+
+// Put stores a response. When the cache is full, expired entries are dropped
+// first, then the entry closest to expiry.
+func (c *ResponseCache) Put(key, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		c.evictLocked(now)
+	}
+	c.entries[key] = responseEntry{expiresAt: now.Add(c.ttl), text: text}
+}
+
+// Delete drops the response stored under key.
+func (c *ResponseCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+func (c *ResponseCache) evictLocked(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for k, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, k)
+			continue
+		}
+		if oldestKey == "" || e.expiresAt.Before(oldest) {
+			oldestKey, oldest = k, e.expiresAt
+		}
+	}
+	if len(c.entries) >= c.maxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
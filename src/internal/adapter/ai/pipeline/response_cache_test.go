@@ -0,0 +1,48 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewResponseCache(time.Hour, 2)
+	cache.now = func() time.Time { return now }
+
+	cache.Put("a", "first")
+	now = now.Add(time.Minute)
+	cache.Put("b", "second")
+	if text, ok := cache.Get("a"); !ok || text != "first" {
+		t.Fatalf("Get(a) = %q, %v", text, ok)
+	}
+
+	cache.Put("c", "third")
+	if _, ok := cache.Get("a"); ok {
+		t.Error("a full cache should evict the entry closest to expiry")
+	}
+	if _, ok := cache.Get("c"); !ok {
+		t.Error("new entry should be stored")
+	}
+
+	now = now.Add(time.Hour)
+	if _, ok := cache.Get("c"); ok {
+		t.Error("expired entries should not be returned")
+	}
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	base := responseCacheKey("public", "gemini", "m1", "sys", "user")
+	if base != responseCacheKey("public", "gemini", "m1", "sys", "user") {
+		t.Error("key should be deterministic")
+	}
+	for _, other := range []string{
+		responseCacheKey("user:u1", "gemini", "m1", "sys", "user"),
+		responseCacheKey("public", "gemini", "m2", "sys", "user"),
+		responseCacheKey("public", "gemini", "m1", "sysuser", ""),
+	} {
+		if other == base {
+			t.Error("keys of different prompts or scopes should differ")
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// decodeResponse decodes an AI response into a schema struct. Fields the
+// schema does not know are logged rather than rejected: a model adding or
+// renaming fields keeps the job running on the fields it still gets right,
+// and the warning shows the drift before the prompt is fixed.
+func decodeResponse[T any](ctx context.Context, phase, schemaVersion, text string) (*T, error) {
+	strict := json.NewDecoder(strings.NewReader(text))
+	strict.DisallowUnknownFields()
+	var resp T
+	err := strict.Decode(&resp)
+	if err == nil {
+		return &resp, nil
+	}
+	field, ok := unknownField(err)
+	if !ok {
+		return nil, fmt.Errorf("json unmarshal: %w", err)
+	}
+
+	slog.WarnContext(ctx, "AI response has a field unknown to its schema",
+		"phase", phase,
+		"schema_version", schemaVersion,
+		"field", field,
+	)
+	var lenient T
+	if err := json.Unmarshal([]byte(text), &lenient); err != nil {
+		return nil, fmt.Errorf("json unmarshal: %w", err)
+	}
+	return &lenient, nil
+}
+
+// unknownField extracts the field name from the error encoding/json returns
+// for unknown fields, which has no exported type.
+func unknownField(err error) (string, bool) {
+	const prefix = "json: unknown field "
+	msg := err.Error()
+	if !strings.HasPrefix(msg, prefix) {
+		return "", false
+	}
+	return strings.Trim(strings.TrimPrefix(msg, prefix), `"`), true
+}
+
+// SetQuarantine stores responses that fail to parse against their schema.
+// A nil quarantine discards them.
+func (p *Provider) SetQuarantine(quarantine specview.OutputQuarantine) {
+	p.quarantine = quarantine
+}
+
+// quarantineOutput stores a response that failed to parse or validate and
+// drops it from the response cache, so the retry asks the model again instead
+// of reparsing it. Storage failures are non-critical: the error is already
+// being handled.
+func (p *Provider) quarantineOutput(ctx context.Context, phase, schemaVersion, model, systemPrompt, userPrompt, text string, parseErr error) {
+	p.dropCachedResponse(ctx, model, systemPrompt, userPrompt)
+	if override, ok := specview.ModelOverride(ctx); ok {
+		model = override
+	}
+	if p.quarantine == nil {
+		return
+	}
+	err := p.quarantine.QuarantineOutput(ctx, specview.QuarantinedOutput{
+		Error:         parseErr.Error(),
+		ModelID:       model,
+		Phase:         phase,
+		Response:      text,
+		SchemaVersion: schemaVersion,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to quarantine AI output (non-critical)",
+			"phase", phase,
+			"error", err,
+		)
+	}
+}
+
+// dropCachedResponse removes a rejected response from the response cache, so
+// later jobs asking the same prompt get a fresh answer.
+func (p *Provider) dropCachedResponse(ctx context.Context, model, systemPrompt, userPrompt string) {
+	if override, ok := specview.ModelOverride(ctx); ok {
+		model = override
+	}
+	if cacheKey, cacheable := p.responseCacheKey(ctx, model, systemPrompt, userPrompt); cacheable {
+		p.responseCache.Delete(cacheKey)
+	}
+}
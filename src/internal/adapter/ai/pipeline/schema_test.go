@@ -0,0 +1,142 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/ai/prompt"
+	"github.com/specvital/worker/internal/adapter/ai/reliability"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type recordingQuarantine struct {
+	outputs []specview.QuarantinedOutput
+}
+
+func (q *recordingQuarantine) QuarantineOutput(_ context.Context, output specview.QuarantinedOutput) error {
+	q.outputs = append(q.outputs, output)
+	return nil
+}
+
+func TestDecodeResponse(t *testing.T) {
+	t.Run("keeps known fields when the response adds fields", func(t *testing.T) {
+		text := `{"domains": [{"name": "Auth", "owner": "team-a", "features": [{"name": "Login", "test_indices": [0]}]}]}`
+
+		output, err := parsePhase1Response(context.Background(), prompt.Phase1SchemaVersion, text)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(output.Domains) != 1 || output.Domains[0].Features[0].Name != "Login" {
+			t.Errorf("known fields should be decoded, got %+v", output.Domains)
+		}
+	})
+
+	t.Run("rejects mistyped fields", func(t *testing.T) {
+		if _, err := parsePhase1Response(context.Background(), prompt.Phase1SchemaVersion, `{"domains": "Auth"}`); err == nil {
+			t.Error("expected error for a mistyped field")
+		}
+	})
+
+	t.Run("rejects unknown schema versions", func(t *testing.T) {
+		if _, err := parsePhase1Response(context.Background(), "phase1.v0", `{"domains": []}`); err == nil {
+			t.Error("expected error for an unknown schema version")
+		}
+	})
+}
+
+func TestPhase1Decoders_CurrentVersion(t *testing.T) {
+	if _, ok := phase1Decoders[prompt.Phase1SchemaVersion]; !ok {
+		t.Errorf("no decoder registered for prompt schema %q", prompt.Phase1SchemaVersion)
+	}
+}
+
+func TestUnknownField(t *testing.T) {
+	field, ok := unknownField(errors.New(`json: unknown field "sections"`))
+	if !ok || field != "sections" {
+		t.Errorf("unknownField() = %q, %v, want sections, true", field, ok)
+	}
+	if _, ok := unknownField(errors.New("unexpected end of JSON input")); ok {
+		t.Error("other errors should not be unknown fields")
+	}
+}
+
+func TestClassifyDomainsSingle_QuarantinesUnparseableOutput(t *testing.T) {
+	cache := NewResponseCache(time.Minute, 0)
+	quarantine := &recordingQuarantine{}
+	p := NewProvider(&fakeBackend{text: `{"domains": {"name": "Auth"}}`}, "m1", "m2")
+	p.phase1Retry = reliability.NewRetryer(reliability.RetryConfig{MaxAttempts: 1})
+	p.SetQuarantine(quarantine)
+	p.SetResponseCache(cache)
+
+	input := specview.Phase1Input{Files: []specview.FileInfo{{Path: "a_test.go", Tests: []specview.TestInfo{{Index: 0, Name: "TestA"}}}}}
+	ctx := specview.WithResponseCacheScope(context.Background(), "public")
+	if _, _, err := p.classifyDomainsSingle(ctx, input, "English", nil); err == nil {
+		t.Fatal("expected parse failure")
+	}
+
+	if len(quarantine.outputs) != 1 {
+		t.Fatalf("quarantined %d outputs, want 1", len(quarantine.outputs))
+	}
+	got := quarantine.outputs[0]
+	if got.Phase != "phase1" || got.SchemaVersion != prompt.Phase1SchemaVersion || got.ModelID != "m1" || got.Response == "" || got.Error == "" {
+		t.Errorf("unexpected quarantined output: %+v", got)
+	}
+	if len(cache.entries) != 0 {
+		t.Error("unparseable response should be dropped from the response cache")
+	}
+}
+
+func TestProvider_QuarantinesUnparseableOutputOfEveryPhase(t *testing.T) {
+	structure := &specview.Phase1Output{Domains: []specview.DomainGroup{{
+		Name:     "Auth",
+		Features: []specview.FeatureGroup{{Name: "Login", TestIndices: []int{0}}},
+	}}}
+	calls := []struct {
+		phase         string
+		schemaVersion string
+		call          func(ctx context.Context, p *Provider) error
+	}{
+		{"phase2", prompt.Phase2SchemaVersion, func(ctx context.Context, p *Provider) error {
+			_, _, err := p.convertTestNames(ctx, specview.Phase2Input{Tests: []specview.TestForConversion{{Index: 0, Name: "TestA"}}}, "English")
+			return err
+		}},
+		{"phase3", prompt.Phase3SchemaVersion, func(ctx context.Context, p *Provider) error {
+			_, _, err := p.generateSummary(ctx, specview.Phase3Input{Domains: []specview.Domain{{Name: "Auth"}}, Language: "English"})
+			return err
+		}},
+		{"placement", prompt.PlacementSchemaVersion, func(ctx context.Context, p *Provider) error {
+			_, _, err := p.placeNewTests(ctx, specview.PlacementInput{
+				ExistingStructure: structure,
+				Language:          "English",
+				NewTests:          []specview.TestInfo{{Index: 1, Name: "TestB"}},
+			})
+			return err
+		}},
+		{"translation", prompt.TranslationSchemaVersion, func(ctx context.Context, p *Provider) error {
+			_, _, err := p.translateTexts(ctx, specview.TranslationInput{SourceLanguage: "English", TargetLanguage: "Korean", Texts: []string{"Logs in"}})
+			return err
+		}},
+	}
+
+	for _, tc := range calls {
+		t.Run(tc.phase, func(t *testing.T) {
+			quarantine := &recordingQuarantine{}
+			p := NewProvider(&fakeBackend{text: `["not", "an", "object"]`}, "m1", "m2")
+			p.phase1Retry = reliability.NewRetryer(reliability.RetryConfig{MaxAttempts: 1})
+			p.phase2Retry = reliability.NewRetryer(reliability.RetryConfig{MaxAttempts: 1})
+			p.SetQuarantine(quarantine)
+
+			if err := tc.call(context.Background(), p); err == nil {
+				t.Fatal("expected parse failure")
+			}
+			if len(quarantine.outputs) != 1 {
+				t.Fatalf("quarantined %d outputs, want 1", len(quarantine.outputs))
+			}
+			if got := quarantine.outputs[0]; got.Phase != tc.phase || got.SchemaVersion != tc.schemaVersion || got.Response == "" {
+				t.Errorf("unexpected quarantined output: %+v", got)
+			}
+		})
+	}
+}
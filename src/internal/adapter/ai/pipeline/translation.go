@@ -0,0 +1,98 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/specvital/worker/internal/adapter/ai/prompt"
+	"github.com/specvital/worker/internal/adapter/ai/reliability"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// translationResponse represents the expected JSON response from a translation call
+// under schema translation.v1.
+type translationResponse struct {
+	Translations []translationItem `json:"translations"`
+}
+
+type translationItem struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+}
+
+// translateTexts translates document texts into the target language.
+// Uses Phase 2 model, circuit breaker and retry since it's a per-text
+// generation task like behavior conversion.
+func (p *Provider) translateTexts(ctx context.Context, input specview.TranslationInput) (*specview.TranslationOutput, *specview.TokenUsage, error) {
+	if len(input.Texts) == 0 {
+		return &specview.TranslationOutput{Texts: []string{}}, nil, nil
+	}
+	if !input.TargetLanguage.IsValid() {
+		return nil, nil, fmt.Errorf("%w: target language is required for translation", specview.ErrInvalidInput)
+	}
+
+	systemPrompt := prompt.SystemPrompt(prompt.Translation, specview.PromptVersion(ctx))
+	userPrompt := prompt.BuildTranslationUserPrompt(input)
+
+	var output *specview.TranslationOutput
+	var usage *specview.TokenUsage
+
+	err := p.phase2Retry.Do(ctx, func() error {
+		result, innerUsage, innerErr := p.generateContent(ctx, p.phase2Model, systemPrompt, userPrompt, p.phase2CB)
+		if innerErr != nil {
+			return innerErr
+		}
+		usage = innerUsage
+
+		var parseErr error
+		output, parseErr = parseTranslationResponse(ctx, result, len(input.Texts))
+		if parseErr != nil {
+			slog.WarnContext(ctx, "failed to parse translation response, will retry",
+				"error", parseErr,
+				"response", truncateForLog(result, 500),
+			)
+			p.quarantineOutput(ctx, "translation", prompt.TranslationSchemaVersion, p.phase2Model, systemPrompt, userPrompt, result, parseErr)
+			return &reliability.RetryableError{Err: parseErr}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("translation failed: %w", err)
+	}
+
+	return output, usage, nil
+}
+
+// parseTranslationResponse parses the JSON response into TranslationOutput,
+// ordered by index. Every index below expectedCount must be translated once.
+func parseTranslationResponse(ctx context.Context, jsonStr string, expectedCount int) (*specview.TranslationOutput, error) {
+	resp, err := decodeResponse[translationResponse](ctx, "translation", prompt.TranslationSchemaVersion, jsonStr)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, expectedCount)
+	seenIndices := make(map[int]bool)
+	for _, t := range resp.Translations {
+		if t.Index < 0 || t.Index >= expectedCount {
+			return nil, fmt.Errorf("invalid index %d (expected 0-%d)", t.Index, expectedCount-1)
+		}
+		if seenIndices[t.Index] {
+			return nil, fmt.Errorf("duplicate index %d", t.Index)
+		}
+		seenIndices[t.Index] = true
+
+		if t.Text == "" {
+			return nil, fmt.Errorf("empty text for index %d", t.Index)
+		}
+		texts[t.Index] = t.Text
+	}
+
+	if len(seenIndices) != expectedCount {
+		return nil, fmt.Errorf("translation count mismatch: got %d, expected %d", len(seenIndices), expectedCount)
+	}
+
+	return &specview.TranslationOutput{Texts: texts}, nil
+}
@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseTranslationResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		json          string
+		expectedCount int
+		want          []string
+		errContains   string
+	}{
+		{
+			name:          "orders texts by index",
+			json:          `{"translations": [{"index": 1, "text": "로그인"}, {"index": 0, "text": "인증"}]}`,
+			expectedCount: 2,
+			want:          []string{"인증", "로그인"},
+		},
+		{
+			name:          "invalid json",
+			json:          `{invalid}`,
+			expectedCount: 1,
+			errContains:   "json unmarshal",
+		},
+		{
+			name:          "index out of range",
+			json:          `{"translations": [{"index": 2, "text": "인증"}]}`,
+			expectedCount: 1,
+			errContains:   "invalid index",
+		},
+		{
+			name:          "duplicate index",
+			json:          `{"translations": [{"index": 0, "text": "인증"}, {"index": 0, "text": "로그인"}]}`,
+			expectedCount: 2,
+			errContains:   "duplicate index",
+		},
+		{
+			name:          "empty text",
+			json:          `{"translations": [{"index": 0, "text": ""}]}`,
+			expectedCount: 1,
+			errContains:   "empty text",
+		},
+		{
+			name:          "missing text",
+			json:          `{"translations": [{"index": 0, "text": "인증"}]}`,
+			expectedCount: 2,
+			errContains:   "translation count mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := parseTranslationResponse(context.Background(), tt.json, tt.expectedCount)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(output.Texts, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected %v, got %v", tt.want, output.Texts)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AppendCorrection adds a correction note to a user prompt whose previous
+// response was rejected, so the model is told what to fix instead of being
+// asked the same question again.
+func AppendCorrection(userPrompt, problem string) string {
+	var sb strings.Builder
+	sb.WriteString(userPrompt)
+	sb.WriteString("\n\n## Correction\n\n")
+	fmt.Fprintf(&sb, "Your previous response was rejected: %s\n", problem)
+	sb.WriteString("Answer again with valid JSON in the required format. ")
+	sb.WriteString("Use only the test indices listed above, place each test once and give every entry a non-empty name or description.\n")
+	return sb.String()
+}
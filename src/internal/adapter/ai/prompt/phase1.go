@@ -11,6 +11,11 @@ import (
 //go:embed templates/phase1_system.md
 var Phase1SystemPrompt string
 
+// Phase1SchemaVersion names the response format Phase1SystemPrompt asks for.
+// Bump it with any template change to the JSON fields, and register a decoder
+// for the new version in the provider.
+const Phase1SchemaVersion = "phase1.v1"
+
 // BuildPhase1UserPrompt builds the user prompt for Phase 1 classification.
 func BuildPhase1UserPrompt(input specview.Phase1Input, language specview.Language) string {
 	var sb strings.Builder
@@ -56,8 +61,8 @@ func BuildPhase1UserPrompt(input specview.Phase1Input, language specview.Languag
 }
 
 // BuildPhase1UserPromptWithAnchors builds the user prompt for Phase 1 classification
-// with anchor domains from previous chunks. This ensures domain naming consistency
-// across chunks.
+// with anchor domains from the repository taxonomy or previous chunks. This ensures
+// domain naming consistency across chunks.
 func BuildPhase1UserPromptWithAnchors(input specview.Phase1Input, language specview.Language, anchors []specview.DomainGroup) string {
 	var sb strings.Builder
 
@@ -65,9 +70,15 @@ func BuildPhase1UserPromptWithAnchors(input specview.Phase1Input, language specv
 	sb.WriteString(fmt.Sprintf("Target Language: %s\n\n", language))
 
 	// Add anchor domains section
-	sb.WriteString("## Existing Domains (MUST reuse if applicable)\n\n")
-	sb.WriteString("The following domains were identified from previous test batches. ")
-	sb.WriteString("You MUST reuse these domain names exactly if the new tests belong to the same business area.\n\n")
+	if input.Constrained {
+		sb.WriteString("## Allowed Domains (MUST use ONLY these)\n\n")
+		sb.WriteString("The following domains were defined by the codebase maintainers and are the ONLY domains allowed. ")
+		sb.WriteString("Do NOT create any other domain.\n\n")
+	} else {
+		sb.WriteString("## Existing Domains (MUST reuse if applicable)\n\n")
+		sb.WriteString("The following domains were defined by the repository maintainers or identified from previous test batches. ")
+		sb.WriteString("You MUST reuse these domain names exactly if the new tests belong to the same business area.\n\n")
+	}
 	sb.WriteString("<anchor_domains>\n")
 	for _, domain := range anchors {
 		sb.WriteString(fmt.Sprintf("- **%s**: %s\n", domain.Name, domain.Description))
@@ -84,9 +95,15 @@ func BuildPhase1UserPromptWithAnchors(input specview.Phase1Input, language specv
 	sb.WriteString("</anchor_domains>\n\n")
 
 	sb.WriteString("## Rules\n")
-	sb.WriteString("1. If a test matches an existing domain, you MUST use that exact domain name\n")
-	sb.WriteString("2. Only create a NEW domain if the test covers a completely new business area\n")
-	sb.WriteString("3. Feature names can be new even within existing domains\n\n")
+	if input.Constrained {
+		sb.WriteString("1. Every domain name MUST be one of the allowed domain names, written exactly as listed\n")
+		sb.WriteString("2. If no allowed domain fits a test, use the domain \"Uncategorized\"\n")
+		sb.WriteString("3. Feature names can be new within the allowed domains\n\n")
+	} else {
+		sb.WriteString("1. If a test matches an existing domain, you MUST use that exact domain name\n")
+		sb.WriteString("2. Only create a NEW domain if the test covers a completely new business area\n")
+		sb.WriteString("3. Feature names can be new even within existing domains\n\n")
+	}
 
 	sb.WriteString("<files>\n")
 
@@ -143,6 +143,35 @@ func TestBuildPhase1UserPrompt_LanguageVariants(t *testing.T) {
 	}
 }
 
+func TestBuildPhase1UserPromptWithAnchors_Constrained(t *testing.T) {
+	input := specview.Phase1Input{
+		Files: []specview.FileInfo{
+			{
+				Path:  "test.go",
+				Tests: []specview.TestInfo{{Index: 0, Name: "TestCharge"}},
+			},
+		},
+	}
+	anchors := []specview.DomainGroup{{Name: "Payments", Description: "Charges (also known as Billing)"}}
+
+	prompt := BuildPhase1UserPromptWithAnchors(input, "English", anchors)
+	if !strings.Contains(prompt, "MUST reuse if applicable") || strings.Contains(prompt, "MUST use ONLY these") {
+		t.Error("unconstrained prompt should allow new domains")
+	}
+
+	input.Constrained = true
+	prompt = BuildPhase1UserPromptWithAnchors(input, "English", anchors)
+	if !strings.Contains(prompt, "## Allowed Domains (MUST use ONLY these)") {
+		t.Error("constrained prompt should list the allowed domains")
+	}
+	if !strings.Contains(prompt, "- **Payments**: Charges (also known as Billing)") {
+		t.Error("constrained prompt should contain the taxonomy domain")
+	}
+	if !strings.Contains(prompt, `use the domain "Uncategorized"`) {
+		t.Error("constrained prompt should name Uncategorized as the fallback")
+	}
+}
+
 func TestPhase1SystemPrompt_ContainsRequiredSections(t *testing.T) {
 	requiredSections := []string{
 		"Constraints",
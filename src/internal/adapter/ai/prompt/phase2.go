@@ -11,6 +11,16 @@ import (
 //go:embed templates/phase2_system.md
 var Phase2SystemPrompt string
 
+// Phase2SchemaVersion names the response format Phase2SystemPrompt asks for.
+// Bump it with any template change to the JSON fields.
+const Phase2SchemaVersion = "phase2.v1"
+
+// styleGuides maps repository-configured description styles to prompt instructions.
+var styleGuides = map[string]string{
+	"concise":  "Keep each description under 8 words; drop qualifiers and examples",
+	"detailed": "Write a full sentence per test, including the condition and expected outcome",
+}
+
 // BuildPhase2UserPrompt builds the user prompt for Phase 2 conversion.
 // Returns the prompt string and index mapping (0-based index → original index).
 // AI receives 0-based indices matching the system prompt example format.
@@ -42,8 +52,12 @@ func BuildPhase2UserPrompt(input specview.Phase2Input, language specview.Languag
 	sb.WriteString("Context:\n")
 	sb.WriteString(fmt.Sprintf("- Domain: %s\n", input.DomainContext))
 	sb.WriteString(fmt.Sprintf("- Feature: %s\n", input.FeatureName))
-	sb.WriteString(fmt.Sprintf("- Target Language: %s (ALL output MUST be in this language)\n\n", language))
-	sb.WriteString("<tests>\n")
+	sb.WriteString(fmt.Sprintf("- Target Language: %s (ALL output MUST be in this language)\n", language))
+	if guide := styleGuides[input.Style]; guide != "" {
+		sb.WriteString(fmt.Sprintf("- Style: %s\n", guide))
+	}
+	writeGlossary(&sb, input.Glossary)
+	sb.WriteString("\n<tests>\n")
 
 	// Build index mapping: position in slice → original test index
 	indexMapping := make([]int, len(input.Tests))
@@ -58,3 +72,22 @@ func BuildPhase2UserPrompt(input specview.Phase2Input, language specview.Languag
 
 	return sb.String(), indexMapping
 }
+
+// writeGlossary lists the terms the descriptions must use. Entries are
+// written in glossary order, so the prompt, like the cache key, depends only
+// on the glossary's content. Entries that are not Valid are left out, since
+// they could break the table or the block.
+func writeGlossary(sb *strings.Builder, glossary *specview.Glossary) {
+	if glossary == nil || len(glossary.Entries) == 0 {
+		return
+	}
+	sb.WriteString("\n<glossary>\n")
+	for _, e := range glossary.Entries {
+		if !e.Valid() {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s|%s\n", e.Term, e.Preferred))
+	}
+	sb.WriteString("</glossary>\n")
+	sb.WriteString("When a test refers to a glossary term (left), write the preferred wording (right) instead of translating it yourself.\n")
+}
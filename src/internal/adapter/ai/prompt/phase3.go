@@ -11,6 +11,10 @@ import (
 //go:embed templates/phase3_system.md
 var Phase3SystemPrompt string
 
+// Phase3SchemaVersion names the response format Phase3SystemPrompt asks for.
+// Bump it with any template change to the JSON fields.
+const Phase3SchemaVersion = "phase3.v1"
+
 // BuildPhase3UserPrompt builds the user prompt for Phase 3 executive summary generation.
 func BuildPhase3UserPrompt(input specview.Phase3Input) string {
 	var sb strings.Builder
@@ -11,6 +11,10 @@ import (
 //go:embed templates/placement_system.md
 var PlacementSystemPrompt string
 
+// PlacementSchemaVersion names the response format PlacementSystemPrompt asks for.
+// Bump it with any template change to the JSON fields.
+const PlacementSchemaVersion = "placement.v1"
+
 // BuildPlacementUserPrompt builds the user prompt for new test placement.
 // Converts existing domain/feature structure and new tests to a compact format
 // optimized for minimal token usage (~300-500 tokens for typical case).
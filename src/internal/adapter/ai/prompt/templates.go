@@ -0,0 +1,129 @@
+package prompt
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// Phases with a system prompt.
+const (
+	Phase1      = "phase1"
+	Phase2      = "phase2"
+	Phase3      = "phase3"
+	Placement   = "placement"
+	Translation = "translation"
+)
+
+// candidatesDir holds the candidate prompt versions, one directory per
+// version with the system prompts it changes, e.g.
+// templates/candidates/v2/phase1_system.md. Phases a candidate leaves out use
+// the default prompt. Candidates must keep the response schema of the
+// default prompts.
+const candidatesDir = "templates/candidates"
+
+//go:embed templates
+var templateFS embed.FS
+
+var candidates, candidatesErr = loadCandidates(templateFS)
+
+// defaultSystemPrompt returns the default version's system prompt of phase.
+func defaultSystemPrompt(phase string) (string, bool) {
+	switch phase {
+	case Phase1:
+		return Phase1SystemPrompt, true
+	case Phase2:
+		return Phase2SystemPrompt, true
+	case Phase3:
+		return Phase3SystemPrompt, true
+	case Placement:
+		return PlacementSystemPrompt, true
+	case Translation:
+		return TranslationSystemPrompt, true
+	default:
+		return "", false
+	}
+}
+
+// loadCandidates reads the candidate prompts of fsys by version and phase.
+func loadCandidates(fsys fs.FS) (map[string]map[string]string, error) {
+	versions := make(map[string]map[string]string)
+	entries, err := fs.ReadDir(fsys, candidatesDir)
+	if err != nil {
+		return versions, nil // no candidates
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		version := entry.Name()
+		if version == specview.DefaultPromptVersion {
+			return nil, fmt.Errorf("candidate prompt version %q is the default version", version)
+		}
+		files, err := fs.ReadDir(fsys, path.Join(candidatesDir, version))
+		if err != nil {
+			return nil, fmt.Errorf("read candidate prompt %s: %w", version, err)
+		}
+		prompts := make(map[string]string, len(files))
+		for _, file := range files {
+			phase, ok := strings.CutSuffix(file.Name(), "_system.md")
+			if _, known := defaultSystemPrompt(phase); !ok || !known {
+				return nil, fmt.Errorf("candidate prompt %s: unexpected file %s", version, file.Name())
+			}
+			text, err := fs.ReadFile(fsys, path.Join(candidatesDir, version, file.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("read candidate prompt %s: %w", version, err)
+			}
+			prompts[phase] = string(text)
+		}
+		versions[version] = prompts
+	}
+	return versions, nil
+}
+
+// SystemPrompt returns the system prompt of phase at version. Versions that
+// do not change the phase's prompt, including unknown ones, get the default.
+func SystemPrompt(phase, version string) string {
+	if text, ok := candidates[version][phase]; ok {
+		return text
+	}
+	text, _ := defaultSystemPrompt(phase)
+	return text
+}
+
+// Versions lists the known prompt versions, the default first.
+func Versions() []string {
+	versions := []string{specview.DefaultPromptVersion}
+	for version := range candidates {
+		versions = append(versions, version)
+	}
+	slices.Sort(versions[1:])
+	return versions
+}
+
+// HasVersion reports whether version is a known prompt version.
+func HasVersion(version string) bool {
+	_, ok := candidates[version]
+	return ok || version == specview.DefaultPromptVersion
+}
+
+// CheckTemplates verifies every embedded system prompt is present, so a broken
+// build fails at startup instead of on the first generation job.
+func CheckTemplates() error {
+	if candidatesErr != nil {
+		return candidatesErr
+	}
+	for _, version := range Versions() {
+		for _, phase := range []string{Phase1, Phase2, Phase3, Placement, Translation} {
+			if strings.TrimSpace(SystemPrompt(phase, version)) == "" {
+				return fmt.Errorf("%s system prompt of version %s is empty", phase, version)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package prompt
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheckTemplates(t *testing.T) {
+	if err := CheckTemplates(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	orig := Phase3SystemPrompt
+	t.Cleanup(func() { Phase3SystemPrompt = orig })
+	Phase3SystemPrompt = "  \n"
+
+	if err := CheckTemplates(); err == nil {
+		t.Error("expected error for empty template")
+	}
+}
+
+func TestLoadCandidates(t *testing.T) {
+	t.Run("reads candidate prompts by version and phase", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"templates/candidates/v2/phase1_system.md": {Data: []byte("candidate phase 1")},
+			"templates/candidates/v2/phase3_system.md": {Data: []byte("candidate phase 3")},
+		}
+		got, err := loadCandidates(fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 1 || got["v2"][Phase1] != "candidate phase 1" || got["v2"][Phase3] != "candidate phase 3" {
+			t.Errorf("unexpected candidates %v", got)
+		}
+	})
+
+	t.Run("no candidates directory", func(t *testing.T) {
+		got, err := loadCandidates(fstest.MapFS{})
+		if err != nil || len(got) != 0 {
+			t.Errorf("expected no candidates, got %v, %v", got, err)
+		}
+	})
+
+	t.Run("rejects unknown phases", func(t *testing.T) {
+		fsys := fstest.MapFS{"templates/candidates/v2/phase4_system.md": {Data: []byte("x")}}
+		if _, err := loadCandidates(fsys); err == nil {
+			t.Error("expected error for unknown phase")
+		}
+	})
+
+	t.Run("rejects the default version", func(t *testing.T) {
+		fsys := fstest.MapFS{"templates/candidates/v1/phase1_system.md": {Data: []byte("x")}}
+		if _, err := loadCandidates(fsys); err == nil {
+			t.Error("expected error for a candidate named like the default version")
+		}
+	})
+}
+
+func TestSystemPrompt(t *testing.T) {
+	orig := candidates
+	t.Cleanup(func() { candidates = orig })
+	candidates = map[string]map[string]string{"v2": {Phase1: "candidate phase 1"}}
+
+	if got := SystemPrompt(Phase1, "v2"); got != "candidate phase 1" {
+		t.Errorf("expected the candidate prompt, got %q", got)
+	}
+	if got := SystemPrompt(Phase2, "v2"); got != Phase2SystemPrompt {
+		t.Error("expected phases the candidate leaves out to use the default prompt")
+	}
+	if got := SystemPrompt(Phase1, "v9"); got != Phase1SystemPrompt {
+		t.Error("expected unknown versions to use the default prompt")
+	}
+	if !HasVersion("v1") || !HasVersion("v2") || HasVersion("v9") {
+		t.Errorf("unexpected known versions %v", Versions())
+	}
+}
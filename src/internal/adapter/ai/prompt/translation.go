@@ -0,0 +1,36 @@
+package prompt
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+//go:embed templates/translation_system.md
+var TranslationSystemPrompt string
+
+// TranslationSchemaVersion names the response format TranslationSystemPrompt asks for.
+// Bump it with any template change to the JSON fields.
+const TranslationSchemaVersion = "translation.v1"
+
+// BuildTranslationUserPrompt builds the user prompt for translating document
+// texts. Texts are numbered by their position in input.Texts, one per line.
+func BuildTranslationUserPrompt(input specview.TranslationInput) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Source Language: %s\n", input.SourceLanguage)
+	fmt.Fprintf(&sb, "Target Language: %s\n", input.TargetLanguage)
+	writeGlossary(&sb, input.Glossary)
+
+	sb.WriteString("\n<texts>\n")
+	for i, text := range input.Texts {
+		fmt.Fprintf(&sb, "%d|%s\n", i, strings.ReplaceAll(text, "\n", " "))
+	}
+	sb.WriteString("</texts>\n\n")
+
+	fmt.Fprintf(&sb, "Translate %d texts.", len(input.Texts))
+
+	return sb.String()
+}
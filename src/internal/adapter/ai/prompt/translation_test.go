@@ -0,0 +1,31 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestBuildTranslationUserPrompt(t *testing.T) {
+	input := specview.TranslationInput{
+		Glossary:       &specview.Glossary{Entries: []specview.GlossaryEntry{{Preferred: "정산", Term: "settlement"}}},
+		SourceLanguage: "English",
+		TargetLanguage: "Korean",
+		Texts:          []string{"Authentication", "Covers login.\nAnd payments."},
+	}
+
+	result := BuildTranslationUserPrompt(input)
+
+	for _, want := range []string{
+		"Source Language: English\n",
+		"Target Language: Korean\n",
+		"<glossary>\nsettlement|정산\n</glossary>",
+		"<texts>\n0|Authentication\n1|Covers login. And payments.\n</texts>",
+		"Translate 2 texts.",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("prompt should contain %q, got:\n%s", want, result)
+		}
+	}
+}
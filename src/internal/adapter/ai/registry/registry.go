@@ -0,0 +1,227 @@
+// Package registry resolves a configured AI vendor name to a specview.AIProvider.
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/ai/anthropic"
+	"github.com/specvital/worker/internal/adapter/ai/gemini"
+	"github.com/specvital/worker/internal/adapter/ai/llm"
+	"github.com/specvital/worker/internal/adapter/ai/mock"
+	"github.com/specvital/worker/internal/adapter/ai/openai"
+	"github.com/specvital/worker/internal/adapter/ai/pipeline"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+const (
+	ProviderAnthropic   = "anthropic"
+	ProviderAzureOpenAI = "azure-openai"
+	ProviderGemini      = "gemini"
+	ProviderMock        = "mock"
+	ProviderOpenAI      = "openai"
+)
+
+var ErrUnknownProvider = errors.New("unknown AI provider")
+
+// Config is the vendor-neutral provider configuration.
+// Empty model fields fall back to the registration defaults.
+type Config struct {
+	APIKey      string
+	APIVersion  string // Anthropic version header or Azure api-version
+	BaseURL     string // API root override; Azure resource endpoint
+	Phase1Model string
+	Phase2Model string
+	Quarantine  specview.OutputQuarantine // stores unparseable responses; ignored by the mock provider
+
+	Phase1Fallbacks []string // models tried when the Phase 1 model is rate limited or unavailable; ignored by the mock provider
+	Phase2Fallbacks []string // models tried when the Phase 2 model is rate limited or unavailable; ignored by the mock provider
+
+	ResponseCacheMaxEntries int           // Gemini only
+	ResponseCacheTTL        time.Duration // Gemini only; zero disables the response cache
+
+	BatchMinTests int           // Phase 1 inputs of at least this many tests use the Message Batches API, zero disables
+	BatchMaxWait  time.Duration // how long a batch may run before it is cancelled
+
+	BatchFallbackAPIKey string // Gemini only; Anthropic key of the batch rate limited Phase 1 inputs fall back to
+	BatchFallbackModel  string // Gemini only; Anthropic model of the fallback batch
+}
+
+// Factory builds a provider from a config whose models are already resolved.
+type Factory func(ctx context.Context, cfg Config) (specview.AIProvider, error)
+
+// Registration describes one provider.
+type Registration struct {
+	DefaultPhase1Model string
+	DefaultPhase2Model string
+	Factory            Factory
+}
+
+// Registry maps provider names to registrations.
+type Registry struct {
+	entries map[string]Registration
+}
+
+// New creates an empty registry.
+func New() *Registry {
+	return &Registry{entries: make(map[string]Registration)}
+}
+
+// Default returns a registry with every built-in provider.
+func Default() *Registry {
+	r := New()
+	r.Register(ProviderGemini, Registration{
+		DefaultPhase1Model: "gemini-2.5-flash",
+		DefaultPhase2Model: "gemini-2.5-flash-lite",
+		Factory: func(ctx context.Context, cfg Config) (specview.AIProvider, error) {
+			provider, err := gemini.NewProvider(ctx, gemini.Config{
+				APIKey:                  cfg.APIKey,
+				Phase1Model:             cfg.Phase1Model,
+				Phase2Model:             cfg.Phase2Model,
+				Phase1Fallbacks:         cfg.Phase1Fallbacks,
+				Phase2Fallbacks:         cfg.Phase2Fallbacks,
+				Quarantine:              cfg.Quarantine,
+				ResponseCacheMaxEntries: cfg.ResponseCacheMaxEntries,
+				ResponseCacheTTL:        cfg.ResponseCacheTTL,
+			})
+			if err != nil {
+				return nil, err
+			}
+			// Large inputs rejected for Gemini quota go to the Anthropic batch API.
+			if cfg.BatchMinTests > 0 && cfg.BatchFallbackAPIKey != "" {
+				batch, err := anthropic.NewBatchProvider(anthropic.Config{APIKey: cfg.BatchFallbackAPIKey}, cfg.BatchFallbackModel)
+				if err != nil {
+					return nil, err
+				}
+				provider.SetBatchFallback(batch, cfg.BatchMinTests, cfg.BatchMaxWait)
+			}
+			return provider, nil
+		},
+	})
+	r.Register(ProviderOpenAI, Registration{
+		DefaultPhase1Model: openai.DefaultPhase1Model,
+		DefaultPhase2Model: openai.DefaultPhase2Model,
+		Factory:            openAIFactory(false),
+	})
+	// Azure has no default models: deployments are named per resource.
+	r.Register(ProviderAzureOpenAI, Registration{
+		Factory: openAIFactory(true),
+	})
+	r.Register(ProviderAnthropic, Registration{
+		DefaultPhase1Model: anthropic.DefaultPhase1Model,
+		DefaultPhase2Model: anthropic.DefaultPhase2Model,
+		Factory: func(_ context.Context, cfg Config) (specview.AIProvider, error) {
+			config := anthropic.Config{
+				APIKey:     cfg.APIKey,
+				APIVersion: cfg.APIVersion,
+				BaseURL:    cfg.BaseURL,
+			}
+			backend, err := anthropic.NewBackend(config)
+			if err != nil {
+				return nil, err
+			}
+			provider := withPipeline(backend, cfg)
+			if cfg.BatchMinTests > 0 {
+				batch, err := anthropic.NewBatchProvider(config, cfg.Phase1Model)
+				if err != nil {
+					return nil, err
+				}
+				provider.SetBatchClassifier(batch, cfg.BatchMinTests, cfg.BatchMaxWait)
+			}
+			return provider, nil
+		},
+	})
+	r.Register(ProviderMock, Registration{
+		DefaultPhase1Model: "mock-model",
+		DefaultPhase2Model: "mock-model",
+		Factory: func(_ context.Context, _ Config) (specview.AIProvider, error) {
+			return mock.NewProvider(), nil
+		},
+	})
+	return r
+}
+
+// Register adds or replaces a provider registration.
+func (r *Registry) Register(name string, reg Registration) {
+	r.entries[strings.ToLower(name)] = reg
+}
+
+// Names returns registered provider names in sorted order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Create resolves per-phase models and builds the named provider.
+// The returned model ID is the resolved Phase 1 model, recorded on generated documents.
+func (r *Registry) Create(ctx context.Context, name string, cfg Config) (specview.AIProvider, string, error) {
+	reg, ok := r.entries[strings.ToLower(name)]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %q (available: %s)", ErrUnknownProvider, name, strings.Join(r.Names(), ", "))
+	}
+
+	if cfg.Phase1Model == "" {
+		cfg.Phase1Model = reg.DefaultPhase1Model
+	}
+	if cfg.Phase2Model == "" {
+		cfg.Phase2Model = reg.DefaultPhase2Model
+	}
+	if cfg.Phase1Model == "" || cfg.Phase2Model == "" {
+		return nil, "", fmt.Errorf("provider %s requires explicit phase 1 and phase 2 models", name)
+	}
+
+	provider, err := reg.Factory(ctx, cfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("create %s provider: %w", name, err)
+	}
+	return provider, cfg.Phase1Model, nil
+}
+
+// ModelID returns the model ID Create records on documents for the named
+// provider, without building the provider. Processes that only read the
+// caches use it to compute the generator's cache keys.
+func (r *Registry) ModelID(name, phase1Model string) (string, error) {
+	reg, ok := r.entries[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("%w: %q (available: %s)", ErrUnknownProvider, name, strings.Join(r.Names(), ", "))
+	}
+	if phase1Model != "" {
+		return phase1Model, nil
+	}
+	if reg.DefaultPhase1Model == "" {
+		return "", fmt.Errorf("provider %s requires an explicit phase 1 model", name)
+	}
+	return reg.DefaultPhase1Model, nil
+}
+
+func openAIFactory(azure bool) Factory {
+	return func(_ context.Context, cfg Config) (specview.AIProvider, error) {
+		backend, err := openai.NewBackend(openai.Config{
+			APIKey:     cfg.APIKey,
+			APIVersion: cfg.APIVersion,
+			Azure:      azure,
+			BaseURL:    cfg.BaseURL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return withPipeline(backend, cfg), nil
+	}
+}
+
+// withPipeline wraps a raw backend with the shared phase pipeline
+// (prompts, chunking, circuit breakers, retries).
+func withPipeline(backend llm.Backend, cfg Config) *pipeline.Provider {
+	provider := pipeline.NewProvider(backend, cfg.Phase1Model, cfg.Phase2Model)
+	provider.SetQuarantine(cfg.Quarantine)
+	provider.SetFallbackModels(cfg.Phase1Fallbacks, cfg.Phase2Fallbacks)
+	return provider
+}
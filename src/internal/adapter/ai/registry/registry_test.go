@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/specvital/worker/internal/adapter/ai/mock"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestDefault_Names(t *testing.T) {
+	got := Default().Names()
+	want := []string{ProviderAnthropic, ProviderAzureOpenAI, ProviderGemini, ProviderMock, ProviderOpenAI}
+	if !slices.Equal(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistry_Create(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, _, err := Default().Create(ctx, "cohere", Config{})
+		if !errors.Is(err, ErrUnknownProvider) {
+			t.Errorf("expected ErrUnknownProvider, got %v", err)
+		}
+	})
+
+	t.Run("resolves default models", func(t *testing.T) {
+		var received Config
+		r := New()
+		r.Register("fake", Registration{
+			DefaultPhase1Model: "p1",
+			DefaultPhase2Model: "p2",
+			Factory: func(_ context.Context, cfg Config) (specview.AIProvider, error) {
+				received = cfg
+				return mock.NewProvider(), nil
+			},
+		})
+
+		_, modelID, err := r.Create(ctx, "FAKE", Config{Phase2Model: "override"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if modelID != "p1" {
+			t.Errorf("modelID = %q, want p1", modelID)
+		}
+		if received.Phase1Model != "p1" || received.Phase2Model != "override" {
+			t.Errorf("unexpected resolved config: %+v", received)
+		}
+	})
+
+	t.Run("azure requires explicit deployments", func(t *testing.T) {
+		_, _, err := Default().Create(ctx, ProviderAzureOpenAI, Config{APIKey: "k", BaseURL: "https://x.openai.azure.com"})
+		if err == nil {
+			t.Fatal("expected error when deployments are not configured")
+		}
+
+		_, modelID, err := Default().Create(ctx, ProviderAzureOpenAI, Config{
+			APIKey:      "k",
+			BaseURL:     "https://x.openai.azure.com",
+			Phase1Model: "classify",
+			Phase2Model: "convert",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if modelID != "classify" {
+			t.Errorf("modelID = %q, want classify", modelID)
+		}
+	})
+
+	t.Run("propagates factory errors", func(t *testing.T) {
+		_, _, err := Default().Create(ctx, ProviderOpenAI, Config{})
+		if err == nil {
+			t.Fatal("expected error for missing API key")
+		}
+	})
+
+	t.Run("mock needs no credentials", func(t *testing.T) {
+		_, modelID, err := Default().Create(ctx, ProviderMock, Config{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if modelID != "mock-model" {
+			t.Errorf("modelID = %q, want mock-model", modelID)
+		}
+	})
+}
+
+func TestRegistry_ModelID(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    string
+		phase1Model string
+		want        string
+		wantErr     bool
+	}{
+		{"provider default", ProviderGemini, "", "gemini-2.5-flash", false},
+		{"explicit model", ProviderGemini, "gemini-2.5-pro", "gemini-2.5-pro", false},
+		{"no default", ProviderAzureOpenAI, "", "", true},
+		{"unknown provider", "cohere", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Default().ModelID(tt.provider, tt.phase1Model)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ModelID() = %q, %v; want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -18,7 +18,7 @@ type RateLimiterConfig struct {
 	RPM         int // Requests per minute
 }
 
-// DefaultRateLimiterConfig returns the default global rate limiter config.
+// DefaultRateLimiterConfig returns the default rate limiter config of a backend.
 // Uses 90% of Gemini's free tier limit (2000 RPM for Flash).
 func DefaultRateLimiterConfig() RateLimiterConfig {
 	return RateLimiterConfig{
@@ -56,42 +56,37 @@ func (rl *RateLimiter) Available() float64 {
 	return float64(rl.limiter.Tokens())
 }
 
-// GlobalRateLimiter is a singleton rate limiter for all AI operations.
+// Rate limiters are kept per backend, so each vendor is throttled against its
+// own quota and providers of the same vendor share one.
 var (
-	globalLimiter   *RateLimiter
-	globalLimiterMu sync.RWMutex
+	limiters   = make(map[string]*RateLimiter)
+	limitersMu sync.Mutex
 )
 
-// GetGlobalRateLimiter returns the singleton rate limiter.
+// RateLimiterFor returns the rate limiter of the named backend.
 // Creates a new instance with default config if not set.
-func GetGlobalRateLimiter() *RateLimiter {
-	globalLimiterMu.RLock()
-	if globalLimiter != nil {
-		defer globalLimiterMu.RUnlock()
-		return globalLimiter
+func RateLimiterFor(backend string) *RateLimiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	rl, ok := limiters[backend]
+	if !ok {
+		rl = NewRateLimiter(DefaultRateLimiterConfig())
+		limiters[backend] = rl
 	}
-	globalLimiterMu.RUnlock()
-
-	globalLimiterMu.Lock()
-	defer globalLimiterMu.Unlock()
-	// Double-check after acquiring write lock
-	if globalLimiter == nil {
-		globalLimiter = NewRateLimiter(DefaultRateLimiterConfig())
-	}
-	return globalLimiter
+	return rl
 }
 
-// SetGlobalRateLimiter sets a custom global rate limiter (for testing).
-// Must be called before any GetGlobalRateLimiter calls in production.
-func SetGlobalRateLimiter(rl *RateLimiter) {
-	globalLimiterMu.Lock()
-	defer globalLimiterMu.Unlock()
-	globalLimiter = rl
+// SetRateLimiter sets a custom rate limiter for the named backend.
+// Must be called before any RateLimiterFor calls for it in production.
+func SetRateLimiter(backend string, rl *RateLimiter) {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	limiters[backend] = rl
 }
 
-// ResetGlobalRateLimiter resets the global rate limiter (for testing).
-func ResetGlobalRateLimiter() {
-	globalLimiterMu.Lock()
-	defer globalLimiterMu.Unlock()
-	globalLimiter = nil
+// ResetRateLimiters drops every backend's rate limiter (for testing).
+func ResetRateLimiters() {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	clear(limiters)
 }
@@ -132,13 +132,19 @@ func TestRateLimiter_DefaultConfig(t *testing.T) {
 	}
 }
 
-func TestGetGlobalRateLimiter_ReturnsSingleton(t *testing.T) {
-	t.Cleanup(ResetGlobalRateLimiter)
+func TestRateLimiterFor(t *testing.T) {
+	t.Cleanup(ResetRateLimiters)
 
-	rl1 := GetGlobalRateLimiter()
-	rl2 := GetGlobalRateLimiter()
+	if RateLimiterFor("gemini") != RateLimiterFor("gemini") {
+		t.Error("expected the same limiter for one backend")
+	}
+	if RateLimiterFor("gemini") == RateLimiterFor("anthropic") {
+		t.Error("expected each backend to have its own limiter")
+	}
 
-	if rl1 != rl2 {
-		t.Error("expected GetGlobalRateLimiter to return the same instance")
+	custom := NewRateLimiter(RateLimiterConfig{RPM: 60})
+	SetRateLimiter("openai", custom)
+	if RateLimiterFor("openai") != custom {
+		t.Error("expected the limiter set for the backend")
 	}
 }
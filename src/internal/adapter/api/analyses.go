@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/webhook"
+)
+
+// maxRequestBytes bounds enqueue request bodies.
+const maxRequestBytes = 64 << 10
+
+// IdempotencyKeyHeader carries the caller's key for retrying an enqueue safely.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type enqueueRequest struct {
+	Branch      string                `json:"branch"`
+	CommitSHA   string                `json:"commit_sha"`
+	Owner       string                `json:"owner"`
+	PathFilters *analysis.PathFilters `json:"path_filters"`
+	Repo        string                `json:"repo"`
+}
+
+type enqueueResponse struct {
+	Duplicate bool   `json:"duplicate,omitempty"`
+	JobID     int64  `json:"job_id,omitempty"`
+	Result    string `json:"result"`
+}
+
+// EnqueueHandler schedules an analysis of a commit. Callers resolve the commit
+// themselves, so the handler never touches the repository.
+// A request with an Idempotency-Key header is enqueued at most once per key;
+// retries get the job of the first request.
+type EnqueueHandler struct {
+	enqueuer           webhook.AnalysisEnqueuer
+	filteredEnqueuer   webhook.FilteredAnalysisEnqueuer
+	idempotentEnqueuer webhook.IdempotentAnalysisEnqueuer
+}
+
+// NewEnqueueHandler creates a handler scheduling analyses through enqueuer.
+func NewEnqueueHandler(enqueuer webhook.AnalysisEnqueuer) *EnqueueHandler {
+	h := &EnqueueHandler{enqueuer: enqueuer}
+	if filtered, ok := enqueuer.(webhook.FilteredAnalysisEnqueuer); ok {
+		h.filteredEnqueuer = filtered
+	}
+	if idempotent, ok := enqueuer.(webhook.IdempotentAnalysisEnqueuer); ok {
+		h.idempotentEnqueuer = idempotent
+	}
+	return h
+}
+
+func (h *EnqueueHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req enqueueRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	analyzeReq := analysis.AnalyzeRequest{
+		Branch:      req.Branch,
+		CommitSHA:   req.CommitSHA,
+		Owner:       req.Owner,
+		PathFilters: req.PathFilters,
+		Repo:        req.Repo,
+	}
+	if err := analyzeReq.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key := r.Header.Get(IdempotencyKeyHeader)
+	if len(key) > webhook.MaxIdempotencyKeyLength {
+		writeError(w, http.StatusBadRequest, "idempotency key too long")
+		return
+	}
+
+	var result webhook.EnqueueResult
+	var err error
+	switch {
+	case key != "" && h.idempotentEnqueuer == nil:
+		writeError(w, http.StatusBadRequest, "idempotency keys are not supported")
+		return
+	case key != "":
+		result, err = h.idempotentEnqueuer.EnqueueIdempotentAnalysis(r.Context(), key, req.Owner, req.Repo, req.Branch, req.CommitSHA, req.PathFilters)
+	case req.PathFilters == nil:
+		err = h.enqueuer.EnqueueBranchAnalysis(r.Context(), req.Owner, req.Repo, req.Branch, req.CommitSHA)
+	case h.filteredEnqueuer != nil:
+		err = h.filteredEnqueuer.EnqueueFilteredAnalysis(r.Context(), req.Owner, req.Repo, req.Branch, req.CommitSHA, req.PathFilters)
+	default:
+		writeError(w, http.StatusBadRequest, "path filters are not supported")
+		return
+	}
+	if errors.Is(err, webhook.ErrIdempotencyKeyReused) {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api enqueue failed",
+			"owner", req.Owner,
+			"repo", req.Repo,
+			"token", tokenName(r),
+			"error", err,
+		)
+		writeError(w, http.StatusInternalServerError, "failed to enqueue analysis")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "analysis enqueued via api",
+		"owner", req.Owner,
+		"repo", req.Repo,
+		"branch", req.Branch,
+		"commit", req.CommitSHA,
+		"duplicate", result.Duplicate,
+		"job_id", result.JobID,
+		"token", tokenName(r),
+	)
+	writeJSON(w, http.StatusAccepted, enqueueResponse{
+		Duplicate: result.Duplicate,
+		JobID:     result.JobID,
+		Result:    string(webhook.OutcomeEnqueued),
+	})
+}
+
+type idempotencyKeyResponse struct {
+	AnalysisID string `json:"analysis_id,omitempty"`
+	JobID      int64  `json:"job_id"`
+	JobState   string `json:"job_state,omitempty"`
+}
+
+// IdempotencyKeyHandler reports the job and analysis an idempotency key maps
+// to. It expects the key path value.
+type IdempotencyKeyHandler struct {
+	enqueuer webhook.IdempotentAnalysisEnqueuer
+}
+
+// NewIdempotencyKeyHandler creates a handler looking keys up through enqueuer.
+func NewIdempotencyKeyHandler(enqueuer webhook.IdempotentAnalysisEnqueuer) *IdempotencyKeyHandler {
+	return &IdempotencyKeyHandler{enqueuer: enqueuer}
+}
+
+func (h *IdempotencyKeyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	if key == "" || len(key) > webhook.MaxIdempotencyKeyLength {
+		writeError(w, http.StatusBadRequest, "invalid idempotency key")
+		return
+	}
+
+	result, err := h.enqueuer.LookupIdempotencyKey(r.Context(), key)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api idempotency key lookup failed",
+			"token", tokenName(r),
+			"error", err,
+		)
+		writeError(w, http.StatusInternalServerError, "failed to read idempotency key")
+		return
+	}
+	if result == nil {
+		writeError(w, http.StatusNotFound, "unknown idempotency key")
+		return
+	}
+
+	resp := idempotencyKeyResponse{JobID: result.JobID, JobState: result.JobState}
+	if result.AnalysisID != nil {
+		resp.AnalysisID = result.AnalysisID.String()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type statusResponse struct {
+	AnalysisID   string     `json:"analysis_id"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	FailureClass string     `json:"failure_class,omitempty"`
+	Status       string     `json:"status"`
+}
+
+// StatusHandler reports the latest analysis of a commit. It expects the
+// owner, repo and sha path values.
+type StatusHandler struct {
+	repository analysis.StatusRepository
+}
+
+// NewStatusHandler creates a handler reading analysis state from repository.
+func NewStatusHandler(repository analysis.StatusRepository) *StatusHandler {
+	return &StatusHandler{repository: repository}
+}
+
+func (h *StatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := analysis.AnalyzeRequest{
+		CommitSHA: r.PathValue("sha"),
+		Owner:     r.PathValue("owner"),
+		Repo:      r.PathValue("repo"),
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.repository.GetLatestStatus(r.Context(), req.Owner, req.Repo, req.CommitSHA)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api status lookup failed",
+			"owner", req.Owner,
+			"repo", req.Repo,
+			"error", err,
+		)
+		writeError(w, http.StatusInternalServerError, "failed to read analysis status")
+		return
+	}
+	if report == nil {
+		writeError(w, http.StatusNotFound, "commit not analyzed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, statusResponse{
+		AnalysisID:   report.AnalysisID.String(),
+		CompletedAt:  report.CompletedAt,
+		CreatedAt:    report.CreatedAt,
+		ErrorMessage: report.ErrorMessage,
+		FailureClass: string(report.FailureClass),
+		Status:       report.Status,
+	})
+}
+
+func tokenName(r *http.Request) string {
+	if token := TokenFromContext(r.Context()); token != nil {
+		return token.Name
+	}
+	return ""
+}
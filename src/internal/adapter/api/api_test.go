@@ -0,0 +1,524 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/servicetoken"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/domain/webhook"
+	specviewuc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+const testToken = "svt_test"
+
+type mockAuthenticator struct {
+	err    error
+	scopes []servicetoken.Scope
+}
+
+func (m *mockAuthenticator) Authenticate(_ context.Context, plaintext string, scope servicetoken.Scope) (*servicetoken.Token, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if plaintext != testToken {
+		return nil, servicetoken.ErrInvalidToken
+	}
+	token := &servicetoken.Token{ID: "token-1", Name: "ci", Scopes: m.scopes}
+	if !token.Allows(scope) {
+		return nil, servicetoken.ErrInsufficientScope
+	}
+	return token, nil
+}
+
+type enqueued struct {
+	branch, commitSHA, owner, repo string
+	filters                        *analysis.PathFilters
+}
+
+type mockEnqueuer struct {
+	calls []enqueued
+	err   error
+}
+
+func (m *mockEnqueuer) EnqueueBranchAnalysis(_ context.Context, owner, repo, branch, commitSHA string) error {
+	m.calls = append(m.calls, enqueued{branch: branch, commitSHA: commitSHA, owner: owner, repo: repo})
+	return m.err
+}
+
+func (m *mockEnqueuer) EnqueueFilteredAnalysis(_ context.Context, owner, repo, branch, commitSHA string, filters *analysis.PathFilters) error {
+	m.calls = append(m.calls, enqueued{branch: branch, commitSHA: commitSHA, owner: owner, repo: repo, filters: filters})
+	return m.err
+}
+
+// mockIdempotentEnqueuer maps each key to the index of its call, plus one.
+type mockIdempotentEnqueuer struct {
+	mockEnqueuer
+	keys map[string]int
+}
+
+func (m *mockIdempotentEnqueuer) EnqueueIdempotentAnalysis(ctx context.Context, key, owner, repo, branch, commitSHA string, filters *analysis.PathFilters) (webhook.EnqueueResult, error) {
+	if n, ok := m.keys[key]; ok {
+		if first := m.calls[n-1]; first.owner != owner || first.repo != repo || first.commitSHA != commitSHA {
+			return webhook.EnqueueResult{}, webhook.ErrIdempotencyKeyReused
+		}
+		return webhook.EnqueueResult{Duplicate: true, JobID: int64(n)}, nil
+	}
+	if err := m.EnqueueFilteredAnalysis(ctx, owner, repo, branch, commitSHA, filters); err != nil {
+		return webhook.EnqueueResult{}, err
+	}
+	m.keys[key] = len(m.calls)
+	return webhook.EnqueueResult{JobID: int64(len(m.calls))}, nil
+}
+
+func (m *mockIdempotentEnqueuer) LookupIdempotencyKey(_ context.Context, key string) (*webhook.EnqueueResult, error) {
+	n, ok := m.keys[key]
+	if !ok {
+		return nil, nil
+	}
+	return &webhook.EnqueueResult{JobID: int64(n), JobState: "completed"}, nil
+}
+
+type mockStatusRepository struct {
+	report *analysis.StatusReport
+}
+
+func (m *mockStatusRepository) GetLatestStatus(_ context.Context, owner, repo, commitSHA string) (*analysis.StatusReport, error) {
+	return m.report, nil
+}
+
+type mockEstimator struct {
+	err      error
+	estimate *specview.SpecViewEstimate
+	requests []specview.SpecViewRequest
+}
+
+func (m *mockEstimator) Execute(_ context.Context, req specview.SpecViewRequest) (*specview.SpecViewEstimate, error) {
+	m.requests = append(m.requests, req)
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return m.estimate, m.err
+}
+
+func newMux(auth Authenticator, enqueuer *mockEnqueuer, status *mockStatusRepository) *http.ServeMux {
+	mux := http.NewServeMux()
+	Register(mux, auth, enqueuer, status, &mockEstimator{}, nil, nil)
+	return mux
+}
+
+func send(h http.Handler, method, path, body, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRequireScope(t *testing.T) {
+	body := `{"owner":"octocat","repo":"hello","commit_sha":"abc123"}`
+
+	tests := []struct {
+		name          string
+		auth          *mockAuthenticator
+		authorization string
+		want          int
+	}{
+		{"valid token", &mockAuthenticator{scopes: []servicetoken.Scope{servicetoken.ScopeEnqueue}}, "Bearer " + testToken, http.StatusAccepted},
+		{"admin token", &mockAuthenticator{scopes: []servicetoken.Scope{servicetoken.ScopeAdmin}}, "Bearer " + testToken, http.StatusAccepted},
+		{"missing header", &mockAuthenticator{}, "", http.StatusUnauthorized},
+		{"basic auth", &mockAuthenticator{}, "Basic dXNlcjpwYXNz", http.StatusUnauthorized},
+		{"unknown token", &mockAuthenticator{}, "Bearer svt_other", http.StatusUnauthorized},
+		{"missing scope", &mockAuthenticator{scopes: []servicetoken.Scope{servicetoken.ScopeReadStatus}}, "Bearer " + testToken, http.StatusForbidden},
+		{"lookup failure", &mockAuthenticator{err: errors.New("connection reset")}, "Bearer " + testToken, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enqueuer := &mockEnqueuer{}
+			rec := send(newMux(tt.auth, enqueuer, &mockStatusRepository{}), http.MethodPost, EnqueuePath, body, tt.authorization)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+			if enqueuedAny := len(enqueuer.calls) > 0; enqueuedAny != (tt.want == http.StatusAccepted) {
+				t.Errorf("unexpected enqueue calls: %+v", enqueuer.calls)
+			}
+		})
+	}
+}
+
+func TestEnqueueHandler(t *testing.T) {
+	auth := &mockAuthenticator{scopes: []servicetoken.Scope{servicetoken.ScopeEnqueue}}
+
+	tests := []struct {
+		name       string
+		body       string
+		enqueueErr error
+		want       int
+	}{
+		{"default branch", `{"owner":"octocat","repo":"hello","commit_sha":"abc123"}`, nil, http.StatusAccepted},
+		{"named branch", `{"owner":"octocat","repo":"hello","branch":"release/v2","commit_sha":"abc123"}`, nil, http.StatusAccepted},
+		{"malformed json", `{"owner":`, nil, http.StatusBadRequest},
+		{"missing commit", `{"owner":"octocat","repo":"hello"}`, nil, http.StatusBadRequest},
+		{"invalid branch", `{"owner":"octocat","repo":"hello","branch":"-x","commit_sha":"abc123"}`, nil, http.StatusBadRequest},
+		{"path filters", `{"owner":"octocat","repo":"hello","commit_sha":"abc123","path_filters":{"include":["services/payments/**"]}}`, nil, http.StatusAccepted},
+		{"invalid path filter", `{"owner":"octocat","repo":"hello","commit_sha":"abc123","path_filters":{"exclude":["[vendor"]}}`, nil, http.StatusBadRequest},
+		{"queue failure", `{"owner":"octocat","repo":"hello","commit_sha":"abc123"}`, errors.New("insert failed"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enqueuer := &mockEnqueuer{err: tt.enqueueErr}
+			rec := send(newMux(auth, enqueuer, &mockStatusRepository{}), http.MethodPost, EnqueuePath, tt.body, "Bearer "+testToken)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	t.Run("passes path filters to the queue", func(t *testing.T) {
+		enqueuer := &mockEnqueuer{}
+		body := `{"owner":"octocat","repo":"hello","commit_sha":"abc123","path_filters":{"include":["services/payments/**"],"exclude":["**/vendor/**"]}}`
+		send(newMux(auth, enqueuer, &mockStatusRepository{}), http.MethodPost, EnqueuePath, body, "Bearer "+testToken)
+
+		want := &analysis.PathFilters{Exclude: []string{"**/vendor/**"}, Include: []string{"services/payments/**"}}
+		if len(enqueuer.calls) != 1 || !reflect.DeepEqual(enqueuer.calls[0].filters, want) {
+			t.Errorf("calls = %+v, want filters %+v", enqueuer.calls, want)
+		}
+	})
+}
+
+func TestEnqueueHandler_IdempotencyKey(t *testing.T) {
+	auth := &mockAuthenticator{scopes: []servicetoken.Scope{servicetoken.ScopeEnqueue, servicetoken.ScopeReadStatus}}
+	body := `{"owner":"octocat","repo":"hello","commit_sha":"abc123"}`
+	post := func(h http.Handler, body, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, EnqueuePath, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		req.Header.Set(IdempotencyKeyHeader, key)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("enqueues once per key", func(t *testing.T) {
+		enqueuer := &mockIdempotentEnqueuer{keys: make(map[string]int)}
+		mux := http.NewServeMux()
+		Register(mux, auth, enqueuer, &mockStatusRepository{}, &mockEstimator{}, nil, nil)
+
+		var responses []enqueueResponse
+		for i := 0; i < 2; i++ {
+			rec := post(mux, body, "web-retry-1")
+			if rec.Code != http.StatusAccepted {
+				t.Fatalf("request %d: status = %d (body %s)", i, rec.Code, rec.Body)
+			}
+			var got enqueueResponse
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			responses = append(responses, got)
+		}
+		if len(enqueuer.calls) != 1 {
+			t.Errorf("expected one enqueue, got %+v", enqueuer.calls)
+		}
+		if responses[0].Duplicate || !responses[1].Duplicate || responses[0].JobID != responses[1].JobID {
+			t.Errorf("unexpected responses: %+v", responses)
+		}
+
+		rec := send(mux, http.MethodGet, "/v1/analyses/idempotency-keys/web-retry-1", "", "Bearer "+testToken)
+		if rec.Code != http.StatusOK {
+			t.Errorf("lookup status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		rec = send(mux, http.MethodGet, "/v1/analyses/idempotency-keys/unknown", "", "Bearer "+testToken)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("unknown key status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("rejects a key reused for another request", func(t *testing.T) {
+		enqueuer := &mockIdempotentEnqueuer{keys: make(map[string]int)}
+		h := NewEnqueueHandler(enqueuer)
+		post(h, body, "web-retry-1")
+		rec := post(h, `{"owner":"octocat","repo":"hello","commit_sha":"def456"}`, "web-retry-1")
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+		}
+	})
+
+	t.Run("rejects keys the queue cannot honor", func(t *testing.T) {
+		enqueuer := &mockEnqueuer{}
+		mux := newMux(auth, enqueuer, &mockStatusRepository{})
+		if rec := post(mux, body, "web-retry-1"); rec.Code != http.StatusBadRequest || len(enqueuer.calls) != 0 {
+			t.Errorf("status = %d, calls = %+v; want 400 without enqueue", rec.Code, enqueuer.calls)
+		}
+		if rec := post(mux, body, strings.Repeat("k", webhook.MaxIdempotencyKeyLength+1)); rec.Code != http.StatusBadRequest {
+			t.Errorf("long key status = %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestStatusHandler(t *testing.T) {
+	auth := &mockAuthenticator{scopes: []servicetoken.Scope{servicetoken.ScopeReadStatus}}
+	path := "/v1/analyses/octocat/hello/commits/abc123"
+
+	t.Run("reports the latest analysis", func(t *testing.T) {
+		status := &mockStatusRepository{report: &analysis.StatusReport{
+			AnalysisID:   analysis.NewUUID(),
+			FailureClass: analysis.FailureCloneTimeout,
+			Status:       "failed",
+		}}
+		rec := send(newMux(auth, &mockEnqueuer{}, status), http.MethodGet, path, "", "Bearer "+testToken)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		var got statusResponse
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if got.Status != "failed" || got.FailureClass != string(analysis.FailureCloneTimeout) || got.AnalysisID != status.report.AnalysisID.String() {
+			t.Errorf("unexpected response: %+v", got)
+		}
+	})
+
+	t.Run("returns 404 for unanalyzed commits", func(t *testing.T) {
+		rec := send(newMux(auth, &mockEnqueuer{}, &mockStatusRepository{}), http.MethodGet, path, "", "Bearer "+testToken)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("rejects enqueue-only tokens", func(t *testing.T) {
+		enqueueOnly := &mockAuthenticator{scopes: []servicetoken.Scope{servicetoken.ScopeEnqueue}}
+		rec := send(newMux(enqueueOnly, &mockEnqueuer{}, &mockStatusRepository{}), http.MethodGet, path, "", "Bearer "+testToken)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestEstimateHandler(t *testing.T) {
+	auth := &mockAuthenticator{scopes: []servicetoken.Scope{servicetoken.ScopeReadStatus}}
+	body := `{"analysis_id":"a1","language":"English","user_id":"u1"}`
+
+	tests := []struct {
+		name string
+		body string
+		err  error
+		want int
+	}{
+		{"estimate", body, nil, http.StatusOK},
+		{"malformed json", `{"analysis_id":`, nil, http.StatusBadRequest},
+		{"missing user", `{"analysis_id":"a1","language":"English"}`, nil, http.StatusBadRequest},
+		{"no test files", body, specviewuc.ErrLoadInventoryFailed, http.StatusNotFound},
+		{"lookup failure", body, errors.New("connection reset"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimator := &mockEstimator{err: tt.err, estimate: &specview.SpecViewEstimate{
+				CachedBehaviors: 30,
+				EstimatedQuota:  70,
+				EstimatedTokens: 25500,
+				TestCount:       100,
+			}}
+			mux := http.NewServeMux()
+			Register(mux, auth, &mockEnqueuer{}, &mockStatusRepository{}, estimator, nil, nil)
+
+			rec := send(mux, http.MethodPost, EstimatePath, tt.body, "Bearer "+testToken)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			var got estimateResponse
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if got.EstimatedQuota != 70 || got.TestCount != 100 || got.EstimatedTokens != 25500 {
+				t.Errorf("unexpected response: %+v", got)
+			}
+			if req := estimator.requests[0]; req.AnalysisID != "a1" || req.Language != "English" || req.UserID != "u1" {
+				t.Errorf("unexpected request: %+v", req)
+			}
+		})
+	}
+}
+
+type mockPinRepository struct {
+	pins map[string]*specview.DocumentPin
+}
+
+func (m *mockPinRepository) PinDocument(_ context.Context, documentID, releaseTag, pinnedBy string) (*specview.DocumentPin, error) {
+	if err := specview.ValidateReleaseTag(releaseTag); err != nil {
+		return nil, err
+	}
+	if documentID == "missing" {
+		return nil, specview.ErrDocumentNotFound
+	}
+	if pin, ok := m.pins[documentID]; ok {
+		if pin.ReleaseTag != releaseTag {
+			return nil, specview.ErrDocumentPinned
+		}
+		return pin, nil
+	}
+	pin := &specview.DocumentPin{DocumentID: documentID, PinnedBy: pinnedBy, ReleaseTag: releaseTag}
+	m.pins[documentID] = pin
+	return pin, nil
+}
+
+func (m *mockPinRepository) UnpinDocument(_ context.Context, documentID string) error {
+	if _, ok := m.pins[documentID]; !ok {
+		return specview.ErrDocumentNotPinned
+	}
+	delete(m.pins, documentID)
+	return nil
+}
+
+func (m *mockPinRepository) GetDocumentPin(_ context.Context, documentID string) (*specview.DocumentPin, error) {
+	return m.pins[documentID], nil
+}
+
+func TestPinHandler(t *testing.T) {
+	admin := &mockAuthenticator{scopes: []servicetoken.Scope{servicetoken.ScopeAdmin}}
+	pins := &mockPinRepository{pins: map[string]*specview.DocumentPin{}}
+	mux := http.NewServeMux()
+	Register(mux, admin, &mockEnqueuer{}, &mockStatusRepository{}, &mockEstimator{}, pins, nil)
+	path := "/v1/specview/documents/doc-1/pin"
+	bearer := "Bearer " + testToken
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"unpinned document", http.MethodGet, path, "", http.StatusNotFound},
+		{"pin", http.MethodPut, path, `{"release_tag":"v1.4.0"}`, http.StatusOK},
+		{"pin again to the same tag", http.MethodPut, path, `{"release_tag":"v1.4.0"}`, http.StatusOK},
+		{"pin to another tag", http.MethodPut, path, `{"release_tag":"v1.5.0"}`, http.StatusConflict},
+		{"missing tag", http.MethodPut, path, `{}`, http.StatusBadRequest},
+		{"unknown document", http.MethodPut, "/v1/specview/documents/missing/pin", `{"release_tag":"v1.4.0"}`, http.StatusNotFound},
+		{"read pin", http.MethodGet, path, "", http.StatusOK},
+		{"unpin", http.MethodDelete, path, "", http.StatusNoContent},
+		{"unpin again", http.MethodDelete, path, "", http.StatusNotFound},
+		{"unsupported method", http.MethodPost, path, "", http.StatusMethodNotAllowed},
+	}
+	for _, step := range steps {
+		rec := send(mux, step.method, step.path, step.body, bearer)
+		if rec.Code != step.want {
+			t.Fatalf("%s: status = %d, want %d (body %s)", step.name, rec.Code, step.want, rec.Body)
+		}
+	}
+
+	t.Run("records the pinning token", func(t *testing.T) {
+		rec := send(mux, http.MethodPut, path, `{"release_tag":"v2.0.0"}`, bearer)
+		var got pinResponse
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if got.DocumentID != "doc-1" || got.ReleaseTag != "v2.0.0" || got.PinnedBy != "ci" {
+			t.Errorf("unexpected response: %+v", got)
+		}
+	})
+
+	t.Run("requires the admin scope", func(t *testing.T) {
+		readOnly := &mockAuthenticator{scopes: []servicetoken.Scope{servicetoken.ScopeReadStatus}}
+		mux := http.NewServeMux()
+		Register(mux, readOnly, &mockEnqueuer{}, &mockStatusRepository{}, &mockEstimator{}, pins, nil)
+		if rec := send(mux, http.MethodGet, path, "", bearer); rec.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+		}
+	})
+}
+
+type mockLifecycleRepository struct {
+	deleted map[string]*specview.DocumentDeletion
+	pinned  map[string]bool
+}
+
+func (m *mockLifecycleRepository) SoftDeleteDocument(_ context.Context, documentID, deletedBy string) (*specview.DocumentDeletion, error) {
+	if documentID == "missing" {
+		return nil, specview.ErrDocumentNotFound
+	}
+	if m.pinned[documentID] {
+		return nil, specview.ErrDocumentPinned
+	}
+	if deletion, ok := m.deleted[documentID]; ok {
+		return deletion, nil
+	}
+	deletion := &specview.DocumentDeletion{DeletedAt: time.Now(), DeletedBy: deletedBy, DocumentID: documentID}
+	m.deleted[documentID] = deletion
+	return deletion, nil
+}
+
+func (m *mockLifecycleRepository) RestoreDocument(_ context.Context, documentID string) error {
+	if _, ok := m.deleted[documentID]; !ok {
+		return specview.ErrDocumentNotDeleted
+	}
+	delete(m.deleted, documentID)
+	return nil
+}
+
+func TestLifecycleHandler(t *testing.T) {
+	admin := &mockAuthenticator{scopes: []servicetoken.Scope{servicetoken.ScopeAdmin}}
+	lifecycle := &mockLifecycleRepository{deleted: map[string]*specview.DocumentDeletion{}, pinned: map[string]bool{"pinned": true}}
+	mux := http.NewServeMux()
+	Register(mux, admin, &mockEnqueuer{}, &mockStatusRepository{}, &mockEstimator{}, nil, lifecycle)
+	path := "/v1/specview/documents/doc-1"
+	bearer := "Bearer " + testToken
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"restore undeleted document", http.MethodPost, path + "/restore", http.StatusNotFound},
+		{"delete", http.MethodDelete, path, http.StatusOK},
+		{"delete again", http.MethodDelete, path, http.StatusOK},
+		{"delete pinned document", http.MethodDelete, "/v1/specview/documents/pinned", http.StatusConflict},
+		{"delete unknown document", http.MethodDelete, "/v1/specview/documents/missing", http.StatusNotFound},
+		{"restore", http.MethodPost, path + "/restore", http.StatusNoContent},
+		{"restore again", http.MethodPost, path + "/restore", http.StatusNotFound},
+	}
+	for _, step := range steps {
+		rec := send(mux, step.method, step.path, "", bearer)
+		if rec.Code != step.want {
+			t.Fatalf("%s: status = %d, want %d (body %s)", step.name, rec.Code, step.want, rec.Body)
+		}
+	}
+
+	t.Run("records the deleting token", func(t *testing.T) {
+		rec := send(mux, http.MethodDelete, path, "", bearer)
+		var got deletionResponse
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if got.DocumentID != "doc-1" || got.DeletedBy != "ci" || got.DeletedAt.IsZero() {
+			t.Errorf("unexpected response: %+v", got)
+		}
+	})
+
+	t.Run("requires the admin scope", func(t *testing.T) {
+		readOnly := &mockAuthenticator{scopes: []servicetoken.Scope{servicetoken.ScopeReadStatus}}
+		mux := http.NewServeMux()
+		Register(mux, readOnly, &mockEnqueuer{}, &mockStatusRepository{}, &mockEstimator{}, nil, lifecycle)
+		if rec := send(mux, http.MethodDelete, path, "", bearer); rec.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+// Package api serves the worker's machine-to-machine HTTP endpoints.
+// Every endpoint requires a service token with the scope it needs.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/servicetoken"
+)
+
+const bearerPrefix = "Bearer "
+
+// Authenticator validates service tokens.
+type Authenticator interface {
+	Authenticate(ctx context.Context, plaintext string, scope servicetoken.Scope) (*servicetoken.Token, error)
+}
+
+type tokenContextKey struct{}
+
+// TokenFromContext returns the token that authenticated the request, if any.
+func TokenFromContext(ctx context.Context) *servicetoken.Token {
+	token, _ := ctx.Value(tokenContextKey{}).(*servicetoken.Token)
+	return token
+}
+
+// RequireScope serves next only for requests bearing an active token that grants scope.
+func RequireScope(auth Authenticator, scope servicetoken.Scope, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, bearerPrefix) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		token, err := auth.Authenticate(r.Context(), strings.TrimPrefix(header, bearerPrefix), scope)
+		switch {
+		case err == nil:
+		case errors.Is(err, servicetoken.ErrInvalidToken):
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		case errors.Is(err, servicetoken.ErrInsufficientScope):
+			writeError(w, http.StatusForbidden, "token lacks scope "+string(scope))
+			return
+		default:
+			slog.ErrorContext(r.Context(), "service token lookup failed", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to authenticate")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenContextKey{}, token)))
+	})
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
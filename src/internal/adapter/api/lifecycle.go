@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type deletionResponse struct {
+	DeletedAt  time.Time `json:"deleted_at"`
+	DeletedBy  string    `json:"deleted_by,omitempty"`
+	DocumentID string    `json:"document_id"`
+}
+
+// LifecycleHandler soft-deletes and restores spec document versions. It
+// expects the id path value and serves DELETE (soft delete) on DocumentPath
+// and POST (restore) on DocumentRestorePath.
+type LifecycleHandler struct {
+	repository specview.DocumentLifecycleRepository
+}
+
+// NewLifecycleHandler creates a handler managing deletions through repository.
+func NewLifecycleHandler(repository specview.DocumentLifecycleRepository) *LifecycleHandler {
+	return &LifecycleHandler{repository: repository}
+}
+
+func (h *LifecycleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	documentID := r.PathValue("id")
+
+	switch r.Method {
+	case http.MethodDelete:
+		deletion, err := h.repository.SoftDeleteDocument(r.Context(), documentID, tokenName(r))
+		if err != nil {
+			h.writeFailure(w, r, "delete", documentID, err)
+			return
+		}
+		slog.InfoContext(r.Context(), "spec document deleted via api",
+			"document_id", documentID,
+			"token", tokenName(r),
+		)
+		writeJSON(w, http.StatusOK, deletionResponse{
+			DeletedAt:  deletion.DeletedAt,
+			DeletedBy:  deletion.DeletedBy,
+			DocumentID: deletion.DocumentID,
+		})
+
+	case http.MethodPost:
+		if err := h.repository.RestoreDocument(r.Context(), documentID); err != nil {
+			h.writeFailure(w, r, "restore", documentID, err)
+			return
+		}
+		slog.InfoContext(r.Context(), "spec document restored via api",
+			"document_id", documentID,
+			"token", tokenName(r),
+		)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *LifecycleHandler) writeFailure(w http.ResponseWriter, r *http.Request, operation, documentID string, err error) {
+	switch {
+	case errors.Is(err, specview.ErrInvalidInput):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, specview.ErrDocumentNotFound), errors.Is(err, specview.ErrDocumentNotDeleted):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, specview.ErrDocumentPinned):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "api document lifecycle failed",
+			"operation", operation,
+			"document_id", documentID,
+			"token", tokenName(r),
+			"error", err,
+		)
+		writeError(w, http.StatusInternalServerError, "failed to "+operation+" document")
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type pinRequest struct {
+	ReleaseTag string `json:"release_tag"`
+}
+
+type pinResponse struct {
+	DocumentID string    `json:"document_id"`
+	PinnedAt   time.Time `json:"pinned_at"`
+	PinnedBy   string    `json:"pinned_by,omitempty"`
+	ReleaseTag string    `json:"release_tag"`
+}
+
+// PinHandler pins spec document versions to releases, keeping them from
+// retention cleanup and regeneration campaigns. It expects the id path value
+// and serves GET (read the pin), PUT (pin) and DELETE (unpin).
+type PinHandler struct {
+	repository specview.DocumentPinRepository
+}
+
+// NewPinHandler creates a handler managing pins through repository.
+func NewPinHandler(repository specview.DocumentPinRepository) *PinHandler {
+	return &PinHandler{repository: repository}
+}
+
+func (h *PinHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	documentID := r.PathValue("id")
+
+	switch r.Method {
+	case http.MethodGet:
+		pin, err := h.repository.GetDocumentPin(r.Context(), documentID)
+		if err != nil {
+			h.writeFailure(w, r, "read", documentID, err)
+			return
+		}
+		if pin == nil {
+			writeError(w, http.StatusNotFound, specview.ErrDocumentNotPinned.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, toPinResponse(pin))
+
+	case http.MethodPut:
+		var req pinRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		pin, err := h.repository.PinDocument(r.Context(), documentID, req.ReleaseTag, tokenName(r))
+		if err != nil {
+			h.writeFailure(w, r, "pin", documentID, err)
+			return
+		}
+		slog.InfoContext(r.Context(), "spec document pinned via api",
+			"document_id", documentID,
+			"release_tag", pin.ReleaseTag,
+			"token", tokenName(r),
+		)
+		writeJSON(w, http.StatusOK, toPinResponse(pin))
+
+	case http.MethodDelete:
+		if err := h.repository.UnpinDocument(r.Context(), documentID); err != nil {
+			h.writeFailure(w, r, "unpin", documentID, err)
+			return
+		}
+		slog.InfoContext(r.Context(), "spec document unpinned via api",
+			"document_id", documentID,
+			"token", tokenName(r),
+		)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *PinHandler) writeFailure(w http.ResponseWriter, r *http.Request, operation, documentID string, err error) {
+	switch {
+	case errors.Is(err, specview.ErrInvalidInput):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, specview.ErrDocumentNotFound), errors.Is(err, specview.ErrDocumentNotPinned):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, specview.ErrDocumentPinned):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "api document pin failed",
+			"operation", operation,
+			"document_id", documentID,
+			"token", tokenName(r),
+			"error", err,
+		)
+		writeError(w, http.StatusInternalServerError, "failed to "+operation+" document")
+	}
+}
+
+func toPinResponse(pin *specview.DocumentPin) pinResponse {
+	return pinResponse{
+		DocumentID: pin.DocumentID,
+		PinnedAt:   pin.PinnedAt,
+		PinnedBy:   pin.PinnedBy,
+		ReleaseTag: pin.ReleaseTag,
+	}
+}
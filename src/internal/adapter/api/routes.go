@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/servicetoken"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/domain/webhook"
+)
+
+const (
+	DocumentPath        = "/v1/specview/documents/{id}"
+	DocumentPinPath     = "/v1/specview/documents/{id}/pin"
+	DocumentRestorePath = "/v1/specview/documents/{id}/restore"
+	EnqueuePath         = "/v1/analyses"
+	EstimatePath        = "/v1/specview/estimate"
+	IdempotencyKeyPath  = "/v1/analyses/idempotency-keys/{key}"
+	StatusPath          = "/v1/analyses/{owner}/{repo}/commits/{sha}"
+)
+
+// Register mounts the API endpoints on mux behind service token checks.
+// The idempotency key lookup is mounted when enqueuer supports keys, and the
+// admin-only document pin and lifecycle endpoints when pins and lifecycle are
+// not nil.
+func Register(
+	mux *http.ServeMux,
+	auth Authenticator,
+	enqueuer webhook.AnalysisEnqueuer,
+	status analysis.StatusRepository,
+	estimator SpecViewEstimator,
+	pins specview.DocumentPinRepository,
+	lifecycle specview.DocumentLifecycleRepository,
+) {
+	mux.Handle("POST "+EnqueuePath, RequireScope(auth, servicetoken.ScopeEnqueue, NewEnqueueHandler(enqueuer)))
+	mux.Handle("POST "+EstimatePath, RequireScope(auth, servicetoken.ScopeReadStatus, NewEstimateHandler(estimator)))
+	mux.Handle("GET "+StatusPath, RequireScope(auth, servicetoken.ScopeReadStatus, NewStatusHandler(status)))
+	if idempotent, ok := enqueuer.(webhook.IdempotentAnalysisEnqueuer); ok {
+		mux.Handle("GET "+IdempotencyKeyPath, RequireScope(auth, servicetoken.ScopeReadStatus, NewIdempotencyKeyHandler(idempotent)))
+	}
+	if pins != nil {
+		mux.Handle(DocumentPinPath, RequireScope(auth, servicetoken.ScopeAdmin, NewPinHandler(pins)))
+	}
+	if lifecycle != nil {
+		handler := RequireScope(auth, servicetoken.ScopeAdmin, NewLifecycleHandler(lifecycle))
+		mux.Handle("DELETE "+DocumentPath, handler)
+		mux.Handle("POST "+DocumentRestorePath, handler)
+	}
+}
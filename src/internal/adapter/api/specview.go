@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	specviewuc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+// SpecViewEstimator prices spec-view generation requests.
+type SpecViewEstimator interface {
+	Execute(ctx context.Context, req specview.SpecViewRequest) (*specview.SpecViewEstimate, error)
+}
+
+type estimateRequest struct {
+	AnalysisID      string `json:"analysis_id"`
+	ForceRegenerate bool   `json:"force_regenerate"`
+	Language        string `json:"language"`
+	ModelID         string `json:"model_id"`
+	Phase1ModelID   string `json:"phase1_model_id"`
+	Phase2ModelID   string `json:"phase2_model_id"`
+	Phase3ModelID   string `json:"phase3_model_id"`
+	Project         string `json:"project"`
+	UserID          string `json:"user_id"`
+}
+
+type estimateResponse struct {
+	CachedBehaviors      int   `json:"cached_behaviors"`
+	ClassificationCached bool  `json:"classification_cached"`
+	DocumentCached       bool  `json:"document_cached"`
+	EstimatedQuota       int   `json:"estimated_quota"`
+	EstimatedTokens      int64 `json:"estimated_tokens"`
+	NewTests             int   `json:"new_tests"`
+	TestCount            int   `json:"test_count"`
+}
+
+// EstimateHandler reports what generating a spec-view document would cost,
+// so the Web app can confirm it with the user before enqueuing the job.
+// The request mirrors the generation job's arguments.
+type EstimateHandler struct {
+	estimator SpecViewEstimator
+}
+
+// NewEstimateHandler creates a handler pricing requests through estimator.
+func NewEstimateHandler(estimator SpecViewEstimator) *EstimateHandler {
+	return &EstimateHandler{estimator: estimator}
+}
+
+func (h *EstimateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req estimateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	estimate, err := h.estimator.Execute(r.Context(), specview.SpecViewRequest{
+		AnalysisID:      req.AnalysisID,
+		ForceRegenerate: req.ForceRegenerate,
+		Language:        specview.Language(req.Language),
+		ModelID:         req.ModelID,
+		Phase1ModelID:   req.Phase1ModelID,
+		Phase2ModelID:   req.Phase2ModelID,
+		Phase3ModelID:   req.Phase3ModelID,
+		Project:         req.Project,
+		UserID:          req.UserID,
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, specview.ErrInvalidInput):
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, specviewuc.ErrLoadInventoryFailed):
+		writeError(w, http.StatusNotFound, "analysis has no test files")
+		return
+	default:
+		slog.ErrorContext(r.Context(), "api spec-view estimate failed",
+			"analysis_id", req.AnalysisID,
+			"token", tokenName(r),
+			"error", err,
+		)
+		writeError(w, http.StatusInternalServerError, "failed to estimate spec-view cost")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, estimateResponse{
+		CachedBehaviors:      estimate.CachedBehaviors,
+		ClassificationCached: estimate.ClassificationCached,
+		DocumentCached:       estimate.DocumentCached,
+		EstimatedQuota:       estimate.EstimatedQuota,
+		EstimatedTokens:      estimate.EstimatedTokens,
+		NewTests:             estimate.NewTests,
+		TestCount:            estimate.TestCount,
+	})
+}
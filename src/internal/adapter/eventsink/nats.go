@@ -0,0 +1,233 @@
+package eventsink
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/outbox"
+)
+
+const defaultNATSPort = "4222"
+
+var _ outbox.Sink = (*NATSSink)(nil)
+
+// NATSSink publishes each event to <prefix>.<event type> over the NATS core
+// protocol. A publish is followed by a PING, so it returns once the server
+// has processed it; core NATS does not confirm delivery to subscribers, use
+// a JetStream stream on the subjects for that. The connection is kept
+// between publishes and redialed after an error.
+type NATSSink struct {
+	addr       string
+	dialer     net.Dialer
+	password   string
+	prefix     string
+	serverName string
+	tls        bool
+	token      string
+	user       string
+
+	mu         sync.Mutex // guards the connection, one publish at a time
+	conn       net.Conn
+	maxPayload int // announced by the server; zero when unknown
+	reader     *bufio.Reader
+}
+
+// natsInfo is the part of the server's INFO message the sink uses.
+type natsInfo struct {
+	MaxPayload  int  `json:"max_payload"`
+	TLSRequired bool `json:"tls_required"`
+}
+
+// NewNATSSink creates a sink for a nats:// or tls:// URL. The path is the
+// subject prefix, with slashes as dots; user info is a user and password, or
+// a token without password.
+func NewNATSSink(u *url.URL) (*NATSSink, error) {
+	if u.Scheme != "nats" && u.Scheme != "tls" {
+		return nil, fmt.Errorf("invalid NATS URL scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("NATS URL has no host")
+	}
+	prefix := strings.ReplaceAll(strings.Trim(u.Path, "/"), "/", ".")
+	if prefix == "" || strings.ContainsAny(prefix, " \t\r\n*>") {
+		return nil, fmt.Errorf("invalid NATS subject prefix %q", prefix)
+	}
+
+	s := &NATSSink{
+		addr:       net.JoinHostPort(u.Hostname(), cmp.Or(u.Port(), defaultNATSPort)),
+		dialer:     net.Dialer{Timeout: publishTimeout},
+		prefix:     prefix,
+		serverName: u.Hostname(),
+		tls:        u.Scheme == "tls",
+	}
+	if u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			s.user, s.password = u.User.Username(), password
+		} else {
+			s.token = u.User.Username()
+		}
+	}
+	return s, nil
+}
+
+func (s *NATSSink) Publish(ctx context.Context, event outbox.Event) error {
+	message, err := marshalEnvelope(event)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return fmt.Errorf("connect to NATS: %w", err)
+		}
+	}
+	if s.maxPayload > 0 && len(message) > s.maxPayload {
+		return fmt.Errorf("event %s is %d bytes, over the server's max payload of %d", event.ID, len(message), s.maxPayload)
+	}
+
+	if err := s.publish(ctx, s.prefix+"."+event.Type, message); err != nil {
+		s.closeLocked()
+		return fmt.Errorf("publish to NATS: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection, if any.
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeLocked()
+	return nil
+}
+
+func (s *NATSSink) closeLocked() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn, s.reader = nil, nil
+	}
+}
+
+func (s *NATSSink) connect(ctx context.Context) error {
+	conn, err := s.dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.setDeadline(ctx, conn)
+
+	reader := bufio.NewReader(conn)
+	line, err := readLine(reader)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("read INFO: %w", err)
+	}
+	infoJSON, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		conn.Close()
+		return fmt.Errorf("expected INFO, got %q", line)
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(infoJSON), &info); err != nil {
+		conn.Close()
+		return fmt.Errorf("decode INFO: %w", err)
+	}
+
+	if s.tls || info.TLSRequired {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: s.serverName, MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return fmt.Errorf("TLS handshake: %w", err)
+		}
+		conn, reader = tlsConn, bufio.NewReader(tlsConn)
+	}
+
+	connectJSON, err := json.Marshal(map[string]any{
+		"auth_token": s.token,
+		"lang":       "go",
+		"name":       "specvital-outbox-relay",
+		"pass":       s.password,
+		"pedantic":   false,
+		"user":       s.user,
+		"verbose":    false,
+	})
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	s.conn, s.reader, s.maxPayload = conn, reader, info.MaxPayload
+
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connectJSON); err != nil {
+		s.closeLocked()
+		return err
+	}
+	if err := s.awaitPong(); err != nil {
+		s.closeLocked()
+		return err
+	}
+	return nil
+}
+
+func (s *NATSSink) publish(ctx context.Context, subject string, message []byte) error {
+	s.setDeadline(ctx, s.conn)
+
+	buf := make([]byte, 0, len(subject)+len(message)+32)
+	buf = fmt.Appendf(buf, "PUB %s %d\r\n", subject, len(message))
+	buf = append(buf, message...)
+	buf = append(buf, "\r\nPING\r\n"...)
+	if _, err := s.conn.Write(buf); err != nil {
+		return err
+	}
+	return s.awaitPong()
+}
+
+// awaitPong reads until the PONG answering the last PING, answering server
+// PINGs on the way. With verbose off, errors are the only other replies.
+func (s *NATSSink) awaitPong() error {
+	for {
+		line, err := readLine(s.reader)
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (s *NATSSink) setDeadline(ctx context.Context, conn net.Conn) {
+	deadline := time.Now().Add(publishTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,139 @@
+package eventsink
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeNATSServer speaks enough of the NATS protocol for one client: it sends
+// INFO, answers PINGs and records CONNECT and PUB messages.
+type fakeNATSServer struct {
+	listener net.Listener
+	messages chan string // "CONNECT <json>" or "<subject> <payload>"
+	reject   string      // -ERR sent instead of the PONG answering a PUB
+}
+
+func newFakeNATSServer(t *testing.T) *fakeNATSServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeNATSServer{listener: listener, messages: make(chan string, 16)}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeNATSServer) url(path string) *url.URL {
+	u, _ := url.Parse("nats://alice:pw@" + s.listener.Addr().String() + path)
+	return u
+}
+
+func (s *fakeNATSServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeNATSServer) handle(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprint(conn, "INFO {\"server_id\":\"fake\",\"max_payload\":1024}\r\n")
+
+	reader := bufio.NewReader(conn)
+	published := false
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			return
+		}
+		switch {
+		case strings.HasPrefix(line, "CONNECT "):
+			s.messages <- line
+		case strings.HasPrefix(line, "PUB "):
+			fields := strings.Fields(line)
+			size, _ := strconv.Atoi(fields[2])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			s.messages <- fields[1] + " " + string(payload[:size])
+			published = true
+		case line == "PING":
+			if published && s.reject != "" {
+				fmt.Fprintf(conn, "-ERR '%s'\r\n", s.reject)
+				return
+			}
+			fmt.Fprint(conn, "PING\r\nPONG\r\n")
+		}
+	}
+}
+
+func TestNATSSink_Publish(t *testing.T) {
+	server := newFakeNATSServer(t)
+	sink, err := NewNATSSink(server.url("/specvital/events"))
+	if err != nil {
+		t.Fatalf("NewNATSSink failed: %v", err)
+	}
+	defer sink.Close()
+
+	event := testEvent()
+	for range 2 {
+		if err := sink.Publish(context.Background(), event); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	connect := <-server.messages
+	if !strings.Contains(connect, `"user":"alice"`) || !strings.Contains(connect, `"pass":"pw"`) {
+		t.Errorf("CONNECT = %s", connect)
+	}
+	for range 2 {
+		msg := <-server.messages
+		subject, payload, _ := strings.Cut(msg, " ")
+		if subject != "specvital.events.analysis.completed" || !strings.Contains(payload, `"id":"`+event.ID+`"`) {
+			t.Errorf("message = %s", msg)
+		}
+	}
+	select {
+	case msg := <-server.messages:
+		t.Errorf("expected the connection to be reused, got %s", msg)
+	default:
+	}
+}
+
+func TestNATSSink_PublishError(t *testing.T) {
+	server := newFakeNATSServer(t)
+	server.reject = "Permissions Violation for Publish"
+	sink, err := NewNATSSink(server.url("/specvital"))
+	if err != nil {
+		t.Fatalf("NewNATSSink failed: %v", err)
+	}
+	defer sink.Close()
+
+	err = sink.Publish(context.Background(), testEvent())
+	if err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Fatalf("expected the server error, got %v", err)
+	}
+	if sink.conn != nil {
+		t.Error("expected the connection to be dropped after an error")
+	}
+
+	event := testEvent()
+	event.Payload = []byte(`"` + strings.Repeat("x", 2048) + `"`)
+	server.reject = ""
+	if err := sink.Publish(context.Background(), event); err == nil || !strings.Contains(err.Error(), "max payload") {
+		t.Errorf("expected the payload limit to be enforced, got %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package eventsink
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/outbox"
+)
+
+const (
+	snsAPIVersion   = "2010-03-31"
+	snsService      = "sns"
+	signAlgorithm   = "AWS4-HMAC-SHA256"
+	amzDateFormat   = "20060102T150405Z"
+	shortDateFormat = "20060102"
+)
+
+var _ outbox.Sink = (*SNSSink)(nil)
+
+// SNSSink publishes each event to an SNS topic with the Query API, signed
+// with AWS Signature V4. The event type is sent as the event_type message
+// attribute, for subscription filter policies. FIFO topics group messages by
+// aggregate and deduplicate them by event ID.
+type SNSSink struct {
+	accessKeyID     string
+	endpoint        *url.URL
+	fifo            bool
+	httpClient      *http.Client
+	now             func() time.Time
+	region          string
+	secretAccessKey string
+	topicARN        string
+}
+
+// NewSNSSink creates a sink for the topic. An empty endpoint uses the
+// regional SNS endpoint of the topic.
+func NewSNSSink(topicARN, endpoint, accessKeyID, secretAccessKey string, httpClient *http.Client) (*SNSSink, error) {
+	parts := strings.Split(topicARN, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != snsService || parts[3] == "" || parts[5] == "" {
+		return nil, fmt.Errorf("invalid SNS topic ARN %q", topicARN)
+	}
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, errors.New("access key ID and secret access key are required for SNS")
+	}
+	if httpClient == nil {
+		return nil, errors.New("http client is required")
+	}
+
+	region := parts[3]
+	if endpoint == "" {
+		endpoint = "https://sns." + region + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("invalid SNS endpoint %q", endpoint)
+	}
+	u.Path = "/"
+
+	return &SNSSink{
+		accessKeyID:     accessKeyID,
+		endpoint:        u,
+		fifo:            strings.HasSuffix(parts[5], ".fifo"),
+		httpClient:      httpClient,
+		now:             time.Now,
+		region:          region,
+		secretAccessKey: secretAccessKey,
+		topicARN:        topicARN,
+	}, nil
+}
+
+func (s *SNSSink) Publish(ctx context.Context, event outbox.Event) error {
+	message, err := marshalEnvelope(event)
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{
+		"Action":   {"Publish"},
+		"Message":  {string(message)},
+		"TopicArn": {s.topicARN},
+		"Version":  {snsAPIVersion},
+
+		"MessageAttributes.entry.1.Name":              {"event_type"},
+		"MessageAttributes.entry.1.Value.DataType":    {"String"},
+		"MessageAttributes.entry.1.Value.StringValue": {event.Type},
+	}
+	if s.fifo {
+		form.Set("MessageGroupId", event.AggregateID)
+		form.Set("MessageDeduplicationId", event.ID)
+	}
+	body := form.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint.String(), strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	s.sign(req, body, s.now().UTC())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("publish to SNS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("publish to SNS: status %d: %s", resp.StatusCode, snsErrorMessage(raw))
+	}
+	return nil
+}
+
+// sign adds the SigV4 Authorization header to req, signing its host,
+// content type, date and body.
+func (s *SNSSink) sign(req *http.Request, body string, now time.Time) {
+	amzDate := now.Format(amzDateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	payloadHash := sha256.Sum256([]byte(body))
+	const signedHeaders = "content-type;host;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method,
+		"/",
+		"",
+		"content-type:" + req.Header.Get("Content-Type") + "\n" +
+			"host:" + req.URL.Host + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := now.Format(shortDateFormat) + "/" + s.region + "/" + snsService + "/aws4_request"
+	hashed := sha256.Sum256([]byte(canonical))
+	stringToSign := signAlgorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), now.Format(shortDateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, snsService)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, s.accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// snsErrorMessage extracts the code and message of an SNS error response,
+// falling back to the raw body.
+func snsErrorMessage(raw []byte) string {
+	var resp struct {
+		Error struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		} `xml:"Error"`
+	}
+	if err := xml.Unmarshal(raw, &resp); err != nil || resp.Error.Code == "" {
+		return strings.TrimSpace(string(raw))
+	}
+	return resp.Error.Code + ": " + resp.Error.Message
+}
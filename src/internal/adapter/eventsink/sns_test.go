@@ -0,0 +1,89 @@
+package eventsink
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSNSSink_Publish(t *testing.T) {
+	var got *http.Request
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ := io.ReadAll(r.Body)
+		form, _ = url.ParseQuery(string(body))
+		w.Write([]byte(`<PublishResponse><PublishResult><MessageId>m-1</MessageId></PublishResult></PublishResponse>`))
+	}))
+	defer server.Close()
+
+	sink, err := NewSNSSink("arn:aws:sns:eu-west-1:123456789012:events.fifo", server.URL, "AKID", "secret", server.Client())
+	if err != nil {
+		t.Fatalf("NewSNSSink failed: %v", err)
+	}
+	sink.now = func() time.Time { return time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC) }
+
+	event := testEvent()
+	if err := sink.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if form.Get("Action") != "Publish" || form.Get("TopicArn") != "arn:aws:sns:eu-west-1:123456789012:events.fifo" {
+		t.Errorf("form = %v", form)
+	}
+	if !strings.Contains(form.Get("Message"), `"id":"`+event.ID+`"`) {
+		t.Errorf("message = %s", form.Get("Message"))
+	}
+	if form.Get("MessageAttributes.entry.1.Value.StringValue") != event.Type {
+		t.Errorf("expected the event type attribute, got %v", form)
+	}
+	if form.Get("MessageGroupId") != event.AggregateID || form.Get("MessageDeduplicationId") != event.ID {
+		t.Errorf("expected FIFO group and deduplication IDs, got %v", form)
+	}
+
+	auth := got.Header.Get("Authorization")
+	wantPrefix := "AWS4-HMAC-SHA256 Credential=AKID/20261014/eu-west-1/sns/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature="
+	if !strings.HasPrefix(auth, wantPrefix) || got.Header.Get("X-Amz-Date") != "20261014T093000Z" {
+		t.Errorf("Authorization = %q, X-Amz-Date = %q", auth, got.Header.Get("X-Amz-Date"))
+	}
+}
+
+func TestSNSSink_PublishError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("MessageGroupId") != "" {
+			t.Error("expected no message group on a standard topic")
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>AuthorizationError</Code><Message>not authorized</Message></Error></ErrorResponse>`))
+	}))
+	defer server.Close()
+
+	sink, err := NewSNSSink("arn:aws:sns:eu-west-1:123456789012:events", server.URL, "AKID", "secret", server.Client())
+	if err != nil {
+		t.Fatalf("NewSNSSink failed: %v", err)
+	}
+	err = sink.Publish(context.Background(), testEvent())
+	if err == nil || !strings.Contains(err.Error(), "AuthorizationError: not authorized") {
+		t.Errorf("expected the SNS error code and message, got %v", err)
+	}
+}
+
+func TestNewSNSSink_DefaultEndpoint(t *testing.T) {
+	sink, err := NewSNSSink("arn:aws:sns:ap-northeast-2:123456789012:events", "", "AKID", "secret", http.DefaultClient)
+	if err != nil {
+		t.Fatalf("NewSNSSink failed: %v", err)
+	}
+	if got := sink.endpoint.String(); got != "https://sns.ap-northeast-2.amazonaws.com/" {
+		t.Errorf("endpoint = %s", got)
+	}
+
+	if _, err := NewSNSSink("arn:aws:sns:ap-northeast-2:123456789012:events", "", "", "", http.DefaultClient); err == nil {
+		t.Error("expected an error without credentials")
+	}
+}
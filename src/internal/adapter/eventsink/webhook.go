@@ -0,0 +1,84 @@
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/outbox"
+)
+
+// Webhook headers. The signature is "sha256=" and the hex HMAC-SHA256 of the
+// body, the scheme GitHub webhooks use.
+const (
+	HeaderDelivery  = "X-Specvital-Delivery"
+	HeaderEvent     = "X-Specvital-Event"
+	HeaderSignature = "X-Specvital-Signature-256"
+)
+
+var _ outbox.Sink = (*WebhookSink)(nil)
+
+// WebhookSink POSTs each event to a URL; any 2xx response accepts it.
+type WebhookSink struct {
+	httpClient *http.Client
+	secret     []byte
+	url        string
+}
+
+// NewWebhookSink creates a sink posting to endpoint.
+func NewWebhookSink(endpoint string, secret []byte, httpClient *http.Client) (*WebhookSink, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("invalid webhook URL %q", endpoint)
+	}
+	if httpClient == nil {
+		return nil, errors.New("http client is required")
+	}
+	return &WebhookSink{httpClient: httpClient, secret: secret, url: endpoint}, nil
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, event outbox.Event) error {
+	body, err := marshalEnvelope(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDelivery, event.ID)
+	req.Header.Set(HeaderEvent, event.Type)
+	if len(s.secret) > 0 {
+		req.Header.Set(HeaderSignature, Sign(s.secret, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("post event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("post event: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Sign returns the signature header value of body, for receivers to compare
+// with hmac.Equal.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,119 @@
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/outbox"
+)
+
+func testEvent() outbox.Event {
+	return outbox.Event{
+		AggregateID: "5f0c2a7e-3c1e-4d7a-9b8e-3f0a1c2d4e5f",
+		Attempts:    1,
+		CreatedAt:   time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC),
+		ID:          "0b9d6c3e-8a55-4c41-9f0e-6d2b7a1e9c40",
+		Payload:     json.RawMessage(`{"owner":"octocat","repo":"hello-world"}`),
+		Type:        outbox.EventAnalysisCompleted,
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{url: "https://hooks.example.com/specvital", want: "*eventsink.WebhookSink"},
+		{url: "nats://nats:4222/specvital", want: "*eventsink.NATSSink"},
+		{url: "arn:aws:sns:us-east-1:123456789012:events", want: "*eventsink.SNSSink"},
+		{url: "", wantErr: true},
+		{url: "ftp://example.com/events", wantErr: true},
+		{url: "nats://nats:4222", wantErr: true},
+		{url: "arn:aws:sqs:us-east-1:123456789012:events", wantErr: true},
+	}
+	cfg := Config{SNSAccessKeyID: "AKID", SNSSecretAccessKey: "secret"}
+	for _, tt := range tests {
+		cfg.URL = tt.url
+		sink, err := New(cfg, nil)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("New(%q): expected an error", tt.url)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("New(%q): %v", tt.url, err)
+			continue
+		}
+		if got := fmt.Sprintf("%T", sink); got != tt.want {
+			t.Errorf("New(%q) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookSink_Publish(t *testing.T) {
+	secret := []byte("s3cret")
+	var got *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sink, err := NewWebhookSink(server.URL+"/events", secret, server.Client())
+	if err != nil {
+		t.Fatalf("NewWebhookSink failed: %v", err)
+	}
+	event := testEvent()
+	if err := sink.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if got.Method != http.MethodPost || got.URL.Path != "/events" {
+		t.Errorf("request = %s %s", got.Method, got.URL.Path)
+	}
+	if got.Header.Get(HeaderEvent) != event.Type || got.Header.Get(HeaderDelivery) != event.ID {
+		t.Errorf("headers = %v", got.Header)
+	}
+	if sig := got.Header.Get(HeaderSignature); sig != Sign(secret, body) {
+		t.Errorf("signature %q does not match the body", sig)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if env.ID != event.ID || env.Type != event.Type || env.AggregateID != event.AggregateID ||
+		string(env.Payload) != string(event.Payload) || !env.CreatedAt.Equal(event.CreatedAt) {
+		t.Errorf("envelope = %+v", env)
+	}
+}
+
+func TestWebhookSink_PublishRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderSignature) != "" {
+			t.Error("expected no signature without a secret")
+		}
+		http.Error(w, "receiver down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sink, err := NewWebhookSink(server.URL, nil, server.Client())
+	if err != nil {
+		t.Fatalf("NewWebhookSink failed: %v", err)
+	}
+	err = sink.Publish(context.Background(), testEvent())
+	if err == nil || !strings.Contains(err.Error(), "503") || !strings.Contains(err.Error(), "receiver down") {
+		t.Errorf("expected the status and body in the error, got %v", err)
+	}
+}
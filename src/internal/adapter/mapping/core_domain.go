@@ -107,13 +107,13 @@ func ConvertCoreFileResult(coreResult *coreparser.FileResult) analysis.FileResul
 	}
 
 	if coreResult.Err != nil {
-		return analysis.FileResult{Err: coreResult.Err, File: nil}
+		return analysis.FileResult{Err: coreResult.Err, File: nil, Path: coreResult.Path}
 	}
 
 	if coreResult.File == nil {
-		return analysis.FileResult{Err: nil, File: nil}
+		return analysis.FileResult{Err: nil, File: nil, Path: coreResult.Path}
 	}
 
 	converted := convertCoreTestFile(*coreResult.File)
-	return analysis.FileResult{Err: nil, File: &converted}
+	return analysis.FileResult{Err: nil, File: &converted, Path: coreResult.Path}
 }
@@ -0,0 +1,140 @@
+package mapping
+
+import (
+	"path"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/coverage"
+)
+
+var _ coverage.Linker = CoverageLinker{}
+
+// testNameSuffixes are file name markers of test files, checked after the
+// extension is removed. Marker-only file names have no stem and never link.
+var testNameSuffixes = []string{".test", ".spec", "_test", "_spec", "-test", "-spec"}
+
+// layoutDirs are directory names that separate test from source trees, such
+// as src/test/java versus src/main/java, and are ignored when comparing directories.
+var layoutDirs = map[string]bool{
+	"__tests__": true, "java": true, "kotlin": true, "lib": true, "main": true,
+	"spec": true, "specs": true, "src": true, "test": true, "tests": true,
+}
+
+// extFamilies groups extensions whose test and source files link across
+// each other, such as a .test.ts file testing a .js file.
+var extFamilies = map[string]string{
+	".cjs": "js", ".cts": "js", ".js": "js", ".jsx": "js", ".mjs": "js", ".mts": "js",
+	".svelte": "js", ".ts": "js", ".tsx": "js", ".vue": "js",
+	".java": "jvm", ".kt": "jvm", ".kts": "jvm", ".scala": "jvm",
+}
+
+// CoverageLinker attributes covered source files to test files by naming
+// convention: auth_test.go, auth.test.ts, test_auth.py, auth_spec.rb and
+// AuthTest.java all test the auth source file of the same language.
+type CoverageLinker struct{}
+
+// Link returns a link for every test file whose source file is in the report.
+// When several source files share the name, the one with the most trailing
+// directories in common wins, ignoring layout directories like src and test,
+// because reports often use absolute or module-qualified paths. Ties are
+// ambiguous and not linked.
+func (CoverageLinker) Link(sources []coverage.SourceFile, testFiles []coverage.TestFile) []coverage.Link {
+	byStem := make(map[string][]int, len(sources))
+	for i, s := range sources {
+		p := cleanReportPath(s.Path)
+		if _, _, isTest := testStem(p); isTest {
+			continue
+		}
+		key := fileKey(strings.ToLower(strings.TrimSuffix(path.Base(p), path.Ext(p))), path.Ext(p))
+		byStem[key] = append(byStem[key], i)
+	}
+
+	var links []coverage.Link
+	for _, tf := range testFiles {
+		p := cleanReportPath(tf.Path)
+		stem, ext, isTest := testStem(p)
+		if !isTest {
+			continue
+		}
+		candidates := byStem[fileKey(stem, ext)]
+		if len(candidates) == 0 {
+			continue
+		}
+
+		testDirs := layoutFreeDirs(p)
+		best, bestScore, tied := -1, -1, false
+		for _, i := range candidates {
+			score := commonTrailingDirs(testDirs, layoutFreeDirs(cleanReportPath(sources[i].Path)))
+			switch {
+			case score > bestScore:
+				best, bestScore, tied = i, score, false
+			case score == bestScore:
+				tied = true
+			}
+		}
+		if tied {
+			continue
+		}
+		links = append(links, coverage.Link{Source: sources[best], TestFileID: tf.ID})
+	}
+	return links
+}
+
+// testStem returns the lowercase name of the source file a test file is named
+// after, and its extension.
+func testStem(p string) (string, string, bool) {
+	ext := path.Ext(p)
+	name := strings.TrimSuffix(path.Base(p), ext)
+	lower := strings.ToLower(name)
+
+	for _, suffix := range testNameSuffixes {
+		if strings.HasSuffix(lower, suffix) && len(lower) > len(suffix) {
+			return lower[:len(lower)-len(suffix)], ext, true
+		}
+	}
+	if rest, ok := strings.CutPrefix(lower, "test_"); ok && rest != "" {
+		return rest, ext, true
+	}
+	// AuthTest, AuthTests and AuthSpec; the marker is case-sensitive so that
+	// Contest is not a test file.
+	for _, suffix := range []string{"Tests", "Test", "Spec"} {
+		if stem, ok := strings.CutSuffix(name, suffix); ok && stem != "" {
+			return strings.ToLower(stem), ext, true
+		}
+	}
+	return "", ext, false
+}
+
+func fileKey(stem, ext string) string {
+	family, ok := extFamilies[strings.ToLower(ext)]
+	if !ok {
+		family = strings.ToLower(ext)
+	}
+	return family + ":" + stem
+}
+
+func layoutFreeDirs(p string) []string {
+	dir := path.Dir(p)
+	if dir == "." || dir == "/" {
+		return nil
+	}
+	var dirs []string
+	for _, d := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if !layoutDirs[strings.ToLower(d)] {
+			dirs = append(dirs, d)
+		}
+	}
+	return dirs
+}
+
+func commonTrailingDirs(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
+
+func cleanReportPath(p string) string {
+	return strings.TrimPrefix(path.Clean(strings.ReplaceAll(p, "\\", "/")), "./")
+}
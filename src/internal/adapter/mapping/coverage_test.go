@@ -0,0 +1,76 @@
+package mapping
+
+import (
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/coverage"
+)
+
+func TestCoverageLinker_Link(t *testing.T) {
+	sources := []coverage.SourceFile{
+		{LinesCovered: 8, LinesValid: 10, Path: "github.com/acme/app/internal/auth/login.go"},
+		{LinesCovered: 1, LinesValid: 4, Path: "github.com/acme/app/internal/billing/login.go"},
+		{LinesCovered: 5, LinesValid: 5, Path: "/home/runner/work/app/web/src/cart.js"},
+		{LinesCovered: 2, LinesValid: 9, Path: "src\\main\\java\\com\\acme\\Order.java"},
+		{LinesCovered: 3, LinesValid: 3, Path: "app/payments.py"},
+		{LinesCovered: 1, LinesValid: 1, Path: "web/src/cart.test.js"},
+		{LinesCovered: 2, LinesValid: 2, Path: "a/util.go"},
+		{LinesCovered: 2, LinesValid: 2, Path: "b/util.go"},
+	}
+	testFiles := []coverage.TestFile{
+		{ID: "tf-go", Path: "internal/auth/login_test.go"},
+		{ID: "tf-js", Path: "web/src/__tests__/cart.test.ts"},
+		{ID: "tf-java", Path: "src/test/java/com/acme/OrderTest.java"},
+		{ID: "tf-py", Path: "tests/test_payments.py"},
+		{ID: "tf-ambiguous", Path: "util_test.go"},
+		{ID: "tf-unrelated", Path: "internal/auth/session_test.go"},
+		{ID: "tf-other-language", Path: "spec/payments_spec.rb"},
+	}
+
+	links := CoverageLinker{}.Link(sources, testFiles)
+
+	got := make(map[string]string, len(links))
+	for _, l := range links {
+		got[l.TestFileID] = l.Source.Path
+	}
+	want := map[string]string{
+		"tf-go":   "github.com/acme/app/internal/auth/login.go",
+		"tf-js":   "/home/runner/work/app/web/src/cart.js",
+		"tf-java": "src\\main\\java\\com\\acme\\Order.java",
+		"tf-py":   "app/payments.py",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("links = %v, want %v", got, want)
+	}
+	for id, path := range want {
+		if got[id] != path {
+			t.Errorf("test file %s linked to %q, want %q", id, got[id], path)
+		}
+	}
+}
+
+func TestTestStem(t *testing.T) {
+	tests := []struct {
+		path     string
+		wantStem string
+		wantTest bool
+	}{
+		{path: "pkg/auth_test.go", wantStem: "auth", wantTest: true},
+		{path: "src/Cart.spec.tsx", wantStem: "cart", wantTest: true},
+		{path: "tests/test_api.py", wantStem: "api", wantTest: true},
+		{path: "spec/user_spec.rb", wantStem: "user", wantTest: true},
+		{path: "src/OrderServiceTests.cs", wantStem: "orderservice", wantTest: true},
+		{path: "src/Contest.java", wantTest: false},
+		{path: "src/test.js", wantTest: false},
+		{path: "src/auth.go", wantTest: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			stem, _, isTest := testStem(tt.path)
+			if isTest != tt.wantTest || stem != tt.wantStem {
+				t.Errorf("testStem(%q) = %q, %v, want %q, %v", tt.path, stem, isTest, tt.wantStem, tt.wantTest)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/specvital/worker/internal/domain/requirement"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// DefaultEmbeddingMinScore is the cosine similarity at or above which
+// EmbeddingMatcher links a behavior to a requirement.
+const DefaultEmbeddingMinScore = 0.75
+
+var _ requirement.Matcher = (*EmbeddingMatcher)(nil)
+
+// EmbeddingMatcher links requirements to behaviors by the cosine similarity
+// of their embeddings, so paraphrases match without sharing words.
+type EmbeddingMatcher struct {
+	limits
+	embedder specview.Embedder
+}
+
+// NewEmbeddingMatcher creates an EmbeddingMatcher. WithMinScore sets the
+// minimum cosine similarity.
+func NewEmbeddingMatcher(embedder specview.Embedder, opts ...Option) *EmbeddingMatcher {
+	return &EmbeddingMatcher{
+		embedder: embedder,
+		limits:   newLimits(DefaultEmbeddingMinScore, opts),
+	}
+}
+
+// Match embeds requirements and candidates in one call and scores every
+// pair. Similarities are clamped to [0, 1].
+func (m *EmbeddingMatcher) Match(
+	ctx context.Context,
+	requirements []requirement.Requirement,
+	candidates []requirement.Candidate,
+) ([]requirement.Match, error) {
+	if len(requirements) == 0 || len(candidates) == 0 {
+		return nil, nil
+	}
+
+	texts := make([]string, 0, len(requirements)+len(candidates))
+	for _, req := range requirements {
+		texts = append(texts, req.Text())
+	}
+	for _, c := range candidates {
+		texts = append(texts, c.Text)
+	}
+	embeddings, err := m.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embed requirements and behaviors: %w", err)
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d embeddings for %d texts", len(embeddings), len(texts))
+	}
+	reqEmbeddings, candEmbeddings := embeddings[:len(requirements)], embeddings[len(requirements):]
+
+	var matches []requirement.Match
+	for i, req := range requirements {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var scored []requirement.Match
+		for j, c := range candidates {
+			score := min(max(cosine(reqEmbeddings[i], candEmbeddings[j]), 0), 1)
+			if score >= m.minScore {
+				scored = append(scored, requirement.Match{
+					BehaviorID:    c.BehaviorID,
+					RequirementID: req.ID,
+					Score:         score,
+				})
+			}
+		}
+		matches = append(matches, m.top(scored)...)
+	}
+
+	return matches, nil
+}
+
+// cosine returns the cosine similarity of a and b, or 0 when either is empty,
+// zero or their lengths differ.
+func cosine(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
@@ -0,0 +1,120 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/requirement"
+)
+
+// fakeEmbedder returns the fixed embedding of each text.
+type fakeEmbedder struct {
+	calls   int
+	err     error
+	vectors map[string][]float32
+}
+
+func (f *fakeEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = f.vectors[text]
+	}
+	return embeddings, nil
+}
+
+func TestEmbeddingMatcher_Match(t *testing.T) {
+	reqs := []requirement.Requirement{
+		{ID: "r1", Title: "Give customers their money back"},
+		{ID: "r2", Title: "Export invoices to PDF"},
+	}
+	candidates := []requirement.Candidate{
+		{BehaviorID: "b1", Text: "Refunds - Issues a partial refund for card payment"},
+		{BehaviorID: "b2", Text: "Login - Rejects invalid password"},
+	}
+	embedder := &fakeEmbedder{vectors: map[string][]float32{
+		"Give customers their money back":                    {1, 0, 0},
+		"Export invoices to PDF":                             {0, 0, 1},
+		"Refunds - Issues a partial refund for card payment": {0.9, 0.1, 0},
+		"Login - Rejects invalid password":                   {0, 1, -0.5},
+	}}
+
+	matches, err := NewEmbeddingMatcher(embedder).Match(context.Background(), reqs, candidates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if embedder.calls != 1 {
+		t.Errorf("expected one embedding call, got %d", embedder.calls)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d: %+v", len(matches), matches)
+	}
+	if matches[0].RequirementID != "r1" || matches[0].BehaviorID != "b1" {
+		t.Errorf("unexpected match: %+v", matches[0])
+	}
+	if matches[0].Score < 0.99 || matches[0].Score > 1 {
+		t.Errorf("expected a score near 1, got %f", matches[0].Score)
+	}
+}
+
+func TestEmbeddingMatcher_MaxPerRequirement(t *testing.T) {
+	reqs := []requirement.Requirement{{ID: "r1", Title: "Refund payments"}}
+	candidates := []requirement.Candidate{
+		{BehaviorID: "b1", Text: "close"},
+		{BehaviorID: "b2", Text: "exact"},
+		{BehaviorID: "b3", Text: "closer"},
+	}
+	embedder := &fakeEmbedder{vectors: map[string][]float32{
+		"Refund payments": {1, 0},
+		"close":           {0.8, 0.2},
+		"exact":           {1, 0},
+		"closer":          {0.9, 0.1},
+	}}
+
+	matches, err := NewEmbeddingMatcher(embedder, WithMaxPerRequirement(2)).Match(context.Background(), reqs, candidates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 2 || matches[0].BehaviorID != "b2" || matches[1].BehaviorID != "b3" {
+		t.Errorf("expected the two closest behaviors in order, got %+v", matches)
+	}
+}
+
+func TestEmbeddingMatcher_Errors(t *testing.T) {
+	reqs := []requirement.Requirement{{ID: "r1", Title: "x"}}
+	candidates := []requirement.Candidate{{BehaviorID: "b1", Text: "y"}}
+
+	embedErr := errors.New("quota exceeded")
+	if _, err := NewEmbeddingMatcher(&fakeEmbedder{err: embedErr}).Match(context.Background(), reqs, candidates); !errors.Is(err, embedErr) {
+		t.Errorf("expected the embedding error, got %v", err)
+	}
+
+	embedder := &fakeEmbedder{}
+	matches, err := NewEmbeddingMatcher(embedder).Match(context.Background(), reqs, nil)
+	if err != nil || len(matches) != 0 || embedder.calls != 0 {
+		t.Errorf("expected no matches and no embedding call without candidates, got %+v, %v, %d calls", matches, err, embedder.calls)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		a, b []float32
+		want float64
+	}{
+		{[]float32{1, 0}, []float32{2, 0}, 1},
+		{[]float32{1, 0}, []float32{0, 1}, 0},
+		{[]float32{1, 0}, []float32{-1, 0}, -1},
+		{[]float32{1, 0}, []float32{0, 0}, 0},
+		{[]float32{1, 0}, []float32{1}, 0},
+		{nil, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := cosine(tt.a, tt.b); got != tt.want {
+			t.Errorf("cosine(%v, %v) = %f, want %f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
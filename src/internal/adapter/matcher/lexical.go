@@ -0,0 +1,206 @@
+package matcher
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/specvital/worker/internal/domain/requirement"
+)
+
+const (
+	DefaultMinScore          = 0.5
+	DefaultMaxPerRequirement = 3
+	minTokenLength           = 3
+)
+
+// stopwords are dropped so that filler words do not inflate overlap scores.
+var stopwords = map[string]struct{}{
+	"and": {}, "are": {}, "can": {}, "for": {}, "from": {}, "has": {}, "have": {},
+	"into": {}, "not": {}, "should": {}, "shall": {}, "that": {}, "the": {},
+	"their": {}, "then": {}, "this": {}, "when": {}, "will": {}, "with": {},
+	"must": {}, "user": {}, "users": {},
+}
+
+var _ requirement.Matcher = (*LexicalMatcher)(nil)
+
+// LexicalMatcher links requirements to behaviors by token overlap.
+// Works without external services; EmbeddingMatcher replaces it when an
+// embedding model is configured.
+type LexicalMatcher struct {
+	limits
+}
+
+// limits bounds the links a matcher records.
+type limits struct {
+	maxPerRequirement int
+	minScore          float64
+}
+
+// Option configures a matcher.
+type Option func(*limits)
+
+// WithMinScore sets the minimum score for a link to be recorded.
+func WithMinScore(score float64) Option {
+	return func(l *limits) {
+		if score > 0 && score <= 1 {
+			l.minScore = score
+		}
+	}
+}
+
+// WithMaxPerRequirement caps how many behaviors are linked to one requirement.
+func WithMaxPerRequirement(n int) Option {
+	return func(l *limits) {
+		if n > 0 {
+			l.maxPerRequirement = n
+		}
+	}
+}
+
+func newLimits(minScore float64, opts []Option) limits {
+	l := limits{
+		maxPerRequirement: DefaultMaxPerRequirement,
+		minScore:          minScore,
+	}
+	for _, opt := range opts {
+		opt(&l)
+	}
+	return l
+}
+
+// top keeps the best-scoring links of one requirement.
+func (l limits) top(scored []requirement.Match) []requirement.Match {
+	sort.SliceStable(scored, func(a, b int) bool {
+		return scored[a].Score > scored[b].Score
+	})
+	if len(scored) > l.maxPerRequirement {
+		scored = scored[:l.maxPerRequirement]
+	}
+	return scored
+}
+
+// NewLexicalMatcher creates a LexicalMatcher.
+func NewLexicalMatcher(opts ...Option) *LexicalMatcher {
+	return &LexicalMatcher{limits: newLimits(DefaultMinScore, opts)}
+}
+
+// Match scores each requirement against every candidate.
+// Score = share of requirement tokens found in the behavior text, so short behaviors
+// that mention every key term of a requirement rank highest.
+func (m *LexicalMatcher) Match(
+	ctx context.Context,
+	requirements []requirement.Requirement,
+	candidates []requirement.Candidate,
+) ([]requirement.Match, error) {
+	candidateTokens := make([]map[string]struct{}, len(candidates))
+	for i, c := range candidates {
+		candidateTokens[i] = tokenize(c.Text)
+	}
+
+	var matches []requirement.Match
+	for _, req := range requirements {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		reqTokens := tokenize(req.Text())
+		if len(reqTokens) == 0 {
+			continue
+		}
+
+		var scored []requirement.Match
+		for i, c := range candidates {
+			score := overlap(reqTokens, candidateTokens[i])
+			if score >= m.minScore {
+				scored = append(scored, requirement.Match{
+					BehaviorID:    c.BehaviorID,
+					RequirementID: req.ID,
+					Score:         score,
+				})
+			}
+		}
+
+		matches = append(matches, m.top(scored)...)
+	}
+
+	return matches, nil
+}
+
+func overlap(reqTokens, candTokens map[string]struct{}) float64 {
+	hits := 0
+	for tok := range reqTokens {
+		if _, ok := candTokens[tok]; ok {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(reqTokens))
+}
+
+func tokenize(text string) map[string]struct{} {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		if len([]rune(f)) < minTokenLength {
+			continue
+		}
+		if _, skip := stopwords[f]; skip {
+			continue
+		}
+		tokens[stem(f)] = struct{}{}
+	}
+	return tokens
+}
+
+// stem reduces inflected English words to a shared stem, so that
+// "invoice"/"invoices"/"invoiced"/"invoicing" collapse. It is a simplified
+// form of the plural and -ed/-ing steps of the Porter stemmer, with a final
+// "e" dropped so that base forms match their inflections.
+func stem(word string) string {
+	switch {
+	case strings.HasSuffix(word, "sses"):
+		word = strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ies") && len(word)-2 >= minTokenLength:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "ied") && len(word)-2 >= minTokenLength:
+		return strings.TrimSuffix(word, "ied") + "y"
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"):
+		// "address", "status": not plurals
+	default:
+		word = trimSuffix(word, "s")
+	}
+
+	for _, suffix := range []string{"ing", "ed"} {
+		if trimmed := trimSuffix(word, suffix); trimmed != word {
+			word = undouble(trimmed)
+			break
+		}
+	}
+	return trimSuffix(word, "e")
+}
+
+// trimSuffix removes suffix from word unless fewer than minTokenLength bytes would remain.
+func trimSuffix(word, suffix string) string {
+	if len(word)-len(suffix) >= minTokenLength && strings.HasSuffix(word, suffix) {
+		return word[:len(word)-len(suffix)]
+	}
+	return word
+}
+
+// undouble drops the last letter of a doubled final consonant left by -ed or
+// -ing ("stopped" -> "stop"). l, s and z stay doubled, as base words end with
+// them ("billed" -> "bill", "passed" -> "pass").
+func undouble(word string) string {
+	n := len(word)
+	if n-1 < minTokenLength || word[n-1] != word[n-2] {
+		return word
+	}
+	if c := word[n-1]; c < 'a' || c > 'z' || strings.IndexByte("aeioulsz", c) >= 0 {
+		return word
+	}
+	return word[:n-1]
+}
@@ -0,0 +1,117 @@
+package matcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/requirement"
+)
+
+func TestLexicalMatcher_Match(t *testing.T) {
+	reqs := []requirement.Requirement{
+		{ID: "r1", Title: "Refund card payments"},
+		{ID: "r2", Title: "Export invoices to PDF"},
+	}
+	candidates := []requirement.Candidate{
+		{BehaviorID: "b1", Text: "Refunds - Issues a partial refund for card payment"},
+		{BehaviorID: "b2", Text: "Login - Rejects invalid password"},
+	}
+
+	matches, err := NewLexicalMatcher().Match(context.Background(), reqs, candidates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d: %+v", len(matches), matches)
+	}
+	if matches[0].RequirementID != "r1" || matches[0].BehaviorID != "b1" {
+		t.Errorf("unexpected match: %+v", matches[0])
+	}
+	if matches[0].Score != 1.0 {
+		t.Errorf("expected full overlap score, got %f", matches[0].Score)
+	}
+}
+
+func TestLexicalMatcher_MaxPerRequirement(t *testing.T) {
+	reqs := []requirement.Requirement{{ID: "r1", Title: "Refund payments"}}
+	candidates := []requirement.Candidate{
+		{BehaviorID: "b1", Text: "refund payment"},
+		{BehaviorID: "b2", Text: "refunds payments"},
+		{BehaviorID: "b3", Text: "refunded"},
+	}
+
+	matches, err := NewLexicalMatcher(WithMaxPerRequirement(2)).Match(context.Background(), reqs, candidates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(matches))
+	}
+	if matches[0].Score < matches[1].Score {
+		t.Error("expected matches sorted by descending score")
+	}
+}
+
+func TestLexicalMatcher_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewLexicalMatcher().Match(ctx, []requirement.Requirement{{ID: "r1", Title: "x"}}, nil)
+	if err == nil {
+		t.Error("expected context error")
+	}
+}
+
+func TestWithMinScore_IgnoresInvalid(t *testing.T) {
+	m := NewLexicalMatcher(WithMinScore(0), WithMinScore(1.5))
+	if m.minScore != DefaultMinScore {
+		t.Errorf("expected default min score, got %f", m.minScore)
+	}
+}
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"refund", "refunds", "refunded", "refunding"}, "refund"},
+		{[]string{"invoice", "invoices", "invoiced", "invoicing"}, "invoic"},
+		{[]string{"license", "licenses", "licensed", "licensing"}, "licens"},
+		{[]string{"response", "responses"}, "respons"},
+		{[]string{"box", "boxes"}, "box"},
+		{[]string{"match", "matches", "matched", "matching"}, "match"},
+		{[]string{"address", "addresses", "addressed"}, "address"},
+		{[]string{"process", "processes", "processed"}, "process"},
+		{[]string{"status", "statuses"}, "status"},
+		{[]string{"policy", "policies"}, "policy"},
+		{[]string{"apply", "applied"}, "apply"},
+		{[]string{"stop", "stopped", "stopping"}, "stop"},
+		{[]string{"bill", "billed", "billing"}, "bill"},
+		{[]string{"pass", "passes", "passed"}, "pass"},
+		{[]string{"add", "added"}, "add"},
+		{[]string{"thing", "things"}, "thing"},
+		{[]string{"use", "uses"}, "use"},
+	}
+
+	for _, tt := range tests {
+		for _, word := range tt.words {
+			if got := stem(word); got != tt.want {
+				t.Errorf("stem(%q) = %q, want %q", word, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestLexicalMatcher_MatchesInflections(t *testing.T) {
+	reqs := []requirement.Requirement{{ID: "r1", Title: "Export invoices"}}
+	candidates := []requirement.Candidate{{BehaviorID: "b1", Text: "Exports the invoice as PDF"}}
+
+	matches, err := NewLexicalMatcher().Match(context.Background(), reqs, candidates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Score != 1.0 {
+		t.Errorf("expected a full match of the plural, got %+v", matches)
+	}
+}
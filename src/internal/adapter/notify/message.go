@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/notification"
+)
+
+// Embed colors of Discord messages.
+const (
+	colorFailure = 0xE01E5A
+	colorSuccess = 0x2EB67D
+)
+
+// field is one labelled line of a message.
+type field struct {
+	name  string
+	value string
+}
+
+func title(o notification.Outcome) string {
+	what := "Analysis of "
+	if o.Subject == notification.SubjectSpecDocument {
+		what = "Spec document of "
+	}
+	if o.Failed() {
+		return what + o.RepoLabel() + " failed"
+	}
+	return what + o.RepoLabel() + " succeeded"
+}
+
+// fields lists what the message reports, skipping what is unknown.
+func fields(o notification.Outcome) []field {
+	var fs []field
+	if o.CommitSHA != "" {
+		commit := shortSHA(o.CommitSHA)
+		if o.Branch != "" {
+			commit += " on " + o.Branch
+		}
+		fs = append(fs, field{name: "Commit", value: commit})
+	}
+	if !o.Failed() {
+		switch o.Subject {
+		case notification.SubjectAnalysis:
+			fs = append(fs, field{name: "Tests", value: strconv.Itoa(o.TestCount)})
+		case notification.SubjectSpecDocument:
+			fs = append(fs, field{name: "Domains", value: strconv.Itoa(o.DomainCount)})
+		}
+	}
+	if o.Duration > 0 {
+		fs = append(fs, field{name: "Duration", value: formatDuration(o.Duration)})
+	}
+	if o.Failed() {
+		fs = append(fs, field{name: "Error", value: o.ErrorClass})
+	}
+	return fs
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
+func status(o notification.Outcome) string {
+	if o.Failed() {
+		return "failed"
+	}
+	return "succeeded"
+}
+
+func eventName(o notification.Outcome) string {
+	return fmt.Sprintf("%s.%s", o.Subject, status(o))
+}
@@ -0,0 +1,215 @@
+// Package notify sends job outcomes to Slack, Discord and generic webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/notification"
+)
+
+var _ notification.Sender = (*HTTPSender)(nil)
+
+// ErrBlockedAddress is returned when a channel URL resolves to an address
+// that notification.IsPublicAddr refuses.
+var ErrBlockedAddress = errors.New("channel address is not public")
+
+// NewHTTPClient creates the client channels are posted with. Channel URLs are
+// user input, so to keep them from reaching internal services the client
+// checks every address it dials after DNS resolution, ignores proxies (which
+// would dial on its behalf) and never follows redirects.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Control:   rejectNonPublic,
+		KeepAlive: 30 * time.Second,
+		Timeout:   10 * time.Second,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+// rejectNonPublic is a net.Dialer Control hook; address is the resolved
+// IP and port about to be connected to.
+func rejectNonPublic(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	if !notification.IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// HTTPSender POSTs each outcome to the channel URL in the payload its kind
+// expects; any 2xx response accepts it.
+type HTTPSender struct {
+	httpClient *http.Client
+}
+
+// NewHTTPSender creates a sender using httpClient, which should be one made by
+// NewHTTPClient: a channel is an arbitrary URL.
+func NewHTTPSender(httpClient *http.Client) (*HTTPSender, error) {
+	if httpClient == nil {
+		return nil, errors.New("http client is required")
+	}
+	return &HTTPSender{httpClient: httpClient}, nil
+}
+
+func (s *HTTPSender) Send(ctx context.Context, channel notification.Channel, outcome notification.Outcome) error {
+	// Channels stored before http URLs were refused are not sent to either.
+	if u, err := url.Parse(channel.URL); err != nil || u.Scheme != "https" {
+		return fmt.Errorf("%w: channel URL is not an https URL", notification.ErrInvalidInput)
+	}
+	body, err := payload(channel.Kind, outcome)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("post notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Redirects are not followed, so a 3xx response fails the send here.
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("post notification: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func payload(kind notification.ChannelKind, o notification.Outcome) ([]byte, error) {
+	var v any
+	switch kind {
+	case notification.ChannelSlack:
+		v = slackMessage(o)
+	case notification.ChannelDiscord:
+		v = discordMessage(o)
+	case notification.ChannelWebhook:
+		v = webhookMessage(o)
+	default:
+		return nil, fmt.Errorf("%w: unknown channel kind %q", notification.ErrInvalidInput, kind)
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s message: %w", kind, err)
+	}
+	return body, nil
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// slackMessage is a mrkdwn text message, which incoming webhooks accept
+// without an app.
+func slackMessage(o notification.Outcome) slackPayload {
+	var b strings.Builder
+	b.WriteString("*" + title(o) + "*")
+	for _, f := range fields(o) {
+		fmt.Fprintf(&b, "\n%s: `%s`", f.name, f.value)
+	}
+	return slackPayload{Text: b.String()}
+}
+
+type discordPayload struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Color  int            `json:"color"`
+	Fields []discordField `json:"fields,omitempty"`
+	Title  string         `json:"title"`
+}
+
+type discordField struct {
+	Inline bool   `json:"inline"`
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+}
+
+func discordMessage(o notification.Outcome) discordPayload {
+	embed := discordEmbed{Color: colorSuccess, Title: title(o)}
+	if o.Failed() {
+		embed.Color = colorFailure
+	}
+	for _, f := range fields(o) {
+		embed.Fields = append(embed.Fields, discordField{Inline: true, Name: f.name, Value: f.value})
+	}
+	return discordPayload{Embeds: []discordEmbed{embed}}
+}
+
+// webhookPayload is the outcome as generic webhooks receive it. Counts are
+// sent only on success, where they are meaningful.
+type webhookPayload struct {
+	AnalysisID      string  `json:"analysis_id,omitempty"`
+	Branch          string  `json:"branch,omitempty"`
+	CommitSHA       string  `json:"commit_sha,omitempty"`
+	DocumentID      string  `json:"document_id,omitempty"`
+	DomainCount     *int    `json:"domain_count,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	ErrorClass      string  `json:"error_class,omitempty"`
+	Event           string  `json:"event"`
+	Host            string  `json:"host"`
+	JobID           int64   `json:"job_id"`
+	Owner           string  `json:"owner"`
+	Repo            string  `json:"repo"`
+	Status          string  `json:"status"`
+	TestCount       *int    `json:"test_count,omitempty"`
+}
+
+func webhookMessage(o notification.Outcome) webhookPayload {
+	p := webhookPayload{
+		AnalysisID:      o.AnalysisID,
+		Branch:          o.Branch,
+		CommitSHA:       o.CommitSHA,
+		DocumentID:      o.DocumentID,
+		DurationSeconds: o.Duration.Seconds(),
+		ErrorClass:      o.ErrorClass,
+		Event:           eventName(o),
+		Host:            o.Host,
+		JobID:           o.JobID,
+		Owner:           o.Owner,
+		Repo:            o.Repo,
+		Status:          status(o),
+	}
+	if p.Host == "" {
+		p.Host = "github.com"
+	}
+	if !o.Failed() {
+		switch o.Subject {
+		case notification.SubjectAnalysis:
+			p.TestCount = &o.TestCount
+		case notification.SubjectSpecDocument:
+			p.DomainCount = &o.DomainCount
+		}
+	}
+	return p
+}
@@ -0,0 +1,193 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/notification"
+)
+
+func testOutcome() notification.Outcome {
+	return notification.Outcome{
+		AnalysisID: "5f0c2a7e-3c1e-4d7a-9b8e-3f0a1c2d4e5f",
+		Branch:     "main",
+		CommitSHA:  "0123456789abcdef0123456789abcdef01234567",
+		Duration:   83 * time.Second,
+		Host:       "github.com",
+		JobID:      42,
+		Owner:      "octocat",
+		Repo:       "hello-world",
+		Subject:    notification.SubjectAnalysis,
+		TestCount:  128,
+	}
+}
+
+func TestHTTPSender_Send(t *testing.T) {
+	var gotBody []byte
+	var gotType string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender, err := NewHTTPSender(server.Client())
+	if err != nil {
+		t.Fatalf("NewHTTPSender: %v", err)
+	}
+
+	t.Run("slack", func(t *testing.T) {
+		channel := notification.Channel{Kind: notification.ChannelSlack, URL: server.URL}
+		if err := sender.Send(context.Background(), channel, testOutcome()); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+		var msg slackPayload
+		if err := json.Unmarshal(gotBody, &msg); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		for _, want := range []string{"*Analysis of octocat/hello-world succeeded*", "Commit: `0123456789ab on main`", "Tests: `128`", "Duration: `1m23s`"} {
+			if !strings.Contains(msg.Text, want) {
+				t.Errorf("text %q missing %q", msg.Text, want)
+			}
+		}
+		if gotType != "application/json" {
+			t.Errorf("Content-Type = %q", gotType)
+		}
+	})
+
+	t.Run("discord failure", func(t *testing.T) {
+		outcome := testOutcome()
+		outcome.ErrorClass = "clone_failed"
+		channel := notification.Channel{Kind: notification.ChannelDiscord, URL: server.URL}
+		if err := sender.Send(context.Background(), channel, outcome); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+		var msg discordPayload
+		if err := json.Unmarshal(gotBody, &msg); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if len(msg.Embeds) != 1 {
+			t.Fatalf("expected one embed, got %d", len(msg.Embeds))
+		}
+		embed := msg.Embeds[0]
+		if embed.Color != colorFailure || embed.Title != "Analysis of octocat/hello-world failed" {
+			t.Errorf("unexpected embed %+v", embed)
+		}
+		var hasError, hasTests bool
+		for _, f := range embed.Fields {
+			hasError = hasError || (f.Name == "Error" && f.Value == "clone_failed")
+			hasTests = hasTests || f.Name == "Tests"
+		}
+		if !hasError || hasTests {
+			t.Errorf("expected the error class and no test count, got %+v", embed.Fields)
+		}
+	})
+
+	t.Run("webhook spec document", func(t *testing.T) {
+		outcome := testOutcome()
+		outcome.Subject = notification.SubjectSpecDocument
+		outcome.DomainCount = 7
+		outcome.Host = ""
+		channel := notification.Channel{Kind: notification.ChannelWebhook, URL: server.URL}
+		if err := sender.Send(context.Background(), channel, outcome); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+		var msg webhookPayload
+		if err := json.Unmarshal(gotBody, &msg); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if msg.Event != "spec_document.succeeded" || msg.Host != "github.com" || msg.DurationSeconds != 83 {
+			t.Errorf("unexpected payload %+v", msg)
+		}
+		if msg.DomainCount == nil || *msg.DomainCount != 7 || msg.TestCount != nil {
+			t.Errorf("expected only the domain count, got %+v", msg)
+		}
+	})
+}
+
+func TestHTTPSender_SendRejected(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	sender, _ := NewHTTPSender(server.Client())
+	channel := notification.Channel{Kind: notification.ChannelSlack, URL: server.URL}
+	err := sender.Send(context.Background(), channel, testOutcome())
+	if err == nil || !strings.Contains(err.Error(), "status 403: invalid_token") {
+		t.Errorf("expected the status and body in the error, got %v", err)
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	sender, err := NewHTTPSender(NewHTTPClient(time.Second))
+	if err != nil {
+		t.Fatalf("NewHTTPSender: %v", err)
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request should not reach the server")
+	}))
+	defer server.Close()
+
+	send := func(url string) error {
+		channel := notification.Channel{Kind: notification.ChannelWebhook, URL: url}
+		return sender.Send(context.Background(), channel, testOutcome())
+	}
+
+	t.Run("http URLs are refused", func(t *testing.T) {
+		for _, url := range []string{"http://127.0.0.1/hook", "http://169.254.169.254/latest/meta-data/"} {
+			if err := send(url); !errors.Is(err, notification.ErrInvalidInput) {
+				t.Errorf("%s: expected ErrInvalidInput, got %v", url, err)
+			}
+		}
+	})
+
+	t.Run("non-public addresses are refused after resolution", func(t *testing.T) {
+		port := server.URL[strings.LastIndex(server.URL, ":"):]
+		for _, url := range []string{
+			server.URL,
+			"https://localhost" + port,
+			"https://169.254.169.254/latest/meta-data/",
+			"https://10.0.0.1/hook",
+		} {
+			if err := send(url); !errors.Is(err, ErrBlockedAddress) {
+				t.Errorf("%s: expected ErrBlockedAddress, got %v", url, err)
+			}
+		}
+	})
+}
+
+func TestNewHTTPClient_DoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://10.0.0.1/hook", http.StatusFound)
+	}))
+	defer server.Close()
+
+	// The test server is on loopback, so only the redirect policy of
+	// NewHTTPClient is kept.
+	client := NewHTTPClient(time.Second)
+	client.Transport = server.Client().Transport
+	sender, _ := NewHTTPSender(client)
+
+	channel := notification.Channel{Kind: notification.ChannelWebhook, URL: server.URL}
+	err := sender.Send(context.Background(), channel, testOutcome())
+	if err == nil || !strings.Contains(err.Error(), "status 302") {
+		t.Errorf("expected the redirect to fail the send, got %v", err)
+	}
+}
+
+func TestRepoLabel(t *testing.T) {
+	outcome := testOutcome()
+	outcome.Host = "gitlab.com"
+	if got := title(outcome); got != "Analysis of gitlab.com/octocat/hello-world succeeded" {
+		t.Errorf("title = %q", got)
+	}
+}
@@ -2,20 +2,61 @@ package parser
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime"
+	"slices"
+	"strings"
 
 	coreparser "github.com/specvital/core/pkg/parser"
 	"github.com/specvital/core/pkg/source"
 	"github.com/specvital/worker/internal/adapter/mapping"
 	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/infra/metrics"
+)
+
+var (
+	_ analysis.FilteringParser   = (*CoreParser)(nil)
+	_ analysis.IncrementalParser = (*CoreParser)(nil)
 )
 
 // CoreParser implements analysis.Parser using specvital/core's parser package.
-type CoreParser struct{}
+// The core parser parses files on a pool of goroutines; batch scans return
+// files sorted by path, streaming scans in the order files finish parsing.
+type CoreParser struct {
+	workers int
+}
+
+// Option configures a CoreParser.
+type Option func(*CoreParser)
+
+// WithWorkers sets how many files are parsed concurrently in one scan. Parsing
+// is CPU-bound, so values above GOMAXPROCS are capped to it.
+// Zero or negative values are ignored and GOMAXPROCS is used.
+func WithWorkers(n int) Option {
+	return func(p *CoreParser) {
+		if n > 0 {
+			p.workers = n
+		}
+	}
+}
 
 // NewCoreParser creates a new CoreParser.
-func NewCoreParser() *CoreParser {
-	return &CoreParser{}
+func NewCoreParser(opts ...Option) *CoreParser {
+	p := &CoreParser{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// parseWorkers returns the scan's parser pool size.
+func (p *CoreParser) parseWorkers() int {
+	procs := runtime.GOMAXPROCS(0)
+	if p.workers <= 0 {
+		return procs
+	}
+	return min(p.workers, procs)
 }
 
 // coreSourceProvider is implemented by sources that can provide
@@ -27,43 +68,202 @@ type coreSourceProvider interface {
 // Scan implements analysis.Parser by delegating to the core parser
 // and converting the result to domain types.
 func (p *CoreParser) Scan(ctx context.Context, src analysis.Source) (*analysis.Inventory, error) {
+	return p.ScanFiltered(ctx, src, nil)
+}
+
+// ScanFiltered implements analysis.FilteringParser. Include patterns and
+// excluded directory names narrow the core parser's discovery; files matched
+// by the remaining exclude patterns are dropped after parsing.
+func (p *CoreParser) ScanFiltered(ctx context.Context, src analysis.Source, filters *analysis.PathFilters) (inv *analysis.Inventory, err error) {
+	defer recoverPanic(&err)
+
 	provider, ok := src.(coreSourceProvider)
 	if !ok {
 		return nil, fmt.Errorf("source does not implement coreSourceProvider interface")
 	}
+	return p.scan(ctx, provider.CoreSource(), filters)
+}
 
-	result, err := coreparser.Scan(ctx, provider.CoreSource())
+// ScanIncremental implements analysis.IncrementalParser, filtering the same
+// way as ScanFiltered. Unchanged files are reported after being read and
+// hashed, but before framework detection and parsing.
+func (p *CoreParser) ScanIncremental(ctx context.Context, src analysis.Source, filters *analysis.PathFilters, known analysis.FileHashes) (inv *analysis.Inventory, err error) {
+	defer recoverPanic(&err)
+
+	provider, ok := src.(coreSourceProvider)
+	if !ok {
+		return nil, fmt.Errorf("source does not implement coreSourceProvider interface")
+	}
+	return p.scan(ctx, newHashingSource(provider.CoreSource(), known), filters)
+}
+
+const (
+	// phaseParsing is the core parser's ScanError phase for per-file parse failures.
+	phaseParsing = "parsing"
+	// confidenceUnknown is the core parser's detection confidence for files no
+	// parser strategy matched.
+	confidenceUnknown = "unknown"
+)
+
+func (p *CoreParser) scan(ctx context.Context, src source.Source, filters *analysis.PathFilters) (*analysis.Inventory, error) {
+	result, err := coreparser.Scan(ctx, src, p.scanOptions(filters)...)
 	if err != nil {
 		return nil, fmt.Errorf("core parser scan: %w", err)
 	}
 
-	return mapping.ConvertCoreToDomainInventory(result.Inventory), nil
+	inv := mapping.ConvertCoreToDomainInventory(result.Inventory)
+	if inv == nil {
+		return nil, nil
+	}
+
+	// The batch scan only counts unmatched files, so their language is unknown.
+	inv.UnmatchedFiles = result.Stats.ConfidenceDist[confidenceUnknown]
+	metrics.ParserFiles.WithLabelValues(analysis.LanguageUnknown, metrics.ParseUnmatched).Add(float64(inv.UnmatchedFiles))
+
+	hashes, _ := src.(*hashingSource)
+	for _, scanErr := range result.Errors {
+		switch {
+		case errors.Is(scanErr.Err, errUnchanged):
+			inv.Files = append(inv.Files, *hashes.unchangedFile(scanErr.Path))
+		case scanErr.Phase == phaseParsing && scanErr.Path != "" && filters.Matches(scanErr.Path):
+			countParsed(scanErr.Path, metrics.ParseFailed)
+			inv.ParseErrors = append(inv.ParseErrors, analysis.NewParseError(scanErr.Path, scanErr.Err))
+		}
+	}
+
+	kept := make([]analysis.TestFile, 0, len(inv.Files))
+	for _, f := range inv.Files {
+		if filters.Matches(f.Path) {
+			if !f.Unchanged {
+				countParsed(f.Path, metrics.ParseMatched)
+			}
+			hashes.attach(&f)
+			kept = append(kept, f)
+		}
+	}
+	slices.SortFunc(kept, func(a, b analysis.TestFile) int { return strings.Compare(a.Path, b.Path) })
+	inv.Files = kept
+	// Parse errors arrive in the order files finish parsing.
+	slices.SortFunc(inv.ParseErrors, func(a, b analysis.ParseError) int { return strings.Compare(a.FilePath, b.FilePath) })
+	return inv, nil
 }
 
 // ScanStream implements analysis.StreamingParser by delegating to the core parser's
 // ScanStreaming and converting results to domain types.
 func (p *CoreParser) ScanStream(ctx context.Context, src analysis.Source) (<-chan analysis.FileResult, error) {
+	return p.ScanStreamFiltered(ctx, src, nil)
+}
+
+// ScanStreamFiltered implements analysis.FilteringParser for streaming scans,
+// filtering the same way as ScanFiltered.
+func (p *CoreParser) ScanStreamFiltered(ctx context.Context, src analysis.Source, filters *analysis.PathFilters) (ch <-chan analysis.FileResult, err error) {
+	defer recoverPanic(&err)
+
+	provider, ok := src.(coreSourceProvider)
+	if !ok {
+		return nil, fmt.Errorf("source does not implement coreSourceProvider interface")
+	}
+	return p.scanStream(ctx, provider.CoreSource(), filters)
+}
+
+// ScanStreamIncremental implements analysis.IncrementalParser for streaming
+// scans, skipping unchanged files the same way as ScanIncremental.
+func (p *CoreParser) ScanStreamIncremental(ctx context.Context, src analysis.Source, filters *analysis.PathFilters, known analysis.FileHashes) (ch <-chan analysis.FileResult, err error) {
+	defer recoverPanic(&err)
+
 	provider, ok := src.(coreSourceProvider)
 	if !ok {
 		return nil, fmt.Errorf("source does not implement coreSourceProvider interface")
 	}
+	return p.scanStream(ctx, newHashingSource(provider.CoreSource(), known), filters)
+}
 
-	coreCh, err := coreparser.ScanStreaming(ctx, provider.CoreSource())
+func (p *CoreParser) scanStream(ctx context.Context, src source.Source, filters *analysis.PathFilters) (<-chan analysis.FileResult, error) {
+	coreCh, err := coreparser.ScanStreaming(ctx, src, p.scanOptions(filters)...)
 	if err != nil {
 		return nil, fmt.Errorf("core parser scan stream: %w", err)
 	}
 
+	hashes, _ := src.(*hashingSource)
+
 	domainCh := make(chan analysis.FileResult)
 	go func() {
 		defer close(domainCh)
+		defer func() {
+			if r := recover(); r != nil {
+				select {
+				case <-ctx.Done():
+				case domainCh <- analysis.FileResult{Err: fmt.Errorf("%w: %v", analysis.ErrParserPanic, r)}:
+				}
+			}
+		}()
 		for coreResult := range coreCh {
+			var result analysis.FileResult
+			if coreResult != nil && errors.Is(coreResult.Err, errUnchanged) {
+				result = analysis.FileResult{File: hashes.unchangedFile(coreResult.Path), Path: coreResult.Path}
+			} else {
+				result = mapping.ConvertCoreFileResult(coreResult)
+			}
+			if result.File != nil && !filters.Matches(result.File.Path) {
+				continue
+			}
+			if result.File == nil && result.Path != "" && !filters.Matches(result.Path) {
+				continue
+			}
+			switch {
+			case result.File != nil:
+				if !result.File.Unchanged {
+					countParsed(result.File.Path, metrics.ParseMatched)
+				}
+			case result.Path == "":
+				// A discovery error, not tied to a file.
+			case result.Err != nil:
+				countParsed(result.Path, metrics.ParseFailed)
+			default:
+				countParsed(result.Path, metrics.ParseUnmatched)
+			}
+			hashes.attach(result.File)
 			select {
 			case <-ctx.Done():
 				return
-			case domainCh <- mapping.ConvertCoreFileResult(coreResult):
+			case domainCh <- result:
 			}
 		}
 	}()
 
 	return domainCh, nil
 }
+
+// countParsed records parser coverage for a file the parser strategies ran on.
+// Callers skip unchanged files, which are never detected or parsed.
+func countParsed(filePath, result string) {
+	metrics.ParserFiles.WithLabelValues(analysis.LanguageOf(filePath), result).Inc()
+}
+
+// scanOptions sizes the parser pool, pushes the filters the core parser supports
+// into its discovery, and isolates parser panics per file.
+func (p *CoreParser) scanOptions(filters *analysis.PathFilters) []coreparser.ScanOption {
+	opts := []coreparser.ScanOption{
+		coreparser.WithRegistry(recoveringRegistry()),
+		coreparser.WithWorkers(p.parseWorkers()),
+	}
+	if filters.IsEmpty() {
+		return opts
+	}
+	if len(filters.Include) > 0 {
+		opts = append(opts, coreparser.WithPatterns(filters.Include))
+	}
+	if dirs := filters.ExcludedDirNames(); len(dirs) > 0 {
+		opts = append(opts, coreparser.WithExcludePatterns(dirs))
+	}
+	return opts
+}
+
+// recoverPanic turns a panic in the parser into analysis.ErrParserPanic, so a
+// panic outside per-file parsing fails the analysis instead of crashing the
+// worker. It must be deferred directly for recover to take effect.
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%w: %v", analysis.ErrParserPanic, r)
+	}
+}
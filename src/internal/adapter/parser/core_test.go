@@ -1,11 +1,20 @@
 package parser
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	_ "github.com/specvital/core/pkg/parser/strategies/all"
+	"github.com/specvital/core/pkg/source"
 	"github.com/specvital/worker/internal/domain/analysis"
 )
 
@@ -61,3 +70,199 @@ func (m *mockInvalidSource) VerifyCommitExists(_ context.Context, _ string) (boo
 }
 
 // Conversion tests moved to adapter/mapping/core_domain_test.go
+
+func TestCoreParser_ScanFiltered(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{
+		"services/payments/charge_test.go",
+		"services/payments/vendor/lib/lib_test.go",
+		"services/payments/gen/api_test.go",
+		"services/users/user_test.go",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		content := "package x\n\nimport \"testing\"\n\nfunc TestX(t *testing.T) {}\n"
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	local, err := source.NewLocalSource(root)
+	if err != nil {
+		t.Fatalf("NewLocalSource failed: %v", err)
+	}
+	src := &localSourceAdapter{src: local}
+	filters := &analysis.PathFilters{
+		Exclude: []string{"**/vendor/**", "services/payments/gen/**"},
+		Include: []string{"services/payments/**"},
+	}
+	want := []string{"services/payments/charge_test.go"}
+
+	t.Run("batch", func(t *testing.T) {
+		inv, err := NewCoreParser().ScanFiltered(context.Background(), src, filters)
+		if err != nil {
+			t.Fatalf("ScanFiltered failed: %v", err)
+		}
+		var got []string
+		for _, f := range inv.Files {
+			got = append(got, f.Path)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("files = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		ch, err := NewCoreParser().ScanStreamFiltered(context.Background(), src, filters)
+		if err != nil {
+			t.Fatalf("ScanStreamFiltered failed: %v", err)
+		}
+		var got []string
+		for result := range ch {
+			if result.Err != nil {
+				t.Fatalf("unexpected result error: %v", result.Err)
+			}
+			got = append(got, result.File.Path)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("files = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestCoreParser_ScanIncremental(t *testing.T) {
+	root := t.TempDir()
+	contents := map[string]string{
+		"pkg/a_test.go": "package pkg\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n",
+		"pkg/b_test.go": "package pkg\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) {}\n",
+	}
+	for f, content := range contents {
+		path := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	local, err := source.NewLocalSource(root)
+	if err != nil {
+		t.Fatalf("NewLocalSource failed: %v", err)
+	}
+	src := &localSourceAdapter{src: local}
+
+	hashOf := func(f string) []byte {
+		sum := sha256.Sum256([]byte(contents[f]))
+		return sum[:]
+	}
+	known := analysis.FileHashes{
+		"pkg/a_test.go": hashOf("pkg/a_test.go"),
+		"pkg/b_test.go": hashOf("pkg/a_test.go"),
+	}
+
+	check := func(t *testing.T, files []analysis.TestFile) {
+		t.Helper()
+		if len(files) != 2 {
+			t.Fatalf("expected 2 files, got %d", len(files))
+		}
+		for _, f := range files {
+			if !bytes.Equal(f.ContentHash, hashOf(f.Path)) {
+				t.Errorf("%s: content hash = %x, want %x", f.Path, f.ContentHash, hashOf(f.Path))
+			}
+			switch f.Path {
+			case "pkg/a_test.go":
+				if !f.Unchanged || f.Framework != "" || len(f.Tests) != 0 {
+					t.Errorf("%s: expected an unchanged placeholder, got %+v", f.Path, f)
+				}
+			case "pkg/b_test.go":
+				if f.Unchanged || f.Framework == "" {
+					t.Errorf("%s: expected a parsed file, got %+v", f.Path, f)
+				}
+			}
+		}
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		inv, err := NewCoreParser().ScanIncremental(context.Background(), src, nil, known)
+		if err != nil {
+			t.Fatalf("ScanIncremental failed: %v", err)
+		}
+		check(t, inv.Files)
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		ch, err := NewCoreParser().ScanStreamIncremental(context.Background(), src, nil, known)
+		if err != nil {
+			t.Fatalf("ScanStreamIncremental failed: %v", err)
+		}
+		var files []analysis.TestFile
+		for result := range ch {
+			if result.Err != nil {
+				t.Fatalf("unexpected result error: %v", result.Err)
+			}
+			if result.File != nil {
+				files = append(files, *result.File)
+			}
+		}
+		check(t, files)
+	})
+}
+
+func TestCoreParser_ParseWorkers(t *testing.T) {
+	procs := runtime.GOMAXPROCS(0)
+	tests := []struct {
+		name string
+		opts []Option
+		want int
+	}{
+		{"default uses GOMAXPROCS", nil, procs},
+		{"zero is ignored", []Option{WithWorkers(0)}, procs},
+		{"fewer than GOMAXPROCS", []Option{WithWorkers(1)}, 1},
+		{"capped to GOMAXPROCS", []Option{WithWorkers(procs + 8)}, procs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewCoreParser(tt.opts...).parseWorkers(); got != tt.want {
+				t.Errorf("parseWorkers() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoreParser_Scan_DeterministicOrder(t *testing.T) {
+	root := t.TempDir()
+	var want []string
+	for i := range 40 {
+		f := fmt.Sprintf("pkg%d/x%02d_test.go", i%4, i)
+		path := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		content := fmt.Sprintf("package pkg\n\nimport \"testing\"\n\nfunc TestX%d(t *testing.T) {}\n", i)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, f)
+	}
+	slices.Sort(want)
+	local, err := source.NewLocalSource(root)
+	if err != nil {
+		t.Fatalf("NewLocalSource failed: %v", err)
+	}
+	src := &localSourceAdapter{src: local}
+
+	for _, workers := range []int{1, 8} {
+		inv, err := NewCoreParser(WithWorkers(workers)).Scan(context.Background(), src)
+		if err != nil {
+			t.Fatalf("Scan with %d workers failed: %v", workers, err)
+		}
+		got := make([]string, 0, len(inv.Files))
+		for _, f := range inv.Files {
+			got = append(got, f.Path)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("files with %d workers = %v, want %v", workers, got, want)
+		}
+	}
+}
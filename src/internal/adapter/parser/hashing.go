@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/specvital/core/pkg/source"
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+// errUnchanged refuses to open a file whose content matches a known hash. The
+// core parser reports it as a parse error of that file and moves on, so the
+// file is never detected or parsed.
+var errUnchanged = errors.New("file content unchanged")
+
+// hashingSource hashes every test file the core parser reads.
+type hashingSource struct {
+	source.Source
+
+	known analysis.FileHashes
+
+	mu     sync.Mutex
+	hashes map[string][]byte
+}
+
+func newHashingSource(src source.Source, known analysis.FileHashes) *hashingSource {
+	return &hashingSource{
+		Source: src,
+		known:  known,
+		hashes: make(map[string][]byte),
+	}
+}
+
+func (s *hashingSource) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	rc, err := s.Source.Open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("read file %s: %w", path, err)
+	}
+
+	sum := sha256.Sum256(content)
+	s.mu.Lock()
+	s.hashes[path] = sum[:]
+	s.mu.Unlock()
+
+	if known, ok := s.known[path]; ok && bytes.Equal(known, sum[:]) {
+		return nil, errUnchanged
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (s *hashingSource) hash(path string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hashes[path]
+}
+
+// attach sets the content hash of a parsed file. Safe to call on nil.
+func (s *hashingSource) attach(file *analysis.TestFile) {
+	if s == nil || file == nil {
+		return
+	}
+	file.ContentHash = s.hash(file.Path)
+}
+
+// unchangedFile returns the placeholder reported for an unchanged file.
+func (s *hashingSource) unchangedFile(path string) *analysis.TestFile {
+	return &analysis.TestFile{Path: path, ContentHash: s.hash(path), Unchanged: true}
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/specvital/core/pkg/domain"
+	"github.com/specvital/core/pkg/parser/framework"
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+// recoveringRegistry copies the default framework registry with every parser
+// wrapped in panic recovery. The core parser parses each file on its own
+// goroutine, where recoverPanic cannot reach: a panic there would crash the
+// worker. Wrapped, it becomes that file's parse error and the file is quarantined.
+var recoveringRegistry = sync.OnceValue(func() *framework.Registry {
+	registry := framework.NewRegistry()
+	for _, def := range framework.DefaultRegistry().All() {
+		wrapped := *def
+		if def.Parser != nil {
+			wrapped.Parser = recoveringParser{def.Parser}
+		}
+		if def.ConfigParser != nil {
+			wrapped.ConfigParser = recoveringConfigParser{def.ConfigParser}
+		}
+		registry.Register(&wrapped)
+	}
+	return registry
+})
+
+type recoveringParser struct {
+	framework.Parser
+}
+
+func (p recoveringParser) Parse(ctx context.Context, source []byte, filename string) (file *domain.TestFile, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			file, err = nil, fmt.Errorf("%w: %s: %v", analysis.ErrParserPanic, filename, r)
+		}
+	}()
+	return p.Parser.Parse(ctx, source, filename)
+}
+
+type recoveringConfigParser struct {
+	framework.ConfigParser
+}
+
+func (p recoveringConfigParser) Parse(ctx context.Context, configPath string, content []byte) (scope *framework.ConfigScope, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			scope, err = nil, fmt.Errorf("%w: %s: %v", analysis.ErrParserPanic, configPath, r)
+		}
+	}()
+	return p.ConfigParser.Parse(ctx, configPath, content)
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/specvital/core/pkg/domain"
+	"github.com/specvital/core/pkg/parser/framework"
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+type panickingParser struct{}
+
+func (panickingParser) Parse(context.Context, []byte, string) (*domain.TestFile, error) {
+	var suites []domain.TestSuite
+	_ = suites[1]
+	return nil, nil
+}
+
+func TestRecoveringParser(t *testing.T) {
+	file, err := recoveringParser{panickingParser{}}.Parse(context.Background(), nil, "broken_test.go")
+	if file != nil {
+		t.Errorf("expected no file, got %+v", file)
+	}
+	if !errors.Is(err, analysis.ErrParserPanic) || !strings.Contains(err.Error(), "broken_test.go") {
+		t.Errorf("expected ErrParserPanic naming the file, got %v", err)
+	}
+
+	parseErr := analysis.NewParseError("broken_test.go", err)
+	if !parseErr.Panicked || parseErr.Message != err.Error() {
+		t.Errorf("unexpected parse error %+v", parseErr)
+	}
+}
+
+func TestRecoveringRegistry(t *testing.T) {
+	registry := recoveringRegistry()
+	if recoveringRegistry() != registry {
+		t.Error("expected the registry built once")
+	}
+
+	want := framework.DefaultRegistry().Names()
+	if got := registry.Names(); !slices.Equal(got, want) || len(got) == 0 {
+		t.Fatalf("frameworks = %v, want %v", got, want)
+	}
+	for _, def := range registry.All() {
+		if _, ok := def.Parser.(recoveringParser); def.Parser != nil && !ok {
+			t.Errorf("%s: parser is not wrapped", def.Name)
+		}
+		if _, ok := def.ConfigParser.(recoveringConfigParser); def.ConfigParser != nil && !ok {
+			t.Errorf("%s: config parser is not wrapped", def.Name)
+		}
+	}
+	for _, def := range framework.DefaultRegistry().All() {
+		if _, ok := def.Parser.(recoveringParser); ok {
+			t.Errorf("%s: default registry was modified", def.Name)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+// maxSourceFiles bounds the stored inventory for very large monorepos.
+const maxSourceFiles = 20000
+
+var sourceExtensions = map[string]bool{
+	".c": true, ".cc": true, ".cpp": true, ".cs": true, ".dart": true, ".ex": true,
+	".go": true, ".h": true, ".hpp": true, ".java": true, ".js": true, ".jsx": true,
+	".kt": true, ".mjs": true, ".php": true, ".py": true, ".rb": true, ".rs": true,
+	".scala": true, ".swift": true, ".ts": true, ".tsx": true, ".vue": true,
+}
+
+// skippedDirs are dependency, build, and tooling directories that never hold first-party code.
+var skippedDirs = map[string]bool{
+	".git": true, ".venv": true, "__pycache__": true, "bin": true, "build": true,
+	"dist": true, "node_modules": true, "obj": true, "target": true, "third_party": true,
+	"vendor": true, "venv": true,
+}
+
+var _ analysis.SourceFileLister = (*CoreParser)(nil)
+
+// ListSourceFiles walks the cloned repository and returns slash-separated relative paths
+// of code files. Generated declaration files (.d.ts) and minified bundles are skipped.
+func (p *CoreParser) ListSourceFiles(ctx context.Context, src analysis.Source) ([]string, error) {
+	provider, ok := src.(coreSourceProvider)
+	if !ok {
+		return nil, fmt.Errorf("source does not implement coreSourceProvider interface")
+	}
+
+	root := provider.CoreSource().Root()
+	var files []string
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if d.IsDir() {
+			if p != root && (skippedDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !isSourceFile(d.Name()) {
+			return nil
+		}
+		if len(files) >= maxSourceFiles {
+			return fs.SkipAll
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk source files: %w", err)
+	}
+
+	return files, nil
+}
+
+func isSourceFile(name string) bool {
+	if strings.HasSuffix(name, ".d.ts") || strings.Contains(name, ".min.") {
+		return false
+	}
+	return sourceExtensions[strings.ToLower(path.Ext(name))]
+}
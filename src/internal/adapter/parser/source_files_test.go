@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/specvital/core/pkg/source"
+)
+
+type localSourceAdapter struct {
+	mockInvalidSource
+	src source.Source
+}
+
+func (a *localSourceAdapter) CoreSource() source.Source { return a.src }
+
+func TestCoreParser_ListSourceFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{
+		"main.go",
+		"pkg/auth/login.go",
+		"pkg/auth/login_test.go",
+		"web/app.tsx",
+		"web/types.d.ts",
+		"web/vendor.min.js",
+		"node_modules/lib/index.js",
+		".github/scripts/run.py",
+		"README.md",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	local, err := source.NewLocalSource(root)
+	if err != nil {
+		t.Fatalf("NewLocalSource failed: %v", err)
+	}
+
+	files, err := NewCoreParser().ListSourceFiles(context.Background(), &localSourceAdapter{src: local})
+	if err != nil {
+		t.Fatalf("ListSourceFiles failed: %v", err)
+	}
+
+	want := []string{"main.go", "pkg/auth/login.go", "pkg/auth/login_test.go", "web/app.tsx"}
+	if !slices.Equal(files, want) {
+		t.Errorf("ListSourceFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestCoreParser_ListSourceFiles_InvalidSourceType(t *testing.T) {
+	_, err := NewCoreParser().ListSourceFiles(context.Background(), &mockInvalidSource{})
+	if err == nil {
+		t.Fatal("expected error for source not implementing coreSourceProvider")
+	}
+}
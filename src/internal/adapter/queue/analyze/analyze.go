@@ -8,6 +8,7 @@ import (
 
 	"github.com/riverqueue/river"
 
+	"github.com/specvital/worker/internal/adapter/queue/jobresult"
 	"github.com/specvital/worker/internal/domain/analysis"
 	"github.com/specvital/worker/internal/domain/quota"
 	uc "github.com/specvital/worker/internal/usecase/analysis"
@@ -23,11 +24,14 @@ const (
 )
 
 type AnalyzeArgs struct {
-	CommitSHA string  `json:"commit_sha" river:"unique"`
-	Owner     string  `json:"owner" river:"unique"`
-	Repo      string  `json:"repo" river:"unique"`
-	Tier      string  `json:"tier,omitempty"`
-	UserID    *string `json:"user_id,omitempty"`
+	Branch      string                `json:"branch,omitempty" river:"unique"` // empty for the default branch
+	CommitSHA   string                `json:"commit_sha" river:"unique"`
+	Host        string                `json:"host,omitempty" river:"unique"` // empty for github.com
+	Owner       string                `json:"owner" river:"unique"`
+	PathFilters *analysis.PathFilters `json:"path_filters,omitempty" river:"unique"` // nil reuses the codebase's last filters
+	Repo        string                `json:"repo" river:"unique"`
+	Tier        string                `json:"tier,omitempty"`
+	UserID      *string               `json:"user_id,omitempty"`
 }
 
 func (AnalyzeArgs) Kind() string { return "analysis:analyze" }
@@ -42,6 +46,18 @@ func (AnalyzeArgs) InsertOpts() river.InsertOpts {
 	}
 }
 
+// Validate rejects args no attempt can analyze.
+func (a AnalyzeArgs) Validate() error {
+	return analysis.AnalyzeRequest{
+		Branch:      a.Branch,
+		CommitSHA:   a.CommitSHA,
+		Host:        a.Host,
+		Owner:       a.Owner,
+		PathFilters: a.PathFilters,
+		Repo:        a.Repo,
+	}.Validate()
+}
+
 type AnalyzeWorker struct {
 	river.WorkerDefaults[AnalyzeArgs]
 	analyzeUC *uc.AnalyzeUseCase
@@ -59,13 +75,6 @@ func (w *AnalyzeWorker) Timeout(job *river.Job[AnalyzeArgs]) time.Duration {
 	return 20 * time.Minute // Large repos (e.g., grafana/grafana) need extended time for cloning and analysis
 }
 
-// Exponential backoff: 1st retry +1s, 2nd +4s, 3rd +9s
-func (w *AnalyzeWorker) NextRetry(job *river.Job[AnalyzeArgs]) time.Time {
-	attempt := job.Attempt
-	backoff := time.Duration(attempt*attempt) * time.Second
-	return time.Now().Add(backoff)
-}
-
 func (w *AnalyzeWorker) Work(ctx context.Context, job *river.Job[AnalyzeArgs]) error {
 	args := job.Args
 
@@ -74,16 +83,22 @@ func (w *AnalyzeWorker) Work(ctx context.Context, job *river.Job[AnalyzeArgs]) e
 
 	slog.InfoContext(ctx, "processing analyze task",
 		"job_id", job.ID,
+		"host", args.Host,
 		"owner", args.Owner,
 		"repo", args.Repo,
+		"branch", args.Branch,
 		"commit", args.CommitSHA,
 	)
 
 	req := analysis.AnalyzeRequest{
-		Owner:     args.Owner,
-		Repo:      args.Repo,
-		CommitSHA: args.CommitSHA,
-		UserID:    args.UserID,
+		Branch:      args.Branch,
+		Host:        args.Host,
+		Owner:       args.Owner,
+		Repo:        args.Repo,
+		CommitSHA:   args.CommitSHA,
+		JobID:       job.ID,
+		PathFilters: args.PathFilters,
+		UserID:      args.UserID,
 	}
 
 	if err := w.analyzeUC.Execute(ctx, req); err != nil {
@@ -94,6 +109,32 @@ func (w *AnalyzeWorker) Work(ctx context.Context, job *river.Job[AnalyzeArgs]) e
 				"repo", args.Repo,
 				"commit", args.CommitSHA,
 			)
+			jobresult.Record(ctx, jobresult.Details{ErrorCode: "already_completed"})
+			return river.JobCancel(err)
+		}
+		if errors.Is(err, analysis.ErrBranchNotFound) {
+			slog.WarnContext(ctx, "branch not found, cancelling job",
+				"job_id", job.ID,
+				"owner", args.Owner,
+				"repo", args.Repo,
+				"branch", args.Branch,
+				"error", err,
+			)
+			jobresult.Record(ctx, jobresult.Details{ErrorCode: "branch_not_found"})
+			return river.JobCancel(err)
+		}
+
+		class := uc.ClassifyFailure(err)
+		jobresult.Record(ctx, jobresult.Details{ErrorCode: string(class)})
+		if !class.Retryable() {
+			slog.WarnContext(ctx, "analysis failure is permanent, cancelling job",
+				"job_id", job.ID,
+				"owner", args.Owner,
+				"repo", args.Repo,
+				"commit", args.CommitSHA,
+				"failure_class", class,
+				"error", err,
+			)
 			return river.JobCancel(err)
 		}
 
@@ -101,7 +142,9 @@ func (w *AnalyzeWorker) Work(ctx context.Context, job *river.Job[AnalyzeArgs]) e
 			"job_id", job.ID,
 			"owner", args.Owner,
 			"repo", args.Repo,
+			"branch", args.Branch,
 			"commit", args.CommitSHA,
+			"failure_class", class,
 			"error", err,
 		)
 		return err
@@ -111,6 +154,7 @@ func (w *AnalyzeWorker) Work(ctx context.Context, job *river.Job[AnalyzeArgs]) e
 		"job_id", job.ID,
 		"owner", args.Owner,
 		"repo", args.Repo,
+		"branch", args.Branch,
 		"commit", args.CommitSHA,
 	)
 
@@ -21,14 +21,14 @@ type mockVCS struct {
 	getHeadCommitFn func(ctx context.Context, url string, token *string) (analysis.CommitInfo, error)
 }
 
-func (m *mockVCS) Clone(ctx context.Context, url string, token *string) (analysis.Source, error) {
+func (m *mockVCS) Clone(ctx context.Context, url string, token *string, branch string) (analysis.Source, error) {
 	if m.cloneFn != nil {
 		return m.cloneFn(ctx, url, token)
 	}
 	return nil, nil
 }
 
-func (m *mockVCS) GetHeadCommit(ctx context.Context, url string, token *string) (analysis.CommitInfo, error) {
+func (m *mockVCS) GetHeadCommit(ctx context.Context, url string, token *string, branch string) (analysis.CommitInfo, error) {
 	if m.getHeadCommitFn != nil {
 		return m.getHeadCommitFn(ctx, url, token)
 	}
@@ -87,8 +87,9 @@ func (m *mockParser) Scan(ctx context.Context, src analysis.Source) (*analysis.I
 
 type mockRepository struct {
 	createAnalysisRecordFn  func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error)
-	recordFailureFn         func(ctx context.Context, analysisID analysis.UUID, errMessage string) error
+	recordFailureFn         func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error
 	saveAnalysisInventoryFn func(ctx context.Context, params analysis.SaveAnalysisInventoryParams) error
+	updateAnalysisSourceFn  func(ctx context.Context, params analysis.UpdateAnalysisSourceParams) error
 }
 
 func (m *mockRepository) CreateAnalysisRecord(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
@@ -98,9 +99,9 @@ func (m *mockRepository) CreateAnalysisRecord(ctx context.Context, params analys
 	return analysis.NewUUID(), nil
 }
 
-func (m *mockRepository) RecordFailure(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+func (m *mockRepository) RecordFailure(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 	if m.recordFailureFn != nil {
-		return m.recordFailureFn(ctx, analysisID, errMessage)
+		return m.recordFailureFn(ctx, analysisID, class, errMessage)
 	}
 	return nil
 }
@@ -112,6 +113,13 @@ func (m *mockRepository) SaveAnalysisInventory(ctx context.Context, params analy
 	return nil
 }
 
+func (m *mockRepository) UpdateAnalysisSource(ctx context.Context, params analysis.UpdateAnalysisSourceParams) error {
+	if m.updateAnalysisSourceFn != nil {
+		return m.updateAnalysisSourceFn(ctx, params)
+	}
+	return nil
+}
+
 type mockCodebaseRepository struct{}
 
 func (m *mockCodebaseRepository) FindByExternalID(ctx context.Context, host, externalRepoID string) (*analysis.Codebase, error) {
@@ -172,6 +180,10 @@ func (m *mockVCSAPIClient) GetRepoInfo(ctx context.Context, host, owner, repo st
 	}, nil
 }
 
+func (m *mockVCSAPIClient) GetRepoStats(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoStats, error) {
+	return analysis.RepoStats{SizeBytes: 1 << 20}, nil
+}
+
 // Test helper functions
 
 func newSuccessfulMocks() (*mockRepository, *mockVCS, *mockParser) {
@@ -284,7 +296,7 @@ func TestAnalyzeWorker_Work(t *testing.T) {
 				repo.createAnalysisRecordFn = func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
 					return testAnalysisID, nil
 				}
-				repo.recordFailureFn = func(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+				repo.recordFailureFn = func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 					return nil
 				}
 
@@ -312,7 +324,7 @@ func TestAnalyzeWorker_Work(t *testing.T) {
 				repo.createAnalysisRecordFn = func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
 					return testAnalysisID, nil
 				}
-				repo.recordFailureFn = func(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+				repo.recordFailureFn = func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 					return nil
 				}
 				repo.saveAnalysisInventoryFn = func(ctx context.Context, params analysis.SaveAnalysisInventoryParams) error {
@@ -474,7 +486,7 @@ func TestAnalyzeWorker_Work_ErrorPropagation(t *testing.T) {
 				repo.createAnalysisRecordFn = func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
 					return testAnalysisID, nil
 				}
-				repo.recordFailureFn = func(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+				repo.recordFailureFn = func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 					return nil
 				}
 
@@ -498,7 +510,7 @@ func TestAnalyzeWorker_Work_ErrorPropagation(t *testing.T) {
 				repo.createAnalysisRecordFn = func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
 					return testAnalysisID, nil
 				}
-				repo.recordFailureFn = func(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+				repo.recordFailureFn = func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 					return nil
 				}
 				repo.saveAnalysisInventoryFn = func(ctx context.Context, params analysis.SaveAnalysisInventoryParams) error {
@@ -549,6 +561,23 @@ func TestAnalyzeArgs_Kind(t *testing.T) {
 	}
 }
 
+func TestAnalyzeArgs_Validate(t *testing.T) {
+	valid := AnalyzeArgs{Owner: "octocat", Repo: "hello", CommitSHA: "abc123"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected valid args, got %v", err)
+	}
+
+	for _, args := range []AnalyzeArgs{
+		{Repo: "hello", CommitSHA: "abc123"},
+		{Owner: "octocat", Repo: "hello"},
+		{Owner: "octocat", Repo: "hello", CommitSHA: "abc123", Branch: "-x"},
+	} {
+		if err := args.Validate(); !errors.Is(err, analysis.ErrInvalidInput) {
+			t.Errorf("%+v: expected ErrInvalidInput, got %v", args, err)
+		}
+	}
+}
+
 func TestAnalyzeWorker_Work_AlreadyCompleted(t *testing.T) {
 	t.Run("should return JobCancel for ErrAlreadyCompleted", func(t *testing.T) {
 		repo, vcs, parser := newSuccessfulMocks()
@@ -557,7 +586,7 @@ func TestAnalyzeWorker_Work_AlreadyCompleted(t *testing.T) {
 		repo.createAnalysisRecordFn = func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
 			return testAnalysisID, nil
 		}
-		repo.recordFailureFn = func(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+		repo.recordFailureFn = func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 			return nil
 		}
 		repo.saveAnalysisInventoryFn = func(ctx context.Context, params analysis.SaveAnalysisInventoryParams) error {
@@ -584,6 +613,40 @@ func TestAnalyzeWorker_Work_AlreadyCompleted(t *testing.T) {
 	})
 }
 
+func TestAnalyzeWorker_Work_BranchNotFound(t *testing.T) {
+	repo, vcs, parser := newSuccessfulMocks()
+	vcs.getHeadCommitFn = func(ctx context.Context, url string, token *string) (analysis.CommitInfo, error) {
+		return analysis.CommitInfo{}, analysis.ErrBranchNotFound
+	}
+
+	analyzeUC := uc.NewAnalyzeUseCase(repo, &mockCodebaseRepository{}, vcs, &mockVCSAPIClient{}, parser, nil, uc.WithParserVersion(testParserVersion))
+	worker := NewAnalyzeWorker(analyzeUC, nil)
+
+	err := worker.Work(context.Background(), newTestJob(AnalyzeArgs{Owner: "owner", Repo: "repo", CommitSHA: "abc123", Branch: "gone"}))
+
+	var cancelErr *rivertype.JobCancelError
+	if !errors.As(err, &cancelErr) || !errors.Is(err, analysis.ErrBranchNotFound) {
+		t.Errorf("expected JobCancel wrapping ErrBranchNotFound, got %v", err)
+	}
+}
+
+func TestAnalyzeWorker_Work_PermanentFailure(t *testing.T) {
+	repo, vcs, parser := newSuccessfulMocks()
+	vcs.getHeadCommitFn = func(ctx context.Context, url string, token *string) (analysis.CommitInfo, error) {
+		return analysis.CommitInfo{}, errors.New("fatal: could not read Username for 'https://github.com': terminal prompts disabled")
+	}
+
+	analyzeUC := uc.NewAnalyzeUseCase(repo, &mockCodebaseRepository{}, vcs, &mockVCSAPIClient{}, parser, nil, uc.WithParserVersion(testParserVersion))
+	worker := NewAnalyzeWorker(analyzeUC, nil)
+
+	err := worker.Work(context.Background(), newTestJob(AnalyzeArgs{Owner: "owner", Repo: "private", CommitSHA: "abc123"}))
+
+	var cancelErr *rivertype.JobCancelError
+	if !errors.As(err, &cancelErr) || !errors.Is(err, uc.ErrHeadCommitFailed) {
+		t.Errorf("expected JobCancel wrapping ErrHeadCommitFailed, got %v", err)
+	}
+}
+
 // mockQuotaRepository tracks calls to DeleteByJobID for testing quota release behavior.
 type mockQuotaRepository struct {
 	deletedJobIDs []int64
@@ -0,0 +1,108 @@
+package analyze
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	uc "github.com/specvital/worker/internal/usecase/analysis"
+)
+
+type CompareArgs struct {
+	BaseCommitSHA string  `json:"base_commit_sha" river:"unique"`
+	HeadBranch    string  `json:"head_branch,omitempty" river:"unique"` // empty for the default branch
+	HeadCommitSHA string  `json:"head_commit_sha" river:"unique"`
+	Owner         string  `json:"owner" river:"unique"`
+	PullRequest   int     `json:"pull_request,omitempty" river:"unique"`
+	Repo          string  `json:"repo" river:"unique"`
+	UserID        *string `json:"user_id,omitempty"`
+}
+
+func (CompareArgs) Kind() string { return "analysis:compare" }
+
+func (CompareArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       QueueDefault,
+		MaxAttempts: maxRetryAttempts,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+type CompareWorker struct {
+	river.WorkerDefaults[CompareArgs]
+	compareUC *uc.CompareUseCase
+}
+
+func NewCompareWorker(compareUC *uc.CompareUseCase) *CompareWorker {
+	return &CompareWorker{compareUC: compareUC}
+}
+
+func (w *CompareWorker) Timeout(job *river.Job[CompareArgs]) time.Duration {
+	return 20 * time.Minute // may analyze the head commit, same budget as AnalyzeWorker
+}
+
+func (w *CompareWorker) Work(ctx context.Context, job *river.Job[CompareArgs]) error {
+	args := job.Args
+
+	slog.InfoContext(ctx, "processing compare task",
+		"job_id", job.ID,
+		"owner", args.Owner,
+		"repo", args.Repo,
+		"pull_request", args.PullRequest,
+		"base_commit", args.BaseCommitSHA,
+		"head_commit", args.HeadCommitSHA,
+	)
+
+	req := analysis.CompareRequest{
+		BaseCommitSHA: args.BaseCommitSHA,
+		HeadBranch:    args.HeadBranch,
+		HeadCommitSHA: args.HeadCommitSHA,
+		JobID:         job.ID,
+		Owner:         args.Owner,
+		PullRequest:   args.PullRequest,
+		Repo:          args.Repo,
+		UserID:        args.UserID,
+	}
+
+	delta, err := w.compareUC.Execute(ctx, req)
+	if err != nil {
+		// Retrying cannot produce a base analysis or bring the branch back to the commit.
+		if errors.Is(err, uc.ErrBaseNotAnalyzed) || errors.Is(err, uc.ErrHeadMoved) ||
+			errors.Is(err, analysis.ErrBranchNotFound) || errors.Is(err, analysis.ErrInvalidInput) {
+			slog.WarnContext(ctx, "compare task cannot run, cancelling job",
+				"job_id", job.ID,
+				"owner", args.Owner,
+				"repo", args.Repo,
+				"pull_request", args.PullRequest,
+				"error", err,
+			)
+			return river.JobCancel(err)
+		}
+
+		slog.ErrorContext(ctx, "compare task failed",
+			"job_id", job.ID,
+			"owner", args.Owner,
+			"repo", args.Repo,
+			"pull_request", args.PullRequest,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.InfoContext(ctx, "compare task completed",
+		"job_id", job.ID,
+		"owner", args.Owner,
+		"repo", args.Repo,
+		"pull_request", args.PullRequest,
+		"tests_added", len(delta.AddedTests),
+		"tests_removed", len(delta.RemovedTests),
+	)
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package analyze
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/specvital/worker/internal/domain/analysis"
+	uc "github.com/specvital/worker/internal/usecase/analysis"
+)
+
+type mockHeadAnalyzer struct {
+	err error
+}
+
+func (m *mockHeadAnalyzer) Execute(ctx context.Context, req analysis.AnalyzeRequest) error {
+	return m.err
+}
+
+type mockTestDeltaRepository struct {
+	analyses map[string]analysis.UUID
+	saveErr  error
+}
+
+func (m *mockTestDeltaRepository) FindCompletedAnalysis(ctx context.Context, owner, repo, commitSHA string) (analysis.UUID, error) {
+	return m.analyses[commitSHA], nil
+}
+
+func (m *mockTestDeltaRepository) GetTestRefs(ctx context.Context, analysisID analysis.UUID) ([]analysis.TestRef, error) {
+	return nil, nil
+}
+
+func (m *mockTestDeltaRepository) SaveTestDelta(ctx context.Context, params analysis.SaveTestDeltaParams) error {
+	return m.saveErr
+}
+
+func TestCompareArgs_Kind(t *testing.T) {
+	if (CompareArgs{}).Kind() != "analysis:compare" {
+		t.Errorf("unexpected kind %q", CompareArgs{}.Kind())
+	}
+	opts := CompareArgs{}.InsertOpts()
+	if opts.Queue != QueueDefault || !opts.UniqueOpts.ByArgs {
+		t.Errorf("unexpected insert opts: %+v", opts)
+	}
+}
+
+func TestCompareWorker_Work(t *testing.T) {
+	args := CompareArgs{
+		BaseCommitSHA: "base123",
+		HeadBranch:    "feature",
+		HeadCommitSHA: "head456",
+		Owner:         "owner",
+		PullRequest:   3,
+		Repo:          "repo",
+	}
+	both := map[string]analysis.UUID{"base123": analysis.NewUUID(), "head456": analysis.NewUUID()}
+
+	tests := []struct {
+		name       string
+		analyzer   *mockHeadAnalyzer
+		repo       *mockTestDeltaRepository
+		wantErr    bool
+		wantCancel bool
+	}{
+		{
+			name:     "success",
+			analyzer: &mockHeadAnalyzer{},
+			repo:     &mockTestDeltaRepository{analyses: both},
+		},
+		{
+			name:       "base not analyzed cancels",
+			analyzer:   &mockHeadAnalyzer{},
+			repo:       &mockTestDeltaRepository{analyses: map[string]analysis.UUID{}},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:       "head moved cancels",
+			analyzer:   &mockHeadAnalyzer{},
+			repo:       &mockTestDeltaRepository{analyses: map[string]analysis.UUID{"base123": analysis.NewUUID()}},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:       "missing branch cancels",
+			analyzer:   &mockHeadAnalyzer{err: analysis.ErrBranchNotFound},
+			repo:       &mockTestDeltaRepository{analyses: map[string]analysis.UUID{"base123": analysis.NewUUID()}},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:     "save failure retries",
+			analyzer: &mockHeadAnalyzer{},
+			repo:     &mockTestDeltaRepository{analyses: both, saveErr: errors.New("connection reset")},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := NewCompareWorker(uc.NewCompareUseCase(tt.analyzer, tt.repo))
+			job := &river.Job[CompareArgs]{JobRow: &rivertype.JobRow{ID: 1}, Args: args}
+
+			err := worker.Work(context.Background(), job)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Work() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var cancelErr *rivertype.JobCancelError
+			if errors.As(err, &cancelErr) != tt.wantCancel {
+				t.Errorf("cancelled = %v, want %v (err: %v)", !tt.wantCancel, tt.wantCancel, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package attribution
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/infra/buildinfo"
+)
+
+// MetadataKey is the river_job.metadata key holding the worker identity.
+const MetadataKey = "worker"
+
+// MetadataRecorder stores the identity of the worker processing a job.
+type MetadataRecorder interface {
+	RecordWorker(ctx context.Context, jobID int64, identity buildinfo.Identity) error
+}
+
+// AttributionMiddleware records which worker instance processed each job,
+// so bad outputs can be traced back to a specific deploy.
+// The identity is written to job metadata before the job runs and included
+// in the execution summary logged after it finishes.
+type AttributionMiddleware struct {
+	river.MiddlewareDefaults
+	identity buildinfo.Identity
+	recorder MetadataRecorder
+}
+
+// NewAttributionMiddleware creates a new attribution middleware.
+func NewAttributionMiddleware(identity buildinfo.Identity, recorder MetadataRecorder) *AttributionMiddleware {
+	return &AttributionMiddleware{
+		identity: identity,
+		recorder: recorder,
+	}
+}
+
+// Work implements river.WorkerMiddleware.
+// Failing to record metadata is non-critical and never blocks the job.
+func (m *AttributionMiddleware) Work(
+	ctx context.Context,
+	job *rivertype.JobRow,
+	doInner func(ctx context.Context) error,
+) error {
+	if err := m.recorder.RecordWorker(ctx, job.ID, m.identity); err != nil {
+		slog.WarnContext(ctx, "failed to record worker identity (non-critical)",
+			"job_id", job.ID,
+			"kind", job.Kind,
+			"error", err,
+		)
+	}
+
+	start := time.Now()
+	err := doInner(ctx)
+
+	attrs := []any{
+		"job_id", job.ID,
+		"kind", job.Kind,
+		"attempt", job.Attempt,
+		"duration_ms", time.Since(start).Milliseconds(),
+		"succeeded", err == nil,
+	}
+	attrs = append(attrs, m.identity.LogAttrs()...)
+	slog.InfoContext(ctx, "job execution summary", attrs...)
+
+	return err
+}
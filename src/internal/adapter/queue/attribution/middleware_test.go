@@ -0,0 +1,79 @@
+package attribution
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/infra/buildinfo"
+)
+
+type mockRecorder struct {
+	err      error
+	identity buildinfo.Identity
+	jobID    int64
+}
+
+func (m *mockRecorder) RecordWorker(_ context.Context, jobID int64, identity buildinfo.Identity) error {
+	m.jobID = jobID
+	m.identity = identity
+	return m.err
+}
+
+func TestAttributionMiddleware_Work(t *testing.T) {
+	identity := buildinfo.Identity{GitSHA: "abc123", Hostname: "worker-1", Version: "v1.0.0"}
+	job := &rivertype.JobRow{ID: 42, Kind: "analysis:analyze", Attempt: 1}
+
+	t.Run("records identity before running job", func(t *testing.T) {
+		recorder := &mockRecorder{}
+		m := NewAttributionMiddleware(identity, recorder)
+
+		var ran bool
+		err := m.Work(context.Background(), job, func(ctx context.Context) error {
+			if recorder.jobID != 42 {
+				t.Error("expected identity to be recorded before job runs")
+			}
+			ran = true
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ran {
+			t.Error("expected inner work to run")
+		}
+		if recorder.identity != identity {
+			t.Errorf("recorded identity = %+v, want %+v", recorder.identity, identity)
+		}
+	})
+
+	t.Run("recorder failure does not block job", func(t *testing.T) {
+		m := NewAttributionMiddleware(identity, &mockRecorder{err: errors.New("db down")})
+
+		var ran bool
+		err := m.Work(context.Background(), job, func(ctx context.Context) error {
+			ran = true
+			return nil
+		})
+
+		if err != nil || !ran {
+			t.Errorf("expected job to run despite recorder error, ran=%v err=%v", ran, err)
+		}
+	})
+
+	t.Run("returns job error", func(t *testing.T) {
+		m := NewAttributionMiddleware(identity, &mockRecorder{})
+		jobErr := errors.New("job failed")
+
+		err := m.Work(context.Background(), job, func(ctx context.Context) error {
+			return jobErr
+		})
+
+		if !errors.Is(err, jobErr) {
+			t.Errorf("expected job error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package attribution
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/specvital/worker/internal/infra/buildinfo"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ MetadataRecorder = (*DBMetadataRecorder)(nil)
+
+// DBMetadataRecorder merges the worker identity into river_job.metadata.
+type DBMetadataRecorder struct {
+	queries *db.Queries
+}
+
+// NewDBMetadataRecorder creates a new DBMetadataRecorder with the given queries.
+func NewDBMetadataRecorder(queries *db.Queries) *DBMetadataRecorder {
+	return &DBMetadataRecorder{queries: queries}
+}
+
+// RecordWorker stores the identity under MetadataKey.
+// Each attempt overwrites the previous value, so the metadata names the
+// worker of the latest attempt.
+func (r *DBMetadataRecorder) RecordWorker(ctx context.Context, jobID int64, identity buildinfo.Identity) error {
+	metadata, err := json.Marshal(map[string]buildinfo.Identity{MetadataKey: identity})
+	if err != nil {
+		return fmt.Errorf("marshal worker identity: %w", err)
+	}
+
+	if err := r.queries.MergeRiverJobMetadata(ctx, db.MergeRiverJobMetadataParams{
+		ID:       jobID,
+		Metadata: metadata,
+	}); err != nil {
+		return fmt.Errorf("merge job metadata: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package coverage
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	"github.com/specvital/worker/internal/adapter/queue/analyze"
+	"github.com/specvital/worker/internal/domain/coverage"
+	uc "github.com/specvital/worker/internal/usecase/coverage"
+)
+
+const (
+	jobKind          = "coverage:ingest"
+	jobTimeout       = 5 * time.Minute
+	maxRetryAttempts = 5
+)
+
+// Args represents the arguments for a coverage ingestion job.
+// AnalysisID routes the job to the codebase region.
+type Args struct {
+	AnalysisID string `json:"analysis_id"`
+	UploadID   string `json:"upload_id" river:"unique"`
+}
+
+// Kind returns the unique identifier for this job type.
+func (Args) Kind() string { return jobKind }
+
+// InsertOpts returns the River insert options for this job type.
+func (Args) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       analyze.QueueScheduled,
+		MaxAttempts: maxRetryAttempts,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+// Worker processes coverage ingestion jobs.
+type Worker struct {
+	river.WorkerDefaults[Args]
+	usecase *uc.IngestCoverageUseCase
+}
+
+// NewWorker creates a new coverage ingestion worker.
+func NewWorker(usecase *uc.IngestCoverageUseCase) *Worker {
+	return &Worker{usecase: usecase}
+}
+
+// Timeout returns the maximum duration for this job.
+func (w *Worker) Timeout(job *river.Job[Args]) time.Duration {
+	return jobTimeout
+}
+
+// Work ingests an uploaded coverage report.
+func (w *Worker) Work(ctx context.Context, job *river.Job[Args]) error {
+	args := job.Args
+
+	summary, err := w.usecase.Execute(ctx, uc.IngestRequest{
+		AnalysisID: args.AnalysisID,
+		UploadID:   args.UploadID,
+	})
+	if err != nil {
+		if isPermanentError(err) {
+			slog.WarnContext(ctx, "permanent error, cancelling job",
+				"job_id", job.ID,
+				"upload_id", args.UploadID,
+				"analysis_id", args.AnalysisID,
+				"error", err,
+			)
+			return river.JobCancel(err)
+		}
+		slog.ErrorContext(ctx, "coverage ingestion failed",
+			"job_id", job.ID,
+			"upload_id", args.UploadID,
+			"analysis_id", args.AnalysisID,
+			"attempt", job.Attempt,
+			"max_attempts", maxRetryAttempts,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.InfoContext(ctx, "coverage ingestion completed",
+		"job_id", job.ID,
+		"upload_id", args.UploadID,
+		"analysis_id", args.AnalysisID,
+		"already_processed", summary.AlreadyProcessed,
+		"sources", summary.Sources,
+		"linked_sources", summary.LinkedSources,
+		"linked_test_files", summary.LinkedTestFiles,
+	)
+	return nil
+}
+
+func isPermanentError(err error) bool {
+	return errors.Is(err, coverage.ErrEmptyReport) ||
+		errors.Is(err, coverage.ErrInvalidFormat) ||
+		errors.Is(err, coverage.ErrInvalidInput) ||
+		errors.Is(err, coverage.ErrUploadNotFound) ||
+		errors.Is(err, uc.ErrAnalysisMismatch)
+}
@@ -0,0 +1,91 @@
+package coverage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/adapter/mapping"
+	"github.com/specvital/worker/internal/domain/coverage"
+	uc "github.com/specvital/worker/internal/usecase/coverage"
+)
+
+type mockRepository struct {
+	content   string
+	getErr    error
+	getTFErr  error
+	saveCalls int
+}
+
+func (m *mockRepository) GetUpload(_ context.Context, uploadID string) (*coverage.Upload, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return &coverage.Upload{AnalysisID: "an-1", Content: []byte(m.content), Format: coverage.FormatLCOV, ID: uploadID}, nil
+}
+
+func (m *mockRepository) GetTestFiles(context.Context, string) ([]coverage.TestFile, error) {
+	return []coverage.TestFile{{ID: "tf-1", Path: "a_test.go"}}, m.getTFErr
+}
+
+func (m *mockRepository) SaveLinks(context.Context, *coverage.Upload, []coverage.Link) (bool, error) {
+	m.saveCalls++
+	return true, nil
+}
+
+func TestArgs_Kind(t *testing.T) {
+	if (Args{}).Kind() != "coverage:ingest" {
+		t.Errorf("expected kind 'coverage:ingest', got '%s'", Args{}.Kind())
+	}
+}
+
+func TestWorker_Work(t *testing.T) {
+	const report = "SF:a.go\nDA:1,1\nend_of_record\n"
+
+	tests := []struct {
+		name       string
+		repo       *mockRepository
+		analysisID string
+		wantErr    bool
+		wantCancel bool
+	}{
+		{name: "success", repo: &mockRepository{content: report}, analysisID: "an-1"},
+		{name: "missing upload is cancelled", repo: &mockRepository{getErr: coverage.ErrUploadNotFound}, wantErr: true, wantCancel: true},
+		{name: "malformed report is cancelled", repo: &mockRepository{content: "SF:a.go\nDA:x\n"}, wantErr: true, wantCancel: true},
+		{name: "empty report is cancelled", repo: &mockRepository{content: "TN:\n"}, wantErr: true, wantCancel: true},
+		{name: "analysis mismatch is cancelled", repo: &mockRepository{content: report}, analysisID: "an-2", wantErr: true, wantCancel: true},
+		{name: "database failure is retried", repo: &mockRepository{content: report, getTFErr: errors.New("connection reset")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := NewWorker(uc.NewIngestCoverageUseCase(tt.repo, mapping.CoverageLinker{}))
+			job := &river.Job[Args]{
+				JobRow: &rivertype.JobRow{ID: 1, Attempt: 1},
+				Args:   Args{AnalysisID: tt.analysisID, UploadID: "up-1"},
+			}
+
+			err := worker.Work(context.Background(), job)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				if tt.repo.saveCalls != 1 {
+					t.Errorf("expected links saved once, got %d", tt.repo.saveCalls)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			var cancelErr *rivertype.JobCancelError
+			if isCancelled := errors.As(err, &cancelErr); isCancelled != tt.wantCancel {
+				t.Errorf("expected cancel=%v, got %T: %v", tt.wantCancel, err, err)
+			}
+		})
+	}
+}
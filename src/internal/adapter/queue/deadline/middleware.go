@@ -0,0 +1,191 @@
+// Package deadline monitors jobs against the SLA target of their kind and
+// records the ones that finish late, with the time they spent in each phase.
+package deadline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/sla"
+	"github.com/specvital/worker/internal/infra/metrics"
+)
+
+var _ rivertype.WorkerMiddleware = (*MonitorMiddleware)(nil)
+
+// Outcomes stored in sla_violations.outcome.
+const (
+	OutcomeCancelled = "cancelled"
+	OutcomeCompleted = "completed"
+	OutcomeFailed    = "failed" // failed on its last attempt
+)
+
+// Violation is a job that finished past its SLA target.
+type Violation struct {
+	AnalysisID   string
+	Attempt      int
+	DocumentID   string
+	Elapsed      time.Duration // from enqueue to completion
+	JobID        int64
+	Kind         string
+	Outcome      string
+	Phases       map[sla.Phase]time.Duration
+	Queue        string
+	SlowestPhase sla.Phase
+	Target       time.Duration
+}
+
+// Recorder stores SLA violations.
+type Recorder interface {
+	RecordViolation(ctx context.Context, violation Violation) error
+}
+
+// ids holds the args fields that identify the subject of a job across kinds.
+type ids struct {
+	AnalysisID string `json:"analysis_id"`
+	DocumentID string `json:"document_id"`
+}
+
+// MonitorMiddleware times the jobs of kinds with an SLA target. Workers and
+// use cases mark their phases with sla.StartPhase. A warning is logged as
+// soon as a running job passes its deadline. When a job finishes, the time
+// of each phase is observed in metrics, and a job that took longer than its
+// target, counted from enqueue, is logged and recorded as a violation.
+// Snoozed attempts and attempts that will be retried are not finished.
+type MonitorMiddleware struct {
+	river.MiddlewareDefaults
+	recorder Recorder
+	targets  sla.Targets
+}
+
+// NewMonitorMiddleware creates a new SLA monitor middleware.
+func NewMonitorMiddleware(targets sla.Targets, recorder Recorder) *MonitorMiddleware {
+	return &MonitorMiddleware{recorder: recorder, targets: targets}
+}
+
+// Work implements river.WorkerMiddleware.
+// Failing to record a violation is non-critical and never changes the job
+// outcome.
+func (m *MonitorMiddleware) Work(
+	ctx context.Context,
+	job *rivertype.JobRow,
+	doInner func(ctx context.Context) error,
+) error {
+	target, ok := m.targets[job.Kind]
+	if !ok {
+		return doInner(ctx)
+	}
+
+	start := time.Now()
+	attemptStart := start
+	if job.AttemptedAt != nil {
+		attemptStart = *job.AttemptedAt
+	}
+	ctx, timings := sla.WithTimings(ctx)
+	timings.Add(sla.PhaseQueued, max(attemptStart.Sub(job.CreatedAt), 0))
+
+	deadline := time.AfterFunc(job.CreatedAt.Add(target).Sub(start), func() {
+		slog.WarnContext(ctx, "job passed its SLA deadline",
+			"job_id", job.ID,
+			"kind", job.Kind,
+			"target_ms", target.Milliseconds(),
+			"phases_ms", milliseconds(timings.Phases()),
+		)
+	})
+	err := doInner(ctx)
+	deadline.Stop()
+
+	outcome, finished := jobOutcome(job, err)
+	if !finished {
+		return err
+	}
+
+	end := time.Now()
+	phases := timings.Phases()
+	var inPhases time.Duration
+	for phase, d := range phases {
+		if phase != sla.PhaseQueued {
+			inPhases += d
+		}
+	}
+	if other := end.Sub(start) - inPhases; other > 0 {
+		phases[sla.PhaseOther] = other
+	}
+	for phase, d := range phases {
+		metrics.JobPhaseDuration.WithLabelValues(job.Kind, string(phase)).Observe(d.Seconds())
+	}
+
+	elapsed := end.Sub(job.CreatedAt)
+	if elapsed <= target {
+		return err
+	}
+
+	violation := Violation{
+		Attempt:      job.Attempt,
+		Elapsed:      elapsed,
+		JobID:        job.ID,
+		Kind:         job.Kind,
+		Outcome:      outcome,
+		Phases:       phases,
+		Queue:        job.Queue,
+		SlowestPhase: sla.Slowest(phases),
+		Target:       target,
+	}
+	var args ids
+	if json.Unmarshal(job.EncodedArgs, &args) == nil {
+		violation.AnalysisID = args.AnalysisID
+		violation.DocumentID = args.DocumentID
+	}
+
+	metrics.SLAViolations.WithLabelValues(job.Kind, string(violation.SlowestPhase)).Inc()
+	slog.WarnContext(ctx, "job exceeded its SLA",
+		"job_id", job.ID,
+		"kind", job.Kind,
+		"outcome", outcome,
+		"elapsed_ms", elapsed.Milliseconds(),
+		"target_ms", target.Milliseconds(),
+		"slowest_phase", violation.SlowestPhase,
+		"phases_ms", milliseconds(phases),
+	)
+	if recErr := m.recorder.RecordViolation(context.WithoutCancel(ctx), violation); recErr != nil {
+		slog.WarnContext(ctx, "failed to record SLA violation (non-critical)",
+			"job_id", job.ID,
+			"kind", job.Kind,
+			"error", recErr,
+		)
+	}
+	return err
+}
+
+// jobOutcome maps the result of an attempt to an outcome; finished is false
+// for snoozes and failures that will be retried.
+func jobOutcome(job *rivertype.JobRow, err error) (outcome string, finished bool) {
+	var cancelErr *rivertype.JobCancelError
+	var snoozeErr *rivertype.JobSnoozeError
+	switch {
+	case err == nil:
+		return OutcomeCompleted, true
+	case errors.As(err, &snoozeErr):
+		return "", false
+	case errors.As(err, &cancelErr):
+		return OutcomeCancelled, true
+	case job.Attempt >= job.MaxAttempts:
+		return OutcomeFailed, true
+	default:
+		return "", false
+	}
+}
+
+// milliseconds converts phase durations for logs and storage.
+func milliseconds(phases map[sla.Phase]time.Duration) map[string]int64 {
+	ms := make(map[string]int64, len(phases))
+	for phase, d := range phases {
+		ms[string(phase)] = d.Milliseconds()
+	}
+	return ms
+}
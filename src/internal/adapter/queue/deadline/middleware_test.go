@@ -0,0 +1,111 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/sla"
+)
+
+type mockRecorder struct {
+	err        error
+	violations []Violation
+}
+
+func (m *mockRecorder) RecordViolation(_ context.Context, violation Violation) error {
+	m.violations = append(m.violations, violation)
+	return m.err
+}
+
+func TestMonitorMiddleware_Work(t *testing.T) {
+	targets := sla.Targets{"analysis:analyze": time.Hour}
+	newJob := func(kind string, enqueued time.Duration, attempt int) *rivertype.JobRow {
+		createdAt := time.Now().Add(-enqueued)
+		attemptedAt := createdAt.Add(enqueued / 2)
+		return &rivertype.JobRow{
+			ID:          42,
+			Attempt:     attempt,
+			AttemptedAt: &attemptedAt,
+			CreatedAt:   createdAt,
+			EncodedArgs: []byte(`{"analysis_id":"0b8f5e3c-57a1-4d2e-9a6b-1f0c2d3e4f50"}`),
+			Kind:        kind,
+			MaxAttempts: 3,
+			Queue:       "analysis_default",
+		}
+	}
+	scanAndSave := func(ctx context.Context) error {
+		stop := sla.StartPhase(ctx, sla.PhaseScan)
+		sla.StartPhase(ctx, sla.PhaseSave)()
+		stop()
+		return nil
+	}
+
+	t.Run("records a late job with its phases", func(t *testing.T) {
+		recorder := &mockRecorder{}
+		m := NewMonitorMiddleware(targets, recorder)
+
+		if err := m.Work(context.Background(), newJob("analysis:analyze", 2*time.Hour, 1), scanAndSave); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(recorder.violations) != 1 {
+			t.Fatalf("expected 1 violation, got %d", len(recorder.violations))
+		}
+		got := recorder.violations[0]
+		if got.Outcome != OutcomeCompleted || got.Target != time.Hour || got.Elapsed < 2*time.Hour {
+			t.Errorf("unexpected violation %+v", got)
+		}
+		if got.SlowestPhase != sla.PhaseQueued || got.Phases[sla.PhaseQueued] < time.Hour {
+			t.Errorf("expected the hour in the queue as the slowest phase, got %q in %v", got.SlowestPhase, got.Phases)
+		}
+		if _, ok := got.Phases[sla.PhaseScan]; !ok {
+			t.Errorf("expected the scan phase timed, got %v", got.Phases)
+		}
+		if got.AnalysisID != "0b8f5e3c-57a1-4d2e-9a6b-1f0c2d3e4f50" || got.JobID != 42 || got.Queue != "analysis_default" {
+			t.Errorf("unexpected job fields %+v", got)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		job     *rivertype.JobRow
+		err     error
+		want    string // outcome of the recorded violation; empty for none
+		wantErr bool
+	}{
+		{name: "on time", job: newJob("analysis:analyze", time.Minute, 1)},
+		{name: "kind without a target", job: newJob("specview:export", 2*time.Hour, 1)},
+		{name: "retried failure", job: newJob("analysis:analyze", 2*time.Hour, 1), err: errors.New("boom"), wantErr: true},
+		{name: "snoozed", job: newJob("analysis:analyze", 2*time.Hour, 1), err: river.JobSnooze(time.Minute), wantErr: true},
+		{name: "last failed attempt", job: newJob("analysis:analyze", 2*time.Hour, 3), err: errors.New("boom"), want: OutcomeFailed, wantErr: true},
+		{name: "cancelled", job: newJob("analysis:analyze", 2*time.Hour, 1), err: river.JobCancel(errors.New("gone")), want: OutcomeCancelled, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &mockRecorder{}
+			m := NewMonitorMiddleware(targets, recorder)
+
+			err := m.Work(context.Background(), tt.job, func(ctx context.Context) error { return tt.err })
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error %v", err)
+			}
+			switch {
+			case tt.want == "" && len(recorder.violations) != 0:
+				t.Errorf("expected no violation, got %+v", recorder.violations)
+			case tt.want != "" && (len(recorder.violations) != 1 || recorder.violations[0].Outcome != tt.want):
+				t.Errorf("expected a %s violation, got %+v", tt.want, recorder.violations)
+			}
+		})
+	}
+
+	t.Run("recorder failure does not fail the job", func(t *testing.T) {
+		m := NewMonitorMiddleware(targets, &mockRecorder{err: errors.New("db down")})
+		if err := m.Work(context.Background(), newJob("analysis:analyze", 2*time.Hour, 1), scanAndSave); err != nil {
+			t.Errorf("expected the job to succeed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package deadline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ Recorder = (*DBRecorder)(nil)
+
+// DBRecorder inserts SLA violations into sla_violations.
+type DBRecorder struct {
+	queries *db.Queries
+}
+
+// NewDBRecorder creates a new DBRecorder with the given queries.
+func NewDBRecorder(queries *db.Queries) *DBRecorder {
+	return &DBRecorder{queries: queries}
+}
+
+// RecordViolation stores the violation with its phases in milliseconds.
+// IDs that are not UUIDs are stored as NULL.
+func (r *DBRecorder) RecordViolation(ctx context.Context, violation Violation) error {
+	phases, err := json.Marshal(milliseconds(violation.Phases))
+	if err != nil {
+		return fmt.Errorf("marshal SLA phases: %w", err)
+	}
+
+	if err := r.queries.InsertSLAViolation(ctx, db.InsertSLAViolationParams{
+		JobID:        violation.JobID,
+		Kind:         violation.Kind,
+		Queue:        violation.Queue,
+		Attempt:      int32(violation.Attempt),
+		Outcome:      violation.Outcome,
+		TargetMs:     violation.Target.Milliseconds(),
+		ElapsedMs:    violation.Elapsed.Milliseconds(),
+		SlowestPhase: string(violation.SlowestPhase),
+		Phases:       phases,
+		AnalysisID:   parseOptionalUUID(violation.AnalysisID),
+		DocumentID:   parseOptionalUUID(violation.DocumentID),
+	}); err != nil {
+		return fmt.Errorf("insert SLA violation: %w", err)
+	}
+	return nil
+}
+
+func parseOptionalUUID(s string) pgtype.UUID {
+	parsed, err := uuid.Parse(s)
+	if err != nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: parsed, Valid: true}
+}
@@ -0,0 +1,48 @@
+package fairness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ BucketStore = (*DBBucketStore)(nil)
+
+// DBBucketStore keeps token buckets in the rate_limit_buckets table.
+// Refill and take happen in one statement, so concurrent workers cannot overdraw a bucket.
+type DBBucketStore struct {
+	queries *db.Queries
+}
+
+// NewDBBucketStore creates a new DBBucketStore with the given queries.
+func NewDBBucketStore(queries *db.Queries) *DBBucketStore {
+	return &DBBucketStore{queries: queries}
+}
+
+func (s *DBBucketStore) TakeToken(ctx context.Context, key string, limit RateLimit) (bool, error) {
+	_, err := s.queries.TakeRateLimitToken(ctx, db.TakeRateLimitTokenParams{
+		BucketKey:       key,
+		Burst:           float64(limit.Burst),
+		RefillPerSecond: float64(limit.PerMinute) / 60,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("take rate limit token: %w", err)
+	}
+	return true, nil
+}
+
+func (s *DBBucketStore) ReturnToken(ctx context.Context, key string, limit RateLimit) error {
+	if err := s.queries.ReturnRateLimitToken(ctx, db.ReturnRateLimitTokenParams{
+		BucketKey: key,
+		Burst:     float64(limit.Burst),
+	}); err != nil {
+		return fmt.Errorf("return rate limit token: %w", err)
+	}
+	return nil
+}
@@ -22,6 +22,33 @@ func DefaultConfig() *Config {
 	}
 }
 
+// RateLimit is a token bucket holding up to Burst tokens, refilled at PerMinute tokens per minute.
+// Each job start takes one token. A zero PerMinute or Burst disables the limit.
+type RateLimit struct {
+	Burst     int
+	PerMinute int
+}
+
+// Enabled reports whether the limit is configured.
+func (l RateLimit) Enabled() bool {
+	return l.Burst > 0 && l.PerMinute > 0
+}
+
+// RefillInterval is the time needed to earn one token.
+func (l RateLimit) RefillInterval() time.Duration {
+	if l.PerMinute <= 0 {
+		return 0
+	}
+	return time.Minute / time.Duration(l.PerMinute)
+}
+
+// RateLimitConfig defines per-user and per-codebase job start rates.
+type RateLimitConfig struct {
+	Codebase     RateLimit
+	SnoozeJitter time.Duration // Random jitter added to the refill interval when snoozing
+	User         RateLimit
+}
+
 // PlanTier represents a user's subscription tier for concurrent limit enforcement.
 type PlanTier string
 
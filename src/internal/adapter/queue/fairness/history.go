@@ -0,0 +1,62 @@
+package fairness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+// DBHistorySource loads the enqueue history recorded in job_results.
+type DBHistorySource struct {
+	queries *db.Queries
+}
+
+// NewDBHistorySource creates a new DBHistorySource with the given queries.
+func NewDBHistorySource(queries *db.Queries) *DBHistorySource {
+	return &DBHistorySource{queries: queries}
+}
+
+// ListJobs returns the started jobs enqueued in [since, until), oldest first.
+// Each job has the tier its user has today, TierFree without an active
+// subscription. Jobs recorded before enqueue times were kept are absent.
+func (s *DBHistorySource) ListJobs(ctx context.Context, since, until time.Time) ([]HistoryJob, error) {
+	rows, err := s.queries.ListJobHistory(ctx, db.ListJobHistoryParams{
+		Since: pgtype.Timestamptz{Time: since, Valid: true},
+		Until: pgtype.Timestamptz{Time: until, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list job history: %w", err)
+	}
+
+	jobs := make([]HistoryJob, 0, len(rows))
+	for _, row := range rows {
+		job := HistoryJob{
+			CodebaseID: uuidString(row.CodebaseID),
+			Duration:   max(row.CompletedAt.Time.Sub(row.StartedAt.Time), 0),
+			EnqueuedAt: row.EnqueuedAt.Time,
+			ID:         row.JobID,
+			Queue:      row.Queue,
+			StartedAt:  row.StartedAt.Time,
+			UserID:     uuidString(row.UserID),
+		}
+		if job.UserID != "" {
+			job.Tier = TierFree
+			if row.Tier.Valid {
+				job.Tier = convertDBTier(row.Tier.PlanTier)
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func uuidString(id pgtype.UUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return uuid.UUID(id.Bytes).String()
+}
@@ -0,0 +1,137 @@
+package fairness
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/specvital/worker/internal/adapter/queue/jobref"
+)
+
+// BucketStore takes tokens from persistent token buckets.
+type BucketStore interface {
+	// TakeToken refills the bucket for the elapsed time and takes one token.
+	// Returns false when the bucket is empty.
+	TakeToken(ctx context.Context, key string, limit RateLimit) (bool, error)
+	// ReturnToken puts back a token taken for a job that did not start.
+	ReturnToken(ctx context.Context, key string, limit RateLimit) error
+}
+
+// CodebaseResolver resolves the codebase a job refers to.
+// Returns nil when the job names no existing codebase.
+type CodebaseResolver interface {
+	Resolve(ctx context.Context, encodedArgs []byte) (*jobref.Codebase, error)
+}
+
+// RateLimitMiddleware enforces token-bucket start rates per user and per codebase.
+// FairnessMiddleware caps concurrent jobs in memory; these buckets live in
+// Postgres, so a user enqueuing hundreds of repositories stays throttled
+// across restarts and worker instances.
+type RateLimitMiddleware struct {
+	river.MiddlewareDefaults
+	codebases CodebaseResolver
+	config    *RateLimitConfig
+	extractor UserJobExtractor
+	store     BucketStore
+}
+
+// NewRateLimitMiddleware creates a new rate limit middleware with the given dependencies.
+func NewRateLimitMiddleware(store BucketStore, extractor UserJobExtractor, codebases CodebaseResolver, config *RateLimitConfig) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		codebases: codebases,
+		config:    config,
+		extractor: extractor,
+		store:     store,
+	}
+}
+
+type bucket struct {
+	key   string
+	limit RateLimit
+}
+
+// Work implements river.WorkerMiddleware by taking one token from each bucket.
+// System jobs (empty userID) bypass limits. Jobs without a token are snoozed
+// until the bucket refills, and the tokens already taken from the other
+// buckets are returned, so a snoozed job costs nothing. Store errors fail
+// open: throttling is best effort and must not block jobs.
+func (m *RateLimitMiddleware) Work(
+	ctx context.Context,
+	job *rivertype.JobRow,
+	doInner func(ctx context.Context) error,
+) error {
+	userID := m.extractor.ExtractUserID(job.EncodedArgs)
+	if userID == "" {
+		return doInner(ctx)
+	}
+
+	var taken []bucket
+	for _, b := range m.buckets(ctx, job, userID) {
+		ok, err := m.store.TakeToken(ctx, b.key, b.limit)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to take rate limit token (non-critical)",
+				"bucket", b.key,
+				"job_id", job.ID,
+				"error", err,
+			)
+			continue
+		}
+		if ok {
+			taken = append(taken, b)
+			continue
+		}
+		m.returnTokens(ctx, job, taken)
+
+		snoozeDuration := b.limit.RefillInterval()
+		if m.config.SnoozeJitter > 0 {
+			snoozeDuration += time.Duration(rand.Int64N(int64(m.config.SnoozeJitter)))
+		}
+		slog.InfoContext(ctx, "rate limit exceeded, snoozing",
+			"bucket", b.key,
+			"job_id", job.ID,
+			"user_id", userID,
+			"snooze_duration", snoozeDuration,
+		)
+		return river.JobSnooze(snoozeDuration)
+	}
+
+	return doInner(ctx)
+}
+
+func (m *RateLimitMiddleware) returnTokens(ctx context.Context, job *rivertype.JobRow, taken []bucket) {
+	for _, b := range taken {
+		if err := m.store.ReturnToken(ctx, b.key, b.limit); err != nil {
+			slog.WarnContext(ctx, "failed to return rate limit token (non-critical)",
+				"bucket", b.key,
+				"job_id", job.ID,
+				"error", err,
+			)
+		}
+	}
+}
+
+func (m *RateLimitMiddleware) buckets(ctx context.Context, job *rivertype.JobRow, userID string) []bucket {
+	var buckets []bucket
+	if m.config.User.Enabled() {
+		buckets = append(buckets, bucket{key: "user:" + userID, limit: m.config.User})
+	}
+	if !m.config.Codebase.Enabled() {
+		return buckets
+	}
+
+	codebase, err := m.codebases.Resolve(ctx, job.EncodedArgs)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to resolve codebase for rate limit (non-critical)",
+			"job_id", job.ID,
+			"error", err,
+		)
+		return buckets
+	}
+	if codebase != nil {
+		buckets = append(buckets, bucket{key: "codebase:" + codebase.ID, limit: m.config.Codebase})
+	}
+	return buckets
+}
@@ -0,0 +1,161 @@
+package fairness
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/adapter/queue/jobref"
+)
+
+// memoryBucketStore is an in-memory token bucket without refill.
+type memoryBucketStore struct {
+	err    error
+	tokens map[string]int
+	taken  []string
+}
+
+func (s *memoryBucketStore) TakeToken(_ context.Context, key string, limit RateLimit) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	if s.tokens == nil {
+		s.tokens = make(map[string]int)
+	}
+	if _, ok := s.tokens[key]; !ok {
+		s.tokens[key] = limit.Burst
+	}
+	if s.tokens[key] < 1 {
+		return false, nil
+	}
+	s.tokens[key]--
+	s.taken = append(s.taken, key)
+	return true, nil
+}
+
+func (s *memoryBucketStore) ReturnToken(_ context.Context, key string, limit RateLimit) error {
+	s.tokens[key] = min(s.tokens[key]+1, limit.Burst)
+	return nil
+}
+
+type mockCodebaseResolver struct {
+	codebase *jobref.Codebase
+	err      error
+}
+
+func (r *mockCodebaseResolver) Resolve(_ context.Context, _ []byte) (*jobref.Codebase, error) {
+	return r.codebase, r.err
+}
+
+func newRateLimitJob(id int64, args string) *rivertype.JobRow {
+	return &rivertype.JobRow{ID: id, EncodedArgs: []byte(args)}
+}
+
+func TestRateLimitMiddleware_Work(t *testing.T) {
+	cfg := &RateLimitConfig{
+		Codebase: RateLimit{Burst: 1, PerMinute: 2},
+		User:     RateLimit{Burst: 2, PerMinute: 6},
+	}
+	codebases := &mockCodebaseResolver{codebase: &jobref.Codebase{ID: "cb-1"}}
+	userJob := `{"user_id":"u1","owner":"o","repo":"r"}`
+
+	t.Run("allows jobs within burst and snoozes beyond it", func(t *testing.T) {
+		store := &memoryBucketStore{}
+		m := NewRateLimitMiddleware(store, &JSONArgsExtractor{}, &mockCodebaseResolver{}, cfg)
+
+		for i := range 2 {
+			if err := m.Work(context.Background(), newRateLimitJob(int64(i), userJob), func(context.Context) error { return nil }); err != nil {
+				t.Fatalf("job %d within burst should run, got %v", i, err)
+			}
+		}
+
+		err := m.Work(context.Background(), newRateLimitJob(3, userJob), func(context.Context) error {
+			t.Error("job beyond burst should not run")
+			return nil
+		})
+		var snoozeErr *river.JobSnoozeError
+		if !errors.As(err, &snoozeErr) {
+			t.Fatalf("expected snooze, got %v", err)
+		}
+		if snoozeErr.Duration != 10*time.Second {
+			t.Errorf("snooze = %v, want user refill interval 10s", snoozeErr.Duration)
+		}
+	})
+
+	t.Run("limits per codebase", func(t *testing.T) {
+		store := &memoryBucketStore{}
+		m := NewRateLimitMiddleware(store, &JSONArgsExtractor{}, codebases, cfg)
+
+		if err := m.Work(context.Background(), newRateLimitJob(1, `{"user_id":"u1"}`), func(context.Context) error { return nil }); err != nil {
+			t.Fatalf("first job should run: %v", err)
+		}
+		err := m.Work(context.Background(), newRateLimitJob(2, `{"user_id":"u2"}`), func(context.Context) error { return nil })
+
+		var snoozeErr *river.JobSnoozeError
+		if !errors.As(err, &snoozeErr) {
+			t.Fatalf("second job on same codebase should snooze, got %v", err)
+		}
+		if snoozeErr.Duration != 30*time.Second {
+			t.Errorf("snooze = %v, want codebase refill interval 30s", snoozeErr.Duration)
+		}
+	})
+
+	t.Run("snoozed jobs return the tokens they took", func(t *testing.T) {
+		store := &memoryBucketStore{}
+		m := NewRateLimitMiddleware(store, &JSONArgsExtractor{}, codebases, cfg)
+
+		if err := m.Work(context.Background(), newRateLimitJob(1, `{"user_id":"u1"}`), func(context.Context) error { return nil }); err != nil {
+			t.Fatalf("first job should run: %v", err)
+		}
+		for i := range 3 {
+			err := m.Work(context.Background(), newRateLimitJob(int64(i+2), `{"user_id":"u2"}`), func(context.Context) error { return nil })
+			var snoozeErr *river.JobSnoozeError
+			if !errors.As(err, &snoozeErr) {
+				t.Fatalf("job on the exhausted codebase should snooze, got %v", err)
+			}
+		}
+		if got := store.tokens["user:u2"]; got != cfg.User.Burst {
+			t.Errorf("user bucket has %d tokens after snoozes, want %d", got, cfg.User.Burst)
+		}
+	})
+
+	t.Run("system jobs bypass limits", func(t *testing.T) {
+		store := &memoryBucketStore{}
+		m := NewRateLimitMiddleware(store, &JSONArgsExtractor{}, codebases, cfg)
+
+		for i := range 5 {
+			if err := m.Work(context.Background(), newRateLimitJob(int64(i), `{"owner":"o","repo":"r"}`), func(context.Context) error { return nil }); err != nil {
+				t.Fatalf("system job should run: %v", err)
+			}
+		}
+		if len(store.taken) != 0 {
+			t.Errorf("system jobs should not take tokens, took %v", store.taken)
+		}
+	})
+
+	t.Run("store errors fail open", func(t *testing.T) {
+		m := NewRateLimitMiddleware(&memoryBucketStore{err: errors.New("db down")}, &JSONArgsExtractor{}, codebases, cfg)
+
+		var ran bool
+		err := m.Work(context.Background(), newRateLimitJob(1, userJob), func(context.Context) error {
+			ran = true
+			return nil
+		})
+		if err != nil || !ran {
+			t.Errorf("expected job to run on store error, ran=%v err=%v", ran, err)
+		}
+	})
+}
+
+func TestRateLimit_RefillInterval(t *testing.T) {
+	if got := (RateLimit{Burst: 1, PerMinute: 4}).RefillInterval(); got != 15*time.Second {
+		t.Errorf("RefillInterval() = %v, want 15s", got)
+	}
+	if (RateLimit{Burst: 0, PerMinute: 4}).Enabled() {
+		t.Error("zero burst should disable the limit")
+	}
+}
@@ -0,0 +1,267 @@
+package fairness
+
+import (
+	"container/heap"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// HistoryJob is one job of the enqueue history replayed by Simulate.
+type HistoryJob struct {
+	CodebaseID string // empty when the job names no codebase
+	Duration   time.Duration
+	EnqueuedAt time.Time
+	ID         int64
+	Queue      string
+	StartedAt  time.Time // when the job actually started
+	Tier       PlanTier
+	UserID     string // empty for system jobs
+}
+
+// Policy is a set of fairness and priority parameters to simulate.
+type Policy struct {
+	Concurrency *Config          // nil disables the per-user concurrent limits
+	RateLimits  *RateLimitConfig // nil disables the token buckets
+	Workers     map[string]int   // River MaxWorkers by queue; queues without an entry never run out of workers
+}
+
+// TierWaits is the distribution of the time jobs of one tier waited between
+// enqueue and start.
+type TierWaits struct {
+	Jobs    int
+	Max     time.Duration
+	P50     time.Duration
+	P90     time.Duration
+	P99     time.Duration
+	Snoozes int      // attempts snoozed by a limit, simulated runs only
+	Tier    PlanTier // empty for system jobs
+}
+
+// minSimSnooze is the shortest snooze Simulate applies.
+const minSimSnooze = time.Second
+
+// tierOrder is the order of the tiers in reports, system jobs last.
+var tierOrder = []PlanTier{TierFree, TierPro, TierProPlus, TierEnterprise, ""}
+
+// ObservedWaits returns the waits the jobs of history actually had.
+func ObservedWaits(history []HistoryJob) []TierWaits {
+	waits := make(map[PlanTier][]time.Duration)
+	for _, job := range history {
+		waits[job.Tier] = append(waits[job.Tier], max(job.StartedAt.Sub(job.EnqueuedAt), 0))
+	}
+	return summarizeWaits(waits, nil)
+}
+
+// Simulate replays history under policy and returns the waits its jobs would
+// have had. Each job is enqueued at its original time and runs as long as its
+// last attempt did. Like River, a queue starts its oldest available job
+// whenever a worker is free, and a job refused by a limit is snoozed and
+// becomes available again later. Limits apply in the order of the
+// middleware: user bucket, codebase bucket, then concurrent limit. Snoozes
+// last at least minSimSnooze so the replay always moves forward, and their
+// jitter is drawn from a fixed seed, so equal inputs give equal reports.
+func Simulate(history []HistoryJob, policy Policy) []TierWaits {
+	s := &simulation{
+		active:  make(map[string]int),
+		buckets: make(map[string]*simBucket),
+		busy:    make(map[string]int),
+		history: history,
+		policy:  policy,
+		rng:     rand.New(rand.NewPCG(1, 2)),
+		snoozes: make(map[PlanTier]int),
+		waiting: make(map[string][]int),
+		waits:   make(map[PlanTier][]time.Duration),
+	}
+	for i, job := range history {
+		s.push(simEvent{at: job.EnqueuedAt, job: i})
+	}
+	for s.events.Len() > 0 {
+		ev := heap.Pop(&s.events).(simEvent)
+		job := history[ev.job]
+		if ev.done {
+			s.busy[job.Queue]--
+			if job.UserID != "" {
+				s.active[job.UserID]--
+			}
+		} else {
+			s.waiting[job.Queue] = append(s.waiting[job.Queue], ev.job)
+		}
+		s.dispatch(job.Queue, ev.at)
+	}
+	return summarizeWaits(s.waits, s.snoozes)
+}
+
+type simulation struct {
+	active  map[string]int // running jobs by user
+	buckets map[string]*simBucket
+	busy    map[string]int // running jobs by queue
+	events  simEvents
+	history []HistoryJob
+	policy  Policy
+	rng     *rand.Rand
+	seq     int
+	snoozes map[PlanTier]int
+	waiting map[string][]int // available jobs by queue, oldest first
+	waits   map[PlanTier][]time.Duration
+}
+
+// dispatch starts available jobs of queue while it has free workers.
+func (s *simulation) dispatch(queue string, now time.Time) {
+	workers, limited := s.policy.Workers[queue]
+	for len(s.waiting[queue]) > 0 && (!limited || s.busy[queue] < workers) {
+		i := s.waiting[queue][0]
+		s.waiting[queue] = s.waiting[queue][1:]
+		job := s.history[i]
+
+		if snooze, ok := s.admit(job, now); !ok {
+			s.snoozes[job.Tier]++
+			s.push(simEvent{at: now.Add(max(snooze, minSimSnooze)), job: i})
+			continue
+		}
+
+		s.busy[queue]++
+		if job.UserID != "" {
+			s.active[job.UserID]++
+		}
+		s.waits[job.Tier] = append(s.waits[job.Tier], now.Sub(job.EnqueuedAt))
+		s.push(simEvent{at: now.Add(job.Duration), done: true, job: i})
+	}
+}
+
+// admit applies the limits to a job about to start and returns how long it
+// is snoozed when one refuses it. System jobs bypass limits.
+func (s *simulation) admit(job HistoryJob, now time.Time) (time.Duration, bool) {
+	if job.UserID == "" {
+		return 0, true
+	}
+
+	if rl := s.policy.RateLimits; rl != nil {
+		var buckets []bucket
+		if rl.User.Enabled() {
+			buckets = append(buckets, bucket{key: "user:" + job.UserID, limit: rl.User})
+		}
+		if rl.Codebase.Enabled() && job.CodebaseID != "" {
+			buckets = append(buckets, bucket{key: "codebase:" + job.CodebaseID, limit: rl.Codebase})
+		}
+		for _, b := range buckets {
+			if !s.takeToken(b, now) {
+				return b.limit.RefillInterval() + s.jitter(rl.SnoozeJitter), false
+			}
+		}
+	}
+
+	if cfg := s.policy.Concurrency; cfg != nil && s.active[job.UserID] >= concurrentLimit(cfg, job.Tier) {
+		return cfg.SnoozeDuration + s.jitter(cfg.SnoozeJitter), false
+	}
+	return 0, true
+}
+
+// takeToken mirrors TakeRateLimitToken: buckets start full and refill
+// continuously up to their burst.
+func (s *simulation) takeToken(b bucket, now time.Time) bool {
+	sb, ok := s.buckets[b.key]
+	if !ok {
+		s.buckets[b.key] = &simBucket{tokens: float64(b.limit.Burst) - 1, updatedAt: now}
+		return true
+	}
+	tokens := min(float64(b.limit.Burst), sb.tokens+now.Sub(sb.updatedAt).Minutes()*float64(b.limit.PerMinute))
+	if tokens < 1 {
+		return false
+	}
+	sb.tokens, sb.updatedAt = tokens-1, now
+	return true
+}
+
+func (s *simulation) jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(s.rng.Int64N(int64(d)))
+}
+
+func (s *simulation) push(ev simEvent) {
+	ev.seq = s.seq
+	s.seq++
+	heap.Push(&s.events, ev)
+}
+
+// concurrentLimit matches PerUserLimiter: Pro Plus shares the Pro limit and
+// unknown tiers get the Free one. Limits below one, which NewPerUserLimiter
+// rejects, count as one.
+func concurrentLimit(cfg *Config, tier PlanTier) int {
+	limit := cfg.FreeConcurrentLimit
+	switch tier {
+	case TierPro, TierProPlus:
+		limit = cfg.ProConcurrentLimit
+	case TierEnterprise:
+		limit = cfg.EnterpriseConcurrentLimit
+	}
+	return max(limit, 1)
+}
+
+type simBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+type simEvent struct {
+	at   time.Time
+	done bool // the job finished; otherwise it became available
+	job  int
+	seq  int
+}
+
+// simEvents is a min-heap of events by time. At equal times finished jobs
+// come first, freeing their workers for the jobs becoming available.
+type simEvents []simEvent
+
+func (e simEvents) Len() int { return len(e) }
+
+func (e simEvents) Less(i, j int) bool {
+	if !e[i].at.Equal(e[j].at) {
+		return e[i].at.Before(e[j].at)
+	}
+	if e[i].done != e[j].done {
+		return e[i].done
+	}
+	return e[i].seq < e[j].seq
+}
+
+func (e simEvents) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+
+func (e *simEvents) Push(x any) { *e = append(*e, x.(simEvent)) }
+
+func (e *simEvents) Pop() any {
+	old := *e
+	ev := old[len(old)-1]
+	*e = old[:len(old)-1]
+	return ev
+}
+
+func summarizeWaits(waits map[PlanTier][]time.Duration, snoozes map[PlanTier]int) []TierWaits {
+	var report []TierWaits
+	for _, tier := range tierOrder {
+		w := waits[tier]
+		if len(w) == 0 {
+			continue
+		}
+		slices.Sort(w)
+		report = append(report, TierWaits{
+			Jobs:    len(w),
+			Max:     w[len(w)-1],
+			P50:     percentile(w, 50),
+			P90:     percentile(w, 90),
+			P99:     percentile(w, 99),
+			Snoozes: snoozes[tier],
+			Tier:    tier,
+		})
+	}
+	return report
+}
+
+// percentile returns the nearest-rank percentile p of sorted.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (len(sorted)*p + 99) / 100
+	return sorted[rank-1]
+}
@@ -0,0 +1,119 @@
+package fairness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var simStart = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+func historyJob(id int64, userID string, tier PlanTier, enqueuedAfter, duration time.Duration) HistoryJob {
+	return HistoryJob{
+		Duration:   duration,
+		EnqueuedAt: simStart.Add(enqueuedAfter),
+		ID:         id,
+		Queue:      "analysis_default",
+		StartedAt:  simStart.Add(enqueuedAfter),
+		Tier:       tier,
+		UserID:     userID,
+	}
+}
+
+func TestSimulate_WorkersQueueJobs(t *testing.T) {
+	history := []HistoryJob{
+		historyJob(1, "", "", 0, time.Minute),
+		historyJob(2, "", "", 0, time.Minute),
+		historyJob(3, "", "", 0, time.Minute),
+	}
+
+	report := Simulate(history, Policy{Workers: map[string]int{"analysis_default": 2}})
+
+	require.Len(t, report, 1)
+	assert.Equal(t, PlanTier(""), report[0].Tier)
+	assert.Equal(t, 3, report[0].Jobs)
+	assert.Equal(t, time.Duration(0), report[0].P50)
+	assert.Equal(t, time.Minute, report[0].Max, "the third job waits for a free worker")
+
+	unlimited := Simulate(history, Policy{})
+	assert.Equal(t, time.Duration(0), unlimited[0].Max, "queues without workers configured never wait")
+}
+
+func TestSimulate_ConcurrentLimitPerTier(t *testing.T) {
+	var history []HistoryJob
+	for i := range 3 {
+		history = append(history,
+			historyJob(int64(i), "free-user", TierFree, 0, 10*time.Second),
+			historyJob(int64(10+i), "pro-user", TierPro, 0, 10*time.Second),
+		)
+	}
+	policy := Policy{
+		Concurrency: &Config{
+			FreeConcurrentLimit:       1,
+			ProConcurrentLimit:        3,
+			EnterpriseConcurrentLimit: 5,
+			SnoozeDuration:            30 * time.Second,
+		},
+	}
+
+	report := Simulate(history, policy)
+
+	require.Len(t, report, 2)
+	free, pro := report[0], report[1]
+	assert.Equal(t, TierFree, free.Tier)
+	assert.Equal(t, 3, free.Snoozes, "the second and third jobs are snoozed together, then the third again")
+	assert.Equal(t, 30*time.Second, free.P50, "the second job starts after one snooze")
+	assert.Equal(t, 60*time.Second, free.Max, "the third job is snoozed twice")
+	assert.Equal(t, TierPro, pro.Tier)
+	assert.Equal(t, 0, pro.Snoozes)
+	assert.Equal(t, time.Duration(0), pro.Max)
+}
+
+func TestSimulate_UserRateLimit(t *testing.T) {
+	history := []HistoryJob{
+		historyJob(1, "u1", TierFree, 0, time.Second),
+		historyJob(2, "u1", TierFree, 2*time.Second, time.Second),
+	}
+	policy := Policy{RateLimits: &RateLimitConfig{User: RateLimit{Burst: 1, PerMinute: 2}}}
+
+	report := Simulate(history, policy)
+
+	require.Len(t, report, 1)
+	assert.Equal(t, 1, report[0].Snoozes)
+	assert.Equal(t, 30*time.Second, report[0].Max, "snoozed for one refill interval")
+}
+
+func TestSimulate_Deterministic(t *testing.T) {
+	var history []HistoryJob
+	for i := range 50 {
+		history = append(history, historyJob(int64(i), "u1", TierFree, time.Duration(i)*time.Second, 5*time.Second))
+	}
+	policy := Policy{Concurrency: DefaultConfig()}
+
+	assert.Equal(t, Simulate(history, policy), Simulate(history, policy))
+}
+
+func TestObservedWaits(t *testing.T) {
+	started := historyJob(1, "u1", TierEnterprise, 0, time.Second)
+	started.StartedAt = started.EnqueuedAt.Add(45 * time.Second)
+
+	report := ObservedWaits([]HistoryJob{started, historyJob(2, "", "", 0, time.Second)})
+
+	require.Len(t, report, 2)
+	assert.Equal(t, TierEnterprise, report[0].Tier)
+	assert.Equal(t, 45*time.Second, report[0].P99)
+	assert.Equal(t, PlanTier(""), report[1].Tier, "system jobs are reported last")
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Second
+	}
+
+	assert.Equal(t, 50*time.Second, percentile(sorted, 50))
+	assert.Equal(t, 99*time.Second, percentile(sorted, 99))
+	assert.Equal(t, time.Second, percentile(sorted[:1], 90))
+}
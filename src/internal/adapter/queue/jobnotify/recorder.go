@@ -0,0 +1,105 @@
+// Package jobnotify turns the final results of analysis and spec document
+// jobs into notification outcomes.
+package jobnotify
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/queue/analyze"
+	"github.com/specvital/worker/internal/adapter/queue/jobresult"
+	"github.com/specvital/worker/internal/adapter/queue/specview"
+	"github.com/specvital/worker/internal/domain/notification"
+)
+
+// errorCodeAlreadyCompleted is recorded by analysis jobs finding their
+// commit analyzed already; nothing happened worth notifying about.
+const errorCodeAlreadyCompleted = "already_completed"
+
+var _ jobresult.Recorder = (*Recorder)(nil)
+
+// Notifier accepts outcomes without blocking.
+type Notifier interface {
+	Notify(ctx context.Context, outcome notification.Outcome) bool
+}
+
+// Recorder records job results with the recorder it wraps and hands the
+// final ones to a notifier. Retryable attempts are not final; a job is
+// reported once, when it completes, is discarded or is cancelled.
+type Recorder struct {
+	next     jobresult.Recorder
+	notifier Notifier
+	now      func() time.Time
+}
+
+// NewRecorder wraps next.
+func NewRecorder(next jobresult.Recorder, notifier Notifier) *Recorder {
+	return &Recorder{next: next, notifier: notifier, now: time.Now}
+}
+
+// RecordResult implements jobresult.Recorder. The notification does not
+// depend on the result being stored.
+func (r *Recorder) RecordResult(ctx context.Context, result jobresult.Result) error {
+	err := r.next.RecordResult(ctx, result)
+	if outcome, ok := outcomeOf(result, r.now()); ok {
+		r.notifier.Notify(ctx, outcome)
+	}
+	return err
+}
+
+// outcomeOf maps a final result of a notified job kind to an outcome.
+func outcomeOf(result jobresult.Result, now time.Time) (notification.Outcome, bool) {
+	switch result.Status {
+	case jobresult.StatusCompleted, jobresult.StatusCancelled, jobresult.StatusDiscarded:
+	default:
+		return notification.Outcome{}, false
+	}
+	if result.ErrorCode == errorCodeAlreadyCompleted {
+		return notification.Outcome{}, false
+	}
+
+	outcome := notification.Outcome{
+		DocumentID: result.DocumentID,
+		JobID:      result.JobID,
+	}
+	if result.Status != jobresult.StatusCompleted {
+		outcome.ErrorClass = result.ErrorCode
+		if outcome.ErrorClass == "" {
+			outcome.ErrorClass = jobresult.ErrorCodeUnknown
+		}
+	}
+	if !result.StartedAt.IsZero() {
+		outcome.Duration = now.Sub(result.StartedAt)
+	}
+
+	switch result.Kind {
+	case analyze.AnalyzeArgs{}.Kind():
+		var args analyze.AnalyzeArgs
+		if err := json.Unmarshal(result.Args, &args); err != nil {
+			return notification.Outcome{}, false
+		}
+		outcome.Branch = args.Branch
+		outcome.CommitSHA = args.CommitSHA
+		outcome.Host = args.Host
+		outcome.Owner = args.Owner
+		outcome.Repo = args.Repo
+		outcome.Subject = notification.SubjectAnalysis
+		if args.UserID != nil {
+			outcome.UserID = *args.UserID
+		}
+
+	case specview.Args{}.Kind():
+		var args specview.Args
+		if err := json.Unmarshal(result.Args, &args); err != nil {
+			return notification.Outcome{}, false
+		}
+		outcome.AnalysisID = args.AnalysisID
+		outcome.Subject = notification.SubjectSpecDocument
+		outcome.UserID = args.UserID
+
+	default:
+		return notification.Outcome{}, false
+	}
+	return outcome, true
+}
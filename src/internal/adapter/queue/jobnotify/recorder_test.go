@@ -0,0 +1,123 @@
+package jobnotify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/queue/jobresult"
+	"github.com/specvital/worker/internal/domain/notification"
+)
+
+type fakeRecorder struct {
+	err     error
+	results []jobresult.Result
+}
+
+func (f *fakeRecorder) RecordResult(_ context.Context, result jobresult.Result) error {
+	f.results = append(f.results, result)
+	return f.err
+}
+
+type fakeNotifier struct {
+	outcomes []notification.Outcome
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, outcome notification.Outcome) bool {
+	f.outcomes = append(f.outcomes, outcome)
+	return true
+}
+
+func TestRecorder(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+	analyzeArgs := []byte(`{"commit_sha":"abc123","owner":"octocat","repo":"hello-world","branch":"main","user_id":"u1"}`)
+	specArgs := []byte(`{"analysis_id":"a1","language":"English","user_id":"u2"}`)
+
+	tests := []struct {
+		name   string
+		result jobresult.Result
+		want   *notification.Outcome
+	}{
+		{
+			name: "completed analysis",
+			result: jobresult.Result{
+				Args: analyzeArgs, JobID: 1, Kind: "analysis:analyze",
+				StartedAt: now.Add(-time.Minute), Status: jobresult.StatusCompleted,
+			},
+			want: &notification.Outcome{
+				Branch: "main", CommitSHA: "abc123", Duration: time.Minute, JobID: 1,
+				Owner: "octocat", Repo: "hello-world", Subject: notification.SubjectAnalysis, UserID: "u1",
+			},
+		},
+		{
+			name: "discarded spec document without an error code",
+			result: jobresult.Result{
+				Args: specArgs, JobID: 2, Kind: "specview:generate", Status: jobresult.StatusDiscarded,
+			},
+			want: &notification.Outcome{
+				AnalysisID: "a1", ErrorClass: jobresult.ErrorCodeUnknown, JobID: 2,
+				Subject: notification.SubjectSpecDocument, UserID: "u2",
+			},
+		},
+		{
+			name:   "retryable attempt",
+			result: jobresult.Result{Args: analyzeArgs, Kind: "analysis:analyze", Status: jobresult.StatusRetryable},
+		},
+		{
+			name: "already analyzed commit",
+			result: jobresult.Result{
+				Args: analyzeArgs, ErrorCode: errorCodeAlreadyCompleted, Kind: "analysis:analyze", Status: jobresult.StatusCompleted,
+			},
+		},
+		{
+			name:   "other job kind",
+			result: jobresult.Result{Args: []byte(`{}`), Kind: "specview:export", Status: jobresult.StatusCompleted},
+		},
+		{
+			name:   "unreadable args",
+			result: jobresult.Result{Args: []byte(`not json`), Kind: "analysis:analyze", Status: jobresult.StatusCompleted},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &fakeRecorder{}
+			notifier := &fakeNotifier{}
+			recorder := NewRecorder(next, notifier)
+			recorder.now = func() time.Time { return now }
+
+			if err := recorder.RecordResult(context.Background(), tt.result); err != nil {
+				t.Fatalf("RecordResult: %v", err)
+			}
+			if len(next.results) != 1 {
+				t.Errorf("expected the result to be recorded once, got %d", len(next.results))
+			}
+			if tt.want == nil {
+				if len(notifier.outcomes) != 0 {
+					t.Errorf("expected no notification, got %+v", notifier.outcomes)
+				}
+				return
+			}
+			if len(notifier.outcomes) != 1 || notifier.outcomes[0] != *tt.want {
+				t.Errorf("outcomes = %+v, want %+v", notifier.outcomes, *tt.want)
+			}
+		})
+	}
+}
+
+func TestRecorder_NotifiesWhenRecordingFails(t *testing.T) {
+	next := &fakeRecorder{err: errors.New("db down")}
+	notifier := &fakeNotifier{}
+	recorder := NewRecorder(next, notifier)
+
+	err := recorder.RecordResult(context.Background(), jobresult.Result{
+		Args: []byte(`{"commit_sha":"abc123","owner":"octocat","repo":"hello-world"}`), Kind: "analysis:analyze", Status: jobresult.StatusCompleted,
+	})
+	if err == nil {
+		t.Error("expected the recorder error to be returned")
+	}
+	if len(notifier.outcomes) != 1 {
+		t.Errorf("expected a notification, got %d", len(notifier.outcomes))
+	}
+}
@@ -0,0 +1,80 @@
+// Package jobref resolves the codebase a River job refers to from its args.
+package jobref
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+// Codebase is the codebase a job operates on.
+type Codebase struct {
+	ID     string
+	Region string // empty for the default region
+}
+
+// ref holds the args fields that identify a codebase across job kinds.
+type ref struct {
+	AnalysisID string `json:"analysis_id"`
+	DocumentID string `json:"document_id"`
+	Owner      string `json:"owner"`
+	Repo       string `json:"repo"`
+}
+
+// DBResolver looks up job codebases in database.
+type DBResolver struct {
+	queries *db.Queries
+}
+
+// NewDBResolver creates a new DBResolver with the given queries.
+func NewDBResolver(queries *db.Queries) *DBResolver {
+	return &DBResolver{queries: queries}
+}
+
+// Resolve finds the codebase by analysis_id, document_id or owner/repo.
+// Returns nil without error when the job names none of them or the codebase
+// does not exist yet (first analysis of a repository).
+func (r *DBResolver) Resolve(ctx context.Context, encodedArgs []byte) (*Codebase, error) {
+	var args ref
+	if err := json.Unmarshal(encodedArgs, &args); err != nil {
+		return nil, fmt.Errorf("parse job args: %w", err)
+	}
+
+	params := db.GetJobCodebaseParams{
+		AnalysisID: parseOptionalUUID(args.AnalysisID),
+		DocumentID: parseOptionalUUID(args.DocumentID),
+	}
+	if args.Owner != "" && args.Repo != "" {
+		params.Owner = pgtype.Text{String: args.Owner, Valid: true}
+		params.Repo = pgtype.Text{String: args.Repo, Valid: true}
+	}
+	if !params.AnalysisID.Valid && !params.DocumentID.Valid && !params.Owner.Valid {
+		return nil, nil
+	}
+
+	row, err := r.queries.GetJobCodebase(ctx, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get job codebase: %w", err)
+	}
+	return &Codebase{
+		ID:     uuid.UUID(row.ID.Bytes).String(),
+		Region: row.Region.String,
+	}, nil
+}
+
+func parseOptionalUUID(s string) pgtype.UUID {
+	parsed, err := uuid.Parse(s)
+	if err != nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: parsed, Valid: true}
+}
@@ -0,0 +1,210 @@
+// Package jobresult writes one normalized result row per River job, so the
+// web can learn the outcome of any job kind from a single table instead of
+// polling the tables each kind writes to.
+package jobresult
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/adapter/queue/poison"
+)
+
+var _ rivertype.WorkerMiddleware = (*ResultMiddleware)(nil)
+
+// Job statuses stored in job_results.status.
+const (
+	StatusCancelled = "cancelled"
+	StatusCompleted = "completed"
+	StatusDiscarded = "discarded" // failed on its last attempt
+	StatusRetryable = "retryable" // failed, another attempt is scheduled
+)
+
+// Error codes set when the worker reports none.
+const (
+	ErrorCodePoisonMessage = "poison_message"
+	ErrorCodeUnknown       = "unknown"
+)
+
+// Result is the outcome of one job attempt.
+type Result struct {
+	AnalysisID string
+	Args       json.RawMessage // encoded job args
+	Attempt    int
+	DocumentID string
+	EnqueuedAt time.Time
+	ErrorCode  string // empty on success
+	JobID      int64
+	Kind       string
+	Queue      string
+	StartedAt  time.Time // start of the attempt; zero when unknown
+	Stats      map[string]any
+	Status     string
+	UserID     string // empty for system jobs
+}
+
+// Recorder stores job results. Each attempt replaces the result of the
+// previous one.
+type Recorder interface {
+	RecordResult(ctx context.Context, result Result) error
+}
+
+// Details are the parts of a result only the worker knows.
+type Details struct {
+	AnalysisID string
+	DocumentID string
+	ErrorCode  string
+	Stats      map[string]any
+}
+
+type collectorKey struct{}
+
+type collector struct {
+	mu      sync.Mutex
+	details Details
+}
+
+// Record adds details to the result of the job running in ctx. Non-empty
+// fields replace earlier ones and stats are merged. Without the middleware
+// it does nothing.
+func Record(ctx context.Context, details Details) {
+	c, ok := ctx.Value(collectorKey{}).(*collector)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if details.AnalysisID != "" {
+		c.details.AnalysisID = details.AnalysisID
+	}
+	if details.DocumentID != "" {
+		c.details.DocumentID = details.DocumentID
+	}
+	if details.ErrorCode != "" {
+		c.details.ErrorCode = details.ErrorCode
+	}
+	if len(details.Stats) > 0 {
+		if c.details.Stats == nil {
+			c.details.Stats = make(map[string]any, len(details.Stats))
+		}
+		maps.Copy(c.details.Stats, details.Stats)
+	}
+}
+
+// ids holds the args fields that identify the subject of a job across kinds.
+type ids struct {
+	AnalysisID string `json:"analysis_id"`
+	DocumentID string `json:"document_id"`
+	UserID     string `json:"user_id"`
+}
+
+// ResultMiddleware records the result of every job attempt that completes,
+// fails or is cancelled. Snoozed attempts are not results. Workers add
+// details with Record; analysis and document IDs default to the job args.
+// The enqueue and start times and the user keep the queueing history the
+// fairness simulator replays.
+type ResultMiddleware struct {
+	river.MiddlewareDefaults
+	recorder Recorder
+}
+
+// NewResultMiddleware creates a new result middleware.
+func NewResultMiddleware(recorder Recorder) *ResultMiddleware {
+	return &ResultMiddleware{recorder: recorder}
+}
+
+// Work implements river.WorkerMiddleware.
+// Failing to record the result is non-critical and never changes the job
+// outcome.
+func (m *ResultMiddleware) Work(
+	ctx context.Context,
+	job *rivertype.JobRow,
+	doInner func(ctx context.Context) error,
+) error {
+	c := &collector{}
+	err := doInner(context.WithValue(ctx, collectorKey{}, c))
+
+	status, ok := resultStatus(job, err)
+	if !ok {
+		return err
+	}
+
+	c.mu.Lock()
+	details := c.details
+	c.mu.Unlock()
+
+	var args ids
+	if json.Unmarshal(job.EncodedArgs, &args) == nil {
+		details.AnalysisID = cmp.Or(details.AnalysisID, args.AnalysisID)
+		details.DocumentID = cmp.Or(details.DocumentID, args.DocumentID)
+	}
+
+	result := Result{
+		AnalysisID: details.AnalysisID,
+		Args:       job.EncodedArgs,
+		Attempt:    job.Attempt,
+		DocumentID: details.DocumentID,
+		EnqueuedAt: job.CreatedAt,
+		JobID:      job.ID,
+		Kind:       job.Kind,
+		Queue:      job.Queue,
+		Stats:      details.Stats,
+		Status:     status,
+		UserID:     args.UserID,
+	}
+	if job.AttemptedAt != nil {
+		result.StartedAt = *job.AttemptedAt
+	}
+	if err != nil {
+		result.ErrorCode = errorCode(details.ErrorCode, err)
+	}
+
+	if recErr := m.recorder.RecordResult(context.WithoutCancel(ctx), result); recErr != nil {
+		slog.WarnContext(ctx, "failed to record job result (non-critical)",
+			"job_id", job.ID,
+			"kind", job.Kind,
+			"status", status,
+			"error", recErr,
+		)
+	}
+	return err
+}
+
+// resultStatus maps the outcome of an attempt to a status; ok is false for
+// snoozes, which leave the job pending.
+func resultStatus(job *rivertype.JobRow, err error) (status string, ok bool) {
+	var cancelErr *rivertype.JobCancelError
+	var snoozeErr *rivertype.JobSnoozeError
+	switch {
+	case err == nil:
+		return StatusCompleted, true
+	case errors.As(err, &snoozeErr):
+		return "", false
+	case errors.As(err, &cancelErr):
+		return StatusCancelled, true
+	case job.Attempt >= job.MaxAttempts:
+		return StatusDiscarded, true
+	default:
+		return StatusRetryable, true
+	}
+}
+
+func errorCode(reported string, err error) string {
+	switch {
+	case reported != "":
+		return reported
+	case errors.Is(err, poison.ErrPoisonMessage):
+		return ErrorCodePoisonMessage
+	default:
+		return ErrorCodeUnknown
+	}
+}
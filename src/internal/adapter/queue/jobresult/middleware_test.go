@@ -0,0 +1,132 @@
+package jobresult
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/adapter/queue/poison"
+)
+
+type mockRecorder struct {
+	err     error
+	results []Result
+}
+
+func (m *mockRecorder) RecordResult(_ context.Context, result Result) error {
+	m.results = append(m.results, result)
+	return m.err
+}
+
+func TestResultMiddleware_Work(t *testing.T) {
+	enqueuedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	startedAt := enqueuedAt.Add(90 * time.Second)
+	newJob := func(attempt int) *rivertype.JobRow {
+		return &rivertype.JobRow{
+			ID:          42,
+			CreatedAt:   enqueuedAt,
+			AttemptedAt: &startedAt,
+			Kind:        "specview:generate",
+			Queue:       "specview_default",
+			Attempt:     attempt,
+			MaxAttempts: 3,
+			EncodedArgs: []byte(`{"analysis_id":"0b8f5e3c-57a1-4d2e-9a6b-1f0c2d3e4f50","user_id":"u1"}`),
+		}
+	}
+
+	t.Run("records a completed job with worker details", func(t *testing.T) {
+		recorder := &mockRecorder{}
+		m := NewResultMiddleware(recorder)
+
+		err := m.Work(context.Background(), newJob(1), func(ctx context.Context) error {
+			Record(ctx, Details{DocumentID: "doc-1", Stats: map[string]any{"cache_hit": true}})
+			Record(ctx, Details{Stats: map[string]any{"duration_ms": 12}})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(recorder.results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(recorder.results))
+		}
+		got := recorder.results[0]
+		if got.Status != StatusCompleted || got.ErrorCode != "" {
+			t.Errorf("unexpected status %q, error code %q", got.Status, got.ErrorCode)
+		}
+		if got.AnalysisID != "0b8f5e3c-57a1-4d2e-9a6b-1f0c2d3e4f50" || got.DocumentID != "doc-1" {
+			t.Errorf("unexpected ids %q, %q", got.AnalysisID, got.DocumentID)
+		}
+		if got.Stats["cache_hit"] != true || got.Stats["duration_ms"] != 12 {
+			t.Errorf("unexpected stats %v", got.Stats)
+		}
+		if got.JobID != 42 || got.Kind != "specview:generate" || got.Queue != "specview_default" || got.Attempt != 1 {
+			t.Errorf("unexpected job fields %+v", got)
+		}
+		if got.UserID != "u1" || !got.EnqueuedAt.Equal(enqueuedAt) || !got.StartedAt.Equal(startedAt) {
+			t.Errorf("unexpected queueing fields %q, %v, %v", got.UserID, got.EnqueuedAt, got.StartedAt)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		attempt  int
+		code     string
+		err      error
+		wantCode string
+		wantStat string
+	}{
+		{"retryable failure", 1, "clone_timeout", errors.New("timeout"), "clone_timeout", StatusRetryable},
+		{"failure on the last attempt", 3, "", errors.New("boom"), ErrorCodeUnknown, StatusDiscarded},
+		{"cancellation", 1, "invalid_input", river.JobCancel(errors.New("bad")), "invalid_input", StatusCancelled},
+		{"poison message", 1, "", river.JobCancel(fmt.Errorf("%w: bad args", poison.ErrPoisonMessage)), ErrorCodePoisonMessage, StatusCancelled},
+	}
+	for _, tt := range tests {
+		t.Run("records "+tt.name, func(t *testing.T) {
+			recorder := &mockRecorder{}
+			m := NewResultMiddleware(recorder)
+
+			err := m.Work(context.Background(), newJob(tt.attempt), func(ctx context.Context) error {
+				Record(ctx, Details{ErrorCode: tt.code})
+				return tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected job error returned, got %v", err)
+			}
+			if len(recorder.results) != 1 {
+				t.Fatalf("expected 1 result, got %d", len(recorder.results))
+			}
+			if got := recorder.results[0]; got.Status != tt.wantStat || got.ErrorCode != tt.wantCode {
+				t.Errorf("got status %q, error code %q; want %q, %q", got.Status, got.ErrorCode, tt.wantStat, tt.wantCode)
+			}
+		})
+	}
+
+	t.Run("skips snoozed attempts", func(t *testing.T) {
+		recorder := &mockRecorder{}
+		m := NewResultMiddleware(recorder)
+
+		_ = m.Work(context.Background(), newJob(1), func(ctx context.Context) error {
+			return river.JobSnooze(0)
+		})
+		if len(recorder.results) != 0 {
+			t.Errorf("expected no result, got %+v", recorder.results)
+		}
+	})
+
+	t.Run("recorder failure does not change the outcome", func(t *testing.T) {
+		m := NewResultMiddleware(&mockRecorder{err: errors.New("db down")})
+
+		if err := m.Work(context.Background(), newJob(1), func(ctx context.Context) error { return nil }); err != nil {
+			t.Errorf("expected success despite recorder error, got %v", err)
+		}
+	})
+}
+
+func TestRecord_WithoutMiddleware(t *testing.T) {
+	Record(context.Background(), Details{ErrorCode: "unknown"})
+}
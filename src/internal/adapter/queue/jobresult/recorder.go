@@ -0,0 +1,68 @@
+package jobresult
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ Recorder = (*DBRecorder)(nil)
+
+// DBRecorder upserts job results into job_results.
+type DBRecorder struct {
+	queries *db.Queries
+}
+
+// NewDBRecorder creates a new DBRecorder with the given queries.
+func NewDBRecorder(queries *db.Queries) *DBRecorder {
+	return &DBRecorder{queries: queries}
+}
+
+// RecordResult stores the result of the job's latest attempt.
+// IDs that are not UUIDs, and zero times, are stored as NULL.
+func (r *DBRecorder) RecordResult(ctx context.Context, result Result) error {
+	stats := result.Stats
+	if stats == nil {
+		stats = map[string]any{}
+	}
+	encoded, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("marshal job stats: %w", err)
+	}
+
+	if err := r.queries.UpsertJobResult(ctx, db.UpsertJobResultParams{
+		JobID:      result.JobID,
+		Kind:       result.Kind,
+		Queue:      result.Queue,
+		Status:     result.Status,
+		Attempt:    int32(result.Attempt),
+		AnalysisID: parseOptionalUUID(result.AnalysisID),
+		DocumentID: parseOptionalUUID(result.DocumentID),
+		ErrorCode:  pgtype.Text{String: result.ErrorCode, Valid: result.ErrorCode != ""},
+		Stats:      encoded,
+		UserID:     parseOptionalUUID(result.UserID),
+		EnqueuedAt: optionalTime(result.EnqueuedAt),
+		StartedAt:  optionalTime(result.StartedAt),
+	}); err != nil {
+		return fmt.Errorf("upsert job result: %w", err)
+	}
+	return nil
+}
+
+func optionalTime(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: !t.IsZero()}
+}
+
+func parseOptionalUUID(s string) pgtype.UUID {
+	parsed, err := uuid.Parse(s)
+	if err != nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: parsed, Valid: true}
+}
@@ -0,0 +1,126 @@
+// Package poison quarantines River jobs whose args cannot be decoded or are
+// invalid, instead of retrying them until their attempts run out.
+package poison
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+)
+
+var _ rivertype.WorkerMiddleware = (*PoisonMiddleware)(nil)
+
+// ErrPoisonMessage is the cancellation reason of quarantined jobs.
+var ErrPoisonMessage = errors.New("poison message")
+
+// Job is a quarantined job with its raw args.
+type Job struct {
+	Attempt     int
+	EncodedArgs []byte
+	Error       string
+	ID          int64
+	Kind        string
+	Queue       string
+}
+
+// Quarantine stores poison jobs for inspection.
+type Quarantine interface {
+	QuarantineJob(ctx context.Context, job Job) error
+}
+
+// Rule checks the args of one job kind.
+type Rule struct {
+	Kind     string
+	Validate func(encodedArgs []byte) error
+}
+
+// validator is implemented by args that check their own fields.
+type validator interface {
+	Validate() error
+}
+
+// RuleFor decodes args into T the way River does and, when T has a
+// Validate method, validates them.
+func RuleFor[T river.JobArgs]() Rule {
+	var zero T
+	return Rule{
+		Kind: zero.Kind(),
+		Validate: func(encodedArgs []byte) error {
+			var args T
+			if err := json.Unmarshal(encodedArgs, &args); err != nil {
+				return fmt.Errorf("decode args: %w", err)
+			}
+			if v, ok := any(args).(validator); ok {
+				return v.Validate()
+			}
+			return nil
+		},
+	}
+}
+
+// PoisonMiddleware checks job args before any other middleware parses them.
+// A job failing its kind's rule can never succeed, so on its first attempt it
+// is copied to the quarantine and cancelled with ErrPoisonMessage. Kinds
+// without a rule run unchecked.
+type PoisonMiddleware struct {
+	river.MiddlewareDefaults
+	quarantine Quarantine
+	rules      map[string]func([]byte) error
+}
+
+// NewPoisonMiddleware creates a middleware checking jobs against rules.
+func NewPoisonMiddleware(quarantine Quarantine, rules ...Rule) *PoisonMiddleware {
+	m := &PoisonMiddleware{
+		quarantine: quarantine,
+		rules:      make(map[string]func([]byte) error, len(rules)),
+	}
+	for _, rule := range rules {
+		m.rules[rule.Kind] = rule.Validate
+	}
+	return m
+}
+
+// Work implements river.WorkerMiddleware.
+// Failing to store the quarantine copy is non-critical: the cancelled job row
+// keeps the args until River prunes it.
+func (m *PoisonMiddleware) Work(
+	ctx context.Context,
+	job *rivertype.JobRow,
+	doInner func(ctx context.Context) error,
+) error {
+	validate, ok := m.rules[job.Kind]
+	if !ok {
+		return doInner(ctx)
+	}
+	checkErr := validate(job.EncodedArgs)
+	if checkErr == nil {
+		return doInner(ctx)
+	}
+
+	slog.WarnContext(ctx, "poison job quarantined",
+		"job_id", job.ID,
+		"kind", job.Kind,
+		"attempt", job.Attempt,
+		"error", checkErr,
+	)
+	if err := m.quarantine.QuarantineJob(ctx, Job{
+		Attempt:     job.Attempt,
+		EncodedArgs: job.EncodedArgs,
+		Error:       checkErr.Error(),
+		ID:          job.ID,
+		Kind:        job.Kind,
+		Queue:       job.Queue,
+	}); err != nil {
+		slog.WarnContext(ctx, "failed to quarantine poison job (non-critical)",
+			"job_id", job.ID,
+			"kind", job.Kind,
+			"error", err,
+		)
+	}
+	return river.JobCancel(fmt.Errorf("%w: %w", ErrPoisonMessage, checkErr))
+}
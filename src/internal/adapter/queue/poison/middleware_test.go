@@ -0,0 +1,82 @@
+package poison
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+)
+
+type testArgs struct {
+	Count int    `json:"count"`
+	Name  string `json:"name"`
+}
+
+func (testArgs) Kind() string { return "test:poison" }
+
+func (a testArgs) Validate() error {
+	if a.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+type mockQuarantine struct {
+	err  error
+	jobs []Job
+}
+
+func (m *mockQuarantine) QuarantineJob(_ context.Context, job Job) error {
+	m.jobs = append(m.jobs, job)
+	return m.err
+}
+
+func TestPoisonMiddleware_Work(t *testing.T) {
+	tests := []struct {
+		name          string
+		kind          string
+		args          string
+		quarantineErr error
+		wantRun       bool
+	}{
+		{name: "valid args run", kind: "test:poison", args: `{"name":"a","count":1}`, wantRun: true},
+		{name: "kinds without a rule run unchecked", kind: "test:other", args: `{"count":"x"}`, wantRun: true},
+		{name: "malformed json is quarantined", kind: "test:poison", args: `{"name":`},
+		{name: "mistyped field is quarantined", kind: "test:poison", args: `{"name":"a","count":"x"}`},
+		{name: "invalid args are quarantined", kind: "test:poison", args: `{"count":1}`},
+		{name: "quarantine failure still cancels", kind: "test:poison", args: `{"count":1}`, quarantineErr: errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quarantine := &mockQuarantine{err: tt.quarantineErr}
+			job := &rivertype.JobRow{ID: 7, Attempt: 1, Kind: tt.kind, Queue: "default", EncodedArgs: []byte(tt.args)}
+
+			var ran bool
+			err := NewPoisonMiddleware(quarantine, RuleFor[testArgs]()).Work(context.Background(), job, func(ctx context.Context) error {
+				ran = true
+				return nil
+			})
+
+			if ran != tt.wantRun {
+				t.Errorf("ran = %v, want %v", ran, tt.wantRun)
+			}
+			if tt.wantRun {
+				if err != nil || len(quarantine.jobs) != 0 {
+					t.Errorf("err = %v, quarantined = %+v; want a clean run", err, quarantine.jobs)
+				}
+				return
+			}
+
+			var cancelErr *river.JobCancelError
+			if !errors.As(err, &cancelErr) || !errors.Is(err, ErrPoisonMessage) {
+				t.Errorf("expected a poison cancellation, got %v", err)
+			}
+			if len(quarantine.jobs) != 1 || quarantine.jobs[0].ID != 7 || string(quarantine.jobs[0].EncodedArgs) != tt.args || quarantine.jobs[0].Error == "" {
+				t.Errorf("unexpected quarantine: %+v", quarantine.jobs)
+			}
+		})
+	}
+}
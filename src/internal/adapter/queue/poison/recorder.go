@@ -0,0 +1,36 @@
+package poison
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ Quarantine = (*DBQuarantine)(nil)
+
+// DBQuarantine stores poison jobs in poison_jobs. Rows outlive the River job,
+// which is pruned with other cancelled jobs.
+type DBQuarantine struct {
+	queries *db.Queries
+}
+
+// NewDBQuarantine creates a new DBQuarantine with the given queries.
+func NewDBQuarantine(queries *db.Queries) *DBQuarantine {
+	return &DBQuarantine{queries: queries}
+}
+
+// QuarantineJob stores the job's raw args and check error.
+func (q *DBQuarantine) QuarantineJob(ctx context.Context, job Job) error {
+	if err := q.queries.InsertPoisonJob(ctx, db.InsertPoisonJobParams{
+		Attempt:     int32(job.Attempt),
+		EncodedArgs: job.EncodedArgs,
+		Error:       job.Error,
+		JobID:       job.ID,
+		Kind:        job.Kind,
+		Queue:       job.Queue,
+	}); err != nil {
+		return fmt.Errorf("insert poison job: %w", err)
+	}
+	return nil
+}
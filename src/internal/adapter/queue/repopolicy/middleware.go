@@ -0,0 +1,93 @@
+// Package repopolicy enforces tenant repository policies on River jobs.
+package repopolicy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/specvital/worker/internal/domain/repopolicy"
+)
+
+var (
+	_ rivertype.JobInsertMiddleware = (*PolicyMiddleware)(nil)
+	_ rivertype.WorkerMiddleware    = (*PolicyMiddleware)(nil)
+)
+
+// jobTarget holds the args fields naming the requesting user and repository.
+type jobTarget struct {
+	Owner  string  `json:"owner"`
+	Repo   string  `json:"repo"`
+	UserID *string `json:"user_id"`
+}
+
+// PolicyMiddleware checks jobs requested by a user against the rules of the
+// user's tenant, both when they are inserted and when a worker starts them.
+// The second check catches rules added while a job was waiting.
+// Jobs without a user or repository, such as system jobs, are not checked.
+type PolicyMiddleware struct {
+	river.MiddlewareDefaults
+	repository repopolicy.Repository
+}
+
+// NewPolicyMiddleware creates a middleware reading rules from repository.
+func NewPolicyMiddleware(repository repopolicy.Repository) *PolicyMiddleware {
+	return &PolicyMiddleware{repository: repository}
+}
+
+// InsertMany implements rivertype.JobInsertMiddleware.
+// A violation fails the whole batch with repopolicy.ErrPolicyViolation.
+func (m *PolicyMiddleware) InsertMany(
+	ctx context.Context,
+	manyParams []*rivertype.JobInsertParams,
+	doInner func(context.Context) ([]*rivertype.JobInsertResult, error),
+) ([]*rivertype.JobInsertResult, error) {
+	for _, params := range manyParams {
+		if err := m.check(ctx, params.EncodedArgs); err != nil {
+			return nil, err
+		}
+	}
+	return doInner(ctx)
+}
+
+// Work implements river.WorkerMiddleware.
+// Violations cancel the job, since retrying cannot succeed; lookup errors
+// fail the attempt so the job is retried rather than run unchecked.
+func (m *PolicyMiddleware) Work(
+	ctx context.Context,
+	job *rivertype.JobRow,
+	doInner func(ctx context.Context) error,
+) error {
+	if err := m.check(ctx, job.EncodedArgs); err != nil {
+		if !errors.Is(err, repopolicy.ErrPolicyViolation) {
+			return err
+		}
+		slog.WarnContext(ctx, "job cancelled by tenant repository policy",
+			"job_id", job.ID,
+			"kind", job.Kind,
+			"error", err,
+		)
+		return river.JobCancel(err)
+	}
+	return doInner(ctx)
+}
+
+func (m *PolicyMiddleware) check(ctx context.Context, encodedArgs []byte) error {
+	var target jobTarget
+	if err := json.Unmarshal(encodedArgs, &target); err != nil {
+		return fmt.Errorf("parse job args: %w", err)
+	}
+	if target.UserID == nil || *target.UserID == "" || target.Owner == "" || target.Repo == "" {
+		return nil
+	}
+
+	rules, err := m.repository.ListRulesForUser(ctx, *target.UserID)
+	if err != nil {
+		return fmt.Errorf("load repository policy: %w", err)
+	}
+	return repopolicy.Check(rules, target.Owner, target.Repo)
+}
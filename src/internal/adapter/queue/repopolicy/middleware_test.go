@@ -0,0 +1,94 @@
+package repopolicy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/repopolicy"
+)
+
+type mockRepository struct {
+	err     error
+	lookups int
+	rules   []repopolicy.Rule
+}
+
+func (m *mockRepository) ListRulesForUser(_ context.Context, _ string) ([]repopolicy.Rule, error) {
+	m.lookups++
+	return m.rules, m.err
+}
+
+var allowAcme = []repopolicy.Rule{{Effect: repopolicy.EffectAllow, Pattern: "acme"}}
+
+func TestPolicyMiddleware_Work(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       string
+		repository *mockRepository
+		wantRun    bool
+		wantCancel bool
+		wantErr    bool
+	}{
+		{name: "allowed repository runs", args: `{"owner":"acme","repo":"api","user_id":"u1"}`, repository: &mockRepository{rules: allowAcme}, wantRun: true},
+		{name: "system job runs unchecked", args: `{"owner":"octocat","repo":"hello"}`, repository: &mockRepository{rules: allowAcme}, wantRun: true},
+		{name: "job without repository runs", args: `{"analysis_id":"a1","user_id":"u1"}`, repository: &mockRepository{rules: allowAcme}, wantRun: true},
+		{name: "denied repository is cancelled", args: `{"owner":"octocat","repo":"hello","user_id":"u1"}`, repository: &mockRepository{rules: allowAcme}, wantCancel: true},
+		{name: "lookup failure is retried", args: `{"owner":"acme","repo":"api","user_id":"u1"}`, repository: &mockRepository{err: errors.New("db down")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &rivertype.JobRow{ID: 7, Kind: "analysis:analyze", EncodedArgs: []byte(tt.args)}
+
+			var ran bool
+			err := NewPolicyMiddleware(tt.repository).Work(context.Background(), job, func(ctx context.Context) error {
+				ran = true
+				return nil
+			})
+
+			if ran != tt.wantRun {
+				t.Errorf("ran = %v, want %v", ran, tt.wantRun)
+			}
+			var cancelErr *river.JobCancelError
+			if got := errors.As(err, &cancelErr); got != tt.wantCancel {
+				t.Errorf("cancelled = %v, want %v (err: %v)", got, tt.wantCancel, err)
+			}
+			if tt.wantCancel && !errors.Is(err, repopolicy.ErrPolicyViolation) {
+				t.Errorf("expected ErrPolicyViolation, got %v", err)
+			}
+			if tt.wantErr && (err == nil || tt.wantCancel) {
+				t.Errorf("expected a retryable error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPolicyMiddleware_InsertMany(t *testing.T) {
+	insert := func(m *PolicyMiddleware, args ...string) (bool, error) {
+		params := make([]*rivertype.JobInsertParams, len(args))
+		for i, a := range args {
+			params[i] = &rivertype.JobInsertParams{EncodedArgs: []byte(a), Kind: "analysis:analyze"}
+		}
+		var inserted bool
+		_, err := m.InsertMany(context.Background(), params, func(context.Context) ([]*rivertype.JobInsertResult, error) {
+			inserted = true
+			return nil, nil
+		})
+		return inserted, err
+	}
+
+	m := NewPolicyMiddleware(&mockRepository{rules: allowAcme})
+
+	if inserted, err := insert(m, `{"owner":"acme","repo":"api","user_id":"u1"}`); err != nil || !inserted {
+		t.Errorf("allowed insert: inserted = %v, err = %v", inserted, err)
+	}
+
+	inserted, err := insert(m, `{"owner":"acme","repo":"api","user_id":"u1"}`, `{"owner":"octocat","repo":"hello","user_id":"u1"}`)
+	if inserted || !errors.Is(err, repopolicy.ErrPolicyViolation) {
+		t.Errorf("denied insert: inserted = %v, err = %v; want ErrPolicyViolation", inserted, err)
+	}
+}
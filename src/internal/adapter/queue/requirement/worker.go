@@ -0,0 +1,102 @@
+package requirement
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	specviewqueue "github.com/specvital/worker/internal/adapter/queue/specview"
+	"github.com/specvital/worker/internal/domain/requirement"
+	uc "github.com/specvital/worker/internal/usecase/requirement"
+)
+
+const (
+	jobKind          = "requirement:match"
+	jobTimeout       = 10 * time.Minute
+	maxRetryAttempts = 3
+)
+
+// Args represents the arguments for a requirement matching job.
+type Args struct {
+	DocumentID       string `json:"document_id" river:"unique"`
+	RequirementSetID string `json:"requirement_set_id" river:"unique"`
+}
+
+// Kind returns the unique identifier for this job type.
+func (Args) Kind() string { return jobKind }
+
+// InsertOpts returns the River insert options for this job type.
+func (Args) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       specviewqueue.QueueScheduled,
+		MaxAttempts: maxRetryAttempts,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+// Worker processes requirement matching jobs.
+type Worker struct {
+	river.WorkerDefaults[Args]
+	usecase *uc.MatchRequirementsUseCase
+}
+
+// NewWorker creates a new requirement matching worker.
+func NewWorker(usecase *uc.MatchRequirementsUseCase) *Worker {
+	return &Worker{usecase: usecase}
+}
+
+// Timeout returns the maximum duration for this job.
+func (w *Worker) Timeout(job *river.Job[Args]) time.Duration {
+	return jobTimeout
+}
+
+// Work matches a requirement set against a spec document.
+func (w *Worker) Work(ctx context.Context, job *river.Job[Args]) error {
+	args := job.Args
+
+	summary, err := w.usecase.Execute(ctx, uc.MatchRequest{
+		DocumentID: args.DocumentID,
+		SetID:      args.RequirementSetID,
+	})
+	if err != nil {
+		if isPermanentError(err) {
+			slog.WarnContext(ctx, "permanent error, cancelling job",
+				"job_id", job.ID,
+				"requirement_set_id", args.RequirementSetID,
+				"document_id", args.DocumentID,
+				"error", err,
+			)
+			return river.JobCancel(err)
+		}
+		slog.ErrorContext(ctx, "requirement match task failed",
+			"job_id", job.ID,
+			"requirement_set_id", args.RequirementSetID,
+			"document_id", args.DocumentID,
+			"attempt", job.Attempt,
+			"max_attempts", maxRetryAttempts,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.InfoContext(ctx, "requirement match task completed",
+		"job_id", job.ID,
+		"requirement_set_id", args.RequirementSetID,
+		"document_id", args.DocumentID,
+		"total_requirements", summary.TotalRequirements,
+		"covered_requirements", summary.CoveredRequirements,
+		"untested_requirements", len(summary.UntestedKeys),
+	)
+	return nil
+}
+
+func isPermanentError(err error) bool {
+	return errors.Is(err, requirement.ErrInvalidInput) ||
+		errors.Is(err, requirement.ErrSetNotFound) ||
+		errors.Is(err, uc.ErrCodebaseMismatch)
+}
@@ -0,0 +1,100 @@
+package requirement
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/requirement"
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/requirement"
+)
+
+type mockRepository struct {
+	getSetErr error
+}
+
+func (m *mockRepository) GetSet(_ context.Context, setID string) (*requirement.Set, error) {
+	if m.getSetErr != nil {
+		return nil, m.getSetErr
+	}
+	return &requirement.Set{
+		CodebaseID:   "cb-1",
+		ID:           setID,
+		Requirements: []requirement.Requirement{{ExternalKey: "REQ-1", ID: "r1", Title: "Refund"}},
+	}, nil
+}
+
+func (m *mockRepository) ReplaceCoverage(context.Context, string, string, []requirement.Match) error {
+	return nil
+}
+
+func (m *mockRepository) SaveSet(context.Context, *requirement.Set) error { return nil }
+
+type mockBehaviorSource struct {
+	codebaseID string
+}
+
+func (m *mockBehaviorSource) GetSearchIndexEntries(context.Context, string) ([]specview.SearchIndexEntry, error) {
+	return []specview.SearchIndexEntry{{BehaviorID: "b1", CodebaseID: m.codebaseID}}, nil
+}
+
+type mockMatcher struct {
+	err error
+}
+
+func (m *mockMatcher) Match(context.Context, []requirement.Requirement, []requirement.Candidate) ([]requirement.Match, error) {
+	return nil, m.err
+}
+
+func TestArgs_Kind(t *testing.T) {
+	if (Args{}).Kind() != "requirement:match" {
+		t.Errorf("expected kind 'requirement:match', got '%s'", Args{}.Kind())
+	}
+}
+
+func TestWorker_Work(t *testing.T) {
+	tests := []struct {
+		name       string
+		repo       *mockRepository
+		codebaseID string
+		matcherErr error
+		wantErr    bool
+		wantCancel bool
+	}{
+		{name: "success", repo: &mockRepository{}, codebaseID: "cb-1"},
+		{name: "set not found is cancelled", repo: &mockRepository{getSetErr: requirement.ErrSetNotFound}, codebaseID: "cb-1", wantErr: true, wantCancel: true},
+		{name: "codebase mismatch is cancelled", repo: &mockRepository{}, codebaseID: "cb-2", wantErr: true, wantCancel: true},
+		{name: "matcher failure is retried", repo: &mockRepository{}, codebaseID: "cb-1", matcherErr: errors.New("timeout"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := uc.NewMatchRequirementsUseCase(tt.repo, &mockBehaviorSource{codebaseID: tt.codebaseID}, &mockMatcher{err: tt.matcherErr})
+			worker := NewWorker(usecase)
+			job := &river.Job[Args]{
+				JobRow: &rivertype.JobRow{ID: 1, Attempt: 1},
+				Args:   Args{DocumentID: "doc-1", RequirementSetID: "set-1"},
+			}
+
+			err := worker.Work(context.Background(), job)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			var cancelErr *rivertype.JobCancelError
+			if isCancelled := errors.As(err, &cancelErr); isCancelled != tt.wantCancel {
+				t.Errorf("expected cancel=%v, got %T: %v", tt.wantCancel, err, err)
+			}
+		})
+	}
+}
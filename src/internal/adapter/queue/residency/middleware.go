@@ -0,0 +1,62 @@
+// Package residency keeps jobs on the workers of their codebase's region.
+package residency
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/specvital/worker/internal/domain/region"
+)
+
+// RegionResolver resolves the region of the codebase a job refers to.
+// found is false when the job names no codebase or the codebase does not exist yet.
+type RegionResolver interface {
+	ResolveRegion(ctx context.Context, encodedArgs []byte) (jobRegion string, found bool, err error)
+}
+
+// ResidencyMiddleware cancels jobs whose codebase is pinned to another region.
+// Queues already route jobs by region; this guards against jobs enqueued
+// to the wrong queue or workers pointed at the wrong database.
+type ResidencyMiddleware struct {
+	river.MiddlewareDefaults
+	region   string
+	resolver RegionResolver
+}
+
+// NewResidencyMiddleware creates a middleware for workers deployed in workerRegion.
+func NewResidencyMiddleware(workerRegion string, resolver RegionResolver) *ResidencyMiddleware {
+	return &ResidencyMiddleware{
+		region:   workerRegion,
+		resolver: resolver,
+	}
+}
+
+// Work implements river.WorkerMiddleware.
+// Resolution errors fail the attempt so the job is retried rather than run
+// without a residency check.
+func (m *ResidencyMiddleware) Work(
+	ctx context.Context,
+	job *rivertype.JobRow,
+	doInner func(ctx context.Context) error,
+) error {
+	jobRegion, found, err := m.resolver.ResolveRegion(ctx, job.EncodedArgs)
+	if err != nil {
+		return fmt.Errorf("resolve job region: %w", err)
+	}
+	if !found || jobRegion == m.region {
+		return doInner(ctx)
+	}
+
+	slog.ErrorContext(ctx, "job cancelled by data residency check",
+		"job_id", job.ID,
+		"kind", job.Kind,
+		"queue", job.Queue,
+		"codebase_region", jobRegion,
+		"worker_region", m.region,
+	)
+	return river.JobCancel(fmt.Errorf("%w: codebase region %q, worker region %q",
+		region.ErrRegionMismatch, jobRegion, m.region))
+}
@@ -0,0 +1,77 @@
+package residency
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/region"
+)
+
+type mockResolver struct {
+	err    error
+	found  bool
+	region string
+}
+
+func (m *mockResolver) ResolveRegion(_ context.Context, _ []byte) (string, bool, error) {
+	return m.region, m.found, m.err
+}
+
+func TestResidencyMiddleware_Work(t *testing.T) {
+	job := &rivertype.JobRow{ID: 7, Kind: "analysis:analyze", Queue: "analysis_default_eu"}
+
+	tests := []struct {
+		name         string
+		resolver     *mockResolver
+		workerRegion string
+		wantRun      bool
+		wantCancel   bool
+	}{
+		{name: "same region runs", resolver: &mockResolver{found: true, region: "eu"}, workerRegion: "eu", wantRun: true},
+		{name: "unknown codebase runs", resolver: &mockResolver{}, workerRegion: "eu", wantRun: true},
+		{name: "other region is cancelled", resolver: &mockResolver{found: true, region: "us"}, workerRegion: "eu", wantCancel: true},
+		{name: "default worker rejects pinned codebase", resolver: &mockResolver{found: true, region: "eu"}, workerRegion: region.Default, wantCancel: true},
+		{name: "regional worker rejects default codebase", resolver: &mockResolver{found: true}, workerRegion: "eu", wantCancel: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewResidencyMiddleware(tt.workerRegion, tt.resolver)
+
+			var ran bool
+			err := m.Work(context.Background(), job, func(ctx context.Context) error {
+				ran = true
+				return nil
+			})
+
+			if ran != tt.wantRun {
+				t.Errorf("ran = %v, want %v", ran, tt.wantRun)
+			}
+			var cancelErr *river.JobCancelError
+			if got := errors.As(err, &cancelErr); got != tt.wantCancel {
+				t.Errorf("cancelled = %v, want %v (err: %v)", got, tt.wantCancel, err)
+			}
+			if tt.wantCancel && !errors.Is(err, region.ErrRegionMismatch) {
+				t.Errorf("expected ErrRegionMismatch, got %v", err)
+			}
+		})
+	}
+
+	t.Run("resolver error fails the attempt", func(t *testing.T) {
+		m := NewResidencyMiddleware("eu", &mockResolver{err: errors.New("db down")})
+
+		err := m.Work(context.Background(), job, func(ctx context.Context) error {
+			t.Error("job should not run without a residency check")
+			return nil
+		})
+
+		var cancelErr *river.JobCancelError
+		if err == nil || errors.As(err, &cancelErr) {
+			t.Errorf("expected retryable error, got %v", err)
+		}
+	})
+}
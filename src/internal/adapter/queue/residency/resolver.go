@@ -0,0 +1,29 @@
+package residency
+
+import (
+	"context"
+
+	"github.com/specvital/worker/internal/adapter/queue/jobref"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ RegionResolver = (*DBRegionResolver)(nil)
+
+// DBRegionResolver resolves the codebase region of a job from database.
+type DBRegionResolver struct {
+	resolver *jobref.DBResolver
+}
+
+// NewDBRegionResolver creates a new DBRegionResolver with the given queries.
+func NewDBRegionResolver(queries *db.Queries) *DBRegionResolver {
+	return &DBRegionResolver{resolver: jobref.NewDBResolver(queries)}
+}
+
+// ResolveRegion returns the region of the codebase named by the job args.
+func (r *DBRegionResolver) ResolveRegion(ctx context.Context, encodedArgs []byte) (string, bool, error) {
+	codebase, err := r.resolver.Resolve(ctx, encodedArgs)
+	if err != nil || codebase == nil {
+		return "", false, err
+	}
+	return codebase.Region, true, nil
+}
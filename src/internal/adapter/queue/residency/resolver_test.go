@@ -0,0 +1,67 @@
+package residency
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/specvital/worker/internal/infra/db"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestDBRegionResolver_ResolveRegion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	var analysisID string
+	err := pool.QueryRow(ctx, `
+		WITH c AS (
+			INSERT INTO codebases (owner, name, external_repo_id, region)
+			VALUES ('acme', 'eu-repo', 'ext-eu', 'eu')
+			RETURNING id
+		)
+		INSERT INTO analyses (codebase_id, commit_sha, status)
+		SELECT id, 'abc123', 'completed' FROM c
+		RETURNING id::text
+	`).Scan(&analysisID)
+	if err != nil {
+		t.Fatalf("seed codebase: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO codebases (owner, name, external_repo_id) VALUES ('acme', 'default-repo', 'ext-default')
+	`); err != nil {
+		t.Fatalf("seed default codebase: %v", err)
+	}
+
+	resolver := NewDBRegionResolver(db.New(pool))
+
+	tests := []struct {
+		name      string
+		args      string
+		wantFound bool
+		want      string
+	}{
+		{name: "by analysis id", args: fmt.Sprintf(`{"analysis_id":%q}`, analysisID), wantFound: true, want: "eu"},
+		{name: "by owner and repo", args: `{"owner":"acme","repo":"eu-repo","commit_sha":"abc123"}`, wantFound: true, want: "eu"},
+		{name: "default region codebase", args: `{"owner":"acme","repo":"default-repo"}`, wantFound: true, want: ""},
+		{name: "new codebase", args: `{"owner":"acme","repo":"new-repo"}`, wantFound: false},
+		{name: "no codebase reference", args: `{"user_id":"u1"}`, wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := resolver.ResolveRegion(ctx, []byte(tt.args))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if found != tt.wantFound || got != tt.want {
+				t.Errorf("ResolveRegion() = (%q, %v), want (%q, %v)", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+var _ rivertype.WorkerMiddleware = (*Middleware)(nil)
+
+// Middleware classifies the error of a failed attempt for the policy, and
+// cancels the job once its class has used up its attempts.
+type Middleware struct {
+	river.MiddlewareDefaults
+	policy *Policy
+}
+
+func NewMiddleware(policy *Policy) *Middleware {
+	return &Middleware{policy: policy}
+}
+
+// Work implements river.WorkerMiddleware.
+func (m *Middleware) Work(ctx context.Context, job *rivertype.JobRow, doInner func(context.Context) error) error {
+	err := doInner(ctx)
+	if err == nil {
+		return nil
+	}
+	var cancelErr *rivertype.JobCancelError
+	var snoozeErr *rivertype.JobSnoozeError
+	if errors.As(err, &cancelErr) || errors.As(err, &snoozeErr) {
+		return err
+	}
+
+	class := Classify(err)
+	if limit := m.policy.Schedule(class).MaxAttempts; limit > 0 && job.Attempt >= limit && job.Attempt < job.MaxAttempts {
+		slog.WarnContext(ctx, "retry attempts of error class exhausted, cancelling job",
+			"job_id", job.ID,
+			"kind", job.Kind,
+			"attempt", job.Attempt,
+			"retry_class", class,
+			"error", err,
+		)
+		return river.JobCancel(fmt.Errorf("%s retries exhausted after %d attempts: %w", class, job.Attempt, err))
+	}
+	if job.Attempt < job.MaxAttempts {
+		m.policy.remember(job.ID, class)
+	}
+	return err
+}
+
+// Classify returns the retry class of a job error.
+func Classify(err error) Class {
+	if isRateLimit(err) {
+		return ClassRateLimit
+	}
+	if isTransient(err) {
+		return ClassTransient
+	}
+	return ClassDefault
+}
+
+func isRateLimit(err error) bool {
+	if errors.Is(err, specview.ErrRateLimited) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many requests")
+}
+
+// isTransient reports database failures a retry soon after usually clears:
+// connection failures and timeouts, deadlocks, serialization failures and
+// shutdowns.
+func isTransient(err error) bool {
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) || pgconn.SafeToRetry(err) || pgconn.Timeout(err) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(pgErr.Code, "08"): // connection exception
+		return true
+	case pgErr.Code == "40001" || pgErr.Code == "40P01": // serialization failure, deadlock
+		return true
+	case pgErr.Code == "57P01" || pgErr.Code == "57P03": // admin shutdown, cannot connect now
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Class
+	}{
+		{"rate limited provider", fmt.Errorf("phase 1: %w", specview.ErrRateLimited), ClassRateLimit},
+		{"throttled API", errors.New("get repository: 429 Too Many Requests"), ClassRateLimit},
+		{"deadlock", fmt.Errorf("save: %w", &pgconn.PgError{Code: "40P01"}), ClassTransient},
+		{"connection lost", &pgconn.PgError{Code: "08006"}, ClassTransient},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, ClassDefault},
+		{"other", errors.New("parse failed"), ClassDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware_Work(t *testing.T) {
+	p := NewPolicy(map[Class]Schedule{ClassRateLimit: {MaxAttempts: 2}})
+	m := NewMiddleware(p)
+	rateLimited := func(context.Context) error { return specview.ErrRateLimited }
+
+	t.Run("remembers the class of a retried failure", func(t *testing.T) {
+		job := &rivertype.JobRow{ID: 1, Attempt: 1, MaxAttempts: 5}
+		if err := m.Work(context.Background(), job, rateLimited); !errors.Is(err, specview.ErrRateLimited) {
+			t.Fatalf("error should pass through, got %v", err)
+		}
+		if p.classes[job.ID] != ClassRateLimit {
+			t.Errorf("class = %q, want rate_limit", p.classes[job.ID])
+		}
+	})
+
+	t.Run("cancels once the class attempts are used up", func(t *testing.T) {
+		job := &rivertype.JobRow{ID: 2, Attempt: 2, MaxAttempts: 5}
+		err := m.Work(context.Background(), job, rateLimited)
+		var cancelErr *rivertype.JobCancelError
+		if !errors.As(err, &cancelErr) || !errors.Is(err, specview.ErrRateLimited) {
+			t.Errorf("expected a cancellation wrapping the error, got %v", err)
+		}
+	})
+
+	t.Run("leaves the last attempt and cancellations alone", func(t *testing.T) {
+		last := &rivertype.JobRow{ID: 3, Attempt: 5, MaxAttempts: 5}
+		if err := m.Work(context.Background(), last, rateLimited); !errors.Is(err, specview.ErrRateLimited) {
+			t.Errorf("got %v", err)
+		}
+		if _, ok := p.classes[last.ID]; ok {
+			t.Error("no retry follows the last attempt, so nothing should be remembered")
+		}
+
+		cancel := river.JobCancel(errors.New("permanent"))
+		if err := m.Work(context.Background(), &rivertype.JobRow{ID: 4, Attempt: 1, MaxAttempts: 5}, func(context.Context) error { return cancel }); err != cancel {
+			t.Errorf("cancellation should pass through, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+// Package retry schedules the retries of failed jobs of every kind from one
+// policy: exponential backoff with jitter, with its own schedule and attempt
+// limit per error class, so a throttled provider is backed off longer than a
+// dropped database connection.
+package retry
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+)
+
+var _ river.ClientRetryPolicy = (*Policy)(nil)
+
+// Class groups the errors retried on the same schedule.
+type Class string
+
+const (
+	ClassDefault   Class = "default"
+	ClassRateLimit Class = "rate_limit" // a provider or API throttled the job
+	ClassTransient Class = "transient"  // lost database connection, deadlock or serialization failure
+)
+
+// Schedule is the backoff of one error class. Zero fields use the defaults
+// of the class.
+type Schedule struct {
+	InitialBackoff time.Duration
+	JitterFactor   float64 // share, 0-1, of the backoff randomly added or removed
+	MaxAttempts    int     // attempts of a job failing with the class; zero keeps the job's own limit
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// DefaultSchedules returns the schedule of every class. The default class
+// keeps the first retry of a failed job 10s out, like the workers' former
+// fixed backoff.
+func DefaultSchedules() map[Class]Schedule {
+	return map[Class]Schedule{
+		ClassDefault: {
+			InitialBackoff: 10 * time.Second,
+			JitterFactor:   0.2,
+			MaxBackoff:     10 * time.Minute,
+			Multiplier:     2,
+		},
+		ClassRateLimit: {
+			InitialBackoff: time.Minute,
+			JitterFactor:   0.3,
+			MaxBackoff:     30 * time.Minute,
+			Multiplier:     2,
+		},
+		ClassTransient: {
+			InitialBackoff: 2 * time.Second,
+			JitterFactor:   0.2,
+			MaxBackoff:     time.Minute,
+			Multiplier:     2,
+		},
+	}
+}
+
+// Backoff returns the delay before the retry following attempt, for a
+// random value in [0, 1).
+func (s Schedule) Backoff(attempt int, random float64) time.Duration {
+	backoff := float64(s.InitialBackoff) * math.Pow(s.Multiplier, float64(max(attempt, 1)-1))
+	backoff = min(backoff, float64(s.MaxBackoff))
+	backoff += backoff * s.JitterFactor * (2*random - 1)
+	return time.Duration(min(backoff, float64(s.MaxBackoff)))
+}
+
+// Policy computes when failed jobs are retried. River asks it for the next
+// retry right after a failed attempt, in the process that ran it, so the
+// middleware hands it the class of the failure in memory.
+type Policy struct {
+	mu        sync.Mutex
+	classes   map[int64]Class // class of the latest failure by job ID, until its retry is scheduled
+	now       func() time.Time
+	random    func() float64
+	schedules map[Class]Schedule
+}
+
+// NewPolicy creates a policy from per-class schedules. Missing classes and
+// zero fields use DefaultSchedules.
+func NewPolicy(schedules map[Class]Schedule) *Policy {
+	merged := DefaultSchedules()
+	for class, s := range schedules {
+		d, ok := merged[class]
+		if !ok {
+			continue
+		}
+		if s.InitialBackoff > 0 {
+			d.InitialBackoff = s.InitialBackoff
+		}
+		if s.JitterFactor > 0 {
+			d.JitterFactor = min(s.JitterFactor, 1)
+		}
+		if s.MaxAttempts > 0 {
+			d.MaxAttempts = s.MaxAttempts
+		}
+		if s.MaxBackoff > 0 {
+			d.MaxBackoff = max(s.MaxBackoff, d.InitialBackoff)
+		}
+		if s.Multiplier >= 1 {
+			d.Multiplier = s.Multiplier
+		}
+		merged[class] = d
+	}
+	return &Policy{
+		classes:   make(map[int64]Class),
+		now:       time.Now,
+		random:    rand.Float64,
+		schedules: merged,
+	}
+}
+
+// Schedule returns the schedule of class, the default class's for unknown ones.
+func (p *Policy) Schedule(class Class) Schedule {
+	if s, ok := p.schedules[class]; ok {
+		return s
+	}
+	return p.schedules[ClassDefault]
+}
+
+// NextRetry implements river.ClientRetryPolicy. Failures the middleware did
+// not classify, such as rescued stuck jobs, use the default class.
+func (p *Policy) NextRetry(job *rivertype.JobRow) time.Time {
+	p.mu.Lock()
+	class, ok := p.classes[job.ID]
+	delete(p.classes, job.ID)
+	p.mu.Unlock()
+	if !ok {
+		class = ClassDefault
+	}
+	return p.now().Add(p.Schedule(class).Backoff(job.Attempt, p.random()))
+}
+
+func (p *Policy) remember(jobID int64, class Class) {
+	p.mu.Lock()
+	p.classes[jobID] = class
+	p.mu.Unlock()
+}
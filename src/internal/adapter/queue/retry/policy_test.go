@@ -0,0 +1,69 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestSchedule_Backoff(t *testing.T) {
+	s := Schedule{InitialBackoff: 10 * time.Second, MaxBackoff: time.Minute, Multiplier: 2, JitterFactor: 0.5}
+
+	tests := []struct {
+		attempt int
+		random  float64
+		want    time.Duration
+	}{
+		{attempt: 1, random: 0.5, want: 10 * time.Second},
+		{attempt: 2, random: 0.5, want: 20 * time.Second},
+		{attempt: 3, random: 0.5, want: 40 * time.Second},
+		{attempt: 4, random: 0.5, want: time.Minute},
+		{attempt: 1, random: 0, want: 5 * time.Second},
+		{attempt: 2, random: 1, want: 30 * time.Second},
+		{attempt: 9, random: 1, want: time.Minute},
+	}
+	for _, tt := range tests {
+		if got := s.Backoff(tt.attempt, tt.random); got != tt.want {
+			t.Errorf("Backoff(%d, %v) = %v, want %v", tt.attempt, tt.random, got, tt.want)
+		}
+	}
+}
+
+func TestNewPolicy_Overrides(t *testing.T) {
+	p := NewPolicy(map[Class]Schedule{
+		ClassRateLimit: {InitialBackoff: 5 * time.Minute, MaxAttempts: 8},
+		"unknown":      {InitialBackoff: time.Hour},
+	})
+
+	rl := p.Schedule(ClassRateLimit)
+	if rl.InitialBackoff != 5*time.Minute || rl.MaxAttempts != 8 {
+		t.Errorf("override not applied: %+v", rl)
+	}
+	if rl.MaxBackoff != DefaultSchedules()[ClassRateLimit].MaxBackoff || rl.Multiplier != 2 {
+		t.Errorf("unset fields should keep the defaults: %+v", rl)
+	}
+	if p.Schedule("unknown") != p.Schedule(ClassDefault) {
+		t.Error("unknown classes should use the default schedule")
+	}
+}
+
+func TestPolicy_NextRetry(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	p := NewPolicy(nil)
+	p.now = func() time.Time { return now }
+	p.random = func() float64 { return 0.5 }
+
+	job := &rivertype.JobRow{ID: 7, Attempt: 2}
+	if got := p.NextRetry(job); got != now.Add(20*time.Second) {
+		t.Errorf("unclassified failure retried at %v, want the default schedule", got.Sub(now))
+	}
+
+	p.remember(job.ID, ClassTransient)
+	if got := p.NextRetry(job); got != now.Add(4*time.Second) {
+		t.Errorf("transient failure retried after %v, want 4s", got.Sub(now))
+	}
+	if _, ok := p.classes[job.ID]; ok {
+		t.Error("class should be forgotten once the retry is scheduled")
+	}
+}
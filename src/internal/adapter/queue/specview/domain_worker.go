@@ -0,0 +1,161 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+const (
+	// QueuePhase2 holds Phase 2 fan-out child jobs. Parents block while their
+	// children run, so children need their own workers to avoid starving.
+	QueuePhase2 = "specview_phase2"
+
+	domainJobKind    = "specview:phase2_domain"
+	domainJobTimeout = 3*time.Hour + 15*time.Minute // Phase 2 hard cap (3h) + data load and cache save
+)
+
+// DomainArgs represents the arguments for a Phase 2 child job converting one domain.
+// The job carries no user_id: the parent was already admitted by the fairness
+// and rate limit middleware, and its children are part of the same request.
+// RequestedBy names the parent's user only to observe their cancellation.
+type DomainArgs struct {
+	AnalysisID      string               `json:"analysis_id"`
+	BudgetTenantID  string               `json:"budget_tenant_id,omitempty"`
+	CacheScope      string               `json:"cache_scope,omitempty"`
+	Domain          specview.DomainGroup `json:"domain" river:"unique"`
+	ForceRegenerate bool                 `json:"force_regenerate,omitempty"`
+	Glossary        *specview.Glossary   `json:"glossary,omitempty"`
+	Language        string               `json:"language"`
+	ModelID         string               `json:"model_id"`
+	ModelOverride   string               `json:"model_override,omitempty"`
+	ParentJobID     int64                `json:"parent_job_id" river:"unique"`
+	Phase2Model     string               `json:"phase2_model,omitempty"`
+	PromptVersion   string               `json:"prompt_version,omitempty"`
+	RequestedAt     time.Time            `json:"requested_at"`
+	RequestedBy     string               `json:"requested_by,omitempty"`
+	Style           string               `json:"style,omitempty"`
+	UncachedTests   int                  `json:"uncached_tests"`
+}
+
+// Kind returns the unique identifier for this job type.
+func (DomainArgs) Kind() string { return domainJobKind }
+
+// InsertOpts returns the River insert options for this job type.
+func (DomainArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       QueuePhase2,
+		MaxAttempts: maxRetryAttempts,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+func newDomainArgs(task specview.Phase2DomainTask) DomainArgs {
+	return DomainArgs{
+		AnalysisID:      task.AnalysisID,
+		BudgetTenantID:  task.BudgetTenantID,
+		CacheScope:      task.CacheScope,
+		Domain:          task.Domain,
+		ForceRegenerate: task.ForceRegenerate,
+		Glossary:        task.Glossary,
+		Language:        string(task.Language),
+		ModelID:         task.ModelID,
+		ModelOverride:   task.ModelOverride,
+		ParentJobID:     task.ParentJobID,
+		Phase2Model:     task.Phase2Model,
+		PromptVersion:   task.PromptVersion,
+		RequestedAt:     task.RequestedAt,
+		RequestedBy:     task.RequestedBy,
+		Style:           task.Style,
+		UncachedTests:   task.UncachedTests,
+	}
+}
+
+// DomainWorker processes Phase 2 child jobs dispatched by a spec-view generation job.
+type DomainWorker struct {
+	river.WorkerDefaults[DomainArgs]
+	usecase *uc.GenerateSpecViewUseCase
+}
+
+// NewDomainWorker creates a new Phase 2 domain worker.
+func NewDomainWorker(usecase *uc.GenerateSpecViewUseCase) *DomainWorker {
+	return &DomainWorker{usecase: usecase}
+}
+
+// Timeout returns the maximum duration for this job.
+func (w *DomainWorker) Timeout(job *river.Job[DomainArgs]) time.Duration {
+	return domainJobTimeout
+}
+
+// Work converts the test names of one domain into the behavior cache.
+func (w *DomainWorker) Work(ctx context.Context, job *river.Job[DomainArgs]) error {
+	args := job.Args
+
+	if args.AnalysisID == "" || args.ParentJobID == 0 || args.Language == "" {
+		err := errors.New("analysis_id, parent_job_id and language are required")
+		slog.WarnContext(ctx, "invalid job arguments, cancelling",
+			"job_id", job.ID,
+			"error", err,
+		)
+		return river.JobCancel(err)
+	}
+
+	slog.InfoContext(ctx, "processing phase 2 domain task",
+		"job_id", job.ID,
+		"parent_job_id", args.ParentJobID,
+		"analysis_id", args.AnalysisID,
+		"domain", args.Domain.Name,
+		"uncached_tests", args.UncachedTests,
+		"attempt", job.Attempt,
+	)
+
+	err := w.usecase.ConvertDomain(ctx, specview.Phase2DomainTask{
+		AnalysisID:      args.AnalysisID,
+		BudgetTenantID:  args.BudgetTenantID,
+		CacheScope:      args.CacheScope,
+		Domain:          args.Domain,
+		ForceRegenerate: args.ForceRegenerate,
+		Glossary:        args.Glossary,
+		Language:        specview.Language(args.Language),
+		ModelID:         args.ModelID,
+		ModelOverride:   args.ModelOverride,
+		ParentJobID:     args.ParentJobID,
+		Phase2Model:     args.Phase2Model,
+		PromptVersion:   args.PromptVersion,
+		RequestedAt:     args.RequestedAt,
+		RequestedBy:     args.RequestedBy,
+		Style:           args.Style,
+		UncachedTests:   args.UncachedTests,
+	})
+	if err == nil {
+		return nil
+	}
+
+	if isPermanentError(err) {
+		slog.WarnContext(ctx, "permanent error, cancelling job",
+			"job_id", job.ID,
+			"parent_job_id", args.ParentJobID,
+			"error", err,
+		)
+		return river.JobCancel(err)
+	}
+
+	slog.ErrorContext(ctx, "phase 2 domain task failed",
+		"job_id", job.ID,
+		"parent_job_id", args.ParentJobID,
+		"domain", args.Domain.Name,
+		"attempt", job.Attempt,
+		"max_attempts", maxRetryAttempts,
+		"will_retry", job.Attempt < maxRetryAttempts,
+		"error", err,
+	)
+	return err
+}
@@ -0,0 +1,119 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+func newDomainJob(args DomainArgs) *river.Job[DomainArgs] {
+	return &river.Job[DomainArgs]{
+		JobRow: &rivertype.JobRow{ID: 7, Attempt: 1},
+		Args:   args,
+	}
+}
+
+func TestDomainArgs_InsertOpts(t *testing.T) {
+	if (DomainArgs{}).Kind() != "specview:phase2_domain" {
+		t.Errorf("unexpected kind %q", DomainArgs{}.Kind())
+	}
+
+	opts := DomainArgs{}.InsertOpts()
+	if opts.Queue != QueuePhase2 {
+		t.Errorf("expected queue %s, got %s", QueuePhase2, opts.Queue)
+	}
+	if !opts.UniqueOpts.ByArgs {
+		t.Error("expected UniqueOpts.ByArgs to be true")
+	}
+}
+
+func TestNewDomainArgs(t *testing.T) {
+	args := newDomainArgs(specview.Phase2DomainTask{
+		AnalysisID:    "analysis-1",
+		Domain:        specview.DomainGroup{Name: "Auth"},
+		Language:      "Korean",
+		ParentJobID:   42,
+		UncachedTests: 3,
+	})
+
+	if args.Language != "Korean" || args.ParentJobID != 42 || args.UncachedTests != 3 || args.Domain.Name != "Auth" {
+		t.Errorf("unexpected args: %+v", args)
+	}
+}
+
+func TestDomainWorker_Work(t *testing.T) {
+	validArgs := DomainArgs{
+		AnalysisID: "analysis-1",
+		Domain: specview.DomainGroup{
+			Name:     "Testing",
+			Features: []specview.FeatureGroup{{Name: "Basics", TestIndices: []int{0}}},
+		},
+		Language:    "English",
+		ModelID:     "test-model",
+		ParentJobID: 42,
+	}
+
+	tests := []struct {
+		name       string
+		args       DomainArgs
+		repo       *mockRepository
+		wantErr    bool
+		wantCancel bool
+	}{
+		{
+			name: "success",
+			args: validArgs,
+			repo: &mockRepository{},
+		},
+		{
+			name:       "missing parent job",
+			args:       DomainArgs{AnalysisID: "analysis-1", Language: "English"},
+			repo:       &mockRepository{},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name: "analysis not found cancels",
+			args: validArgs,
+			repo: &mockRepository{
+				getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+					return nil, specview.ErrAnalysisNotFound
+				},
+			},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name: "load failure retries",
+			args: validArgs,
+			repo: &mockRepository{
+				getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+					return nil, errors.New("connection reset")
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := NewDomainWorker(uc.NewGenerateSpecViewUseCase(tt.repo, &mockAIProvider{}, "test-model"))
+
+			err := worker.Work(context.Background(), newDomainJob(tt.args))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Work() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var cancelErr *rivertype.JobCancelError
+			if errors.As(err, &cancelErr) != tt.wantCancel {
+				t.Errorf("cancelled = %v, want %v (err: %v)", !tt.wantCancel, tt.wantCancel, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+const (
+	exportJobKind     = "specview:export"
+	exportJobTimeout  = 5 * time.Minute
+	exportMaxAttempts = 5
+)
+
+// ExportArgs represents the arguments for a spec document export job.
+type ExportArgs struct {
+	DocumentID string `json:"document_id" river:"unique"`
+	Format     string `json:"format" river:"unique"` // markdown, html or confluence
+}
+
+// Kind returns the unique identifier for this job type.
+func (ExportArgs) Kind() string { return exportJobKind }
+
+// InsertOpts returns the River insert options for this job type.
+func (ExportArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       QueueScheduled,
+		MaxAttempts: exportMaxAttempts,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+// ExportWorker processes spec document export jobs.
+type ExportWorker struct {
+	river.WorkerDefaults[ExportArgs]
+	usecase *uc.ExportSpecDocumentUseCase
+}
+
+// NewExportWorker creates a new export worker.
+func NewExportWorker(usecase *uc.ExportSpecDocumentUseCase) *ExportWorker {
+	return &ExportWorker{usecase: usecase}
+}
+
+// Timeout returns the maximum duration for this job.
+func (w *ExportWorker) Timeout(job *river.Job[ExportArgs]) time.Duration {
+	return exportJobTimeout
+}
+
+// Work renders a saved spec document and stores the artifact.
+func (w *ExportWorker) Work(ctx context.Context, job *river.Job[ExportArgs]) error {
+	args := job.Args
+
+	format, err := specview.ParseExportFormat(args.Format)
+	if err == nil && args.DocumentID == "" {
+		err = errors.New("document_id is required")
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "invalid job arguments, cancelling",
+			"job_id", job.ID,
+			"error", err,
+		)
+		return river.JobCancel(err)
+	}
+
+	artifact, err := w.usecase.Execute(ctx, args.DocumentID, format)
+	if err != nil {
+		if errors.Is(err, specview.ErrInvalidInput) || errors.Is(err, specview.ErrDocumentNotFound) {
+			slog.WarnContext(ctx, "permanent error, cancelling job",
+				"job_id", job.ID,
+				"document_id", args.DocumentID,
+				"error", err,
+			)
+			return river.JobCancel(err)
+		}
+		slog.ErrorContext(ctx, "specview export task failed",
+			"job_id", job.ID,
+			"document_id", args.DocumentID,
+			"format", args.Format,
+			"attempt", job.Attempt,
+			"max_attempts", exportMaxAttempts,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.InfoContext(ctx, "specview export task completed",
+		"job_id", job.ID,
+		"document_id", args.DocumentID,
+		"format", format,
+		"bytes", len(artifact.Data),
+	)
+	return nil
+}
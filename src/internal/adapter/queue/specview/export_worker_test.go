@@ -0,0 +1,110 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+type mockExportRepository struct {
+	getErr  error
+	saveErr error
+	saved   int
+}
+
+func (m *mockExportRepository) GetExportSource(_ context.Context, documentID string) (*specview.ExportSource, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return &specview.ExportSource{Document: &specview.SpecDocument{ID: documentID, Version: 1}}, nil
+}
+
+func (m *mockExportRepository) SaveExport(_ context.Context, _ *specview.ExportArtifact) error {
+	m.saved++
+	return m.saveErr
+}
+
+func TestExportArgs_Kind(t *testing.T) {
+	if (ExportArgs{}).Kind() != "specview:export" {
+		t.Errorf("expected kind 'specview:export', got '%s'", ExportArgs{}.Kind())
+	}
+}
+
+func TestExportWorker_Work(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       ExportArgs
+		repo       *mockExportRepository
+		wantErr    bool
+		wantCancel bool
+	}{
+		{
+			name: "success",
+			args: ExportArgs{DocumentID: "doc-1", Format: "markdown"},
+			repo: &mockExportRepository{},
+		},
+		{
+			name:       "unsupported format is cancelled",
+			args:       ExportArgs{DocumentID: "doc-1", Format: "pdf"},
+			repo:       &mockExportRepository{},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:       "empty document ID is cancelled",
+			args:       ExportArgs{Format: "html"},
+			repo:       &mockExportRepository{},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:       "missing document is cancelled",
+			args:       ExportArgs{DocumentID: "doc-1", Format: "html"},
+			repo:       &mockExportRepository{getErr: specview.ErrDocumentNotFound},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:    "transient failure is retried",
+			args:    ExportArgs{DocumentID: "doc-1", Format: "confluence"},
+			repo:    &mockExportRepository{saveErr: errors.New("connection reset")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := NewExportWorker(uc.NewExportSpecDocumentUseCase(tt.repo))
+			job := &river.Job[ExportArgs]{
+				JobRow: &rivertype.JobRow{ID: 1, Attempt: 1},
+				Args:   tt.args,
+			}
+
+			err := worker.Work(context.Background(), job)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				if tt.repo.saved != 1 {
+					t.Errorf("expected one saved export, got %d", tt.repo.saved)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			var cancelErr *rivertype.JobCancelError
+			isCancelled := errors.As(err, &cancelErr)
+			if tt.wantCancel != isCancelled {
+				t.Errorf("expected cancel=%v, got %T: %v", tt.wantCancel, err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package specview
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+
+	"github.com/specvital/worker/internal/domain/region"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ specview.Phase2FanOut = (*FanOut)(nil)
+
+// FanOut dispatches Phase 2 domain jobs through the River client of the running
+// parent job and reads their state back from river_job.
+type FanOut struct {
+	queries *db.Queries
+	region  string
+}
+
+// NewFanOut creates a FanOut routing child jobs to the Phase 2 queue of the given region.
+func NewFanOut(queries *db.Queries, jobRegion string) *FanOut {
+	return &FanOut{queries: queries, region: jobRegion}
+}
+
+// DispatchDomains enqueues one DomainArgs job per task.
+// Must be called from within a job, where the River client is in the context.
+func (f *FanOut) DispatchDomains(ctx context.Context, tasks []specview.Phase2DomainTask) error {
+	client, err := river.ClientFromContextSafely[pgx.Tx](ctx)
+	if err != nil {
+		return fmt.Errorf("river client unavailable: %w", err)
+	}
+
+	opts := &river.InsertOpts{Queue: region.QueueName(QueuePhase2, f.region)}
+	params := make([]river.InsertManyParams, len(tasks))
+	for i, task := range tasks {
+		params[i] = river.InsertManyParams{Args: newDomainArgs(task), InsertOpts: opts}
+	}
+	if _, err := client.InsertMany(ctx, params); err != nil {
+		return fmt.Errorf("insert domain jobs: %w", err)
+	}
+	return nil
+}
+
+// ChildStatus counts the domain jobs of the parent by state.
+func (f *FanOut) ChildStatus(ctx context.Context, parentJobID int64) (specview.Phase2ChildStatus, error) {
+	row, err := f.queries.GetPhase2ChildStatus(ctx, parentJobID)
+	if err != nil {
+		return specview.Phase2ChildStatus{}, fmt.Errorf("get child job status: %w", err)
+	}
+	return specview.Phase2ChildStatus{
+		Active:         int(row.Active),
+		Completed:      int(row.Completed),
+		ConvertedTests: int(row.ConvertedTests),
+		Failed:         int(row.Failed),
+	}, nil
+}
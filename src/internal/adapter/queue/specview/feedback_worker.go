@@ -0,0 +1,116 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	"github.com/specvital/worker/internal/adapter/queue/jobresult"
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+const (
+	feedbackJobKind     = "specview:apply-feedback"
+	feedbackJobTimeout  = 1 * time.Minute
+	feedbackMaxAttempts = 5
+)
+
+// FeedbackArgs represents the arguments for applying an accepted behavior
+// correction to the behavior cache.
+type FeedbackArgs struct {
+	FeedbackID string `json:"feedback_id" river:"unique"`
+}
+
+// Kind returns the unique identifier for this job type.
+func (FeedbackArgs) Kind() string { return feedbackJobKind }
+
+// InsertOpts returns the River insert options for this job type.
+func (FeedbackArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       QueueScheduled,
+		MaxAttempts: feedbackMaxAttempts,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+// FeedbackOutput is recorded as the River job output.
+type FeedbackOutput struct {
+	Applied    bool   `json:"applied"` // false when an earlier attempt applied it
+	DocumentID string `json:"document_id"`
+}
+
+// FeedbackWorker processes behavior feedback jobs.
+type FeedbackWorker struct {
+	river.WorkerDefaults[FeedbackArgs]
+	usecase *uc.ApplyBehaviorFeedbackUseCase
+}
+
+// NewFeedbackWorker creates a new behavior feedback worker.
+func NewFeedbackWorker(usecase *uc.ApplyBehaviorFeedbackUseCase) *FeedbackWorker {
+	return &FeedbackWorker{usecase: usecase}
+}
+
+// Timeout returns the maximum duration for this job.
+func (w *FeedbackWorker) Timeout(job *river.Job[FeedbackArgs]) time.Duration {
+	return feedbackJobTimeout
+}
+
+// Work writes the corrected description as a human-verified cache entry.
+func (w *FeedbackWorker) Work(ctx context.Context, job *river.Job[FeedbackArgs]) error {
+	if job.Args.FeedbackID == "" {
+		err := errors.New("feedback_id is required")
+		slog.WarnContext(ctx, "invalid job arguments, cancelling",
+			"job_id", job.ID,
+			"error", err,
+		)
+		return river.JobCancel(err)
+	}
+
+	result, err := w.usecase.Execute(ctx, job.Args.FeedbackID)
+	if err != nil {
+		if errors.Is(err, specview.ErrInvalidInput) || errors.Is(err, specview.ErrFeedbackNotFound) {
+			slog.WarnContext(ctx, "permanent error, cancelling job",
+				"job_id", job.ID,
+				"feedback_id", job.Args.FeedbackID,
+				"error", err,
+			)
+			return river.JobCancel(err)
+		}
+		slog.ErrorContext(ctx, "specview feedback task failed",
+			"job_id", job.ID,
+			"feedback_id", job.Args.FeedbackID,
+			"attempt", job.Attempt,
+			"max_attempts", feedbackMaxAttempts,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.InfoContext(ctx, "specview feedback task completed",
+		"job_id", job.ID,
+		"feedback_id", job.Args.FeedbackID,
+		"document_id", result.DocumentID,
+		"applied", result.Applied,
+	)
+
+	jobresult.Record(ctx, jobresult.Details{
+		DocumentID: result.DocumentID,
+		Stats:      map[string]any{"applied": result.Applied},
+	})
+	if err := river.RecordOutput(ctx, FeedbackOutput{
+		Applied:    result.Applied,
+		DocumentID: result.DocumentID,
+	}); err != nil {
+		slog.WarnContext(ctx, "failed to record job output (non-critical)",
+			"job_id", job.ID,
+			"error", err,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+type mockFeedbackRepository struct {
+	applyErr error
+	getErr   error
+	status   specview.FeedbackStatus
+}
+
+func (m *mockFeedbackRepository) GetFeedback(_ context.Context, feedbackID string) (*specview.BehaviorFeedback, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return &specview.BehaviorFeedback{
+		CacheKeyHash:         []byte("key"),
+		CorrectedDescription: "Signs the user in",
+		DocumentID:           "doc-1",
+		ID:                   feedbackID,
+		Status:               m.status,
+	}, nil
+}
+
+func (m *mockFeedbackRepository) ApplyFeedback(_ context.Context, _ *specview.BehaviorFeedback) error {
+	return m.applyErr
+}
+
+func TestFeedbackArgs_Kind(t *testing.T) {
+	if (FeedbackArgs{}).Kind() != "specview:apply-feedback" {
+		t.Errorf("expected kind 'specview:apply-feedback', got '%s'", FeedbackArgs{}.Kind())
+	}
+}
+
+func TestFeedbackArgs_InsertOpts(t *testing.T) {
+	opts := FeedbackArgs{}.InsertOpts()
+
+	if opts.Queue != QueueScheduled {
+		t.Errorf("expected queue %s, got %s", QueueScheduled, opts.Queue)
+	}
+	if !opts.UniqueOpts.ByArgs {
+		t.Error("expected UniqueOpts.ByArgs to be true")
+	}
+}
+
+func TestFeedbackWorker_Work(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       FeedbackArgs
+		repo       *mockFeedbackRepository
+		wantErr    bool
+		wantCancel bool
+	}{
+		{
+			name: "success",
+			args: FeedbackArgs{FeedbackID: "fb-1"},
+			repo: &mockFeedbackRepository{status: specview.FeedbackAccepted},
+		},
+		{
+			name: "already applied",
+			args: FeedbackArgs{FeedbackID: "fb-1"},
+			repo: &mockFeedbackRepository{status: specview.FeedbackApplied},
+		},
+		{
+			name:       "empty feedback ID is cancelled",
+			args:       FeedbackArgs{},
+			repo:       &mockFeedbackRepository{},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:       "missing feedback is cancelled",
+			args:       FeedbackArgs{FeedbackID: "fb-1"},
+			repo:       &mockFeedbackRepository{getErr: specview.ErrFeedbackNotFound},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:       "rejected feedback is cancelled",
+			args:       FeedbackArgs{FeedbackID: "fb-1"},
+			repo:       &mockFeedbackRepository{status: specview.FeedbackRejected},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:    "transient failure is retried",
+			args:    FeedbackArgs{FeedbackID: "fb-1"},
+			repo:    &mockFeedbackRepository{status: specview.FeedbackAccepted, applyErr: errors.New("connection reset")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := NewFeedbackWorker(uc.NewApplyBehaviorFeedbackUseCase(tt.repo))
+			job := &river.Job[FeedbackArgs]{
+				JobRow: &rivertype.JobRow{ID: 1, Attempt: 1},
+				Args:   tt.args,
+			}
+
+			err := worker.Work(context.Background(), job)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			var cancelErr *rivertype.JobCancelError
+			isCancelled := errors.As(err, &cancelErr)
+			if tt.wantCancel != isCancelled {
+				t.Errorf("expected cancel=%v, got %T: %v", tt.wantCancel, err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+const (
+	gapsJobKind     = "specview:gaps"
+	gapsJobTimeout  = 5 * time.Minute
+	gapsMaxAttempts = 5
+)
+
+// GapsArgs represents the arguments for a spec coverage gap analysis job.
+type GapsArgs struct {
+	DocumentID string `json:"document_id" river:"unique"`
+}
+
+// Kind returns the unique identifier for this job type.
+func (GapsArgs) Kind() string { return gapsJobKind }
+
+// InsertOpts returns the River insert options for this job type.
+func (GapsArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       QueueScheduled,
+		MaxAttempts: gapsMaxAttempts,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+// GapsWorker processes spec coverage gap analysis jobs.
+type GapsWorker struct {
+	river.WorkerDefaults[GapsArgs]
+	usecase *uc.AnalyzeSpecGapsUseCase
+}
+
+// NewGapsWorker creates a new gap analysis worker.
+func NewGapsWorker(usecase *uc.AnalyzeSpecGapsUseCase) *GapsWorker {
+	return &GapsWorker{usecase: usecase}
+}
+
+// Timeout returns the maximum duration for this job.
+func (w *GapsWorker) Timeout(job *river.Job[GapsArgs]) time.Duration {
+	return gapsJobTimeout
+}
+
+// Work computes the gap report for a saved spec document.
+func (w *GapsWorker) Work(ctx context.Context, job *river.Job[GapsArgs]) error {
+	if job.Args.DocumentID == "" {
+		err := errors.New("document_id is required")
+		slog.WarnContext(ctx, "invalid job arguments, cancelling",
+			"job_id", job.ID,
+			"error", err,
+		)
+		return river.JobCancel(err)
+	}
+
+	report, err := w.usecase.Execute(ctx, job.Args.DocumentID)
+	if err != nil {
+		if errors.Is(err, specview.ErrInvalidInput) {
+			slog.WarnContext(ctx, "permanent error, cancelling job",
+				"job_id", job.ID,
+				"document_id", job.Args.DocumentID,
+				"error", err,
+			)
+			return river.JobCancel(err)
+		}
+		slog.ErrorContext(ctx, "specview gaps task failed",
+			"job_id", job.ID,
+			"document_id", job.Args.DocumentID,
+			"attempt", job.Attempt,
+			"max_attempts", gapsMaxAttempts,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.InfoContext(ctx, "specview gaps task completed",
+		"job_id", job.ID,
+		"document_id", job.Args.DocumentID,
+		"total_source_files", report.TotalSourceFiles,
+		"covered_source_files", report.CoveredSourceFiles,
+		"uncovered_packages", len(report.UncoveredPackages),
+	)
+	return nil
+}
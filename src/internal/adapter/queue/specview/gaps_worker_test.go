@@ -0,0 +1,109 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+type mockGapRepository struct {
+	getErr  error
+	saveErr error
+}
+
+func (m *mockGapRepository) GetGapInputs(_ context.Context, _ string) (*specview.GapInputs, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return &specview.GapInputs{SourceFiles: []string{"pkg/a.go"}}, nil
+}
+
+func (m *mockGapRepository) SaveGapReport(_ context.Context, _ *specview.GapReport) error {
+	return m.saveErr
+}
+
+func TestGapsArgs_Kind(t *testing.T) {
+	if (GapsArgs{}).Kind() != "specview:gaps" {
+		t.Errorf("expected kind 'specview:gaps', got '%s'", GapsArgs{}.Kind())
+	}
+}
+
+func TestGapsArgs_InsertOpts(t *testing.T) {
+	opts := GapsArgs{}.InsertOpts()
+
+	if opts.Queue != QueueScheduled {
+		t.Errorf("expected queue %s, got %s", QueueScheduled, opts.Queue)
+	}
+	if !opts.UniqueOpts.ByArgs {
+		t.Error("expected UniqueOpts.ByArgs to be true")
+	}
+}
+
+func TestGapsWorker_Work(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       GapsArgs
+		repo       *mockGapRepository
+		wantErr    bool
+		wantCancel bool
+	}{
+		{
+			name: "success",
+			args: GapsArgs{DocumentID: "doc-1"},
+			repo: &mockGapRepository{},
+		},
+		{
+			name:       "empty document ID is cancelled",
+			args:       GapsArgs{},
+			repo:       &mockGapRepository{},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:       "invalid input is cancelled",
+			args:       GapsArgs{DocumentID: "bad"},
+			repo:       &mockGapRepository{getErr: specview.ErrInvalidInput},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:    "transient failure is retried",
+			args:    GapsArgs{DocumentID: "doc-1"},
+			repo:    &mockGapRepository{saveErr: errors.New("connection reset")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := NewGapsWorker(uc.NewAnalyzeSpecGapsUseCase(tt.repo))
+			job := &river.Job[GapsArgs]{
+				JobRow: &rivertype.JobRow{ID: 1, Attempt: 1},
+				Args:   tt.args,
+			}
+
+			err := worker.Work(context.Background(), job)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			var cancelErr *rivertype.JobCancelError
+			isCancelled := errors.As(err, &cancelErr)
+			if tt.wantCancel != isCancelled {
+				t.Errorf("expected cancel=%v, got %T: %v", tt.wantCancel, err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+
+	"github.com/specvital/worker/internal/domain/region"
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+const (
+	indexJobKind     = "specview:index"
+	indexJobTimeout  = 5 * time.Minute
+	indexMaxAttempts = 5
+)
+
+// IndexArgs represents the arguments for a spec document search indexing job.
+type IndexArgs struct {
+	DocumentID string `json:"document_id" river:"unique"`
+}
+
+// Kind returns the unique identifier for this job type.
+func (IndexArgs) Kind() string { return indexJobKind }
+
+// InsertOpts returns the River insert options for this job type.
+// Indexing is background work, so it never competes with user-facing generation.
+func (IndexArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       QueueScheduled,
+		MaxAttempts: indexMaxAttempts,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+// IndexWorker processes spec document search indexing jobs.
+type IndexWorker struct {
+	river.WorkerDefaults[IndexArgs]
+	usecase *uc.IndexSpecDocumentUseCase
+}
+
+// NewIndexWorker creates a new search indexing worker.
+func NewIndexWorker(usecase *uc.IndexSpecDocumentUseCase) *IndexWorker {
+	return &IndexWorker{usecase: usecase}
+}
+
+// Timeout returns the maximum duration for this job.
+func (w *IndexWorker) Timeout(job *river.Job[IndexArgs]) time.Duration {
+	return indexJobTimeout
+}
+
+// Work indexes a saved spec document.
+func (w *IndexWorker) Work(ctx context.Context, job *river.Job[IndexArgs]) error {
+	if job.Args.DocumentID == "" {
+		err := errors.New("document_id is required")
+		slog.WarnContext(ctx, "invalid job arguments, cancelling",
+			"job_id", job.ID,
+			"error", err,
+		)
+		return river.JobCancel(err)
+	}
+
+	count, err := w.usecase.Execute(ctx, job.Args.DocumentID)
+	if err != nil {
+		if errors.Is(err, specview.ErrInvalidInput) {
+			slog.WarnContext(ctx, "permanent error, cancelling job",
+				"job_id", job.ID,
+				"document_id", job.Args.DocumentID,
+				"error", err,
+			)
+			return river.JobCancel(err)
+		}
+		slog.ErrorContext(ctx, "specview index task failed",
+			"job_id", job.ID,
+			"document_id", job.Args.DocumentID,
+			"attempt", job.Attempt,
+			"max_attempts", indexMaxAttempts,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.InfoContext(ctx, "specview index task completed",
+		"job_id", job.ID,
+		"document_id", job.Args.DocumentID,
+		"entries", count,
+	)
+	return nil
+}
+
+// enqueueFollowUps schedules search indexing and gap analysis for a freshly generated document.
+// Failure is non-critical: the document is already saved and both jobs can be re-run later.
+func enqueueFollowUps(ctx context.Context, documentID, jobRegion string) {
+	client, err := river.ClientFromContextSafely[pgx.Tx](ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "river client unavailable, skipping follow-up jobs (non-critical)",
+			"document_id", documentID,
+			"error", err,
+		)
+		return
+	}
+
+	opts := &river.InsertOpts{Queue: region.QueueName(QueueScheduled, jobRegion)}
+	if _, err := client.InsertMany(ctx, []river.InsertManyParams{
+		{Args: IndexArgs{DocumentID: documentID}, InsertOpts: opts},
+		{Args: GapsArgs{DocumentID: documentID}, InsertOpts: opts},
+	}); err != nil {
+		slog.WarnContext(ctx, "failed to enqueue follow-up jobs (non-critical)",
+			"document_id", documentID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,111 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+type mockSearchRepository struct {
+	getErr     error
+	replaceErr error
+	replaced   int
+}
+
+func (m *mockSearchRepository) GetSearchIndexEntries(_ context.Context, documentID string) ([]specview.SearchIndexEntry, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return []specview.SearchIndexEntry{{BehaviorID: "b1", DocumentID: documentID}}, nil
+}
+
+func (m *mockSearchRepository) ReplaceDocumentEntries(_ context.Context, _ string, entries []specview.SearchIndexEntry) error {
+	m.replaced += len(entries)
+	return m.replaceErr
+}
+
+func TestIndexArgs_Kind(t *testing.T) {
+	if (IndexArgs{}).Kind() != "specview:index" {
+		t.Errorf("expected kind 'specview:index', got '%s'", IndexArgs{}.Kind())
+	}
+}
+
+func TestIndexArgs_InsertOpts(t *testing.T) {
+	opts := IndexArgs{}.InsertOpts()
+
+	if opts.Queue != QueueScheduled {
+		t.Errorf("expected queue %s, got %s", QueueScheduled, opts.Queue)
+	}
+	if !opts.UniqueOpts.ByArgs {
+		t.Error("expected UniqueOpts.ByArgs to be true")
+	}
+}
+
+func TestIndexWorker_Work(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       IndexArgs
+		repo       *mockSearchRepository
+		wantErr    bool
+		wantCancel bool
+	}{
+		{
+			name: "success",
+			args: IndexArgs{DocumentID: "doc-1"},
+			repo: &mockSearchRepository{},
+		},
+		{
+			name:       "empty document ID is cancelled",
+			args:       IndexArgs{},
+			repo:       &mockSearchRepository{},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:       "invalid input is cancelled",
+			args:       IndexArgs{DocumentID: "bad"},
+			repo:       &mockSearchRepository{getErr: specview.ErrInvalidInput},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:    "transient failure is retried",
+			args:    IndexArgs{DocumentID: "doc-1"},
+			repo:    &mockSearchRepository{replaceErr: errors.New("connection reset")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := NewIndexWorker(uc.NewIndexSpecDocumentUseCase(tt.repo, tt.repo))
+			job := &river.Job[IndexArgs]{
+				JobRow: &rivertype.JobRow{ID: 1, Attempt: 1},
+				Args:   tt.args,
+			}
+
+			err := worker.Work(context.Background(), job)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			var cancelErr *rivertype.JobCancelError
+			isCancelled := errors.As(err, &cancelErr)
+			if tt.wantCancel != isCancelled {
+				t.Errorf("expected cancel=%v, got %T: %v", tt.wantCancel, err, err)
+			}
+		})
+	}
+}
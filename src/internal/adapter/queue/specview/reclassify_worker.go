@@ -0,0 +1,127 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	"github.com/specvital/worker/internal/adapter/queue/jobresult"
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+const (
+	reclassifyJobKind     = "specview:reclassify-uncategorized"
+	reclassifyJobTimeout  = 30 * time.Minute
+	reclassifyMaxAttempts = 3
+)
+
+// ReclassifyArgs represents the arguments for re-placing the Uncategorized
+// tests of a spec document.
+type ReclassifyArgs struct {
+	DocumentID string `json:"document_id" river:"unique"`
+}
+
+// Kind returns the unique identifier for this job type.
+func (ReclassifyArgs) Kind() string { return reclassifyJobKind }
+
+// InsertOpts returns the River insert options for this job type.
+func (ReclassifyArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       QueueScheduled,
+		MaxAttempts: reclassifyMaxAttempts,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+// ReclassifyOutput is recorded as the River job output.
+type ReclassifyOutput struct {
+	DocumentID string `json:"document_id"` // new version, empty when nothing moved
+	Moved      int    `json:"moved"`
+	Remaining  int    `json:"remaining"`
+	Version    int32  `json:"version,omitempty"`
+}
+
+// ReclassifyWorker processes Uncategorized reclassification jobs.
+type ReclassifyWorker struct {
+	river.WorkerDefaults[ReclassifyArgs]
+	region  string
+	usecase *uc.ReclassifyUncategorizedUseCase
+}
+
+// NewReclassifyWorker creates a new reclassification worker. Follow-up jobs of
+// new versions go to the scheduled queue of region.
+func NewReclassifyWorker(usecase *uc.ReclassifyUncategorizedUseCase, region string) *ReclassifyWorker {
+	return &ReclassifyWorker{region: region, usecase: usecase}
+}
+
+// Timeout returns the maximum duration for this job.
+func (w *ReclassifyWorker) Timeout(job *river.Job[ReclassifyArgs]) time.Duration {
+	return reclassifyJobTimeout
+}
+
+// Work places the Uncategorized tests of a saved document and saves the new version.
+func (w *ReclassifyWorker) Work(ctx context.Context, job *river.Job[ReclassifyArgs]) error {
+	if job.Args.DocumentID == "" {
+		err := errors.New("document_id is required")
+		slog.WarnContext(ctx, "invalid job arguments, cancelling",
+			"job_id", job.ID,
+			"error", err,
+		)
+		return river.JobCancel(err)
+	}
+
+	result, err := w.usecase.Execute(ctx, job.Args.DocumentID)
+	if err != nil {
+		if errors.Is(err, specview.ErrInvalidInput) || errors.Is(err, specview.ErrDocumentNotFound) {
+			slog.WarnContext(ctx, "permanent error, cancelling job",
+				"job_id", job.ID,
+				"document_id", job.Args.DocumentID,
+				"error", err,
+			)
+			return river.JobCancel(err)
+		}
+		slog.ErrorContext(ctx, "specview reclassify task failed",
+			"job_id", job.ID,
+			"document_id", job.Args.DocumentID,
+			"attempt", job.Attempt,
+			"max_attempts", reclassifyMaxAttempts,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.InfoContext(ctx, "specview reclassify task completed",
+		"job_id", job.ID,
+		"source_document_id", job.Args.DocumentID,
+		"document_id", result.DocumentID,
+		"moved", result.Moved,
+		"remaining", result.Remaining,
+	)
+
+	jobresult.Record(ctx, jobresult.Details{
+		DocumentID: result.DocumentID,
+		Stats:      map[string]any{"moved": result.Moved, "remaining": result.Remaining},
+	})
+	if err := river.RecordOutput(ctx, ReclassifyOutput{
+		DocumentID: result.DocumentID,
+		Moved:      result.Moved,
+		Remaining:  result.Remaining,
+		Version:    result.Version,
+	}); err != nil {
+		slog.WarnContext(ctx, "failed to record job output (non-critical)",
+			"job_id", job.ID,
+			"error", err,
+		)
+	}
+
+	if result.DocumentID != "" {
+		enqueueFollowUps(ctx, result.DocumentID, w.region)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	uc "github.com/specvital/worker/internal/usecase/specview"
+)
+
+type mockReclassifyRepository struct {
+	getErr  error
+	saveErr error
+}
+
+func (m *mockReclassifyRepository) GetDocument(_ context.Context, _ string) (*specview.SpecDocument, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return &specview.SpecDocument{
+		Domains: []specview.Domain{
+			{Name: "Auth", Features: []specview.Feature{{Name: "Login"}}},
+			{Name: uc.UncategorizedName, Features: []specview.Feature{{
+				Name:      uc.UncategorizedName,
+				Behaviors: []specview.Behavior{{OriginalName: "TestLogin"}},
+			}}},
+		},
+	}, nil
+}
+
+func (m *mockReclassifyRepository) GetTestDataByAnalysisID(_ context.Context, _ string) ([]specview.FileInfo, error) {
+	return nil, nil
+}
+
+func (m *mockReclassifyRepository) SaveDocument(_ context.Context, doc *specview.SpecDocument) error {
+	doc.ID = "doc-2"
+	return m.saveErr
+}
+
+func TestReclassifyArgs_Kind(t *testing.T) {
+	if (ReclassifyArgs{}).Kind() != "specview:reclassify-uncategorized" {
+		t.Errorf("expected kind 'specview:reclassify-uncategorized', got '%s'", ReclassifyArgs{}.Kind())
+	}
+}
+
+func TestReclassifyArgs_InsertOpts(t *testing.T) {
+	opts := ReclassifyArgs{}.InsertOpts()
+
+	if opts.Queue != QueueScheduled {
+		t.Errorf("expected queue %s, got %s", QueueScheduled, opts.Queue)
+	}
+	if !opts.UniqueOpts.ByArgs {
+		t.Error("expected UniqueOpts.ByArgs to be true")
+	}
+}
+
+func TestReclassifyWorker_Work(t *testing.T) {
+	placeAll := &mockAIProvider{
+		placeNewTestsFn: func(ctx context.Context, input specview.PlacementInput) (*specview.PlacementOutput, *specview.TokenUsage, error) {
+			return &specview.PlacementOutput{Placements: []specview.TestPlacement{
+				{DomainName: "Auth", FeatureName: "Login", TestIndex: 0},
+			}}, nil, nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		args       ReclassifyArgs
+		repo       *mockReclassifyRepository
+		wantErr    bool
+		wantCancel bool
+	}{
+		{
+			name: "success",
+			args: ReclassifyArgs{DocumentID: "doc-1"},
+			repo: &mockReclassifyRepository{},
+		},
+		{
+			name:       "empty document ID is cancelled",
+			args:       ReclassifyArgs{},
+			repo:       &mockReclassifyRepository{},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:       "missing document is cancelled",
+			args:       ReclassifyArgs{DocumentID: "doc-1"},
+			repo:       &mockReclassifyRepository{getErr: specview.ErrDocumentNotFound},
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:    "transient failure is retried",
+			args:    ReclassifyArgs{DocumentID: "doc-1"},
+			repo:    &mockReclassifyRepository{saveErr: errors.New("connection reset")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := NewReclassifyWorker(uc.NewReclassifyUncategorizedUseCase(tt.repo, placeAll), "")
+			job := &river.Job[ReclassifyArgs]{
+				JobRow: &rivertype.JobRow{ID: 1, Attempt: 1},
+				Args:   tt.args,
+			}
+
+			err := worker.Work(context.Background(), job)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			var cancelErr *rivertype.JobCancelError
+			isCancelled := errors.As(err, &cancelErr)
+			if tt.wantCancel != isCancelled {
+				t.Errorf("expected cancel=%v, got %T: %v", tt.wantCancel, err, err)
+			}
+		})
+	}
+}
@@ -3,11 +3,13 @@ package specview
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/riverqueue/river"
 
+	"github.com/specvital/worker/internal/adapter/queue/jobresult"
 	"github.com/specvital/worker/internal/domain/quota"
 	"github.com/specvital/worker/internal/domain/specview"
 	uc "github.com/specvital/worker/internal/usecase/specview"
@@ -21,18 +23,24 @@ const (
 
 	jobKind          = "specview:generate"
 	maxRetryAttempts = 3
-	jobTimeout       = 90 * time.Minute
-	initialBackoff   = 10 * time.Second
+	jobTimeout       = 5 * time.Hour // Phase 1 (60m) + Phase 2 hard cap (3h) + Phase 3 and save
 )
 
 // Args represents the arguments for a spec-view generation job.
 type Args struct {
-	AnalysisID      string `json:"analysis_id" river:"unique"`
-	ForceRegenerate bool   `json:"force_regenerate,omitempty"` // skip cache and create new version
-	Language        string `json:"language" river:"unique"`    // optional, defaults to "English"
-	ModelID         string `json:"model_id,omitempty"`
-	Tier            string `json:"tier,omitempty"`
-	UserID          string `json:"user_id" river:"unique"` // required: document owner
+	AnalysisID      string   `json:"analysis_id" river:"unique"`
+	DryRun          bool     `json:"dry_run,omitempty" river:"unique"` // preview the document without AI calls or quota
+	ForceRegenerate bool     `json:"force_regenerate,omitempty"`       // skip cache and create new version
+	Language        string   `json:"language" river:"unique"`          // optional, defaults to the repo rules language or "English"
+	ModelID         string   `json:"model_id,omitempty"`
+	Phase1ModelID   string   `json:"phase1_model_id,omitempty" river:"unique"` // optional: Phase 1 model, from the worker's allow-list
+	Phase2ModelID   string   `json:"phase2_model_id,omitempty" river:"unique"` // optional: Phase 2 model, from the worker's allow-list
+	Phase3ModelID   string   `json:"phase3_model_id,omitempty" river:"unique"` // optional: Phase 3 model, from the worker's allow-list
+	Project         string   `json:"project,omitempty" river:"unique"`         // optional: monorepo project root to scope the document to
+	TeamSlices      bool     `json:"team_slices,omitempty"`                    // also save per-team child documents
+	Tier            string   `json:"tier,omitempty"`
+	TranslateTo     []string `json:"translate_to,omitempty"` // also save the document translated into these languages
+	UserID          string   `json:"user_id" river:"unique"` // required: document owner
 }
 
 // Kind returns the unique identifier for this job type.
@@ -49,19 +57,56 @@ func (Args) InsertOpts() river.InsertOpts {
 	}
 }
 
+// Validate rejects args without a document to generate. The language is
+// resolved later, so it may be empty.
+func (a Args) Validate() error {
+	if a.AnalysisID == "" {
+		return fmt.Errorf("%w: analysis ID is required", specview.ErrInvalidInput)
+	}
+	if a.UserID == "" {
+		return fmt.Errorf("%w: user ID is required", specview.ErrInvalidInput)
+	}
+	return nil
+}
+
+// Output is recorded on the River job row of a completed generation.
+type Output struct {
+	CacheHit       bool              `json:"cache_hit"`
+	DocumentID     string            `json:"document_id"`
+	DryRun         bool              `json:"dry_run,omitempty"`
+	Generator      string            `json:"generator,omitempty"`    // "ai" or "heuristic"
+	RemainingQuota *int64            `json:"remaining_quota"`        // behaviors left this month, null when unlimited or unknown
+	Translations   map[string]string `json:"translations,omitempty"` // language -> translated document ID
+}
+
 // Worker processes spec-view generation jobs.
 type Worker struct {
 	river.WorkerDefaults[Args]
 	quotaRepo quota.ReservationRepository
+	region    string
 	usecase   *uc.GenerateSpecViewUseCase
 }
 
+// WorkerOption is a functional option for configuring Worker.
+type WorkerOption func(*Worker)
+
+// WithRegion routes follow-up jobs to the scheduled queue of the given region.
+func WithRegion(r string) WorkerOption {
+	return func(w *Worker) {
+		w.region = r
+	}
+}
+
 // NewWorker creates a new spec-view worker.
-func NewWorker(usecase *uc.GenerateSpecViewUseCase, quotaRepo quota.ReservationRepository) *Worker {
-	return &Worker{
+func NewWorker(usecase *uc.GenerateSpecViewUseCase, quotaRepo quota.ReservationRepository, opts ...WorkerOption) *Worker {
+	w := &Worker{
 		quotaRepo: quotaRepo,
 		usecase:   usecase,
 	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
 }
 
 // Timeout returns the maximum duration for this job.
@@ -69,14 +114,6 @@ func (w *Worker) Timeout(job *river.Job[Args]) time.Duration {
 	return jobTimeout
 }
 
-// NextRetry returns the next retry time with exponential backoff.
-// Backoff: 10s, 40s, 90s (attempt² × 10s)
-func (w *Worker) NextRetry(job *river.Job[Args]) time.Time {
-	attempt := job.Attempt
-	backoff := time.Duration(attempt*attempt) * initialBackoff
-	return time.Now().Add(backoff)
-}
-
 // Work processes a spec-view generation job.
 func (w *Worker) Work(ctx context.Context, job *river.Job[Args]) error {
 	startTime := time.Now()
@@ -96,20 +133,14 @@ func (w *Worker) Work(ctx context.Context, job *river.Job[Args]) error {
 		"analysis_id", args.AnalysisID,
 		"language", language,
 		"model_id", args.ModelID,
+		"phase1_model_id", args.Phase1ModelID,
+		"phase2_model_id", args.Phase2ModelID,
+		"phase3_model_id", args.Phase3ModelID,
+		"dry_run", args.DryRun,
 		"attempt", job.Attempt,
 	)
 
-	if args.AnalysisID == "" {
-		err := errors.New("analysis_id is required")
-		slog.WarnContext(ctx, "invalid job arguments, cancelling",
-			"job_id", job.ID,
-			"error", err,
-		)
-		return river.JobCancel(err)
-	}
-
-	if args.UserID == "" {
-		err := errors.New("user_id is required")
+	if err := args.Validate(); err != nil {
 		slog.WarnContext(ctx, "invalid job arguments, cancelling",
 			"job_id", job.ID,
 			"error", err,
@@ -118,12 +149,26 @@ func (w *Worker) Work(ctx context.Context, job *river.Job[Args]) error {
 	}
 
 	lang := specview.Language(language)
+	translateTo := make([]specview.Language, len(args.TranslateTo))
+	for i, l := range args.TranslateTo {
+		translateTo[i] = specview.Language(l)
+	}
 
 	req := specview.SpecViewRequest{
 		AnalysisID:      args.AnalysisID,
+		DefaultLanguage: args.Language == "",
+		DryRun:          args.DryRun,
 		ForceRegenerate: args.ForceRegenerate,
+		JobID:           job.ID,
 		Language:        lang,
 		ModelID:         args.ModelID,
+		Phase1ModelID:   args.Phase1ModelID,
+		Phase2ModelID:   args.Phase2ModelID,
+		Phase3ModelID:   args.Phase3ModelID,
+		Project:         args.Project,
+		RequestedAt:     job.CreatedAt,
+		TeamSlices:      args.TeamSlices,
+		TranslateTo:     translateTo,
 		UserID:          args.UserID,
 	}
 
@@ -139,6 +184,19 @@ func (w *Worker) Work(ctx context.Context, job *river.Job[Args]) error {
 		"document_id", result.DocumentID,
 		"cache_hit", result.CacheHit,
 		"duration_ms", durationMs,
+		"generator", result.Generator,
+	}
+	if args.DryRun {
+		logFields = append(logFields, "dry_run", true)
+	}
+	if result.Shared {
+		logFields = append(logFields, "shared", true)
+	}
+	if len(result.TeamDocumentIDs) > 0 {
+		logFields = append(logFields, "team_documents", len(result.TeamDocumentIDs))
+	}
+	if len(result.Translations) > 0 {
+		logFields = append(logFields, "translations", len(result.Translations))
 	}
 	if result.AnalysisContext != nil {
 		logFields = append(logFields,
@@ -150,11 +208,66 @@ func (w *Worker) Work(ctx context.Context, job *river.Job[Args]) error {
 
 	slog.InfoContext(ctx, "specview generation task completed", logFields...)
 
+	stats := map[string]any{
+		"cache_hit":   result.CacheHit,
+		"duration_ms": durationMs,
+		"generator":   string(result.Generator),
+	}
+	if args.DryRun {
+		stats["dry_run"] = true
+	}
+	if len(result.TeamDocumentIDs) > 0 {
+		stats["team_documents"] = len(result.TeamDocumentIDs)
+	}
+	if len(result.Translations) > 0 {
+		stats["translations"] = len(result.Translations)
+	}
+	if result.Quality != nil {
+		stats["quality_score"] = result.Quality.Score
+		stats["quality_flagged"] = result.Quality.Flagged
+	}
+	jobresult.Record(ctx, jobresult.Details{DocumentID: result.DocumentID, Stats: stats})
+
+	output := Output{
+		CacheHit:       result.CacheHit,
+		DocumentID:     result.DocumentID,
+		DryRun:         args.DryRun,
+		Generator:      string(result.Generator),
+		RemainingQuota: result.RemainingQuota,
+	}
+	for _, t := range result.Translations {
+		if output.Translations == nil {
+			output.Translations = make(map[string]string, len(result.Translations))
+		}
+		output.Translations[string(t.Language)] = t.DocumentID
+	}
+	if err := river.RecordOutput(ctx, output); err != nil {
+		slog.WarnContext(ctx, "failed to record job output (non-critical)",
+			"job_id", job.ID,
+			"error", err,
+		)
+	}
+
+	// Cache hits reuse a document that was processed when it was first
+	// generated. Dry-run previews are neither indexed nor analyzed.
+	if args.DryRun {
+		return nil
+	}
+	if !result.CacheHit && result.DocumentID != "" {
+		enqueueFollowUps(ctx, result.DocumentID, w.region)
+	}
+	for _, t := range result.Translations {
+		if !t.CacheHit {
+			enqueueFollowUps(ctx, t.DocumentID, w.region)
+		}
+	}
+
 	return nil
 }
 
 func (w *Worker) handleError(ctx context.Context, job *river.Job[Args], err error) error {
 	args := job.Args
+	jobresult.Record(ctx, jobresult.Details{ErrorCode: errorCode(err)})
 
 	if isPermanentError(err) {
 		slog.WarnContext(ctx, "permanent error, cancelling job",
@@ -165,10 +278,14 @@ func (w *Worker) handleError(ctx context.Context, job *river.Job[Args], err erro
 			"will_retry", false,
 			"error", err,
 		)
+		w.usecase.DiscardCheckpoint(context.WithoutCancel(ctx), job.ID)
 		return river.JobCancel(err)
 	}
 
 	willRetry := job.Attempt < maxRetryAttempts
+	if !willRetry {
+		w.usecase.DiscardCheckpoint(context.WithoutCancel(ctx), job.ID)
+	}
 	slog.ErrorContext(ctx, "specview generation task failed",
 		"job_id", job.ID,
 		"analysis_id", args.AnalysisID,
@@ -181,7 +298,30 @@ func (w *Worker) handleError(ctx context.Context, job *river.Job[Args], err erro
 	return err
 }
 
+// errorCode names err in the job result.
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, specview.ErrAnalysisNotFound):
+		return "analysis_not_found"
+	case errors.Is(err, specview.ErrBudgetExceeded):
+		return "budget_exceeded"
+	case errors.Is(err, specview.ErrEncryptionUnavailable):
+		return "encryption_unavailable"
+	case errors.Is(err, specview.ErrGenerationCancelled):
+		return "generation_cancelled"
+	case errors.Is(err, specview.ErrInvalidInput):
+		return "invalid_input"
+	case errors.Is(err, specview.ErrInvalidOutput):
+		return "invalid_output"
+	default:
+		return jobresult.ErrorCodeUnknown
+	}
+}
+
 func isPermanentError(err error) bool {
 	return errors.Is(err, specview.ErrAnalysisNotFound) ||
+		errors.Is(err, specview.ErrBudgetExceeded) ||
+		errors.Is(err, specview.ErrEncryptionUnavailable) ||
+		errors.Is(err, specview.ErrGenerationCancelled) ||
 		errors.Is(err, specview.ErrInvalidInput)
 }
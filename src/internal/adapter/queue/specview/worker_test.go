@@ -3,6 +3,8 @@ package specview
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -198,6 +200,17 @@ func TestArgs_Kind(t *testing.T) {
 	}
 }
 
+func TestArgs_Validate(t *testing.T) {
+	if err := (Args{AnalysisID: "a1", UserID: "u1"}).Validate(); err != nil {
+		t.Errorf("expected valid args without a language, got %v", err)
+	}
+	for _, args := range []Args{{UserID: "u1"}, {AnalysisID: "a1"}} {
+		if err := args.Validate(); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("%+v: expected ErrInvalidInput, got %v", args, err)
+		}
+	}
+}
+
 func TestArgs_InsertOpts(t *testing.T) {
 	args := Args{}
 	opts := args.InsertOpts()
@@ -210,6 +223,19 @@ func TestArgs_InsertOpts(t *testing.T) {
 	}
 }
 
+func TestArgs_UniqueFields(t *testing.T) {
+	argsType := reflect.TypeOf(Args{})
+	for _, name := range []string{"AnalysisID", "DryRun", "Language", "Phase1ModelID", "Phase2ModelID", "Phase3ModelID", "Project", "UserID"} {
+		field, ok := argsType.FieldByName(name)
+		if !ok {
+			t.Fatalf("Args has no field %s", name)
+		}
+		if field.Tag.Get("river") != "unique" {
+			t.Errorf("expected %s to be part of the unique key", name)
+		}
+	}
+}
+
 func TestWorker_Timeout(t *testing.T) {
 	repo, ai := newSuccessfulMocks()
 	usecase := uc.NewGenerateSpecViewUseCase(repo, ai, "test-model")
@@ -218,45 +244,11 @@ func TestWorker_Timeout(t *testing.T) {
 	job := newTestJob(Args{AnalysisID: "test-id", Language: "en"})
 	timeout := worker.Timeout(job)
 
-	if timeout != 90*time.Minute {
-		t.Errorf("expected timeout 90 minutes, got %v", timeout)
+	if timeout != 5*time.Hour {
+		t.Errorf("expected timeout 5 hours, got %v", timeout)
 	}
-}
-
-func TestWorker_NextRetry(t *testing.T) {
-	tests := []struct {
-		name            string
-		attempt         int
-		expectedBackoff time.Duration
-	}{
-		{"first attempt", 1, 10 * time.Second},
-		{"second attempt", 2, 40 * time.Second},
-		{"third attempt", 3, 90 * time.Second},
-	}
-
-	repo, ai := newSuccessfulMocks()
-	usecase := uc.NewGenerateSpecViewUseCase(repo, ai, "test-model")
-	worker := NewWorker(usecase, nil)
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			job := &river.Job[Args]{
-				JobRow: &rivertype.JobRow{
-					ID:      1,
-					Attempt: tt.attempt,
-				},
-				Args: Args{AnalysisID: "test-id", Language: "en"},
-			}
-
-			before := time.Now()
-			nextRetry := worker.NextRetry(job)
-
-			expectedTime := before.Add(tt.expectedBackoff)
-			diff := nextRetry.Sub(expectedTime)
-			if diff < -100*time.Millisecond || diff > 100*time.Millisecond {
-				t.Errorf("expected retry around %v from now, got %v", tt.expectedBackoff, nextRetry.Sub(before))
-			}
-		})
+	if budget := uc.DefaultPhase1Timeout + uc.DefaultPhase2MaxTimeout + uc.DefaultPhase3Timeout; timeout <= budget {
+		t.Errorf("timeout %v must exceed the phase budget %v", timeout, budget)
 	}
 }
 
@@ -267,6 +259,7 @@ func TestWorker_Work(t *testing.T) {
 		setupMocks func() (*mockRepository, *mockAIProvider)
 		wantErr    bool
 		wantCancel bool
+		wantErrIs  error
 	}{
 		{
 			name: "success case",
@@ -308,6 +301,7 @@ func TestWorker_Work(t *testing.T) {
 			},
 			wantErr:    true,
 			wantCancel: true,
+			wantErrIs:  specview.ErrInvalidInput,
 		},
 		{
 			name: "invalid args - empty user ID",
@@ -321,6 +315,7 @@ func TestWorker_Work(t *testing.T) {
 			},
 			wantErr:    true,
 			wantCancel: true,
+			wantErrIs:  specview.ErrInvalidInput,
 		},
 		{
 			name: "empty language defaults to English",
@@ -421,6 +416,9 @@ func TestWorker_Work(t *testing.T) {
 				if tt.wantCancel && !isCancelled {
 					t.Errorf("expected JobCancelError, got %T: %v", err, err)
 				}
+				if tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("expected %v, got %v", tt.wantErrIs, err)
+				}
 				if !tt.wantCancel && isCancelled {
 					t.Errorf("expected retryable error, got JobCancelError: %v", err)
 				}
@@ -503,6 +501,16 @@ func TestIsPermanentError(t *testing.T) {
 			err:       specview.ErrInvalidInput,
 			permanent: true,
 		},
+		{
+			name:      "budget exceeded",
+			err:       fmt.Errorf("%w: phase1 needs about 600 tokens", specview.ErrBudgetExceeded),
+			permanent: true,
+		},
+		{
+			name:      "cancelled by user",
+			err:       fmt.Errorf("AI processing failed: phase 2: %w", specview.ErrGenerationCancelled),
+			permanent: true,
+		},
 		{
 			name:      "wrapped analysis not found",
 			err:       errors.New("wrapped: " + specview.ErrAnalysisNotFound.Error()),
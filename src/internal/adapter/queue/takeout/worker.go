@@ -0,0 +1,114 @@
+package takeout
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	specviewqueue "github.com/specvital/worker/internal/adapter/queue/specview"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/domain/takeout"
+	uc "github.com/specvital/worker/internal/usecase/takeout"
+)
+
+const (
+	jobKind          = "tenant:takeout"
+	jobTimeout       = 30 * time.Minute
+	maxRetryAttempts = 3
+)
+
+// Args represents the arguments for a tenant takeout job. The takeout row is
+// created, pending, by whoever requests the export.
+type Args struct {
+	TakeoutID string `json:"takeout_id" river:"unique"`
+}
+
+// Kind returns the unique identifier for this job type.
+func (Args) Kind() string { return jobKind }
+
+// InsertOpts returns the River insert options for this job type.
+func (Args) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       specviewqueue.QueueScheduled,
+		MaxAttempts: maxRetryAttempts,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+// Worker processes tenant takeout jobs.
+type Worker struct {
+	river.WorkerDefaults[Args]
+	usecase *uc.ExportTenantUseCase
+}
+
+// NewWorker creates a new tenant takeout worker.
+func NewWorker(usecase *uc.ExportTenantUseCase) *Worker {
+	return &Worker{usecase: usecase}
+}
+
+// Timeout returns the maximum duration for this job.
+func (w *Worker) Timeout(job *river.Job[Args]) time.Duration {
+	return jobTimeout
+}
+
+// Work exports the tenant's data and records the download link. The takeout
+// is marked failed once the job is cancelled or out of attempts.
+func (w *Worker) Work(ctx context.Context, job *river.Job[Args]) error {
+	args := job.Args
+
+	archive, err := w.usecase.Execute(ctx, args.TakeoutID)
+	if errors.Is(err, uc.ErrAlreadyCompleted) {
+		slog.InfoContext(ctx, "takeout already completed, skipping",
+			"job_id", job.ID,
+			"takeout_id", args.TakeoutID,
+		)
+		return nil
+	}
+	if err != nil {
+		permanent := isPermanentError(err)
+		if permanent || job.Attempt >= maxRetryAttempts {
+			if failErr := w.usecase.Fail(ctx, args.TakeoutID, err); failErr != nil {
+				slog.WarnContext(ctx, "failed to record takeout failure (non-critical)",
+					"takeout_id", args.TakeoutID,
+					"error", failErr,
+				)
+			}
+		}
+		if permanent {
+			slog.WarnContext(ctx, "permanent error, cancelling job",
+				"job_id", job.ID,
+				"takeout_id", args.TakeoutID,
+				"error", err,
+			)
+			return river.JobCancel(err)
+		}
+		slog.ErrorContext(ctx, "tenant takeout task failed",
+			"job_id", job.ID,
+			"takeout_id", args.TakeoutID,
+			"attempt", job.Attempt,
+			"max_attempts", maxRetryAttempts,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.InfoContext(ctx, "tenant takeout task completed",
+		"job_id", job.ID,
+		"takeout_id", args.TakeoutID,
+		"object_key", archive.ObjectKey,
+		"bytes", archive.SizeBytes,
+		"expires_at", archive.ExpiresAt,
+	)
+	return nil
+}
+
+func isPermanentError(err error) bool {
+	return errors.Is(err, takeout.ErrInvalidInput) ||
+		errors.Is(err, takeout.ErrNotFound) ||
+		errors.Is(err, specview.ErrEncryptionUnavailable)
+}
@@ -0,0 +1,143 @@
+package takeout
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/domain/takeout"
+	uc "github.com/specvital/worker/internal/usecase/takeout"
+)
+
+type mockRepository struct {
+	failed    string
+	getErr    error
+	completed bool
+}
+
+func (m *mockRepository) GetTakeout(_ context.Context, id string) (*takeout.Takeout, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	status := takeout.StatusPending
+	if m.completed {
+		status = takeout.StatusCompleted
+	}
+	return &takeout.Takeout{ID: id, Status: status, TenantID: "tenant-1"}, nil
+}
+
+func (m *mockRepository) StartTakeout(context.Context, string) (bool, error) {
+	return !m.completed, nil
+}
+
+func (m *mockRepository) CompleteTakeout(context.Context, string, takeout.Archive) error { return nil }
+
+func (m *mockRepository) FailTakeout(_ context.Context, _ string, reason string) error {
+	m.failed = reason
+	return nil
+}
+
+func (m *mockRepository) ListMembers(context.Context, string) ([]takeout.Member, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) ListCodebases(context.Context, string) ([]takeout.Codebase, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) ListAnalyses(context.Context, string) ([]takeout.Analysis, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) ListTests(context.Context, string) ([]takeout.Test, error) { return nil, nil }
+
+func (m *mockRepository) ListDocumentIDs(context.Context, string) ([]string, error) { return nil, nil }
+
+func (m *mockRepository) ListUsageEvents(context.Context, string) ([]takeout.UsageEvent, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) ListAIUsage(context.Context, string) ([]takeout.AIUsage, error) {
+	return nil, nil
+}
+
+type mockDocuments struct{}
+
+func (mockDocuments) GetExportSource(context.Context, string) (*specview.ExportSource, error) {
+	return nil, specview.ErrDocumentNotFound
+}
+
+func (mockDocuments) SaveExport(context.Context, *specview.ExportArtifact) error { return nil }
+
+type mockStore struct {
+	putErr error
+}
+
+func (m *mockStore) Put(_ context.Context, _ string, body io.Reader, _ int64, _ string) error {
+	_, _ = io.Copy(io.Discard, body)
+	return m.putErr
+}
+
+func (m *mockStore) SignedURL(context.Context, string, time.Duration) (string, error) {
+	return "https://storage.example.com/a.zip", nil
+}
+
+func TestArgs_Kind(t *testing.T) {
+	if (Args{}).Kind() != "tenant:takeout" {
+		t.Errorf("expected kind 'tenant:takeout', got '%s'", Args{}.Kind())
+	}
+}
+
+func TestWorker_Work(t *testing.T) {
+	tests := []struct {
+		name       string
+		repo       *mockRepository
+		putErr     error
+		attempt    int
+		wantErr    bool
+		wantCancel bool
+		wantFailed bool
+	}{
+		{name: "success", repo: &mockRepository{}, attempt: 1},
+		{name: "completed takeout is skipped", repo: &mockRepository{completed: true}, attempt: 1},
+		{name: "missing takeout is cancelled", repo: &mockRepository{getErr: takeout.ErrNotFound}, attempt: 1, wantErr: true, wantCancel: true, wantFailed: true},
+		{name: "upload failure is retried", repo: &mockRepository{}, putErr: errors.New("timeout"), attempt: 1, wantErr: true},
+		{name: "last attempt marks the takeout failed", repo: &mockRepository{}, putErr: errors.New("timeout"), attempt: maxRetryAttempts, wantErr: true, wantFailed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := uc.NewExportTenantUseCase(tt.repo, mockDocuments{}, &mockStore{putErr: tt.putErr})
+			worker := NewWorker(usecase)
+			job := &river.Job[Args]{
+				JobRow: &rivertype.JobRow{ID: 1, Attempt: tt.attempt},
+				Args:   Args{TakeoutID: "to-1"},
+			}
+
+			err := worker.Work(context.Background(), job)
+			if (tt.repo.failed != "") != tt.wantFailed {
+				t.Errorf("failure recorded = %q, want recorded=%v", tt.repo.failed, tt.wantFailed)
+			}
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			var cancelErr *rivertype.JobCancelError
+			if isCancelled := errors.As(err, &cancelErr); isCancelled != tt.wantCancel {
+				t.Errorf("expected cancel=%v, got %T: %v", tt.wantCancel, err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package testrun
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+
+	"github.com/specvital/worker/internal/adapter/queue/analyze"
+	"github.com/specvital/worker/internal/domain/testrun"
+	uc "github.com/specvital/worker/internal/usecase/testrun"
+)
+
+const (
+	jobKind          = "testrun:ingest"
+	jobTimeout       = 5 * time.Minute
+	maxRetryAttempts = 5
+)
+
+// Args represents the arguments for a test result ingestion job.
+// AnalysisID routes the job to the codebase region.
+type Args struct {
+	AnalysisID string `json:"analysis_id"`
+	UploadID   string `json:"upload_id" river:"unique"`
+}
+
+// Kind returns the unique identifier for this job type.
+func (Args) Kind() string { return jobKind }
+
+// InsertOpts returns the River insert options for this job type.
+func (Args) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       analyze.QueueScheduled,
+		MaxAttempts: maxRetryAttempts,
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+// Worker processes test result ingestion jobs.
+type Worker struct {
+	river.WorkerDefaults[Args]
+	usecase *uc.IngestTestResultsUseCase
+}
+
+// NewWorker creates a new test result ingestion worker.
+func NewWorker(usecase *uc.IngestTestResultsUseCase) *Worker {
+	return &Worker{usecase: usecase}
+}
+
+// Timeout returns the maximum duration for this job.
+func (w *Worker) Timeout(job *river.Job[Args]) time.Duration {
+	return jobTimeout
+}
+
+// Work ingests an uploaded CI test report.
+func (w *Worker) Work(ctx context.Context, job *river.Job[Args]) error {
+	args := job.Args
+
+	summary, err := w.usecase.Execute(ctx, uc.IngestRequest{
+		AnalysisID: args.AnalysisID,
+		UploadID:   args.UploadID,
+	})
+	if err != nil {
+		if isPermanentError(err) {
+			slog.WarnContext(ctx, "permanent error, cancelling job",
+				"job_id", job.ID,
+				"upload_id", args.UploadID,
+				"analysis_id", args.AnalysisID,
+				"error", err,
+			)
+			return river.JobCancel(err)
+		}
+		slog.ErrorContext(ctx, "test result ingestion failed",
+			"job_id", job.ID,
+			"upload_id", args.UploadID,
+			"analysis_id", args.AnalysisID,
+			"attempt", job.Attempt,
+			"max_attempts", maxRetryAttempts,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.InfoContext(ctx, "test result ingestion completed",
+		"job_id", job.ID,
+		"upload_id", args.UploadID,
+		"analysis_id", args.AnalysisID,
+		"already_processed", summary.AlreadyProcessed,
+		"results", summary.Results,
+		"matched", summary.Matched,
+		"unmatched", summary.Unmatched,
+		"failing", summary.Failing,
+		"flaky", summary.Flaky,
+	)
+	return nil
+}
+
+func isPermanentError(err error) bool {
+	return errors.Is(err, testrun.ErrEmptyReport) ||
+		errors.Is(err, testrun.ErrInvalidFormat) ||
+		errors.Is(err, testrun.ErrInvalidInput) ||
+		errors.Is(err, testrun.ErrUploadNotFound) ||
+		errors.Is(err, uc.ErrAnalysisMismatch)
+}
@@ -0,0 +1,89 @@
+package testrun
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"github.com/specvital/worker/internal/domain/testrun"
+	uc "github.com/specvital/worker/internal/usecase/testrun"
+)
+
+type mockRepository struct {
+	content   string
+	getErr    error
+	getTCErr  error
+	saveCalls int
+}
+
+func (m *mockRepository) GetUpload(_ context.Context, uploadID string) (*testrun.Upload, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return &testrun.Upload{AnalysisID: "an-1", Content: []byte(m.content), Format: testrun.FormatJUnit, ID: uploadID}, nil
+}
+
+func (m *mockRepository) GetTestCases(context.Context, string) ([]testrun.TestCase, error) {
+	return []testrun.TestCase{{FilePath: "a_test.go", ID: "tc-1", Name: "TestA"}}, m.getTCErr
+}
+
+func (m *mockRepository) SaveResults(context.Context, string, []testrun.CaseResult) (bool, error) {
+	m.saveCalls++
+	return true, nil
+}
+
+func TestArgs_Kind(t *testing.T) {
+	if (Args{}).Kind() != "testrun:ingest" {
+		t.Errorf("expected kind 'testrun:ingest', got '%s'", Args{}.Kind())
+	}
+}
+
+func TestWorker_Work(t *testing.T) {
+	const report = `<testsuite><testcase name="TestA" file="a_test.go"/></testsuite>`
+
+	tests := []struct {
+		name       string
+		repo       *mockRepository
+		analysisID string
+		wantErr    bool
+		wantCancel bool
+	}{
+		{name: "success", repo: &mockRepository{content: report}, analysisID: "an-1"},
+		{name: "missing upload is cancelled", repo: &mockRepository{getErr: testrun.ErrUploadNotFound}, wantErr: true, wantCancel: true},
+		{name: "malformed report is cancelled", repo: &mockRepository{content: "<testsuite"}, wantErr: true, wantCancel: true},
+		{name: "analysis mismatch is cancelled", repo: &mockRepository{content: report}, analysisID: "an-2", wantErr: true, wantCancel: true},
+		{name: "database failure is retried", repo: &mockRepository{content: report, getTCErr: errors.New("connection reset")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := NewWorker(uc.NewIngestTestResultsUseCase(tt.repo))
+			job := &river.Job[Args]{
+				JobRow: &rivertype.JobRow{ID: 1, Attempt: 1},
+				Args:   Args{AnalysisID: tt.analysisID, UploadID: "up-1"},
+			}
+
+			err := worker.Work(context.Background(), job)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				if tt.repo.saveCalls != 1 {
+					t.Errorf("expected results saved once, got %d", tt.repo.saveCalls)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			var cancelErr *rivertype.JobCancelError
+			if isCancelled := errors.As(err, &cancelErr); isCancelled != tt.wantCancel {
+				t.Errorf("expected cancel=%v, got %T: %v", tt.wantCancel, err, err)
+			}
+		})
+	}
+}
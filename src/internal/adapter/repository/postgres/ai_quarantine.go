@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ specview.OutputQuarantine = (*AIOutputQuarantineRepository)(nil)
+
+// AIOutputQuarantineRepository stores AI responses that failed to parse.
+type AIOutputQuarantineRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAIOutputQuarantineRepository creates a new AIOutputQuarantineRepository.
+func NewAIOutputQuarantineRepository(pool *pgxpool.Pool) *AIOutputQuarantineRepository {
+	return &AIOutputQuarantineRepository{pool: pool}
+}
+
+func (r *AIOutputQuarantineRepository) QuarantineOutput(ctx context.Context, output specview.QuarantinedOutput) error {
+	queries := db.New(r.pool)
+	err := queries.InsertAIOutputQuarantine(ctx, db.InsertAIOutputQuarantineParams{
+		Error:         output.Error,
+		ModelID:       output.ModelID,
+		Phase:         output.Phase,
+		Response:      output.Response,
+		SchemaVersion: output.SchemaVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("insert AI output quarantine: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestAIOutputQuarantineRepository_QuarantineOutput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewAIOutputQuarantineRepository(pool)
+	ctx := context.Background()
+
+	err := repo.QuarantineOutput(ctx, specview.QuarantinedOutput{
+		Error:         "json unmarshal: cannot unmarshal object",
+		ModelID:       "gemini-2.5-flash",
+		Phase:         "phase1",
+		Response:      `{"domains": {"name": "Auth"}}`,
+		SchemaVersion: "phase1.v1",
+	})
+	if err != nil {
+		t.Fatalf("QuarantineOutput failed: %v", err)
+	}
+
+	var phase, schemaVersion, response string
+	err = pool.QueryRow(ctx, `SELECT phase, schema_version, response FROM ai_output_quarantine`).Scan(&phase, &schemaVersion, &response)
+	if err != nil {
+		t.Fatalf("query quarantine: %v", err)
+	}
+	if phase != "phase1" || schemaVersion != "phase1.v1" || response != `{"domains": {"name": "Auth"}}` {
+		t.Errorf("unexpected row: phase=%q schema_version=%q response=%q", phase, schemaVersion, response)
+	}
+}
@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var (
+	_ specview.AIUsageRecorder = (*AIUsageRepository)(nil)
+	_ specview.AIUsageRecorder = (*SpecDocumentRepository)(nil)
+	_ specview.AIUsageReporter = (*AIUsageRepository)(nil)
+)
+
+// AIUsageRepository stores per-phase token usage in ai_usage and aggregates
+// it for billing and capacity planning.
+type AIUsageRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAIUsageRepository creates a new AIUsageRepository.
+func NewAIUsageRepository(pool *pgxpool.Pool) *AIUsageRepository {
+	return &AIUsageRepository{pool: pool}
+}
+
+// RecordAIUsage stores the usages in one transaction. IDs that are not UUIDs
+// are stored as NULL, like the user of a fan-out child.
+func (r *AIUsageRepository) RecordAIUsage(ctx context.Context, usages []specview.AIUsage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "RecordAIUsage",
+				"error", rbErr,
+			)
+		}
+	}()
+
+	queries := db.New(tx)
+	for _, usage := range usages {
+		if usage.TotalTokens < 0 || usage.PromptTokens < 0 || usage.CandidatesTokens < 0 {
+			return fmt.Errorf("%w: token count must not be negative", specview.ErrInvalidInput)
+		}
+		jobID := pgtype.Int8{Int64: usage.JobID, Valid: usage.JobID != 0}
+		userID, documentID := optionalPgUUID(usage.UserID), optionalPgUUID(usage.DocumentID)
+
+		// Attribute the job's fan-out children before inserting, so the
+		// inserted usage is not mistaken for a child's.
+		if jobID.Valid && userID.Valid && documentID.Valid {
+			if _, err := queries.AttributeAIUsage(ctx, db.AttributeAIUsageParams{
+				DocumentID: documentID,
+				JobID:      jobID,
+				UserID:     userID,
+			}); err != nil {
+				return fmt.Errorf("attribute AI usage: %w", err)
+			}
+		}
+
+		if err := queries.InsertAIUsage(ctx, db.InsertAIUsageParams{
+			AnalysisID:       optionalPgUUID(usage.AnalysisID),
+			CandidatesTokens: usage.CandidatesTokens,
+			DocumentID:       documentID,
+			FallbackFrom:     pgtype.Text{String: usage.FallbackFrom, Valid: usage.FallbackFrom != ""},
+			JobID:            jobID,
+			Model:            usage.Model,
+			Phase:            usage.Phase,
+			PromptTokens:     usage.PromptTokens,
+			TotalTokens:      usage.TotalTokens,
+			UserID:           userID,
+		}); err != nil {
+			return fmt.Errorf("insert AI usage: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *AIUsageRepository) DailyAIUsage(ctx context.Context, from, to time.Time) ([]specview.AIUsageTotals, error) {
+	rows, err := db.New(r.pool).GetDailyAIUsage(ctx, db.GetDailyAIUsageParams{
+		Since: pgtype.Timestamptz{Time: from, Valid: true},
+		Until: pgtype.Timestamptz{Time: to, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get daily AI usage: %w", err)
+	}
+
+	totals := make([]specview.AIUsageTotals, 0, len(rows))
+	for _, row := range rows {
+		totals = append(totals, specview.AIUsageTotals{
+			CandidatesTokens: row.CandidatesTokens,
+			Day:              row.Day.Time.UTC(),
+			Jobs:             row.Jobs,
+			Model:            row.Model,
+			Phase:            row.Phase,
+			PromptTokens:     row.PromptTokens,
+			TotalTokens:      row.TotalTokens,
+		})
+	}
+	return totals, nil
+}
+
+func (r *AIUsageRepository) AIUsageByUser(ctx context.Context, from, to time.Time, limit int) ([]specview.AIUsageTotals, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: limit must be positive", specview.ErrInvalidInput)
+	}
+
+	rows, err := db.New(r.pool).GetAIUsageByUser(ctx, db.GetAIUsageByUserParams{
+		RowLimit: int32(min(limit, math.MaxInt32)),
+		Since:    pgtype.Timestamptz{Time: from, Valid: true},
+		Until:    pgtype.Timestamptz{Time: to, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get AI usage by user: %w", err)
+	}
+
+	totals := make([]specview.AIUsageTotals, 0, len(rows))
+	for _, row := range rows {
+		totals = append(totals, specview.AIUsageTotals{
+			CandidatesTokens: row.CandidatesTokens,
+			Jobs:             row.Jobs,
+			PromptTokens:     row.PromptTokens,
+			TotalTokens:      row.TotalTokens,
+			UserID:           fromPgUUID(row.UserID).String(),
+		})
+	}
+	return totals, nil
+}
+
+// RecordAIUsage makes the generation use case persist its token usage.
+func (r *SpecDocumentRepository) RecordAIUsage(ctx context.Context, usages []specview.AIUsage) error {
+	return NewAIUsageRepository(r.pool).RecordAIUsage(ctx, usages)
+}
+
+// optionalPgUUID converts id, or returns NULL when it is not a UUID.
+func optionalPgUUID(id string) pgtype.UUID {
+	parsed, err := analysis.ParseUUID(id)
+	if err != nil {
+		return pgtype.UUID{}
+	}
+	return toPgUUID(parsed)
+}
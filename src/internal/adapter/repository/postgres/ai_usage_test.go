@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestAIUsageRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewAIUsageRepository(pool)
+	ctx := context.Background()
+
+	userID := setupTestUser(t, ctx, pool)
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, NewAnalysisRepository(pool), pool)
+
+	var documentID string
+	if err := pool.QueryRow(ctx, `
+		INSERT INTO spec_documents (user_id, analysis_id, content_hash, language, model_id)
+		VALUES ($1, $2, 'usage', 'English', 'gemini-2.5-flash')
+		RETURNING id::text
+	`, userID, analysisID.String()).Scan(&documentID); err != nil {
+		t.Fatalf("insert document: %v", err)
+	}
+
+	// A fan-out child records first, without a user or document.
+	if err := repo.RecordAIUsage(ctx, []specview.AIUsage{{
+		AnalysisID: analysisID.String(),
+		JobID:      7,
+		Phase:      "phase2",
+		TokenUsage: specview.TokenUsage{Model: "gemini-2.5-flash", PromptTokens: 80, CandidatesTokens: 20, TotalTokens: 100},
+	}}); err != nil {
+		t.Fatalf("RecordAIUsage (child) failed: %v", err)
+	}
+	if err := repo.RecordAIUsage(ctx, []specview.AIUsage{
+		{
+			AnalysisID: analysisID.String(),
+			DocumentID: documentID,
+			JobID:      7,
+			Phase:      "phase1",
+			TokenUsage: specview.TokenUsage{Model: "gemini-2.5-pro", PromptTokens: 30, CandidatesTokens: 10, TotalTokens: 40},
+			UserID:     userID,
+		},
+		{
+			AnalysisID: analysisID.String(),
+			DocumentID: documentID,
+			JobID:      7,
+			Phase:      "phase2",
+			TokenUsage: specview.TokenUsage{FallbackFrom: "gemini-2.5-pro", Model: "gemini-2.5-flash", TotalTokens: 5},
+			UserID:     userID,
+		},
+	}); err != nil {
+		t.Fatalf("RecordAIUsage (parent) failed: %v", err)
+	}
+
+	from := time.Now().UTC().Add(-time.Hour)
+	to := time.Now().UTC().Add(time.Hour)
+
+	t.Run("attributes the children to the parent's user and document", func(t *testing.T) {
+		var unattributed int
+		if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM ai_usage WHERE user_id IS NULL OR document_id IS NULL`).Scan(&unattributed); err != nil {
+			t.Fatalf("count usage: %v", err)
+		}
+		if unattributed != 0 {
+			t.Errorf("expected every usage attributed, got %d without a user or document", unattributed)
+		}
+	})
+
+	t.Run("sums daily usage by model and phase", func(t *testing.T) {
+		totals, err := repo.DailyAIUsage(ctx, from, to)
+		if err != nil {
+			t.Fatalf("DailyAIUsage failed: %v", err)
+		}
+		if len(totals) != 2 {
+			t.Fatalf("expected 2 groups, got %+v", totals)
+		}
+		flash := totals[0]
+		if flash.Model != "gemini-2.5-flash" || flash.Phase != "phase2" || flash.TotalTokens != 105 ||
+			flash.PromptTokens != 80 || flash.Jobs != 1 {
+			t.Errorf("unexpected flash totals %+v", flash)
+		}
+		if pro := totals[1]; pro.Model != "gemini-2.5-pro" || pro.TotalTokens != 40 {
+			t.Errorf("unexpected pro totals %+v", pro)
+		}
+		if flash.Day.After(time.Now()) {
+			t.Errorf("day %v should be today", flash.Day)
+		}
+	})
+
+	t.Run("sums usage by user", func(t *testing.T) {
+		totals, err := repo.AIUsageByUser(ctx, from, to, 10)
+		if err != nil {
+			t.Fatalf("AIUsageByUser failed: %v", err)
+		}
+		if len(totals) != 1 || totals[0].UserID != userID || totals[0].TotalTokens != 145 || totals[0].Jobs != 1 {
+			t.Errorf("unexpected user totals %+v", totals)
+		}
+	})
+
+	t.Run("excludes usage outside the range", func(t *testing.T) {
+		totals, err := repo.DailyAIUsage(ctx, to, to.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("DailyAIUsage failed: %v", err)
+		}
+		if len(totals) != 0 {
+			t.Errorf("expected no usage, got %+v", totals)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"log/slog"
 	"maps"
 	"slices"
@@ -17,12 +18,17 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/specvital/core/pkg/parser"
 	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/curation"
 	"github.com/specvital/worker/internal/infra/db"
 )
 
 const defaultHost = "github.com"
 const maxErrorMessageLength = 1000
 
+// inventoryStagingBatchSize bounds the test cases staged per round of
+// SaveAnalysisInventory.
+const inventoryStagingBatchSize = 5000
+
 func truncateErrorMessage(msg string) string {
 	if len(msg) <= maxErrorMessageLength {
 		return msg
@@ -111,6 +117,13 @@ func (r *AnalysisRepository) CreateAnalysisRecord(ctx context.Context, params an
 		analysisID = *params.AnalysisID
 	}
 
+	var pathFilters []byte
+	if !params.PathFilters.IsEmpty() {
+		if pathFilters, err = json.Marshal(params.PathFilters); err != nil {
+			return analysis.NilUUID, fmt.Errorf("marshal path filters: %w", err)
+		}
+	}
+
 	dbAnalysis, err := queries.CreateAnalysis(ctx, db.CreateAnalysisParams{
 		ID:            toPgUUID(analysisID),
 		CodebaseID:    codebaseID,
@@ -119,6 +132,7 @@ func (r *AnalysisRepository) CreateAnalysisRecord(ctx context.Context, params an
 		Status:        db.AnalysisStatusRunning,
 		StartedAt:     pgtype.Timestamptz{Time: startedAt, Valid: true},
 		ParserVersion: params.ParserVersion,
+		PathFilters:   pathFilters,
 	})
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -135,7 +149,453 @@ func (r *AnalysisRepository) CreateAnalysisRecord(ctx context.Context, params an
 	return fromPgUUID(dbAnalysis.ID), nil
 }
 
-func (r *AnalysisRepository) RecordFailure(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+// UpdateAnalysisSource points an analysis recorded before its clone at the
+// codebase, branch and commit the clone resolved.
+func (r *AnalysisRepository) UpdateAnalysisSource(ctx context.Context, params analysis.UpdateAnalysisSourceParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	var pathFilters []byte
+	if !params.PathFilters.IsEmpty() {
+		var err error
+		if pathFilters, err = json.Marshal(params.PathFilters); err != nil {
+			return fmt.Errorf("marshal path filters: %w", err)
+		}
+	}
+
+	queries := db.New(r.pool)
+	if err := queries.UpdateAnalysisSource(ctx, db.UpdateAnalysisSourceParams{
+		ID:          toPgUUID(params.AnalysisID),
+		CodebaseID:  toPgUUID(params.CodebaseID),
+		CommitSha:   params.CommitSHA,
+		BranchName:  pgtype.Text{String: params.Branch, Valid: params.Branch != ""},
+		PathFilters: pathFilters,
+	}); err != nil {
+		return fmt.Errorf("update analysis source: %w", err)
+	}
+	return nil
+}
+
+// SaveSourceFiles replaces the file inventory for an analysis.
+func (r *AnalysisRepository) SaveSourceFiles(ctx context.Context, analysisID analysis.UUID, paths []string) error {
+	if analysisID == analysis.NilUUID {
+		return fmt.Errorf("%w: analysis ID is required", analysis.ErrInvalidInput)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	pgID := toPgUUID(analysisID)
+	rows := make([][]any, 0, len(paths))
+	for _, p := range paths {
+		// Paths beyond the column limit cannot be linked reliably, so skip rather than truncate.
+		if utf8.RuneCountInString(p) > maxFilePathLength {
+			continue
+		}
+		rows = append(rows, []any{pgID, p})
+	}
+
+	if _, err := r.pool.CopyFrom(
+		ctx,
+		pgx.Identifier{"analysis_source_files"},
+		db.AnalysisSourceFileCopyColumns,
+		pgx.CopyFromRows(rows),
+	); err != nil {
+		return fmt.Errorf("copy source files: %w", err)
+	}
+	return nil
+}
+
+// SaveLanguageStats stores the language distribution of the analysis' test files.
+func (r *AnalysisRepository) SaveLanguageStats(ctx context.Context, analysisID analysis.UUID, stats []analysis.LanguageStats) error {
+	if analysisID == analysis.NilUUID {
+		return fmt.Errorf("%w: analysis ID is required", analysis.ErrInvalidInput)
+	}
+	if len(stats) == 0 {
+		return nil
+	}
+
+	pgID := toPgUUID(analysisID)
+	rows := make([][]any, 0, len(stats))
+	for _, s := range stats {
+		rows = append(rows, []any{pgID, s.Language, int32(s.Files), int32(s.Tests), int32(s.FailedFiles), int32(s.UnmatchedFiles)})
+	}
+
+	if _, err := r.pool.CopyFrom(
+		ctx,
+		pgx.Identifier{"analysis_languages"},
+		db.AnalysisLanguageCopyColumns,
+		pgx.CopyFromRows(rows),
+	); err != nil {
+		return fmt.Errorf("copy language stats: %w", err)
+	}
+	return nil
+}
+
+// SaveUnmatchedSamples stores the sampled test file candidates no parser strategy matched.
+func (r *AnalysisRepository) SaveUnmatchedSamples(ctx context.Context, analysisID analysis.UUID, samples []analysis.UnmatchedSample) error {
+	if analysisID == analysis.NilUUID {
+		return fmt.Errorf("%w: analysis ID is required", analysis.ErrInvalidInput)
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	pgID := toPgUUID(analysisID)
+	rows := make([][]any, 0, len(samples))
+	for _, s := range samples {
+		snippet := pgtype.Text{String: s.Snippet, Valid: s.Snippet != ""}
+		rows = append(rows, []any{pgID, s.FilePath, s.Language, snippet})
+	}
+
+	if _, err := r.pool.CopyFrom(
+		ctx,
+		pgx.Identifier{"unmatched_file_samples"},
+		db.UnmatchedFileSampleCopyColumns,
+		pgx.CopyFromRows(rows),
+	); err != nil {
+		return fmt.Errorf("copy unmatched samples: %w", err)
+	}
+	return nil
+}
+
+// SaveSharedHelpers stores the helpers and fixtures imported by many of the analysis' test files.
+func (r *AnalysisRepository) SaveSharedHelpers(ctx context.Context, analysisID analysis.UUID, helpers []analysis.SharedHelper) error {
+	if analysisID == analysis.NilUUID {
+		return fmt.Errorf("%w: analysis ID is required", analysis.ErrInvalidInput)
+	}
+
+	pgID := toPgUUID(analysisID)
+	rows := make([][]any, 0, len(helpers))
+	for _, h := range helpers {
+		if utf8.RuneCountInString(h.Path) > maxFilePathLength {
+			continue
+		}
+		rows = append(rows, []any{pgID, h.Path, h.TestFiles})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if _, err := r.pool.CopyFrom(
+		ctx,
+		pgx.Identifier{"analysis_shared_helpers"},
+		db.AnalysisSharedHelperCopyColumns,
+		pgx.CopyFromRows(rows),
+	); err != nil {
+		return fmt.Errorf("copy shared helpers: %w", err)
+	}
+	return nil
+}
+
+// QuarantineFiles records the files the analysis skipped after parse failures and
+// stores their count on the analysis row. Recording a file twice is a no-op.
+func (r *AnalysisRepository) QuarantineFiles(ctx context.Context, analysisID analysis.UUID, errs []analysis.ParseError) error {
+	if analysisID == analysis.NilUUID {
+		return fmt.Errorf("%w: analysis ID is required", analysis.ErrInvalidInput)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "QuarantineFiles",
+				"error", rbErr,
+				"analysis_id", analysisID,
+			)
+		}
+	}()
+
+	queries := db.New(tx)
+	pgID := toPgUUID(analysisID)
+	for _, e := range errs {
+		if utf8.RuneCountInString(e.FilePath) > maxFilePathLength {
+			continue
+		}
+		if err := queries.InsertParseError(ctx, db.InsertParseErrorParams{
+			AnalysisID: pgID,
+			FilePath:   e.FilePath,
+			Message:    truncateErrorMessage(e.Message),
+			Panicked:   e.Panicked,
+		}); err != nil {
+			return fmt.Errorf("insert parse error for %s: %w", e.FilePath, err)
+		}
+	}
+	if err := queries.UpdateAnalysisQuarantinedFiles(ctx, pgID); err != nil {
+		return fmt.Errorf("update quarantined files: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SaveCurationRules stores the parsed rules file on the analysis row.
+func (r *AnalysisRepository) SaveCurationRules(ctx context.Context, analysisID analysis.UUID, rules *curation.Rules) error {
+	if analysisID == analysis.NilUUID {
+		return fmt.Errorf("%w: analysis ID is required", analysis.ErrInvalidInput)
+	}
+	if rules == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("marshal curation rules: %w", err)
+	}
+
+	queries := db.New(r.pool)
+	if err := queries.UpdateAnalysisCurationRules(ctx, db.UpdateAnalysisCurationRulesParams{
+		ID:            toPgUUID(analysisID),
+		CurationRules: data,
+	}); err != nil {
+		return fmt.Errorf("update curation rules: %w", err)
+	}
+	return nil
+}
+
+// GetLatestPathFilters returns the path filters of the codebase's most recent
+// analysis, or nil when it had none or the codebase was never analyzed.
+func (r *AnalysisRepository) GetLatestPathFilters(ctx context.Context, codebaseID analysis.UUID) (*analysis.PathFilters, error) {
+	if codebaseID == analysis.NilUUID {
+		return nil, fmt.Errorf("%w: codebase ID is required", analysis.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+	data, err := queries.GetLatestCodebasePathFilters(ctx, toPgUUID(codebaseID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get latest path filters: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var filters analysis.PathFilters
+	if err := json.Unmarshal(data, &filters); err != nil {
+		return nil, fmt.Errorf("unmarshal path filters: %w", err)
+	}
+	return &filters, nil
+}
+
+// GetReusableFiles returns the codebase's latest completed analysis made with
+// parserVersion and the content hashes of its test files. Files saved before
+// hashing was introduced have no hash and are never reused.
+func (r *AnalysisRepository) GetReusableFiles(ctx context.Context, codebaseID analysis.UUID, parserVersion string) (analysis.UUID, analysis.FileHashes, error) {
+	if codebaseID == analysis.NilUUID {
+		return analysis.NilUUID, nil, fmt.Errorf("%w: codebase ID is required", analysis.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+	rows, err := queries.GetReusableFileHashes(ctx, db.GetReusableFileHashesParams{
+		CodebaseID:    toPgUUID(codebaseID),
+		ParserVersion: parserVersion,
+	})
+	if err != nil {
+		return analysis.NilUUID, nil, fmt.Errorf("get reusable file hashes: %w", err)
+	}
+	if len(rows) == 0 {
+		return analysis.NilUUID, nil, nil
+	}
+
+	hashes := make(analysis.FileHashes, len(rows))
+	for _, row := range rows {
+		hashes[row.FilePath] = row.ContentHash
+	}
+	return fromPgUUID(rows[0].AnalysisID), hashes, nil
+}
+
+// GetForkReusableFiles returns the latest completed analysis of another
+// codebase in the same region at commitSHA made with parserVersion and the
+// content hashes of its test files. Codebases with fork deduplication
+// disabled neither reuse nor lend files.
+func (r *AnalysisRepository) GetForkReusableFiles(ctx context.Context, codebaseID analysis.UUID, commitSHA, parserVersion string) (analysis.UUID, analysis.FileHashes, error) {
+	if codebaseID == analysis.NilUUID {
+		return analysis.NilUUID, nil, fmt.Errorf("%w: codebase ID is required", analysis.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+	rows, err := queries.GetForkReusableFileHashes(ctx, db.GetForkReusableFileHashesParams{
+		CodebaseID:    toPgUUID(codebaseID),
+		CommitSha:     commitSHA,
+		ParserVersion: parserVersion,
+	})
+	if err != nil {
+		return analysis.NilUUID, nil, fmt.Errorf("get fork reusable file hashes: %w", err)
+	}
+	if len(rows) == 0 {
+		return analysis.NilUUID, nil, nil
+	}
+
+	hashes := make(analysis.FileHashes, len(rows))
+	for _, row := range rows {
+		hashes[row.FilePath] = row.ContentHash
+	}
+	return fromPgUUID(rows[0].AnalysisID), hashes, nil
+}
+
+// SaveFingerprint stores the test file fingerprint of the analysis and links
+// it to the analysis it duplicates, if any.
+func (r *AnalysisRepository) SaveFingerprint(ctx context.Context, analysisID analysis.UUID, fingerprint []byte, duplicateOf analysis.UUID) error {
+	if analysisID == analysis.NilUUID {
+		return fmt.Errorf("%w: analysis ID is required", analysis.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+	if err := queries.UpdateAnalysisFingerprint(ctx, db.UpdateAnalysisFingerprintParams{
+		ID:               toPgUUID(analysisID),
+		TestFingerprint:  fingerprint,
+		DeduplicatedFrom: toPgUUID(duplicateOf),
+	}); err != nil {
+		return fmt.Errorf("update analysis fingerprint: %w", err)
+	}
+	return nil
+}
+
+// GetTestFiles rebuilds the test files of the analysis at paths from their
+// stored suites and tests. Saving them again writes the same rows. Paths the
+// analysis has no test file for are skipped.
+func (r *AnalysisRepository) GetTestFiles(ctx context.Context, analysisID analysis.UUID, paths []string) ([]analysis.TestFile, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	queries := db.New(r.pool)
+	fileRows, err := queries.GetTestFilesByPaths(ctx, db.GetTestFilesByPathsParams{
+		AnalysisID: toPgUUID(analysisID),
+		Paths:      paths,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get test files: %w", err)
+	}
+
+	files := make([]analysis.TestFile, len(fileRows))
+	fileIDs := make([]pgtype.UUID, len(fileRows))
+	fileIndex := make(map[pgtype.UUID]int, len(fileRows))
+	for i, row := range fileRows {
+		var hints *analysis.DomainHints
+		if len(row.DomainHints) > 0 {
+			hints = &analysis.DomainHints{}
+			if err := json.Unmarshal(row.DomainHints, hints); err != nil {
+				return nil, fmt.Errorf("unmarshal domain hints for %q: %w", row.FilePath, err)
+			}
+		}
+		files[i] = analysis.TestFile{
+			DomainHints: hints,
+			Framework:   row.Framework.String,
+			Path:        row.FilePath,
+			Project:     row.Project.String,
+		}
+		fileIDs[i] = row.ID
+		fileIndex[row.ID] = i
+	}
+	if len(files) == 0 {
+		return files, nil
+	}
+
+	suiteRows, err := queries.GetTestSuitesByFileIDs(ctx, fileIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get test suites: %w", err)
+	}
+	caseRows, err := queries.GetTestCasesByFileIDs(ctx, fileIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get test cases: %w", err)
+	}
+
+	type suiteNode struct {
+		children []pgtype.UUID
+		suite    analysis.TestSuite
+	}
+	// Rows are ordered by depth, so every parent is seen before its children.
+	nodes := make(map[pgtype.UUID]*suiteNode, len(suiteRows))
+	for _, row := range suiteRows {
+		nodes[row.ID] = &suiteNode{suite: analysis.TestSuite{
+			Location: analysis.Location{StartLine: int(row.LineNumber.Int32)},
+			Name:     row.Name,
+		}}
+		if row.ParentID.Valid {
+			if parent, ok := nodes[row.ParentID]; ok {
+				parent.children = append(parent.children, row.ID)
+			}
+		}
+	}
+	for _, row := range caseRows {
+		node, ok := nodes[row.SuiteID]
+		if !ok {
+			continue
+		}
+		variants := 0
+		if row.VariantCount > 1 {
+			variants = int(row.VariantCount)
+		}
+		node.suite.Tests = append(node.suite.Tests, analysis.Test{
+			Location: analysis.Location{StartLine: int(row.LineNumber.Int32)},
+			Name:     row.Name,
+			Status:   analysis.TestStatus(row.Status),
+			Variants: variants,
+		})
+	}
+
+	var build func(id pgtype.UUID) analysis.TestSuite
+	build = func(id pgtype.UUID) analysis.TestSuite {
+		node := nodes[id]
+		suite := node.suite
+		for _, child := range node.children {
+			suite.Suites = append(suite.Suites, build(child))
+		}
+		return suite
+	}
+	for _, row := range suiteRows {
+		if !row.ParentID.Valid {
+			file := &files[fileIndex[row.FileID]]
+			file.Suites = append(file.Suites, build(row.ID))
+		}
+	}
+
+	return files, nil
+}
+
+// SaveProgressEvent stores a progress event and publishes it on the analysis_progress channel.
+func (r *AnalysisRepository) SaveProgressEvent(ctx context.Context, event analysis.ProgressEvent) error {
+	if event.Owner == "" || event.Repo == "" || event.Stage == "" {
+		return fmt.Errorf("%w: owner, repo and stage are required", analysis.ErrInvalidInput)
+	}
+
+	params := db.InsertAnalysisEventParams{
+		JobID:          pgtype.Int8{Int64: event.JobID, Valid: event.JobID != 0},
+		Owner:          event.Owner,
+		Repo:           event.Repo,
+		CommitSha:      event.CommitSHA,
+		Stage:          string(event.Stage),
+		FilesProcessed: int32(event.FilesProcessed),
+		FilesTotal:     int32(event.FilesTotal),
+		CreatedAt:      pgtype.Timestamptz{Time: event.OccurredAt, Valid: !event.OccurredAt.IsZero()},
+	}
+	if event.AnalysisID != nil {
+		params.AnalysisID = toPgUUID(*event.AnalysisID)
+	}
+	if !params.CreatedAt.Valid {
+		params.CreatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	}
+
+	queries := db.New(r.pool)
+	if err := queries.InsertAnalysisEvent(ctx, params); err != nil {
+		return fmt.Errorf("insert analysis event: %w", err)
+	}
+	return nil
+}
+
+func (r *AnalysisRepository) RecordFailure(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 	if analysisID == analysis.NilUUID {
 		return fmt.Errorf("%w: analysis ID is required", analysis.ErrInvalidInput)
 	}
@@ -165,6 +625,7 @@ func (r *AnalysisRepository) RecordFailure(ctx context.Context, analysisID analy
 		ID:           pgID,
 		ErrorMessage: pgtype.Text{String: truncatedMsg, Valid: true},
 		CompletedAt:  pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		FailureClass: db.NullAnalysisFailureClass{AnalysisFailureClass: db.AnalysisFailureClass(class), Valid: class != ""},
 	}); err != nil {
 		return fmt.Errorf("update analysis failed: %w", err)
 	}
@@ -176,11 +637,48 @@ func (r *AnalysisRepository) RecordFailure(ctx context.Context, analysisID analy
 	return nil
 }
 
+// SaveAnalysisInventory stages the inventory in bounded rounds of at most
+// inventoryStagingBatchSize test cases, then promotes it and completes the
+// analysis in one short transaction, so readers see all of it or none of it.
 func (r *AnalysisRepository) SaveAnalysisInventory(ctx context.Context, params analysis.SaveAnalysisInventoryParams) error {
 	if err := params.Validate(); err != nil {
 		return err
 	}
 
+	queries := db.New(r.pool)
+	pgID := toPgUUID(params.AnalysisID)
+
+	// Rows left by an interrupted attempt would otherwise be promoted twice.
+	if err := queries.DeleteStagedInventory(ctx, pgID); err != nil {
+		return fmt.Errorf("clear staged inventory: %w", err)
+	}
+
+	err := r.stageAndPromoteInventory(ctx, pgID, params)
+	if err != nil {
+		if delErr := queries.DeleteStagedInventory(context.WithoutCancel(ctx), pgID); delErr != nil {
+			slog.WarnContext(ctx, "failed to discard staged inventory (non-critical)",
+				"analysis_id", params.AnalysisID,
+				"error", delErr,
+			)
+		}
+	}
+	return err
+}
+
+func (r *AnalysisRepository) stageAndPromoteInventory(ctx context.Context, pgID pgtype.UUID, params analysis.SaveAnalysisInventoryParams) error {
+	var totalSuites, totalTests int
+	for chunk := range inventoryChunks(params.Inventory, inventoryStagingBatchSize) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		suites, tests, err := r.stageInventoryChunk(ctx, pgID, chunk)
+		if err != nil {
+			return fmt.Errorf("stage inventory: %w", err)
+		}
+		totalSuites += suites
+		totalTests += tests
+	}
+
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("begin transaction: %w", err)
@@ -196,11 +694,15 @@ func (r *AnalysisRepository) SaveAnalysisInventory(ctx context.Context, params a
 	}()
 
 	queries := db.New(tx)
-	pgID := toPgUUID(params.AnalysisID)
 
-	_, totalSuites, totalTests, err := r.saveInventory(ctx, tx, pgID, params.Inventory)
-	if err != nil {
-		return fmt.Errorf("save inventory: %w", err)
+	if err := queries.PromoteStagedTestFiles(ctx, pgID); err != nil {
+		return fmt.Errorf("promote test files: %w", err)
+	}
+	if err := queries.PromoteStagedTestSuites(ctx, pgID); err != nil {
+		return fmt.Errorf("promote test suites: %w", err)
+	}
+	if err := queries.PromoteStagedTestCases(ctx, pgID); err != nil {
+		return fmt.Errorf("promote test cases: %w", err)
 	}
 
 	if err := queries.UpdateAnalysisCompleted(ctx, db.UpdateAnalysisCompletedParams{
@@ -221,6 +723,14 @@ func (r *AnalysisRepository) SaveAnalysisInventory(ctx context.Context, params a
 		return err
 	}
 
+	if err := insertAnalysisCompletedEvent(ctx, queries, pgID); err != nil {
+		return err
+	}
+
+	if err := queries.DeleteStagedInventory(ctx, pgID); err != nil {
+		return fmt.Errorf("delete staged inventory: %w", err)
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -228,6 +738,147 @@ func (r *AnalysisRepository) SaveAnalysisInventory(ctx context.Context, params a
 	return nil
 }
 
+// inventoryChunks yields the files of an inventory in runs holding about
+// maxTests test cases each; a single file larger than that is a run of its own.
+func inventoryChunks(inventory *analysis.Inventory, maxTests int) iter.Seq[[]analysis.TestFile] {
+	return func(yield func([]analysis.TestFile) bool) {
+		if inventory == nil {
+			return
+		}
+		start, tests := 0, 0
+		for i, file := range inventory.Files {
+			n := countFileTests(file)
+			if i > start && tests+n > maxTests {
+				if !yield(inventory.Files[start:i]) {
+					return
+				}
+				start, tests = i, 0
+			}
+			tests += n
+		}
+		if start < len(inventory.Files) {
+			yield(inventory.Files[start:])
+		}
+	}
+}
+
+func countFileTests(file analysis.TestFile) int {
+	n := len(file.Tests)
+	var countSuite func(analysis.TestSuite)
+	countSuite = func(suite analysis.TestSuite) {
+		n += len(suite.Tests)
+		for _, nested := range suite.Suites {
+			countSuite(nested)
+		}
+	}
+	for _, suite := range file.Suites {
+		countSuite(suite)
+	}
+	return n
+}
+
+// stageInventoryChunk copies one run of files with their suites and test
+// cases into the staging tables. IDs are generated here, so a whole run goes
+// in with three COPYs instead of a round trip per row.
+func (r *AnalysisRepository) stageInventoryChunk(
+	ctx context.Context,
+	analysisID pgtype.UUID,
+	files []analysis.TestFile,
+) (totalSuites, totalTests int, err error) {
+	fileIDs := make(map[string]pgtype.UUID, len(files))
+	fileRows := make([][]any, len(files))
+	for i, file := range files {
+		hints, err := marshalDomainHints(file)
+		if err != nil {
+			return 0, 0, err
+		}
+		id := toPgUUID(analysis.NewUUID())
+		fileIDs[file.Path] = id
+		fileRows[i] = []any{
+			id,
+			analysisID,
+			file.Path,
+			pgtype.Text{String: file.Framework, Valid: file.Framework != ""},
+			hints,
+			pgtype.Text{String: file.Project, Valid: file.Project != ""},
+			file.ContentHash,
+		}
+	}
+
+	suites, tests := flattenInventory(&analysis.Inventory{Files: files}, fileIDs)
+
+	suiteIDs := make(map[int]pgtype.UUID, len(suites))
+	for _, s := range suites {
+		suiteIDs[s.tempID] = toPgUUID(analysis.NewUUID())
+	}
+	suiteRows := make([][]any, len(suites))
+	for i, s := range suites {
+		parentID := pgtype.UUID{}
+		if s.parentTemp >= 0 {
+			parentID = suiteIDs[s.parentTemp]
+		}
+		suiteRows[i] = []any{
+			suiteIDs[s.tempID],
+			analysisID,
+			s.fileID,
+			parentID,
+			truncateString(s.suite.Name, maxTestSuiteNameLength),
+			pgtype.Int4{Int32: int32(s.suite.Location.StartLine), Valid: true},
+			int32(s.depth),
+		}
+	}
+
+	testRows := make([][]any, len(tests))
+	for i, t := range tests {
+		testRows[i] = []any{
+			analysisID,
+			suiteIDs[t.suiteTempID],
+			truncateString(t.test.Name, maxTestCaseNameLength),
+			pgtype.Int4{Int32: int32(t.test.Location.StartLine), Valid: true},
+			mapTestStatus(t.test.Status),
+			[]byte("[]"),
+			pgtype.Text{},
+			int32(t.test.VariantCount()),
+		}
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "stageInventoryChunk",
+				"error", rbErr,
+			)
+		}
+	}()
+
+	for _, table := range []struct {
+		name    string
+		columns []string
+		rows    [][]any
+	}{
+		{"analysis_staging_test_files", db.StagedTestFileCopyColumns, fileRows},
+		{"analysis_staging_test_suites", db.StagedTestSuiteCopyColumns, suiteRows},
+		{"analysis_staging_test_cases", db.StagedTestCaseCopyColumns, testRows},
+	} {
+		if len(table.rows) == 0 {
+			continue
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{table.name}, table.columns, pgx.CopyFromRows(table.rows)); err != nil {
+			return 0, 0, fmt.Errorf("copy %s: %w", table.name, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return len(suites), len(tests), nil
+}
+
 // recordUserHistory records user analysis history and usage event.
 // Skips recording if userID is nil or invalid format.
 func (r *AnalysisRepository) recordUserHistory(
@@ -344,6 +995,10 @@ func (r *AnalysisRepository) SaveAnalysisResult(ctx context.Context, params Save
 		return fmt.Errorf("update analysis: %w", err)
 	}
 
+	if err := insertAnalysisCompletedEvent(ctx, queries, pgID); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -352,6 +1007,7 @@ func (r *AnalysisRepository) SaveAnalysisResult(ctx context.Context, params Save
 }
 
 const (
+	maxFilePathLength      = 1000
 	maxTestCaseNameLength  = 2000
 	maxTestSuiteNameLength = 500
 )
@@ -544,6 +1200,7 @@ func (r *AnalysisRepository) saveTestsCopyFrom(
 			mapTestStatus(t.test.Status),
 			[]byte("[]"),
 			pgtype.Text{},
+			int32(t.test.VariantCount()),
 		}
 	}
 
@@ -572,29 +1229,29 @@ func (r *AnalysisRepository) saveFilesBatch(
 	type fileData struct {
 		path      string
 		framework pgtype.Text
+		hash      []byte
 		hints     []byte
+		project   pgtype.Text
 	}
 	prepared := make([]fileData, len(files))
 
 	for i, file := range files {
-		var hintsJSON []byte
-		if file.DomainHints != nil {
-			var err error
-			hintsJSON, err = json.Marshal(file.DomainHints)
-			if err != nil {
-				return nil, fmt.Errorf("marshal domain hints for %q: %w", file.Path, err)
-			}
+		hintsJSON, err := marshalDomainHints(file)
+		if err != nil {
+			return nil, err
 		}
 		prepared[i] = fileData{
 			path:      file.Path,
 			framework: pgtype.Text{String: file.Framework, Valid: file.Framework != ""},
+			hash:      file.ContentHash,
 			hints:     hintsJSON,
+			project:   pgtype.Text{String: file.Project, Valid: file.Project != ""},
 		}
 	}
 
 	batch := &pgx.Batch{}
 	for _, fd := range prepared {
-		batch.Queue(db.InsertTestFileBatch, analysisID, fd.path, fd.framework, fd.hints)
+		batch.Queue(db.InsertTestFileBatch, analysisID, fd.path, fd.framework, fd.hints, fd.project, fd.hash)
 	}
 
 	results := tx.SendBatch(ctx, batch)
@@ -612,6 +1269,17 @@ func (r *AnalysisRepository) saveFilesBatch(
 	return fileIDs, nil
 }
 
+func marshalDomainHints(file analysis.TestFile) ([]byte, error) {
+	if file.DomainHints == nil {
+		return nil, nil
+	}
+	hints, err := json.Marshal(file.DomainHints)
+	if err != nil {
+		return nil, fmt.Errorf("marshal domain hints for %q: %w", file.Path, err)
+	}
+	return hints, nil
+}
+
 func (r *AnalysisRepository) saveInventory(
 	ctx context.Context,
 	tx pgx.Tx,
@@ -740,6 +1408,10 @@ func (r *AnalysisRepository) FinalizeAnalysis(ctx context.Context, params analys
 		return err
 	}
 
+	if err := insertAnalysisCompletedEvent(ctx, queries, pgID); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ analysis.StatusRepository = (*AnalysisStatusRepository)(nil)
+
+// AnalysisStatusRepository reads the state of analyses by commit.
+type AnalysisStatusRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAnalysisStatusRepository creates a new AnalysisStatusRepository.
+func NewAnalysisStatusRepository(pool *pgxpool.Pool) *AnalysisStatusRepository {
+	return &AnalysisStatusRepository{pool: pool}
+}
+
+func (r *AnalysisStatusRepository) GetLatestStatus(ctx context.Context, owner, repo, commitSHA string) (*analysis.StatusReport, error) {
+	row, err := db.New(r.pool).GetLatestAnalysisStatusByCommit(ctx, db.GetLatestAnalysisStatusByCommitParams{
+		Host:      defaultHost,
+		Owner:     owner,
+		Name:      repo,
+		CommitSha: commitSHA,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get latest analysis status: %w", err)
+	}
+
+	report := &analysis.StatusReport{
+		AnalysisID:   fromPgUUID(row.ID),
+		CompletedAt:  optionalTime(row.CompletedAt),
+		CreatedAt:    row.CreatedAt.Time,
+		ErrorMessage: row.ErrorMessage.String,
+		Status:       string(row.Status),
+	}
+	if row.FailureClass.Valid {
+		report.FailureClass = analysis.FailureClass(row.FailureClass.AnalysisFailureClass)
+	}
+	return report, nil
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +15,7 @@ import (
 	"github.com/specvital/core/pkg/domain"
 	"github.com/specvital/core/pkg/parser"
 	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/infra/db"
 	testdb "github.com/specvital/worker/internal/testutil/postgres"
 )
 
@@ -344,14 +349,14 @@ func TestAnalysisRepository_RecordFailure(t *testing.T) {
 		}
 
 		errMessage := "scan failed: parser error"
-		err = repo.RecordFailure(ctx, analysisID, errMessage)
+		err = repo.RecordFailure(ctx, analysisID, analysis.FailureCloneTimeout, errMessage)
 		if err != nil {
 			t.Fatalf("RecordFailure failed: %v", err)
 		}
 
-		var status, savedErrMsg string
+		var status, savedErrMsg, failureClass string
 		pgID := toPgUUID(analysisID)
-		err = pool.QueryRow(ctx, "SELECT status, error_message FROM analyses WHERE id = $1", pgID).Scan(&status, &savedErrMsg)
+		err = pool.QueryRow(ctx, "SELECT status, error_message, failure_class FROM analyses WHERE id = $1", pgID).Scan(&status, &savedErrMsg, &failureClass)
 		if err != nil {
 			t.Fatalf("failed to query analysis: %v", err)
 		}
@@ -362,10 +367,13 @@ func TestAnalysisRepository_RecordFailure(t *testing.T) {
 		if savedErrMsg != errMessage {
 			t.Errorf("expected error message '%s', got '%s'", errMessage, savedErrMsg)
 		}
+		if failureClass != string(analysis.FailureCloneTimeout) {
+			t.Errorf("expected failure class '%s', got '%s'", analysis.FailureCloneTimeout, failureClass)
+		}
 	})
 
 	t.Run("should fail with invalid analysis ID", func(t *testing.T) {
-		err := repo.RecordFailure(ctx, analysis.NilUUID, "some error")
+		err := repo.RecordFailure(ctx, analysis.NilUUID, analysis.FailureUnknown, "some error")
 		if !errors.Is(err, analysis.ErrInvalidInput) {
 			t.Errorf("expected ErrInvalidInput, got %v", err)
 		}
@@ -380,7 +388,64 @@ func TestAnalysisRepository_RecordFailure(t *testing.T) {
 			ExternalRepoID: "empty-err-id",
 			ParserVersion:  testParserVersion,
 		})
-		err := repo.RecordFailure(ctx, analysisID, "")
+		err := repo.RecordFailure(ctx, analysisID, analysis.FailureUnknown, "")
+		if !errors.Is(err, analysis.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestAnalysisRepository_SaveProgressEvent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewAnalysisRepository(pool)
+	ctx := context.Background()
+
+	t.Run("should save event and notify listeners", func(t *testing.T) {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			t.Fatalf("acquire connection: %v", err)
+		}
+		defer conn.Release()
+		if _, err := conn.Exec(ctx, "LISTEN analysis_progress"); err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+
+		err = repo.SaveProgressEvent(ctx, analysis.ProgressEvent{
+			CommitSHA: "abc123",
+			JobID:     42,
+			Owner:     "progress-owner",
+			Repo:      "progress-repo",
+			Stage:     analysis.StageCloneStarted,
+		})
+		if err != nil {
+			t.Fatalf("SaveProgressEvent failed: %v", err)
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		notification, err := conn.Conn().WaitForNotification(waitCtx)
+		if err != nil {
+			t.Fatalf("WaitForNotification failed: %v", err)
+		}
+		if !strings.Contains(notification.Payload, `"stage":"clone_started"`) {
+			t.Errorf("unexpected payload: %s", notification.Payload)
+		}
+
+		var count int
+		pool.QueryRow(ctx, "SELECT COUNT(*) FROM analysis_events WHERE job_id = 42 AND analysis_id IS NULL").Scan(&count)
+		if count != 1 {
+			t.Errorf("expected 1 event row, got %d", count)
+		}
+	})
+
+	t.Run("should fail without stage", func(t *testing.T) {
+		err := repo.SaveProgressEvent(ctx, analysis.ProgressEvent{Owner: "o", Repo: "r"})
 		if !errors.Is(err, analysis.ErrInvalidInput) {
 			t.Errorf("expected ErrInvalidInput, got %v", err)
 		}
@@ -426,6 +491,101 @@ func TestAnalysisRepository_CreateAnalysisRecord(t *testing.T) {
 			t.Errorf("expected status 'running', got '%s'", status)
 		}
 	})
+
+	t.Run("should return path filters of the latest analysis", func(t *testing.T) {
+		filters := &analysis.PathFilters{Exclude: []string{"**/vendor/**"}, Include: []string{"services/payments/**"}}
+		analysisID, err := repo.CreateAnalysisRecord(ctx, analysis.CreateAnalysisRecordParams{
+			Owner:          "filters-owner",
+			Repo:           "filters-repo",
+			CommitSHA:      "aaa111",
+			Branch:         "main",
+			ExternalRepoID: "filters-id",
+			ParserVersion:  testParserVersion,
+			PathFilters:    filters,
+		})
+		if err != nil {
+			t.Fatalf("CreateAnalysisRecord failed: %v", err)
+		}
+
+		var codebaseID pgtype.UUID
+		if err := pool.QueryRow(ctx, "SELECT codebase_id FROM analyses WHERE id = $1", toPgUUID(analysisID)).Scan(&codebaseID); err != nil {
+			t.Fatalf("failed to query analysis: %v", err)
+		}
+
+		got, err := repo.GetLatestPathFilters(ctx, fromPgUUID(codebaseID))
+		if err != nil {
+			t.Fatalf("GetLatestPathFilters failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, filters) {
+			t.Errorf("GetLatestPathFilters() = %+v, want %+v", got, filters)
+		}
+
+		if got, err := repo.GetLatestPathFilters(ctx, analysis.NewUUID()); err != nil || got != nil {
+			t.Errorf("unknown codebase: got %+v, %v, want nil", got, err)
+		}
+	})
+}
+
+func TestAnalysisRepository_UpdateAnalysisSource(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewAnalysisRepository(pool)
+	ctx := context.Background()
+
+	analysisID, err := repo.CreateAnalysisRecord(ctx, analysis.CreateAnalysisRecordParams{
+		Owner:          "source-owner",
+		Repo:           "source-repo",
+		CommitSHA:      "requested",
+		ExternalRepoID: "source-id",
+		ParserVersion:  testParserVersion,
+	})
+	if err != nil {
+		t.Fatalf("CreateAnalysisRecord failed: %v", err)
+	}
+
+	var codebaseID pgtype.UUID
+	if err := pool.QueryRow(ctx, "SELECT codebase_id FROM analyses WHERE id = $1", toPgUUID(analysisID)).Scan(&codebaseID); err != nil {
+		t.Fatalf("failed to query analysis: %v", err)
+	}
+
+	filters := &analysis.PathFilters{Include: []string{"services/**"}}
+	err = repo.UpdateAnalysisSource(ctx, analysis.UpdateAnalysisSourceParams{
+		AnalysisID:  analysisID,
+		Branch:      "main",
+		CodebaseID:  fromPgUUID(codebaseID),
+		CommitSHA:   "cloned",
+		PathFilters: filters,
+	})
+	if err != nil {
+		t.Fatalf("UpdateAnalysisSource failed: %v", err)
+	}
+
+	var commitSHA, branch, status string
+	err = pool.QueryRow(ctx, "SELECT commit_sha, branch_name, status FROM analyses WHERE id = $1", toPgUUID(analysisID)).Scan(&commitSHA, &branch, &status)
+	if err != nil {
+		t.Fatalf("failed to query analysis: %v", err)
+	}
+	if commitSHA != "cloned" || branch != "main" || status != "running" {
+		t.Errorf("got commit %q, branch %q, status %q, want cloned, main, running", commitSHA, branch, status)
+	}
+
+	got, err := repo.GetLatestPathFilters(ctx, fromPgUUID(codebaseID))
+	if err != nil {
+		t.Fatalf("GetLatestPathFilters failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, filters) {
+		t.Errorf("GetLatestPathFilters() = %+v, want %+v", got, filters)
+	}
+
+	err = repo.UpdateAnalysisSource(ctx, analysis.UpdateAnalysisSourceParams{AnalysisID: analysisID, CodebaseID: fromPgUUID(codebaseID)})
+	if !errors.Is(err, analysis.ErrInvalidInput) {
+		t.Errorf("missing commit SHA: expected ErrInvalidInput, got %v", err)
+	}
 }
 
 func TestAnalysisRepository_SaveAnalysisInventory(t *testing.T) {
@@ -520,6 +680,110 @@ func TestAnalysisRepository_SaveAnalysisInventory(t *testing.T) {
 			t.Errorf("expected status 'completed', got '%s'", status)
 		}
 	})
+
+	t.Run("should stage large inventories over several rounds", func(t *testing.T) {
+		analysisID, err := repo.CreateAnalysisRecord(ctx, analysis.CreateAnalysisRecordParams{
+			Owner:          "staging-owner",
+			Repo:           "staging-repo",
+			CommitSHA:      "staging-sha",
+			Branch:         "main",
+			ExternalRepoID: "staging-id",
+			ParserVersion:  testParserVersion,
+		})
+		if err != nil {
+			t.Fatalf("CreateAnalysisRecord failed: %v", err)
+		}
+
+		const fileCount, testsPerFile = 30, 400
+		files := make([]analysis.TestFile, fileCount)
+		for i := range files {
+			tests := make([]analysis.Test, testsPerFile)
+			for j := range tests {
+				tests[j] = analysis.Test{Name: fmt.Sprintf("test %d", j), Location: analysis.Location{StartLine: j + 1}}
+			}
+			files[i] = analysis.TestFile{
+				Path:      fmt.Sprintf("pkg/file%d_test.go", i),
+				Framework: "go-test",
+				Suites: []analysis.TestSuite{{
+					Name:   "Outer",
+					Suites: []analysis.TestSuite{{Name: "Inner", Tests: tests}},
+				}},
+			}
+		}
+
+		err = repo.SaveAnalysisInventory(ctx, analysis.SaveAnalysisInventoryParams{
+			AnalysisID: analysisID,
+			Inventory:  &analysis.Inventory{Files: files},
+		})
+		if err != nil {
+			t.Fatalf("SaveAnalysisInventory failed: %v", err)
+		}
+
+		pgID := toPgUUID(analysisID)
+		var totalSuites, totalTests, storedTests, nestedSuites int
+		if err := pool.QueryRow(ctx, "SELECT total_suites, total_tests FROM analyses WHERE id = $1", pgID).
+			Scan(&totalSuites, &totalTests); err != nil {
+			t.Fatalf("failed to query analysis: %v", err)
+		}
+		if totalSuites != fileCount*2 || totalTests != fileCount*testsPerFile {
+			t.Errorf("totals = %d suites, %d tests", totalSuites, totalTests)
+		}
+
+		if err := pool.QueryRow(ctx, `
+			SELECT COUNT(*) FROM test_cases tc
+			JOIN test_suites ts ON ts.id = tc.suite_id
+			JOIN test_files tf ON tf.id = ts.file_id
+			WHERE tf.analysis_id = $1`, pgID).Scan(&storedTests); err != nil {
+			t.Fatalf("failed to count test cases: %v", err)
+		}
+		if storedTests != fileCount*testsPerFile {
+			t.Errorf("expected %d stored test cases, got %d", fileCount*testsPerFile, storedTests)
+		}
+
+		if err := pool.QueryRow(ctx, `
+			SELECT COUNT(*) FROM test_suites child
+			JOIN test_suites parent ON parent.id = child.parent_id
+			JOIN test_files tf ON tf.id = child.file_id
+			WHERE tf.analysis_id = $1 AND child.name = 'Inner' AND parent.name = 'Outer'`, pgID).Scan(&nestedSuites); err != nil {
+			t.Fatalf("failed to count nested suites: %v", err)
+		}
+		if nestedSuites != fileCount {
+			t.Errorf("expected %d nested suites linked to their parents, got %d", fileCount, nestedSuites)
+		}
+
+		var staged int
+		if err := pool.QueryRow(ctx, `
+			SELECT (SELECT COUNT(*) FROM analysis_staging_test_files)
+			     + (SELECT COUNT(*) FROM analysis_staging_test_suites)
+			     + (SELECT COUNT(*) FROM analysis_staging_test_cases)`).Scan(&staged); err != nil {
+			t.Fatalf("failed to count staged rows: %v", err)
+		}
+		if staged != 0 {
+			t.Errorf("expected the staging tables to be empty, got %d rows", staged)
+		}
+	})
+}
+
+func Test_inventoryChunks(t *testing.T) {
+	file := func(tests int) analysis.TestFile {
+		return analysis.TestFile{
+			Tests:  make([]analysis.Test, tests/2),
+			Suites: []analysis.TestSuite{{Suites: []analysis.TestSuite{{Tests: make([]analysis.Test, tests-tests/2)}}}},
+		}
+	}
+	inventory := &analysis.Inventory{Files: []analysis.TestFile{file(4), file(4), file(12), file(1), file(0)}}
+
+	var sizes []int
+	for chunk := range inventoryChunks(inventory, 10) {
+		sizes = append(sizes, len(chunk))
+	}
+	if !slices.Equal(sizes, []int{2, 1, 2}) {
+		t.Errorf("chunk sizes = %v, want [2 1 2]", sizes)
+	}
+
+	for range inventoryChunks(nil, 10) {
+		t.Error("expected no chunks for a nil inventory")
+	}
 }
 
 func Test_truncateErrorMessage(t *testing.T) {
@@ -2341,3 +2605,338 @@ func TestAnalysisRepository_FinalizeAnalysis(t *testing.T) {
 		}
 	})
 }
+
+func TestAnalysisRepository_FileReuse(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewAnalysisRepository(pool)
+	ctx := context.Background()
+
+	analysisID, err := repo.CreateAnalysisRecord(ctx, analysis.CreateAnalysisRecordParams{
+		Owner:          "reuse-owner",
+		Repo:           "reuse-repo",
+		CommitSHA:      "reuse123",
+		Branch:         "main",
+		ExternalRepoID: "reuse-id",
+		ParserVersion:  testParserVersion,
+	})
+	if err != nil {
+		t.Fatalf("CreateAnalysisRecord failed: %v", err)
+	}
+	var codebaseID pgtype.UUID
+	if err := pool.QueryRow(ctx, "SELECT codebase_id FROM analyses WHERE id = $1", toPgUUID(analysisID)).Scan(&codebaseID); err != nil {
+		t.Fatalf("failed to get codebase: %v", err)
+	}
+
+	file := analysis.TestFile{
+		ContentHash: []byte("hash-a"),
+		DomainHints: &analysis.DomainHints{Imports: []string{"auth"}},
+		Framework:   "jest",
+		Path:        "src/auth.test.ts",
+		Project:     "src",
+		Suites: []analysis.TestSuite{{
+			Location: analysis.Location{StartLine: 1},
+			Name:     "Auth",
+			Suites: []analysis.TestSuite{{
+				Location: analysis.Location{StartLine: 2},
+				Name:     "login",
+				Tests: []analysis.Test{
+					{Location: analysis.Location{StartLine: 3}, Name: "accepts a password", Status: analysis.TestStatusActive},
+					{Location: analysis.Location{StartLine: 4}, Name: "locks after %d attempts", Status: analysis.TestStatusSkipped, Variants: 3},
+				},
+			}},
+		}},
+	}
+	unhashed := analysis.TestFile{Path: "src/legacy.test.ts", Framework: "jest"}
+	if _, err := repo.SaveAnalysisBatch(ctx, analysis.SaveAnalysisBatchParams{
+		AnalysisID: analysisID,
+		Files:      []analysis.TestFile{file, unhashed},
+	}); err != nil {
+		t.Fatalf("SaveAnalysisBatch failed: %v", err)
+	}
+
+	t.Run("should only reuse completed analyses", func(t *testing.T) {
+		previousID, hashes, err := repo.GetReusableFiles(ctx, fromPgUUID(codebaseID), testParserVersion)
+		if err != nil {
+			t.Fatalf("GetReusableFiles failed: %v", err)
+		}
+		if previousID != analysis.NilUUID || hashes != nil {
+			t.Errorf("expected no reusable files before completion, got %s %v", previousID, hashes)
+		}
+	})
+
+	if err := repo.FinalizeAnalysis(ctx, analysis.FinalizeAnalysisParams{AnalysisID: analysisID, TotalSuites: 2, TotalTests: 2}); err != nil {
+		t.Fatalf("FinalizeAnalysis failed: %v", err)
+	}
+
+	t.Run("should return hashed files of the same parser version", func(t *testing.T) {
+		previousID, hashes, err := repo.GetReusableFiles(ctx, fromPgUUID(codebaseID), testParserVersion)
+		if err != nil {
+			t.Fatalf("GetReusableFiles failed: %v", err)
+		}
+		if previousID != analysisID {
+			t.Errorf("previous analysis = %s, want %s", previousID, analysisID)
+		}
+		if len(hashes) != 1 || string(hashes[file.Path]) != "hash-a" {
+			t.Errorf("hashes = %v, want only the hashed file", hashes)
+		}
+
+		previousID, _, err = repo.GetReusableFiles(ctx, fromPgUUID(codebaseID), "v9.9.9")
+		if err != nil || previousID != analysis.NilUUID {
+			t.Errorf("expected nothing to reuse with another parser version, got %s, %v", previousID, err)
+		}
+	})
+
+	t.Run("should rebuild saved test files", func(t *testing.T) {
+		files, err := repo.GetTestFiles(ctx, analysisID, []string{file.Path, "src/missing.test.ts"})
+		if err != nil {
+			t.Fatalf("GetTestFiles failed: %v", err)
+		}
+		want := file
+		want.ContentHash = nil
+		if len(files) != 1 || !reflect.DeepEqual(files[0], want) {
+			t.Errorf("GetTestFiles = %+v, want %+v", files, want)
+		}
+	})
+}
+
+func TestAnalysisRepository_ForkReuse(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewAnalysisRepository(pool)
+	ctx := context.Background()
+
+	create := func(owner, externalID string) (analysis.UUID, analysis.UUID) {
+		t.Helper()
+		analysisID, err := repo.CreateAnalysisRecord(ctx, analysis.CreateAnalysisRecordParams{
+			Owner:          owner,
+			Repo:           "fork-repo",
+			CommitSHA:      "fork123",
+			Branch:         "main",
+			ExternalRepoID: externalID,
+			ParserVersion:  testParserVersion,
+		})
+		if err != nil {
+			t.Fatalf("CreateAnalysisRecord failed: %v", err)
+		}
+		var codebaseID pgtype.UUID
+		if err := pool.QueryRow(ctx, "SELECT codebase_id FROM analyses WHERE id = $1", toPgUUID(analysisID)).Scan(&codebaseID); err != nil {
+			t.Fatalf("failed to get codebase: %v", err)
+		}
+		return analysisID, fromPgUUID(codebaseID)
+	}
+
+	upstreamID, _ := create("upstream-owner", "upstream-id")
+	if _, err := repo.SaveAnalysisBatch(ctx, analysis.SaveAnalysisBatchParams{
+		AnalysisID: upstreamID,
+		Files:      []analysis.TestFile{{ContentHash: []byte("hash-a"), Framework: "jest", Path: "src/auth.test.ts"}},
+	}); err != nil {
+		t.Fatalf("SaveAnalysisBatch failed: %v", err)
+	}
+	if err := repo.FinalizeAnalysis(ctx, analysis.FinalizeAnalysisParams{AnalysisID: upstreamID}); err != nil {
+		t.Fatalf("FinalizeAnalysis failed: %v", err)
+	}
+	forkID, forkCodebaseID := create("fork-owner", "fork-id")
+
+	t.Run("should return the files of another codebase at the same commit", func(t *testing.T) {
+		donorID, hashes, err := repo.GetForkReusableFiles(ctx, forkCodebaseID, "fork123", testParserVersion)
+		if err != nil {
+			t.Fatalf("GetForkReusableFiles failed: %v", err)
+		}
+		if donorID != upstreamID || string(hashes["src/auth.test.ts"]) != "hash-a" {
+			t.Errorf("got %s %v, want the upstream analysis and its hashes", donorID, hashes)
+		}
+
+		donorID, _, err = repo.GetForkReusableFiles(ctx, forkCodebaseID, "other456", testParserVersion)
+		if err != nil || donorID != analysis.NilUUID {
+			t.Errorf("expected nothing to reuse at another commit, got %s, %v", donorID, err)
+		}
+	})
+
+	t.Run("should respect the opt-out", func(t *testing.T) {
+		if _, err := pool.Exec(ctx, "UPDATE codebases SET fork_dedup_enabled = false WHERE id = $1", toPgUUID(forkCodebaseID)); err != nil {
+			t.Fatalf("failed to opt out: %v", err)
+		}
+		defer pool.Exec(ctx, "UPDATE codebases SET fork_dedup_enabled = true WHERE id = $1", toPgUUID(forkCodebaseID))
+
+		donorID, _, err := repo.GetForkReusableFiles(ctx, forkCodebaseID, "fork123", testParserVersion)
+		if err != nil || donorID != analysis.NilUUID {
+			t.Errorf("expected nothing to reuse after opting out, got %s, %v", donorID, err)
+		}
+	})
+
+	t.Run("should save the fingerprint and the duplicated analysis", func(t *testing.T) {
+		if err := repo.SaveFingerprint(ctx, forkID, []byte("fingerprint"), upstreamID); err != nil {
+			t.Fatalf("SaveFingerprint failed: %v", err)
+		}
+		var fingerprint []byte
+		var duplicateOf pgtype.UUID
+		if err := pool.QueryRow(ctx, "SELECT test_fingerprint, deduplicated_from FROM analyses WHERE id = $1", toPgUUID(forkID)).Scan(&fingerprint, &duplicateOf); err != nil {
+			t.Fatalf("failed to query analysis: %v", err)
+		}
+		if string(fingerprint) != "fingerprint" || fromPgUUID(duplicateOf) != upstreamID {
+			t.Errorf("got %q from %s, want the fingerprint linked to the upstream analysis", fingerprint, fromPgUUID(duplicateOf))
+		}
+	})
+}
+
+func TestAnalysisRepository_QuarantineFiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewAnalysisRepository(pool)
+	ctx := context.Background()
+
+	analysisID, err := repo.CreateAnalysisRecord(ctx, analysis.CreateAnalysisRecordParams{
+		Owner:          "quarantine-owner",
+		Repo:           "quarantine-repo",
+		CommitSHA:      "quarantine123",
+		Branch:         "main",
+		ExternalRepoID: "quarantine-id",
+		ParserVersion:  testParserVersion,
+	})
+	if err != nil {
+		t.Fatalf("CreateAnalysisRecord failed: %v", err)
+	}
+
+	t.Run("should record parse errors and count them once", func(t *testing.T) {
+		errs := []analysis.ParseError{
+			{FilePath: "src/broken.test.ts", Message: "parser panicked: index out of range", Panicked: true},
+			{FilePath: "src/invalid.test.ts", Message: strings.Repeat("x", 2000)},
+		}
+		for range 2 {
+			if err := repo.QuarantineFiles(ctx, analysisID, errs); err != nil {
+				t.Fatalf("QuarantineFiles failed: %v", err)
+			}
+		}
+
+		var count int32
+		if err := pool.QueryRow(ctx, "SELECT quarantined_files FROM analyses WHERE id = $1", toPgUUID(analysisID)).Scan(&count); err != nil {
+			t.Fatalf("failed to query analysis: %v", err)
+		}
+		if count != 2 {
+			t.Errorf("quarantined_files = %d, want 2", count)
+		}
+
+		rows, err := db.New(pool).ListParseErrors(ctx, toPgUUID(analysisID))
+		if err != nil {
+			t.Fatalf("ListParseErrors failed: %v", err)
+		}
+		if len(rows) != 2 || !rows[0].Panicked || rows[1].Panicked || len(rows[1].Message) > maxErrorMessageLength {
+			t.Errorf("unexpected parse errors %+v", rows)
+		}
+	})
+
+	t.Run("should fail with invalid analysis ID", func(t *testing.T) {
+		err := repo.QuarantineFiles(ctx, analysis.NilUUID, []analysis.ParseError{{FilePath: "a.test.ts"}})
+		if !errors.Is(err, analysis.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestAnalysisRepository_SaveLanguageStats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewAnalysisRepository(pool)
+	ctx := context.Background()
+
+	analysisID, err := repo.CreateAnalysisRecord(ctx, analysis.CreateAnalysisRecordParams{
+		Owner:          "language-owner",
+		Repo:           "language-repo",
+		CommitSHA:      "language123",
+		Branch:         "main",
+		ExternalRepoID: "language-id",
+		ParserVersion:  testParserVersion,
+	})
+	if err != nil {
+		t.Fatalf("CreateAnalysisRecord failed: %v", err)
+	}
+
+	stats := []analysis.LanguageStats{
+		{Language: "go", Files: 3, Tests: 12, FailedFiles: 1},
+		{Language: "typescript", Files: 1, Tests: 2, UnmatchedFiles: 4},
+	}
+	if err := repo.SaveLanguageStats(ctx, analysisID, stats); err != nil {
+		t.Fatalf("SaveLanguageStats failed: %v", err)
+	}
+
+	var files, tests, unmatched int
+	if err := pool.QueryRow(ctx, `
+		SELECT SUM(files), SUM(tests), SUM(unmatched_files) FROM analysis_languages WHERE analysis_id = $1
+	`, toPgUUID(analysisID)).Scan(&files, &tests, &unmatched); err != nil {
+		t.Fatalf("failed to query language stats: %v", err)
+	}
+	if files != 4 || tests != 14 || unmatched != 4 {
+		t.Errorf("files = %d, tests = %d, unmatched = %d", files, tests, unmatched)
+	}
+
+	if err := repo.SaveLanguageStats(ctx, analysis.NilUUID, stats); !errors.Is(err, analysis.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestAnalysisRepository_SaveUnmatchedSamples(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewAnalysisRepository(pool)
+	ctx := context.Background()
+
+	analysisID, err := repo.CreateAnalysisRecord(ctx, analysis.CreateAnalysisRecordParams{
+		Owner:          "unmatched-owner",
+		Repo:           "unmatched-repo",
+		CommitSHA:      "unmatched123",
+		Branch:         "main",
+		ExternalRepoID: "unmatched-id",
+		ParserVersion:  testParserVersion,
+	})
+	if err != nil {
+		t.Fatalf("CreateAnalysisRecord failed: %v", err)
+	}
+
+	samples := []analysis.UnmatchedSample{
+		{FilePath: "spec/a_spec.cr", Language: "crystal", Snippet: `require "spec"`},
+		{FilePath: "test/b.test.mjs", Language: "javascript"},
+	}
+	if err := repo.SaveUnmatchedSamples(ctx, analysisID, samples); err != nil {
+		t.Fatalf("SaveUnmatchedSamples failed: %v", err)
+	}
+
+	var count, withSnippet int
+	if err := pool.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(snippet) FROM unmatched_file_samples WHERE analysis_id = $1
+	`, toPgUUID(analysisID)).Scan(&count, &withSnippet); err != nil {
+		t.Fatalf("failed to query unmatched samples: %v", err)
+	}
+	if count != 2 || withSnippet != 1 {
+		t.Errorf("count = %d, with snippet = %d", count, withSnippet)
+	}
+
+	if err := repo.SaveUnmatchedSamples(ctx, analysis.NilUUID, samples); !errors.Is(err, analysis.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
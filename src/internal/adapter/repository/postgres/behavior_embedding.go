@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+// Embeddings are sent as pgvector text, several kilobytes each, so they are
+// written and looked up in smaller chunks than cache key hashes.
+const embeddingChunkSize = 500
+
+// SaveBehaviorEmbeddings stores test name embeddings for the semantic
+// behavior cache. Keys already stored keep their embedding.
+func (r *SpecDocumentRepository) SaveBehaviorEmbeddings(
+	ctx context.Context,
+	partition []byte,
+	embeddings []specview.BehaviorEmbedding,
+) error {
+	queries := db.New(r.pool)
+	for chunk := range slices.Chunk(embeddings, embeddingChunkSize) {
+		params := db.InsertBehaviorEmbeddingsParams{
+			PartitionHash:  partition,
+			CacheKeyHashes: make([][]byte, 0, len(chunk)),
+			Embeddings:     make([]string, 0, len(chunk)),
+		}
+		for _, e := range chunk {
+			if len(e.Embedding) != specview.EmbeddingDimensions {
+				return fmt.Errorf("%w: embedding has %d dimensions, want %d", specview.ErrInvalidInput, len(e.Embedding), specview.EmbeddingDimensions)
+			}
+			params.CacheKeyHashes = append(params.CacheKeyHashes, e.CacheKeyHash)
+			params.Embeddings = append(params.Embeddings, formatVector(e.Embedding))
+		}
+		if err := queries.InsertBehaviorEmbeddings(ctx, params); err != nil {
+			return fmt.Errorf("insert behavior embeddings: %w", err)
+		}
+	}
+	return nil
+}
+
+// FindSimilarBehaviors returns, by position in embeddings, the cached
+// behavior of the nearest test in partition at least minSimilarity alike.
+func (r *SpecDocumentRepository) FindSimilarBehaviors(
+	ctx context.Context,
+	partition []byte,
+	embeddings [][]float32,
+	minSimilarity float64,
+) (map[int]specview.SimilarBehavior, error) {
+	result := make(map[int]specview.SimilarBehavior)
+	queries := db.New(r.pool)
+	for offset := 0; offset < len(embeddings); offset += embeddingChunkSize {
+		chunk := embeddings[offset:min(offset+embeddingChunkSize, len(embeddings))]
+		vectors := make([]string, len(chunk))
+		for i, e := range chunk {
+			if len(e) != specview.EmbeddingDimensions {
+				return nil, fmt.Errorf("%w: embedding has %d dimensions, want %d", specview.ErrInvalidInput, len(e), specview.EmbeddingDimensions)
+			}
+			vectors[i] = formatVector(e)
+		}
+		rows, err := queries.FindSimilarBehaviors(ctx, db.FindSimilarBehaviorsParams{
+			Embeddings:    vectors,
+			MinSimilarity: minSimilarity,
+			PartitionHash: partition,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("find similar behaviors: %w", err)
+		}
+		for _, row := range rows {
+			result[offset+int(row.QueryIndex)-1] = specview.SimilarBehavior{
+				Description: row.ConvertedDescription,
+				Similarity:  row.Similarity,
+			}
+		}
+	}
+	return result, nil
+}
+
+// formatVector renders v in pgvector's text format.
+func formatVector(v []float32) string {
+	var b strings.Builder
+	b.Grow(len(v) * 10)
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
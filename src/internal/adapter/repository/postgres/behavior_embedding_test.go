@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestSpecDocumentRepository_SemanticBehaviorCache(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewSpecDocumentRepository(pool)
+	ctx := context.Background()
+
+	// unit returns an embedding pointing mostly along axis, tilted by tilt.
+	unit := func(axis int, tilt float32) []float32 {
+		v := make([]float32, specview.EmbeddingDimensions)
+		v[axis] = 1
+		v[axis+1] = tilt
+		return v
+	}
+	partition := specview.SemanticCachePartition("English", "gemini-2.5-flash", "", "", "v1", "")
+	other := specview.SemanticCachePartition("Korean", "gemini-2.5-flash", "", "", "v1", "")
+
+	if err := repo.SaveBehaviorCache(ctx, []specview.BehaviorCacheEntry{
+		{CacheKeyHash: []byte("login"), Description: "User can log in"},
+	}); err != nil {
+		t.Fatalf("SaveBehaviorCache failed: %v", err)
+	}
+	if err := repo.SaveBehaviorEmbeddings(ctx, partition, []specview.BehaviorEmbedding{
+		{CacheKeyHash: []byte("login"), Embedding: unit(0, 0)},
+		{CacheKeyHash: []byte("uncached"), Embedding: unit(10, 0)},
+	}); err != nil {
+		t.Fatalf("SaveBehaviorEmbeddings failed: %v", err)
+	}
+
+	t.Run("finds cached behaviors of similar tests", func(t *testing.T) {
+		found, err := repo.FindSimilarBehaviors(ctx, partition, [][]float32{unit(0, 0.1), unit(5, 0), unit(10, 0)}, 0.95)
+		if err != nil {
+			t.Fatalf("FindSimilarBehaviors failed: %v", err)
+		}
+		if len(found) != 1 || found[0].Description != "User can log in" || found[0].Similarity < 0.99 {
+			t.Errorf("expected only the near login test, got %+v", found)
+		}
+	})
+
+	t.Run("stays within the partition", func(t *testing.T) {
+		found, err := repo.FindSimilarBehaviors(ctx, other, [][]float32{unit(0, 0)}, 0.95)
+		if err != nil {
+			t.Fatalf("FindSimilarBehaviors failed: %v", err)
+		}
+		if len(found) != 0 {
+			t.Errorf("expected no hits in another partition, got %+v", found)
+		}
+	})
+
+	t.Run("rejects embeddings of the wrong size", func(t *testing.T) {
+		if err := repo.SaveBehaviorEmbeddings(ctx, partition, []specview.BehaviorEmbedding{
+			{CacheKeyHash: []byte("short"), Embedding: []float32{1, 0}},
+		}); err == nil {
+			t.Error("expected an error for a 2-dimensional embedding")
+		}
+	})
+
+	t.Run("retention sweeps embeddings without a cached behavior", func(t *testing.T) {
+		if _, err := pool.Exec(ctx, `UPDATE behavior_cache_embeddings SET created_at = now() - interval '2 days'`); err != nil {
+			t.Fatalf("age embeddings: %v", err)
+		}
+		result, err := NewRetentionRepository(pool).DeleteOrphanBehaviorEmbeddings(ctx, 100)
+		if err != nil {
+			t.Fatalf("DeleteOrphanBehaviorEmbeddings failed: %v", err)
+		}
+		if result.DeletedCount != 1 {
+			t.Errorf("expected the uncached embedding deleted, got %d", result.DeletedCount)
+		}
+	})
+
+	t.Run("retention skips databases without the embeddings table", func(t *testing.T) {
+		if _, err := pool.Exec(ctx, `DROP TABLE behavior_cache_embeddings`); err != nil {
+			t.Fatalf("drop embeddings table: %v", err)
+		}
+		result, err := NewRetentionRepository(pool).DeleteOrphanBehaviorEmbeddings(ctx, 100)
+		if err != nil || result.DeletedCount != 0 {
+			t.Errorf("expected nothing deleted without an error, got %d, %v", result.DeletedCount, err)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ specview.FeedbackRepository = (*BehaviorFeedbackRepository)(nil)
+
+// BehaviorFeedbackRepository applies user corrections of behaviors to the
+// behavior cache.
+type BehaviorFeedbackRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewBehaviorFeedbackRepository creates a new BehaviorFeedbackRepository.
+func NewBehaviorFeedbackRepository(pool *pgxpool.Pool) *BehaviorFeedbackRepository {
+	return &BehaviorFeedbackRepository{pool: pool}
+}
+
+func (r *BehaviorFeedbackRepository) GetFeedback(ctx context.Context, feedbackID string) (*specview.BehaviorFeedback, error) {
+	parsedID, err := analysis.ParseUUID(feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid feedback ID format", specview.ErrInvalidInput)
+	}
+
+	row, err := db.New(r.pool).GetBehaviorFeedback(ctx, toPgUUID(parsedID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, specview.ErrFeedbackNotFound
+		}
+		return nil, fmt.Errorf("get behavior feedback: %w", err)
+	}
+	return &specview.BehaviorFeedback{
+		AnalysisID:           fromPgUUID(row.AnalysisID).String(),
+		CacheKeyHash:         row.CacheKeyHash,
+		CorrectedDescription: row.CorrectedDescription,
+		DocumentID:           fromPgUUID(row.DocumentID).String(),
+		ID:                   fromPgUUID(row.ID).String(),
+		Status:               specview.FeedbackStatus(row.Status),
+		UserID:               fromPgUUID(row.UserID).String(),
+	}, nil
+}
+
+// ApplyFeedback upserts the verified cache entry and marks the feedback
+// applied in one transaction. Feedback no longer accepted, e.g. rejected since
+// it was loaded, leaves the cache unchanged.
+func (r *BehaviorFeedbackRepository) ApplyFeedback(ctx context.Context, feedback *specview.BehaviorFeedback) error {
+	parsedID, err := analysis.ParseUUID(feedback.ID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid feedback ID format", specview.ErrInvalidInput)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "ApplyFeedback",
+				"error", rbErr,
+			)
+		}
+	}()
+
+	queries := db.New(tx)
+	if err := queries.UpsertVerifiedBehaviorCache(ctx, db.UpsertVerifiedBehaviorCacheParams{
+		AnalysisID:           optionalPgUUID(feedback.AnalysisID),
+		CacheKeyHash:         feedback.CacheKeyHash,
+		ConvertedDescription: feedback.CorrectedDescription,
+	}); err != nil {
+		return fmt.Errorf("upsert verified behavior cache: %w", err)
+	}
+
+	n, err := queries.MarkBehaviorFeedbackApplied(ctx, toPgUUID(parsedID))
+	if err != nil {
+		return fmt.Errorf("mark behavior feedback applied: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: feedback %s is no longer accepted", specview.ErrInvalidInput, feedback.ID)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestBehaviorFeedbackRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	feedbackRepo := NewBehaviorFeedbackRepository(pool)
+	docRepo := NewSpecDocumentRepository(pool)
+	ctx := context.Background()
+
+	userID := setupTestUser(t, ctx, pool)
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, NewAnalysisRepository(pool), pool)
+	cacheKey := []byte("behavior-cache-key")
+
+	var documentID, behaviorID string
+	if err := pool.QueryRow(ctx, `
+		INSERT INTO spec_documents (user_id, analysis_id, content_hash, language, model_id, retention_days_at_creation)
+		VALUES ($1, $2, 'feedback', 'English', 'gemini-2.5-flash', 30)
+		RETURNING id::text
+	`, userID, analysisID.String()).Scan(&documentID); err != nil {
+		t.Fatalf("insert document: %v", err)
+	}
+	if err := pool.QueryRow(ctx, `
+		WITH d AS (
+			INSERT INTO spec_domains (document_id, name) VALUES ($1, 'Auth') RETURNING id
+		), f AS (
+			INSERT INTO spec_features (domain_id, name) SELECT id, 'Login' FROM d RETURNING id
+		)
+		INSERT INTO spec_behaviors (feature_id, original_name, converted_description, cache_key_hash)
+		SELECT id, 'TestLogin', 'Logs in', $2 FROM f
+		RETURNING id::text
+	`, documentID, cacheKey).Scan(&behaviorID); err != nil {
+		t.Fatalf("insert behavior: %v", err)
+	}
+
+	insertFeedback := func(t *testing.T, status string) string {
+		t.Helper()
+		var id string
+		if err := pool.QueryRow(ctx, `
+			INSERT INTO behavior_feedback (behavior_id, user_id, corrected_description, status)
+			VALUES ($1, $2, 'Signs the user in with a password', $3)
+			RETURNING id::text
+		`, behaviorID, userID, status).Scan(&id); err != nil {
+			t.Fatalf("insert feedback: %v", err)
+		}
+		return id
+	}
+
+	if err := docRepo.SaveBehaviorCache(ctx, []specview.BehaviorCacheEntry{{CacheKeyHash: cacheKey, Description: "Logs in"}}); err != nil {
+		t.Fatalf("SaveBehaviorCache failed: %v", err)
+	}
+
+	t.Run("applies accepted feedback as a verified entry", func(t *testing.T) {
+		feedbackID := insertFeedback(t, "accepted")
+
+		feedback, err := feedbackRepo.GetFeedback(ctx, feedbackID)
+		if err != nil {
+			t.Fatalf("GetFeedback failed: %v", err)
+		}
+		if feedback.Status != specview.FeedbackAccepted || feedback.DocumentID != documentID || string(feedback.CacheKeyHash) != string(cacheKey) {
+			t.Fatalf("unexpected feedback %+v", feedback)
+		}
+
+		if err := feedbackRepo.ApplyFeedback(ctx, feedback); err != nil {
+			t.Fatalf("ApplyFeedback failed: %v", err)
+		}
+		if err := feedbackRepo.ApplyFeedback(ctx, feedback); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput applying twice, got %v", err)
+		}
+		if got, err := feedbackRepo.GetFeedback(ctx, feedbackID); err != nil || got.Status != specview.FeedbackApplied {
+			t.Errorf("expected applied feedback, got %+v, %v", got, err)
+		}
+
+		verified, err := docRepo.FindVerifiedBehaviors(ctx, [][]byte{cacheKey, []byte("other")})
+		if err != nil {
+			t.Fatalf("FindVerifiedBehaviors failed: %v", err)
+		}
+		if len(verified) != 1 || verified["6265686176696f722d63616368652d6b6579"] != "Signs the user in with a password" {
+			t.Errorf("unexpected verified behaviors %v", verified)
+		}
+	})
+
+	t.Run("AI conversions keep verified entries", func(t *testing.T) {
+		if err := docRepo.SaveBehaviorCache(ctx, []specview.BehaviorCacheEntry{{CacheKeyHash: cacheKey, Description: "Logs in again"}}); err != nil {
+			t.Fatalf("SaveBehaviorCache failed: %v", err)
+		}
+		cached, err := docRepo.FindCachedBehaviors(ctx, [][]byte{cacheKey})
+		if err != nil {
+			t.Fatalf("FindCachedBehaviors failed: %v", err)
+		}
+		for _, description := range cached {
+			if description != "Signs the user in with a password" {
+				t.Errorf("expected the verified description to be kept, got %q", description)
+			}
+		}
+	})
+
+	t.Run("pending feedback is not applied", func(t *testing.T) {
+		feedback, err := feedbackRepo.GetFeedback(ctx, insertFeedback(t, "pending"))
+		if err != nil {
+			t.Fatalf("GetFeedback failed: %v", err)
+		}
+		if err := feedbackRepo.ApplyFeedback(ctx, feedback); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("unknown feedback", func(t *testing.T) {
+		if _, err := feedbackRepo.GetFeedback(ctx, "00000000-0000-0000-0000-000000000001"); !errors.Is(err, specview.ErrFeedbackNotFound) {
+			t.Errorf("expected ErrFeedbackNotFound, got %v", err)
+		}
+	})
+}
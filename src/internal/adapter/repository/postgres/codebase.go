@@ -103,6 +103,7 @@ func (r *CodebaseRepository) MarkStaleAndUpsert(ctx context.Context, staleID ana
 		DefaultBranch:  pgtype.Text{String: params.DefaultBranch, Valid: params.DefaultBranch != ""},
 		ExternalRepoID: params.ExternalRepoID,
 		IsPrivate:      params.IsPrivate,
+		Region:         pgtype.Text{String: params.Region, Valid: params.Region != ""},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("upsert codebase: %w", err)
@@ -181,13 +182,15 @@ func (r *CodebaseRepository) FindWithLastCommit(ctx context.Context, host, owner
 	}
 
 	return &analysis.Codebase{
-		ExternalRepoID: row.ExternalRepoID,
-		Host:           row.Host,
-		ID:             fromPgUUID(row.ID),
-		IsStale:        row.IsStale,
-		LastCommitSHA:  row.LastCommitSha,
-		Name:           row.Name,
-		Owner:          row.Owner,
+		ExternalRepoID:   row.ExternalRepoID,
+		ForkDedupEnabled: row.ForkDedupEnabled,
+		Host:             row.Host,
+		ID:               fromPgUUID(row.ID),
+		IsStale:          row.IsStale,
+		LastCommitSHA:    row.LastCommitSha,
+		Name:             row.Name,
+		Owner:            row.Owner,
+		Region:           row.Region.String,
 	}, nil
 }
 
@@ -205,6 +208,7 @@ func (r *CodebaseRepository) Upsert(ctx context.Context, params analysis.UpsertC
 		DefaultBranch:  pgtype.Text{String: params.DefaultBranch, Valid: params.DefaultBranch != ""},
 		ExternalRepoID: params.ExternalRepoID,
 		IsPrivate:      params.IsPrivate,
+		Region:         pgtype.Text{String: params.Region, Valid: params.Region != ""},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("upsert codebase: %w", err)
@@ -215,11 +219,13 @@ func (r *CodebaseRepository) Upsert(ctx context.Context, params analysis.UpsertC
 
 func mapCodebase(row db.Codebasis) *analysis.Codebase {
 	return &analysis.Codebase{
-		ExternalRepoID: row.ExternalRepoID,
-		Host:           row.Host,
-		ID:             fromPgUUID(row.ID),
-		IsStale:        row.IsStale,
-		Name:           row.Name,
-		Owner:          row.Owner,
+		ExternalRepoID:   row.ExternalRepoID,
+		ForkDedupEnabled: row.ForkDedupEnabled,
+		Host:             row.Host,
+		ID:               fromPgUUID(row.ID),
+		IsStale:          row.IsStale,
+		Name:             row.Name,
+		Owner:            row.Owner,
+		Region:           row.Region.String,
 	}
 }
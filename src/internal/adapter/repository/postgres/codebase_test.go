@@ -394,3 +394,60 @@ func TestCodebaseRepository_UpdateOwnerName(t *testing.T) {
 		}
 	})
 }
+
+func TestCodebaseRepository_FindWithLastCommit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	codebaseRepo := NewCodebaseRepository(pool)
+	ctx := context.Background()
+
+	_, err := analysisRepo.CreateAnalysisRecord(ctx, analysis.CreateAnalysisRecordParams{
+		Owner:          "last-commit-owner",
+		Repo:           "last-commit-repo",
+		CommitSHA:      "last-commit-sha",
+		Branch:         "main",
+		ExternalRepoID: "last-commit-ext-id",
+		ParserVersion:  codebaseTestParserVersion,
+	})
+	if err != nil {
+		t.Fatalf("CreateAnalysisRecord failed: %v", err)
+	}
+
+	t.Run("should read fork dedup enabled by default", func(t *testing.T) {
+		codebase, err := codebaseRepo.FindWithLastCommit(ctx, "github.com", "last-commit-owner", "last-commit-repo")
+		if err != nil {
+			t.Fatalf("FindWithLastCommit failed: %v", err)
+		}
+		if !codebase.ForkDedupEnabled {
+			t.Error("expected fork dedup enabled")
+		}
+	})
+
+	t.Run("should read the fork dedup opt-out", func(t *testing.T) {
+		if _, err := pool.Exec(ctx, "UPDATE codebases SET fork_dedup_enabled = false WHERE owner = $1", "last-commit-owner"); err != nil {
+			t.Fatalf("opt out of fork dedup: %v", err)
+		}
+
+		codebase, err := codebaseRepo.FindWithLastCommit(ctx, "github.com", "last-commit-owner", "last-commit-repo")
+		if err != nil {
+			t.Fatalf("FindWithLastCommit failed: %v", err)
+		}
+		if codebase.ForkDedupEnabled {
+			t.Error("expected fork dedup disabled")
+		}
+
+		byName, err := codebaseRepo.FindByOwnerName(ctx, "github.com", "last-commit-owner", "last-commit-repo")
+		if err != nil {
+			t.Fatalf("FindByOwnerName failed: %v", err)
+		}
+		if byName.ForkDedupEnabled {
+			t.Error("expected fork dedup disabled through FindByOwnerName")
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/coverage"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ coverage.Repository = (*CoverageRepository)(nil)
+
+// CoverageRepository reads coverage report uploads, stores the source files
+// linked to test files in test_file_coverage and annotates spec behaviors in
+// spec_behavior_coverage.
+type CoverageRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCoverageRepository(pool *pgxpool.Pool) *CoverageRepository {
+	return &CoverageRepository{pool: pool}
+}
+
+func (r *CoverageRepository) GetUpload(ctx context.Context, uploadID string) (*coverage.Upload, error) {
+	parsedID, err := analysis.ParseUUID(uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid upload ID format", coverage.ErrInvalidInput)
+	}
+
+	row, err := db.New(r.pool).GetCoverageUpload(ctx, toPgUUID(parsedID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, coverage.ErrUploadNotFound
+		}
+		return nil, fmt.Errorf("get coverage upload: %w", err)
+	}
+
+	return &coverage.Upload{
+		AnalysisID: fromPgUUID(row.AnalysisID).String(),
+		Content:    row.Content,
+		Format:     coverage.Format(row.Format),
+		ID:         fromPgUUID(row.ID).String(),
+	}, nil
+}
+
+func (r *CoverageRepository) GetTestFiles(ctx context.Context, analysisID string) ([]coverage.TestFile, error) {
+	parsedID, err := analysis.ParseUUID(analysisID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid analysis ID format", coverage.ErrInvalidInput)
+	}
+
+	rows, err := db.New(r.pool).GetTestFilePathsByAnalysisID(ctx, toPgUUID(parsedID))
+	if err != nil {
+		return nil, fmt.Errorf("get test files: %w", err)
+	}
+
+	files := make([]coverage.TestFile, len(rows))
+	for i, row := range rows {
+		files[i] = coverage.TestFile{ID: fromPgUUID(row.ID).String(), Path: row.FilePath}
+	}
+	return files, nil
+}
+
+func (r *CoverageRepository) SaveLinks(ctx context.Context, upload *coverage.Upload, links []coverage.Link) (bool, error) {
+	parsedUploadID, err := analysis.ParseUUID(upload.ID)
+	if err != nil {
+		return false, fmt.Errorf("%w: invalid upload ID format", coverage.ErrInvalidInput)
+	}
+	parsedAnalysisID, err := analysis.ParseUUID(upload.AnalysisID)
+	if err != nil {
+		return false, fmt.Errorf("%w: invalid analysis ID format", coverage.ErrInvalidInput)
+	}
+	pgUploadID := toPgUUID(parsedUploadID)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "SaveLinks",
+				"upload_id", upload.ID,
+				"error", rbErr,
+			)
+		}
+	}()
+
+	queries := db.New(tx)
+	claimed, err := queries.MarkCoverageUploadProcessed(ctx, pgUploadID)
+	if err != nil {
+		return false, fmt.Errorf("mark upload processed: %w", err)
+	}
+	if claimed == 0 {
+		return false, nil
+	}
+
+	if len(links) > 0 {
+		batch := &pgx.Batch{}
+		for _, link := range links {
+			testFileID, err := analysis.ParseUUID(link.TestFileID)
+			if err != nil {
+				return false, fmt.Errorf("parse test file ID %q: %w", link.TestFileID, err)
+			}
+			batch.Queue(db.UpsertTestFileCoverageBatch,
+				toPgUUID(testFileID),
+				link.Source.Path,
+				int32(link.Source.LinesValid),
+				int32(link.Source.LinesCovered),
+				pgUploadID,
+			)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return false, fmt.Errorf("upsert test file coverage: %w", err)
+		}
+
+		if _, err := queries.AnnotateBehaviorCoverage(ctx, toPgUUID(parsedAnalysisID)); err != nil {
+			return false, fmt.Errorf("annotate behavior coverage: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit transaction: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/coverage"
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestCoverageRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	specRepo := NewSpecDocumentRepository(pool)
+	repo := NewCoverageRepository(pool)
+	ctx := context.Background()
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	insertUpload := func(t *testing.T) string {
+		t.Helper()
+		var id string
+		err := pool.QueryRow(ctx, `
+			INSERT INTO coverage_uploads (analysis_id, format, content)
+			VALUES ($1, 'lcov', $2) RETURNING id::text
+		`, analysisID.String(), []byte("SF:src/user.go\nDA:1,1\nend_of_record\n")).Scan(&id)
+		if err != nil {
+			t.Fatalf("insert upload: %v", err)
+		}
+		return id
+	}
+
+	t.Run("should load an upload and the analysis test files", func(t *testing.T) {
+		upload, err := repo.GetUpload(ctx, insertUpload(t))
+		if err != nil {
+			t.Fatalf("GetUpload failed: %v", err)
+		}
+		if upload.AnalysisID != analysisID.String() || upload.Format != coverage.FormatLCOV {
+			t.Errorf("unexpected upload: %+v", upload)
+		}
+
+		files, err := repo.GetTestFiles(ctx, upload.AnalysisID)
+		if err != nil {
+			t.Fatalf("GetTestFiles failed: %v", err)
+		}
+		if len(files) == 0 || files[0].Path != "src/user_test.go" || files[0].ID == "" {
+			t.Errorf("unexpected test files: %+v", files)
+		}
+	})
+
+	t.Run("should annotate behaviors of linked test files once per upload", func(t *testing.T) {
+		userID := setupTestUser(t, ctx, pool)
+		testData, err := specRepo.GetTestDataByAnalysisID(ctx, analysisID.String())
+		if err != nil || len(testData) == 0 || len(testData[0].Tests) == 0 {
+			t.Fatalf("GetTestDataByAnalysisID failed: %v", err)
+		}
+		doc := &specview.SpecDocument{
+			AnalysisID:  analysisID.String(),
+			ContentHash: []byte("coverage-hash"),
+			Language:    "English",
+			ModelID:     "gemini-2.5-flash",
+			UserID:      userID,
+			Domains: []specview.Domain{{
+				Name: "Users",
+				Features: []specview.Feature{{
+					Name: "Creation",
+					Behaviors: []specview.Behavior{{
+						Description:  "Creates a user",
+						OriginalName: testData[0].Tests[0].Name,
+						TestCaseID:   testData[0].Tests[0].TestCaseID,
+					}},
+				}},
+			}},
+		}
+		if err := specRepo.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+
+		files, err := repo.GetTestFiles(ctx, analysisID.String())
+		if err != nil {
+			t.Fatalf("GetTestFiles failed: %v", err)
+		}
+		upload, err := repo.GetUpload(ctx, insertUpload(t))
+		if err != nil {
+			t.Fatalf("GetUpload failed: %v", err)
+		}
+		links := []coverage.Link{
+			{Source: coverage.SourceFile{LinesCovered: 3, LinesValid: 4, Path: "src/user.go"}, TestFileID: files[0].ID},
+			{Source: coverage.SourceFile{LinesCovered: 1, LinesValid: 6, Path: "src/user_store.go"}, TestFileID: files[0].ID},
+		}
+		saved, err := repo.SaveLinks(ctx, upload, links)
+		if err != nil || !saved {
+			t.Fatalf("SaveLinks = %v, %v", saved, err)
+		}
+		saved, err = repo.SaveLinks(ctx, upload, links)
+		if err != nil || saved {
+			t.Fatalf("second SaveLinks = %v, %v, want false", saved, err)
+		}
+
+		var valid, covered int
+		err = pool.QueryRow(ctx, `
+			SELECT c.lines_valid, c.lines_covered
+			FROM spec_behavior_coverage c
+			JOIN spec_behaviors sb ON sb.id = c.behavior_id
+			JOIN spec_features sf ON sf.id = sb.feature_id
+			JOIN spec_domains dom ON dom.id = sf.domain_id
+			WHERE dom.document_id = $1
+		`, doc.ID).Scan(&valid, &covered)
+		if err != nil {
+			t.Fatalf("query behavior coverage: %v", err)
+		}
+		if valid != 10 || covered != 4 {
+			t.Errorf("behavior coverage = %d/%d, want 4/10", covered, valid)
+		}
+	})
+
+	t.Run("should report a missing upload", func(t *testing.T) {
+		_, err := repo.GetUpload(ctx, "00000000-0000-0000-0000-000000000000")
+		if !errors.Is(err, coverage.ErrUploadNotFound) {
+			t.Errorf("expected ErrUploadNotFound, got %v", err)
+		}
+	})
+}
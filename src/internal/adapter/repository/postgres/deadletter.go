@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/deadletter"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ deadletter.Repository = (*DeadLetterRepository)(nil)
+
+// DeadLetterRepository reads discarded River jobs.
+type DeadLetterRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDeadLetterRepository creates a new DeadLetterRepository.
+func NewDeadLetterRepository(pool *pgxpool.Pool) *DeadLetterRepository {
+	return &DeadLetterRepository{pool: pool}
+}
+
+func (r *DeadLetterRepository) ListDiscardedJobs(ctx context.Context, filter deadletter.Filter) ([]deadletter.Job, error) {
+	jobIDs := filter.JobIDs
+	if jobIDs == nil {
+		jobIDs = []int64{}
+	}
+
+	queries := db.New(r.pool)
+	rows, err := queries.ListDiscardedJobs(ctx, db.ListDiscardedJobsParams{
+		DiscardedAfter: pgtype.Timestamptz{Time: filter.DiscardedAfter, Valid: true},
+		JobIds:         jobIDs,
+		Kinds:          filter.Kinds,
+		MaxRows:        int32(filter.Limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list discarded jobs: %w", err)
+	}
+
+	jobs := make([]deadletter.Job, len(rows))
+	for i, row := range rows {
+		jobs[i] = deadletter.Job{
+			AnalysisStatus: row.AnalysisStatus,
+			Attempt:        int(row.Attempt),
+			CommitSHA:      row.CommitSha,
+			ID:             row.ID,
+			Kind:           row.Kind,
+			LastError:      row.LastError,
+			MaxAttempts:    int(row.MaxAttempts),
+			Owner:          row.Owner,
+			Repo:           row.Repo,
+		}
+		if row.AnalysisID.Valid {
+			jobs[i].AnalysisID = fromPgUUID(row.AnalysisID).String()
+		}
+		if row.FinalizedAt.Valid {
+			jobs[i].DiscardedAt = row.FinalizedAt.Time
+		}
+	}
+	return jobs, nil
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/deadletter"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func insertDiscardedJob(t *testing.T, ctx context.Context, pool *pgxpool.Pool, kind, args string) int64 {
+	t.Helper()
+
+	var id int64
+	err := pool.QueryRow(ctx, `
+		INSERT INTO river_job (state, attempt, max_attempts, attempted_at, finalized_at, args, errors, kind, queue)
+		VALUES ('discarded', 3, 3, now(), now(), $1::jsonb,
+			ARRAY['{"attempt":1,"error":"first"}'::jsonb, '{"attempt":3,"error":"clone timeout"}'::jsonb],
+			$2, 'default')
+		RETURNING id
+	`, args, kind).Scan(&id)
+	if err != nil {
+		t.Fatalf("insert discarded job: %v", err)
+	}
+	return id
+}
+
+func TestDeadLetterRepository_ListDiscardedJobs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	repo := NewDeadLetterRepository(pool)
+	ctx := context.Background()
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	specviewJobID := insertDiscardedJob(t, ctx, pool, "specview:generate",
+		fmt.Sprintf(`{"analysis_id":%q,"language":"English","user_id":"u1"}`, analysisID.String()))
+	analyzeJobID := insertDiscardedJob(t, ctx, pool, "analysis:analyze",
+		`{"owner":"ghost","repo":"missing","commit_sha":"deadbeef"}`)
+
+	t.Run("should join specview job with its analysis", func(t *testing.T) {
+		jobs, err := repo.ListDiscardedJobs(ctx, deadletter.Filter{
+			JobIDs: []int64{specviewJobID},
+			Kinds:  []string{"specview:generate"},
+			Limit:  10,
+		})
+		if err != nil {
+			t.Fatalf("ListDiscardedJobs failed: %v", err)
+		}
+		if len(jobs) != 1 {
+			t.Fatalf("expected 1 job, got %d", len(jobs))
+		}
+		job := jobs[0]
+		if job.AnalysisID != analysisID.String() || job.Owner != "testowner" || job.AnalysisStatus != "completed" {
+			t.Errorf("unexpected join result: %+v", job)
+		}
+		if job.LastError != "clone timeout" {
+			t.Errorf("expected last error, got %q", job.LastError)
+		}
+	})
+
+	t.Run("should fall back to args when no analysis matches", func(t *testing.T) {
+		jobs, err := repo.ListDiscardedJobs(ctx, deadletter.Filter{
+			Kinds: []string{"analysis:analyze"},
+			Limit: 10,
+		})
+		if err != nil {
+			t.Fatalf("ListDiscardedJobs failed: %v", err)
+		}
+		if len(jobs) != 1 || jobs[0].ID != analyzeJobID {
+			t.Fatalf("expected analyze job, got %+v", jobs)
+		}
+		if jobs[0].AnalysisID != "" || jobs[0].Owner != "ghost" || jobs[0].CommitSHA != "deadbeef" {
+			t.Errorf("unexpected fallback result: %+v", jobs[0])
+		}
+	})
+}
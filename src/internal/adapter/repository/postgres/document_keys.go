@@ -0,0 +1,519 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/core/pkg/crypto"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+// resealBatchSize bounds the documents listed per round of a reseal.
+const resealBatchSize = 100
+
+// DocumentKeyring encrypts the generated text of documents owned by tenants
+// that turned on document encryption: executive summaries, behavior
+// descriptions and the descriptions of merged tests. Each tenant has one
+// active data key, stored wrapped with the master key. Documents record the
+// key that sealed them, so documents sealed before a rotation keep opening with
+// their retired key until they are resealed.
+type DocumentKeyring struct {
+	master   crypto.Encryptor
+	pool     *pgxpool.Pool
+	previous crypto.Encryptor
+
+	mu   sync.Mutex
+	keys map[pgtype.UUID]crypto.Encryptor
+}
+
+// DocumentKeyringOption configures a DocumentKeyring.
+type DocumentKeyringOption func(*DocumentKeyring)
+
+// WithPreviousMasterKey unwraps data keys wrapped before a master key rotation,
+// until Rewrap has moved them to the current master key. Nil is ignored.
+func WithPreviousMasterKey(previous crypto.Encryptor) DocumentKeyringOption {
+	return func(k *DocumentKeyring) {
+		if previous != nil {
+			k.previous = previous
+		}
+	}
+}
+
+// NewDocumentKeyring creates a keyring wrapping data keys with master. The
+// keyring owns master and the previous master key and closes them on Close.
+func NewDocumentKeyring(pool *pgxpool.Pool, master crypto.Encryptor, opts ...DocumentKeyringOption) *DocumentKeyring {
+	k := &DocumentKeyring{
+		keys:   make(map[pgtype.UUID]crypto.Encryptor),
+		master: master,
+		pool:   pool,
+	}
+	for _, opt := range opts {
+		opt(k)
+	}
+	return k
+}
+
+// Close releases the unwrapped data keys and the master keys.
+func (k *DocumentKeyring) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	var errs []error
+	for id, enc := range k.keys {
+		if err := enc.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(k.keys, id)
+	}
+	if err := k.master.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if k.previous != nil {
+		if err := k.previous.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetTenantEncryption turns document encryption on or off for the tenant.
+// Documents saved afterwards are sealed, or stored in plaintext; existing
+// documents are left as they are and keep opening. Reseal seals them.
+func (k *DocumentKeyring) SetTenantEncryption(ctx context.Context, tenantID string, enabled bool) error {
+	parsedID, err := analysis.ParseUUID(tenantID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid tenant ID format", specview.ErrInvalidInput)
+	}
+
+	n, err := db.New(k.pool).SetTenantDocumentEncryption(ctx, db.SetTenantDocumentEncryptionParams{
+		Enabled:  enabled,
+		TenantID: toPgUUID(parsedID),
+	})
+	if err != nil {
+		return fmt.Errorf("set tenant document encryption: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: tenant %s not found", specview.ErrInvalidInput, tenantID)
+	}
+	return nil
+}
+
+// RotateTenantKey retires the tenant's active data key and reseals every
+// document of the tenant with a new one. It returns the documents resealed.
+// A failed reseal can be resumed with Reseal; documents not yet resealed keep
+// opening with the retired key.
+func (k *DocumentKeyring) RotateTenantKey(ctx context.Context, tenantID string) (int, error) {
+	parsedID, err := k.encryptingTenant(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := db.New(k.pool).RetireTenantDocumentKey(ctx, parsedID); err != nil {
+		return 0, fmt.Errorf("retire tenant document key: %w", err)
+	}
+	return k.reseal(ctx, parsedID)
+}
+
+// Reseal seals every document of the tenant not yet sealed with its active
+// data key, plaintext documents included, and returns the documents resealed.
+func (k *DocumentKeyring) Reseal(ctx context.Context, tenantID string) (int, error) {
+	parsedID, err := k.encryptingTenant(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return k.reseal(ctx, parsedID)
+}
+
+// Rewrap wraps every data key still wrapped with the previous master key with
+// the current one and returns the keys rewrapped. Once it has run, the
+// previous master key can be dropped.
+func (k *DocumentKeyring) Rewrap(ctx context.Context) (int, error) {
+	queries := db.New(k.pool)
+
+	keys, err := queries.ListTenantDocumentKeys(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list tenant document keys: %w", err)
+	}
+
+	rewrapped := 0
+	for _, key := range keys {
+		raw, previous, err := k.unwrap(key.WrappedKey)
+		if err != nil {
+			return rewrapped, fmt.Errorf("document key %s: %w", fromPgUUID(key.ID), err)
+		}
+		if !previous {
+			continue
+		}
+		wrapped, err := k.master.Encrypt(raw)
+		if err != nil {
+			return rewrapped, fmt.Errorf("wrap document key %s: %w", fromPgUUID(key.ID), err)
+		}
+		if err := queries.UpdateTenantDocumentKeyWrap(ctx, db.UpdateTenantDocumentKeyWrapParams{
+			ID:         key.ID,
+			WrappedKey: wrapped,
+		}); err != nil {
+			return rewrapped, fmt.Errorf("update document key %s: %w", fromPgUUID(key.ID), err)
+		}
+		rewrapped++
+	}
+	return rewrapped, nil
+}
+
+// encryptingTenant parses tenantID and checks that the tenant encrypts its documents.
+func (k *DocumentKeyring) encryptingTenant(ctx context.Context, tenantID string) (pgtype.UUID, error) {
+	parsedID, err := analysis.ParseUUID(tenantID)
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("%w: invalid tenant ID format", specview.ErrInvalidInput)
+	}
+
+	enabled, err := db.New(k.pool).GetTenantDocumentEncryption(ctx, toPgUUID(parsedID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.UUID{}, fmt.Errorf("%w: tenant %s not found", specview.ErrInvalidInput, tenantID)
+		}
+		return pgtype.UUID{}, fmt.Errorf("get tenant document encryption: %w", err)
+	}
+	if !enabled {
+		return pgtype.UUID{}, fmt.Errorf("%w: tenant %s does not encrypt documents", specview.ErrInvalidInput, tenantID)
+	}
+	return toPgUUID(parsedID), nil
+}
+
+func (k *DocumentKeyring) reseal(ctx context.Context, tenantID pgtype.UUID) (int, error) {
+	queries := db.New(k.pool)
+
+	keyID, enc, err := k.activeKey(ctx, queries, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	resealed := 0
+	for {
+		ids, err := queries.ListTenantDocumentsToReseal(ctx, db.ListTenantDocumentsToResealParams{
+			BatchSize: resealBatchSize,
+			KeyID:     keyID,
+			TenantID:  tenantID,
+		})
+		if err != nil {
+			return resealed, fmt.Errorf("list documents to reseal: %w", err)
+		}
+		if len(ids) == 0 {
+			return resealed, nil
+		}
+		for _, id := range ids {
+			if err := k.resealDocument(ctx, id, keyID, enc); err != nil {
+				return resealed, fmt.Errorf("reseal document %s: %w", fromPgUUID(id), err)
+			}
+			resealed++
+		}
+	}
+}
+
+// resealDocument opens the text of one document with the key that sealed it,
+// if any, and seals it with enc. Search entries of the document are dropped,
+// encrypted documents are not indexed.
+func (k *DocumentKeyring) resealDocument(ctx context.Context, documentID, keyID pgtype.UUID, enc crypto.Encryptor) error {
+	tx, err := k.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "resealDocument",
+				"document_id", fromPgUUID(documentID).String(),
+				"error", rbErr,
+			)
+		}
+	}()
+
+	queries := db.New(tx)
+
+	doc, err := queries.LockSpecDocumentText(ctx, documentID)
+	if err != nil {
+		return fmt.Errorf("lock spec document: %w", err)
+	}
+	if doc.EncryptionKeyID == keyID {
+		return nil
+	}
+	old, err := k.opener(ctx, queries, doc.EncryptionKeyID)
+	if err != nil {
+		return err
+	}
+
+	summary, err := resealText(old, enc, doc.ExecutiveSummary.String)
+	if err != nil {
+		return err
+	}
+
+	texts, err := queries.GetSpecDocumentBehaviorTexts(ctx, documentID)
+	if err != nil {
+		return fmt.Errorf("get behavior texts: %w", err)
+	}
+	if len(texts) > 0 {
+		batch := &pgx.Batch{}
+		for _, t := range texts {
+			description, err := resealText(old, enc, t.ConvertedDescription)
+			if err != nil {
+				return err
+			}
+			query := db.UpdateSpecBehaviorDescriptionBatch
+			if t.IsMerge {
+				query = db.UpdateSpecBehaviorMergeDescriptionBatch
+			}
+			batch.Queue(query, t.ID, description)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("update behavior texts: %w", err)
+		}
+	}
+
+	if err := queries.UpdateSpecDocumentText(ctx, db.UpdateSpecDocumentTextParams{
+		EncryptionKeyID:  keyID,
+		ExecutiveSummary: pgtype.Text{String: summary, Valid: doc.ExecutiveSummary.Valid},
+		ID:               documentID,
+	}); err != nil {
+		return fmt.Errorf("update spec document: %w", err)
+	}
+	if _, err := queries.DeleteSpecSearchEntriesByDocumentID(ctx, documentID); err != nil {
+		return fmt.Errorf("delete spec search entries: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// sealer returns the key new documents of the user are sealed with and its ID,
+// or a nil encryptor when the user's tenant stores documents in plaintext.
+// Safe to call on nil; a tenant that encrypts its documents then fails with
+// ErrEncryptionUnavailable.
+func (k *DocumentKeyring) sealer(ctx context.Context, queries *db.Queries, userID pgtype.UUID) (pgtype.UUID, crypto.Encryptor, error) {
+	setting, err := queries.GetUserDocumentEncryption(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.UUID{}, nil, nil
+		}
+		return pgtype.UUID{}, nil, fmt.Errorf("get user document encryption: %w", err)
+	}
+	if !setting.DocumentEncryption {
+		return pgtype.UUID{}, nil, nil
+	}
+	if k == nil {
+		return pgtype.UUID{}, nil, fmt.Errorf("%w: tenant %s encrypts its documents",
+			specview.ErrEncryptionUnavailable, fromPgUUID(setting.TenantID))
+	}
+	return k.activeKey(ctx, queries, setting.TenantID)
+}
+
+// opener returns the key a document was sealed with, or nil for a plaintext
+// document. Safe to call on nil; a sealed document then fails with
+// ErrEncryptionUnavailable.
+func (k *DocumentKeyring) opener(ctx context.Context, queries *db.Queries, keyID pgtype.UUID) (crypto.Encryptor, error) {
+	if !keyID.Valid {
+		return nil, nil
+	}
+	if k == nil {
+		return nil, fmt.Errorf("%w: document is sealed with key %s", specview.ErrEncryptionUnavailable, fromPgUUID(keyID))
+	}
+
+	k.mu.Lock()
+	enc, ok := k.keys[keyID]
+	k.mu.Unlock()
+	if ok {
+		return enc, nil
+	}
+
+	wrapped, err := queries.GetTenantDocumentKey(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant document key: %w", err)
+	}
+	return k.dataKey(keyID, wrapped)
+}
+
+// activeKey returns the tenant's active data key, creating it if there is none.
+func (k *DocumentKeyring) activeKey(ctx context.Context, queries *db.Queries, tenantID pgtype.UUID) (pgtype.UUID, crypto.Encryptor, error) {
+	active, err := queries.GetActiveTenantDocumentKey(ctx, tenantID)
+	if err == nil {
+		enc, err := k.dataKey(active.ID, active.WrappedKey)
+		return active.ID, enc, err
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return pgtype.UUID{}, nil, fmt.Errorf("get active tenant document key: %w", err)
+	}
+
+	wrapped, err := k.newDataKey()
+	if err != nil {
+		return pgtype.UUID{}, nil, err
+	}
+	id, err := queries.InsertTenantDocumentKey(ctx, db.InsertTenantDocumentKeyParams{
+		TenantID:   tenantID,
+		WrappedKey: wrapped,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Created concurrently; use the winner's key.
+		active, err = queries.GetActiveTenantDocumentKey(ctx, tenantID)
+		if err != nil {
+			return pgtype.UUID{}, nil, fmt.Errorf("get active tenant document key: %w", err)
+		}
+		id, wrapped = active.ID, active.WrappedKey
+	} else if err != nil {
+		return pgtype.UUID{}, nil, fmt.Errorf("insert tenant document key: %w", err)
+	}
+
+	enc, err := k.dataKey(id, wrapped)
+	return id, enc, err
+}
+
+// dataKey unwraps a data key and caches it by ID. Rewrapping changes the
+// wrapping but not the key, so cached keys never go stale.
+func (k *DocumentKeyring) dataKey(id pgtype.UUID, wrapped string) (crypto.Encryptor, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if enc, ok := k.keys[id]; ok {
+		return enc, nil
+	}
+	raw, _, err := k.unwrap(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("document key %s: %w", fromPgUUID(id), err)
+	}
+	enc, err := crypto.NewEncryptorFromBase64(raw)
+	if err != nil {
+		return nil, fmt.Errorf("document key %s: %w", fromPgUUID(id), err)
+	}
+	k.keys[id] = enc
+	return enc, nil
+}
+
+// unwrap returns the base64 data key and whether it is wrapped with the
+// previous master key.
+func (k *DocumentKeyring) unwrap(wrapped string) (string, bool, error) {
+	raw, err := k.master.Decrypt(wrapped)
+	if err == nil {
+		return raw, false, nil
+	}
+	if k.previous != nil {
+		if raw, prevErr := k.previous.Decrypt(wrapped); prevErr == nil {
+			return raw, true, nil
+		}
+	}
+	return "", false, fmt.Errorf("unwrap: %w", err)
+}
+
+func (k *DocumentKeyring) newDataKey() (string, error) {
+	key := make([]byte, crypto.KeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("generate document key: %w", err)
+	}
+	wrapped, err := k.master.Encrypt(base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		return "", fmt.Errorf("wrap document key: %w", err)
+	}
+	return wrapped, nil
+}
+
+// DocumentOption configures a repository reading or writing spec document text.
+type DocumentOption func(*documentOptions)
+
+type documentOptions struct {
+	keyring *DocumentKeyring
+}
+
+// WithDocumentKeyring seals and opens the text of documents of tenants that
+// encrypt their documents. Without it, saving such a document and reading a
+// sealed one fail with ErrEncryptionUnavailable. Nil is ignored.
+func WithDocumentKeyring(keyring *DocumentKeyring) DocumentOption {
+	return func(o *documentOptions) {
+		if keyring != nil {
+			o.keyring = keyring
+		}
+	}
+}
+
+func applyDocumentOptions(opts []DocumentOption) documentOptions {
+	var o documentOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// openDocument maps a document row and its content rows, opening sealed text.
+func openDocument(
+	ctx context.Context,
+	keyring *DocumentKeyring,
+	queries *db.Queries,
+	row db.SpecDocument,
+	content []db.GetSpecDocumentContentRow,
+) (*specview.SpecDocument, error) {
+	doc := toDomainSpecDocument(row)
+	if content != nil {
+		doc.Domains = toDomainDocumentContent(content)
+	}
+
+	enc, err := keyring.opener(ctx, queries, row.EncryptionKeyID)
+	if err != nil || enc == nil {
+		return doc, err
+	}
+
+	if doc.ExecutiveSummary, err = openText(enc, doc.ExecutiveSummary); err != nil {
+		return nil, err
+	}
+	for i := range doc.Domains {
+		for j := range doc.Domains[i].Features {
+			behaviors := doc.Domains[i].Features[j].Behaviors
+			for k := range behaviors {
+				if behaviors[k].Description, err = openText(enc, behaviors[k].Description); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return doc, nil
+}
+
+// sealText encrypts text with enc. Empty text, and any text when enc is nil,
+// is returned unchanged.
+func sealText(enc crypto.Encryptor, text string) (string, error) {
+	if enc == nil || text == "" {
+		return text, nil
+	}
+	sealed, err := enc.Encrypt(text)
+	if err != nil {
+		return "", fmt.Errorf("seal document text: %w", err)
+	}
+	return sealed, nil
+}
+
+// openText decrypts text sealed by sealText with the same enc.
+func openText(enc crypto.Encryptor, text string) (string, error) {
+	if enc == nil || text == "" {
+		return text, nil
+	}
+	opened, err := enc.Decrypt(text)
+	if err != nil {
+		return "", fmt.Errorf("open document text: %w", err)
+	}
+	return opened, nil
+}
+
+func resealText(old, enc crypto.Encryptor, text string) (string, error) {
+	opened, err := openText(old, text)
+	if err != nil {
+		return "", err
+	}
+	return sealText(enc, opened)
+}
@@ -0,0 +1,241 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/specvital/core/pkg/crypto"
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func testMasterKey(t *testing.T, b byte) crypto.Encryptor {
+	t.Helper()
+
+	enc, err := crypto.NewEncryptorFromBase64(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, crypto.KeyLength)))
+	if err != nil {
+		t.Fatalf("create master key: %v", err)
+	}
+	return enc
+}
+
+func TestDocumentKeyring_Unwrap(t *testing.T) {
+	old := NewDocumentKeyring(nil, testMasterKey(t, 1))
+	wrapped, err := old.newDataKey()
+	if err != nil {
+		t.Fatalf("newDataKey failed: %v", err)
+	}
+
+	rotated := NewDocumentKeyring(nil, testMasterKey(t, 2), WithPreviousMasterKey(testMasterKey(t, 1)))
+	if _, previous, err := rotated.unwrap(wrapped); err != nil || !previous {
+		t.Errorf("unwrap with previous master key = %v, %v, want previous", previous, err)
+	}
+
+	if _, _, err := NewDocumentKeyring(nil, testMasterKey(t, 2)).unwrap(wrapped); err == nil {
+		t.Error("expected error without the previous master key")
+	}
+}
+
+func TestDocumentText(t *testing.T) {
+	enc := testMasterKey(t, 3)
+
+	if got, err := sealText(nil, "plain"); err != nil || got != "plain" {
+		t.Errorf("sealText(nil) = %q, %v, want plaintext", got, err)
+	}
+	if got, err := sealText(enc, ""); err != nil || got != "" {
+		t.Errorf("sealText(empty) = %q, %v, want empty", got, err)
+	}
+
+	sealed, err := sealText(enc, "Should log in")
+	if err != nil || sealed == "Should log in" {
+		t.Fatalf("sealText = %q, %v, want ciphertext", sealed, err)
+	}
+	if opened, err := openText(enc, sealed); err != nil || opened != "Should log in" {
+		t.Errorf("openText = %q, %v, want original text", opened, err)
+	}
+	if _, err := openText(testMasterKey(t, 4), sealed); err == nil {
+		t.Error("expected error opening with another key")
+	}
+}
+
+func TestDocumentKeyring_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	keyring := NewDocumentKeyring(pool, testMasterKey(t, 1))
+	specRepo := NewSpecDocumentRepository(pool, WithDocumentKeyring(keyring))
+	plainRepo := NewSpecDocumentRepository(pool)
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, NewAnalysisRepository(pool), pool)
+	userID := setupTestUser(t, ctx, pool)
+
+	var codebaseID, tenantID string
+	if err := pool.QueryRow(ctx, "SELECT codebase_id::text FROM analyses WHERE id = $1", analysisID.String()).Scan(&codebaseID); err != nil {
+		t.Fatalf("failed to get codebase: %v", err)
+	}
+	if err := pool.QueryRow(ctx, "INSERT INTO tenants (name) VALUES ('acme') RETURNING id::text").Scan(&tenantID); err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "INSERT INTO tenant_members (tenant_id, user_id) VALUES ($1, $2)", tenantID, userID); err != nil {
+		t.Fatalf("failed to add member: %v", err)
+	}
+
+	newDoc := func(hash, description string) *specview.SpecDocument {
+		return &specview.SpecDocument{
+			AnalysisID:       analysisID.String(),
+			ContentHash:      []byte(hash),
+			ExecutiveSummary: "Login works",
+			Language:         "English",
+			ModelID:          "gemini-2.5-flash",
+			UserID:           userID,
+			Domains: []specview.Domain{{
+				Name: "Auth",
+				Features: []specview.Feature{{
+					Name: "Login",
+					Behaviors: []specview.Behavior{{
+						Description:  description,
+						Merged:       []specview.MergedTestCase{{Description: description + " again", OriginalName: "TestLoginAgain", Similarity: 0.95}},
+						OriginalName: "TestLogin",
+					}},
+				}},
+			}},
+		}
+	}
+	storedText := func(t *testing.T, documentID string) (summary, behavior, merged string) {
+		t.Helper()
+		if err := pool.QueryRow(ctx, `
+			SELECT sd.executive_summary, sb.converted_description, mg.converted_description
+			FROM spec_documents sd
+			JOIN spec_domains dom ON dom.document_id = sd.id
+			JOIN spec_features sf ON sf.domain_id = dom.id
+			JOIN spec_behaviors sb ON sb.feature_id = sf.id
+			JOIN spec_behavior_merges mg ON mg.behavior_id = sb.id
+			WHERE sd.id = $1
+		`, documentID).Scan(&summary, &behavior, &merged); err != nil {
+			t.Fatalf("failed to read stored text: %v", err)
+		}
+		return summary, behavior, merged
+	}
+
+	plain := newDoc("plain-hash", "Should log in")
+	if err := specRepo.SaveDocument(ctx, plain); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+	if summary, _, _ := storedText(t, plain.ID); summary != "Login works" {
+		t.Fatalf("document of a tenant without encryption should be plaintext, got %q", summary)
+	}
+
+	if err := keyring.SetTenantEncryption(ctx, tenantID, true); err != nil {
+		t.Fatalf("SetTenantEncryption failed: %v", err)
+	}
+
+	sealed := newDoc("sealed-hash", "Should log in with SSO")
+
+	t.Run("should refuse to save plaintext without a keyring", func(t *testing.T) {
+		if err := plainRepo.SaveDocument(ctx, newDoc("refused-hash", "x")); !errors.Is(err, specview.ErrEncryptionUnavailable) {
+			t.Errorf("expected ErrEncryptionUnavailable, got %v", err)
+		}
+	})
+
+	t.Run("should seal stored text and open it on read", func(t *testing.T) {
+		if err := specRepo.SaveDocument(ctx, sealed); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+		summary, behavior, merged := storedText(t, sealed.ID)
+		if summary == "Login works" || behavior == "Should log in with SSO" || merged == "Should log in with SSO again" {
+			t.Errorf("stored text is plaintext: %q, %q, %q", summary, behavior, merged)
+		}
+
+		doc, err := specRepo.GetDocumentAsOf(ctx, userID, codebaseID, "English", time.Now())
+		if err != nil {
+			t.Fatalf("GetDocumentAsOf failed: %v", err)
+		}
+		if doc == nil || doc.ID != sealed.ID || doc.ExecutiveSummary != "Login works" {
+			t.Fatalf("expected the sealed document opened, got %+v", doc)
+		}
+		if got := doc.Domains[0].Features[0].Behaviors[0].Description; got != "Should log in with SSO" {
+			t.Errorf("behavior description = %q, want opened text", got)
+		}
+
+		if _, err := plainRepo.GetDocumentAsOf(ctx, userID, codebaseID, "English", time.Now()); !errors.Is(err, specview.ErrEncryptionUnavailable) {
+			t.Errorf("expected ErrEncryptionUnavailable without a keyring, got %v", err)
+		}
+	})
+
+	t.Run("should reseal every document on rotation", func(t *testing.T) {
+		var oldKeyID string
+		if err := pool.QueryRow(ctx, "SELECT encryption_key_id::text FROM spec_documents WHERE id = $1", sealed.ID).Scan(&oldKeyID); err != nil {
+			t.Fatalf("failed to read key: %v", err)
+		}
+		_, oldBehavior, _ := storedText(t, sealed.ID)
+
+		n, err := keyring.RotateTenantKey(ctx, tenantID)
+		if err != nil {
+			t.Fatalf("RotateTenantKey failed: %v", err)
+		}
+		if n != 2 {
+			t.Errorf("resealed %d documents, want the sealed and the plaintext one", n)
+		}
+
+		var sealedWithOld int
+		if err := pool.QueryRow(ctx, `
+			SELECT count(*) FROM spec_documents WHERE encryption_key_id IS NULL OR encryption_key_id = $1
+		`, oldKeyID).Scan(&sealedWithOld); err != nil {
+			t.Fatalf("failed to count documents: %v", err)
+		}
+		if sealedWithOld != 0 {
+			t.Errorf("%d documents still plaintext or sealed with the retired key", sealedWithOld)
+		}
+		if _, behavior, _ := storedText(t, sealed.ID); behavior == oldBehavior || behavior == "Should log in with SSO" {
+			t.Errorf("behavior was not resealed: %q", behavior)
+		}
+
+		fresh := NewSpecDocumentRepository(pool, WithDocumentKeyring(NewDocumentKeyring(pool, testMasterKey(t, 1))))
+		doc, err := fresh.FindDocumentByContentHash(ctx, userID, []byte("plain-hash"), "English", "gemini-2.5-flash")
+		if err != nil || doc == nil || doc.ExecutiveSummary != "Login works" {
+			t.Errorf("expected the resealed plaintext document to open, got %+v, %v", doc, err)
+		}
+	})
+
+	t.Run("should rewrap keys after a master key change", func(t *testing.T) {
+		rotated := NewDocumentKeyring(pool, testMasterKey(t, 2), WithPreviousMasterKey(testMasterKey(t, 1)))
+
+		n, err := rotated.Rewrap(ctx)
+		if err != nil {
+			t.Fatalf("Rewrap failed: %v", err)
+		}
+		if n != 2 {
+			t.Errorf("rewrapped %d keys, want the active and the retired one", n)
+		}
+
+		repo := NewSpecDocumentRepository(pool, WithDocumentKeyring(NewDocumentKeyring(pool, testMasterKey(t, 2))))
+		doc, err := repo.GetDocumentAsOf(ctx, userID, codebaseID, "English", time.Now())
+		if err != nil || doc == nil || doc.ExecutiveSummary != "Login works" {
+			t.Errorf("expected the document to open with the new master key alone, got %+v, %v", doc, err)
+		}
+	})
+
+	t.Run("should not index or share sealed documents", func(t *testing.T) {
+		entries, err := NewSpecSearchRepository(pool).GetSearchIndexEntries(ctx, sealed.ID)
+		if err != nil {
+			t.Fatalf("GetSearchIndexEntries failed: %v", err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("expected no search entries, got %d", len(entries))
+		}
+
+		doc, err := plainRepo.FindShareableDocument(ctx, setupTestUser(t, ctx, pool), analysisID.String(), []byte("sealed-hash"), "English", "gemini-2.5-flash")
+		if err != nil || doc != nil {
+			t.Errorf("expected no shareable document, got %+v, %v", doc, err)
+		}
+	})
+}
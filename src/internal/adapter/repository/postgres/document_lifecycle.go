@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ specview.DocumentLifecycleRepository = (*DocumentLifecycleRepository)(nil)
+
+// DocumentLifecycleRepository soft-deletes and restores spec document versions.
+type DocumentLifecycleRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDocumentLifecycleRepository creates a new DocumentLifecycleRepository.
+func NewDocumentLifecycleRepository(pool *pgxpool.Pool) *DocumentLifecycleRepository {
+	return &DocumentLifecycleRepository{pool: pool}
+}
+
+func (r *DocumentLifecycleRepository) SoftDeleteDocument(ctx context.Context, documentID, deletedBy string) (*specview.DocumentDeletion, error) {
+	parsedID, err := analysis.ParseUUID(documentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+	pin, err := queries.GetSpecDocumentPin(ctx, toPgUUID(parsedID))
+	if err == nil {
+		return nil, fmt.Errorf("%w to release %s", specview.ErrDocumentPinned, pin.ReleaseTag)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("get document pin: %w", err)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer rollbackLifecycle(ctx, tx, "SoftDeleteDocument", documentID)
+
+	queries = db.New(tx)
+	row, err := queries.SoftDeleteSpecDocument(ctx, db.SoftDeleteSpecDocumentParams{
+		DeletedBy:  pgtype.Text{String: deletedBy, Valid: deletedBy != ""},
+		DocumentID: toPgUUID(parsedID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, specview.ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("soft delete document: %w", err)
+	}
+	// Deleted documents must not show up in search.
+	if _, err := queries.DeleteSpecSearchEntriesByDocumentID(ctx, toPgUUID(parsedID)); err != nil {
+		return nil, fmt.Errorf("delete spec search entries: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	return &specview.DocumentDeletion{
+		DeletedAt:  row.DeletedAt.Time,
+		DeletedBy:  row.DeletedBy.String,
+		DocumentID: fromPgUUID(row.ID).String(),
+	}, nil
+}
+
+func (r *DocumentLifecycleRepository) RestoreDocument(ctx context.Context, documentID string) error {
+	parsedID, err := analysis.ParseUUID(documentID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer rollbackLifecycle(ctx, tx, "RestoreDocument", documentID)
+
+	queries := db.New(tx)
+	n, err := queries.RestoreSpecDocument(ctx, toPgUUID(parsedID))
+	if err != nil {
+		return fmt.Errorf("restore document: %w", err)
+	}
+	if n == 0 {
+		return specview.ErrDocumentNotDeleted
+	}
+	// The entries were dropped on delete; rebuild them from the saved content.
+	if _, err := queries.DeleteSpecSearchEntriesByDocumentID(ctx, toPgUUID(parsedID)); err != nil {
+		return fmt.Errorf("delete spec search entries: %w", err)
+	}
+	if _, err := queries.RebuildSpecSearchEntries(ctx, toPgUUID(parsedID)); err != nil {
+		return fmt.Errorf("rebuild spec search entries: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func rollbackLifecycle(ctx context.Context, tx pgx.Tx, operation, documentID string) {
+	if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+		slog.ErrorContext(ctx, "failed to rollback transaction",
+			"operation", operation,
+			"document_id", documentID,
+			"error", err,
+		)
+	}
+}
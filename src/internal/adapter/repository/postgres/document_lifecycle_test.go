@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/retention"
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestDocumentLifecycleRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	lifecycleRepo := NewDocumentLifecycleRepository(pool)
+	pinRepo := NewDocumentPinRepository(pool)
+	retentionRepo := NewRetentionRepository(pool)
+	ctx := context.Background()
+
+	userID := setupTestUser(t, ctx, pool)
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, NewAnalysisRepository(pool), pool)
+
+	insertVersion := func(t *testing.T, version int, age string) string {
+		t.Helper()
+		var id string
+		if err := pool.QueryRow(ctx, `
+			INSERT INTO spec_documents (user_id, analysis_id, content_hash, language, model_id, version, created_at)
+			VALUES ($1, $2, 'versioned', 'English', 'gemini-2.5-flash', $3, now() - $4::interval)
+			RETURNING id::text
+		`, userID, analysisID.String(), version, age).Scan(&id); err != nil {
+			t.Fatalf("insert document version %d: %v", version, err)
+		}
+		return id
+	}
+	exists := func(t *testing.T, id string) bool {
+		t.Helper()
+		var count int
+		if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM spec_documents WHERE id = $1`, id).Scan(&count); err != nil {
+			t.Fatalf("count documents: %v", err)
+		}
+		return count == 1
+	}
+
+	t.Run("soft deletes and restores a document", func(t *testing.T) {
+		documentID := insertVersion(t, 1, "1 hour")
+
+		deletion, err := lifecycleRepo.SoftDeleteDocument(ctx, documentID, "ops")
+		if err != nil {
+			t.Fatalf("SoftDeleteDocument failed: %v", err)
+		}
+		if deletion.DeletedBy != "ops" || deletion.DeletedAt.IsZero() {
+			t.Errorf("unexpected deletion %+v", deletion)
+		}
+		again, err := lifecycleRepo.SoftDeleteDocument(ctx, documentID, "someone-else")
+		if err != nil || again.DeletedBy != "ops" {
+			t.Errorf("expected the first deletion to be kept, got %+v, %v", again, err)
+		}
+
+		if err := lifecycleRepo.RestoreDocument(ctx, documentID); err != nil {
+			t.Fatalf("RestoreDocument failed: %v", err)
+		}
+		if err := lifecycleRepo.RestoreDocument(ctx, documentID); !errors.Is(err, specview.ErrDocumentNotDeleted) {
+			t.Errorf("expected ErrDocumentNotDeleted, got %v", err)
+		}
+	})
+
+	t.Run("hides a deleted document and its search entries until restored", func(t *testing.T) {
+		specRepo := NewSpecDocumentRepository(pool)
+		searchRepo := NewSpecSearchRepository(pool)
+		doc := &specview.SpecDocument{
+			AnalysisID:  analysisID.String(),
+			ContentHash: []byte("lifecycle-search"),
+			Language:    "English",
+			ModelID:     "gemini-2.5-flash",
+			UserID:      userID,
+			Domains: []specview.Domain{{
+				Name: "Payments",
+				Features: []specview.Feature{{
+					Name:      "Refunds",
+					Behaviors: []specview.Behavior{{OriginalName: "TestRefund", Description: "Issues a refund"}},
+				}},
+			}},
+		}
+		if err := specRepo.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+		entries, err := searchRepo.GetSearchIndexEntries(ctx, doc.ID)
+		if err != nil {
+			t.Fatalf("GetSearchIndexEntries failed: %v", err)
+		}
+		if err := searchRepo.ReplaceDocumentEntries(ctx, doc.ID, entries); err != nil {
+			t.Fatalf("ReplaceDocumentEntries failed: %v", err)
+		}
+		searchEntries := func(t *testing.T) int {
+			t.Helper()
+			var count int
+			if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM spec_search_entries WHERE document_id = $1`, doc.ID).Scan(&count); err != nil {
+				t.Fatalf("count search entries: %v", err)
+			}
+			return count
+		}
+
+		if _, err := lifecycleRepo.SoftDeleteDocument(ctx, doc.ID, "ops"); err != nil {
+			t.Fatalf("SoftDeleteDocument failed: %v", err)
+		}
+		if n := searchEntries(t); n != 0 {
+			t.Errorf("expected search entries dropped on delete, got %d", n)
+		}
+		if _, err := specRepo.GetDocument(ctx, doc.ID); !errors.Is(err, specview.ErrDocumentNotFound) {
+			t.Errorf("expected a deleted document not found, got %v", err)
+		}
+		if entries, err := searchRepo.GetSearchIndexEntries(ctx, doc.ID); err != nil || len(entries) != 0 {
+			t.Errorf("expected a deleted document not indexed, got %d entries, %v", len(entries), err)
+		}
+
+		if err := lifecycleRepo.RestoreDocument(ctx, doc.ID); err != nil {
+			t.Fatalf("RestoreDocument failed: %v", err)
+		}
+		if n := searchEntries(t); n != 1 {
+			t.Errorf("expected search entries rebuilt on restore, got %d", n)
+		}
+		if _, err := specRepo.GetDocument(ctx, doc.ID); err != nil {
+			t.Errorf("expected a restored document found, got %v", err)
+		}
+	})
+
+	t.Run("rejects pinned and unknown documents", func(t *testing.T) {
+		documentID := insertVersion(t, 1, "1 hour")
+		if _, err := pinRepo.PinDocument(ctx, documentID, "v2.0.0", ""); err != nil {
+			t.Fatalf("PinDocument failed: %v", err)
+		}
+		if _, err := lifecycleRepo.SoftDeleteDocument(ctx, documentID, ""); !errors.Is(err, specview.ErrDocumentPinned) {
+			t.Errorf("expected ErrDocumentPinned, got %v", err)
+		}
+		if _, err := lifecycleRepo.SoftDeleteDocument(ctx, "00000000-0000-0000-0000-000000000001", ""); !errors.Is(err, specview.ErrDocumentNotFound) {
+			t.Errorf("expected ErrDocumentNotFound, got %v", err)
+		}
+		if _, err := lifecycleRepo.SoftDeleteDocument(ctx, "not-a-uuid", ""); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("retention purges soft-deleted documents after the grace period", func(t *testing.T) {
+		documentID := insertVersion(t, 1, "1 hour")
+		if _, err := lifecycleRepo.SoftDeleteDocument(ctx, documentID, ""); err != nil {
+			t.Fatalf("SoftDeleteDocument failed: %v", err)
+		}
+
+		if _, err := retentionRepo.DeleteSoftDeletedSpecDocuments(ctx, time.Hour, 100); err != nil {
+			t.Fatalf("DeleteSoftDeletedSpecDocuments failed: %v", err)
+		}
+		if !exists(t, documentID) {
+			t.Fatal("expected the document to survive within the grace period")
+		}
+		if _, err := retentionRepo.DeleteSoftDeletedSpecDocuments(ctx, -time.Minute, 100); err != nil {
+			t.Fatalf("DeleteSoftDeletedSpecDocuments failed: %v", err)
+		}
+		if exists(t, documentID) {
+			t.Error("expected the document to be purged after the grace period")
+		}
+	})
+
+	t.Run("version policy keeps the latest versions", func(t *testing.T) {
+		if _, err := pool.Exec(ctx, `DELETE FROM spec_documents`); err != nil {
+			t.Fatalf("reset documents: %v", err)
+		}
+		oldest := insertVersion(t, 1, "40 days")
+		older := insertVersion(t, 2, "35 days")
+		latest := insertVersion(t, 3, "31 days")
+
+		deleted, err := retentionRepo.DeleteSupersededSpecDocuments(ctx, retention.NewVersionPolicy(30, 2), 100)
+		if err != nil {
+			t.Fatalf("DeleteSupersededSpecDocuments failed: %v", err)
+		}
+		if deleted.DeletedCount != 1 {
+			t.Errorf("DeletedCount = %d, want 1", deleted.DeletedCount)
+		}
+		if exists(t, oldest) || !exists(t, older) || !exists(t, latest) {
+			t.Error("expected only the oldest version to be pruned")
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ specview.DocumentPinRepository = (*DocumentPinRepository)(nil)
+
+// DocumentPinRepository pins spec document versions to releases.
+type DocumentPinRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDocumentPinRepository creates a new DocumentPinRepository.
+func NewDocumentPinRepository(pool *pgxpool.Pool) *DocumentPinRepository {
+	return &DocumentPinRepository{pool: pool}
+}
+
+func (r *DocumentPinRepository) PinDocument(ctx context.Context, documentID, releaseTag, pinnedBy string) (*specview.DocumentPin, error) {
+	parsedID, err := analysis.ParseUUID(documentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+	if err := specview.ValidateReleaseTag(releaseTag); err != nil {
+		return nil, err
+	}
+
+	row, err := db.New(r.pool).PinSpecDocument(ctx, db.PinSpecDocumentParams{
+		DocumentID: toPgUUID(parsedID),
+		PinnedBy:   pgtype.Text{String: pinnedBy, Valid: pinnedBy != ""},
+		ReleaseTag: releaseTag,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, specview.ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("pin document: %w", err)
+	}
+	if row.ReleaseTag != releaseTag {
+		return nil, fmt.Errorf("%w to release %s", specview.ErrDocumentPinned, row.ReleaseTag)
+	}
+	return toDomainDocumentPin(row), nil
+}
+
+func (r *DocumentPinRepository) UnpinDocument(ctx context.Context, documentID string) error {
+	parsedID, err := analysis.ParseUUID(documentID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+
+	n, err := db.New(r.pool).UnpinSpecDocument(ctx, toPgUUID(parsedID))
+	if err != nil {
+		return fmt.Errorf("unpin document: %w", err)
+	}
+	if n == 0 {
+		return specview.ErrDocumentNotPinned
+	}
+	return nil
+}
+
+func (r *DocumentPinRepository) GetDocumentPin(ctx context.Context, documentID string) (*specview.DocumentPin, error) {
+	parsedID, err := analysis.ParseUUID(documentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+
+	row, err := db.New(r.pool).GetSpecDocumentPin(ctx, toPgUUID(parsedID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get document pin: %w", err)
+	}
+	return toDomainDocumentPin(row), nil
+}
+
+func toDomainDocumentPin(row db.SpecDocumentPin) *specview.DocumentPin {
+	return &specview.DocumentPin{
+		DocumentID: fromPgUUID(row.DocumentID).String(),
+		PinnedAt:   row.PinnedAt.Time,
+		PinnedBy:   row.PinnedBy.String,
+		ReleaseTag: row.ReleaseTag,
+	}
+}
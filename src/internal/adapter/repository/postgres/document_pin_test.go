@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestDocumentPinRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	pinRepo := NewDocumentPinRepository(pool)
+	retentionRepo := NewRetentionRepository(pool)
+	ctx := context.Background()
+
+	userID := setupTestUser(t, ctx, pool)
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, NewAnalysisRepository(pool), pool)
+
+	var documentID, sliceID string
+	if err := pool.QueryRow(ctx, `
+		INSERT INTO spec_documents (user_id, analysis_id, content_hash, language, model_id, retention_days_at_creation, created_at)
+		VALUES ($1, $2, 'pinned', 'English', 'gemini-2.5-flash', 1, now() - interval '2 days')
+		RETURNING id::text
+	`, userID, analysisID.String()).Scan(&documentID); err != nil {
+		t.Fatalf("insert document: %v", err)
+	}
+	if err := pool.QueryRow(ctx, `
+		INSERT INTO spec_documents (user_id, analysis_id, content_hash, language, model_id, retention_days_at_creation, created_at, parent_document_id, team)
+		VALUES ($1, $2, 'pinned', 'English', 'gemini-2.5-flash', 1, now() - interval '2 days', $3, 'payments')
+		RETURNING id::text
+	`, userID, analysisID.String(), documentID).Scan(&sliceID); err != nil {
+		t.Fatalf("insert team slice: %v", err)
+	}
+
+	t.Run("pins a document once", func(t *testing.T) {
+		pin, err := pinRepo.PinDocument(ctx, documentID, "v1.4.0", "release-bot")
+		if err != nil {
+			t.Fatalf("PinDocument failed: %v", err)
+		}
+		if pin.ReleaseTag != "v1.4.0" || pin.PinnedBy != "release-bot" || pin.PinnedAt.IsZero() {
+			t.Errorf("unexpected pin %+v", pin)
+		}
+		if _, err := pinRepo.PinDocument(ctx, documentID, "v1.4.0", "someone-else"); err != nil {
+			t.Errorf("expected pinning to the same tag to succeed, got %v", err)
+		}
+		if _, err := pinRepo.PinDocument(ctx, documentID, "v1.5.0", ""); !errors.Is(err, specview.ErrDocumentPinned) {
+			t.Errorf("expected ErrDocumentPinned, got %v", err)
+		}
+		if got, err := pinRepo.GetDocumentPin(ctx, documentID); err != nil || got == nil || got.PinnedBy != "release-bot" {
+			t.Errorf("expected the first pin to be kept, got %+v, %v", got, err)
+		}
+	})
+
+	t.Run("rejects team slices and unknown documents", func(t *testing.T) {
+		if _, err := pinRepo.PinDocument(ctx, sliceID, "v1.4.0", ""); !errors.Is(err, specview.ErrDocumentNotFound) {
+			t.Errorf("expected ErrDocumentNotFound for a team slice, got %v", err)
+		}
+		if _, err := pinRepo.PinDocument(ctx, "00000000-0000-0000-0000-000000000001", "v1.4.0", ""); !errors.Is(err, specview.ErrDocumentNotFound) {
+			t.Errorf("expected ErrDocumentNotFound, got %v", err)
+		}
+		if _, err := pinRepo.PinDocument(ctx, documentID, "v1 4", ""); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for a tag with whitespace, got %v", err)
+		}
+	})
+
+	t.Run("retention keeps pinned documents and their slices", func(t *testing.T) {
+		if _, err := retentionRepo.DeleteExpiredSpecDocuments(ctx, 100); err != nil {
+			t.Fatalf("DeleteExpiredSpecDocuments failed: %v", err)
+		}
+		var count int
+		if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM spec_documents WHERE id IN ($1, $2)`, documentID, sliceID).Scan(&count); err != nil {
+			t.Fatalf("count documents: %v", err)
+		}
+		if count != 2 {
+			t.Errorf("expected the pinned document and its slice to survive, got %d", count)
+		}
+	})
+
+	t.Run("unpinned documents expire again", func(t *testing.T) {
+		if err := pinRepo.UnpinDocument(ctx, documentID); err != nil {
+			t.Fatalf("UnpinDocument failed: %v", err)
+		}
+		if err := pinRepo.UnpinDocument(ctx, documentID); !errors.Is(err, specview.ErrDocumentNotPinned) {
+			t.Errorf("expected ErrDocumentNotPinned, got %v", err)
+		}
+		if pin, err := pinRepo.GetDocumentPin(ctx, documentID); err != nil || pin != nil {
+			t.Errorf("expected no pin, got %+v, %v", pin, err)
+		}
+
+		if _, err := retentionRepo.DeleteExpiredSpecDocuments(ctx, 100); err != nil {
+			t.Fatalf("DeleteExpiredSpecDocuments failed: %v", err)
+		}
+		var count int
+		if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM spec_documents WHERE id = $1`, documentID).Scan(&count); err != nil {
+			t.Fatalf("count documents: %v", err)
+		}
+		if count != 0 {
+			t.Error("expected the unpinned document to be deleted")
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/jobadmin"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ jobadmin.Repository = (*JobAdminRepository)(nil)
+
+// JobAdminRepository reads River jobs for operators and bumps their priority.
+type JobAdminRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewJobAdminRepository creates a new JobAdminRepository.
+func NewJobAdminRepository(pool *pgxpool.Pool) *JobAdminRepository {
+	return &JobAdminRepository{pool: pool}
+}
+
+func (r *JobAdminRepository) ListJobs(ctx context.Context, filter jobadmin.Filter) ([]jobadmin.Job, error) {
+	queries := db.New(r.pool)
+	rows, err := queries.ListRiverJobs(ctx, db.ListRiverJobsParams{
+		Kinds:   nonNil(filter.Kinds),
+		MaxRows: int32(filter.Limit),
+		Queues:  nonNil(filter.Queues),
+		States:  filter.States,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+
+	jobs := make([]jobadmin.Job, len(rows))
+	for i, row := range rows {
+		jobs[i] = jobadmin.Job{
+			Attempt:     int(row.Attempt),
+			AttemptedAt: timeOrZero(row.AttemptedAt),
+			CreatedAt:   timeOrZero(row.CreatedAt),
+			Errors:      decodeAttemptErrors(row.Errors),
+			FinalizedAt: timeOrZero(row.FinalizedAt),
+			ID:          row.ID,
+			Kind:        row.Kind,
+			MaxAttempts: int(row.MaxAttempts),
+			Priority:    int(row.Priority),
+			Queue:       row.Queue,
+			ScheduledAt: timeOrZero(row.ScheduledAt),
+			Snoozes:     int(row.Snoozes),
+			State:       row.State,
+		}
+	}
+	return jobs, nil
+}
+
+func (r *JobAdminRepository) GetJob(ctx context.Context, id int64) (*jobadmin.Job, error) {
+	queries := db.New(r.pool)
+	row, err := queries.GetRiverJob(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, jobadmin.ErrNotFound
+		}
+		return nil, fmt.Errorf("get job %d: %w", id, err)
+	}
+
+	metadata := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(row.Metadata, &metadata); err != nil {
+		return nil, fmt.Errorf("decode metadata of job %d: %w", id, err)
+	}
+	var snoozes int
+	if raw, ok := metadata["snoozes"]; ok {
+		_ = json.Unmarshal(raw, &snoozes)
+	}
+
+	return &jobadmin.Job{
+		Args:        row.Args,
+		Attempt:     int(row.Attempt),
+		AttemptedAt: timeOrZero(row.AttemptedAt),
+		AttemptedBy: row.AttemptedBy,
+		CreatedAt:   timeOrZero(row.CreatedAt),
+		Errors:      decodeAttemptErrors(row.Errors),
+		FinalizedAt: timeOrZero(row.FinalizedAt),
+		ID:          row.ID,
+		Kind:        row.Kind,
+		MaxAttempts: int(row.MaxAttempts),
+		Metadata:    metadata,
+		Priority:    int(row.Priority),
+		Queue:       row.Queue,
+		ScheduledAt: timeOrZero(row.ScheduledAt),
+		Snoozes:     snoozes,
+		State:       string(row.State),
+		Tags:        row.Tags,
+	}, nil
+}
+
+func (r *JobAdminRepository) SetPriority(ctx context.Context, id int64, priority int) error {
+	queries := db.New(r.pool)
+	n, err := queries.UpdatePendingRiverJobPriority(ctx, db.UpdatePendingRiverJobPriorityParams{
+		ID:       id,
+		Priority: int16(priority),
+	})
+	if err != nil {
+		return fmt.Errorf("update priority of job %d: %w", id, err)
+	}
+	if n > 0 {
+		return nil
+	}
+
+	if _, err := queries.GetRiverJob(ctx, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return jobadmin.ErrNotFound
+		}
+		return fmt.Errorf("get job %d: %w", id, err)
+	}
+	return jobadmin.ErrJobStarted
+}
+
+// decodeAttemptErrors skips entries that do not decode; River writes them,
+// so a bad entry only costs its line in the listing.
+func decodeAttemptErrors(raw [][]byte) []jobadmin.AttemptError {
+	errs := make([]jobadmin.AttemptError, 0, len(raw))
+	for _, entry := range raw {
+		var e jobadmin.AttemptError
+		if err := json.Unmarshal(entry, &e); err == nil {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+func timeOrZero(t pgtype.Timestamptz) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return t.Time
+}
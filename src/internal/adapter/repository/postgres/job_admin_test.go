@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/jobadmin"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func insertRiverJob(t *testing.T, ctx context.Context, pool *pgxpool.Pool, state, kind, metadata string) int64 {
+	t.Helper()
+
+	var id int64
+	err := pool.QueryRow(ctx, `
+		INSERT INTO river_job (state, attempt, max_attempts, attempted_at, finalized_at, args, errors, kind, metadata, queue)
+		VALUES ($1::text::river_job_state, 1, 3,
+			CASE WHEN $1::text = 'available' THEN NULL ELSE now() END,
+			CASE WHEN $1::text IN ('cancelled', 'completed', 'discarded') THEN now() END,
+			'{"owner":"octocat"}', ARRAY['{"attempt":1,"at":"2026-10-14T09:30:00Z","error":"clone timeout"}'::jsonb],
+			$2, $3::jsonb, 'default')
+		RETURNING id
+	`, state, kind, metadata).Scan(&id)
+	if err != nil {
+		t.Fatalf("insert %s job: %v", state, err)
+	}
+	return id
+}
+
+func TestJobAdminRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewJobAdminRepository(pool)
+	ctx := context.Background()
+
+	runningID := insertRiverJob(t, ctx, pool, "running", "analysis:analyze", `{"worker":{"hostname":"w1"}}`)
+	snoozedID := insertRiverJob(t, ctx, pool, "scheduled", "specview:generate", `{"snoozes":2}`)
+	insertRiverJob(t, ctx, pool, "scheduled", "specview:generate", `{}`)
+	availableID := insertRiverJob(t, ctx, pool, "available", "analysis:analyze", `{}`)
+
+	t.Run("should list jobs by state", func(t *testing.T) {
+		jobs, err := repo.ListJobs(ctx, jobadmin.Filter{States: []string{"running", jobadmin.StateSnoozed}, Limit: 10})
+		if err != nil {
+			t.Fatalf("ListJobs failed: %v", err)
+		}
+		if len(jobs) != 2 || jobs[0].ID != snoozedID || jobs[1].ID != runningID {
+			t.Fatalf("expected the snoozed and the running job, got %+v", jobs)
+		}
+		if jobs[0].Snoozes != 2 || jobs[1].LastError() != "clone timeout" {
+			t.Errorf("snoozes = %d, last error = %q", jobs[0].Snoozes, jobs[1].LastError())
+		}
+	})
+
+	t.Run("should filter by kind", func(t *testing.T) {
+		jobs, err := repo.ListJobs(ctx, jobadmin.Filter{
+			Kinds:  []string{"specview:generate"},
+			Limit:  10,
+			States: []string{"running", "available"},
+		})
+		if err != nil {
+			t.Fatalf("ListJobs failed: %v", err)
+		}
+		if len(jobs) != 0 {
+			t.Errorf("expected no specview jobs, got %+v", jobs)
+		}
+	})
+
+	t.Run("should show a job with its metadata", func(t *testing.T) {
+		job, err := repo.GetJob(ctx, runningID)
+		if err != nil {
+			t.Fatalf("GetJob failed: %v", err)
+		}
+		if string(job.Metadata["worker"]) != `{"hostname": "w1"}` || string(job.Args) != `{"owner": "octocat"}` {
+			t.Errorf("metadata = %s, args = %s", job.Metadata["worker"], job.Args)
+		}
+
+		if _, err := repo.GetJob(ctx, 1<<40); !errors.Is(err, jobadmin.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("should only reprioritize jobs not started yet", func(t *testing.T) {
+		if err := repo.SetPriority(ctx, availableID, 2); err != nil {
+			t.Fatalf("SetPriority failed: %v", err)
+		}
+		job, err := repo.GetJob(ctx, availableID)
+		if err != nil || job.Priority != 2 {
+			t.Errorf("priority = %v, %v, want 2", job, err)
+		}
+
+		if err := repo.SetPriority(ctx, runningID, 1); !errors.Is(err, jobadmin.ErrJobStarted) {
+			t.Errorf("expected ErrJobStarted, got %v", err)
+		}
+		if err := repo.SetPriority(ctx, 1<<40, 1); !errors.Is(err, jobadmin.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
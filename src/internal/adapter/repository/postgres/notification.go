@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/notification"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ notification.Repository = (*NotificationRepository)(nil)
+
+// NotificationRepository stores notification channels and looks up what the
+// outcomes sent to them report.
+type NotificationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewNotificationRepository creates a new NotificationRepository.
+func NewNotificationRepository(pool *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{pool: pool}
+}
+
+func (r *NotificationRepository) CreateChannel(ctx context.Context, channel notification.Channel) (*notification.Channel, error) {
+	if err := channel.Validate(); err != nil {
+		return nil, err
+	}
+	codebaseID, err := parseNotificationID(channel.CodebaseID, "codebase")
+	if err != nil {
+		return nil, err
+	}
+	userID, err := parseNotificationID(channel.UserID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := db.New(r.pool).InsertNotificationChannel(ctx, db.InsertNotificationChannelParams{
+		CodebaseID: codebaseID,
+		Kind:       string(channel.Kind),
+		OnFailure:  channel.OnFailure,
+		OnSuccess:  channel.OnSuccess,
+		Url:        channel.URL,
+		UserID:     userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("insert notification channel: %w", err)
+	}
+	created := toNotificationChannel(row)
+	return &created, nil
+}
+
+func (r *NotificationRepository) DeleteChannel(ctx context.Context, id string) error {
+	channelID, err := parseNotificationID(id, "channel")
+	if err != nil {
+		return err
+	}
+	n, err := db.New(r.pool).DeleteNotificationChannel(ctx, channelID)
+	if err != nil {
+		return fmt.Errorf("delete notification channel %s: %w", id, err)
+	}
+	if n == 0 {
+		return notification.ErrNotFound
+	}
+	return nil
+}
+
+func (r *NotificationRepository) GetChannel(ctx context.Context, id string) (*notification.Channel, error) {
+	channelID, err := parseNotificationID(id, "channel")
+	if err != nil {
+		return nil, err
+	}
+	row, err := db.New(r.pool).GetNotificationChannel(ctx, channelID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, notification.ErrNotFound
+		}
+		return nil, fmt.Errorf("get notification channel %s: %w", id, err)
+	}
+	channel := toNotificationChannel(row)
+	return &channel, nil
+}
+
+func (r *NotificationRepository) ListChannels(ctx context.Context, codebaseID, userID string) ([]notification.Channel, error) {
+	codebase, err := parseNotificationID(codebaseID, "codebase")
+	if err != nil {
+		return nil, err
+	}
+	user, err := parseNotificationID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.New(r.pool).ListNotificationChannels(ctx, db.ListNotificationChannelsParams{
+		CodebaseID: codebase,
+		UserID:     user,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list notification channels: %w", err)
+	}
+	return toNotificationChannels(rows), nil
+}
+
+func (r *NotificationRepository) ChannelsFor(ctx context.Context, outcome notification.Outcome) ([]notification.Channel, error) {
+	codebaseID, err := parseNotificationID(outcome.CodebaseID, "codebase")
+	if err != nil {
+		return nil, err
+	}
+	userID, err := parseNotificationID(outcome.UserID, "user")
+	if err != nil {
+		return nil, err
+	}
+	if !codebaseID.Valid && !userID.Valid {
+		return nil, nil
+	}
+
+	rows, err := db.New(r.pool).ListNotificationChannelsForOutcome(ctx, db.ListNotificationChannelsForOutcomeParams{
+		CodebaseID: codebaseID,
+		Failed:     outcome.Failed(),
+		UserID:     userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list notification channels of job %d: %w", outcome.JobID, err)
+	}
+	return toNotificationChannels(rows), nil
+}
+
+func (r *NotificationRepository) ResolveOutcome(ctx context.Context, outcome notification.Outcome) (notification.Outcome, error) {
+	queries := db.New(r.pool)
+
+	switch outcome.Subject {
+	case notification.SubjectAnalysis:
+		row, err := queries.GetAnalysisNotificationContext(ctx, db.GetAnalysisNotificationContextParams{
+			CommitSha: outcome.CommitSHA,
+			Host:      cmp.Or(outcome.Host, "github.com"),
+			Name:      outcome.Repo,
+			Owner:     outcome.Owner,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return outcome, nil
+		}
+		if err != nil {
+			return outcome, fmt.Errorf("get notification context of analysis job %d: %w", outcome.JobID, err)
+		}
+		outcome.CodebaseID = fromPgUUID(row.CodebaseID).String()
+		if row.AnalysisID.Valid {
+			outcome.AnalysisID = fromPgUUID(row.AnalysisID).String()
+			outcome.Branch = cmp.Or(outcome.Branch, row.BranchName.String)
+			outcome.TestCount = int(row.TotalTests.Int32)
+		}
+		return outcome, nil
+
+	case notification.SubjectSpecDocument:
+		analysisID, err := analysis.ParseUUID(outcome.AnalysisID)
+		if err != nil {
+			return outcome, fmt.Errorf("%w: invalid analysis ID format", notification.ErrInvalidInput)
+		}
+		documentID, err := parseNotificationID(outcome.DocumentID, "document")
+		if err != nil {
+			return outcome, err
+		}
+		row, err := queries.GetSpecDocumentNotificationContext(ctx, db.GetSpecDocumentNotificationContextParams{
+			AnalysisID: toPgUUID(analysisID),
+			DocumentID: documentID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return outcome, nil
+		}
+		if err != nil {
+			return outcome, fmt.Errorf("get notification context of spec document job %d: %w", outcome.JobID, err)
+		}
+		outcome.Branch = row.BranchName.String
+		outcome.CodebaseID = fromPgUUID(row.CodebaseID).String()
+		outcome.CommitSHA = row.CommitSha
+		outcome.DomainCount = int(row.DomainCount)
+		outcome.Host = row.Host
+		outcome.Owner = row.Owner
+		outcome.Repo = row.Name
+		return outcome, nil
+
+	default:
+		return outcome, fmt.Errorf("%w: unknown subject %q", notification.ErrInvalidInput, outcome.Subject)
+	}
+}
+
+// parseNotificationID maps an empty ID to NULL.
+func parseNotificationID(id, kind string) (pgtype.UUID, error) {
+	if id == "" {
+		return pgtype.UUID{}, nil
+	}
+	parsed, err := analysis.ParseUUID(id)
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("%w: invalid %s ID format", notification.ErrInvalidInput, kind)
+	}
+	return toPgUUID(parsed), nil
+}
+
+func toNotificationChannels(rows []db.NotificationChannel) []notification.Channel {
+	channels := make([]notification.Channel, len(rows))
+	for i, row := range rows {
+		channels[i] = toNotificationChannel(row)
+	}
+	return channels
+}
+
+func toNotificationChannel(row db.NotificationChannel) notification.Channel {
+	return notification.Channel{
+		CodebaseID: optionalID(row.CodebaseID),
+		CreatedAt:  row.CreatedAt.Time,
+		ID:         fromPgUUID(row.ID).String(),
+		Kind:       notification.ChannelKind(row.Kind),
+		OnFailure:  row.OnFailure,
+		OnSuccess:  row.OnSuccess,
+		URL:        row.Url,
+		UserID:     optionalID(row.UserID),
+	}
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/notification"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestNotificationRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewNotificationRepository(pool)
+	ctx := context.Background()
+
+	userID := setupTestUser(t, ctx, pool)
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, NewAnalysisRepository(pool), pool)
+
+	var codebaseID, owner, name string
+	if err := pool.QueryRow(ctx, `
+		SELECT c.id::text, c.owner, c.name FROM analyses a JOIN codebases c ON c.id = a.codebase_id WHERE a.id = $1
+	`, analysisID.String()).Scan(&codebaseID, &owner, &name); err != nil {
+		t.Fatalf("get codebase: %v", err)
+	}
+
+	codebaseChannel, err := repo.CreateChannel(ctx, notification.Channel{
+		CodebaseID: codebaseID,
+		Kind:       notification.ChannelSlack,
+		OnFailure:  true,
+		OnSuccess:  true,
+		URL:        "https://hooks.slack.com/services/T000/B000/XXXX",
+	})
+	if err != nil {
+		t.Fatalf("CreateChannel failed: %v", err)
+	}
+	userChannel, err := repo.CreateChannel(ctx, notification.Channel{
+		Kind:      notification.ChannelDiscord,
+		OnFailure: true,
+		URL:       "https://discord.com/api/webhooks/1/abc",
+		UserID:    userID,
+	})
+	if err != nil {
+		t.Fatalf("CreateChannel failed: %v", err)
+	}
+
+	t.Run("rejects invalid channels", func(t *testing.T) {
+		_, err := repo.CreateChannel(ctx, notification.Channel{
+			CodebaseID: codebaseID,
+			Kind:       notification.ChannelWebhook,
+			OnSuccess:  true,
+			URL:        "ftp://example.com",
+		})
+		if !errors.Is(err, notification.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("lists channels by owner", func(t *testing.T) {
+		channels, err := repo.ListChannels(ctx, codebaseID, "")
+		if err != nil {
+			t.Fatalf("ListChannels failed: %v", err)
+		}
+		if len(channels) != 1 || channels[0].ID != codebaseChannel.ID {
+			t.Errorf("expected the codebase channel, got %+v", channels)
+		}
+		all, err := repo.ListChannels(ctx, "", "")
+		if err != nil || len(all) != 2 {
+			t.Errorf("expected both channels, got %d, %v", len(all), err)
+		}
+	})
+
+	t.Run("resolves an analysis outcome and its channels", func(t *testing.T) {
+		outcome, err := repo.ResolveOutcome(ctx, notification.Outcome{
+			CommitSHA: "abc123def456",
+			Owner:     owner,
+			Repo:      name,
+			Subject:   notification.SubjectAnalysis,
+			UserID:    userID,
+		})
+		if err != nil {
+			t.Fatalf("ResolveOutcome failed: %v", err)
+		}
+		if outcome.CodebaseID != codebaseID || outcome.AnalysisID != analysisID.String() || outcome.Branch != "main" {
+			t.Errorf("unexpected outcome %+v", outcome)
+		}
+
+		channels, err := repo.ChannelsFor(ctx, outcome)
+		if err != nil {
+			t.Fatalf("ChannelsFor failed: %v", err)
+		}
+		if len(channels) != 1 || channels[0].ID != codebaseChannel.ID {
+			t.Errorf("expected only the codebase channel for a success, got %+v", channels)
+		}
+
+		outcome.ErrorClass = "clone_failed"
+		channels, err = repo.ChannelsFor(ctx, outcome)
+		if err != nil || len(channels) != 2 {
+			t.Errorf("expected both channels for a failure, got %d, %v", len(channels), err)
+		}
+	})
+
+	t.Run("leaves outcomes of unknown codebases as they are", func(t *testing.T) {
+		in := notification.Outcome{CommitSHA: "abc", Owner: "nobody", Repo: "nothing", Subject: notification.SubjectAnalysis}
+		out, err := repo.ResolveOutcome(ctx, in)
+		if err != nil || out != in {
+			t.Errorf("expected the outcome unchanged, got %+v, %v", out, err)
+		}
+	})
+
+	t.Run("deletes channels", func(t *testing.T) {
+		if err := repo.DeleteChannel(ctx, userChannel.ID); err != nil {
+			t.Fatalf("DeleteChannel failed: %v", err)
+		}
+		if _, err := repo.GetChannel(ctx, userChannel.ID); !errors.Is(err, notification.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if err := repo.DeleteChannel(ctx, userChannel.ID); !errors.Is(err, notification.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/outbox"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ outbox.Repository = (*OutboxRepository)(nil)
+
+// OutboxRepository hands outbox events to relays. The events themselves are
+// written by AnalysisRepository and SpecDocumentRepository, in the
+// transactions completing an analysis or storing a spec document.
+type OutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewOutboxRepository creates a new OutboxRepository.
+func NewOutboxRepository(pool *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{pool: pool}
+}
+
+func (r *OutboxRepository) ClaimEvents(ctx context.Context, limit, maxAttempts int, leaseUntil time.Time) ([]outbox.Event, error) {
+	queries := db.New(r.pool)
+	rows, err := queries.ClaimOutboxEvents(ctx, db.ClaimOutboxEventsParams{
+		LeaseUntil:  pgtype.Timestamptz{Time: leaseUntil, Valid: true},
+		MaxAttempts: int32(maxAttempts),
+		MaxEvents:   int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox events: %w", err)
+	}
+
+	events := make([]outbox.Event, len(rows))
+	for i, row := range rows {
+		events[i] = outbox.Event{
+			AggregateID: fromPgUUID(row.AggregateID).String(),
+			Attempts:    int(row.Attempts),
+			CreatedAt:   row.CreatedAt.Time,
+			ID:          fromPgUUID(row.ID).String(),
+			Payload:     row.Payload,
+			Type:        row.EventType,
+		}
+	}
+	// UPDATE ... RETURNING does not keep the claim order.
+	slices.SortFunc(events, func(a, b outbox.Event) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id string) error {
+	eventID, err := analysis.ParseUUID(id)
+	if err != nil {
+		return fmt.Errorf("invalid event ID %q: %w", id, err)
+	}
+	if err := db.New(r.pool).MarkOutboxEventPublished(ctx, toPgUUID(eventID)); err != nil {
+		return fmt.Errorf("mark outbox event %s published: %w", id, err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id string, nextAttemptAt time.Time, errMessage string) error {
+	eventID, err := analysis.ParseUUID(id)
+	if err != nil {
+		return fmt.Errorf("invalid event ID %q: %w", id, err)
+	}
+	if err := db.New(r.pool).MarkOutboxEventFailed(ctx, db.MarkOutboxEventFailedParams{
+		ID:            toPgUUID(eventID),
+		LastError:     pgtype.Text{String: errMessage, Valid: errMessage != ""},
+		NextAttemptAt: pgtype.Timestamptz{Time: nextAttemptAt, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("mark outbox event %s failed: %w", id, err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) DeleteSettled(ctx context.Context, before time.Time, maxAttempts, limit int) (int, error) {
+	n, err := db.New(r.pool).DeleteSettledOutboxEvents(ctx, db.DeleteSettledOutboxEventsParams{
+		Before:      pgtype.Timestamptz{Time: before, Valid: true},
+		MaxAttempts: int32(maxAttempts),
+		MaxRows:     int32(limit),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("delete settled outbox events: %w", err)
+	}
+	return int(n), nil
+}
+
+// insertAnalysisCompletedEvent writes the completion event of an analysis in
+// the transaction completing it, after its totals are set.
+func insertAnalysisCompletedEvent(ctx context.Context, queries *db.Queries, analysisID pgtype.UUID) error {
+	if err := queries.InsertAnalysisCompletedOutboxEvent(ctx, db.InsertAnalysisCompletedOutboxEventParams{
+		AnalysisID: analysisID,
+		EventType:  outbox.EventAnalysisCompleted,
+	}); err != nil {
+		return fmt.Errorf("insert outbox event: %w", err)
+	}
+	return nil
+}
+
+// insertSpecDocumentCompletedEvent writes the event of a stored spec document
+// in the transaction storing it.
+func insertSpecDocumentCompletedEvent(ctx context.Context, queries *db.Queries, documentID pgtype.UUID) error {
+	if err := queries.InsertSpecDocumentOutboxEvent(ctx, db.InsertSpecDocumentOutboxEventParams{
+		DocumentID: documentID,
+		EventType:  outbox.EventSpecDocumentCompleted,
+	}); err != nil {
+		return fmt.Errorf("insert outbox event: %w", err)
+	}
+	return nil
+}
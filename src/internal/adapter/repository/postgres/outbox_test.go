@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/outbox"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	repo := NewOutboxRepository(pool)
+	ctx := context.Background()
+
+	analysisID, err := analysisRepo.CreateAnalysisRecord(ctx, analysis.CreateAnalysisRecordParams{
+		Owner:          "outbox-owner",
+		Repo:           "outbox-repo",
+		CommitSHA:      "outbox-sha",
+		Branch:         "main",
+		ExternalRepoID: "outbox-id",
+		ParserVersion:  testParserVersion,
+	})
+	if err != nil {
+		t.Fatalf("CreateAnalysisRecord failed: %v", err)
+	}
+
+	t.Run("should write the completion event with the inventory", func(t *testing.T) {
+		err := analysisRepo.SaveAnalysisInventory(ctx, analysis.SaveAnalysisInventoryParams{
+			AnalysisID: analysisID,
+			Inventory: &analysis.Inventory{Files: []analysis.TestFile{{
+				Path:      "a_test.go",
+				Framework: "go-test",
+				Tests:     []analysis.Test{{Name: "TestA"}},
+			}}},
+		})
+		if err != nil {
+			t.Fatalf("SaveAnalysisInventory failed: %v", err)
+		}
+
+		events, err := repo.ClaimEvents(ctx, 10, outbox.DefaultMaxAttempts, time.Now().Add(time.Minute))
+		if err != nil {
+			t.Fatalf("ClaimEvents failed: %v", err)
+		}
+		if len(events) != 1 {
+			t.Fatalf("expected one event, got %+v", events)
+		}
+		e := events[0]
+		if e.Type != outbox.EventAnalysisCompleted || e.AggregateID != analysisID.String() || e.Attempts != 1 {
+			t.Errorf("event = %+v", e)
+		}
+		var payload struct {
+			CommitSHA  string `json:"commit_sha"`
+			Owner      string `json:"owner"`
+			TotalTests int    `json:"total_tests"`
+		}
+		if err := json.Unmarshal(e.Payload, &payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		if payload.Owner != "outbox-owner" || payload.CommitSHA != "outbox-sha" || payload.TotalTests != 1 {
+			t.Errorf("payload = %s", e.Payload)
+		}
+
+		again, err := repo.ClaimEvents(ctx, 10, outbox.DefaultMaxAttempts, time.Now().Add(time.Minute))
+		if err != nil || len(again) != 0 {
+			t.Errorf("expected the leased event not to be claimed again, got %+v, %v", again, err)
+		}
+
+		if err := repo.MarkFailed(ctx, e.ID, time.Now().Add(-time.Second), "sink down"); err != nil {
+			t.Fatalf("MarkFailed failed: %v", err)
+		}
+		retried, err := repo.ClaimEvents(ctx, 10, outbox.DefaultMaxAttempts, time.Now().Add(time.Minute))
+		if err != nil || len(retried) != 1 || retried[0].Attempts != 2 {
+			t.Fatalf("expected the failed event to be due again, got %+v, %v", retried, err)
+		}
+
+		if err := repo.MarkPublished(ctx, e.ID); err != nil {
+			t.Fatalf("MarkPublished failed: %v", err)
+		}
+		n, err := repo.DeleteSettled(ctx, time.Now().Add(time.Second), outbox.DefaultMaxAttempts, 10)
+		if err != nil || n != 1 {
+			t.Errorf("expected the published event to be purged, got %d, %v", n, err)
+		}
+	})
+
+	t.Run("should not write an event when completing fails", func(t *testing.T) {
+		duplicateID, err := analysisRepo.CreateAnalysisRecord(ctx, analysis.CreateAnalysisRecordParams{
+			Owner:          "outbox-owner",
+			Repo:           "outbox-repo",
+			CommitSHA:      "outbox-sha",
+			Branch:         "main",
+			ExternalRepoID: "outbox-id",
+			ParserVersion:  testParserVersion,
+		})
+		if err != nil {
+			t.Fatalf("CreateAnalysisRecord failed: %v", err)
+		}
+		err = analysisRepo.FinalizeAnalysis(ctx, analysis.FinalizeAnalysisParams{AnalysisID: duplicateID})
+		if !errors.Is(err, analysis.ErrAlreadyCompleted) {
+			t.Fatalf("expected ErrAlreadyCompleted, got %v", err)
+		}
+
+		var count int
+		if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM outbox_events").Scan(&count); err != nil {
+			t.Fatalf("count events: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("expected the rolled back transaction to leave no event, got %d", count)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/curation"
+	"github.com/specvital/worker/internal/domain/parsercompat"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ parsercompat.Repository = (*ParserCompatRepository)(nil)
+
+// ParserCompatRepository samples stored analyses and persists compatibility reports.
+type ParserCompatRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewParserCompatRepository creates a new ParserCompatRepository.
+func NewParserCompatRepository(pool *pgxpool.Pool) *ParserCompatRepository {
+	return &ParserCompatRepository{pool: pool}
+}
+
+// parserCompatDetail is one entry of parser_compat_reports.details.
+type parserCompatDetail struct {
+	parsercompat.Result
+	BaselineVersion string `json:"baseline_version"`
+	CommitSHA       string `json:"commit_sha"`
+	Owner           string `json:"owner"`
+	Repo            string `json:"repo"`
+}
+
+func (r *ParserCompatRepository) ListSamples(ctx context.Context, candidateVersion string, limit int) ([]parsercompat.Sample, error) {
+	queries := db.New(r.pool)
+	rows, err := queries.ListParserCompatSamples(ctx, db.ListParserCompatSamplesParams{
+		CandidateVersion: candidateVersion,
+		MaxRows:          int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list parser compat samples: %w", err)
+	}
+
+	samples := make([]parsercompat.Sample, len(rows))
+	for i, row := range rows {
+		samples[i] = parsercompat.Sample{
+			AnalysisID:    fromPgUUID(row.AnalysisID),
+			CommitSHA:     row.CommitSha,
+			Owner:         row.Owner,
+			ParserVersion: row.ParserVersion,
+			Repo:          row.Repo,
+		}
+		if len(row.CurationRules) > 0 {
+			var rules curation.Rules
+			if err := json.Unmarshal(row.CurationRules, &rules); err != nil {
+				return nil, fmt.Errorf("unmarshal curation rules for %s/%s: %w", row.Owner, row.Repo, err)
+			}
+			samples[i].Rules = &rules
+		}
+	}
+	return samples, nil
+}
+
+func (r *ParserCompatRepository) GetFileTestCounts(ctx context.Context, analysisID analysis.UUID) (map[string]int, error) {
+	queries := db.New(r.pool)
+	rows, err := queries.GetFileTestCounts(ctx, toPgUUID(analysisID))
+	if err != nil {
+		return nil, fmt.Errorf("get file test counts: %w", err)
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.FilePath] = int(row.TestCount)
+	}
+	return counts, nil
+}
+
+// SaveReport inserts the report and sets its ID and CreatedAt.
+func (r *ParserCompatRepository) SaveReport(ctx context.Context, report *parsercompat.Report) error {
+	if report == nil {
+		return fmt.Errorf("%w: report is required", parsercompat.ErrInvalidInput)
+	}
+
+	details := make([]parserCompatDetail, len(report.Results))
+	for i, res := range report.Results {
+		details[i] = parserCompatDetail{
+			BaselineVersion: res.Sample.ParserVersion,
+			CommitSHA:       res.Sample.CommitSHA,
+			Owner:           res.Sample.Owner,
+			Repo:            res.Sample.Repo,
+			Result:          res,
+		}
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("marshal report details: %w", err)
+	}
+
+	queries := db.New(r.pool)
+	row, err := queries.InsertParserCompatReport(ctx, db.InsertParserCompatReportParams{
+		CandidateVersion: report.CandidateVersion,
+		Details:          data,
+		Failed:           int32(report.Count(parsercompat.StatusFailed)),
+		Matched:          int32(report.Count(parsercompat.StatusMatch)),
+		Mismatched:       int32(report.Count(parsercompat.StatusMismatch)),
+		SampleSize:       int32(len(report.Results)),
+		Skipped:          int32(report.Count(parsercompat.StatusSkipped)),
+	})
+	if err != nil {
+		return fmt.Errorf("insert parser compat report: %w", err)
+	}
+
+	report.ID = fromPgUUID(row.ID)
+	if row.CreatedAt.Valid {
+		report.CreatedAt = row.CreatedAt.Time
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/parsercompat"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestParserCompatRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	repo := NewParserCompatRepository(pool)
+	ctx := context.Background()
+
+	wrapper := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	analysisID := analysis.UUID(wrapper.id)
+
+	t.Run("should sample analyses from other parser versions", func(t *testing.T) {
+		samples, err := repo.ListSamples(ctx, "v2.0.0", 10)
+		if err != nil {
+			t.Fatalf("ListSamples failed: %v", err)
+		}
+		if len(samples) != 1 || samples[0].AnalysisID != analysisID {
+			t.Fatalf("expected the stored analysis, got %+v", samples)
+		}
+		if samples[0].ParserVersion != "v1.0.0-test" || samples[0].Owner != "testowner" {
+			t.Errorf("unexpected sample: %+v", samples[0])
+		}
+
+		samples, err = repo.ListSamples(ctx, "v1.0.0-test", 10)
+		if err != nil {
+			t.Fatalf("ListSamples failed: %v", err)
+		}
+		if len(samples) != 0 {
+			t.Errorf("expected analyses from the candidate version to be excluded, got %d", len(samples))
+		}
+	})
+
+	t.Run("should count stored tests per file", func(t *testing.T) {
+		counts, err := repo.GetFileTestCounts(ctx, analysisID)
+		if err != nil {
+			t.Fatalf("GetFileTestCounts failed: %v", err)
+		}
+		if counts["src/user_test.go"] != 2 {
+			t.Errorf("expected 2 tests in src/user_test.go, got %+v", counts)
+		}
+	})
+
+	t.Run("should count the variants of collapsed tests", func(t *testing.T) {
+		_, err := pool.Exec(ctx, `
+			UPDATE test_cases SET variant_count = 3
+			WHERE id = (
+				SELECT tc.id FROM test_cases tc
+				JOIN test_suites s ON s.id = tc.suite_id
+				JOIN test_files f ON f.id = s.file_id
+				WHERE f.analysis_id = $1 AND f.file_path = 'src/user_test.go'
+				LIMIT 1
+			)`, toPgUUID(analysisID))
+		if err != nil {
+			t.Fatalf("collapse test: %v", err)
+		}
+
+		counts, err := repo.GetFileTestCounts(ctx, analysisID)
+		if err != nil {
+			t.Fatalf("GetFileTestCounts failed: %v", err)
+		}
+		if counts["src/user_test.go"] != 4 {
+			t.Errorf("expected 4 test variants in src/user_test.go, got %+v", counts)
+		}
+	})
+
+	t.Run("should save report with summary counts", func(t *testing.T) {
+		report := &parsercompat.Report{
+			CandidateVersion: "v2.0.0",
+			Results: []parsercompat.Result{
+				{Status: parsercompat.StatusMatch},
+				{Status: parsercompat.StatusMismatch, FileDiffs: []parsercompat.FileDiff{{Baseline: 2, Candidate: 1, Path: "a_test.go"}}},
+			},
+		}
+
+		if err := repo.SaveReport(ctx, report); err != nil {
+			t.Fatalf("SaveReport failed: %v", err)
+		}
+		if report.ID == analysis.NilUUID || report.CreatedAt.IsZero() {
+			t.Errorf("expected ID and CreatedAt to be set, got %+v", report)
+		}
+
+		var matched, mismatched int
+		err := pool.QueryRow(ctx, `SELECT matched, mismatched FROM parser_compat_reports WHERE id = $1`, toPgUUID(report.ID)).
+			Scan(&matched, &mismatched)
+		if err != nil {
+			t.Fatalf("query report: %v", err)
+		}
+		if matched != 1 || mismatched != 1 {
+			t.Errorf("expected 1 matched and 1 mismatched, got %d/%d", matched, mismatched)
+		}
+	})
+}
@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/regen"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ regen.Repository = (*RegenCampaignRepository)(nil)
+
+// RegenCampaignRepository stores bulk regeneration campaigns.
+type RegenCampaignRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRegenCampaignRepository creates a new RegenCampaignRepository.
+func NewRegenCampaignRepository(pool *pgxpool.Pool) *RegenCampaignRepository {
+	return &RegenCampaignRepository{pool: pool}
+}
+
+func (r *RegenCampaignRepository) CreateCampaign(
+	ctx context.Context,
+	name string,
+	selection regen.Selection,
+	throttle regen.Throttle,
+) (*regen.Campaign, error) {
+	selectionJSON, err := json.Marshal(selection)
+	if err != nil {
+		return nil, fmt.Errorf("marshal selection: %w", err)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "CreateCampaign",
+				"error", rbErr,
+			)
+		}
+	}()
+
+	queries := db.New(tx)
+
+	id, err := queries.InsertRegenCampaign(ctx, db.InsertRegenCampaignParams{
+		MaxInFlight:   int32(throttle.MaxInFlight),
+		Name:          name,
+		RatePerMinute: int32(throttle.RatePerMinute),
+		Selection:     selectionJSON,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("insert campaign: %w", err)
+	}
+
+	if _, err := queries.InsertRegenCampaignTargets(ctx, db.InsertRegenCampaignTargetsParams{
+		CampaignID:    id,
+		CreatedBefore: pgtype.Timestamptz{Time: selection.CreatedBefore, Valid: true},
+		Language:      selection.Language,
+		MaxRows:       int32(selection.Limit),
+		ModelID:       selection.ModelID,
+	}); err != nil {
+		return nil, fmt.Errorf("insert campaign targets: %w", err)
+	}
+
+	row, err := queries.GetRegenCampaign(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get created campaign: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	return toDomainCampaign(db.ListRegenCampaignsRow(row)), nil
+}
+
+func (r *RegenCampaignRepository) GetCampaign(ctx context.Context, id string) (*regen.Campaign, error) {
+	parsedID, err := analysis.ParseUUID(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid campaign ID format", regen.ErrInvalidInput)
+	}
+
+	row, err := db.New(r.pool).GetRegenCampaign(ctx, toPgUUID(parsedID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, regen.ErrNotFound
+		}
+		return nil, fmt.Errorf("get campaign: %w", err)
+	}
+	return toDomainCampaign(db.ListRegenCampaignsRow(row)), nil
+}
+
+func (r *RegenCampaignRepository) ListCampaigns(ctx context.Context) ([]regen.Campaign, error) {
+	rows, err := db.New(r.pool).ListRegenCampaigns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list campaigns: %w", err)
+	}
+
+	campaigns := make([]regen.Campaign, len(rows))
+	for i, row := range rows {
+		campaigns[i] = *toDomainCampaign(row)
+	}
+	return campaigns, nil
+}
+
+func (r *RegenCampaignRepository) ListTargets(
+	ctx context.Context,
+	campaignID string,
+	status regen.TargetStatus,
+	limit int,
+) ([]regen.Target, error) {
+	parsedID, err := analysis.ParseUUID(campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid campaign ID format", regen.ErrInvalidInput)
+	}
+
+	rows, err := db.New(r.pool).ListRegenTargetsByStatus(ctx, db.ListRegenTargetsByStatusParams{
+		CampaignID: toPgUUID(parsedID),
+		MaxRows:    int32(limit),
+		Status:     string(status),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list campaign targets: %w", err)
+	}
+
+	targets := make([]regen.Target, len(rows))
+	for i, row := range rows {
+		targets[i] = regen.Target{
+			AnalysisID: fromPgUUID(row.AnalysisID).String(),
+			CampaignID: fromPgUUID(row.CampaignID).String(),
+			DocumentID: fromPgUUID(row.DocumentID).String(),
+			Error:      row.Error.String,
+			ID:         fromPgUUID(row.ID).String(),
+			JobID:      row.JobID.Int64,
+			Language:   row.Language,
+			Status:     regen.TargetStatus(row.Status),
+			TeamSlices: row.TeamSlices,
+			UserID:     fromPgUUID(row.UserID).String(),
+		}
+	}
+	return targets, nil
+}
+
+func (r *RegenCampaignRepository) MarkEnqueued(ctx context.Context, targetID string, jobID int64) error {
+	parsedID, err := analysis.ParseUUID(targetID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid target ID format", regen.ErrInvalidInput)
+	}
+
+	if err := db.New(r.pool).MarkRegenTargetEnqueued(ctx, db.MarkRegenTargetEnqueuedParams{
+		ID:    toPgUUID(parsedID),
+		JobID: pgtype.Int8{Int64: jobID, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("mark target enqueued: %w", err)
+	}
+	return nil
+}
+
+func (r *RegenCampaignRepository) MarkFailed(ctx context.Context, targetID string, reason string) error {
+	parsedID, err := analysis.ParseUUID(targetID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid target ID format", regen.ErrInvalidInput)
+	}
+
+	if err := db.New(r.pool).MarkRegenTargetFailed(ctx, db.MarkRegenTargetFailedParams{
+		Error: pgtype.Text{String: reason, Valid: true},
+		ID:    toPgUUID(parsedID),
+	}); err != nil {
+		return fmt.Errorf("mark target failed: %w", err)
+	}
+	return nil
+}
+
+func (r *RegenCampaignRepository) SyncTargets(ctx context.Context, campaignID string) (int, error) {
+	parsedID, err := analysis.ParseUUID(campaignID)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid campaign ID format", regen.ErrInvalidInput)
+	}
+
+	n, err := db.New(r.pool).SyncRegenTargets(ctx, toPgUUID(parsedID))
+	if err != nil {
+		return 0, fmt.Errorf("sync campaign targets: %w", err)
+	}
+	return int(n), nil
+}
+
+func (r *RegenCampaignRepository) UpdateStatus(ctx context.Context, id string, from, to regen.Status) error {
+	parsedID, err := analysis.ParseUUID(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid campaign ID format", regen.ErrInvalidInput)
+	}
+
+	n, err := db.New(r.pool).UpdateRegenCampaignStatus(ctx, db.UpdateRegenCampaignStatusParams{
+		FromStatus: string(from),
+		ID:         toPgUUID(parsedID),
+		ToStatus:   string(to),
+	})
+	if err != nil {
+		return fmt.Errorf("update campaign status: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: campaign is no longer %s", regen.ErrInvalidTransition, from)
+	}
+	return nil
+}
+
+func toDomainCampaign(row db.ListRegenCampaignsRow) *regen.Campaign {
+	campaign := &regen.Campaign{
+		CreatedAt: row.CreatedAt.Time,
+		ID:        fromPgUUID(row.ID).String(),
+		Name:      row.Name,
+		Progress: regen.Progress{
+			Enqueued:  int(row.Enqueued),
+			Failed:    int(row.Failed),
+			Pending:   int(row.Pending),
+			Succeeded: int(row.Succeeded),
+		},
+		Status: regen.Status(row.Status),
+		Throttle: regen.Throttle{
+			MaxInFlight:   int(row.MaxInFlight),
+			RatePerMinute: int(row.RatePerMinute),
+		},
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+	// The selection is informational; a malformed one is left empty.
+	_ = json.Unmarshal(row.Selection, &campaign.Selection)
+	return campaign
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/repopolicy"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ repopolicy.Repository = (*RepoPolicyRepository)(nil)
+
+// RepoPolicyRepository reads tenant repository policies.
+type RepoPolicyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepoPolicyRepository creates a new RepoPolicyRepository.
+func NewRepoPolicyRepository(pool *pgxpool.Pool) *RepoPolicyRepository {
+	return &RepoPolicyRepository{pool: pool}
+}
+
+// ListRulesForUser returns no rules for user IDs that are not UUIDs, which
+// cannot be tenant members.
+func (r *RepoPolicyRepository) ListRulesForUser(ctx context.Context, userID string) ([]repopolicy.Rule, error) {
+	parsed, err := analysis.ParseUUID(userID)
+	if err != nil {
+		return nil, nil
+	}
+
+	queries := db.New(r.pool)
+	rows, err := queries.ListRepoPoliciesByUser(ctx, toPgUUID(parsed))
+	if err != nil {
+		return nil, fmt.Errorf("list repo policies: %w", err)
+	}
+
+	rules := make([]repopolicy.Rule, len(rows))
+	for i, row := range rows {
+		rules[i] = repopolicy.Rule{Effect: repopolicy.Effect(row.Effect), Pattern: row.Pattern}
+	}
+	return rules, nil
+}
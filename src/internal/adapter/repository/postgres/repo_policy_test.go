@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/repopolicy"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestRepoPolicyRepository_ListRulesForUser(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewRepoPolicyRepository(pool)
+	ctx := context.Background()
+
+	var memberID, outsiderID, tenantID string
+	if err := pool.QueryRow(ctx, "INSERT INTO users (email, username) VALUES ('member@example.com', 'member') RETURNING id::text").Scan(&memberID); err != nil {
+		t.Fatalf("failed to create member: %v", err)
+	}
+	if err := pool.QueryRow(ctx, "INSERT INTO users (email, username) VALUES ('outsider@example.com', 'outsider') RETURNING id::text").Scan(&outsiderID); err != nil {
+		t.Fatalf("failed to create outsider: %v", err)
+	}
+	if err := pool.QueryRow(ctx, "INSERT INTO tenants (name) VALUES ('acme') RETURNING id::text").Scan(&tenantID); err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "INSERT INTO tenant_members (tenant_id, user_id) VALUES ($1, $2)", tenantID, memberID); err != nil {
+		t.Fatalf("failed to add member: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO tenant_repo_policies (tenant_id, effect, pattern)
+		VALUES ($1, 'allow', 'acme'), ($1, 'deny', 'acme/secret-*')
+	`, tenantID); err != nil {
+		t.Fatalf("failed to add rules: %v", err)
+	}
+
+	t.Run("should return the rules of the user's tenant", func(t *testing.T) {
+		rules, err := repo.ListRulesForUser(ctx, memberID)
+		if err != nil {
+			t.Fatalf("ListRulesForUser failed: %v", err)
+		}
+		want := []repopolicy.Rule{
+			{Effect: repopolicy.EffectAllow, Pattern: "acme"},
+			{Effect: repopolicy.EffectDeny, Pattern: "acme/secret-*"},
+		}
+		if len(rules) != len(want) || rules[0] != want[0] || rules[1] != want[1] {
+			t.Errorf("rules = %+v, want %+v", rules, want)
+		}
+	})
+
+	t.Run("should return no rules outside tenants", func(t *testing.T) {
+		for _, userID := range []string{outsiderID, "not-a-uuid"} {
+			rules, err := repo.ListRulesForUser(ctx, userID)
+			if err != nil || len(rules) != 0 {
+				t.Errorf("ListRulesForUser(%q) = %+v, %v; want no rules", userID, rules, err)
+			}
+		}
+	})
+
+	t.Run("should reject unknown effects", func(t *testing.T) {
+		if _, err := pool.Exec(ctx, "INSERT INTO tenant_repo_policies (tenant_id, effect, pattern) VALUES ($1, 'block', 'x')", tenantID); err == nil {
+			t.Error("expected the effect check constraint to reject the rule")
+		}
+	})
+}
@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/requirement"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ requirement.Repository = (*RequirementRepository)(nil)
+
+type RequirementRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRequirementRepository(pool *pgxpool.Pool) *RequirementRepository {
+	return &RequirementRepository{pool: pool}
+}
+
+func (r *RequirementRepository) SaveSet(ctx context.Context, set *requirement.Set) error {
+	if set == nil {
+		return fmt.Errorf("%w: set is nil", requirement.ErrInvalidInput)
+	}
+
+	codebaseID, err := analysis.ParseUUID(set.CodebaseID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid codebase ID", requirement.ErrInvalidInput)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "SaveSet",
+				"codebase_id", set.CodebaseID,
+				"error", rbErr,
+			)
+		}
+	}()
+
+	queries := db.New(tx)
+
+	setID, err := queries.InsertRequirementSet(ctx, db.InsertRequirementSetParams{
+		CodebaseID:   toPgUUID(codebaseID),
+		Name:         set.Name,
+		SourceFormat: string(set.SourceFormat),
+	})
+	if err != nil {
+		return fmt.Errorf("insert requirement set: %w", err)
+	}
+
+	rows := make([][]any, len(set.Requirements))
+	for i, req := range set.Requirements {
+		rows[i] = []any{
+			setID,
+			req.ExternalKey,
+			req.Title,
+			pgtype.Text{String: req.Description, Valid: req.Description != ""},
+			int32(i),
+		}
+	}
+
+	if _, err := tx.Conn().CopyFrom(
+		ctx,
+		pgx.Identifier{"requirements"},
+		db.RequirementCopyColumns,
+		pgx.CopyFromRows(rows),
+	); err != nil {
+		return fmt.Errorf("copy requirements: %w", err)
+	}
+
+	// COPY cannot return generated IDs, so read them back in sort order.
+	saved, err := queries.GetRequirementsBySetID(ctx, setID)
+	if err != nil {
+		return fmt.Errorf("get saved requirements: %w", err)
+	}
+	if len(saved) != len(set.Requirements) {
+		return fmt.Errorf("requirement count mismatch: saved %d, expected %d", len(saved), len(set.Requirements))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	set.ID = fromPgUUID(setID).String()
+	for i := range set.Requirements {
+		set.Requirements[i].ID = fromPgUUID(saved[i].ID).String()
+	}
+
+	return nil
+}
+
+func (r *RequirementRepository) GetSet(ctx context.Context, setID string) (*requirement.Set, error) {
+	parsedSetID, err := analysis.ParseUUID(setID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid set ID format", requirement.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+
+	row, err := queries.GetRequirementSetByID(ctx, toPgUUID(parsedSetID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, requirement.ErrSetNotFound
+		}
+		return nil, fmt.Errorf("get requirement set: %w", err)
+	}
+
+	reqRows, err := queries.GetRequirementsBySetID(ctx, row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get requirements: %w", err)
+	}
+
+	reqs := make([]requirement.Requirement, len(reqRows))
+	for i, rr := range reqRows {
+		reqs[i] = requirement.Requirement{
+			Description: rr.Description.String,
+			ExternalKey: rr.ExternalKey,
+			ID:          fromPgUUID(rr.ID).String(),
+			Title:       rr.Title,
+		}
+	}
+
+	return &requirement.Set{
+		CodebaseID:   fromPgUUID(row.CodebaseID).String(),
+		ID:           fromPgUUID(row.ID).String(),
+		Name:         row.Name,
+		Requirements: reqs,
+		SourceFormat: requirement.Format(row.SourceFormat),
+	}, nil
+}
+
+func (r *RequirementRepository) ReplaceCoverage(
+	ctx context.Context,
+	setID string,
+	documentID string,
+	matches []requirement.Match,
+) error {
+	parsedSetID, err := analysis.ParseUUID(setID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid set ID format", requirement.ErrInvalidInput)
+	}
+	parsedDocID, err := analysis.ParseUUID(documentID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid document ID format", requirement.ErrInvalidInput)
+	}
+
+	rows := make([][]any, len(matches))
+	for i, m := range matches {
+		reqID, err := analysis.ParseUUID(m.RequirementID)
+		if err != nil {
+			return fmt.Errorf("%w: invalid requirement ID", requirement.ErrInvalidInput)
+		}
+		behaviorID, err := analysis.ParseUUID(m.BehaviorID)
+		if err != nil {
+			return fmt.Errorf("%w: invalid behavior ID", requirement.ErrInvalidInput)
+		}
+		rows[i] = []any{
+			toPgUUID(reqID),
+			toPgUUID(parsedDocID),
+			toPgUUID(behaviorID),
+			confidenceToNumeric(m.Score),
+		}
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "ReplaceCoverage",
+				"set_id", setID,
+				"document_id", documentID,
+				"error", rbErr,
+			)
+		}
+	}()
+
+	queries := db.New(tx)
+
+	if _, err := queries.DeleteRequirementCoverageBySetAndDocument(ctx, db.DeleteRequirementCoverageBySetAndDocumentParams{
+		RequirementSetID: toPgUUID(parsedSetID),
+		DocumentID:       toPgUUID(parsedDocID),
+	}); err != nil {
+		return fmt.Errorf("delete requirement coverage: %w", err)
+	}
+
+	if len(rows) > 0 {
+		if _, err := tx.Conn().CopyFrom(
+			ctx,
+			pgx.Identifier{"requirement_coverage"},
+			db.RequirementCoverageCopyColumns,
+			pgx.CopyFromRows(rows),
+		); err != nil {
+			return fmt.Errorf("copy requirement coverage: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/requirement"
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestRequirementRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	specRepo := NewSpecDocumentRepository(pool)
+	searchRepo := NewSpecSearchRepository(pool)
+	reqRepo := NewRequirementRepository(pool)
+	ctx := context.Background()
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	userID := setupTestUser(t, ctx, pool)
+
+	doc := &specview.SpecDocument{
+		AnalysisID:  analysisID.String(),
+		ContentHash: []byte("requirement-hash"),
+		Language:    "English",
+		ModelID:     "gemini-2.5-flash",
+		UserID:      userID,
+		Domains: []specview.Domain{{
+			Name: "Payments",
+			Features: []specview.Feature{{
+				Name:      "Refunds",
+				Behaviors: []specview.Behavior{{OriginalName: "TestRefund", Description: "Issues a refund"}},
+			}},
+		}},
+	}
+	if err := specRepo.SaveDocument(ctx, doc); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+	entries, err := searchRepo.GetSearchIndexEntries(ctx, doc.ID)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("GetSearchIndexEntries failed: %v (%d entries)", err, len(entries))
+	}
+
+	set := &requirement.Set{
+		CodebaseID: entries[0].CodebaseID,
+		Name:       "PRD",
+		Requirements: []requirement.Requirement{
+			{ExternalKey: "REQ-1", Title: "Refunds"},
+			{ExternalKey: "REQ-2", Title: "Invoices", Description: "PDF export"},
+		},
+		SourceFormat: requirement.FormatCSV,
+	}
+
+	t.Run("should save set and assign IDs in order", func(t *testing.T) {
+		if err := reqRepo.SaveSet(ctx, set); err != nil {
+			t.Fatalf("SaveSet failed: %v", err)
+		}
+		if set.ID == "" || set.Requirements[0].ID == "" || set.Requirements[1].ID == "" {
+			t.Fatalf("expected IDs to be assigned: %+v", set)
+		}
+
+		loaded, err := reqRepo.GetSet(ctx, set.ID)
+		if err != nil {
+			t.Fatalf("GetSet failed: %v", err)
+		}
+		if len(loaded.Requirements) != 2 || loaded.Requirements[1].Description != "PDF export" {
+			t.Errorf("unexpected loaded set: %+v", loaded)
+		}
+	})
+
+	t.Run("should replace coverage idempotently", func(t *testing.T) {
+		matches := []requirement.Match{{BehaviorID: entries[0].BehaviorID, RequirementID: set.Requirements[0].ID, Score: 0.8}}
+
+		for range 2 {
+			if err := reqRepo.ReplaceCoverage(ctx, set.ID, doc.ID, matches); err != nil {
+				t.Fatalf("ReplaceCoverage failed: %v", err)
+			}
+		}
+
+		var count int
+		pool.QueryRow(ctx, "SELECT COUNT(*) FROM requirement_coverage WHERE document_id = $1", doc.ID).Scan(&count)
+		if count != 1 {
+			t.Errorf("expected 1 coverage row, got %d", count)
+		}
+	})
+
+	t.Run("should return ErrSetNotFound", func(t *testing.T) {
+		_, err := reqRepo.GetSet(ctx, "00000000-0000-0000-0000-000000000000")
+		if !errors.Is(err, requirement.ErrSetNotFound) {
+			t.Errorf("expected ErrSetNotFound, got %v", err)
+		}
+	})
+}
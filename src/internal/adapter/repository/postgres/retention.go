@@ -2,8 +2,12 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/specvital/worker/internal/domain/retention"
 	"github.com/specvital/worker/internal/infra/db"
@@ -51,6 +55,49 @@ func (r *RetentionRepository) DeleteExpiredSpecDocuments(ctx context.Context, ba
 	return retention.DeleteResult{DeletedCount: deleted}, nil
 }
 
+// DeleteSupersededSpecDocuments removes full spec document versions older
+// than the policy cutoff, keeping the latest policy.KeepVersions() versions of
+// each analysis, language and model.
+func (r *RetentionRepository) DeleteSupersededSpecDocuments(ctx context.Context, policy retention.VersionPolicy, batchSize int) (retention.DeleteResult, error) {
+	if !policy.Enabled() {
+		return retention.DeleteResult{}, nil
+	}
+	if batchSize <= 0 {
+		batchSize = retention.DefaultBatchSize
+	}
+
+	queries := db.New(r.pool)
+	deleted, err := queries.DeleteSupersededSpecDocuments(ctx, db.DeleteSupersededSpecDocumentsParams{
+		BatchSize:     int32(batchSize),
+		CreatedBefore: pgtype.Timestamptz{Time: policy.CutoffTime(time.Now()), Valid: true},
+		KeepVersions:  int32(policy.KeepVersions()),
+	})
+	if err != nil {
+		return retention.DeleteResult{}, fmt.Errorf("delete superseded spec documents: %w", err)
+	}
+
+	return retention.DeleteResult{DeletedCount: deleted}, nil
+}
+
+// DeleteSoftDeletedSpecDocuments removes spec documents
+// soft-deleted longer than grace ago.
+func (r *RetentionRepository) DeleteSoftDeletedSpecDocuments(ctx context.Context, grace time.Duration, batchSize int) (retention.DeleteResult, error) {
+	if batchSize <= 0 {
+		batchSize = retention.DefaultBatchSize
+	}
+
+	queries := db.New(r.pool)
+	deleted, err := queries.DeleteSoftDeletedSpecDocuments(ctx, db.DeleteSoftDeletedSpecDocumentsParams{
+		BatchSize:     int32(batchSize),
+		DeletedBefore: pgtype.Timestamptz{Time: time.Now().Add(-grace), Valid: true},
+	})
+	if err != nil {
+		return retention.DeleteResult{}, fmt.Errorf("delete soft-deleted spec documents: %w", err)
+	}
+
+	return retention.DeleteResult{DeletedCount: deleted}, nil
+}
+
 // DeleteOrphanedAnalyses removes analyses that have no references
 // in user_analysis_history.
 func (r *RetentionRepository) DeleteOrphanedAnalyses(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
@@ -67,5 +114,122 @@ func (r *RetentionRepository) DeleteOrphanedAnalyses(ctx context.Context, batchS
 	return retention.DeleteResult{DeletedCount: deleted}, nil
 }
 
+// DeleteStaleBehaviorCaches removes behavior cache entries written for
+// codebases that have been stale for over a day.
+func (r *RetentionRepository) DeleteStaleBehaviorCaches(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if batchSize <= 0 {
+		batchSize = retention.DefaultBatchSize
+	}
+
+	queries := db.New(r.pool)
+	deleted, err := queries.DeleteStaleCodebaseBehaviorCaches(ctx, int32(batchSize))
+	if err != nil {
+		return retention.DeleteResult{}, fmt.Errorf("delete stale behavior caches: %w", err)
+	}
+
+	return retention.DeleteResult{DeletedCount: deleted}, nil
+}
+
+// DeleteStaleClassificationCaches removes classification cache entries
+// written for codebases that have been stale for over a day.
+func (r *RetentionRepository) DeleteStaleClassificationCaches(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if batchSize <= 0 {
+		batchSize = retention.DefaultBatchSize
+	}
+
+	queries := db.New(r.pool)
+	deleted, err := queries.DeleteStaleCodebaseClassificationCaches(ctx, int32(batchSize))
+	if err != nil {
+		return retention.DeleteResult{}, fmt.Errorf("delete stale classification caches: %w", err)
+	}
+
+	return retention.DeleteResult{DeletedCount: deleted}, nil
+}
+
+// DeleteOrphanBehaviorEmbeddings removes semantic cache embeddings older than
+// a day that have no behavior cache entry. Databases without pgvector have no
+// embeddings table, and so nothing to delete.
+func (r *RetentionRepository) DeleteOrphanBehaviorEmbeddings(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if batchSize <= 0 {
+		batchSize = retention.DefaultBatchSize
+	}
+
+	queries := db.New(r.pool)
+	deleted, err := queries.DeleteOrphanBehaviorEmbeddings(ctx, int32(batchSize))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "42P01" {
+			return retention.DeleteResult{}, nil
+		}
+		return retention.DeleteResult{}, fmt.Errorf("delete orphan behavior embeddings: %w", err)
+	}
+
+	return retention.DeleteResult{DeletedCount: deleted}, nil
+}
+
+// DeleteExpiredWebhookDeliveries removes webhook delivery records
+// past their deduplication window.
+func (r *RetentionRepository) DeleteExpiredWebhookDeliveries(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if batchSize <= 0 {
+		batchSize = retention.DefaultBatchSize
+	}
+
+	queries := db.New(r.pool)
+	deleted, err := queries.DeleteExpiredWebhookDeliveries(ctx, int32(batchSize))
+	if err != nil {
+		return retention.DeleteResult{}, fmt.Errorf("delete expired webhook deliveries: %w", err)
+	}
+
+	return retention.DeleteResult{DeletedCount: deleted}, nil
+}
+
+// DeleteExpiredIdempotencyKeys removes enqueue idempotency keys
+// past their deduplication window.
+func (r *RetentionRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if batchSize <= 0 {
+		batchSize = retention.DefaultBatchSize
+	}
+
+	queries := db.New(r.pool)
+	deleted, err := queries.DeleteExpiredIdempotencyKeys(ctx, int32(batchSize))
+	if err != nil {
+		return retention.DeleteResult{}, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+
+	return retention.DeleteResult{DeletedCount: deleted}, nil
+}
+
+// DeleteIdleRateLimitBuckets removes rate limit buckets untouched
+// for a day, which have refilled and are recreated full on demand.
+func (r *RetentionRepository) DeleteIdleRateLimitBuckets(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if batchSize <= 0 {
+		batchSize = retention.DefaultBatchSize
+	}
+
+	queries := db.New(r.pool)
+	deleted, err := queries.DeleteIdleRateLimitBuckets(ctx, int32(batchSize))
+	if err != nil {
+		return retention.DeleteResult{}, fmt.Errorf("delete idle rate limit buckets: %w", err)
+	}
+
+	return retention.DeleteResult{DeletedCount: deleted}, nil
+}
+
+// DeleteExpiredAnalysisEvents removes analysis progress events older than a
+// week, past the window idempotency key lookups read them in.
+func (r *RetentionRepository) DeleteExpiredAnalysisEvents(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if batchSize <= 0 {
+		batchSize = retention.DefaultBatchSize
+	}
+
+	queries := db.New(r.pool)
+	deleted, err := queries.DeleteExpiredAnalysisEvents(ctx, int32(batchSize))
+	if err != nil {
+		return retention.DeleteResult{}, fmt.Errorf("delete expired analysis events: %w", err)
+	}
+
+	return retention.DeleteResult{DeletedCount: deleted}, nil
+}
+
 // Compile-time interface check
 var _ retention.CleanupRepository = (*RetentionRepository)(nil)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
 	testdb "github.com/specvital/worker/internal/testutil/postgres"
 )
 
@@ -636,6 +637,155 @@ func TestRetentionRepository_DeleteOrphanedAnalyses(t *testing.T) {
 	})
 }
 
+func TestRetentionRepository_DeleteStaleCodebaseCaches(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	retentionRepo := NewRetentionRepository(pool)
+	docRepo := NewSpecDocumentRepository(pool)
+	ctx := context.Background()
+
+	saveCaches := func(t *testing.T, name string, staleFor string) {
+		t.Helper()
+		var codebaseID, analysisID pgtype.UUID
+		if err := pool.QueryRow(ctx, `
+			INSERT INTO codebases (host, owner, name, external_repo_id, is_stale, updated_at)
+			VALUES ('github.com', 'cache-owner', $1, $1, $2::text <> '', now() - COALESCE(NULLIF($2::text, '')::interval, interval '0'))
+			RETURNING id
+		`, name, staleFor).Scan(&codebaseID); err != nil {
+			t.Fatalf("failed to create codebase: %v", err)
+		}
+		if err := pool.QueryRow(ctx, `
+			INSERT INTO analyses (codebase_id, commit_sha, status, parser_version)
+			VALUES ($1, 'cache123', 'completed', 'v1.0.0')
+			RETURNING id
+		`, codebaseID).Scan(&analysisID); err != nil {
+			t.Fatalf("failed to create analysis: %v", err)
+		}
+		id := fromPgUUID(analysisID).String()
+
+		if err := docRepo.SaveBehaviorCache(ctx, []specview.BehaviorCacheEntry{
+			{AnalysisID: id, CacheKeyHash: []byte(name), Description: "cached"},
+		}); err != nil {
+			t.Fatalf("SaveBehaviorCache failed: %v", err)
+		}
+		if err := docRepo.SaveClassificationCache(ctx, &specview.ClassificationCache{
+			AnalysisID:           id,
+			ClassificationResult: &specview.Phase1Output{},
+			FileSignature:        []byte(name),
+			Language:             "English",
+			ModelID:              "gemini-2.5-flash",
+		}); err != nil {
+			t.Fatalf("SaveClassificationCache failed: %v", err)
+		}
+	}
+
+	saveCaches(t, "live-repo", "")
+	saveCaches(t, "recently-stale-repo", "1 hour")
+	saveCaches(t, "stale-repo", "2 days")
+
+	behaviors, err := retentionRepo.DeleteStaleBehaviorCaches(ctx, 100)
+	if err != nil {
+		t.Fatalf("DeleteStaleBehaviorCaches failed: %v", err)
+	}
+	classifications, err := retentionRepo.DeleteStaleClassificationCaches(ctx, 100)
+	if err != nil {
+		t.Fatalf("DeleteStaleClassificationCaches failed: %v", err)
+	}
+	if behaviors.DeletedCount != 1 || classifications.DeletedCount != 1 {
+		t.Errorf("expected the stale codebase's entries deleted, got %d behaviors and %d classifications",
+			behaviors.DeletedCount, classifications.DeletedCount)
+	}
+
+	var remaining int
+	if err := pool.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM behavior_caches WHERE cache_key_hash = ANY($1::bytea[]))
+		     + (SELECT COUNT(*) FROM classification_caches WHERE content_hash = ANY($1::bytea[]))
+	`, [][]byte{[]byte("live-repo"), []byte("recently-stale-repo")}).Scan(&remaining); err != nil {
+		t.Fatalf("failed to count caches: %v", err)
+	}
+	if remaining != 4 {
+		t.Errorf("expected the live and recently stale entries kept, got %d of 4", remaining)
+	}
+}
+
+func TestRetentionRepository_DeleteIdleRateLimitBuckets(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	retentionRepo := NewRetentionRepository(pool)
+	ctx := context.Background()
+
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at) VALUES
+			('user:active', 1, now() - interval '1 hour'),
+			('user:idle', 0, now() - interval '2 days')
+	`); err != nil {
+		t.Fatalf("failed to create buckets: %v", err)
+	}
+
+	result, err := retentionRepo.DeleteIdleRateLimitBuckets(ctx, 100)
+	if err != nil {
+		t.Fatalf("DeleteIdleRateLimitBuckets failed: %v", err)
+	}
+	if result.DeletedCount != 1 {
+		t.Errorf("DeletedCount = %d, want 1", result.DeletedCount)
+	}
+
+	var activeKept bool
+	if err := pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM rate_limit_buckets WHERE bucket_key = 'user:active')").Scan(&activeKept); err != nil {
+		t.Fatalf("failed to query buckets: %v", err)
+	}
+	if !activeKept {
+		t.Error("expected the active bucket kept")
+	}
+}
+
+func TestRetentionRepository_DeleteExpiredAnalysisEvents(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	retentionRepo := NewRetentionRepository(pool)
+	ctx := context.Background()
+
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO analysis_events (job_id, owner, repo, commit_sha, stage, created_at) VALUES
+			(1, 'owner', 'repo', 'abc123', 'clone', now() - interval '1 day'),
+			(2, 'owner', 'repo', 'abc123', 'clone', now() - interval '8 days'),
+			(2, 'owner', 'repo', 'abc123', 'completed', now() - interval '8 days')
+	`); err != nil {
+		t.Fatalf("failed to create events: %v", err)
+	}
+
+	result, err := retentionRepo.DeleteExpiredAnalysisEvents(ctx, 100)
+	if err != nil {
+		t.Fatalf("DeleteExpiredAnalysisEvents failed: %v", err)
+	}
+	if result.DeletedCount != 2 {
+		t.Errorf("DeletedCount = %d, want 2", result.DeletedCount)
+	}
+
+	var recentKept bool
+	if err := pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM analysis_events WHERE job_id = 1)").Scan(&recentKept); err != nil {
+		t.Fatalf("failed to query events: %v", err)
+	}
+	if !recentKept {
+		t.Error("expected the recent event kept")
+	}
+}
+
 func TestRetentionRepository_DefaultBatchSize(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/servicetoken"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ servicetoken.Repository = (*ServiceTokenRepository)(nil)
+
+// ServiceTokenRepository stores service tokens by hash.
+type ServiceTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewServiceTokenRepository creates a new ServiceTokenRepository.
+func NewServiceTokenRepository(pool *pgxpool.Pool) *ServiceTokenRepository {
+	return &ServiceTokenRepository{pool: pool}
+}
+
+func (r *ServiceTokenRepository) CreateToken(ctx context.Context, params servicetoken.CreateParams) (*servicetoken.Token, error) {
+	var expiresAt pgtype.Timestamptz
+	if params.ExpiresAt != nil {
+		expiresAt = pgtype.Timestamptz{Time: *params.ExpiresAt, Valid: true}
+	}
+
+	scopes := make([]string, len(params.Scopes))
+	for i, s := range params.Scopes {
+		scopes[i] = string(s)
+	}
+
+	row, err := db.New(r.pool).InsertServiceToken(ctx, db.InsertServiceTokenParams{
+		ExpiresAt:   expiresAt,
+		Name:        params.Name,
+		Scopes:      scopes,
+		TokenHash:   params.Hash,
+		TokenPrefix: params.Prefix,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("insert service token: %w", err)
+	}
+	return toServiceToken(row), nil
+}
+
+func (r *ServiceTokenRepository) FindTokenByHash(ctx context.Context, hash []byte) (*servicetoken.Token, error) {
+	row, err := db.New(r.pool).GetServiceTokenByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get service token: %w", err)
+	}
+	return toServiceToken(row), nil
+}
+
+func (r *ServiceTokenRepository) ListTokens(ctx context.Context) ([]servicetoken.Token, error) {
+	rows, err := db.New(r.pool).ListServiceTokens(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list service tokens: %w", err)
+	}
+
+	tokens := make([]servicetoken.Token, len(rows))
+	for i, row := range rows {
+		tokens[i] = *toServiceToken(row)
+	}
+	return tokens, nil
+}
+
+func (r *ServiceTokenRepository) RevokeToken(ctx context.Context, id string) error {
+	tokenID, err := analysis.ParseUUID(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid token ID format", servicetoken.ErrInvalidInput)
+	}
+
+	n, err := db.New(r.pool).RevokeServiceToken(ctx, toPgUUID(tokenID))
+	if err != nil {
+		return fmt.Errorf("revoke service token: %w", err)
+	}
+	if n == 0 {
+		return servicetoken.ErrTokenNotFound
+	}
+	return nil
+}
+
+func (r *ServiceTokenRepository) TouchToken(ctx context.Context, id string) error {
+	tokenID, err := analysis.ParseUUID(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid token ID format", servicetoken.ErrInvalidInput)
+	}
+
+	if err := db.New(r.pool).TouchServiceToken(ctx, toPgUUID(tokenID)); err != nil {
+		return fmt.Errorf("touch service token: %w", err)
+	}
+	return nil
+}
+
+func toServiceToken(row db.ServiceToken) *servicetoken.Token {
+	scopes := make([]servicetoken.Scope, len(row.Scopes))
+	for i, s := range row.Scopes {
+		scopes[i] = servicetoken.Scope(s)
+	}
+	return &servicetoken.Token{
+		CreatedAt:  row.CreatedAt.Time,
+		ExpiresAt:  optionalTime(row.ExpiresAt),
+		ID:         fromPgUUID(row.ID).String(),
+		LastUsedAt: optionalTime(row.LastUsedAt),
+		Name:       row.Name,
+		Prefix:     row.TokenPrefix,
+		RevokedAt:  optionalTime(row.RevokedAt),
+		Scopes:     scopes,
+	}
+}
+
+func optionalTime(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
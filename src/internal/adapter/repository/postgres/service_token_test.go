@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/servicetoken"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestServiceTokenRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewServiceTokenRepository(pool)
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+	hash := servicetoken.Hash("svt_test-token")
+	created, err := repo.CreateToken(ctx, servicetoken.CreateParams{
+		Hash:   hash,
+		Prefix: "svt_test-tok",
+		IssueParams: servicetoken.IssueParams{
+			ExpiresAt: &expiresAt,
+			Name:      "ci",
+			Scopes:    []servicetoken.Scope{servicetoken.ScopeEnqueue, servicetoken.ScopeReadStatus},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+
+	t.Run("should find tokens by hash", func(t *testing.T) {
+		token, err := repo.FindTokenByHash(ctx, hash)
+		if err != nil {
+			t.Fatalf("FindTokenByHash failed: %v", err)
+		}
+		if token == nil || token.ID != created.ID || token.Name != "ci" || len(token.Scopes) != 2 {
+			t.Fatalf("unexpected token: %+v", token)
+		}
+		if token.ExpiresAt == nil || !token.ExpiresAt.Equal(expiresAt) {
+			t.Errorf("expected expiry %v, got %v", expiresAt, token.ExpiresAt)
+		}
+
+		token, err = repo.FindTokenByHash(ctx, servicetoken.Hash("svt_unknown"))
+		if err != nil {
+			t.Fatalf("FindTokenByHash failed: %v", err)
+		}
+		if token != nil {
+			t.Errorf("expected nil for unknown hash, got %+v", token)
+		}
+	})
+
+	t.Run("should reject duplicate hashes", func(t *testing.T) {
+		_, err := repo.CreateToken(ctx, servicetoken.CreateParams{
+			Hash:        hash,
+			Prefix:      "svt_test-tok",
+			IssueParams: servicetoken.IssueParams{Name: "dup", Scopes: []servicetoken.Scope{servicetoken.ScopeAdmin}},
+		})
+		if err == nil {
+			t.Error("expected unique violation")
+		}
+	})
+
+	t.Run("should record last use", func(t *testing.T) {
+		if err := repo.TouchToken(ctx, created.ID); err != nil {
+			t.Fatalf("TouchToken failed: %v", err)
+		}
+		token, err := repo.FindTokenByHash(ctx, hash)
+		if err != nil {
+			t.Fatalf("FindTokenByHash failed: %v", err)
+		}
+		if token.LastUsedAt == nil {
+			t.Error("expected last_used_at to be set")
+		}
+	})
+
+	t.Run("should revoke tokens once", func(t *testing.T) {
+		if err := repo.RevokeToken(ctx, created.ID); err != nil {
+			t.Fatalf("RevokeToken failed: %v", err)
+		}
+		if err := repo.RevokeToken(ctx, created.ID); !errors.Is(err, servicetoken.ErrTokenNotFound) {
+			t.Errorf("expected ErrTokenNotFound on second revoke, got %v", err)
+		}
+
+		tokens, err := repo.ListTokens(ctx)
+		if err != nil {
+			t.Fatalf("ListTokens failed: %v", err)
+		}
+		if len(tokens) != 1 || tokens[0].RevokedAt == nil {
+			t.Errorf("expected one revoked token, got %+v", tokens)
+		}
+	})
+}
@@ -11,19 +11,53 @@ import (
 	"math/big"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/core/pkg/crypto"
 	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/curation"
 	"github.com/specvital/worker/internal/domain/specview"
 	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/metrics"
+	"golang.org/x/sync/errgroup"
 )
 
-var _ specview.Repository = (*SpecDocumentRepository)(nil)
+var (
+	_ specview.BudgetRepository             = (*SpecDocumentRepository)(nil)
+	_ specview.CancellationReader           = (*SpecDocumentRepository)(nil)
+	_ specview.CheckpointRepository         = (*SpecDocumentRepository)(nil)
+	_ specview.CurationRulesReader          = (*SpecDocumentRepository)(nil)
+	_ specview.DecisionWriter               = (*SpecDocumentRepository)(nil)
+	_ specview.DocumentHistoryReader        = (*SpecDocumentRepository)(nil)
+	_ specview.DocumentReader               = (*SpecDocumentRepository)(nil)
+	_ specview.GenerationClaims             = (*SpecDocumentRepository)(nil)
+	_ specview.GenerationProgressRepository = (*SpecDocumentRepository)(nil)
+	_ specview.GlossaryReader               = (*SpecDocumentRepository)(nil)
+	_ specview.OutlineReader                = (*SpecDocumentRepository)(nil)
+	_ specview.QuotaReader                  = (*SpecDocumentRepository)(nil)
+	_ specview.ReclassifyRepository         = (*SpecDocumentRepository)(nil)
+	_ specview.Repository                   = (*SpecDocumentRepository)(nil)
+	_ specview.SemanticBehaviorCache        = (*SpecDocumentRepository)(nil)
+	_ specview.SharingRepository            = (*SpecDocumentRepository)(nil)
+	_ specview.TaxonomyReader               = (*SpecDocumentRepository)(nil)
+	_ specview.TenantLookup                 = (*SpecDocumentRepository)(nil)
+	_ specview.VerifiedBehaviorReader       = (*SpecDocumentRepository)(nil)
+)
+
+// Behavior cache lookups of mega-jobs are split so that no single query
+// carries tens of thousands of hashes.
+const (
+	behaviorLookupChunkSize   = 5000 // hashes per query
+	behaviorLookupConcurrency = 4    // chunk queries in flight per lookup
+)
 
 type SpecDocumentRepository struct {
-	pool *pgxpool.Pool
+	keyring         *DocumentKeyring
+	lookupChunkSize int
+	pool            *pgxpool.Pool
 }
 
 type suiteInfo struct {
@@ -32,8 +66,9 @@ type suiteInfo struct {
 	parentID pgtype.UUID
 }
 
-func NewSpecDocumentRepository(pool *pgxpool.Pool) *SpecDocumentRepository {
-	return &SpecDocumentRepository{pool: pool}
+func NewSpecDocumentRepository(pool *pgxpool.Pool, opts ...DocumentOption) *SpecDocumentRepository {
+	o := applyDocumentOptions(opts)
+	return &SpecDocumentRepository{keyring: o.keyring, lookupChunkSize: behaviorLookupChunkSize, pool: pool}
 }
 
 func (r *SpecDocumentRepository) FindDocumentByContentHash(
@@ -63,6 +98,79 @@ func (r *SpecDocumentRepository) FindDocumentByContentHash(
 		return nil, fmt.Errorf("find spec document: %w", err)
 	}
 
+	return openDocument(ctx, r.keyring, queries, doc, nil)
+}
+
+// FindShareableDocument implements specview.SharingRepository.
+func (r *SpecDocumentRepository) FindShareableDocument(
+	ctx context.Context,
+	userID, analysisID string,
+	contentHash []byte,
+	language specview.Language,
+	modelID string,
+) (*specview.SpecDocument, error) {
+	parsedUserID, err := analysis.ParseUUID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid user ID format", specview.ErrInvalidInput)
+	}
+	parsedAnalysisID, err := analysis.ParseUUID(analysisID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid analysis ID format", specview.ErrInvalidInput)
+	}
+
+	doc, err := db.New(r.pool).FindShareableSpecDocument(ctx, db.FindShareableSpecDocumentParams{
+		AnalysisID:  toPgUUID(parsedAnalysisID),
+		ContentHash: contentHash,
+		Language:    string(language),
+		ModelID:     modelID,
+		UserID:      toPgUUID(parsedUserID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find shareable spec document: %w", err)
+	}
+
+	return toDomainSpecDocument(doc), nil
+}
+
+// IsSharingOptedOut implements specview.SharingRepository.
+func (r *SpecDocumentRepository) IsSharingOptedOut(ctx context.Context, analysisID string) (bool, error) {
+	parsedID, err := analysis.ParseUUID(analysisID)
+	if err != nil {
+		return false, fmt.Errorf("%w: invalid analysis ID format", specview.ErrInvalidInput)
+	}
+
+	optedOut, err := db.New(r.pool).IsCodebaseSharingOptedOut(ctx, toPgUUID(parsedID))
+	if err != nil {
+		return false, fmt.Errorf("check sharing opt-out: %w", err)
+	}
+	return optedOut, nil
+}
+
+// RecordDocumentShare implements specview.SharingRepository.
+func (r *SpecDocumentRepository) RecordDocumentShare(ctx context.Context, documentID, userID, basis string) error {
+	parsedDocumentID, err := analysis.ParseUUID(documentID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+	parsedUserID, err := analysis.ParseUUID(userID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid user ID format", specview.ErrInvalidInput)
+	}
+
+	if err := db.New(r.pool).UpsertSpecDocumentShare(ctx, db.UpsertSpecDocumentShareParams{
+		Basis:      basis,
+		DocumentID: toPgUUID(parsedDocumentID),
+		UserID:     toPgUUID(parsedUserID),
+	}); err != nil {
+		return fmt.Errorf("record document share: %w", err)
+	}
+	return nil
+}
+
+func toDomainSpecDocument(doc db.SpecDocument) *specview.SpecDocument {
 	var executiveSummary string
 	if doc.ExecutiveSummary.Valid {
 		executiveSummary = doc.ExecutiveSummary.String
@@ -72,13 +180,30 @@ func (r *SpecDocumentRepository) FindDocumentByContentHash(
 		AnalysisID:       fromPgUUID(doc.AnalysisID).String(),
 		ContentHash:      doc.ContentHash,
 		CreatedAt:        doc.CreatedAt.Time,
+		DryRun:           doc.IsDryRun,
 		ExecutiveSummary: executiveSummary,
+		Generator:        specview.Generator(doc.Generator),
 		ID:               fromPgUUID(doc.ID).String(),
 		Language:         specview.Language(doc.Language),
 		ModelID:          doc.ModelID,
+		PromptVersion:    doc.PromptVersion,
+		Quality:          toQualityReport(doc.QualityReport),
 		UserID:           fromPgUUID(doc.UserID).String(),
 		Version:          doc.Version,
-	}, nil
+	}
+}
+
+// toQualityReport decodes a stored quality report. Documents saved before
+// scoring, and team slices, have none.
+func toQualityReport(raw []byte) *specview.QualityReport {
+	if len(raw) == 0 {
+		return nil
+	}
+	var report specview.QualityReport
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return nil
+	}
+	return &report
 }
 
 func (r *SpecDocumentRepository) GetAnalysisContext(
@@ -101,12 +226,338 @@ func (r *SpecDocumentRepository) GetAnalysisContext(
 	}
 
 	return &specview.AnalysisContext{
-		Host:  row.Host,
-		Owner: row.Owner,
-		Repo:  row.Repo,
+		Host:    row.Host,
+		Owner:   row.Owner,
+		Private: row.IsPrivate,
+		Repo:    row.Repo,
 	}, nil
 }
 
+// GetCurationRules returns the rules file stored with the analysis, or nil when it had none.
+func (r *SpecDocumentRepository) GetCurationRules(
+	ctx context.Context,
+	analysisID string,
+) (*curation.Rules, error) {
+	parsedID, err := analysis.ParseUUID(analysisID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid analysis ID format", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+
+	data, err := queries.GetAnalysisCurationRules(ctx, toPgUUID(parsedID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, specview.ErrAnalysisNotFound
+		}
+		return nil, fmt.Errorf("get curation rules: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var rules curation.Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("unmarshal curation rules: %w", err)
+	}
+	return &rules, nil
+}
+
+// GetGlossary implements specview.GlossaryReader.
+func (r *SpecDocumentRepository) GetGlossary(
+	ctx context.Context,
+	userID string,
+	analysisID string,
+	language specview.Language,
+) (*specview.Glossary, error) {
+	parsedUserID, err := analysis.ParseUUID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid user ID", specview.ErrInvalidInput)
+	}
+	parsedAnalysisID, err := analysis.ParseUUID(analysisID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid analysis ID format", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+
+	rows, err := queries.GetGlossaryEntries(ctx, db.GetGlossaryEntriesParams{
+		AnalysisID: toPgUUID(parsedAnalysisID),
+		Language:   string(language),
+		UserID:     toPgUUID(parsedUserID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get glossary entries: %w", err)
+	}
+
+	var userEntries, codebaseEntries []specview.GlossaryEntry
+	for _, row := range rows {
+		entry := specview.GlossaryEntry{Preferred: row.Preferred, Term: row.Term}
+		if row.IsCodebase {
+			codebaseEntries = append(codebaseEntries, entry)
+		} else {
+			userEntries = append(userEntries, entry)
+		}
+	}
+	return specview.MergeGlossary(userEntries, codebaseEntries), nil
+}
+
+// GetTaxonomy implements specview.TaxonomyReader.
+func (r *SpecDocumentRepository) GetTaxonomy(ctx context.Context, analysisID string) (*specview.Taxonomy, error) {
+	parsedID, err := analysis.ParseUUID(analysisID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid analysis ID format", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+
+	row, err := queries.GetCodebaseTaxonomy(ctx, toPgUUID(parsedID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get codebase taxonomy: %w", err)
+	}
+
+	var domains []specview.TaxonomyDomain
+	if err := json.Unmarshal(row.Domains, &domains); err != nil {
+		return nil, fmt.Errorf("unmarshal codebase taxonomy: %w", err)
+	}
+	taxonomy := &specview.Taxonomy{Mode: specview.TaxonomyMode(row.Mode)}
+	for _, d := range domains {
+		if strings.TrimSpace(d.Name) != "" {
+			taxonomy.Domains = append(taxonomy.Domains, d)
+		}
+	}
+	if taxonomy.IsEmpty() {
+		return nil, nil
+	}
+	return taxonomy, nil
+}
+
+// GetLatestDocumentOutline returns the domains and features of the user's latest
+// document for the analysis's codebase, with behaviors reduced to their original names.
+func (r *SpecDocumentRepository) GetLatestDocumentOutline(
+	ctx context.Context,
+	userID string,
+	analysisID string,
+	language specview.Language,
+) ([]specview.Domain, error) {
+	parsedUserID, err := analysis.ParseUUID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid user ID", specview.ErrInvalidInput)
+	}
+	parsedAnalysisID, err := analysis.ParseUUID(analysisID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid analysis ID format", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+
+	rows, err := queries.GetLatestDocumentOutline(ctx, db.GetLatestDocumentOutlineParams{
+		AnalysisID: toPgUUID(parsedAnalysisID),
+		Language:   string(language),
+		UserID:     toPgUUID(parsedUserID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get latest document outline: %w", err)
+	}
+
+	var domains []specview.Domain
+	for _, row := range rows {
+		if n := len(domains); n == 0 || domains[n-1].Slug != row.DomainSlug || domains[n-1].Name != row.DomainName {
+			domains = append(domains, specview.Domain{Name: row.DomainName, Slug: row.DomainSlug})
+		}
+		domain := &domains[len(domains)-1]
+		if n := len(domain.Features); n == 0 || domain.Features[n-1].Slug != row.FeatureSlug || domain.Features[n-1].Name != row.FeatureName {
+			domain.Features = append(domain.Features, specview.Feature{Name: row.FeatureName, Slug: row.FeatureSlug})
+		}
+		if row.OriginalName != "" {
+			feature := &domain.Features[len(domain.Features)-1]
+			feature.Behaviors = append(feature.Behaviors, specview.Behavior{OriginalName: row.OriginalName})
+		}
+	}
+	return domains, nil
+}
+
+// GetDocumentAsOf implements specview.DocumentHistoryReader.
+func (r *SpecDocumentRepository) GetDocumentAsOf(
+	ctx context.Context,
+	userID string,
+	codebaseID string,
+	language specview.Language,
+	asOf time.Time,
+) (*specview.SpecDocument, error) {
+	parsedUserID, err := analysis.ParseUUID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid user ID format", specview.ErrInvalidInput)
+	}
+	parsedCodebaseID, err := analysis.ParseUUID(codebaseID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid codebase ID format", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+
+	row, err := queries.FindSpecDocumentAsOf(ctx, db.FindSpecDocumentAsOfParams{
+		AsOf:       pgtype.Timestamptz{Time: asOf, Valid: true},
+		CodebaseID: toPgUUID(parsedCodebaseID),
+		Language:   string(language),
+		UserID:     toPgUUID(parsedUserID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find spec document as of %s: %w", asOf.Format(time.RFC3339), err)
+	}
+
+	content, err := queries.GetSpecDocumentContent(ctx, row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get spec document content: %w", err)
+	}
+
+	return openDocument(ctx, r.keyring, queries, row, content)
+}
+
+// GetDocument implements specview.ReclassifyRepository.
+func (r *SpecDocumentRepository) GetDocument(ctx context.Context, documentID string) (*specview.SpecDocument, error) {
+	parsedDocID, err := analysis.ParseUUID(documentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+	pgDocID := toPgUUID(parsedDocID)
+
+	row, err := queries.GetSpecDocumentByID(ctx, pgDocID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, specview.ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("get spec document: %w", err)
+	}
+
+	content, err := queries.GetSpecDocumentContent(ctx, pgDocID)
+	if err != nil {
+		return nil, fmt.Errorf("get spec document content: %w", err)
+	}
+
+	return openDocument(ctx, r.keyring, queries, row, content)
+}
+
+// toDomainDocumentContent rebuilds the domain tree from rows ordered by domain,
+// feature and behavior. Features without behaviors come as a single row with
+// a NULL behavior. Behaviors saved before test case links were stored have no
+// TestCaseIDs and link their TestCaseID alone.
+func toDomainDocumentContent(rows []db.GetSpecDocumentContentRow) []specview.Domain {
+	var domains []specview.Domain
+	for _, row := range rows {
+		domainID := fromPgUUID(row.DomainID).String()
+		if n := len(domains); n == 0 || domains[n-1].ID != domainID {
+			domains = append(domains, specview.Domain{
+				Confidence:  numericToConfidence(row.ClassificationConfidence),
+				Description: row.DomainDescription.String,
+				ID:          domainID,
+				Name:        row.DomainName,
+				Slug:        row.DomainSlug,
+			})
+		}
+		domain := &domains[len(domains)-1]
+
+		featureID := fromPgUUID(row.FeatureID).String()
+		if n := len(domain.Features); n == 0 || domain.Features[n-1].ID != featureID {
+			domain.Features = append(domain.Features, specview.Feature{
+				Description: row.FeatureDescription.String,
+				ID:          featureID,
+				Name:        row.FeatureName,
+				Slug:        row.FeatureSlug,
+			})
+		}
+		if !row.BehaviorID.Valid {
+			continue
+		}
+
+		behavior := specview.Behavior{
+			Description:  row.ConvertedDescription.String,
+			ID:           fromPgUUID(row.BehaviorID).String(),
+			OriginalName: row.OriginalName.String,
+		}
+		if row.SourceTestCaseID.Valid {
+			behavior.TestCaseID = fromPgUUID(row.SourceTestCaseID).String()
+		}
+		for _, id := range row.TestCaseIds {
+			behavior.TestCaseIDs = append(behavior.TestCaseIDs, fromPgUUID(id).String())
+		}
+		feature := &domain.Features[len(domain.Features)-1]
+		feature.Behaviors = append(feature.Behaviors, behavior)
+	}
+	return domains
+}
+
+func (r *SpecDocumentRepository) SaveGenerationProgress(
+	ctx context.Context,
+	progress specview.GenerationProgress,
+) error {
+	parsedID, err := analysis.ParseUUID(progress.AnalysisID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid analysis ID format", specview.ErrInvalidInput)
+	}
+	if progress.Language == "" || progress.Status == "" {
+		return fmt.Errorf("%w: language and status are required", specview.ErrInvalidInput)
+	}
+
+	startedAt := progress.StartedAt
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+
+	queries := db.New(r.pool)
+	if err := queries.UpsertSpecGenerationProgress(ctx, db.UpsertSpecGenerationProgressParams{
+		AnalysisID:        toPgUUID(parsedID),
+		Language:          string(progress.Language),
+		Status:            string(progress.Status),
+		TotalFeatures:     int32(progress.TotalFeatures),
+		CompletedFeatures: int32(progress.CompletedFeatures),
+		FailedFeatures:    int32(progress.FailedFeatures),
+		CacheHitRate:      progress.CacheHitRate,
+		EtaSeconds:        progress.ETASeconds,
+		StartedAt:         pgtype.Timestamptz{Time: startedAt, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("upsert generation progress: %w", err)
+	}
+	return nil
+}
+
+func (r *SpecDocumentRepository) IsGenerationCancelled(
+	ctx context.Context,
+	analysisID string,
+	language specview.Language,
+	requestedBy string,
+	since time.Time,
+) (bool, error) {
+	parsedID, err := analysis.ParseUUID(analysisID)
+	if err != nil {
+		return false, fmt.Errorf("%w: invalid analysis ID format", specview.ErrInvalidInput)
+	}
+	userID, err := analysis.ParseUUID(requestedBy)
+	if err != nil {
+		return false, fmt.Errorf("%w: invalid user ID format", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+	cancelled, err := queries.IsSpecGenerationCancelled(ctx, db.IsSpecGenerationCancelledParams{
+		AnalysisID:  toPgUUID(parsedID),
+		Language:    string(language),
+		RequestedBy: toPgUUID(userID),
+		Since:       pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("check generation cancellation: %w", err)
+	}
+	return cancelled, nil
+}
+
 func (r *SpecDocumentRepository) GetTestDataByAnalysisID(
 	ctx context.Context,
 	analysisID string,
@@ -147,6 +598,7 @@ func (r *SpecDocumentRepository) aggregateTestData(
 			file = &specview.FileInfo{
 				Framework: row.Framework.String,
 				Path:      row.FilePath,
+				Project:   row.Project.String,
 				Tests:     make([]specview.TestInfo, 0),
 			}
 
@@ -262,18 +714,48 @@ func (r *SpecDocumentRepository) SaveDocument(
 		return fmt.Errorf("%w: invalid analysis ID", specview.ErrInvalidInput)
 	}
 
-	currentVersion, err := queries.GetMaxVersionByUserAnalysisAndLanguage(ctx, db.GetMaxVersionByUserAnalysisAndLanguageParams{
-		UserID:     toPgUUID(userID),
-		AnalysisID: toPgUUID(analysisID),
-		Language:   string(doc.Language),
-	})
+	// Project documents are versioned apart from the full document of the analysis.
+	project := pgtype.Text{String: doc.Project, Valid: doc.Project != ""}
+
+	// Team slices share the parent's version; only full documents start a new one.
+	var parentID pgtype.UUID
+	var team pgtype.Text
+	version := doc.Version
+	if doc.ParentDocumentID != "" {
+		parsed, err := analysis.ParseUUID(doc.ParentDocumentID)
+		if err != nil {
+			return fmt.Errorf("%w: invalid parent document ID", specview.ErrInvalidInput)
+		}
+		if doc.Team == "" {
+			return fmt.Errorf("%w: team is required for a child document", specview.ErrInvalidInput)
+		}
+		parentID = toPgUUID(parsed)
+		team = pgtype.Text{String: doc.Team, Valid: true}
+	} else {
+		currentVersion, err := queries.GetMaxVersionByUserAnalysisAndLanguage(ctx, db.GetMaxVersionByUserAnalysisAndLanguageParams{
+			UserID:     toPgUUID(userID),
+			AnalysisID: toPgUUID(analysisID),
+			Language:   string(doc.Language),
+			Project:    project,
+		})
+		if err != nil {
+			return fmt.Errorf("get max version: %w", err)
+		}
+		version = currentVersion + 1
+	}
+
+	keyID, enc, err := r.keyring.sealer(ctx, queries, toPgUUID(userID))
 	if err != nil {
-		return fmt.Errorf("get max version: %w", err)
+		return err
 	}
 
 	var executiveSummary pgtype.Text
 	if doc.ExecutiveSummary != "" {
-		executiveSummary = pgtype.Text{String: doc.ExecutiveSummary, Valid: true}
+		summary, err := sealText(enc, doc.ExecutiveSummary)
+		if err != nil {
+			return err
+		}
+		executiveSummary = pgtype.Text{String: summary, Valid: true}
 	}
 
 	retentionDays, retErr := queries.GetUserRetentionDays(ctx, toPgUUID(userID))
@@ -281,6 +763,18 @@ func (r *SpecDocumentRepository) SaveDocument(
 		return fmt.Errorf("get user retention days: %w", retErr)
 	}
 
+	var qualityScore pgtype.Float8
+	var qualityFlagged bool
+	var qualityReport []byte
+	if doc.Quality != nil {
+		qualityReport, err = json.Marshal(doc.Quality)
+		if err != nil {
+			return fmt.Errorf("marshal quality report: %w", err)
+		}
+		qualityScore = pgtype.Float8{Float64: doc.Quality.Score, Valid: true}
+		qualityFlagged = doc.Quality.Flagged
+	}
+
 	docID, err := queries.InsertSpecDocument(ctx, db.InsertSpecDocumentParams{
 		UserID:                  toPgUUID(userID),
 		AnalysisID:              toPgUUID(analysisID),
@@ -288,19 +782,60 @@ func (r *SpecDocumentRepository) SaveDocument(
 		Language:                string(doc.Language),
 		ExecutiveSummary:        executiveSummary,
 		ModelID:                 doc.ModelID,
-		Version:                 currentVersion + 1,
+		Version:                 version,
 		RetentionDaysAtCreation: retentionDays,
+		ParentDocumentID:        parentID,
+		Team:                    team,
+		Project:                 project,
+		EncryptionKeyID:         keyID,
+		PromptVersion:           cmp.Or(doc.PromptVersion, specview.DefaultPromptVersion),
+		QualityScore:            qualityScore,
+		QualityFlagged:          qualityFlagged,
+		QualityReport:           qualityReport,
+		IsDryRun:                doc.DryRun,
+		Generator:               string(cmp.Or(doc.Generator, specview.GeneratorAI)),
 	})
 	if err != nil {
 		return fmt.Errorf("insert spec document: %w", err)
 	}
 
 	doc.ID = fromPgUUID(docID).String()
+	doc.Version = version
 
-	if err := r.saveDomains(ctx, tx, docID, doc.Domains); err != nil {
+	if err := r.saveDomains(ctx, tx, docID, doc.Domains, enc); err != nil {
 		return err
 	}
 
+	if err := r.saveDecisions(ctx, queries, docID, toPgUUID(analysisID), doc); err != nil {
+		return err
+	}
+
+	// Coverage uploaded before the document was generated.
+	if _, err := queries.AnnotateBehaviorCoverage(ctx, toPgUUID(analysisID)); err != nil {
+		return fmt.Errorf("annotate behavior coverage: %w", err)
+	}
+
+	// Team slices are part of their parent's generation, which announces it.
+	if doc.ParentDocumentID == "" {
+		if err := insertSpecDocumentCompletedEvent(ctx, queries, docID); err != nil {
+			return err
+		}
+	}
+
+	// The saved document supersedes the progress checkpointed by its job,
+	// and the tokens its job has charged so far were spent on it.
+	if doc.JobID != 0 {
+		if err := queries.DeleteSpecGenerationCheckpoint(ctx, doc.JobID); err != nil {
+			return fmt.Errorf("delete generation checkpoint: %w", err)
+		}
+		if err := queries.AttributeTenantAIUsage(ctx, db.AttributeTenantAIUsageParams{
+			DocumentID: docID,
+			JobID:      pgtype.Int8{Int64: doc.JobID, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("attribute tenant AI usage: %w", err)
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -313,6 +848,7 @@ func (r *SpecDocumentRepository) saveDomains(
 	tx pgx.Tx,
 	documentID pgtype.UUID,
 	domains []specview.Domain,
+	enc crypto.Encryptor,
 ) error {
 	if len(domains) == 0 {
 		return nil
@@ -327,6 +863,7 @@ func (r *SpecDocumentRepository) saveDomains(
 			pgtype.Text{String: domain.Description, Valid: domain.Description != ""},
 			int32(i),
 			confidenceToNumeric(domain.Confidence),
+			domain.Slug,
 		)
 	}
 
@@ -348,7 +885,7 @@ func (r *SpecDocumentRepository) saveDomains(
 	}
 
 	for i, domain := range domains {
-		if err := r.saveFeatures(ctx, tx, domainIDs[i], domain.Features); err != nil {
+		if err := r.saveFeatures(ctx, tx, domainIDs[i], domain.Features, enc); err != nil {
 			return fmt.Errorf("save features for domain %q: %w", domain.Name, err)
 		}
 	}
@@ -361,6 +898,7 @@ func (r *SpecDocumentRepository) saveFeatures(
 	tx pgx.Tx,
 	domainID pgtype.UUID,
 	features []specview.Feature,
+	enc crypto.Encryptor,
 ) error {
 	if len(features) == 0 {
 		return nil
@@ -374,6 +912,7 @@ func (r *SpecDocumentRepository) saveFeatures(
 			feature.Name,
 			pgtype.Text{String: feature.Description, Valid: feature.Description != ""},
 			int32(i),
+			feature.Slug,
 		)
 	}
 
@@ -406,7 +945,7 @@ func (r *SpecDocumentRepository) saveFeatures(
 	}
 
 	if len(allBehaviors) > 0 {
-		if err := r.saveBehaviorsCopyFrom(ctx, tx, allBehaviors); err != nil {
+		if err := r.saveBehaviorsCopyFrom(ctx, tx, allBehaviors, enc); err != nil {
 			return err
 		}
 	}
@@ -424,25 +963,59 @@ func (r *SpecDocumentRepository) saveBehaviorsCopyFrom(
 	ctx context.Context,
 	tx pgx.Tx,
 	behaviors []behaviorWithFeatureID,
+	enc crypto.Encryptor,
 ) error {
 	rows := make([][]any, len(behaviors))
+	var linkRows, mergeRows [][]any
 
 	for i, b := range behaviors {
-		var testCaseID pgtype.UUID
-		if b.behavior.TestCaseID != "" {
-			parsedID, err := analysis.ParseUUID(b.behavior.TestCaseID)
-			if err != nil {
-				return fmt.Errorf("parse test case ID %q: %w", b.behavior.TestCaseID, err)
-			}
-			testCaseID = toPgUUID(parsedID)
+		testCaseID, err := optionalTestCaseID(b.behavior.TestCaseID)
+		if err != nil {
+			return err
+		}
+
+		description, err := sealText(enc, b.behavior.Description)
+		if err != nil {
+			return err
 		}
 
+		// IDs are assigned here so merged test cases can reference their behavior.
+		behaviorID := toPgUUID(analysis.NewUUID())
 		rows[i] = []any{
+			behaviorID,
 			b.featureID,
 			testCaseID,
 			b.behavior.OriginalName,
-			b.behavior.Description,
+			description,
 			int32(b.sortOrder),
+			b.behavior.CacheKeyHash,
+		}
+
+		for j, id := range b.behavior.LinkedTestCaseIDs() {
+			linkedID, err := optionalTestCaseID(id)
+			if err != nil {
+				return err
+			}
+			linkRows = append(linkRows, []any{behaviorID, linkedID, int32(j)})
+		}
+
+		for j, m := range b.behavior.Merged {
+			mergedTestCaseID, err := optionalTestCaseID(m.TestCaseID)
+			if err != nil {
+				return err
+			}
+			mergedDescription, err := sealText(enc, m.Description)
+			if err != nil {
+				return err
+			}
+			mergeRows = append(mergeRows, []any{
+				behaviorID,
+				mergedTestCaseID,
+				m.OriginalName,
+				mergedDescription,
+				confidenceToNumeric(m.Similarity),
+				int32(j),
+			})
 		}
 	}
 
@@ -456,9 +1029,144 @@ func (r *SpecDocumentRepository) saveBehaviorsCopyFrom(
 		return fmt.Errorf("copy spec behaviors: %w", err)
 	}
 
+	if len(linkRows) > 0 {
+		_, err = tx.Conn().CopyFrom(
+			ctx,
+			pgx.Identifier{"spec_behavior_test_cases"},
+			db.SpecBehaviorTestCaseCopyColumns,
+			pgx.CopyFromRows(linkRows),
+		)
+		if err != nil {
+			return fmt.Errorf("copy spec behavior test cases: %w", err)
+		}
+	}
+
+	if len(mergeRows) == 0 {
+		return nil
+	}
+	_, err = tx.Conn().CopyFrom(
+		ctx,
+		pgx.Identifier{"spec_behavior_merges"},
+		db.SpecBehaviorMergeCopyColumns,
+		pgx.CopyFromRows(mergeRows),
+	)
+	if err != nil {
+		return fmt.Errorf("copy spec behavior merges: %w", err)
+	}
+
+	return nil
+}
+
+func optionalTestCaseID(id string) (pgtype.UUID, error) {
+	if id == "" {
+		return pgtype.UUID{}, nil
+	}
+	parsedID, err := analysis.ParseUUID(id)
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("parse test case ID %q: %w", id, err)
+	}
+	return toPgUUID(parsedID), nil
+}
+
+// saveDecisions links the decisions written ahead under the document's
+// generation ID to it, and stores those whose write failed. A document without
+// a generation ID stores all of its decisions under a new one.
+func (r *SpecDocumentRepository) saveDecisions(
+	ctx context.Context,
+	queries *db.Queries,
+	documentID pgtype.UUID,
+	analysisID pgtype.UUID,
+	doc *specview.SpecDocument,
+) error {
+	generationID := analysis.NewUUID()
+	if doc.GenerationID != "" {
+		parsed, err := analysis.ParseUUID(doc.GenerationID)
+		if err != nil {
+			return fmt.Errorf("%w: invalid generation ID", specview.ErrInvalidInput)
+		}
+		generationID = parsed
+		if err := queries.LinkSpecDocumentDecisions(ctx, db.LinkSpecDocumentDecisionsParams{
+			DocumentID:   documentID,
+			GenerationID: toPgUUID(generationID),
+		}); err != nil {
+			return fmt.Errorf("link spec document decisions: %w", err)
+		}
+	}
+	if len(doc.Decisions) == 0 {
+		return nil
+	}
+
+	params := db.InsertSpecDocumentDecisionsParams{
+		AnalysisID:   analysisID,
+		DocumentID:   documentID,
+		GenerationID: toPgUUID(generationID),
+		Details:      make([][]byte, len(doc.Decisions)),
+		Kinds:        make([]string, len(doc.Decisions)),
+		OccurredAts:  make([]pgtype.Timestamptz, len(doc.Decisions)),
+		Sequences:    make([]int32, len(doc.Decisions)),
+		Subjects:     make([]string, len(doc.Decisions)),
+	}
+	for i, d := range doc.Decisions {
+		detail, err := decisionDetail(d)
+		if err != nil {
+			return err
+		}
+		params.Details[i] = detail
+		params.Kinds[i] = string(d.Kind)
+		params.OccurredAts[i] = pgtype.Timestamptz{Time: d.OccurredAt, Valid: true}
+		params.Sequences[i] = int32(i)
+		params.Subjects[i] = d.Subject
+	}
+	if err := queries.InsertSpecDocumentDecisions(ctx, params); err != nil {
+		return fmt.Errorf("insert spec document decisions: %w", err)
+	}
+	return nil
+}
+
+// AppendDecision writes a decision of a generation ahead of its document.
+func (r *SpecDocumentRepository) AppendDecision(
+	ctx context.Context,
+	analysisID, generationID string,
+	sequence int,
+	event specview.DecisionEvent,
+) error {
+	parsedAnalysisID, err := analysis.ParseUUID(analysisID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid analysis ID", specview.ErrInvalidInput)
+	}
+	parsedGenerationID, err := analysis.ParseUUID(generationID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid generation ID", specview.ErrInvalidInput)
+	}
+	detail, err := decisionDetail(event)
+	if err != nil {
+		return err
+	}
+	if err := db.New(r.pool).AppendSpecDocumentDecision(ctx, db.AppendSpecDocumentDecisionParams{
+		AnalysisID:   toPgUUID(parsedAnalysisID),
+		Detail:       detail,
+		GenerationID: toPgUUID(parsedGenerationID),
+		Kind:         string(event.Kind),
+		OccurredAt:   pgtype.Timestamptz{Time: event.OccurredAt, Valid: true},
+		Sequence:     int32(sequence),
+		Subject:      event.Subject,
+	}); err != nil {
+		return fmt.Errorf("append spec document decision: %w", err)
+	}
 	return nil
 }
 
+func decisionDetail(d specview.DecisionEvent) ([]byte, error) {
+	if len(d.Detail) == 0 {
+		return []byte("{}"), nil
+	}
+	encoded, err := json.Marshal(d.Detail)
+	if err != nil {
+		return nil, fmt.Errorf("marshal decision detail: %w", err)
+	}
+	return encoded, nil
+}
+
 func uuidBytesToString(bytes [16]byte) string {
 	return fmt.Sprintf("%x-%x-%x-%x-%x",
 		bytes[0:4], bytes[4:6], bytes[6:8], bytes[8:10], bytes[10:16])
@@ -473,6 +1181,15 @@ func confidenceToNumeric(confidence float64) pgtype.Numeric {
 	}
 }
 
+// numericToConfidence reads a stored confidence; NULL reads as zero.
+func numericToConfidence(n pgtype.Numeric) float64 {
+	f, err := n.Float64Value()
+	if err != nil || !f.Valid {
+		return 0
+	}
+	return f.Float64
+}
+
 func (r *SpecDocumentRepository) RecordUsageEvent(
 	ctx context.Context,
 	userID string,
@@ -538,6 +1255,8 @@ func (r *SpecDocumentRepository) RecordUserHistory(
 // FindCachedBehaviors looks up cached behavior descriptions by cache key hashes.
 // Returns a map of cache_key_hash (hex-encoded) -> converted_description.
 // Only found entries are included in the result map.
+// Large lookups run as chunked queries, a few at a time, and fail as a whole
+// when any chunk fails.
 func (r *SpecDocumentRepository) FindCachedBehaviors(
 	ctx context.Context,
 	cacheKeyHashes [][]byte,
@@ -548,22 +1267,61 @@ func (r *SpecDocumentRepository) FindCachedBehaviors(
 
 	queries := db.New(r.pool)
 
-	rows, err := queries.FindBehaviorCachesByHashes(ctx, cacheKeyHashes)
-	if err != nil {
+	chunks := slices.Collect(slices.Chunk(cacheKeyHashes, max(r.lookupChunkSize, 1)))
+	chunkRows := make([][]db.FindBehaviorCachesByHashesRow, len(chunks))
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(behaviorLookupConcurrency)
+	for i, chunk := range chunks {
+		g.Go(func() error {
+			rows, err := queries.FindBehaviorCachesByHashes(gCtx, chunk)
+			if err != nil {
+				return fmt.Errorf("chunk %d of %d: %w", i+1, len(chunks), err)
+			}
+			chunkRows[i] = rows
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
 		return nil, fmt.Errorf("find cached behaviors: %w", err)
 	}
 
-	result := make(map[string]string, len(rows))
-	for _, row := range rows {
-		hexKey := fmt.Sprintf("%x", row.CacheKeyHash)
-		result[hexKey] = row.ConvertedDescription
+	result := make(map[string]string, len(cacheKeyHashes))
+	for _, rows := range chunkRows {
+		for _, row := range rows {
+			hexKey := fmt.Sprintf("%x", row.CacheKeyHash)
+			result[hexKey] = row.ConvertedDescription
+		}
 	}
+	metrics.CacheLookups.WithLabelValues(metrics.CacheBehavior, metrics.ResultHit).Add(float64(len(result)))
+	metrics.CacheLookups.WithLabelValues(metrics.CacheBehavior, metrics.ResultMiss).Add(float64(len(cacheKeyHashes)-len(result)))
+
+	return result, nil
+}
 
+// FindVerifiedBehaviors looks up the human-verified behavior cache entries
+// among cacheKeyHashes. Returns a map of cache_key_hash (hex-encoded) ->
+// converted_description.
+func (r *SpecDocumentRepository) FindVerifiedBehaviors(
+	ctx context.Context,
+	cacheKeyHashes [][]byte,
+) (map[string]string, error) {
+	result := make(map[string]string)
+	queries := db.New(r.pool)
+	for chunk := range slices.Chunk(cacheKeyHashes, max(r.lookupChunkSize, 1)) {
+		rows, err := queries.FindVerifiedBehaviorCachesByHashes(ctx, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("find verified behaviors: %w", err)
+		}
+		for _, row := range rows {
+			result[fmt.Sprintf("%x", row.CacheKeyHash)] = row.ConvertedDescription
+		}
+	}
 	return result, nil
 }
 
 // SaveBehaviorCache saves behavior cache entries to the database.
-// Uses upsert semantics: existing entries are updated, new entries are inserted.
+// Uses upsert semantics: existing entries are updated, new entries are
+// inserted. Human-verified entries are kept.
 func (r *SpecDocumentRepository) SaveBehaviorCache(
 	ctx context.Context,
 	entries []specview.BehaviorCacheEntry,
@@ -574,7 +1332,7 @@ func (r *SpecDocumentRepository) SaveBehaviorCache(
 
 	batch := &pgx.Batch{}
 	for _, entry := range entries {
-		batch.Queue(db.UpsertBehaviorCacheBatch, entry.CacheKeyHash, entry.Description)
+		batch.Queue(db.UpsertBehaviorCacheBatch, entry.CacheKeyHash, entry.Description, optionalPgUUID(entry.AnalysisID))
 	}
 
 	results := r.pool.SendBatch(ctx, batch)
@@ -607,10 +1365,12 @@ func (r *SpecDocumentRepository) FindClassificationCache(
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
+			metrics.CacheLookups.WithLabelValues(metrics.CacheClassification, metrics.ResultMiss).Inc()
 			return nil, nil
 		}
 		return nil, fmt.Errorf("find classification cache: %w", err)
 	}
+	metrics.CacheLookups.WithLabelValues(metrics.CacheClassification, metrics.ResultHit).Inc()
 
 	var phase1Output specview.Phase1Output
 	if err := json.Unmarshal(row.Phase1Output, &phase1Output); err != nil {
@@ -673,6 +1433,7 @@ func (r *SpecDocumentRepository) SaveClassificationCache(
 		ModelID:      cache.ModelID,
 		Phase1Output: phase1OutputJSON,
 		TestIndexMap: testIndexMapJSON,
+		AnalysisID:   optionalPgUUID(cache.AnalysisID),
 	})
 	if err != nil {
 		return fmt.Errorf("upsert classification cache: %w", err)
@@ -680,3 +1441,299 @@ func (r *SpecDocumentRepository) SaveClassificationCache(
 
 	return nil
 }
+
+// LoadCheckpoint returns the generation checkpoint of the job, or nil when none exists.
+func (r *SpecDocumentRepository) LoadCheckpoint(ctx context.Context, jobID int64) (*specview.GenerationCheckpoint, error) {
+	queries := db.New(r.pool)
+
+	row, err := queries.GetSpecGenerationCheckpoint(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get generation checkpoint: %w", err)
+	}
+
+	var phase1Output specview.Phase1Output
+	if err := json.Unmarshal(row.Phase1Output, &phase1Output); err != nil {
+		return nil, fmt.Errorf("unmarshal phase1_output: %w", err)
+	}
+
+	rows, err := queries.ListSpecGenerationCheckpointFeatures(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("list generation checkpoint features: %w", err)
+	}
+	features := make([]specview.FeatureCheckpoint, 0, len(rows))
+	for _, feature := range rows {
+		var behaviors []specview.BehaviorSpec
+		if err := json.Unmarshal(feature.Behaviors, &behaviors); err != nil {
+			return nil, fmt.Errorf("unmarshal behaviors: %w", err)
+		}
+		features = append(features, specview.FeatureCheckpoint{
+			Behaviors:    behaviors,
+			DomainIndex:  int(feature.DomainIndex),
+			FeatureIndex: int(feature.FeatureIndex),
+		})
+	}
+
+	return &specview.GenerationCheckpoint{
+		AnalysisID:   fromPgUUID(row.AnalysisID).String(),
+		ContentHash:  row.ContentHash,
+		Features:     features,
+		JobID:        row.JobID,
+		Phase1Output: &phase1Output,
+	}, nil
+}
+
+// SaveCheckpoint creates or replaces the generation checkpoint of the job.
+func (r *SpecDocumentRepository) SaveCheckpoint(ctx context.Context, checkpoint *specview.GenerationCheckpoint) error {
+	if checkpoint == nil {
+		return fmt.Errorf("%w: checkpoint is nil", specview.ErrInvalidInput)
+	}
+	if checkpoint.JobID == 0 {
+		return fmt.Errorf("%w: job ID is required", specview.ErrInvalidInput)
+	}
+	if checkpoint.Phase1Output == nil {
+		return fmt.Errorf("%w: phase 1 output is required", specview.ErrInvalidInput)
+	}
+	analysisID, err := analysis.ParseUUID(checkpoint.AnalysisID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid analysis ID", specview.ErrInvalidInput)
+	}
+
+	phase1OutputJSON, err := json.Marshal(checkpoint.Phase1Output)
+	if err != nil {
+		return fmt.Errorf("marshal phase1_output: %w", err)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "SaveCheckpoint",
+				"job_id", checkpoint.JobID,
+				"error", rbErr,
+			)
+		}
+	}()
+
+	queries := db.New(tx)
+	if err := queries.UpsertSpecGenerationCheckpoint(ctx, db.UpsertSpecGenerationCheckpointParams{
+		JobID:        checkpoint.JobID,
+		AnalysisID:   toPgUUID(analysisID),
+		ContentHash:  checkpoint.ContentHash,
+		Phase1Output: phase1OutputJSON,
+	}); err != nil {
+		return fmt.Errorf("upsert generation checkpoint: %w", err)
+	}
+	if err := queries.DeleteSpecGenerationCheckpointFeatures(ctx, checkpoint.JobID); err != nil {
+		return fmt.Errorf("delete generation checkpoint features: %w", err)
+	}
+	if err := insertCheckpointFeatures(ctx, queries, checkpoint.JobID, checkpoint.Features); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// AppendCheckpointFeatures adds converted features to the saved checkpoint of
+// the job, writing only the given features.
+func (r *SpecDocumentRepository) AppendCheckpointFeatures(ctx context.Context, jobID int64, features []specview.FeatureCheckpoint) error {
+	if jobID == 0 {
+		return fmt.Errorf("%w: job ID is required", specview.ErrInvalidInput)
+	}
+	if len(features) == 0 {
+		return nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "AppendCheckpointFeatures",
+				"job_id", jobID,
+				"error", rbErr,
+			)
+		}
+	}()
+
+	if err := insertCheckpointFeatures(ctx, db.New(tx), jobID, features); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func insertCheckpointFeatures(ctx context.Context, queries *db.Queries, jobID int64, features []specview.FeatureCheckpoint) error {
+	for _, feature := range features {
+		behaviorsJSON, err := json.Marshal(feature.Behaviors)
+		if err != nil {
+			return fmt.Errorf("marshal behaviors: %w", err)
+		}
+		if err := queries.InsertSpecGenerationCheckpointFeature(ctx, db.InsertSpecGenerationCheckpointFeatureParams{
+			JobID:        jobID,
+			DomainIndex:  int32(feature.DomainIndex),
+			FeatureIndex: int32(feature.FeatureIndex),
+			Behaviors:    behaviorsJSON,
+		}); err != nil {
+			return fmt.Errorf("insert generation checkpoint feature: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteCheckpoint removes the generation checkpoint of the job.
+func (r *SpecDocumentRepository) DeleteCheckpoint(ctx context.Context, jobID int64) error {
+	queries := db.New(r.pool)
+	if err := queries.DeleteSpecGenerationCheckpoint(ctx, jobID); err != nil {
+		return fmt.Errorf("delete generation checkpoint: %w", err)
+	}
+	return nil
+}
+
+// ClaimGeneration claims the generation key for the job until expiresAt.
+func (r *SpecDocumentRepository) ClaimGeneration(ctx context.Context, key []byte, jobID int64, expiresAt time.Time) (bool, error) {
+	queries := db.New(r.pool)
+	n, err := queries.ClaimSpecGeneration(ctx, db.ClaimSpecGenerationParams{
+		ClaimKey:  key,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		JobID:     jobID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("claim generation: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ExtendGenerationClaim moves the expiry of the job's generation claim.
+func (r *SpecDocumentRepository) ExtendGenerationClaim(ctx context.Context, key []byte, jobID int64, expiresAt time.Time) (bool, error) {
+	queries := db.New(r.pool)
+	n, err := queries.ExtendSpecGenerationClaim(ctx, db.ExtendSpecGenerationClaimParams{
+		ClaimKey:  key,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		JobID:     jobID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("extend generation claim: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ReleaseGeneration removes the job's generation claim.
+func (r *SpecDocumentRepository) ReleaseGeneration(ctx context.Context, key []byte, jobID int64) error {
+	queries := db.New(r.pool)
+	if err := queries.ReleaseSpecGenerationClaim(ctx, db.ReleaseSpecGenerationClaimParams{
+		ClaimKey: key,
+		JobID:    jobID,
+	}); err != nil {
+		return fmt.Errorf("release generation claim: %w", err)
+	}
+	return nil
+}
+
+// GetRemainingBudget returns nil for user IDs that are not UUIDs, which cannot
+// be tenant members.
+func (r *SpecDocumentRepository) GetRemainingBudget(ctx context.Context, userID string) (*specview.Budget, error) {
+	parsed, err := analysis.ParseUUID(userID)
+	if err != nil {
+		return nil, nil
+	}
+
+	queries := db.New(r.pool)
+	row, err := queries.GetTenantAIBudgetByUser(ctx, toPgUUID(parsed))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get tenant AI budget: %w", err)
+	}
+
+	return &specview.Budget{
+		DowngradeModel:     row.DowngradeModel.String,
+		RemainingBehaviors: remainingBudget(row.MonthlyBehaviorLimit, row.BehaviorsUsed),
+		RemainingTokens:    remainingBudget(row.MonthlyTokenLimit, row.TokensUsed),
+		TenantID:           fromPgUUID(row.TenantID).String(),
+	}, nil
+}
+
+// GetUserTenantID returns "" for user IDs that are not UUIDs, which cannot be
+// tenant members.
+func (r *SpecDocumentRepository) GetUserTenantID(ctx context.Context, userID string) (string, error) {
+	parsed, err := analysis.ParseUUID(userID)
+	if err != nil {
+		return "", nil
+	}
+
+	tenantID, err := db.New(r.pool).GetUserTenantID(ctx, toPgUUID(parsed))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("get user tenant: %w", err)
+	}
+	return fromPgUUID(tenantID).String(), nil
+}
+
+func remainingBudget(limit pgtype.Int8, used int64) *int64 {
+	if !limit.Valid {
+		return nil
+	}
+	remaining := limit.Int64 - used
+	return &remaining
+}
+
+// RecordTokenUsage adds tokens spent by the job to the tenant's month.
+func (r *SpecDocumentRepository) RecordTokenUsage(ctx context.Context, tenantID string, jobID int64, tokens int64) error {
+	parsedTenantID, err := analysis.ParseUUID(tenantID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid tenant ID format", specview.ErrInvalidInput)
+	}
+
+	if tokens < 0 {
+		return fmt.Errorf("%w: token count must not be negative", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+	if err := queries.RecordTenantAIUsage(ctx, db.RecordTenantAIUsageParams{
+		TenantID: toPgUUID(parsedTenantID),
+		JobID:    pgtype.Int8{Int64: jobID, Valid: jobID != 0},
+		Tokens:   tokens,
+	}); err != nil {
+		return fmt.Errorf("record tenant AI usage: %w", err)
+	}
+	return nil
+}
+
+// GetQuotaStatus returns nil for user IDs that are not UUIDs, which have no
+// subscription.
+func (r *SpecDocumentRepository) GetQuotaStatus(ctx context.Context, userID string) (*specview.QuotaStatus, error) {
+	parsed, err := analysis.ParseUUID(userID)
+	if err != nil {
+		return nil, nil
+	}
+
+	queries := db.New(r.pool)
+	row, err := queries.GetUserSpecViewQuota(ctx, toPgUUID(parsed))
+	if err != nil {
+		return nil, fmt.Errorf("get spec-view quota: %w", err)
+	}
+
+	status := &specview.QuotaStatus{Used: row.Used}
+	if row.SpecviewLimit.Valid {
+		limit := int64(row.SpecviewLimit.Int32)
+		status.Limit = &limit
+	}
+	return status, nil
+}
@@ -5,11 +5,17 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/specvital/core/pkg/domain"
 	"github.com/specvital/core/pkg/parser"
+	"github.com/specvital/worker/internal/domain/analysis"
 	"github.com/specvital/worker/internal/domain/specview"
 	testdb "github.com/specvital/worker/internal/testutil/postgres"
 )
@@ -163,6 +169,215 @@ func TestSpecDocumentRepository_SaveDocument(t *testing.T) {
 		}
 	})
 
+	t.Run("should save merged test cases with their behavior", func(t *testing.T) {
+		analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+		userID := setupTestUser(t, ctx, pool)
+
+		files, err := specRepo.GetTestDataByAnalysisID(ctx, analysisID.String())
+		if err != nil {
+			t.Fatalf("GetTestDataByAnalysisID failed: %v", err)
+		}
+		if len(files) == 0 || len(files[0].Tests) < 2 {
+			t.Fatal("expected at least two test cases")
+		}
+		tests := files[0].Tests
+
+		doc := &specview.SpecDocument{
+			AnalysisID:  analysisID.String(),
+			ContentHash: []byte("merge-hash"),
+			Language:    "English",
+			ModelID:     "gemini-2.5-flash",
+			UserID:      userID,
+			Domains: []specview.Domain{{
+				Name: "Users",
+				Features: []specview.Feature{{
+					Name: "Creation",
+					Behaviors: []specview.Behavior{{
+						Description:  "Creates a user",
+						OriginalName: tests[0].Name,
+						TestCaseID:   tests[0].TestCaseID,
+						Merged: []specview.MergedTestCase{{
+							Description:  "Creates a user.",
+							OriginalName: tests[1].Name,
+							Similarity:   1,
+							TestCaseID:   tests[1].TestCaseID,
+						}},
+					}},
+				}},
+			}},
+		}
+		if err := specRepo.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+
+		var behaviorName, mergedName, mergedTestCaseID string
+		err = pool.QueryRow(ctx, `
+			SELECT sb.original_name, m.original_name, m.source_test_case_id::text
+			FROM spec_behavior_merges m
+			JOIN spec_behaviors sb ON sb.id = m.behavior_id
+			JOIN spec_features sf ON sf.id = sb.feature_id
+			JOIN spec_domains dom ON dom.id = sf.domain_id
+			WHERE dom.document_id = $1
+		`, doc.ID).Scan(&behaviorName, &mergedName, &mergedTestCaseID)
+		if err != nil {
+			t.Fatalf("query merges: %v", err)
+		}
+		if behaviorName != tests[0].Name || mergedName != tests[1].Name || mergedTestCaseID != tests[1].TestCaseID {
+			t.Errorf("unexpected merge: behavior %q, merged %q (%s)", behaviorName, mergedName, mergedTestCaseID)
+		}
+	})
+
+	t.Run("should link a behavior to every test case it describes", func(t *testing.T) {
+		analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+		userID := setupTestUser(t, ctx, pool)
+
+		files, err := specRepo.GetTestDataByAnalysisID(ctx, analysisID.String())
+		if err != nil {
+			t.Fatalf("GetTestDataByAnalysisID failed: %v", err)
+		}
+		if len(files) == 0 || len(files[0].Tests) < 2 {
+			t.Fatal("expected at least two test cases")
+		}
+		tests := files[0].Tests
+
+		doc := &specview.SpecDocument{
+			AnalysisID:  analysisID.String(),
+			ContentHash: []byte("link-hash"),
+			Language:    "English",
+			ModelID:     "gemini-2.5-flash",
+			UserID:      userID,
+			Domains: []specview.Domain{{
+				Name: "Users",
+				Features: []specview.Feature{{
+					Name: "Creation",
+					Behaviors: []specview.Behavior{{
+						Description:  "Creates a user",
+						OriginalName: tests[0].Name,
+						TestCaseID:   tests[0].TestCaseID,
+						TestCaseIDs:  []string{tests[1].TestCaseID, tests[0].TestCaseID},
+					}},
+				}},
+			}},
+		}
+		if err := specRepo.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+
+		rows, err := pool.Query(ctx, `
+			SELECT bt.test_case_id::text
+			FROM spec_behavior_test_cases bt
+			JOIN spec_behaviors sb ON sb.id = bt.behavior_id
+			JOIN spec_features sf ON sf.id = sb.feature_id
+			JOIN spec_domains dom ON dom.id = sf.domain_id
+			WHERE dom.document_id = $1
+			ORDER BY bt.sort_order
+		`, doc.ID)
+		if err != nil {
+			t.Fatalf("query links: %v", err)
+		}
+		linked, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			t.Fatalf("collect links: %v", err)
+		}
+		want := []string{tests[0].TestCaseID, tests[1].TestCaseID}
+		if len(linked) != 2 || linked[0] != want[0] || linked[1] != want[1] {
+			t.Errorf("linked test cases = %v, want %v", linked, want)
+		}
+	})
+
+	t.Run("should save decision log in order", func(t *testing.T) {
+		analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+		userID := setupTestUser(t, ctx, pool)
+
+		now := time.Now()
+		doc := &specview.SpecDocument{
+			AnalysisID:  analysisID.String(),
+			ContentHash: []byte("decision-hash"),
+			Decisions: []specview.DecisionEvent{
+				{Kind: specview.DecisionFileExcluded, OccurredAt: now, Subject: "gen/api_test.go", Detail: map[string]any{"tests": 3}},
+				{Kind: specview.DecisionBehaviorCacheHit, OccurredAt: now, Subject: "TestLogin"},
+			},
+			Language: "English",
+			ModelID:  "gemini-2.5-flash",
+			UserID:   userID,
+		}
+		if err := specRepo.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+
+		rows, err := pool.Query(ctx, `
+			SELECT kind, subject, detail::text FROM spec_document_decisions
+			WHERE document_id = $1 ORDER BY sequence
+		`, doc.ID)
+		if err != nil {
+			t.Fatalf("query decisions: %v", err)
+		}
+		defer rows.Close()
+		var got []string
+		for rows.Next() {
+			var kind, subject, detail string
+			if err := rows.Scan(&kind, &subject, &detail); err != nil {
+				t.Fatalf("scan decision: %v", err)
+			}
+			got = append(got, kind+" "+subject+" "+detail)
+		}
+		want := []string{`file_excluded gen/api_test.go {"tests": 3}`, "behavior_cache_hit TestLogin {}"}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("decisions = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("should keep decisions written ahead and link them on save", func(t *testing.T) {
+		analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+		userID := setupTestUser(t, ctx, pool)
+
+		generationID := analysis.NewUUID().String()
+		now := time.Now()
+		events := []specview.DecisionEvent{
+			{Kind: specview.DecisionClassificationCache, OccurredAt: now, Subject: "phase1", Detail: map[string]any{"outcome": "miss"}},
+			{Kind: specview.DecisionBehaviorCacheMiss, OccurredAt: now, Subject: "TestLogin"},
+		}
+		// The second write failed: saving the document stores it.
+		if err := specRepo.AppendDecision(ctx, analysisID.String(), generationID, 0, events[0]); err != nil {
+			t.Fatalf("AppendDecision failed: %v", err)
+		}
+
+		var pending int
+		if err := pool.QueryRow(ctx, `
+			SELECT count(*) FROM spec_document_decisions
+			WHERE generation_id = $1 AND document_id IS NULL
+		`, generationID).Scan(&pending); err != nil {
+			t.Fatalf("count pending decisions: %v", err)
+		}
+		if pending != 1 {
+			t.Fatalf("pending decisions = %d, want 1 before the document is saved", pending)
+		}
+
+		doc := &specview.SpecDocument{
+			AnalysisID:   analysisID.String(),
+			ContentHash:  []byte("write-ahead-hash"),
+			Decisions:    events,
+			GenerationID: generationID,
+			Language:     "English",
+			ModelID:      "gemini-2.5-flash",
+			UserID:       userID,
+		}
+		if err := specRepo.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+
+		var linked, total int
+		if err := pool.QueryRow(ctx, `
+			SELECT count(*) FILTER (WHERE document_id = $2), count(*) FROM spec_document_decisions
+			WHERE generation_id = $1
+		`, generationID, doc.ID).Scan(&linked, &total); err != nil {
+			t.Fatalf("count decisions: %v", err)
+		}
+		if linked != 2 || total != 2 {
+			t.Errorf("linked %d of %d decisions, want 2 of 2", linked, total)
+		}
+	})
+
 	t.Run("should return nil for non-existent content hash", func(t *testing.T) {
 		userID := setupTestUser(t, ctx, pool)
 		doc, err := specRepo.FindDocumentByContentHash(ctx, userID, []byte("non-existent"), "English", "model")
@@ -230,6 +445,51 @@ func TestSpecDocumentRepository_VersionManagement(t *testing.T) {
 		}
 	})
 
+	t.Run("should save team slice under the parent version", func(t *testing.T) {
+		analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+		userID := setupTestUser(t, ctx, pool)
+
+		parent := &specview.SpecDocument{
+			AnalysisID:  analysisID.String(),
+			ContentHash: []byte("hash-team"),
+			Language:    "English",
+			ModelID:     "gemini-2.5-flash",
+			UserID:      userID,
+		}
+		if err := specRepo.SaveDocument(ctx, parent); err != nil {
+			t.Fatalf("SaveDocument parent failed: %v", err)
+		}
+
+		child := &specview.SpecDocument{
+			AnalysisID:       parent.AnalysisID,
+			ContentHash:      parent.ContentHash,
+			Language:         parent.Language,
+			ModelID:          parent.ModelID,
+			ParentDocumentID: parent.ID,
+			Team:             "payments",
+			UserID:           userID,
+			Version:          parent.Version,
+		}
+		if err := specRepo.SaveDocument(ctx, child); err != nil {
+			t.Fatalf("SaveDocument child failed: %v", err)
+		}
+
+		var version int32
+		var team string
+		pool.QueryRow(ctx, "SELECT version, team FROM spec_documents WHERE id = $1 AND parent_document_id = $2", child.ID, parent.ID).Scan(&version, &team)
+		if version != parent.Version || team != "payments" {
+			t.Errorf("child version = %d, team = %q, want %d and payments", version, team, parent.Version)
+		}
+
+		found, err := specRepo.FindDocumentByContentHash(ctx, userID, parent.ContentHash, "English", "gemini-2.5-flash")
+		if err != nil {
+			t.Fatalf("FindDocumentByContentHash failed: %v", err)
+		}
+		if found == nil || found.ID != parent.ID {
+			t.Errorf("expected cache lookup to return the parent, got %+v", found)
+		}
+	})
+
 	t.Run("should find only latest version by content hash", func(t *testing.T) {
 		analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
 		userID := setupTestUser(t, ctx, pool)
@@ -278,6 +538,273 @@ func TestSpecDocumentRepository_VersionManagement(t *testing.T) {
 	})
 }
 
+func TestSpecDocumentRepository_GetLatestDocumentOutline(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	specRepo := NewSpecDocumentRepository(pool)
+	ctx := context.Background()
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	userID := setupTestUser(t, ctx, pool)
+
+	t.Run("should return nil without a previous document", func(t *testing.T) {
+		outline, err := specRepo.GetLatestDocumentOutline(ctx, userID, analysisID.String(), "English")
+		if err != nil {
+			t.Fatalf("GetLatestDocumentOutline failed: %v", err)
+		}
+		if outline != nil {
+			t.Errorf("expected nil outline, got %+v", outline)
+		}
+	})
+
+	t.Run("should return slugs and original names of the latest version", func(t *testing.T) {
+		for i, slug := range []string{"old-auth", "auth"} {
+			doc := &specview.SpecDocument{
+				AnalysisID:  analysisID.String(),
+				ContentHash: []byte(fmt.Sprintf("outline-hash-%d", i)),
+				Language:    "English",
+				ModelID:     "gemini-2.5-flash",
+				UserID:      userID,
+				Domains: []specview.Domain{{
+					Name: "Auth",
+					Slug: slug,
+					Features: []specview.Feature{{
+						Name: "Login",
+						Slug: "login",
+						Behaviors: []specview.Behavior{
+							{Description: "logs in", OriginalName: "should login"},
+							{Description: "logs out", OriginalName: "should logout"},
+						},
+					}},
+				}},
+			}
+			if err := specRepo.SaveDocument(ctx, doc); err != nil {
+				t.Fatalf("SaveDocument failed: %v", err)
+			}
+		}
+
+		outline, err := specRepo.GetLatestDocumentOutline(ctx, userID, analysisID.String(), "English")
+		if err != nil {
+			t.Fatalf("GetLatestDocumentOutline failed: %v", err)
+		}
+		if len(outline) != 1 || outline[0].Slug != "auth" {
+			t.Fatalf("expected latest domain slug, got %+v", outline)
+		}
+		features := outline[0].Features
+		if len(features) != 1 || features[0].Slug != "login" || len(features[0].Behaviors) != 2 {
+			t.Fatalf("unexpected features: %+v", features)
+		}
+		if features[0].Behaviors[0].OriginalName != "should login" {
+			t.Errorf("expected original names, got %+v", features[0].Behaviors)
+		}
+
+		other, err := specRepo.GetLatestDocumentOutline(ctx, userID, analysisID.String(), "Korean")
+		if err != nil {
+			t.Fatalf("GetLatestDocumentOutline failed: %v", err)
+		}
+		if other != nil {
+			t.Errorf("expected no outline for another language, got %+v", other)
+		}
+	})
+}
+
+func TestSpecDocumentRepository_GetGlossary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	specRepo := NewSpecDocumentRepository(pool)
+	ctx := context.Background()
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	userID := setupTestUser(t, ctx, pool)
+
+	glossary, err := specRepo.GetGlossary(ctx, userID, analysisID.String(), "Korean")
+	if err != nil || glossary != nil {
+		t.Fatalf("expected no glossary, got %+v, %v", glossary, err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO glossary_entries (user_id, codebase_id, language, term, preferred)
+		VALUES
+			($1, NULL, 'Korean', 'settlement', '결제'),
+			($1, NULL, 'Korean', 'refund', '환불'),
+			($1, NULL, 'English', 'settlement', 'payout'),
+			(NULL, (SELECT codebase_id FROM analyses WHERE id = $2), 'Korean', 'Settlement', '정산')`,
+		userID, analysisID.String())
+	if err != nil {
+		t.Fatalf("insert glossary entries: %v", err)
+	}
+
+	glossary, err = specRepo.GetGlossary(ctx, userID, analysisID.String(), "Korean")
+	if err != nil {
+		t.Fatalf("GetGlossary failed: %v", err)
+	}
+	want := []specview.GlossaryEntry{
+		{Preferred: "환불", Term: "refund"},
+		{Preferred: "정산", Term: "Settlement"},
+	}
+	if glossary == nil || !reflect.DeepEqual(glossary.Entries, want) {
+		t.Errorf("expected codebase entry to override the user's, got %+v", glossary)
+	}
+}
+
+func TestSpecDocumentRepository_GetTaxonomy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	specRepo := NewSpecDocumentRepository(pool)
+	ctx := context.Background()
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+
+	taxonomy, err := specRepo.GetTaxonomy(ctx, analysisID.String())
+	if err != nil || taxonomy != nil {
+		t.Fatalf("expected no taxonomy, got %+v, %v", taxonomy, err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO codebase_taxonomies (codebase_id, domains, mode)
+		VALUES ((SELECT codebase_id FROM analyses WHERE id = $1), $2, 'reject')`,
+		analysisID.String(), `[{"name": "Payments", "aliases": ["Billing"]}, {"name": " "}]`)
+	if err != nil {
+		t.Fatalf("insert taxonomy: %v", err)
+	}
+
+	taxonomy, err = specRepo.GetTaxonomy(ctx, analysisID.String())
+	if err != nil {
+		t.Fatalf("GetTaxonomy failed: %v", err)
+	}
+	want := &specview.Taxonomy{
+		Domains: []specview.TaxonomyDomain{{Aliases: []string{"Billing"}, Name: "Payments"}},
+		Mode:    specview.TaxonomyReject,
+	}
+	if !reflect.DeepEqual(taxonomy, want) {
+		t.Errorf("expected the taxonomy without blank domains, got %+v", taxonomy)
+	}
+}
+
+func TestSpecDocumentRepository_GetDocumentAsOf(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	specRepo := NewSpecDocumentRepository(pool)
+	ctx := context.Background()
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	userID := setupTestUser(t, ctx, pool)
+
+	var codebaseID string
+	if err := pool.QueryRow(ctx, "SELECT codebase_id::text FROM analyses WHERE id = $1", analysisID.String()).Scan(&codebaseID); err != nil {
+		t.Fatalf("failed to get codebase: %v", err)
+	}
+
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var docs []*specview.SpecDocument
+	for i, name := range []string{"Should log in", "Should log in with SSO"} {
+		doc := &specview.SpecDocument{
+			AnalysisID:       analysisID.String(),
+			ContentHash:      []byte(fmt.Sprintf("as-of-hash-%d", i)),
+			ExecutiveSummary: fmt.Sprintf("summary v%d", i+1),
+			Language:         "English",
+			ModelID:          "gemini-2.5-flash",
+			UserID:           userID,
+			Domains: []specview.Domain{{
+				Confidence: 0.9,
+				Name:       "Auth",
+				Features: []specview.Feature{
+					{Name: "Login", Behaviors: []specview.Behavior{{Description: name, OriginalName: "TestLogin"}}},
+					{Name: "Logout"},
+				},
+			}},
+		}
+		if err := specRepo.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+		if _, err := pool.Exec(ctx, "UPDATE spec_documents SET created_at = $2 WHERE id = $1", doc.ID, base.AddDate(0, 0, 10*i)); err != nil {
+			t.Fatalf("failed to backdate document: %v", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	t.Run("should return nil before the first document", func(t *testing.T) {
+		doc, err := specRepo.GetDocumentAsOf(ctx, userID, codebaseID, "English", base)
+		if err != nil {
+			t.Fatalf("GetDocumentAsOf failed: %v", err)
+		}
+		if doc != nil {
+			t.Errorf("expected nil, got version %d", doc.Version)
+		}
+	})
+
+	t.Run("should return the latest version created before the time", func(t *testing.T) {
+		doc, err := specRepo.GetDocumentAsOf(ctx, userID, codebaseID, "English", base.AddDate(0, 0, 5))
+		if err != nil {
+			t.Fatalf("GetDocumentAsOf failed: %v", err)
+		}
+		if doc == nil || doc.ID != docs[0].ID || doc.ExecutiveSummary != "summary v1" {
+			t.Fatalf("expected version 1, got %+v", doc)
+		}
+		if len(doc.Domains) != 1 || doc.Domains[0].Confidence != 0.9 || len(doc.Domains[0].Features) != 2 {
+			t.Fatalf("unexpected domains: %+v", doc.Domains)
+		}
+		login, logout := doc.Domains[0].Features[0], doc.Domains[0].Features[1]
+		if len(login.Behaviors) != 1 || login.Behaviors[0].Description != "Should log in" {
+			t.Errorf("unexpected behaviors: %+v", login.Behaviors)
+		}
+		if len(logout.Behaviors) != 0 {
+			t.Errorf("feature without behaviors should stay empty, got %+v", logout.Behaviors)
+		}
+
+		latest, err := specRepo.GetDocumentAsOf(ctx, userID, codebaseID, "English", time.Now())
+		if err != nil {
+			t.Fatalf("GetDocumentAsOf failed: %v", err)
+		}
+		if latest == nil || latest.ID != docs[1].ID {
+			t.Errorf("expected version 2 now, got %+v", latest)
+		}
+	})
+
+	t.Run("should not return other languages or users", func(t *testing.T) {
+		doc, err := specRepo.GetDocumentAsOf(ctx, userID, codebaseID, "Korean", time.Now())
+		if err != nil || doc != nil {
+			t.Errorf("expected nil for another language, got %+v, %v", doc, err)
+		}
+
+		otherUser := setupTestUser(t, ctx, pool)
+		doc, err = specRepo.GetDocumentAsOf(ctx, otherUser, codebaseID, "English", time.Now())
+		if err != nil || doc != nil {
+			t.Errorf("expected nil for another user, got %+v, %v", doc, err)
+		}
+	})
+
+	t.Run("should reject invalid IDs", func(t *testing.T) {
+		if _, err := specRepo.GetDocumentAsOf(ctx, userID, "not-a-uuid", "English", time.Now()); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
 func TestSpecDocumentRepository_FindDocumentByContentHash(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -323,6 +850,33 @@ func TestSpecDocumentRepository_FindDocumentByContentHash(t *testing.T) {
 		}
 	})
 
+	t.Run("should round-trip the generator", func(t *testing.T) {
+		analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+		userID := setupTestUser(t, ctx, pool)
+		contentHash := []byte("heuristic-hash-for-find-test")
+
+		doc := &specview.SpecDocument{
+			AnalysisID:  analysisID.String(),
+			ContentHash: contentHash,
+			Generator:   specview.GeneratorHeuristic,
+			Language:    "English",
+			ModelID:     "heuristic",
+			UserID:      userID,
+			Domains:     []specview.Domain{},
+		}
+		if err := specRepo.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+
+		found, err := specRepo.FindDocumentByContentHash(ctx, userID, contentHash, "English", "heuristic")
+		if err != nil || found == nil {
+			t.Fatalf("expected to find document, got %v", err)
+		}
+		if found.Generator != specview.GeneratorHeuristic {
+			t.Errorf("expected generator %q, got %q", specview.GeneratorHeuristic, found.Generator)
+		}
+	})
+
 	t.Run("should not find document with different language", func(t *testing.T) {
 		analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
 		userID := setupTestUser(t, ctx, pool)
@@ -593,6 +1147,35 @@ func TestSpecDocumentRepository_BehaviorCache(t *testing.T) {
 		}
 	})
 
+	t.Run("should merge lookups split into chunks", func(t *testing.T) {
+		chunked := NewSpecDocumentRepository(pool)
+		chunked.lookupChunkSize = 2
+
+		var hashes [][]byte
+		var entries []specview.BehaviorCacheEntry
+		for i := range 7 {
+			hash := []byte{0xc0, 0xde, byte(i)}
+			hashes = append(hashes, hash)
+			if i%2 == 0 {
+				entries = append(entries, specview.BehaviorCacheEntry{CacheKeyHash: hash, Description: fmt.Sprintf("behavior %d", i)})
+			}
+		}
+		if err := chunked.SaveBehaviorCache(ctx, entries); err != nil {
+			t.Fatalf("SaveBehaviorCache failed: %v", err)
+		}
+
+		result, err := chunked.FindCachedBehaviors(ctx, hashes)
+		if err != nil {
+			t.Fatalf("FindCachedBehaviors failed: %v", err)
+		}
+		if len(result) != 4 {
+			t.Errorf("expected 4 entries across chunks, got %d", len(result))
+		}
+		if result["c0de06"] != "behavior 6" {
+			t.Errorf("unexpected description in the last chunk: %q", result["c0de06"])
+		}
+	})
+
 	t.Run("should do nothing for empty entries", func(t *testing.T) {
 		err := specRepo.SaveBehaviorCache(ctx, nil)
 		if err != nil {
@@ -1091,3 +1674,442 @@ func createNestedSuiteInventory() *parser.ScanResult {
 		},
 	}
 }
+
+func TestSpecDocumentRepository_SaveGenerationProgress(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	specRepo := NewSpecDocumentRepository(pool)
+	ctx := context.Background()
+
+	t.Run("should upsert snapshot and notify listeners", func(t *testing.T) {
+		analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			t.Fatalf("acquire connection: %v", err)
+		}
+		defer conn.Release()
+		if _, err := conn.Exec(ctx, "LISTEN spec_generation_progress"); err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+
+		startedAt := time.Now()
+		progress := specview.GenerationProgress{
+			AnalysisID:        analysisID.String(),
+			CacheHitRate:      0.5,
+			CompletedFeatures: 3,
+			Language:          "en",
+			StartedAt:         startedAt,
+			Status:            specview.GenerationRunning,
+			TotalFeatures:     10,
+		}
+		if err := specRepo.SaveGenerationProgress(ctx, progress); err != nil {
+			t.Fatalf("SaveGenerationProgress failed: %v", err)
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		notification, err := conn.Conn().WaitForNotification(waitCtx)
+		if err != nil {
+			t.Fatalf("WaitForNotification failed: %v", err)
+		}
+		if !strings.Contains(notification.Payload, `"completed_features":3`) {
+			t.Errorf("unexpected payload: %s", notification.Payload)
+		}
+
+		// A stale snapshot of the same run must not regress progress.
+		stale := progress
+		stale.CompletedFeatures = 2
+		if err := specRepo.SaveGenerationProgress(ctx, stale); err != nil {
+			t.Fatalf("SaveGenerationProgress (stale) failed: %v", err)
+		}
+
+		done := progress
+		done.CompletedFeatures = 10
+		done.Status = specview.GenerationCompleted
+		if err := specRepo.SaveGenerationProgress(ctx, done); err != nil {
+			t.Fatalf("SaveGenerationProgress (completed) failed: %v", err)
+		}
+
+		var completed int32
+		var status string
+		err = pool.QueryRow(ctx,
+			"SELECT completed_features, status FROM spec_generation_progress WHERE analysis_id = $1 AND language = 'en'",
+			analysisID.id,
+		).Scan(&completed, &status)
+		if err != nil {
+			t.Fatalf("query progress: %v", err)
+		}
+		if completed != 10 || status != string(specview.GenerationCompleted) {
+			t.Errorf("expected completed snapshot, got completed=%d status=%s", completed, status)
+		}
+	})
+
+	t.Run("should return ErrInvalidInput for invalid analysis ID", func(t *testing.T) {
+		err := specRepo.SaveGenerationProgress(ctx, specview.GenerationProgress{
+			AnalysisID: "invalid-uuid",
+			Language:   "en",
+			Status:     specview.GenerationRunning,
+		})
+		if !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestSpecDocumentRepository_IsGenerationCancelled(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	specRepo := NewSpecDocumentRepository(pool)
+	ctx := context.Background()
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, NewAnalysisRepository(pool), pool)
+
+	var requester, otherUser string
+	if err := pool.QueryRow(ctx, "INSERT INTO users (email, username) VALUES ('requester@example.com', 'requester') RETURNING id::text").Scan(&requester); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := pool.QueryRow(ctx, "INSERT INTO users (email, username) VALUES ('other@example.com', 'other') RETURNING id::text").Scan(&otherUser); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	cancelled, err := specRepo.IsGenerationCancelled(ctx, analysisID.String(), "en", requester, time.Now().Add(-time.Hour))
+	if err != nil || cancelled {
+		t.Fatalf("expected no cancellation, got %v, %v", cancelled, err)
+	}
+
+	if _, err := pool.Exec(ctx,
+		"INSERT INTO spec_generation_cancellations (analysis_id, language, requested_by) VALUES ($1, 'en', $2)",
+		analysisID.id, requester,
+	); err != nil {
+		t.Fatalf("insert cancellation: %v", err)
+	}
+
+	t.Run("should report a cancellation requested since the job was queued", func(t *testing.T) {
+		cancelled, err := specRepo.IsGenerationCancelled(ctx, analysisID.String(), "en", requester, time.Now().Add(-time.Hour))
+		if err != nil || !cancelled {
+			t.Errorf("expected cancellation, got %v, %v", cancelled, err)
+		}
+	})
+
+	t.Run("should ignore another user's cancellation", func(t *testing.T) {
+		cancelled, err := specRepo.IsGenerationCancelled(ctx, analysisID.String(), "en", otherUser, time.Now().Add(-time.Hour))
+		if err != nil || cancelled {
+			t.Errorf("expected another requester unaffected, got %v, %v", cancelled, err)
+		}
+	})
+
+	t.Run("should ignore an earlier cancellation or another language", func(t *testing.T) {
+		if cancelled, err := specRepo.IsGenerationCancelled(ctx, analysisID.String(), "en", requester, time.Now().Add(time.Hour)); err != nil || cancelled {
+			t.Errorf("expected an earlier request ignored, got %v, %v", cancelled, err)
+		}
+		if cancelled, err := specRepo.IsGenerationCancelled(ctx, analysisID.String(), "ko", requester, time.Now().Add(-time.Hour)); err != nil || cancelled {
+			t.Errorf("expected another language unaffected, got %v, %v", cancelled, err)
+		}
+	})
+
+	t.Run("should return ErrInvalidInput for invalid IDs", func(t *testing.T) {
+		if _, err := specRepo.IsGenerationCancelled(ctx, "invalid-uuid", "en", requester, time.Now()); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+		if _, err := specRepo.IsGenerationCancelled(ctx, analysisID.String(), "en", "invalid-uuid", time.Now()); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for requester, got %v", err)
+		}
+	})
+}
+
+func TestSpecDocumentRepository_Checkpoint(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewSpecDocumentRepository(pool)
+	analysisRepo := NewAnalysisRepository(pool)
+	ctx := context.Background()
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	jobID := insertDiscardedJob(t, ctx, pool, "specview:generate", `{"analysis_id":"a1","language":"English","user_id":"u1"}`)
+
+	got, err := repo.LoadCheckpoint(ctx, jobID)
+	if err != nil || got != nil {
+		t.Fatalf("expected no checkpoint, got %+v, %v", got, err)
+	}
+
+	login := specview.FeatureCheckpoint{
+		Behaviors: []specview.BehaviorSpec{{Confidence: 0.9, Description: "Should log in", TestIndex: 0}},
+	}
+	logout := specview.FeatureCheckpoint{
+		Behaviors:    []specview.BehaviorSpec{{Confidence: 0.9, Description: "Should log out", TestIndex: 1}},
+		FeatureIndex: 1,
+	}
+	checkpoint := &specview.GenerationCheckpoint{
+		AnalysisID:  analysisID.String(),
+		ContentHash: []byte("checkpoint-hash"),
+		Features:    []specview.FeatureCheckpoint{logout},
+		JobID:       jobID,
+		Phase1Output: &specview.Phase1Output{Domains: []specview.DomainGroup{
+			{Name: "Auth", Features: []specview.FeatureGroup{
+				{Name: "Login", TestIndices: []int{0}},
+				{Name: "Logout", TestIndices: []int{1}},
+			}},
+		}},
+	}
+	if err := repo.SaveCheckpoint(ctx, checkpoint); err != nil {
+		t.Fatalf("SaveCheckpoint failed: %v", err)
+	}
+
+	checkpoint.Features = []specview.FeatureCheckpoint{login}
+	if err := repo.SaveCheckpoint(ctx, checkpoint); err != nil {
+		t.Fatalf("SaveCheckpoint (replace) failed: %v", err)
+	}
+	if err := repo.AppendCheckpointFeatures(ctx, jobID, []specview.FeatureCheckpoint{logout}); err != nil {
+		t.Fatalf("AppendCheckpointFeatures failed: %v", err)
+	}
+
+	got, err = repo.LoadCheckpoint(ctx, jobID)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint failed: %v", err)
+	}
+	if !got.Matches([]byte("checkpoint-hash")) || len(got.Phase1Output.Domains) != 1 || got.AnalysisID != analysisID.String() {
+		t.Fatalf("unexpected checkpoint: %+v", got)
+	}
+	if len(got.Features) != 2 ||
+		got.Features[0].Behaviors[0].Description != "Should log in" ||
+		got.Features[1].Behaviors[0].Description != "Should log out" {
+		t.Errorf("unexpected features: %+v", got.Features)
+	}
+
+	if err := repo.DeleteCheckpoint(ctx, jobID); err != nil {
+		t.Fatalf("DeleteCheckpoint failed: %v", err)
+	}
+	if got, _ := repo.LoadCheckpoint(ctx, jobID); got != nil {
+		t.Errorf("checkpoint should be deleted, got %+v", got)
+	}
+	var features int
+	if err := pool.QueryRow(ctx, "SELECT count(*) FROM spec_generation_checkpoint_features WHERE job_id = $1", jobID).Scan(&features); err != nil {
+		t.Fatalf("failed to count features: %v", err)
+	}
+	if features != 0 {
+		t.Errorf("features should be deleted with their checkpoint, %d left", features)
+	}
+
+	t.Run("deleted when the document is saved", func(t *testing.T) {
+		if err := repo.SaveCheckpoint(ctx, checkpoint); err != nil {
+			t.Fatalf("SaveCheckpoint failed: %v", err)
+		}
+		if err := repo.SaveDocument(ctx, &specview.SpecDocument{
+			AnalysisID:  analysisID.String(),
+			ContentHash: []byte("checkpoint-hash"),
+			JobID:       jobID,
+			Language:    "English",
+			ModelID:     "gemini-2.5-flash",
+			UserID:      setupTestUser(t, ctx, pool),
+		}); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+		if got, _ := repo.LoadCheckpoint(ctx, jobID); got != nil {
+			t.Errorf("checkpoint should be deleted with the saved document, got %+v", got)
+		}
+	})
+
+	t.Run("deleted with the analysis", func(t *testing.T) {
+		if err := repo.SaveCheckpoint(ctx, checkpoint); err != nil {
+			t.Fatalf("SaveCheckpoint failed: %v", err)
+		}
+		if _, err := pool.Exec(ctx, "DELETE FROM analyses WHERE id = $1", analysisID.String()); err != nil {
+			t.Fatalf("failed to delete analysis: %v", err)
+		}
+		if got, _ := repo.LoadCheckpoint(ctx, jobID); got != nil {
+			t.Errorf("checkpoint should be deleted with its analysis, got %+v", got)
+		}
+	})
+}
+
+func TestSpecDocumentRepository_GenerationClaims(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewSpecDocumentRepository(pool)
+	ctx := context.Background()
+	first := insertDiscardedJob(t, ctx, pool, "specview:generate", `{"analysis_id":"a1","language":"English","user_id":"u1"}`)
+	second := insertDiscardedJob(t, ctx, pool, "specview:generate", `{"analysis_id":"a1","language":"English","user_id":"u2"}`)
+	key := specview.GenerationClaimKey([]byte("content"), "English", "gemini-2.5-flash", "")
+	expiresAt := time.Now().Add(time.Minute)
+
+	claim := func(jobID int64, expiresAt time.Time) bool {
+		t.Helper()
+		claimed, err := repo.ClaimGeneration(ctx, key, jobID, expiresAt)
+		if err != nil {
+			t.Fatalf("ClaimGeneration failed: %v", err)
+		}
+		return claimed
+	}
+
+	if !claim(first, expiresAt) {
+		t.Fatal("expected the first job to claim the key")
+	}
+	if !claim(first, expiresAt) {
+		t.Error("expected the holder to claim its key again")
+	}
+	if claim(second, expiresAt) {
+		t.Error("expected the second job to find the key claimed")
+	}
+
+	if ok, err := repo.ExtendGenerationClaim(ctx, key, second, expiresAt); err != nil || ok {
+		t.Errorf("expected no claim to extend for the second job, got %v, %v", ok, err)
+	}
+	if ok, err := repo.ExtendGenerationClaim(ctx, key, first, time.Now().Add(-time.Second)); err != nil || !ok {
+		t.Fatalf("ExtendGenerationClaim = %v, %v", ok, err)
+	}
+	if !claim(second, expiresAt) {
+		t.Fatal("expected an expired claim to be taken over")
+	}
+
+	if err := repo.ReleaseGeneration(ctx, key, first); err != nil {
+		t.Fatalf("ReleaseGeneration failed: %v", err)
+	}
+	if claim(first, expiresAt) {
+		t.Error("releasing a claim the job lost should keep the new holder's")
+	}
+	if err := repo.ReleaseGeneration(ctx, key, second); err != nil {
+		t.Fatalf("ReleaseGeneration failed: %v", err)
+	}
+	if !claim(first, expiresAt) {
+		t.Error("expected a released key to be claimable")
+	}
+}
+
+func TestSpecDocumentRepository_Budget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewSpecDocumentRepository(pool)
+	ctx := context.Background()
+
+	var memberID, outsiderID, tenantID string
+	if err := pool.QueryRow(ctx, "INSERT INTO users (email, username) VALUES ('member@example.com', 'member') RETURNING id::text").Scan(&memberID); err != nil {
+		t.Fatalf("failed to create member: %v", err)
+	}
+	if err := pool.QueryRow(ctx, "INSERT INTO users (email, username) VALUES ('outsider@example.com', 'outsider') RETURNING id::text").Scan(&outsiderID); err != nil {
+		t.Fatalf("failed to create outsider: %v", err)
+	}
+	if err := pool.QueryRow(ctx, "INSERT INTO tenants (name) VALUES ('acme') RETURNING id::text").Scan(&tenantID); err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "INSERT INTO tenant_members (tenant_id, user_id) VALUES ($1, $2)", tenantID, memberID); err != nil {
+		t.Fatalf("failed to add member: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO tenant_ai_budgets (tenant_id, monthly_token_limit, downgrade_model)
+		VALUES ($1, 1000, 'gemini-2.5-flash-lite')
+	`, tenantID); err != nil {
+		t.Fatalf("failed to add budget: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO tenant_ai_usage (tenant_id, tokens, created_at)
+		VALUES ($1, 300, now()), ($1, 5000, now() - interval '2 months')
+	`, tenantID); err != nil {
+		t.Fatalf("failed to add usage: %v", err)
+	}
+
+	t.Run("should return what remains this month", func(t *testing.T) {
+		budget, err := repo.GetRemainingBudget(ctx, memberID)
+		if err != nil {
+			t.Fatalf("GetRemainingBudget failed: %v", err)
+		}
+		if budget == nil || budget.TenantID != tenantID || budget.DowngradeModel != "gemini-2.5-flash-lite" {
+			t.Fatalf("unexpected budget: %+v", budget)
+		}
+		if budget.RemainingTokens == nil || *budget.RemainingTokens != 700 {
+			t.Errorf("remaining tokens = %v, want 700", budget.RemainingTokens)
+		}
+		if budget.RemainingBehaviors != nil {
+			t.Errorf("behaviors should be unlimited, got %d", *budget.RemainingBehaviors)
+		}
+	})
+
+	t.Run("should return no budget outside tenants", func(t *testing.T) {
+		for _, userID := range []string{outsiderID, "not-a-uuid"} {
+			budget, err := repo.GetRemainingBudget(ctx, userID)
+			if err != nil || budget != nil {
+				t.Errorf("GetRemainingBudget(%q) = %+v, %v; want nil", userID, budget, err)
+			}
+		}
+	})
+
+	t.Run("should resolve the tenant of members only", func(t *testing.T) {
+		if got, err := repo.GetUserTenantID(ctx, memberID); err != nil || got != tenantID {
+			t.Errorf("GetUserTenantID(member) = %q, %v; want %q", got, err, tenantID)
+		}
+		for _, userID := range []string{outsiderID, "not-a-uuid"} {
+			if got, err := repo.GetUserTenantID(ctx, userID); err != nil || got != "" {
+				t.Errorf("GetUserTenantID(%q) = %q, %v; want no tenant", userID, got, err)
+			}
+		}
+	})
+
+	t.Run("should reject invalid token usage", func(t *testing.T) {
+		if err := repo.RecordTokenUsage(ctx, "not-a-uuid", 42, 10); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+		if err := repo.RecordTokenUsage(ctx, tenantID, 42, -1); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestSpecDocumentRepository_GetQuotaStatus(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewSpecDocumentRepository(pool)
+	ctx := context.Background()
+	userID := setupTestUser(t, ctx, pool)
+
+	status, err := repo.GetQuotaStatus(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetQuotaStatus failed: %v", err)
+	}
+	if status.Limit != nil || status.Used != 0 {
+		t.Errorf("expected unlimited unused quota without plans, got %+v", status)
+	}
+
+	if _, err := pool.Exec(ctx, `INSERT INTO subscription_plans (tier, specview_monthly_limit) VALUES ('free', 100)`); err != nil {
+		t.Fatalf("insert free plan: %v", err)
+	}
+	status, err = repo.GetQuotaStatus(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetQuotaStatus failed: %v", err)
+	}
+	if status.Limit == nil || *status.Limit != 100 {
+		t.Errorf("expected the free plan limit, got %+v", status)
+	}
+
+	if status, err := repo.GetQuotaStatus(ctx, "not-a-uuid"); err != nil || status != nil {
+		t.Errorf("expected nil status for a non-UUID user, got %+v, %v", status, err)
+	}
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ specview.ExportRepository = (*SpecExportRepository)(nil)
+
+// SpecExportRepository loads spec documents for export and stores the
+// rendered artifacts in spec_document_exports.
+type SpecExportRepository struct {
+	keyring *DocumentKeyring
+	pool    *pgxpool.Pool
+}
+
+func NewSpecExportRepository(pool *pgxpool.Pool, opts ...DocumentOption) *SpecExportRepository {
+	o := applyDocumentOptions(opts)
+	return &SpecExportRepository{keyring: o.keyring, pool: pool}
+}
+
+func (r *SpecExportRepository) GetExportSource(ctx context.Context, documentID string) (*specview.ExportSource, error) {
+	parsedDocID, err := analysis.ParseUUID(documentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+	pgDocID := toPgUUID(parsedDocID)
+
+	row, err := queries.GetSpecDocumentByID(ctx, pgDocID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, specview.ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("get spec document: %w", err)
+	}
+
+	content, err := queries.GetSpecDocumentContent(ctx, pgDocID)
+	if err != nil {
+		return nil, fmt.Errorf("get spec document content: %w", err)
+	}
+
+	doc, err := openDocument(ctx, r.keyring, queries, row, content)
+	if err != nil {
+		return nil, err
+	}
+	src := &specview.ExportSource{Document: doc}
+
+	repo, err := queries.GetAnalysisContext(ctx, row.AnalysisID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("get analysis context: %w", err)
+	}
+	if err == nil {
+		src.Repository = repo.Owner + "/" + repo.Repo
+	}
+
+	helperRows, err := queries.GetSharedHelperTestCases(ctx, row.AnalysisID)
+	if err != nil {
+		return nil, fmt.Errorf("get shared helper test cases: %w", err)
+	}
+	src.SharedFixtures = groupSharedFixtures(helperRows)
+	return src, nil
+}
+
+// groupSharedFixtures folds the per test case rows into fixtures, keeping
+// their order.
+func groupSharedFixtures(rows []db.GetSharedHelperTestCasesRow) []specview.SharedFixture {
+	var fixtures []specview.SharedFixture
+	index := make(map[string]int)
+	for _, row := range rows {
+		i, ok := index[row.HelperPath]
+		if !ok {
+			i = len(fixtures)
+			index[row.HelperPath] = i
+			fixtures = append(fixtures, specview.SharedFixture{
+				Path:          row.HelperPath,
+				TestFileCount: int(row.TestFileCount),
+			})
+		}
+		fixtures[i].TestCaseIDs = append(fixtures[i].TestCaseIDs, fromPgUUID(row.TestCaseID).String())
+	}
+	return fixtures
+}
+
+func (r *SpecExportRepository) SaveExport(ctx context.Context, artifact *specview.ExportArtifact) error {
+	if artifact == nil {
+		return fmt.Errorf("%w: artifact is nil", specview.ErrInvalidInput)
+	}
+
+	parsedDocID, err := analysis.ParseUUID(artifact.DocumentID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+	err = queries.UpsertSpecDocumentExport(ctx, db.UpsertSpecDocumentExportParams{
+		Content:     artifact.Data,
+		ContentType: artifact.ContentType,
+		DocumentID:  toPgUUID(parsedDocID),
+		FileName:    artifact.FileName,
+		Format:      string(artifact.Format),
+	})
+	if err != nil {
+		return fmt.Errorf("upsert spec document export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestSpecExportRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	specRepo := NewSpecDocumentRepository(pool)
+	exportRepo := NewSpecExportRepository(pool)
+	ctx := context.Background()
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	userID := setupTestUser(t, ctx, pool)
+
+	doc := &specview.SpecDocument{
+		AnalysisID:  analysisID.String(),
+		ContentHash: []byte("export-hash"),
+		Language:    "English",
+		ModelID:     "gemini-2.5-flash",
+		UserID:      userID,
+		Domains: []specview.Domain{{
+			Name: "Auth",
+			Features: []specview.Feature{{
+				Name:      "Login",
+				Behaviors: []specview.Behavior{{OriginalName: "TestLogin", Description: "Logs in"}},
+			}},
+		}},
+	}
+	if err := specRepo.SaveDocument(ctx, doc); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+
+	t.Run("should load the full document", func(t *testing.T) {
+		src, err := exportRepo.GetExportSource(ctx, doc.ID)
+		if err != nil {
+			t.Fatalf("GetExportSource failed: %v", err)
+		}
+		if src.Repository == "" {
+			t.Error("expected the repository name")
+		}
+		domains := src.Document.Domains
+		if len(domains) != 1 || len(domains[0].Features) != 1 || domains[0].Features[0].Behaviors[0].Description != "Logs in" {
+			t.Errorf("unexpected content: %+v", domains)
+		}
+	})
+
+	t.Run("should load the shared fixtures of the analysis", func(t *testing.T) {
+		helpers := []analysis.SharedHelper{{Path: "src/testutil", TestFiles: []string{"src/user_test.go"}}}
+		if err := analysisRepo.SaveSharedHelpers(ctx, analysis.UUID(analysisID.id), helpers); err != nil {
+			t.Fatalf("SaveSharedHelpers failed: %v", err)
+		}
+
+		src, err := exportRepo.GetExportSource(ctx, doc.ID)
+		if err != nil {
+			t.Fatalf("GetExportSource failed: %v", err)
+		}
+		if len(src.SharedFixtures) != 1 {
+			t.Fatalf("expected one shared fixture, got %+v", src.SharedFixtures)
+		}
+		fixture := src.SharedFixtures[0]
+		if fixture.Path != "src/testutil" || fixture.TestFileCount != 1 || len(fixture.TestCaseIDs) == 0 {
+			t.Errorf("unexpected fixture %+v", fixture)
+		}
+
+		if err := analysisRepo.SaveSharedHelpers(ctx, analysis.NilUUID, helpers); !errors.Is(err, analysis.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("should report a missing document", func(t *testing.T) {
+		_, err := exportRepo.GetExportSource(ctx, "00000000-0000-0000-0000-000000000001")
+		if !errors.Is(err, specview.ErrDocumentNotFound) {
+			t.Errorf("expected ErrDocumentNotFound, got %v", err)
+		}
+	})
+
+	t.Run("should replace the artifact of the same format", func(t *testing.T) {
+		for _, data := range []string{"# v1", "# v2"} {
+			err := exportRepo.SaveExport(ctx, &specview.ExportArtifact{
+				ContentType: "text/markdown; charset=utf-8",
+				Data:        []byte(data),
+				DocumentID:  doc.ID,
+				FileName:    "spec-v1.md",
+				Format:      specview.ExportMarkdown,
+			})
+			if err != nil {
+				t.Fatalf("SaveExport failed: %v", err)
+			}
+		}
+
+		var count int
+		var content []byte
+		err := pool.QueryRow(ctx, `
+			SELECT COUNT(*) OVER (), content FROM spec_document_exports WHERE document_id = $1
+		`, doc.ID).Scan(&count, &content)
+		if err != nil {
+			t.Fatalf("query exports: %v", err)
+		}
+		if count != 1 || string(content) != "# v2" {
+			t.Errorf("expected one replaced export, got %d rows with %q", count, content)
+		}
+	})
+}
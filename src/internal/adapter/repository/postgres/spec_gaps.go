@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ specview.GapRepository = (*SpecGapRepository)(nil)
+
+type SpecGapRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSpecGapRepository(pool *pgxpool.Pool) *SpecGapRepository {
+	return &SpecGapRepository{pool: pool}
+}
+
+func (r *SpecGapRepository) GetGapInputs(ctx context.Context, documentID string) (*specview.GapInputs, error) {
+	parsedDocID, err := analysis.ParseUUID(documentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+	pgDocID := toPgUUID(parsedDocID)
+
+	sourceFiles, err := queries.GetSourceFilePathsByDocumentID(ctx, pgDocID)
+	if err != nil {
+		return nil, fmt.Errorf("get source files: %w", err)
+	}
+
+	testFiles, err := queries.GetMappedTestFilePathsByDocumentID(ctx, pgDocID)
+	if err != nil {
+		return nil, fmt.Errorf("get mapped test files: %w", err)
+	}
+
+	return &specview.GapInputs{
+		MappedTestFiles: testFiles,
+		SourceFiles:     sourceFiles,
+	}, nil
+}
+
+func (r *SpecGapRepository) SaveGapReport(ctx context.Context, report *specview.GapReport) error {
+	if report == nil {
+		return fmt.Errorf("%w: report is nil", specview.ErrInvalidInput)
+	}
+
+	parsedDocID, err := analysis.ParseUUID(report.DocumentID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+
+	packages := report.UncoveredPackages
+	if packages == nil {
+		packages = []specview.GapPackage{}
+	}
+	packagesJSON, err := json.Marshal(packages)
+	if err != nil {
+		return fmt.Errorf("marshal uncovered packages: %w", err)
+	}
+
+	files := report.UncoveredFiles
+	if files == nil {
+		files = []string{}
+	}
+	filesJSON, err := json.Marshal(files)
+	if err != nil {
+		return fmt.Errorf("marshal uncovered files: %w", err)
+	}
+
+	queries := db.New(r.pool)
+
+	if err := queries.UpsertSpecGapReport(ctx, db.UpsertSpecGapReportParams{
+		DocumentID:         toPgUUID(parsedDocID),
+		TotalSourceFiles:   int32(report.TotalSourceFiles),
+		CoveredSourceFiles: int32(report.CoveredSourceFiles),
+		UncoveredPackages:  packagesJSON,
+		UncoveredFiles:     filesJSON,
+	}); err != nil {
+		return fmt.Errorf("upsert spec gap report: %w", err)
+	}
+
+	return nil
+}
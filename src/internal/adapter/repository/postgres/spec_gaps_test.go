@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestSpecGapRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	specRepo := NewSpecDocumentRepository(pool)
+	gapRepo := NewSpecGapRepository(pool)
+	ctx := context.Background()
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	userID := setupTestUser(t, ctx, pool)
+
+	var testCaseID, testFilePath string
+	if err := pool.QueryRow(ctx, `
+		SELECT tc.id::text, tf.file_path FROM test_cases tc
+		JOIN test_suites ts ON ts.id = tc.suite_id
+		JOIN test_files tf ON tf.id = ts.file_id
+		WHERE tf.analysis_id = $1
+		LIMIT 1
+	`, analysisID.String()).Scan(&testCaseID, &testFilePath); err != nil {
+		t.Fatalf("failed to get test case: %v", err)
+	}
+
+	doc := &specview.SpecDocument{
+		AnalysisID:  analysisID.String(),
+		ContentHash: []byte("gap-hash"),
+		Language:    "English",
+		ModelID:     "gemini-2.5-flash",
+		UserID:      userID,
+		Domains: []specview.Domain{{
+			Name: "Auth",
+			Features: []specview.Feature{{
+				Name:      "Login",
+				Behaviors: []specview.Behavior{{OriginalName: "TestLogin", Description: "Logs in", TestCaseID: testCaseID}},
+			}},
+		}},
+	}
+	if err := specRepo.SaveDocument(ctx, doc); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+
+	parsedAnalysisID, err := analysis.ParseUUID(analysisID.String())
+	if err != nil {
+		t.Fatalf("ParseUUID failed: %v", err)
+	}
+	if err := analysisRepo.SaveSourceFiles(ctx, parsedAnalysisID, []string{"pkg/a.go", "pkg/b.go"}); err != nil {
+		t.Fatalf("SaveSourceFiles failed: %v", err)
+	}
+
+	t.Run("should load source and mapped test files", func(t *testing.T) {
+		inputs, err := gapRepo.GetGapInputs(ctx, doc.ID)
+		if err != nil {
+			t.Fatalf("GetGapInputs failed: %v", err)
+		}
+		if len(inputs.SourceFiles) != 2 {
+			t.Errorf("expected 2 source files, got %v", inputs.SourceFiles)
+		}
+		if len(inputs.MappedTestFiles) != 1 || inputs.MappedTestFiles[0] != testFilePath {
+			t.Errorf("expected mapped test file %q, got %v", testFilePath, inputs.MappedTestFiles)
+		}
+	})
+
+	t.Run("should upsert report", func(t *testing.T) {
+		report := &specview.GapReport{
+			DocumentID:        doc.ID,
+			TotalSourceFiles:  2,
+			UncoveredFiles:    []string{"pkg/a.go", "pkg/b.go"},
+			UncoveredPackages: []specview.GapPackage{{FileCount: 2, Path: "pkg"}},
+		}
+		for range 2 {
+			if err := gapRepo.SaveGapReport(ctx, report); err != nil {
+				t.Fatalf("SaveGapReport failed: %v", err)
+			}
+		}
+
+		var count, total int
+		pool.QueryRow(ctx, "SELECT COUNT(*), MAX(total_source_files) FROM spec_gap_reports WHERE document_id = $1", doc.ID).Scan(&count, &total)
+		if count != 1 || total != 2 {
+			t.Errorf("expected 1 report with 2 files, got count=%d total=%d", count, total)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var (
+	_ specview.SearchIndexer          = (*SpecSearchRepository)(nil)
+	_ specview.SearchSourceRepository = (*SpecSearchRepository)(nil)
+)
+
+// SpecSearchRepository indexes spec behaviors into Postgres full-text search.
+// The tsvector column is generated by the database, so only raw text is written here.
+type SpecSearchRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSpecSearchRepository(pool *pgxpool.Pool) *SpecSearchRepository {
+	return &SpecSearchRepository{pool: pool}
+}
+
+func (r *SpecSearchRepository) GetSearchIndexEntries(
+	ctx context.Context,
+	documentID string,
+) ([]specview.SearchIndexEntry, error) {
+	parsedDocID, err := analysis.ParseUUID(documentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+
+	queries := db.New(r.pool)
+
+	rows, err := queries.GetSpecSearchSourceByDocumentID(ctx, toPgUUID(parsedDocID))
+	if err != nil {
+		return nil, fmt.Errorf("get spec search source: %w", err)
+	}
+
+	entries := make([]specview.SearchIndexEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = specview.SearchIndexEntry{
+			BehaviorDescription: row.BehaviorDescription,
+			BehaviorID:          fromPgUUID(row.BehaviorID).String(),
+			CodebaseID:          fromPgUUID(row.CodebaseID).String(),
+			DocumentID:          fromPgUUID(row.DocumentID).String(),
+			DocumentVersion:     row.DocumentVersion,
+			DomainName:          row.DomainName,
+			FeatureName:         row.FeatureName,
+			Language:            specview.Language(row.Language),
+		}
+	}
+
+	return entries, nil
+}
+
+func (r *SpecSearchRepository) ReplaceDocumentEntries(
+	ctx context.Context,
+	documentID string,
+	entries []specview.SearchIndexEntry,
+) error {
+	parsedDocID, err := analysis.ParseUUID(documentID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid document ID format", specview.ErrInvalidInput)
+	}
+
+	rows := make([][]any, len(entries))
+	for i, e := range entries {
+		if e.DocumentID != documentID {
+			return fmt.Errorf("%w: entry belongs to document %s", specview.ErrInvalidInput, e.DocumentID)
+		}
+		codebaseID, err := analysis.ParseUUID(e.CodebaseID)
+		if err != nil {
+			return fmt.Errorf("%w: invalid codebase ID", specview.ErrInvalidInput)
+		}
+		behaviorID, err := analysis.ParseUUID(e.BehaviorID)
+		if err != nil {
+			return fmt.Errorf("%w: invalid behavior ID", specview.ErrInvalidInput)
+		}
+		rows[i] = []any{
+			toPgUUID(parsedDocID),
+			toPgUUID(codebaseID),
+			e.DocumentVersion,
+			string(e.Language),
+			toPgUUID(behaviorID),
+			e.DomainName,
+			e.FeatureName,
+			e.BehaviorDescription,
+		}
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "ReplaceDocumentEntries",
+				"document_id", documentID,
+				"error", rbErr,
+			)
+		}
+	}()
+
+	queries := db.New(tx)
+
+	if _, err := queries.DeleteSpecSearchEntriesByDocumentID(ctx, toPgUUID(parsedDocID)); err != nil {
+		return fmt.Errorf("delete spec search entries: %w", err)
+	}
+
+	if len(rows) > 0 {
+		_, err = tx.Conn().CopyFrom(
+			ctx,
+			pgx.Identifier{"spec_search_entries"},
+			db.SpecSearchEntryCopyColumns,
+			pgx.CopyFromRows(rows),
+		)
+		if err != nil {
+			return fmt.Errorf("copy spec search entries: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
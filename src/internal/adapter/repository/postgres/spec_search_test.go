@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestSpecSearchRepository_ReplaceDocumentEntries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	specRepo := NewSpecDocumentRepository(pool)
+	searchRepo := NewSpecSearchRepository(pool)
+	ctx := context.Background()
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	userID := setupTestUser(t, ctx, pool)
+
+	doc := &specview.SpecDocument{
+		AnalysisID:  analysisID.String(),
+		ContentHash: []byte("search-hash"),
+		Language:    "English",
+		ModelID:     "gemini-2.5-flash",
+		UserID:      userID,
+		Domains: []specview.Domain{
+			{
+				Name: "Payments",
+				Features: []specview.Feature{
+					{
+						Name: "Refunds",
+						Behaviors: []specview.Behavior{
+							{OriginalName: "TestRefundPartial", Description: "Issues a partial refund to the card"},
+							{OriginalName: "TestRefundDenied", Description: "Rejects refund after settlement window"},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := specRepo.SaveDocument(ctx, doc); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+
+	entries, err := searchRepo.GetSearchIndexEntries(ctx, doc.ID)
+	if err != nil {
+		t.Fatalf("GetSearchIndexEntries failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].FeatureName != "Refunds" || entries[0].DocumentVersion != 1 {
+		t.Errorf("unexpected entry: %+v", entries[0])
+	}
+
+	t.Run("should be searchable by full-text query", func(t *testing.T) {
+		if err := searchRepo.ReplaceDocumentEntries(ctx, doc.ID, entries); err != nil {
+			t.Fatalf("ReplaceDocumentEntries failed: %v", err)
+		}
+
+		var matches int
+		err := pool.QueryRow(ctx, `
+			SELECT COUNT(*) FROM spec_search_entries
+			WHERE codebase_id = $1 AND search_vector @@ plainto_tsquery('simple', 'refund')
+		`, entries[0].CodebaseID).Scan(&matches)
+		if err != nil {
+			t.Fatalf("search query failed: %v", err)
+		}
+		if matches != 2 {
+			t.Errorf("expected 2 matches, got %d", matches)
+		}
+	})
+
+	t.Run("should be idempotent on re-index", func(t *testing.T) {
+		if err := searchRepo.ReplaceDocumentEntries(ctx, doc.ID, entries[:1]); err != nil {
+			t.Fatalf("ReplaceDocumentEntries failed: %v", err)
+		}
+
+		var count int
+		pool.QueryRow(ctx, "SELECT COUNT(*) FROM spec_search_entries WHERE document_id = $1", doc.ID).Scan(&count)
+		if count != 1 {
+			t.Errorf("expected 1 entry after replace, got %d", count)
+		}
+	})
+
+	t.Run("should reject entries from another document", func(t *testing.T) {
+		foreign := entries[0]
+		foreign.DocumentID = "00000000-0000-0000-0000-000000000001"
+		if err := searchRepo.ReplaceDocumentEntries(ctx, doc.ID, []specview.SearchIndexEntry{foreign}); err == nil {
+			t.Error("expected error for foreign entry")
+		}
+	})
+}
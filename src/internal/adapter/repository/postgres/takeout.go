@@ -0,0 +1,274 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/takeout"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ takeout.Repository = (*TakeoutRepository)(nil)
+
+// TakeoutRepository reads tenant data for takeouts and tracks them in tenant_takeouts.
+type TakeoutRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTakeoutRepository creates a new TakeoutRepository.
+func NewTakeoutRepository(pool *pgxpool.Pool) *TakeoutRepository {
+	return &TakeoutRepository{pool: pool}
+}
+
+func (r *TakeoutRepository) GetTakeout(ctx context.Context, id string) (*takeout.Takeout, error) {
+	pgID, err := parseTakeoutID(id, "takeout")
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := db.New(r.pool).GetTenantTakeout(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, takeout.ErrNotFound
+		}
+		return nil, fmt.Errorf("get takeout: %w", err)
+	}
+	return &takeout.Takeout{
+		ID:         fromPgUUID(row.ID).String(),
+		Status:     takeout.Status(row.Status),
+		TenantID:   fromPgUUID(row.TenantID).String(),
+		TenantName: row.TenantName,
+	}, nil
+}
+
+func (r *TakeoutRepository) StartTakeout(ctx context.Context, id string) (bool, error) {
+	pgID, err := parseTakeoutID(id, "takeout")
+	if err != nil {
+		return false, err
+	}
+
+	n, err := db.New(r.pool).StartTenantTakeout(ctx, pgID)
+	if err != nil {
+		return false, fmt.Errorf("start takeout: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *TakeoutRepository) CompleteTakeout(ctx context.Context, id string, archive takeout.Archive) error {
+	pgID, err := parseTakeoutID(id, "takeout")
+	if err != nil {
+		return err
+	}
+
+	if err := db.New(r.pool).CompleteTenantTakeout(ctx, db.CompleteTenantTakeoutParams{
+		DownloadExpiresAt: pgtype.Timestamptz{Time: archive.ExpiresAt, Valid: true},
+		DownloadUrl:       pgtype.Text{String: archive.DownloadURL, Valid: true},
+		ID:                pgID,
+		ObjectKey:         pgtype.Text{String: archive.ObjectKey, Valid: true},
+		SizeBytes:         pgtype.Int8{Int64: archive.SizeBytes, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("complete takeout: %w", err)
+	}
+	return nil
+}
+
+func (r *TakeoutRepository) FailTakeout(ctx context.Context, id string, reason string) error {
+	pgID, err := parseTakeoutID(id, "takeout")
+	if err != nil {
+		return err
+	}
+
+	if err := db.New(r.pool).FailTenantTakeout(ctx, db.FailTenantTakeoutParams{
+		ErrorMessage: pgtype.Text{String: reason, Valid: true},
+		ID:           pgID,
+	}); err != nil {
+		return fmt.Errorf("fail takeout: %w", err)
+	}
+	return nil
+}
+
+func (r *TakeoutRepository) ListMembers(ctx context.Context, tenantID string) ([]takeout.Member, error) {
+	pgID, err := parseTakeoutID(tenantID, "tenant")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.New(r.pool).ListTenantMembers(ctx, pgID)
+	if err != nil {
+		return nil, fmt.Errorf("list tenant members: %w", err)
+	}
+
+	members := make([]takeout.Member, len(rows))
+	for i, row := range rows {
+		members[i] = takeout.Member{
+			JoinedAt: row.CreatedAt.Time,
+			UserID:   fromPgUUID(row.UserID).String(),
+		}
+	}
+	return members, nil
+}
+
+func (r *TakeoutRepository) ListCodebases(ctx context.Context, tenantID string) ([]takeout.Codebase, error) {
+	pgID, err := parseTakeoutID(tenantID, "tenant")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.New(r.pool).ListTenantCodebases(ctx, pgID)
+	if err != nil {
+		return nil, fmt.Errorf("list tenant codebases: %w", err)
+	}
+
+	codebases := make([]takeout.Codebase, len(rows))
+	for i, row := range rows {
+		codebases[i] = takeout.Codebase{
+			CreatedAt:     row.CreatedAt.Time,
+			DefaultBranch: row.DefaultBranch.String,
+			Host:          row.Host,
+			ID:            fromPgUUID(row.ID).String(),
+			IsPrivate:     row.IsPrivate,
+			Name:          row.Name,
+			Owner:         row.Owner,
+		}
+	}
+	return codebases, nil
+}
+
+func (r *TakeoutRepository) ListAnalyses(ctx context.Context, tenantID string) ([]takeout.Analysis, error) {
+	pgID, err := parseTakeoutID(tenantID, "tenant")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.New(r.pool).ListTenantAnalyses(ctx, pgID)
+	if err != nil {
+		return nil, fmt.Errorf("list tenant analyses: %w", err)
+	}
+
+	analyses := make([]takeout.Analysis, len(rows))
+	for i, row := range rows {
+		analyses[i] = takeout.Analysis{
+			BranchName:    row.BranchName.String,
+			CodebaseID:    fromPgUUID(row.CodebaseID).String(),
+			CommitSHA:     row.CommitSha,
+			CompletedAt:   optionalTime(row.CompletedAt),
+			CreatedAt:     row.CreatedAt.Time,
+			ID:            fromPgUUID(row.ID).String(),
+			ParserVersion: row.ParserVersion,
+			Status:        string(row.Status),
+			TotalSuites:   int(row.TotalSuites),
+			TotalTests:    int(row.TotalTests),
+		}
+	}
+	return analyses, nil
+}
+
+func (r *TakeoutRepository) ListTests(ctx context.Context, analysisID string) ([]takeout.Test, error) {
+	pgID, err := parseTakeoutID(analysisID, "analysis")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.New(r.pool).GetTestRefsByAnalysisID(ctx, pgID)
+	if err != nil {
+		return nil, fmt.Errorf("list analysis tests: %w", err)
+	}
+
+	tests := make([]takeout.Test, len(rows))
+	for i, row := range rows {
+		tests[i] = takeout.Test{
+			FilePath:  row.FilePath,
+			Name:      row.TestName,
+			SuitePath: row.SuitePath,
+		}
+	}
+	return tests, nil
+}
+
+func (r *TakeoutRepository) ListDocumentIDs(ctx context.Context, tenantID string) ([]string, error) {
+	pgID, err := parseTakeoutID(tenantID, "tenant")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.New(r.pool).ListTenantDocumentIDs(ctx, pgID)
+	if err != nil {
+		return nil, fmt.Errorf("list tenant documents: %w", err)
+	}
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = fromPgUUID(row).String()
+	}
+	return ids, nil
+}
+
+func (r *TakeoutRepository) ListUsageEvents(ctx context.Context, tenantID string) ([]takeout.UsageEvent, error) {
+	pgID, err := parseTakeoutID(tenantID, "tenant")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.New(r.pool).ListTenantUsageEvents(ctx, pgID)
+	if err != nil {
+		return nil, fmt.Errorf("list tenant usage events: %w", err)
+	}
+
+	events := make([]takeout.UsageEvent, len(rows))
+	for i, row := range rows {
+		events[i] = takeout.UsageEvent{
+			AnalysisID:  optionalID(row.AnalysisID),
+			CreatedAt:   row.CreatedAt.Time,
+			DocumentID:  optionalID(row.DocumentID),
+			EventType:   string(row.EventType),
+			ID:          fromPgUUID(row.ID).String(),
+			QuotaAmount: int(row.QuotaAmount),
+			UserID:      fromPgUUID(row.UserID).String(),
+		}
+	}
+	return events, nil
+}
+
+func (r *TakeoutRepository) ListAIUsage(ctx context.Context, tenantID string) ([]takeout.AIUsage, error) {
+	pgID, err := parseTakeoutID(tenantID, "tenant")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.New(r.pool).ListTenantAIUsage(ctx, pgID)
+	if err != nil {
+		return nil, fmt.Errorf("list tenant AI usage: %w", err)
+	}
+
+	usage := make([]takeout.AIUsage, len(rows))
+	for i, row := range rows {
+		usage[i] = takeout.AIUsage{
+			CreatedAt:  row.CreatedAt.Time,
+			DocumentID: optionalID(row.DocumentID),
+			ID:         fromPgUUID(row.ID).String(),
+			Tokens:     row.Tokens,
+		}
+	}
+	return usage, nil
+}
+
+func parseTakeoutID(id, kind string) (pgtype.UUID, error) {
+	parsed, err := analysis.ParseUUID(id)
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("%w: invalid %s ID format", takeout.ErrInvalidInput, kind)
+	}
+	return toPgUUID(parsed), nil
+}
+
+// optionalID returns the empty string for a NULL ID.
+func optionalID(id pgtype.UUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return fromPgUUID(id).String()
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/domain/takeout"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestTakeoutRepository_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewTakeoutRepository(pool)
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, NewAnalysisRepository(pool), pool)
+	userID := setupTestUser(t, ctx, pool)
+	outsiderID := setupTestUser(t, ctx, pool)
+
+	var tenantID, takeoutID string
+	if err := pool.QueryRow(ctx, "INSERT INTO tenants (name) VALUES ('acme') RETURNING id::text").Scan(&tenantID); err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "INSERT INTO tenant_members (tenant_id, user_id) VALUES ($1, $2)", tenantID, userID); err != nil {
+		t.Fatalf("failed to add member: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "INSERT INTO user_analysis_history (user_id, analysis_id) VALUES ($1, $2)", userID, analysisID.String()); err != nil {
+		t.Fatalf("failed to add history: %v", err)
+	}
+	doc := &specview.SpecDocument{
+		AnalysisID:  analysisID.String(),
+		ContentHash: []byte("takeout-hash"),
+		Language:    "English",
+		ModelID:     "gemini-2.5-flash",
+		UserID:      userID,
+	}
+	if err := NewSpecDocumentRepository(pool).SaveDocument(ctx, doc); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+	for _, user := range []string{userID, outsiderID} {
+		if _, err := pool.Exec(ctx, `
+			INSERT INTO usage_events (user_id, event_type, document_id, quota_amount) VALUES ($1, 'specview', $2, 3)
+		`, user, doc.ID); err != nil {
+			t.Fatalf("failed to add usage: %v", err)
+		}
+	}
+	if err := pool.QueryRow(ctx, "INSERT INTO tenant_takeouts (tenant_id) VALUES ($1) RETURNING id::text", tenantID).Scan(&takeoutID); err != nil {
+		t.Fatalf("failed to create takeout: %v", err)
+	}
+
+	t.Run("should list the tenant's data only", func(t *testing.T) {
+		members, err := repo.ListMembers(ctx, tenantID)
+		if err != nil || len(members) != 1 || members[0].UserID != userID {
+			t.Errorf("ListMembers = %+v, %v", members, err)
+		}
+		codebases, err := repo.ListCodebases(ctx, tenantID)
+		if err != nil || len(codebases) != 1 {
+			t.Errorf("ListCodebases = %+v, %v", codebases, err)
+		}
+		analyses, err := repo.ListAnalyses(ctx, tenantID)
+		if err != nil || len(analyses) != 1 || analyses[0].ID != analysisID.String() {
+			t.Fatalf("ListAnalyses = %+v, %v", analyses, err)
+		}
+		tests, err := repo.ListTests(ctx, analyses[0].ID)
+		if err != nil || len(tests) == 0 || tests[0].SuitePath == "" {
+			t.Errorf("ListTests = %+v, %v", tests, err)
+		}
+		ids, err := repo.ListDocumentIDs(ctx, tenantID)
+		if err != nil || len(ids) != 1 || ids[0] != doc.ID {
+			t.Errorf("ListDocumentIDs = %v, %v", ids, err)
+		}
+		events, err := repo.ListUsageEvents(ctx, tenantID)
+		if err != nil || len(events) != 1 || events[0].UserID != userID || events[0].AnalysisID != "" {
+			t.Errorf("ListUsageEvents = %+v, %v", events, err)
+		}
+	})
+
+	t.Run("should track the takeout to completion", func(t *testing.T) {
+		got, err := repo.GetTakeout(ctx, takeoutID)
+		if err != nil || got.TenantID != tenantID || got.TenantName != "acme" || got.Status != takeout.StatusPending {
+			t.Fatalf("GetTakeout = %+v, %v", got, err)
+		}
+
+		started, err := repo.StartTakeout(ctx, takeoutID)
+		if err != nil || !started {
+			t.Fatalf("StartTakeout = %v, %v", started, err)
+		}
+		if err := repo.CompleteTakeout(ctx, takeoutID, takeout.Archive{
+			DownloadURL: "https://storage.example.com/a.zip?sig",
+			ObjectKey:   "takeouts/a.zip",
+			SizeBytes:   42,
+		}); err != nil {
+			t.Fatalf("CompleteTakeout failed: %v", err)
+		}
+		if err := repo.FailTakeout(ctx, takeoutID, "late failure"); err != nil {
+			t.Fatalf("FailTakeout failed: %v", err)
+		}
+
+		got, err = repo.GetTakeout(ctx, takeoutID)
+		if err != nil || got.Status != takeout.StatusCompleted {
+			t.Errorf("expected a completed takeout to stay completed, got %+v, %v", got, err)
+		}
+		if started, err := repo.StartTakeout(ctx, takeoutID); err != nil || started {
+			t.Errorf("StartTakeout on a completed takeout = %v, %v, want false", started, err)
+		}
+	})
+
+	t.Run("should report a missing takeout", func(t *testing.T) {
+		if _, err := repo.GetTakeout(ctx, "0b6f4c1e-8d9a-4b3e-9a57-2f1d6c0e7a11"); !errors.Is(err, takeout.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.GetTakeout(ctx, "not-a-uuid"); !errors.Is(err, takeout.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ analysis.TestDeltaRepository = (*TestDeltaRepository)(nil)
+
+// TestDeltaRepository reads analysis inventories for comparison and stores test deltas.
+type TestDeltaRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTestDeltaRepository creates a new TestDeltaRepository.
+func NewTestDeltaRepository(pool *pgxpool.Pool) *TestDeltaRepository {
+	return &TestDeltaRepository{pool: pool}
+}
+
+func (r *TestDeltaRepository) FindCompletedAnalysis(ctx context.Context, owner, repo, commitSHA string) (analysis.UUID, error) {
+	queries := db.New(r.pool)
+	id, err := queries.FindCompletedAnalysisByCommit(ctx, db.FindCompletedAnalysisByCommitParams{
+		Host:      defaultHost,
+		Owner:     owner,
+		Name:      repo,
+		CommitSha: commitSHA,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return analysis.NilUUID, nil
+		}
+		return analysis.NilUUID, fmt.Errorf("find completed analysis: %w", err)
+	}
+	return fromPgUUID(id), nil
+}
+
+func (r *TestDeltaRepository) GetTestRefs(ctx context.Context, analysisID analysis.UUID) ([]analysis.TestRef, error) {
+	queries := db.New(r.pool)
+	rows, err := queries.GetTestRefsByAnalysisID(ctx, toPgUUID(analysisID))
+	if err != nil {
+		return nil, fmt.Errorf("get test refs: %w", err)
+	}
+
+	refs := make([]analysis.TestRef, len(rows))
+	for i, row := range rows {
+		refs[i] = analysis.TestRef{
+			FilePath:  row.FilePath,
+			Name:      row.TestName,
+			SuitePath: row.SuitePath,
+		}
+	}
+	return refs, nil
+}
+
+func (r *TestDeltaRepository) SaveTestDelta(ctx context.Context, params analysis.SaveTestDeltaParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	delta := params.Delta
+	// Empty lists rather than null keep the details readable by consumers.
+	details, err := json.Marshal(analysis.TestDelta{
+		AddedSuites:   nonNil(delta.AddedSuites),
+		AddedTests:    nonNil(delta.AddedTests),
+		RemovedSuites: nonNil(delta.RemovedSuites),
+		RemovedTests:  nonNil(delta.RemovedTests),
+		RenamedTests:  nonNil(delta.RenamedTests),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal test delta: %w", err)
+	}
+
+	queries := db.New(r.pool)
+	if err := queries.UpsertTestDelta(ctx, db.UpsertTestDeltaParams{
+		BaseAnalysisID:    toPgUUID(params.BaseAnalysisID),
+		PullRequestNumber: pgtype.Int4{Int32: int32(params.PullRequest), Valid: params.PullRequest > 0},
+		TestsAdded:        int32(len(delta.AddedTests)),
+		TestsRemoved:      int32(len(delta.RemovedTests)),
+		TestsRenamed:      int32(len(delta.RenamedTests)),
+		SuitesAdded:       int32(len(delta.AddedSuites)),
+		SuitesRemoved:     int32(len(delta.RemovedSuites)),
+		Details:           details,
+		HeadAnalysisID:    toPgUUID(params.HeadAnalysisID),
+	}); err != nil {
+		return fmt.Errorf("upsert test delta: %w", err)
+	}
+	return nil
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestTestDeltaRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	deltaRepo := NewTestDeltaRepository(pool)
+	ctx := context.Background()
+
+	wrapped := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	analysisID, err := analysis.ParseUUID(wrapped.String())
+	if err != nil {
+		t.Fatalf("ParseUUID failed: %v", err)
+	}
+
+	var repoName string
+	if err := pool.QueryRow(ctx, `
+		SELECT c.name FROM codebases c JOIN analyses a ON a.codebase_id = c.id WHERE a.id = $1
+	`, wrapped.String()).Scan(&repoName); err != nil {
+		t.Fatalf("failed to get codebase name: %v", err)
+	}
+
+	t.Run("should find the completed analysis of a commit", func(t *testing.T) {
+		id, err := deltaRepo.FindCompletedAnalysis(ctx, "testowner", repoName, "abc123def456")
+		if err != nil {
+			t.Fatalf("FindCompletedAnalysis failed: %v", err)
+		}
+		if id != analysisID {
+			t.Errorf("expected %s, got %s", analysisID, id)
+		}
+
+		id, err = deltaRepo.FindCompletedAnalysis(ctx, "testowner", repoName, "unknown")
+		if err != nil || id != analysis.NilUUID {
+			t.Errorf("expected NilUUID without error, got %s, %v", id, err)
+		}
+	})
+
+	t.Run("should load tests with nested suite paths", func(t *testing.T) {
+		refs, err := deltaRepo.GetTestRefs(ctx, analysisID)
+		if err != nil {
+			t.Fatalf("GetTestRefs failed: %v", err)
+		}
+		if len(refs) == 0 {
+			t.Fatal("expected test refs")
+		}
+		nested := false
+		for _, ref := range refs {
+			if ref.FilePath == "" || ref.SuitePath == "" || ref.Name == "" {
+				t.Errorf("incomplete ref: %+v", ref)
+			}
+			if strings.Contains(ref.SuitePath, " > ") {
+				nested = true
+			}
+		}
+		if !nested {
+			t.Errorf("expected a nested suite path, got %+v", refs)
+		}
+	})
+
+	t.Run("should upsert the delta for a base and head", func(t *testing.T) {
+		params := analysis.SaveTestDeltaParams{
+			BaseAnalysisID: analysisID,
+			Delta: analysis.TestDelta{
+				AddedTests: []analysis.TestRef{{FilePath: "a_test.go", SuitePath: "A", Name: "new"}},
+			},
+			HeadAnalysisID: analysisID,
+			PullRequest:    12,
+		}
+		if err := deltaRepo.SaveTestDelta(ctx, params); err != nil {
+			t.Fatalf("SaveTestDelta failed: %v", err)
+		}
+
+		params.Delta = analysis.TestDelta{
+			RemovedTests: []analysis.TestRef{{FilePath: "a_test.go", SuitePath: "A", Name: "old"}},
+		}
+		params.PullRequest = 0
+		if err := deltaRepo.SaveTestDelta(ctx, params); err != nil {
+			t.Fatalf("SaveTestDelta (update) failed: %v", err)
+		}
+
+		var count, added, removed int
+		var pullRequest *int
+		var renamedJSON string
+		if err := pool.QueryRow(ctx, `
+			SELECT count(*) OVER (), tests_added, tests_removed, pull_request_number, details->>'renamedTests'
+			FROM test_deltas WHERE head_analysis_id = $1
+		`, wrapped.String()).Scan(&count, &added, &removed, &pullRequest, &renamedJSON); err != nil {
+			t.Fatalf("failed to read test delta: %v", err)
+		}
+		if count != 1 || added != 0 || removed != 1 {
+			t.Errorf("unexpected delta row: count=%d added=%d removed=%d", count, added, removed)
+		}
+		if pullRequest == nil || *pullRequest != 12 {
+			t.Errorf("expected pull request number to be kept, got %v", pullRequest)
+		}
+		if renamedJSON != "[]" {
+			t.Errorf("expected empty renamed list, got %s", renamedJSON)
+		}
+	})
+}
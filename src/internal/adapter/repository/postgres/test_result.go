@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/domain/testrun"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ testrun.Repository = (*TestResultRepository)(nil)
+
+// TestResultRepository reads CI test report uploads and stores per-test-case
+// results in test_case_results.
+type TestResultRepository struct {
+	pool     *pgxpool.Pool
+	testData *SpecDocumentRepository
+}
+
+func NewTestResultRepository(pool *pgxpool.Pool) *TestResultRepository {
+	return &TestResultRepository{pool: pool, testData: NewSpecDocumentRepository(pool)}
+}
+
+func (r *TestResultRepository) GetUpload(ctx context.Context, uploadID string) (*testrun.Upload, error) {
+	parsedID, err := analysis.ParseUUID(uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid upload ID format", testrun.ErrInvalidInput)
+	}
+
+	row, err := db.New(r.pool).GetTestResultUpload(ctx, toPgUUID(parsedID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, testrun.ErrUploadNotFound
+		}
+		return nil, fmt.Errorf("get test result upload: %w", err)
+	}
+
+	return &testrun.Upload{
+		AnalysisID: fromPgUUID(row.AnalysisID).String(),
+		Content:    row.Content,
+		Format:     testrun.Format(row.Format),
+		ID:         fromPgUUID(row.ID).String(),
+	}, nil
+}
+
+func (r *TestResultRepository) GetTestCases(ctx context.Context, analysisID string) ([]testrun.TestCase, error) {
+	files, err := r.testData.GetTestDataByAnalysisID(ctx, analysisID)
+	if err != nil {
+		if errors.Is(err, specview.ErrInvalidInput) {
+			return nil, fmt.Errorf("%w: invalid analysis ID format", testrun.ErrInvalidInput)
+		}
+		return nil, err
+	}
+
+	var cases []testrun.TestCase
+	for _, f := range files {
+		for _, t := range f.Tests {
+			cases = append(cases, testrun.TestCase{
+				FilePath:  f.Path,
+				ID:        t.TestCaseID,
+				Name:      t.Name,
+				SuitePath: t.SuitePath,
+			})
+		}
+	}
+	return cases, nil
+}
+
+func (r *TestResultRepository) SaveResults(ctx context.Context, uploadID string, results []testrun.CaseResult) (bool, error) {
+	parsedUploadID, err := analysis.ParseUUID(uploadID)
+	if err != nil {
+		return false, fmt.Errorf("%w: invalid upload ID format", testrun.ErrInvalidInput)
+	}
+	pgUploadID := toPgUUID(parsedUploadID)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "SaveResults",
+				"upload_id", uploadID,
+				"error", rbErr,
+			)
+		}
+	}()
+
+	claimed, err := db.New(tx).MarkTestResultUploadProcessed(ctx, pgUploadID)
+	if err != nil {
+		return false, fmt.Errorf("mark upload processed: %w", err)
+	}
+	if claimed == 0 {
+		return false, nil
+	}
+
+	if len(results) > 0 {
+		batch := &pgx.Batch{}
+		for _, res := range results {
+			testCaseID, err := analysis.ParseUUID(res.TestCaseID)
+			if err != nil {
+				return false, fmt.Errorf("parse test case ID %q: %w", res.TestCaseID, err)
+			}
+			batch.Queue(db.UpsertTestCaseResultBatch,
+				toPgUUID(testCaseID),
+				string(res.Status),
+				res.Duration.Milliseconds(),
+				int32(res.Runs),
+				int32(res.Passes),
+				int32(res.Failures),
+				pgUploadID,
+			)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return false, fmt.Errorf("upsert test case results: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit transaction: %w", err)
+	}
+	return true, nil
+}
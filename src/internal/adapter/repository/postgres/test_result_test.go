@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/testrun"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestTestResultRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	analysisRepo := NewAnalysisRepository(pool)
+	repo := NewTestResultRepository(pool)
+	ctx := context.Background()
+
+	analysisID := setupTestAnalysisWithNestedSuites(t, ctx, analysisRepo, pool)
+	insertUpload := func(t *testing.T, content string) string {
+		t.Helper()
+		var id string
+		err := pool.QueryRow(ctx, `
+			INSERT INTO test_result_uploads (analysis_id, format, content)
+			VALUES ($1, 'junit', $2) RETURNING id::text
+		`, analysisID.String(), []byte(content)).Scan(&id)
+		if err != nil {
+			t.Fatalf("insert upload: %v", err)
+		}
+		return id
+	}
+
+	t.Run("should load an upload and the analysis test cases", func(t *testing.T) {
+		uploadID := insertUpload(t, "<testsuite/>")
+
+		upload, err := repo.GetUpload(ctx, uploadID)
+		if err != nil {
+			t.Fatalf("GetUpload failed: %v", err)
+		}
+		if upload.AnalysisID != analysisID.String() || upload.Format != testrun.FormatJUnit {
+			t.Errorf("unexpected upload: %+v", upload)
+		}
+
+		cases, err := repo.GetTestCases(ctx, upload.AnalysisID)
+		if err != nil {
+			t.Fatalf("GetTestCases failed: %v", err)
+		}
+		var nested *testrun.TestCase
+		for i := range cases {
+			if cases[i].Name == "TestNestedCreate" {
+				nested = &cases[i]
+			}
+		}
+		if nested == nil || nested.FilePath != "src/user_test.go" || nested.SuitePath != "UserService > Create" {
+			t.Errorf("unexpected test cases: %+v", cases)
+		}
+	})
+
+	t.Run("should accumulate results once per upload", func(t *testing.T) {
+		cases, err := repo.GetTestCases(ctx, analysisID.String())
+		if err != nil || len(cases) == 0 {
+			t.Fatalf("GetTestCases failed: %v", err)
+		}
+		result := testrun.CaseResult{Failures: 1, Runs: 1, Status: testrun.StatusFailed, TestCaseID: cases[0].ID}
+
+		first := insertUpload(t, "<testsuite/>")
+		for range 2 {
+			if _, err := repo.SaveResults(ctx, first, []testrun.CaseResult{result}); err != nil {
+				t.Fatalf("SaveResults failed: %v", err)
+			}
+		}
+		result.Failures, result.Passes, result.Status = 0, 1, testrun.StatusPassed
+		saved, err := repo.SaveResults(ctx, insertUpload(t, "<testsuite/>"), []testrun.CaseResult{result})
+		if err != nil || !saved {
+			t.Fatalf("SaveResults = %v, %v", saved, err)
+		}
+
+		var status string
+		var runs, passes, failures int
+		err = pool.QueryRow(ctx, `
+			SELECT last_status, runs, passes, failures FROM test_case_results WHERE test_case_id = $1
+		`, cases[0].ID).Scan(&status, &runs, &passes, &failures)
+		if err != nil {
+			t.Fatalf("query results: %v", err)
+		}
+		if status != "passed" || runs != 2 || passes != 1 || failures != 1 {
+			t.Errorf("got status %s, runs %d, passes %d, failures %d", status, runs, passes, failures)
+		}
+	})
+
+	t.Run("should report a missing upload", func(t *testing.T) {
+		_, err := repo.GetUpload(ctx, "00000000-0000-0000-0000-000000000000")
+		if !errors.Is(err, testrun.ErrUploadNotFound) {
+			t.Errorf("expected ErrUploadNotFound, got %v", err)
+		}
+	})
+}
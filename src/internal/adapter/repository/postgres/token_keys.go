@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/tokenkeys"
+)
+
+// reencryptBatchSize bounds the OAuth accounts listed per round of a re-encryption.
+const reencryptBatchSize = 100
+
+// ReencryptResult counts the outcome of a token re-encryption.
+type ReencryptResult struct {
+	Failed      int // tokens no key of the keyring opens; left as they are
+	Reencrypted int
+	Skipped     int // tokens replaced by a login while they were re-encrypted
+}
+
+// TokenKeyRotation moves stored OAuth tokens to the current key of a keyring,
+// so retired keys can be dropped.
+type TokenKeyRotation struct {
+	keyring *tokenkeys.Keyring
+	pool    *pgxpool.Pool
+}
+
+func NewTokenKeyRotation(pool *pgxpool.Pool, keyring *tokenkeys.Keyring) *TokenKeyRotation {
+	return &TokenKeyRotation{keyring: keyring, pool: pool}
+}
+
+// KeyUsage counts the stored tokens by the ID of the key that sealed them,
+// tokenkeys.LegacyKeyID for tokens without an envelope.
+func (r *TokenKeyRotation) KeyUsage(ctx context.Context) (map[string]int, error) {
+	usage := make(map[string]int)
+	err := r.eachToken(ctx, func(row db.ListOAuthAccessTokensRow) error {
+		usage[tokenkeys.KeyID(row.AccessToken)]++
+		return nil
+	})
+	return usage, err
+}
+
+// Reencrypt seals every token not sealed with the current key with it. A
+// token that fails to open is logged and left for an operator, and the run
+// goes on with the next one.
+func (r *TokenKeyRotation) Reencrypt(ctx context.Context) (ReencryptResult, error) {
+	var result ReencryptResult
+	queries := db.New(r.pool)
+
+	err := r.eachToken(ctx, func(row db.ListOAuthAccessTokensRow) error {
+		if r.keyring.IsCurrent(row.AccessToken) {
+			return nil
+		}
+		plaintext, err := r.keyring.Decrypt(row.AccessToken)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to decrypt oauth token, leaving it as is",
+				"oauth_account_id", fromPgUUID(row.ID),
+				"key_id", tokenkeys.KeyID(row.AccessToken),
+				"error", err,
+			)
+			result.Failed++
+			return nil
+		}
+		sealed, err := r.keyring.Encrypt(plaintext)
+		if err != nil {
+			return fmt.Errorf("encrypt token of oauth account %s: %w", fromPgUUID(row.ID), err)
+		}
+
+		n, err := queries.ReplaceOAuthAccessToken(ctx, db.ReplaceOAuthAccessTokenParams{
+			ID:       row.ID,
+			NewToken: sealed,
+			OldToken: row.AccessToken,
+		})
+		if err != nil {
+			return fmt.Errorf("replace token of oauth account %s: %w", fromPgUUID(row.ID), err)
+		}
+		if n == 0 {
+			result.Skipped++
+			return nil
+		}
+		result.Reencrypted++
+		return nil
+	})
+	return result, err
+}
+
+func (r *TokenKeyRotation) eachToken(ctx context.Context, fn func(db.ListOAuthAccessTokensRow) error) error {
+	queries := db.New(r.pool)
+	after := pgtype.UUID{Valid: true}
+	for {
+		rows, err := queries.ListOAuthAccessTokens(ctx, db.ListOAuthAccessTokensParams{
+			AfterID:   after,
+			BatchSize: reencryptBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("list oauth tokens: %w", err)
+		}
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if len(rows) < reencryptBatchSize {
+			return nil
+		}
+		after = rows[len(rows)-1].ID
+	}
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/specvital/worker/internal/infra/tokenkeys"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestTokenKeyRotation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	legacySealed, err := testMasterKey(t, 1).Encrypt("gho_legacy")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	insertAccount := func(username, token string) string {
+		t.Helper()
+		var userID string
+		if err := pool.QueryRow(ctx, `INSERT INTO users (username) VALUES ($1) RETURNING id::text`, username).Scan(&userID); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		if _, err := pool.Exec(ctx, `
+			INSERT INTO oauth_accounts (user_id, provider, provider_user_id, access_token)
+			VALUES ($1::uuid, 'github', $2, $3)
+		`, userID, username, token); err != nil {
+			t.Fatalf("failed to create oauth account: %v", err)
+		}
+		return userID
+	}
+	legacyUser := insertAccount("legacy", legacySealed)
+	insertAccount("unknown", "sk1:retired:abc")
+
+	keyring, err := tokenkeys.New(testMasterKey(t, 1), []tokenkeys.Key{{ID: "2026-10", Encryptor: testMasterKey(t, 2)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer keyring.Close()
+	rotation := NewTokenKeyRotation(pool, keyring)
+
+	result, err := rotation.Reencrypt(ctx)
+	if err != nil {
+		t.Fatalf("Reencrypt failed: %v", err)
+	}
+	if result.Reencrypted != 1 || result.Failed != 1 {
+		t.Errorf("result = %+v, want 1 re-encrypted and 1 failed", result)
+	}
+
+	usage, err := rotation.KeyUsage(ctx)
+	if err != nil {
+		t.Fatalf("KeyUsage failed: %v", err)
+	}
+	if usage["2026-10"] != 1 || usage["retired"] != 1 || usage[tokenkeys.LegacyKeyID] != 0 {
+		t.Errorf("usage = %v", usage)
+	}
+
+	token, err := NewUserRepository(pool, keyring).GetOAuthToken(ctx, legacyUser, "github")
+	if err != nil || token != "gho_legacy" {
+		t.Errorf("GetOAuthToken = %q, %v, want the re-encrypted token", token, err)
+	}
+
+	again, err := rotation.Reencrypt(ctx)
+	if err != nil || again.Reencrypted != 0 {
+		t.Errorf("second run = %+v, %v, want nothing left to re-encrypt", again, err)
+	}
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var _ specview.UsageNotifier = (*UsageWarningRepository)(nil)
+
+// UsageWarningRepository records quota warnings and publishes them on the
+// usage_warning NOTIFY channel for the notification service.
+type UsageWarningRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewUsageWarningRepository creates a new UsageWarningRepository.
+func NewUsageWarningRepository(pool *pgxpool.Pool) *UsageWarningRepository {
+	return &UsageWarningRepository{pool: pool}
+}
+
+// NotifyUsageWarning publishes the warning unless one was already recorded for
+// the user, month and threshold.
+func (r *UsageWarningRepository) NotifyUsageWarning(ctx context.Context, warning specview.UsageWarning) error {
+	userID, err := analysis.ParseUUID(warning.UserID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid user ID", specview.ErrInvalidInput)
+	}
+	var documentID pgtype.UUID
+	if parsed, err := analysis.ParseUUID(warning.DocumentID); err == nil {
+		documentID = toPgUUID(parsed)
+	}
+
+	queries := db.New(r.pool)
+	err = queries.InsertUsageWarning(ctx, db.InsertUsageWarningParams{
+		DocumentID: documentID,
+		QuotaLimit: warning.Limit,
+		QuotaUsed:  warning.Used,
+		Threshold:  int16(warning.Threshold),
+		UserID:     toPgUUID(userID),
+	})
+	if err != nil {
+		return fmt.Errorf("insert usage warning: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestUsageWarningRepository_NotifyUsageWarning(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewUsageWarningRepository(pool)
+	ctx := context.Background()
+	userID := setupTestUser(t, ctx, pool)
+
+	warning := specview.UsageWarning{Limit: 100, Remaining: 15, Threshold: 80, Used: 85, UserID: userID}
+	for i := 0; i < 2; i++ {
+		if err := repo.NotifyUsageWarning(ctx, warning); err != nil {
+			t.Fatalf("NotifyUsageWarning failed: %v", err)
+		}
+	}
+
+	var count int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM usage_warnings WHERE user_id = $1`, userID).Scan(&count); err != nil {
+		t.Fatalf("count usage warnings: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected one warning per threshold and month, got %d", count)
+	}
+}
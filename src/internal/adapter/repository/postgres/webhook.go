@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/webhook"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+var (
+	_ webhook.DeliveryStore = (*WebhookRepository)(nil)
+	_ webhook.Repository    = (*WebhookRepository)(nil)
+)
+
+// WebhookRepository maps webhook events to codebases and their analyses,
+// and records deliveries for deduplication.
+type WebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWebhookRepository creates a new WebhookRepository.
+func NewWebhookRepository(pool *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{pool: pool}
+}
+
+func (r *WebhookRepository) FindCodebase(ctx context.Context, host, externalRepoID string) (*webhook.Codebase, error) {
+	queries := db.New(r.pool)
+	row, err := queries.FindCodebaseByExternalID(ctx, db.FindCodebaseByExternalIDParams{
+		Host:           host,
+		ExternalRepoID: externalRepoID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find codebase by external id: %w", err)
+	}
+	return &webhook.Codebase{ID: fromPgUUID(row.ID)}, nil
+}
+
+func (r *WebhookRepository) HasAnalysisForCommit(ctx context.Context, codebaseID analysis.UUID, branch, commitSHA string) (bool, error) {
+	queries := db.New(r.pool)
+	exists, err := queries.HasAnalysisForCommit(ctx, db.HasAnalysisForCommitParams{
+		Branch:     branch,
+		CodebaseID: toPgUUID(codebaseID),
+		CommitSha:  commitSHA,
+	})
+	if err != nil {
+		return false, fmt.Errorf("check analysis for commit: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *WebhookRepository) ClaimDelivery(ctx context.Context, deliveryID, event string, expiresAt time.Time) (bool, webhook.Outcome, error) {
+	queries := db.New(r.pool)
+	_, err := queries.ClaimWebhookDelivery(ctx, db.ClaimWebhookDeliveryParams{
+		DeliveryID: deliveryID,
+		Event:      event,
+		ExpiresAt:  pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err == nil {
+		return true, "", nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, "", fmt.Errorf("claim webhook delivery: %w", err)
+	}
+
+	outcome, err := queries.GetWebhookDeliveryOutcome(ctx, deliveryID)
+	if err != nil {
+		// Released between the two queries: the first attempt is still settling.
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, "", nil
+		}
+		return false, "", fmt.Errorf("get webhook delivery outcome: %w", err)
+	}
+	return false, webhook.Outcome(outcome.String), nil
+}
+
+func (r *WebhookRepository) CompleteDelivery(ctx context.Context, deliveryID string, outcome webhook.Outcome) error {
+	queries := db.New(r.pool)
+	if err := queries.CompleteWebhookDelivery(ctx, db.CompleteWebhookDeliveryParams{
+		DeliveryID: deliveryID,
+		Outcome:    pgtype.Text{String: string(outcome), Valid: true},
+	}); err != nil {
+		return fmt.Errorf("complete webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ReleaseDelivery(ctx context.Context, deliveryID string) error {
+	queries := db.New(r.pool)
+	if err := queries.ReleaseWebhookDelivery(ctx, deliveryID); err != nil {
+		return fmt.Errorf("release webhook delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/webhook"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewWebhookRepository(pool)
+	ctx := context.Background()
+
+	wrapper := setupTestAnalysisWithNestedSuites(t, ctx, NewAnalysisRepository(pool), pool)
+
+	var host, externalRepoID string
+	var codebaseID [16]byte
+	if err := pool.QueryRow(ctx, `
+		SELECT c.host, c.external_repo_id, c.id FROM analyses a
+		JOIN codebases c ON c.id = a.codebase_id
+		WHERE a.id = $1
+	`, wrapper.id).Scan(&host, &externalRepoID, &codebaseID); err != nil {
+		t.Fatalf("failed to load codebase: %v", err)
+	}
+
+	t.Run("should find registered codebases by external id", func(t *testing.T) {
+		codebase, err := repo.FindCodebase(ctx, host, externalRepoID)
+		if err != nil {
+			t.Fatalf("FindCodebase failed: %v", err)
+		}
+		if codebase == nil || codebase.ID != analysis.UUID(codebaseID) {
+			t.Errorf("expected codebase %x, got %+v", codebaseID, codebase)
+		}
+
+		codebase, err = repo.FindCodebase(ctx, host, "unknown-repo")
+		if err != nil {
+			t.Fatalf("FindCodebase failed: %v", err)
+		}
+		if codebase != nil {
+			t.Errorf("expected nil for unknown repository, got %+v", codebase)
+		}
+	})
+
+	t.Run("should report analyzed commits", func(t *testing.T) {
+		analyzed, err := repo.HasAnalysisForCommit(ctx, analysis.UUID(codebaseID), "main", "abc123def456")
+		if err != nil {
+			t.Fatalf("HasAnalysisForCommit failed: %v", err)
+		}
+		if !analyzed {
+			t.Error("expected stored commit to be analyzed")
+		}
+
+		analyzed, err = repo.HasAnalysisForCommit(ctx, analysis.UUID(codebaseID), "main", "fffffff")
+		if err != nil {
+			t.Fatalf("HasAnalysisForCommit failed: %v", err)
+		}
+		if analyzed {
+			t.Error("expected new commit not to be analyzed")
+		}
+
+		analyzed, err = repo.HasAnalysisForCommit(ctx, analysis.UUID(codebaseID), "release/v2", "abc123def456")
+		if err != nil {
+			t.Fatalf("HasAnalysisForCommit failed: %v", err)
+		}
+		if analyzed {
+			t.Error("expected the commit not to be analyzed on another branch")
+		}
+	})
+
+	t.Run("should ignore failed analyses", func(t *testing.T) {
+		if _, err := pool.Exec(ctx, `UPDATE analyses SET status = 'failed' WHERE id = $1`, wrapper.id); err != nil {
+			t.Fatalf("failed to mark analysis failed: %v", err)
+		}
+
+		analyzed, err := repo.HasAnalysisForCommit(ctx, analysis.UUID(codebaseID), "main", "abc123def456")
+		if err != nil {
+			t.Fatalf("HasAnalysisForCommit failed: %v", err)
+		}
+		if analyzed {
+			t.Error("expected failed analysis to allow a retry")
+		}
+	})
+}
+
+func TestWebhookRepository_Deliveries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	repo := NewWebhookRepository(pool)
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	t.Run("should claim a delivery once and replay its outcome", func(t *testing.T) {
+		claimed, _, err := repo.ClaimDelivery(ctx, "delivery-1", "push", expiresAt)
+		if err != nil || !claimed {
+			t.Fatalf("first ClaimDelivery = %v, %v; want claimed", claimed, err)
+		}
+
+		claimed, previous, err := repo.ClaimDelivery(ctx, "delivery-1", "push", expiresAt)
+		if err != nil || claimed || previous != "" {
+			t.Fatalf("in-progress ClaimDelivery = %v, %q, %v; want unclaimed without outcome", claimed, previous, err)
+		}
+
+		if err := repo.CompleteDelivery(ctx, "delivery-1", webhook.OutcomeEnqueued); err != nil {
+			t.Fatalf("CompleteDelivery failed: %v", err)
+		}
+		claimed, previous, err = repo.ClaimDelivery(ctx, "delivery-1", "push", expiresAt)
+		if err != nil || claimed || previous != webhook.OutcomeEnqueued {
+			t.Errorf("repeat ClaimDelivery = %v, %q, %v; want unclaimed with enqueued", claimed, previous, err)
+		}
+	})
+
+	t.Run("should reclaim released and expired deliveries", func(t *testing.T) {
+		if _, _, err := repo.ClaimDelivery(ctx, "delivery-2", "push", expiresAt); err != nil {
+			t.Fatalf("ClaimDelivery failed: %v", err)
+		}
+		if err := repo.ReleaseDelivery(ctx, "delivery-2"); err != nil {
+			t.Fatalf("ReleaseDelivery failed: %v", err)
+		}
+		if claimed, _, err := repo.ClaimDelivery(ctx, "delivery-2", "push", expiresAt); err != nil || !claimed {
+			t.Errorf("ClaimDelivery after release = %v, %v; want claimed", claimed, err)
+		}
+
+		if _, _, err := repo.ClaimDelivery(ctx, "delivery-3", "push", time.Now().Add(-time.Minute)); err != nil {
+			t.Fatalf("ClaimDelivery failed: %v", err)
+		}
+		if err := repo.CompleteDelivery(ctx, "delivery-3", webhook.OutcomeEnqueued); err != nil {
+			t.Fatalf("CompleteDelivery failed: %v", err)
+		}
+		if claimed, _, err := repo.ClaimDelivery(ctx, "delivery-3", "push", expiresAt); err != nil || !claimed {
+			t.Errorf("ClaimDelivery after expiry = %v, %v; want claimed", claimed, err)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+// Package storage uploads files to S3-compatible object storage (AWS S3,
+// MinIO, Cloudflare R2, GCS interoperability mode) through aws-sdk-go-v2.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/specvital/worker/internal/domain/takeout"
+)
+
+// responseHeaderTimeout bounds the wait for a response once a request is
+// sent. Uploads have no overall deadline besides their context, since large
+// archives take long to send.
+const responseHeaderTimeout = time.Minute
+
+var _ takeout.ObjectStore = (*S3Store)(nil)
+
+// S3Config locates a bucket and the credentials writing to it.
+type S3Config struct {
+	AccessKeyID     string
+	Bucket          string
+	Endpoint        string // e.g. https://s3.us-east-1.amazonaws.com
+	PathStyle       bool   // address the bucket as <endpoint>/<bucket>, as MinIO expects
+	Region          string
+	SecretAccessKey string
+}
+
+// S3Store puts objects with PutObject and links them with presigned GET
+// URLs. Payloads are sent unsigned, so an upload streams from its reader
+// without being hashed first; use an HTTPS endpoint.
+type S3Store struct {
+	bucket  string
+	client  *s3.Client
+	presign *s3.PresignClient
+}
+
+// NewS3Store creates a store for the bucket. A nil httpClient uses the SDK's
+// client with dial, TLS handshake and response header timeouts.
+func NewS3Store(cfg S3Config, httpClient *http.Client) (*S3Store, error) {
+	if cfg.Bucket == "" || cfg.Endpoint == "" || cfg.Region == "" {
+		return nil, errors.New("bucket, endpoint and region are required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("access key ID and secret access key are required")
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "https" && endpoint.Scheme != "http") {
+		return nil, fmt.Errorf("invalid endpoint %q", cfg.Endpoint)
+	}
+
+	var client s3.HTTPClient = awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		tr.ResponseHeaderTimeout = responseHeaderTimeout
+	})
+	if httpClient != nil {
+		client = httpClient
+	}
+
+	s3Client := s3.New(s3.Options{
+		BaseEndpoint: aws.String(cfg.Endpoint),
+		Credentials:  credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		HTTPClient:   client,
+		Region:       cfg.Region,
+		UsePathStyle: cfg.PathStyle,
+		// Checksums other than the unsigned payload would need the body
+		// read ahead or sent chunked, which not every S3-compatible store
+		// accepts.
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+	})
+	return &S3Store{
+		bucket:  cfg.Bucket,
+		client:  s3Client,
+		presign: s3.NewPresignClient(s3Client),
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Body:          body,
+		Bucket:        aws.String(s.bucket),
+		ContentLength: aws.Int64(size),
+		ContentType:   aws.String(contentType),
+		Key:           aws.String(key),
+	}, s3.WithAPIOptions(v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware))
+	if err != nil {
+		return fmt.Errorf("put object: %w", err)
+	}
+	return nil
+}
+
+// SignedURL presigns a GET of key. ttl is capped at takeout.MaxURLTTL, the
+// longest validity SigV4 allows.
+func (s *S3Store) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", fmt.Errorf("%w: link validity must be positive", takeout.ErrInvalidInput)
+	}
+	ttl = min(ttl, takeout.MaxURLTTL)
+
+	req, err := s.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("presign object URL: %w", err)
+	}
+	return req.URL, nil
+}
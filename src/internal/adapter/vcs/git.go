@@ -3,7 +3,10 @@ package vcs
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"strings"
@@ -11,61 +14,128 @@ import (
 
 	"github.com/specvital/core/pkg/source"
 	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/infra/metrics"
 )
 
 // GitVCS implements analysis.VCS using specvital/core's GitSource.
-// It is a thin, stateless adapter that delegates to the underlying source package.
-// Concurrency control (semaphore) is managed by the use case layer, not here.
-type GitVCS struct{}
+// It is a thin adapter that delegates to the underlying source package.
+// Concurrency control (semaphore) is managed by the use case layer, not here;
+// disk usage is bounded by an optional WorkspaceManager.
+type GitVCS struct {
+	workspace *WorkspaceManager
+}
+
+// GitOption configures a GitVCS.
+type GitOption func(*GitVCS)
+
+// WithWorkspaceManager charges every clone against the manager's disk quota.
+func WithWorkspaceManager(m *WorkspaceManager) GitOption {
+	return func(v *GitVCS) {
+		v.workspace = m
+	}
+}
 
 // NewGitVCS creates a new GitVCS.
-func NewGitVCS() *GitVCS {
-	return &GitVCS{}
+func NewGitVCS(opts ...GitOption) *GitVCS {
+	v := &GitVCS{}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
 // Clone implements analysis.VCS by cloning a Git repository.
-func (v *GitVCS) Clone(ctx context.Context, url string, token *string) (analysis.Source, error) {
+// An empty branch clones the default branch.
+func (v *GitVCS) Clone(ctx context.Context, url string, token *string, branch string) (analysis.Source, error) {
 	if url == "" {
 		return nil, fmt.Errorf("clone repository: URL is required")
 	}
+	if branch != "" && !analysis.IsValidBranchName(branch) {
+		return nil, fmt.Errorf("clone repository: %w: invalid branch name", analysis.ErrInvalidInput)
+	}
 
 	var opts *source.GitOptions
+	if token != nil || branch != "" {
+		opts = &source.GitOptions{Branch: branch}
+	}
 	if token != nil {
-		opts = &source.GitOptions{
-			Credentials: &source.GitCredentials{
-				Username: "x-access-token",
-				Password: *token,
-			},
+		opts.Credentials = &source.GitCredentials{
+			Username: "x-access-token",
+			Password: *token,
 		}
 	}
 
-	gitSrc, err := source.NewGitSource(ctx, url, opts)
+	if v.workspace == nil {
+		gitSrc, err := newGitSource(ctx, url, opts)
+		if err != nil {
+			return nil, fmt.Errorf("clone repository %q: %w", url, err)
+		}
+		return &gitSourceAdapter{gitSrc: gitSrc}, nil
+	}
+
+	lease, err := v.workspace.acquire(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("clone repository %q: %w", url, err)
 	}
 
-	return &gitSourceAdapter{gitSrc: gitSrc}, nil
+	gitSrc, err := newGitSource(ctx, url, opts)
+	if err != nil {
+		lease.release()
+		return nil, fmt.Errorf("clone repository %q: %w", url, err)
+	}
+
+	size, tooLarge, err := lease.measure(gitSrc.Root())
+	if err != nil {
+		// Keep the reservation charged; the clone is usable.
+		slog.WarnContext(ctx, "failed to measure clone size (non-critical)",
+			"url", url,
+			"error", err,
+		)
+	}
+	if tooLarge {
+		closeErr := gitSrc.Close()
+		lease.release()
+		return nil, errors.Join(
+			fmt.Errorf("clone repository %q: %w: %d bytes exceeds workspace quota", url, analysis.ErrRepoTooLarge, size),
+			closeErr,
+		)
+	}
+
+	return &gitSourceAdapter{gitSrc: gitSrc, lease: lease}, nil
 }
 
 // GetHeadCommit returns the HEAD commit info (SHA and visibility) using git ls-remote.
+// An empty branch resolves the default branch.
 // It determines visibility by trying unauthenticated access first:
 // - Success without token = public repository (IsPrivate=false)
 // - Failure without token, success with token = private repository (IsPrivate=true)
-func (v *GitVCS) GetHeadCommit(ctx context.Context, url string, token *string) (analysis.CommitInfo, error) {
+func (v *GitVCS) GetHeadCommit(ctx context.Context, url string, token *string, branch string) (analysis.CommitInfo, error) {
 	if url == "" {
 		return analysis.CommitInfo{}, fmt.Errorf("get head commit: URL is required")
 	}
+	if branch != "" && !analysis.IsValidBranchName(branch) {
+		return analysis.CommitInfo{}, fmt.Errorf("get head commit: %w: invalid branch name", analysis.ErrInvalidInput)
+	}
 
-	sha, err := v.lsRemote(ctx, url, nil)
+	ref := "HEAD"
+	if branch != "" {
+		ref = "refs/heads/" + branch
+	}
+
+	sha, err := v.lsRemote(ctx, url, nil, ref)
 	if err == nil {
 		return analysis.CommitInfo{SHA: sha, IsPrivate: false}, nil
 	}
+	// The repository was readable, so it is public and the branch is missing.
+	if errors.Is(err, analysis.ErrBranchNotFound) {
+		return analysis.CommitInfo{}, fmt.Errorf("git ls-remote %q %s: %w", url, ref, err)
+	}
 
 	if token == nil {
 		return analysis.CommitInfo{}, fmt.Errorf("git ls-remote %q: %w", url, err)
 	}
 
-	sha, err = v.lsRemote(ctx, url, token)
+	sha, err = v.lsRemote(ctx, url, token, ref)
 	if err != nil {
 		return analysis.CommitInfo{}, fmt.Errorf("git ls-remote %q: %w", url, err)
 	}
@@ -73,13 +143,13 @@ func (v *GitVCS) GetHeadCommit(ctx context.Context, url string, token *string) (
 	return analysis.CommitInfo{SHA: sha, IsPrivate: true}, nil
 }
 
-func (v *GitVCS) lsRemote(ctx context.Context, url string, token *string) (string, error) {
+func (v *GitVCS) lsRemote(ctx context.Context, url string, token *string, ref string) (string, error) {
 	targetURL := url
 	if token != nil {
 		targetURL = strings.Replace(url, "https://", fmt.Sprintf("https://x-access-token:%s@", *token), 1)
 	}
 
-	cmd := exec.CommandContext(ctx, "git", "ls-remote", targetURL, "HEAD")
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", targetURL, ref)
 	cmd.Env = []string{
 		"PATH=" + os.Getenv("PATH"),
 		"GIT_TERMINAL_PROMPT=0",
@@ -97,6 +167,9 @@ func (v *GitVCS) lsRemote(ctx context.Context, url string, token *string) (strin
 
 	output := strings.TrimSpace(stdout.String())
 	if output == "" {
+		if ref != "HEAD" {
+			return "", analysis.ErrBranchNotFound
+		}
 		return "", fmt.Errorf("empty response")
 	}
 
@@ -112,6 +185,7 @@ func (v *GitVCS) lsRemote(ctx context.Context, url string, token *string) (strin
 // It also provides access to the underlying source.Source for parser integration.
 type gitSourceAdapter struct {
 	gitSrc *source.GitSource
+	lease  *workspaceLease // nil without a workspace manager
 }
 
 func (a *gitSourceAdapter) Branch() string {
@@ -127,7 +201,11 @@ func (a *gitSourceAdapter) CommittedAt() time.Time {
 }
 
 func (a *gitSourceAdapter) Close(_ context.Context) error {
-	return a.gitSrc.Close()
+	err := a.gitSrc.Close()
+	if a.lease != nil {
+		a.lease.release()
+	}
+	return err
 }
 
 // VerifyCommitExists checks if a commit SHA exists in the remote repository
@@ -164,9 +242,36 @@ func (a *gitSourceAdapter) VerifyCommitExists(ctx context.Context, sha string) (
 	return true, nil
 }
 
+// ReadFile reads a repository file relative to the clone root, up to maxBytes.
+// Larger files are rejected rather than truncated so callers never parse partial content.
+func (a *gitSourceAdapter) ReadFile(ctx context.Context, path string, maxBytes int64) ([]byte, error) {
+	rc, err := a.gitSrc.Open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("read %s: file exceeds %d bytes", path, maxBytes)
+	}
+	return data, nil
+}
+
 // CoreSource returns the underlying source.Source for use by the parser adapter.
 // This allows the parser to access the core source interface without exposing
 // implementation details in the domain layer.
 func (a *gitSourceAdapter) CoreSource() source.Source {
 	return a.gitSrc
 }
+
+// newGitSource clones url, recording the clone duration by outcome.
+func newGitSource(ctx context.Context, url string, opts *source.GitOptions) (*source.GitSource, error) {
+	start := time.Now()
+	gitSrc, err := source.NewGitSource(ctx, url, opts)
+	metrics.CloneDuration.WithLabelValues(metrics.ErrorOutcome(err)).Observe(time.Since(start).Seconds())
+	return gitSrc, err
+}
@@ -2,8 +2,11 @@ package vcs
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
+
+	"github.com/specvital/worker/internal/domain/analysis"
 )
 
 func TestNewGitVCS(t *testing.T) {
@@ -15,7 +18,7 @@ func TestNewGitVCS(t *testing.T) {
 
 func TestGitVCS_Clone_EmptyURL(t *testing.T) {
 	vcs := NewGitVCS()
-	_, err := vcs.Clone(context.Background(), "", nil)
+	_, err := vcs.Clone(context.Background(), "", nil, "")
 	if err == nil {
 		t.Fatal("expected error for empty URL")
 	}
@@ -26,7 +29,7 @@ func TestGitVCS_Clone_EmptyURL(t *testing.T) {
 
 func TestGitVCS_GetHeadCommit_EmptyURL(t *testing.T) {
 	vcs := NewGitVCS()
-	_, err := vcs.GetHeadCommit(context.Background(), "", nil)
+	_, err := vcs.GetHeadCommit(context.Background(), "", nil, "")
 	if err == nil {
 		t.Fatal("expected error for empty URL")
 	}
@@ -35,9 +38,34 @@ func TestGitVCS_GetHeadCommit_EmptyURL(t *testing.T) {
 	}
 }
 
+func TestGitVCS_InvalidBranch(t *testing.T) {
+	vcs := NewGitVCS()
+	if _, err := vcs.Clone(context.Background(), "https://github.com/octocat/Hello-World", nil, "--upload-pack=x"); !errors.Is(err, analysis.ErrInvalidInput) {
+		t.Errorf("Clone: expected ErrInvalidInput, got %v", err)
+	}
+	if _, err := vcs.GetHeadCommit(context.Background(), "https://github.com/octocat/Hello-World", nil, "a..b"); !errors.Is(err, analysis.ErrInvalidInput) {
+		t.Errorf("GetHeadCommit: expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestGitVCS_GetHeadCommit_MissingBranch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	vcs := NewGitVCS()
+	_, err := vcs.GetHeadCommit(context.Background(), "https://github.com/octocat/Hello-World", nil, "no-such-branch-12345")
+	if err == nil {
+		t.Fatal("expected error for missing branch")
+	}
+	if !errors.Is(err, analysis.ErrBranchNotFound) {
+		t.Skipf("skipping test due to network error: %v", err)
+	}
+}
+
 func TestGitVCS_GetHeadCommit_PublicRepo(t *testing.T) {
 	vcs := NewGitVCS()
-	info, err := vcs.GetHeadCommit(context.Background(), "https://github.com/octocat/Hello-World", nil)
+	info, err := vcs.GetHeadCommit(context.Background(), "https://github.com/octocat/Hello-World", nil, "")
 	if err != nil {
 		t.Skipf("skipping test due to network error: %v", err)
 	}
@@ -51,7 +79,7 @@ func TestGitVCS_GetHeadCommit_PublicRepo(t *testing.T) {
 
 func TestGitVCS_GetHeadCommit_InvalidURL(t *testing.T) {
 	vcs := NewGitVCS()
-	_, err := vcs.GetHeadCommit(context.Background(), "not-a-valid-url", nil)
+	_, err := vcs.GetHeadCommit(context.Background(), "not-a-valid-url", nil, "")
 	if err == nil {
 		t.Fatal("expected error for invalid URL")
 	}
@@ -59,7 +87,7 @@ func TestGitVCS_GetHeadCommit_InvalidURL(t *testing.T) {
 
 func TestGitVCS_GetHeadCommit_PrivateRepoWithoutToken(t *testing.T) {
 	vcs := NewGitVCS()
-	_, err := vcs.GetHeadCommit(context.Background(), "https://github.com/octocat/private-nonexistent-repo-12345", nil)
+	_, err := vcs.GetHeadCommit(context.Background(), "https://github.com/octocat/private-nonexistent-repo-12345", nil, "")
 	if err == nil {
 		t.Fatal("expected error for inaccessible repo without token")
 	}
@@ -72,7 +100,7 @@ func TestGitVCS_GetHeadCommit_PrivateRepoWithToken(t *testing.T) {
 
 	vcs := NewGitVCS()
 	invalidToken := "invalid-token"
-	_, err := vcs.GetHeadCommit(context.Background(), "https://github.com/octocat/private-nonexistent-repo-12345", &invalidToken)
+	_, err := vcs.GetHeadCommit(context.Background(), "https://github.com/octocat/private-nonexistent-repo-12345", &invalidToken, "")
 	if err == nil {
 		t.Fatal("expected error for invalid token")
 	}
@@ -83,7 +111,7 @@ func TestGitVCS_GetHeadCommit_ContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	_, err := vcs.GetHeadCommit(ctx, "https://github.com/octocat/Hello-World", nil)
+	_, err := vcs.GetHeadCommit(ctx, "https://github.com/octocat/Hello-World", nil, "")
 	if err == nil {
 		t.Fatal("expected context cancellation error")
 	}
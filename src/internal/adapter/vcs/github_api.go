@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/specview"
 )
 
 const (
@@ -16,38 +18,110 @@ const (
 )
 
 type GitHubAPIClient struct {
-	apiBase    string
-	httpClient *http.Client
+	apiBase         string
+	enterpriseBases map[string]string // API base by GitHub Enterprise host
+	httpClient      *http.Client
 }
 
-var _ analysis.VCSAPIClient = (*GitHubAPIClient)(nil)
+var (
+	_ analysis.VCSAPIClient      = (*GitHubAPIClient)(nil)
+	_ specview.RepoLicenseLookup = (*GitHubAPIClient)(nil)
+)
 
-func NewGitHubAPIClient(httpClient *http.Client) *GitHubAPIClient {
+// NewGitHubAPIClient creates a client of the github.com API and of the
+// GitHub Enterprise Server APIs of enterpriseHosts, served below /api/v3.
+func NewGitHubAPIClient(httpClient *http.Client, enterpriseHosts ...string) *GitHubAPIClient {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
-	return &GitHubAPIClient{
-		apiBase:    gitHubAPIBase,
-		httpClient: httpClient,
+	c := &GitHubAPIClient{
+		apiBase:         gitHubAPIBase,
+		enterpriseBases: make(map[string]string, len(enterpriseHosts)),
+		httpClient:      httpClient,
+	}
+	for _, host := range enterpriseHosts {
+		host = strings.ToLower(host)
+		if host != gitHubHost {
+			c.enterpriseBases[host] = "https://" + host + "/api/v3"
+		}
 	}
+	return c
 }
 
 func (c *GitHubAPIClient) GetRepoInfo(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoInfo, error) {
-	if host != gitHubHost {
-		return analysis.RepoInfo{}, fmt.Errorf("%w: unsupported host %q (only %q is supported)", analysis.ErrInvalidInput, host, gitHubHost)
+	result, err := c.getRepository(ctx, host, owner, repo, token)
+	if err != nil {
+		return analysis.RepoInfo{}, err
+	}
+
+	return analysis.RepoInfo{
+		ExternalRepoID: strconv.FormatInt(result.ID, 10),
+		Name:           result.Name,
+		Owner:          result.Owner.Login,
+	}, nil
+}
+
+func (c *GitHubAPIClient) GetRepoStats(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoStats, error) {
+	result, err := c.getRepository(ctx, host, owner, repo, token)
+	if err != nil {
+		return analysis.RepoStats{}, err
+	}
+
+	return analysis.RepoStats{
+		Language:  result.Language,
+		SizeBytes: result.SizeKB << 10,
+	}, nil
+}
+
+// GetRepoLicense reports visibility and the detected license, unauthenticated:
+// documents are only shared for repositories anyone can read.
+func (c *GitHubAPIClient) GetRepoLicense(ctx context.Context, host, owner, repo string) (specview.RepoLicense, error) {
+	result, err := c.getRepository(ctx, host, owner, repo, nil)
+	if err != nil {
+		return specview.RepoLicense{}, err
+	}
+
+	license := specview.RepoLicense{Private: result.Private}
+	if result.License != nil {
+		license.SPDXID = result.License.SPDXID
+	}
+	return license, nil
+}
+
+type gitHubRepository struct {
+	ID       int64  `json:"id"`
+	Language string `json:"language"`
+	License  *struct {
+		SPDXID string `json:"spdx_id"` // "NOASSERTION" when GitHub cannot identify it
+	} `json:"license"`
+	Name  string `json:"name"`
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Private bool  `json:"private"`
+	SizeKB  int64 `json:"size"` // size of the git data in KiB
+}
+
+func (c *GitHubAPIClient) getRepository(ctx context.Context, host, owner, repo string, token *string) (*gitHubRepository, error) {
+	apiBase, ok := c.enterpriseBases[strings.ToLower(host)]
+	if host == gitHubHost {
+		apiBase, ok = c.apiBase, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported host %q (neither %q nor a configured enterprise host)", analysis.ErrInvalidInput, host, gitHubHost)
 	}
 	if owner == "" {
-		return analysis.RepoInfo{}, fmt.Errorf("%w: owner is required", analysis.ErrInvalidInput)
+		return nil, fmt.Errorf("%w: owner is required", analysis.ErrInvalidInput)
 	}
 	if repo == "" {
-		return analysis.RepoInfo{}, fmt.Errorf("%w: repo is required", analysis.ErrInvalidInput)
+		return nil, fmt.Errorf("%w: repo is required", analysis.ErrInvalidInput)
 	}
 
-	url := fmt.Sprintf("%s/repos/%s/%s", c.apiBase, owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s", apiBase, owner, repo)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return analysis.RepoInfo{}, fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/vnd.github+json")
@@ -58,31 +132,20 @@ func (c *GitHubAPIClient) GetRepoInfo(ctx context.Context, host, owner, repo str
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return analysis.RepoInfo{}, fmt.Errorf("get repository %s/%s: %w", owner, repo, err)
+		return nil, fmt.Errorf("get repository %s/%s: %w", owner, repo, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return analysis.RepoInfo{}, fmt.Errorf("%w: %s/%s", analysis.ErrRepoNotFound, owner, repo)
+		return nil, fmt.Errorf("%w: %s/%s", analysis.ErrRepoNotFound, owner, repo)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return analysis.RepoInfo{}, fmt.Errorf("get repository %s/%s: unexpected status %d", owner, repo, resp.StatusCode)
+		return nil, fmt.Errorf("get repository %s/%s: unexpected status %d", owner, repo, resp.StatusCode)
 	}
 
-	var result struct {
-		ID    int64  `json:"id"`
-		Name  string `json:"name"`
-		Owner struct {
-			Login string `json:"login"`
-		} `json:"owner"`
-	}
+	var result gitHubRepository
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return analysis.RepoInfo{}, fmt.Errorf("decode response: %w", err)
+		return nil, fmt.Errorf("decode response: %w", err)
 	}
-
-	return analysis.RepoInfo{
-		ExternalRepoID: strconv.FormatInt(result.ID, 10),
-		Name:           result.Name,
-		Owner:          result.Owner.Login,
-	}, nil
+	return &result, nil
 }
@@ -135,6 +135,30 @@ func TestGitHubAPIClient_GetRepoInfo(t *testing.T) {
 		}
 	})
 
+	t.Run("enterprise host", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v3/repos/owner/repo" {
+				t.Errorf("unexpected path %q", r.URL.Path)
+			}
+			w.Write([]byte(`{"id": 7, "name": "repo", "owner": {"login": "owner"}}`))
+		}))
+		defer server.Close()
+
+		client := NewGitHubAPIClient(server.Client(), "GitHub.Example.com")
+		if _, ok := client.enterpriseBases["github.example.com"]; !ok {
+			t.Fatalf("enterprise host not registered: %v", client.enterpriseBases)
+		}
+		client.enterpriseBases["github.example.com"] = server.URL + "/api/v3"
+
+		info, err := client.GetRepoInfo(context.Background(), "github.example.com", "owner", "repo", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if info.ExternalRepoID != "7" {
+			t.Errorf("ExternalRepoID = %q, want 7", info.ExternalRepoID)
+		}
+	})
+
 	t.Run("empty owner", func(t *testing.T) {
 		client := NewGitHubAPIClient(nil)
 		_, err := client.GetRepoInfo(context.Background(), "github.com", "", "repo", nil)
@@ -210,6 +234,79 @@ func TestGitHubAPIClient_GetRepoInfo(t *testing.T) {
 	})
 }
 
+func TestGitHubAPIClient_GetRepoStats(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/repos/octocat/Hello-World" {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id": 1296269, "name": "Hello-World", "owner": {"login": "octocat"}, "size": 2048, "language": "Go"}`))
+		}))
+		defer server.Close()
+
+		client := newTestClient(server)
+
+		stats, err := client.GetRepoStats(context.Background(), "github.com", "octocat", "Hello-World", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stats.SizeBytes != 2048<<10 {
+			t.Errorf("expected %d bytes, got %d", 2048<<10, stats.SizeBytes)
+		}
+		if stats.Language != "Go" {
+			t.Errorf("expected language Go, got %q", stats.Language)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := newTestClient(server)
+
+		_, err := client.GetRepoStats(context.Background(), "github.com", "owner", "missing", nil)
+		if !errors.Is(err, analysis.ErrRepoNotFound) {
+			t.Errorf("expected ErrRepoNotFound, got %v", err)
+		}
+	})
+}
+
+func TestGitHubAPIClient_GetRepoLicense(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantPrivate bool
+		wantSPDX    string
+	}{
+		{"public with license", `{"id": 1, "private": false, "license": {"key": "mit", "spdx_id": "MIT"}}`, false, "MIT"},
+		{"no license", `{"id": 1, "private": false, "license": null}`, false, ""},
+		{"private", `{"id": 1, "private": true, "license": {"spdx_id": "Apache-2.0"}}`, true, "Apache-2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "" {
+					t.Error("license lookups should be unauthenticated")
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			license, err := newTestClient(server).GetRepoLicense(context.Background(), "github.com", "octocat", "Hello-World")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if license.Private != tt.wantPrivate || license.SPDXID != tt.wantSPDX {
+				t.Errorf("got %+v, want private=%v spdx=%q", license, tt.wantPrivate, tt.wantSPDX)
+			}
+		})
+	}
+}
+
 func newTestClient(server *httptest.Server) *GitHubAPIClient {
 	return &GitHubAPIClient{
 		apiBase:    server.URL,
@@ -0,0 +1,283 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+const (
+	// appJWTLifetime is the validity of the JWT authenticating as the app.
+	// GitHub rejects JWTs valid for more than 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockSkew backdates the JWT against clock drift with GitHub.
+	appJWTClockSkew = 60 * time.Second
+	// installationTokenRefreshMargin is how long before expiry a cached
+	// installation token is replaced, so a clone never starts with a token
+	// about to expire.
+	installationTokenRefreshMargin = 10 * time.Minute
+	// installationCacheTTL bounds how long an installation ID is reused
+	// before it is looked up again.
+	installationCacheTTL = time.Hour
+	// notInstalledCacheTTL bounds how long a repository without the app
+	// skips the installation lookup, so installing the app takes effect soon.
+	notInstalledCacheTTL = 10 * time.Minute
+)
+
+// GitHubAppTokenSource mints installation access tokens for the repositories
+// a GitHub App is installed on. Tokens are scoped to the one repository with
+// read access to its contents and cached until shortly before they expire.
+type GitHubAppTokenSource struct {
+	apiBase    string
+	appID      int64
+	httpClient *http.Client
+	key        *rsa.PrivateKey
+	now        func() time.Time
+
+	mu    sync.Mutex
+	repos map[string]*appRepoEntry // by lowercase owner/repo
+}
+
+type appRepoEntry struct {
+	checkedAt      time.Time // when installationID was looked up
+	installationID int64     // zero when the app is not installed
+	token          string
+	tokenExpiresAt time.Time
+}
+
+var _ analysis.InstallationTokenSource = (*GitHubAppTokenSource)(nil)
+
+// NewGitHubAppTokenSource creates a token source for the GitHub App appID
+// signing with privateKeyPEM, the PEM-encoded RSA key GitHub generated for the
+// app (PKCS#1 or PKCS#8).
+func NewGitHubAppTokenSource(appID int64, privateKeyPEM string, httpClient *http.Client) (*GitHubAppTokenSource, error) {
+	if appID <= 0 {
+		return nil, fmt.Errorf("%w: github app id is required", analysis.ErrInvalidInput)
+	}
+	key, err := parseRSAPrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("github app private key: %w", err)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &GitHubAppTokenSource{
+		apiBase:    gitHubAPIBase,
+		appID:      appID,
+		httpClient: httpClient,
+		key:        key,
+		now:        time.Now,
+		repos:      make(map[string]*appRepoEntry),
+	}, nil
+}
+
+// GetInstallationToken returns a token for owner/repo, minting one when none
+// is cached or the cached one is about to expire. It returns
+// analysis.ErrAppNotInstalled when the app cannot access the repository.
+func (s *GitHubAppTokenSource) GetInstallationToken(ctx context.Context, owner, repo string) (string, error) {
+	if owner == "" || repo == "" {
+		return "", fmt.Errorf("%w: owner and repo are required", analysis.ErrInvalidInput)
+	}
+	key := strings.ToLower(owner + "/" + repo)
+	now := s.now()
+
+	s.mu.Lock()
+	entry := s.repos[key]
+	var cached appRepoEntry
+	if entry != nil {
+		cached = *entry
+	}
+	s.mu.Unlock()
+
+	if cached.token != "" && now.Before(cached.tokenExpiresAt.Add(-installationTokenRefreshMargin)) {
+		return cached.token, nil
+	}
+
+	installationID := cached.installationID
+	switch {
+	case entry != nil && installationID == 0 && now.Sub(cached.checkedAt) < notInstalledCacheTTL:
+		return "", fmt.Errorf("%w: %s/%s", analysis.ErrAppNotInstalled, owner, repo)
+	case installationID == 0 || now.Sub(cached.checkedAt) >= installationCacheTTL:
+		id, err := s.findInstallation(ctx, owner, repo)
+		if errors.Is(err, analysis.ErrAppNotInstalled) {
+			s.store(key, &appRepoEntry{checkedAt: now})
+			return "", err
+		}
+		if err != nil {
+			return "", err
+		}
+		installationID = id
+		cached = appRepoEntry{checkedAt: now, installationID: id}
+	}
+
+	token, expiresAt, err := s.createToken(ctx, installationID, repo)
+	if errors.Is(err, analysis.ErrAppNotInstalled) {
+		// The app was uninstalled or lost access since the lookup.
+		s.store(key, &appRepoEntry{checkedAt: now})
+		return "", fmt.Errorf("%w: %s/%s", analysis.ErrAppNotInstalled, owner, repo)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	cached.token = token
+	cached.tokenExpiresAt = expiresAt
+	s.store(key, &cached)
+	return token, nil
+}
+
+func (s *GitHubAppTokenSource) store(key string, entry *appRepoEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repos[key] = entry
+}
+
+// findInstallation returns the ID of the app installation covering owner/repo.
+func (s *GitHubAppTokenSource) findInstallation(ctx context.Context, owner, repo string) (int64, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/installation", s.apiBase, owner, repo)
+	resp, err := s.do(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("get installation %s/%s: %w", owner, repo, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, fmt.Errorf("%w: %s/%s", analysis.ErrAppNotInstalled, owner, repo)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("get installation %s/%s: unexpected status %d", owner, repo, resp.StatusCode)
+	}
+
+	var result struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("decode installation: %w", err)
+	}
+	return result.ID, nil
+}
+
+// createToken mints an installation token limited to reading repo.
+func (s *GitHubAppTokenSource) createToken(ctx context.Context, installationID int64, repo string) (string, time.Time, error) {
+	body, err := json.Marshal(map[string]any{
+		"repositories": []string{repo},
+		"permissions":  map[string]string{"contents": "read", "metadata": "read"},
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("encode token request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", s.apiBase, installationID)
+	resp, err := s.do(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("create installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+	case http.StatusNotFound, http.StatusUnprocessableEntity:
+		return "", time.Time{}, analysis.ErrAppNotInstalled
+	default:
+		return "", time.Time{}, fmt.Errorf("create installation token: unexpected status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		ExpiresAt time.Time `json:"expires_at"`
+		Token     string    `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", time.Time{}, fmt.Errorf("decode installation token: %w", err)
+	}
+	if result.Token == "" {
+		return "", time.Time{}, errors.New("create installation token: empty token")
+	}
+	return result.Token, result.ExpiresAt, nil
+}
+
+func (s *GitHubAppTokenSource) do(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	jwt, err := s.appJWT()
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return s.httpClient.Do(req)
+}
+
+// appJWT signs the short-lived RS256 JWT authenticating as the app itself.
+func (s *GitHubAppTokenSource) appJWT() (string, error) {
+	now := s.now()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign app jwt: %w", err)
+	}
+	return signingInput + "." + enc.EncodeToString(sig), nil
+}
+
+// parseRSAPrivateKey decodes a PEM RSA key. Escaped newlines are accepted,
+// as keys passed through environment variables often carry them.
+func parseRSAPrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(pemKey, `\n`, "\n")))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("key is not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,217 @@
+package vcs
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+func generateTestAppKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	block := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return key, string(block)
+}
+
+func newTestAppTokenSource(t *testing.T, server *httptest.Server, now *time.Time) (*GitHubAppTokenSource, *rsa.PrivateKey) {
+	t.Helper()
+	key, pemKey := generateTestAppKey(t)
+	source, err := NewGitHubAppTokenSource(42, pemKey, server.Client())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	source.apiBase = server.URL
+	source.now = func() time.Time { return *now }
+	return source, key
+}
+
+func verifyAppJWT(t *testing.T, r *http.Request, key *rsa.PublicKey) {
+	t.Helper()
+	parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected a JWT, got %q", r.Header.Get("Authorization"))
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("decode signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		t.Errorf("invalid JWT signature: %v", err)
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims struct {
+		Exp int64  `json:"exp"`
+		Iat int64  `json:"iat"`
+		Iss string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("decode claims: %v", err)
+	}
+	if claims.Iss != "42" {
+		t.Errorf("expected iss 42, got %q", claims.Iss)
+	}
+	if lifetime := time.Duration(claims.Exp-claims.Iat) * time.Second; lifetime > 10*time.Minute {
+		t.Errorf("JWT valid for %v, GitHub allows at most 10m", lifetime)
+	}
+}
+
+func TestNewGitHubAppTokenSource(t *testing.T) {
+	_, pemKey := generateTestAppKey(t)
+
+	t.Run("accepts escaped newlines", func(t *testing.T) {
+		if _, err := NewGitHubAppTokenSource(42, strings.ReplaceAll(pemKey, "\n", `\n`), nil); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("requires app id", func(t *testing.T) {
+		if _, err := NewGitHubAppTokenSource(0, pemKey, nil); !errors.Is(err, analysis.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("rejects invalid key", func(t *testing.T) {
+		if _, err := NewGitHubAppTokenSource(42, "not a key", nil); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestGitHubAppTokenSource_GetInstallationToken(t *testing.T) {
+	t.Run("mints and caches a repository token", func(t *testing.T) {
+		now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+		var lookups, mints atomic.Int32
+		var key *rsa.PrivateKey
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			verifyAppJWT(t, r, &key.PublicKey)
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/repos/octocat/private-repo/installation":
+				lookups.Add(1)
+				w.Write([]byte(`{"id": 7}`))
+			case r.Method == http.MethodPost && r.URL.Path == "/app/installations/7/access_tokens":
+				n := mints.Add(1)
+				var body struct {
+					Repositories []string `json:"repositories"`
+				}
+				json.NewDecoder(r.Body).Decode(&body)
+				if len(body.Repositories) != 1 || body.Repositories[0] != "private-repo" {
+					t.Errorf("expected the token scoped to private-repo, got %v", body.Repositories)
+				}
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, n, now.Add(time.Hour).Format(time.RFC3339))
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		defer server.Close()
+
+		source, k := newTestAppTokenSource(t, server, &now)
+		key = k
+		ctx := context.Background()
+
+		token, err := source.GetInstallationToken(ctx, "octocat", "private-repo")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if token != "ghs_1" {
+			t.Errorf("expected ghs_1, got %q", token)
+		}
+
+		token, _ = source.GetInstallationToken(ctx, "Octocat", "Private-Repo")
+		if token != "ghs_1" || mints.Load() != 1 {
+			t.Errorf("expected the cached token, got %q after %d mints", token, mints.Load())
+		}
+
+		now = now.Add(55 * time.Minute)
+		token, err = source.GetInstallationToken(ctx, "octocat", "private-repo")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if token != "ghs_2" {
+			t.Errorf("expected a refreshed token close to expiry, got %q", token)
+		}
+		if lookups.Load() != 1 {
+			t.Errorf("expected the installation ID to be reused, got %d lookups", lookups.Load())
+		}
+	})
+
+	t.Run("not installed is cached", func(t *testing.T) {
+		now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+		var lookups atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lookups.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		source, _ := newTestAppTokenSource(t, server, &now)
+		ctx := context.Background()
+
+		for range 2 {
+			if _, err := source.GetInstallationToken(ctx, "octocat", "public-repo"); !errors.Is(err, analysis.ErrAppNotInstalled) {
+				t.Errorf("expected ErrAppNotInstalled, got %v", err)
+			}
+		}
+		if lookups.Load() != 1 {
+			t.Errorf("expected one lookup, got %d", lookups.Load())
+		}
+
+		now = now.Add(notInstalledCacheTTL)
+		source.GetInstallationToken(ctx, "octocat", "public-repo")
+		if lookups.Load() != 2 {
+			t.Errorf("expected a new lookup after the negative cache expired, got %d", lookups.Load())
+		}
+	})
+
+	t.Run("uninstalled app drops the cached installation", func(t *testing.T) {
+		now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				w.Write([]byte(`{"id": 7}`))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		source, _ := newTestAppTokenSource(t, server, &now)
+		if _, err := source.GetInstallationToken(context.Background(), "octocat", "private-repo"); !errors.Is(err, analysis.ErrAppNotInstalled) {
+			t.Errorf("expected ErrAppNotInstalled, got %v", err)
+		}
+	})
+
+	t.Run("unexpected status", func(t *testing.T) {
+		now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		source, _ := newTestAppTokenSource(t, server, &now)
+		_, err := source.GetInstallationToken(context.Background(), "octocat", "private-repo")
+		if err == nil || errors.Is(err, analysis.ErrAppNotInstalled) {
+			t.Errorf("expected an infrastructure error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+const (
+	// DefaultCloneReserveBytes is the space held for a clone while it is in progress,
+	// before its real size is known.
+	DefaultCloneReserveBytes int64 = 256 << 20
+
+	// DefaultOrphanAge is how old a clone workspace must be to count as
+	// orphaned. It is well above the 20-minute timeout of the jobs that clone,
+	// so live clones of other workers sharing the directory are never removed.
+	DefaultOrphanAge = time.Hour
+
+	// workspacePrefix is the directory prefix the core GitSource uses under os.TempDir().
+	workspacePrefix = "gitsource-"
+)
+
+// WorkspaceManager tracks disk used by clones and enforces a total quota.
+// A clone reserves space before it starts and is charged its measured size once done.
+// Clones wait while the reservation would exceed the quota, so concurrent clones
+// cannot fill the disk. A quota of zero only tracks usage.
+type WorkspaceManager struct {
+	dir       string
+	mu        sync.Mutex
+	orphanAge time.Duration
+	quota     int64
+	released  chan struct{} // closed and replaced whenever space is freed
+	reserve   int64
+	used      int64
+}
+
+// WorkspaceOption configures a WorkspaceManager.
+type WorkspaceOption func(*WorkspaceManager)
+
+// WithCloneReserve sets the space held for an in-progress clone.
+// Zero or negative values are ignored and the default is used.
+func WithCloneReserve(bytes int64) WorkspaceOption {
+	return func(m *WorkspaceManager) {
+		if bytes > 0 {
+			m.reserve = bytes
+		}
+	}
+}
+
+// WithOrphanAge sets how old a clone workspace must be before CleanupOrphans
+// removes it. Zero or negative values are ignored and the default is used.
+func WithOrphanAge(d time.Duration) WorkspaceOption {
+	return func(m *WorkspaceManager) {
+		if d > 0 {
+			m.orphanAge = d
+		}
+	}
+}
+
+// NewWorkspaceManager creates a manager for clones under os.TempDir().
+// quotaBytes of zero or less disables the quota.
+func NewWorkspaceManager(quotaBytes int64, opts ...WorkspaceOption) *WorkspaceManager {
+	m := &WorkspaceManager{
+		dir:       os.TempDir(),
+		orphanAge: DefaultOrphanAge,
+		quota:     max(quotaBytes, 0),
+		released:  make(chan struct{}),
+		reserve:   DefaultCloneReserveBytes,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// CleanupOrphans removes clone workspaces left behind by a previous process,
+// e.g. after a crash or OOM kill. Call it on startup before accepting jobs.
+// The workspace directory is the shared os.TempDir(), so only workspaces
+// last modified longer than the orphan age ago are removed; newer ones may
+// belong to other workers running on the same host.
+func (m *WorkspaceManager) CleanupOrphans(ctx context.Context) (int, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return 0, fmt.Errorf("read workspace dir %s: %w", m.dir, err)
+	}
+
+	removed := 0
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), workspacePrefix) {
+			continue
+		}
+		path := filepath.Join(m.dir, e.Name())
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < m.orphanAge {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			slog.WarnContext(ctx, "failed to remove orphaned workspace",
+				"path", path,
+				"error", err,
+			)
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Used returns the bytes currently charged to clones, including reservations.
+func (m *WorkspaceManager) Used() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.used
+}
+
+// acquire reserves space for a clone, waiting until enough space is freed.
+// It fails with analysis.ErrWorkspaceFull when ctx ends first.
+func (m *WorkspaceManager) acquire(ctx context.Context) (*workspaceLease, error) {
+	for {
+		m.mu.Lock()
+		// An idle workspace always admits one clone, so a quota below the
+		// reservation cannot block forever.
+		if m.quota == 0 || m.used == 0 || m.used+m.reserve <= m.quota {
+			m.used += m.reserve
+			m.mu.Unlock()
+			return &workspaceLease{bytes: m.reserve, manager: m}, nil
+		}
+		released := m.released
+		used := m.used
+		m.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %d of %d bytes in use: %w", analysis.ErrWorkspaceFull, used, m.quota, ctx.Err())
+		case <-released:
+		}
+	}
+}
+
+// adjust replaces a lease's charge, waking waiters if it shrank.
+func (m *WorkspaceManager) adjust(lease *workspaceLease, bytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.used += bytes - lease.bytes
+	shrank := bytes < lease.bytes
+	lease.bytes = bytes
+	if shrank {
+		m.notifyLocked()
+	}
+}
+
+func (m *WorkspaceManager) notifyLocked() {
+	close(m.released)
+	m.released = make(chan struct{})
+}
+
+// workspaceLease is the space charged to one clone until it is released.
+type workspaceLease struct {
+	bytes    int64
+	manager  *WorkspaceManager
+	released sync.Once
+}
+
+// measure charges the lease the actual size of root and reports whether
+// the clone alone exceeds the quota.
+func (l *workspaceLease) measure(root string) (int64, bool, error) {
+	size, err := dirSize(root)
+	if err != nil {
+		return 0, false, err
+	}
+	l.manager.adjust(l, size)
+	return size, l.manager.quota > 0 && size > l.manager.quota, nil
+}
+
+func (l *workspaceLease) release() {
+	l.released.Do(func() {
+		l.manager.adjust(l, 0)
+	})
+}
+
+func dirSize(root string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("measure workspace %s: %w", root, err)
+	}
+	return size, nil
+}
@@ -0,0 +1,141 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+func newTestWorkspace(t *testing.T, quota, reserve int64) *WorkspaceManager {
+	t.Helper()
+	m := NewWorkspaceManager(quota, WithCloneReserve(reserve))
+	m.dir = t.TempDir()
+	return m
+}
+
+func writeClone(t *testing.T, dir string, size int) string {
+	t.Helper()
+	root, err := os.MkdirTemp(dir, workspacePrefix+"*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "data"), make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestWorkspaceManager_QuotaWaitsForRelease(t *testing.T) {
+	m := newTestWorkspace(t, 100, 60)
+
+	first, err := m.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		_, err := m.acquire(context.Background())
+		acquired <- err
+	}()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("second acquire should wait, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	first.release()
+	if err := <-acquired; err != nil {
+		t.Fatalf("second acquire: %v", err)
+	}
+	if got := m.Used(); got != 60 {
+		t.Errorf("Used() = %d, want 60", got)
+	}
+}
+
+func TestWorkspaceManager_AcquireTimesOut(t *testing.T) {
+	m := newTestWorkspace(t, 100, 60)
+	if _, err := m.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := m.acquire(ctx)
+	if !errors.Is(err, analysis.ErrWorkspaceFull) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected ErrWorkspaceFull wrapping the deadline, got %v", err)
+	}
+}
+
+func TestWorkspaceManager_MeasureChargesActualSize(t *testing.T) {
+	m := newTestWorkspace(t, 100, 60)
+	lease, err := m.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	size, tooLarge, err := lease.measure(writeClone(t, m.dir, 30))
+	if err != nil || tooLarge || size != 30 {
+		t.Fatalf("measure = (%d, %v, %v), want (30, false, nil)", size, tooLarge, err)
+	}
+	if got := m.Used(); got != 30 {
+		t.Errorf("Used() = %d, want 30", got)
+	}
+
+	if _, tooLarge, _ := lease.measure(writeClone(t, m.dir, 150)); !tooLarge {
+		t.Error("expected a clone above the quota to be reported as too large")
+	}
+
+	lease.release()
+	lease.release()
+	if got := m.Used(); got != 0 {
+		t.Errorf("Used() after release = %d, want 0", got)
+	}
+}
+
+func TestWorkspaceManager_ZeroQuotaOnlyTracks(t *testing.T) {
+	m := newTestWorkspace(t, 0, 60)
+	for i := 0; i < 3; i++ {
+		if _, err := m.acquire(context.Background()); err != nil {
+			t.Fatalf("acquire %d: %v", i, err)
+		}
+	}
+	if got := m.Used(); got != 180 {
+		t.Errorf("Used() = %d, want 180", got)
+	}
+}
+
+func TestWorkspaceManager_CleanupOrphans(t *testing.T) {
+	m := newTestWorkspace(t, 0, 0)
+	old := time.Now().Add(-2 * DefaultOrphanAge)
+	for range 2 {
+		if err := os.Chtimes(writeClone(t, m.dir, 1), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	live := writeClone(t, m.dir, 1)
+	unrelated := filepath.Join(m.dir, "unrelated")
+	if err := os.Mkdir(unrelated, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(unrelated, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := m.CleanupOrphans(context.Background())
+	if err != nil || removed != 2 {
+		t.Fatalf("CleanupOrphans = (%d, %v), want (2, nil)", removed, err)
+	}
+	if _, err := os.Stat(live); err != nil {
+		t.Errorf("a recent clone of another worker should be kept: %v", err)
+	}
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Errorf("unrelated directory should be kept: %v", err)
+	}
+}
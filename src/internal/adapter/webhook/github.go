@@ -0,0 +1,193 @@
+// Package webhook receives repository webhooks and turns them into analysis triggers.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/webhook"
+)
+
+const (
+	githubHost = "github.com"
+
+	// maxPayloadBytes matches GitHub's webhook payload cap.
+	maxPayloadBytes = 25 << 20
+
+	headerDelivery  = "X-GitHub-Delivery"
+	headerEvent     = "X-GitHub-Event"
+	headerSignature = "X-Hub-Signature-256"
+	signaturePrefix = "sha256="
+)
+
+// Triggerer starts analyses for webhook triggers.
+type Triggerer interface {
+	Execute(ctx context.Context, trigger webhook.Trigger) (webhook.Outcome, error)
+}
+
+// GitHubHandler validates GitHub webhooks and triggers analyses for pushes to
+// the default branch and pull requests merged into it.
+// Other branches are ignored: only the default branch is tracked automatically,
+// other branches are analyzed on request.
+type GitHubHandler struct {
+	secret    []byte
+	triggerer Triggerer
+}
+
+// NewGitHubHandler creates a handler verifying payloads with the webhook secret.
+func NewGitHubHandler(secret []byte, triggerer Triggerer) *GitHubHandler {
+	return &GitHubHandler{
+		secret:    secret,
+		triggerer: triggerer,
+	}
+}
+
+type githubRepository struct {
+	DefaultBranch string `json:"default_branch"`
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Owner         struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+type pushEvent struct {
+	After      string           `json:"after"`
+	Deleted    bool             `json:"deleted"`
+	Ref        string           `json:"ref"`
+	Repository githubRepository `json:"repository"`
+}
+
+type pullRequestEvent struct {
+	Action      string `json:"action"`
+	PullRequest struct {
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+		MergeCommitSHA string `json:"merge_commit_sha"`
+		Merged         bool   `json:"merged"`
+	} `json:"pull_request"`
+	Repository githubRepository `json:"repository"`
+}
+
+type response struct {
+	Reason string `json:"reason,omitempty"`
+	Result string `json:"result"`
+}
+
+func (h *GitHubHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadBytes))
+	if err != nil {
+		http.Error(w, "failed to read payload", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !h.validSignature(body, r.Header.Get(headerSignature)) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event := r.Header.Get(headerEvent)
+	trigger, reason, err := parseTrigger(event, body)
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if trigger == nil {
+		writeJSON(w, http.StatusOK, response{Reason: reason, Result: "ignored"})
+		return
+	}
+	// The delivery ID is not covered by the signature; it only identifies
+	// GitHub's redeliveries of a payload that is itself signed.
+	trigger.DeliveryID = r.Header.Get(headerDelivery)
+
+	outcome, err := h.triggerer.Execute(r.Context(), *trigger)
+	if err != nil {
+		if errors.Is(err, webhook.ErrInvalidTrigger) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.ErrorContext(r.Context(), "webhook trigger failed",
+			"event", event,
+			"owner", trigger.Owner,
+			"repo", trigger.Repo,
+			"error", err,
+		)
+		http.Error(w, "failed to trigger analysis", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, response{Result: string(outcome)})
+}
+
+func (h *GitHubHandler) validSignature(body []byte, header string) bool {
+	if len(h.secret) == 0 || !strings.HasPrefix(header, signaturePrefix) {
+		return false
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(header, signaturePrefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// parseTrigger returns a nil trigger with a reason for events that don't start an analysis.
+func parseTrigger(event string, body []byte) (*webhook.Trigger, string, error) {
+	switch event {
+	case "ping":
+		return nil, "ping", nil
+	case "push":
+		var e pushEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, "", err
+		}
+		if e.Deleted {
+			return nil, "branch deleted", nil
+		}
+		if e.Ref != "refs/heads/"+e.Repository.DefaultBranch {
+			return nil, "not the default branch", nil
+		}
+		return newTrigger(event, e.Repository, e.After), "", nil
+	case "pull_request":
+		var e pullRequestEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, "", err
+		}
+		if e.Action != "closed" || !e.PullRequest.Merged {
+			return nil, "pull request not merged", nil
+		}
+		if e.PullRequest.Base.Ref != e.Repository.DefaultBranch {
+			return nil, "not merged into the default branch", nil
+		}
+		return newTrigger(event, e.Repository, e.PullRequest.MergeCommitSHA), "", nil
+	default:
+		return nil, "unsupported event", nil
+	}
+}
+
+func newTrigger(event string, repo githubRepository, commitSHA string) *webhook.Trigger {
+	return &webhook.Trigger{
+		Branch:         repo.DefaultBranch,
+		CommitSHA:      commitSHA,
+		Event:          event,
+		ExternalRepoID: strconv.FormatInt(repo.ID, 10),
+		Host:           githubHost,
+		Owner:          repo.Owner.Login,
+		Repo:           repo.Name,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/webhook"
+)
+
+const testDeliveryID = "72d3162e-cc78-11e3-81ab-4c9367dc0958"
+
+var testSecret = []byte("s3cret")
+
+type mockTriggerer struct {
+	err      error
+	outcome  webhook.Outcome
+	triggers []webhook.Trigger
+}
+
+func (m *mockTriggerer) Execute(_ context.Context, trigger webhook.Trigger) (webhook.Outcome, error) {
+	m.triggers = append(m.triggers, trigger)
+	return m.outcome, m.err
+}
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func deliver(h http.Handler, event, body, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(body))
+	req.Header.Set(headerDelivery, testDeliveryID)
+	req.Header.Set(headerEvent, event)
+	req.Header.Set(headerSignature, signature)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+const repoJSON = `"repository":{"id":42,"name":"hello","default_branch":"main","owner":{"login":"octocat"}}`
+
+func TestGitHubHandler_Signature(t *testing.T) {
+	body := `{"zen":"hi"}`
+
+	tests := []struct {
+		name      string
+		secret    []byte
+		signature string
+		want      int
+	}{
+		{"valid signature", testSecret, sign(body), http.StatusOK},
+		{"missing signature", testSecret, "", http.StatusUnauthorized},
+		{"wrong signature", testSecret, sign(body + "x"), http.StatusUnauthorized},
+		{"malformed signature", testSecret, "sha256=zz", http.StatusUnauthorized},
+		{"no secret configured", nil, sign(body), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := deliver(NewGitHubHandler(tt.secret, &mockTriggerer{}), "ping", body, tt.signature)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubHandler_Events(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      string
+		body       string
+		wantStatus int
+		wantSHA    string // empty when no trigger is expected
+	}{
+		{
+			name:       "push to default branch",
+			event:      "push",
+			body:       `{"ref":"refs/heads/main","after":"abc123",` + repoJSON + `}`,
+			wantStatus: http.StatusAccepted,
+			wantSHA:    "abc123",
+		},
+		{
+			name:       "push to other branch",
+			event:      "push",
+			body:       `{"ref":"refs/heads/feature","after":"abc123",` + repoJSON + `}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "default branch deleted",
+			event:      "push",
+			body:       `{"ref":"refs/heads/main","deleted":true,"after":"0000000000000000000000000000000000000000",` + repoJSON + `}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "pull request merged into default branch",
+			event:      "pull_request",
+			body:       `{"action":"closed","pull_request":{"merged":true,"merge_commit_sha":"def456","base":{"ref":"main"}},` + repoJSON + `}`,
+			wantStatus: http.StatusAccepted,
+			wantSHA:    "def456",
+		},
+		{
+			name:       "pull request opened",
+			event:      "pull_request",
+			body:       `{"action":"opened","pull_request":{"merged":false,"base":{"ref":"main"}},` + repoJSON + `}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "pull request merged into other branch",
+			event:      "pull_request",
+			body:       `{"action":"closed","pull_request":{"merged":true,"merge_commit_sha":"def456","base":{"ref":"release"}},` + repoJSON + `}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unsupported event",
+			event:      "issues",
+			body:       `{}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "malformed payload",
+			event:      "push",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			triggerer := &mockTriggerer{outcome: webhook.OutcomeEnqueued}
+			rec := deliver(NewGitHubHandler(testSecret, triggerer), tt.event, tt.body, sign(tt.body))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantSHA == "" {
+				if len(triggerer.triggers) != 0 {
+					t.Errorf("expected no trigger, got %+v", triggerer.triggers)
+				}
+				return
+			}
+			if len(triggerer.triggers) != 1 {
+				t.Fatalf("expected one trigger, got %d", len(triggerer.triggers))
+			}
+			got := triggerer.triggers[0]
+			want := webhook.Trigger{
+				Branch:         "main",
+				CommitSHA:      tt.wantSHA,
+				DeliveryID:     testDeliveryID,
+				Event:          tt.event,
+				ExternalRepoID: "42",
+				Host:           "github.com",
+				Owner:          "octocat",
+				Repo:           "hello",
+			}
+			if got != want {
+				t.Errorf("trigger = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestGitHubHandler_TriggerErrors(t *testing.T) {
+	body := `{"ref":"refs/heads/main","after":"abc123",` + repoJSON + `}`
+
+	rec := deliver(NewGitHubHandler(testSecret, &mockTriggerer{err: errors.New("queue down")}), "push", body, sign(body))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+
+	rec = deliver(NewGitHubHandler(testSecret, &mockTriggerer{err: webhook.ErrInvalidTrigger}), "push", body, sign(body))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
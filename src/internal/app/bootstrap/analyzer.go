@@ -5,28 +5,42 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/specvital/worker/internal/adapter/queue/analyze"
 	"github.com/specvital/worker/internal/app"
+	"github.com/specvital/worker/internal/domain/hostpool"
+	"github.com/specvital/worker/internal/domain/region"
+	"github.com/specvital/worker/internal/domain/sla"
 	"github.com/specvital/worker/internal/infra/buildinfo"
 	"github.com/specvital/worker/internal/infra/config"
 	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/httpserver"
 	infraqueue "github.com/specvital/worker/internal/infra/queue"
 )
 
 // AnalyzerConfig holds configuration for the analyzer service.
 type AnalyzerConfig struct {
 	DatabaseURL     string
+	DrainTimeout    time.Duration
 	EncryptionKey   string
 	Fairness        config.FairnessConfig
+	GitHubApp       config.GitHubAppConfig
+	HostPools       []hostpool.Pool
+	HTTPAddr        string
+	MinHelperRefs   int
+	MinTestVariants int
+	Notifications   config.NotificationsConfig
 	QueueWorkers    config.QueueWorkers
+	Region          string
+	Retry           config.RetryConfig
 	ServiceName     string
 	ShutdownTimeout time.Duration
+	SLATargets      sla.Targets
 	Streaming       config.StreamingConfig
+	TokenKeys       config.TokenKeysConfig
+	Tracing         config.TracingConfig
+	Workspace       config.WorkspaceConfig
 }
 
 // Validate checks that required analyzer configuration fields are set.
@@ -37,7 +51,7 @@ func (c *AnalyzerConfig) Validate() error {
 	if c.DatabaseURL == "" {
 		return fmt.Errorf("database URL is required")
 	}
-	if c.EncryptionKey == "" {
+	if c.EncryptionKey == "" && !c.TokenKeys.Configured() {
 		return fmt.Errorf("encryption key is required")
 	}
 	return nil
@@ -48,6 +62,9 @@ func (c *AnalyzerConfig) applyDefaults() {
 	if c.ShutdownTimeout <= 0 {
 		c.ShutdownTimeout = infraqueue.DefaultShutdownTimeout
 	}
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = infraqueue.DefaultRescueStuckJobsAfter
+	}
 }
 
 // StartAnalyzer starts the analyzer service for queue processing.
@@ -71,6 +88,26 @@ func StartAnalyzer(cfg AnalyzerConfig) error {
 	defer pool.Close()
 
 	slog.Info("postgres connected")
+	registerQueueDepthMetric(pool)
+
+	identity := buildinfo.CurrentIdentity()
+	slog.Info("worker identity", identity.LogAttrs()...)
+
+	stopTracing := startTracing(ctx, cfg.Tracing, cfg.ServiceName, identity)
+	defer stopTracing()
+
+	health := httpserver.NewHealth()
+	httpSrv, err := startHTTPServer(cfg.HTTPAddr, AnalyzerBuildReport(cfg, identity), health, pool)
+	if err != nil {
+		return fmt.Errorf("http server: %w", err)
+	}
+	if httpSrv != nil {
+		defer func() {
+			if err := httpSrv.Stop(context.Background()); err != nil {
+				slog.Error("http server stop error", "error", err)
+			}
+		}()
+	}
 
 	parserVersion := buildinfo.ExtractCoreVersion()
 	if err := registerParserVersion(ctx, pool, parserVersion); err != nil {
@@ -78,11 +115,22 @@ func StartAnalyzer(cfg AnalyzerConfig) error {
 	}
 
 	container, err := app.NewAnalyzerContainer(ctx, app.ContainerConfig{
-		EncryptionKey: cfg.EncryptionKey,
-		Fairness:      cfg.Fairness,
-		ParserVersion: parserVersion,
-		Pool:          pool,
-		Streaming:     cfg.Streaming,
+		EncryptionKey:   cfg.EncryptionKey,
+		Fairness:        cfg.Fairness,
+		GitHubApp:       cfg.GitHubApp,
+		HostPools:       cfg.HostPools,
+		Identity:        identity,
+		MinHelperRefs:   cfg.MinHelperRefs,
+		MinTestVariants: cfg.MinTestVariants,
+		Notifications:   cfg.Notifications,
+		ParserVersion:   parserVersion,
+		Pool:            pool,
+		Region:          cfg.Region,
+		Retry:           cfg.Retry,
+		SLATargets:      cfg.SLATargets,
+		Streaming:       cfg.Streaming,
+		TokenKeys:       cfg.TokenKeys,
+		Workspace:       cfg.Workspace,
 	})
 	if err != nil {
 		return fmt.Errorf("container: %w", err)
@@ -93,10 +141,13 @@ func StartAnalyzer(cfg AnalyzerConfig) error {
 		}
 	}()
 
-	queues := buildAnalyzerQueues(cfg.QueueWorkers)
+	runWarmup(ctx, defaultWarmupTimeout, []warmupStep{poolWarmupStep(pool)})
+
+	queues := buildAnalyzerQueues(cfg.QueueWorkers, cfg.HostPools, cfg.Region)
 	srv, err := infraqueue.NewServer(ctx, infraqueue.ServerConfig{
 		Pool:            pool,
 		Queues:          queues,
+		RetryPolicy:     container.RetryPolicy,
 		ShutdownTimeout: cfg.ShutdownTimeout,
 		Workers:         container.Workers,
 		Middleware:      container.Middleware,
@@ -109,17 +160,12 @@ func StartAnalyzer(cfg AnalyzerConfig) error {
 	if err := srv.Start(ctx); err != nil {
 		return fmt.Errorf("start server: %w", err)
 	}
+	health.AddCheck("postgres", pool.Ping)
+	health.AddCheck("queue", srv.Ready)
+	health.SetJobs(srv)
 	slog.Info("analyzer ready")
 
-	shutdown := make(chan os.Signal, 1)
-	signal.Notify(shutdown, syscall.SIGTERM, syscall.SIGINT)
-
-	sig := <-shutdown
-	slog.Info("shutdown signal received", "signal", sig.String())
-
-	if err := srv.Stop(ctx); err != nil {
-		slog.Error("queue server stop error", "error", err)
-	}
+	awaitShutdown(ctx, srv, health, cfg.DrainTimeout)
 	slog.Info("queue server stopped")
 
 	slog.Info("service shutdown complete", "name", cfg.ServiceName)
@@ -127,10 +173,27 @@ func StartAnalyzer(cfg AnalyzerConfig) error {
 }
 
 // buildAnalyzerQueues creates queue allocations for analyzer service.
-func buildAnalyzerQueues(qw config.QueueWorkers) []infraqueue.QueueAllocation {
-	return []infraqueue.QueueAllocation{
-		{Name: analyze.QueuePriority, MaxWorkers: qw.Priority},
-		{Name: analyze.QueueDefault, MaxWorkers: qw.Default},
-		{Name: analyze.QueueScheduled, MaxWorkers: qw.Scheduled},
+// Workers only subscribe to the queues of their own region. Each host pool
+// adds its own tier queues, so the jobs of its host never take the worker
+// slots of the others.
+func buildAnalyzerQueues(qw config.QueueWorkers, pools []hostpool.Pool, workerRegion string) []infraqueue.QueueAllocation {
+	queues := []infraqueue.QueueAllocation{
+		{Name: region.QueueName(analyze.QueuePriority, workerRegion), MaxWorkers: qw.Priority},
+		{Name: region.QueueName(analyze.QueueDefault, workerRegion), MaxWorkers: qw.Default},
+		{Name: region.QueueName(analyze.QueueScheduled, workerRegion), MaxWorkers: qw.Scheduled},
+	}
+	for _, p := range pools {
+		poolWorkers := func(tier int) int {
+			if p.Workers > 0 {
+				return p.Workers
+			}
+			return tier
+		}
+		queues = append(queues,
+			infraqueue.QueueAllocation{Name: region.QueueName(hostpool.QueueName(analyze.QueuePriority, p.Name), workerRegion), MaxWorkers: poolWorkers(qw.Priority)},
+			infraqueue.QueueAllocation{Name: region.QueueName(hostpool.QueueName(analyze.QueueDefault, p.Name), workerRegion), MaxWorkers: poolWorkers(qw.Default)},
+			infraqueue.QueueAllocation{Name: region.QueueName(hostpool.QueueName(analyze.QueueScheduled, p.Name), workerRegion), MaxWorkers: poolWorkers(qw.Scheduled)},
+		)
 	}
+	return queues
 }
@@ -0,0 +1,103 @@
+package bootstrap
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/specvital/worker/internal/infra/httpserver"
+	infraqueue "github.com/specvital/worker/internal/infra/queue"
+)
+
+// drainProgressInterval is how often a drain logs the jobs it waits for.
+const drainProgressInterval = 30 * time.Second
+
+// queueServer is the part of infraqueue.Server used to shut a service down.
+type queueServer interface {
+	Drain(ctx context.Context) error
+	InFlightJobs() []infraqueue.InFlightJob
+	Stop(ctx context.Context) error
+}
+
+// awaitShutdown blocks until the service is told to stop, then stops srv.
+// SIGTERM and SIGINT stop within the shutdown timeout. SIGUSR1 or
+// POST /admin/drain start a drain instead: no new jobs are fetched and jobs
+// in flight may run up to drainTimeout, which suits deploys where long
+// spec-view jobs would not fit the shutdown timeout.
+func awaitShutdown(ctx context.Context, srv queueServer, health *httpserver.Health, drainTimeout time.Duration) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	select {
+	case sig := <-signals:
+		if sig != syscall.SIGUSR1 {
+			slog.Info("shutdown signal received", "signal", sig.String())
+			if err := srv.Stop(ctx); err != nil {
+				slog.Error("queue server stop error", "error", err)
+			}
+			return
+		}
+		slog.Info("drain signal received", "signal", sig.String())
+		health.RequestDrain()
+	case <-health.DrainRequested():
+		slog.Info("drain requested over http")
+	}
+
+	drain(ctx, srv, signals, drainTimeout, drainProgressInterval)
+}
+
+// drain waits for srv to finish its in-flight jobs, logging progress every
+// interval. A SIGTERM or SIGINT during the drain cancels the remaining jobs.
+func drain(ctx context.Context, srv queueServer, signals <-chan os.Signal, timeout, interval time.Duration) {
+	drainCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	slog.Info("draining queue server", "in_flight", len(srv.InFlightJobs()), "timeout", timeout)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Drain(drainCtx) }()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				slog.Error("queue server drain error", "error", err)
+			}
+			slog.Info("drain complete", "duration_ms", time.Since(started).Milliseconds())
+			return
+		case <-ticker.C:
+			logDrainProgress(srv.InFlightJobs(), started)
+		case sig := <-signals:
+			if sig == syscall.SIGUSR1 {
+				continue
+			}
+			slog.Warn("shutdown signal received while draining, cancelling in-flight jobs", "signal", sig.String())
+			cancel()
+		}
+	}
+}
+
+// logDrainProgress reports the jobs still running, oldest first.
+func logDrainProgress(jobs []infraqueue.InFlightJob, started time.Time) {
+	attrs := []any{
+		"in_flight", len(jobs),
+		"elapsed_ms", time.Since(started).Milliseconds(),
+	}
+	if len(jobs) > 0 {
+		oldest := jobs[0]
+		attrs = append(attrs,
+			"oldest_job_id", oldest.ID,
+			"oldest_job_kind", oldest.Kind,
+			"oldest_running_ms", time.Since(oldest.StartedAt).Milliseconds(),
+		)
+	}
+	slog.Info("drain progress", attrs...)
+}
@@ -0,0 +1,100 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	infraqueue "github.com/specvital/worker/internal/infra/queue"
+)
+
+// fakeQueueServer drains once release is closed or the drain is cancelled.
+type fakeQueueServer struct {
+	cancelled bool
+	release   chan struct{}
+}
+
+func (f *fakeQueueServer) Drain(ctx context.Context) error {
+	select {
+	case <-f.release:
+		return nil
+	case <-ctx.Done():
+		f.cancelled = true
+		return nil
+	}
+}
+
+func (f *fakeQueueServer) InFlightJobs() []infraqueue.InFlightJob {
+	return []infraqueue.InFlightJob{{ID: 1, Kind: "specview:generate", StartedAt: time.Now()}}
+}
+
+func (f *fakeQueueServer) Stop(context.Context) error {
+	return nil
+}
+
+func runDrain(srv *fakeQueueServer, signals chan os.Signal, timeout time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		drain(context.Background(), srv, signals, timeout, time.Millisecond)
+		close(done)
+	}()
+	return done
+}
+
+func waitDone(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("drain did not return")
+	}
+}
+
+func TestDrain_WaitsForInFlightJobs(t *testing.T) {
+	srv := &fakeQueueServer{release: make(chan struct{})}
+	signals := make(chan os.Signal, 1)
+	done := runDrain(srv, signals, time.Hour)
+
+	signals <- syscall.SIGUSR1
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("drain returned while jobs were in flight")
+	default:
+	}
+
+	close(srv.release)
+	waitDone(t, done)
+	if srv.cancelled {
+		t.Error("a completed drain should not cancel jobs")
+	}
+}
+
+func TestDrain_CancelsJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		signal  os.Signal
+		timeout time.Duration
+	}{
+		{"sigterm during drain", syscall.SIGTERM, time.Hour},
+		{"drain timeout", nil, 10 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &fakeQueueServer{release: make(chan struct{})}
+			signals := make(chan os.Signal, 1)
+			done := runDrain(srv, signals, tt.timeout)
+			if tt.signal != nil {
+				signals <- tt.signal
+			}
+
+			waitDone(t, done)
+			if !srv.cancelled {
+				t.Error("remaining jobs should be cancelled")
+			}
+		})
+	}
+}
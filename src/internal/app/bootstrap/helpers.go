@@ -6,13 +6,21 @@ import (
 	"log/slog"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/specvital/worker/internal/adapter/repository/postgres"
 	"github.com/specvital/worker/internal/infra/buildinfo"
+	"github.com/specvital/worker/internal/infra/config"
+	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/metrics"
 	infraqueue "github.com/specvital/worker/internal/infra/queue"
+	"github.com/specvital/worker/internal/infra/tracing"
 )
 
+// tracingFlushTimeout bounds exporting the last spans on shutdown.
+const tracingFlushTimeout = 5 * time.Second
+
 // maskURL returns a sanitized URL for logging (hides credentials).
 func maskURL(rawURL string) string {
 	parsed, err := url.Parse(rawURL)
@@ -52,6 +60,35 @@ func registerParserVersion(ctx context.Context, pool *pgxpool.Pool, version stri
 	return nil
 }
 
+// registerQueueDepthMetric exposes River queue depth on /metrics.
+// Metrics are auxiliary, so a failed registration only logs.
+func registerQueueDepthMetric(pool *pgxpool.Pool) {
+	if err := metrics.RegisterQueueDepth(metrics.Default, db.New(pool)); err != nil {
+		slog.Warn("failed to register queue depth metric (non-critical)", "error", err)
+	}
+}
+
+// startTracing exports traces when an OTLP endpoint is configured.
+// Like metrics, tracing is auxiliary: a failed setup only logs. The returned
+// func flushes pending spans and must run after the queue server stops.
+func startTracing(ctx context.Context, cfg config.TracingConfig, serviceName string, identity buildinfo.Identity) func() {
+	shutdown, err := tracing.Setup(ctx, cfg, serviceName, identity.Version)
+	if err != nil {
+		slog.Warn("failed to set up tracing (non-critical)", "error", err)
+		return func() {}
+	}
+	if cfg.Endpoint != "" {
+		slog.Info("tracing enabled", "endpoint", cfg.Endpoint, "sample_ratio", cfg.SampleRatio)
+	}
+	return func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+		defer cancel()
+		if err := shutdown(flushCtx); err != nil {
+			slog.Warn("failed to flush traces (non-critical)", "error", err)
+		}
+	}
+}
+
 // logQueueSubscription logs the queues that a service is subscribing to.
 func logQueueSubscription(service string, queues []infraqueue.QueueAllocation) {
 	var queueInfo []string
@@ -0,0 +1,42 @@
+package bootstrap
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/specvital/worker/internal/infra/config"
+	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/httpserver"
+	infraqueue "github.com/specvital/worker/internal/infra/queue"
+)
+
+// startIdleDetector serves /idle for the subscribed queues and listens for
+// River insert notifications that end an idle period. The returned func stops
+// listening.
+func startIdleDetector(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	jobs infraqueue.JobSource,
+	queues []infraqueue.QueueAllocation,
+	cfg config.IdleConfig,
+	health *httpserver.Health,
+) func() {
+	names := make([]string, 0, len(queues))
+	for _, q := range queues {
+		names = append(names, q.Name)
+	}
+
+	detector := infraqueue.NewIdleDetector(db.New(pool), jobs, names,
+		infraqueue.WithIdleGrace(cfg.Grace),
+		infraqueue.WithIdleWakeLead(cfg.WakeLead),
+	)
+	health.SetIdle(detector)
+
+	listenCtx, cancel := context.WithCancel(ctx)
+	go detector.Listen(listenCtx, pool)
+
+	slog.Info("idle detection enabled", "queues", names)
+	return cancel
+}
@@ -0,0 +1,112 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/specvital/worker/internal/adapter/eventsink"
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/infra/config"
+	"github.com/specvital/worker/internal/infra/db"
+	outboxuc "github.com/specvital/worker/internal/usecase/outbox"
+)
+
+// OutboxRelayConfig holds configuration for the outbox relay.
+type OutboxRelayConfig struct {
+	DatabaseURL string
+	Outbox      config.OutboxConfig
+	ServiceName string
+}
+
+// Validate checks that required outbox relay configuration fields are set.
+func (c *OutboxRelayConfig) Validate() error {
+	if c.ServiceName == "" {
+		return errors.New("service name is required")
+	}
+	if c.DatabaseURL == "" {
+		return errors.New("database URL is required")
+	}
+	if c.Outbox.SinkURL == "" {
+		return errors.New("sink URL is required (set OUTBOX_SINK_URL)")
+	}
+	return nil
+}
+
+// RunOutboxRelay publishes outbox events to the configured sink until ctx
+// ends, which is a clean shutdown.
+func RunOutboxRelay(ctx context.Context, cfg OutboxRelayConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	sink, err := eventsink.New(eventsink.Config{
+		SNSAccessKeyID:     cfg.Outbox.SNSAccessKeyID,
+		SNSEndpoint:        cfg.Outbox.SNSEndpoint,
+		SNSSecretAccessKey: cfg.Outbox.SNSSecretAccessKey,
+		URL:                cfg.Outbox.SinkURL,
+		WebhookSecret:      cfg.Outbox.WebhookSecret,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("create sink: %w", err)
+	}
+	if closer, ok := sink.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	slog.Info("starting service", "name", cfg.ServiceName)
+	slog.Info("config loaded",
+		"database_url", maskURL(cfg.DatabaseURL),
+		"sink", sinkLabel(cfg.Outbox.SinkURL),
+	)
+
+	pool, err := db.NewPool(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("database connection: %w", err)
+	}
+	defer pool.Close()
+
+	slog.Info("postgres connected")
+
+	var opts []outboxuc.Option
+	if cfg.Outbox.BatchSize > 0 {
+		opts = append(opts, outboxuc.WithBatchSize(cfg.Outbox.BatchSize))
+	}
+	if cfg.Outbox.MaxAttempts > 0 {
+		opts = append(opts, outboxuc.WithMaxAttempts(cfg.Outbox.MaxAttempts))
+	}
+	if cfg.Outbox.PollInterval > 0 {
+		opts = append(opts, outboxuc.WithPollInterval(cfg.Outbox.PollInterval))
+	}
+	if cfg.Outbox.Retention > 0 {
+		opts = append(opts, outboxuc.WithRetention(cfg.Outbox.Retention))
+	}
+
+	relay := outboxuc.NewRelayUseCase(postgres.NewOutboxRepository(pool), sink, opts...)
+	err = relay.Run(ctx, func(r outboxuc.RoundResult) {
+		if r.Claimed > 0 {
+			slog.Info("outbox round",
+				"published", r.Published,
+				"failed", r.Failed,
+				"gave_up", r.GaveUp,
+			)
+		}
+	})
+	if errors.Is(err, context.Canceled) {
+		slog.Info("service stopped", "name", cfg.ServiceName)
+		return nil
+	}
+	return err
+}
+
+// sinkLabel is the sink URL fit for logs: topic ARNs carry no secrets, URLs
+// may.
+func sinkLabel(sinkURL string) string {
+	if strings.HasPrefix(sinkURL, "arn:") {
+		return sinkURL
+	}
+	return maskURL(sinkURL)
+}
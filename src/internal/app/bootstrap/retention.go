@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/domain/retention"
 	"github.com/specvital/worker/internal/infra/db"
 	retentionuc "github.com/specvital/worker/internal/usecase/retention"
 )
@@ -20,8 +21,17 @@ type RetentionConfig struct {
 	BatchSize   int
 	BatchSleep  time.Duration
 	DatabaseURL string
-	ServiceName string
-	Timeout     time.Duration
+	// DeletedDocumentGrace is how long soft-deleted spec documents can be
+	// restored before they are purged; zero uses the default.
+	DeletedDocumentGrace time.Duration
+	// KeepVersions is how many versions of a spec document the version
+	// retention policy keeps; zero uses the default.
+	KeepVersions int
+	ServiceName  string
+	Timeout      time.Duration
+	// VersionRetentionDays is the age past which superseded spec document
+	// versions are pruned; zero disables pruning.
+	VersionRetentionDays int
 }
 
 // Validate checks that required retention configuration fields are set.
@@ -74,6 +84,12 @@ func RunRetentionCleanup(cfg RetentionConfig) (*retentionuc.CleanupResult, error
 	if cfg.BatchSleep > 0 {
 		opts = append(opts, retentionuc.WithBatchSleep(cfg.BatchSleep))
 	}
+	if cfg.DeletedDocumentGrace > 0 {
+		opts = append(opts, retentionuc.WithDeletedDocumentGrace(cfg.DeletedDocumentGrace))
+	}
+	if policy := retention.NewVersionPolicy(cfg.VersionRetentionDays, cfg.KeepVersions); policy.Enabled() {
+		opts = append(opts, retentionuc.WithVersionPolicy(policy))
+	}
 
 	usecase := retentionuc.NewCleanupUseCase(repo, opts...)
 
@@ -4,29 +4,56 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/specvital/worker/internal/adapter/queue/specview"
 	"github.com/specvital/worker/internal/app"
+	"github.com/specvital/worker/internal/domain/region"
+	"github.com/specvital/worker/internal/domain/sla"
+	specviewdomain "github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/buildinfo"
 	"github.com/specvital/worker/internal/infra/config"
 	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/httpserver"
 	infraqueue "github.com/specvital/worker/internal/infra/queue"
 )
 
+// specGeneratorRescueStuckJobsAfter must exceed the longest job timeout
+// (spec-view generation: 5h), or River retries jobs that are still running.
+// It is also the default drain timeout: a job running longer is retried
+// elsewhere anyway, so there is no point in waiting for it.
+const specGeneratorRescueStuckJobsAfter = 6 * time.Hour
+
 // SpecGeneratorConfig holds configuration for the spec-generator service.
 type SpecGeneratorConfig struct {
-	DatabaseURL       string
-	Fairness          config.FairnessConfig
-	GeminiAPIKey      string
-	GeminiPhase1Model string
-	GeminiPhase2Model string
-	MockMode          bool
-	QueueWorkers      config.QueueWorkers
-	ServiceName       string
-	ShutdownTimeout   time.Duration
+	AI                 config.AIConfig
+	CacheKeyHash       config.CacheKeyHashConfig
+	CacheScope         specviewdomain.CacheScopeMode
+	DatabaseURL        string
+	DedupSimilarity    float64
+	DrainTimeout       time.Duration
+	DocumentEncryption config.DocumentEncryptionConfig
+	DocumentSharing    bool
+	Fairness           config.FairnessConfig
+	FanOut             config.FanOutConfig
+	GeneratorMode      specviewdomain.GeneratorMode
+	HTTPAddr           string
+	Idle               config.IdleConfig
+	InFlightWait       time.Duration
+	MockMode           bool
+	Notifications      config.NotificationsConfig
+	PromptExperiment   config.PromptExperimentConfig
+	Quality            config.QualityConfig
+	QueueWorkers       config.QueueWorkers
+	QuotaWarnings      []int
+	Region             string
+	Retry              config.RetryConfig
+	SemanticCache      config.SemanticCacheConfig
+	ServiceName        string
+	ShutdownTimeout    time.Duration
+	SLATargets         sla.Targets
+	Takeout            config.TakeoutConfig
+	Tracing            config.TracingConfig
 }
 
 // Validate checks that required spec-generator configuration fields are set.
@@ -37,9 +64,9 @@ func (c *SpecGeneratorConfig) Validate() error {
 	if c.DatabaseURL == "" {
 		return fmt.Errorf("database URL is required")
 	}
-	// Skip GeminiAPIKey validation when MockMode is enabled
-	if !c.MockMode && c.GeminiAPIKey == "" {
-		return fmt.Errorf("gemini API key is required (set MOCK_MODE=true to skip)")
+	// Skip AI API key validation when MockMode is enabled
+	if !c.MockMode && c.AI.APIKey == "" {
+		return fmt.Errorf("AI API key is required (set MOCK_MODE=true to skip)")
 	}
 	return nil
 }
@@ -49,10 +76,13 @@ func (c *SpecGeneratorConfig) applyDefaults() {
 	if c.ShutdownTimeout <= 0 {
 		c.ShutdownTimeout = infraqueue.DefaultShutdownTimeout
 	}
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = specGeneratorRescueStuckJobsAfter
+	}
 }
 
 // StartSpecGenerator starts the spec-generator service for queue processing.
-// Spec-generators consume specview:generate tasks and process them using Gemini AI.
+// Spec-generators consume specview:generate tasks and process them using the configured AI provider.
 // Horizontal scaling is safe - multiple spec-generator instances share the workload.
 func StartSpecGenerator(cfg SpecGeneratorConfig) error {
 	if err := cfg.Validate(); err != nil {
@@ -72,14 +102,50 @@ func StartSpecGenerator(cfg SpecGeneratorConfig) error {
 	defer pool.Close()
 
 	slog.Info("postgres connected")
+	registerQueueDepthMetric(pool)
+
+	identity := buildinfo.CurrentIdentity()
+	slog.Info("worker identity", identity.LogAttrs()...)
+
+	stopTracing := startTracing(ctx, cfg.Tracing, cfg.ServiceName, identity)
+	defer stopTracing()
+
+	health := httpserver.NewHealth()
+	httpSrv, err := startHTTPServer(cfg.HTTPAddr, SpecGeneratorBuildReport(cfg, identity), health, pool)
+	if err != nil {
+		return fmt.Errorf("http server: %w", err)
+	}
+	if httpSrv != nil {
+		defer func() {
+			if err := httpSrv.Stop(context.Background()); err != nil {
+				slog.Error("http server stop error", "error", err)
+			}
+		}()
+	}
 
 	container, err := app.NewSpecGeneratorContainer(ctx, app.ContainerConfig{
-		Fairness:          cfg.Fairness,
-		GeminiAPIKey:      cfg.GeminiAPIKey,
-		GeminiPhase1Model: cfg.GeminiPhase1Model,
-		GeminiPhase2Model: cfg.GeminiPhase2Model,
-		MockMode:          cfg.MockMode,
-		Pool:              pool,
+		AI:                 cfg.AI,
+		CacheKeyHash:       cfg.CacheKeyHash,
+		CacheScope:         cfg.CacheScope,
+		DedupSimilarity:    cfg.DedupSimilarity,
+		DocumentEncryption: cfg.DocumentEncryption,
+		DocumentSharing:    cfg.DocumentSharing,
+		Fairness:           cfg.Fairness,
+		FanOut:             cfg.FanOut,
+		GeneratorMode:      cfg.GeneratorMode,
+		Identity:           identity,
+		InFlightWait:       cfg.InFlightWait,
+		MockMode:           cfg.MockMode,
+		Notifications:      cfg.Notifications,
+		Pool:               pool,
+		PromptExperiment:   cfg.PromptExperiment,
+		Quality:            cfg.Quality,
+		QuotaWarnings:      cfg.QuotaWarnings,
+		Region:             cfg.Region,
+		Retry:              cfg.Retry,
+		SemanticCache:      cfg.SemanticCache,
+		SLATargets:         cfg.SLATargets,
+		Takeout:            cfg.Takeout,
 	})
 	if err != nil {
 		return fmt.Errorf("container: %w", err)
@@ -90,13 +156,17 @@ func StartSpecGenerator(cfg SpecGeneratorConfig) error {
 		}
 	}()
 
-	queues := buildSpecGeneratorQueues(cfg.QueueWorkers)
+	runWarmup(ctx, defaultWarmupTimeout, specGeneratorWarmupSteps(pool, container.AIProvider))
+
+	queues := buildSpecGeneratorQueues(cfg.QueueWorkers, cfg.FanOut, cfg.Region)
 	srv, err := infraqueue.NewServer(ctx, infraqueue.ServerConfig{
-		Pool:            pool,
-		Queues:          queues,
-		ShutdownTimeout: cfg.ShutdownTimeout,
-		Workers:         container.Workers,
-		Middleware:      container.Middleware,
+		Pool:                 pool,
+		Queues:               queues,
+		RescueStuckJobsAfter: specGeneratorRescueStuckJobsAfter,
+		RetryPolicy:          container.RetryPolicy,
+		ShutdownTimeout:      cfg.ShutdownTimeout,
+		Workers:              container.Workers,
+		Middleware:           container.Middleware,
 	})
 	if err != nil {
 		return fmt.Errorf("queue server: %w", err)
@@ -106,17 +176,16 @@ func StartSpecGenerator(cfg SpecGeneratorConfig) error {
 	if err := srv.Start(ctx); err != nil {
 		return fmt.Errorf("start server: %w", err)
 	}
+	health.AddCheck("postgres", pool.Ping)
+	health.AddCheck("queue", srv.Ready)
+	health.SetJobs(srv)
+	if cfg.Idle.Enabled {
+		stopIdle := startIdleDetector(ctx, pool, srv, queues, cfg.Idle, health)
+		defer stopIdle()
+	}
 	slog.Info("spec-generator ready")
 
-	shutdown := make(chan os.Signal, 1)
-	signal.Notify(shutdown, syscall.SIGTERM, syscall.SIGINT)
-
-	sig := <-shutdown
-	slog.Info("shutdown signal received", "signal", sig.String())
-
-	if err := srv.Stop(ctx); err != nil {
-		slog.Error("queue server stop error", "error", err)
-	}
+	awaitShutdown(ctx, srv, health, cfg.DrainTimeout)
 	slog.Info("queue server stopped")
 
 	slog.Info("service shutdown complete", "name", cfg.ServiceName)
@@ -124,10 +193,19 @@ func StartSpecGenerator(cfg SpecGeneratorConfig) error {
 }
 
 // buildSpecGeneratorQueues creates queue allocations for spec-generator service.
-func buildSpecGeneratorQueues(qw config.QueueWorkers) []infraqueue.QueueAllocation {
-	return []infraqueue.QueueAllocation{
-		{Name: specview.QueuePriority, MaxWorkers: qw.Priority},
-		{Name: specview.QueueDefault, MaxWorkers: qw.Default},
-		{Name: specview.QueueScheduled, MaxWorkers: qw.Scheduled},
+// Workers only subscribe to the queues of their own region. The Phase 2 child
+// queue is only subscribed when fan-out is enabled.
+func buildSpecGeneratorQueues(qw config.QueueWorkers, fanOut config.FanOutConfig, workerRegion string) []infraqueue.QueueAllocation {
+	queues := []infraqueue.QueueAllocation{
+		{Name: region.QueueName(specview.QueuePriority, workerRegion), MaxWorkers: qw.Priority},
+		{Name: region.QueueName(specview.QueueDefault, workerRegion), MaxWorkers: qw.Default},
+		{Name: region.QueueName(specview.QueueScheduled, workerRegion), MaxWorkers: qw.Scheduled},
+	}
+	if fanOut.Enabled {
+		queues = append(queues, infraqueue.QueueAllocation{
+			Name:       region.QueueName(specview.QueuePhase2, workerRegion),
+			MaxWorkers: fanOut.Workers,
+		})
 	}
+	return queues
 }
@@ -0,0 +1,28 @@
+package bootstrap
+
+import (
+	"testing"
+
+	"github.com/specvital/worker/internal/adapter/queue/specview"
+	infraqueue "github.com/specvital/worker/internal/infra/queue"
+)
+
+func TestSpecGeneratorRescueStuckJobsAfter_ExceedsJobTimeouts(t *testing.T) {
+	if timeout := (&specview.Worker{}).Timeout(nil); timeout >= specGeneratorRescueStuckJobsAfter {
+		t.Errorf("specview job timeout %v must be below rescue threshold %v", timeout, specGeneratorRescueStuckJobsAfter)
+	}
+}
+
+func TestApplyDefaults_DrainTimeout(t *testing.T) {
+	specGenerator := &SpecGeneratorConfig{}
+	specGenerator.applyDefaults()
+	if specGenerator.DrainTimeout != specGeneratorRescueStuckJobsAfter {
+		t.Errorf("spec-generator DrainTimeout = %v, want %v", specGenerator.DrainTimeout, specGeneratorRescueStuckJobsAfter)
+	}
+
+	analyzer := &AnalyzerConfig{}
+	analyzer.applyDefaults()
+	if analyzer.DrainTimeout != infraqueue.DefaultRescueStuckJobsAfter {
+		t.Errorf("analyzer DrainTimeout = %v, want %v", analyzer.DrainTimeout, infraqueue.DefaultRescueStuckJobsAfter)
+	}
+}
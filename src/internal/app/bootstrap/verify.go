@@ -0,0 +1,297 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/core/pkg/crypto"
+	"github.com/specvital/worker/internal/adapter/ai/prompt"
+	"github.com/specvital/worker/internal/adapter/ai/registry"
+	"github.com/specvital/worker/internal/app"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/config"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+// DefaultVerifyCheckTimeout bounds each readiness check, so an unreachable
+// dependency is reported instead of hanging the verification.
+const DefaultVerifyCheckTimeout = 15 * time.Second
+
+// CheckStatus is the outcome of a readiness check.
+type CheckStatus string
+
+const (
+	CheckFailed  CheckStatus = "FAIL"
+	CheckOK      CheckStatus = "OK"
+	CheckSkipped CheckStatus = "SKIP"
+)
+
+// CheckResult is one line of a readiness report.
+type CheckResult struct {
+	Detail string
+	Name   string
+	Status CheckStatus
+}
+
+// VerifyReport lists the readiness checks in the order they ran.
+type VerifyReport struct {
+	Checks []CheckResult
+}
+
+// Ready reports whether no check failed.
+func (r *VerifyReport) Ready() bool {
+	for _, c := range r.Checks {
+		if c.Status == CheckFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// run adds the result of one check, bounded by timeout.
+func (r *VerifyReport) run(ctx context.Context, timeout time.Duration, name string, check func(ctx context.Context) (string, error)) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	detail, err := check(ctx)
+	if err != nil {
+		r.Checks = append(r.Checks, CheckResult{Detail: err.Error(), Name: name, Status: CheckFailed})
+		return
+	}
+	r.Checks = append(r.Checks, CheckResult{Detail: detail, Name: name, Status: CheckOK})
+}
+
+func (r *VerifyReport) skip(name, reason string) {
+	r.Checks = append(r.Checks, CheckResult{Detail: reason, Name: name, Status: CheckSkipped})
+}
+
+// VerifyConfig loads the configuration the way the services do and checks
+// every dependency they need at startup: the database, the River tables, the
+// encryption keys, the prompt templates and the AI provider. Checks continue
+// past failures so one run reports every problem, and a check whose input is
+// missing is skipped. Nothing is written and no job is enqueued.
+func VerifyConfig(ctx context.Context, timeout time.Duration) *VerifyReport {
+	if timeout <= 0 {
+		timeout = DefaultVerifyCheckTimeout
+	}
+	report := &VerifyReport{}
+
+	// Settings other than the secrets still drive the remaining checks when
+	// only a secret is missing.
+	cfg, err := loadConfig(config.Load)
+	if err != nil {
+		report.Checks = append(report.Checks, CheckResult{Detail: err.Error(), Name: "config", Status: CheckFailed})
+		cfg, _ = loadConfig(func() (*config.Config, error) { return config.LoadSettings(), nil })
+	} else {
+		report.Checks = append(report.Checks, CheckResult{Detail: "all settings loaded", Name: "config", Status: CheckOK})
+	}
+
+	databaseURL := os.Getenv("DATABASE_URL")
+	var pool *pgxpool.Pool
+	if databaseURL == "" {
+		report.skip("database", "DATABASE_URL is not set")
+	} else {
+		report.run(ctx, timeout, "database", func(ctx context.Context) (string, error) {
+			p, err := db.NewPool(ctx, databaseURL)
+			if err != nil {
+				return "", err
+			}
+			pool = p
+			return maskURL(databaseURL), nil
+		})
+	}
+	if pool != nil {
+		defer pool.Close()
+	}
+
+	switch {
+	case pool == nil:
+		report.skip("queue", "no database connection")
+	case cfg == nil:
+		report.skip("queue", "settings did not load")
+	default:
+		report.run(ctx, timeout, "queue", func(ctx context.Context) (string, error) {
+			return checkQueues(ctx, pool, cfg)
+		})
+	}
+
+	report.run(ctx, timeout, "encryption_key", func(ctx context.Context) (string, error) {
+		var keys config.TokenKeysConfig
+		if cfg != nil {
+			keys = cfg.TokenKeys
+		}
+		return checkTokenKeys(ctx, os.Getenv("ENCRYPTION_KEY"), keys)
+	})
+
+	if key := os.Getenv("DOCUMENT_ENCRYPTION_KEY"); key == "" {
+		report.skip("document_encryption_key", "DOCUMENT_ENCRYPTION_KEY is not set")
+	} else {
+		report.run(ctx, timeout, "document_encryption_key", func(context.Context) (string, error) {
+			return checkDocumentEncryptionKeys(key, os.Getenv("DOCUMENT_ENCRYPTION_KEY_PREVIOUS"))
+		})
+	}
+
+	report.run(ctx, timeout, "prompt_templates", func(context.Context) (string, error) {
+		if err := prompt.CheckTemplates(); err != nil {
+			return "", err
+		}
+		return "all templates embedded", nil
+	})
+
+	if cfg == nil {
+		report.skip("ai_provider", "settings did not load")
+	} else {
+		report.run(ctx, timeout, "ai_provider", func(ctx context.Context) (string, error) {
+			return checkAIProvider(ctx, cfg.AI, cfg.MockMode)
+		})
+	}
+
+	return report
+}
+
+// loadConfig runs a config loader, turning the panics raised for invalid
+// settings into an error.
+func loadConfig(load func() (*config.Config, error)) (cfg *config.Config, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			cfg, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+	return load()
+}
+
+// checkQueues reads the state of every queue the services subscribe to,
+// which fails when the River migrations have not been applied.
+func checkQueues(ctx context.Context, pool *pgxpool.Pool, cfg *config.Config) (string, error) {
+	var queues []string
+	for _, q := range buildAnalyzerQueues(cfg.Queue.Analyzer, cfg.HostPools, cfg.Region) {
+		queues = append(queues, q.Name)
+	}
+	for _, q := range buildSpecGeneratorQueues(cfg.Queue.Specgen, cfg.FanOut, cfg.Region) {
+		queues = append(queues, q.Name)
+	}
+	state, err := db.New(pool).GetQueueIdleState(ctx, queues)
+	if err != nil {
+		return "", fmt.Errorf("read river_job: %w", err)
+	}
+	return fmt.Sprintf("%d queues, %d active jobs", len(queues), state.Active), nil
+}
+
+// checkEncryptionKey checks that the key decrypts tokens the way the analyzer
+// would, by round-tripping a probe value.
+func checkEncryptionKey(key string) (string, error) {
+	if key == "" {
+		return "", errors.New("ENCRYPTION_KEY is not set")
+	}
+	encryptor, err := crypto.NewEncryptorFromBase64(key)
+	if err != nil {
+		return "", err
+	}
+	const probe = "specvital-config-verify"
+	sealed, err := encryptor.Encrypt(probe)
+	if err != nil {
+		return "", fmt.Errorf("encrypt probe: %w", err)
+	}
+	opened, err := encryptor.Decrypt(sealed)
+	if err != nil {
+		return "", fmt.Errorf("decrypt probe: %w", err)
+	}
+	if opened != probe {
+		return "", errors.New("probe did not round-trip")
+	}
+	return "key decodes and round-trips", nil
+}
+
+// checkTokenKeys checks that the legacy key and the versioned token keys load
+// from their source and round-trip a probe through the current key.
+func checkTokenKeys(ctx context.Context, legacyKey string, keys config.TokenKeysConfig) (string, error) {
+	if !keys.Configured() {
+		return checkEncryptionKey(legacyKey)
+	}
+	keyring, err := app.NewTokenKeyring(ctx, legacyKey, keys)
+	if err != nil {
+		return "", err
+	}
+	defer keyring.Close()
+
+	const probe = "specvital-config-verify"
+	sealed, err := keyring.Encrypt(probe)
+	if err != nil {
+		return "", fmt.Errorf("encrypt probe: %w", err)
+	}
+	if opened, err := keyring.Decrypt(sealed); err != nil || opened != probe {
+		return "", fmt.Errorf("probe did not round-trip: %v", err)
+	}
+	return fmt.Sprintf("keys load and round-trip, current key %q", keyring.CurrentKeyID()), nil
+}
+
+// checkDocumentEncryptionKeys checks the master key wrapping tenant document
+// keys and, when set, the previous one still unwrapping them.
+func checkDocumentEncryptionKeys(key, previous string) (string, error) {
+	detail, err := checkEncryptionKey(key)
+	if err != nil {
+		return "", err
+	}
+	if previous == "" {
+		return detail, nil
+	}
+	if _, err := checkEncryptionKey(previous); err != nil {
+		return "", fmt.Errorf("DOCUMENT_ENCRYPTION_KEY_PREVIOUS: %w", err)
+	}
+	return detail + ", previous key too", nil
+}
+
+// checkAIProvider builds the configured provider and pings it when it
+// supports warmup. The mock provider is built but never pinged.
+func checkAIProvider(ctx context.Context, ai config.AIConfig, mockMode bool) (string, error) {
+	providerName := ai.Provider
+	if mockMode {
+		providerName = registry.ProviderMock
+	} else if ai.APIKey == "" {
+		return "", errors.New("AI_API_KEY is required (set MOCK_MODE=true to skip)")
+	}
+
+	provider, modelID, err := registry.Default().Create(ctx, providerName, registry.Config{
+		APIKey:      ai.APIKey,
+		APIVersion:  ai.APIVersion,
+		BaseURL:     ai.BaseURL,
+		Phase1Model: ai.Phase1Model,
+		Phase2Model: ai.Phase2Model,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer provider.Close()
+
+	warmer, ok := provider.(specview.Warmer)
+	if !ok {
+		return fmt.Sprintf("%s (%s), not pinged", providerName, modelID), nil
+	}
+	if err := warmer.Warmup(ctx); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s (%s) reachable", providerName, modelID), nil
+}
+
+// PrintVerifyReport writes the report as a table followed by a summary line.
+func PrintVerifyReport(w io.Writer, report *VerifyReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range report.Checks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Status, c.Name, c.Detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if report.Ready() {
+		_, err := fmt.Fprintln(w, "\nready")
+		return err
+	}
+	_, err := fmt.Fprintln(w, "\nnot ready")
+	return err
+}
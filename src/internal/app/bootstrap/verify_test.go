@@ -0,0 +1,139 @@
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/infra/config"
+)
+
+func checkStatuses(report *VerifyReport) map[string]CheckStatus {
+	statuses := make(map[string]CheckStatus, len(report.Checks))
+	for _, c := range report.Checks {
+		statuses[c.Name] = c.Status
+	}
+	return statuses
+}
+
+func TestVerifyConfig(t *testing.T) {
+	validKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+
+	t.Run("reports every missing setting without a database", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "")
+		t.Setenv("DOCUMENT_ENCRYPTION_KEY", "")
+		t.Setenv("ENCRYPTION_KEY", "")
+		t.Setenv("MOCK_MODE", "")
+
+		report := VerifyConfig(context.Background(), time.Second)
+
+		want := map[string]CheckStatus{
+			"config":                  CheckFailed,
+			"database":                CheckSkipped,
+			"queue":                   CheckSkipped,
+			"encryption_key":          CheckFailed,
+			"document_encryption_key": CheckSkipped,
+			"prompt_templates":        CheckOK,
+			"ai_provider":             CheckFailed,
+		}
+		got := checkStatuses(report)
+		for name, status := range want {
+			if got[name] != status {
+				t.Errorf("%s = %s, want %s", name, got[name], status)
+			}
+		}
+		if report.Ready() {
+			t.Error("report should not be ready")
+		}
+	})
+
+	t.Run("builds the mock provider in mock mode", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "")
+		t.Setenv("ENCRYPTION_KEY", validKey)
+		t.Setenv("MOCK_MODE", "true")
+
+		got := checkStatuses(VerifyConfig(context.Background(), time.Second))
+
+		if got["encryption_key"] != CheckOK {
+			t.Errorf("encryption_key = %s, want OK", got["encryption_key"])
+		}
+		if got["ai_provider"] != CheckOK {
+			t.Errorf("ai_provider = %s, want OK", got["ai_provider"])
+		}
+	})
+
+	t.Run("reports invalid settings instead of panicking", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "")
+		t.Setenv("ENCRYPTION_KEY", validKey)
+		t.Setenv("FAIRNESS_SNOOZE_DURATION", "-1s")
+
+		got := checkStatuses(VerifyConfig(context.Background(), time.Second))
+		if got["config"] != CheckFailed || got["ai_provider"] != CheckSkipped {
+			t.Errorf("config = %s, ai_provider = %s, want FAIL and SKIP", got["config"], got["ai_provider"])
+		}
+
+		t.Setenv("DATABASE_URL", "postgres://localhost/specvital")
+		if _, err := loadConfig(config.Load); err == nil || !strings.Contains(err.Error(), "FAIRNESS_SNOOZE_DURATION") {
+			t.Errorf("loadConfig() error = %v, want FAIRNESS_SNOOZE_DURATION", err)
+		}
+	})
+}
+
+func TestCheckTokenKeys(t *testing.T) {
+	validKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+	ctx := context.Background()
+
+	detail, err := checkTokenKeys(ctx, "", config.TokenKeysConfig{Keys: "2026-10=" + validKey})
+	if err != nil || !strings.Contains(detail, "2026-10") {
+		t.Errorf("versioned keys: %q, %v", detail, err)
+	}
+	if _, err := checkTokenKeys(ctx, validKey, config.TokenKeysConfig{Keys: "2026-10=short"}); err == nil {
+		t.Error("expected error for an invalid versioned key")
+	}
+	if _, err := checkTokenKeys(ctx, "", config.TokenKeysConfig{}); err == nil {
+		t.Error("expected error without any key")
+	}
+}
+
+func TestCheckDocumentEncryptionKeys(t *testing.T) {
+	validKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+
+	if _, err := checkDocumentEncryptionKeys(validKey, ""); err != nil {
+		t.Errorf("valid key: %v", err)
+	}
+	if _, err := checkDocumentEncryptionKeys(validKey, "not-a-key"); err == nil || !strings.Contains(err.Error(), "DOCUMENT_ENCRYPTION_KEY_PREVIOUS") {
+		t.Errorf("invalid previous key: error = %v, want DOCUMENT_ENCRYPTION_KEY_PREVIOUS", err)
+	}
+}
+
+func TestCheckEncryptionKey(t *testing.T) {
+	if _, err := checkEncryptionKey("not-a-key"); err == nil {
+		t.Error("expected error for an invalid key")
+	}
+	if _, err := checkEncryptionKey(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Error("expected error for a short key")
+	}
+}
+
+func TestPrintVerifyReport(t *testing.T) {
+	report := &VerifyReport{Checks: []CheckResult{
+		{Detail: "postgres://localhost/...", Name: "database", Status: CheckOK},
+		{Detail: "ENCRYPTION_KEY is not set", Name: "encryption_key", Status: CheckFailed},
+	}}
+
+	var buf bytes.Buffer
+	if err := PrintVerifyReport(&buf, report); err != nil {
+		t.Fatalf("PrintVerifyReport failed: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "FAIL  encryption_key  ENCRYPTION_KEY is not set") {
+		t.Errorf("missing failed check line:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "not ready\n") {
+		t.Errorf("missing summary:\n%s", out)
+	}
+}
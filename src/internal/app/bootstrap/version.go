@@ -0,0 +1,116 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/adapter/api"
+	"github.com/specvital/worker/internal/adapter/queue/analyze"
+	"github.com/specvital/worker/internal/adapter/queue/coverage"
+	"github.com/specvital/worker/internal/adapter/queue/requirement"
+	"github.com/specvital/worker/internal/adapter/queue/specview"
+	"github.com/specvital/worker/internal/adapter/queue/takeout"
+	"github.com/specvital/worker/internal/adapter/queue/testrun"
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/domain/servicetoken"
+	specviewdomain "github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/buildinfo"
+	"github.com/specvital/worker/internal/infra/httpserver"
+	servicetokenuc "github.com/specvital/worker/internal/usecase/servicetoken"
+)
+
+// AnalyzerBuildReport describes the analyzer build and its enabled features.
+func AnalyzerBuildReport(cfg AnalyzerConfig, identity buildinfo.Identity) buildinfo.Report {
+	report := buildinfo.NewReport(cfg.ServiceName, identity,
+		[]string{analyze.AnalyzeArgs{}.Kind(), analyze.CompareArgs{}.Kind(), testrun.Args{}.Kind(), coverage.Args{}.Kind()},
+		map[string]bool{
+			"code_coverage":      true,
+			"curation_rules":     true,
+			"fairness":           cfg.Fairness.Enabled,
+			"github_app":         cfg.GitHubApp.AppID != 0,
+			"host_pools":         len(cfg.HostPools) > 0,
+			"path_filters":       true,
+			"pr_compare":         true,
+			"progress_events":    true,
+			"rate_limit":         cfg.Fairness.Enabled && cfg.Fairness.RateLimitEnabled,
+			"repo_policy":        true,
+			"test_results":       true,
+			"tracing":            cfg.Tracing.Endpoint != "",
+			"worker_attribution": true,
+			"workspace_quota":    cfg.Workspace.QuotaBytes > 0,
+		},
+	)
+	report.Region = cfg.Region
+	return report
+}
+
+// SpecGeneratorBuildReport describes the spec-generator build and its enabled features.
+func SpecGeneratorBuildReport(cfg SpecGeneratorConfig, identity buildinfo.Identity) buildinfo.Report {
+	report := buildinfo.NewReport(cfg.ServiceName, identity,
+		[]string{
+			specview.Args{}.Kind(),
+			specview.IndexArgs{}.Kind(),
+			specview.GapsArgs{}.Kind(),
+			specview.DomainArgs{}.Kind(),
+			specview.ExportArgs{}.Kind(),
+			specview.ReclassifyArgs{}.Kind(),
+			specview.FeedbackArgs{}.Kind(),
+			requirement.Args{}.Kind(),
+			takeout.Args{}.Kind(),
+		},
+		map[string]bool{
+			"curation_rules":       true,
+			"document_encryption":  cfg.DocumentEncryption.MasterKey != "",
+			"document_sharing":     cfg.DocumentSharing,
+			"fairness":             cfg.Fairness.Enabled,
+			"gap_analysis":         true,
+			"heuristic_generation": cfg.GeneratorMode != "" && cfg.GeneratorMode != specviewdomain.GeneratorModeAI,
+			"idle_detection":       cfg.Idle.Enabled,
+			"mock_ai":              cfg.MockMode,
+			"phase2_fanout":        cfg.FanOut.Enabled,
+			"quota_warnings":       true,
+			"rate_limit":           cfg.Fairness.Enabled && cfg.Fairness.RateLimitEnabled,
+			"requirement_matching": true,
+			"search_index":         true,
+			"tenant_takeout":       cfg.Takeout.Bucket != "",
+			"tracing":              cfg.Tracing.Endpoint != "",
+			"worker_attribution":   true,
+		},
+	)
+	report.Region = cfg.Region
+	if !cfg.MockMode {
+		report.AIProvider = cfg.AI.Provider
+	}
+	return report
+}
+
+// PrintBuildReport writes the report as indented JSON, for the -version flag.
+func PrintBuildReport(w io.Writer, report buildinfo.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// startHTTPServer serves /version, /metrics and the health endpoints when addr
+// is set, and the admin endpoints to admin-scoped service tokens of pool.
+// Returns nil when disabled.
+func startHTTPServer(addr string, report buildinfo.Report, health *httpserver.Health, pool *pgxpool.Pool) (*httpserver.Server, error) {
+	if addr == "" {
+		return nil, nil
+	}
+	tokens := servicetokenuc.NewServiceTokenUseCase(postgres.NewServiceTokenRepository(pool))
+	adminAuth := httpserver.WithAdminAuth(func(next http.Handler) http.Handler {
+		return api.RequireScope(tokens, servicetoken.ScopeAdmin, next)
+	})
+	srv, err := httpserver.NewServer(addr, httpserver.NewMux(report, health, adminAuth))
+	if err != nil {
+		return nil, fmt.Errorf("listen %s: %w", addr, err)
+	}
+	srv.Start()
+	slog.Info("http server listening", "addr", srv.Addr())
+	return srv, nil
+}
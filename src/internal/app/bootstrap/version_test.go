@@ -0,0 +1,77 @@
+package bootstrap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/hostpool"
+	"github.com/specvital/worker/internal/infra/buildinfo"
+	"github.com/specvital/worker/internal/infra/config"
+)
+
+func TestSpecGeneratorBuildReport(t *testing.T) {
+	identity := buildinfo.Identity{GitSHA: "abc123", Hostname: "host", Version: "v1.0.0"}
+
+	t.Run("reports provider and job kinds", func(t *testing.T) {
+		report := SpecGeneratorBuildReport(SpecGeneratorConfig{
+			AI:          config.AIConfig{Provider: "gemini"},
+			Fairness:    config.FairnessConfig{Enabled: true},
+			ServiceName: "spec-generator",
+		}, identity)
+
+		if report.AIProvider != "gemini" || report.Service != "spec-generator" || report.GitSHA != "abc123" {
+			t.Errorf("unexpected report: %+v", report)
+		}
+		if !report.Features["fairness"] || report.Features["mock_ai"] {
+			t.Errorf("unexpected features: %v", report.Features)
+		}
+		if len(report.JobKinds) != 9 || report.JobKinds[0] != "specview:generate" {
+			t.Errorf("unexpected job kinds: %v", report.JobKinds)
+		}
+	})
+
+	t.Run("mock mode hides provider", func(t *testing.T) {
+		report := SpecGeneratorBuildReport(SpecGeneratorConfig{
+			AI:       config.AIConfig{Provider: "gemini"},
+			MockMode: true,
+		}, identity)
+
+		if report.AIProvider != "" || !report.Features["mock_ai"] {
+			t.Errorf("unexpected mock report: %+v", report)
+		}
+	})
+}
+
+func TestBuildQueues_Region(t *testing.T) {
+	qw := config.QueueWorkers{Default: 2, Priority: 3, Scheduled: 1}
+
+	for _, q := range buildAnalyzerQueues(qw, nil, "eu") {
+		if !strings.HasSuffix(q.Name, "_eu") {
+			t.Errorf("analyzer queue %q is not scoped to region", q.Name)
+		}
+	}
+	for _, q := range buildSpecGeneratorQueues(qw, config.FanOutConfig{Enabled: true, Workers: 4}, "") {
+		if strings.Contains(q.Name, "_eu") {
+			t.Errorf("default region queue %q should not be scoped", q.Name)
+		}
+	}
+}
+
+func TestBuildQueues_HostPools(t *testing.T) {
+	qw := config.QueueWorkers{Default: 2, Priority: 3, Scheduled: 1}
+	pools := []hostpool.Pool{{Host: "github.example.com", Name: "ghe", Workers: 4}, {Host: "git.example.org", Name: "lab"}}
+
+	workers := make(map[string]int)
+	for _, q := range buildAnalyzerQueues(qw, pools, "eu") {
+		workers[q.Name] = q.MaxWorkers
+	}
+	if len(workers) != 9 {
+		t.Errorf("expected the shared queues and three per pool, got %v", workers)
+	}
+	if workers["analysis_default_ghe_eu"] != 4 || workers["analysis_scheduled_ghe_eu"] != 4 {
+		t.Errorf("pool queues should use the pool's workers, got %v", workers)
+	}
+	if workers["analysis_priority_lab_eu"] != qw.Priority {
+		t.Errorf("pool without workers should use the tier's, got %v", workers)
+	}
+}
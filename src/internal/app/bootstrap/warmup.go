@@ -0,0 +1,71 @@
+package bootstrap
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/worker/internal/adapter/ai/prompt"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+// defaultWarmupTimeout bounds the whole warmup so a slow dependency delays
+// startup but never blocks it.
+const defaultWarmupTimeout = 30 * time.Second
+
+// warmupStep is one named warmup action.
+type warmupStep struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// runWarmup runs the steps in order before the queue server starts, so the
+// worker only takes jobs once connections are established.
+// Failures are non-critical: the first job simply pays the cold-start cost.
+func runWarmup(ctx context.Context, timeout time.Duration, steps []warmupStep) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	for _, step := range steps {
+		stepStarted := time.Now()
+		if err := step.run(ctx); err != nil {
+			slog.WarnContext(ctx, "warmup step failed (non-critical)",
+				"step", step.name,
+				"error", err,
+			)
+			continue
+		}
+		slog.InfoContext(ctx, "warmup step completed",
+			"step", step.name,
+			"duration_ms", time.Since(stepStarted).Milliseconds(),
+		)
+	}
+	slog.InfoContext(ctx, "warmup completed", "duration_ms", time.Since(started).Milliseconds())
+}
+
+// poolWarmupStep opens the pool's minimum connections.
+func poolWarmupStep(pool *pgxpool.Pool) warmupStep {
+	return warmupStep{
+		name: "db_pool",
+		run: func(ctx context.Context) error {
+			_, err := db.Prefill(ctx, pool)
+			return err
+		},
+	}
+}
+
+// specGeneratorWarmupSteps prefills the pool, checks the prompt templates and
+// pings the AI provider when it supports warmup (the mock provider does not).
+func specGeneratorWarmupSteps(pool *pgxpool.Pool, provider specview.AIProvider) []warmupStep {
+	steps := []warmupStep{
+		poolWarmupStep(pool),
+		{name: "prompt_templates", run: func(context.Context) error { return prompt.CheckTemplates() }},
+	}
+	if warmer, ok := provider.(specview.Warmer); ok {
+		steps = append(steps, warmupStep{name: "ai_provider", run: warmer.Warmup})
+	}
+	return steps
+}
@@ -0,0 +1,64 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/adapter/ai/mock"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestRunWarmup(t *testing.T) {
+	t.Run("continues after a failed step", func(t *testing.T) {
+		var ran []string
+		steps := []warmupStep{
+			{name: "first", run: func(context.Context) error { ran = append(ran, "first"); return errors.New("boom") }},
+			{name: "second", run: func(context.Context) error { ran = append(ran, "second"); return nil }},
+		}
+
+		runWarmup(context.Background(), time.Second, steps)
+
+		if len(ran) != 2 {
+			t.Errorf("ran = %v, want both steps", ran)
+		}
+	})
+
+	t.Run("bounds steps by the timeout", func(t *testing.T) {
+		steps := []warmupStep{
+			{name: "slow", run: func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }},
+		}
+
+		started := time.Now()
+		runWarmup(context.Background(), 10*time.Millisecond, steps)
+
+		if elapsed := time.Since(started); elapsed > time.Second {
+			t.Errorf("warmup took %v, expected timeout to stop it", elapsed)
+		}
+	})
+}
+
+type warmingProvider struct {
+	specview.AIProvider
+}
+
+func (warmingProvider) Warmup(context.Context) error { return nil }
+
+func TestSpecGeneratorWarmupSteps(t *testing.T) {
+	names := func(steps []warmupStep) []string {
+		var out []string
+		for _, s := range steps {
+			out = append(out, s.name)
+		}
+		return out
+	}
+
+	if got := names(specGeneratorWarmupSteps(nil, mock.NewProvider())); len(got) != 2 {
+		t.Errorf("mock provider steps = %v, want db_pool and prompt_templates", got)
+	}
+	got := names(specGeneratorWarmupSteps(nil, warmingProvider{}))
+	if len(got) != 3 || got[2] != "ai_provider" {
+		t.Errorf("steps = %v, want ai_provider last", got)
+	}
+}
@@ -3,25 +3,42 @@ package app
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/rivertype"
-	"github.com/specvital/core/pkg/crypto"
+	"github.com/specvital/worker/internal/adapter/mapping"
 	"github.com/specvital/worker/internal/adapter/parser"
 	"github.com/specvital/worker/internal/adapter/queue/analyze"
+	"github.com/specvital/worker/internal/adapter/queue/attribution"
+	coveragequeue "github.com/specvital/worker/internal/adapter/queue/coverage"
+	"github.com/specvital/worker/internal/adapter/queue/deadline"
 	"github.com/specvital/worker/internal/adapter/queue/fairness"
+	"github.com/specvital/worker/internal/adapter/queue/jobresult"
+	"github.com/specvital/worker/internal/adapter/queue/poison"
+	"github.com/specvital/worker/internal/adapter/queue/repopolicy"
+	"github.com/specvital/worker/internal/adapter/queue/residency"
+	"github.com/specvital/worker/internal/adapter/queue/retry"
+	testrunqueue "github.com/specvital/worker/internal/adapter/queue/testrun"
 	"github.com/specvital/worker/internal/adapter/repository/postgres"
 	"github.com/specvital/worker/internal/adapter/vcs"
 	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/metrics"
 	infraqueue "github.com/specvital/worker/internal/infra/queue"
+	"github.com/specvital/worker/internal/infra/tracing"
 	analysisuc "github.com/specvital/worker/internal/usecase/analysis"
+	coverageuc "github.com/specvital/worker/internal/usecase/coverage"
+	notificationuc "github.com/specvital/worker/internal/usecase/notification"
+	testrunuc "github.com/specvital/worker/internal/usecase/testrun"
 )
 
 // AnalyzerContainer holds dependencies for the analyzer worker service.
 type AnalyzerContainer struct {
 	AnalyzeWorker *analyze.AnalyzeWorker
 	Middleware    []rivertype.WorkerMiddleware
+	Notifications *notificationuc.Dispatcher // nil when notifications are disabled
 	QueueClient   *infraqueue.Client
+	RetryPolicy   *retry.Policy
 	Workers       *river.Workers
 }
 
@@ -31,7 +48,7 @@ func NewAnalyzerContainer(ctx context.Context, cfg ContainerConfig) (*AnalyzerCo
 		return nil, fmt.Errorf("invalid container config: %w", err)
 	}
 
-	encryptor, err := crypto.NewEncryptorFromBase64(cfg.EncryptionKey)
+	encryptor, err := NewTokenKeyring(ctx, cfg.EncryptionKey, cfg.TokenKeys)
 	if err != nil {
 		return nil, fmt.Errorf("create encryptor: %w", err)
 	}
@@ -40,26 +57,86 @@ func NewAnalyzerContainer(ctx context.Context, cfg ContainerConfig) (*AnalyzerCo
 	codebaseRepo := postgres.NewCodebaseRepository(cfg.Pool)
 	quotaRepo := postgres.NewQuotaReservationRepository(cfg.Pool)
 	userRepo := postgres.NewUserRepository(cfg.Pool, encryptor)
-	gitVCS := vcs.NewGitVCS()
-	githubAPIClient := vcs.NewGitHubAPIClient(nil)
-	coreParser := parser.NewCoreParser()
-	analyzeUC := analysisuc.NewAnalyzeUseCase(
-		analysisRepo, codebaseRepo, gitVCS, githubAPIClient, coreParser, userRepo,
+	workspace := vcs.NewWorkspaceManager(cfg.Workspace.QuotaBytes, vcs.WithCloneReserve(cfg.Workspace.CloneReserveBytes))
+	if removed, err := workspace.CleanupOrphans(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to clean up orphaned workspaces (non-critical)", "error", err)
+	} else if removed > 0 {
+		slog.InfoContext(ctx, "removed orphaned workspaces", "count", removed)
+	}
+	gitVCS := vcs.NewGitVCS(vcs.WithWorkspaceManager(workspace))
+	var enterpriseHosts []string
+	for _, p := range cfg.HostPools {
+		enterpriseHosts = append(enterpriseHosts, p.Host)
+	}
+	githubAPIClient := vcs.NewGitHubAPIClient(nil, enterpriseHosts...)
+	coreParser := parser.NewCoreParser(parser.WithWorkers(cfg.Streaming.ParseWorkers))
+	analyzeOpts := []analysisuc.Option{
 		analysisuc.WithParserVersion(cfg.ParserVersion),
 		analysisuc.WithBatchSize(cfg.Streaming.BatchSize),
-	)
+		analysisuc.WithUnmatchedSampling(cfg.Streaming.UnmatchedSamples, cfg.Streaming.UnmatchedSnippetLines),
+		analysisuc.WithMaxRepoSize(cfg.Workspace.MaxRepoSizeBytes),
+		analysisuc.WithTestVariantCollapse(cfg.MinTestVariants),
+		analysisuc.WithSharedHelpers(cfg.MinHelperRefs),
+		analysisuc.WithRegion(cfg.Region),
+		analysisuc.WithEnterpriseHosts(enterpriseHosts...),
+	}
+	for _, p := range cfg.HostPools {
+		analyzeOpts = append(analyzeOpts, analysisuc.WithHostCloneLimit(p.Host, p.MaxConcurrentClones))
+	}
+	if cfg.GitHubApp.AppID != 0 {
+		appTokens, err := vcs.NewGitHubAppTokenSource(cfg.GitHubApp.AppID, cfg.GitHubApp.PrivateKey, nil)
+		if err != nil {
+			return nil, fmt.Errorf("create github app token source: %w", err)
+		}
+		analyzeOpts = append(analyzeOpts, analysisuc.WithInstallationTokens(appTokens))
+	}
+	analyzeUC := analysisuc.NewAnalyzeUseCase(analysisRepo, codebaseRepo, gitVCS, githubAPIClient, coreParser, userRepo, analyzeOpts...)
 	analyzeWorker := analyze.NewAnalyzeWorker(analyzeUC, quotaRepo)
+	compareUC := analysisuc.NewCompareUseCase(analyzeUC, postgres.NewTestDeltaRepository(cfg.Pool))
+	compareWorker := analyze.NewCompareWorker(compareUC)
+	testRunWorker := testrunqueue.NewWorker(testrunuc.NewIngestTestResultsUseCase(postgres.NewTestResultRepository(cfg.Pool)))
+	coverageWorker := coveragequeue.NewWorker(coverageuc.NewIngestCoverageUseCase(postgres.NewCoverageRepository(cfg.Pool), mapping.CoverageLinker{}))
 
 	workers := river.NewWorkers()
 	river.AddWorker(workers, analyzeWorker)
+	river.AddWorker(workers, compareWorker)
+	river.AddWorker(workers, testRunWorker)
+	river.AddWorker(workers, coverageWorker)
 
-	queueClient, err := infraqueue.NewClient(ctx, cfg.Pool)
+	policyMiddleware := repopolicy.NewPolicyMiddleware(postgres.NewRepoPolicyRepository(cfg.Pool))
+	queueClient, err := infraqueue.NewClient(ctx, cfg.Pool,
+		infraqueue.WithHostPools(cfg.HostPools),
+		infraqueue.WithRegion(cfg.Region),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("create queue client: %w", err)
 	}
 
-	var middleware []rivertype.WorkerMiddleware
 	queries := db.New(cfg.Pool)
+	resultRecorder, notifications, err := NewJobResultRecorder(cfg.Pool, queries, cfg.Notifications)
+	if err != nil {
+		return nil, err
+	}
+	middleware := []rivertype.WorkerMiddleware{
+		tracing.NewJobMiddleware(),
+		metrics.NewJobMiddleware(),
+		deadline.NewMonitorMiddleware(cfg.SLATargets, deadline.NewDBRecorder(queries)),
+		attribution.NewAttributionMiddleware(cfg.Identity, attribution.NewDBMetadataRecorder(queries)),
+		// Outside the poison middleware, so quarantined jobs get a result too.
+		jobresult.NewResultMiddleware(resultRecorder),
+		// Before the middleware below, which parse args and retry on failure.
+		poison.NewPoisonMiddleware(poison.NewDBQuarantine(queries),
+			poison.RuleFor[analyze.AnalyzeArgs](),
+			poison.RuleFor[analyze.CompareArgs](),
+			poison.RuleFor[coveragequeue.Args](),
+			poison.RuleFor[testrunqueue.Args](),
+		),
+		residency.NewResidencyMiddleware(cfg.Region, residency.NewDBRegionResolver(queries)),
+		policyMiddleware,
+	}
+	if rl := NewRateLimitMiddleware(cfg.Fairness, queries); rl != nil {
+		middleware = append(middleware, rl)
+	}
 	tierResolver := fairness.NewDBTierResolver(queries)
 	fm, err := NewFairnessMiddleware(cfg.Fairness, tierResolver)
 	if err != nil {
@@ -68,17 +145,26 @@ func NewAnalyzerContainer(ctx context.Context, cfg ContainerConfig) (*AnalyzerCo
 	if fm != nil {
 		middleware = append(middleware, fm)
 	}
+	// Innermost, so it classifies the worker's own error.
+	retryPolicy := NewRetryPolicy(cfg.Retry)
+	middleware = append(middleware, retry.NewMiddleware(retryPolicy))
 
 	return &AnalyzerContainer{
 		AnalyzeWorker: analyzeWorker,
 		Middleware:    middleware,
+		Notifications: notifications,
 		QueueClient:   queueClient,
+		RetryPolicy:   retryPolicy,
 		Workers:       workers,
 	}, nil
 }
 
 // Close releases container resources.
+// Queued notifications are sent first, while the pool is still open.
 func (c *AnalyzerContainer) Close() error {
+	if c.Notifications != nil {
+		c.Notifications.Close()
+	}
 	if c.QueueClient != nil {
 		if err := c.QueueClient.Close(); err != nil {
 			return fmt.Errorf("close queue client: %w", err)
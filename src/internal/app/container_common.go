@@ -1,24 +1,63 @@
 package app
 
 import (
+	"cmp"
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/specvital/core/pkg/crypto"
+	"github.com/specvital/worker/internal/adapter/notify"
 	"github.com/specvital/worker/internal/adapter/queue/fairness"
+	"github.com/specvital/worker/internal/adapter/queue/jobnotify"
+	"github.com/specvital/worker/internal/adapter/queue/jobref"
+	"github.com/specvital/worker/internal/adapter/queue/jobresult"
+	"github.com/specvital/worker/internal/adapter/queue/retry"
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/domain/hostpool"
+	"github.com/specvital/worker/internal/domain/sla"
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/infra/buildinfo"
 	"github.com/specvital/worker/internal/infra/config"
+	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/tokenkeys"
+	notificationuc "github.com/specvital/worker/internal/usecase/notification"
 )
 
 // ContainerConfig holds common configuration for dependency injection containers.
 type ContainerConfig struct {
-	EncryptionKey     string
-	Fairness          config.FairnessConfig
-	GeminiAPIKey      string
-	GeminiPhase1Model string // optional: default gemini-2.5-flash
-	GeminiPhase2Model string // optional: default gemini-2.5-flash-lite
-	MockMode          bool   // enable mock AI provider for development/testing
-	ParserVersion     string
-	Pool              *pgxpool.Pool
-	Streaming         config.StreamingConfig
+	AI                 config.AIConfig                 // empty models fall back to the provider defaults
+	CacheKeyHash       config.CacheKeyHashConfig       // behavior cache key hash; empty uses SHA-256
+	CacheScope         specview.CacheScopeMode         // who shares behavior cache entries; empty is global
+	DedupSimilarity    float64                         // similarity at which duplicate behaviors are merged; zero uses the default
+	DocumentEncryption config.DocumentEncryptionConfig // per-tenant encryption of generated documents
+	DocumentSharing    bool                            // share documents of public, suitably licensed repositories across users
+	EncryptionKey      string
+	Fairness           config.FairnessConfig
+	FanOut             config.FanOutConfig    // Phase 2 fan-out across per-domain child jobs
+	GeneratorMode      specview.GeneratorMode // when spec documents are generated heuristically instead of by the AI
+	GitHubApp          config.GitHubAppConfig // app whose installation tokens clone private repositories; no app ID disables them
+	HostPools          []hostpool.Pool        // hosts analyzed with their own clone limit
+	Identity           buildinfo.Identity     // worker identity recorded on processed jobs
+	InFlightWait       time.Duration          // how long a spec job waits for another generating the same content; zero disables
+	MinHelperRefs      int                    // test files importing a helper for it to be recorded as shared
+	MinTestVariants    int                    // smallest group of parameter variants collapsed into one test
+	MockMode           bool                   // enable mock AI provider for development/testing
+	Notifications      config.NotificationsConfig
+	ParserVersion      string
+	PromptExperiment   config.PromptExperimentConfig // share of jobs generated with a candidate prompt version
+	Pool               *pgxpool.Pool
+	Quality            config.QualityConfig       // spec document quality thresholds and low-confidence retries
+	QuotaWarnings      []int                      // monthly quota shares, in percent, that trigger usage warnings
+	Region             string                     // data-residency region of this worker
+	Retry              config.RetryConfig         // retry schedules of failed jobs per error class
+	SemanticCache      config.SemanticCacheConfig // reuse of cached behaviors of near-identical tests
+	SLATargets         sla.Targets                // target job durations by kind; jobs of other kinds are not monitored
+	Streaming          config.StreamingConfig
+	Takeout            config.TakeoutConfig   // tenant takeout bucket; no bucket disables takeouts
+	TokenKeys          config.TokenKeysConfig // versioned OAuth token keys next to EncryptionKey, the legacy key
+	Workspace          config.WorkspaceConfig
 }
 
 // Validate checks that required common configuration fields are set.
@@ -34,7 +73,7 @@ func (c ContainerConfig) ValidateAnalyzer() error {
 	if err := c.Validate(); err != nil {
 		return err
 	}
-	if c.EncryptionKey == "" {
+	if c.EncryptionKey == "" && !c.TokenKeys.Configured() {
 		return fmt.Errorf("encryption key is required")
 	}
 	if c.ParserVersion == "" {
@@ -48,13 +87,92 @@ func (c ContainerConfig) ValidateSpecGenerator() error {
 	if err := c.Validate(); err != nil {
 		return err
 	}
-	// Skip GeminiAPIKey validation when MockMode is enabled
-	if !c.MockMode && c.GeminiAPIKey == "" {
-		return fmt.Errorf("gemini API key is required (set MOCK_MODE=true to skip)")
+	// Skip AI API key validation when MockMode is enabled
+	if !c.MockMode && c.AI.APIKey == "" {
+		return fmt.Errorf("AI API key is required (set MOCK_MODE=true to skip)")
 	}
 	return nil
 }
 
+// NewTokenKeyring creates the keyring opening stored OAuth tokens with the
+// legacy key and the versioned keys of cfg, loaded from their source.
+//
+// Returns error if no key is configured or a key is invalid.
+func NewTokenKeyring(ctx context.Context, legacyKey string, cfg config.TokenKeysConfig) (*tokenkeys.Keyring, error) {
+	var legacy crypto.Encryptor
+	if legacyKey != "" {
+		enc, err := crypto.NewEncryptorFromBase64(legacyKey)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key: %w", err)
+		}
+		legacy = enc
+	}
+
+	var keys []tokenkeys.Key
+	if src := tokenKeySource(cfg); src != nil {
+		loaded, err := tokenkeys.Load(ctx, src)
+		if err != nil {
+			if legacy != nil {
+				legacy.Close()
+			}
+			return nil, fmt.Errorf("load token keys: %w", err)
+		}
+		keys = loaded
+	}
+
+	keyring, err := tokenkeys.New(legacy, keys)
+	if err != nil {
+		if legacy != nil {
+			legacy.Close()
+		}
+		for _, k := range keys {
+			k.Encryptor.Close()
+		}
+		return nil, err
+	}
+	return keyring, nil
+}
+
+func tokenKeySource(cfg config.TokenKeysConfig) tokenkeys.Source {
+	switch {
+	case cfg.VaultPath != "":
+		return tokenkeys.VaultSource{Addr: cfg.VaultAddr, Field: cfg.VaultField, Path: cfg.VaultPath, Token: cfg.VaultToken}
+	case cfg.File != "":
+		return tokenkeys.FileSource(cfg.File)
+	case cfg.Keys != "":
+		return tokenkeys.StaticSource(cfg.Keys)
+	default:
+		return nil
+	}
+}
+
+// NewDocumentKeyring creates the keyring encrypting the documents of tenants
+// that turned on document encryption.
+//
+// Returns nil if no master key is configured (DOCUMENT_ENCRYPTION_KEY unset).
+// Returns error if a key is not a valid base64-encoded 32-byte key.
+func NewDocumentKeyring(pool *pgxpool.Pool, cfg config.DocumentEncryptionConfig) (*postgres.DocumentKeyring, error) {
+	if cfg.MasterKey == "" {
+		return nil, nil
+	}
+
+	master, err := crypto.NewEncryptorFromBase64(cfg.MasterKey)
+	if err != nil {
+		return nil, fmt.Errorf("document encryption key: %w", err)
+	}
+
+	var opts []postgres.DocumentKeyringOption
+	if cfg.PreviousMasterKey != "" {
+		previous, err := crypto.NewEncryptorFromBase64(cfg.PreviousMasterKey)
+		if err != nil {
+			master.Close()
+			return nil, fmt.Errorf("previous document encryption key: %w", err)
+		}
+		opts = append(opts, postgres.WithPreviousMasterKey(previous))
+	}
+	return postgres.NewDocumentKeyring(pool, master, opts...), nil
+}
+
 // NewFairnessMiddleware creates a new fairness middleware from configuration.
 //
 // Why factory pattern: Each container (analyzer/spec-generator) needs independent
@@ -84,3 +202,66 @@ func NewFairnessMiddleware(cfg config.FairnessConfig, tierResolver fairness.Tier
 
 	return fairness.NewFairnessMiddleware(limiter, extractor, tierResolver, fairnessConfig), nil
 }
+
+// NewJobResultRecorder creates the recorder of job results. With
+// notifications enabled it also hands finished analysis and spec document
+// jobs to a dispatcher, which the container closes on shutdown.
+//
+// Returns a nil dispatcher if notifications are disabled (NOTIFICATIONS_ENABLED=false).
+func NewJobResultRecorder(pool *pgxpool.Pool, queries *db.Queries, cfg config.NotificationsConfig) (jobresult.Recorder, *notificationuc.Dispatcher, error) {
+	recorder := jobresult.NewDBRecorder(queries)
+	if !cfg.Enabled {
+		return recorder, nil, nil
+	}
+
+	sendTimeout := cmp.Or(cfg.SendTimeout, notificationuc.DefaultSendTimeout)
+	sender, err := notify.NewHTTPSender(notify.NewHTTPClient(sendTimeout))
+	if err != nil {
+		return nil, nil, fmt.Errorf("create notification sender: %w", err)
+	}
+	dispatcher := notificationuc.NewDispatcher(postgres.NewNotificationRepository(pool), sender,
+		notificationuc.WithQueueSize(cfg.QueueSize),
+		notificationuc.WithSendTimeout(sendTimeout),
+		notificationuc.WithWorkers(cfg.Workers),
+	)
+	return jobnotify.NewRecorder(recorder, dispatcher), dispatcher, nil
+}
+
+// NewRetryPolicy creates the retry policy shared by every job kind from the
+// per-class schedules of cfg. Unset values use the retry package defaults.
+func NewRetryPolicy(cfg config.RetryConfig) *retry.Policy {
+	schedule := func(s config.RetrySchedule) retry.Schedule {
+		return retry.Schedule{
+			InitialBackoff: s.InitialBackoff,
+			JitterFactor:   cfg.JitterFactor,
+			MaxAttempts:    s.MaxAttempts,
+			MaxBackoff:     s.MaxBackoff,
+		}
+	}
+	return retry.NewPolicy(map[retry.Class]retry.Schedule{
+		retry.ClassDefault:   schedule(cfg.Default),
+		retry.ClassRateLimit: schedule(cfg.RateLimit),
+		retry.ClassTransient: schedule(cfg.Transient),
+	})
+}
+
+// NewRateLimitMiddleware creates a per-user and per-codebase rate limiting
+// middleware backed by Postgres token buckets, so limits survive restarts.
+//
+// Returns nil if fairness or rate limiting is disabled.
+func NewRateLimitMiddleware(cfg config.FairnessConfig, queries *db.Queries) *fairness.RateLimitMiddleware {
+	if !cfg.Enabled || !cfg.RateLimitEnabled {
+		return nil
+	}
+
+	return fairness.NewRateLimitMiddleware(
+		fairness.NewDBBucketStore(queries),
+		&fairness.JSONArgsExtractor{},
+		jobref.NewDBResolver(queries),
+		&fairness.RateLimitConfig{
+			Codebase:     fairness.RateLimit{Burst: cfg.CodebaseBurst, PerMinute: cfg.CodebaseRatePerMinute},
+			SnoozeJitter: cfg.SnoozeJitter,
+			User:         fairness.RateLimit{Burst: cfg.UserBurst, PerMinute: cfg.UserRatePerMinute},
+		},
+	)
+}
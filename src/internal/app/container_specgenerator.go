@@ -4,27 +4,52 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/rivertype"
 	"github.com/specvital/worker/internal/adapter/ai/gemini"
-	"github.com/specvital/worker/internal/adapter/ai/mock"
+	"github.com/specvital/worker/internal/adapter/ai/heuristic"
+	"github.com/specvital/worker/internal/adapter/ai/prompt"
+	"github.com/specvital/worker/internal/adapter/ai/registry"
+	"github.com/specvital/worker/internal/adapter/matcher"
+	"github.com/specvital/worker/internal/adapter/queue/attribution"
+	"github.com/specvital/worker/internal/adapter/queue/deadline"
 	"github.com/specvital/worker/internal/adapter/queue/fairness"
+	"github.com/specvital/worker/internal/adapter/queue/jobresult"
+	"github.com/specvital/worker/internal/adapter/queue/poison"
+	requirementqueue "github.com/specvital/worker/internal/adapter/queue/requirement"
+	"github.com/specvital/worker/internal/adapter/queue/residency"
+	"github.com/specvital/worker/internal/adapter/queue/retry"
 	specviewqueue "github.com/specvital/worker/internal/adapter/queue/specview"
+	takeoutqueue "github.com/specvital/worker/internal/adapter/queue/takeout"
 	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/adapter/storage"
+	"github.com/specvital/worker/internal/adapter/vcs"
+	"github.com/specvital/worker/internal/domain/requirement"
 	"github.com/specvital/worker/internal/domain/specview"
 	"github.com/specvital/worker/internal/infra/db"
+	"github.com/specvital/worker/internal/infra/metrics"
 	infraqueue "github.com/specvital/worker/internal/infra/queue"
+	"github.com/specvital/worker/internal/infra/tracing"
+	notificationuc "github.com/specvital/worker/internal/usecase/notification"
+	requirementuc "github.com/specvital/worker/internal/usecase/requirement"
 	specviewuc "github.com/specvital/worker/internal/usecase/specview"
+	takeoutuc "github.com/specvital/worker/internal/usecase/takeout"
 )
 
 // SpecGeneratorContainer holds dependencies for the spec-generator worker service.
 type SpecGeneratorContainer struct {
-	AIProvider     specview.AIProvider
-	Middleware     []rivertype.WorkerMiddleware
-	QueueClient    *infraqueue.Client
-	SpecViewWorker *specviewqueue.Worker
-	Workers        *river.Workers
+	AIProvider      specview.AIProvider
+	DocumentKeyring *postgres.DocumentKeyring // nil when document encryption is not configured
+	GapsWorker      *specviewqueue.GapsWorker
+	IndexWorker     *specviewqueue.IndexWorker
+	Middleware      []rivertype.WorkerMiddleware
+	Notifications   *notificationuc.Dispatcher // nil when notifications are disabled
+	QueueClient     *infraqueue.Client
+	RetryPolicy     *retry.Policy
+	SpecViewWorker  *specviewqueue.Worker
+	Workers         *river.Workers
 }
 
 // NewSpecGeneratorContainer creates and initializes a new spec-generator container with all required dependencies.
@@ -33,48 +58,180 @@ func NewSpecGeneratorContainer(ctx context.Context, cfg ContainerConfig) (*SpecG
 		return nil, fmt.Errorf("invalid container config: %w", err)
 	}
 
-	var aiProvider specview.AIProvider
-	var defaultModelID string
-
+	providerName := cfg.AI.Provider
 	if cfg.MockMode {
 		slog.Info("mock mode enabled, using mock AI provider")
-		aiProvider = mock.NewProvider()
-		defaultModelID = "mock-model"
-	} else {
-		geminiProvider, err := gemini.NewProvider(ctx, gemini.Config{
-			APIKey:      cfg.GeminiAPIKey,
-			Phase1Model: cfg.GeminiPhase1Model,
-			Phase2Model: cfg.GeminiPhase2Model,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("create gemini provider: %w", err)
-		}
-		aiProvider = geminiProvider
-		defaultModelID = cfg.GeminiPhase1Model
-		if defaultModelID == "" {
-			defaultModelID = "gemini-2.5-flash"
-		}
+		providerName = registry.ProviderMock
+	}
+
+	aiProvider, defaultModelID, err := registry.Default().Create(ctx, providerName, registry.Config{
+		APIKey:      cfg.AI.APIKey,
+		APIVersion:  cfg.AI.APIVersion,
+		BaseURL:     cfg.AI.BaseURL,
+		Phase1Model: cfg.AI.Phase1Model,
+		Phase2Model: cfg.AI.Phase2Model,
+		Quarantine:  postgres.NewAIOutputQuarantineRepository(cfg.Pool),
+
+		Phase1Fallbacks: cfg.AI.Phase1Fallbacks,
+		Phase2Fallbacks: cfg.AI.Phase2Fallbacks,
+
+		ResponseCacheMaxEntries: cfg.AI.ResponseCacheMaxEntries,
+		ResponseCacheTTL:        cfg.AI.ResponseCacheTTL,
+
+		BatchMinTests: cfg.AI.BatchMinTests,
+		BatchMaxWait:  cfg.AI.BatchMaxWait,
+
+		BatchFallbackAPIKey: cfg.AI.BatchFallbackAPIKey,
+		BatchFallbackModel:  cfg.AI.BatchFallbackModel,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create AI provider: %w", err)
 	}
+	slog.Info("AI provider configured", "provider", providerName, "model", defaultModelID)
 
-	specDocRepo := postgres.NewSpecDocumentRepository(cfg.Pool)
+	keyring, err := NewDocumentKeyring(cfg.Pool, cfg.DocumentEncryption)
+	if err != nil {
+		return nil, fmt.Errorf("create document keyring: %w", err)
+	}
+	if keyring != nil {
+		slog.Info("document encryption configured")
+	}
+
+	specDocRepo := postgres.NewSpecDocumentRepository(cfg.Pool, postgres.WithDocumentKeyring(keyring))
 	quotaRepo := postgres.NewQuotaReservationRepository(cfg.Pool)
+	queries := db.New(cfg.Pool)
+	if candidate := cfg.PromptExperiment.Candidate; candidate != "" && !prompt.HasVersion(candidate) {
+		return nil, fmt.Errorf("unknown candidate prompt version %q (known: %s)", candidate, strings.Join(prompt.Versions(), ", "))
+	}
+
+	specViewOpts := []specviewuc.Option{
+		specviewuc.WithAllowedModels(cfg.AI.AllowedModels),
+		specviewuc.WithBehaviorDedup(cfg.DedupSimilarity),
+		specviewuc.WithCacheKeyHash(cfg.CacheKeyHash.Algorithm, cfg.CacheKeyHash.Legacy),
+		specviewuc.WithCacheScope(cfg.CacheScope),
+		specviewuc.WithDryRun(heuristic.NewProvider()),
+		specviewuc.WithHeuristic(heuristic.NewProvider(), cfg.GeneratorMode),
+		specviewuc.WithInFlightDedup(cfg.InFlightWait),
+		specviewuc.WithPromptExperiment(cfg.PromptExperiment.Candidate, cfg.PromptExperiment.Percent),
+		specviewuc.WithQualityScoring(cfg.Quality.LowConfidence, cfg.Quality.FlagBelow, cfg.Quality.Refine),
+		specviewuc.WithQuotaWarnings(postgres.NewUsageWarningRepository(cfg.Pool), cfg.QuotaWarnings),
+	}
+	if cfg.FanOut.Enabled {
+		specViewOpts = append(specViewOpts,
+			specviewuc.WithPhase2FanOut(specviewqueue.NewFanOut(queries, cfg.Region), cfg.FanOut.MinDomains),
+		)
+	}
+	if cfg.DocumentSharing {
+		specViewOpts = append(specViewOpts, specviewuc.WithDocumentSharing(vcs.NewGitHubAPIClient(nil)))
+	}
+	// Requirement matching shares the embedder and falls back to token
+	// overlap without it.
+	var requirementMatcher requirement.Matcher = matcher.NewLexicalMatcher()
+	if cfg.SemanticCache.Enabled && !cfg.MockMode {
+		embedder, err := gemini.NewEmbedder(ctx, cfg.SemanticCache.APIKey, cfg.SemanticCache.Model)
+		if err != nil {
+			return nil, fmt.Errorf("create embedder: %w", err)
+		}
+		specViewOpts = append(specViewOpts, specviewuc.WithSemanticCache(embedder, cfg.SemanticCache.Similarity))
+		requirementMatcher = matcher.NewEmbeddingMatcher(embedder)
+		slog.Info("semantic behavior cache configured")
+	}
 	specViewUC := specviewuc.NewGenerateSpecViewUseCase(
 		specDocRepo,
 		aiProvider,
 		defaultModelID,
+		specViewOpts...,
 	)
-	specViewWorker := specviewqueue.NewWorker(specViewUC, quotaRepo)
+	specViewWorker := specviewqueue.NewWorker(specViewUC, quotaRepo, specviewqueue.WithRegion(cfg.Region))
+	domainWorker := specviewqueue.NewDomainWorker(specViewUC)
+
+	searchRepo := postgres.NewSpecSearchRepository(cfg.Pool)
+	indexUC := specviewuc.NewIndexSpecDocumentUseCase(searchRepo, searchRepo)
+	indexWorker := specviewqueue.NewIndexWorker(indexUC)
+
+	gapRepo := postgres.NewSpecGapRepository(cfg.Pool)
+	gapsUC := specviewuc.NewAnalyzeSpecGapsUseCase(gapRepo)
+	gapsWorker := specviewqueue.NewGapsWorker(gapsUC)
+
+	exportRepo := postgres.NewSpecExportRepository(cfg.Pool, postgres.WithDocumentKeyring(keyring))
+	exportUC := specviewuc.NewExportSpecDocumentUseCase(exportRepo)
+	exportWorker := specviewqueue.NewExportWorker(exportUC)
+
+	reclassifyUC := specviewuc.NewReclassifyUncategorizedUseCase(specDocRepo, aiProvider)
+	reclassifyWorker := specviewqueue.NewReclassifyWorker(reclassifyUC, cfg.Region)
+
+	feedbackUC := specviewuc.NewApplyBehaviorFeedbackUseCase(postgres.NewBehaviorFeedbackRepository(cfg.Pool))
+	feedbackWorker := specviewqueue.NewFeedbackWorker(feedbackUC)
+
+	requirementRepo := postgres.NewRequirementRepository(cfg.Pool)
+	matchUC := requirementuc.NewMatchRequirementsUseCase(requirementRepo, searchRepo, requirementMatcher)
+	requirementWorker := requirementqueue.NewWorker(matchUC)
 
 	workers := river.NewWorkers()
 	river.AddWorker(workers, specViewWorker)
+	river.AddWorker(workers, domainWorker)
+	river.AddWorker(workers, indexWorker)
+	river.AddWorker(workers, gapsWorker)
+	river.AddWorker(workers, exportWorker)
+	river.AddWorker(workers, reclassifyWorker)
+	river.AddWorker(workers, feedbackWorker)
+	river.AddWorker(workers, requirementWorker)
+
+	if cfg.Takeout.Bucket != "" {
+		store, err := storage.NewS3Store(storage.S3Config{
+			AccessKeyID:     cfg.Takeout.AccessKeyID,
+			Bucket:          cfg.Takeout.Bucket,
+			Endpoint:        cfg.Takeout.Endpoint,
+			PathStyle:       cfg.Takeout.PathStyle,
+			Region:          cfg.Takeout.Region,
+			SecretAccessKey: cfg.Takeout.SecretAccessKey,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("create takeout store: %w", err)
+		}
+		takeoutUC := takeoutuc.NewExportTenantUseCase(
+			postgres.NewTakeoutRepository(cfg.Pool),
+			exportRepo,
+			store,
+			takeoutuc.WithURLTTL(cfg.Takeout.URLTTL),
+		)
+		river.AddWorker(workers, takeoutqueue.NewWorker(takeoutUC))
+		slog.Info("tenant takeout configured", "bucket", cfg.Takeout.Bucket)
+	}
 
-	queueClient, err := infraqueue.NewClient(ctx, cfg.Pool)
+	queueClient, err := infraqueue.NewClient(ctx, cfg.Pool, infraqueue.WithRegion(cfg.Region))
 	if err != nil {
 		return nil, fmt.Errorf("create queue client: %w", err)
 	}
 
-	var middleware []rivertype.WorkerMiddleware
-	queries := db.New(cfg.Pool)
+	resultRecorder, notifications, err := NewJobResultRecorder(cfg.Pool, queries, cfg.Notifications)
+	if err != nil {
+		return nil, err
+	}
+	middleware := []rivertype.WorkerMiddleware{
+		tracing.NewJobMiddleware(),
+		metrics.NewJobMiddleware(),
+		deadline.NewMonitorMiddleware(cfg.SLATargets, deadline.NewDBRecorder(queries)),
+		attribution.NewAttributionMiddleware(cfg.Identity, attribution.NewDBMetadataRecorder(queries)),
+		// Outside the poison middleware, so quarantined jobs get a result too.
+		jobresult.NewResultMiddleware(resultRecorder),
+		// Before the middleware below, which parse args and retry on failure.
+		poison.NewPoisonMiddleware(poison.NewDBQuarantine(queries),
+			poison.RuleFor[specviewqueue.Args](),
+			poison.RuleFor[specviewqueue.DomainArgs](),
+			poison.RuleFor[specviewqueue.ExportArgs](),
+			poison.RuleFor[specviewqueue.FeedbackArgs](),
+			poison.RuleFor[specviewqueue.GapsArgs](),
+			poison.RuleFor[specviewqueue.IndexArgs](),
+			poison.RuleFor[specviewqueue.ReclassifyArgs](),
+			poison.RuleFor[requirementqueue.Args](),
+			poison.RuleFor[takeoutqueue.Args](),
+		),
+		residency.NewResidencyMiddleware(cfg.Region, residency.NewDBRegionResolver(queries)),
+	}
+	if rl := NewRateLimitMiddleware(cfg.Fairness, queries); rl != nil {
+		middleware = append(middleware, rl)
+	}
 	tierResolver := fairness.NewDBTierResolver(queries)
 	fm, err := NewFairnessMiddleware(cfg.Fairness, tierResolver)
 	if err != nil {
@@ -83,20 +240,33 @@ func NewSpecGeneratorContainer(ctx context.Context, cfg ContainerConfig) (*SpecG
 	if fm != nil {
 		middleware = append(middleware, fm)
 	}
+	// Innermost, so it classifies the worker's own error.
+	retryPolicy := NewRetryPolicy(cfg.Retry)
+	middleware = append(middleware, retry.NewMiddleware(retryPolicy))
 
 	return &SpecGeneratorContainer{
-		AIProvider:     aiProvider,
-		Middleware:     middleware,
-		QueueClient:    queueClient,
-		SpecViewWorker: specViewWorker,
-		Workers:        workers,
+		AIProvider:      aiProvider,
+		DocumentKeyring: keyring,
+		GapsWorker:      gapsWorker,
+		IndexWorker:     indexWorker,
+		Middleware:      middleware,
+		Notifications:   notifications,
+		QueueClient:     queueClient,
+		RetryPolicy:     retryPolicy,
+		SpecViewWorker:  specViewWorker,
+		Workers:         workers,
 	}, nil
 }
 
 // Close releases container resources.
+// Queued notifications are sent first, while the pool is still open.
 func (c *SpecGeneratorContainer) Close() error {
 	var errs []error
 
+	if c.Notifications != nil {
+		c.Notifications.Close()
+	}
+
 	if c.QueueClient != nil {
 		if err := c.QueueClient.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("close queue client: %w", err))
@@ -109,6 +279,12 @@ func (c *SpecGeneratorContainer) Close() error {
 		}
 	}
 
+	if c.DocumentKeyring != nil {
+		if err := c.DocumentKeyring.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close document keyring: %w", err))
+		}
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("close spec-generator container: %v", errs)
 	}
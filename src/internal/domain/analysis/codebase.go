@@ -9,13 +9,15 @@ import (
 var ErrCodebaseNotFound = errors.New("codebase not found")
 
 type Codebase struct {
-	ExternalRepoID string
-	Host           string
-	ID             UUID
-	IsStale        bool
-	LastCommitSHA  string
-	Name           string
-	Owner          string
+	ExternalRepoID   string
+	ForkDedupEnabled bool // false when the codebase opted out of fork deduplication
+	Host             string
+	ID               UUID
+	IsStale          bool
+	LastCommitSHA    string
+	Name             string
+	Owner            string
+	Region           string // empty for the default region
 }
 
 type UpsertCodebaseParams struct {
@@ -25,6 +27,7 @@ type UpsertCodebaseParams struct {
 	IsPrivate      bool
 	Name           string
 	Owner          string
+	Region         string // applied only when the codebase is created
 }
 
 func (p UpsertCodebaseParams) Validate() error {
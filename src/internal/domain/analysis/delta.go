@@ -0,0 +1,245 @@
+package analysis
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// TestDeltaRepository loads stored inventories for comparison and records the
+// resulting test deltas.
+type TestDeltaRepository interface {
+	// FindCompletedAnalysis returns NilUUID without error when the commit has no
+	// completed analysis.
+	FindCompletedAnalysis(ctx context.Context, owner, repo, commitSHA string) (UUID, error)
+	GetTestRefs(ctx context.Context, analysisID UUID) ([]TestRef, error)
+	SaveTestDelta(ctx context.Context, params SaveTestDeltaParams) error
+}
+
+// CompareRequest asks for the test delta between a pull request's base commit,
+// which must already be analyzed, and its head commit, analyzed on demand.
+type CompareRequest struct {
+	BaseCommitSHA string
+	HeadBranch    string // branch whose HEAD is the head commit, empty for the default branch
+	HeadCommitSHA string
+	JobID         int64 // optional: queue job ID, used to correlate progress events
+	Owner         string
+	PullRequest   int // optional: pull request number, 0 when unknown
+	Repo          string
+	UserID        *string
+}
+
+func (r CompareRequest) Validate() error {
+	if r.BaseCommitSHA == "" {
+		return fmt.Errorf("%w: base commit SHA is required", ErrInvalidInput)
+	}
+	if r.PullRequest < 0 {
+		return fmt.Errorf("%w: pull request number cannot be negative", ErrInvalidInput)
+	}
+	return r.HeadAnalyzeRequest().Validate()
+}
+
+// HeadAnalyzeRequest is the analysis request for the head commit.
+func (r CompareRequest) HeadAnalyzeRequest() AnalyzeRequest {
+	return AnalyzeRequest{
+		Branch:    r.HeadBranch,
+		CommitSHA: r.HeadCommitSHA,
+		JobID:     r.JobID,
+		Owner:     r.Owner,
+		Repo:      r.Repo,
+		UserID:    r.UserID,
+	}
+}
+
+type SaveTestDeltaParams struct {
+	BaseAnalysisID UUID
+	Delta          TestDelta
+	HeadAnalysisID UUID
+	PullRequest    int
+}
+
+func (p SaveTestDeltaParams) Validate() error {
+	if p.BaseAnalysisID == NilUUID || p.HeadAnalysisID == NilUUID {
+		return fmt.Errorf("%w: base and head analysis IDs are required", ErrInvalidInput)
+	}
+	return nil
+}
+
+// TestRef identifies a test by its file, suite path ("Outer > Inner") and name.
+type TestRef struct {
+	FilePath  string `json:"filePath"`
+	Name      string `json:"name"`
+	SuitePath string `json:"suitePath"`
+}
+
+// SuiteRef identifies a suite by its file and path.
+type SuiteRef struct {
+	FilePath string `json:"filePath"`
+	Path     string `json:"path"`
+}
+
+type TestRename struct {
+	From TestRef `json:"from"`
+	To   TestRef `json:"to"`
+}
+
+// TestDelta is the difference between two inventories. Renamed tests appear
+// only in RenamedTests, not as an addition plus a removal.
+type TestDelta struct {
+	AddedSuites   []SuiteRef   `json:"addedSuites"`
+	AddedTests    []TestRef    `json:"addedTests"`
+	RemovedSuites []SuiteRef   `json:"removedSuites"`
+	RemovedTests  []TestRef    `json:"removedTests"`
+	RenamedTests  []TestRename `json:"renamedTests"`
+}
+
+// IsEmpty reports whether the inventories held the same tests and suites.
+func (d TestDelta) IsEmpty() bool {
+	return len(d.AddedSuites) == 0 && len(d.AddedTests) == 0 &&
+		len(d.RemovedSuites) == 0 && len(d.RemovedTests) == 0 && len(d.RenamedTests) == 0
+}
+
+// DiffInventories compares the base and head tests. Duplicate refs (e.g. tests
+// sharing a name in one suite) are compared by count. A removed and an added
+// test count as a rename when the test moved to another file under the same
+// suite path and name, or when they are the only change left in their suite.
+func DiffInventories(base, head []TestRef) TestDelta {
+	counts := make(map[TestRef]int, len(base))
+	for _, ref := range base {
+		counts[ref]++
+	}
+	for _, ref := range head {
+		counts[ref]--
+	}
+
+	var removed, added []TestRef
+	for _, ref := range base {
+		if counts[ref] > 0 {
+			removed = append(removed, ref)
+			counts[ref]--
+		}
+	}
+	for _, ref := range head {
+		if counts[ref] < 0 {
+			added = append(added, ref)
+			counts[ref]++
+		}
+	}
+	slices.SortFunc(removed, compareTestRefs)
+	slices.SortFunc(added, compareTestRefs)
+
+	var delta TestDelta
+	removed, added, delta.RenamedTests = pairMovedTests(removed, added)
+	var renamed []TestRename
+	removed, added, renamed = pairRenamedTests(removed, added)
+	delta.RenamedTests = append(delta.RenamedTests, renamed...)
+	slices.SortFunc(delta.RenamedTests, func(a, b TestRename) int {
+		return compareTestRefs(a.From, b.From)
+	})
+	delta.AddedTests = added
+	delta.RemovedTests = removed
+
+	baseSuites, headSuites := suitesOf(base), suitesOf(head)
+	delta.AddedSuites = suiteDifference(headSuites, baseSuites)
+	delta.RemovedSuites = suiteDifference(baseSuites, headSuites)
+	return delta
+}
+
+// pairMovedTests matches removals and additions with the same suite path and
+// name in different files.
+func pairMovedTests(removed, added []TestRef) ([]TestRef, []TestRef, []TestRename) {
+	type nameKey struct{ suitePath, name string }
+	addedByName := make(map[nameKey][]int)
+	for i, ref := range added {
+		key := nameKey{ref.SuitePath, ref.Name}
+		addedByName[key] = append(addedByName[key], i)
+	}
+
+	var renames []TestRename
+	usedAdded := make([]bool, len(added))
+	var keptRemoved []TestRef
+	for _, ref := range removed {
+		key := nameKey{ref.SuitePath, ref.Name}
+		candidates := addedByName[key]
+		if len(candidates) == 0 {
+			keptRemoved = append(keptRemoved, ref)
+			continue
+		}
+		i := candidates[0]
+		addedByName[key] = candidates[1:]
+		usedAdded[i] = true
+		renames = append(renames, TestRename{From: ref, To: added[i]})
+	}
+	return keptRemoved, unused(added, usedAdded), renames
+}
+
+// pairRenamedTests matches the single removal and single addition left in a suite.
+func pairRenamedTests(removed, added []TestRef) ([]TestRef, []TestRef, []TestRename) {
+	removedBySuite := make(map[SuiteRef][]int)
+	for i, ref := range removed {
+		removedBySuite[suiteOf(ref)] = append(removedBySuite[suiteOf(ref)], i)
+	}
+	addedBySuite := make(map[SuiteRef][]int)
+	for i, ref := range added {
+		addedBySuite[suiteOf(ref)] = append(addedBySuite[suiteOf(ref)], i)
+	}
+
+	var renames []TestRename
+	usedRemoved := make([]bool, len(removed))
+	usedAdded := make([]bool, len(added))
+	for suite, r := range removedBySuite {
+		a := addedBySuite[suite]
+		if len(r) != 1 || len(a) != 1 {
+			continue
+		}
+		usedRemoved[r[0]], usedAdded[a[0]] = true, true
+		renames = append(renames, TestRename{From: removed[r[0]], To: added[a[0]]})
+	}
+	return unused(removed, usedRemoved), unused(added, usedAdded), renames
+}
+
+func unused(refs []TestRef, used []bool) []TestRef {
+	var result []TestRef
+	for i, ref := range refs {
+		if !used[i] {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
+func suiteOf(ref TestRef) SuiteRef {
+	return SuiteRef{FilePath: ref.FilePath, Path: ref.SuitePath}
+}
+
+func suitesOf(refs []TestRef) map[SuiteRef]struct{} {
+	suites := make(map[SuiteRef]struct{})
+	for _, ref := range refs {
+		if ref.SuitePath != "" {
+			suites[suiteOf(ref)] = struct{}{}
+		}
+	}
+	return suites
+}
+
+func suiteDifference(a, b map[SuiteRef]struct{}) []SuiteRef {
+	var result []SuiteRef
+	for suite := range a {
+		if _, ok := b[suite]; !ok {
+			result = append(result, suite)
+		}
+	}
+	slices.SortFunc(result, func(x, y SuiteRef) int {
+		return cmp.Or(cmp.Compare(x.FilePath, y.FilePath), cmp.Compare(x.Path, y.Path))
+	})
+	return result
+}
+
+func compareTestRefs(a, b TestRef) int {
+	return cmp.Or(
+		cmp.Compare(a.FilePath, b.FilePath),
+		cmp.Compare(a.SuitePath, b.SuitePath),
+		cmp.Compare(a.Name, b.Name),
+	)
+}
@@ -0,0 +1,140 @@
+package analysis
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func ref(file, suite, name string) TestRef {
+	return TestRef{FilePath: file, Name: name, SuitePath: suite}
+}
+
+func TestDiffInventories(t *testing.T) {
+	t.Run("identical inventories", func(t *testing.T) {
+		refs := []TestRef{ref("a_test.go", "A", "works"), ref("a_test.go", "A", "fails")}
+		if delta := DiffInventories(refs, refs); !delta.IsEmpty() {
+			t.Errorf("expected empty delta, got %+v", delta)
+		}
+	})
+
+	t.Run("added and removed tests and suites", func(t *testing.T) {
+		base := []TestRef{
+			ref("a_test.go", "A", "one"),
+			ref("a_test.go", "A", "two"),
+			ref("a_test.go", "A > Old", "gone"),
+		}
+		head := []TestRef{
+			ref("a_test.go", "A", "one"),
+			ref("a_test.go", "A", "two"),
+			ref("a_test.go", "A", "three"),
+			ref("b_test.go", "B", "new"),
+			ref("b_test.go", "B", "newer"),
+		}
+
+		delta := DiffInventories(base, head)
+
+		wantAdded := []TestRef{ref("a_test.go", "A", "three"), ref("b_test.go", "B", "new"), ref("b_test.go", "B", "newer")}
+		if !reflect.DeepEqual(delta.AddedTests, wantAdded) {
+			t.Errorf("AddedTests = %+v, want %+v", delta.AddedTests, wantAdded)
+		}
+		if !reflect.DeepEqual(delta.RemovedTests, []TestRef{ref("a_test.go", "A > Old", "gone")}) {
+			t.Errorf("unexpected RemovedTests: %+v", delta.RemovedTests)
+		}
+		if len(delta.RenamedTests) != 0 {
+			t.Errorf("expected no renames, got %+v", delta.RenamedTests)
+		}
+		if !reflect.DeepEqual(delta.AddedSuites, []SuiteRef{{FilePath: "b_test.go", Path: "B"}}) {
+			t.Errorf("unexpected AddedSuites: %+v", delta.AddedSuites)
+		}
+		if !reflect.DeepEqual(delta.RemovedSuites, []SuiteRef{{FilePath: "a_test.go", Path: "A > Old"}}) {
+			t.Errorf("unexpected RemovedSuites: %+v", delta.RemovedSuites)
+		}
+	})
+
+	t.Run("single change in a suite is a rename", func(t *testing.T) {
+		base := []TestRef{ref("a_test.go", "A", "keeps"), ref("a_test.go", "A", "old name")}
+		head := []TestRef{ref("a_test.go", "A", "keeps"), ref("a_test.go", "A", "new name")}
+
+		delta := DiffInventories(base, head)
+
+		want := []TestRename{{From: ref("a_test.go", "A", "old name"), To: ref("a_test.go", "A", "new name")}}
+		if !reflect.DeepEqual(delta.RenamedTests, want) {
+			t.Errorf("RenamedTests = %+v, want %+v", delta.RenamedTests, want)
+		}
+		if len(delta.AddedTests) != 0 || len(delta.RemovedTests) != 0 {
+			t.Errorf("renamed tests must not count as added or removed: %+v", delta)
+		}
+	})
+
+	t.Run("test moved to another file is a rename", func(t *testing.T) {
+		base := []TestRef{ref("old_test.go", "Auth", "login")}
+		head := []TestRef{ref("new_test.go", "Auth", "login")}
+
+		delta := DiffInventories(base, head)
+
+		want := []TestRename{{From: ref("old_test.go", "Auth", "login"), To: ref("new_test.go", "Auth", "login")}}
+		if !reflect.DeepEqual(delta.RenamedTests, want) {
+			t.Errorf("RenamedTests = %+v, want %+v", delta.RenamedTests, want)
+		}
+		if len(delta.AddedSuites) != 1 || len(delta.RemovedSuites) != 1 {
+			t.Errorf("expected the suite to move files, got %+v", delta)
+		}
+	})
+
+	t.Run("several changes in a suite are not guessed as renames", func(t *testing.T) {
+		base := []TestRef{ref("a_test.go", "A", "x"), ref("a_test.go", "A", "y")}
+		head := []TestRef{ref("a_test.go", "A", "p"), ref("a_test.go", "A", "q")}
+
+		delta := DiffInventories(base, head)
+
+		if len(delta.RenamedTests) != 0 || len(delta.AddedTests) != 2 || len(delta.RemovedTests) != 2 {
+			t.Errorf("unexpected delta: %+v", delta)
+		}
+	})
+
+	t.Run("duplicate names are compared by count", func(t *testing.T) {
+		base := []TestRef{ref("a_test.go", "A", "dup")}
+		head := []TestRef{ref("a_test.go", "A", "dup"), ref("a_test.go", "A", "dup")}
+
+		delta := DiffInventories(base, head)
+
+		if !reflect.DeepEqual(delta.AddedTests, []TestRef{ref("a_test.go", "A", "dup")}) {
+			t.Errorf("unexpected AddedTests: %+v", delta.AddedTests)
+		}
+	})
+}
+
+func TestCompareRequest_Validate(t *testing.T) {
+	valid := CompareRequest{
+		BaseCommitSHA: "base123",
+		HeadBranch:    "feature/login",
+		HeadCommitSHA: "head456",
+		Owner:         "owner",
+		PullRequest:   12,
+		Repo:          "repo",
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*CompareRequest)
+	}{
+		{"missing base commit", func(r *CompareRequest) { r.BaseCommitSHA = "" }},
+		{"missing head commit", func(r *CompareRequest) { r.HeadCommitSHA = "" }},
+		{"negative pull request", func(r *CompareRequest) { r.PullRequest = -1 }},
+		{"invalid head branch", func(r *CompareRequest) { r.HeadBranch = "-delete" }},
+		{"invalid owner", func(r *CompareRequest) { r.Owner = "a/b" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.modify(&req)
+			if err := req.Validate(); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+}
@@ -4,6 +4,11 @@ import "errors"
 
 var (
 	ErrAlreadyCompleted = errors.New("analysis already completed")
+	ErrBranchNotFound   = errors.New("branch not found")
+	ErrCloneAuthFailed  = errors.New("repository authentication failed")
 	ErrInvalidInput     = errors.New("invalid input")
+	ErrParserPanic      = errors.New("parser panicked")
 	ErrRepoNotFound     = errors.New("repository not found")
+	ErrRepoTooLarge     = errors.New("repository too large")
+	ErrWorkspaceFull    = errors.New("clone workspace quota exhausted")
 )
@@ -0,0 +1,28 @@
+package analysis
+
+// FailureClass categorizes why an analysis failed, so operations can aggregate
+// failures and retries can be tuned per class. Values match the
+// analysis_failure_class enum.
+type FailureClass string
+
+const (
+	FailureCloneAuthFailed FailureClass = "clone_auth_failed"
+	FailureCloneTimeout    FailureClass = "clone_timeout"
+	FailureDiskFull        FailureClass = "disk_full"
+	FailureOOM             FailureClass = "oom"
+	FailureParserPanic     FailureClass = "parser_panic"
+	FailureRepoTooLarge    FailureClass = "repo_too_large"
+	FailureUnknown         FailureClass = "unknown"
+)
+
+// Retryable reports whether running the same job again can succeed.
+// Missing credentials, oversized repositories and parser panics on the same
+// commit fail the same way every time.
+func (c FailureClass) Retryable() bool {
+	switch c {
+	case FailureCloneAuthFailed, FailureParserPanic, FailureRepoTooLarge:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,102 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const (
+	// maxPathFilterPatterns bounds the patterns of one include or exclude list.
+	maxPathFilterPatterns = 100
+	// maxPathFilterLength bounds a single pattern.
+	maxPathFilterLength = 500
+)
+
+// PathFilters restricts an analysis to part of a repository, e.g. only
+// "services/payments/**" or everything but "**/vendor/**". Patterns are
+// doublestar globs matched against slash-separated paths relative to the
+// repository root. A file is analyzed when it matches an Include pattern, or
+// Include is empty, and matches no Exclude pattern.
+type PathFilters struct {
+	Exclude []string `json:"exclude,omitempty"`
+	Include []string `json:"include,omitempty"`
+}
+
+// IsEmpty reports whether the filters let every file through. Safe to call on nil.
+func (f *PathFilters) IsEmpty() bool {
+	return f == nil || (len(f.Exclude) == 0 && len(f.Include) == 0)
+}
+
+func (f *PathFilters) Validate() error {
+	if f == nil {
+		return nil
+	}
+	if len(f.Exclude) > maxPathFilterPatterns || len(f.Include) > maxPathFilterPatterns {
+		return fmt.Errorf("%w: at most %d include and %d exclude path filters are allowed",
+			ErrInvalidInput, maxPathFilterPatterns, maxPathFilterPatterns)
+	}
+	for _, p := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if err := validatePathFilter(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validatePathFilter(pattern string) error {
+	switch {
+	case pattern == "":
+		return fmt.Errorf("%w: path filter is empty", ErrInvalidInput)
+	case len(pattern) > maxPathFilterLength:
+		return fmt.Errorf("%w: path filter exceeds %d characters", ErrInvalidInput, maxPathFilterLength)
+	case strings.HasPrefix(pattern, "/") || strings.HasPrefix(pattern, "./"):
+		return fmt.Errorf("%w: path filter %q must be relative to the repository root", ErrInvalidInput, pattern)
+	case !doublestar.ValidatePattern(pattern):
+		return fmt.Errorf("%w: invalid path filter %q", ErrInvalidInput, pattern)
+	}
+	return nil
+}
+
+// Matches reports whether the file at path is analyzed. Safe to call on nil.
+func (f *PathFilters) Matches(path string) bool {
+	if f.IsEmpty() {
+		return true
+	}
+	if len(f.Include) > 0 && !matchAny(f.Include, path) {
+		return false
+	}
+	return !matchAny(f.Exclude, path)
+}
+
+// ExcludedDirNames returns the directory names that Exclude drops at any depth,
+// from patterns of the form "**/<name>/**". A scanner can skip such directories
+// without descending into them instead of matching every file below.
+func (f *PathFilters) ExcludedDirNames() []string {
+	if f == nil {
+		return nil
+	}
+	var names []string
+	for _, p := range f.Exclude {
+		name, ok := strings.CutPrefix(p, "**/")
+		if !ok {
+			continue
+		}
+		name, ok = strings.CutSuffix(name, "/**")
+		if !ok || name == "" || strings.ContainsAny(name, "/*?[]{}\\") {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func matchAny(patterns []string, path string) bool {
+	for _, p := range patterns {
+		if ok, err := doublestar.Match(p, path); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package analysis
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPathFilters_Matches(t *testing.T) {
+	filters := &PathFilters{
+		Exclude: []string{"**/vendor/**", "**/*.gen_test.go"},
+		Include: []string{"services/payments/**", "libs/money/**"},
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"services/payments/charge_test.go", true},
+		{"libs/money/round_test.go", true},
+		{"services/users/user_test.go", false},
+		{"services/payments/vendor/lib/lib_test.go", false},
+		{"services/payments/api.gen_test.go", false},
+	}
+	for _, tt := range tests {
+		if got := filters.Matches(tt.path); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	var none *PathFilters
+	if !none.Matches("anything_test.go") || !none.IsEmpty() {
+		t.Error("nil filters should match every path")
+	}
+	if !(&PathFilters{Exclude: []string{"vendor/**"}}).Matches("src/vendor/lib_test.go") {
+		t.Error("exclude without ** should only match from the repository root")
+	}
+}
+
+func TestPathFilters_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters *PathFilters
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"valid", &PathFilters{Include: []string{"services/**"}, Exclude: []string{"**/testdata/**"}}, false},
+		{"empty pattern", &PathFilters{Exclude: []string{""}}, true},
+		{"unclosed bracket", &PathFilters{Include: []string{"services/[payments"}}, true},
+		{"absolute", &PathFilters{Include: []string{"/services/**"}}, true},
+		{"dot prefix", &PathFilters{Exclude: []string{"./vendor/**"}}, true},
+		{"too long", &PathFilters{Include: []string{strings.Repeat("a", maxPathFilterLength+1)}}, true},
+		{"too many", &PathFilters{Exclude: slices.Repeat([]string{"a/**"}, maxPathFilterPatterns+1)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filters.Validate()
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPathFilters_ExcludedDirNames(t *testing.T) {
+	filters := &PathFilters{Exclude: []string{"**/vendor/**", "**/gen*/**", "build/**", "**/a/b/**", "**/fixtures/**"}}
+	if got := filters.ExcludedDirNames(); !slices.Equal(got, []string{"vendor", "fixtures"}) {
+		t.Errorf("ExcludedDirNames() = %v", got)
+	}
+}
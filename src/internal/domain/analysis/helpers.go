@@ -0,0 +1,250 @@
+package analysis
+
+import (
+	"cmp"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultMinHelperReferences is how many test files must reference a helper
+// or fixture for the analysis to record it as shared.
+const DefaultMinHelperReferences = 5
+
+// MaxSharedHelpers bounds the shared helpers recorded per analysis, the most
+// referenced first.
+const MaxSharedHelpers = 100
+
+// SharedHelper is a helper or fixture module referenced by many test files,
+// where a change puts every behavior of those files at risk.
+type SharedHelper struct {
+	Path      string   // repository-relative file, or package directory for Go
+	TestFiles []string // referencing test files, sorted
+}
+
+var (
+	goImportBlock  = regexp.MustCompile(`(?s)\bimport\s*\(([^)]*)\)`)
+	goImportLine   = regexp.MustCompile(`(?m)^\s*import\s+(?:[\w.]+\s+)?"([^"\n]+)"`)
+	goQuoted       = regexp.MustCompile(`"([^"\n]+)"`)
+	goModuleLine   = regexp.MustCompile(`(?m)^\s*module\s+(\S+)`)
+	jsImport       = regexp.MustCompile(`(?:\bfrom\s+|\bimport\s+|\brequire\(\s*|\bimport\(\s*)["']([^"'\n]+)["']`)
+	jvmImport      = regexp.MustCompile(`(?m)^\s*import\s+(?:static\s+)?([\w.]+)`)
+	pyFromImport   = regexp.MustCompile(`(?m)^\s*from\s+([.\w]+)\s+import\b`)
+	pyImport       = regexp.MustCompile(`(?m)^\s*import\s+([\w.]+(?:\s*,\s*[\w.]+)*)`)
+	jsResolveOrder = []string{"", ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", "/index.ts", "/index.tsx", "/index.js", "/index.jsx"}
+)
+
+// ParseGoModule returns the module path declared by a go.mod, or "".
+func ParseGoModule(data []byte) string {
+	if m := goModuleLine.FindSubmatch(data); m != nil {
+		return strings.Trim(string(m[1]), `"`)
+	}
+	return ""
+}
+
+// HelperResolver resolves the imports of test files to files of the
+// repository. Imports of third-party packages, and imports it cannot resolve,
+// are ignored.
+type HelperResolver struct {
+	dirs      map[string]bool
+	files     map[string]bool
+	goModule  string
+	jvmByName map[string][]string // Java and Kotlin files by class name
+}
+
+// NewHelperResolver indexes sourceFiles, slash-separated and relative to the
+// repository root. goModule is the module path of the root go.mod, empty for
+// repositories without one.
+func NewHelperResolver(sourceFiles []string, goModule string) *HelperResolver {
+	r := &HelperResolver{
+		dirs:      make(map[string]bool),
+		files:     make(map[string]bool, len(sourceFiles)),
+		goModule:  goModule,
+		jvmByName: make(map[string][]string),
+	}
+	for _, f := range sourceFiles {
+		r.files[f] = true
+		r.dirs[path.Dir(f)] = true
+		if ext := path.Ext(f); ext == ".java" || ext == ".kt" {
+			name := strings.TrimSuffix(path.Base(f), ext)
+			r.jvmByName[name] = append(r.jvmByName[name], strings.TrimSuffix(f, ext))
+		}
+	}
+	return r
+}
+
+// References returns the repository files, or Go package directories, that the
+// test file at testFile references, sorted and without testFile itself. Python
+// tests also reference every conftest.py of their directory and its parents,
+// which pytest loads implicitly.
+func (r *HelperResolver) References(testFile string, content []byte) []string {
+	seen := make(map[string]bool)
+	add := func(target string, ok bool) {
+		if ok && target != testFile {
+			seen[target] = true
+		}
+	}
+
+	dir := path.Dir(testFile)
+	switch LanguageOf(testFile) {
+	case "go":
+		for _, spec := range goImports(content) {
+			add(r.resolveGo(spec))
+		}
+	case "javascript", "typescript":
+		for _, m := range jsImport.FindAllSubmatch(content, -1) {
+			add(r.resolveJS(dir, string(m[1])))
+		}
+	case "python":
+		for _, m := range pyFromImport.FindAllSubmatch(content, -1) {
+			add(r.resolvePython(dir, string(m[1])))
+		}
+		for _, m := range pyImport.FindAllSubmatch(content, -1) {
+			for _, module := range strings.Split(string(m[1]), ",") {
+				add(r.resolvePython(dir, strings.TrimSpace(module)))
+			}
+		}
+		for d := dir; ; d = path.Dir(d) {
+			conftest := path.Join(d, "conftest.py")
+			add(conftest, r.files[conftest])
+			if d == "." || d == "/" {
+				break
+			}
+		}
+	case "java", "kotlin":
+		for _, m := range jvmImport.FindAllSubmatch(content, -1) {
+			add(r.resolveJVM(string(m[1])))
+		}
+	}
+
+	refs := make([]string, 0, len(seen))
+	for target := range seen {
+		refs = append(refs, target)
+	}
+	slices.Sort(refs)
+	return refs
+}
+
+func goImports(content []byte) []string {
+	var specs []string
+	for _, block := range goImportBlock.FindAllSubmatch(content, -1) {
+		for _, m := range goQuoted.FindAllSubmatch(block[1], -1) {
+			specs = append(specs, string(m[1]))
+		}
+	}
+	for _, m := range goImportLine.FindAllSubmatch(content, -1) {
+		specs = append(specs, string(m[1]))
+	}
+	return specs
+}
+
+// resolveGo maps an import of the root module to its package directory.
+func (r *HelperResolver) resolveGo(spec string) (string, bool) {
+	if r.goModule == "" {
+		return "", false
+	}
+	rel, ok := strings.CutPrefix(spec, r.goModule+"/")
+	if !ok {
+		return "", false
+	}
+	return rel, r.dirs[rel]
+}
+
+// resolveJS maps a relative import to a file, trying the extensions and index
+// files bundlers resolve. Bare and aliased specifiers are ignored.
+func (r *HelperResolver) resolveJS(dir, spec string) (string, bool) {
+	if !strings.HasPrefix(spec, "./") && !strings.HasPrefix(spec, "../") {
+		return "", false
+	}
+	base := path.Join(dir, spec)
+	for _, suffix := range jsResolveOrder {
+		if candidate := base + suffix; r.files[candidate] {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// resolvePython maps a module, relative (leading dots) or absolute from the
+// repository root or a src directory, to its file or package __init__.py.
+func (r *HelperResolver) resolvePython(dir, module string) (string, bool) {
+	var bases []string
+	if rest := strings.TrimLeft(module, "."); rest != module {
+		base := dir
+		for range len(module) - len(rest) - 1 {
+			base = path.Dir(base)
+		}
+		bases = []string{path.Join(base, strings.ReplaceAll(rest, ".", "/"))}
+	} else {
+		rel := strings.ReplaceAll(module, ".", "/")
+		bases = []string{rel, path.Join("src", rel)}
+	}
+	for _, base := range bases {
+		for _, candidate := range []string{base + ".py", path.Join(base, "__init__.py")} {
+			if r.files[candidate] {
+				return candidate, true
+			}
+		}
+	}
+	return "", false
+}
+
+// resolveJVM maps a class import, static member imports included, to the Java
+// or Kotlin file whose path ends with the package path. Wildcards are ignored.
+func (r *HelperResolver) resolveJVM(spec string) (string, bool) {
+	parts := strings.Split(spec, ".")
+	for n := len(parts); n >= 2; n-- {
+		suffix := strings.Join(parts[:n], "/")
+		for _, candidate := range r.jvmByName[parts[n-1]] {
+			if candidate == suffix || strings.HasSuffix(candidate, "/"+suffix) {
+				for _, ext := range []string{".java", ".kt"} {
+					if r.files[candidate+ext] {
+						return candidate + ext, true
+					}
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// HelperTally collects, per referenced file, the test files referencing it.
+// The zero value is not usable; create one with make.
+type HelperTally map[string]map[string]struct{}
+
+// Add records that testFile references each of helpers.
+func (t HelperTally) Add(testFile string, helpers []string) {
+	for _, helper := range helpers {
+		files, ok := t[helper]
+		if !ok {
+			files = make(map[string]struct{})
+			t[helper] = files
+		}
+		files[testFile] = struct{}{}
+	}
+}
+
+// Shared returns up to limit helpers referenced by at least minReferences
+// test files, the most referenced first, ties by path.
+func (t HelperTally) Shared(minReferences, limit int) []SharedHelper {
+	var helpers []SharedHelper
+	for helper, files := range t {
+		if len(files) < max(minReferences, 1) {
+			continue
+		}
+		testFiles := make([]string, 0, len(files))
+		for f := range files {
+			testFiles = append(testFiles, f)
+		}
+		slices.Sort(testFiles)
+		helpers = append(helpers, SharedHelper{Path: helper, TestFiles: testFiles})
+	}
+	slices.SortFunc(helpers, func(a, b SharedHelper) int {
+		return cmp.Or(cmp.Compare(len(b.TestFiles), len(a.TestFiles)), cmp.Compare(a.Path, b.Path))
+	})
+	if limit > 0 && len(helpers) > limit {
+		helpers = helpers[:limit]
+	}
+	return helpers
+}
@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseGoModule(t *testing.T) {
+	if got := ParseGoModule([]byte("// comment\nmodule github.com/acme/shop\n\ngo 1.24\n")); got != "github.com/acme/shop" {
+		t.Errorf("ParseGoModule() = %q", got)
+	}
+	if got := ParseGoModule([]byte("go 1.24\n")); got != "" {
+		t.Errorf("expected no module, got %q", got)
+	}
+}
+
+func TestHelperResolver_References(t *testing.T) {
+	resolver := NewHelperResolver([]string{
+		"conftest.py",
+		"internal/testutil/db.go",
+		"src/shop/factories.py",
+		"src/test/java/com/acme/Fixtures.java",
+		"tests/api/conftest.py",
+		"tests/api/test_orders.py",
+		"tests/helpers.py",
+		"web/src/test-utils/index.ts",
+		"web/src/test-utils/render.tsx",
+		"web/src/cart.test.ts",
+	}, "github.com/acme/shop")
+
+	tests := []struct {
+		name     string
+		testFile string
+		content  string
+		want     []string
+	}{
+		{
+			name:     "go imports of the root module",
+			testFile: "internal/order/order_test.go",
+			content:  "package order\n\nimport (\n\t\"testing\"\n\n\tdb \"github.com/acme/shop/internal/testutil\"\n\t\"github.com/stretchr/testify/require\"\n)\n",
+			want:     []string{"internal/testutil"},
+		},
+		{
+			name:     "relative javascript imports",
+			testFile: "web/src/cart.test.ts",
+			content:  "import { render } from './test-utils/render'\nimport setup from \"./test-utils\"\nconst React = require('react')\nimport '../missing'\n",
+			want:     []string{"web/src/test-utils/index.ts", "web/src/test-utils/render.tsx"},
+		},
+		{
+			name:     "python imports and implicit conftests",
+			testFile: "tests/api/test_orders.py",
+			content:  "import pytest\nfrom shop.factories import OrderFactory\nfrom ..helpers import login\n",
+			want:     []string{"conftest.py", "src/shop/factories.py", "tests/api/conftest.py", "tests/helpers.py"},
+		},
+		{
+			name:     "java class and static imports",
+			testFile: "src/test/java/com/acme/OrderTest.java",
+			content:  "import static com.acme.Fixtures.order;\nimport com.acme.Fixtures;\nimport org.junit.Test;\n",
+			want:     []string{"src/test/java/com/acme/Fixtures.java"},
+		},
+		{
+			name:     "a file does not reference itself",
+			testFile: "tests/api/conftest.py",
+			content:  "",
+			want:     []string{"conftest.py"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolver.References(tt.testFile, []byte(tt.content)); !slices.Equal(got, tt.want) {
+				t.Errorf("References() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHelperTally_Shared(t *testing.T) {
+	tally := make(HelperTally)
+	tally.Add("a_test.go", []string{"testutil", "fixtures"})
+	tally.Add("b_test.go", []string{"testutil", "fixtures"})
+	tally.Add("c_test.go", []string{"testutil"})
+	tally.Add("c_test.go", []string{"testutil"})
+	tally.Add("d_test.go", []string{"mocks"})
+
+	got := tally.Shared(2, 0)
+	if len(got) != 2 || got[0].Path != "testutil" || got[1].Path != "fixtures" {
+		t.Fatalf("Shared() = %+v", got)
+	}
+	if !slices.Equal(got[0].TestFiles, []string{"a_test.go", "b_test.go", "c_test.go"}) {
+		t.Errorf("TestFiles = %v", got[0].TestFiles)
+	}
+	if limited := tally.Shared(1, 1); len(limited) != 1 || limited[0].Path != "testutil" {
+		t.Errorf("expected the most referenced helper only, got %+v", limited)
+	}
+}
@@ -1,17 +1,66 @@
 package analysis
 
+import (
+	"crypto/sha256"
+	"errors"
+	"maps"
+	"slices"
+)
+
 type Inventory struct {
-	Files []TestFile
+	Files          []TestFile
+	ParseErrors    []ParseError // files that failed to parse and are left out of Files
+	UnmatchedFiles int          // test file candidates no parser strategy matched
+}
+
+// ParseError records a test file that could not be parsed. The file is
+// quarantined: skipped by the analysis instead of failing it.
+type ParseError struct {
+	FilePath string
+	Message  string
+	Panicked bool // the parser panicked rather than returning an error
+}
+
+// NewParseError describes the parse failure err of the file at path.
+func NewParseError(path string, err error) ParseError {
+	return ParseError{
+		FilePath: path,
+		Message:  err.Error(),
+		Panicked: errors.Is(err, ErrParserPanic),
+	}
 }
 
 type TestFile struct {
 	Path        string
 	Framework   string
 	DomainHints *DomainHints
+	Project     string // root directory of the monorepo project holding the file; empty for the repository root
+	ContentHash []byte // SHA-256 of the file content; nil when the parser does not hash files
+	Unchanged   bool   // not parsed: the content matches a previous analysis, whose suites and tests apply
 	Suites      []TestSuite
 	Tests       []Test
 }
 
+// FileHashes maps test file paths to the content hashes they were parsed with.
+type FileHashes map[string][]byte
+
+// Fingerprint identifies the test file set: a SHA-256 over every path and its
+// content hash, in path order. Analyses with equal fingerprints hold the same
+// test files with the same content. Nil when h is empty.
+func (h FileHashes) Fingerprint() []byte {
+	if len(h) == 0 {
+		return nil
+	}
+	sum := sha256.New()
+	for _, path := range slices.Sorted(maps.Keys(h)) {
+		sum.Write([]byte(path))
+		sum.Write([]byte{0})
+		sum.Write(h[path])
+		sum.Write([]byte{0})
+	}
+	return sum.Sum(nil)
+}
+
 type DomainHints struct {
 	Calls   []string
 	Imports []string
@@ -28,6 +77,7 @@ type Test struct {
 	Name     string
 	Location Location
 	Status   TestStatus
+	Variants int // parameter variants collapsed into this test; 0 for a plain test
 }
 
 type Location struct {
@@ -0,0 +1,34 @@
+package analysis
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFileHashes_Fingerprint(t *testing.T) {
+	hashes := FileHashes{"a_test.go": []byte("a"), "b_test.go": []byte("b")}
+
+	if got := (FileHashes{}).Fingerprint(); got != nil {
+		t.Errorf("expected no fingerprint for no files, got %x", got)
+	}
+	if !bytes.Equal(hashes.Fingerprint(), FileHashes{"b_test.go": []byte("b"), "a_test.go": []byte("a")}.Fingerprint()) {
+		t.Error("expected the fingerprint independent of insertion order")
+	}
+
+	tests := []struct {
+		name   string
+		hashes FileHashes
+	}{
+		{"changed content", FileHashes{"a_test.go": []byte("a"), "b_test.go": []byte("b2")}},
+		{"renamed file", FileHashes{"a_test.go": []byte("a"), "c_test.go": []byte("b")}},
+		{"removed file", FileHashes{"a_test.go": []byte("a")}},
+		{"shifted boundary", FileHashes{"a_test.goa": nil, "b_test.go": []byte("b")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if bytes.Equal(hashes.Fingerprint(), tt.hashes.Fingerprint()) {
+				t.Error("expected a different fingerprint")
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package analysis
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// LanguageUnknown labels test files whose language cannot be told from the path,
+// and unmatched files the scan reported without a path.
+const LanguageUnknown = "unknown"
+
+// languageByExt mirrors the core parser's language detection, so files no parser
+// strategy matched are attributed to a language the same way as parsed ones.
+var languageByExt = map[string]string{
+	".cc":    "cpp",
+	".cjs":   "javascript",
+	".cpp":   "cpp",
+	".cs":    "csharp",
+	".cxx":   "cpp",
+	".go":    "go",
+	".java":  "java",
+	".js":    "javascript",
+	".jsx":   "javascript",
+	".kt":    "kotlin",
+	".kts":   "kotlin",
+	".mjs":   "javascript",
+	".php":   "php",
+	".py":    "python",
+	".rb":    "ruby",
+	".rs":    "rust",
+	".swift": "swift",
+	".ts":    "typescript",
+	".tsx":   "typescript",
+}
+
+// LanguageOf returns the programming language of the test file at filePath,
+// detected from its extension, or LanguageUnknown.
+func LanguageOf(filePath string) string {
+	if lang, ok := languageByExt[strings.ToLower(path.Ext(filePath))]; ok {
+		return lang
+	}
+	return LanguageUnknown
+}
+
+// LanguageStats counts the test files of one language in an analysis.
+type LanguageStats struct {
+	Language       string
+	Files          int // parsed test files, reused ones included
+	Tests          int
+	FailedFiles    int // quarantined after a parse failure
+	UnmatchedFiles int // test file candidates no parser strategy matched
+}
+
+// LanguageTally accumulates LanguageStats per language while a scan is read.
+// The zero value is not usable; create one with make.
+type LanguageTally map[string]*LanguageStats
+
+func (t LanguageTally) stats(lang string) *LanguageStats {
+	s, ok := t[lang]
+	if !ok {
+		s = &LanguageStats{Language: lang}
+		t[lang] = s
+	}
+	return s
+}
+
+// AddFile counts a parsed test file and its tests, nested suites included.
+func (t LanguageTally) AddFile(f TestFile) {
+	s := t.stats(LanguageOf(f.Path))
+	s.Files++
+	s.Tests += len(f.Tests)
+	for _, suite := range f.Suites {
+		s.Tests += suite.countTests()
+	}
+}
+
+// AddFailed counts a test file quarantined after a parse failure.
+func (t LanguageTally) AddFailed(filePath string) {
+	t.stats(LanguageOf(filePath)).FailedFiles++
+}
+
+// AddUnmatched counts n test file candidates at filePath's language that no
+// parser strategy matched. An empty filePath counts them as LanguageUnknown.
+func (t LanguageTally) AddUnmatched(filePath string, n int) {
+	if n > 0 {
+		t.stats(LanguageOf(filePath)).UnmatchedFiles += n
+	}
+}
+
+// Stats returns the tallied languages sorted by name.
+func (t LanguageTally) Stats() []LanguageStats {
+	stats := make([]LanguageStats, 0, len(t))
+	for _, s := range t {
+		stats = append(stats, *s)
+	}
+	slices.SortFunc(stats, func(a, b LanguageStats) int { return strings.Compare(a.Language, b.Language) })
+	return stats
+}
+
+func (s TestSuite) countTests() int {
+	n := len(s.Tests)
+	for _, child := range s.Suites {
+		n += child.countTests()
+	}
+	return n
+}
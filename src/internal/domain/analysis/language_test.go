@@ -0,0 +1,50 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLanguageOf(t *testing.T) {
+	tests := map[string]string{
+		"src/app.test.ts":        "typescript",
+		"src/App.spec.TSX":       "typescript",
+		"web/util.test.mjs":      "javascript",
+		"pkg/auth/login_test.go": "go",
+		"tests/test_api.py":      "python",
+		"app/src/MainTest.kt":    "kotlin",
+		"Makefile":               LanguageUnknown,
+		"tests/fixture.snap":     LanguageUnknown,
+	}
+	for path, want := range tests {
+		if got := LanguageOf(path); got != want {
+			t.Errorf("LanguageOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestLanguageTally(t *testing.T) {
+	tally := make(LanguageTally)
+	tally.AddFile(TestFile{
+		Path:  "login_test.go",
+		Tests: []Test{{Name: "TestLogin"}},
+		Suites: []TestSuite{{
+			Tests:  []Test{{Name: "a"}},
+			Suites: []TestSuite{{Tests: []Test{{Name: "b"}, {Name: "c"}}}},
+		}},
+	})
+	tally.AddFile(TestFile{Path: "logout_test.go"})
+	tally.AddFailed("broken.test.ts")
+	tally.AddUnmatched("widget.test.ts", 1)
+	tally.AddUnmatched("", 2)
+	tally.AddUnmatched("", 0)
+
+	want := []LanguageStats{
+		{Language: "go", Files: 2, Tests: 4},
+		{Language: "typescript", FailedFiles: 1, UnmatchedFiles: 1},
+		{Language: LanguageUnknown, UnmatchedFiles: 2},
+	}
+	if got := tally.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
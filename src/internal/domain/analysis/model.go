@@ -5,11 +5,20 @@ import (
 	"strings"
 )
 
+// maxBranchNameLength matches analyses.branch_name.
+const maxBranchNameLength = 255
+
 type AnalyzeRequest struct {
+	Branch    string // optional: branch to analyze, empty for the default branch
+	Host      string // optional: VCS host, e.g. a GitHub Enterprise host, empty for github.com
 	Owner     string
 	Repo      string
 	CommitSHA string
+	JobID     int64 // optional: queue job ID, used to correlate progress events
 	UserID    *string
+	// PathFilters limits the analysis to part of the repository. Nil reuses the
+	// filters of the codebase's previous analysis; an empty value clears them.
+	PathFilters *PathFilters
 }
 
 func (r AnalyzeRequest) Validate() error {
@@ -28,9 +37,53 @@ func (r AnalyzeRequest) Validate() error {
 	if !isValidGitHubName(r.Owner) || !isValidGitHubName(r.Repo) {
 		return fmt.Errorf("%w: invalid characters in owner/repo", ErrInvalidInput)
 	}
+	if r.Branch != "" && !IsValidBranchName(r.Branch) {
+		return fmt.Errorf("%w: invalid branch name", ErrInvalidInput)
+	}
+	if r.Host != "" && !isValidHost(r.Host) {
+		return fmt.Errorf("%w: invalid host", ErrInvalidInput)
+	}
+	if err := r.PathFilters.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// IsValidBranchName reports whether name is a safe git branch name: the subset of
+// git check-ref-format that also keeps it from being read as a command-line flag.
+func IsValidBranchName(name string) bool {
+	if name == "" || len(name) > maxBranchNameLength {
+		return false
+	}
+	if name == "@" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "/") ||
+		strings.HasSuffix(name, "/") || strings.HasSuffix(name, ".") || strings.HasSuffix(name, ".lock") {
+		return false
+	}
+	if strings.Contains(name, "..") || strings.Contains(name, "//") || strings.Contains(name, "/.") || strings.Contains(name, "@{") {
+		return false
+	}
+	for _, r := range name {
+		if r <= ' ' || r == 0x7f || strings.ContainsRune("~^:?*[\\", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidHost reports whether s is a bare host name, so it cannot change the
+// scheme, port or path of the clone URL it is put in.
+func isValidHost(s string) bool {
+	if len(s) > 253 || strings.HasPrefix(s, ".") || strings.HasPrefix(s, "-") || strings.Contains(s, "..") {
+		return false
+	}
+	for _, r := range s {
+		if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
 func isValidGitHubName(s string) bool {
 	if s == "" {
 		return false
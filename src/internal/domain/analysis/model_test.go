@@ -2,6 +2,7 @@ package analysis
 
 import (
 	"errors"
+	"strings"
 	"testing"
 )
 
@@ -121,6 +122,31 @@ func TestAnalyzeRequest_Validate(t *testing.T) {
 			req:     AnalyzeRequest{Owner: ".", Repo: "repo", CommitSHA: "abc123"},
 			wantErr: ErrInvalidInput,
 		},
+		{
+			name:    "valid with branch",
+			req:     AnalyzeRequest{Owner: "owner", Repo: "repo", CommitSHA: "abc123", Branch: "release/v1.2"},
+			wantErr: nil,
+		},
+		{
+			name:    "branch read as a flag",
+			req:     AnalyzeRequest{Owner: "owner", Repo: "repo", CommitSHA: "abc123", Branch: "--upload-pack=evil"},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "valid with enterprise host",
+			req:     AnalyzeRequest{Owner: "owner", Repo: "repo", CommitSHA: "abc123", Host: "github.example.com"},
+			wantErr: nil,
+		},
+		{
+			name:    "host with path",
+			req:     AnalyzeRequest{Owner: "owner", Repo: "repo", CommitSHA: "abc123", Host: "evil.example/owner"},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "host with port",
+			req:     AnalyzeRequest{Owner: "owner", Repo: "repo", CommitSHA: "abc123", Host: "github.example.com:8443"},
+			wantErr: ErrInvalidInput,
+		},
 	}
 
 	for _, tt := range tests {
@@ -238,3 +264,41 @@ func TestIsValidGitHubName(t *testing.T) {
 		})
 	}
 }
+
+func TestIsValidBranchName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"main", true},
+		{"feature/login-flow", true},
+		{"release/v1.2.0", true},
+		{"user@fix", true},
+		{"", false},
+		{"-rf", false},
+		{".hidden", false},
+		{"feature/.hidden", false},
+		{"a..b", false},
+		{"feature//login", false},
+		{"feature/", false},
+		{"branch.", false},
+		{"branch.lock", false},
+		{"@", false},
+		{"a@{1}", false},
+		{"with space", false},
+		{"tilde~1", false},
+		{"caret^", false},
+		{"colon:ref", false},
+		{"glob*", false},
+		{"back\\slash", false},
+		{strings.Repeat("a", 256), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := IsValidBranchName(tt.input); got != tt.want {
+				t.Errorf("IsValidBranchName(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -13,7 +13,36 @@ type StreamingParser interface {
 }
 
 // FileResult represents a single file parsing result from streaming parser.
+// Path is set when Err concerns a single file, which the analyzer quarantines
+// instead of failing the scan; an error without a Path fails the whole scan.
+// A result with a Path but neither File nor Err is a test file candidate no
+// parser strategy matched.
 type FileResult struct {
 	Err  error
 	File *TestFile
+	Path string
+}
+
+// FilteringParser applies path filters while discovering test files, so most
+// filtered files are never read. Optional capability: without it, the analyzer
+// drops filtered files after parsing.
+type FilteringParser interface {
+	ScanFiltered(ctx context.Context, src Source, filters *PathFilters) (*Inventory, error)
+	ScanStreamFiltered(ctx context.Context, src Source, filters *PathFilters) (<-chan FileResult, error)
+}
+
+// IncrementalParser skips test files whose content is unchanged since a previous
+// analysis. A file whose content hash equals known[path] comes back with Unchanged
+// set and no suites or tests instead of being parsed; every other file is parsed
+// and carries its ContentHash. Filters apply as with FilteringParser.
+// Optional capability: without it, every file is parsed on every analysis.
+type IncrementalParser interface {
+	ScanIncremental(ctx context.Context, src Source, filters *PathFilters, known FileHashes) (*Inventory, error)
+	ScanStreamIncremental(ctx context.Context, src Source, filters *PathFilters, known FileHashes) (<-chan FileResult, error)
+}
+
+// SourceFileLister lists code files in a cloned repository, test files included.
+// Optional capability: the analyzer records the file inventory only when the parser implements it.
+type SourceFileLister interface {
+	ListSourceFiles(ctx context.Context, src Source) ([]string, error)
 }
@@ -0,0 +1,39 @@
+package analysis
+
+import (
+	"context"
+	"time"
+)
+
+// ProgressStage identifies a step of the analysis pipeline reported to the Web UI.
+type ProgressStage string
+
+const (
+	StageCloneStarted   ProgressStage = "clone_started"
+	StageCloneCompleted ProgressStage = "clone_completed"
+	StageScanStarted    ProgressStage = "scan_started"
+	StageScanProgress   ProgressStage = "scan_progress"
+	StageSaving         ProgressStage = "saving"
+	StageCompleted      ProgressStage = "completed"
+	StageFailed         ProgressStage = "failed"
+)
+
+// ProgressEvent is a single progress update for an analysis job.
+// AnalysisID is nil for stages before the analysis record exists (clone),
+// so consumers correlate events by JobID or owner/repo/commit.
+type ProgressEvent struct {
+	AnalysisID     *UUID
+	CommitSHA      string
+	FilesProcessed int
+	FilesTotal     int // 0 when unknown (streaming scan)
+	JobID          int64
+	OccurredAt     time.Time
+	Owner          string
+	Repo           string
+	Stage          ProgressStage
+}
+
+// ProgressRepository persists progress events and notifies listeners.
+type ProgressRepository interface {
+	SaveProgressEvent(ctx context.Context, event ProgressEvent) error
+}
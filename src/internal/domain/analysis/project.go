@@ -0,0 +1,101 @@
+package analysis
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Project manifests that mark a project root inside a repository.
+const (
+	GoModFile       = "go.mod"
+	PackageJSONFile = "package.json"
+	PomFile         = "pom.xml"
+)
+
+// ParseNPMWorkspaces returns the workspace patterns of a package.json, in either
+// the array form or the Yarn {"packages": [...]} form. A manifest without
+// workspaces returns nil.
+func ParseNPMWorkspaces(data []byte) ([]string, error) {
+	var manifest struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", PackageJSONFile, err)
+	}
+	if len(manifest.Workspaces) == 0 {
+		return nil, nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(manifest.Workspaces, &patterns); err == nil {
+		return patterns, nil
+	}
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(manifest.Workspaces, &object); err != nil {
+		return nil, fmt.Errorf("parse %s workspaces: %w", PackageJSONFile, err)
+	}
+	return object.Packages, nil
+}
+
+// ParseMavenModules returns the module directories listed by a pom.xml,
+// relative to the pom's own directory.
+func ParseMavenModules(data []byte) ([]string, error) {
+	var pom struct {
+		Modules []string `xml:"modules>module"`
+	}
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", PomFile, err)
+	}
+	modules := make([]string, 0, len(pom.Modules))
+	for _, m := range pom.Modules {
+		if m = strings.TrimSpace(m); m != "" {
+			modules = append(modules, m)
+		}
+	}
+	return modules, nil
+}
+
+// MatchWorkspaces reports whether dir, slash-separated and relative to the
+// repository root, is a package of the npm workspace patterns. Patterns use
+// path.Match syntax per segment, "**" matches any number of segments and a
+// leading "!" excludes directories matched by earlier patterns.
+func MatchWorkspaces(patterns []string, dir string) bool {
+	matched := false
+	for _, p := range patterns {
+		negated := strings.HasPrefix(p, "!")
+		p = strings.Trim(strings.TrimPrefix(strings.TrimPrefix(p, "!"), "./"), "/")
+		if p == "" {
+			continue
+		}
+		if matchSegments(strings.Split(p, "/"), strings.Split(dir, "/")) {
+			matched = !negated
+		}
+	}
+	return matched
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,80 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNPMWorkspaces(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "array form", data: `{"workspaces": ["packages/*", "apps/web"]}`, want: []string{"packages/*", "apps/web"}},
+		{name: "yarn object form", data: `{"workspaces": {"packages": ["libs/**"], "nohoist": ["**/react"]}}`, want: []string{"libs/**"}},
+		{name: "no workspaces", data: `{"name": "app"}`},
+		{name: "invalid json", data: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNPMWorkspaces([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("workspaces = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMavenModules(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <artifactId>parent</artifactId>
+  <modules>
+    <module>core</module>
+    <module> services/api </module>
+  </modules>
+</project>`
+
+	got, err := ParseMavenModules([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"core", "services/api"}) {
+		t.Errorf("modules = %v", got)
+	}
+
+	if _, err := ParseMavenModules([]byte("<project>")); err == nil {
+		t.Error("expected error for truncated pom")
+	}
+}
+
+func TestMatchWorkspaces(t *testing.T) {
+	patterns := []string{"packages/*", "./apps/**", "!packages/legacy", "tools/cli/"}
+
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{dir: "packages/ui", want: true},
+		{dir: "packages/ui/src", want: false},
+		{dir: "packages/legacy", want: false},
+		{dir: "apps/web", want: true},
+		{dir: "apps/mobile/ios", want: true},
+		{dir: "tools/cli", want: true},
+		{dir: "tools", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			if got := MatchWorkspaces(patterns, tt.dir); got != tt.want {
+				t.Errorf("MatchWorkspaces(%q) = %v, want %v", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/specvital/worker/internal/domain/curation"
 )
 
 type Repository interface {
 	CreateAnalysisRecord(ctx context.Context, params CreateAnalysisRecordParams) (UUID, error)
-	RecordFailure(ctx context.Context, analysisID UUID, errMessage string) error
+	RecordFailure(ctx context.Context, analysisID UUID, class FailureClass, errMessage string) error
 	SaveAnalysisInventory(ctx context.Context, params SaveAnalysisInventoryParams) error
+	UpdateAnalysisSource(ctx context.Context, params UpdateAnalysisSourceParams) error
 }
 
 // StreamingRepository extends Repository with batch-based storage for streaming pipeline.
@@ -19,14 +22,81 @@ type StreamingRepository interface {
 	SaveAnalysisBatch(ctx context.Context, params SaveAnalysisBatchParams) (*BatchStats, error)
 }
 
+// CurationRulesRepository stores the repository rules file parsed during analysis,
+// so spec generation applies the rules from the same commit.
+type CurationRulesRepository interface {
+	SaveCurationRules(ctx context.Context, analysisID UUID, rules *curation.Rules) error
+}
+
+// PathFiltersRepository looks up the path filters a codebase was last analyzed
+// with, so a re-analysis requested without filters applies the same ones.
+type PathFiltersRepository interface {
+	GetLatestPathFilters(ctx context.Context, codebaseID UUID) (*PathFilters, error)
+}
+
+// FileReuseRepository loads the test files of a previous analysis, so files whose
+// content is unchanged are copied into a new analysis instead of being re-parsed.
+type FileReuseRepository interface {
+	// GetReusableFiles returns the latest completed analysis of the codebase made
+	// with parserVersion and the content hashes of its test files, or NilUUID
+	// without error when there is none.
+	GetReusableFiles(ctx context.Context, codebaseID UUID, parserVersion string) (UUID, FileHashes, error)
+	// GetTestFiles returns the test files of the analysis at paths, with their suites and tests.
+	GetTestFiles(ctx context.Context, analysisID UUID, paths []string) ([]TestFile, error)
+}
+
+// ForkReuseRepository finds the test files of another codebase at the same
+// commit, so the first analysis of a fork copies the inventory of the
+// repository it was forked from instead of parsing it again.
+type ForkReuseRepository interface {
+	// GetForkReusableFiles returns the latest completed analysis of another
+	// codebase at commitSHA made with parserVersion and the content hashes of
+	// its test files, or NilUUID without error when there is none or either
+	// codebase opted out of fork deduplication.
+	GetForkReusableFiles(ctx context.Context, codebaseID UUID, commitSHA, parserVersion string) (UUID, FileHashes, error)
+	// SaveFingerprint records the test file fingerprint of the analysis and the
+	// analysis of another codebase it duplicates, NilUUID when none.
+	SaveFingerprint(ctx context.Context, analysisID UUID, fingerprint []byte, duplicateOf UUID) error
+}
+
+// ParseErrorRepository records the files an analysis quarantined because they
+// failed to parse. A completed analysis with quarantined files is a partial success.
+type ParseErrorRepository interface {
+	QuarantineFiles(ctx context.Context, analysisID UUID, errs []ParseError) error
+}
+
+// LanguageStatsRepository stores the language distribution of an analysis' test
+// files, including the candidates no parser strategy matched.
+type LanguageStatsRepository interface {
+	SaveLanguageStats(ctx context.Context, analysisID UUID, stats []LanguageStats) error
+}
+
+// UnmatchedSampleRepository stores a sample of the analysis' test file
+// candidates no parser strategy matched, for extending framework support.
+type UnmatchedSampleRepository interface {
+	SaveUnmatchedSamples(ctx context.Context, analysisID UUID, samples []UnmatchedSample) error
+}
+
+// SharedHelperRepository stores the helpers and fixtures many test files of an
+// analysis reference, so the spec view can flag the behaviors relying on them.
+type SharedHelperRepository interface {
+	SaveSharedHelpers(ctx context.Context, analysisID UUID, helpers []SharedHelper) error
+}
+
+// SourceFileRepository stores the repository file inventory used for spec gap analysis.
+type SourceFileRepository interface {
+	SaveSourceFiles(ctx context.Context, analysisID UUID, paths []string) error
+}
+
 type CreateAnalysisRecordParams struct {
 	AnalysisID     *UUID
-	Branch         string
+	Branch         string // empty until the clone resolves the default branch
 	CodebaseID     *UUID
 	CommitSHA      string
 	ExternalRepoID string
 	Owner          string
 	ParserVersion  string
+	PathFilters    *PathFilters
 	Repo           string
 }
 
@@ -37,9 +107,6 @@ func (p CreateAnalysisRecordParams) Validate() error {
 	if p.Repo == "" {
 		return fmt.Errorf("%w: repo is required", ErrInvalidInput)
 	}
-	if p.Branch == "" {
-		return fmt.Errorf("%w: branch is required", ErrInvalidInput)
-	}
 	if p.CommitSHA == "" {
 		return fmt.Errorf("%w: commit SHA is required", ErrInvalidInput)
 	}
@@ -50,9 +117,35 @@ func (p CreateAnalysisRecordParams) Validate() error {
 	if p.ParserVersion == "" {
 		return fmt.Errorf("%w: parser version is required", ErrInvalidInput)
 	}
+	if err := p.PathFilters.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// UpdateAnalysisSourceParams sets what an analysis recorded before its clone
+// analyzed, once the clone resolved the branch, commit and codebase.
+type UpdateAnalysisSourceParams struct {
+	AnalysisID  UUID
+	Branch      string
+	CodebaseID  UUID
+	CommitSHA   string
+	PathFilters *PathFilters
+}
+
+func (p UpdateAnalysisSourceParams) Validate() error {
+	if p.AnalysisID == NilUUID {
+		return fmt.Errorf("%w: analysis ID is required", ErrInvalidInput)
+	}
+	if p.CodebaseID == NilUUID {
+		return fmt.Errorf("%w: codebase ID is required", ErrInvalidInput)
+	}
+	if p.CommitSHA == "" {
+		return fmt.Errorf("%w: commit SHA is required", ErrInvalidInput)
+	}
+	return p.PathFilters.Validate()
+}
+
 type SaveAnalysisInventoryParams struct {
 	AnalysisID  UUID
 	CommittedAt time.Time
@@ -0,0 +1,22 @@
+package analysis
+
+import (
+	"context"
+	"time"
+)
+
+// StatusReport is the state of the latest analysis of a commit.
+type StatusReport struct {
+	AnalysisID   UUID
+	CompletedAt  *time.Time
+	CreatedAt    time.Time
+	ErrorMessage string
+	FailureClass FailureClass // empty unless the analysis failed
+	Status       string       // pending, running, completed or failed
+}
+
+// StatusRepository reads analysis state for callers polling a commit.
+type StatusRepository interface {
+	// GetLatestStatus returns nil without error when the commit was never analyzed.
+	GetLatestStatus(ctx context.Context, owner, repo, commitSHA string) (*StatusReport, error)
+}
@@ -17,3 +17,17 @@ var ErrTokenNotFound = errors.New("oauth token not found")
 type TokenLookup interface {
 	GetOAuthToken(ctx context.Context, userID string, provider string) (string, error)
 }
+
+// ErrAppNotInstalled indicates the VCS app is not installed on the repository.
+// This is the expected condition for most repositories and falls back to the user's OAuth token.
+var ErrAppNotInstalled = errors.New("app not installed on repository")
+
+// InstallationTokenSource mints repository access tokens from a VCS app
+// installation, such as a GitHub App installed on a private repository.
+//
+// Implementations should return:
+//   - ErrAppNotInstalled: when the app cannot access the repository (expected, falls back to OAuth)
+//   - Other errors: minting failures (logged, falls back to OAuth)
+type InstallationTokenSource interface {
+	GetInstallationToken(ctx context.Context, owner, repo string) (string, error)
+}
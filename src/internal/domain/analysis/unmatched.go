@@ -0,0 +1,94 @@
+package analysis
+
+import (
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultUnmatchedSamples is how many unmatched test file candidates an
+// analysis keeps for extending framework support.
+const DefaultUnmatchedSamples = 20
+
+// UnmatchedSample is a test file candidate no parser strategy matched.
+type UnmatchedSample struct {
+	FilePath string
+	Language string
+	Snippet  string // redacted head of the file, empty when snippets are disabled
+}
+
+// UnmatchedSampler keeps a uniform random sample of up to limit paths out of
+// all the unmatched candidates offered to it, however many there are.
+type UnmatchedSampler struct {
+	limit int
+	paths []string
+	seen  int
+}
+
+// NewUnmatchedSampler creates a sampler keeping up to limit paths.
+// A limit of zero or less keeps none.
+func NewUnmatchedSampler(limit int) *UnmatchedSampler {
+	return &UnmatchedSampler{limit: max(limit, 0)}
+}
+
+// Add offers an unmatched candidate to the sample.
+func (s *UnmatchedSampler) Add(filePath string) {
+	if s.limit == 0 || filePath == "" {
+		return
+	}
+	s.seen++
+	if len(s.paths) < s.limit {
+		s.paths = append(s.paths, filePath)
+		return
+	}
+	if i := rand.IntN(s.seen); i < s.limit {
+		s.paths[i] = filePath
+	}
+}
+
+// Paths returns the sampled paths sorted.
+func (s *UnmatchedSampler) Paths() []string {
+	paths := slices.Clone(s.paths)
+	slices.Sort(paths)
+	return paths
+}
+
+const redacted = "[REDACTED]"
+
+var (
+	secretAssignment = regexp.MustCompile(`(?i)\b(\w*(?:passw(?:or)?d|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credential)\w*["']?\s*[:=]+\s*)(["'` + "`" + `]?)[^"'` + "`" + `\s,;)]+`)
+	urlCredentials   = regexp.MustCompile(`(://)[^/\s:@]+:[^/\s@]+@`)
+	emailAddress     = regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)+`)
+	opaqueToken      = regexp.MustCompile(`[A-Za-z0-9+_-]{32,}={0,2}`)
+)
+
+// RedactSnippet returns the first maxLines lines of content with values that
+// may be credentials or personal data replaced: assignments to secret-like
+// names, URL credentials, email addresses and long opaque tokens. Imports and
+// test calls, which tell the framework, are kept.
+func RedactSnippet(content []byte, maxLines int) string {
+	if maxLines <= 0 {
+		return ""
+	}
+	lines := strings.SplitN(string(content), "\n", maxLines+1)
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+	text := strings.ToValidUTF8(strings.Join(lines, "\n"), "")
+
+	text = secretAssignment.ReplaceAllString(text, "${1}${2}"+redacted)
+	text = urlCredentials.ReplaceAllString(text, "${1}"+redacted+"@")
+	text = emailAddress.ReplaceAllString(text, redacted)
+	return opaqueToken.ReplaceAllStringFunc(text, func(token string) string {
+		// Long identifiers are common in tests; tokens mix letters and digits.
+		if strings.ContainsAny(token, "0123456789") && strings.ContainsFunc(token, isLetter) {
+			return redacted
+		}
+		return token
+	})
+}
+
+func isLetter(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
@@ -0,0 +1,96 @@
+package analysis
+
+import "regexp"
+
+// DefaultMinTestVariants is the smallest group of sibling tests sharing a name
+// template that is collapsed into one logical test.
+const DefaultMinTestVariants = 3
+
+// Parameterization patterns test frameworks name variants with. Names only
+// differing elsewhere, such as "returns 404" and "returns 500", are distinct
+// behaviors and never collapse.
+var (
+	// pytest and JUnit parameter IDs, as in test_add[1-2-3].
+	paramIDPattern = regexp.MustCompile(`\[[^\[\]]*\]$`)
+	// JUnit 5 default invocation names, as in "[2] 1, 2, 3".
+	invocationPattern = regexp.MustCompile(`^\[\d+\] .*$`)
+	// Go's suffixes of unnamed or duplicate subtests, as in "#01" and "empty#02".
+	subtestIndexPattern = regexp.MustCompile(`#\d+$`)
+)
+
+// VariantCount returns the number of parameter variants the test stands for.
+func (t Test) VariantCount() int {
+	return max(t.Variants, 1)
+}
+
+// CollapseTestVariants merges sibling tests whose names differ only in a
+// recognized parameterization pattern, such as pytest parametrized IDs, JUnit 5
+// invocation names or Go's numbered subtests, into the first test of the group.
+// The survivor keeps its name and counts the group in Variants. Groups smaller
+// than minVariants, or tests of a different status, are left alone. Returns
+// the number of tests removed.
+func CollapseTestVariants(file *TestFile, minVariants int) int {
+	if file == nil || minVariants < 2 {
+		return 0
+	}
+	removed := 0
+	file.Tests, removed = collapseTests(file.Tests, minVariants)
+	for i := range file.Suites {
+		removed += collapseSuite(&file.Suites[i], minVariants)
+	}
+	return removed
+}
+
+func collapseSuite(suite *TestSuite, minVariants int) int {
+	removed := 0
+	suite.Tests, removed = collapseTests(suite.Tests, minVariants)
+	for i := range suite.Suites {
+		removed += collapseSuite(&suite.Suites[i], minVariants)
+	}
+	return removed
+}
+
+func collapseTests(tests []Test, minVariants int) ([]Test, int) {
+	if len(tests) < minVariants {
+		return tests, 0
+	}
+
+	type group struct {
+		kept    int // index of the survivor in kept; -1 until it is added
+		members int
+	}
+	groups := make(map[string]*group, len(tests))
+	keys := make([]string, len(tests))
+	for i, t := range tests {
+		keys[i] = string(t.Status) + "\x00" + variantKey(t.Name)
+		if g, ok := groups[keys[i]]; ok {
+			g.members++
+		} else {
+			groups[keys[i]] = &group{kept: -1, members: 1}
+		}
+	}
+
+	kept := make([]Test, 0, len(tests))
+	for i, t := range tests {
+		g := groups[keys[i]]
+		switch {
+		case g.members < minVariants:
+			kept = append(kept, t)
+		case g.kept >= 0:
+			kept[g.kept].Variants += t.VariantCount()
+		default:
+			t.Variants = t.VariantCount()
+			g.kept = len(kept)
+			kept = append(kept, t)
+		}
+	}
+	return kept, len(tests) - len(kept)
+}
+
+// variantKey replaces the parameterization pattern of a test name with a
+// placeholder.
+func variantKey(name string) string {
+	key := invocationPattern.ReplaceAllString(name, "[#]")
+	key = paramIDPattern.ReplaceAllString(key, "[]")
+	return subtestIndexPattern.ReplaceAllString(key, "#")
+}
@@ -0,0 +1,126 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func names(tests []Test) []string {
+	out := make([]string, len(tests))
+	for i, t := range tests {
+		out[i] = t.Name
+	}
+	return out
+}
+
+func TestCollapseTestVariants(t *testing.T) {
+	t.Run("collapses parameter variants into the first test", func(t *testing.T) {
+		file := &TestFile{
+			Path: "test_math.py",
+			Tests: []Test{
+				{Name: "test_add[1-2-3]"},
+				{Name: "test_divide"},
+				{Name: "test_add[2-2-4]"},
+				{Name: "test_add[neg-1--1]"},
+			},
+		}
+
+		if removed := CollapseTestVariants(file, 3); removed != 2 {
+			t.Errorf("removed = %d, want 2", removed)
+		}
+		if got := names(file.Tests); !reflect.DeepEqual(got, []string{"test_add[1-2-3]", "test_divide"}) {
+			t.Errorf("tests = %v", got)
+		}
+		if file.Tests[0].VariantCount() != 3 || file.Tests[1].VariantCount() != 1 {
+			t.Errorf("variant counts = %d, %d, want 3, 1", file.Tests[0].VariantCount(), file.Tests[1].VariantCount())
+		}
+	})
+
+	t.Run("collapses nested suites and keeps distinct names", func(t *testing.T) {
+		file := &TestFile{Suites: []TestSuite{{
+			Name: "TestParse",
+			Suites: []TestSuite{{
+				Name: "cases",
+				Tests: []Test{
+					{Name: "(dynamic)"}, {Name: "(dynamic)"}, {Name: "(dynamic)"},
+					{Name: "[1] 2, 2, 4"}, {Name: "[2] 3, 3, 6"}, {Name: "[3] 4, 4, 8"},
+					{Name: "rejects empty input"},
+				},
+			}},
+		}}}
+
+		if removed := CollapseTestVariants(file, DefaultMinTestVariants); removed != 4 {
+			t.Errorf("removed = %d, want 4", removed)
+		}
+		tests := file.Suites[0].Suites[0].Tests
+		if got := names(tests); !reflect.DeepEqual(got, []string{"(dynamic)", "[1] 2, 2, 4", "rejects empty input"}) {
+			t.Errorf("tests = %v", got)
+		}
+	})
+
+	t.Run("leaves small groups and different statuses alone", func(t *testing.T) {
+		file := &TestFile{Tests: []Test{
+			{Name: "case#01"},
+			{Name: "case#02"},
+			{Name: "case#03", Status: TestStatusSkipped},
+		}}
+
+		if removed := CollapseTestVariants(file, 3); removed != 0 {
+			t.Errorf("removed = %d, want 0", removed)
+		}
+	})
+
+	t.Run("keeps names differing outside parameterization patterns", func(t *testing.T) {
+		file := &TestFile{Tests: []Test{
+			{Name: "returns 404"},
+			{Name: "returns 500"},
+			{Name: "returns 503"},
+			{Name: `rejects "admin"`},
+			{Name: `rejects "guest"`},
+			{Name: `rejects "root"`},
+		}}
+
+		if removed := CollapseTestVariants(file, 3); removed != 0 {
+			t.Errorf("removed = %d, want 0: %v", removed, names(file.Tests))
+		}
+	})
+
+	t.Run("adds up variants already collapsed", func(t *testing.T) {
+		file := &TestFile{Tests: []Test{{Name: "case[1]", Variants: 4}, {Name: "case[2]"}}}
+
+		CollapseTestVariants(file, 2)
+		if len(file.Tests) != 1 || file.Tests[0].Variants != 5 {
+			t.Errorf("tests = %+v", file.Tests)
+		}
+	})
+
+	t.Run("is disabled below two variants", func(t *testing.T) {
+		file := &TestFile{Tests: []Test{{Name: "case[1]"}, {Name: "case[2]"}}}
+
+		if removed := CollapseTestVariants(file, 1); removed != 0 || len(file.Tests) != 2 {
+			t.Errorf("expected no collapse, got %+v", file.Tests)
+		}
+	})
+}
+
+func TestVariantKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"test_add[1-2-3]", "test_add[neg-1--1]", true},
+		{"[1] 1, 2, 3", "[12] a, b, c", true},
+		{"#00", "#01", true},
+		{"empty#01", "empty#02", true},
+		{"returns 404", "returns 500", false},
+		{`rejects "admin"`, `rejects "guest"`, false},
+		{"test_add[1]", "test_sub[1]", false},
+		{"empty#01", "full#01", false},
+	}
+
+	for _, tt := range tests {
+		if got := variantKey(tt.a) == variantKey(tt.b); got != tt.same {
+			t.Errorf("variantKey(%q) == variantKey(%q) is %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
@@ -11,13 +11,15 @@ type CommitInfo struct {
 	SHA       string
 }
 
+// VCS accesses remote repositories. An empty branch means the default branch.
 type VCS interface {
-	Clone(ctx context.Context, url string, token *string) (Source, error)
-	// GetHeadCommit returns the HEAD commit info (SHA and visibility) of the default branch.
+	Clone(ctx context.Context, url string, token *string, branch string) (Source, error)
+	// GetHeadCommit returns the HEAD commit info (SHA and visibility) of the branch.
+	// Returns ErrBranchNotFound when the branch does not exist.
 	// It determines visibility by trying unauthenticated access first:
 	// - Success without token = public repository (IsPrivate=false)
 	// - Failure without token, success with token = private repository (IsPrivate=true)
-	GetHeadCommit(ctx context.Context, url string, token *string) (CommitInfo, error)
+	GetHeadCommit(ctx context.Context, url string, token *string, branch string) (CommitInfo, error)
 }
 
 type Source interface {
@@ -32,13 +34,28 @@ type Source interface {
 	VerifyCommitExists(ctx context.Context, sha string) (bool, error)
 }
 
+// FileReader is implemented by sources that can read repository files directly.
+// Returns an error wrapping fs.ErrNotExist when the file is absent.
+type FileReader interface {
+	ReadFile(ctx context.Context, path string, maxBytes int64) ([]byte, error)
+}
+
 type RepoInfo struct {
 	ExternalRepoID string
 	Name           string
 	Owner          string
 }
 
+// RepoStats are hints about a repository available before cloning it.
+type RepoStats struct {
+	Language  string // primary language as detected by the host, empty when unknown
+	SizeBytes int64  // approximate size of the repository's full git history
+}
+
 type VCSAPIClient interface {
 	// Returns ErrRepoNotFound if the repository does not exist.
 	GetRepoInfo(ctx context.Context, host, owner, repo string, token *string) (RepoInfo, error)
+	// GetRepoStats returns size and language hints.
+	// Returns ErrRepoNotFound if the repository does not exist.
+	GetRepoStats(ctx context.Context, host, owner, repo string, token *string) (RepoStats, error)
 }
@@ -0,0 +1,10 @@
+package coverage
+
+import "errors"
+
+var (
+	ErrEmptyReport    = errors.New("coverage report has no source files")
+	ErrInvalidFormat  = errors.New("invalid coverage report format")
+	ErrInvalidInput   = errors.New("invalid input")
+	ErrUploadNotFound = errors.New("coverage report upload not found")
+)
@@ -0,0 +1,41 @@
+package coverage
+
+// Format is the format of an uploaded coverage report.
+type Format string
+
+const (
+	FormatCobertura Format = "cobertura"
+	FormatLCOV      Format = "lcov"
+)
+
+// SourceFile is the line coverage of one source file in a report.
+type SourceFile struct {
+	LinesCovered int
+	LinesValid   int
+	Path         string // as reported; often absolute or module-qualified
+}
+
+// Upload is a coverage report stored for an analysis.
+type Upload struct {
+	AnalysisID string
+	Content    []byte
+	Format     Format
+	ID         string
+}
+
+// TestFile is a test file of the analysis inventory.
+type TestFile struct {
+	ID   string
+	Path string
+}
+
+// Link is a covered source file attributed to the test file that exercises it.
+type Link struct {
+	Source     SourceFile
+	TestFileID string
+}
+
+// Linker attributes the source files of a coverage report to inventory test files.
+type Linker interface {
+	Link(sources []SourceFile, testFiles []TestFile) []Link
+}
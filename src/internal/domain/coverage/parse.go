@@ -0,0 +1,168 @@
+package coverage
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type coberturaReport struct {
+	XMLName  xml.Name `xml:"coverage"`
+	Packages []struct {
+		Classes []coberturaClass `xml:"classes>class"`
+	} `xml:"packages>package"`
+}
+
+type coberturaClass struct {
+	Filename string `xml:"filename,attr"`
+	Lines    []struct {
+		Hits   int64 `xml:"hits,attr"`
+		Number int   `xml:"number,attr"`
+	} `xml:"lines>line"`
+}
+
+// sourceLines collects the lines of a source file that may be reported
+// several times, by several LCOV records or Cobertura classes.
+type sourceLines struct {
+	covered map[int]bool
+	found   int // LCOV LF totals of records without line data
+	hit     int
+	path    string
+}
+
+type collector struct {
+	files []*sourceLines
+	index map[string]int
+}
+
+func (c *collector) file(path string) *sourceLines {
+	if i, ok := c.index[path]; ok {
+		return c.files[i]
+	}
+	if c.index == nil {
+		c.index = make(map[string]int)
+	}
+	f := &sourceLines{covered: make(map[int]bool), path: path}
+	c.index[path] = len(c.files)
+	c.files = append(c.files, f)
+	return f
+}
+
+func (f *sourceLines) line(number int, covered bool) {
+	f.covered[number] = f.covered[number] || covered
+}
+
+func (c *collector) sources() []SourceFile {
+	sources := make([]SourceFile, 0, len(c.files))
+	for _, f := range c.files {
+		source := SourceFile{LinesCovered: f.hit, LinesValid: f.found + len(f.covered), Path: f.path}
+		for _, covered := range f.covered {
+			if covered {
+				source.LinesCovered++
+			}
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// Parse reads a coverage report in the given format and returns its source
+// files in report order. Returns ErrEmptyReport when the report has none.
+func Parse(format Format, r io.Reader) ([]SourceFile, error) {
+	var sources []SourceFile
+	var err error
+	switch format {
+	case FormatCobertura:
+		sources, err = parseCobertura(r)
+	case FormatLCOV:
+		sources, err = parseLCOV(r)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, ErrEmptyReport
+	}
+	return sources, nil
+}
+
+func parseCobertura(r io.Reader) ([]SourceFile, error) {
+	var report coberturaReport
+	if err := xml.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("%w: decode Cobertura XML: %w", ErrInvalidFormat, err)
+	}
+
+	var c collector
+	for _, pkg := range report.Packages {
+		for _, class := range pkg.Classes {
+			if class.Filename == "" {
+				continue
+			}
+			f := c.file(class.Filename)
+			for _, l := range class.Lines {
+				f.line(l.Number, l.Hits > 0)
+			}
+		}
+	}
+	return c.sources(), nil
+}
+
+// parseLCOV reads SF, DA, LF and LH records. Line data (DA) wins over the
+// LF/LH totals of the same record.
+func parseLCOV(r io.Reader) ([]SourceFile, error) {
+	var c collector
+	var current *sourceLines
+	var found, hit int
+	hasLines := false
+
+	endRecord := func() {
+		if current != nil && !hasLines {
+			current.found += found
+			current.hit += min(hit, found)
+		}
+		current, found, hit, hasLines = nil, 0, 0, false
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		key, value, _ := strings.Cut(text, ":")
+		switch key {
+		case "SF":
+			endRecord()
+			current = c.file(value)
+		case "DA":
+			if current == nil {
+				return nil, fmt.Errorf("%w: line %d: DA outside a source file record", ErrInvalidFormat, line)
+			}
+			fields := strings.Split(value, ",")
+			number, numErr := strconv.Atoi(fields[0])
+			if len(fields) < 2 || numErr != nil {
+				return nil, fmt.Errorf("%w: line %d: malformed DA record %q", ErrInvalidFormat, line, text)
+			}
+			// Some tools write fractional hit counts.
+			hits, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: malformed DA record %q", ErrInvalidFormat, line, text)
+			}
+			current.line(number, hits > 0)
+			hasLines = true
+		case "LF":
+			found, _ = strconv.Atoi(value)
+		case "LH":
+			hit, _ = strconv.Atoi(value)
+		case "end_of_record":
+			endRecord()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: read LCOV: %w", ErrInvalidFormat, err)
+	}
+	endRecord()
+	return c.sources(), nil
+}
@@ -0,0 +1,109 @@
+package coverage
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		report  string
+		want    []SourceFile
+		wantErr error
+	}{
+		{
+			name:   "lcov line data",
+			format: FormatLCOV,
+			report: "TN:\nSF:/home/runner/work/app/src/auth.ts\nDA:1,3\nDA:2,0\nDA:3,1.5\nLF:3\nLH:2\nend_of_record\n",
+			want:   []SourceFile{{LinesCovered: 2, LinesValid: 3, Path: "/home/runner/work/app/src/auth.ts"}},
+		},
+		{
+			name:   "lcov records of the same file are merged",
+			format: FormatLCOV,
+			report: "TN:unit\nSF:src/a.js\nDA:1,0\nDA:2,0\nend_of_record\nTN:e2e\nSF:src/a.js\nDA:2,4\nend_of_record\nSF:src/b.js\nLF:10\nLH:4\nend_of_record\n",
+			want: []SourceFile{
+				{LinesCovered: 1, LinesValid: 2, Path: "src/a.js"},
+				{LinesCovered: 4, LinesValid: 10, Path: "src/b.js"},
+			},
+		},
+		{
+			name:    "lcov malformed line data",
+			format:  FormatLCOV,
+			report:  "SF:src/a.js\nDA:one,1\nend_of_record\n",
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "lcov line data without a source file",
+			format:  FormatLCOV,
+			report:  "DA:1,1\n",
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "lcov without records",
+			format:  FormatLCOV,
+			report:  "TN:\n",
+			wantErr: ErrEmptyReport,
+		},
+		{
+			name:   "cobertura classes of the same file are merged",
+			format: FormatCobertura,
+			report: `<?xml version="1.0"?>
+<coverage line-rate="0.5">
+  <sources><source>/src</source></sources>
+  <packages>
+    <package name="auth">
+      <classes>
+        <class name="Login" filename="auth/login.py">
+          <lines><line number="1" hits="1"/><line number="2" hits="0"/></lines>
+        </class>
+        <class name="Login$Inner" filename="auth/login.py">
+          <lines><line number="2" hits="2"/><line number="3" hits="0"/></lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`,
+			want: []SourceFile{{LinesCovered: 2, LinesValid: 3, Path: "auth/login.py"}},
+		},
+		{
+			name:    "cobertura with another root element",
+			format:  FormatCobertura,
+			report:  `<testsuite/>`,
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "cobertura without classes",
+			format:  FormatCobertura,
+			report:  `<coverage><packages/></coverage>`,
+			wantErr: ErrEmptyReport,
+		},
+		{
+			name:    "unsupported format",
+			format:  "jacoco",
+			report:  "<report/>",
+			wantErr: ErrInvalidFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.format, strings.NewReader(tt.report))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package coverage
+
+import "context"
+
+// Repository loads uploaded coverage reports and stores their links.
+type Repository interface {
+	// GetUpload returns ErrUploadNotFound if the upload does not exist.
+	GetUpload(ctx context.Context, uploadID string) (*Upload, error)
+
+	// GetTestFiles returns the test files of an analysis.
+	GetTestFiles(ctx context.Context, analysisID string) ([]TestFile, error)
+
+	// SaveLinks stores the links of an upload, replacing earlier coverage of the
+	// same test and source file, recomputes the coverage of the spec behaviors of
+	// the analysis and marks the upload processed. Returns false without saving
+	// when the upload was already processed.
+	SaveLinks(ctx context.Context, upload *Upload, links []Link) (bool, error)
+}
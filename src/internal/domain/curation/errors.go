@@ -0,0 +1,5 @@
+package curation
+
+import "errors"
+
+var ErrInvalidRules = errors.New("invalid curation rules")
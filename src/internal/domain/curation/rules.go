@@ -0,0 +1,280 @@
+// Package curation defines per-repository rules that steer analysis and spec generation.
+// Rules are read from a .specvital.yml file at the repository root during analysis,
+// stored with the analysis, and applied again when a spec document is generated.
+package curation
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames are the rules file locations checked at the repository root, in order.
+var FileNames = []string{".specvital.yml", ".specvital.yaml"}
+
+// SupportedVersion is the only rules file schema version understood by this worker.
+const SupportedVersion = 1
+
+// Style controls how behavior descriptions are phrased in Phase 2.
+type Style string
+
+const (
+	StyleConcise  Style = "concise"
+	StyleDetailed Style = "detailed"
+)
+
+// maxTaxonomyDomains bounds the taxonomy template so it cannot crowd out the test listing in prompts.
+const maxTaxonomyDomains = 50
+
+// defaultGeneratedPatterns match common code generator output when SkipGenerated is set.
+var defaultGeneratedPatterns = []string{
+	"**/*_gen_test.*",
+	"**/*.generated.*",
+	"**/*_generated_test.*",
+	"**/generated/**",
+	"**/__generated__/**",
+}
+
+// Rules is the parsed rules file.
+type Rules struct {
+	Domains       DomainRules     `json:"domains" yaml:"domains"`
+	Exclude       []string        `json:"exclude,omitempty" yaml:"exclude"`
+	Generated     []string        `json:"generated,omitempty" yaml:"generated"`
+	Generation    GenerationRules `json:"generation" yaml:"generation"`
+	Owners        []OwnerRule     `json:"owners,omitempty" yaml:"owners"`
+	SkipGenerated bool            `json:"skip_generated,omitempty" yaml:"skip_generated"`
+	Version       int             `json:"version" yaml:"version"`
+}
+
+// GenerationRules override spec generation parameters for the repository.
+// Language only replaces the default; an explicit language on the request wins.
+type GenerationRules struct {
+	Language string           `json:"language,omitempty" yaml:"language"`
+	Style    Style            `json:"style,omitempty" yaml:"style"`
+	Taxonomy []TaxonomyDomain `json:"taxonomy,omitempty" yaml:"taxonomy"`
+}
+
+// TaxonomyDomain is a maintainer-defined domain the classifier must reuse when applicable.
+type TaxonomyDomain struct {
+	Description string   `json:"description,omitempty" yaml:"description"`
+	Features    []string `json:"features,omitempty" yaml:"features"`
+	Name        string   `json:"name" yaml:"name"`
+}
+
+// DomainRules adjust the AI classification.
+type DomainRules struct {
+	Force  []ForceRule       `json:"force,omitempty" yaml:"force"`
+	Rename map[string]string `json:"rename,omitempty" yaml:"rename"`
+}
+
+// ForceRule pins matching tests to a domain (and optionally a feature),
+// overriding the AI classification. Path matches test file paths and Suite
+// matches "Outer > Inner" suite paths; a rule with both requires both.
+type ForceRule struct {
+	Domain  string `json:"domain" yaml:"domain"`
+	Feature string `json:"feature,omitempty" yaml:"feature"`
+	Path    string `json:"path,omitempty" yaml:"path"`
+	Suite   string `json:"suite,omitempty" yaml:"suite"`
+}
+
+// OwnerRule assigns the test files matching Paths to a team.
+// A file matched by several rules belongs to every matching team.
+type OwnerRule struct {
+	Paths []string `json:"paths" yaml:"paths"`
+	Team  string   `json:"team" yaml:"team"`
+}
+
+// Parse decodes and validates a rules file. Unknown keys are rejected so typos surface.
+func Parse(data []byte) (*Rules, error) {
+	var rules Rules
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRules, err)
+	}
+	if rules.Version == 0 {
+		rules.Version = SupportedVersion
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// Validate checks version, glob syntax, and force rule completeness.
+func (r *Rules) Validate() error {
+	if r.Version != SupportedVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidRules, r.Version)
+	}
+	for _, p := range append(append([]string{}, r.Exclude...), r.Generated...) {
+		if !doublestar.ValidatePattern(p) {
+			return fmt.Errorf("%w: invalid glob %q", ErrInvalidRules, p)
+		}
+	}
+	switch r.Generation.Style {
+	case "", StyleConcise, StyleDetailed:
+	default:
+		return fmt.Errorf("%w: unsupported style %q", ErrInvalidRules, r.Generation.Style)
+	}
+	if len(r.Generation.Taxonomy) > maxTaxonomyDomains {
+		return fmt.Errorf("%w: taxonomy exceeds %d domains", ErrInvalidRules, maxTaxonomyDomains)
+	}
+	for i, d := range r.Generation.Taxonomy {
+		if strings.TrimSpace(d.Name) == "" {
+			return fmt.Errorf("%w: taxonomy domain %d has no name", ErrInvalidRules, i)
+		}
+	}
+	for from, to := range r.Domains.Rename {
+		if strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+			return fmt.Errorf("%w: rename entries need both names", ErrInvalidRules)
+		}
+	}
+	for i, o := range r.Owners {
+		if strings.TrimSpace(o.Team) == "" {
+			return fmt.Errorf("%w: owner rule %d has no team", ErrInvalidRules, i)
+		}
+		if len(o.Paths) == 0 {
+			return fmt.Errorf("%w: owner rule %d has no paths", ErrInvalidRules, i)
+		}
+		for _, p := range o.Paths {
+			if !doublestar.ValidatePattern(p) {
+				return fmt.Errorf("%w: owner rule %d has invalid path glob %q", ErrInvalidRules, i, p)
+			}
+		}
+	}
+	for i, f := range r.Domains.Force {
+		if strings.TrimSpace(f.Domain) == "" {
+			return fmt.Errorf("%w: force rule %d has no domain", ErrInvalidRules, i)
+		}
+		if f.Path == "" && f.Suite == "" {
+			return fmt.Errorf("%w: force rule %d needs path or suite", ErrInvalidRules, i)
+		}
+		if f.Path != "" && !doublestar.ValidatePattern(f.Path) {
+			return fmt.Errorf("%w: force rule %d has invalid path glob %q", ErrInvalidRules, i, f.Path)
+		}
+		if f.Suite != "" && !doublestar.ValidatePattern(f.Suite) {
+			return fmt.Errorf("%w: force rule %d has invalid suite glob %q", ErrInvalidRules, i, f.Suite)
+		}
+	}
+	return nil
+}
+
+// IsEmpty reports whether the rules change nothing.
+func (r *Rules) IsEmpty() bool {
+	return r == nil || (len(r.Exclude) == 0 && !r.SkipGenerated &&
+		len(r.Domains.Force) == 0 && len(r.Domains.Rename) == 0 &&
+		r.Generation.Language == "" && r.Generation.Style == "" && len(r.Generation.Taxonomy) == 0 &&
+		len(r.Owners) == 0)
+}
+
+// ExcludesFile reports whether a test file should be dropped during analysis.
+func (r *Rules) ExcludesFile(filePath string) bool {
+	if r == nil {
+		return false
+	}
+	if matchAny(r.Exclude, filePath) {
+		return true
+	}
+	if r.SkipGenerated {
+		return matchAny(defaultGeneratedPatterns, filePath) || matchAny(r.Generated, filePath)
+	}
+	return false
+}
+
+// RenameDomain returns the configured replacement name, or name unchanged.
+func (r *Rules) RenameDomain(name string) string {
+	if r == nil {
+		return name
+	}
+	if renamed, ok := r.Domains.Rename[name]; ok {
+		return renamed
+	}
+	return name
+}
+
+// Language returns the repository default spec language, or "" when unset.
+func (r *Rules) Language() string {
+	if r == nil {
+		return ""
+	}
+	return strings.TrimSpace(r.Generation.Language)
+}
+
+// Style returns the configured description style, or "" for the default.
+func (r *Rules) Style() Style {
+	if r == nil {
+		return ""
+	}
+	return r.Generation.Style
+}
+
+// Taxonomy returns the taxonomy template, or nil.
+func (r *Rules) Taxonomy() []TaxonomyDomain {
+	if r == nil {
+		return nil
+	}
+	return r.Generation.Taxonomy
+}
+
+// ForcedPlacement returns the first force rule matching the test location.
+func (r *Rules) ForcedPlacement(filePath, suitePath string) (ForceRule, bool) {
+	if r == nil {
+		return ForceRule{}, false
+	}
+	for _, f := range r.Domains.Force {
+		if f.Path != "" && !match(f.Path, filePath) {
+			continue
+		}
+		if f.Suite != "" && !match(f.Suite, suitePath) {
+			continue
+		}
+		return f, true
+	}
+	return ForceRule{}, false
+}
+
+// Teams returns the teams named by owner rules, in declaration order without duplicates.
+func (r *Rules) Teams() []string {
+	if r == nil {
+		return nil
+	}
+	var teams []string
+	seen := make(map[string]bool, len(r.Owners))
+	for _, o := range r.Owners {
+		if !seen[o.Team] {
+			seen[o.Team] = true
+			teams = append(teams, o.Team)
+		}
+	}
+	return teams
+}
+
+// OwnsFile reports whether any owner rule for team matches the test file.
+func (r *Rules) OwnsFile(team, filePath string) bool {
+	if r == nil {
+		return false
+	}
+	for _, o := range r.Owners {
+		if o.Team == team && matchAny(o.Paths, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if match(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func match(pattern, name string) bool {
+	ok, err := doublestar.Match(pattern, name)
+	return err == nil && ok
+}
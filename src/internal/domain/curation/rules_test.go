@@ -0,0 +1,193 @@
+package curation
+
+import (
+	"errors"
+	"testing"
+)
+
+const sampleRules = `
+version: 1
+exclude:
+  - "e2e/**"
+skip_generated: true
+generated:
+  - "**/mocks/**"
+domains:
+  rename:
+    Auth: Authentication
+  force:
+    - path: "pkg/billing/**"
+      suite: "Refund*"
+      domain: Billing
+      feature: Refunds
+    - path: "pkg/billing/**"
+      domain: Billing
+`
+
+func TestParse(t *testing.T) {
+	t.Run("valid rules", func(t *testing.T) {
+		rules, err := Parse([]byte(sampleRules))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rules.Exclude) != 1 || !rules.SkipGenerated || len(rules.Domains.Force) != 2 {
+			t.Errorf("unexpected rules: %+v", rules)
+		}
+	})
+
+	t.Run("version defaults to 1", func(t *testing.T) {
+		rules, err := Parse([]byte("exclude: [\"a/**\"]"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rules.Version != SupportedVersion {
+			t.Errorf("Version = %d, want %d", rules.Version, SupportedVersion)
+		}
+	})
+
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"unknown key", "excludes: [\"a\"]"},
+		{"unsupported version", "version: 2"},
+		{"invalid glob", "exclude: [\"a/[\"]"},
+		{"force without domain", "domains:\n  force:\n    - path: a/**"},
+		{"force without matcher", "domains:\n  force:\n    - domain: X"},
+		{"empty rename target", "domains:\n  rename:\n    A: \"\""},
+		{"malformed yaml", "exclude: ["},
+		{"unknown style", "generation:\n  style: verbose"},
+		{"taxonomy without name", "generation:\n  taxonomy:\n    - description: x"},
+		{"owner without team", "owners:\n  - paths: [\"a/**\"]"},
+		{"owner without paths", "owners:\n  - team: payments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.yaml)); !errors.Is(err, ErrInvalidRules) {
+				t.Errorf("expected ErrInvalidRules, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRules_ExcludesFile(t *testing.T) {
+	rules, _ := Parse([]byte(sampleRules))
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"e2e/login.spec.ts", true},
+		{"pkg/api/client_gen_test.go", true},
+		{"src/__generated__/types.test.ts", true},
+		{"internal/mocks/repo_test.go", true},
+		{"pkg/api/client_test.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := rules.ExcludesFile(tt.path); got != tt.want {
+				t.Errorf("ExcludesFile(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("generated patterns ignored without skip_generated", func(t *testing.T) {
+		r := &Rules{Version: 1}
+		if r.ExcludesFile("pkg/api/client_gen_test.go") {
+			t.Error("expected generated file to be kept")
+		}
+	})
+
+	t.Run("nil rules exclude nothing", func(t *testing.T) {
+		var r *Rules
+		if r.ExcludesFile("e2e/a.ts") || !r.IsEmpty() {
+			t.Error("expected nil rules to be a no-op")
+		}
+	})
+}
+
+func TestRules_Domains(t *testing.T) {
+	rules, _ := Parse([]byte(sampleRules))
+
+	if got := rules.RenameDomain("Auth"); got != "Authentication" {
+		t.Errorf("RenameDomain(Auth) = %q", got)
+	}
+	if got := rules.RenameDomain("Payments"); got != "Payments" {
+		t.Errorf("RenameDomain(Payments) = %q", got)
+	}
+
+	f, ok := rules.ForcedPlacement("pkg/billing/refund_test.go", "RefundSuite")
+	if !ok || f.Feature != "Refunds" {
+		t.Errorf("expected first rule to match, got %+v %v", f, ok)
+	}
+
+	f, ok = rules.ForcedPlacement("pkg/billing/invoice_test.go", "InvoiceSuite")
+	if !ok || f.Feature != "" || f.Domain != "Billing" {
+		t.Errorf("expected path-only rule to match, got %+v %v", f, ok)
+	}
+
+	if _, ok := rules.ForcedPlacement("pkg/auth/login_test.go", "Login"); ok {
+		t.Error("expected no placement outside billing")
+	}
+}
+
+func TestRules_Generation(t *testing.T) {
+	rules, err := Parse([]byte(`
+generation:
+  language: Korean
+  style: concise
+  taxonomy:
+    - name: Billing
+      description: Invoices and refunds
+      features: [Refunds, Invoices]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rules.IsEmpty() {
+		t.Error("expected generation-only rules not to be empty")
+	}
+	if rules.Language() != "Korean" || rules.Style() != StyleConcise {
+		t.Errorf("unexpected generation rules: %+v", rules.Generation)
+	}
+	if tax := rules.Taxonomy(); len(tax) != 1 || len(tax[0].Features) != 2 {
+		t.Errorf("unexpected taxonomy: %+v", tax)
+	}
+
+	var nilRules *Rules
+	if nilRules.Language() != "" || nilRules.Style() != "" || nilRules.Taxonomy() != nil {
+		t.Error("expected nil rules to have no generation overrides")
+	}
+}
+
+func TestRules_Owners(t *testing.T) {
+	rules, err := Parse([]byte(`
+owners:
+  - team: payments
+    paths: ["pkg/billing/**"]
+  - team: identity
+    paths: ["pkg/auth/**", "pkg/session/**"]
+  - team: payments
+    paths: ["pkg/checkout/**"]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rules.IsEmpty() {
+		t.Error("expected owner-only rules not to be empty")
+	}
+	if teams := rules.Teams(); len(teams) != 2 || teams[0] != "payments" || teams[1] != "identity" {
+		t.Errorf("Teams() = %v, want [payments identity]", teams)
+	}
+	if !rules.OwnsFile("payments", "pkg/checkout/cart_test.go") || !rules.OwnsFile("identity", "pkg/session/store_test.go") {
+		t.Error("expected owner rules to match their paths")
+	}
+	if rules.OwnsFile("identity", "pkg/billing/refund_test.go") {
+		t.Error("expected a team not to own another team's files")
+	}
+
+	var nilRules *Rules
+	if nilRules.Teams() != nil || nilRules.OwnsFile("payments", "a") {
+		t.Error("expected nil rules to have no owners")
+	}
+}
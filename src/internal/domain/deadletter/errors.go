@@ -0,0 +1,7 @@
+package deadletter
+
+import "errors"
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+)
@@ -0,0 +1,50 @@
+// Package deadletter models jobs that River discarded after exhausting their attempts.
+package deadletter
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 1000
+)
+
+// Job is a discarded job joined with the analysis it refers to.
+// Analysis fields are empty when no analysis matches the job args.
+type Job struct {
+	AnalysisID     string
+	AnalysisStatus string
+	Attempt        int
+	CommitSHA      string
+	DiscardedAt    time.Time
+	ID             int64
+	Kind           string
+	LastError      string
+	MaxAttempts    int
+	Owner          string
+	Repo           string
+}
+
+// Filter selects discarded jobs, newest first.
+type Filter struct {
+	DiscardedAfter time.Time // zero means no lower bound
+	JobIDs         []int64   // empty means any job
+	Kinds          []string
+	Limit          int // 0 means DefaultLimit
+}
+
+// Normalize applies defaults and validates the filter.
+func (f Filter) Normalize() (Filter, error) {
+	if len(f.Kinds) == 0 {
+		return f, fmt.Errorf("%w: at least one job kind is required", ErrInvalidInput)
+	}
+	if f.Limit < 0 || f.Limit > MaxLimit {
+		return f, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidInput, MaxLimit)
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultLimit
+	}
+	return f, nil
+}
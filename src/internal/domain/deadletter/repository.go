@@ -0,0 +1,15 @@
+package deadletter
+
+import "context"
+
+// Repository queries discarded jobs.
+type Repository interface {
+	// ListDiscardedJobs returns discarded jobs matching the filter, newest first.
+	ListDiscardedJobs(ctx context.Context, filter Filter) ([]Job, error)
+}
+
+// Requeuer makes a discarded job available again.
+type Requeuer interface {
+	// RequeueJob schedules the job to run now. Attempts are extended if exhausted.
+	RequeueJob(ctx context.Context, jobID int64) error
+}
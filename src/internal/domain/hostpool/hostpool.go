@@ -0,0 +1,130 @@
+// Package hostpool partitions analysis by VCS host. The repositories of a
+// host with a pool are analyzed from the pool's own queues, with their own
+// workers and clone limit, so a slow host cannot occupy every worker slot.
+package hostpool
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultHost is the host of repositories whose host is unset.
+	DefaultHost = "github.com"
+
+	maxNameLength = 32
+)
+
+var (
+	ErrInvalidPool = errors.New("invalid host pool")
+
+	// Must also be a valid River queue name suffix.
+	namePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	hostPattern = regexp.MustCompile(`^[a-z0-9]+([.-][a-z0-9]+)*$`)
+)
+
+// Pool routes the analyses of one host to queues named after the pool.
+type Pool struct {
+	Host                string
+	MaxConcurrentClones int64 // clones of the host running at once; zero uses the analyzer default
+	Name                string
+	Workers             int // workers of each of the pool's queues; zero uses the analyzer default
+}
+
+// Validate checks that a pool name is usable as a queue suffix, e.g. "ghe".
+func Validate(name string) error {
+	if len(name) > maxNameLength || !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: name %q must be lowercase letters, digits and dashes (max %d)", ErrInvalidPool, name, maxNameLength)
+	}
+	return nil
+}
+
+// ValidateHost checks that host is a bare lowercase host name, e.g. "github.example.com".
+func ValidateHost(host string) error {
+	if !hostPattern.MatchString(host) {
+		return fmt.Errorf("%w: host %q must be a lowercase host name without scheme, port or path", ErrInvalidPool, host)
+	}
+	return nil
+}
+
+// QueueName scopes a base queue name to a pool.
+// An empty pool keeps the unscoped name used by hosts without a pool.
+func QueueName(base, pool string) string {
+	if pool == "" {
+		return base
+	}
+	return base + "_" + pool
+}
+
+// ForHost returns the name of the pool of host, or "" when it has none.
+// An empty host is DefaultHost.
+func ForHost(pools []Pool, host string) string {
+	host = cmp.Or(host, DefaultHost)
+	for _, p := range pools {
+		if strings.EqualFold(p.Host, host) {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// Parse reads a pool spec: comma-separated "<name>=<host>[:<workers>[:<clones>]]"
+// entries, e.g. "ghe=github.example.com:10:1".
+func Parse(spec string) ([]Pool, error) {
+	var pools []Pool
+	names := make(map[string]bool)
+	hosts := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: entry %q must be <name>=<host>[:<workers>[:<clones>]]", ErrInvalidPool, entry)
+		}
+		fields := strings.Split(rest, ":")
+		if len(fields) > 3 {
+			return nil, fmt.Errorf("%w: entry %q has too many fields", ErrInvalidPool, entry)
+		}
+		p := Pool{Host: strings.ToLower(fields[0]), Name: name}
+		if err := Validate(p.Name); err != nil {
+			return nil, err
+		}
+		if err := ValidateHost(p.Host); err != nil {
+			return nil, err
+		}
+		if len(fields) > 1 {
+			n, err := parseLimit(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: workers of %q: %w", ErrInvalidPool, name, err)
+			}
+			p.Workers = int(n)
+		}
+		if len(fields) > 2 {
+			n, err := parseLimit(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("%w: clones of %q: %w", ErrInvalidPool, name, err)
+			}
+			p.MaxConcurrentClones = n
+		}
+		if names[p.Name] || hosts[p.Host] {
+			return nil, fmt.Errorf("%w: %q repeats a pool name or host", ErrInvalidPool, entry)
+		}
+		names[p.Name], hosts[p.Host] = true, true
+		pools = append(pools, p)
+	}
+	return pools, nil
+}
+
+func parseLimit(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q is not a positive number", s)
+	}
+	return n, nil
+}
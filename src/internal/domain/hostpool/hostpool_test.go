@@ -0,0 +1,69 @@
+package hostpool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	pools, err := Parse("ghe=GitHub.Example.com:10:1, lab=git.lab.example")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Pool{
+		{Host: "github.example.com", MaxConcurrentClones: 1, Name: "ghe", Workers: 10},
+		{Host: "git.lab.example", Name: "lab"},
+	}
+	if len(pools) != len(want) {
+		t.Fatalf("got %+v, want %+v", pools, want)
+	}
+	for i := range want {
+		if pools[i] != want[i] {
+			t.Errorf("pool %d = %+v, want %+v", i, pools[i], want[i])
+		}
+	}
+
+	if pools, err := Parse(""); err != nil || len(pools) != 0 {
+		t.Errorf("empty spec = %+v, %v; want no pools", pools, err)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"github.example.com",
+		"GHE=github.example.com",
+		"ghe_1=github.example.com",
+		"ghe=https://github.example.com",
+		"ghe=github.example.com:0",
+		"ghe=github.example.com:10:two",
+		"ghe=github.example.com:10:1:1",
+		"ghe=a.example,ghe=b.example",
+		"a=github.example.com,b=github.example.com",
+	} {
+		if _, err := Parse(spec); !errors.Is(err, ErrInvalidPool) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidPool", spec, err)
+		}
+	}
+}
+
+func TestQueueName(t *testing.T) {
+	if got := QueueName("analysis_default", ""); got != "analysis_default" {
+		t.Errorf("expected unscoped name, got %q", got)
+	}
+	if got := QueueName("analysis_default", "ghe"); got != "analysis_default_ghe" {
+		t.Errorf("expected pool suffix, got %q", got)
+	}
+}
+
+func TestForHost(t *testing.T) {
+	pools := []Pool{{Host: "github.example.com", Name: "ghe"}}
+	if got := ForHost(pools, "GitHub.example.com"); got != "ghe" {
+		t.Errorf("ForHost = %q, want ghe", got)
+	}
+	if got := ForHost(pools, "github.com"); got != "" {
+		t.Errorf("ForHost(github.com) = %q, want no pool", got)
+	}
+	if got := ForHost([]Pool{{Host: DefaultHost, Name: "dotcom"}}, ""); got != "dotcom" {
+		t.Errorf("ForHost(\"\") = %q, want the pool of %s", got, DefaultHost)
+	}
+}
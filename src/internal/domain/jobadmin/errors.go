@@ -0,0 +1,9 @@
+package jobadmin
+
+import "errors"
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrJobStarted   = errors.New("job already started")
+	ErrNotFound     = errors.New("job not found")
+)
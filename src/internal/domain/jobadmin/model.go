@@ -0,0 +1,102 @@
+// Package jobadmin models River jobs as operators inspect and change them.
+package jobadmin
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 1000
+)
+
+// River priorities run from 1, picked first, to 4.
+const (
+	HighestPriority = 1
+	LowestPriority  = 4
+)
+
+// StateSnoozed selects scheduled jobs that River snoozed at least once. It
+// is not a River state: River stores a snoozed job as scheduled and counts
+// its snoozes in the job metadata.
+const StateSnoozed = "snoozed"
+
+var states = []string{
+	"available", "cancelled", "completed", "discarded", "pending",
+	"retryable", "running", "scheduled", StateSnoozed,
+}
+
+// Job is a River job row. Metadata holds every key of river_job.metadata,
+// e.g. the worker identity recorded by the attribution middleware.
+type Job struct {
+	Args        json.RawMessage
+	Attempt     int
+	AttemptedAt time.Time // zero until the first attempt
+	AttemptedBy []string
+	CreatedAt   time.Time
+	Errors      []AttemptError
+	FinalizedAt time.Time // zero until the job is finalized
+	ID          int64
+	Kind        string
+	MaxAttempts int
+	Metadata    map[string]json.RawMessage
+	Priority    int
+	Queue       string
+	ScheduledAt time.Time
+	Snoozes     int
+	State       string
+	Tags        []string
+}
+
+// LastError returns the error of the latest failed attempt, or "".
+func (j Job) LastError() string {
+	if len(j.Errors) == 0 {
+		return ""
+	}
+	return j.Errors[len(j.Errors)-1].Error
+}
+
+// AttemptError is one entry of river_job.errors.
+type AttemptError struct {
+	At      time.Time `json:"at"`
+	Attempt int       `json:"attempt"`
+	Error   string    `json:"error"`
+}
+
+// Filter selects jobs, newest first.
+type Filter struct {
+	Kinds  []string // empty means any kind
+	Limit  int      // 0 means DefaultLimit
+	Queues []string // empty means any queue
+	States []string
+}
+
+// Normalize applies defaults and validates the filter.
+func (f Filter) Normalize() (Filter, error) {
+	if len(f.States) == 0 {
+		return f, fmt.Errorf("%w: at least one job state is required", ErrInvalidInput)
+	}
+	for _, state := range f.States {
+		if !slices.Contains(states, state) {
+			return f, fmt.Errorf("%w: unknown job state %q", ErrInvalidInput, state)
+		}
+	}
+	if f.Limit < 0 || f.Limit > MaxLimit {
+		return f, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidInput, MaxLimit)
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultLimit
+	}
+	return f, nil
+}
+
+// ValidatePriority reports whether p is a River priority.
+func ValidatePriority(p int) error {
+	if p < HighestPriority || p > LowestPriority {
+		return fmt.Errorf("%w: priority must be between %d and %d", ErrInvalidInput, HighestPriority, LowestPriority)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package jobadmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFilter_Normalize(t *testing.T) {
+	f, err := Filter{States: []string{"running", StateSnoozed}}.Normalize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Limit != DefaultLimit {
+		t.Errorf("expected the default limit, got %d", f.Limit)
+	}
+
+	for name, filter := range map[string]Filter{
+		"no states":     {},
+		"unknown state": {States: []string{"stuck"}},
+		"limit too big": {States: []string{"running"}, Limit: MaxLimit + 1},
+	} {
+		if _, err := filter.Normalize(); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: expected ErrInvalidInput, got %v", name, err)
+		}
+	}
+}
+
+func TestValidatePriority(t *testing.T) {
+	for _, p := range []int{HighestPriority, LowestPriority} {
+		if err := ValidatePriority(p); err != nil {
+			t.Errorf("ValidatePriority(%d) = %v", p, err)
+		}
+	}
+	for _, p := range []int{0, 5} {
+		if err := ValidatePriority(p); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("ValidatePriority(%d) = %v, want ErrInvalidInput", p, err)
+		}
+	}
+}
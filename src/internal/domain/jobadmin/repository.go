@@ -0,0 +1,14 @@
+package jobadmin
+
+import "context"
+
+// Repository reads River jobs and changes what River has no client API for.
+type Repository interface {
+	// ListJobs returns jobs matching the filter, newest first.
+	ListJobs(ctx context.Context, filter Filter) ([]Job, error)
+	// GetJob returns ErrNotFound for an unknown ID.
+	GetJob(ctx context.Context, id int64) (*Job, error)
+	// SetPriority changes the priority of a job that has not started yet.
+	// It returns ErrJobStarted once the job is running or finalized.
+	SetPriority(ctx context.Context, id int64, priority int) error
+}
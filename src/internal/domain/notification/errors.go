@@ -0,0 +1,8 @@
+package notification
+
+import "errors"
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrNotFound     = errors.New("notification channel not found")
+)
@@ -0,0 +1,127 @@
+// Package notification defines the channels told about finished analysis and
+// spec document jobs, and the outcomes they are told.
+package notification
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"time"
+)
+
+// ChannelKind selects the payload a channel is sent.
+type ChannelKind string
+
+const (
+	ChannelDiscord ChannelKind = "discord" // Discord webhook, one embed per outcome
+	ChannelSlack   ChannelKind = "slack"   // Slack incoming webhook, one text message per outcome
+	ChannelWebhook ChannelKind = "webhook" // any endpoint accepting the outcome as JSON
+)
+
+// ParseChannelKind validates a channel kind.
+func ParseChannelKind(s string) (ChannelKind, error) {
+	switch kind := ChannelKind(s); kind {
+	case ChannelDiscord, ChannelSlack, ChannelWebhook:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("%w: unknown channel kind %q (want discord, slack or webhook)", ErrInvalidInput, s)
+	}
+}
+
+// Channel is a destination for the outcomes of one codebase or one user.
+// Exactly one of CodebaseID and UserID is set.
+type Channel struct {
+	CodebaseID string
+	CreatedAt  time.Time
+	ID         string
+	Kind       ChannelKind
+	OnFailure  bool
+	OnSuccess  bool
+	URL        string
+	UserID     string
+}
+
+// Validate checks a channel before it is stored.
+func (c Channel) Validate() error {
+	if (c.CodebaseID == "") == (c.UserID == "") {
+		return fmt.Errorf("%w: a channel belongs to either a codebase or a user", ErrInvalidInput)
+	}
+	if _, err := ParseChannelKind(string(c.Kind)); err != nil {
+		return err
+	}
+	if !c.OnSuccess && !c.OnFailure {
+		return fmt.Errorf("%w: a channel must be notified on success, failure or both", ErrInvalidInput)
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: channel URL %q is not an https URL", ErrInvalidInput, c.URL)
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && !IsPublicAddr(addr) {
+		return fmt.Errorf("%w: channel URL %q points to a non-public address", ErrInvalidInput, c.URL)
+	}
+	return nil
+}
+
+// cgnat is the shared address space of carrier-grade NAT (RFC 6598), which
+// clouds also use for internal services.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublicAddr reports whether channels may be sent to addr. Channel URLs are
+// user input posted to from inside the cluster, so loopback, private,
+// link-local (including cloud metadata at 169.254.169.254), unspecified and
+// multicast addresses are refused.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!cgnat.Contains(addr)
+}
+
+// Subject is what a finished job produced.
+type Subject string
+
+const (
+	SubjectAnalysis     Subject = "analysis"
+	SubjectSpecDocument Subject = "spec_document"
+)
+
+// Outcome is a finished job as channels are told about it. The job fills in
+// what its args and result carry; Repository.ResolveOutcome adds the rest.
+type Outcome struct {
+	AnalysisID  string
+	Branch      string
+	CodebaseID  string
+	CommitSHA   string
+	DocumentID  string // empty unless a spec document was saved
+	DomainCount int    // spec documents only
+	Duration    time.Duration
+	ErrorClass  string // empty on success
+	Host        string
+	JobID       int64
+	Owner       string
+	Repo        string
+	Subject     Subject
+	TestCount   int // analyses only
+	UserID      string
+}
+
+// Failed reports whether the job failed for good.
+func (o Outcome) Failed() bool {
+	return o.ErrorClass != ""
+}
+
+// RepoLabel is the repository as "owner/repo", prefixed with its host
+// unless it is github.com.
+func (o Outcome) RepoLabel() string {
+	label := o.Owner + "/" + o.Repo
+	if o.Host != "" && o.Host != "github.com" {
+		label = o.Host + "/" + label
+	}
+	return label
+}
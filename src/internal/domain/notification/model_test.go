@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestChannel_Validate(t *testing.T) {
+	valid := Channel{Kind: ChannelSlack, OnFailure: true, URL: "https://hooks.slack.com/services/T0/B0/x", UserID: "u1"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("unexpected error for a valid channel: %v", err)
+	}
+
+	for name, url := range map[string]string{
+		"http":             "http://hooks.example.com/hook",
+		"loopback":         "http://127.0.0.1/hook",
+		"metadata":         "http://169.254.169.254/latest/meta-data/",
+		"https loopback":   "https://127.0.0.1/hook",
+		"https metadata":   "https://169.254.169.254/latest/meta-data/",
+		"https private":    "https://10.1.2.3/hook",
+		"https ipv6 local": "https://[::1]/hook",
+		"no host":          "https:///hook",
+	} {
+		channel := valid
+		channel.URL = url
+		if err := channel.Validate(); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: expected ErrInvalidInput, got %v", name, err)
+		}
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.215.14", true},
+		{"2606:2800:21f:cb07:6820:80da:af6b:8b2c", true},
+		{"127.0.0.1", false},
+		{"10.0.0.1", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"224.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("IsPublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package notification
+
+import "context"
+
+// Repository stores channels and resolves what outcomes report.
+type Repository interface {
+	CreateChannel(ctx context.Context, channel Channel) (*Channel, error)
+	DeleteChannel(ctx context.Context, id string) error
+	GetChannel(ctx context.Context, id string) (*Channel, error)
+	// ListChannels lists the channels of a codebase, of a user, or all
+	// channels when both are empty.
+	ListChannels(ctx context.Context, codebaseID, userID string) ([]Channel, error)
+	// ChannelsFor returns the channels of the outcome's codebase and user that
+	// want outcomes like it.
+	ChannelsFor(ctx context.Context, outcome Outcome) ([]Channel, error)
+	// ResolveOutcome fills in the codebase, branch and counts of an outcome.
+	// An outcome whose codebase is unknown is returned as is.
+	ResolveOutcome(ctx context.Context, outcome Outcome) (Outcome, error)
+}
+
+// Sender delivers an outcome to one channel.
+type Sender interface {
+	Send(ctx context.Context, channel Channel, outcome Outcome) error
+}
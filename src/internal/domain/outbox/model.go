@@ -0,0 +1,48 @@
+// Package outbox defines the events written in the same transaction as the
+// state change they announce, and the sinks a relay publishes them to.
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event types. Consumers rely on them; do not rename.
+const (
+	EventAnalysisCompleted     = "analysis.completed"
+	EventSpecDocumentCompleted = "spec_document.completed"
+)
+
+const (
+	// DefaultMaxAttempts is how often an event is offered to the sink before
+	// the relay gives up on it.
+	DefaultMaxAttempts = 20
+
+	initialBackoff = 10 * time.Second
+	maxBackoff     = time.Hour
+)
+
+// Event is a stored outbox event. Delivery is at least once: a relay that
+// publishes an event but fails to record it publishes it again, so consumers
+// deduplicate by ID.
+type Event struct {
+	AggregateID string // analysis or spec document the event is about
+	Attempts    int    // publish attempts, including the current one
+	CreatedAt   time.Time
+	ID          string
+	Payload     json.RawMessage
+	Type        string
+}
+
+// Backoff is the wait before retrying an event whose attempt-th publish
+// failed: 10s doubling per attempt, capped at an hour.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	backoff := initialBackoff
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
@@ -0,0 +1,26 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// Repository hands stored events to relays and records the outcome.
+type Repository interface {
+	// ClaimEvents leases up to limit due events with fewer than maxAttempts
+	// attempts until leaseUntil and counts the attempt.
+	ClaimEvents(ctx context.Context, limit, maxAttempts int, leaseUntil time.Time) ([]Event, error)
+	// MarkPublished records that the sink accepted the event.
+	MarkPublished(ctx context.Context, id string) error
+	// MarkFailed schedules the next attempt of the event.
+	MarkFailed(ctx context.Context, id string, nextAttemptAt time.Time, errMessage string) error
+	// DeleteSettled deletes up to limit events published before the cutoff,
+	// or created before it and out of attempts.
+	DeleteSettled(ctx context.Context, before time.Time, maxAttempts, limit int) (int, error)
+}
+
+// Sink publishes events to downstream systems.
+type Sink interface {
+	// Publish returns nil once the sink has accepted the event.
+	Publish(ctx context.Context, event Event) error
+}
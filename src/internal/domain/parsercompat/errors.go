@@ -0,0 +1,7 @@
+package parsercompat
+
+import "errors"
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+)
@@ -0,0 +1,122 @@
+// Package parsercompat compares test inventories produced by two core parser
+// versions so an upgrade cannot silently change test counts.
+package parsercompat
+
+import (
+	"sort"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/curation"
+)
+
+// Status is the outcome of validating one sampled repository.
+type Status string
+
+const (
+	StatusMatch    Status = "match"
+	StatusMismatch Status = "mismatch"
+	StatusSkipped  Status = "skipped" // baseline commit is no longer HEAD
+	StatusFailed   Status = "failed"  // clone or parse error
+)
+
+// Sample is a stored analysis parsed by an older parser version.
+type Sample struct {
+	AnalysisID    analysis.UUID
+	CommitSHA     string
+	Owner         string
+	ParserVersion string
+	Repo          string
+	Rules         *curation.Rules // applied to the candidate inventory, as during analysis
+}
+
+// FileDiff is a file whose test count differs between parsers.
+// A count of 0 means the file is missing on that side.
+type FileDiff struct {
+	Baseline  int    `json:"baseline"`
+	Candidate int    `json:"candidate"`
+	Path      string `json:"path"`
+}
+
+// Result is the comparison for one sample.
+type Result struct {
+	BaselineTests  int        `json:"baseline_tests"`
+	CandidateTests int        `json:"candidate_tests"`
+	FileDiffs      []FileDiff `json:"file_diffs,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+	Sample         Sample     `json:"-"`
+	Status         Status     `json:"status"`
+}
+
+// Report aggregates the results of a validation run.
+type Report struct {
+	CandidateVersion string
+	CreatedAt        time.Time
+	ID               analysis.UUID
+	Results          []Result
+}
+
+// Count returns the number of results with the given status.
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Compatible reports whether every compared sample matched.
+// Skipped and failed samples do not count as regressions, but a run with
+// no compared samples proves nothing and is not compatible.
+func (r *Report) Compatible() bool {
+	return r.Count(StatusMatch) > 0 && r.Count(StatusMismatch) == 0
+}
+
+// CountTests returns the number of tests per file path, nested suites included.
+func CountTests(files []analysis.TestFile) map[string]int {
+	counts := make(map[string]int, len(files))
+	for _, f := range files {
+		n := len(f.Tests)
+		for _, s := range f.Suites {
+			n += countSuiteTests(s)
+		}
+		counts[f.Path] += n
+	}
+	return counts
+}
+
+func countSuiteTests(s analysis.TestSuite) int {
+	n := len(s.Tests)
+	for _, child := range s.Suites {
+		n += countSuiteTests(child)
+	}
+	return n
+}
+
+// DiffTestCounts returns files whose counts differ, sorted by path.
+func DiffTestCounts(baseline, candidate map[string]int) []FileDiff {
+	var diffs []FileDiff
+	for path, b := range baseline {
+		if c := candidate[path]; c != b {
+			diffs = append(diffs, FileDiff{Baseline: b, Candidate: c, Path: path})
+		}
+	}
+	for path, c := range candidate {
+		if _, ok := baseline[path]; !ok && c != 0 {
+			diffs = append(diffs, FileDiff{Candidate: c, Path: path})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+// Total sums the per-file counts.
+func Total(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
@@ -0,0 +1,80 @@
+package parsercompat
+
+import (
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+func TestCountTests(t *testing.T) {
+	files := []analysis.TestFile{
+		{
+			Path:  "a_test.go",
+			Tests: []analysis.Test{{Name: "TestTop"}},
+			Suites: []analysis.TestSuite{{
+				Name:   "Outer",
+				Tests:  []analysis.Test{{Name: "one"}, {Name: "two"}},
+				Suites: []analysis.TestSuite{{Name: "Inner", Tests: []analysis.Test{{Name: "three"}}}},
+			}},
+		},
+		{Path: "empty_test.go"},
+	}
+
+	counts := CountTests(files)
+
+	if counts["a_test.go"] != 4 {
+		t.Errorf("expected 4 tests in a_test.go, got %d", counts["a_test.go"])
+	}
+	if n, ok := counts["empty_test.go"]; !ok || n != 0 {
+		t.Errorf("expected empty file with 0 tests, got %d (present=%v)", n, ok)
+	}
+	if Total(counts) != 4 {
+		t.Errorf("expected total 4, got %d", Total(counts))
+	}
+}
+
+func TestDiffTestCounts(t *testing.T) {
+	baseline := map[string]int{"same.go": 3, "changed.go": 5, "removed.go": 2}
+	candidate := map[string]int{"same.go": 3, "changed.go": 4, "added.go": 1, "empty.go": 0}
+
+	diffs := DiffTestCounts(baseline, candidate)
+
+	want := []FileDiff{
+		{Path: "added.go", Candidate: 1},
+		{Path: "changed.go", Baseline: 5, Candidate: 4},
+		{Path: "removed.go", Baseline: 2},
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("expected %d diffs, got %+v", len(want), diffs)
+	}
+	for i := range want {
+		if diffs[i] != want[i] {
+			t.Errorf("diff[%d] = %+v, want %+v", i, diffs[i], want[i])
+		}
+	}
+}
+
+func TestReport_Compatible(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []Status
+		want     bool
+	}{
+		{name: "all matched", statuses: []Status{StatusMatch, StatusMatch}, want: true},
+		{name: "skipped ignored", statuses: []Status{StatusMatch, StatusSkipped, StatusFailed}, want: true},
+		{name: "mismatch", statuses: []Status{StatusMatch, StatusMismatch}, want: false},
+		{name: "nothing compared", statuses: []Status{StatusSkipped}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &Report{}
+			for _, s := range tt.statuses {
+				report.Results = append(report.Results, Result{Status: s})
+			}
+			if got := report.Compatible(); got != tt.want {
+				t.Errorf("Compatible() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package parsercompat
+
+import (
+	"context"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+// Repository provides baseline inventories and stores compatibility reports.
+type Repository interface {
+	// ListSamples returns a random sample of the latest completed analyses of
+	// public codebases that were parsed by a version other than candidateVersion.
+	ListSamples(ctx context.Context, candidateVersion string, limit int) ([]Sample, error)
+
+	// GetFileTestCounts returns the stored test count per file of an analysis,
+	// counting a collapsed test as its variants, as a fresh scan reports them.
+	GetFileTestCounts(ctx context.Context, analysisID analysis.UUID) (map[string]int, error)
+
+	// SaveReport persists the report and assigns its ID.
+	SaveReport(ctx context.Context, report *Report) error
+}
@@ -0,0 +1,9 @@
+package regen
+
+import "errors"
+
+var (
+	ErrInvalidInput      = errors.New("invalid input")
+	ErrInvalidTransition = errors.New("invalid campaign status transition")
+	ErrNotFound          = errors.New("campaign not found")
+)
@@ -0,0 +1,135 @@
+// Package regen models bulk re-generation campaigns: a fixed set of spec
+// documents regenerated at a throttled rate, e.g. after a prompt fix.
+package regen
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	DefaultLimit         = 500
+	DefaultMaxInFlight   = 20
+	DefaultRatePerMinute = 10
+	MaxLimit             = 10000
+)
+
+// Status is the lifecycle state of a campaign.
+type Status string
+
+const (
+	StatusCancelled Status = "cancelled"
+	StatusCompleted Status = "completed"
+	StatusPaused    Status = "paused"
+	StatusRunning   Status = "running"
+)
+
+// transitions lists the statuses each status may move to. Completed and
+// cancelled campaigns are final.
+var transitions = map[Status][]Status{
+	StatusPaused:  {StatusRunning, StatusCancelled},
+	StatusRunning: {StatusPaused, StatusCompleted, StatusCancelled},
+}
+
+// CanTransition reports whether a campaign may move from one status to another.
+func CanTransition(from, to Status) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// TargetStatus tracks one document of a campaign.
+type TargetStatus string
+
+const (
+	TargetEnqueued  TargetStatus = "enqueued"
+	TargetFailed    TargetStatus = "failed"
+	TargetPending   TargetStatus = "pending"
+	TargetSucceeded TargetStatus = "succeeded"
+)
+
+// Selection picks the documents a campaign regenerates. Only the latest
+// version of each document is considered.
+type Selection struct {
+	CreatedBefore time.Time `json:"created_before"` // zero means now
+	Language      string    `json:"language,omitempty"`
+	Limit         int       `json:"limit"` // 0 means DefaultLimit
+	ModelID       string    `json:"model_id,omitempty"`
+}
+
+// Normalize applies defaults and validates the selection.
+func (s Selection) Normalize(now time.Time) (Selection, error) {
+	if s.Limit < 0 || s.Limit > MaxLimit {
+		return s, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidInput, MaxLimit)
+	}
+	if s.Limit == 0 {
+		s.Limit = DefaultLimit
+	}
+	if s.CreatedBefore.IsZero() || s.CreatedBefore.After(now) {
+		s.CreatedBefore = now
+	}
+	return s, nil
+}
+
+// Throttle bounds how fast a campaign enqueues jobs.
+type Throttle struct {
+	MaxInFlight   int // jobs enqueued but not finished
+	RatePerMinute int
+}
+
+// Normalize applies defaults and validates the throttle.
+func (t Throttle) Normalize() (Throttle, error) {
+	if t.MaxInFlight < 0 || t.RatePerMinute < 0 {
+		return t, fmt.Errorf("%w: throttle values must not be negative", ErrInvalidInput)
+	}
+	if t.MaxInFlight == 0 {
+		t.MaxInFlight = DefaultMaxInFlight
+	}
+	if t.RatePerMinute == 0 {
+		t.RatePerMinute = DefaultRatePerMinute
+	}
+	return t, nil
+}
+
+// Progress counts the targets of a campaign by status.
+type Progress struct {
+	Enqueued  int
+	Failed    int
+	Pending   int
+	Succeeded int
+}
+
+// Total returns the number of targets.
+func (p Progress) Total() int {
+	return p.Enqueued + p.Failed + p.Pending + p.Succeeded
+}
+
+// Done reports whether every target has finished.
+func (p Progress) Done() bool {
+	return p.Pending == 0 && p.Enqueued == 0
+}
+
+// Campaign is a bulk regeneration with its current progress.
+type Campaign struct {
+	CreatedAt time.Time
+	ID        string
+	Name      string
+	Progress  Progress
+	Selection Selection
+	Status    Status
+	Throttle  Throttle
+	UpdatedAt time.Time
+}
+
+// Target is one document to regenerate.
+type Target struct {
+	AnalysisID string
+	CampaignID string
+	DocumentID string
+	Error      string // set when failed
+	ID         string
+	JobID      int64 // set once enqueued
+	Language   string
+	Status     TargetStatus
+	TeamSlices bool // the document had team slices, so the new version gets them too
+	UserID     string
+}
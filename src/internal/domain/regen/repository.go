@@ -0,0 +1,31 @@
+package regen
+
+import "context"
+
+// Repository stores campaigns and their targets.
+type Repository interface {
+	// CreateCampaign stores the campaign with the targets the selection matches.
+	CreateCampaign(ctx context.Context, name string, selection Selection, throttle Throttle) (*Campaign, error)
+	// GetCampaign returns ErrNotFound when no campaign has the ID.
+	GetCampaign(ctx context.Context, id string) (*Campaign, error)
+	// ListCampaigns returns all campaigns, newest first.
+	ListCampaigns(ctx context.Context) ([]Campaign, error)
+	// ListTargets returns up to limit targets in the status, in selection order.
+	ListTargets(ctx context.Context, campaignID string, status TargetStatus, limit int) ([]Target, error)
+	// MarkEnqueued records the job regenerating the target.
+	MarkEnqueued(ctx context.Context, targetID string, jobID int64) error
+	// MarkFailed records a target that could not be enqueued.
+	MarkFailed(ctx context.Context, targetID string, reason string) error
+	// SyncTargets settles enqueued targets whose job has finished and returns how many changed.
+	SyncTargets(ctx context.Context, campaignID string) (int, error)
+	// UpdateStatus moves the campaign from one status to another and returns
+	// ErrInvalidTransition when its status is no longer from.
+	UpdateStatus(ctx context.Context, id string, from, to Status) error
+}
+
+// Enqueuer schedules the regeneration of a target.
+type Enqueuer interface {
+	// EnqueueRegeneration returns the ID of the job, which may be an existing
+	// unfinished job for the same document.
+	EnqueueRegeneration(ctx context.Context, target Target) (int64, error)
+}
@@ -0,0 +1,43 @@
+// Package region models data residency. A codebase pinned to a region is only
+// processed by workers deployed in that region, against that region's database.
+package region
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Default is the unnamed region of single-region deployments.
+// Codebases without a region belong to it.
+const Default = ""
+
+const maxLength = 32
+
+var (
+	ErrInvalidRegion  = errors.New("invalid region")
+	ErrRegionMismatch = errors.New("job belongs to another region")
+
+	// Must also be a valid River queue name suffix.
+	namePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+// Validate checks that a region name is usable as a queue suffix, e.g. "eu" or "eu-west".
+func Validate(region string) error {
+	if region == Default {
+		return nil
+	}
+	if len(region) > maxLength || !namePattern.MatchString(region) {
+		return fmt.Errorf("%w: %q must be lowercase letters, digits and dashes (max %d)", ErrInvalidRegion, region, maxLength)
+	}
+	return nil
+}
+
+// QueueName scopes a base queue name to a region.
+// The default region keeps the unscoped name so single-region deployments are unchanged.
+func QueueName(base, region string) string {
+	if region == Default {
+		return base
+	}
+	return base + "_" + region
+}
@@ -0,0 +1,43 @@
+package region
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		region  string
+		wantErr bool
+	}{
+		{region: "", wantErr: false},
+		{region: "eu", wantErr: false},
+		{region: "eu-west-1", wantErr: false},
+		{region: "EU", wantErr: true},
+		{region: "eu_west", wantErr: true},
+		{region: "-eu", wantErr: true},
+		{region: "eu:west", wantErr: true},
+		{region: "abcdefghijklmnopqrstuvwxyz0123456789", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			err := Validate(tt.region)
+			if tt.wantErr && !errors.Is(err, ErrInvalidRegion) {
+				t.Errorf("Validate(%q) = %v, want ErrInvalidRegion", tt.region, err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate(%q) unexpected error: %v", tt.region, err)
+			}
+		})
+	}
+}
+
+func TestQueueName(t *testing.T) {
+	if got := QueueName("analysis_default", Default); got != "analysis_default" {
+		t.Errorf("default region should keep base name, got %q", got)
+	}
+	if got := QueueName("analysis_default", "eu"); got != "analysis_default_eu" {
+		t.Errorf("expected region suffix, got %q", got)
+	}
+}
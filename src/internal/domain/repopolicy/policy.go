@@ -0,0 +1,87 @@
+// Package repopolicy models per-tenant allow and deny rules restricting which
+// repositories a tenant's users can analyze.
+package repopolicy
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Effect is what a matching rule does.
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny  Effect = "deny"
+)
+
+const maxPatternLength = 200
+
+var (
+	ErrInvalidRule     = errors.New("invalid repository policy rule")
+	ErrPolicyViolation = errors.New("repository not allowed by tenant policy")
+)
+
+// Rule matches repositories by "owner/repo" pattern. "*" matches any run of
+// characters within a segment, and a bare owner such as "octocat" matches all
+// of its repositories. Matching is case-insensitive, like GitHub names.
+type Rule struct {
+	Effect  Effect
+	Pattern string
+}
+
+// Validate checks the effect and pattern syntax.
+func (r Rule) Validate() error {
+	if r.Effect != EffectAllow && r.Effect != EffectDeny {
+		return fmt.Errorf("%w: unknown effect %q", ErrInvalidRule, r.Effect)
+	}
+	if r.Pattern == "" || len(r.Pattern) > maxPatternLength || strings.Count(r.Pattern, "/") > 1 {
+		return fmt.Errorf("%w: pattern %q must be owner or owner/repo", ErrInvalidRule, r.Pattern)
+	}
+	if _, err := path.Match(r.pattern(), ""); err != nil {
+		return fmt.Errorf("%w: pattern %q: %w", ErrInvalidRule, r.Pattern, err)
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to owner/repo.
+// Invalid patterns never match.
+func (r Rule) Matches(owner, repo string) bool {
+	ok, err := path.Match(r.pattern(), strings.ToLower(owner+"/"+repo))
+	return err == nil && ok
+}
+
+func (r Rule) pattern() string {
+	p := strings.ToLower(r.Pattern)
+	if !strings.Contains(p, "/") {
+		p += "/*"
+	}
+	return p
+}
+
+// Check evaluates a tenant's rules for owner/repo. Deny rules win. When any
+// allow rule exists, the repository must match one of them. Without rules
+// every repository is allowed.
+func Check(rules []Rule, owner, repo string) error {
+	hasAllow := false
+	allowed := false
+	for _, r := range rules {
+		if !r.Matches(owner, repo) {
+			hasAllow = hasAllow || r.Effect == EffectAllow
+			continue
+		}
+		switch r.Effect {
+		case EffectDeny:
+			return fmt.Errorf("%w: %s/%s denied by rule %q", ErrPolicyViolation, owner, repo, r.Pattern)
+		case EffectAllow:
+			hasAllow = true
+			allowed = true
+		}
+	}
+	if hasAllow && !allowed {
+		return fmt.Errorf("%w: %s/%s matches no allow rule", ErrPolicyViolation, owner, repo)
+	}
+	return nil
+}
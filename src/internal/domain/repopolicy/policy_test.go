@@ -0,0 +1,66 @@
+package repopolicy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRule_Validate(t *testing.T) {
+	tests := []struct {
+		rule    Rule
+		wantErr bool
+	}{
+		{Rule{Effect: EffectAllow, Pattern: "octocat"}, false},
+		{Rule{Effect: EffectDeny, Pattern: "octocat/secret-*"}, false},
+		{Rule{Effect: EffectAllow, Pattern: "*"}, false},
+		{Rule{Effect: "block", Pattern: "octocat"}, true},
+		{Rule{Effect: EffectAllow, Pattern: ""}, true},
+		{Rule{Effect: EffectAllow, Pattern: "a/b/c"}, true},
+		{Rule{Effect: EffectAllow, Pattern: "octocat/[a"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.rule.Effect)+" "+tt.rule.Pattern, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected ErrInvalidRule, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	allowOrg := Rule{Effect: EffectAllow, Pattern: "acme"}
+	denySecret := Rule{Effect: EffectDeny, Pattern: "acme/secret-*"}
+
+	tests := []struct {
+		name    string
+		rules   []Rule
+		owner   string
+		repo    string
+		allowed bool
+	}{
+		{"no rules", nil, "octocat", "hello", true},
+		{"allowed org", []Rule{allowOrg}, "acme", "api", true},
+		{"case-insensitive", []Rule{allowOrg}, "ACME", "API", true},
+		{"outside allow list", []Rule{allowOrg}, "octocat", "hello", false},
+		{"deny wins over allow", []Rule{allowOrg, denySecret}, "acme", "secret-keys", false},
+		{"deny only", []Rule{denySecret}, "octocat", "hello", true},
+		{"exact repo", []Rule{{Effect: EffectAllow, Pattern: "octocat/hello"}}, "octocat", "hello-world", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.rules, tt.owner, tt.repo)
+			if tt.allowed && err != nil {
+				t.Errorf("expected allowed, got %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrPolicyViolation) {
+				t.Errorf("expected ErrPolicyViolation, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package repopolicy
+
+import "context"
+
+// Repository loads policy rules.
+type Repository interface {
+	// ListRulesForUser returns the rules of the tenant the user belongs to,
+	// empty when the user belongs to no tenant.
+	ListRulesForUser(ctx context.Context, userID string) ([]Rule, error)
+}
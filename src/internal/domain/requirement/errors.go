@@ -0,0 +1,10 @@
+package requirement
+
+import "errors"
+
+var (
+	ErrEmptyRequirementSet = errors.New("requirement set is empty")
+	ErrInvalidFormat       = errors.New("invalid requirements format")
+	ErrInvalidInput        = errors.New("invalid input")
+	ErrSetNotFound         = errors.New("requirement set not found")
+)
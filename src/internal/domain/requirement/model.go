@@ -0,0 +1,77 @@
+package requirement
+
+import "fmt"
+
+// Format identifies the upload format of a requirements list.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// Requirement is a single externally-authored requirement to be traced to behaviors.
+type Requirement struct {
+	Description string
+	ExternalKey string // team-assigned identifier (e.g., "REQ-101"), unique per set
+	ID          string
+	Title       string
+}
+
+// Text returns the content used for matching against behaviors.
+func (r Requirement) Text() string {
+	if r.Description == "" {
+		return r.Title
+	}
+	return r.Title + " " + r.Description
+}
+
+// Set is an uploaded requirements list scoped to a codebase.
+type Set struct {
+	CodebaseID   string
+	ID           string
+	Name         string
+	Requirements []Requirement
+	SourceFormat Format
+}
+
+// Validate checks required fields and key uniqueness before persistence.
+func (s Set) Validate() error {
+	if s.CodebaseID == "" {
+		return fmt.Errorf("%w: codebase ID is required", ErrInvalidFormat)
+	}
+	if s.Name == "" {
+		return fmt.Errorf("%w: set name is required", ErrInvalidFormat)
+	}
+	if len(s.Requirements) == 0 {
+		return ErrEmptyRequirementSet
+	}
+	seen := make(map[string]struct{}, len(s.Requirements))
+	for i, r := range s.Requirements {
+		if r.ExternalKey == "" {
+			return fmt.Errorf("%w: requirement %d has no key", ErrInvalidFormat, i+1)
+		}
+		if r.Title == "" {
+			return fmt.Errorf("%w: requirement %s has no title", ErrInvalidFormat, r.ExternalKey)
+		}
+		if _, dup := seen[r.ExternalKey]; dup {
+			return fmt.Errorf("%w: duplicate requirement key %s", ErrInvalidFormat, r.ExternalKey)
+		}
+		seen[r.ExternalKey] = struct{}{}
+	}
+	return nil
+}
+
+// Match links a requirement to a behavior that exercises it.
+type Match struct {
+	BehaviorID    string
+	RequirementID string
+	Score         float64 // similarity in [0, 1]
+}
+
+// CoverageSummary reports how much of a requirement set is exercised by a document.
+type CoverageSummary struct {
+	CoveredRequirements int
+	TotalRequirements   int
+	UntestedKeys        []string // external keys with no matching behavior
+}
@@ -0,0 +1,59 @@
+package requirement
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSet_Validate(t *testing.T) {
+	valid := func() Set {
+		return Set{
+			CodebaseID: "cb-1",
+			Name:       "Q3 PRD",
+			Requirements: []Requirement{
+				{ExternalKey: "REQ-1", Title: "Refunds"},
+				{ExternalKey: "REQ-2", Title: "Invoices"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*Set)
+		wantErr error
+	}{
+		{"valid set", func(*Set) {}, nil},
+		{"missing codebase", func(s *Set) { s.CodebaseID = "" }, ErrInvalidFormat},
+		{"missing name", func(s *Set) { s.Name = "" }, ErrInvalidFormat},
+		{"no requirements", func(s *Set) { s.Requirements = nil }, ErrEmptyRequirementSet},
+		{"missing key", func(s *Set) { s.Requirements[0].ExternalKey = "" }, ErrInvalidFormat},
+		{"missing title", func(s *Set) { s.Requirements[1].Title = "" }, ErrInvalidFormat},
+		{"duplicate key", func(s *Set) { s.Requirements[1].ExternalKey = "REQ-1" }, ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.mutate(&s)
+			err := s.Validate()
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRequirement_Text(t *testing.T) {
+	if got := (Requirement{Title: "Refunds"}).Text(); got != "Refunds" {
+		t.Errorf("Text() = %q, want %q", got, "Refunds")
+	}
+	if got := (Requirement{Title: "Refunds", Description: "card"}).Text(); got != "Refunds card" {
+		t.Errorf("Text() = %q, want %q", got, "Refunds card")
+	}
+}
@@ -0,0 +1,120 @@
+package requirement
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Header aliases accepted in CSV uploads; teams export from different trackers.
+var (
+	keyHeaders         = []string{"key", "id", "requirement_id"}
+	titleHeaders       = []string{"title", "name", "summary"}
+	descriptionHeaders = []string{"description", "details", "body"}
+)
+
+type jsonRequirement struct {
+	Description string `json:"description"`
+	Key         string `json:"key"`
+	Title       string `json:"title"`
+}
+
+// Parse reads a requirements list in the given format.
+func Parse(format Format, r io.Reader) ([]Requirement, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatJSON:
+		return parseJSON(r)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidFormat, format)
+	}
+}
+
+func parseCSV(r io.Reader) ([]Requirement, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrEmptyRequirementSet
+		}
+		return nil, fmt.Errorf("%w: read header: %w", ErrInvalidFormat, err)
+	}
+
+	keyIdx := findColumn(header, keyHeaders)
+	titleIdx := findColumn(header, titleHeaders)
+	descIdx := findColumn(header, descriptionHeaders)
+	if keyIdx < 0 || titleIdx < 0 {
+		return nil, fmt.Errorf("%w: CSV header must include key and title columns", ErrInvalidFormat)
+	}
+
+	var reqs []Requirement
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidFormat, line, err)
+		}
+		req := Requirement{
+			ExternalKey: field(record, keyIdx),
+			Title:       field(record, titleIdx),
+			Description: field(record, descIdx),
+		}
+		if req.ExternalKey == "" && req.Title == "" {
+			continue
+		}
+		reqs = append(reqs, req)
+	}
+
+	if len(reqs) == 0 {
+		return nil, ErrEmptyRequirementSet
+	}
+	return reqs, nil
+}
+
+func parseJSON(r io.Reader) ([]Requirement, error) {
+	var items []jsonRequirement
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFormat, err)
+	}
+	if len(items) == 0 {
+		return nil, ErrEmptyRequirementSet
+	}
+
+	reqs := make([]Requirement, len(items))
+	for i, item := range items {
+		reqs[i] = Requirement{
+			Description: strings.TrimSpace(item.Description),
+			ExternalKey: strings.TrimSpace(item.Key),
+			Title:       strings.TrimSpace(item.Title),
+		}
+	}
+	return reqs, nil
+}
+
+func findColumn(header []string, aliases []string) int {
+	for i, h := range header {
+		name := strings.ToLower(strings.TrimSpace(h))
+		for _, alias := range aliases {
+			if name == alias {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func field(record []string, idx int) string {
+	if idx < 0 || idx >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[idx])
+}
@@ -0,0 +1,86 @@
+package requirement
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse_CSV(t *testing.T) {
+	t.Run("parses header aliases and optional description", func(t *testing.T) {
+		input := "ID,Summary,Details\nREQ-1,Refund card payments,Partial refunds allowed\nREQ-2,Export invoices,\n"
+
+		reqs, err := Parse(FormatCSV, strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(reqs) != 2 {
+			t.Fatalf("expected 2 requirements, got %d", len(reqs))
+		}
+		if reqs[0].ExternalKey != "REQ-1" || reqs[0].Title != "Refund card payments" || reqs[0].Description != "Partial refunds allowed" {
+			t.Errorf("unexpected first requirement: %+v", reqs[0])
+		}
+		if reqs[1].Description != "" {
+			t.Errorf("expected empty description, got %q", reqs[1].Description)
+		}
+	})
+
+	t.Run("skips blank rows", func(t *testing.T) {
+		reqs, err := Parse(FormatCSV, strings.NewReader("key,title\nREQ-1,A\n,\n"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(reqs) != 1 {
+			t.Errorf("expected 1 requirement, got %d", len(reqs))
+		}
+	})
+
+	t.Run("rejects missing title column", func(t *testing.T) {
+		_, err := Parse(FormatCSV, strings.NewReader("key,owner\nREQ-1,alice\n"))
+		if !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("expected ErrInvalidFormat, got %v", err)
+		}
+	})
+
+	t.Run("rejects empty input", func(t *testing.T) {
+		_, err := Parse(FormatCSV, strings.NewReader(""))
+		if !errors.Is(err, ErrEmptyRequirementSet) {
+			t.Errorf("expected ErrEmptyRequirementSet, got %v", err)
+		}
+	})
+}
+
+func TestParse_JSON(t *testing.T) {
+	t.Run("parses array", func(t *testing.T) {
+		input := `[{"key":"REQ-1","title":" Refunds ","description":"card"}]`
+
+		reqs, err := Parse(FormatJSON, strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(reqs) != 1 || reqs[0].Title != "Refunds" {
+			t.Errorf("unexpected result: %+v", reqs)
+		}
+	})
+
+	t.Run("rejects malformed JSON", func(t *testing.T) {
+		_, err := Parse(FormatJSON, strings.NewReader(`{"key":`))
+		if !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("expected ErrInvalidFormat, got %v", err)
+		}
+	})
+
+	t.Run("rejects empty array", func(t *testing.T) {
+		_, err := Parse(FormatJSON, strings.NewReader(`[]`))
+		if !errors.Is(err, ErrEmptyRequirementSet) {
+			t.Errorf("expected ErrEmptyRequirementSet, got %v", err)
+		}
+	})
+}
+
+func TestParse_UnsupportedFormat(t *testing.T) {
+	_, err := Parse(Format("xlsx"), strings.NewReader(""))
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("expected ErrInvalidFormat, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package requirement
+
+import "context"
+
+// Repository defines persistence for requirement sets and their coverage matrix.
+type Repository interface {
+	// SaveSet stores the set and its requirements, assigning IDs in place.
+	SaveSet(ctx context.Context, set *Set) error
+
+	// GetSet loads a requirement set with all requirements in upload order.
+	// Returns ErrSetNotFound if the set does not exist.
+	GetSet(ctx context.Context, setID string) (*Set, error)
+
+	// ReplaceCoverage replaces the coverage rows of a set for one document.
+	// Re-running a match against the same document is idempotent.
+	ReplaceCoverage(ctx context.Context, setID string, documentID string, matches []Match) error
+}
+
+// Candidate is a behavior that a requirement may be linked to.
+type Candidate struct {
+	BehaviorID string
+	Text       string
+}
+
+// Matcher scores requirements against behaviors.
+// Implementations may use lexical overlap, embeddings, or an AI model.
+type Matcher interface {
+	Match(ctx context.Context, requirements []Requirement, candidates []Candidate) ([]Match, error)
+}
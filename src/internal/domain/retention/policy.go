@@ -46,3 +46,47 @@ func (p Policy) IsExpired(createdAt time.Time, now time.Time) bool {
 func (p Policy) CutoffTime(now time.Time) time.Time {
 	return now.AddDate(0, 0, -p.retentionDays)
 }
+
+// DefaultKeepVersions is how many of the latest versions of a document the
+// version policy keeps regardless of age.
+const DefaultKeepVersions = 3
+
+// DefaultDeletedDocumentGrace is how long a soft-deleted spec document can be
+// restored before cleanup removes it.
+const DefaultDeletedDocumentGrace = 7 * 24 * time.Hour
+
+// VersionPolicy prunes superseded spec document versions: versions older than
+// the retention period are deleted, except the latest of each series. The
+// zero value prunes nothing.
+type VersionPolicy struct {
+	keepVersions  int
+	retentionDays int
+}
+
+// NewVersionPolicy creates a VersionPolicy deleting versions older than days,
+// keeping the keep latest ones. days <= 0 disables pruning; keep <= 0 uses
+// DefaultKeepVersions.
+func NewVersionPolicy(days, keep int) VersionPolicy {
+	if days <= 0 {
+		return VersionPolicy{}
+	}
+	if keep <= 0 {
+		keep = DefaultKeepVersions
+	}
+	return VersionPolicy{keepVersions: keep, retentionDays: days}
+}
+
+// Enabled reports whether the policy prunes versions.
+func (p VersionPolicy) Enabled() bool {
+	return p.retentionDays > 0
+}
+
+// KeepVersions returns how many of the latest versions are always kept.
+func (p VersionPolicy) KeepVersions() int {
+	return p.keepVersions
+}
+
+// CutoffTime returns the time before which superseded versions are deleted.
+func (p VersionPolicy) CutoffTime(now time.Time) time.Time {
+	return now.AddDate(0, 0, -p.retentionDays)
+}
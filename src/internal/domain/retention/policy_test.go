@@ -91,3 +91,39 @@ func TestPolicy_CutoffTime(t *testing.T) {
 		t.Errorf("CutoffTime() = %v, want %v", got, want)
 	}
 }
+
+func TestNewVersionPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		days        int
+		keep        int
+		wantEnabled bool
+		wantKeep    int
+	}{
+		{"days and keep", 90, 5, true, 5},
+		{"zero keep uses default", 90, 0, true, DefaultKeepVersions},
+		{"zero days disables", 0, 5, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewVersionPolicy(tt.days, tt.keep)
+			if got := p.Enabled(); got != tt.wantEnabled {
+				t.Errorf("Enabled() = %v, want %v", got, tt.wantEnabled)
+			}
+			if got := p.KeepVersions(); got != tt.wantKeep {
+				t.Errorf("KeepVersions() = %d, want %d", got, tt.wantKeep)
+			}
+		})
+	}
+}
+
+func TestVersionPolicy_CutoffTime(t *testing.T) {
+	p := NewVersionPolicy(30, 1)
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	if got := p.CutoffTime(now); !got.Equal(want) {
+		t.Errorf("CutoffTime() = %v, want %v", got, want)
+	}
+}
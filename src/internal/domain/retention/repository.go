@@ -1,6 +1,9 @@
 package retention
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // CleanupRepository defines operations for retention-based data cleanup.
 type CleanupRepository interface {
@@ -14,10 +17,55 @@ type CleanupRepository interface {
 	// Returns the number of deleted records.
 	DeleteExpiredSpecDocuments(ctx context.Context, batchSize int) (DeleteResult, error)
 
+	// DeleteSupersededSpecDocuments removes spec document versions older than
+	// the policy cutoff, keeping the latest versions of each document series.
+	// Returns the number of deleted records.
+	DeleteSupersededSpecDocuments(ctx context.Context, policy VersionPolicy, batchSize int) (DeleteResult, error)
+
+	// DeleteSoftDeletedSpecDocuments removes spec documents soft-deleted
+	// longer than grace ago.
+	// Returns the number of deleted records.
+	DeleteSoftDeletedSpecDocuments(ctx context.Context, grace time.Duration, batchSize int) (DeleteResult, error)
+
 	// DeleteOrphanedAnalyses removes analyses that have no references
 	// in user_analysis_history.
 	// Returns the number of deleted records.
 	DeleteOrphanedAnalyses(ctx context.Context, batchSize int) (DeleteResult, error)
+
+	// DeleteStaleBehaviorCaches removes behavior cache entries written for
+	// codebases that have been stale for over a day.
+	// Returns the number of deleted records.
+	DeleteStaleBehaviorCaches(ctx context.Context, batchSize int) (DeleteResult, error)
+
+	// DeleteStaleClassificationCaches removes classification cache entries
+	// written for codebases that have been stale for over a day.
+	// Returns the number of deleted records.
+	DeleteStaleClassificationCaches(ctx context.Context, batchSize int) (DeleteResult, error)
+
+	// DeleteOrphanBehaviorEmbeddings removes semantic cache embeddings older
+	// than a day that have no behavior cache entry.
+	// Returns the number of deleted records.
+	DeleteOrphanBehaviorEmbeddings(ctx context.Context, batchSize int) (DeleteResult, error)
+
+	// DeleteExpiredWebhookDeliveries removes webhook delivery records
+	// past their deduplication window.
+	// Returns the number of deleted records.
+	DeleteExpiredWebhookDeliveries(ctx context.Context, batchSize int) (DeleteResult, error)
+
+	// DeleteExpiredIdempotencyKeys removes enqueue idempotency keys
+	// past their deduplication window.
+	// Returns the number of deleted records.
+	DeleteExpiredIdempotencyKeys(ctx context.Context, batchSize int) (DeleteResult, error)
+
+	// DeleteIdleRateLimitBuckets removes rate limit buckets untouched
+	// for a day, which have refilled and are recreated full on demand.
+	// Returns the number of deleted records.
+	DeleteIdleRateLimitBuckets(ctx context.Context, batchSize int) (DeleteResult, error)
+
+	// DeleteExpiredAnalysisEvents removes analysis progress events
+	// older than a week.
+	// Returns the number of deleted records.
+	DeleteExpiredAnalysisEvents(ctx context.Context, batchSize int) (DeleteResult, error)
 }
 
 // DeleteResult holds the outcome of a deletion operation.
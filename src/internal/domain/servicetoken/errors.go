@@ -0,0 +1,10 @@
+package servicetoken
+
+import "errors"
+
+var (
+	ErrInsufficientScope = errors.New("insufficient token scope")
+	ErrInvalidInput      = errors.New("invalid input")
+	ErrInvalidToken      = errors.New("invalid service token")
+	ErrTokenNotFound     = errors.New("service token not found")
+)
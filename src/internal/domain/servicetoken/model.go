@@ -0,0 +1,137 @@
+// Package servicetoken models bearer tokens that machine callers present to the
+// worker's HTTP surfaces instead of being trusted implicitly.
+package servicetoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxNameLength matches service_tokens.name.
+	MaxNameLength = 100
+
+	// TokenPrefix marks plaintext service tokens so they are recognizable in
+	// logs and secret scanners.
+	TokenPrefix = "svt_"
+
+	// displayPrefixLength is how much of the plaintext is stored to identify a token.
+	displayPrefixLength = 12
+	secretBytes         = 32
+)
+
+// Scope is a permission granted to a token.
+type Scope string
+
+const (
+	// ScopeAdmin grants every other scope.
+	ScopeAdmin      Scope = "admin"
+	ScopeEnqueue    Scope = "enqueue"
+	ScopeReadStatus Scope = "read-status"
+)
+
+// Scopes lists every valid scope.
+var Scopes = []Scope{ScopeAdmin, ScopeEnqueue, ScopeReadStatus}
+
+func (s Scope) IsValid() bool {
+	return slices.Contains(Scopes, s)
+}
+
+// ParseScopes parses a comma-separated scope list.
+func ParseScopes(s string) ([]Scope, error) {
+	var scopes []Scope
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		scope := Scope(part)
+		if !scope.IsValid() {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidInput, part)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// Token is an issued service token. The plaintext is never stored.
+type Token struct {
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	ID         string
+	LastUsedAt *time.Time
+	Name       string
+	Prefix     string // first characters of the plaintext, for identification
+	RevokedAt  *time.Time
+	Scopes     []Scope
+}
+
+// Allows reports whether the token grants scope.
+func (t Token) Allows(scope Scope) bool {
+	return slices.Contains(t.Scopes, ScopeAdmin) || slices.Contains(t.Scopes, scope)
+}
+
+// ActiveAt reports whether the token is neither revoked nor expired at now.
+func (t Token) ActiveAt(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
+
+// IssueParams describes a token to issue.
+type IssueParams struct {
+	ExpiresAt *time.Time // nil for no expiry
+	Name      string
+	Scopes    []Scope
+}
+
+// Validate checks the params against now.
+func (p IssueParams) Validate(now time.Time) error {
+	if p.Name == "" || len(p.Name) > MaxNameLength {
+		return fmt.Errorf("%w: name must be 1-%d characters", ErrInvalidInput, MaxNameLength)
+	}
+	if len(p.Scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidInput)
+	}
+	for _, s := range p.Scopes {
+		if !s.IsValid() {
+			return fmt.Errorf("%w: unknown scope %q", ErrInvalidInput, s)
+		}
+	}
+	if p.ExpiresAt != nil && !p.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: expiry must be in the future", ErrInvalidInput)
+	}
+	return nil
+}
+
+// Generate returns a new random plaintext token.
+func Generate() (string, error) {
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate service token: %w", err)
+	}
+	return TokenPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// Hash returns the digest stored for a plaintext token. Tokens carry 256 bits of
+// entropy, so a fast unsalted hash is enough to keep stored digests useless.
+func Hash(plaintext string) []byte {
+	sum := sha256.Sum256([]byte(plaintext))
+	return sum[:]
+}
+
+// DisplayPrefix returns the part of a plaintext token stored for identification.
+func DisplayPrefix(plaintext string) string {
+	if len(plaintext) <= displayPrefixLength {
+		return plaintext
+	}
+	return plaintext[:displayPrefixLength]
+}
@@ -0,0 +1,124 @@
+package servicetoken
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToken_Allows(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []Scope
+		scope  Scope
+		want   bool
+	}{
+		{name: "granted", scopes: []Scope{ScopeEnqueue}, scope: ScopeEnqueue, want: true},
+		{name: "not granted", scopes: []Scope{ScopeEnqueue}, scope: ScopeReadStatus, want: false},
+		{name: "admin grants all", scopes: []Scope{ScopeAdmin}, scope: ScopeReadStatus, want: true},
+		{name: "no scopes", scope: ScopeEnqueue, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Token{Scopes: tt.scopes}).Allows(tt.scope); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToken_ActiveAt(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		token Token
+		want  bool
+	}{
+		{name: "no expiry", token: Token{}, want: true},
+		{name: "expires later", token: Token{ExpiresAt: &future}, want: true},
+		{name: "expired", token: Token{ExpiresAt: &past}, want: false},
+		{name: "revoked", token: Token{RevokedAt: &past}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.token.ActiveAt(now); got != tt.want {
+				t.Errorf("ActiveAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes("enqueue, read-status,enqueue,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(scopes) != 2 || scopes[0] != ScopeEnqueue || scopes[1] != ScopeReadStatus {
+		t.Errorf("unexpected scopes: %v", scopes)
+	}
+
+	if _, err := ParseScopes("enqueue,write"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestIssueParams_Validate(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		params  IssueParams
+		wantErr bool
+	}{
+		{name: "valid", params: IssueParams{Name: "ci", Scopes: []Scope{ScopeEnqueue}}},
+		{name: "missing name", params: IssueParams{Scopes: []Scope{ScopeEnqueue}}, wantErr: true},
+		{name: "long name", params: IssueParams{Name: strings.Repeat("a", MaxNameLength+1), Scopes: []Scope{ScopeEnqueue}}, wantErr: true},
+		{name: "no scopes", params: IssueParams{Name: "ci"}, wantErr: true},
+		{name: "unknown scope", params: IssueParams{Name: "ci", Scopes: []Scope{"write"}}, wantErr: true},
+		{name: "expired", params: IssueParams{ExpiresAt: &past, Name: "ci", Scopes: []Scope{ScopeEnqueue}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.params.Validate(now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	a, err := Generate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := Generate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(a, TokenPrefix) {
+		t.Errorf("expected %q prefix, got %q", TokenPrefix, a)
+	}
+	if a == b {
+		t.Error("expected distinct tokens")
+	}
+	if bytes.Equal(Hash(a), Hash(b)) {
+		t.Error("expected distinct hashes")
+	}
+	if got := DisplayPrefix(a); len(got) != displayPrefixLength || !strings.HasPrefix(a, got) {
+		t.Errorf("unexpected display prefix %q", got)
+	}
+}
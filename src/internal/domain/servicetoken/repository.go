@@ -0,0 +1,25 @@
+package servicetoken
+
+import "context"
+
+// CreateParams is a token ready to be stored.
+type CreateParams struct {
+	Hash   []byte
+	Prefix string
+	IssueParams
+}
+
+// Repository stores service tokens by hash.
+type Repository interface {
+	CreateToken(ctx context.Context, params CreateParams) (*Token, error)
+
+	// FindTokenByHash returns nil without error when no token has the hash.
+	FindTokenByHash(ctx context.Context, hash []byte) (*Token, error)
+
+	ListTokens(ctx context.Context) ([]Token, error)
+
+	// RevokeToken fails with ErrTokenNotFound when no active token has the ID.
+	RevokeToken(ctx context.Context, id string) error
+
+	TouchToken(ctx context.Context, id string) error
+}
@@ -0,0 +1,73 @@
+// Package sla sets target completion durations per job kind and times the
+// phases of a job, so jobs exceeding their target can be traced to the phase
+// that made them slow.
+package sla
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+)
+
+var ErrInvalidTarget = errors.New("invalid SLA target")
+
+// Phase names a step of a job. Phases are exclusive: time spent in a phase
+// started while another runs counts only for the inner one.
+type Phase string
+
+const (
+	PhaseQueued Phase = "queued" // from enqueue to the start of the attempt, earlier attempts included
+	PhaseOther  Phase = "other"  // time of the attempt outside every phase
+
+	// Analysis phases.
+	PhaseClone Phase = "clone"
+	PhaseScan  Phase = "scan"
+	PhaseSave  Phase = "save"
+
+	// Spec generation phases.
+	PhaseClassify  Phase = "phase1"
+	PhaseConvert   Phase = "phase2"
+	PhaseSummarize Phase = "phase3"
+)
+
+// DefaultTargets are the targets of job kinds JOB_SLA_TARGETS leaves unset.
+// The analysis target is the analysis timeout; spec generation allows for a
+// Phase 2 of a few hundred features.
+var DefaultTargets = Targets{
+	"analysis:analyze":  15 * time.Minute,
+	"specview:generate": 30 * time.Minute,
+}
+
+// Targets maps job kinds to the duration from enqueue to completion they
+// should finish within. Kinds without a target are not tracked.
+type Targets map[string]time.Duration
+
+// ParseTargets reads comma-separated "<kind>=<duration>" entries, e.g.
+// "analysis:analyze=10m,specview:generate=45m", over DefaultTargets. A zero
+// duration removes the target of a kind.
+func ParseTargets(spec string) (Targets, error) {
+	targets := maps.Clone(DefaultTargets)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kind, value, ok := strings.Cut(entry, "=")
+		kind = strings.TrimSpace(kind)
+		if !ok || kind == "" {
+			return nil, fmt.Errorf("%w: entry %q must be <kind>=<duration>", ErrInvalidTarget, entry)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%w: duration of %q must be a non-negative duration like 15m", ErrInvalidTarget, kind)
+		}
+		if d == 0 {
+			delete(targets, kind)
+			continue
+		}
+		targets[kind] = d
+	}
+	return targets, nil
+}
@@ -0,0 +1,85 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    Targets
+		wantErr bool
+	}{
+		{name: "empty keeps the defaults", spec: "", want: DefaultTargets},
+		{
+			name: "overrides and adds kinds",
+			spec: "analysis:analyze=10m, specview:export=1m",
+			want: Targets{"analysis:analyze": 10 * time.Minute, "specview:export": time.Minute, "specview:generate": 30 * time.Minute},
+		},
+		{name: "zero removes a target", spec: "specview:generate=0s", want: Targets{"analysis:analyze": 15 * time.Minute}},
+		{name: "missing duration", spec: "analysis:analyze", wantErr: true},
+		{name: "missing kind", spec: "=10m", wantErr: true},
+		{name: "invalid duration", spec: "analysis:analyze=ten", wantErr: true},
+		{name: "negative duration", spec: "analysis:analyze=-1m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTargets(tt.spec)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTarget) {
+					t.Errorf("expected ErrInvalidTarget, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("ParseTargets(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseTargets("specview:generate=0s"); err != nil || DefaultTargets["specview:generate"] == 0 {
+		t.Error("expected the defaults left unchanged")
+	}
+}
+
+func TestStartPhase(t *testing.T) {
+	clock := time.Unix(0, 0)
+	timings := newTimings(func() time.Time { return clock })
+	ctx := context.WithValue(context.Background(), timingsKey{}, timings)
+	advance := func(d time.Duration) { clock = clock.Add(d) }
+
+	stopScan := StartPhase(ctx, PhaseScan)
+	advance(3 * time.Second)
+	stopSave := StartPhase(ctx, PhaseSave)
+	advance(2 * time.Second)
+	stopSave()
+	advance(time.Second)
+	if got := timings.Phases(); got[PhaseScan] != 4*time.Second {
+		t.Errorf("running scan = %v, want 4s", got[PhaseScan])
+	}
+	stopScan()
+	advance(time.Second)
+	StartPhase(ctx, PhaseSave)()
+	timings.Add(PhaseQueued, time.Minute)
+
+	want := map[Phase]time.Duration{PhaseQueued: time.Minute, PhaseSave: 2 * time.Second, PhaseScan: 4 * time.Second}
+	if got := timings.Phases(); !maps.Equal(got, want) {
+		t.Errorf("Phases() = %v, want %v", got, want)
+	}
+	if got := Slowest(want); got != PhaseQueued {
+		t.Errorf("Slowest() = %q, want %q", got, PhaseQueued)
+	}
+	if got := Slowest(map[Phase]time.Duration{PhaseSave: time.Second, PhaseClone: time.Second}); got != PhaseClone {
+		t.Errorf("Slowest() of a tie = %q, want %q", got, PhaseClone)
+	}
+
+	StartPhase(context.Background(), PhaseScan)()
+}
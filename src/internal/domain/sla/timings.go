@@ -0,0 +1,104 @@
+package sla
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Timings accumulates the time a job spends in each phase. Safe for
+// concurrent use, but phases are meant to be started and stopped by the
+// goroutine running the job.
+type Timings struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	phases  map[Phase]time.Duration
+	running []runningPhase
+}
+
+type runningPhase struct {
+	phase Phase
+	since time.Time
+}
+
+func newTimings(now func() time.Time) *Timings {
+	return &Timings{now: now, phases: make(map[Phase]time.Duration)}
+}
+
+type timingsKey struct{}
+
+// WithTimings makes StartPhase calls on the returned context accumulate into
+// the returned timings.
+func WithTimings(ctx context.Context) (context.Context, *Timings) {
+	t := newTimings(time.Now)
+	return context.WithValue(ctx, timingsKey{}, t), t
+}
+
+// StartPhase starts timing phase and returns the function that stops it. The
+// running phase, if any, is paused until then. Without WithTimings it does
+// nothing.
+func StartPhase(ctx context.Context, phase Phase) (stop func()) {
+	t, ok := ctx.Value(timingsKey{}).(*Timings)
+	if !ok {
+		return func() {}
+	}
+	t.start(phase)
+	return t.stop
+}
+
+func (t *Timings) start(phase Phase) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	if n := len(t.running); n > 0 {
+		t.phases[t.running[n-1].phase] += now.Sub(t.running[n-1].since)
+	}
+	t.running = append(t.running, runningPhase{phase: phase, since: now})
+}
+
+func (t *Timings) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := len(t.running)
+	if n == 0 {
+		return
+	}
+	now := t.now()
+	t.phases[t.running[n-1].phase] += now.Sub(t.running[n-1].since)
+	t.running = t.running[:n-1]
+	if n > 1 {
+		t.running[n-2].since = now
+	}
+}
+
+// Add counts d towards phase.
+func (t *Timings) Add(phase Phase, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phases[phase] += d
+}
+
+// Phases returns the time spent in each phase so far, running phases
+// included.
+func (t *Timings) Phases() map[Phase]time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	phases := maps.Clone(t.phases)
+	if n := len(t.running); n > 0 {
+		phases[t.running[n-1].phase] += t.now().Sub(t.running[n-1].since)
+	}
+	return phases
+}
+
+// Slowest returns the phase with the most time in phases, or "" when it is
+// empty. Ties go to the phase named first.
+func Slowest(phases map[Phase]time.Duration) Phase {
+	var slowest Phase
+	for phase, d := range phases {
+		if slowest == "" || d > phases[slowest] || (d == phases[slowest] && phase < slowest) {
+			slowest = phase
+		}
+	}
+	return slowest
+}
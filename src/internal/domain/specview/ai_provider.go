@@ -27,3 +27,55 @@ type AIProvider interface {
 	// Close releases resources held by the provider.
 	Close() error
 }
+
+// Warmer is an optional AIProvider capability for establishing the connection
+// to the model vendor before the first job, without spending tokens.
+type Warmer interface {
+	Warmup(ctx context.Context) error
+}
+
+// AvailabilityReporter is an optional AIProvider capability reporting whether
+// calls can currently reach the model vendor, e.g. false while a circuit
+// breaker is open.
+type AvailabilityReporter interface {
+	Available() bool
+}
+
+type responseCacheScopeKey struct{}
+
+// WithResponseCacheScope allows providers to reuse AI responses for calls made
+// with ctx, but only between calls of the same scope. Calls without a scope
+// are never served from or added to a response cache.
+func WithResponseCacheScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, responseCacheScopeKey{}, scope)
+}
+
+// ResponseCacheScope returns the scope set by WithResponseCacheScope.
+func ResponseCacheScope(ctx context.Context) (string, bool) {
+	scope, ok := ctx.Value(responseCacheScopeKey{}).(string)
+	return scope, ok && scope != ""
+}
+
+// ResponseCacheScopeFor returns the response cache scope of a user's
+// generation. Prompts built from public repositories are shared by all users;
+// those of private repositories only by the requesting user.
+func ResponseCacheScopeFor(analysis *AnalysisContext, userID string) string {
+	if analysis == nil || analysis.Private {
+		return "user:" + userID
+	}
+	return "public"
+}
+
+type modelOverrideKey struct{}
+
+// WithModelOverride makes providers use model instead of their configured
+// models for calls made with ctx.
+func WithModelOverride(ctx context.Context, model string) context.Context {
+	return context.WithValue(ctx, modelOverrideKey{}, model)
+}
+
+// ModelOverride returns the model set by WithModelOverride.
+func ModelOverride(ctx context.Context) (string, bool) {
+	model, ok := ctx.Value(modelOverrideKey{}).(string)
+	return model, ok && model != ""
+}
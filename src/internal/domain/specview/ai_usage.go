@@ -0,0 +1,49 @@
+package specview
+
+import (
+	"context"
+	"time"
+)
+
+// AIUsage is the token usage of one phase of a generation job.
+type AIUsage struct {
+	TokenUsage
+	AnalysisID string
+	DocumentID string // empty when the job saved no document
+	JobID      int64  // the parent job for Phase 2 fan-out children
+	Phase      string
+	UserID     string // empty for Phase 2 fan-out children, whose jobs carry no user
+}
+
+// AIUsageRecorder is an optional Repository capability for persisting token
+// usage, so billing and capacity planning can query it instead of logs.
+type AIUsageRecorder interface {
+	// RecordAIUsage stores usages. Earlier usages of the same job recorded
+	// without a user, by fan-out children, are attributed to the user and
+	// document of the usages that have them.
+	RecordAIUsage(ctx context.Context, usages []AIUsage) error
+}
+
+// AIUsageTotals sums recorded token usage over a group of usages. Fields the
+// grouping does not use are zero.
+type AIUsageTotals struct {
+	CandidatesTokens int64
+	Day              time.Time
+	Jobs             int64 // distinct jobs in the group
+	Model            string
+	Phase            string
+	PromptTokens     int64
+	TotalTokens      int64
+	UserID           string
+}
+
+// AIUsageReporter aggregates the usage stored by an AIUsageRecorder over
+// [from, to).
+type AIUsageReporter interface {
+	// DailyAIUsage sums usage by UTC day, model and phase, oldest day first.
+	DailyAIUsage(ctx context.Context, from, to time.Time) ([]AIUsageTotals, error)
+
+	// AIUsageByUser sums usage by user, the largest total first, returning at
+	// most limit users. Usage not attributed to a user is left out.
+	AIUsageByUser(ctx context.Context, from, to time.Time, limit int) ([]AIUsageTotals, error)
+}
@@ -0,0 +1,67 @@
+package specview
+
+import (
+	"context"
+	"time"
+)
+
+// BatchJobState represents the lifecycle of an asynchronous batch job.
+type BatchJobState string
+
+const (
+	BatchJobStateCancelled BatchJobState = "cancelled"
+	BatchJobStateExpired   BatchJobState = "expired"
+	BatchJobStatePending   BatchJobState = "pending"
+	BatchJobStateRunning   BatchJobState = "running"
+	BatchJobStateSucceeded BatchJobState = "succeeded"
+)
+
+// IsTerminal reports whether the job will not change state again.
+func (s BatchJobState) IsTerminal() bool {
+	switch s {
+	case BatchJobStateCancelled, BatchJobStateExpired, BatchJobStateSucceeded:
+		return true
+	}
+	return false
+}
+
+// BatchRequest is a single prompt submitted as part of a batch job.
+// CustomID correlates the request with its result.
+type BatchRequest struct {
+	CustomID     string
+	Model        string
+	SystemPrompt string
+	UserPrompt   string
+}
+
+// BatchResult is the outcome of one BatchRequest.
+// Err is set when the provider rejected or failed that request.
+type BatchResult struct {
+	CustomID string
+	Err      error
+	Text     string
+	Usage    *TokenUsage
+}
+
+// BatchJobStatus is a snapshot of a batch job.
+// Results is populated only once the job reaches BatchJobStateSucceeded.
+type BatchJobStatus struct {
+	CompletedAt *time.Time
+	JobID       string
+	Results     []BatchResult
+	State       BatchJobState
+}
+
+// BatchProvider submits AI work asynchronously at reduced cost.
+// Batch APIs trade latency (minutes to hours) for quota headroom,
+// so they suit large Phase 1 classifications that are not user-blocking.
+type BatchProvider interface {
+	// CreateClassificationJob submits a Phase 1 classification as a single-request batch.
+	CreateClassificationJob(ctx context.Context, input Phase1Input) (string, error)
+	// CreateJob submits arbitrary prompts and returns the provider job ID.
+	CreateJob(ctx context.Context, requests []BatchRequest) (string, error)
+	// GetJobStatus polls a job and collects results once it has finished.
+	GetJobStatus(ctx context.Context, jobID string) (*BatchJobStatus, error)
+	// CancelJob stops a job whose results are no longer needed.
+	CancelJob(ctx context.Context, jobID string) error
+}
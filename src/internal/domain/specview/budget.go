@@ -0,0 +1,84 @@
+package specview
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrBudgetExceeded is returned when a generation would overspend the monthly
+// AI budget of the requesting user's organization.
+var ErrBudgetExceeded = errors.New("organization AI budget exceeded")
+
+// Budget is what remains this month of an organization's AI budget.
+// A nil remaining amount means the budget does not limit it.
+type Budget struct {
+	DowngradeModel     string // model used instead of rejecting a job that would overspend, empty rejects
+	RemainingBehaviors *int64
+	RemainingTokens    *int64
+	TenantID           string
+}
+
+// BudgetDecision is the outcome of checking a job against a budget.
+type BudgetDecision int
+
+const (
+	BudgetAllow BudgetDecision = iota
+	BudgetDowngrade
+	BudgetReject
+)
+
+// Check decides whether work costing the estimated tokens and behaviors fits
+// the budget. An exhausted budget rejects; one that is merely too small
+// downgrades when a downgrade model is configured. Jobs already running on
+// the downgrade model continue until the budget is exhausted.
+func (b *Budget) Check(tokens, behaviors int64, modelID string) BudgetDecision {
+	if b == nil {
+		return BudgetAllow
+	}
+	if exhausted(b.RemainingTokens) || exhausted(b.RemainingBehaviors) {
+		return BudgetReject
+	}
+	if fits(b.RemainingTokens, tokens) && fits(b.RemainingBehaviors, behaviors) {
+		return BudgetAllow
+	}
+	switch b.DowngradeModel {
+	case "":
+		return BudgetReject
+	case modelID:
+		return BudgetAllow
+	default:
+		return BudgetDowngrade
+	}
+}
+
+// Spend deducts tokens from the remaining budget.
+func (b *Budget) Spend(tokens int64) {
+	if b == nil || b.RemainingTokens == nil {
+		return
+	}
+	remaining := *b.RemainingTokens - tokens
+	b.RemainingTokens = &remaining
+}
+
+func exhausted(remaining *int64) bool {
+	return remaining != nil && *remaining <= 0
+}
+
+func fits(remaining *int64, amount int64) bool {
+	return remaining == nil || amount <= *remaining
+}
+
+// BudgetRepository is an optional Repository capability for enforcing
+// organization AI budgets. Behaviors are counted from usage events; tokens
+// are recorded separately since usage events only hold quota. Tokens are
+// charged under the job that spent them as each phase ends; saving the
+// job's document attributes its charges to the document.
+type BudgetRepository interface {
+	// GetRemainingBudget returns the budget of the user's organization.
+	// Returns nil without error when the user's organization has no budget.
+	GetRemainingBudget(ctx context.Context, userID string) (*Budget, error)
+
+	// RecordTokenUsage adds tokens spent by the job to the organization's
+	// month. A zero job ID records tokens no document is attributed.
+	RecordTokenUsage(ctx context.Context, tenantID string, jobID int64, tokens int64) error
+}
@@ -0,0 +1,45 @@
+package specview
+
+import "testing"
+
+func TestBudget_Check(t *testing.T) {
+	remaining := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		name      string
+		budget    *Budget
+		tokens    int64
+		behaviors int64
+		modelID   string
+		want      BudgetDecision
+	}{
+		{"no budget", nil, 1000, 10, "large", BudgetAllow},
+		{"unlimited", &Budget{}, 1000, 10, "large", BudgetAllow},
+		{"fits", &Budget{RemainingTokens: remaining(1000), RemainingBehaviors: remaining(10)}, 1000, 10, "large", BudgetAllow},
+		{"tokens exhausted", &Budget{DowngradeModel: "small", RemainingTokens: remaining(0)}, 1, 0, "large", BudgetReject},
+		{"behaviors exhausted", &Budget{DowngradeModel: "small", RemainingBehaviors: remaining(-3)}, 1, 0, "large", BudgetReject},
+		{"too small with downgrade", &Budget{DowngradeModel: "small", RemainingTokens: remaining(500)}, 1000, 0, "large", BudgetDowngrade},
+		{"too small without downgrade", &Budget{RemainingBehaviors: remaining(5)}, 0, 10, "large", BudgetReject},
+		{"too small and already downgraded", &Budget{DowngradeModel: "small", RemainingTokens: remaining(500)}, 1000, 0, "small", BudgetAllow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.budget.Check(tt.tokens, tt.behaviors, tt.modelID); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudget_Spend(t *testing.T) {
+	tokens := int64(100)
+	budget := &Budget{RemainingTokens: &tokens}
+	budget.Spend(30)
+	if *budget.RemainingTokens != 70 || tokens != 100 {
+		t.Errorf("remaining = %d, caller's value = %d", *budget.RemainingTokens, tokens)
+	}
+
+	var unlimited *Budget
+	unlimited.Spend(30)
+}
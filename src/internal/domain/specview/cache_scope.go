@@ -0,0 +1,85 @@
+package specview
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// CacheScopeMode decides who shares behavior cache entries. Entries are keyed
+// by test and never name their owner, so a global cache reuses behaviors
+// generated for one tenant in the documents of another.
+type CacheScopeMode string
+
+const (
+	CacheScopeGlobal       CacheScopeMode = "global"       // every user shares the cache
+	CacheScopeOrganization CacheScopeMode = "organization" // members of a tenant share it; users outside tenants keep their own
+	CacheScopeUser         CacheScopeMode = "user"         // every user keeps their own
+)
+
+// ParseCacheScopeMode validates a mode name. Empty is CacheScopeGlobal.
+func ParseCacheScopeMode(s string) (CacheScopeMode, error) {
+	mode := CacheScopeMode(strings.ToLower(strings.TrimSpace(s)))
+	switch mode {
+	case "":
+		return CacheScopeGlobal, nil
+	case CacheScopeGlobal, CacheScopeOrganization, CacheScopeUser:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: unknown cache scope %q", ErrInvalidInput, s)
+	}
+}
+
+// Scope returns the cache key scope of a user, empty for the global cache.
+// tenantID is the user's tenant; without one the organization mode falls
+// back to the user, so tenantless users never share entries with a tenant.
+func (m CacheScopeMode) Scope(userID, tenantID string) string {
+	switch m {
+	case CacheScopeOrganization:
+		if tenantID != "" {
+			return "tenant:" + tenantID
+		}
+		return "user:" + userID
+	case CacheScopeUser:
+		return "user:" + userID
+	default:
+		return ""
+	}
+}
+
+// TenantLookup is an optional Repository capability for scoping the behavior
+// cache by organization.
+type TenantLookup interface {
+	// GetUserTenantID returns the tenant the user belongs to, or "" without
+	// error when the user belongs to none.
+	GetUserTenantID(ctx context.Context, userID string) (string, error)
+}
+
+type cacheScopeKey struct{}
+
+// WithCacheScope makes behavior cache keys computed for ctx include scope.
+func WithCacheScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, cacheScopeKey{}, scope)
+}
+
+// CacheScope returns the scope set by WithCacheScope, or "" for the global
+// cache.
+func CacheScope(ctx context.Context) string {
+	scope, _ := ctx.Value(cacheScopeKey{}).(string)
+	return scope
+}
+
+// ScopedSignature mixes a cache scope into a classification signature, so
+// classifications cached in one scope are never served in another. Returns
+// signature unchanged for the global cache.
+func ScopedSignature(signature []byte, scope string) []byte {
+	if scope == "" {
+		return signature
+	}
+	h := sha256.New()
+	h.Write(signature)
+	h.Write([]byte{0})
+	h.Write([]byte("scope:" + scope))
+	return h.Sum(nil)
+}
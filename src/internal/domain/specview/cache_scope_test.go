@@ -0,0 +1,77 @@
+package specview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseCacheScopeMode(t *testing.T) {
+	for input, want := range map[string]CacheScopeMode{
+		"":              CacheScopeGlobal,
+		"global":        CacheScopeGlobal,
+		" Organization": CacheScopeOrganization,
+		"user":          CacheScopeUser,
+	} {
+		if got, err := ParseCacheScopeMode(input); err != nil || got != want {
+			t.Errorf("ParseCacheScopeMode(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseCacheScopeMode("team"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestCacheScopeMode_Scope(t *testing.T) {
+	tests := []struct {
+		mode     CacheScopeMode
+		tenantID string
+		want     string
+	}{
+		{CacheScopeGlobal, "acme", ""},
+		{CacheScopeOrganization, "acme", "tenant:acme"},
+		{CacheScopeOrganization, "", "user:u1"},
+		{CacheScopeUser, "acme", "user:u1"},
+	}
+	for _, tt := range tests {
+		if got := tt.mode.Scope("u1", tt.tenantID); got != tt.want {
+			t.Errorf("%s.Scope(u1, %q) = %q, want %q", tt.mode, tt.tenantID, got, tt.want)
+		}
+	}
+}
+
+func TestCacheScope(t *testing.T) {
+	ctx := context.Background()
+	if got := CacheScope(ctx); got != "" {
+		t.Errorf("expected the global scope by default, got %q", got)
+	}
+	if got := CacheScope(WithCacheScope(ctx, "tenant:acme")); got != "tenant:acme" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestGenerateCacheKeyHash_Scope(t *testing.T) {
+	key := BehaviorCacheKey{TestName: "test", FilePath: "test.ts", Language: "English", ModelID: "gemini-2.5-flash"}
+	global := GenerateCacheKeyHash(key)
+
+	key.Scope = "tenant:acme"
+	acme := GenerateCacheKeyHash(key)
+	key.Scope = "tenant:globex"
+	globex := GenerateCacheKeyHash(key)
+
+	if bytes.Equal(global, acme) || bytes.Equal(acme, globex) {
+		t.Error("each scope should produce its own hash")
+	}
+}
+
+func TestScopedSignature(t *testing.T) {
+	signature := []byte("signature")
+	if got := ScopedSignature(signature, ""); !bytes.Equal(got, signature) {
+		t.Errorf("the global cache should keep the signature, got %x", got)
+	}
+	acme := ScopedSignature(signature, "tenant:acme")
+	if bytes.Equal(acme, signature) || bytes.Equal(acme, ScopedSignature(signature, "tenant:globex")) {
+		t.Error("each scope should produce its own signature")
+	}
+}
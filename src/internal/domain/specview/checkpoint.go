@@ -0,0 +1,47 @@
+package specview
+
+import "context"
+
+// GenerationCheckpoint is the progress of a generation job saved while it runs,
+// so a retry of the job resumes at Phase 2 instead of classifying again.
+type GenerationCheckpoint struct {
+	AnalysisID   string
+	ContentHash  []byte              // curated content hash the progress was made for
+	Features     []FeatureCheckpoint // Phase 2 features converted without failures
+	JobID        int64
+	Phase1Output *Phase1Output // before curation rules are applied
+}
+
+// FeatureCheckpoint holds the behaviors of one converted feature. Indices
+// refer to the Phase 1 output after curation rules are applied.
+type FeatureCheckpoint struct {
+	Behaviors    []BehaviorSpec
+	DomainIndex  int
+	FeatureIndex int
+}
+
+// Matches reports whether the checkpoint was made for the given content.
+// Progress made for other content must not be reused.
+func (c *GenerationCheckpoint) Matches(contentHash []byte) bool {
+	return c != nil && c.Phase1Output != nil && string(c.ContentHash) == string(contentHash)
+}
+
+// CheckpointRepository is an optional Repository capability for persisting
+// generation progress per queue job.
+type CheckpointRepository interface {
+	// AppendCheckpointFeatures adds converted features to the checkpoint of
+	// the job saved by SaveCheckpoint.
+	AppendCheckpointFeatures(ctx context.Context, jobID int64, features []FeatureCheckpoint) error
+
+	// DeleteCheckpoint removes the checkpoint of the job. Deleting a missing
+	// checkpoint is not an error.
+	DeleteCheckpoint(ctx context.Context, jobID int64) error
+
+	// LoadCheckpoint returns the checkpoint of the job, or nil without error
+	// when there is none.
+	LoadCheckpoint(ctx context.Context, jobID int64) (*GenerationCheckpoint, error)
+
+	// SaveCheckpoint creates or replaces the checkpoint of the job, including
+	// its features.
+	SaveCheckpoint(ctx context.Context, checkpoint *GenerationCheckpoint) error
+}
@@ -9,6 +9,7 @@ import (
 // ClassificationCache represents a cached Phase 1 classification result.
 // Used for incremental caching: when tests change, only new tests are classified.
 type ClassificationCache struct {
+	AnalysisID           string                 // analysis whose codebase owns the cache, for stale codebase cleanup; optional
 	ClassificationResult *Phase1Output          // Phase 1 output (domain/feature structure)
 	CreatedAt            time.Time              // cache creation timestamp
 	ExpiresAt            time.Time              // cache expiration timestamp
@@ -2,13 +2,40 @@ package specview
 
 import (
 	"crypto/sha256"
+	"fmt"
+	"hash"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/zeebo/blake3"
 	"golang.org/x/text/unicode/norm"
 )
 
+// HashAlgorithm selects the hash function of behavior cache keys.
+type HashAlgorithm string
+
+const (
+	HashBLAKE3 HashAlgorithm = "blake3"
+	HashSHA256 HashAlgorithm = "sha256" // default, and the only one before keys were versioned
+)
+
+// blake3KeyVersion prefixes BLAKE3 keys, so they never collide with the
+// unprefixed SHA-256 keys stored in the same cache.
+const blake3KeyVersion = 0x02
+
+// ParseHashAlgorithm parses a hash algorithm name. Empty selects SHA-256.
+func ParseHashAlgorithm(name string) (HashAlgorithm, error) {
+	switch algorithm := HashAlgorithm(strings.ToLower(strings.TrimSpace(name))); algorithm {
+	case "":
+		return HashSHA256, nil
+	case HashBLAKE3, HashSHA256:
+		return algorithm, nil
+	default:
+		return "", fmt.Errorf("%w: unknown hash algorithm %q", ErrInvalidInput, name)
+	}
+}
+
 // GenerateContentHash creates a deterministic hash from test files and language.
 // Hash = SHA256(sorted_file_paths + sorted_test_names + language)
 func GenerateContentHash(files []FileInfo, language Language) []byte {
@@ -73,11 +100,22 @@ func normalizeTestName(name string) string {
 	return strings.Join(fields, " ")
 }
 
-// GenerateCacheKeyHash creates a deterministic SHA-256 hash for behavior caching.
+// GenerateCacheKeyHash creates a deterministic hash for behavior caching.
 // Hash = SHA256(NFC(test_name) + "\x00" + NFC(suite_path) + "\x00" + NFC(file_path) + "\x00" + NFC(language) + "\x00" + NFC(model_id))
+// A non-empty style is appended as a further component, then a non-empty
+// glossary version, then a candidate prompt version, then a cache scope.
 // Unicode NFC normalization ensures equivalent Unicode sequences produce the same hash.
+// With key.Algorithm set to HashBLAKE3, BLAKE3-256 replaces SHA-256 and the
+// key is prefixed with a version byte.
 func GenerateCacheKeyHash(key BehaviorCacheKey) []byte {
-	h := sha256.New()
+	var h hash.Hash
+	var prefix []byte
+	if key.Algorithm == HashBLAKE3 {
+		h = blake3.New()
+		prefix = []byte{blake3KeyVersion}
+	} else {
+		h = sha256.New()
+	}
 
 	// Apply NFC normalization to all string components
 	h.Write(norm.NFC.Bytes([]byte(normalizeTestName(key.TestName))))
@@ -94,5 +132,25 @@ func GenerateCacheKeyHash(key BehaviorCacheKey) []byte {
 
 	h.Write(norm.NFC.Bytes([]byte(key.ModelID)))
 
-	return h.Sum(nil)
+	if key.Style != "" {
+		h.Write([]byte{0})
+		h.Write([]byte(key.Style))
+	}
+
+	if key.GlossaryVersion != "" {
+		h.Write([]byte{0})
+		h.Write([]byte("glossary:" + key.GlossaryVersion))
+	}
+
+	if key.PromptVersion != "" && key.PromptVersion != DefaultPromptVersion {
+		h.Write([]byte{0})
+		h.Write([]byte("prompt:" + key.PromptVersion))
+	}
+
+	if key.Scope != "" {
+		h.Write([]byte{0})
+		h.Write([]byte("scope:" + key.Scope))
+	}
+
+	return h.Sum(prefix)
 }
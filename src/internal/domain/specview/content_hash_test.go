@@ -2,6 +2,7 @@ package specview
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"testing"
 )
 
@@ -497,6 +498,28 @@ func TestGenerateCacheKeyHash_DifferentModelID(t *testing.T) {
 	}
 }
 
+func TestGenerateCacheKeyHash_Style(t *testing.T) {
+	base := BehaviorCacheKey{
+		TestName: "test",
+		FilePath: "test.ts",
+		Language: "English",
+		ModelID:  "gemini-2.5-flash",
+	}
+	styled := base
+	styled.Style = "concise"
+
+	if bytes.Equal(GenerateCacheKeyHash(base), GenerateCacheKeyHash(styled)) {
+		t.Error("style should produce a different hash")
+	}
+
+	// Empty style must keep the legacy hash so existing cache entries stay valid.
+	legacy := sha256.New()
+	legacy.Write([]byte("test\x00\x00test.ts\x00English\x00gemini-2.5-flash"))
+	if !bytes.Equal(GenerateCacheKeyHash(base), legacy.Sum(nil)) {
+		t.Error("empty style should not change the hash")
+	}
+}
+
 func TestGenerateCacheKeyHash_DifferentSuitePath(t *testing.T) {
 	key1 := BehaviorCacheKey{
 		TestName:  "test",
@@ -616,3 +639,49 @@ func TestGenerateCacheKeyHash_FilePathNormalization(t *testing.T) {
 		t.Error("file path normalization should produce same hash for unix and windows paths")
 	}
 }
+
+func TestGenerateCacheKeyHash_Algorithm(t *testing.T) {
+	key := BehaviorCacheKey{
+		TestName: "should login with valid credentials",
+		FilePath: "src/auth/login_test.ts",
+		Language: "Korean",
+		ModelID:  "gemini-2.5-flash",
+	}
+	sha := GenerateCacheKeyHash(key)
+
+	key.Algorithm = HashSHA256
+	if !bytes.Equal(GenerateCacheKeyHash(key), sha) {
+		t.Error("explicit SHA-256 should match the default key")
+	}
+
+	key.Algorithm = HashBLAKE3
+	b3 := GenerateCacheKeyHash(key)
+	if !bytes.Equal(b3, GenerateCacheKeyHash(key)) {
+		t.Error("BLAKE3 key should be deterministic")
+	}
+	if len(b3) != 33 || b3[0] != blake3KeyVersion {
+		t.Errorf("expected a version byte and a 32-byte BLAKE3 digest, got %x", b3)
+	}
+	if bytes.Equal(b3[1:], sha) {
+		t.Error("BLAKE3 and SHA-256 keys should differ")
+	}
+}
+
+func TestParseHashAlgorithm(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    HashAlgorithm
+		wantErr bool
+	}{
+		{"", HashSHA256, false},
+		{"sha256", HashSHA256, false},
+		{" BLAKE3 ", HashBLAKE3, false},
+		{"md5", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseHashAlgorithm(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseHashAlgorithm(%q) = %q, %v", tt.name, got, err)
+		}
+	}
+}
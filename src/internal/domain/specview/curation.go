@@ -0,0 +1,194 @@
+package specview
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+
+	"github.com/specvital/worker/internal/domain/curation"
+)
+
+// CurationRulesReader is an optional Repository capability for loading the
+// repository rules file stored with an analysis.
+type CurationRulesReader interface {
+	// GetCurationRules returns nil without error when the analysis has no rules.
+	GetCurationRules(ctx context.Context, analysisID string) (*curation.Rules, error)
+}
+
+// FilterCuratedFiles drops files excluded by the rules.
+// Analyses stored before the rules changed may still contain them.
+func FilterCuratedFiles(files []FileInfo, rules *curation.Rules) []FileInfo {
+	if rules.IsEmpty() {
+		return files
+	}
+	kept := make([]FileInfo, 0, len(files))
+	for _, f := range files {
+		if !rules.ExcludesFile(f.Path) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// CuratedContentHash mixes the rules into a content hash so that editing the
+// rules file invalidates previously generated documents.
+func CuratedContentHash(contentHash []byte, rules *curation.Rules) []byte {
+	if rules.IsEmpty() {
+		return contentHash
+	}
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return contentHash
+	}
+	h := sha256.New()
+	h.Write(contentHash)
+	h.Write([]byte{0})
+	h.Write(encoded)
+	return h.Sum(nil)
+}
+
+// TaxonomyAnchors converts the rules taxonomy template into Phase 1 anchor domains.
+func TaxonomyAnchors(rules *curation.Rules) []DomainGroup {
+	taxonomy := rules.Taxonomy()
+	if len(taxonomy) == 0 {
+		return nil
+	}
+	anchors := make([]DomainGroup, len(taxonomy))
+	for i, d := range taxonomy {
+		features := make([]FeatureGroup, len(d.Features))
+		for j, name := range d.Features {
+			features[j] = FeatureGroup{Name: name}
+		}
+		anchors[i] = DomainGroup{Description: d.Description, Features: features, Name: d.Name}
+	}
+	return anchors
+}
+
+// TaxonomySignature mixes the taxonomy into a classification cache signature,
+// since the same files classified against a different template differ.
+func TaxonomySignature(signature []byte, taxonomy []DomainGroup) []byte {
+	if len(taxonomy) == 0 {
+		return signature
+	}
+	h := sha256.New()
+	h.Write(signature)
+	for _, d := range taxonomy {
+		h.Write([]byte{0})
+		h.Write([]byte(d.Name))
+		for _, f := range d.Features {
+			h.Write([]byte{1})
+			h.Write([]byte(f.Name))
+		}
+	}
+	return h.Sum(nil)
+}
+
+// ApplyCurationRules returns a copy of the classification with forced placements
+// and domain renames applied. Forced tests are moved after the AI classification
+// so the rules always win; domains that end up with the same name are merged.
+func ApplyCurationRules(output *Phase1Output, files []FileInfo, rules *curation.Rules) *Phase1Output {
+	if output == nil || rules.IsEmpty() {
+		return output
+	}
+
+	type location struct {
+		filePath  string
+		suitePath string
+	}
+	locations := make(map[int]location)
+	for _, f := range files {
+		for _, t := range f.Tests {
+			locations[t.Index] = location{filePath: f.Path, suitePath: t.SuitePath}
+		}
+	}
+
+	type placement struct {
+		domain  string
+		feature string
+		index   int
+	}
+	var forced []placement
+
+	result := &Phase1Output{}
+	for _, d := range output.Domains {
+		domain := DomainGroup{Confidence: d.Confidence, Description: d.Description, Name: d.Name}
+		for _, feat := range d.Features {
+			kept := make([]int, 0, len(feat.TestIndices))
+			for _, idx := range feat.TestIndices {
+				loc, ok := locations[idx]
+				if !ok {
+					kept = append(kept, idx)
+					continue
+				}
+				rule, matched := rules.ForcedPlacement(loc.filePath, loc.suitePath)
+				if !matched {
+					kept = append(kept, idx)
+					continue
+				}
+				featureName := rule.Feature
+				if featureName == "" {
+					featureName = feat.Name
+				}
+				forced = append(forced, placement{domain: rule.Domain, feature: featureName, index: idx})
+			}
+			if len(kept) > 0 {
+				feat.TestIndices = kept
+				domain.Features = append(domain.Features, feat)
+			}
+		}
+		if len(domain.Features) > 0 {
+			result.Domains = append(result.Domains, domain)
+		}
+	}
+
+	for _, p := range forced {
+		domain, _ := findOrAddDomain(result, p.domain)
+		feature, _ := findOrAddFeature(domain, p.feature)
+		feature.TestIndices = append(feature.TestIndices, p.index)
+	}
+
+	if len(rules.Domains.Rename) == 0 {
+		return result
+	}
+
+	renamed := &Phase1Output{}
+	for _, d := range result.Domains {
+		target, created := findOrAddDomain(renamed, rules.RenameDomain(d.Name))
+		if created {
+			target.Confidence = d.Confidence
+			target.Description = d.Description
+		}
+		for _, feat := range d.Features {
+			merged, created := findOrAddFeature(target, feat.Name)
+			if created {
+				merged.Confidence = feat.Confidence
+				merged.Description = feat.Description
+			}
+			merged.TestIndices = append(merged.TestIndices, feat.TestIndices...)
+		}
+	}
+	return renamed
+}
+
+// findOrAddDomain returns the named domain, appending it when missing,
+// and reports whether it was created.
+// Rule-created groups get full confidence since a human chose them.
+func findOrAddDomain(output *Phase1Output, name string) (*DomainGroup, bool) {
+	for i := range output.Domains {
+		if output.Domains[i].Name == name {
+			return &output.Domains[i], false
+		}
+	}
+	output.Domains = append(output.Domains, DomainGroup{Confidence: 1, Name: name})
+	return &output.Domains[len(output.Domains)-1], true
+}
+
+func findOrAddFeature(domain *DomainGroup, name string) (*FeatureGroup, bool) {
+	for i := range domain.Features {
+		if domain.Features[i].Name == name {
+			return &domain.Features[i], false
+		}
+	}
+	domain.Features = append(domain.Features, FeatureGroup{Confidence: 1, Name: name})
+	return &domain.Features[len(domain.Features)-1], true
+}
@@ -0,0 +1,122 @@
+package specview
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/curation"
+)
+
+func curationTestFiles() []FileInfo {
+	return []FileInfo{
+		{Path: "src/auth/login.test.ts", Tests: []TestInfo{
+			{Index: 0, Name: "logs in", SuitePath: "Login"},
+			{Index: 1, Name: "rejects bad password", SuitePath: "Login"},
+		}},
+		{Path: "src/billing/invoice.test.ts", Tests: []TestInfo{
+			{Index: 2, Name: "renders pdf", SuitePath: "Invoice > PDF"},
+		}},
+	}
+}
+
+func curationTestOutput() *Phase1Output {
+	return &Phase1Output{Domains: []DomainGroup{
+		{Name: "Auth", Confidence: 0.9, Features: []FeatureGroup{
+			{Name: "Login", Confidence: 0.9, TestIndices: []int{0, 1}},
+		}},
+		{Name: "Misc", Confidence: 0.4, Features: []FeatureGroup{
+			{Name: "Rendering", Confidence: 0.4, TestIndices: []int{2}},
+		}},
+	}}
+}
+
+func TestApplyCurationRules(t *testing.T) {
+	t.Run("returns output unchanged without rules", func(t *testing.T) {
+		output := curationTestOutput()
+		if got := ApplyCurationRules(output, curationTestFiles(), nil); got != output {
+			t.Error("expected same output for nil rules")
+		}
+	})
+
+	t.Run("forces suite into new domain and drops emptied groups", func(t *testing.T) {
+		rules := &curation.Rules{Domains: curation.DomainRules{Force: []curation.ForceRule{
+			{Domain: "Billing", Feature: "Invoices", Suite: "Invoice > *"},
+		}}}
+
+		got := ApplyCurationRules(curationTestOutput(), curationTestFiles(), rules)
+
+		if len(got.Domains) != 2 || got.Domains[0].Name != "Auth" || got.Domains[1].Name != "Billing" {
+			t.Fatalf("unexpected domains: %+v", got.Domains)
+		}
+		billing := got.Domains[1]
+		if billing.Confidence != 1 || len(billing.Features) != 1 || billing.Features[0].Name != "Invoices" {
+			t.Errorf("unexpected forced domain: %+v", billing)
+		}
+		if len(billing.Features[0].TestIndices) != 1 || billing.Features[0].TestIndices[0] != 2 {
+			t.Errorf("expected test 2 in forced feature, got %v", billing.Features[0].TestIndices)
+		}
+	})
+
+	t.Run("force without feature keeps original feature name", func(t *testing.T) {
+		rules := &curation.Rules{Domains: curation.DomainRules{Force: []curation.ForceRule{
+			{Domain: "Identity", Path: "src/auth/**"},
+		}}}
+
+		got := ApplyCurationRules(curationTestOutput(), curationTestFiles(), rules)
+
+		last := got.Domains[len(got.Domains)-1]
+		if last.Name != "Identity" || last.Features[0].Name != "Login" || len(last.Features[0].TestIndices) != 2 {
+			t.Errorf("unexpected forced placement: %+v", last)
+		}
+	})
+
+	t.Run("rename merges domains", func(t *testing.T) {
+		rules := &curation.Rules{Domains: curation.DomainRules{Rename: map[string]string{"Misc": "Auth"}}}
+
+		got := ApplyCurationRules(curationTestOutput(), curationTestFiles(), rules)
+
+		if len(got.Domains) != 1 || got.Domains[0].Name != "Auth" || len(got.Domains[0].Features) != 2 {
+			t.Fatalf("expected merged Auth domain, got %+v", got.Domains)
+		}
+		if got.Domains[0].Confidence != 0.9 {
+			t.Errorf("expected first domain confidence to be kept, got %v", got.Domains[0].Confidence)
+		}
+	})
+
+	t.Run("does not mutate input", func(t *testing.T) {
+		output := curationTestOutput()
+		rules := &curation.Rules{Domains: curation.DomainRules{Force: []curation.ForceRule{
+			{Domain: "Billing", Path: "**/invoice.test.ts"},
+		}}}
+
+		ApplyCurationRules(output, curationTestFiles(), rules)
+
+		if output.Domains[1].Features[0].TestIndices[0] != 2 {
+			t.Error("input output was modified")
+		}
+	})
+}
+
+func TestFilterCuratedFiles(t *testing.T) {
+	rules := &curation.Rules{Exclude: []string{"src/billing/**"}}
+
+	got := FilterCuratedFiles(curationTestFiles(), rules)
+
+	if len(got) != 1 || got[0].Path != "src/auth/login.test.ts" {
+		t.Errorf("unexpected files: %+v", got)
+	}
+}
+
+func TestCuratedContentHash(t *testing.T) {
+	base := []byte("hash")
+
+	if got := CuratedContentHash(base, nil); !bytes.Equal(got, base) {
+		t.Error("expected unchanged hash without rules")
+	}
+
+	a := CuratedContentHash(base, &curation.Rules{Exclude: []string{"a/**"}})
+	b := CuratedContentHash(base, &curation.Rules{Exclude: []string{"b/**"}})
+	if bytes.Equal(a, base) || bytes.Equal(a, b) {
+		t.Error("expected rules to change the hash")
+	}
+}
@@ -0,0 +1,110 @@
+package specview
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DecisionKind names a generation decision recorded in a document's decision log.
+type DecisionKind string
+
+const (
+	DecisionBehaviorCacheHit    DecisionKind = "behavior_cache_hit"
+	DecisionBehaviorCacheMiss   DecisionKind = "behavior_cache_miss"
+	DecisionBehaviorMerged      DecisionKind = "behavior_merged"
+	DecisionClassificationCache DecisionKind = "classification_cache"
+	DecisionDocumentTranslated  DecisionKind = "document_translated"
+	DecisionFeatureFallback     DecisionKind = "feature_fallback"
+	DecisionFileExcluded        DecisionKind = "file_excluded"
+	DecisionForcedPlacement     DecisionKind = "forced_placement"
+	DecisionHeuristicFallback   DecisionKind = "heuristic_fallback"
+	DecisionLowConfidenceRetry  DecisionKind = "low_confidence_retry"
+	DecisionModelDowngrade      DecisionKind = "model_downgrade"
+	DecisionModelFallback       DecisionKind = "model_fallback"
+	DecisionPlacementFallback   DecisionKind = "placement_fallback"
+	DecisionReclassified        DecisionKind = "uncategorized_reclassified"
+	DecisionTaxonomyRemapped    DecisionKind = "taxonomy_remapped"
+)
+
+// DecisionEvent explains one choice made while generating a document, so the
+// Web app can answer "why does this look like this?" for a test, feature or file.
+type DecisionEvent struct {
+	Detail     map[string]any // kind-specific fields, stored as JSON
+	Kind       DecisionKind
+	OccurredAt time.Time
+	Subject    string // test name, feature name or file path the decision is about
+}
+
+// DecisionWriter is an optional Repository capability for writing decision
+// events ahead of their document, so the decisions of a generation that fails
+// before its document is saved are kept. Saving the document links the events
+// of its GenerationID to it.
+type DecisionWriter interface {
+	// AppendDecision stores event as the sequence-th decision of the generation.
+	// Appending a sequence already stored is not an error.
+	AppendDecision(ctx context.Context, analysisID, generationID string, sequence int, event DecisionEvent) error
+}
+
+// DecisionLog collects decision events in the order they were made.
+// It is safe for concurrent use, and a nil log discards events.
+type DecisionLog struct {
+	events       []DecisionEvent
+	generationID string
+	mu           sync.Mutex
+	write        func(sequence int, event DecisionEvent)
+}
+
+func NewDecisionLog() *DecisionLog {
+	return &DecisionLog{}
+}
+
+// NewWriteAheadDecisionLog returns a log that passes each event to write, with
+// its sequence, as soon as it is recorded. write is called outside the log's
+// lock, so events of concurrent callers may reach it out of sequence.
+func NewWriteAheadDecisionLog(generationID string, write func(sequence int, event DecisionEvent)) *DecisionLog {
+	return &DecisionLog{generationID: generationID, write: write}
+}
+
+// GenerationID returns the ID the events are written ahead under, or "" when
+// they are not.
+func (l *DecisionLog) GenerationID() string {
+	if l == nil {
+		return ""
+	}
+	return l.generationID
+}
+
+func (l *DecisionLog) Record(kind DecisionKind, subject string, detail map[string]any) {
+	if l == nil {
+		return
+	}
+	event := DecisionEvent{
+		Detail:     detail,
+		Kind:       kind,
+		OccurredAt: time.Now(),
+		Subject:    subject,
+	}
+	l.mu.Lock()
+	sequence := len(l.events)
+	l.events = append(l.events, event)
+	l.mu.Unlock()
+	if l.write != nil {
+		l.write(sequence, event)
+	}
+}
+
+// Events returns a copy of the recorded events.
+func (l *DecisionLog) Events() []DecisionEvent {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.events) == 0 {
+		return nil
+	}
+	events := make([]DecisionEvent, len(l.events))
+	copy(events, l.events)
+	return events
+}
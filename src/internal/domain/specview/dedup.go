@@ -0,0 +1,128 @@
+package specview
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultDedupSimilarity is the similarity at or above which two behaviors of
+// the same feature are treated as duplicates.
+const DefaultDedupSimilarity = 0.9
+
+// MergedTestCase is a test case whose behavior was collapsed into another
+// behavior of the same feature.
+type MergedTestCase struct {
+	Description  string  // behavior description before the merge
+	OriginalName string  // original test name
+	Similarity   float64 // 1 for an exact duplicate
+	TestCaseID   string
+}
+
+// BehaviorMerge reports one behavior collapsed by DedupBehaviors.
+type BehaviorMerge struct {
+	Into   Behavior // surviving behavior, before the merged test case was added
+	Merged MergedTestCase
+}
+
+// DedupBehaviors collapses behaviors of the same feature whose descriptions
+// are equal after normalization (case, punctuation and spacing) or at least
+// minSimilarity alike. The first behavior of a group survives, references the
+// others in Merged and links their test cases; order is otherwise kept. A
+// minSimilarity outside (0, 1] merges exact duplicates only.
+func DedupBehaviors(doc *SpecDocument, minSimilarity float64) []BehaviorMerge {
+	if doc == nil {
+		return nil
+	}
+	if minSimilarity <= 0 || minSimilarity > 1 {
+		minSimilarity = 1
+	}
+
+	var merges []BehaviorMerge
+	for di := range doc.Domains {
+		for fi := range doc.Domains[di].Features {
+			feature := &doc.Domains[di].Features[fi]
+			feature.Behaviors, merges = dedupFeature(feature.Behaviors, minSimilarity, merges)
+		}
+	}
+	return merges
+}
+
+func dedupFeature(behaviors []Behavior, minSimilarity float64, merges []BehaviorMerge) ([]Behavior, []BehaviorMerge) {
+	if len(behaviors) < 2 {
+		return behaviors, merges
+	}
+
+	kept := make([]Behavior, 0, len(behaviors))
+	keys := make([][]rune, 0, len(behaviors))
+	for _, b := range behaviors {
+		key := dedupKey(b.Description)
+		match, similarity := -1, 0.0
+		if len(key) > 0 {
+			for i, k := range keys {
+				if s := textSimilarity(key, k, minSimilarity); s >= minSimilarity && s > similarity {
+					match, similarity = i, s
+				}
+			}
+		}
+		if match < 0 {
+			kept = append(kept, b)
+			keys = append(keys, key)
+			continue
+		}
+
+		merged := MergedTestCase{
+			Description:  b.Description,
+			OriginalName: b.OriginalName,
+			Similarity:   similarity,
+			TestCaseID:   b.TestCaseID,
+		}
+		merges = append(merges, BehaviorMerge{Into: kept[match], Merged: merged})
+		kept[match].Merged = append(kept[match].Merged, merged)
+		kept[match].Merged = append(kept[match].Merged, b.Merged...)
+		kept[match].TestCaseIDs = append(kept[match].LinkedTestCaseIDs(), b.LinkedTestCaseIDs()...)
+	}
+	return kept, merges
+}
+
+// dedupKey lowercases the description and reduces punctuation and spacing to
+// single spaces.
+func dedupKey(description string) []rune {
+	fields := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return []rune(strings.Join(fields, " "))
+}
+
+// textSimilarity is 1 minus the edit distance relative to the longer text.
+// Returns 0 without computing the distance when the length difference alone
+// keeps the texts below minSimilarity.
+func textSimilarity(a, b []rune, minSimilarity float64) float64 {
+	if string(a) == string(b) {
+		return 1
+	}
+	longer := max(len(a), len(b))
+	if diff := max(len(a)-len(b), len(b)-len(a)); 1-float64(diff)/float64(longer) < minSimilarity {
+		return 0
+	}
+	return 1 - float64(editDistance(a, b))/float64(longer)
+}
+
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
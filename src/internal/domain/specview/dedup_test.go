@@ -0,0 +1,80 @@
+package specview
+
+import "testing"
+
+func dedupDocument(behaviors ...Behavior) *SpecDocument {
+	return &SpecDocument{
+		Domains: []Domain{{
+			Name:     "Auth",
+			Features: []Feature{{Name: "Login", Behaviors: behaviors}},
+		}},
+	}
+}
+
+func TestDedupBehaviors(t *testing.T) {
+	t.Run("merges exact duplicates after normalization", func(t *testing.T) {
+		doc := dedupDocument(
+			Behavior{Description: "Rejects an empty password", OriginalName: "case 1", TestCaseID: "tc-1"},
+			Behavior{Description: "Accepts a valid password", OriginalName: "case 2", TestCaseID: "tc-2"},
+			Behavior{Description: "rejects an empty password.", OriginalName: "case 3", TestCaseID: "tc-3"},
+		)
+
+		merges := DedupBehaviors(doc, 1)
+
+		behaviors := doc.Domains[0].Features[0].Behaviors
+		if len(behaviors) != 2 || len(merges) != 1 {
+			t.Fatalf("expected 2 behaviors and 1 merge, got %d and %d", len(behaviors), len(merges))
+		}
+		merged := behaviors[0].Merged
+		if len(merged) != 1 || merged[0].TestCaseID != "tc-3" || merged[0].Similarity != 1 {
+			t.Errorf("unexpected merged test cases: %+v", merged)
+		}
+		if merges[0].Into.TestCaseID != "tc-1" || merges[0].Merged.OriginalName != "case 3" {
+			t.Errorf("unexpected merge: %+v", merges[0])
+		}
+		if ids := behaviors[0].LinkedTestCaseIDs(); len(ids) != 2 || ids[0] != "tc-1" || ids[1] != "tc-3" {
+			t.Errorf("linked test cases = %v, want [tc-1 tc-3]", ids)
+		}
+	})
+
+	t.Run("merges near duplicates at the similarity threshold", func(t *testing.T) {
+		doc := dedupDocument(
+			Behavior{Description: "Rejects a password shorter than 8 characters", TestCaseID: "tc-1"},
+			Behavior{Description: "Rejects a password shorter than 12 characters", TestCaseID: "tc-2"},
+		)
+
+		if merges := DedupBehaviors(doc, 0.9); len(merges) != 1 {
+			t.Fatalf("expected 1 merge, got %d", len(merges))
+		}
+		if sim := doc.Domains[0].Features[0].Behaviors[0].Merged[0].Similarity; sim >= 1 || sim < 0.9 {
+			t.Errorf("similarity = %v, want in [0.9, 1)", sim)
+		}
+	})
+
+	t.Run("keeps distinct behaviors", func(t *testing.T) {
+		doc := dedupDocument(
+			Behavior{Description: "Rejects an empty password", TestCaseID: "tc-1"},
+			Behavior{Description: "Locks the account after five failures", TestCaseID: "tc-2"},
+			Behavior{Description: "", TestCaseID: "tc-3"},
+			Behavior{Description: "", TestCaseID: "tc-4"},
+		)
+
+		if merges := DedupBehaviors(doc, DefaultDedupSimilarity); len(merges) != 0 {
+			t.Errorf("expected no merges, got %+v", merges)
+		}
+		if n := len(doc.Domains[0].Features[0].Behaviors); n != 4 {
+			t.Errorf("expected 4 behaviors, got %d", n)
+		}
+	})
+
+	t.Run("does not merge across features", func(t *testing.T) {
+		doc := &SpecDocument{Domains: []Domain{{Features: []Feature{
+			{Behaviors: []Behavior{{Description: "Sends a confirmation email"}}},
+			{Behaviors: []Behavior{{Description: "Sends a confirmation email"}}},
+		}}}}
+
+		if merges := DedupBehaviors(doc, DefaultDedupSimilarity); len(merges) != 0 {
+			t.Errorf("expected no merges, got %+v", merges)
+		}
+	})
+}
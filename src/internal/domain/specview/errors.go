@@ -1,11 +1,53 @@
 package specview
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	ErrAIUnavailable    = errors.New("AI service unavailable")
 	ErrAnalysisNotFound = errors.New("analysis not found")
+	ErrDocumentNotFound = errors.New("spec document not found")
 	ErrInvalidInput     = errors.New("invalid input")
 	ErrOutputTruncated  = errors.New("AI output truncated due to token limit")
 	ErrRateLimited      = errors.New("rate limit exceeded")
+
+	// ErrEncryptionUnavailable means a document is, or must be, encrypted but
+	// no document keyring is configured.
+	ErrEncryptionUnavailable = errors.New("document encryption unavailable")
+
+	// ErrGenerationCancelled means the user cancelled the generation while it ran.
+	ErrGenerationCancelled = errors.New("spec generation cancelled")
+
+	// ErrDocumentPinned means the document version is already pinned to
+	// another release.
+	ErrDocumentPinned = errors.New("spec document already pinned")
+
+	// ErrDocumentNotPinned means the document version has no pin to remove.
+	ErrDocumentNotPinned = errors.New("spec document not pinned")
+
+	// ErrDocumentNotDeleted means the document version has no soft delete to
+	// undo.
+	ErrDocumentNotDeleted = errors.New("spec document not deleted")
+
+	// ErrInvalidOutput means an AI response failed validation and could not
+	// be repaired.
+	ErrInvalidOutput = errors.New("invalid AI output")
 )
+
+// OutputValidationError lists why the AI response of a phase was rejected.
+// It matches ErrInvalidOutput.
+type OutputValidationError struct {
+	Phase    string
+	Problems []string
+}
+
+func (e *OutputValidationError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrInvalidOutput, e.Phase, strings.Join(e.Problems, "; "))
+}
+
+func (e *OutputValidationError) Unwrap() error {
+	return ErrInvalidOutput
+}
@@ -0,0 +1,17 @@
+package specview
+
+// EstimatedTokensPerTest is a rough token cost of one test in a Phase 1 or
+// Phase 2 prompt and response, used to price work before running it.
+const EstimatedTokensPerTest = 150
+
+// SpecViewEstimate predicts what a generation request would cost, from the
+// test inventory and the caches, without calling the AI.
+type SpecViewEstimate struct {
+	CachedBehaviors      int   // tests whose behavior is in the behavior cache
+	ClassificationCached bool  // Phase 1 starts from the classification cache
+	DocumentCached       bool  // the user's existing document is served at no cost
+	EstimatedQuota       int   // behaviors billed: tests missing from the behavior cache
+	EstimatedTokens      int64 // Phase 1 and Phase 2 tokens; the summary is not included
+	NewTests             int   // tests Phase 1 places into the cached classification
+	TestCount            int
+}
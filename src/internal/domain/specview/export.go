@@ -0,0 +1,244 @@
+package specview
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// ExportFormat is a rendering of a spec document for consumers outside SpecVital.
+type ExportFormat string
+
+const (
+	ExportConfluence ExportFormat = "confluence" // Confluence storage format (XHTML)
+	ExportHTML       ExportFormat = "html"
+	ExportMarkdown   ExportFormat = "markdown"
+)
+
+var exportFormats = map[ExportFormat]struct {
+	contentType string
+	extension   string
+}{
+	ExportConfluence: {contentType: "application/xhtml+xml; charset=utf-8", extension: ".xml"},
+	ExportHTML:       {contentType: "text/html; charset=utf-8", extension: ".html"},
+	ExportMarkdown:   {contentType: "text/markdown; charset=utf-8", extension: ".md"},
+}
+
+// ParseExportFormat validates a format name.
+func ParseExportFormat(s string) (ExportFormat, error) {
+	format := ExportFormat(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := exportFormats[format]; !ok {
+		return "", fmt.Errorf("%w: unsupported export format %q", ErrInvalidInput, s)
+	}
+	return format, nil
+}
+
+// ExportSource is what an export renders: the full document and the
+// repository it documents.
+type ExportSource struct {
+	Document       *SpecDocument
+	Repository     string          // owner/name, empty when unknown
+	SharedFixtures []SharedFixture // of the document's analysis, most imported first
+}
+
+// ExportArtifact is a rendered spec document.
+type ExportArtifact struct {
+	ContentType string
+	Data        []byte
+	DocumentID  string
+	FileName    string
+	Format      ExportFormat
+}
+
+// ExportRepository loads documents to export and stores the rendered artifacts.
+// Postgres is the default store; an object storage or Confluence uploader can
+// implement the same port.
+type ExportRepository interface {
+	// GetExportSource returns ErrDocumentNotFound if the document does not exist.
+	GetExportSource(ctx context.Context, documentID string) (*ExportSource, error)
+	// SaveExport replaces the artifact of the same document and format.
+	SaveExport(ctx context.Context, artifact *ExportArtifact) error
+}
+
+// RenderDocument renders the document in the given format. Domains, features
+// and behaviors keep their stored order. A behavior without a description is
+// rendered with its original test name. Behaviors relying on shared fixtures
+// are listed again, per fixture, in a closing section.
+func RenderDocument(src ExportSource, format ExportFormat) (*ExportArtifact, error) {
+	meta, ok := exportFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported export format %q", ErrInvalidInput, format)
+	}
+	if src.Document == nil {
+		return nil, fmt.Errorf("%w: document is required", ErrInvalidInput)
+	}
+
+	var data string
+	switch format {
+	case ExportMarkdown:
+		data = renderMarkdown(src)
+	case ExportHTML:
+		data = renderHTML(src)
+	case ExportConfluence:
+		data = renderConfluence(src)
+	}
+
+	return &ExportArtifact{
+		ContentType: meta.contentType,
+		Data:        []byte(data),
+		DocumentID:  src.Document.ID,
+		FileName:    fmt.Sprintf("spec-v%d%s", src.Document.Version, meta.extension),
+		Format:      format,
+	}, nil
+}
+
+func exportTitle(src ExportSource) string {
+	if src.Repository == "" {
+		return "Specification"
+	}
+	return src.Repository + " Specification"
+}
+
+func exportSubtitle(doc *SpecDocument) string {
+	return fmt.Sprintf("Version %d · %s · %s", doc.Version, doc.Language, doc.ModelID)
+}
+
+func behaviorText(b Behavior) string {
+	if b.Description != "" {
+		return b.Description
+	}
+	return b.OriginalName
+}
+
+func renderMarkdown(src ExportSource) string {
+	doc := src.Document
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n_%s_\n", markdownText(exportTitle(src)), exportSubtitle(doc))
+	if doc.ExecutiveSummary != "" {
+		fmt.Fprintf(&sb, "\n%s\n", doc.ExecutiveSummary)
+	}
+	for _, domain := range doc.Domains {
+		fmt.Fprintf(&sb, "\n## %s\n", markdownText(domain.Name))
+		if domain.Description != "" {
+			fmt.Fprintf(&sb, "\n%s\n", domain.Description)
+		}
+		for _, feature := range domain.Features {
+			fmt.Fprintf(&sb, "\n### %s\n", markdownText(feature.Name))
+			if feature.Description != "" {
+				fmt.Fprintf(&sb, "\n%s\n", feature.Description)
+			}
+			if len(feature.Behaviors) > 0 {
+				sb.WriteString("\n")
+			}
+			for _, behavior := range feature.Behaviors {
+				fmt.Fprintf(&sb, "- %s\n", markdownText(behaviorText(behavior)))
+			}
+		}
+	}
+	if usages := FixtureUsages(doc, src.SharedFixtures); len(usages) > 0 {
+		fmt.Fprintf(&sb, "\n## %s\n\n%s\n", sharedFixturesTitle, sharedFixturesIntro)
+		for _, usage := range usages {
+			fmt.Fprintf(&sb, "\n### `%s`\n\n_%s_\n\n", markdownText(usage.Fixture.Path), fixtureSubtitle(usage.Fixture))
+			listed, rest := fixtureBehaviors(usage)
+			for _, behavior := range listed {
+				fmt.Fprintf(&sb, "- %s\n", markdownText(behaviorText(behavior)))
+			}
+			if rest > 0 {
+				fmt.Fprintf(&sb, "- _and %d more_\n", rest)
+			}
+		}
+	}
+	return sb.String()
+}
+
+const (
+	sharedFixturesTitle = "Shared fixtures"
+	sharedFixturesIntro = "Behaviors relying on helpers or fixtures imported by many test files. A change to one of them puts all of its behaviors at risk."
+)
+
+func fixtureSubtitle(f SharedFixture) string {
+	if f.TestFileCount == 1 {
+		return "Imported by 1 test file"
+	}
+	return fmt.Sprintf("Imported by %d test files", f.TestFileCount)
+}
+
+// fixtureBehaviors returns the behaviors of usage to list and how many more there are.
+func fixtureBehaviors(usage FixtureUsage) ([]Behavior, int) {
+	if len(usage.Behaviors) <= MaxFixtureBehaviors {
+		return usage.Behaviors, 0
+	}
+	return usage.Behaviors[:MaxFixtureBehaviors], len(usage.Behaviors) - MaxFixtureBehaviors
+}
+
+// markdownText keeps a name on one line so it cannot end a heading or list item early.
+func markdownText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func renderHTML(src ExportSource) string {
+	title := html.EscapeString(exportTitle(src))
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<!DOCTYPE html>\n<html lang=\"%s\">\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n",
+		html.EscapeString(string(src.Document.Language)), title)
+	writeHTMLBody(&sb, src, func(sb *strings.Builder, summary string) {
+		fmt.Fprintf(sb, "<p>%s</p>\n", html.EscapeString(summary))
+	})
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
+
+// renderConfluence writes the body of a Confluence page in storage format,
+// with the executive summary in an info panel.
+func renderConfluence(src ExportSource) string {
+	var sb strings.Builder
+	writeHTMLBody(&sb, src, func(sb *strings.Builder, summary string) {
+		fmt.Fprintf(sb, "<ac:structured-macro ac:name=\"info\"><ac:rich-text-body><p>%s</p></ac:rich-text-body></ac:structured-macro>\n",
+			html.EscapeString(summary))
+	})
+	return sb.String()
+}
+
+func writeHTMLBody(sb *strings.Builder, src ExportSource, writeSummary func(*strings.Builder, string)) {
+	doc := src.Document
+	fmt.Fprintf(sb, "<h1>%s</h1>\n<p><em>%s</em></p>\n", html.EscapeString(exportTitle(src)), html.EscapeString(exportSubtitle(doc)))
+	if doc.ExecutiveSummary != "" {
+		writeSummary(sb, doc.ExecutiveSummary)
+	}
+	for _, domain := range doc.Domains {
+		fmt.Fprintf(sb, "<h2>%s</h2>\n", html.EscapeString(domain.Name))
+		if domain.Description != "" {
+			fmt.Fprintf(sb, "<p>%s</p>\n", html.EscapeString(domain.Description))
+		}
+		for _, feature := range domain.Features {
+			fmt.Fprintf(sb, "<h3>%s</h3>\n", html.EscapeString(feature.Name))
+			if feature.Description != "" {
+				fmt.Fprintf(sb, "<p>%s</p>\n", html.EscapeString(feature.Description))
+			}
+			if len(feature.Behaviors) == 0 {
+				continue
+			}
+			sb.WriteString("<ul>\n")
+			for _, behavior := range feature.Behaviors {
+				fmt.Fprintf(sb, "<li>%s</li>\n", html.EscapeString(behaviorText(behavior)))
+			}
+			sb.WriteString("</ul>\n")
+		}
+	}
+	if usages := FixtureUsages(doc, src.SharedFixtures); len(usages) > 0 {
+		fmt.Fprintf(sb, "<h2>%s</h2>\n<p>%s</p>\n", sharedFixturesTitle, html.EscapeString(sharedFixturesIntro))
+		for _, usage := range usages {
+			fmt.Fprintf(sb, "<h3><code>%s</code></h3>\n<p><em>%s</em></p>\n<ul>\n",
+				html.EscapeString(usage.Fixture.Path), fixtureSubtitle(usage.Fixture))
+			listed, rest := fixtureBehaviors(usage)
+			for _, behavior := range listed {
+				fmt.Fprintf(sb, "<li>%s</li>\n", html.EscapeString(behaviorText(behavior)))
+			}
+			if rest > 0 {
+				fmt.Fprintf(sb, "<li><em>and %d more</em></li>\n", rest)
+			}
+			sb.WriteString("</ul>\n")
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package specview
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func newExportSource() ExportSource {
+	return ExportSource{
+		Document: &SpecDocument{
+			Domains: []Domain{{
+				Description: "Money movement",
+				Name:        "Billing & Payments",
+				Features: []Feature{{
+					Name: "Settlement",
+					Behaviors: []Behavior{
+						{Description: "Settles <daily> batches", OriginalName: "TestSettleDaily"},
+						{OriginalName: "TestSettleRetry"},
+					},
+				}},
+			}},
+			ExecutiveSummary: "Covers billing.",
+			ID:               "doc-1",
+			Language:         "English",
+			ModelID:          "gemini-2.5-flash",
+			Version:          3,
+		},
+		Repository: "acme/billing",
+	}
+}
+
+func TestRenderDocument_Markdown(t *testing.T) {
+	artifact, err := RenderDocument(newExportSource(), ExportMarkdown)
+	if err != nil {
+		t.Fatalf("RenderDocument failed: %v", err)
+	}
+
+	want := `# acme/billing Specification
+
+_Version 3 · English · gemini-2.5-flash_
+
+Covers billing.
+
+## Billing & Payments
+
+Money movement
+
+### Settlement
+
+- Settles <daily> batches
+- TestSettleRetry
+`
+	if got := string(artifact.Data); got != want {
+		t.Errorf("markdown mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if artifact.FileName != "spec-v3.md" || artifact.ContentType != "text/markdown; charset=utf-8" {
+		t.Errorf("unexpected artifact metadata: %+v", artifact)
+	}
+}
+
+func TestRenderDocument_HTMLEscapes(t *testing.T) {
+	for _, format := range []ExportFormat{ExportHTML, ExportConfluence} {
+		t.Run(string(format), func(t *testing.T) {
+			artifact, err := RenderDocument(newExportSource(), format)
+			if err != nil {
+				t.Fatalf("RenderDocument failed: %v", err)
+			}
+			data := string(artifact.Data)
+			if !strings.Contains(data, "<h2>Billing &amp; Payments</h2>") {
+				t.Errorf("domain heading should be escaped:\n%s", data)
+			}
+			if !strings.Contains(data, "<li>Settles &lt;daily&gt; batches</li>") {
+				t.Errorf("behavior should be escaped:\n%s", data)
+			}
+		})
+	}
+
+	artifact, _ := RenderDocument(newExportSource(), ExportConfluence)
+	if !strings.Contains(string(artifact.Data), `<ac:structured-macro ac:name="info">`) {
+		t.Errorf("confluence export should put the summary in an info panel:\n%s", artifact.Data)
+	}
+	if strings.Contains(string(artifact.Data), "<html") {
+		t.Error("confluence export should be a page body")
+	}
+}
+
+func TestRenderDocument_SharedFixtures(t *testing.T) {
+	src := newExportSource()
+	behaviors := src.Document.Domains[0].Features[0].Behaviors
+	behaviors[0].TestCaseID = "tc-daily"
+	behaviors[1].TestCaseIDs = []string{"tc-retry"}
+	src.SharedFixtures = []SharedFixture{
+		{Path: "testutil/ledger", TestCaseIDs: []string{"tc-daily", "tc-retry"}, TestFileCount: 7},
+		{Path: "testutil/unused", TestCaseIDs: []string{"tc-other"}, TestFileCount: 5},
+	}
+
+	artifact, err := RenderDocument(src, ExportMarkdown)
+	if err != nil {
+		t.Fatalf("RenderDocument failed: %v", err)
+	}
+	data := string(artifact.Data)
+	want := "\n### `testutil/ledger`\n\n_Imported by 7 test files_\n\n- Settles <daily> batches\n- TestSettleRetry\n"
+	if !strings.HasSuffix(data, want) {
+		t.Errorf("markdown should end with the fixture section:\n%s", data)
+	}
+	if strings.Contains(data, "testutil/unused") {
+		t.Error("fixtures no behavior relies on should be left out")
+	}
+
+	artifact, err = RenderDocument(src, ExportHTML)
+	if err != nil {
+		t.Fatalf("RenderDocument failed: %v", err)
+	}
+	if !strings.Contains(string(artifact.Data), "<h3><code>testutil/ledger</code></h3>") {
+		t.Errorf("html should list the fixture:\n%s", artifact.Data)
+	}
+}
+
+func TestFixtureUsages(t *testing.T) {
+	behaviors := make([]Behavior, MaxFixtureBehaviors+2)
+	ids := make([]string, len(behaviors))
+	for i := range behaviors {
+		ids[i] = fmt.Sprintf("tc-%d", i)
+		behaviors[i] = Behavior{OriginalName: ids[i], TestCaseID: ids[i]}
+	}
+	doc := &SpecDocument{Domains: []Domain{{Features: []Feature{{Behaviors: behaviors}}}}}
+
+	usages := FixtureUsages(doc, []SharedFixture{{Path: "conftest.py", TestCaseIDs: ids}})
+	if len(usages) != 1 || len(usages[0].Behaviors) != len(behaviors) {
+		t.Fatalf("usages = %+v", usages)
+	}
+	listed, rest := fixtureBehaviors(usages[0])
+	if len(listed) != MaxFixtureBehaviors || rest != 2 {
+		t.Errorf("listed %d, rest %d", len(listed), rest)
+	}
+	if FixtureUsages(doc, nil) != nil {
+		t.Error("expected no usages without fixtures")
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	if format, err := ParseExportFormat(" Markdown "); err != nil || format != ExportMarkdown {
+		t.Errorf("ParseExportFormat = %q, %v", format, err)
+	}
+	if _, err := ParseExportFormat("pdf"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for pdf, got %v", err)
+	}
+}
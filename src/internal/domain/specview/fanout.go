@@ -0,0 +1,49 @@
+package specview
+
+import (
+	"context"
+	"time"
+)
+
+// Phase2FanOut distributes Phase 2 across child jobs, one per domain, so large
+// documents convert in parallel on several worker replicas. Children write their
+// behaviors to the behavior cache, from which the parent assembles the document.
+type Phase2FanOut interface {
+	// ChildStatus summarizes the child jobs dispatched by the parent job.
+	ChildStatus(ctx context.Context, parentJobID int64) (Phase2ChildStatus, error)
+	// DispatchDomains enqueues one child job per task.
+	DispatchDomains(ctx context.Context, tasks []Phase2DomainTask) error
+}
+
+// Phase2DomainTask is the Phase 2 work for a single domain.
+type Phase2DomainTask struct {
+	AnalysisID      string
+	BudgetTenantID  string // organization the parent's budget charges, empty when it has none
+	CacheScope      string // the parent's behavior cache scope
+	Domain          DomainGroup
+	ForceRegenerate bool      // regenerate behaviors even when cached
+	Glossary        *Glossary // the parent's glossary, so children convert with the same terms
+	Language        Language
+	ModelID         string
+	ModelOverride   string // provider model of a budget-downgraded parent, empty uses the configured models
+	ParentJobID     int64
+	Phase2Model     string    // Phase 2 model the parent's request selected, empty uses the configured model
+	PromptVersion   string    // the parent's prompt version
+	RequestedAt     time.Time // the parent's RequestedAt, so children observe the same cancellations
+	RequestedBy     string    // the parent's user, whose cancellations children observe
+	Style           string
+	UncachedTests   int // tests needing AI conversion when the task was dispatched
+}
+
+// Phase2ChildStatus counts the child jobs of a parent job by outcome.
+type Phase2ChildStatus struct {
+	Active         int // not yet finalized, including retryable
+	Completed      int
+	ConvertedTests int // sum of UncachedTests over completed children, for quota
+	Failed         int // cancelled or discarded; the parent converts their tests itself
+}
+
+// Total returns the number of child jobs dispatched.
+func (s Phase2ChildStatus) Total() int {
+	return s.Active + s.Completed + s.Failed
+}
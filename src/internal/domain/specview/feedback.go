@@ -0,0 +1,55 @@
+package specview
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrFeedbackNotFound means the behavior feedback does not exist, or its
+// behavior was deleted with its document.
+var ErrFeedbackNotFound = errors.New("behavior feedback not found")
+
+// FeedbackStatus is the review state of a user's behavior correction.
+type FeedbackStatus string
+
+const (
+	FeedbackPending  FeedbackStatus = "pending"
+	FeedbackAccepted FeedbackStatus = "accepted"
+	FeedbackRejected FeedbackStatus = "rejected"
+	FeedbackApplied  FeedbackStatus = "applied" // written to the behavior cache
+)
+
+// BehaviorFeedback is a user's correction of a behavior description.
+type BehaviorFeedback struct {
+	AnalysisID           string
+	CacheKeyHash         []byte // cache key of the corrected behavior; nil for behaviors saved without one
+	CorrectedDescription string
+	DocumentID           string
+	ID                   string
+	Status               FeedbackStatus
+	UserID               string
+}
+
+// FeedbackRepository loads behavior feedback and applies accepted feedback.
+type FeedbackRepository interface {
+	// GetFeedback returns ErrFeedbackNotFound if the feedback does not exist.
+	GetFeedback(ctx context.Context, feedbackID string) (*BehaviorFeedback, error)
+
+	// ApplyFeedback writes the corrected description as the human-verified
+	// behavior cache entry and marks the feedback applied, atomically.
+	ApplyFeedback(ctx context.Context, feedback *BehaviorFeedback) error
+}
+
+// VerifiedBehaviorReader is an optional Repository capability for looking up
+// human-verified behavior cache entries, which a forced regeneration keeps.
+type VerifiedBehaviorReader interface {
+	// FindVerifiedBehaviors returns descriptions by hex-encoded cache key hash.
+	FindVerifiedBehaviors(ctx context.Context, cacheKeyHashes [][]byte) (map[string]string, error)
+}
+
+// FeedbackResult reports what applying a behavior feedback did.
+type FeedbackResult struct {
+	Applied    bool // false when an earlier attempt already applied it
+	DocumentID string
+	FeedbackID string
+}
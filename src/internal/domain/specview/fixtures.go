@@ -0,0 +1,54 @@
+package specview
+
+// MaxFixtureBehaviors bounds the behaviors listed per shared fixture in an
+// export; the remainder is only counted.
+const MaxFixtureBehaviors = 10
+
+// SharedFixture is a test helper or fixture module imported by many test
+// files of the analysis, with the test cases of those files.
+type SharedFixture struct {
+	Path          string
+	TestCaseIDs   []string
+	TestFileCount int
+}
+
+// FixtureUsage lists the behaviors of a document relying on a shared fixture.
+type FixtureUsage struct {
+	Behaviors []Behavior // document order
+	Fixture   SharedFixture
+}
+
+// FixtureUsages returns, in the order of fixtures, the behaviors of doc
+// linked to a test case of each fixture. Fixtures no behavior relies on are
+// left out.
+func FixtureUsages(doc *SpecDocument, fixtures []SharedFixture) []FixtureUsage {
+	if doc == nil || len(fixtures) == 0 {
+		return nil
+	}
+
+	var usages []FixtureUsage
+	for _, fixture := range fixtures {
+		testCases := make(map[string]struct{}, len(fixture.TestCaseIDs))
+		for _, id := range fixture.TestCaseIDs {
+			testCases[id] = struct{}{}
+		}
+
+		usage := FixtureUsage{Fixture: fixture}
+		for _, domain := range doc.Domains {
+			for _, feature := range domain.Features {
+				for _, behavior := range feature.Behaviors {
+					for _, id := range behavior.LinkedTestCaseIDs() {
+						if _, ok := testCases[id]; ok {
+							usage.Behaviors = append(usage.Behaviors, behavior)
+							break
+						}
+					}
+				}
+			}
+		}
+		if len(usage.Behaviors) > 0 {
+			usages = append(usages, usage)
+		}
+	}
+	return usages
+}
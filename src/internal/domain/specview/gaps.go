@@ -0,0 +1,161 @@
+package specview
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+)
+
+// MaxGapReportFiles bounds the uncovered file list stored with a report.
+const MaxGapReportFiles = 1000
+
+// GapInputs holds the file inventories needed to compute spec gaps.
+type GapInputs struct {
+	// MappedTestFiles are test files that contributed at least one behavior to the document.
+	MappedTestFiles []string
+	// SourceFiles are all code files recorded for the document's analysis.
+	SourceFiles []string
+}
+
+// GapPackage is a production directory with no linked tests.
+type GapPackage struct {
+	FileCount int    `json:"file_count"`
+	Path      string `json:"path"`
+}
+
+// GapReport lists production code that has no behaviors in a spec document.
+type GapReport struct {
+	CoveredSourceFiles int
+	DocumentID         string
+	TotalSourceFiles   int
+	UncoveredFiles     []string // sorted, capped at MaxGapReportFiles
+	UncoveredPackages  []GapPackage
+}
+
+// GapRepository loads gap inputs and stores the resulting report.
+type GapRepository interface {
+	// GetGapInputs returns empty inventories without error if the analysis recorded no source files.
+	GetGapInputs(ctx context.Context, documentID string) (*GapInputs, error)
+	// SaveGapReport replaces the report attached to the document.
+	SaveGapReport(ctx context.Context, report *GapReport) error
+}
+
+// BuildGapReport links test files to production files by naming convention and
+// reports production files and directories left without linked tests.
+//
+// A test links to a source file when both normalize to the same directory and stem
+// (pkg/auth/login_test.go -> pkg/auth/login.go, src/test/java/a/FooTest.java ->
+// src/main/java/a/Foo.java, tests/auth/test_login.py -> auth/login.py).
+// A directory is covered when any linked test lives in its normalized directory.
+func BuildGapReport(documentID string, inputs GapInputs) *GapReport {
+	fileKeys := make(map[string]bool, len(inputs.MappedTestFiles))
+	dirKeys := make(map[string]bool, len(inputs.MappedTestFiles))
+	for _, t := range inputs.MappedTestFiles {
+		dir, stem := linkKey(t, true)
+		dirKeys[dir] = true
+		if stem != "" {
+			fileKeys[dir+"/"+stem] = true
+		}
+	}
+
+	report := &GapReport{DocumentID: documentID}
+	packageFiles := make(map[string]int)
+
+	for _, f := range inputs.SourceFiles {
+		if IsTestFilePath(f) {
+			continue
+		}
+		report.TotalSourceFiles++
+
+		dir, stem := linkKey(f, false)
+		if fileKeys[dir+"/"+stem] {
+			report.CoveredSourceFiles++
+			continue
+		}
+		report.UncoveredFiles = append(report.UncoveredFiles, f)
+		if !dirKeys[dir] {
+			packageFiles[path.Dir(f)]++
+		}
+	}
+
+	slices.Sort(report.UncoveredFiles)
+	if len(report.UncoveredFiles) > MaxGapReportFiles {
+		report.UncoveredFiles = report.UncoveredFiles[:MaxGapReportFiles]
+	}
+
+	for dir, count := range packageFiles {
+		report.UncoveredPackages = append(report.UncoveredPackages, GapPackage{FileCount: count, Path: dir})
+	}
+	slices.SortFunc(report.UncoveredPackages, func(a, b GapPackage) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return report
+}
+
+// testDirSegments mark directories that only hold tests.
+var testDirSegments = map[string]bool{"__tests__": true, "spec": true, "specs": true, "test": true, "tests": true}
+
+// layoutDirSegments are conventional source roots dropped before linking,
+// so mirrored test trees (src/test/java vs src/main/java) line up.
+var layoutDirSegments = map[string]bool{"lib": true, "main": true, "src": true}
+
+var testStemPrefixes = []string{"test_", "test-"}
+
+var testStemSuffixes = []string{".test", ".spec", "_test", "_spec", "-test", "-spec"}
+
+// camelTestSuffixes are matched case-sensitively so "latest" or "contest" are not tests.
+var camelTestSuffixes = []string{"Tests", "Test", "Spec"}
+
+// IsTestFilePath reports whether a path looks like a test file by common naming conventions.
+func IsTestFilePath(p string) bool {
+	for _, seg := range strings.Split(path.Dir(p), "/") {
+		if testDirSegments[strings.ToLower(seg)] {
+			return true
+		}
+	}
+	base := path.Base(p)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	return stripTestAffixes(stem) != strings.ToLower(stem)
+}
+
+func linkKey(p string, isTest bool) (string, string) {
+	var segs []string
+	for _, seg := range strings.Split(path.Dir(p), "/") {
+		lower := strings.ToLower(seg)
+		if seg == "." || seg == "" || layoutDirSegments[lower] || testDirSegments[lower] {
+			continue
+		}
+		segs = append(segs, lower)
+	}
+
+	base := path.Base(p)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	if isTest {
+		return strings.Join(segs, "/"), stripTestAffixes(stem)
+	}
+	return strings.Join(segs, "/"), strings.ToLower(stem)
+}
+
+// stripTestAffixes removes test naming affixes and returns the lowercased subject stem.
+func stripTestAffixes(stem string) string {
+	for _, suffix := range camelTestSuffixes {
+		if trimmed := strings.TrimSuffix(stem, suffix); trimmed != stem && trimmed != "" {
+			return strings.ToLower(trimmed)
+		}
+	}
+
+	lower := strings.ToLower(stem)
+	for _, prefix := range testStemPrefixes {
+		if trimmed := strings.TrimPrefix(lower, prefix); trimmed != lower && trimmed != "" {
+			return trimmed
+		}
+	}
+	for _, suffix := range testStemSuffixes {
+		if trimmed := strings.TrimSuffix(lower, suffix); trimmed != lower && trimmed != "" {
+			return trimmed
+		}
+	}
+	return lower
+}
@@ -0,0 +1,98 @@
+package specview
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIsTestFilePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"pkg/auth/login_test.go", true},
+		{"src/app.test.tsx", true},
+		{"src/app.spec.ts", true},
+		{"tests/test_login.py", true},
+		{"src/__tests__/Button.tsx", true},
+		{"src/test/java/a/FooTest.java", true},
+		{"spec/models/user_spec.rb", true},
+		{"pkg/auth/login.go", false},
+		{"src/latest.ts", false},
+		{"src/contest/handler.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := IsTestFilePath(tt.path); got != tt.want {
+				t.Errorf("IsTestFilePath(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildGapReport(t *testing.T) {
+	inputs := GapInputs{
+		MappedTestFiles: []string{
+			"pkg/auth/login_test.go",
+			"src/test/java/com/acme/InvoiceTest.java",
+			"tests/billing/test_refund.py",
+			"web/src/__tests__/Button.test.tsx",
+		},
+		SourceFiles: []string{
+			"pkg/auth/login.go",
+			"pkg/auth/logout.go",
+			"pkg/auth/login_test.go",
+			"pkg/payments/charge.go",
+			"pkg/payments/refund.go",
+			"src/main/java/com/acme/Invoice.java",
+			"billing/refund.py",
+			"web/src/Button.tsx",
+			"web/src/Modal.tsx",
+		},
+	}
+
+	report := BuildGapReport("doc-1", inputs)
+
+	if report.DocumentID != "doc-1" {
+		t.Errorf("DocumentID = %q", report.DocumentID)
+	}
+	if report.TotalSourceFiles != 8 {
+		t.Errorf("TotalSourceFiles = %d, want 8 (test files excluded)", report.TotalSourceFiles)
+	}
+	if report.CoveredSourceFiles != 4 {
+		t.Errorf("CoveredSourceFiles = %d, want 4", report.CoveredSourceFiles)
+	}
+
+	wantFiles := []string{"pkg/auth/logout.go", "pkg/payments/charge.go", "pkg/payments/refund.go", "web/src/Modal.tsx"}
+	if !slices.Equal(report.UncoveredFiles, wantFiles) {
+		t.Errorf("UncoveredFiles = %v, want %v", report.UncoveredFiles, wantFiles)
+	}
+
+	// pkg/auth and web/src have linked tests; only pkg/payments is an uncovered package.
+	if len(report.UncoveredPackages) != 1 || report.UncoveredPackages[0] != (GapPackage{FileCount: 2, Path: "pkg/payments"}) {
+		t.Errorf("UncoveredPackages = %+v", report.UncoveredPackages)
+	}
+}
+
+func TestBuildGapReport_Empty(t *testing.T) {
+	report := BuildGapReport("doc-1", GapInputs{})
+	if report.TotalSourceFiles != 0 || len(report.UncoveredFiles) != 0 || len(report.UncoveredPackages) != 0 {
+		t.Errorf("expected empty report, got %+v", report)
+	}
+}
+
+func TestBuildGapReport_CapsFiles(t *testing.T) {
+	files := make([]string, MaxGapReportFiles+10)
+	for i := range files {
+		files[i] = "pkg/big/f" + string(rune('a'+i%26)) + string(rune('a'+i/26%26)) + string(rune('a'+i/676)) + ".go"
+	}
+
+	report := BuildGapReport("doc-1", GapInputs{SourceFiles: files})
+	if len(report.UncoveredFiles) != MaxGapReportFiles {
+		t.Errorf("expected %d files, got %d", MaxGapReportFiles, len(report.UncoveredFiles))
+	}
+	if report.TotalSourceFiles != len(files) {
+		t.Errorf("TotalSourceFiles = %d, want %d", report.TotalSourceFiles, len(files))
+	}
+}
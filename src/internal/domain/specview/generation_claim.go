@@ -0,0 +1,41 @@
+package specview
+
+import (
+	"context"
+	"crypto/sha256"
+	"time"
+)
+
+// GenerationClaims is an optional Repository capability coordinating jobs
+// that generate the same content at the same time. A job claims the content
+// before Phase 1; another job finding it claimed waits for the claim to go,
+// then generates from the behavior and classification caches the first job
+// filled instead of paying for the same AI calls again.
+type GenerationClaims interface {
+	// ClaimGeneration claims key for the job until expiresAt and reports
+	// true, or reports false while another job holds an unexpired claim. A
+	// job may claim a key it already holds, e.g. on a retry.
+	ClaimGeneration(ctx context.Context, key []byte, jobID int64, expiresAt time.Time) (bool, error)
+
+	// ExtendGenerationClaim moves the expiry of the job's claim on key and
+	// reports false when the job no longer holds it.
+	ExtendGenerationClaim(ctx context.Context, key []byte, jobID int64, expiresAt time.Time) (bool, error)
+
+	// ReleaseGeneration removes the job's claim on key. Releasing a claim
+	// the job does not hold is not an error.
+	ReleaseGeneration(ctx context.Context, key []byte, jobID int64) error
+}
+
+// GenerationClaimKey identifies the AI work of a generation: the document
+// content hash, the language and model, and the behavior cache scope. Jobs
+// with equal keys for different users fill the same cache entries.
+func GenerationClaimKey(contentHash []byte, lang Language, modelID, scope string) []byte {
+	h := sha256.New()
+	h.Write(contentHash)
+	h.Write([]byte{0})
+	for _, part := range []string{string(lang), modelID, scope} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,37 @@
+package specview
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Generator names what produced the content of a document.
+type Generator string
+
+const (
+	GeneratorAI        Generator = "ai"        // an AI model
+	GeneratorHeuristic Generator = "heuristic" // rules deriving the document from test names, without AI
+)
+
+// GeneratorMode decides when documents are generated heuristically instead
+// of by the AI provider.
+type GeneratorMode string
+
+const (
+	GeneratorModeAI        GeneratorMode = "ai"        // always the AI; outages fail the job, which is retried
+	GeneratorModeFallback  GeneratorMode = "fallback"  // the AI, falling back to heuristics while it is unavailable
+	GeneratorModeHeuristic GeneratorMode = "heuristic" // always heuristics; no AI calls
+)
+
+// ParseGeneratorMode validates a mode name. Empty is GeneratorModeAI.
+func ParseGeneratorMode(s string) (GeneratorMode, error) {
+	mode := GeneratorMode(strings.ToLower(strings.TrimSpace(s)))
+	switch mode {
+	case "":
+		return GeneratorModeAI, nil
+	case GeneratorModeAI, GeneratorModeFallback, GeneratorModeHeuristic:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: unknown generator mode %q", ErrInvalidInput, s)
+	}
+}
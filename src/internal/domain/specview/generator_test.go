@@ -0,0 +1,22 @@
+package specview
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseGeneratorMode(t *testing.T) {
+	for input, want := range map[string]GeneratorMode{
+		"":          GeneratorModeAI,
+		"ai":        GeneratorModeAI,
+		" Fallback": GeneratorModeFallback,
+		"heuristic": GeneratorModeHeuristic,
+	} {
+		if got, err := ParseGeneratorMode(input); err != nil || got != want {
+			t.Errorf("ParseGeneratorMode(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseGeneratorMode("rules"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package specview
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// GlossaryEntry asks Phase 2 to render a term with the preferred wording,
+// e.g. "settlement" as "정산" in Korean documents.
+type GlossaryEntry struct {
+	Preferred string `json:"preferred"`
+	Term      string `json:"term"`
+}
+
+// MaxGlossaryTermLength is the longest term or preferred wording, in
+// characters, an entry may have.
+const MaxGlossaryTermLength = 100
+
+// Valid reports whether the entry can be listed in a prompt: its term and
+// wording are non-empty, at most MaxGlossaryTermLength characters, and free
+// of line breaks and other control characters, of the "|" separating them
+// and of "<" and ">", which could close the glossary block or open another.
+func (e GlossaryEntry) Valid() bool {
+	return validGlossaryText(e.Term) && validGlossaryText(e.Preferred)
+}
+
+func validGlossaryText(s string) bool {
+	if s == "" || utf8.RuneCountInString(s) > MaxGlossaryTermLength {
+		return false
+	}
+	return !strings.ContainsFunc(s, func(r rune) bool {
+		return unicode.IsControl(r) || r == '|' || r == '<' || r == '>'
+	})
+}
+
+// Glossary is the terminology applied to one document's behaviors.
+// Entries are sorted by term and unique per term (case-insensitive).
+type Glossary struct {
+	Entries []GlossaryEntry `json:"entries"`
+}
+
+// MergeGlossary combines a user's entries with a codebase's. The codebase
+// entry wins when both define a term, since it is the more specific scope.
+// Entries that are not Valid once trimmed are dropped. Returns nil when no
+// entry remains.
+func MergeGlossary(userEntries, codebaseEntries []GlossaryEntry) *Glossary {
+	byTerm := make(map[string]GlossaryEntry)
+	for _, entries := range [][]GlossaryEntry{userEntries, codebaseEntries} {
+		for _, e := range entries {
+			e.Term = strings.TrimSpace(e.Term)
+			e.Preferred = strings.TrimSpace(e.Preferred)
+			if !e.Valid() {
+				continue
+			}
+			byTerm[strings.ToLower(e.Term)] = e
+		}
+	}
+	if len(byTerm) == 0 {
+		return nil
+	}
+
+	g := &Glossary{Entries: make([]GlossaryEntry, 0, len(byTerm))}
+	for _, e := range byTerm {
+		g.Entries = append(g.Entries, e)
+	}
+	sort.Slice(g.Entries, func(i, j int) bool {
+		return strings.ToLower(g.Entries[i].Term) < strings.ToLower(g.Entries[j].Term)
+	})
+	return g
+}
+
+// Version identifies the glossary's content for the behavior cache key, so
+// any change to the entries invalidates descriptions generated under it.
+// Returns "" for an empty glossary, keeping cache keys of documents without
+// one unchanged.
+func (g *Glossary) Version() string {
+	if g == nil || len(g.Entries) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, e := range g.Entries {
+		h.Write(norm.NFC.Bytes([]byte(e.Term)))
+		h.Write([]byte{0})
+		h.Write(norm.NFC.Bytes([]byte(e.Preferred)))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// GlossaryContentHash mixes the glossary version into a content hash, so a
+// glossary edit regenerates the document instead of serving the cached one.
+// Returns contentHash unchanged for an empty glossary.
+func GlossaryContentHash(contentHash []byte, glossary *Glossary) []byte {
+	version := glossary.Version()
+	if version == "" {
+		return contentHash
+	}
+	h := sha256.New()
+	h.Write(contentHash)
+	h.Write([]byte{0})
+	h.Write([]byte("glossary:" + version))
+	return h.Sum(nil)
+}
+
+// GlossaryReader is an optional Repository capability for loading glossaries.
+type GlossaryReader interface {
+	// GetGlossary returns the glossary for the user's document of the
+	// analysis in the given language, merging the user's entries with those
+	// of the analysis's codebase. Returns nil without error when neither has
+	// entries for the language.
+	GetGlossary(ctx context.Context, userID, analysisID string, lang Language) (*Glossary, error)
+}
@@ -0,0 +1,15 @@
+package specview
+
+import (
+	"context"
+	"time"
+)
+
+// DocumentHistoryReader loads past states of a user's documents, for audits
+// and comparing a document with an earlier version.
+type DocumentHistoryReader interface {
+	// GetDocumentAsOf returns the latest full document the user had for the
+	// codebase and language created before asOf, with its domains, features
+	// and behaviors. Returns nil without error when there is none.
+	GetDocumentAsOf(ctx context.Context, userID, codebaseID string, language Language, asOf time.Time) (*SpecDocument, error)
+}
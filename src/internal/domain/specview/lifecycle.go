@@ -0,0 +1,28 @@
+package specview
+
+import (
+	"context"
+	"time"
+)
+
+// DocumentDeletion records the soft delete of a document version. A deleted
+// version and its team slices are hidden from readers and no longer reused,
+// shared or regenerated; retention cleanup removes them after a grace period
+// unless they are restored first.
+type DocumentDeletion struct {
+	DeletedAt  time.Time
+	DeletedBy  string // operator or service token that deleted it; empty when unknown
+	DocumentID string
+}
+
+// DocumentLifecycleRepository soft-deletes and restores document versions.
+type DocumentLifecycleRepository interface {
+	// SoftDeleteDocument deletes the full document. Deleting it again returns
+	// the existing deletion. Returns ErrDocumentNotFound when there is no such
+	// full document, and ErrDocumentPinned when it is pinned.
+	SoftDeleteDocument(ctx context.Context, documentID, deletedBy string) (*DocumentDeletion, error)
+
+	// RestoreDocument undoes the soft delete of the document. Returns
+	// ErrDocumentNotDeleted when it is not deleted.
+	RestoreDocument(ctx context.Context, documentID string) error
+}
@@ -12,10 +12,20 @@ type Language string
 // SpecViewRequest represents a request to generate a spec-view document.
 type SpecViewRequest struct {
 	AnalysisID      string
-	ForceRegenerate bool     // skip cache and create new version
+	DefaultLanguage bool  // Language was defaulted, so repository rules may override it
+	DryRun          bool  // generate with the dry-run provider instead of the AI; nothing is charged
+	ForceRegenerate bool  // skip cache and create new version
+	JobID           int64 // optional: queue job ID, required for Phase 2 fan-out
 	Language        Language
-	ModelID         string   // optional: AI model override
-	UserID          string   // required: document owner
+	ModelID         string     // optional: AI model override
+	Phase1ModelID   string     // optional: model of Phase 1 classification, from the allow-list
+	Phase2ModelID   string     // optional: model of Phase 2 conversion, from the allow-list
+	Phase3ModelID   string     // optional: model of the Phase 3 summary, from the allow-list
+	Project         string     // optional: monorepo project root the document is scoped to
+	RequestedAt     time.Time  // optional: when the job was queued; cancellations requested earlier are ignored
+	TeamSlices      bool       // also save a child document per team named by the repository owner rules
+	TranslateTo     []Language // optional: also save the document translated into these languages
+	UserID          string     // required: document owner
 }
 
 func (r SpecViewRequest) Validate() error {
@@ -34,6 +44,11 @@ func (r SpecViewRequest) Validate() error {
 	return nil
 }
 
+// PhaseModels returns the per-phase models the request selects.
+func (r SpecViewRequest) PhaseModels() PhaseModels {
+	return PhaseModels{Phase1: r.Phase1ModelID, Phase2: r.Phase2ModelID, Phase3: r.Phase3ModelID}
+}
+
 // IsValid checks if the language is not empty.
 func (l Language) IsValid() bool {
 	return l != ""
@@ -41,11 +56,24 @@ func (l Language) IsValid() bool {
 
 // SpecViewResult represents the result of spec-view generation.
 type SpecViewResult struct {
-	AnalysisContext     *AnalysisContext    // repository context for logging
-	BehaviorCacheStats  *BehaviorCacheStats // Phase 2 behavior cache statistics (nil on document cache hit)
-	CacheHit            bool
-	ContentHash         []byte
-	DocumentID          string
+	AnalysisContext    *AnalysisContext    // repository context for logging
+	BehaviorCacheStats *BehaviorCacheStats // Phase 2 behavior cache statistics (nil on document cache hit)
+	CacheHit           bool
+	ContentHash        []byte
+	DocumentID         string
+	Generator          Generator            // what generated the document
+	Quality            *QualityReport       // quality of the generated document, nil on document cache hit
+	RemainingQuota     *int64               // behaviors left this month after the job, nil when unlimited or unknown
+	Shared             bool                 // served from another user's document of a shareable public repository
+	TeamDocumentIDs    map[string]string    // team -> child document ID, when team slices were saved
+	Translations       []TranslatedDocument // one per language of TranslateTo, except failed translations
+}
+
+// TranslatedDocument reports the document of one language of TranslateTo.
+type TranslatedDocument struct {
+	CacheHit   bool // an earlier translation of the same content was reused
+	DocumentID string
+	Language   Language
 }
 
 // BehaviorCacheStats represents cache hit/miss statistics for Phase 2 behavior cache.
@@ -58,9 +86,11 @@ type BehaviorCacheStats struct {
 
 // Phase1Input represents input for domain classification (Phase 1).
 type Phase1Input struct {
-	AnalysisID string // for chunk caching across retries
-	Files      []FileInfo
-	Language   Language
+	AnalysisID  string // for chunk caching across retries
+	Constrained bool   // Taxonomy is the complete list of allowed domains
+	Files       []FileInfo
+	Language    Language
+	Taxonomy    []DomainGroup // optional: maintainer-defined domains the classifier must reuse
 }
 
 // FileInfo represents a test file with its tests and domain hints.
@@ -68,6 +98,7 @@ type FileInfo struct {
 	DomainHints *DomainHints
 	Framework   string
 	Path        string
+	Project     string // monorepo project root; empty for the repository root
 	Tests       []TestInfo
 }
 
@@ -79,7 +110,7 @@ type DomainHints struct {
 
 // TestInfo represents a single test within a file.
 type TestInfo struct {
-	Index      int    // unique identifier for cross-referencing in Phase1Output.FeatureGroup.TestIndices
+	Index      int // unique identifier for cross-referencing in Phase1Output.FeatureGroup.TestIndices
 	Name       string
 	SuitePath  string // nested suite path (e.g., "SuiteA > SuiteB")
 	TestCaseID string // FK to test_cases table
@@ -110,7 +141,9 @@ type FeatureGroup struct {
 type Phase2Input struct {
 	DomainContext string // domain context for better conversion
 	FeatureName   string
+	Glossary      *Glossary // optional terminology; nil leaves wording to the model
 	Language      Language
+	Style         string // optional: "concise" or "detailed" phrasing
 	Tests         []TestForConversion
 }
 
@@ -148,11 +181,21 @@ type SpecDocument struct {
 	AnalysisID       string
 	ContentHash      []byte
 	CreatedAt        time.Time
+	Decisions        []DecisionEvent // generation decision log, saved with the document
 	Domains          []Domain
+	DryRun           bool // generated by the dry-run provider: a preview of the structure, no AI output
 	ExecutiveSummary string
+	GenerationID     string    // ID the decisions were written ahead under; empty when they were not
+	Generator        Generator // what generated the content; empty is GeneratorAI
 	ID               string
+	JobID            int64 // queue job that generated the document; saving it drops the job's checkpoint and claims its token charges
 	Language         Language
 	ModelID          string
+	ParentDocumentID string         // set on team slices: the full document this one was cut from
+	Project          string         // set on documents scoped to one monorepo project
+	PromptVersion    string         // prompt version the document was generated with
+	Quality          *QualityReport // nil on team slices, reclassified versions and documents saved before scoring
+	Team             string         // set on team slices
 	UserID           string
 	Version          int32
 }
@@ -164,6 +207,7 @@ type Domain struct {
 	Features    []Feature
 	ID          string
 	Name        string
+	Slug        string // stable across versions, for deep links
 }
 
 // Feature represents a feature within a domain.
@@ -173,36 +217,64 @@ type Feature struct {
 	Description string
 	ID          string
 	Name        string
+	Slug        string // stable across versions, unique within the domain
 }
 
 // Behavior represents a behavior (converted test) within a feature.
 type Behavior struct {
+	CacheKeyHash []byte // behavior cache key the description was stored under; nil for reclassified versions
 	Confidence   float64
 	Description  string
 	ID           string
-	OriginalName string // original test name before conversion
-	TestCaseID   string // FK to test_cases table
+	Merged       []MergedTestCase // duplicate behaviors collapsed into this one
+	OriginalName string           // original test name before conversion
+	TestCaseID   string           // FK to test_cases table; the primary test case
+	TestCaseIDs  []string         // every test case the behavior describes, TestCaseID first
 }
 
-// AnalysisContext provides repository identification context for logging.
+// LinkedTestCaseIDs returns every test case the behavior describes, the primary
+// one first and without duplicates. A behavior without TestCaseIDs links its
+// TestCaseID alone.
+func (b Behavior) LinkedTestCaseIDs() []string {
+	ids := make([]string, 0, len(b.TestCaseIDs)+1)
+	seen := make(map[string]struct{}, len(b.TestCaseIDs)+1)
+	for _, id := range append([]string{b.TestCaseID}, b.TestCaseIDs...) {
+		if _, dup := seen[id]; dup || id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// AnalysisContext identifies the repository of an analysis, for logging and
+// for scoping what may be reused across users.
 type AnalysisContext struct {
-	Host  string
-	Owner string
-	Repo  string
+	Host    string
+	Owner   string
+	Private bool
+	Repo    string
 }
 
 // BehaviorCacheEntry represents a cached behavior conversion result.
 // Cache key is generated from: test_name + suite_path + file_path + language + model_id
 type BehaviorCacheEntry struct {
-	CacheKeyHash []byte // SHA-256 hash of cache key components
+	AnalysisID   string // analysis whose codebase owns the entry, for stale codebase cleanup; optional
+	CacheKeyHash []byte // hash of cache key components, see GenerateCacheKeyHash
 	Description  string // converted behavior description
 }
 
 // BehaviorCacheKey represents the components used to generate a cache key hash.
 type BehaviorCacheKey struct {
-	FilePath  string
-	Language  Language
-	ModelID   string
-	SuitePath string
-	TestName  string
+	Algorithm       HashAlgorithm // empty hashes with SHA-256
+	FilePath        string
+	GlossaryVersion string // optional; omitted from the hash when empty, like Style
+	Language        Language
+	ModelID         string
+	PromptVersion   string // omitted from the hash when empty or DefaultPromptVersion
+	Scope           string // CacheScopeMode.Scope of the requesting user; omitted from the hash when empty (global)
+	Style           string // optional; omitted from the hash when empty so existing entries stay valid
+	SuitePath       string
+	TestName        string
 }
@@ -2,6 +2,7 @@ package specview
 
 import (
 	"errors"
+	"strings"
 	"testing"
 )
 
@@ -105,3 +106,25 @@ func TestLanguage_IsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestBehavior_LinkedTestCaseIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		behavior Behavior
+		want     []string
+	}{
+		{name: "primary only", behavior: Behavior{TestCaseID: "tc-1"}, want: []string{"tc-1"}},
+		{name: "primary first without duplicates", behavior: Behavior{TestCaseID: "tc-2", TestCaseIDs: []string{"tc-1", "tc-2", "tc-1", "tc-3"}}, want: []string{"tc-2", "tc-1", "tc-3"}},
+		{name: "no primary", behavior: Behavior{TestCaseIDs: []string{"tc-1"}}, want: []string{"tc-1"}},
+		{name: "none", behavior: Behavior{}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.behavior.LinkedTestCaseIDs()
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("LinkedTestCaseIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package specview
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// MaxModelIDLength is the longest model ID documents and caches are stored
+// with (the model_id columns are varchar(100)).
+const MaxModelIDLength = 100
+
+// PhaseModels selects the model of each generation phase for one request.
+// Empty fields keep the model the provider is configured with.
+type PhaseModels struct {
+	Phase1 string // domain classification and placement of new tests
+	Phase2 string // behavior conversion
+	Phase3 string // executive summary
+}
+
+// IsZero reports whether no phase selects a model.
+func (m PhaseModels) IsZero() bool {
+	return m == PhaseModels{}
+}
+
+// Validate checks that every selected model is in allowed.
+func (m PhaseModels) Validate(allowed []string) error {
+	for _, phase := range []struct{ name, model string }{
+		{"phase 1", m.Phase1},
+		{"phase 2", m.Phase2},
+		{"phase 3", m.Phase3},
+	} {
+		if phase.model != "" && !slices.Contains(allowed, phase.model) {
+			return fmt.Errorf("%w: model %q is not allowed for %s", ErrInvalidInput, phase.model, phase.name)
+		}
+	}
+	return nil
+}
+
+// ValidateModelID checks that the document model ID of m and modelID fits in
+// MaxModelIDLength. The cache keys of each phase are never longer.
+func (m PhaseModels) ValidateModelID(modelID string) error {
+	if id := m.DocumentModelID(modelID); len(id) > MaxModelIDLength {
+		return fmt.Errorf("%w: model ID %q exceeds %d characters", ErrInvalidInput, id, MaxModelIDLength)
+	}
+	return nil
+}
+
+// CacheKeys returns the model IDs keying the cached outputs of each phase.
+// Phases without a selected model use modelID, which names the generator's
+// configuration rather than the model each phase runs; selected models are
+// prefixed with their phase, so their outputs never mix with entries cached
+// under modelID even when the names match.
+func (m PhaseModels) CacheKeys(modelID string) PhaseModels {
+	key := func(phase, model string) string {
+		if model == "" {
+			return modelID
+		}
+		return phase + "=" + model
+	}
+	return PhaseModels{
+		Phase1: key("phase1", m.Phase1),
+		Phase2: key("phase2", m.Phase2),
+		Phase3: key("phase3", m.Phase3),
+	}
+}
+
+// DocumentModelID returns the model ID documents generated with m are
+// labelled and looked up by: modelID followed by each selected phase model,
+// or modelID alone when no phase selects one.
+func (m PhaseModels) DocumentModelID(modelID string) string {
+	keys := m.CacheKeys(modelID)
+	id := modelID
+	for _, key := range []string{keys.Phase1, keys.Phase2, keys.Phase3} {
+		if key != modelID {
+			id += "," + key
+		}
+	}
+	return id
+}
+
+type phaseModelsKey struct{}
+
+// WithPhaseModels makes providers use the selected models for the calls of
+// their phase made with ctx. A model set by WithModelOverride takes
+// precedence.
+func WithPhaseModels(ctx context.Context, models PhaseModels) context.Context {
+	return context.WithValue(ctx, phaseModelsKey{}, models)
+}
+
+// RequestedPhaseModels returns the models set by WithPhaseModels.
+func RequestedPhaseModels(ctx context.Context) PhaseModels {
+	models, _ := ctx.Value(phaseModelsKey{}).(PhaseModels)
+	return models
+}
@@ -0,0 +1,63 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPhaseModels_Validate(t *testing.T) {
+	allowed := []string{"gemini-2.5-pro", "gemini-2.5-flash"}
+
+	if err := (PhaseModels{}).Validate(nil); err != nil {
+		t.Errorf("no selection should need no allow-list, got %v", err)
+	}
+	if err := (PhaseModels{Phase1: "gemini-2.5-pro", Phase3: "gemini-2.5-flash"}).Validate(allowed); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (PhaseModels{Phase2: "gemini-ultra"}).Validate(allowed); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestPhaseModels_ValidateModelID(t *testing.T) {
+	models := PhaseModels{Phase1: "gemini-2.5-pro", Phase2: "gemini-2.5-flash", Phase3: "gemini-2.5-flash-lite"}
+	if err := models.ValidateModelID("gemini-2.5-flash"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	long := strings.Repeat("m", 40)
+	models = PhaseModels{Phase1: long, Phase2: long}
+	if err := models.ValidateModelID("gemini-2.5-flash"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for a %d-character model ID, got %v", len(models.DocumentModelID("gemini-2.5-flash")), err)
+	}
+	if err := (PhaseModels{}).ValidateModelID(strings.Repeat("m", MaxModelIDLength+1)); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for a long default model ID, got %v", err)
+	}
+}
+
+func TestPhaseModels_CacheKeys(t *testing.T) {
+	models := PhaseModels{Phase2: "gemini-2.5-flash"}
+
+	want := PhaseModels{Phase1: "gemini-2.5-flash", Phase2: "phase2=gemini-2.5-flash", Phase3: "gemini-2.5-flash"}
+	if got := models.CacheKeys("gemini-2.5-flash"); got != want {
+		t.Errorf("CacheKeys() = %+v, want %+v", got, want)
+	}
+	if got := models.DocumentModelID("gemini-2.5-flash"); got != "gemini-2.5-flash,phase2=gemini-2.5-flash" {
+		t.Errorf("DocumentModelID() = %q", got)
+	}
+	if got := (PhaseModels{}).DocumentModelID("gemini-2.5-flash"); got != "gemini-2.5-flash" {
+		t.Errorf("DocumentModelID() without a selection = %q, want the model ID", got)
+	}
+}
+
+func TestWithPhaseModels(t *testing.T) {
+	if got := RequestedPhaseModels(context.Background()); !got.IsZero() {
+		t.Errorf("expected no models, got %+v", got)
+	}
+	models := PhaseModels{Phase1: "gemini-2.5-pro"}
+	if got := RequestedPhaseModels(WithPhaseModels(context.Background(), models)); got != models {
+		t.Errorf("RequestedPhaseModels() = %+v, want %+v", got, models)
+	}
+}
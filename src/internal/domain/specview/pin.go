@@ -0,0 +1,52 @@
+package specview
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxReleaseTagLength bounds the release tag a document version is pinned to.
+const MaxReleaseTagLength = 255
+
+// DocumentPin fixes a document version to a release of the repository. A
+// pinned version is immutable: retention cleanup keeps it, along with its
+// analysis and team slices, and regeneration campaigns skip it.
+type DocumentPin struct {
+	DocumentID string
+	PinnedAt   time.Time
+	PinnedBy   string // operator or service token that pinned it; empty when unknown
+	ReleaseTag string
+}
+
+// ValidateReleaseTag checks a release tag, e.g. "v1.4.0", before it is pinned.
+func ValidateReleaseTag(tag string) error {
+	if strings.TrimSpace(tag) == "" {
+		return fmt.Errorf("%w: release tag is required", ErrInvalidInput)
+	}
+	if len(tag) > MaxReleaseTagLength {
+		return fmt.Errorf("%w: release tag exceeds %d bytes", ErrInvalidInput, MaxReleaseTagLength)
+	}
+	if strings.IndexFunc(tag, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+		return fmt.Errorf("%w: release tag must not contain whitespace", ErrInvalidInput)
+	}
+	return nil
+}
+
+// DocumentPinRepository pins document versions to releases.
+type DocumentPinRepository interface {
+	// PinDocument pins the full document to releaseTag. Pinning it again to the
+	// same tag returns the existing pin; another tag returns ErrDocumentPinned.
+	// Returns ErrDocumentNotFound when there is no such full document.
+	PinDocument(ctx context.Context, documentID, releaseTag, pinnedBy string) (*DocumentPin, error)
+
+	// UnpinDocument removes the pin of the document, making it subject to
+	// retention and campaigns again. Returns ErrDocumentNotPinned when it has none.
+	UnpinDocument(ctx context.Context, documentID string) error
+
+	// GetDocumentPin returns the pin of the document, or nil without error
+	// when it is not pinned.
+	GetDocumentPin(ctx context.Context, documentID string) (*DocumentPin, error)
+}
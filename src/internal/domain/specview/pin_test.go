@@ -0,0 +1,32 @@
+package specview
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateReleaseTag(t *testing.T) {
+	tests := []struct {
+		tag   string
+		valid bool
+	}{
+		{"v1.4.0", true},
+		{"release/2026-10", true},
+		{"", false},
+		{"  ", false},
+		{"v1 4", false},
+		{"v1\n", false},
+		{strings.Repeat("v", MaxReleaseTagLength), true},
+		{strings.Repeat("v", MaxReleaseTagLength+1), false},
+	}
+	for _, tt := range tests {
+		err := ValidateReleaseTag(tt.tag)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateReleaseTag(%q) = %v, want nil", tt.tag, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("ValidateReleaseTag(%q) = %v, want ErrInvalidInput", tt.tag, err)
+		}
+	}
+}
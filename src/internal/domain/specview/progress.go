@@ -0,0 +1,46 @@
+package specview
+
+import (
+	"context"
+	"time"
+)
+
+// GenerationStatus is the state of a spec generation progress snapshot.
+type GenerationStatus string
+
+const (
+	GenerationRunning   GenerationStatus = "running"
+	GenerationCancelled GenerationStatus = "cancelled"
+	GenerationCompleted GenerationStatus = "completed"
+	GenerationFailed    GenerationStatus = "failed"
+)
+
+// GenerationProgress is a Phase 2 progress snapshot for one analysis and language.
+type GenerationProgress struct {
+	AnalysisID        string
+	CacheHitRate      float64 // share of tests served from the behavior cache
+	CompletedFeatures int
+	ETASeconds        int64 // 0 when unknown
+	FailedFeatures    int
+	Language          Language
+	StartedAt         time.Time
+	Status            GenerationStatus
+	TotalFeatures     int
+}
+
+// GenerationProgressRepository is an optional Repository capability for
+// persisting progress snapshots that the Web app polls or subscribes to.
+type GenerationProgressRepository interface {
+	// SaveGenerationProgress upserts the latest snapshot and notifies listeners.
+	SaveGenerationProgress(ctx context.Context, progress GenerationProgress) error
+}
+
+// CancellationReader is an optional Repository capability for observing users
+// aborting a generation. The Web app records the requests; Phase 2 polls them
+// and stops, keeping the behaviors converted so far in the cache.
+type CancellationReader interface {
+	// IsGenerationCancelled reports whether requestedBy asked to cancel their
+	// generation for the analysis and language at or after since. Generations
+	// of other users for the same analysis and language are unaffected.
+	IsGenerationCancelled(ctx context.Context, analysisID string, language Language, requestedBy string, since time.Time) (bool, error)
+}
@@ -0,0 +1,16 @@
+package specview
+
+// FilterProjectFiles keeps the files of one monorepo project, identified by its
+// root directory as detected at analysis time. An empty project keeps every file.
+func FilterProjectFiles(files []FileInfo, project string) []FileInfo {
+	if project == "" {
+		return files
+	}
+	kept := make([]FileInfo, 0, len(files))
+	for _, f := range files {
+		if f.Project == project {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,19 @@
+package specview
+
+import "testing"
+
+func TestFilterProjectFiles(t *testing.T) {
+	files := []FileInfo{
+		{Path: "README_test.go"},
+		{Path: "services/api/handler_test.go", Project: "services/api"},
+		{Path: "services/web/app.test.ts", Project: "services/web"},
+	}
+
+	if got := FilterProjectFiles(files, ""); len(got) != 3 {
+		t.Errorf("empty project kept %d files, want 3", len(got))
+	}
+	got := FilterProjectFiles(files, "services/api")
+	if len(got) != 1 || got[0].Path != "services/api/handler_test.go" {
+		t.Errorf("unexpected files: %+v", got)
+	}
+}
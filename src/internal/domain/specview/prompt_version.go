@@ -0,0 +1,67 @@
+package specview
+
+import (
+	"context"
+	"crypto/sha256"
+	"hash/fnv"
+)
+
+// DefaultPromptVersion is the prompt version every job uses unless routed to
+// a candidate by a PromptExperiment.
+const DefaultPromptVersion = "v1"
+
+// PromptExperiment routes a share of jobs to a candidate prompt version, so
+// its documents can be compared with those of the default prompts offline.
+type PromptExperiment struct {
+	Candidate string // empty disables the experiment
+	Percent   int    // share of jobs routed to Candidate, 0-100
+}
+
+// Version returns the prompt version for the document of analysisID in lang.
+// The choice hashes both, so retries and regenerations of a document stay on
+// the same prompt.
+func (e PromptExperiment) Version(analysisID string, lang Language) string {
+	if e.Candidate == "" || e.Candidate == DefaultPromptVersion || e.Percent <= 0 {
+		return DefaultPromptVersion
+	}
+	h := fnv.New32a()
+	h.Write([]byte(analysisID))
+	h.Write([]byte{0})
+	h.Write([]byte(lang))
+	if int(h.Sum32()%100) < e.Percent {
+		return e.Candidate
+	}
+	return DefaultPromptVersion
+}
+
+type promptVersionKey struct{}
+
+// WithPromptVersion makes providers use the prompts of version for calls
+// made with ctx.
+func WithPromptVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, promptVersionKey{}, version)
+}
+
+// PromptVersion returns the version set by WithPromptVersion, or
+// DefaultPromptVersion.
+func PromptVersion(ctx context.Context) string {
+	if version, ok := ctx.Value(promptVersionKey{}).(string); ok && version != "" {
+		return version
+	}
+	return DefaultPromptVersion
+}
+
+// PromptContentHash mixes a candidate prompt version into a content hash or
+// classification signature, so documents and classifications made with
+// different prompts are never served for one another. Returns hash unchanged
+// for the default version.
+func PromptContentHash(hash []byte, version string) []byte {
+	if version == "" || version == DefaultPromptVersion {
+		return hash
+	}
+	h := sha256.New()
+	h.Write(hash)
+	h.Write([]byte{0})
+	h.Write([]byte("prompt:" + version))
+	return h.Sum(nil)
+}
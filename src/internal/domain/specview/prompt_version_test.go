@@ -0,0 +1,81 @@
+package specview
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestPromptExperiment_Version(t *testing.T) {
+	t.Run("disabled experiments use the default version", func(t *testing.T) {
+		for _, e := range []PromptExperiment{{}, {Candidate: "v2"}, {Candidate: DefaultPromptVersion, Percent: 100}} {
+			if got := e.Version("analysis-1", "English"); got != DefaultPromptVersion {
+				t.Errorf("%+v: got %q, want %q", e, got, DefaultPromptVersion)
+			}
+		}
+	})
+
+	t.Run("routes about percent of documents to the candidate", func(t *testing.T) {
+		e := PromptExperiment{Candidate: "v2", Percent: 20}
+		routed := 0
+		for i := range 1000 {
+			id := fmt.Sprintf("analysis-%d", i)
+			version := e.Version(id, "English")
+			if version != e.Version(id, "English") {
+				t.Fatalf("version of %s is not deterministic", id)
+			}
+			if version == "v2" {
+				routed++
+			}
+		}
+		if routed < 150 || routed > 250 {
+			t.Errorf("expected about 200 of 1000 documents routed, got %d", routed)
+		}
+	})
+
+	t.Run("full rollout routes every document", func(t *testing.T) {
+		e := PromptExperiment{Candidate: "v2", Percent: 100}
+		if got := e.Version("analysis-1", "Korean"); got != "v2" {
+			t.Errorf("got %q, want v2", got)
+		}
+	})
+}
+
+func TestPromptVersion(t *testing.T) {
+	ctx := context.Background()
+	if got := PromptVersion(ctx); got != DefaultPromptVersion {
+		t.Errorf("got %q, want the default version", got)
+	}
+	if got := PromptVersion(WithPromptVersion(ctx, "v2")); got != "v2" {
+		t.Errorf("got %q, want v2", got)
+	}
+}
+
+func TestPromptContentHash(t *testing.T) {
+	hash := []byte("content hash")
+	if got := PromptContentHash(hash, DefaultPromptVersion); !bytes.Equal(got, hash) {
+		t.Error("expected the default version to leave the hash unchanged")
+	}
+	if got := PromptContentHash(hash, ""); !bytes.Equal(got, hash) {
+		t.Error("expected an empty version to leave the hash unchanged")
+	}
+	v2 := PromptContentHash(hash, "v2")
+	if bytes.Equal(v2, hash) || bytes.Equal(v2, PromptContentHash(hash, "v3")) {
+		t.Error("expected each candidate version to change the hash")
+	}
+}
+
+func TestGenerateCacheKeyHash_PromptVersion(t *testing.T) {
+	key := BehaviorCacheKey{FilePath: "a_test.go", Language: "English", ModelID: "m", TestName: "TestA"}
+	base := GenerateCacheKeyHash(key)
+
+	key.PromptVersion = DefaultPromptVersion
+	if !bytes.Equal(GenerateCacheKeyHash(key), base) {
+		t.Error("expected the default version to keep existing cache keys")
+	}
+	key.PromptVersion = "v2"
+	if bytes.Equal(GenerateCacheKeyHash(key), base) {
+		t.Error("expected a candidate version to change the cache key")
+	}
+}
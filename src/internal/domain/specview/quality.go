@@ -0,0 +1,107 @@
+package specview
+
+// UncategorizedName names the domain and feature holding tests no domain was found for.
+const UncategorizedName = "Uncategorized"
+
+const (
+	// DefaultLowConfidence is the confidence below which a behavior counts as low-confidence.
+	DefaultLowConfidence = 0.5
+	// DefaultQualityFlagBelow is the quality score below which a document is flagged.
+	DefaultQualityFlagBelow = 0.7
+)
+
+// Weights of the ratios subtracted from a perfect quality score.
+const (
+	qualityWeightFallback      = 0.2
+	qualityWeightLowConfidence = 0.5
+	qualityWeightUncategorized = 0.3
+)
+
+// QualityThresholds decide which behaviors count as low-confidence and which
+// documents are flagged.
+type QualityThresholds struct {
+	FlagBelow     float64 // documents scoring below are flagged
+	LowConfidence float64 // behaviors below are low-confidence
+}
+
+// DefaultQualityThresholds returns the default thresholds.
+func DefaultQualityThresholds() QualityThresholds {
+	return QualityThresholds{FlagBelow: DefaultQualityFlagBelow, LowConfidence: DefaultLowConfidence}
+}
+
+// QualityReport scores how much of a document the AI was unsure about.
+type QualityReport struct {
+	Behaviors              int     `json:"behaviors"`
+	FallbackFeatures       int     `json:"fallback_features"` // features whose conversion failed and kept raw test names
+	Features               int     `json:"features"`
+	Flagged                bool    `json:"flagged"`
+	LowConfidenceBehaviors int     `json:"low_confidence_behaviors"`
+	Refined                bool    `json:"refined,omitempty"` // low-confidence features were converted again, see DecisionLowConfidenceRetry
+	Score                  float64 `json:"score"`             // 1 is best, 0 worst
+	UncategorizedBehaviors int     `json:"uncategorized_behaviors"`
+}
+
+// LowConfidenceRatio is the share of behaviors below the low-confidence threshold.
+func (r QualityReport) LowConfidenceRatio() float64 {
+	return ratio(r.LowConfidenceBehaviors, r.Behaviors)
+}
+
+// UncategorizedRatio is the share of behaviors in the Uncategorized domain.
+func (r QualityReport) UncategorizedRatio() float64 {
+	return ratio(r.UncategorizedBehaviors, r.Behaviors)
+}
+
+// FallbackRatio is the share of features whose conversion fell back to raw test names.
+func (r QualityReport) FallbackRatio() float64 {
+	return min(ratio(r.FallbackFeatures, r.Features), 1)
+}
+
+// ScoreDocument scores doc. Fallbacks are counted from decisions, the
+// document's decision log. The score starts at 1 and loses half the
+// low-confidence ratio, 0.3 of the Uncategorized ratio and 0.2 of the fallback
+// ratio. A document without behaviors scores 1.
+func ScoreDocument(doc *SpecDocument, decisions []DecisionEvent, thresholds QualityThresholds) QualityReport {
+	var report QualityReport
+	if doc == nil {
+		report.Score = 1
+		return report
+	}
+
+	for _, d := range doc.Domains {
+		report.Features += len(d.Features)
+		for _, f := range d.Features {
+			for _, b := range f.Behaviors {
+				report.Behaviors++
+				if b.Confidence < thresholds.LowConfidence {
+					report.LowConfidenceBehaviors++
+				}
+				if d.Name == UncategorizedName {
+					report.UncategorizedBehaviors++
+				}
+			}
+		}
+	}
+	for _, e := range decisions {
+		switch e.Kind {
+		case DecisionFeatureFallback:
+			report.FallbackFeatures++
+		case DecisionLowConfidenceRetry:
+			report.Refined = true
+		}
+	}
+
+	report.Score = 1 -
+		qualityWeightLowConfidence*report.LowConfidenceRatio() -
+		qualityWeightUncategorized*report.UncategorizedRatio() -
+		qualityWeightFallback*report.FallbackRatio()
+	report.Score = max(report.Score, 0)
+	report.Flagged = report.Score < thresholds.FlagBelow
+	return report
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
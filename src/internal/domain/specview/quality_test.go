@@ -0,0 +1,62 @@
+package specview
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScoreDocument(t *testing.T) {
+	thresholds := DefaultQualityThresholds()
+
+	t.Run("confident document scores 1", func(t *testing.T) {
+		doc := &SpecDocument{Domains: []Domain{{
+			Name:     "Auth",
+			Features: []Feature{{Name: "Login", Behaviors: []Behavior{{Confidence: 0.9}, {Confidence: 1}}}},
+		}}}
+
+		report := ScoreDocument(doc, nil, thresholds)
+		if report.Score != 1 || report.Flagged || report.Behaviors != 2 || report.Features != 1 {
+			t.Errorf("unexpected report %+v", report)
+		}
+	})
+
+	t.Run("penalizes low confidence, Uncategorized and fallbacks", func(t *testing.T) {
+		doc := &SpecDocument{Domains: []Domain{
+			{
+				Name: "Auth",
+				Features: []Feature{
+					{Name: "Login", Behaviors: []Behavior{{Confidence: 0.9}, {Confidence: 0.2}}},
+					{Name: "Logout", Behaviors: []Behavior{{Confidence: 0}}},
+				},
+			},
+			{
+				Name:     UncategorizedName,
+				Features: []Feature{{Name: UncategorizedName, Behaviors: []Behavior{{Confidence: 0.8}}}},
+			},
+		}}
+		decisions := []DecisionEvent{
+			{Kind: DecisionFeatureFallback, Subject: "Logout"},
+			{Kind: DecisionBehaviorCacheMiss, Subject: "logs in"},
+		}
+
+		report := ScoreDocument(doc, decisions, thresholds)
+		if report.LowConfidenceBehaviors != 2 || report.UncategorizedBehaviors != 1 || report.FallbackFeatures != 1 {
+			t.Fatalf("unexpected counts %+v", report)
+		}
+		// 1 - 0.5*(2/4) - 0.3*(1/4) - 0.2*(1/3)
+		want := 1 - 0.25 - 0.075 - 0.2/3
+		if math.Abs(report.Score-want) > 1e-9 {
+			t.Errorf("score = %v, want %v", report.Score, want)
+		}
+		if !report.Flagged {
+			t.Error("expected the document to be flagged")
+		}
+	})
+
+	t.Run("empty document is not flagged", func(t *testing.T) {
+		report := ScoreDocument(&SpecDocument{}, nil, thresholds)
+		if report.Score != 1 || report.Flagged {
+			t.Errorf("unexpected report %+v", report)
+		}
+	})
+}
@@ -0,0 +1,19 @@
+package specview
+
+import "context"
+
+// QuarantinedOutput is an AI response that could not be parsed against the
+// response schema of its prompt.
+type QuarantinedOutput struct {
+	Error         string
+	ModelID       string
+	Phase         string
+	Response      string
+	SchemaVersion string
+}
+
+// OutputQuarantine keeps unparseable AI outputs for analysis, so schema drift
+// in a model's responses can be studied after the retry that discarded them.
+type OutputQuarantine interface {
+	QuarantineOutput(ctx context.Context, output QuarantinedOutput) error
+}
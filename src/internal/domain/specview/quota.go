@@ -0,0 +1,66 @@
+package specview
+
+import "context"
+
+// DefaultQuotaWarningThreshold is the share of the monthly quota, in percent,
+// at which a user is warned when no thresholds are configured.
+const DefaultQuotaWarningThreshold = 80
+
+// QuotaStatus is a user's spec-view quota for the current month.
+type QuotaStatus struct {
+	Limit *int64 // behaviors allowed this month, nil when unlimited
+	Used  int64  // behaviors billed this month, including the current job
+}
+
+// Remaining returns the behaviors left this month, never below zero.
+// Returns nil when the quota is unlimited.
+func (q *QuotaStatus) Remaining() *int64 {
+	if q == nil || q.Limit == nil {
+		return nil
+	}
+	remaining := max(*q.Limit-q.Used, 0)
+	return &remaining
+}
+
+// CrossedThreshold returns the highest threshold, in percent of the limit,
+// that spending amount crossed: usage was below it before the spend and is at
+// or above it now. Returns 0 when no threshold was crossed or the quota is
+// unlimited.
+func (q *QuotaStatus) CrossedThreshold(amount int64, thresholds []int) int {
+	if q == nil || q.Limit == nil || *q.Limit <= 0 || amount <= 0 {
+		return 0
+	}
+	before := (q.Used - amount) * 100
+	after := q.Used * 100
+	crossed := 0
+	for _, t := range thresholds {
+		mark := int64(t) * *q.Limit
+		if before < mark && after >= mark && t > crossed {
+			crossed = t
+		}
+	}
+	return crossed
+}
+
+// QuotaReader is an optional Repository capability for reading user quotas.
+type QuotaReader interface {
+	// GetQuotaStatus returns the user's quota for the current month.
+	GetQuotaStatus(ctx context.Context, userID string) (*QuotaStatus, error)
+}
+
+// UsageWarning tells a user that a generation pushed their usage past a
+// share of the monthly quota.
+type UsageWarning struct {
+	DocumentID string
+	Limit      int64
+	Remaining  int64
+	Threshold  int // percent of Limit
+	Used       int64
+	UserID     string
+}
+
+// UsageNotifier delivers usage warnings. Implementations send a warning for a
+// threshold at most once per user and month, so retried jobs do not repeat it.
+type UsageNotifier interface {
+	NotifyUsageWarning(ctx context.Context, warning UsageWarning) error
+}
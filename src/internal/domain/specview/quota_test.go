@@ -0,0 +1,46 @@
+package specview
+
+import "testing"
+
+func TestQuotaStatus_Remaining(t *testing.T) {
+	limit := int64(100)
+	if got := (&QuotaStatus{Limit: &limit, Used: 30}).Remaining(); got == nil || *got != 70 {
+		t.Errorf("Remaining() = %v, want 70", got)
+	}
+	if got := (&QuotaStatus{Limit: &limit, Used: 130}).Remaining(); got == nil || *got != 0 {
+		t.Errorf("Remaining() over the limit = %v, want 0", got)
+	}
+	if got := (&QuotaStatus{Used: 30}).Remaining(); got != nil {
+		t.Errorf("Remaining() unlimited = %v, want nil", *got)
+	}
+}
+
+func TestQuotaStatus_CrossedThreshold(t *testing.T) {
+	limit := int64(200)
+	thresholds := []int{80, 95}
+
+	tests := []struct {
+		name   string
+		used   int64
+		amount int64
+		want   int
+	}{
+		{name: "below every threshold", used: 150, amount: 10, want: 0},
+		{name: "reaches first threshold", used: 160, amount: 10, want: 80},
+		{name: "already past first threshold", used: 170, amount: 5, want: 0},
+		{name: "jumps past both thresholds", used: 195, amount: 60, want: 95},
+		{name: "no spend", used: 160, amount: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &QuotaStatus{Limit: &limit, Used: tt.used}
+			if got := status.CrossedThreshold(tt.amount, thresholds); got != tt.want {
+				t.Errorf("CrossedThreshold(%d) = %d, want %d", tt.amount, got, tt.want)
+			}
+		})
+	}
+
+	if got := (&QuotaStatus{Used: 1000}).CrossedThreshold(1000, thresholds); got != 0 {
+		t.Errorf("unlimited quota crossed %d, want 0", got)
+	}
+}
@@ -0,0 +1,27 @@
+package specview
+
+import "context"
+
+// ReclassifyRepository loads a saved document and saves its reclassified version.
+type ReclassifyRepository interface {
+	// GetDocument returns the document with its domains, features and behaviors.
+	// Returns ErrDocumentNotFound if the document does not exist.
+	GetDocument(ctx context.Context, documentID string) (*SpecDocument, error)
+
+	// GetTestDataByAnalysisID retrieves the test inventory, for the suite paths
+	// of the reclassified tests.
+	GetTestDataByAnalysisID(ctx context.Context, analysisID string) ([]FileInfo, error)
+
+	// SaveDocument saves the document as the next version.
+	SaveDocument(ctx context.Context, doc *SpecDocument) error
+}
+
+// ReclassifyResult reports what a reclassification of the Uncategorized domain moved.
+type ReclassifyResult struct {
+	DocumentID       string // new version; empty when nothing moved and no version was saved
+	Moved            int    // behaviors moved out of Uncategorized
+	Remaining        int    // behaviors left in Uncategorized
+	SourceDocumentID string
+	TokenUsage       *TokenUsage
+	Version          int32
+}
@@ -27,6 +27,7 @@ type Repository interface {
 
 	// SaveDocument saves the complete 4-table hierarchy in a single transaction.
 	// This includes spec_documents, spec_domains, spec_features, and spec_behaviors.
+	// A full document gets the next version; a team slice (ParentDocumentID set) keeps doc.Version.
 	SaveDocument(ctx context.Context, doc *SpecDocument) error
 
 	// FindCachedBehaviors looks up cached behavior descriptions by cache key hashes.
@@ -0,0 +1,31 @@
+package specview
+
+import "context"
+
+// SearchIndexEntry is a single behavior flattened with its domain/feature context
+// so a search backend can answer "which behavior covers X?" without joins.
+type SearchIndexEntry struct {
+	BehaviorDescription string
+	BehaviorID          string
+	CodebaseID          string
+	DocumentID          string
+	DocumentVersion     int32
+	DomainName          string
+	FeatureName         string
+	Language            Language
+}
+
+// SearchSourceRepository loads indexable content of a saved spec document.
+type SearchSourceRepository interface {
+	// GetSearchIndexEntries returns behaviors of the document in display order.
+	// Returns an empty slice without error if the document has no behaviors.
+	GetSearchIndexEntries(ctx context.Context, documentID string) ([]SearchIndexEntry, error)
+}
+
+// SearchIndexer writes spec document entries into a search backend.
+// Postgres FTS is the default; an external engine can implement the same port.
+type SearchIndexer interface {
+	// ReplaceDocumentEntries atomically replaces all entries of a document.
+	// Re-indexing the same document is idempotent.
+	ReplaceDocumentEntries(ctx context.Context, documentID string, entries []SearchIndexEntry) error
+}
@@ -0,0 +1,71 @@
+package specview
+
+import (
+	"context"
+	"crypto/sha256"
+)
+
+// EmbeddingDimensions is the length of the test name embeddings stored for
+// the semantic behavior cache.
+const EmbeddingDimensions = 768
+
+// DefaultSemanticCacheSimilarity is the cosine similarity at or above which
+// the cached behavior of a near-identical test is reused.
+const DefaultSemanticCacheSimilarity = 0.95
+
+// Embedder turns texts into embeddings of EmbeddingDimensions values, one per
+// text and in order.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// BehaviorEmbedding is the embedding of a test, keyed like its behavior cache
+// entry.
+type BehaviorEmbedding struct {
+	CacheKeyHash []byte
+	Embedding    []float32
+}
+
+// SimilarBehavior is a cached behavior of a test near the one looked up.
+type SimilarBehavior struct {
+	Description string
+	Similarity  float64 // cosine similarity of the test name embeddings
+}
+
+// SemanticBehaviorCache is an optional Repository capability keeping test
+// name embeddings next to the behavior cache, so tests missing it under their
+// exact key can reuse the behavior of a renamed or reworded test.
+type SemanticBehaviorCache interface {
+	// SaveBehaviorEmbeddings stores embeddings in partition. Keys already
+	// stored keep their embedding.
+	SaveBehaviorEmbeddings(ctx context.Context, partition []byte, embeddings []BehaviorEmbedding) error
+	// FindSimilarBehaviors returns, by position in embeddings, the cached
+	// behavior of the nearest test in partition at least minSimilarity alike.
+	// Embeddings without such a neighbor are absent.
+	FindSimilarBehaviors(ctx context.Context, partition []byte, embeddings [][]float32, minSimilarity float64) (map[int]SimilarBehavior, error)
+}
+
+// SemanticCachePartition hashes the behavior cache key components other than
+// the test itself. Neighbors are only looked up within a partition, so a
+// reused behavior was generated in the same language, by the same model,
+// with the same style, glossary and prompt, and in the same cache scope.
+func SemanticCachePartition(lang Language, modelID, style, glossaryVersion, promptVersion, scope string) []byte {
+	h := sha256.New()
+	for _, part := range []string{string(lang), modelID, style, glossaryVersion, promptVersion} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	if scope != "" {
+		h.Write([]byte("scope:" + scope))
+		h.Write([]byte{0})
+	}
+	return h.Sum(nil)
+}
+
+// SemanticCacheText is the text embedded for a test: its suite path and name.
+func SemanticCacheText(test TestInfo) string {
+	if test.SuitePath == "" {
+		return test.Name
+	}
+	return test.SuitePath + " > " + test.Name
+}
@@ -0,0 +1,35 @@
+package specview
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSemanticCachePartition(t *testing.T) {
+	base := SemanticCachePartition("English", "gemini-2.5-flash", "", "g1", "v1", "")
+	if !bytes.Equal(base, SemanticCachePartition("English", "gemini-2.5-flash", "", "g1", "v1", "")) {
+		t.Error("expected the partition to be deterministic")
+	}
+	for name, other := range map[string][]byte{
+		"language":         SemanticCachePartition("Korean", "gemini-2.5-flash", "", "g1", "v1", ""),
+		"model":            SemanticCachePartition("English", "gemini-2.5-pro", "", "g1", "v1", ""),
+		"style":            SemanticCachePartition("English", "gemini-2.5-flash", "bdd", "g1", "v1", ""),
+		"glossary version": SemanticCachePartition("English", "gemini-2.5-flash", "", "g2", "v1", ""),
+		"prompt version":   SemanticCachePartition("English", "gemini-2.5-flash", "", "g1", "v2", ""),
+		"cache scope":      SemanticCachePartition("English", "gemini-2.5-flash", "", "g1", "v1", "tenant:acme"),
+		"shifted parts":    SemanticCachePartition("English", "gemini-2.5-flash", "g1", "", "v1", ""),
+	} {
+		if bytes.Equal(base, other) {
+			t.Errorf("expected a different %s to change the partition", name)
+		}
+	}
+}
+
+func TestSemanticCacheText(t *testing.T) {
+	if got := SemanticCacheText(TestInfo{Name: "logs in"}); got != "logs in" {
+		t.Errorf("expected the bare name, got %q", got)
+	}
+	if got := SemanticCacheText(TestInfo{Name: "logs in", SuitePath: "Auth > Login"}); got != "Auth > Login > logs in" {
+		t.Errorf("expected the suite path to prefix the name, got %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package specview
+
+import (
+	"context"
+	"strings"
+)
+
+// RepoLicense is what the VCS host reports about a repository when a
+// document of it is about to be shared.
+type RepoLicense struct {
+	Private bool
+	SPDXID  string // empty when the host detected no license
+}
+
+// RepoLicenseLookup fetches the current visibility and license of a repository.
+type RepoLicenseLookup interface {
+	GetRepoLicense(ctx context.Context, host, owner, repo string) (RepoLicense, error)
+}
+
+// SharingRepository is an optional Repository capability for serving one
+// user's document to another user requesting the same codebase and content.
+type SharingRepository interface {
+	// FindShareableDocument returns the latest full document another user generated
+	// for the codebase of the analysis with the same content, language and model.
+	// Returns nil without error when there is none.
+	FindShareableDocument(ctx context.Context, userID, analysisID string, contentHash []byte, language Language, modelID string) (*SpecDocument, error)
+
+	// IsSharingOptedOut reports whether the codebase of the analysis is on the opt-out list.
+	IsSharingOptedOut(ctx context.Context, analysisID string) (bool, error)
+
+	// RecordDocumentShare stores why the document was served to the user.
+	RecordDocumentShare(ctx context.Context, documentID, userID, basis string) error
+}
+
+// shareableLicenses are the SPDX IDs whose terms allow redistributing material
+// derived from the repository. Repositories without a detected license are
+// all rights reserved and never shared.
+var shareableLicenses = map[string]bool{
+	"0BSD":         true,
+	"AGPL-3.0":     true,
+	"Apache-2.0":   true,
+	"BSD-2-Clause": true,
+	"BSD-3-Clause": true,
+	"BSL-1.0":      true,
+	"CC0-1.0":      true,
+	"CC-BY-4.0":    true,
+	"EPL-2.0":      true,
+	"GPL-2.0":      true,
+	"GPL-3.0":      true,
+	"ISC":          true,
+	"LGPL-2.1":     true,
+	"LGPL-3.0":     true,
+	"MIT":          true,
+	"MPL-2.0":      true,
+	"Unlicense":    true,
+	"Zlib":         true,
+}
+
+// SharingBasis returns the basis recorded when a document of the repository is
+// shared, such as "public:MIT", or false when it must not be shared.
+func SharingBasis(license RepoLicense) (string, bool) {
+	if license.Private {
+		return "", false
+	}
+	id := strings.TrimSpace(license.SPDXID)
+	if !shareableLicenses[id] {
+		return "", false
+	}
+	return "public:" + id, true
+}
@@ -0,0 +1,27 @@
+package specview
+
+import "testing"
+
+func TestSharingBasis(t *testing.T) {
+	tests := []struct {
+		name    string
+		license RepoLicense
+		want    string
+		wantOK  bool
+	}{
+		{"permissive", RepoLicense{SPDXID: "Apache-2.0"}, "public:Apache-2.0", true},
+		{"copyleft", RepoLicense{SPDXID: "GPL-3.0"}, "public:GPL-3.0", true},
+		{"no license", RepoLicense{}, "", false},
+		{"unidentified license", RepoLicense{SPDXID: "NOASSERTION"}, "", false},
+		{"private", RepoLicense{Private: true, SPDXID: "MIT"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SharingBasis(tt.license)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("SharingBasis(%+v) = %q, %v; want %q, %v", tt.license, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package specview
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	fallbackDomainSlug  = "domain"
+	fallbackFeatureSlug = "feature"
+	maxSlugLength       = 200 // leaves room for a collision suffix in varchar(255)
+)
+
+// OutlineReader is an optional Repository capability for loading the outline of
+// the user's latest document for the same codebase, whose slugs new versions keep.
+type OutlineReader interface {
+	// GetLatestDocumentOutline returns nil without error when no document exists.
+	// Behaviors only carry their OriginalName.
+	GetLatestDocumentOutline(ctx context.Context, userID, analysisID string, language Language) ([]Domain, error)
+}
+
+// Slugify derives a URL-safe identifier from a display name: letters and digits
+// are lowercased, every other run of characters becomes a single hyphen.
+// Non-Latin letters are kept so names in any document language stay readable.
+func Slugify(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingHyphen = b.Len() > 0
+			continue
+		}
+		if pendingHyphen {
+			b.WriteByte('-')
+			pendingHyphen = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(truncateUTF8(slug, maxSlugLength), "-")
+	}
+	return slug
+}
+
+// AssignSlugs sets stable slugs on domains and features so deep links survive
+// regeneration. An entity keeps the slug of its counterpart in previous when
+// their names normalize to the same slug, or failing that when most of its tests
+// belonged to that counterpart, so renames by the AI keep their links.
+// Domains must already be in their final order: collisions get "-2", "-3", ...
+// suffixes in that order.
+func AssignSlugs(domains []Domain, previous []Domain) {
+	prevDomains := make([]outlineNode, len(previous))
+	for i, d := range previous {
+		prevDomains[i] = outlineNode{slug: d.Slug, tests: domainTestNames(d)}
+	}
+	domainSlugs := assignNodeSlugs(len(domains), prevDomains, fallbackDomainSlug,
+		func(i int) string { return domains[i].Name },
+		func(i int) map[string]struct{} { return domainTestNames(domains[i]) },
+	)
+
+	for di := range domains {
+		domains[di].Slug = domainSlugs[di].slug
+
+		var prevFeatures []outlineNode
+		if match := domainSlugs[di].match; match >= 0 {
+			for _, f := range previous[match].Features {
+				prevFeatures = append(prevFeatures, outlineNode{slug: f.Slug, tests: featureTestNames(f)})
+			}
+		}
+		features := domains[di].Features
+		featureSlugs := assignNodeSlugs(len(features), prevFeatures, fallbackFeatureSlug,
+			func(i int) string { return features[i].Name },
+			func(i int) map[string]struct{} { return featureTestNames(features[i]) },
+		)
+		for fi := range features {
+			features[fi].Slug = featureSlugs[fi].slug
+		}
+	}
+}
+
+type outlineNode struct {
+	slug  string
+	tests map[string]struct{}
+}
+
+type slugAssignment struct {
+	match int // index into previous, -1 when new
+	slug  string
+}
+
+func assignNodeSlugs(
+	n int,
+	previous []outlineNode,
+	fallback string,
+	name func(int) string,
+	tests func(int) map[string]struct{},
+) []slugAssignment {
+	result := make([]slugAssignment, n)
+	claimed := make([]bool, len(previous))
+	taken := make(map[string]bool, n)
+
+	prevBySlug := make(map[string]int, len(previous))
+	for i, p := range previous {
+		if _, exists := prevBySlug[p.slug]; !exists && p.slug != "" {
+			prevBySlug[p.slug] = i
+		}
+	}
+
+	// Exact name matches first, so a renamed sibling cannot steal a slug
+	// from an entity that kept its name.
+	for i := range result {
+		result[i].match = -1
+		slug := Slugify(name(i))
+		if p, ok := prevBySlug[slug]; ok && !claimed[p] {
+			claimed[p] = true
+			result[i] = slugAssignment{match: p, slug: slug}
+			taken[slug] = true
+		}
+	}
+
+	for i := range result {
+		if result[i].match >= 0 {
+			continue
+		}
+		if p := bestOverlap(tests(i), previous, claimed); p >= 0 && !taken[previous[p].slug] {
+			claimed[p] = true
+			result[i] = slugAssignment{match: p, slug: previous[p].slug}
+			taken[previous[p].slug] = true
+			continue
+		}
+
+		base := Slugify(name(i))
+		if base == "" {
+			base = fallback
+		}
+		slug := base
+		for suffix := 2; taken[slug]; suffix++ {
+			slug = base + "-" + strconv.Itoa(suffix)
+		}
+		result[i].slug = slug
+		taken[slug] = true
+	}
+	return result
+}
+
+// bestOverlap returns the unclaimed previous node holding more than half of tests, or -1.
+func bestOverlap(tests map[string]struct{}, previous []outlineNode, claimed []bool) int {
+	best, bestCount := -1, 0
+	for i, p := range previous {
+		if claimed[i] || p.slug == "" {
+			continue
+		}
+		count := 0
+		for t := range tests {
+			if _, ok := p.tests[t]; ok {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = i, count
+		}
+	}
+	if bestCount*2 <= len(tests) {
+		return -1
+	}
+	return best
+}
+
+func domainTestNames(d Domain) map[string]struct{} {
+	names := make(map[string]struct{})
+	for _, f := range d.Features {
+		for _, b := range f.Behaviors {
+			names[b.OriginalName] = struct{}{}
+		}
+	}
+	return names
+}
+
+func featureTestNames(f Feature) map[string]struct{} {
+	names := make(map[string]struct{}, len(f.Behaviors))
+	for _, b := range f.Behaviors {
+		names[b.OriginalName] = struct{}{}
+	}
+	return names
+}
+
+// truncateUTF8 cuts s to at most maxBytes without splitting a rune.
+func truncateUTF8(s string, maxBytes int) string {
+	cut := 0
+	for i, r := range s {
+		size := utf8.RuneLen(r)
+		if i+size > maxBytes {
+			break
+		}
+		cut = i + size
+	}
+	return s[:cut]
+}
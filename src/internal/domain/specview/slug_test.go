@@ -0,0 +1,143 @@
+package specview
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"User Authentication", "user-authentication"},
+		{"  OAuth 2.0 / SSO  ", "oauth-2-0-sso"},
+		{"Payments & Billing", "payments-billing"},
+		{"사용자 인증", "사용자-인증"},
+		{"---", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.name); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("truncates long names on rune boundaries", func(t *testing.T) {
+		got := Slugify(strings.Repeat("인증 ", 100))
+		if len(got) > maxSlugLength {
+			t.Errorf("expected at most %d bytes, got %d", maxSlugLength, len(got))
+		}
+		if strings.HasSuffix(got, "-") || !utf8.ValidString(got) {
+			t.Errorf("unexpected truncation: %q", got)
+		}
+	})
+}
+
+func outlineDomain(name, slug string, features ...Feature) Domain {
+	return Domain{Features: features, Name: name, Slug: slug}
+}
+
+func outlineFeature(name, slug string, tests ...string) Feature {
+	behaviors := make([]Behavior, len(tests))
+	for i, test := range tests {
+		behaviors[i] = Behavior{OriginalName: test}
+	}
+	return Feature{Behaviors: behaviors, Name: name, Slug: slug}
+}
+
+func TestAssignSlugs(t *testing.T) {
+	t.Run("derives slugs from names without a previous document", func(t *testing.T) {
+		domains := []Domain{
+			outlineDomain("Auth", "", outlineFeature("Login Flow", "", "a")),
+			outlineDomain("!!!", "", outlineFeature("", "", "b")),
+		}
+
+		AssignSlugs(domains, nil)
+
+		if domains[0].Slug != "auth" || domains[0].Features[0].Slug != "login-flow" {
+			t.Errorf("unexpected slugs: %+v", domains[0])
+		}
+		if domains[1].Slug != fallbackDomainSlug || domains[1].Features[0].Slug != fallbackFeatureSlug {
+			t.Errorf("expected fallback slugs, got %+v", domains[1])
+		}
+	})
+
+	t.Run("keeps the slug of a renamed entity holding most of its tests", func(t *testing.T) {
+		previous := []Domain{
+			outlineDomain("Authentication", "authentication",
+				outlineFeature("Sign In", "sign-in", "login ok", "login fails", "login locked"),
+			),
+		}
+		domains := []Domain{
+			outlineDomain("Auth & Sessions", "",
+				outlineFeature("Login", "", "login ok", "login fails", "new test"),
+			),
+		}
+
+		AssignSlugs(domains, previous)
+
+		if domains[0].Slug != "authentication" {
+			t.Errorf("expected renamed domain to keep its slug, got %q", domains[0].Slug)
+		}
+		if domains[0].Features[0].Slug != "sign-in" {
+			t.Errorf("expected renamed feature to keep its slug, got %q", domains[0].Features[0].Slug)
+		}
+	})
+
+	t.Run("does not reuse a slug without majority overlap", func(t *testing.T) {
+		previous := []Domain{
+			outlineDomain("Billing", "billing", outlineFeature("Invoices", "invoices", "a", "b")),
+		}
+		domains := []Domain{
+			outlineDomain("Reports", "", outlineFeature("Exports", "", "a", "c", "d")),
+		}
+
+		AssignSlugs(domains, previous)
+
+		if domains[0].Slug != "reports" || domains[0].Features[0].Slug != "exports" {
+			t.Errorf("expected new slugs, got %+v", domains[0])
+		}
+	})
+
+	t.Run("exact matches win over test overlap", func(t *testing.T) {
+		previous := []Domain{
+			outlineDomain("Auth", "auth", outlineFeature("Login", "login", "a", "b")),
+		}
+		domains := []Domain{
+			outlineDomain("Accounts", "", outlineFeature("Login", "", "a", "b")),
+			outlineDomain("Auth", "", outlineFeature("Login", "", "c")),
+		}
+
+		AssignSlugs(domains, previous)
+
+		if domains[1].Slug != "auth" {
+			t.Errorf("expected exact match to keep the slug, got %q", domains[1].Slug)
+		}
+		if domains[0].Slug != "accounts" {
+			t.Errorf("expected new slug for the other domain, got %q", domains[0].Slug)
+		}
+	})
+
+	t.Run("suffixes colliding slugs in order", func(t *testing.T) {
+		domains := []Domain{
+			outlineDomain("Auth", "", outlineFeature("Login", "", "a"), outlineFeature("login", "", "b")),
+			outlineDomain("auth", ""),
+			outlineDomain("AUTH!", ""),
+		}
+
+		AssignSlugs(domains, nil)
+
+		got := []string{domains[0].Slug, domains[1].Slug, domains[2].Slug}
+		if got[0] != "auth" || got[1] != "auth-2" || got[2] != "auth-3" {
+			t.Errorf("unexpected domain slugs: %v", got)
+		}
+		if domains[0].Features[1].Slug != "login-2" {
+			t.Errorf("expected feature collision suffix, got %q", domains[0].Features[1].Slug)
+		}
+	})
+}
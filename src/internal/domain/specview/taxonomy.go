@@ -0,0 +1,197 @@
+package specview
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TaxonomyMode decides what happens to a Phase 1 domain outside a codebase
+// taxonomy.
+type TaxonomyMode string
+
+const (
+	// TaxonomyRemap moves the domain into the canonical domain it names or
+	// most resembles, or into Uncategorized when none is alike enough.
+	TaxonomyRemap TaxonomyMode = "remap"
+	// TaxonomyReject moves every domain the taxonomy does not name into
+	// Uncategorized.
+	TaxonomyReject TaxonomyMode = "reject"
+)
+
+// TaxonomyRemapSimilarity is the least name similarity (edit distance) at
+// which TaxonomyRemap moves a domain into a canonical domain.
+const TaxonomyRemapSimilarity = 0.8
+
+// TaxonomyDomain is one canonical domain of a codebase.
+type TaxonomyDomain struct {
+	Aliases     []string `json:"aliases,omitempty"` // other names of the domain, e.g. "Billing" for "Payments"
+	Description string   `json:"description,omitempty"`
+	Name        string   `json:"name"`
+}
+
+// Taxonomy is the canonical domain list of a codebase. Phase 1 classifies
+// into these domains only, and the classification is constrained to them
+// afterwards according to Mode.
+type Taxonomy struct {
+	Domains []TaxonomyDomain
+	Mode    TaxonomyMode
+}
+
+// TaxonomyReader is an optional Repository capability for loading the
+// taxonomy of an analysis's codebase.
+type TaxonomyReader interface {
+	// GetTaxonomy returns nil without error when the codebase has none.
+	GetTaxonomy(ctx context.Context, analysisID string) (*Taxonomy, error)
+}
+
+// TaxonomyRemapping reports one Phase 1 domain moved by ConstrainToTaxonomy.
+type TaxonomyRemapping struct {
+	From string
+	To   string // canonical domain, or UncategorizedName
+}
+
+// IsEmpty reports whether the taxonomy names no domain.
+func (t *Taxonomy) IsEmpty() bool {
+	return t == nil || len(t.Domains) == 0
+}
+
+// Version identifies the taxonomy's content for the classification cache key
+// and the document content hash, so any edit reclassifies. Returns "" for an
+// empty taxonomy.
+func (t *Taxonomy) Version() string {
+	if t.IsEmpty() {
+		return ""
+	}
+	h := sha256.New()
+	h.Write([]byte(t.Mode))
+	for _, d := range t.Domains {
+		h.Write([]byte{0})
+		h.Write(norm.NFC.Bytes([]byte(d.Name)))
+		h.Write([]byte{1})
+		h.Write(norm.NFC.Bytes([]byte(d.Description)))
+		for _, alias := range d.Aliases {
+			h.Write([]byte{2})
+			h.Write(norm.NFC.Bytes([]byte(alias)))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Anchors converts the taxonomy into Phase 1 anchor domains. Aliases are
+// listed in the description, so the classifier maps them too.
+func (t *Taxonomy) Anchors() []DomainGroup {
+	if t.IsEmpty() {
+		return nil
+	}
+	anchors := make([]DomainGroup, len(t.Domains))
+	for i, d := range t.Domains {
+		description := d.Description
+		if len(d.Aliases) > 0 {
+			description = strings.TrimSpace(description + " (also known as " + strings.Join(d.Aliases, ", ") + ")")
+		}
+		anchors[i] = DomainGroup{Description: description, Name: d.Name}
+	}
+	return anchors
+}
+
+// TaxonomyHash mixes the taxonomy version into a content hash or
+// classification signature. Returns hash unchanged for an empty taxonomy.
+func TaxonomyHash(hash []byte, t *Taxonomy) []byte {
+	version := t.Version()
+	if version == "" {
+		return hash
+	}
+	h := sha256.New()
+	h.Write(hash)
+	h.Write([]byte{0})
+	h.Write([]byte("taxonomy:" + version))
+	return h.Sum(nil)
+}
+
+// ConstrainToTaxonomy returns a copy of the classification whose domains are
+// all canonical or Uncategorized, and the domains it moved. A domain named
+// like a canonical domain or one of its aliases (case-insensitive) takes the
+// canonical name. Any other domain goes to Uncategorized, or under
+// TaxonomyRemap to the canonical domain whose name or alias is at least
+// TaxonomyRemapSimilarity alike. Features of domains that end up with the same
+// name are merged by name.
+func ConstrainToTaxonomy(output *Phase1Output, t *Taxonomy) (*Phase1Output, []TaxonomyRemapping) {
+	if output == nil || t.IsEmpty() {
+		return output, nil
+	}
+
+	type canonical struct {
+		description string
+		name        string
+	}
+	// keys lists the names and aliases in taxonomy order, so the most
+	// similar name is chosen deterministically.
+	var keys []string
+	names := make(map[string]canonical)
+	for _, d := range t.Domains {
+		c := canonical{description: d.Description, name: d.Name}
+		for _, name := range append([]string{d.Name}, d.Aliases...) {
+			key := string(dedupKey(name))
+			if _, dup := names[key]; dup || key == "" {
+				continue
+			}
+			keys = append(keys, key)
+			names[key] = c
+		}
+	}
+
+	resolve := func(name string) (canonical, bool) {
+		key := dedupKey(name)
+		if string(key) == string(dedupKey(UncategorizedName)) {
+			return canonical{name: UncategorizedName}, true
+		}
+		if c, ok := names[string(key)]; ok {
+			return c, true
+		}
+		if t.Mode == TaxonomyReject {
+			return canonical{}, false
+		}
+		var best canonical
+		bestSimilarity := 0.0
+		for _, candidate := range keys {
+			if s := textSimilarity(key, []rune(candidate), TaxonomyRemapSimilarity); s >= TaxonomyRemapSimilarity && s > bestSimilarity {
+				best, bestSimilarity = names[candidate], s
+			}
+		}
+		return best, bestSimilarity > 0
+	}
+
+	result := &Phase1Output{}
+	var remapped []TaxonomyRemapping
+	for _, d := range output.Domains {
+		target, ok := resolve(d.Name)
+		if !ok {
+			target = canonical{name: UncategorizedName}
+		}
+		if target.name != d.Name {
+			remapped = append(remapped, TaxonomyRemapping{From: d.Name, To: target.name})
+		}
+
+		domain, created := findOrAddDomain(result, target.name)
+		if created {
+			domain.Confidence = d.Confidence
+			domain.Description = target.description
+			if domain.Description == "" && ok {
+				domain.Description = d.Description
+			}
+		}
+		for _, feat := range d.Features {
+			merged, created := findOrAddFeature(domain, feat.Name)
+			if created {
+				merged.Confidence = feat.Confidence
+				merged.Description = feat.Description
+			}
+			merged.TestIndices = append(merged.TestIndices, feat.TestIndices...)
+		}
+	}
+	return result, remapped
+}
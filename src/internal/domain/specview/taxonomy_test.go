@@ -0,0 +1,122 @@
+package specview
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func newTaxonomyOutput() *Phase1Output {
+	return &Phase1Output{Domains: []DomainGroup{
+		{Name: "payments", Description: "Charging cards", Features: []FeatureGroup{{Name: "Charge", TestIndices: []int{0}}}},
+		{Name: "Billing", Features: []FeatureGroup{{Name: "Invoices", TestIndices: []int{1}}}},
+		{Name: "Notificaton", Features: []FeatureGroup{{Name: "Email", TestIndices: []int{2}}}},
+		{Name: "Search", Features: []FeatureGroup{{Name: "Filters", TestIndices: []int{3}}}},
+		{Name: "Uncategorized", Features: []FeatureGroup{{Name: "Uncategorized", TestIndices: []int{4}}}},
+	}}
+}
+
+func newTaxonomy(mode TaxonomyMode) *Taxonomy {
+	return &Taxonomy{
+		Domains: []TaxonomyDomain{
+			{Aliases: []string{"Billing"}, Description: "Card payments and invoices", Name: "Payments"},
+			{Name: "Notification"},
+		},
+		Mode: mode,
+	}
+}
+
+func TestConstrainToTaxonomy(t *testing.T) {
+	t.Run("remap moves similar domains into canonical ones", func(t *testing.T) {
+		got, remapped := ConstrainToTaxonomy(newTaxonomyOutput(), newTaxonomy(TaxonomyRemap))
+
+		names := make([]string, len(got.Domains))
+		for i, d := range got.Domains {
+			names[i] = d.Name
+		}
+		if want := []string{"Payments", "Notification", "Uncategorized"}; !reflect.DeepEqual(names, want) {
+			t.Fatalf("domains = %v, want %v", names, want)
+		}
+		if payments := got.Domains[0]; len(payments.Features) != 2 || payments.Description != "Card payments and invoices" {
+			t.Errorf("expected Billing merged into Payments, got %+v", payments)
+		}
+		if features := got.Domains[2].Features; len(features) != 2 || features[0].Name != "Filters" {
+			t.Errorf("expected Search's features in Uncategorized, got %+v", features)
+		}
+
+		want := []TaxonomyRemapping{
+			{From: "payments", To: "Payments"},
+			{From: "Billing", To: "Payments"},
+			{From: "Notificaton", To: "Notification"},
+			{From: "Search", To: "Uncategorized"},
+		}
+		if !reflect.DeepEqual(remapped, want) {
+			t.Errorf("remapped = %+v, want %+v", remapped, want)
+		}
+	})
+
+	t.Run("reject keeps only named domains", func(t *testing.T) {
+		got, remapped := ConstrainToTaxonomy(newTaxonomyOutput(), newTaxonomy(TaxonomyReject))
+
+		if len(got.Domains) != 2 || got.Domains[1].Name != "Uncategorized" {
+			t.Fatalf("expected Payments and Uncategorized, got %+v", got.Domains)
+		}
+		var uncategorized []int
+		for _, f := range got.Domains[1].Features {
+			uncategorized = append(uncategorized, f.TestIndices...)
+		}
+		if len(uncategorized) != 3 {
+			t.Errorf("expected the misspelled and unknown domains rejected, got %v", uncategorized)
+		}
+		if len(remapped) != 4 {
+			t.Errorf("expected 4 remappings, got %+v", remapped)
+		}
+	})
+
+	t.Run("returns the output unchanged without a taxonomy", func(t *testing.T) {
+		output := newTaxonomyOutput()
+		got, remapped := ConstrainToTaxonomy(output, nil)
+		if got != output || remapped != nil {
+			t.Errorf("expected no change, got %+v, %+v", got, remapped)
+		}
+	})
+}
+
+func TestTaxonomyVersion(t *testing.T) {
+	var empty *Taxonomy
+	if v := empty.Version(); v != "" {
+		t.Errorf("nil taxonomy version = %q, want empty", v)
+	}
+
+	remap := newTaxonomy(TaxonomyRemap)
+	if remap.Version() == "" || remap.Version() != newTaxonomy(TaxonomyRemap).Version() {
+		t.Error("versions should match for the same taxonomy")
+	}
+	if remap.Version() == newTaxonomy(TaxonomyReject).Version() {
+		t.Error("version should change with the mode")
+	}
+	aliased := newTaxonomy(TaxonomyRemap)
+	aliased.Domains[1].Aliases = []string{"Alerts"}
+	if remap.Version() == aliased.Version() {
+		t.Error("version should change with the aliases")
+	}
+
+	hash := []byte("signature")
+	if !bytes.Equal(TaxonomyHash(hash, nil), hash) {
+		t.Error("hash should be unchanged without a taxonomy")
+	}
+	if bytes.Equal(TaxonomyHash(hash, remap), hash) {
+		t.Error("taxonomy should change the hash")
+	}
+}
+
+func TestTaxonomyAnchors(t *testing.T) {
+	anchors := newTaxonomy(TaxonomyRemap).Anchors()
+	want := []DomainGroup{
+		{Description: "Card payments and invoices (also known as Billing)", Name: "Payments"},
+		{Name: "Notification"},
+	}
+	if !reflect.DeepEqual(anchors, want) {
+		t.Errorf("anchors = %+v, want %+v", anchors, want)
+	}
+}
@@ -0,0 +1,52 @@
+package specview
+
+// SliceForTeam cuts a team-scoped child document from a saved full document.
+// Only behaviors for which owns returns true are kept; features left without
+// behaviors and domains left without features are dropped. Domain and feature
+// slugs are kept so links resolve the same way in both documents.
+// Returns nil when the team owns none of the document's behaviors.
+func SliceForTeam(doc *SpecDocument, team string, owns func(Behavior) bool) *SpecDocument {
+	var domains []Domain
+	for _, d := range doc.Domains {
+		var features []Feature
+		for _, f := range d.Features {
+			var behaviors []Behavior
+			for _, b := range f.Behaviors {
+				if owns(b) {
+					b.ID = ""
+					behaviors = append(behaviors, b)
+				}
+			}
+			if len(behaviors) == 0 {
+				continue
+			}
+			f.Behaviors = behaviors
+			f.ID = ""
+			features = append(features, f)
+		}
+		if len(features) == 0 {
+			continue
+		}
+		d.Features = features
+		d.ID = ""
+		domains = append(domains, d)
+	}
+	if len(domains) == 0 {
+		return nil
+	}
+
+	return &SpecDocument{
+		AnalysisID:       doc.AnalysisID,
+		ContentHash:      doc.ContentHash,
+		Domains:          domains,
+		DryRun:           doc.DryRun,
+		Generator:        doc.Generator,
+		Language:         doc.Language,
+		ModelID:          doc.ModelID,
+		ParentDocumentID: doc.ID,
+		Project:          doc.Project,
+		Team:             team,
+		UserID:           doc.UserID,
+		Version:          doc.Version,
+	}
+}
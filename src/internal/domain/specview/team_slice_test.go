@@ -0,0 +1,52 @@
+package specview
+
+import "testing"
+
+func TestSliceForTeam(t *testing.T) {
+	doc := &SpecDocument{
+		ContentHash: []byte{1},
+		Domains: []Domain{
+			{ID: "d1", Name: "Billing", Slug: "billing", Features: []Feature{
+				{ID: "f1", Name: "Refunds", Slug: "refunds", Behaviors: []Behavior{
+					{ID: "b1", TestCaseID: "tc-pay-1"},
+					{ID: "b2", TestCaseID: "tc-id-1"},
+				}},
+				{ID: "f2", Name: "Invoices", Slug: "invoices", Behaviors: []Behavior{{ID: "b3", TestCaseID: "tc-id-2"}}},
+			}},
+			{ID: "d2", Name: "Auth", Slug: "auth", Features: []Feature{
+				{ID: "f3", Name: "Login", Slug: "login", Behaviors: []Behavior{{ID: "b4", TestCaseID: "tc-id-3"}}},
+			}},
+		},
+		ExecutiveSummary: "Full summary",
+		ID:               "doc-1",
+		Language:         "English",
+		Version:          3,
+	}
+	ownsPayments := func(b Behavior) bool { return b.TestCaseID == "tc-pay-1" }
+
+	slice := SliceForTeam(doc, "payments", ownsPayments)
+
+	if slice == nil {
+		t.Fatal("expected a slice")
+	}
+	if slice.ParentDocumentID != "doc-1" || slice.Team != "payments" || slice.Version != 3 || slice.ExecutiveSummary != "" {
+		t.Errorf("unexpected slice metadata: %+v", slice)
+	}
+	if len(slice.Domains) != 1 || len(slice.Domains[0].Features) != 1 {
+		t.Fatalf("expected only Billing > Refunds, got %+v", slice.Domains)
+	}
+	feature := slice.Domains[0].Features[0]
+	if feature.Slug != "refunds" || len(feature.Behaviors) != 1 || feature.Behaviors[0].TestCaseID != "tc-pay-1" {
+		t.Errorf("unexpected feature: %+v", feature)
+	}
+	if slice.Domains[0].ID != "" || feature.ID != "" || feature.Behaviors[0].ID != "" {
+		t.Error("expected slice IDs to be cleared for insertion")
+	}
+	if len(doc.Domains[0].Features[0].Behaviors) != 2 || doc.Domains[0].ID != "d1" {
+		t.Error("expected the parent document to be left unchanged")
+	}
+
+	if SliceForTeam(doc, "nobody", func(Behavior) bool { return false }) != nil {
+		t.Error("expected nil for a team that owns nothing")
+	}
+}
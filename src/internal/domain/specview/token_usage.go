@@ -1,19 +1,28 @@
 package specview
 
+import "cmp"
+
 // TokenUsage represents token consumption for a single AI call.
 type TokenUsage struct {
 	CandidatesTokens int32
+	FallbackFrom     string // configured model that was unavailable, empty when it produced the output
 	Model            string
 	PromptTokens     int32
 	TotalTokens      int32
 }
 
-// Add combines two TokenUsage values.
+// Add combines two TokenUsage values. The sum keeps the first model, or the
+// first fallback model when either value fell back, so a fallback is not hidden.
 func (t TokenUsage) Add(other TokenUsage) TokenUsage {
-	return TokenUsage{
+	sum := TokenUsage{
 		CandidatesTokens: t.CandidatesTokens + other.CandidatesTokens,
-		Model:            t.Model,
+		FallbackFrom:     t.FallbackFrom,
+		Model:            cmp.Or(t.Model, other.Model),
 		PromptTokens:     t.PromptTokens + other.PromptTokens,
 		TotalTokens:      t.TotalTokens + other.TotalTokens,
 	}
+	if t.FallbackFrom == "" && other.FallbackFrom != "" {
+		sum.FallbackFrom, sum.Model = other.FallbackFrom, other.Model
+	}
+	return sum
 }
@@ -0,0 +1,32 @@
+package specview
+
+import "context"
+
+// Translator is an optional AIProvider capability for translating the texts
+// of a generated document into another language, at a fraction of the cost
+// of generating the document again in that language.
+type Translator interface {
+	// TranslateTexts returns the texts in the target language, in the order
+	// and number they were given. Returns token usage for the API call.
+	TranslateTexts(ctx context.Context, input TranslationInput) (*TranslationOutput, *TokenUsage, error)
+}
+
+// TranslationInput represents the texts of a document to translate.
+type TranslationInput struct {
+	Glossary       *Glossary // optional: preferred wordings of the target language
+	SourceLanguage Language
+	TargetLanguage Language
+	Texts          []string
+}
+
+// TranslationOutput represents the translated texts.
+type TranslationOutput struct {
+	Texts []string
+}
+
+// DocumentReader is an optional Repository capability for loading a saved
+// document with its content, so a cached document can still be translated.
+type DocumentReader interface {
+	// GetDocument returns ErrDocumentNotFound if the document does not exist.
+	GetDocument(ctx context.Context, documentID string) (*SpecDocument, error)
+}
@@ -0,0 +1,8 @@
+package takeout
+
+import "errors"
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrNotFound     = errors.New("takeout not found")
+)
@@ -0,0 +1,111 @@
+// Package takeout models the full export of a tenant's data: its members,
+// codebases, analyses, generated documents and usage, packaged as one archive
+// the tenant downloads through a signed link.
+package takeout
+
+import "time"
+
+// FormatVersion is the version of the archive layout, recorded in the manifest.
+// Bump it when files are removed or their fields change meaning.
+const FormatVersion = 1
+
+const (
+	DefaultURLTTL = 72 * time.Hour
+	// MaxURLTTL is the longest validity of a signed download link (SigV4 limit).
+	MaxURLTTL = 7 * 24 * time.Hour
+)
+
+// Status is the lifecycle state of a takeout.
+type Status string
+
+const (
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+)
+
+// Takeout is one requested export of a tenant.
+type Takeout struct {
+	ID         string
+	Status     Status
+	TenantID   string
+	TenantName string
+}
+
+// Archive is the uploaded result of a takeout.
+type Archive struct {
+	DownloadURL string
+	ExpiresAt   time.Time // when DownloadURL stops working
+	ObjectKey   string
+	SizeBytes   int64
+}
+
+// Manifest describes the archive. Counts holds the records of each JSON Lines
+// file and, under "documents/", the exported documents, so a consumer can
+// check that an import is complete.
+type Manifest struct {
+	Counts        map[string]int `json:"counts"`
+	FormatVersion int            `json:"format_version"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+	TakeoutID     string         `json:"takeout_id"`
+	TenantID      string         `json:"tenant_id"`
+	TenantName    string         `json:"tenant_name"`
+}
+
+// Member is a user of the tenant.
+type Member struct {
+	JoinedAt time.Time `json:"joined_at"`
+	UserID   string    `json:"user_id"`
+}
+
+// Codebase is a repository analyzed by a member of the tenant.
+type Codebase struct {
+	CreatedAt     time.Time `json:"created_at"`
+	DefaultBranch string    `json:"default_branch,omitempty"`
+	Host          string    `json:"host"`
+	ID            string    `json:"id"`
+	IsPrivate     bool      `json:"is_private"`
+	Name          string    `json:"name"`
+	Owner         string    `json:"owner"`
+}
+
+// Analysis is one analysis in the history of a member of the tenant.
+type Analysis struct {
+	BranchName    string     `json:"branch_name,omitempty"`
+	CodebaseID    string     `json:"codebase_id"`
+	CommitSHA     string     `json:"commit_sha"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ID            string     `json:"id"`
+	ParserVersion string     `json:"parser_version"`
+	Status        string     `json:"status"`
+	TotalSuites   int        `json:"total_suites"`
+	TotalTests    int        `json:"total_tests"`
+}
+
+// Test is one test case found by an analysis.
+type Test struct {
+	FilePath  string `json:"file_path"`
+	Name      string `json:"name"`
+	SuitePath string `json:"suite_path"` // "Outer > Inner"
+}
+
+// UsageEvent is one quota-consuming event of a member.
+type UsageEvent struct {
+	AnalysisID  string    `json:"analysis_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	DocumentID  string    `json:"document_id,omitempty"`
+	EventType   string    `json:"event_type"`
+	ID          string    `json:"id"`
+	QuotaAmount int       `json:"quota_amount"`
+	UserID      string    `json:"user_id"`
+}
+
+// AIUsage is the AI tokens the tenant spent on one generation.
+type AIUsage struct {
+	CreatedAt  time.Time `json:"created_at"`
+	DocumentID string    `json:"document_id,omitempty"`
+	ID         string    `json:"id"`
+	Tokens     int64     `json:"tokens"`
+}
@@ -0,0 +1,36 @@
+package takeout
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Repository reads the data of a tenant and tracks takeouts.
+type Repository interface {
+	// GetTakeout returns ErrNotFound when no takeout has the ID.
+	GetTakeout(ctx context.Context, id string) (*Takeout, error)
+	// StartTakeout marks the takeout running and reports false when it is already completed.
+	StartTakeout(ctx context.Context, id string) (bool, error)
+	// CompleteTakeout records the uploaded archive.
+	CompleteTakeout(ctx context.Context, id string, archive Archive) error
+	// FailTakeout records why the takeout failed; a completed takeout is left as is.
+	FailTakeout(ctx context.Context, id string, reason string) error
+
+	ListMembers(ctx context.Context, tenantID string) ([]Member, error)
+	ListCodebases(ctx context.Context, tenantID string) ([]Codebase, error)
+	ListAnalyses(ctx context.Context, tenantID string) ([]Analysis, error)
+	ListTests(ctx context.Context, analysisID string) ([]Test, error)
+	// ListDocumentIDs returns the spec documents of the members, oldest first.
+	ListDocumentIDs(ctx context.Context, tenantID string) ([]string, error)
+	ListUsageEvents(ctx context.Context, tenantID string) ([]UsageEvent, error)
+	ListAIUsage(ctx context.Context, tenantID string) ([]AIUsage, error)
+}
+
+// ObjectStore holds takeout archives and hands out time-limited download links.
+type ObjectStore interface {
+	// Put uploads size bytes read from body under key.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// SignedURL returns a link downloading key without credentials until ttl elapses.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
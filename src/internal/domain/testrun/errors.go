@@ -0,0 +1,10 @@
+package testrun
+
+import "errors"
+
+var (
+	ErrEmptyReport    = errors.New("test report has no results")
+	ErrInvalidFormat  = errors.New("invalid test report format")
+	ErrInvalidInput   = errors.New("invalid input")
+	ErrUploadNotFound = errors.New("test report upload not found")
+)
@@ -0,0 +1,149 @@
+package testrun
+
+import (
+	"path"
+	"strings"
+	"unicode"
+)
+
+type caseIndex struct {
+	byFull  map[string][]int // suite path and name
+	byShort map[string][]int // name only
+	cases   []TestCase
+}
+
+func newCaseIndex(cases []TestCase) *caseIndex {
+	idx := &caseIndex{
+		byFull:  make(map[string][]int, len(cases)),
+		byShort: make(map[string][]int, len(cases)),
+		cases:   cases,
+	}
+	for i, tc := range cases {
+		short := nameKey(tc.Name)
+		idx.byShort[short] = append(idx.byShort[short], i)
+		if tc.SuitePath != "" {
+			full := nameKey(tc.SuitePath + " " + tc.Name)
+			idx.byFull[full] = append(idx.byFull[full], i)
+		}
+	}
+	return idx
+}
+
+// Match aggregates results by the inventory test case they report on and
+// returns them in report order, with the number of results that matched no
+// test case.
+//
+// A result matches a test case in the same file whose suite path and name, or
+// else name alone, equal the result name ignoring case, punctuation and
+// spacing, so "Auth › logs in" matches suite "Auth" and "TestLogin/valid_user"
+// matches suite "TestLogin". The file is the reported path, compared by suffix
+// since CI paths are often absolute; the package directory for go test results;
+// or a Java class name. A result matching several test cases is not counted
+// for any of them.
+func Match(results []Result, cases []TestCase) ([]CaseResult, int) {
+	idx := newCaseIndex(cases)
+	aggregated := make(map[string]*CaseResult)
+	var order []string
+	unmatched := 0
+
+	for _, result := range results {
+		i, ok := idx.find(result)
+		if !ok {
+			unmatched++
+			continue
+		}
+		id := cases[i].ID
+		agg, seen := aggregated[id]
+		if !seen {
+			agg = &CaseResult{TestCaseID: id}
+			aggregated[id] = agg
+			order = append(order, id)
+		}
+		agg.add(result)
+	}
+
+	matched := make([]CaseResult, len(order))
+	for i, id := range order {
+		matched[i] = *aggregated[id]
+	}
+	return matched, unmatched
+}
+
+func (idx *caseIndex) find(result Result) (int, bool) {
+	name := nameKey(result.Name)
+	fullKeys := []string{name}
+	if result.ClassName != "" {
+		fullKeys = append(fullKeys, nameKey(result.ClassName+" "+result.Name))
+	}
+
+	var full []int
+	for _, key := range fullKeys {
+		full = idx.filter(full, idx.byFull[key], result)
+	}
+	if len(full) > 0 {
+		return single(full)
+	}
+	return single(idx.filter(nil, idx.byShort[name], result))
+}
+
+// filter appends the candidates in the result's file to dst, without duplicates.
+func (idx *caseIndex) filter(dst []int, candidates []int, result Result) []int {
+	for _, i := range candidates {
+		if !sameFile(result, idx.cases[i].FilePath) {
+			continue
+		}
+		dup := false
+		for _, j := range dst {
+			if j == i {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			dst = append(dst, i)
+		}
+	}
+	return dst
+}
+
+func single(candidates []int) (int, bool) {
+	if len(candidates) != 1 {
+		return 0, false
+	}
+	return candidates[0], true
+}
+
+// sameFile reports whether the result may come from the inventory file.
+// Results without any location match every file.
+func sameFile(result Result, filePath string) bool {
+	filePath = cleanPath(filePath)
+	switch {
+	case result.FilePath != "":
+		reported := cleanPath(result.FilePath)
+		return reported == filePath ||
+			strings.HasSuffix(reported, "/"+filePath) ||
+			strings.HasSuffix(filePath, "/"+reported)
+	case result.Package != "":
+		dir := path.Dir(filePath)
+		return dir == "." || result.Package == dir || strings.HasSuffix(result.Package, "/"+dir)
+	case strings.Contains(result.ClassName, "."):
+		classPath := strings.ReplaceAll(result.ClassName, ".", "/")
+		noExt := strings.TrimSuffix(filePath, path.Ext(filePath))
+		return noExt == classPath || strings.HasSuffix(noExt, "/"+classPath)
+	default:
+		return true
+	}
+}
+
+func cleanPath(p string) string {
+	return strings.TrimPrefix(path.Clean(strings.ReplaceAll(p, "\\", "/")), "./")
+}
+
+// nameKey lowercases a test name and reduces separators, such as " > ", " › ",
+// "/" and "_", to single spaces.
+func nameKey(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(fields, " ")
+}
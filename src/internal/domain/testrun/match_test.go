@@ -0,0 +1,67 @@
+package testrun
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	cases := []TestCase{
+		{FilePath: "src/auth.test.ts", ID: "tc-login", Name: "logs in", SuitePath: "Auth"},
+		{FilePath: "src/auth.test.ts", ID: "tc-sso-login", Name: "logs in", SuitePath: "Auth > SSO"},
+		{FilePath: "src/auth.test.ts", ID: "tc-logout", Name: "logs out", SuitePath: "Auth"},
+		{FilePath: "internal/auth/login_test.go", ID: "tc-go-valid", Name: "valid user", SuitePath: "TestLogin"},
+		{FilePath: "src/test/java/com/example/FooTest.java", ID: "tc-java", Name: "bar", SuitePath: "FooTest"},
+	}
+
+	t.Run("matches by suite path and file suffix", func(t *testing.T) {
+		matched, unmatched := Match([]Result{
+			{FilePath: "/home/runner/work/app/src/auth.test.ts", Name: "Auth › SSO › logs in", Status: StatusPassed},
+			{ClassName: "Auth", FilePath: "src/auth.test.ts", Name: "logs in", Status: StatusFailed},
+		}, cases)
+
+		if unmatched != 0 || len(matched) != 2 {
+			t.Fatalf("expected 2 matches, got %+v (%d unmatched)", matched, unmatched)
+		}
+		if matched[0].TestCaseID != "tc-sso-login" || matched[1].TestCaseID != "tc-login" {
+			t.Errorf("unexpected matches: %+v", matched)
+		}
+	})
+
+	t.Run("matches go subtests by package directory", func(t *testing.T) {
+		matched, unmatched := Match([]Result{
+			{Name: "TestLogin/valid_user", Package: "github.com/acme/app/internal/auth", Status: StatusPassed},
+			{Name: "TestLogin/valid_user", Package: "github.com/acme/app/internal/billing", Status: StatusPassed},
+		}, cases)
+
+		if unmatched != 1 || len(matched) != 1 || matched[0].TestCaseID != "tc-go-valid" {
+			t.Errorf("unexpected matches: %+v (%d unmatched)", matched, unmatched)
+		}
+	})
+
+	t.Run("matches java classes to their file", func(t *testing.T) {
+		matched, _ := Match([]Result{{ClassName: "com.example.FooTest", Name: "bar", Status: StatusPassed}}, cases)
+		if len(matched) != 1 || matched[0].TestCaseID != "tc-java" {
+			t.Errorf("unexpected matches: %+v", matched)
+		}
+	})
+
+	t.Run("leaves ambiguous names unmatched", func(t *testing.T) {
+		matched, unmatched := Match([]Result{{FilePath: "src/auth.test.ts", Name: "logs in", Status: StatusPassed}}, cases)
+		if len(matched) != 0 || unmatched != 1 {
+			t.Errorf("expected the result unmatched, got %+v", matched)
+		}
+	})
+
+	t.Run("aggregates repeated runs", func(t *testing.T) {
+		run := func(status Status) Result {
+			return Result{FilePath: "src/auth.test.ts", Name: "Auth logs out", Status: status}
+		}
+		matched, _ := Match([]Result{run(StatusFailed), run(StatusPassed), run(StatusSkipped)}, cases)
+
+		if len(matched) != 1 {
+			t.Fatalf("expected 1 aggregated result, got %+v", matched)
+		}
+		got := matched[0]
+		if got.Runs != 3 || got.Failures != 1 || got.Passes != 1 || got.Status != StatusSkipped || !got.Flaky() {
+			t.Errorf("unexpected aggregate: %+v", got)
+		}
+	})
+}
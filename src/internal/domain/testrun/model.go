@@ -0,0 +1,74 @@
+package testrun
+
+import "time"
+
+// Format identifies the format of an uploaded test report.
+type Format string
+
+const (
+	FormatGoTestJSON Format = "go-test-json" // output of go test -json
+	FormatJUnit      Format = "junit"        // JUnit XML, as written by most CI test reporters
+)
+
+// Status is the outcome of a single test run.
+type Status string
+
+const (
+	StatusFailed  Status = "failed" // includes JUnit errors
+	StatusPassed  Status = "passed"
+	StatusSkipped Status = "skipped"
+)
+
+// Result is one run of one test as reported by CI. A report may contain
+// several runs of the same test, such as retries or go test -count.
+type Result struct {
+	ClassName string // JUnit classname; often the suite or a Java class
+	Duration  time.Duration
+	FilePath  string // as reported, possibly absolute; empty when unknown
+	Name      string
+	Package   string // Go import path for go test -json results
+	Status    Status
+}
+
+// Upload is a test report uploaded from CI for an analyzed commit.
+type Upload struct {
+	AnalysisID string
+	Content    []byte
+	Format     Format
+	ID         string
+}
+
+// TestCase is an inventory test case that results can be matched to.
+type TestCase struct {
+	FilePath  string
+	ID        string
+	Name      string
+	SuitePath string // enclosing suite names, outermost first, joined by " > "
+}
+
+// CaseResult aggregates the runs of one test case across a report.
+type CaseResult struct {
+	Duration   time.Duration // of the last run
+	Failures   int
+	Passes     int
+	Runs       int    // including skipped runs
+	Status     Status // of the last run
+	TestCaseID string
+}
+
+// Flaky reports whether the test case both passed and failed.
+func (r CaseResult) Flaky() bool {
+	return r.Failures > 0 && r.Passes > 0
+}
+
+func (r *CaseResult) add(result Result) {
+	r.Duration = result.Duration
+	r.Runs++
+	r.Status = result.Status
+	switch result.Status {
+	case StatusFailed:
+		r.Failures++
+	case StatusPassed:
+		r.Passes++
+	}
+}
@@ -0,0 +1,159 @@
+package testrun
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxGoTestLine bounds a single go test -json event; test output lines are
+// embedded in events and can be long.
+const maxGoTestLine = 16 << 20
+
+// junitSuite matches both <testsuites> and <testsuite>, which nest freely
+// across reporters.
+type junitSuite struct {
+	File   string       `xml:"file,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Tests  []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string    `xml:"classname,attr"`
+	Error     *struct{} `xml:"error"`
+	Failure   *struct{} `xml:"failure"`
+	File      string    `xml:"file,attr"`
+	Name      string    `xml:"name,attr"`
+	Skipped   *struct{} `xml:"skipped"`
+	Time      string    `xml:"time,attr"`
+}
+
+type goTestEvent struct {
+	Action  string  `json:"Action"`
+	Elapsed float64 `json:"Elapsed"`
+	Package string  `json:"Package"`
+	Test    string  `json:"Test"`
+}
+
+// Parse reads a test report in the given format.
+// Returns ErrEmptyReport when the report contains no test results.
+func Parse(format Format, r io.Reader) ([]Result, error) {
+	var results []Result
+	var err error
+	switch format {
+	case FormatGoTestJSON:
+		results, err = parseGoTestJSON(r)
+	case FormatJUnit:
+		results, err = parseJUnit(r)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, ErrEmptyReport
+	}
+	return results, nil
+}
+
+func parseJUnit(r io.Reader) ([]Result, error) {
+	var root junitSuite
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("%w: decode JUnit XML: %w", ErrInvalidFormat, err)
+	}
+
+	var results []Result
+	var walk func(suite junitSuite, file string)
+	walk = func(suite junitSuite, file string) {
+		if suite.File != "" {
+			file = suite.File
+		}
+		for _, tc := range suite.Tests {
+			if tc.Name == "" {
+				continue
+			}
+			result := Result{
+				ClassName: tc.ClassName,
+				Duration:  parseSeconds(tc.Time),
+				FilePath:  file,
+				Name:      tc.Name,
+				Status:    StatusPassed,
+			}
+			if tc.File != "" {
+				result.FilePath = tc.File
+			}
+			switch {
+			case tc.Failure != nil || tc.Error != nil:
+				result.Status = StatusFailed
+			case tc.Skipped != nil:
+				result.Status = StatusSkipped
+			}
+			results = append(results, result)
+		}
+		for _, child := range suite.Suites {
+			walk(child, file)
+		}
+	}
+	walk(root, "")
+	return results, nil
+}
+
+// parseSeconds reads a JUnit time attribute; some reporters use thousands
+// separators. Unparseable values read as zero.
+func parseSeconds(s string) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", ""), 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func parseGoTestJSON(r io.Reader) ([]Result, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxGoTestLine)
+
+	var results []Result
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		// Build errors and other tool output are printed as plain text.
+		if len(text) == 0 || text[0] != '{' {
+			continue
+		}
+		var event goTestEvent
+		if err := json.Unmarshal(text, &event); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidFormat, line, err)
+		}
+		if event.Test == "" {
+			continue
+		}
+
+		var status Status
+		switch event.Action {
+		case "fail":
+			status = StatusFailed
+		case "pass":
+			status = StatusPassed
+		case "skip":
+			status = StatusSkipped
+		default:
+			continue
+		}
+		results = append(results, Result{
+			Duration: time.Duration(event.Elapsed * float64(time.Second)),
+			Name:     event.Test,
+			Package:  event.Package,
+			Status:   status,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: read go test output: %w", ErrInvalidFormat, err)
+	}
+	return results, nil
+}
@@ -0,0 +1,109 @@
+package testrun
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse_JUnit(t *testing.T) {
+	t.Run("reads nested suites and statuses", func(t *testing.T) {
+		input := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="auth" file="/home/runner/work/app/src/auth.test.ts">
+    <testcase classname="Auth" name="logs in" time="0.25"/>
+    <testcase classname="Auth" name="rejects bad password" time="1,002.5"><failure message="expected 401"/></testcase>
+    <testsuite name="sso">
+      <testcase classname="Auth SSO" name="redirects" file="src/sso.test.ts"><skipped/></testcase>
+      <testcase classname="Auth SSO" name="times out"><error/></testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+
+		results, err := Parse(FormatJUnit, strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(results) != 4 {
+			t.Fatalf("expected 4 results, got %d", len(results))
+		}
+		if r := results[0]; r.Status != StatusPassed || r.Duration != 250*time.Millisecond || r.FilePath != "/home/runner/work/app/src/auth.test.ts" {
+			t.Errorf("unexpected first result: %+v", r)
+		}
+		if r := results[1]; r.Status != StatusFailed || r.Duration != 1002500*time.Millisecond {
+			t.Errorf("unexpected failed result: %+v", r)
+		}
+		if r := results[2]; r.Status != StatusSkipped || r.FilePath != "src/sso.test.ts" {
+			t.Errorf("unexpected skipped result: %+v", r)
+		}
+		if r := results[3]; r.Status != StatusFailed || r.FilePath != "/home/runner/work/app/src/auth.test.ts" {
+			t.Errorf("errors should fail and inherit the suite file: %+v", r)
+		}
+	})
+
+	t.Run("accepts a single testsuite root", func(t *testing.T) {
+		results, err := Parse(FormatJUnit, strings.NewReader(`<testsuite><testcase classname="com.example.FooTest" name="bar"/></testsuite>`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(results) != 1 || results[0].ClassName != "com.example.FooTest" {
+			t.Errorf("unexpected results: %+v", results)
+		}
+	})
+
+	t.Run("rejects malformed XML", func(t *testing.T) {
+		if _, err := Parse(FormatJUnit, strings.NewReader("<testsuite><testcase")); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("expected ErrInvalidFormat, got %v", err)
+		}
+	})
+
+	t.Run("rejects a report without tests", func(t *testing.T) {
+		if _, err := Parse(FormatJUnit, strings.NewReader("<testsuites/>")); !errors.Is(err, ErrEmptyReport) {
+			t.Errorf("expected ErrEmptyReport, got %v", err)
+		}
+	})
+}
+
+func TestParse_GoTestJSON(t *testing.T) {
+	t.Run("reads test outcomes and skips other events", func(t *testing.T) {
+		input := strings.Join([]string{
+			`# github.com/acme/app/internal/auth [build output]`,
+			`{"Action":"run","Package":"github.com/acme/app/internal/auth","Test":"TestLogin"}`,
+			`{"Action":"output","Package":"github.com/acme/app/internal/auth","Test":"TestLogin","Output":"=== RUN   TestLogin\n"}`,
+			`{"Action":"pass","Package":"github.com/acme/app/internal/auth","Test":"TestLogin/valid_user","Elapsed":0.01}`,
+			`{"Action":"fail","Package":"github.com/acme/app/internal/auth","Test":"TestLogin","Elapsed":1.5}`,
+			`{"Action":"skip","Package":"github.com/acme/app/internal/auth","Test":"TestLogout","Elapsed":0}`,
+			`{"Action":"pass","Package":"github.com/acme/app/internal/auth","Elapsed":2}`,
+		}, "\n")
+
+		results, err := Parse(FormatGoTestJSON, strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(results) != 3 {
+			t.Fatalf("expected 3 results, got %+v", results)
+		}
+		if r := results[0]; r.Name != "TestLogin/valid_user" || r.Status != StatusPassed || r.Package != "github.com/acme/app/internal/auth" {
+			t.Errorf("unexpected first result: %+v", r)
+		}
+		if r := results[1]; r.Status != StatusFailed || r.Duration != 1500*time.Millisecond {
+			t.Errorf("unexpected failed result: %+v", r)
+		}
+		if results[2].Status != StatusSkipped {
+			t.Errorf("expected skipped result, got %+v", results[2])
+		}
+	})
+
+	t.Run("rejects malformed events", func(t *testing.T) {
+		if _, err := Parse(FormatGoTestJSON, strings.NewReader(`{"Action":`)); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("expected ErrInvalidFormat, got %v", err)
+		}
+	})
+}
+
+func TestParse_UnsupportedFormat(t *testing.T) {
+	if _, err := Parse("trx", strings.NewReader("")); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("expected ErrInvalidFormat, got %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package testrun
+
+import "context"
+
+// Repository loads uploaded reports and stores the matched results.
+type Repository interface {
+	// GetUpload returns ErrUploadNotFound if the upload does not exist.
+	GetUpload(ctx context.Context, uploadID string) (*Upload, error)
+
+	// GetTestCases returns the test cases of an analysis with their file and suite path.
+	GetTestCases(ctx context.Context, analysisID string) ([]TestCase, error)
+
+	// SaveResults adds the results of an upload to the last-run status of its
+	// test cases and marks the upload processed. Returns false without saving
+	// when the upload was already processed, so retried jobs do not count runs twice.
+	SaveResults(ctx context.Context, uploadID string, results []CaseResult) (bool, error)
+}
@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultDeliveryTTL covers GitHub's redelivery window: deliveries from the
+// past three days can be redelivered from the webhook settings.
+const DefaultDeliveryTTL = 72 * time.Hour
+
+// MaxDeliveryIDLength bounds delivery IDs to the storage column size.
+const MaxDeliveryIDLength = 100
+
+// DeliveryStore records webhook deliveries so that a redelivered event does
+// not trigger twice.
+type DeliveryStore interface {
+	// ClaimDelivery records a new delivery and reports true. For a delivery
+	// already recorded it reports false with the outcome stored for it, empty
+	// while the first attempt is still running.
+	ClaimDelivery(ctx context.Context, deliveryID, event string, expiresAt time.Time) (bool, Outcome, error)
+	// CompleteDelivery stores the outcome returned to redeliveries.
+	CompleteDelivery(ctx context.Context, deliveryID string, outcome Outcome) error
+	// ReleaseDelivery forgets an unfinished delivery so a redelivery is processed again.
+	ReleaseDelivery(ctx context.Context, deliveryID string) error
+}
@@ -0,0 +1,8 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused for a different request")
+	ErrInvalidTrigger       = errors.New("invalid webhook trigger")
+)
@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+// DefaultIdempotencyKeyTTL is how long an idempotency key maps to the job it
+// created. It outlasts the Web app's retries and GitHub's redelivery window.
+const DefaultIdempotencyKeyTTL = 72 * time.Hour
+
+// MaxIdempotencyKeyLength bounds idempotency keys to the storage column size.
+const MaxIdempotencyKeyLength = 255
+
+// DeliveryIdempotencyKey is the idempotency key of the analysis enqueued for a
+// webhook delivery.
+func DeliveryIdempotencyKey(deliveryID string) string {
+	return "webhook-delivery:" + deliveryID
+}
+
+// EnqueueResult is the job an idempotency key maps to.
+type EnqueueResult struct {
+	AnalysisID *analysis.UUID // nil until the job records its analysis
+	Duplicate  bool           // the key was used before and no job was enqueued
+	JobID      int64
+	JobState   string // empty once the job has been pruned from the queue
+}
+
+// IdempotentAnalysisEnqueuer schedules analyses under caller-provided
+// idempotency keys, so that a retried request never enqueues twice.
+// Optional capability: without it, requests carrying a key are rejected.
+type IdempotentAnalysisEnqueuer interface {
+	// EnqueueIdempotentAnalysis enqueues a branch analysis unless key was used
+	// before, in which case it reports the job the first request created. Reusing
+	// a key for a different request fails with ErrIdempotencyKeyReused.
+	EnqueueIdempotentAnalysis(ctx context.Context, key, owner, repo, branch, commitSHA string, filters *analysis.PathFilters) (EnqueueResult, error)
+	// LookupIdempotencyKey returns nil without error for unknown or expired keys.
+	LookupIdempotencyKey(ctx context.Context, key string) (*EnqueueResult, error)
+}
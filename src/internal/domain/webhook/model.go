@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"fmt"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+// Trigger is a repository event that may start an analysis: a push to the
+// default branch, or a pull request merged into it.
+type Trigger struct {
+	Branch         string
+	CommitSHA      string
+	DeliveryID     string // provider delivery ID, empty to skip deduplication
+	Event          string // source event name, for logging
+	ExternalRepoID string // stable across renames, used to map to a codebase
+	Host           string
+	Owner          string // current owner and name from the event payload
+	Repo           string
+}
+
+// Validate checks that the trigger identifies a repository and commit.
+func (t Trigger) Validate() error {
+	if t.Host == "" || t.ExternalRepoID == "" {
+		return fmt.Errorf("%w: repository id is required", ErrInvalidTrigger)
+	}
+	if t.Owner == "" || t.Repo == "" {
+		return fmt.Errorf("%w: owner and repo are required", ErrInvalidTrigger)
+	}
+	if t.CommitSHA == "" {
+		return fmt.Errorf("%w: commit sha is required", ErrInvalidTrigger)
+	}
+	if t.Branch != "" && !analysis.IsValidBranchName(t.Branch) {
+		return fmt.Errorf("%w: invalid branch name", ErrInvalidTrigger)
+	}
+	if len(t.DeliveryID) > MaxDeliveryIDLength {
+		return fmt.Errorf("%w: delivery id too long", ErrInvalidTrigger)
+	}
+	return nil
+}
+
+// Codebase is the registered codebase a trigger maps to.
+type Codebase struct {
+	ID analysis.UUID
+}
+
+// Outcome describes what a trigger resulted in.
+type Outcome string
+
+const (
+	OutcomeAlreadyAnalyzed    Outcome = "already_analyzed"
+	OutcomeDeliveryInProgress Outcome = "delivery_in_progress"
+	OutcomeEnqueued           Outcome = "enqueued"
+	OutcomeUnknownCodebase    Outcome = "unknown_codebase"
+)
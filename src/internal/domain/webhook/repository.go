@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+// Repository maps webhook events to registered codebases.
+type Repository interface {
+	// FindCodebase returns nil without error when the repository is not registered.
+	FindCodebase(ctx context.Context, host, externalRepoID string) (*Codebase, error)
+
+	// HasAnalysisForCommit reports whether the commit was analyzed or is being analyzed
+	// on the branch. Failed analyses don't count, so a new push can retry them.
+	HasAnalysisForCommit(ctx context.Context, codebaseID analysis.UUID, branch, commitSHA string) (bool, error)
+}
+
+// AnalysisEnqueuer schedules analysis jobs.
+type AnalysisEnqueuer interface {
+	EnqueueBranchAnalysis(ctx context.Context, owner, repo, branch, commitSHA string) error
+}
+
+// FilteredAnalysisEnqueuer schedules analyses limited by path filters.
+// Optional capability: without it, enqueue requests carrying filters are rejected.
+type FilteredAnalysisEnqueuer interface {
+	EnqueueFilteredAnalysis(ctx context.Context, owner, repo, branch, commitSHA string, filters *analysis.PathFilters) error
+}
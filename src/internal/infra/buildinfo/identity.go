@@ -0,0 +1,81 @@
+package buildinfo
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+// Set at build time, e.g.
+// go build -ldflags "-X github.com/specvital/worker/internal/infra/buildinfo.version=v1.2.0
+// -X github.com/specvital/worker/internal/infra/buildinfo.gitSHA=$(git rev-parse HEAD)"
+var (
+	gitSHA  string
+	version string
+)
+
+// Identity describes the worker instance and build that processed a job.
+type Identity struct {
+	GitSHA   string `json:"git_sha"`
+	Hostname string `json:"hostname"`
+	Version  string `json:"version"`
+}
+
+// CurrentIdentity returns the identity of the running process.
+// Values not injected via ldflags fall back to Go build info,
+// and any value that cannot be determined is "unknown".
+func CurrentIdentity() Identity {
+	id := Identity{
+		GitSHA:  gitSHA,
+		Version: version,
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if id.Version == "" && info.Main.Version != "(devel)" {
+			id.Version = info.Main.Version
+		}
+		if id.GitSHA == "" {
+			id.GitSHA = vcsRevision(info)
+		}
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		id.Hostname = hostname
+	}
+
+	if id.GitSHA == "" {
+		id.GitSHA = "unknown"
+	}
+	if id.Hostname == "" {
+		id.Hostname = "unknown"
+	}
+	if id.Version == "" {
+		id.Version = "unknown"
+	}
+	return id
+}
+
+// LogAttrs returns the identity as slog key-value pairs.
+func (i Identity) LogAttrs() []any {
+	return []any{
+		"worker_hostname", i.Hostname,
+		"worker_version", i.Version,
+		"worker_git_sha", i.GitSHA,
+	}
+}
+
+func vcsRevision(info *debug.BuildInfo) string {
+	var revision string
+	var modified bool
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+	return revision
+}
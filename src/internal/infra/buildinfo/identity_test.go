@@ -0,0 +1,64 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestCurrentIdentity(t *testing.T) {
+	t.Run("uses ldflags values when set", func(t *testing.T) {
+		origSHA, origVersion := gitSHA, version
+		t.Cleanup(func() { gitSHA, version = origSHA, origVersion })
+		gitSHA, version = "abc123", "v1.2.0"
+
+		id := CurrentIdentity()
+
+		if id.GitSHA != "abc123" || id.Version != "v1.2.0" {
+			t.Errorf("unexpected identity: %+v", id)
+		}
+		if id.Hostname == "" {
+			t.Error("expected hostname to be set")
+		}
+	})
+
+	t.Run("never returns empty fields", func(t *testing.T) {
+		id := CurrentIdentity()
+
+		if id.GitSHA == "" || id.Hostname == "" || id.Version == "" {
+			t.Errorf("expected all fields to be set, got %+v", id)
+		}
+	})
+}
+
+func TestVcsRevision(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings []debug.BuildSetting
+		want     string
+	}{
+		{
+			name:     "no vcs info",
+			settings: nil,
+			want:     "",
+		},
+		{
+			name:     "clean checkout",
+			settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "deadbeef"}, {Key: "vcs.modified", Value: "false"}},
+			want:     "deadbeef",
+		},
+		{
+			name:     "modified checkout",
+			settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "deadbeef"}, {Key: "vcs.modified", Value: "true"}},
+			want:     "deadbeef-dirty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := vcsRevision(&debug.BuildInfo{Settings: tt.settings})
+			if got != tt.want {
+				t.Errorf("vcsRevision() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package buildinfo
+
+// Report describes a worker build and what it can do.
+// The web app reads it to gate UI features on worker capability.
+type Report struct {
+	AIProvider    string          `json:"ai_provider,omitempty"`
+	Features      map[string]bool `json:"features"`
+	GitSHA        string          `json:"git_sha"`
+	Hostname      string          `json:"hostname"`
+	JobKinds      []string        `json:"job_kinds"`
+	ParserVersion string          `json:"parser_version"`
+	Region        string          `json:"region"`
+	Service       string          `json:"service"`
+	Version       string          `json:"version"`
+}
+
+// NewReport builds a report for the given service from the process identity
+// and the core parser version linked into the binary.
+func NewReport(service string, identity Identity, jobKinds []string, features map[string]bool) Report {
+	if features == nil {
+		features = map[string]bool{}
+	}
+	if jobKinds == nil {
+		jobKinds = []string{}
+	}
+	return Report{
+		Features:      features,
+		GitSHA:        identity.GitSHA,
+		Hostname:      identity.Hostname,
+		JobKinds:      jobKinds,
+		ParserVersion: ExtractCoreVersion(),
+		Service:       service,
+		Version:       identity.Version,
+	}
+}
@@ -5,7 +5,13 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/specvital/worker/internal/domain/hostpool"
+	"github.com/specvital/worker/internal/domain/region"
+	"github.com/specvital/worker/internal/domain/sla"
+	"github.com/specvital/worker/internal/domain/specview"
 )
 
 // Default worker counts for queue allocation.
@@ -35,7 +41,8 @@ type QueueConfig struct {
 	Specgen  QueueWorkers
 }
 
-// FairnessConfig defines per-tier concurrent job limits and snooze parameters.
+// FairnessConfig defines per-tier concurrent job limits, snooze parameters
+// and per-user/per-codebase job start rate limits.
 type FairnessConfig struct {
 	Enabled                   bool
 	FreeConcurrentLimit       int
@@ -43,23 +50,217 @@ type FairnessConfig struct {
 	EnterpriseConcurrentLimit int
 	SnoozeDuration            time.Duration
 	SnoozeJitter              time.Duration
+	RateLimitEnabled          bool
+	UserRatePerMinute         int
+	UserBurst                 int
+	CodebaseRatePerMinute     int
+	CodebaseBurst             int
+}
+
+// CacheKeyHashConfig selects the hash of behavior cache keys, and the
+// previous one whose keys are still read while stored entries migrate.
+type CacheKeyHashConfig struct {
+	Algorithm specview.HashAlgorithm // sha256 (default) or blake3
+	Legacy    specview.HashAlgorithm // empty reads only Algorithm keys
+}
+
+// NotificationsConfig controls telling the channels of codebases and users
+// about finished analysis and spec document jobs.
+type NotificationsConfig struct {
+	Enabled     bool
+	QueueSize   int           // outcomes waiting to be sent before new ones are dropped; zero uses the default
+	SendTimeout time.Duration // bound on each send; zero uses the default
+	Workers     int           // outcomes sent concurrently; zero uses the default
+}
+
+// OutboxConfig configures the relay publishing outbox events.
+type OutboxConfig struct {
+	BatchSize          int           // events claimed per round; zero uses the default
+	MaxAttempts        int           // publishes of an event before it is abandoned; zero uses the default
+	PollInterval       time.Duration // wait after a round that drained the outbox; zero uses the default
+	Retention          time.Duration // how long published and abandoned events are kept; zero uses the default
+	SinkURL            string        // webhook URL, SNS topic ARN or nats:// URL; empty disables the relay
+	SNSAccessKeyID     string
+	SNSEndpoint        string
+	SNSSecretAccessKey string
+	WebhookSecret      string
+}
+
+// PromptExperimentConfig routes a share of spec-view jobs to a candidate
+// prompt version, so its documents can be compared with the default's.
+type PromptExperimentConfig struct {
+	Candidate string // candidate prompt version; empty disables the experiment
+	Percent   int    // share of jobs, 0-100, generated with Candidate
+}
+
+// QualityConfig controls scoring generated spec documents and retrying the
+// low-confidence features of flagged ones.
+type QualityConfig struct {
+	FlagBelow     float64 // quality score, 0-1, below which a document is flagged; zero uses the default
+	LowConfidence float64 // confidence, 0-1, below which a behavior is low-confidence; zero uses the default
+	Refine        bool    // retry the low-confidence features of flagged documents once
+}
+
+// SemanticCacheConfig enables reusing the cached behavior of a test whose
+// name embedding is near a missed test's, e.g. after a rename.
+type SemanticCacheConfig struct {
+	APIKey     string // Gemini API key of the embedding model
+	Enabled    bool
+	Model      string  // embedding model; empty uses the provider default
+	Similarity float64 // cosine similarity, 0-1, at which a behavior is reused; zero uses the default
+}
+
+// DocumentEncryptionConfig holds the master keys wrapping the per-tenant keys
+// that encrypt generated documents, base64-encoded like ENCRYPTION_KEY.
+type DocumentEncryptionConfig struct {
+	MasterKey         string // empty disables document encryption
+	PreviousMasterKey string // still unwraps keys until they are rewrapped
+}
+
+// FanOutConfig controls distributing spec-view Phase 2 across per-domain child jobs.
+type FanOutConfig struct {
+	Enabled    bool
+	MinDomains int // domains needing conversion before a document fans out
+	Workers    int // workers for the Phase 2 child queue
+}
+
+// GitHubAppConfig identifies the GitHub App whose installation tokens clone
+// the repositories it is installed on.
+type GitHubAppConfig struct {
+	AppID      int64  // zero disables installation tokens
+	PrivateKey string // PEM-encoded RSA key; escaped newlines are accepted
+}
+
+// IdleConfig controls reporting when spec-generator replicas can be scaled to zero.
+type IdleConfig struct {
+	Enabled  bool
+	Grace    time.Duration // time without work before reporting idle; zero uses the queue default
+	WakeLead time.Duration // how early a scheduled job ends idleness; zero uses the queue default
+}
+
+// AIConfig selects the AI provider and its per-phase models.
+type AIConfig struct {
+	APIKey      string
+	APIVersion  string
+	BaseURL     string
+	Phase1Model string
+	Phase2Model string
+	Provider    string
+
+	AllowedModels   []string // models a request may select per phase; empty rejects per-phase selection
+	Phase1Fallbacks []string // models tried in order when the Phase 1 model is rate limited or unavailable
+	Phase2Fallbacks []string // models tried in order when the Phase 2 model is rate limited or unavailable
+
+	ResponseCacheMaxEntries int           // zero uses the provider default
+	ResponseCacheTTL        time.Duration // zero disables the Gemini response cache
+
+	BatchMinTests int           // Anthropic Phase 1 inputs of at least this many tests use the batch API; zero disables
+	BatchMaxWait  time.Duration // zero uses the provider default
+
+	BatchFallbackAPIKey string // Anthropic key of the batch Gemini Phase 1 falls back to when rate limited; empty disables
+	BatchFallbackModel  string // empty uses the Anthropic default Phase 1 model
+}
+
+// RetrySchedule is the backoff of one error class of failed jobs. Zero
+// fields use the defaults of the class.
+type RetrySchedule struct {
+	InitialBackoff time.Duration
+	MaxAttempts    int // attempts of a job failing with the class; zero keeps the job's own limit
+	MaxBackoff     time.Duration
+}
+
+// RetryConfig sets when failed jobs of every kind are retried, per error class.
+type RetryConfig struct {
+	Default      RetrySchedule
+	JitterFactor float64 // share, 0-1, of each backoff randomized; zero uses the default
+	RateLimit    RetrySchedule
+	Transient    RetrySchedule // database connection failures, deadlocks, serialization failures
 }
 
 // StreamingConfig holds configuration for streaming analysis pipeline.
 type StreamingConfig struct {
-	BatchSize int
+	BatchSize             int
+	ParseWorkers          int // files parsed concurrently per scan; zero uses GOMAXPROCS, larger values are capped to it
+	UnmatchedSamples      int // unmatched test file candidates sampled per analysis; negative disables sampling
+	UnmatchedSnippetLines int // redacted lines stored with each sample; zero stores paths only
+}
+
+// TakeoutConfig locates the S3-compatible bucket holding tenant takeout archives.
+type TakeoutConfig struct {
+	AccessKeyID     string
+	Bucket          string // empty disables tenant takeouts
+	Endpoint        string
+	PathStyle       bool
+	Region          string
+	SecretAccessKey string
+	URLTTL          time.Duration // validity of the signed download link; zero uses the default
+}
+
+// TokenKeysConfig locates the versioned keys encrypting stored OAuth tokens,
+// next to the legacy ENCRYPTION_KEY. At most one source is set.
+type TokenKeysConfig struct {
+	File       string // key spec file, e.g. mounted by a secret manager
+	Keys       string // key spec "<id>=<base64>,...", current key first
+	VaultAddr  string
+	VaultField string // field of the Vault secret holding the key spec; empty uses "keys"
+	VaultPath  string // Vault API path of the secret below /v1; empty disables Vault
+	VaultToken string
+}
+
+// Configured reports whether a source of versioned keys is set.
+func (c TokenKeysConfig) Configured() bool {
+	return c.Keys != "" || c.File != "" || c.VaultPath != ""
+}
+
+// TracingConfig controls OpenTelemetry trace export.
+type TracingConfig struct {
+	Endpoint    string  // OTLP/HTTP collector endpoint; empty disables tracing
+	SampleRatio float64 // fraction of new traces sampled, 0 to 1
+}
+
+// WorkspaceConfig bounds the disk used by repository clones.
+type WorkspaceConfig struct {
+	CloneReserveBytes int64 // space held for a clone until its size is known
+	MaxRepoSizeBytes  int64 // largest repository, as reported by the VCS API, that is cloned
+	QuotaBytes        int64 // total clone disk quota; zero disables it
 }
 
 type Config struct {
-	DatabaseURL       string
-	EncryptionKey     string
-	Fairness          FairnessConfig
-	GeminiAPIKey      string
-	GeminiPhase1Model string
-	GeminiPhase2Model string
-	MockMode          bool
-	Queue             QueueConfig
-	Streaming         StreamingConfig
+	AI                 AIConfig
+	CacheKeyHash       CacheKeyHashConfig
+	CacheScope         specview.CacheScopeMode // who shares behavior cache entries; empty is global
+	DatabaseURL        string
+	DedupSimilarity    float64 // similarity at which duplicate behaviors are merged; zero uses the default
+	DocumentEncryption DocumentEncryptionConfig
+	DocumentSharing    bool          // serve public, suitably licensed documents across users
+	DrainTimeout       time.Duration // bound on a drain; zero uses the queue default
+	EncryptionKey      string
+	Fairness           FairnessConfig
+	FanOut             FanOutConfig
+	GeneratorMode      specview.GeneratorMode // when spec documents are generated without AI; empty is never
+	GitHubApp          GitHubAppConfig
+	HostPools          []hostpool.Pool // analyzer pools of hosts analyzed apart from the rest
+	HTTPAddr           string          // empty disables the HTTP server
+	Idle               IdleConfig
+	InFlightWait       time.Duration // how long a spec job waits for another generating the same content; zero disables
+	MinHelperRefs      int           // test files importing a helper for it to be recorded as shared; zero uses the default, negative disables
+	MinTestVariants    int           // sibling tests sharing a name template collapsed into one; zero uses the default, negative disables
+	MockMode           bool
+	Notifications      NotificationsConfig
+	Outbox             OutboxConfig
+	PromptExperiment   PromptExperimentConfig
+	Quality            QualityConfig
+	Queue              QueueConfig
+	QuotaWarnings      []int  // shares of the monthly quota, in percent, at which users are warned
+	Region             string // data-residency region; empty for single-region deployments
+	Retry              RetryConfig
+	SemanticCache      SemanticCacheConfig
+	SLATargets         sla.Targets // target duration from enqueue to completion, by job kind
+	Streaming          StreamingConfig
+	Takeout            TakeoutConfig
+	TokenKeys          TokenKeysConfig
+	Tracing            TracingConfig
+	Workspace          WorkspaceConfig
 }
 
 func Load() (*Config, error) {
@@ -69,21 +270,253 @@ func Load() (*Config, error) {
 	}
 
 	encryptionKey := os.Getenv("ENCRYPTION_KEY")
-	if encryptionKey == "" {
-		return nil, errors.New("ENCRYPTION_KEY is required")
+	cfg := LoadSettings()
+	if encryptionKey == "" && !cfg.TokenKeys.Configured() {
+		return nil, errors.New("ENCRYPTION_KEY is required (or versioned keys in ENCRYPTION_KEYS, ENCRYPTION_KEYS_FILE or ENCRYPTION_KEYS_VAULT_PATH)")
+	}
+	if sources := countSet(cfg.TokenKeys.Keys, cfg.TokenKeys.File, cfg.TokenKeys.VaultPath); sources > 1 {
+		return nil, errors.New("only one of ENCRYPTION_KEYS, ENCRYPTION_KEYS_FILE and ENCRYPTION_KEYS_VAULT_PATH can be set")
+	}
+	if cfg.TokenKeys.VaultPath != "" && (cfg.TokenKeys.VaultAddr == "" || cfg.TokenKeys.VaultToken == "") {
+		return nil, errors.New("VAULT_ADDR and VAULT_TOKEN are required with ENCRYPTION_KEYS_VAULT_PATH")
 	}
+	if _, err := hostpool.Parse(os.Getenv("ANALYZER_HOST_POOLS")); err != nil {
+		return nil, fmt.Errorf("ANALYZER_HOST_POOLS: %w", err)
+	}
+	if _, err := sla.ParseTargets(os.Getenv("JOB_SLA_TARGETS")); err != nil {
+		return nil, fmt.Errorf("JOB_SLA_TARGETS: %w", err)
+	}
+	if err := region.Validate(cfg.Region); err != nil {
+		return nil, fmt.Errorf("WORKER_REGION: %w", err)
+	}
+	if _, err := specview.ParseHashAlgorithm(string(cfg.CacheKeyHash.Algorithm)); err != nil {
+		return nil, fmt.Errorf("BEHAVIOR_CACHE_KEY_HASH: %w", err)
+	}
+	if _, err := specview.ParseHashAlgorithm(string(cfg.CacheKeyHash.Legacy)); err != nil {
+		return nil, fmt.Errorf("BEHAVIOR_CACHE_KEY_HASH_LEGACY: %w", err)
+	}
+	if _, err := specview.ParseCacheScopeMode(string(cfg.CacheScope)); err != nil {
+		return nil, fmt.Errorf("BEHAVIOR_CACHE_SCOPE: %w", err)
+	}
+	if _, err := specview.ParseGeneratorMode(string(cfg.GeneratorMode)); err != nil {
+		return nil, fmt.Errorf("SPEC_GENERATOR_MODE: %w", err)
+	}
+	if p := cfg.PromptExperiment.Percent; p < 0 || p > 100 {
+		return nil, fmt.Errorf("AI_PROMPT_CANDIDATE_PERCENT: %d is outside 0-100", p)
+	}
+	if f := cfg.Quality.FlagBelow; f < 0 || f > 1 {
+		return nil, fmt.Errorf("SPEC_QUALITY_FLAG_BELOW: %v is outside 0-1", f)
+	}
+	if c := cfg.Quality.LowConfidence; c < 0 || c > 1 {
+		return nil, fmt.Errorf("SPEC_QUALITY_LOW_CONFIDENCE: %v is outside 0-1", c)
+	}
+	if j := cfg.Retry.JitterFactor; j < 0 || j > 1 {
+		return nil, fmt.Errorf("RETRY_JITTER_FACTOR: %v is outside 0-1", j)
+	}
+	if s := cfg.SemanticCache.Similarity; s < 0 || s > 1 {
+		return nil, fmt.Errorf("BEHAVIOR_SEMANTIC_CACHE_SIMILARITY: %v is outside 0-1", s)
+	}
+	if cfg.SemanticCache.Enabled && cfg.SemanticCache.APIKey == "" && !cfg.MockMode {
+		return nil, errors.New("BEHAVIOR_SEMANTIC_CACHE_API_KEY (or GEMINI_API_KEY) is required when the semantic behavior cache is enabled")
+	}
+	if (cfg.GitHubApp.AppID != 0) != (cfg.GitHubApp.PrivateKey != "") {
+		return nil, errors.New("GITHUB_APP_ID and GITHUB_APP_PRIVATE_KEY must be set together")
+	}
+	cfg.DatabaseURL = databaseURL
+	cfg.EncryptionKey = encryptionKey
+	return cfg, nil
+}
 
+// LoadSettings loads every setting except the required secrets.
+// Used where no connection is made, such as printing build info.
+func LoadSettings() *Config {
 	return &Config{
-		DatabaseURL:       databaseURL,
-		EncryptionKey:     encryptionKey,
-		Fairness:          loadFairnessConfig(),
-		GeminiAPIKey:      os.Getenv("GEMINI_API_KEY"),
-		GeminiPhase1Model: os.Getenv("GEMINI_PHASE1_MODEL"),
-		GeminiPhase2Model: os.Getenv("GEMINI_PHASE2_MODEL"),
-		MockMode:          os.Getenv("MOCK_MODE") == "true",
-		Queue:             loadQueueConfig(),
-		Streaming:         loadStreamingConfig(),
-	}, nil
+		AI:                 loadAIConfig(),
+		CacheKeyHash:       loadCacheKeyHashConfig(),
+		CacheScope:         specview.CacheScopeMode(os.Getenv("BEHAVIOR_CACHE_SCOPE")),
+		DedupSimilarity:    getEnvFloat("BEHAVIOR_DEDUP_SIMILARITY", 0),
+		DocumentEncryption: loadDocumentEncryptionConfig(),
+		DocumentSharing:    getEnvBool("DOCUMENT_SHARING_ENABLED", false),
+		DrainTimeout:       getEnvDuration("DRAIN_TIMEOUT", 0),
+		Fairness:           loadFairnessConfig(),
+		FanOut:             loadFanOutConfig(),
+		GeneratorMode:      specview.GeneratorMode(os.Getenv("SPEC_GENERATOR_MODE")),
+		GitHubApp:          loadGitHubAppConfig(),
+		HostPools:          loadHostPools(),
+		HTTPAddr:           loadHTTPAddr(),
+		Idle:               loadIdleConfig(),
+		InFlightWait:       getEnvDuration("GENERATION_DEDUP_WAIT", 0),
+		MinHelperRefs:      getEnvInt("TEST_HELPERS_MIN_REFERENCES", 0),
+		MinTestVariants:    getEnvInt("TEST_VARIANTS_MIN", 0),
+		MockMode:           os.Getenv("MOCK_MODE") == "true",
+		Notifications:      loadNotificationsConfig(),
+		Outbox:             loadOutboxConfig(),
+		PromptExperiment:   loadPromptExperiment(),
+		Quality:            loadQualityConfig(),
+		Queue:              loadQueueConfig(),
+		QuotaWarnings:      getEnvIntList("QUOTA_WARNING_THRESHOLDS", nil),
+		Region:             os.Getenv("WORKER_REGION"),
+		Retry:              loadRetryConfig(),
+		SemanticCache:      loadSemanticCacheConfig(),
+		SLATargets:         loadSLATargets(),
+		Streaming:          loadStreamingConfig(),
+		Takeout:            loadTakeoutConfig(),
+		TokenKeys:          loadTokenKeysConfig(),
+		Tracing:            loadTracingConfig(),
+		Workspace:          loadWorkspaceConfig(),
+	}
+}
+
+func loadCacheKeyHashConfig() CacheKeyHashConfig {
+	return CacheKeyHashConfig{
+		Algorithm: specview.HashAlgorithm(os.Getenv("BEHAVIOR_CACHE_KEY_HASH")),
+		Legacy:    specview.HashAlgorithm(os.Getenv("BEHAVIOR_CACHE_KEY_HASH_LEGACY")),
+	}
+}
+
+func loadGitHubAppConfig() GitHubAppConfig {
+	appID, _ := strconv.ParseInt(os.Getenv("GITHUB_APP_ID"), 10, 64)
+	return GitHubAppConfig{
+		AppID:      max(appID, 0),
+		PrivateKey: os.Getenv("GITHUB_APP_PRIVATE_KEY"),
+	}
+}
+
+// loadHostPools reads ANALYZER_HOST_POOLS, ignoring an invalid spec,
+// which Load reports.
+func loadHostPools() []hostpool.Pool {
+	pools, err := hostpool.Parse(os.Getenv("ANALYZER_HOST_POOLS"))
+	if err != nil {
+		return nil
+	}
+	return pools
+}
+
+// loadSLATargets reads JOB_SLA_TARGETS, falling back to the default targets
+// on an invalid spec, which Load reports.
+func loadSLATargets() sla.Targets {
+	targets, err := sla.ParseTargets(os.Getenv("JOB_SLA_TARGETS"))
+	if err != nil {
+		return sla.DefaultTargets
+	}
+	return targets
+}
+
+func loadRetryConfig() RetryConfig {
+	return RetryConfig{
+		Default:      loadRetrySchedule("RETRY_DEFAULT"),
+		JitterFactor: getEnvFloat("RETRY_JITTER_FACTOR", 0),
+		RateLimit:    loadRetrySchedule("RETRY_RATE_LIMIT"),
+		Transient:    loadRetrySchedule("RETRY_TRANSIENT"),
+	}
+}
+
+func loadRetrySchedule(prefix string) RetrySchedule {
+	return RetrySchedule{
+		InitialBackoff: getEnvDuration(prefix+"_INITIAL_BACKOFF", 0),
+		MaxAttempts:    getEnvInt(prefix+"_MAX_ATTEMPTS", 0),
+		MaxBackoff:     getEnvDuration(prefix+"_MAX_BACKOFF", 0),
+	}
+}
+
+func loadTokenKeysConfig() TokenKeysConfig {
+	return TokenKeysConfig{
+		File:       os.Getenv("ENCRYPTION_KEYS_FILE"),
+		Keys:       os.Getenv("ENCRYPTION_KEYS"),
+		VaultAddr:  os.Getenv("VAULT_ADDR"),
+		VaultField: os.Getenv("ENCRYPTION_KEYS_VAULT_FIELD"),
+		VaultPath:  os.Getenv("ENCRYPTION_KEYS_VAULT_PATH"),
+		VaultToken: os.Getenv("VAULT_TOKEN"),
+	}
+}
+
+func countSet(values ...string) int {
+	n := 0
+	for _, v := range values {
+		if v != "" {
+			n++
+		}
+	}
+	return n
+}
+
+func loadPromptExperiment() PromptExperimentConfig {
+	return PromptExperimentConfig{
+		Candidate: os.Getenv("AI_PROMPT_CANDIDATE"),
+		Percent:   getEnvInt("AI_PROMPT_CANDIDATE_PERCENT", 0),
+	}
+}
+
+// loadQualityConfig loads the spec document quality settings.
+// Defaults: SPEC_QUALITY_FLAG_BELOW=0 (0.7), SPEC_QUALITY_LOW_CONFIDENCE=0 (0.5),
+// SPEC_QUALITY_REFINE=false
+func loadQualityConfig() QualityConfig {
+	return QualityConfig{
+		FlagBelow:     getEnvFloat("SPEC_QUALITY_FLAG_BELOW", 0),
+		LowConfidence: getEnvFloat("SPEC_QUALITY_LOW_CONFIDENCE", 0),
+		Refine:        getEnvBool("SPEC_QUALITY_REFINE", false),
+	}
+}
+
+// loadSemanticCacheConfig reads the semantic behavior cache settings. The
+// embedding model is always Gemini's, so the key falls back to GEMINI_API_KEY
+// whatever the AI provider.
+func loadSemanticCacheConfig() SemanticCacheConfig {
+	return SemanticCacheConfig{
+		APIKey:     getEnvString("BEHAVIOR_SEMANTIC_CACHE_API_KEY", os.Getenv("GEMINI_API_KEY")),
+		Enabled:    getEnvBool("BEHAVIOR_SEMANTIC_CACHE_ENABLED", false),
+		Model:      os.Getenv("BEHAVIOR_SEMANTIC_CACHE_MODEL"),
+		Similarity: getEnvFloat("BEHAVIOR_SEMANTIC_CACHE_SIMILARITY", 0),
+	}
+}
+
+// loadHTTPAddr returns HTTP_ADDR, falling back to the platform-injected PORT.
+func loadHTTPAddr() string {
+	if addr := os.Getenv("HTTP_ADDR"); addr != "" {
+		return addr
+	}
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return ""
+}
+
+// loadAIConfig loads AI provider settings from environment variables.
+// AI_PROVIDER defaults to gemini; the GEMINI_* variables remain as fallbacks
+// so existing deployments keep working without changes.
+func loadAIConfig() AIConfig {
+	cfg := AIConfig{
+		APIKey:      os.Getenv("AI_API_KEY"),
+		APIVersion:  os.Getenv("AI_API_VERSION"),
+		BaseURL:     os.Getenv("AI_BASE_URL"),
+		Phase1Model: os.Getenv("AI_PHASE1_MODEL"),
+		Phase2Model: os.Getenv("AI_PHASE2_MODEL"),
+		Provider:    strings.ToLower(os.Getenv("AI_PROVIDER")),
+
+		AllowedModels:   getEnvStringList("AI_ALLOWED_MODELS"),
+		Phase1Fallbacks: getEnvStringList("AI_PHASE1_FALLBACK_MODELS"),
+		Phase2Fallbacks: getEnvStringList("AI_PHASE2_FALLBACK_MODELS"),
+
+		ResponseCacheMaxEntries: getEnvInt("AI_RESPONSE_CACHE_MAX_ENTRIES", 0),
+		ResponseCacheTTL:        getEnvDuration("AI_RESPONSE_CACHE_TTL", 0),
+
+		BatchMinTests: getEnvInt("AI_BATCH_MIN_TESTS", 0),
+		BatchMaxWait:  getEnvDuration("AI_BATCH_MAX_WAIT", 0),
+
+		BatchFallbackAPIKey: os.Getenv("AI_BATCH_FALLBACK_API_KEY"),
+		BatchFallbackModel:  os.Getenv("AI_BATCH_FALLBACK_MODEL"),
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = "gemini"
+	}
+
+	if cfg.Provider == "gemini" {
+		cfg.APIKey = getEnvString("AI_API_KEY", os.Getenv("GEMINI_API_KEY"))
+		cfg.Phase1Model = getEnvString("AI_PHASE1_MODEL", os.Getenv("GEMINI_PHASE1_MODEL"))
+		cfg.Phase2Model = getEnvString("AI_PHASE2_MODEL", os.Getenv("GEMINI_PHASE2_MODEL"))
+	}
+
+	return cfg
 }
 
 func loadQueueConfig() QueueConfig {
@@ -102,7 +535,8 @@ func loadQueueConfig() QueueConfig {
 }
 
 // loadFairnessConfig loads fairness settings from environment variables.
-// Defaults: ENABLED=true, FREE=1, PRO=3, ENTERPRISE=5, SNOOZE=30s, JITTER=10s
+// Defaults: ENABLED=true, FREE=1, PRO=3, ENTERPRISE=5, SNOOZE=30s, JITTER=10s,
+// RATE_LIMIT_ENABLED=false, USER=10/min (burst 20), CODEBASE=2/min (burst 5)
 func loadFairnessConfig() FairnessConfig {
 	cfg := FairnessConfig{
 		Enabled:                   getEnvBool("FAIRNESS_ENABLED", true),
@@ -111,6 +545,11 @@ func loadFairnessConfig() FairnessConfig {
 		EnterpriseConcurrentLimit: getEnvInt("FAIRNESS_ENTERPRISE_LIMIT", 5),
 		SnoozeDuration:            getEnvDuration("FAIRNESS_SNOOZE_DURATION", 30*time.Second),
 		SnoozeJitter:              getEnvDuration("FAIRNESS_SNOOZE_JITTER", 10*time.Second),
+		RateLimitEnabled:          getEnvBool("FAIRNESS_RATE_LIMIT_ENABLED", false),
+		UserRatePerMinute:         getEnvInt("FAIRNESS_USER_RATE_PER_MINUTE", 10),
+		UserBurst:                 getEnvInt("FAIRNESS_USER_BURST", 20),
+		CodebaseRatePerMinute:     getEnvInt("FAIRNESS_CODEBASE_RATE_PER_MINUTE", 2),
+		CodebaseBurst:             getEnvInt("FAIRNESS_CODEBASE_BURST", 5),
 	}
 
 	if cfg.Enabled {
@@ -146,6 +585,13 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return parsed
 }
 
+func getEnvString(key string, defaultValue string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultValue
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	val := os.Getenv(key)
 	if val == "" {
@@ -158,6 +604,18 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return parsed
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	val := os.Getenv(key)
 	if val == "" {
@@ -173,9 +631,151 @@ func getEnvInt(key string, defaultValue int) int {
 	return parsed
 }
 
+// getEnvSignedInt is getEnvInt for settings where a negative value means
+// something, such as disabling a feature.
+func getEnvSignedInt(key string, defaultValue int) int {
+	parsed, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || parsed == 0 {
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvIntList parses a comma-separated list of positive integers,
+// returning defaultValue when any element is invalid.
+func getEnvIntList(key string, defaultValue []int) []int {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultValue
+	}
+	var list []int
+	for _, part := range strings.Split(val, ",") {
+		parsed, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || parsed <= 0 {
+			return defaultValue
+		}
+		list = append(list, parsed)
+	}
+	return list
+}
+
+// getEnvStringList parses a comma-separated list, dropping empty elements.
+func getEnvStringList(key string) []string {
+	var list []string
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
+
+// loadFanOutConfig loads Phase 2 fan-out settings from environment variables.
+// Defaults: ENABLED=false, MIN_DOMAINS=4, WORKERS=10
+func loadFanOutConfig() FanOutConfig {
+	return FanOutConfig{
+		Enabled:    getEnvBool("PHASE2_FANOUT_ENABLED", false),
+		MinDomains: getEnvInt("PHASE2_FANOUT_MIN_DOMAINS", 4),
+		Workers:    getEnvInt("SPECGEN_QUEUE_PHASE2_WORKERS", 10),
+	}
+}
+
+func loadIdleConfig() IdleConfig {
+	return IdleConfig{
+		Enabled:  getEnvBool("IDLE_DETECTION_ENABLED", false),
+		Grace:    getEnvDuration("IDLE_GRACE", 0),
+		WakeLead: getEnvDuration("IDLE_WAKE_LEAD", 0),
+	}
+}
+
+// loadDocumentEncryptionConfig loads the document encryption master keys.
+func loadDocumentEncryptionConfig() DocumentEncryptionConfig {
+	return DocumentEncryptionConfig{
+		MasterKey:         os.Getenv("DOCUMENT_ENCRYPTION_KEY"),
+		PreviousMasterKey: os.Getenv("DOCUMENT_ENCRYPTION_KEY_PREVIOUS"),
+	}
+}
+
+// loadWorkspaceConfig loads clone disk quota settings.
+// Defaults: QUOTA_MB=0 (unlimited), CLONE_RESERVE_MB=256, MAX_REPO_SIZE_MB=5120
+func loadWorkspaceConfig() WorkspaceConfig {
+	return WorkspaceConfig{
+		CloneReserveBytes: int64(getEnvInt("WORKSPACE_CLONE_RESERVE_MB", 256)) << 20,
+		MaxRepoSizeBytes:  int64(getEnvInt("MAX_REPO_SIZE_MB", 5120)) << 20,
+		QuotaBytes:        int64(getEnvInt("WORKSPACE_QUOTA_MB", 0)) << 20,
+	}
+}
+
+// loadTakeoutConfig loads the tenant takeout bucket settings.
+// Defaults: TAKEOUT_S3_BUCKET unset (disabled), TAKEOUT_S3_ENDPOINT=https://s3.<region>.amazonaws.com,
+// TAKEOUT_S3_REGION=us-east-1, TAKEOUT_URL_TTL=0 (72h)
+func loadTakeoutConfig() TakeoutConfig {
+	cfg := TakeoutConfig{
+		AccessKeyID:     os.Getenv("TAKEOUT_S3_ACCESS_KEY_ID"),
+		Bucket:          os.Getenv("TAKEOUT_S3_BUCKET"),
+		Endpoint:        os.Getenv("TAKEOUT_S3_ENDPOINT"),
+		PathStyle:       getEnvBool("TAKEOUT_S3_PATH_STYLE", false),
+		Region:          getEnvString("TAKEOUT_S3_REGION", "us-east-1"),
+		SecretAccessKey: os.Getenv("TAKEOUT_S3_SECRET_ACCESS_KEY"),
+		URLTTL:          getEnvDuration("TAKEOUT_URL_TTL", 0),
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	return cfg
+}
+
+// loadNotificationsConfig loads the job notification settings.
+// Defaults: NOTIFICATIONS_ENABLED=false, NOTIFICATIONS_QUEUE_SIZE=0 (256),
+// NOTIFICATIONS_SEND_TIMEOUT=0 (10s), NOTIFICATIONS_WORKERS=0 (2)
+func loadNotificationsConfig() NotificationsConfig {
+	return NotificationsConfig{
+		Enabled:     getEnvBool("NOTIFICATIONS_ENABLED", false),
+		QueueSize:   getEnvInt("NOTIFICATIONS_QUEUE_SIZE", 0),
+		SendTimeout: getEnvDuration("NOTIFICATIONS_SEND_TIMEOUT", 0),
+		Workers:     getEnvInt("NOTIFICATIONS_WORKERS", 0),
+	}
+}
+
+// loadOutboxConfig loads the outbox relay settings. SNS credentials fall back
+// to the standard AWS variables.
+// Defaults: OUTBOX_SINK_URL unset (relay disabled), OUTBOX_BATCH_SIZE=0 (50),
+// OUTBOX_MAX_ATTEMPTS=0 (20), OUTBOX_POLL_INTERVAL=0 (5s), OUTBOX_RETENTION=0 (168h)
+func loadOutboxConfig() OutboxConfig {
+	return OutboxConfig{
+		BatchSize:          getEnvInt("OUTBOX_BATCH_SIZE", 0),
+		MaxAttempts:        getEnvInt("OUTBOX_MAX_ATTEMPTS", 0),
+		PollInterval:       getEnvDuration("OUTBOX_POLL_INTERVAL", 0),
+		Retention:          getEnvDuration("OUTBOX_RETENTION", 0),
+		SinkURL:            os.Getenv("OUTBOX_SINK_URL"),
+		SNSAccessKeyID:     getEnvString("OUTBOX_SNS_ACCESS_KEY_ID", os.Getenv("AWS_ACCESS_KEY_ID")),
+		SNSEndpoint:        os.Getenv("OUTBOX_SNS_ENDPOINT"),
+		SNSSecretAccessKey: getEnvString("OUTBOX_SNS_SECRET_ACCESS_KEY", os.Getenv("AWS_SECRET_ACCESS_KEY")),
+		WebhookSecret:      os.Getenv("OUTBOX_WEBHOOK_SECRET"),
+	}
+}
+
+// loadTracingConfig loads trace export settings using the standard OTel variables.
+// Defaults: OTEL_EXPORTER_OTLP_ENDPOINT unset (disabled), OTEL_TRACES_SAMPLER_ARG=1
+func loadTracingConfig() TracingConfig {
+	cfg := TracingConfig{
+		Endpoint:    os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		SampleRatio: getEnvFloat("OTEL_TRACES_SAMPLER_ARG", 1),
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		cfg.SampleRatio = 1
+	}
+	return cfg
+}
+
 // loadStreamingConfig loads streaming analysis pipeline settings.
+// Defaults: ANALYSIS_BATCH_SIZE=100, ANALYSIS_PARSE_WORKERS=0 (GOMAXPROCS),
+// ANALYSIS_UNMATCHED_SAMPLES=20, ANALYSIS_UNMATCHED_SNIPPET_LINES=0 (paths only)
 func loadStreamingConfig() StreamingConfig {
 	return StreamingConfig{
-		BatchSize: getEnvInt("ANALYSIS_BATCH_SIZE", 100),
+		BatchSize:             getEnvInt("ANALYSIS_BATCH_SIZE", 100),
+		ParseWorkers:          getEnvInt("ANALYSIS_PARSE_WORKERS", 0),
+		UnmatchedSamples:      getEnvSignedInt("ANALYSIS_UNMATCHED_SAMPLES", 20),
+		UnmatchedSnippetLines: getEnvInt("ANALYSIS_UNMATCHED_SNIPPET_LINES", 0),
 	}
 }
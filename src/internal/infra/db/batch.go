@@ -5,33 +5,127 @@ INSERT INTO test_suites (file_id, parent_id, name, line_number, depth)
 VALUES ($1, $2, $3, $4, $5)
 RETURNING id`
 
-var TestCaseCopyColumns = []string{"suite_id", "name", "line_number", "status", "tags", "modifier"}
+var TestCaseCopyColumns = []string{"suite_id", "name", "line_number", "status", "tags", "modifier", "variant_count"}
+
+var StagedTestFileCopyColumns = []string{"id", "analysis_id", "file_path", "framework", "domain_hints", "project", "content_hash"}
+
+var StagedTestSuiteCopyColumns = []string{"id", "analysis_id", "file_id", "parent_id", "name", "line_number", "depth"}
+
+var StagedTestCaseCopyColumns = []string{"analysis_id", "suite_id", "name", "line_number", "status", "tags", "modifier", "variant_count"}
 
 const InsertSpecDomainBatch = `
-INSERT INTO spec_domains (document_id, name, description, sort_order, classification_confidence)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO spec_domains (document_id, name, description, sort_order, classification_confidence, slug)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING id`
 
 const InsertSpecFeatureBatch = `
-INSERT INTO spec_features (domain_id, name, description, sort_order)
-VALUES ($1, $2, $3, $4)
+INSERT INTO spec_features (domain_id, name, description, sort_order, slug)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING id`
 
 var SpecBehaviorCopyColumns = []string{
+	"id",
 	"feature_id",
 	"source_test_case_id",
 	"original_name",
 	"converted_description",
 	"sort_order",
+	"cache_key_hash",
 }
 
+var SpecBehaviorMergeCopyColumns = []string{
+	"behavior_id",
+	"source_test_case_id",
+	"original_name",
+	"converted_description",
+	"similarity",
+	"sort_order",
+}
+
+var SpecBehaviorTestCaseCopyColumns = []string{"behavior_id", "test_case_id", "sort_order"}
+
+const UpdateSpecBehaviorDescriptionBatch = `
+UPDATE spec_behaviors SET converted_description = $2 WHERE id = $1`
+
+const UpdateSpecBehaviorMergeDescriptionBatch = `
+UPDATE spec_behavior_merges SET converted_description = $2 WHERE id = $1`
+
 const UpsertBehaviorCacheBatch = `
-INSERT INTO behavior_caches (cache_key_hash, converted_description)
-VALUES ($1, $2)
+INSERT INTO behavior_caches (cache_key_hash, converted_description, codebase_id)
+VALUES ($1, $2, (SELECT a.codebase_id FROM analyses a WHERE a.id = $3))
 ON CONFLICT (cache_key_hash) DO UPDATE
-SET converted_description = EXCLUDED.converted_description`
+SET converted_description = EXCLUDED.converted_description,
+    codebase_id = COALESCE(EXCLUDED.codebase_id, behavior_caches.codebase_id)
+WHERE NOT behavior_caches.human_verified`
+
+const UpsertTestCaseResultBatch = `
+INSERT INTO test_case_results (test_case_id, last_status, last_duration_ms, runs, passes, failures, last_upload_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (test_case_id) DO UPDATE SET
+    last_status = EXCLUDED.last_status,
+    last_duration_ms = EXCLUDED.last_duration_ms,
+    runs = test_case_results.runs + EXCLUDED.runs,
+    passes = test_case_results.passes + EXCLUDED.passes,
+    failures = test_case_results.failures + EXCLUDED.failures,
+    last_upload_id = EXCLUDED.last_upload_id,
+    updated_at = now()`
+
+const UpsertTestFileCoverageBatch = `
+INSERT INTO test_file_coverage (test_file_id, source_path, lines_valid, lines_covered, upload_id)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (test_file_id, source_path) DO UPDATE SET
+    lines_valid = EXCLUDED.lines_valid,
+    lines_covered = EXCLUDED.lines_covered,
+    upload_id = EXCLUDED.upload_id,
+    updated_at = now()`
 
 const InsertTestFileBatch = `
-INSERT INTO test_files (analysis_id, file_path, framework, domain_hints)
-VALUES ($1, $2, $3, $4)
+INSERT INTO test_files (analysis_id, file_path, framework, domain_hints, project, content_hash)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING id`
+
+var SpecSearchEntryCopyColumns = []string{
+	"document_id",
+	"codebase_id",
+	"document_version",
+	"language",
+	"behavior_id",
+	"domain_name",
+	"feature_name",
+	"behavior_description",
+}
+
+var RequirementCopyColumns = []string{
+	"requirement_set_id",
+	"external_key",
+	"title",
+	"description",
+	"sort_order",
+}
+
+var RequirementCoverageCopyColumns = []string{
+	"requirement_id",
+	"document_id",
+	"behavior_id",
+	"score",
+}
+
+var AnalysisSourceFileCopyColumns = []string{"analysis_id", "file_path"}
+
+var AnalysisLanguageCopyColumns = []string{
+	"analysis_id",
+	"language",
+	"files",
+	"tests",
+	"failed_files",
+	"unmatched_files",
+}
+
+var AnalysisSharedHelperCopyColumns = []string{"analysis_id", "helper_path", "test_files"}
+
+var UnmatchedFileSampleCopyColumns = []string{
+	"analysis_id",
+	"file_path",
+	"language",
+	"snippet",
+}
@@ -11,6 +11,53 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AnalysisFailureClass string
+
+const (
+	AnalysisFailureClassCloneAuthFailed AnalysisFailureClass = "clone_auth_failed"
+	AnalysisFailureClassCloneTimeout    AnalysisFailureClass = "clone_timeout"
+	AnalysisFailureClassParserPanic     AnalysisFailureClass = "parser_panic"
+	AnalysisFailureClassRepoTooLarge    AnalysisFailureClass = "repo_too_large"
+	AnalysisFailureClassDiskFull        AnalysisFailureClass = "disk_full"
+	AnalysisFailureClassOom             AnalysisFailureClass = "oom"
+	AnalysisFailureClassUnknown         AnalysisFailureClass = "unknown"
+)
+
+func (e *AnalysisFailureClass) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AnalysisFailureClass(s)
+	case string:
+		*e = AnalysisFailureClass(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AnalysisFailureClass: %T", src)
+	}
+	return nil
+}
+
+type NullAnalysisFailureClass struct {
+	AnalysisFailureClass AnalysisFailureClass `json:"analysis_failure_class"`
+	Valid                bool                 `json:"valid"` // Valid is true if AnalysisFailureClass is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAnalysisFailureClass) Scan(value interface{}) error {
+	if value == nil {
+		ns.AnalysisFailureClass, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AnalysisFailureClass.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAnalysisFailureClass) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AnalysisFailureClass), nil
+}
+
 type AnalysisStatus string
 
 const (
@@ -360,20 +407,116 @@ func (ns NullUsageEventType) Value() (driver.Value, error) {
 	return string(ns.UsageEventType), nil
 }
 
-type Analysis struct {
+type AiOutputQuarantine struct {
 	ID            pgtype.UUID        `json:"id"`
-	CodebaseID    pgtype.UUID        `json:"codebase_id"`
-	CommitSha     string             `json:"commit_sha"`
-	BranchName    pgtype.Text        `json:"branch_name"`
-	Status        AnalysisStatus     `json:"status"`
-	ErrorMessage  pgtype.Text        `json:"error_message"`
-	StartedAt     pgtype.Timestamptz `json:"started_at"`
-	CompletedAt   pgtype.Timestamptz `json:"completed_at"`
+	Phase         string             `json:"phase"`
+	SchemaVersion string             `json:"schema_version"`
+	ModelID       string             `json:"model_id"`
+	Response      string             `json:"response"`
+	Error         string             `json:"error"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
-	TotalSuites   int32              `json:"total_suites"`
-	TotalTests    int32              `json:"total_tests"`
-	CommittedAt   pgtype.Timestamptz `json:"committed_at"`
-	ParserVersion string             `json:"parser_version"`
+}
+
+type AiUsage struct {
+	ID               pgtype.UUID        `json:"id"`
+	JobID            pgtype.Int8        `json:"job_id"`
+	AnalysisID       pgtype.UUID        `json:"analysis_id"`
+	UserID           pgtype.UUID        `json:"user_id"`
+	DocumentID       pgtype.UUID        `json:"document_id"`
+	Phase            string             `json:"phase"`
+	Model            string             `json:"model"`
+	FallbackFrom     pgtype.Text        `json:"fallback_from"`
+	PromptTokens     int32              `json:"prompt_tokens"`
+	CandidatesTokens int32              `json:"candidates_tokens"`
+	TotalTokens      int32              `json:"total_tokens"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type Analysis struct {
+	ID               pgtype.UUID              `json:"id"`
+	CodebaseID       pgtype.UUID              `json:"codebase_id"`
+	CommitSha        string                   `json:"commit_sha"`
+	BranchName       pgtype.Text              `json:"branch_name"`
+	Status           AnalysisStatus           `json:"status"`
+	ErrorMessage     pgtype.Text              `json:"error_message"`
+	StartedAt        pgtype.Timestamptz       `json:"started_at"`
+	CompletedAt      pgtype.Timestamptz       `json:"completed_at"`
+	CreatedAt        pgtype.Timestamptz       `json:"created_at"`
+	TotalSuites      int32                    `json:"total_suites"`
+	TotalTests       int32                    `json:"total_tests"`
+	CommittedAt      pgtype.Timestamptz       `json:"committed_at"`
+	ParserVersion    string                   `json:"parser_version"`
+	CurationRules    []byte                   `json:"curation_rules"`
+	FailureClass     NullAnalysisFailureClass `json:"failure_class"`
+	PathFilters      []byte                   `json:"path_filters"`
+	QuarantinedFiles int32                    `json:"quarantined_files"`
+	TestFingerprint  []byte                   `json:"test_fingerprint"`
+	DeduplicatedFrom pgtype.UUID              `json:"deduplicated_from"`
+}
+
+type AnalysisEvent struct {
+	ID             pgtype.UUID        `json:"id"`
+	JobID          pgtype.Int8        `json:"job_id"`
+	AnalysisID     pgtype.UUID        `json:"analysis_id"`
+	Owner          string             `json:"owner"`
+	Repo           string             `json:"repo"`
+	CommitSha      string             `json:"commit_sha"`
+	Stage          string             `json:"stage"`
+	FilesProcessed int32              `json:"files_processed"`
+	FilesTotal     int32              `json:"files_total"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type AnalysisLanguage struct {
+	AnalysisID     pgtype.UUID `json:"analysis_id"`
+	Language       string      `json:"language"`
+	Files          int32       `json:"files"`
+	Tests          int32       `json:"tests"`
+	FailedFiles    int32       `json:"failed_files"`
+	UnmatchedFiles int32       `json:"unmatched_files"`
+}
+
+type AnalysisSharedHelper struct {
+	AnalysisID pgtype.UUID        `json:"analysis_id"`
+	HelperPath string             `json:"helper_path"`
+	TestFiles  []string           `json:"test_files"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type AnalysisSourceFile struct {
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+	FilePath   string      `json:"file_path"`
+}
+
+type AnalysisStagingTestCase struct {
+	AnalysisID   pgtype.UUID `json:"analysis_id"`
+	SuiteID      pgtype.UUID `json:"suite_id"`
+	Name         string      `json:"name"`
+	LineNumber   pgtype.Int4 `json:"line_number"`
+	Status       TestStatus  `json:"status"`
+	Tags         []byte      `json:"tags"`
+	Modifier     pgtype.Text `json:"modifier"`
+	VariantCount int32       `json:"variant_count"`
+}
+
+type AnalysisStagingTestFile struct {
+	ID          pgtype.UUID `json:"id"`
+	AnalysisID  pgtype.UUID `json:"analysis_id"`
+	FilePath    string      `json:"file_path"`
+	Framework   pgtype.Text `json:"framework"`
+	DomainHints []byte      `json:"domain_hints"`
+	Project     pgtype.Text `json:"project"`
+	ContentHash []byte      `json:"content_hash"`
+}
+
+type AnalysisStagingTestSuite struct {
+	ID         pgtype.UUID `json:"id"`
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+	FileID     pgtype.UUID `json:"file_id"`
+	ParentID   pgtype.UUID `json:"parent_id"`
+	Name       string      `json:"name"`
+	LineNumber pgtype.Int4 `json:"line_number"`
+	Depth      int32       `json:"depth"`
 }
 
 type AtlasSchemaRevision struct {
@@ -396,6 +539,25 @@ type BehaviorCach struct {
 	CacheKeyHash         []byte             `json:"cache_key_hash"`
 	ConvertedDescription string             `json:"converted_description"`
 	CreatedAt            pgtype.Timestamptz `json:"created_at"`
+	CodebaseID           pgtype.UUID        `json:"codebase_id"`
+	HumanVerified        bool               `json:"human_verified"`
+}
+
+type BehaviorCacheEmbedding struct {
+	CacheKeyHash  []byte             `json:"cache_key_hash"`
+	PartitionHash []byte             `json:"partition_hash"`
+	Embedding     interface{}        `json:"embedding"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type BehaviorFeedback struct {
+	ID                   pgtype.UUID        `json:"id"`
+	BehaviorID           pgtype.UUID        `json:"behavior_id"`
+	UserID               pgtype.UUID        `json:"user_id"`
+	CorrectedDescription string             `json:"corrected_description"`
+	Status               string             `json:"status"`
+	CreatedAt            pgtype.Timestamptz `json:"created_at"`
+	AppliedAt            pgtype.Timestamptz `json:"applied_at"`
 }
 
 type ClassificationCach struct {
@@ -406,20 +568,55 @@ type ClassificationCach struct {
 	Phase1Output []byte             `json:"phase1_output"`
 	TestIndexMap []byte             `json:"test_index_map"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	CodebaseID   pgtype.UUID        `json:"codebase_id"`
+}
+
+type CodebaseSharingOptOut struct {
+	CodebaseID pgtype.UUID        `json:"codebase_id"`
+	Reason     pgtype.Text        `json:"reason"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type CodebaseTaxonomy struct {
+	CodebaseID pgtype.UUID        `json:"codebase_id"`
+	Domains    []byte             `json:"domains"`
+	Mode       string             `json:"mode"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Codebasis struct {
-	ID             pgtype.UUID        `json:"id"`
-	Host           string             `json:"host"`
-	Owner          string             `json:"owner"`
-	Name           string             `json:"name"`
-	DefaultBranch  pgtype.Text        `json:"default_branch"`
+	ID               pgtype.UUID        `json:"id"`
+	Host             string             `json:"host"`
+	Owner            string             `json:"owner"`
+	Name             string             `json:"name"`
+	DefaultBranch    pgtype.Text        `json:"default_branch"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	LastViewedAt     pgtype.Timestamptz `json:"last_viewed_at"`
+	ExternalRepoID   string             `json:"external_repo_id"`
+	IsStale          bool               `json:"is_stale"`
+	IsPrivate        bool               `json:"is_private"`
+	Region           pgtype.Text        `json:"region"`
+	ForkDedupEnabled bool               `json:"fork_dedup_enabled"`
+}
+
+type CoverageUpload struct {
+	ID          pgtype.UUID        `json:"id"`
+	AnalysisID  pgtype.UUID        `json:"analysis_id"`
+	Format      string             `json:"format"`
+	Content     []byte             `json:"content"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	ProcessedAt pgtype.Timestamptz `json:"processed_at"`
+}
+
+type EnqueueIdempotencyKey struct {
+	IdempotencyKey string             `json:"idempotency_key"`
+	JobKind        string             `json:"job_kind"`
+	RequestHash    string             `json:"request_hash"`
+	JobID          pgtype.Int8        `json:"job_id"`
 	CreatedAt      pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
-	LastViewedAt   pgtype.Timestamptz `json:"last_viewed_at"`
-	ExternalRepoID string             `json:"external_repo_id"`
-	IsStale        bool               `json:"is_stale"`
-	IsPrivate      bool               `json:"is_private"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
 }
 
 type GithubAppInstallation struct {
@@ -446,6 +643,44 @@ type GithubOrganization struct {
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
 
+type GlossaryEntry struct {
+	ID         pgtype.UUID        `json:"id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	CodebaseID pgtype.UUID        `json:"codebase_id"`
+	Language   string             `json:"language"`
+	Term       string             `json:"term"`
+	Preferred  string             `json:"preferred"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type JobResult struct {
+	JobID       int64              `json:"job_id"`
+	Kind        string             `json:"kind"`
+	Queue       string             `json:"queue"`
+	Status      string             `json:"status"`
+	Attempt     int32              `json:"attempt"`
+	AnalysisID  pgtype.UUID        `json:"analysis_id"`
+	DocumentID  pgtype.UUID        `json:"document_id"`
+	ErrorCode   pgtype.Text        `json:"error_code"`
+	Stats       []byte             `json:"stats"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	EnqueuedAt  pgtype.Timestamptz `json:"enqueued_at"`
+	StartedAt   pgtype.Timestamptz `json:"started_at"`
+}
+
+type NotificationChannel struct {
+	ID         pgtype.UUID        `json:"id"`
+	CodebaseID pgtype.UUID        `json:"codebase_id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Kind       string             `json:"kind"`
+	Url        string             `json:"url"`
+	OnSuccess  bool               `json:"on_success"`
+	OnFailure  bool               `json:"on_failure"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
 type OauthAccount struct {
 	ID               pgtype.UUID        `json:"id"`
 	UserID           pgtype.UUID        `json:"user_id"`
@@ -458,6 +693,49 @@ type OauthAccount struct {
 	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
 }
 
+type OutboxEvent struct {
+	ID            pgtype.UUID        `json:"id"`
+	EventType     string             `json:"event_type"`
+	AggregateID   pgtype.UUID        `json:"aggregate_id"`
+	Payload       []byte             `json:"payload"`
+	Attempts      int32              `json:"attempts"`
+	LastError     pgtype.Text        `json:"last_error"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	PublishedAt   pgtype.Timestamptz `json:"published_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type ParseError struct {
+	ID         pgtype.UUID        `json:"id"`
+	AnalysisID pgtype.UUID        `json:"analysis_id"`
+	FilePath   string             `json:"file_path"`
+	Message    string             `json:"message"`
+	Panicked   bool               `json:"panicked"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type ParserCompatReport struct {
+	ID               pgtype.UUID        `json:"id"`
+	CandidateVersion string             `json:"candidate_version"`
+	SampleSize       int32              `json:"sample_size"`
+	Matched          int32              `json:"matched"`
+	Mismatched       int32              `json:"mismatched"`
+	Skipped          int32              `json:"skipped"`
+	Failed           int32              `json:"failed"`
+	Details          []byte             `json:"details"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type PoisonJob struct {
+	JobID       int64              `json:"job_id"`
+	Kind        string             `json:"kind"`
+	Queue       string             `json:"queue"`
+	Attempt     int32              `json:"attempt"`
+	EncodedArgs []byte             `json:"encoded_args"`
+	Error       string             `json:"error"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
 type QuotaReservation struct {
 	ID             pgtype.UUID        `json:"id"`
 	UserID         pgtype.UUID        `json:"user_id"`
@@ -468,6 +746,12 @@ type QuotaReservation struct {
 	CreatedAt      pgtype.Timestamptz `json:"created_at"`
 }
 
+type RateLimitBucket struct {
+	BucketKey string             `json:"bucket_key"`
+	Tokens    float64            `json:"tokens"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
 type RefreshToken struct {
 	ID        pgtype.UUID        `json:"id"`
 	UserID    pgtype.UUID        `json:"user_id"`
@@ -479,6 +763,60 @@ type RefreshToken struct {
 	Replaces  pgtype.UUID        `json:"replaces"`
 }
 
+type RegenCampaign struct {
+	ID            pgtype.UUID        `json:"id"`
+	Name          string             `json:"name"`
+	Status        string             `json:"status"`
+	RatePerMinute int32              `json:"rate_per_minute"`
+	MaxInFlight   int32              `json:"max_in_flight"`
+	Selection     []byte             `json:"selection"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+type RegenCampaignTarget struct {
+	ID         pgtype.UUID        `json:"id"`
+	CampaignID pgtype.UUID        `json:"campaign_id"`
+	DocumentID pgtype.UUID        `json:"document_id"`
+	AnalysisID pgtype.UUID        `json:"analysis_id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Language   string             `json:"language"`
+	TeamSlices bool               `json:"team_slices"`
+	Status     string             `json:"status"`
+	JobID      pgtype.Int8        `json:"job_id"`
+	Error      pgtype.Text        `json:"error"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	EnqueuedAt pgtype.Timestamptz `json:"enqueued_at"`
+	FinishedAt pgtype.Timestamptz `json:"finished_at"`
+}
+
+type Requirement struct {
+	ID               pgtype.UUID        `json:"id"`
+	RequirementSetID pgtype.UUID        `json:"requirement_set_id"`
+	ExternalKey      string             `json:"external_key"`
+	Title            string             `json:"title"`
+	Description      pgtype.Text        `json:"description"`
+	SortOrder        int32              `json:"sort_order"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type RequirementCoverage struct {
+	ID            pgtype.UUID        `json:"id"`
+	RequirementID pgtype.UUID        `json:"requirement_id"`
+	DocumentID    pgtype.UUID        `json:"document_id"`
+	BehaviorID    pgtype.UUID        `json:"behavior_id"`
+	Score         pgtype.Numeric     `json:"score"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type RequirementSet struct {
+	ID           pgtype.UUID        `json:"id"`
+	CodebaseID   pgtype.UUID        `json:"codebase_id"`
+	Name         string             `json:"name"`
+	SourceFormat string             `json:"source_format"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
 type RiverClient struct {
 	ID        string             `json:"id"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
@@ -534,6 +872,34 @@ type RiverQueue struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type ServiceToken struct {
+	ID          pgtype.UUID        `json:"id"`
+	Name        string             `json:"name"`
+	TokenHash   []byte             `json:"token_hash"`
+	TokenPrefix string             `json:"token_prefix"`
+	Scopes      []string           `json:"scopes"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt  pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt   pgtype.Timestamptz `json:"revoked_at"`
+}
+
+type SlaViolation struct {
+	ID           pgtype.UUID        `json:"id"`
+	JobID        int64              `json:"job_id"`
+	Kind         string             `json:"kind"`
+	Queue        string             `json:"queue"`
+	Attempt      int32              `json:"attempt"`
+	Outcome      string             `json:"outcome"`
+	TargetMs     int64              `json:"target_ms"`
+	ElapsedMs    int64              `json:"elapsed_ms"`
+	SlowestPhase string             `json:"slowest_phase"`
+	Phases       []byte             `json:"phases"`
+	AnalysisID   pgtype.UUID        `json:"analysis_id"`
+	DocumentID   pgtype.UUID        `json:"document_id"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
 type SpecBehavior struct {
 	ID                   pgtype.UUID        `json:"id"`
 	FeatureID            pgtype.UUID        `json:"feature_id"`
@@ -542,6 +908,31 @@ type SpecBehavior struct {
 	ConvertedDescription string             `json:"converted_description"`
 	SortOrder            int32              `json:"sort_order"`
 	CreatedAt            pgtype.Timestamptz `json:"created_at"`
+	CacheKeyHash         []byte             `json:"cache_key_hash"`
+}
+
+type SpecBehaviorCoverage struct {
+	BehaviorID   pgtype.UUID        `json:"behavior_id"`
+	LinesValid   int32              `json:"lines_valid"`
+	LinesCovered int32              `json:"lines_covered"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type SpecBehaviorMerge struct {
+	ID                   pgtype.UUID        `json:"id"`
+	BehaviorID           pgtype.UUID        `json:"behavior_id"`
+	SourceTestCaseID     pgtype.UUID        `json:"source_test_case_id"`
+	OriginalName         string             `json:"original_name"`
+	ConvertedDescription string             `json:"converted_description"`
+	Similarity           pgtype.Numeric     `json:"similarity"`
+	SortOrder            int32              `json:"sort_order"`
+	CreatedAt            pgtype.Timestamptz `json:"created_at"`
+}
+
+type SpecBehaviorTestCase struct {
+	BehaviorID pgtype.UUID `json:"behavior_id"`
+	TestCaseID pgtype.UUID `json:"test_case_id"`
+	SortOrder  int32       `json:"sort_order"`
 }
 
 type SpecDocument struct {
@@ -556,6 +947,55 @@ type SpecDocument struct {
 	Version                 int32              `json:"version"`
 	UserID                  pgtype.UUID        `json:"user_id"`
 	RetentionDaysAtCreation pgtype.Int4        `json:"retention_days_at_creation"`
+	ParentDocumentID        pgtype.UUID        `json:"parent_document_id"`
+	Team                    pgtype.Text        `json:"team"`
+	Project                 pgtype.Text        `json:"project"`
+	EncryptionKeyID         pgtype.UUID        `json:"encryption_key_id"`
+	PromptVersion           string             `json:"prompt_version"`
+	DeletedAt               pgtype.Timestamptz `json:"deleted_at"`
+	DeletedBy               pgtype.Text        `json:"deleted_by"`
+	QualityScore            pgtype.Float8      `json:"quality_score"`
+	QualityFlagged          bool               `json:"quality_flagged"`
+	QualityReport           []byte             `json:"quality_report"`
+	IsDryRun                bool               `json:"is_dry_run"`
+	Generator               string             `json:"generator"`
+}
+
+type SpecDocumentDecision struct {
+	ID           pgtype.UUID        `json:"id"`
+	DocumentID   pgtype.UUID        `json:"document_id"`
+	AnalysisID   pgtype.UUID        `json:"analysis_id"`
+	GenerationID pgtype.UUID        `json:"generation_id"`
+	Sequence     int32              `json:"sequence"`
+	Kind         string             `json:"kind"`
+	Subject      string             `json:"subject"`
+	Detail       []byte             `json:"detail"`
+	OccurredAt   pgtype.Timestamptz `json:"occurred_at"`
+}
+
+type SpecDocumentExport struct {
+	ID          pgtype.UUID        `json:"id"`
+	DocumentID  pgtype.UUID        `json:"document_id"`
+	Format      string             `json:"format"`
+	ContentType string             `json:"content_type"`
+	FileName    string             `json:"file_name"`
+	Content     []byte             `json:"content"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type SpecDocumentPin struct {
+	DocumentID pgtype.UUID        `json:"document_id"`
+	ReleaseTag string             `json:"release_tag"`
+	PinnedBy   pgtype.Text        `json:"pinned_by"`
+	PinnedAt   pgtype.Timestamptz `json:"pinned_at"`
+}
+
+type SpecDocumentShare struct {
+	DocumentID pgtype.UUID        `json:"document_id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Basis      string             `json:"basis"`
+	SharedAt   pgtype.Timestamptz `json:"shared_at"`
 }
 
 type SpecDomain struct {
@@ -567,6 +1007,7 @@ type SpecDomain struct {
 	ClassificationConfidence pgtype.Numeric     `json:"classification_confidence"`
 	CreatedAt                pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt                pgtype.Timestamptz `json:"updated_at"`
+	Slug                     string             `json:"slug"`
 }
 
 type SpecFeature struct {
@@ -577,6 +1018,74 @@ type SpecFeature struct {
 	SortOrder   int32              `json:"sort_order"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	Slug        string             `json:"slug"`
+}
+
+type SpecGapReport struct {
+	DocumentID         pgtype.UUID        `json:"document_id"`
+	TotalSourceFiles   int32              `json:"total_source_files"`
+	CoveredSourceFiles int32              `json:"covered_source_files"`
+	UncoveredPackages  []byte             `json:"uncovered_packages"`
+	UncoveredFiles     []byte             `json:"uncovered_files"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+}
+
+type SpecGenerationCancellation struct {
+	AnalysisID  pgtype.UUID        `json:"analysis_id"`
+	Language    string             `json:"language"`
+	RequestedBy pgtype.UUID        `json:"requested_by"`
+	RequestedAt pgtype.Timestamptz `json:"requested_at"`
+}
+
+type SpecGenerationCheckpoint struct {
+	JobID        int64              `json:"job_id"`
+	AnalysisID   pgtype.UUID        `json:"analysis_id"`
+	ContentHash  []byte             `json:"content_hash"`
+	Phase1Output []byte             `json:"phase1_output"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type SpecGenerationCheckpointFeature struct {
+	JobID        int64              `json:"job_id"`
+	DomainIndex  int32              `json:"domain_index"`
+	FeatureIndex int32              `json:"feature_index"`
+	Behaviors    []byte             `json:"behaviors"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type SpecGenerationClaim struct {
+	ClaimKey  []byte             `json:"claim_key"`
+	JobID     int64              `json:"job_id"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type SpecGenerationProgress struct {
+	AnalysisID        pgtype.UUID        `json:"analysis_id"`
+	Language          string             `json:"language"`
+	Status            string             `json:"status"`
+	TotalFeatures     int32              `json:"total_features"`
+	CompletedFeatures int32              `json:"completed_features"`
+	FailedFeatures    int32              `json:"failed_features"`
+	CacheHitRate      float64            `json:"cache_hit_rate"`
+	EtaSeconds        int64              `json:"eta_seconds"`
+	StartedAt         pgtype.Timestamptz `json:"started_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+}
+
+type SpecSearchEntry struct {
+	ID                  pgtype.UUID        `json:"id"`
+	DocumentID          pgtype.UUID        `json:"document_id"`
+	CodebaseID          pgtype.UUID        `json:"codebase_id"`
+	DocumentVersion     int32              `json:"document_version"`
+	Language            string             `json:"language"`
+	BehaviorID          pgtype.UUID        `json:"behavior_id"`
+	DomainName          string             `json:"domain_name"`
+	FeatureName         string             `json:"feature_name"`
+	BehaviorDescription string             `json:"behavior_description"`
+	SearchVector        interface{}        `json:"search_vector"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
 }
 
 type SubscriptionPlan struct {
@@ -595,14 +1104,102 @@ type SystemConfig struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type Tenant struct {
+	ID                 pgtype.UUID        `json:"id"`
+	Name               string             `json:"name"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	DocumentEncryption bool               `json:"document_encryption"`
+}
+
+type TenantAiBudget struct {
+	TenantID             pgtype.UUID        `json:"tenant_id"`
+	MonthlyTokenLimit    pgtype.Int8        `json:"monthly_token_limit"`
+	MonthlyBehaviorLimit pgtype.Int8        `json:"monthly_behavior_limit"`
+	DowngradeModel       pgtype.Text        `json:"downgrade_model"`
+	CreatedAt            pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
+}
+
+type TenantAiUsage struct {
+	ID         pgtype.UUID        `json:"id"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
+	DocumentID pgtype.UUID        `json:"document_id"`
+	Tokens     int64              `json:"tokens"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	JobID      pgtype.Int8        `json:"job_id"`
+}
+
+type TenantDocumentKey struct {
+	ID         pgtype.UUID        `json:"id"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
+	WrappedKey string             `json:"wrapped_key"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	RetiredAt  pgtype.Timestamptz `json:"retired_at"`
+}
+
+type TenantMember struct {
+	UserID    pgtype.UUID        `json:"user_id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type TenantRepoPolicy struct {
+	ID        pgtype.UUID        `json:"id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	Effect    string             `json:"effect"`
+	Pattern   string             `json:"pattern"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type TenantTakeout struct {
+	ID                pgtype.UUID        `json:"id"`
+	TenantID          pgtype.UUID        `json:"tenant_id"`
+	Status            string             `json:"status"`
+	ObjectKey         pgtype.Text        `json:"object_key"`
+	SizeBytes         pgtype.Int8        `json:"size_bytes"`
+	DownloadUrl       pgtype.Text        `json:"download_url"`
+	DownloadExpiresAt pgtype.Timestamptz `json:"download_expires_at"`
+	ErrorMessage      pgtype.Text        `json:"error_message"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	CompletedAt       pgtype.Timestamptz `json:"completed_at"`
+}
+
 type TestCase struct {
-	ID         pgtype.UUID `json:"id"`
-	SuiteID    pgtype.UUID `json:"suite_id"`
-	Name       string      `json:"name"`
-	LineNumber pgtype.Int4 `json:"line_number"`
-	Status     TestStatus  `json:"status"`
-	Tags       []byte      `json:"tags"`
-	Modifier   pgtype.Text `json:"modifier"`
+	ID           pgtype.UUID `json:"id"`
+	SuiteID      pgtype.UUID `json:"suite_id"`
+	Name         string      `json:"name"`
+	LineNumber   pgtype.Int4 `json:"line_number"`
+	Status       TestStatus  `json:"status"`
+	Tags         []byte      `json:"tags"`
+	Modifier     pgtype.Text `json:"modifier"`
+	VariantCount int32       `json:"variant_count"`
+}
+
+type TestCaseResult struct {
+	TestCaseID     pgtype.UUID        `json:"test_case_id"`
+	LastStatus     string             `json:"last_status"`
+	LastDurationMs int64              `json:"last_duration_ms"`
+	Runs           int32              `json:"runs"`
+	Passes         int32              `json:"passes"`
+	Failures       int32              `json:"failures"`
+	LastUploadID   pgtype.UUID        `json:"last_upload_id"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type TestDelta struct {
+	ID                pgtype.UUID        `json:"id"`
+	CodebaseID        pgtype.UUID        `json:"codebase_id"`
+	BaseAnalysisID    pgtype.UUID        `json:"base_analysis_id"`
+	HeadAnalysisID    pgtype.UUID        `json:"head_analysis_id"`
+	PullRequestNumber pgtype.Int4        `json:"pull_request_number"`
+	TestsAdded        int32              `json:"tests_added"`
+	TestsRemoved      int32              `json:"tests_removed"`
+	TestsRenamed      int32              `json:"tests_renamed"`
+	SuitesAdded       int32              `json:"suites_added"`
+	SuitesRemoved     int32              `json:"suites_removed"`
+	Details           []byte             `json:"details"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
 }
 
 type TestFile struct {
@@ -611,6 +1208,26 @@ type TestFile struct {
 	FilePath    string      `json:"file_path"`
 	Framework   pgtype.Text `json:"framework"`
 	DomainHints []byte      `json:"domain_hints"`
+	Project     pgtype.Text `json:"project"`
+	ContentHash []byte      `json:"content_hash"`
+}
+
+type TestFileCoverage struct {
+	TestFileID   pgtype.UUID        `json:"test_file_id"`
+	SourcePath   string             `json:"source_path"`
+	LinesValid   int32              `json:"lines_valid"`
+	LinesCovered int32              `json:"lines_covered"`
+	UploadID     pgtype.UUID        `json:"upload_id"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type TestResultUpload struct {
+	ID          pgtype.UUID        `json:"id"`
+	AnalysisID  pgtype.UUID        `json:"analysis_id"`
+	Format      string             `json:"format"`
+	Content     []byte             `json:"content"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	ProcessedAt pgtype.Timestamptz `json:"processed_at"`
 }
 
 type TestSuite struct {
@@ -622,6 +1239,14 @@ type TestSuite struct {
 	FileID     pgtype.UUID `json:"file_id"`
 }
 
+type UnmatchedFileSample struct {
+	AnalysisID pgtype.UUID        `json:"analysis_id"`
+	FilePath   string             `json:"file_path"`
+	Language   string             `json:"language"`
+	Snippet    pgtype.Text        `json:"snippet"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
 type UsageEvent struct {
 	ID          pgtype.UUID        `json:"id"`
 	UserID      pgtype.UUID        `json:"user_id"`
@@ -632,6 +1257,17 @@ type UsageEvent struct {
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 }
 
+type UsageWarning struct {
+	ID          pgtype.UUID        `json:"id"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	PeriodStart pgtype.Date        `json:"period_start"`
+	Threshold   int16              `json:"threshold"`
+	DocumentID  pgtype.UUID        `json:"document_id"`
+	QuotaUsed   int64              `json:"quota_used"`
+	QuotaLimit  int64              `json:"quota_limit"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
 type User struct {
 	ID           pgtype.UUID        `json:"id"`
 	Email        pgtype.Text        `json:"email"`
@@ -710,3 +1346,11 @@ type UserSubscription struct {
 	CreatedAt          pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
 }
+
+type WebhookDelivery struct {
+	DeliveryID string             `json:"delivery_id"`
+	Event      string             `json:"event"`
+	Outcome    pgtype.Text        `json:"outcome"`
+	ReceivedAt pgtype.Timestamptz `json:"received_at"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/specvital/worker/internal/infra/tracing"
 )
 
 const (
@@ -33,6 +35,7 @@ func NewPool(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
 	config.HealthCheckPeriod = defaultHealthCheckPeriod
 	config.MaxConnIdleTime = defaultMaxConnIdleTime
 	config.MaxConnLifetime = defaultMaxConnLifetime
+	config.ConnConfig.Tracer = tracing.NewQueryTracer()
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -1,6 +1,7 @@
 -- name: UpsertCodebase :one
-INSERT INTO codebases (host, owner, name, default_branch, external_repo_id, is_private)
-VALUES ($1, $2, $3, $4, $5, $6)
+-- Region is set on creation and never changed by later analyses.
+INSERT INTO codebases (host, owner, name, default_branch, external_repo_id, is_private, region)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 ON CONFLICT (host, external_repo_id)
 DO UPDATE SET
     owner = EXCLUDED.owner,
@@ -8,6 +9,7 @@ DO UPDATE SET
     default_branch = COALESCE(EXCLUDED.default_branch, codebases.default_branch),
     is_stale = false,
     is_private = EXCLUDED.is_private,
+    region = COALESCE(codebases.region, EXCLUDED.region),
     updated_at = now()
 RETURNING *;
 
@@ -23,8 +25,8 @@ WHERE host = $1 AND owner = $2 AND name = $3 AND is_stale = false;
 SELECT * FROM codebases WHERE id = $1;
 
 -- name: CreateAnalysis :one
-INSERT INTO analyses (id, codebase_id, commit_sha, branch_name, status, started_at, parser_version)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO analyses (id, codebase_id, commit_sha, branch_name, status, started_at, parser_version, path_filters)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: UpdateAnalysisCompleted :exec
@@ -34,7 +36,12 @@ WHERE id = $1;
 
 -- name: UpdateAnalysisFailed :exec
 UPDATE analyses
-SET status = 'failed', error_message = $2, completed_at = $3
+SET status = 'failed', error_message = $2, completed_at = $3, failure_class = $4
+WHERE id = $1;
+
+-- name: UpdateAnalysisSource :exec
+UPDATE analyses
+SET codebase_id = $2, commit_sha = $3, branch_name = $4, path_filters = $5
 WHERE id = $1;
 
 -- name: CreateTestCase :one
@@ -116,7 +123,8 @@ SELECT value FROM system_config WHERE key = $1;
 -- name: GetMaxVersionByUserAnalysisAndLanguage :one
 SELECT COALESCE(MAX(version), 0)::int as max_version
 FROM spec_documents
-WHERE user_id = $1 AND analysis_id = $2 AND language = $3;
+WHERE user_id = $1 AND analysis_id = $2 AND language = $3 AND parent_document_id IS NULL
+  AND project IS NOT DISTINCT FROM $4;
 
 -- name: FindSpecDocumentByContentHash :one
 SELECT sd.* FROM spec_documents sd
@@ -124,27 +132,127 @@ WHERE sd.user_id = $1
   AND sd.content_hash = $2
   AND sd.language = $3
   AND sd.model_id = $4
+  AND sd.parent_document_id IS NULL
+  AND sd.deleted_at IS NULL
   AND sd.version = (
     SELECT MAX(version)
     FROM spec_documents
     WHERE user_id = sd.user_id
       AND analysis_id = sd.analysis_id
       AND language = sd.language
+      AND parent_document_id IS NULL
+      AND project IS NOT DISTINCT FROM sd.project
   );
 
+-- name: FindShareableSpecDocument :one
+-- Latest full document another user generated for the same codebase and content.
+-- Encrypted and deleted documents are never shared.
+SELECT sd.* FROM spec_documents sd
+JOIN analyses a ON a.id = sd.analysis_id
+WHERE sd.user_id <> @user_id
+  AND sd.content_hash = @content_hash
+  AND sd.language = @language
+  AND sd.model_id = @model_id
+  AND sd.parent_document_id IS NULL
+  AND sd.encryption_key_id IS NULL
+  AND sd.deleted_at IS NULL
+  AND a.codebase_id = (SELECT codebase_id FROM analyses WHERE id = @analysis_id)
+ORDER BY sd.created_at DESC
+LIMIT 1;
+
+-- name: IsCodebaseSharingOptedOut :one
+SELECT EXISTS(
+    SELECT 1 FROM codebase_sharing_opt_outs o
+    JOIN analyses a ON a.codebase_id = o.codebase_id
+    WHERE a.id = @analysis_id
+) AS opted_out;
+
+-- name: UpsertSpecDocumentShare :exec
+INSERT INTO spec_document_shares (document_id, user_id, basis)
+VALUES (@document_id, @user_id, @basis)
+ON CONFLICT (document_id, user_id) DO UPDATE SET
+    basis = EXCLUDED.basis,
+    shared_at = now();
+
+-- name: GetLatestDocumentOutline :many
+-- Outline of the user's latest document for the codebase of the given analysis, in any version.
+SELECT
+    dom.slug AS domain_slug,
+    dom.name AS domain_name,
+    sf.slug AS feature_slug,
+    sf.name AS feature_name,
+    COALESCE(sb.original_name, '')::text AS original_name
+FROM spec_domains dom
+JOIN spec_features sf ON sf.domain_id = dom.id
+LEFT JOIN spec_behaviors sb ON sb.feature_id = sf.id
+WHERE dom.document_id = (
+    SELECT sd.id
+    FROM spec_documents sd
+    JOIN analyses a ON a.id = sd.analysis_id
+    WHERE sd.user_id = @user_id
+      AND sd.language = @language
+      AND sd.parent_document_id IS NULL
+      AND sd.project IS NULL
+      AND sd.deleted_at IS NULL
+      AND a.codebase_id = (SELECT codebase_id FROM analyses WHERE id = @analysis_id)
+    ORDER BY sd.created_at DESC, sd.version DESC
+    LIMIT 1
+)
+ORDER BY dom.sort_order, sf.sort_order, sb.sort_order;
+
+-- name: FindSpecDocumentAsOf :one
+-- Latest full document the user had for the codebase before the given time.
+SELECT sd.* FROM spec_documents sd
+JOIN analyses a ON a.id = sd.analysis_id
+WHERE sd.user_id = @user_id
+  AND a.codebase_id = @codebase_id
+  AND sd.language = @language
+  AND sd.parent_document_id IS NULL
+  AND sd.project IS NULL
+  AND sd.deleted_at IS NULL
+  AND sd.created_at < @as_of
+ORDER BY sd.created_at DESC, sd.version DESC
+LIMIT 1;
+
+-- name: GetSpecDocumentContent :many
+SELECT
+    dom.id AS domain_id,
+    dom.name AS domain_name,
+    dom.description AS domain_description,
+    dom.classification_confidence,
+    dom.slug AS domain_slug,
+    sf.id AS feature_id,
+    sf.name AS feature_name,
+    sf.description AS feature_description,
+    sf.slug AS feature_slug,
+    sb.id AS behavior_id,
+    sb.original_name,
+    sb.converted_description,
+    sb.source_test_case_id,
+    ARRAY(
+        SELECT bt.test_case_id FROM spec_behavior_test_cases bt
+        WHERE bt.behavior_id = sb.id
+        ORDER BY bt.sort_order
+    )::uuid[] AS test_case_ids
+FROM spec_domains dom
+JOIN spec_features sf ON sf.domain_id = dom.id
+LEFT JOIN spec_behaviors sb ON sb.feature_id = sf.id
+WHERE dom.document_id = $1
+ORDER BY dom.sort_order, sf.sort_order, sb.sort_order;
+
 -- name: InsertSpecDocument :one
-INSERT INTO spec_documents (user_id, analysis_id, content_hash, language, executive_summary, model_id, version, retention_days_at_creation)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO spec_documents (user_id, analysis_id, content_hash, language, executive_summary, model_id, version, retention_days_at_creation, parent_document_id, team, project, encryption_key_id, prompt_version, quality_score, quality_flagged, quality_report, is_dry_run, generator)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 RETURNING id;
 
 -- name: InsertSpecDomain :one
-INSERT INTO spec_domains (document_id, name, description, sort_order, classification_confidence)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO spec_domains (document_id, name, description, sort_order, classification_confidence, slug)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING id;
 
 -- name: InsertSpecFeature :one
-INSERT INTO spec_features (domain_id, name, description, sort_order)
-VALUES ($1, $2, $3, $4)
+INSERT INTO spec_features (domain_id, name, description, sort_order, slug)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING id;
 
 -- name: GetTestDataByAnalysisID :many
@@ -153,6 +261,7 @@ SELECT
     tf.file_path,
     tf.framework,
     tf.domain_hints,
+    tf.project,
     ts.id as suite_id,
     ts.parent_id as suite_parent_id,
     ts.name as suite_name,
@@ -189,12 +298,28 @@ WHERE user_id = $1
   AND event_type = 'specview'
   AND created_at >= date_trunc('month', CURRENT_DATE);
 
+-- name: GetUserSpecViewQuota :one
+-- Spec-view limit of the user's active plan, or of the free plan without one,
+-- with this month's usage. A NULL limit means unlimited.
+SELECT
+    (SELECT sp.specview_monthly_limit
+     FROM subscription_plans sp
+     WHERE sp.id = COALESCE(
+         (SELECT us.plan_id FROM user_subscriptions us WHERE us.user_id = $1 AND us.status = 'active' LIMIT 1),
+         (SELECT fp.id FROM subscription_plans fp WHERE fp.tier = 'free' ORDER BY fp.created_at LIMIT 1)
+     )) AS specview_limit,
+    (SELECT COALESCE(SUM(e.quota_amount), 0)
+     FROM usage_events e
+     WHERE e.user_id = $1
+       AND e.event_type = 'specview'
+       AND e.created_at >= date_trunc('month', CURRENT_DATE))::bigint AS used;
+
 -- name: RecordAnalysisUsageEvent :exec
 INSERT INTO usage_events (user_id, event_type, analysis_id, quota_amount)
 VALUES ($1, 'analysis', $2, $3);
 
 -- name: GetAnalysisContext :one
-SELECT c.host, c.owner, c.name as repo
+SELECT c.host, c.owner, c.name as repo, c.is_private
 FROM analyses a
 JOIN codebases c ON a.codebase_id = c.id
 WHERE a.id = $1;
@@ -209,10 +334,69 @@ FROM behavior_caches
 WHERE cache_key_hash = ANY($1::bytea[]);
 
 -- name: UpsertBehaviorCache :exec
-INSERT INTO behavior_caches (cache_key_hash, converted_description)
-VALUES ($1, $2)
+-- The entry belongs to the codebase of the analysis that last wrote it.
+INSERT INTO behavior_caches (cache_key_hash, converted_description, codebase_id)
+VALUES ($1, $2, (SELECT a.codebase_id FROM analyses a WHERE a.id = @analysis_id))
+ON CONFLICT (cache_key_hash) DO UPDATE
+SET converted_description = EXCLUDED.converted_description,
+    codebase_id = COALESCE(EXCLUDED.codebase_id, behavior_caches.codebase_id)
+WHERE NOT behavior_caches.human_verified;
+
+-- name: FindVerifiedBehaviorCachesByHashes :many
+SELECT cache_key_hash, converted_description
+FROM behavior_caches
+WHERE cache_key_hash = ANY($1::bytea[]) AND human_verified;
+
+-- name: UpsertVerifiedBehaviorCache :exec
+-- Verified entries are only ever replaced by another verified entry.
+INSERT INTO behavior_caches (cache_key_hash, converted_description, codebase_id, human_verified)
+VALUES ($1, $2, (SELECT a.codebase_id FROM analyses a WHERE a.id = @analysis_id), true)
 ON CONFLICT (cache_key_hash) DO UPDATE
-SET converted_description = EXCLUDED.converted_description;
+SET converted_description = EXCLUDED.converted_description,
+    codebase_id = COALESCE(EXCLUDED.codebase_id, behavior_caches.codebase_id),
+    human_verified = true;
+
+-- name: InsertBehaviorEmbeddings :exec
+-- Embeddings are text in pgvector's '[x,y,...]' format. Keys already stored
+-- keep their embedding.
+INSERT INTO behavior_cache_embeddings (cache_key_hash, partition_hash, embedding)
+SELECT t.cache_key_hash, @partition_hash::bytea, t.embedding::vector
+FROM unnest(@cache_key_hashes::bytea[], @embeddings::text[]) AS t(cache_key_hash, embedding)
+ON CONFLICT (cache_key_hash) DO NOTHING;
+
+-- name: FindSimilarBehaviors :many
+-- Returns, per query embedding (1-based index), the cached behavior of the
+-- nearest test in the partition by cosine distance, when similar enough.
+SELECT q.idx::int AS query_index, n.converted_description, n.similarity::float8 AS similarity
+FROM unnest(@embeddings::text[]) WITH ORDINALITY AS q(embedding, idx)
+CROSS JOIN LATERAL (
+    SELECT bc.converted_description, 1 - (e.embedding <=> q.embedding::vector) AS similarity
+    FROM behavior_cache_embeddings e
+    JOIN behavior_caches bc ON bc.cache_key_hash = e.cache_key_hash
+    WHERE e.partition_hash = @partition_hash::bytea
+    ORDER BY e.embedding <=> q.embedding::vector
+    LIMIT 1
+) n
+WHERE n.similarity >= @min_similarity::float8;
+
+-- =============================================================================
+-- BEHAVIOR FEEDBACK
+-- =============================================================================
+
+-- name: GetBehaviorFeedback :one
+SELECT bf.id, bf.user_id, bf.corrected_description, bf.status,
+       sb.cache_key_hash, sd.id AS document_id, sd.analysis_id
+FROM behavior_feedback bf
+JOIN spec_behaviors sb ON sb.id = bf.behavior_id
+JOIN spec_features sf ON sf.id = sb.feature_id
+JOIN spec_domains sdm ON sdm.id = sf.domain_id
+JOIN spec_documents sd ON sd.id = sdm.document_id
+WHERE bf.id = $1;
+
+-- name: MarkBehaviorFeedbackApplied :execrows
+UPDATE behavior_feedback
+SET status = 'applied', applied_at = now()
+WHERE id = $1 AND status = 'accepted';
 
 -- =============================================================================
 -- CLASSIFICATION CACHES
@@ -224,11 +408,13 @@ FROM classification_caches
 WHERE content_hash = $1 AND language = $2 AND model_id = $3;
 
 -- name: UpsertClassificationCache :exec
-INSERT INTO classification_caches (content_hash, language, model_id, phase1_output, test_index_map)
-VALUES ($1, $2, $3, $4, $5)
+-- The entry belongs to the codebase of the analysis that last wrote it.
+INSERT INTO classification_caches (content_hash, language, model_id, phase1_output, test_index_map, codebase_id)
+VALUES ($1, $2, $3, $4, $5, (SELECT a.codebase_id FROM analyses a WHERE a.id = @analysis_id))
 ON CONFLICT ON CONSTRAINT uq_classification_caches_key DO UPDATE
 SET phase1_output = EXCLUDED.phase1_output,
     test_index_map = EXCLUDED.test_index_map,
+    codebase_id = COALESCE(EXCLUDED.codebase_id, classification_caches.codebase_id),
     created_at = now();
 
 -- name: DeleteExpiredClassificationCaches :execrows
@@ -279,23 +465,1585 @@ WHERE id IN (
 );
 
 -- name: DeleteExpiredSpecDocuments :execrows
--- Deletes spec_documents that have exceeded their retention period.
+-- Deletes spec_documents that have exceeded their retention period. Pinned
+-- documents and the team slices of pinned documents are kept.
 DELETE FROM spec_documents
 WHERE id IN (
-    SELECT id FROM spec_documents
-    WHERE retention_days_at_creation IS NOT NULL
-      AND created_at < now() - (retention_days_at_creation || ' days')::interval
+    SELECT sd.id FROM spec_documents sd
+    WHERE sd.retention_days_at_creation IS NOT NULL
+      AND sd.created_at < now() - (sd.retention_days_at_creation || ' days')::interval
+      AND NOT EXISTS (
+          SELECT 1 FROM spec_document_pins p
+          WHERE p.document_id = COALESCE(sd.parent_document_id, sd.id)
+      )
+    LIMIT $1
+);
+
+-- name: DeleteSupersededSpecDocuments :execrows
+-- Deletes full document versions created before @created_before, keeping the
+-- @keep_versions latest undeleted versions of each user, analysis, project,
+-- language and model. Pinned versions are kept and count toward the latest.
+-- Team slices, domains, features and behaviors go with their documents.
+DELETE FROM spec_documents
+WHERE id IN (
+    SELECT ranked.id FROM (
+        SELECT sd.id, sd.created_at,
+            row_number() OVER (
+                PARTITION BY sd.user_id, sd.analysis_id, sd.project, sd.language, sd.model_id
+                ORDER BY sd.version DESC
+            ) AS recency
+        FROM spec_documents sd
+        WHERE sd.parent_document_id IS NULL
+          AND sd.deleted_at IS NULL
+    ) ranked
+    WHERE ranked.recency > @keep_versions::int
+      AND ranked.created_at < @created_before
+      AND NOT EXISTS (SELECT 1 FROM spec_document_pins p WHERE p.document_id = ranked.id)
+    LIMIT @batch_size
+);
+
+-- name: DeleteSoftDeletedSpecDocuments :execrows
+-- Deletes full documents soft-deleted before @deleted_before, with their team
+-- slices. Pinned documents cannot be deleted, but are kept regardless.
+DELETE FROM spec_documents
+WHERE id IN (
+    SELECT sd.id FROM spec_documents sd
+    WHERE sd.deleted_at < @deleted_before
+      AND NOT EXISTS (SELECT 1 FROM spec_document_pins p WHERE p.document_id = sd.id)
+    LIMIT @batch_size
+);
+
+-- name: DeleteExpiredWebhookDeliveries :execrows
+-- Deletes webhook delivery records past their deduplication window.
+DELETE FROM webhook_deliveries
+WHERE delivery_id IN (
+    SELECT delivery_id FROM webhook_deliveries
+    WHERE expires_at < now()
+    LIMIT $1
+);
+
+-- name: DeleteStaleCodebaseBehaviorCaches :execrows
+-- Deletes behavior cache entries of codebases stale for over a day, e.g.
+-- repositories deleted and recreated under the same name. The grace period
+-- keeps them for a codebase restored soon after.
+DELETE FROM behavior_caches
+WHERE id IN (
+    SELECT bc.id FROM behavior_caches bc
+    JOIN codebases c ON c.id = bc.codebase_id
+    WHERE c.is_stale = true
+      AND c.updated_at < now() - interval '1 day'
+    LIMIT $1
+);
+
+-- name: DeleteOrphanBehaviorEmbeddings :execrows
+-- Deletes embeddings older than a day without a behavior cache entry: tests
+-- whose conversion never succeeded, or whose entry was deleted since.
+DELETE FROM behavior_cache_embeddings
+WHERE cache_key_hash IN (
+    SELECT e.cache_key_hash FROM behavior_cache_embeddings e
+    WHERE e.created_at < now() - interval '1 day'
+      AND NOT EXISTS (SELECT 1 FROM behavior_caches bc WHERE bc.cache_key_hash = e.cache_key_hash)
+    LIMIT $1
+);
+
+-- name: DeleteStaleCodebaseClassificationCaches :execrows
+-- Deletes classification cache entries of codebases stale for over a day.
+DELETE FROM classification_caches
+WHERE id IN (
+    SELECT cc.id FROM classification_caches cc
+    JOIN codebases c ON c.id = cc.codebase_id
+    WHERE c.is_stale = true
+      AND c.updated_at < now() - interval '1 day'
     LIMIT $1
 );
 
 -- name: DeleteOrphanedAnalyses :execrows
 -- Deletes analyses that have no references in user_analysis_history.
--- These are orphaned records that no user is tracking anymore.
+-- These are orphaned records that no user is tracking anymore. Analyses with a
+-- pinned document are kept, since deleting them would delete the document.
 DELETE FROM analyses
 WHERE id IN (
     SELECT a.id FROM analyses a
     LEFT JOIN user_analysis_history uah ON a.id = uah.analysis_id
     WHERE uah.analysis_id IS NULL
       AND a.created_at < now() - interval '1 day'
+      AND NOT EXISTS (
+          SELECT 1 FROM spec_documents sd
+          JOIN spec_document_pins p ON p.document_id = sd.id
+          WHERE sd.analysis_id = a.id
+      )
+    LIMIT $1
+);
+
+-- =============================================================================
+-- SPEC SEARCH INDEX
+-- =============================================================================
+
+-- name: GetSpecSearchSourceByDocumentID :many
+-- Encrypted and deleted documents are not indexed.
+SELECT
+    sd.id as document_id,
+    a.codebase_id,
+    sd.version as document_version,
+    sd.language,
+    sb.id as behavior_id,
+    dom.name as domain_name,
+    sf.name as feature_name,
+    sb.converted_description as behavior_description
+FROM spec_documents sd
+JOIN analyses a ON a.id = sd.analysis_id
+JOIN spec_domains dom ON dom.document_id = sd.id
+JOIN spec_features sf ON sf.domain_id = dom.id
+JOIN spec_behaviors sb ON sb.feature_id = sf.id
+WHERE sd.id = $1
+  AND sd.encryption_key_id IS NULL
+  AND sd.deleted_at IS NULL
+ORDER BY dom.sort_order, sf.sort_order, sb.sort_order;
+
+-- name: DeleteSpecSearchEntriesByDocumentID :execrows
+DELETE FROM spec_search_entries WHERE document_id = $1;
+
+-- name: RebuildSpecSearchEntries :execrows
+-- Indexes the saved behaviors of the document; encrypted and deleted documents
+-- are skipped.
+INSERT INTO spec_search_entries (document_id, codebase_id, document_version, language, behavior_id, domain_name, feature_name, behavior_description)
+SELECT sd.id, a.codebase_id, sd.version, sd.language, sb.id, dom.name, sf.name, sb.converted_description
+FROM spec_documents sd
+JOIN analyses a ON a.id = sd.analysis_id
+JOIN spec_domains dom ON dom.document_id = sd.id
+JOIN spec_features sf ON sf.domain_id = dom.id
+JOIN spec_behaviors sb ON sb.feature_id = sf.id
+WHERE sd.id = $1
+  AND sd.encryption_key_id IS NULL
+  AND sd.deleted_at IS NULL;
+
+-- =============================================================================
+-- REQUIREMENTS
+-- =============================================================================
+
+-- name: InsertRequirementSet :one
+INSERT INTO requirement_sets (codebase_id, name, source_format)
+VALUES ($1, $2, $3)
+RETURNING id;
+
+-- name: GetRequirementSetByID :one
+SELECT * FROM requirement_sets WHERE id = $1;
+
+-- name: GetRequirementsBySetID :many
+SELECT id, external_key, title, description
+FROM requirements
+WHERE requirement_set_id = $1
+ORDER BY sort_order;
+
+-- name: DeleteRequirementCoverageBySetAndDocument :execrows
+DELETE FROM requirement_coverage rc
+USING requirements r
+WHERE rc.requirement_id = r.id
+  AND r.requirement_set_id = $1
+  AND rc.document_id = $2;
+
+-- =============================================================================
+-- SPEC GAPS
+-- =============================================================================
+
+-- name: GetSourceFilePathsByDocumentID :many
+SELECT sf.file_path
+FROM analysis_source_files sf
+JOIN spec_documents sd ON sd.analysis_id = sf.analysis_id
+WHERE sd.id = $1
+ORDER BY sf.file_path;
+
+-- name: GetMappedTestFilePathsByDocumentID :many
+SELECT DISTINCT tf.file_path
+FROM spec_domains dom
+JOIN spec_features f ON f.domain_id = dom.id
+JOIN spec_behaviors b ON b.feature_id = f.id
+JOIN test_cases tc ON tc.id = b.source_test_case_id
+    OR tc.id IN (SELECT bt.test_case_id FROM spec_behavior_test_cases bt WHERE bt.behavior_id = b.id)
+JOIN test_suites ts ON ts.id = tc.suite_id
+JOIN test_files tf ON tf.id = ts.file_id
+WHERE dom.document_id = $1
+ORDER BY tf.file_path;
+
+-- name: UpsertSpecGapReport :exec
+INSERT INTO spec_gap_reports (document_id, total_source_files, covered_source_files, uncovered_packages, uncovered_files)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (document_id) DO UPDATE
+SET total_source_files = EXCLUDED.total_source_files,
+    covered_source_files = EXCLUDED.covered_source_files,
+    uncovered_packages = EXCLUDED.uncovered_packages,
+    uncovered_files = EXCLUDED.uncovered_files,
+    created_at = now();
+
+-- name: UpdateAnalysisCurationRules :exec
+UPDATE analyses
+SET curation_rules = $2
+WHERE id = $1;
+
+-- name: GetAnalysisCurationRules :one
+SELECT curation_rules FROM analyses WHERE id = $1;
+
+-- name: GetLatestCodebasePathFilters :one
+-- Path filters of the most recent analysis of the codebase, whatever its status.
+SELECT path_filters FROM analyses
+WHERE codebase_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: InsertAnalysisEvent :exec
+-- Saves a progress event and notifies LISTEN analysis_progress subscribers in one statement.
+WITH inserted AS (
+    INSERT INTO analysis_events (job_id, analysis_id, owner, repo, commit_sha, stage, files_processed, files_total, created_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    RETURNING job_id, analysis_id, owner, repo, commit_sha, stage, files_processed, files_total, created_at
+)
+SELECT pg_notify('analysis_progress', row_to_json(inserted)::text) FROM inserted;
+
+-- name: MergeRiverJobMetadata :exec
+-- Merges keys into a running job's metadata; River merges its own updates on completion.
+UPDATE river_job
+SET metadata = metadata || @metadata::jsonb
+WHERE id = @id;
+
+-- name: UpsertSpecGenerationProgress :exec
+-- Stores the latest Phase 2 snapshot and notifies LISTEN spec_generation_progress subscribers.
+-- Within one run (same started_at), stale snapshots and updates after a terminal status are ignored.
+WITH upserted AS (
+    INSERT INTO spec_generation_progress (analysis_id, language, status, total_features, completed_features, failed_features, cache_hit_rate, eta_seconds, started_at, updated_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, now())
+    ON CONFLICT (analysis_id, language) DO UPDATE
+    SET status = EXCLUDED.status,
+        total_features = EXCLUDED.total_features,
+        completed_features = EXCLUDED.completed_features,
+        failed_features = EXCLUDED.failed_features,
+        cache_hit_rate = EXCLUDED.cache_hit_rate,
+        eta_seconds = EXCLUDED.eta_seconds,
+        started_at = EXCLUDED.started_at,
+        updated_at = now()
+    WHERE spec_generation_progress.started_at <> EXCLUDED.started_at
+       OR (spec_generation_progress.status = 'running'
+           AND spec_generation_progress.completed_features <= EXCLUDED.completed_features)
+    RETURNING analysis_id, language, status, total_features, completed_features, failed_features, cache_hit_rate, eta_seconds, started_at, updated_at
+)
+SELECT pg_notify('spec_generation_progress', row_to_json(upserted)::text) FROM upserted;
+
+-- name: ListDiscardedJobs :many
+-- Dead-letter view of discarded jobs joined with the analysis they refer to.
+-- Specview jobs match by analysis_id; analyze jobs by owner/repo/commit (latest analysis).
+SELECT
+    j.id,
+    j.kind,
+    j.attempt,
+    j.max_attempts,
+    j.finalized_at,
+    COALESCE(j.errors[array_upper(j.errors, 1)] ->> 'error', '')::text AS last_error,
+    a.analysis_id,
+    COALESCE(a.status::text, '')::text AS analysis_status,
+    COALESCE(a.owner, j.args ->> 'owner', '')::text AS owner,
+    COALESCE(a.repo, j.args ->> 'repo', '')::text AS repo,
+    COALESCE(a.commit_sha, j.args ->> 'commit_sha', '')::text AS commit_sha
+FROM river_job j
+LEFT JOIN LATERAL (
+    SELECT an.id AS analysis_id, an.status, an.commit_sha, c.owner, c.name AS repo
+    FROM analyses an
+    JOIN codebases c ON c.id = an.codebase_id
+    WHERE (j.kind = 'specview:generate' AND an.id = (j.args ->> 'analysis_id')::uuid)
+       OR (j.kind = 'analysis:analyze'
+           AND c.owner = j.args ->> 'owner'
+           AND c.name = j.args ->> 'repo'
+           AND an.commit_sha = j.args ->> 'commit_sha')
+    ORDER BY an.created_at DESC
+    LIMIT 1
+) a ON true
+WHERE j.state = 'discarded'
+  AND j.kind = ANY(@kinds::text[])
+  AND j.finalized_at >= @discarded_after::timestamptz
+  AND (cardinality(@job_ids::bigint[]) = 0 OR j.id = ANY(@job_ids::bigint[]))
+ORDER BY j.finalized_at DESC
+LIMIT @max_rows;
+
+-- name: ListParserCompatSamples :many
+-- Random sample of public repositories whose latest completed analysis was
+-- produced by a parser version other than the candidate.
+SELECT s.analysis_id, s.commit_sha, s.parser_version, s.curation_rules, s.owner, s.repo
+FROM (
+    SELECT DISTINCT ON (c.id)
+        a.id AS analysis_id,
+        a.commit_sha,
+        a.parser_version,
+        a.curation_rules,
+        c.owner,
+        c.name AS repo
+    FROM codebases c
+    JOIN analyses a ON a.codebase_id = c.id
+    WHERE c.is_stale = false
+      AND c.is_private = false
+      AND a.status = 'completed'
+    ORDER BY c.id, a.completed_at DESC
+) s
+WHERE s.parser_version <> @candidate_version::text
+ORDER BY random()
+LIMIT @max_rows;
+
+-- name: GetFileTestCounts :many
+SELECT f.file_path, COALESCE(SUM(tc.variant_count), 0)::int AS test_count
+FROM test_files f
+LEFT JOIN test_suites s ON s.file_id = f.id
+LEFT JOIN test_cases tc ON tc.suite_id = s.id
+WHERE f.analysis_id = $1
+GROUP BY f.file_path;
+
+-- name: InsertParserCompatReport :one
+INSERT INTO parser_compat_reports (candidate_version, sample_size, matched, mismatched, skipped, failed, details)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, created_at;
+
+-- name: GetJobCodebase :one
+-- Resolves the codebase a job refers to by analysis, spec document or owner/repo, in that order.
+SELECT c.id, c.region
+FROM codebases c
+WHERE c.id = COALESCE(
+    (SELECT a.codebase_id FROM analyses a WHERE a.id = sqlc.narg(analysis_id)::uuid),
+    (SELECT a.codebase_id FROM spec_documents d JOIN analyses a ON a.id = d.analysis_id WHERE d.id = sqlc.narg(document_id)::uuid),
+    (SELECT cb.id FROM codebases cb
+     WHERE cb.host = 'github.com' AND cb.owner = sqlc.narg(owner)::text AND cb.name = sqlc.narg(repo)::text AND cb.is_stale = false)
+);
+
+-- name: TakeRateLimitToken :one
+-- Token bucket: refills for the time elapsed since the last take (capped at burst)
+-- and takes one token. Returns no row when fewer than one token is available.
+INSERT INTO rate_limit_buckets AS b (bucket_key, tokens, updated_at)
+VALUES (@bucket_key, @burst::float8 - 1, now())
+ON CONFLICT (bucket_key) DO UPDATE
+SET tokens = LEAST(@burst::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * @refill_per_second::float8) - 1,
+    updated_at = now()
+WHERE LEAST(@burst::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * @refill_per_second::float8) >= 1
+RETURNING tokens;
+
+-- name: ReturnRateLimitToken :exec
+-- Puts back a token taken by TakeRateLimitToken. The refill clock is left
+-- alone, so the bucket refills as if the token had not been taken.
+UPDATE rate_limit_buckets
+SET tokens = LEAST(@burst::float8, tokens + 1)
+WHERE bucket_key = @bucket_key;
+
+-- name: HasAnalysisForCommit :one
+-- Failed analyses are excluded so that a new push can retry them.
+SELECT EXISTS(
+    SELECT 1 FROM analyses
+    WHERE codebase_id = @codebase_id
+      AND COALESCE(branch_name, '') = @branch::text
+      AND commit_sha = @commit_sha
+      AND status <> 'failed'
+) AS exists;
+
+-- name: GetPhase2ChildStatus :one
+-- Counts the Phase 2 domain jobs dispatched by a parent spec-view job.
+SELECT
+    count(*) FILTER (WHERE state NOT IN ('completed', 'cancelled', 'discarded'))::int AS active,
+    count(*) FILTER (WHERE state = 'completed')::int AS completed,
+    count(*) FILTER (WHERE state IN ('cancelled', 'discarded'))::int AS failed,
+    COALESCE(sum((args ->> 'uncached_tests')::int) FILTER (WHERE state = 'completed'), 0)::int AS converted_tests
+FROM river_job
+WHERE kind = 'specview:phase2_domain'
+  AND args @> jsonb_build_object('parent_job_id', @parent_job_id::bigint);
+
+-- =============================================================================
+-- TEST DELTAS
+-- =============================================================================
+
+-- name: FindCompletedAnalysisByCommit :one
+-- Latest completed analysis of the commit on any branch.
+SELECT a.id
+FROM analyses a
+JOIN codebases c ON c.id = a.codebase_id
+WHERE c.host = @host AND c.owner = @owner AND c.name = @name AND c.is_stale = false
+  AND a.commit_sha = @commit_sha
+  AND a.status = 'completed'
+ORDER BY a.completed_at DESC
+LIMIT 1;
+
+-- name: GetTestRefsByAnalysisID :many
+-- Every test of the analysis with its file and full suite path ("Outer > Inner").
+WITH RECURSIVE suite_paths AS (
+    SELECT ts.id, ts.file_id, ts.name::text AS path
+    FROM test_suites ts
+    JOIN test_files tf ON tf.id = ts.file_id
+    WHERE tf.analysis_id = @analysis_id AND ts.parent_id IS NULL
+    UNION ALL
+    SELECT ts.id, ts.file_id, (sp.path || ' > ' || ts.name)::text
+    FROM test_suites ts
+    JOIN suite_paths sp ON ts.parent_id = sp.id
+)
+SELECT tf.file_path, sp.path AS suite_path, tc.name AS test_name
+FROM suite_paths sp
+JOIN test_files tf ON tf.id = sp.file_id
+JOIN test_cases tc ON tc.suite_id = sp.id
+ORDER BY tf.file_path, sp.path, tc.name;
+
+-- name: UpsertTestDelta :exec
+INSERT INTO test_deltas (
+    codebase_id, base_analysis_id, head_analysis_id, pull_request_number,
+    tests_added, tests_removed, tests_renamed, suites_added, suites_removed, details
+)
+SELECT a.codebase_id, @base_analysis_id::uuid, a.id, sqlc.narg(pull_request_number)::int,
+    @tests_added::int, @tests_removed::int, @tests_renamed::int, @suites_added::int, @suites_removed::int, @details::jsonb
+FROM analyses a
+WHERE a.id = @head_analysis_id::uuid
+ON CONFLICT ON CONSTRAINT uq_test_deltas_base_head DO UPDATE
+SET pull_request_number = COALESCE(EXCLUDED.pull_request_number, test_deltas.pull_request_number),
+    tests_added = EXCLUDED.tests_added,
+    tests_removed = EXCLUDED.tests_removed,
+    tests_renamed = EXCLUDED.tests_renamed,
+    suites_added = EXCLUDED.suites_added,
+    suites_removed = EXCLUDED.suites_removed,
+    details = EXCLUDED.details,
+    updated_at = now();
+
+-- =============================================================================
+-- SERVICE TOKENS
+-- =============================================================================
+
+-- name: InsertServiceToken :one
+INSERT INTO service_tokens (name, token_hash, token_prefix, scopes, expires_at)
+VALUES (@name, @token_hash, @token_prefix, @scopes::text[], sqlc.narg(expires_at))
+RETURNING *;
+
+-- name: GetServiceTokenByHash :one
+SELECT * FROM service_tokens
+WHERE token_hash = @token_hash;
+
+-- name: ListServiceTokens :many
+SELECT * FROM service_tokens
+ORDER BY created_at DESC;
+
+-- name: TouchServiceToken :exec
+UPDATE service_tokens SET last_used_at = now()
+WHERE id = @id;
+
+-- name: RevokeServiceToken :execrows
+UPDATE service_tokens SET revoked_at = now()
+WHERE id = @id AND revoked_at IS NULL;
+
+-- name: GetLatestAnalysisStatusByCommit :one
+-- Most recent analysis of the commit on any branch, whatever its status.
+SELECT a.id, a.status, a.failure_class, a.error_message, a.created_at, a.completed_at
+FROM analyses a
+JOIN codebases c ON c.id = a.codebase_id
+WHERE c.host = @host AND c.owner = @owner AND c.name = @name AND c.is_stale = false
+  AND a.commit_sha = @commit_sha
+ORDER BY a.created_at DESC
+LIMIT 1;
+
+-- =============================================================================
+-- METRICS
+-- =============================================================================
+
+-- name: CountRiverJobsByQueueState :many
+-- Jobs waiting or running per queue, for the queue depth gauge.
+SELECT queue, state::text AS state, count(*)::bigint AS jobs
+FROM river_job
+WHERE state IN ('available', 'scheduled', 'retryable', 'running')
+GROUP BY queue, state;
+
+-- name: GetQueueIdleState :one
+-- Jobs of the queues that are workable now, and the earliest time a scheduled
+-- job becomes workable. Snoozed jobs are scheduled until their snooze ends.
+SELECT
+    count(*) FILTER (WHERE state IN ('available', 'running'))::bigint AS active,
+    min(scheduled_at) FILTER (WHERE state IN ('scheduled', 'retryable'))::timestamptz AS next_scheduled_at
+FROM river_job
+WHERE queue = ANY(@queues::text[]);
+
+-- =============================================================================
+-- WEBHOOK DELIVERIES
+-- =============================================================================
+
+-- name: ClaimWebhookDelivery :one
+-- Records a delivery. Expired records and unfinished ones abandoned for five
+-- minutes are reclaimed; otherwise no row is returned.
+INSERT INTO webhook_deliveries AS d (delivery_id, event, expires_at)
+VALUES (@delivery_id, @event, @expires_at)
+ON CONFLICT (delivery_id) DO UPDATE
+SET event = EXCLUDED.event,
+    outcome = NULL,
+    received_at = now(),
+    expires_at = EXCLUDED.expires_at
+WHERE d.expires_at <= now()
+   OR (d.outcome IS NULL AND d.received_at < now() - interval '5 minutes')
+RETURNING delivery_id;
+
+-- name: GetWebhookDeliveryOutcome :one
+SELECT outcome FROM webhook_deliveries
+WHERE delivery_id = @delivery_id;
+
+-- name: CompleteWebhookDelivery :exec
+UPDATE webhook_deliveries SET outcome = @outcome
+WHERE delivery_id = @delivery_id;
+
+-- name: ReleaseWebhookDelivery :exec
+-- Forgets an unfinished delivery so that a redelivery is processed again.
+DELETE FROM webhook_deliveries
+WHERE delivery_id = @delivery_id AND outcome IS NULL;
+
+-- =============================================================================
+-- TENANT REPOSITORY POLICIES
+-- =============================================================================
+
+-- name: ListRepoPoliciesByUser :many
+-- Rules of the tenant the user belongs to.
+SELECT p.effect, p.pattern
+FROM tenant_repo_policies p
+JOIN tenant_members m ON m.tenant_id = p.tenant_id
+WHERE m.user_id = @user_id
+ORDER BY p.effect, p.pattern;
+
+-- =============================================================================
+-- REGENERATION CAMPAIGNS
+-- =============================================================================
+
+-- name: InsertRegenCampaign :one
+INSERT INTO regen_campaigns (name, rate_per_minute, max_in_flight, selection)
+VALUES (@name, @rate_per_minute, @max_in_flight, @selection)
+RETURNING id;
+
+-- name: InsertRegenCampaignTargets :execrows
+-- Targets the latest full document per user, analysis and language, so a
+-- document already regenerated since the cutoff is not selected again.
+-- Pinned and deleted documents are never targeted.
+INSERT INTO regen_campaign_targets (campaign_id, document_id, analysis_id, user_id, language, team_slices)
+SELECT @campaign_id, d.id, d.analysis_id, d.user_id, d.language,
+       EXISTS(SELECT 1 FROM spec_documents c WHERE c.parent_document_id = d.id)
+FROM (
+    SELECT DISTINCT ON (sd.user_id, sd.analysis_id, sd.language)
+        sd.id, sd.analysis_id, sd.user_id, sd.language, sd.model_id, sd.created_at
+    FROM spec_documents sd
+    WHERE sd.parent_document_id IS NULL
+      AND sd.project IS NULL
+      AND sd.deleted_at IS NULL
+    ORDER BY sd.user_id, sd.analysis_id, sd.language, sd.version DESC
+) d
+WHERE (@model_id::text = '' OR d.model_id = @model_id::text)
+  AND (@language::text = '' OR d.language = @language::text)
+  AND d.created_at < @created_before
+  AND NOT EXISTS (SELECT 1 FROM spec_document_pins p WHERE p.document_id = d.id)
+ORDER BY d.created_at
+LIMIT @max_rows;
+
+-- name: GetRegenCampaign :one
+SELECT
+    c.id, c.name, c.status, c.rate_per_minute, c.max_in_flight, c.selection, c.created_at, c.updated_at,
+    COUNT(t.id) FILTER (WHERE t.status = 'pending')::int AS pending,
+    COUNT(t.id) FILTER (WHERE t.status = 'enqueued')::int AS enqueued,
+    COUNT(t.id) FILTER (WHERE t.status = 'succeeded')::int AS succeeded,
+    COUNT(t.id) FILTER (WHERE t.status = 'failed')::int AS failed
+FROM regen_campaigns c
+LEFT JOIN regen_campaign_targets t ON t.campaign_id = c.id
+WHERE c.id = @id
+GROUP BY c.id;
+
+-- name: ListRegenCampaigns :many
+SELECT
+    c.id, c.name, c.status, c.rate_per_minute, c.max_in_flight, c.selection, c.created_at, c.updated_at,
+    COUNT(t.id) FILTER (WHERE t.status = 'pending')::int AS pending,
+    COUNT(t.id) FILTER (WHERE t.status = 'enqueued')::int AS enqueued,
+    COUNT(t.id) FILTER (WHERE t.status = 'succeeded')::int AS succeeded,
+    COUNT(t.id) FILTER (WHERE t.status = 'failed')::int AS failed
+FROM regen_campaigns c
+LEFT JOIN regen_campaign_targets t ON t.campaign_id = c.id
+GROUP BY c.id
+ORDER BY c.created_at DESC;
+
+-- name: UpdateRegenCampaignStatus :execrows
+-- Only applies when the status is still @from_status, so concurrent
+-- transitions cannot overwrite each other.
+UPDATE regen_campaigns SET status = @to_status, updated_at = now()
+WHERE id = @id AND status = @from_status;
+
+-- name: ListRegenTargetsByStatus :many
+SELECT id, campaign_id, document_id, analysis_id, user_id, language, team_slices, status, job_id, error
+FROM regen_campaign_targets
+WHERE campaign_id = @campaign_id AND status = @status
+ORDER BY created_at, id
+LIMIT @max_rows;
+
+-- name: MarkRegenTargetEnqueued :exec
+UPDATE regen_campaign_targets
+SET status = 'enqueued', job_id = @job_id, enqueued_at = now()
+WHERE id = @id;
+
+-- name: MarkRegenTargetFailed :exec
+UPDATE regen_campaign_targets
+SET status = 'failed', error = @error, finished_at = now()
+WHERE id = @id;
+
+-- name: SyncRegenTargets :execrows
+-- Settles enqueued targets whose job is finalized. A job pruned from river_job
+-- before it was seen counts as failed.
+UPDATE regen_campaign_targets t
+SET status = CASE WHEN j.state = 'completed' THEN 'succeeded' ELSE 'failed' END,
+    error = CASE
+        WHEN j.id IS NULL THEN 'job no longer exists'
+        WHEN j.state = 'completed' THEN NULL
+        ELSE COALESCE(j.errors[array_upper(j.errors, 1)] ->> 'error', j.state::text)
+    END,
+    finished_at = now()
+FROM regen_campaign_targets s
+LEFT JOIN river_job j ON j.id = s.job_id
+WHERE t.id = s.id
+  AND s.campaign_id = @campaign_id
+  AND s.status = 'enqueued'
+  AND (j.id IS NULL OR j.state IN ('completed', 'cancelled', 'discarded'));
+
+-- =============================================================================
+-- GENERATION CHECKPOINTS
+-- =============================================================================
+
+-- name: GetSpecGenerationCheckpoint :one
+SELECT job_id, analysis_id, content_hash, phase1_output, created_at, updated_at
+FROM spec_generation_checkpoints
+WHERE job_id = $1;
+
+-- name: UpsertSpecGenerationCheckpoint :exec
+-- Rows are removed with their river_job row when River prunes finalized jobs,
+-- with their analysis, or when the generated document is saved.
+INSERT INTO spec_generation_checkpoints (job_id, analysis_id, content_hash, phase1_output)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (job_id) DO UPDATE
+SET analysis_id = EXCLUDED.analysis_id,
+    content_hash = EXCLUDED.content_hash,
+    phase1_output = EXCLUDED.phase1_output,
+    updated_at = now();
+
+-- name: ListSpecGenerationCheckpointFeatures :many
+SELECT job_id, domain_index, feature_index, behaviors, created_at
+FROM spec_generation_checkpoint_features
+WHERE job_id = $1
+ORDER BY created_at, domain_index, feature_index;
+
+-- name: InsertSpecGenerationCheckpointFeature :exec
+INSERT INTO spec_generation_checkpoint_features (job_id, domain_index, feature_index, behaviors)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (job_id, domain_index, feature_index) DO UPDATE
+SET behaviors = EXCLUDED.behaviors;
+
+-- name: DeleteSpecGenerationCheckpointFeatures :exec
+DELETE FROM spec_generation_checkpoint_features WHERE job_id = $1;
+
+-- name: DeleteSpecGenerationCheckpoint :exec
+DELETE FROM spec_generation_checkpoints WHERE job_id = $1;
+
+-- =============================================================================
+-- DECISION LOG
+-- =============================================================================
+
+-- name: AppendSpecDocumentDecision :exec
+-- Written ahead of the document; saving it sets document_id.
+INSERT INTO spec_document_decisions (analysis_id, generation_id, sequence, kind, subject, detail, occurred_at)
+VALUES (@analysis_id, @generation_id, @sequence, @kind, @subject, @detail, @occurred_at)
+ON CONFLICT (generation_id, sequence) DO NOTHING;
+
+-- name: InsertSpecDocumentDecisions :exec
+-- Stores the decisions of a saved document. Those already written ahead are kept.
+INSERT INTO spec_document_decisions (document_id, analysis_id, generation_id, sequence, kind, subject, detail, occurred_at)
+SELECT @document_id::uuid, @analysis_id::uuid, @generation_id::uuid, t.sequence, t.kind, t.subject, t.detail, t.occurred_at
+FROM unnest(@sequences::int[], @kinds::text[], @subjects::text[], @details::jsonb[], @occurred_ats::timestamptz[]) AS t(sequence, kind, subject, detail, occurred_at)
+ON CONFLICT (generation_id, sequence) DO NOTHING;
+
+-- name: LinkSpecDocumentDecisions :exec
+UPDATE spec_document_decisions SET document_id = @document_id
+WHERE generation_id = @generation_id;
+
+-- =============================================================================
+-- GENERATION CLAIMS
+-- =============================================================================
+
+-- name: ClaimSpecGeneration :execrows
+-- Zero rows means another job holds an unexpired claim. Rows are removed with
+-- their river_job row when a holder dies without releasing its claim.
+INSERT INTO spec_generation_claims (claim_key, job_id, expires_at)
+VALUES (@claim_key, @job_id, @expires_at)
+ON CONFLICT (claim_key) DO UPDATE
+SET job_id = EXCLUDED.job_id,
+    expires_at = EXCLUDED.expires_at,
+    created_at = CASE WHEN spec_generation_claims.job_id = EXCLUDED.job_id THEN spec_generation_claims.created_at ELSE now() END
+WHERE spec_generation_claims.job_id = EXCLUDED.job_id
+   OR spec_generation_claims.expires_at < now();
+
+-- name: ExtendSpecGenerationClaim :execrows
+UPDATE spec_generation_claims SET expires_at = @expires_at
+WHERE claim_key = @claim_key AND job_id = @job_id;
+
+-- name: ReleaseSpecGenerationClaim :exec
+DELETE FROM spec_generation_claims WHERE claim_key = @claim_key AND job_id = @job_id;
+
+-- =============================================================================
+-- TENANT AI BUDGETS
+-- =============================================================================
+
+-- name: GetTenantAIBudgetByUser :one
+-- Budget of the tenant the user belongs to, with this month's spend.
+-- Behaviors are the specview quota of all tenant members.
+SELECT b.tenant_id, b.monthly_token_limit, b.monthly_behavior_limit, b.downgrade_model,
+    (SELECT COALESCE(SUM(u.tokens), 0)
+     FROM tenant_ai_usage u
+     WHERE u.tenant_id = b.tenant_id
+       AND u.created_at >= date_trunc('month', CURRENT_DATE))::bigint AS tokens_used,
+    (SELECT COALESCE(SUM(e.quota_amount), 0)
+     FROM usage_events e
+     JOIN tenant_members tm ON tm.user_id = e.user_id
+     WHERE tm.tenant_id = b.tenant_id
+       AND e.event_type = 'specview'
+       AND e.created_at >= date_trunc('month', CURRENT_DATE))::bigint AS behaviors_used
+FROM tenant_ai_budgets b
+JOIN tenant_members m ON m.tenant_id = b.tenant_id
+WHERE m.user_id = $1;
+
+-- name: RecordTenantAIUsage :exec
+INSERT INTO tenant_ai_usage (tenant_id, job_id, tokens)
+VALUES ($1, $2, $3);
+
+-- name: AttributeTenantAIUsage :exec
+-- Jobs charge tokens as each phase spends them, before the document exists;
+-- saving the document links the job's charges to it.
+UPDATE tenant_ai_usage
+SET document_id = @document_id
+WHERE job_id = @job_id
+  AND document_id IS NULL;
+
+-- =============================================================================
+-- AI OUTPUT QUARANTINE
+-- =============================================================================
+
+-- name: InsertAIOutputQuarantine :exec
+INSERT INTO ai_output_quarantine (phase, schema_version, model_id, response, error)
+VALUES ($1, $2, $3, $4, $5);
+
+-- =============================================================================
+-- GLOSSARY
+-- =============================================================================
+
+-- name: GetGlossaryEntries :many
+-- Entries of the user and of the analysis's codebase for the language; is_codebase marks the codebase scope.
+SELECT
+    (g.codebase_id IS NOT NULL)::boolean AS is_codebase,
+    g.term,
+    g.preferred
+FROM glossary_entries g
+WHERE g.language = @language
+  AND (
+    g.user_id = @user_id
+    OR g.codebase_id = (SELECT codebase_id FROM analyses WHERE id = @analysis_id)
+  )
+ORDER BY g.term;
+
+-- =============================================================================
+-- CODEBASE TAXONOMIES
+-- =============================================================================
+
+-- name: GetCodebaseTaxonomy :one
+-- Canonical domains of the analysis's codebase; no rows when it has none.
+SELECT t.domains, t.mode
+FROM codebase_taxonomies t
+WHERE t.codebase_id = (SELECT codebase_id FROM analyses WHERE id = @analysis_id);
+
+-- =============================================================================
+-- USAGE WARNINGS
+-- =============================================================================
+
+-- name: InsertUsageWarning :exec
+-- Records a quota warning once per user, month and threshold, and publishes
+-- new ones on the usage_warning channel.
+WITH inserted AS (
+    INSERT INTO usage_warnings (user_id, period_start, threshold, document_id, quota_used, quota_limit)
+    VALUES ($1, date_trunc('month', CURRENT_DATE)::date, $2, $3, $4, $5)
+    ON CONFLICT (user_id, period_start, threshold) DO NOTHING
+    RETURNING user_id, threshold, document_id, quota_used, quota_limit, created_at
+)
+SELECT pg_notify('usage_warning', row_to_json(inserted)::text) FROM inserted;
+
+-- =============================================================================
+-- SPEC DOCUMENT EXPORTS
+-- =============================================================================
+
+-- name: GetSpecDocumentByID :one
+-- Deleted documents and the team slices of deleted documents are not found.
+SELECT sd.* FROM spec_documents sd
+LEFT JOIN spec_documents parent ON parent.id = sd.parent_document_id
+WHERE sd.id = $1
+  AND sd.deleted_at IS NULL
+  AND parent.deleted_at IS NULL;
+
+-- name: UpsertSpecDocumentExport :exec
+INSERT INTO spec_document_exports (document_id, format, content_type, file_name, content)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (document_id, format) DO UPDATE SET
+    content_type = EXCLUDED.content_type,
+    file_name = EXCLUDED.file_name,
+    content = EXCLUDED.content,
+    updated_at = now();
+
+-- =============================================================================
+-- TEST RESULTS
+-- =============================================================================
+
+-- name: GetTestResultUpload :one
+SELECT id, analysis_id, format, content FROM test_result_uploads WHERE id = $1;
+
+-- name: MarkTestResultUploadProcessed :execrows
+-- Claims an upload for ingestion; zero rows means it was already processed.
+UPDATE test_result_uploads SET processed_at = now()
+WHERE id = $1 AND processed_at IS NULL;
+
+-- =============================================================================
+-- CODE COVERAGE
+-- =============================================================================
+
+-- name: GetCoverageUpload :one
+SELECT id, analysis_id, format, content FROM coverage_uploads WHERE id = $1;
+
+-- name: MarkCoverageUploadProcessed :execrows
+-- Claims an upload for ingestion; zero rows means it was already processed.
+UPDATE coverage_uploads SET processed_at = now()
+WHERE id = $1 AND processed_at IS NULL;
+
+-- name: GetTestFilePathsByAnalysisID :many
+SELECT id, file_path FROM test_files WHERE analysis_id = $1 ORDER BY file_path;
+
+-- name: AnnotateBehaviorCoverage :execrows
+-- Sets the coverage signal of the behaviors in the analysis' documents from the
+-- source files linked to the test files of their test cases. A source file
+-- linked through several test files counts once.
+INSERT INTO spec_behavior_coverage (behavior_id, lines_valid, lines_covered)
+SELECT s.behavior_id, SUM(s.lines_valid)::int, SUM(s.lines_covered)::int
+FROM (
+    SELECT DISTINCT ON (sb.id, cov.source_path) sb.id AS behavior_id, cov.lines_valid, cov.lines_covered
+    FROM spec_documents sd
+    JOIN spec_domains dom ON dom.document_id = sd.id
+    JOIN spec_features sf ON sf.domain_id = dom.id
+    JOIN spec_behaviors sb ON sb.feature_id = sf.id
+    JOIN test_cases tc ON tc.id = sb.source_test_case_id
+        OR tc.id IN (SELECT bt.test_case_id FROM spec_behavior_test_cases bt WHERE bt.behavior_id = sb.id)
+    JOIN test_suites ts ON ts.id = tc.suite_id
+    JOIN test_file_coverage cov ON cov.test_file_id = ts.file_id
+    WHERE sd.analysis_id = $1
+    ORDER BY sb.id, cov.source_path, cov.updated_at DESC
+) s
+GROUP BY s.behavior_id
+ON CONFLICT (behavior_id) DO UPDATE SET
+    lines_valid = EXCLUDED.lines_valid,
+    lines_covered = EXCLUDED.lines_covered,
+    updated_at = now();
+
+-- =============================================================================
+-- TENANT DOCUMENT KEYS
+-- =============================================================================
+
+-- name: GetUserTenantID :one
+SELECT tenant_id FROM tenant_members WHERE user_id = $1;
+
+-- name: GetUserDocumentEncryption :one
+-- Whether the tenant the user belongs to encrypts its documents.
+SELECT t.id AS tenant_id, t.document_encryption
+FROM tenants t
+JOIN tenant_members m ON m.tenant_id = t.id
+WHERE m.user_id = $1;
+
+-- name: GetTenantDocumentEncryption :one
+SELECT document_encryption FROM tenants WHERE id = $1;
+
+-- name: SetTenantDocumentEncryption :execrows
+UPDATE tenants SET document_encryption = @enabled WHERE id = @tenant_id;
+
+-- name: GetActiveTenantDocumentKey :one
+SELECT id, wrapped_key FROM tenant_document_keys
+WHERE tenant_id = $1 AND retired_at IS NULL;
+
+-- name: InsertTenantDocumentKey :one
+-- Zero rows means another transaction created the active key first.
+INSERT INTO tenant_document_keys (tenant_id, wrapped_key)
+VALUES (@tenant_id, @wrapped_key)
+ON CONFLICT (tenant_id) WHERE retired_at IS NULL DO NOTHING
+RETURNING id;
+
+-- name: GetTenantDocumentKey :one
+SELECT wrapped_key FROM tenant_document_keys WHERE id = $1;
+
+-- name: RetireTenantDocumentKey :execrows
+UPDATE tenant_document_keys SET retired_at = now()
+WHERE tenant_id = $1 AND retired_at IS NULL;
+
+-- name: ListTenantDocumentKeys :many
+SELECT id, wrapped_key FROM tenant_document_keys ORDER BY id;
+
+-- name: UpdateTenantDocumentKeyWrap :exec
+UPDATE tenant_document_keys SET wrapped_key = @wrapped_key WHERE id = @id;
+
+-- name: ListTenantDocumentsToReseal :many
+-- Documents of the tenant's members not sealed with @key_id, plaintext ones included.
+SELECT sd.id
+FROM spec_documents sd
+JOIN tenant_members m ON m.user_id = sd.user_id
+WHERE m.tenant_id = @tenant_id
+  AND sd.encryption_key_id IS DISTINCT FROM @key_id::uuid
+ORDER BY sd.id
+LIMIT @batch_size;
+
+-- name: LockSpecDocumentText :one
+SELECT executive_summary, encryption_key_id FROM spec_documents
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetSpecDocumentBehaviorTexts :many
+-- Behavior and merged test descriptions of the document; is_merge marks spec_behavior_merges rows.
+SELECT sb.id, sb.converted_description, false AS is_merge
+FROM spec_domains dom
+JOIN spec_features sf ON sf.domain_id = dom.id
+JOIN spec_behaviors sb ON sb.feature_id = sf.id
+WHERE dom.document_id = $1
+UNION ALL
+SELECT mg.id, mg.converted_description, true AS is_merge
+FROM spec_domains dom
+JOIN spec_features sf ON sf.domain_id = dom.id
+JOIN spec_behaviors sb ON sb.feature_id = sf.id
+JOIN spec_behavior_merges mg ON mg.behavior_id = sb.id
+WHERE dom.document_id = $1;
+
+-- name: UpdateSpecDocumentText :exec
+UPDATE spec_documents
+SET executive_summary = @executive_summary, encryption_key_id = @encryption_key_id
+WHERE id = @id;
+
+-- =============================================================================
+-- TEST FILE REUSE
+-- =============================================================================
+
+-- name: GetReusableFileHashes :many
+-- Content hashes of the test files of the codebase's latest completed analysis
+-- made with the given parser version. Files parsed by another version are never reused.
+SELECT tf.analysis_id, tf.file_path, tf.content_hash
+FROM test_files tf
+WHERE tf.analysis_id = (
+    SELECT a.id FROM analyses a
+    WHERE a.codebase_id = @codebase_id
+      AND a.status = 'completed'
+      AND a.parser_version = @parser_version
+    ORDER BY a.completed_at DESC
+    LIMIT 1
+)
+  AND tf.content_hash IS NOT NULL;
+
+-- name: GetForkReusableFileHashes :many
+-- Content hashes of the test files of the latest completed analysis of another
+-- codebase in the same region at the same commit, typically the repository the
+-- codebase was forked from, made with the given parser version. Codebases that
+-- opted out of fork deduplication neither reuse nor lend files.
+SELECT tf.analysis_id, tf.file_path, tf.content_hash
+FROM test_files tf
+WHERE tf.analysis_id = (
+    SELECT a.id FROM analyses a
+    JOIN codebases donor ON donor.id = a.codebase_id
+    JOIN codebases self ON self.id = @codebase_id
+    WHERE a.commit_sha = @commit_sha
+      AND a.parser_version = @parser_version
+      AND a.status = 'completed'
+      AND a.codebase_id <> self.id
+      AND donor.fork_dedup_enabled
+      AND self.fork_dedup_enabled
+      AND donor.region IS NOT DISTINCT FROM self.region
+    ORDER BY a.completed_at DESC
+    LIMIT 1
+)
+  AND tf.content_hash IS NOT NULL;
+
+-- name: GetTestFilesByPaths :many
+SELECT id, file_path, framework, domain_hints, project
+FROM test_files
+WHERE analysis_id = @analysis_id AND file_path = ANY(@paths::text[]);
+
+-- name: GetTestSuitesByFileIDs :many
+SELECT id, file_id, parent_id, name, line_number
+FROM test_suites
+WHERE file_id = ANY(@file_ids::uuid[])
+ORDER BY depth, line_number, name;
+
+-- name: GetTestCasesByFileIDs :many
+SELECT tc.suite_id, tc.name, tc.line_number, tc.status, tc.variant_count
+FROM test_cases tc
+JOIN test_suites s ON s.id = tc.suite_id
+WHERE s.file_id = ANY(@file_ids::uuid[])
+ORDER BY tc.line_number, tc.name;
+
+-- =============================================================================
+-- TENANT TAKEOUTS
+-- =============================================================================
+
+-- name: GetTenantTakeout :one
+SELECT t.id, t.tenant_id, t.status, tn.name AS tenant_name
+FROM tenant_takeouts t
+JOIN tenants tn ON tn.id = t.tenant_id
+WHERE t.id = $1;
+
+-- name: StartTenantTakeout :execrows
+-- Zero rows means the takeout is already completed.
+UPDATE tenant_takeouts SET status = 'running', error_message = NULL
+WHERE id = $1 AND status <> 'completed';
+
+-- name: CompleteTenantTakeout :exec
+UPDATE tenant_takeouts
+SET status = 'completed',
+    object_key = @object_key,
+    size_bytes = @size_bytes,
+    download_url = @download_url,
+    download_expires_at = @download_expires_at,
+    error_message = NULL,
+    completed_at = now()
+WHERE id = @id;
+
+-- name: FailTenantTakeout :exec
+UPDATE tenant_takeouts SET status = 'failed', error_message = @error_message
+WHERE id = @id AND status <> 'completed';
+
+-- name: ListTenantMembers :many
+SELECT user_id, created_at FROM tenant_members
+WHERE tenant_id = $1
+ORDER BY created_at, user_id;
+
+-- name: ListTenantCodebases :many
+-- Codebases any member of the tenant has analyzed.
+SELECT c.id, c.host, c.owner, c.name, c.default_branch, c.is_private, c.created_at
+FROM codebases c
+WHERE EXISTS (
+    SELECT 1 FROM analyses a
+    JOIN user_analysis_history h ON h.analysis_id = a.id
+    JOIN tenant_members m ON m.user_id = h.user_id
+    WHERE a.codebase_id = c.id AND m.tenant_id = $1
+)
+ORDER BY c.host, c.owner, c.name;
+
+-- name: ListTenantAnalyses :many
+-- Analyses in the history of any member of the tenant.
+SELECT a.id, a.codebase_id, a.commit_sha, a.branch_name, a.status, a.parser_version,
+    a.total_suites, a.total_tests, a.created_at, a.completed_at
+FROM analyses a
+WHERE EXISTS (
+    SELECT 1 FROM user_analysis_history h
+    JOIN tenant_members m ON m.user_id = h.user_id
+    WHERE h.analysis_id = a.id AND m.tenant_id = $1
+)
+ORDER BY a.created_at, a.id;
+
+-- name: ListTenantDocumentIDs :many
+SELECT sd.id
+FROM spec_documents sd
+JOIN tenant_members m ON m.user_id = sd.user_id
+WHERE m.tenant_id = $1
+ORDER BY sd.created_at, sd.id;
+
+-- name: ListTenantUsageEvents :many
+SELECT e.id, e.user_id, e.event_type, e.analysis_id, e.document_id, e.quota_amount, e.created_at
+FROM usage_events e
+JOIN tenant_members m ON m.user_id = e.user_id
+WHERE m.tenant_id = $1
+ORDER BY e.created_at, e.id;
+
+-- name: ListTenantAIUsage :many
+SELECT id, document_id, tokens, created_at
+FROM tenant_ai_usage
+WHERE tenant_id = $1
+ORDER BY created_at, id;
+
+-- ==============================================================================
+-- PARSE ERRORS
+-- ==============================================================================
+
+-- name: InsertParseError :exec
+INSERT INTO parse_errors (analysis_id, file_path, message, panicked)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (analysis_id, file_path) DO NOTHING;
+
+-- name: UpdateAnalysisFingerprint :exec
+UPDATE analyses
+SET test_fingerprint = $2, deduplicated_from = $3
+WHERE id = $1;
+
+-- name: UpdateAnalysisQuarantinedFiles :exec
+UPDATE analyses
+SET quarantined_files = (SELECT COUNT(*) FROM parse_errors WHERE analysis_id = $1)
+WHERE id = $1;
+
+-- name: ListParseErrors :many
+SELECT file_path, message, panicked
+FROM parse_errors
+WHERE analysis_id = $1
+ORDER BY file_path;
+
+-- name: IsSpecGenerationCancelled :one
+-- The Web app upserts a row when a user cancels their generation; requests of
+-- other users, or older than the job, left over from an earlier run, do not count.
+SELECT EXISTS(
+    SELECT 1 FROM spec_generation_cancellations
+    WHERE analysis_id = @analysis_id
+      AND language = @language
+      AND requested_by = @requested_by
+      AND requested_at >= @since
+) AS cancelled;
+
+-- ==============================================================================
+-- ENQUEUE IDEMPOTENCY KEYS
+-- ==============================================================================
+
+-- name: ClaimIdempotencyKey :one
+-- Records a new key. Expired keys are reclaimed; otherwise no row is returned.
+-- A concurrent claim of the same key waits for the first transaction to settle.
+INSERT INTO enqueue_idempotency_keys AS k (idempotency_key, job_kind, request_hash, expires_at)
+VALUES (@idempotency_key, @job_kind, @request_hash, @expires_at)
+ON CONFLICT (idempotency_key) DO UPDATE
+SET job_kind = EXCLUDED.job_kind,
+    request_hash = EXCLUDED.request_hash,
+    job_id = NULL,
+    created_at = now(),
+    expires_at = EXCLUDED.expires_at
+WHERE k.expires_at <= now()
+RETURNING idempotency_key;
+
+-- name: SetIdempotencyKeyJob :exec
+UPDATE enqueue_idempotency_keys SET job_id = @job_id
+WHERE idempotency_key = @idempotency_key;
+
+-- name: GetIdempotencyKey :one
+-- The analysis comes from the job's progress events, so it is known once the
+-- job has started.
+SELECT
+    k.job_kind,
+    k.request_hash,
+    k.job_id,
+    j.state AS job_state,
+    (
+        SELECT e.analysis_id FROM analysis_events e
+        WHERE e.job_id = k.job_id AND e.analysis_id IS NOT NULL
+        ORDER BY e.created_at DESC
+        LIMIT 1
+    )::uuid AS analysis_id
+FROM enqueue_idempotency_keys k
+LEFT JOIN river_job j ON j.id = k.job_id
+WHERE k.idempotency_key = @idempotency_key AND k.expires_at > now();
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+-- Deletes idempotency keys past their deduplication window.
+DELETE FROM enqueue_idempotency_keys
+WHERE idempotency_key IN (
+    SELECT idempotency_key FROM enqueue_idempotency_keys
+    WHERE expires_at < now()
     LIMIT $1
 );
+
+-- name: DeleteExpiredAnalysisEvents :execrows
+-- Deletes analysis progress events older than a week. Idempotency key lookups
+-- resolve analysis IDs through them for 72 hours.
+DELETE FROM analysis_events
+WHERE id IN (
+    SELECT id FROM analysis_events
+    WHERE created_at < now() - interval '7 days'
+    LIMIT $1
+);
+
+-- name: DeleteIdleRateLimitBuckets :execrows
+-- Deletes rate limit buckets untouched for a day. They have refilled by then,
+-- and a missing bucket starts full.
+DELETE FROM rate_limit_buckets
+WHERE bucket_key IN (
+    SELECT bucket_key FROM rate_limit_buckets
+    WHERE updated_at < now() - interval '1 day'
+    LIMIT $1
+);
+
+-- ==============================================================================
+-- POISON JOBS
+-- ==============================================================================
+
+-- name: InsertPoisonJob :exec
+-- The job is cancelled right after, so a job is quarantined once; a retried
+-- cancellation keeps the first record.
+INSERT INTO poison_jobs (job_id, kind, queue, attempt, encoded_args, error)
+VALUES (@job_id, @kind, @queue, @attempt, @encoded_args, @error)
+ON CONFLICT (job_id) DO NOTHING;
+
+-- ==============================================================================
+-- JOB RESULTS
+-- ==============================================================================
+
+-- name: UpsertJobResult :exec
+-- Each attempt replaces the result of the previous one.
+INSERT INTO job_results (job_id, kind, queue, status, attempt, analysis_id, document_id, error_code, stats, user_id, enqueued_at, started_at)
+VALUES (@job_id, @kind, @queue, @status, @attempt, @analysis_id, @document_id, @error_code, @stats, @user_id, @enqueued_at, @started_at)
+ON CONFLICT (job_id) DO UPDATE SET
+    status = EXCLUDED.status,
+    attempt = EXCLUDED.attempt,
+    analysis_id = EXCLUDED.analysis_id,
+    document_id = EXCLUDED.document_id,
+    error_code = EXCLUDED.error_code,
+    stats = EXCLUDED.stats,
+    user_id = EXCLUDED.user_id,
+    enqueued_at = EXCLUDED.enqueued_at,
+    started_at = EXCLUDED.started_at,
+    completed_at = now();
+
+-- name: ListJobHistory :many
+-- Jobs enqueued in [@since, @until) with the tier of their user's active
+-- subscription today and the codebase of their analysis, oldest first. tier
+-- is NULL for system jobs and users without an active subscription.
+SELECT
+    jr.job_id,
+    jr.kind,
+    jr.queue,
+    jr.user_id,
+    jr.enqueued_at,
+    jr.started_at,
+    jr.completed_at,
+    a.codebase_id,
+    sp.tier
+FROM job_results jr
+LEFT JOIN analyses a ON a.id = jr.analysis_id
+LEFT JOIN user_subscriptions us ON us.user_id = jr.user_id AND us.status = 'active'
+LEFT JOIN subscription_plans sp ON sp.id = us.plan_id
+WHERE jr.enqueued_at >= @since
+  AND jr.enqueued_at < @until
+  AND jr.started_at IS NOT NULL
+ORDER BY jr.enqueued_at, jr.job_id;
+
+-- ==============================================================================
+-- SLA VIOLATIONS
+-- ==============================================================================
+
+-- name: InsertSLAViolation :exec
+-- phases holds the milliseconds spent in each phase of the job.
+INSERT INTO sla_violations (job_id, kind, queue, attempt, outcome, target_ms, elapsed_ms, slowest_phase, phases, analysis_id, document_id)
+VALUES (@job_id, @kind, @queue, @attempt, @outcome, @target_ms, @elapsed_ms, @slowest_phase, @phases, @analysis_id, @document_id);
+
+-- ==============================================================================
+-- SPEC DOCUMENT PINS
+-- ==============================================================================
+
+-- name: PinSpecDocument :one
+-- Only full, undeleted documents are pinned. Pinning a pinned document keeps
+-- its pin and returns it, so the caller can tell a repeat from a conflicting
+-- tag.
+INSERT INTO spec_document_pins (document_id, release_tag, pinned_by)
+SELECT sd.id, @release_tag, @pinned_by
+FROM spec_documents sd
+WHERE sd.id = @document_id
+  AND sd.parent_document_id IS NULL
+  AND sd.deleted_at IS NULL
+ON CONFLICT (document_id) DO UPDATE SET release_tag = spec_document_pins.release_tag
+RETURNING *;
+
+-- name: UnpinSpecDocument :execrows
+DELETE FROM spec_document_pins WHERE document_id = $1;
+
+-- name: GetSpecDocumentPin :one
+SELECT * FROM spec_document_pins WHERE document_id = $1;
+
+-- ==============================================================================
+-- SPEC DOCUMENT LIFECYCLE
+-- ==============================================================================
+
+-- name: SoftDeleteSpecDocument :one
+-- Only full documents are deleted; their team slices follow them. Deleting a
+-- deleted document keeps its first deletion.
+UPDATE spec_documents
+SET deleted_at = COALESCE(deleted_at, now()),
+    deleted_by = CASE WHEN deleted_at IS NULL THEN @deleted_by ELSE deleted_by END
+WHERE id = @document_id
+  AND parent_document_id IS NULL
+RETURNING id, deleted_at, deleted_by;
+
+-- name: RestoreSpecDocument :execrows
+UPDATE spec_documents
+SET deleted_at = NULL,
+    deleted_by = NULL
+WHERE id = $1
+  AND parent_document_id IS NULL
+  AND deleted_at IS NOT NULL;
+
+-- ==============================================================================
+-- AI USAGE
+-- ==============================================================================
+
+-- name: InsertAIUsage :exec
+INSERT INTO ai_usage (job_id, analysis_id, user_id, document_id, phase, model, fallback_from, prompt_tokens, candidates_tokens, total_tokens)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: AttributeAIUsage :execrows
+-- Phase 2 fan-out children record their usage under the parent's job ID
+-- without a user or document, which the parent fills in once it has saved
+-- the document.
+UPDATE ai_usage
+SET user_id = @user_id, document_id = @document_id
+WHERE job_id = @job_id
+  AND user_id IS NULL
+  AND document_id IS NULL;
+
+-- name: GetDailyAIUsage :many
+SELECT
+    date_trunc('day', created_at, 'UTC')::timestamptz AS day,
+    model,
+    phase,
+    COUNT(DISTINCT job_id)::bigint AS jobs,
+    COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+    COALESCE(SUM(candidates_tokens), 0)::bigint AS candidates_tokens,
+    COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens
+FROM ai_usage
+WHERE created_at >= @since
+  AND created_at < @until
+GROUP BY 1, model, phase
+ORDER BY 1, model, phase;
+
+-- name: GetAIUsageByUser :many
+SELECT
+    user_id,
+    COUNT(DISTINCT job_id)::bigint AS jobs,
+    COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+    COALESCE(SUM(candidates_tokens), 0)::bigint AS candidates_tokens,
+    COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens
+FROM ai_usage
+WHERE user_id IS NOT NULL
+  AND created_at >= @since
+  AND created_at < @until
+GROUP BY user_id
+ORDER BY total_tokens DESC, user_id
+LIMIT @row_limit;
+
+-- =============================================================================
+-- SHARED TEST HELPERS
+-- =============================================================================
+
+-- name: GetSharedHelperTestCases :many
+-- One row per test case of a test file importing the helper, most referenced helpers first.
+SELECT h.helper_path, cardinality(h.test_files)::int AS test_file_count, tc.id AS test_case_id
+FROM analysis_shared_helpers h
+JOIN test_files tf ON tf.analysis_id = h.analysis_id AND tf.file_path = ANY(h.test_files)
+JOIN test_suites ts ON ts.file_id = tf.id
+JOIN test_cases tc ON tc.suite_id = ts.id
+WHERE h.analysis_id = $1
+ORDER BY cardinality(h.test_files) DESC, h.helper_path;
+
+-- =============================================================================
+-- OAUTH TOKEN KEYS
+-- =============================================================================
+
+-- name: ListOAuthAccessTokens :many
+-- Pages by id, so logins during a re-encryption neither skip nor repeat rows.
+SELECT id, access_token::text AS access_token
+FROM oauth_accounts
+WHERE access_token IS NOT NULL AND access_token <> '' AND id > @after_id
+ORDER BY id
+LIMIT @batch_size;
+
+-- name: ReplaceOAuthAccessToken :execrows
+-- Zero rows means the token changed since it was read, e.g. by a new login.
+UPDATE oauth_accounts
+SET access_token = @new_token::text
+WHERE id = @id AND access_token = @old_token::text;
+
+-- =============================================================================
+-- ANALYSIS INVENTORY STAGING
+-- =============================================================================
+
+-- name: PromoteStagedTestFiles :exec
+INSERT INTO test_files (id, analysis_id, file_path, framework, domain_hints, project, content_hash)
+SELECT id, analysis_id, file_path, framework, domain_hints, project, content_hash
+FROM analysis_staging_test_files
+WHERE analysis_id = @analysis_id;
+
+-- name: PromoteStagedTestSuites :exec
+-- A single statement, so parents staged after their children still satisfy the parent key.
+INSERT INTO test_suites (id, parent_id, name, line_number, depth, file_id)
+SELECT id, parent_id, name, line_number, depth, file_id
+FROM analysis_staging_test_suites
+WHERE analysis_id = @analysis_id;
+
+-- name: PromoteStagedTestCases :exec
+INSERT INTO test_cases (suite_id, name, line_number, status, tags, modifier, variant_count)
+SELECT suite_id, name, line_number, status, tags, modifier, variant_count
+FROM analysis_staging_test_cases
+WHERE analysis_id = @analysis_id;
+
+-- name: DeleteStagedInventory :exec
+WITH deleted_files AS (
+    DELETE FROM analysis_staging_test_files WHERE analysis_id = @analysis_id
+), deleted_suites AS (
+    DELETE FROM analysis_staging_test_suites WHERE analysis_id = @analysis_id
+)
+DELETE FROM analysis_staging_test_cases WHERE analysis_id = @analysis_id;
+
+-- =============================================================================
+-- JOB ADMINISTRATION
+-- =============================================================================
+
+-- name: ListRiverJobs :many
+-- 'snoozed' selects scheduled jobs whose metadata counts River snoozes.
+SELECT
+    id,
+    kind,
+    queue,
+    state::text AS state,
+    attempt,
+    max_attempts,
+    priority,
+    created_at,
+    scheduled_at,
+    attempted_at,
+    finalized_at,
+    errors,
+    COALESCE((metadata ->> 'snoozes')::int, 0)::int AS snoozes
+FROM river_job
+WHERE (state::text = ANY(@states::text[])
+       OR ('snoozed' = ANY(@states::text[]) AND state = 'scheduled' AND metadata ->> 'snoozes' IS NOT NULL))
+  AND (cardinality(@kinds::text[]) = 0 OR kind = ANY(@kinds::text[]))
+  AND (cardinality(@queues::text[]) = 0 OR queue = ANY(@queues::text[]))
+ORDER BY id DESC
+LIMIT @max_rows;
+
+-- name: GetRiverJob :one
+SELECT * FROM river_job WHERE id = @id;
+
+-- name: UpdatePendingRiverJobPriority :execrows
+-- Only jobs not yet fetched; River reads the priority when it fetches a job.
+UPDATE river_job
+SET priority = @priority
+WHERE id = @id
+  AND state IN ('available', 'pending', 'retryable', 'scheduled');
+
+-- =============================================================================
+-- OUTBOX
+-- =============================================================================
+
+-- name: InsertAnalysisCompletedOutboxEvent :exec
+INSERT INTO outbox_events (event_type, aggregate_id, payload)
+SELECT @event_type::text, a.id, jsonb_build_object(
+    'analysis_id', a.id,
+    'codebase_id', c.id,
+    'host', c.host,
+    'owner', c.owner,
+    'repo', c.name,
+    'branch', a.branch_name,
+    'commit_sha', a.commit_sha,
+    'total_suites', a.total_suites,
+    'total_tests', a.total_tests,
+    'completed_at', a.completed_at
+)
+FROM analyses a
+JOIN codebases c ON c.id = a.codebase_id
+WHERE a.id = @analysis_id;
+
+-- name: InsertSpecDocumentOutboxEvent :exec
+INSERT INTO outbox_events (event_type, aggregate_id, payload)
+SELECT @event_type::text, d.id, jsonb_build_object(
+    'document_id', d.id,
+    'analysis_id', a.id,
+    'user_id', d.user_id,
+    'language', d.language,
+    'version', d.version,
+    'project', d.project,
+    'model_id', d.model_id,
+    'host', c.host,
+    'owner', c.owner,
+    'repo', c.name,
+    'commit_sha', a.commit_sha,
+    'created_at', d.created_at
+)
+FROM spec_documents d
+JOIN analyses a ON a.id = d.analysis_id
+JOIN codebases c ON c.id = a.codebase_id
+WHERE d.id = @document_id;
+
+-- name: ClaimOutboxEvents :many
+-- Leases due events to one relay: until lease_until passes no other relay
+-- claims them, and a relay that dies mid-batch leaves them to the next.
+WITH due AS (
+    SELECT id FROM outbox_events
+    WHERE published_at IS NULL
+      AND next_attempt_at <= now()
+      AND attempts < @max_attempts
+    ORDER BY created_at
+    LIMIT @max_events
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE outbox_events e
+SET attempts = e.attempts + 1,
+    next_attempt_at = @lease_until
+FROM due
+WHERE e.id = due.id
+RETURNING e.id, e.event_type, e.aggregate_id, e.payload, e.attempts, e.created_at;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+SET published_at = now(),
+    last_error = NULL
+WHERE id = @id;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events
+SET next_attempt_at = @next_attempt_at,
+    last_error = @last_error
+WHERE id = @id;
+
+-- name: DeleteSettledOutboxEvents :execrows
+-- Events published before the cutoff, and events created before it that ran
+-- out of attempts.
+DELETE FROM outbox_events
+WHERE id IN (
+    SELECT id FROM outbox_events
+    WHERE published_at < @before
+       OR (published_at IS NULL AND attempts >= @max_attempts AND created_at < @before)
+    LIMIT @max_rows
+);
+
+-- =============================================================================
+-- NOTIFICATIONS
+-- =============================================================================
+
+-- name: InsertNotificationChannel :one
+INSERT INTO notification_channels (codebase_id, user_id, kind, url, on_success, on_failure)
+VALUES (@codebase_id, @user_id, @kind, @url, @on_success, @on_failure)
+RETURNING *;
+
+-- name: GetNotificationChannel :one
+SELECT * FROM notification_channels WHERE id = @id;
+
+-- name: ListNotificationChannels :many
+SELECT * FROM notification_channels
+WHERE (sqlc.narg(codebase_id)::uuid IS NULL OR codebase_id = sqlc.narg(codebase_id))
+  AND (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id))
+ORDER BY created_at, id;
+
+-- name: DeleteNotificationChannel :execrows
+DELETE FROM notification_channels WHERE id = @id;
+
+-- name: ListNotificationChannelsForOutcome :many
+-- Channels of the codebase and of the user that want outcomes of this kind.
+SELECT * FROM notification_channels
+WHERE (codebase_id = sqlc.narg(codebase_id) OR user_id = sqlc.narg(user_id))
+  AND CASE WHEN @failed::boolean THEN on_failure ELSE on_success END
+ORDER BY created_at, id;
+
+-- name: GetAnalysisNotificationContext :one
+-- The latest analysis of the commit, if any; a job failing before it
+-- created one still resolves its codebase.
+SELECT c.id AS codebase_id, c.host, c.owner, c.name, a.id AS analysis_id, a.branch_name, a.total_tests
+FROM codebases c
+LEFT JOIN LATERAL (
+    SELECT id, branch_name, total_tests FROM analyses
+    WHERE codebase_id = c.id AND commit_sha = @commit_sha
+    ORDER BY created_at DESC
+    LIMIT 1
+) a ON true
+WHERE c.host = @host AND c.owner = @owner AND c.name = @name AND c.is_stale = false;
+
+-- name: GetSpecDocumentNotificationContext :one
+SELECT c.id AS codebase_id, c.host, c.owner, c.name, a.commit_sha, a.branch_name,
+    (SELECT count(*) FROM spec_domains WHERE document_id = sqlc.narg(document_id)::uuid) AS domain_count
+FROM analyses a
+JOIN codebases c ON c.id = a.codebase_id
+WHERE a.id = @analysis_id;
@@ -11,6 +11,117 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const annotateBehaviorCoverage = `-- name: AnnotateBehaviorCoverage :execrows
+INSERT INTO spec_behavior_coverage (behavior_id, lines_valid, lines_covered)
+SELECT s.behavior_id, SUM(s.lines_valid)::int, SUM(s.lines_covered)::int
+FROM (
+    SELECT DISTINCT ON (sb.id, cov.source_path) sb.id AS behavior_id, cov.lines_valid, cov.lines_covered
+    FROM spec_documents sd
+    JOIN spec_domains dom ON dom.document_id = sd.id
+    JOIN spec_features sf ON sf.domain_id = dom.id
+    JOIN spec_behaviors sb ON sb.feature_id = sf.id
+    JOIN test_cases tc ON tc.id = sb.source_test_case_id
+        OR tc.id IN (SELECT bt.test_case_id FROM spec_behavior_test_cases bt WHERE bt.behavior_id = sb.id)
+    JOIN test_suites ts ON ts.id = tc.suite_id
+    JOIN test_file_coverage cov ON cov.test_file_id = ts.file_id
+    WHERE sd.analysis_id = $1
+    ORDER BY sb.id, cov.source_path, cov.updated_at DESC
+) s
+GROUP BY s.behavior_id
+ON CONFLICT (behavior_id) DO UPDATE SET
+    lines_valid = EXCLUDED.lines_valid,
+    lines_covered = EXCLUDED.lines_covered,
+    updated_at = now()
+`
+
+// Sets the coverage signal of the behaviors in the analysis' documents from the
+// source files linked to the test files of their test cases. A source file
+// linked through several test files counts once.
+func (q *Queries) AnnotateBehaviorCoverage(ctx context.Context, analysisID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, annotateBehaviorCoverage, analysisID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const appendSpecDocumentDecision = `-- name: AppendSpecDocumentDecision :exec
+INSERT INTO spec_document_decisions (analysis_id, generation_id, sequence, kind, subject, detail, occurred_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (generation_id, sequence) DO NOTHING
+`
+
+type AppendSpecDocumentDecisionParams struct {
+	AnalysisID   pgtype.UUID        `json:"analysis_id"`
+	GenerationID pgtype.UUID        `json:"generation_id"`
+	Sequence     int32              `json:"sequence"`
+	Kind         string             `json:"kind"`
+	Subject      string             `json:"subject"`
+	Detail       []byte             `json:"detail"`
+	OccurredAt   pgtype.Timestamptz `json:"occurred_at"`
+}
+
+// =============================================================================
+// DECISION LOG
+// =============================================================================
+// Written ahead of the document; saving it sets document_id.
+func (q *Queries) AppendSpecDocumentDecision(ctx context.Context, arg AppendSpecDocumentDecisionParams) error {
+	_, err := q.db.Exec(ctx, appendSpecDocumentDecision,
+		arg.AnalysisID,
+		arg.GenerationID,
+		arg.Sequence,
+		arg.Kind,
+		arg.Subject,
+		arg.Detail,
+		arg.OccurredAt,
+	)
+	return err
+}
+
+const attributeAIUsage = `-- name: AttributeAIUsage :execrows
+UPDATE ai_usage
+SET user_id = $1, document_id = $2
+WHERE job_id = $3
+  AND user_id IS NULL
+  AND document_id IS NULL
+`
+
+type AttributeAIUsageParams struct {
+	UserID     pgtype.UUID `json:"user_id"`
+	DocumentID pgtype.UUID `json:"document_id"`
+	JobID      pgtype.Int8 `json:"job_id"`
+}
+
+// Phase 2 fan-out children record their usage under the parent's job ID
+// without a user or document, which the parent fills in once it has saved
+// the document.
+func (q *Queries) AttributeAIUsage(ctx context.Context, arg AttributeAIUsageParams) (int64, error) {
+	result, err := q.db.Exec(ctx, attributeAIUsage, arg.UserID, arg.DocumentID, arg.JobID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const attributeTenantAIUsage = `-- name: AttributeTenantAIUsage :exec
+UPDATE tenant_ai_usage
+SET document_id = $1
+WHERE job_id = $2
+  AND document_id IS NULL
+`
+
+type AttributeTenantAIUsageParams struct {
+	DocumentID pgtype.UUID `json:"document_id"`
+	JobID      pgtype.Int8 `json:"job_id"`
+}
+
+// Jobs charge tokens as each phase spends them, before the document exists;
+// saving the document links the job's charges to it.
+func (q *Queries) AttributeTenantAIUsage(ctx context.Context, arg AttributeTenantAIUsageParams) error {
+	_, err := q.db.Exec(ctx, attributeTenantAIUsage, arg.DocumentID, arg.JobID)
+	return err
+}
+
 const checkAnalysisExists = `-- name: CheckAnalysisExists :one
 SELECT EXISTS(SELECT 1 FROM analyses WHERE id = $1) as exists
 `
@@ -22,10 +133,255 @@ func (q *Queries) CheckAnalysisExists(ctx context.Context, id pgtype.UUID) (bool
 	return exists, err
 }
 
+const claimIdempotencyKey = `-- name: ClaimIdempotencyKey :one
+INSERT INTO enqueue_idempotency_keys AS k (idempotency_key, job_kind, request_hash, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (idempotency_key) DO UPDATE
+SET job_kind = EXCLUDED.job_kind,
+    request_hash = EXCLUDED.request_hash,
+    job_id = NULL,
+    created_at = now(),
+    expires_at = EXCLUDED.expires_at
+WHERE k.expires_at <= now()
+RETURNING idempotency_key
+`
+
+type ClaimIdempotencyKeyParams struct {
+	IdempotencyKey string             `json:"idempotency_key"`
+	JobKind        string             `json:"job_kind"`
+	RequestHash    string             `json:"request_hash"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+}
+
+// ==============================================================================
+// ENQUEUE IDEMPOTENCY KEYS
+// ==============================================================================
+// Records a new key. Expired keys are reclaimed; otherwise no row is returned.
+// A concurrent claim of the same key waits for the first transaction to settle.
+func (q *Queries) ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (string, error) {
+	row := q.db.QueryRow(ctx, claimIdempotencyKey,
+		arg.IdempotencyKey,
+		arg.JobKind,
+		arg.RequestHash,
+		arg.ExpiresAt,
+	)
+	var idempotency_key string
+	err := row.Scan(&idempotency_key)
+	return idempotency_key, err
+}
+
+const claimOutboxEvents = `-- name: ClaimOutboxEvents :many
+WITH due AS (
+    SELECT id FROM outbox_events
+    WHERE published_at IS NULL
+      AND next_attempt_at <= now()
+      AND attempts < $1
+    ORDER BY created_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE outbox_events e
+SET attempts = e.attempts + 1,
+    next_attempt_at = $3
+FROM due
+WHERE e.id = due.id
+RETURNING e.id, e.event_type, e.aggregate_id, e.payload, e.attempts, e.created_at
+`
+
+type ClaimOutboxEventsParams struct {
+	MaxAttempts int32              `json:"max_attempts"`
+	MaxEvents   int32              `json:"max_events"`
+	LeaseUntil  pgtype.Timestamptz `json:"lease_until"`
+}
+
+type ClaimOutboxEventsRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	EventType   string             `json:"event_type"`
+	AggregateID pgtype.UUID        `json:"aggregate_id"`
+	Payload     []byte             `json:"payload"`
+	Attempts    int32              `json:"attempts"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+// Leases due events to one relay: until lease_until passes no other relay
+// claims them, and a relay that dies mid-batch leaves them to the next.
+func (q *Queries) ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]ClaimOutboxEventsRow, error) {
+	rows, err := q.db.Query(ctx, claimOutboxEvents, arg.MaxAttempts, arg.MaxEvents, arg.LeaseUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ClaimOutboxEventsRow{}
+	for rows.Next() {
+		var i ClaimOutboxEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventType,
+			&i.AggregateID,
+			&i.Payload,
+			&i.Attempts,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimSpecGeneration = `-- name: ClaimSpecGeneration :execrows
+INSERT INTO spec_generation_claims (claim_key, job_id, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (claim_key) DO UPDATE
+SET job_id = EXCLUDED.job_id,
+    expires_at = EXCLUDED.expires_at,
+    created_at = CASE WHEN spec_generation_claims.job_id = EXCLUDED.job_id THEN spec_generation_claims.created_at ELSE now() END
+WHERE spec_generation_claims.job_id = EXCLUDED.job_id
+   OR spec_generation_claims.expires_at < now()
+`
+
+type ClaimSpecGenerationParams struct {
+	ClaimKey  []byte             `json:"claim_key"`
+	JobID     int64              `json:"job_id"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+// =============================================================================
+// GENERATION CLAIMS
+// =============================================================================
+// Zero rows means another job holds an unexpired claim. Rows are removed with
+// their river_job row when a holder dies without releasing its claim.
+func (q *Queries) ClaimSpecGeneration(ctx context.Context, arg ClaimSpecGenerationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, claimSpecGeneration, arg.ClaimKey, arg.JobID, arg.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimWebhookDelivery = `-- name: ClaimWebhookDelivery :one
+
+INSERT INTO webhook_deliveries AS d (delivery_id, event, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (delivery_id) DO UPDATE
+SET event = EXCLUDED.event,
+    outcome = NULL,
+    received_at = now(),
+    expires_at = EXCLUDED.expires_at
+WHERE d.expires_at <= now()
+   OR (d.outcome IS NULL AND d.received_at < now() - interval '5 minutes')
+RETURNING delivery_id
+`
+
+type ClaimWebhookDeliveryParams struct {
+	DeliveryID string             `json:"delivery_id"`
+	Event      string             `json:"event"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+}
+
+// =============================================================================
+// WEBHOOK DELIVERIES
+// =============================================================================
+// Records a delivery. Expired records and unfinished ones abandoned for five
+// minutes are reclaimed; otherwise no row is returned.
+func (q *Queries) ClaimWebhookDelivery(ctx context.Context, arg ClaimWebhookDeliveryParams) (string, error) {
+	row := q.db.QueryRow(ctx, claimWebhookDelivery, arg.DeliveryID, arg.Event, arg.ExpiresAt)
+	var delivery_id string
+	err := row.Scan(&delivery_id)
+	return delivery_id, err
+}
+
+const completeTenantTakeout = `-- name: CompleteTenantTakeout :exec
+UPDATE tenant_takeouts
+SET status = 'completed',
+    object_key = $1,
+    size_bytes = $2,
+    download_url = $3,
+    download_expires_at = $4,
+    error_message = NULL,
+    completed_at = now()
+WHERE id = $5
+`
+
+type CompleteTenantTakeoutParams struct {
+	ObjectKey         pgtype.Text        `json:"object_key"`
+	SizeBytes         pgtype.Int8        `json:"size_bytes"`
+	DownloadUrl       pgtype.Text        `json:"download_url"`
+	DownloadExpiresAt pgtype.Timestamptz `json:"download_expires_at"`
+	ID                pgtype.UUID        `json:"id"`
+}
+
+func (q *Queries) CompleteTenantTakeout(ctx context.Context, arg CompleteTenantTakeoutParams) error {
+	_, err := q.db.Exec(ctx, completeTenantTakeout,
+		arg.ObjectKey,
+		arg.SizeBytes,
+		arg.DownloadUrl,
+		arg.DownloadExpiresAt,
+		arg.ID,
+	)
+	return err
+}
+
+const completeWebhookDelivery = `-- name: CompleteWebhookDelivery :exec
+UPDATE webhook_deliveries SET outcome = $1
+WHERE delivery_id = $2
+`
+
+type CompleteWebhookDeliveryParams struct {
+	Outcome    pgtype.Text `json:"outcome"`
+	DeliveryID string      `json:"delivery_id"`
+}
+
+func (q *Queries) CompleteWebhookDelivery(ctx context.Context, arg CompleteWebhookDeliveryParams) error {
+	_, err := q.db.Exec(ctx, completeWebhookDelivery, arg.Outcome, arg.DeliveryID)
+	return err
+}
+
+const countRiverJobsByQueueState = `-- name: CountRiverJobsByQueueState :many
+
+SELECT queue, state::text AS state, count(*)::bigint AS jobs
+FROM river_job
+WHERE state IN ('available', 'scheduled', 'retryable', 'running')
+GROUP BY queue, state
+`
+
+type CountRiverJobsByQueueStateRow struct {
+	Queue string `json:"queue"`
+	State string `json:"state"`
+	Jobs  int64  `json:"jobs"`
+}
+
+// =============================================================================
+// METRICS
+// =============================================================================
+// Jobs waiting or running per queue, for the queue depth gauge.
+func (q *Queries) CountRiverJobsByQueueState(ctx context.Context) ([]CountRiverJobsByQueueStateRow, error) {
+	rows, err := q.db.Query(ctx, countRiverJobsByQueueState)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountRiverJobsByQueueStateRow
+	for rows.Next() {
+		var i CountRiverJobsByQueueStateRow
+		if err := rows.Scan(&i.Queue, &i.State, &i.Jobs); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createAnalysis = `-- name: CreateAnalysis :one
-INSERT INTO analyses (id, codebase_id, commit_sha, branch_name, status, started_at, parser_version)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, codebase_id, commit_sha, branch_name, status, error_message, started_at, completed_at, created_at, total_suites, total_tests, committed_at, parser_version
+INSERT INTO analyses (id, codebase_id, commit_sha, branch_name, status, started_at, parser_version, path_filters)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, codebase_id, commit_sha, branch_name, status, error_message, started_at, completed_at, created_at, total_suites, total_tests, committed_at, parser_version, curation_rules, failure_class, path_filters, quarantined_files, test_fingerprint, deduplicated_from
 `
 
 type CreateAnalysisParams struct {
@@ -36,6 +392,7 @@ type CreateAnalysisParams struct {
 	Status        AnalysisStatus     `json:"status"`
 	StartedAt     pgtype.Timestamptz `json:"started_at"`
 	ParserVersion string             `json:"parser_version"`
+	PathFilters   []byte             `json:"path_filters"`
 }
 
 func (q *Queries) CreateAnalysis(ctx context.Context, arg CreateAnalysisParams) (Analysis, error) {
@@ -47,6 +404,7 @@ func (q *Queries) CreateAnalysis(ctx context.Context, arg CreateAnalysisParams)
 		arg.Status,
 		arg.StartedAt,
 		arg.ParserVersion,
+		arg.PathFilters,
 	)
 	var i Analysis
 	err := row.Scan(
@@ -63,6 +421,12 @@ func (q *Queries) CreateAnalysis(ctx context.Context, arg CreateAnalysisParams)
 		&i.TotalTests,
 		&i.CommittedAt,
 		&i.ParserVersion,
+		&i.CurationRules,
+		&i.FailureClass,
+		&i.PathFilters,
+		&i.QuarantinedFiles,
+		&i.TestFingerprint,
+		&i.DeduplicatedFrom,
 	)
 	return i, err
 }
@@ -70,7 +434,7 @@ func (q *Queries) CreateAnalysis(ctx context.Context, arg CreateAnalysisParams)
 const createTestCase = `-- name: CreateTestCase :one
 INSERT INTO test_cases (suite_id, name, line_number, status, tags, modifier)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, suite_id, name, line_number, status, tags, modifier
+RETURNING id, suite_id, name, line_number, status, tags, modifier, variant_count
 `
 
 type CreateTestCaseParams struct {
@@ -100,10 +464,30 @@ func (q *Queries) CreateTestCase(ctx context.Context, arg CreateTestCaseParams)
 		&i.Status,
 		&i.Tags,
 		&i.Modifier,
+		&i.VariantCount,
 	)
 	return i, err
 }
 
+const deleteExpiredAnalysisEvents = `-- name: DeleteExpiredAnalysisEvents :execrows
+DELETE FROM analysis_events
+WHERE id IN (
+    SELECT id FROM analysis_events
+    WHERE created_at < now() - interval '7 days'
+    LIMIT $1
+)
+`
+
+// Deletes analysis progress events older than a week. Idempotency key lookups
+// resolve analysis IDs through them for 72 hours.
+func (q *Queries) DeleteExpiredAnalysisEvents(ctx context.Context, limit int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredAnalysisEvents, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteExpiredClassificationCaches = `-- name: DeleteExpiredClassificationCaches :execrows
 DELETE FROM classification_caches
 WHERE created_at < now() - $1::interval
@@ -117,17 +501,40 @@ func (q *Queries) DeleteExpiredClassificationCaches(ctx context.Context, dollar_
 	return result.RowsAffected(), nil
 }
 
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM enqueue_idempotency_keys
+WHERE idempotency_key IN (
+    SELECT idempotency_key FROM enqueue_idempotency_keys
+    WHERE expires_at < now()
+    LIMIT $1
+)
+`
+
+// Deletes idempotency keys past their deduplication window.
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, limit int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredIdempotencyKeys, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteExpiredSpecDocuments = `-- name: DeleteExpiredSpecDocuments :execrows
 DELETE FROM spec_documents
 WHERE id IN (
-    SELECT id FROM spec_documents
-    WHERE retention_days_at_creation IS NOT NULL
-      AND created_at < now() - (retention_days_at_creation || ' days')::interval
+    SELECT sd.id FROM spec_documents sd
+    WHERE sd.retention_days_at_creation IS NOT NULL
+      AND sd.created_at < now() - (sd.retention_days_at_creation || ' days')::interval
+      AND NOT EXISTS (
+          SELECT 1 FROM spec_document_pins p
+          WHERE p.document_id = COALESCE(sd.parent_document_id, sd.id)
+      )
     LIMIT $1
 )
 `
 
-// Deletes spec_documents that have exceeded their retention period.
+// Deletes spec_documents that have exceeded their retention period. Pinned
+// documents and the team slices of pinned documents are kept.
 func (q *Queries) DeleteExpiredSpecDocuments(ctx context.Context, limit int32) (int64, error) {
 	result, err := q.db.Exec(ctx, deleteExpiredSpecDocuments, limit)
 	if err != nil {
@@ -161,6 +568,75 @@ func (q *Queries) DeleteExpiredUserAnalysisHistory(ctx context.Context, limit in
 	return result.RowsAffected(), nil
 }
 
+const deleteExpiredWebhookDeliveries = `-- name: DeleteExpiredWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE delivery_id IN (
+    SELECT delivery_id FROM webhook_deliveries
+    WHERE expires_at < now()
+    LIMIT $1
+)
+`
+
+// Deletes webhook delivery records past their deduplication window.
+func (q *Queries) DeleteExpiredWebhookDeliveries(ctx context.Context, limit int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredWebhookDeliveries, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteIdleRateLimitBuckets = `-- name: DeleteIdleRateLimitBuckets :execrows
+DELETE FROM rate_limit_buckets
+WHERE bucket_key IN (
+    SELECT bucket_key FROM rate_limit_buckets
+    WHERE updated_at < now() - interval '1 day'
+    LIMIT $1
+)
+`
+
+// Deletes rate limit buckets untouched for a day. They have refilled by then,
+// and a missing bucket starts full.
+func (q *Queries) DeleteIdleRateLimitBuckets(ctx context.Context, limit int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteIdleRateLimitBuckets, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteNotificationChannel = `-- name: DeleteNotificationChannel :execrows
+DELETE FROM notification_channels WHERE id = $1
+`
+
+func (q *Queries) DeleteNotificationChannel(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteNotificationChannel, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteOrphanBehaviorEmbeddings = `-- name: DeleteOrphanBehaviorEmbeddings :execrows
+DELETE FROM behavior_cache_embeddings
+WHERE cache_key_hash IN (
+    SELECT e.cache_key_hash FROM behavior_cache_embeddings e
+    WHERE e.created_at < now() - interval '1 day'
+      AND NOT EXISTS (SELECT 1 FROM behavior_caches bc WHERE bc.cache_key_hash = e.cache_key_hash)
+    LIMIT $1
+)
+`
+
+// Deletes embeddings older than a day without a behavior cache entry: tests
+// whose conversion never succeeded, or whose entry was deleted since.
+func (q *Queries) DeleteOrphanBehaviorEmbeddings(ctx context.Context, limit int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOrphanBehaviorEmbeddings, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteOrphanedAnalyses = `-- name: DeleteOrphanedAnalyses :execrows
 DELETE FROM analyses
 WHERE id IN (
@@ -168,12 +644,18 @@ WHERE id IN (
     LEFT JOIN user_analysis_history uah ON a.id = uah.analysis_id
     WHERE uah.analysis_id IS NULL
       AND a.created_at < now() - interval '1 day'
+      AND NOT EXISTS (
+          SELECT 1 FROM spec_documents sd
+          JOIN spec_document_pins p ON p.document_id = sd.id
+          WHERE sd.analysis_id = a.id
+      )
     LIMIT $1
 )
 `
 
 // Deletes analyses that have no references in user_analysis_history.
-// These are orphaned records that no user is tracking anymore.
+// These are orphaned records that no user is tracking anymore. Analyses with a
+// pinned document are kept, since deleting them would delete the document.
 func (q *Queries) DeleteOrphanedAnalyses(ctx context.Context, limit int32) (int64, error) {
 	result, err := q.db.Exec(ctx, deleteOrphanedAnalyses, limit)
 	if err != nil {
@@ -195,60 +677,290 @@ func (q *Queries) DeleteQuotaReservationByJobID(ctx context.Context, jobID int64
 	return err
 }
 
-const findBehaviorCachesByHashes = `-- name: FindBehaviorCachesByHashes :many
-
-SELECT cache_key_hash, converted_description
-FROM behavior_caches
-WHERE cache_key_hash = ANY($1::bytea[])
+const deleteRequirementCoverageBySetAndDocument = `-- name: DeleteRequirementCoverageBySetAndDocument :execrows
+DELETE FROM requirement_coverage rc
+USING requirements r
+WHERE rc.requirement_id = r.id
+  AND r.requirement_set_id = $1
+  AND rc.document_id = $2
 `
 
-type FindBehaviorCachesByHashesRow struct {
-	CacheKeyHash         []byte `json:"cache_key_hash"`
-	ConvertedDescription string `json:"converted_description"`
+type DeleteRequirementCoverageBySetAndDocumentParams struct {
+	RequirementSetID pgtype.UUID `json:"requirement_set_id"`
+	DocumentID       pgtype.UUID `json:"document_id"`
 }
 
-// =============================================================================
-// BEHAVIOR CACHES
-// =============================================================================
-func (q *Queries) FindBehaviorCachesByHashes(ctx context.Context, dollar_1 [][]byte) ([]FindBehaviorCachesByHashesRow, error) {
-	rows, err := q.db.Query(ctx, findBehaviorCachesByHashes, dollar_1)
+func (q *Queries) DeleteRequirementCoverageBySetAndDocument(ctx context.Context, arg DeleteRequirementCoverageBySetAndDocumentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRequirementCoverageBySetAndDocument, arg.RequirementSetID, arg.DocumentID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []FindBehaviorCachesByHashesRow{}
-	for rows.Next() {
-		var i FindBehaviorCachesByHashesRow
-		if err := rows.Scan(&i.CacheKeyHash, &i.ConvertedDescription); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const findClassificationCacheByKey = `-- name: FindClassificationCacheByKey :one
+const deleteSettledOutboxEvents = `-- name: DeleteSettledOutboxEvents :execrows
+DELETE FROM outbox_events
+WHERE id IN (
+    SELECT id FROM outbox_events
+    WHERE published_at < $1
+       OR (published_at IS NULL AND attempts >= $2 AND created_at < $1)
+    LIMIT $3
+)
+`
 
-SELECT id, content_hash, language, model_id, phase1_output, test_index_map, created_at
-FROM classification_caches
-WHERE content_hash = $1 AND language = $2 AND model_id = $3
+type DeleteSettledOutboxEventsParams struct {
+	Before      pgtype.Timestamptz `json:"before"`
+	MaxAttempts int32              `json:"max_attempts"`
+	MaxRows     int32              `json:"max_rows"`
+}
+
+// Events published before the cutoff, and events created before it that ran
+// out of attempts.
+func (q *Queries) DeleteSettledOutboxEvents(ctx context.Context, arg DeleteSettledOutboxEventsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSettledOutboxEvents, arg.Before, arg.MaxAttempts, arg.MaxRows)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSoftDeletedSpecDocuments = `-- name: DeleteSoftDeletedSpecDocuments :execrows
+DELETE FROM spec_documents
+WHERE id IN (
+    SELECT sd.id FROM spec_documents sd
+    WHERE sd.deleted_at < $1
+      AND NOT EXISTS (SELECT 1 FROM spec_document_pins p WHERE p.document_id = sd.id)
+    LIMIT $2
+)
 `
 
-type FindClassificationCacheByKeyParams struct {
-	ContentHash []byte `json:"content_hash"`
-	Language    string `json:"language"`
-	ModelID     string `json:"model_id"`
+type DeleteSoftDeletedSpecDocumentsParams struct {
+	DeletedBefore pgtype.Timestamptz `json:"deleted_before"`
+	BatchSize     int32              `json:"batch_size"`
 }
 
-// =============================================================================
-// CLASSIFICATION CACHES
-// =============================================================================
-func (q *Queries) FindClassificationCacheByKey(ctx context.Context, arg FindClassificationCacheByKeyParams) (ClassificationCach, error) {
-	row := q.db.QueryRow(ctx, findClassificationCacheByKey, arg.ContentHash, arg.Language, arg.ModelID)
-	var i ClassificationCach
+// Deletes full documents soft-deleted before @deleted_before, with their team
+// slices. Pinned documents cannot be deleted, but are kept regardless.
+func (q *Queries) DeleteSoftDeletedSpecDocuments(ctx context.Context, arg DeleteSoftDeletedSpecDocumentsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSoftDeletedSpecDocuments, arg.DeletedBefore, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSpecGenerationCheckpoint = `-- name: DeleteSpecGenerationCheckpoint :exec
+DELETE FROM spec_generation_checkpoints WHERE job_id = $1
+`
+
+func (q *Queries) DeleteSpecGenerationCheckpoint(ctx context.Context, jobID int64) error {
+	_, err := q.db.Exec(ctx, deleteSpecGenerationCheckpoint, jobID)
+	return err
+}
+
+const deleteSpecGenerationCheckpointFeatures = `-- name: DeleteSpecGenerationCheckpointFeatures :exec
+DELETE FROM spec_generation_checkpoint_features WHERE job_id = $1
+`
+
+func (q *Queries) DeleteSpecGenerationCheckpointFeatures(ctx context.Context, jobID int64) error {
+	_, err := q.db.Exec(ctx, deleteSpecGenerationCheckpointFeatures, jobID)
+	return err
+}
+
+const deleteSpecSearchEntriesByDocumentID = `-- name: DeleteSpecSearchEntriesByDocumentID :execrows
+DELETE FROM spec_search_entries WHERE document_id = $1
+`
+
+func (q *Queries) DeleteSpecSearchEntriesByDocumentID(ctx context.Context, documentID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSpecSearchEntriesByDocumentID, documentID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteStagedInventory = `-- name: DeleteStagedInventory :exec
+WITH deleted_files AS (
+    DELETE FROM analysis_staging_test_files WHERE analysis_id = $1
+), deleted_suites AS (
+    DELETE FROM analysis_staging_test_suites WHERE analysis_id = $1
+)
+DELETE FROM analysis_staging_test_cases WHERE analysis_id = $1
+`
+
+func (q *Queries) DeleteStagedInventory(ctx context.Context, analysisID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteStagedInventory, analysisID)
+	return err
+}
+
+const deleteStaleCodebaseBehaviorCaches = `-- name: DeleteStaleCodebaseBehaviorCaches :execrows
+DELETE FROM behavior_caches
+WHERE id IN (
+    SELECT bc.id FROM behavior_caches bc
+    JOIN codebases c ON c.id = bc.codebase_id
+    WHERE c.is_stale = true
+      AND c.updated_at < now() - interval '1 day'
+    LIMIT $1
+)
+`
+
+// Deletes behavior cache entries of codebases stale for over a day, e.g.
+// repositories deleted and recreated under the same name. The grace period
+// keeps them for a codebase restored soon after.
+func (q *Queries) DeleteStaleCodebaseBehaviorCaches(ctx context.Context, limit int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteStaleCodebaseBehaviorCaches, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteStaleCodebaseClassificationCaches = `-- name: DeleteStaleCodebaseClassificationCaches :execrows
+DELETE FROM classification_caches
+WHERE id IN (
+    SELECT cc.id FROM classification_caches cc
+    JOIN codebases c ON c.id = cc.codebase_id
+    WHERE c.is_stale = true
+      AND c.updated_at < now() - interval '1 day'
+    LIMIT $1
+)
+`
+
+// Deletes classification cache entries of codebases stale for over a day.
+func (q *Queries) DeleteStaleCodebaseClassificationCaches(ctx context.Context, limit int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteStaleCodebaseClassificationCaches, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSupersededSpecDocuments = `-- name: DeleteSupersededSpecDocuments :execrows
+DELETE FROM spec_documents
+WHERE id IN (
+    SELECT ranked.id FROM (
+        SELECT sd.id, sd.created_at,
+            row_number() OVER (
+                PARTITION BY sd.user_id, sd.analysis_id, sd.project, sd.language, sd.model_id
+                ORDER BY sd.version DESC
+            ) AS recency
+        FROM spec_documents sd
+        WHERE sd.parent_document_id IS NULL
+          AND sd.deleted_at IS NULL
+    ) ranked
+    WHERE ranked.recency > $1::int
+      AND ranked.created_at < $2
+      AND NOT EXISTS (SELECT 1 FROM spec_document_pins p WHERE p.document_id = ranked.id)
+    LIMIT $3
+)
+`
+
+type DeleteSupersededSpecDocumentsParams struct {
+	KeepVersions  int32              `json:"keep_versions"`
+	CreatedBefore pgtype.Timestamptz `json:"created_before"`
+	BatchSize     int32              `json:"batch_size"`
+}
+
+// Deletes full document versions created before @created_before, keeping the
+// @keep_versions latest undeleted versions of each user, analysis, project,
+// language and model. Pinned versions are kept and count toward the latest.
+// Team slices, domains, features and behaviors go with their documents.
+func (q *Queries) DeleteSupersededSpecDocuments(ctx context.Context, arg DeleteSupersededSpecDocumentsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSupersededSpecDocuments, arg.KeepVersions, arg.CreatedBefore, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const extendSpecGenerationClaim = `-- name: ExtendSpecGenerationClaim :execrows
+UPDATE spec_generation_claims SET expires_at = $1
+WHERE claim_key = $2 AND job_id = $3
+`
+
+type ExtendSpecGenerationClaimParams struct {
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	ClaimKey  []byte             `json:"claim_key"`
+	JobID     int64              `json:"job_id"`
+}
+
+func (q *Queries) ExtendSpecGenerationClaim(ctx context.Context, arg ExtendSpecGenerationClaimParams) (int64, error) {
+	result, err := q.db.Exec(ctx, extendSpecGenerationClaim, arg.ExpiresAt, arg.ClaimKey, arg.JobID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const failTenantTakeout = `-- name: FailTenantTakeout :exec
+UPDATE tenant_takeouts SET status = 'failed', error_message = $1
+WHERE id = $2 AND status <> 'completed'
+`
+
+type FailTenantTakeoutParams struct {
+	ErrorMessage pgtype.Text `json:"error_message"`
+	ID           pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) FailTenantTakeout(ctx context.Context, arg FailTenantTakeoutParams) error {
+	_, err := q.db.Exec(ctx, failTenantTakeout, arg.ErrorMessage, arg.ID)
+	return err
+}
+
+const findBehaviorCachesByHashes = `-- name: FindBehaviorCachesByHashes :many
+
+SELECT cache_key_hash, converted_description
+FROM behavior_caches
+WHERE cache_key_hash = ANY($1::bytea[])
+`
+
+type FindBehaviorCachesByHashesRow struct {
+	CacheKeyHash         []byte `json:"cache_key_hash"`
+	ConvertedDescription string `json:"converted_description"`
+}
+
+// =============================================================================
+// BEHAVIOR CACHES
+// =============================================================================
+func (q *Queries) FindBehaviorCachesByHashes(ctx context.Context, dollar_1 [][]byte) ([]FindBehaviorCachesByHashesRow, error) {
+	rows, err := q.db.Query(ctx, findBehaviorCachesByHashes, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FindBehaviorCachesByHashesRow{}
+	for rows.Next() {
+		var i FindBehaviorCachesByHashesRow
+		if err := rows.Scan(&i.CacheKeyHash, &i.ConvertedDescription); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findClassificationCacheByKey = `-- name: FindClassificationCacheByKey :one
+
+SELECT id, content_hash, language, model_id, phase1_output, test_index_map, created_at
+FROM classification_caches
+WHERE content_hash = $1 AND language = $2 AND model_id = $3
+`
+
+type FindClassificationCacheByKeyParams struct {
+	ContentHash []byte `json:"content_hash"`
+	Language    string `json:"language"`
+	ModelID     string `json:"model_id"`
+}
+
+// =============================================================================
+// CLASSIFICATION CACHES
+// =============================================================================
+func (q *Queries) FindClassificationCacheByKey(ctx context.Context, arg FindClassificationCacheByKeyParams) (ClassificationCach, error) {
+	row := q.db.QueryRow(ctx, findClassificationCacheByKey, arg.ContentHash, arg.Language, arg.ModelID)
+	var i ClassificationCach
 	err := row.Scan(
 		&i.ID,
 		&i.ContentHash,
@@ -262,7 +974,7 @@ func (q *Queries) FindClassificationCacheByKey(ctx context.Context, arg FindClas
 }
 
 const findCodebaseByExternalID = `-- name: FindCodebaseByExternalID :one
-SELECT id, host, owner, name, default_branch, created_at, updated_at, last_viewed_at, external_repo_id, is_stale, is_private FROM codebases
+SELECT id, host, owner, name, default_branch, created_at, updated_at, last_viewed_at, external_repo_id, is_stale, is_private, region, fork_dedup_enabled FROM codebases
 WHERE host = $1 AND external_repo_id = $2
 `
 
@@ -286,12 +998,14 @@ func (q *Queries) FindCodebaseByExternalID(ctx context.Context, arg FindCodebase
 		&i.ExternalRepoID,
 		&i.IsStale,
 		&i.IsPrivate,
+		&i.Region,
+		&i.ForkDedupEnabled,
 	)
 	return i, err
 }
 
 const findCodebaseByOwnerName = `-- name: FindCodebaseByOwnerName :one
-SELECT id, host, owner, name, default_branch, created_at, updated_at, last_viewed_at, external_repo_id, is_stale, is_private FROM codebases
+SELECT id, host, owner, name, default_branch, created_at, updated_at, last_viewed_at, external_repo_id, is_stale, is_private, region, fork_dedup_enabled FROM codebases
 WHERE host = $1 AND owner = $2 AND name = $3 AND is_stale = false
 `
 
@@ -316,13 +1030,15 @@ func (q *Queries) FindCodebaseByOwnerName(ctx context.Context, arg FindCodebaseB
 		&i.ExternalRepoID,
 		&i.IsStale,
 		&i.IsPrivate,
+		&i.Region,
+		&i.ForkDedupEnabled,
 	)
 	return i, err
 }
 
 const findCodebaseWithLastCommitByOwnerName = `-- name: FindCodebaseWithLastCommitByOwnerName :one
 SELECT
-    c.id, c.host, c.owner, c.name, c.default_branch, c.created_at, c.updated_at, c.last_viewed_at, c.external_repo_id, c.is_stale, c.is_private,
+    c.id, c.host, c.owner, c.name, c.default_branch, c.created_at, c.updated_at, c.last_viewed_at, c.external_repo_id, c.is_stale, c.is_private, c.region, c.fork_dedup_enabled,
     COALESCE(a.commit_sha, '') as last_commit_sha
 FROM codebases c
 LEFT JOIN (
@@ -341,18 +1057,20 @@ type FindCodebaseWithLastCommitByOwnerNameParams struct {
 }
 
 type FindCodebaseWithLastCommitByOwnerNameRow struct {
-	ID             pgtype.UUID        `json:"id"`
-	Host           string             `json:"host"`
-	Owner          string             `json:"owner"`
-	Name           string             `json:"name"`
-	DefaultBranch  pgtype.Text        `json:"default_branch"`
-	CreatedAt      pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
-	LastViewedAt   pgtype.Timestamptz `json:"last_viewed_at"`
-	ExternalRepoID string             `json:"external_repo_id"`
-	IsStale        bool               `json:"is_stale"`
-	IsPrivate      bool               `json:"is_private"`
-	LastCommitSha  string             `json:"last_commit_sha"`
+	ID               pgtype.UUID        `json:"id"`
+	Host             string             `json:"host"`
+	Owner            string             `json:"owner"`
+	Name             string             `json:"name"`
+	DefaultBranch    pgtype.Text        `json:"default_branch"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	LastViewedAt     pgtype.Timestamptz `json:"last_viewed_at"`
+	ExternalRepoID   string             `json:"external_repo_id"`
+	IsStale          bool               `json:"is_stale"`
+	IsPrivate        bool               `json:"is_private"`
+	Region           pgtype.Text        `json:"region"`
+	ForkDedupEnabled bool               `json:"fork_dedup_enabled"`
+	LastCommitSha    string             `json:"last_commit_sha"`
 }
 
 func (q *Queries) FindCodebaseWithLastCommitByOwnerName(ctx context.Context, arg FindCodebaseWithLastCommitByOwnerNameParams) (FindCodebaseWithLastCommitByOwnerNameRow, error) {
@@ -370,39 +1088,76 @@ func (q *Queries) FindCodebaseWithLastCommitByOwnerName(ctx context.Context, arg
 		&i.ExternalRepoID,
 		&i.IsStale,
 		&i.IsPrivate,
+		&i.Region,
+		&i.ForkDedupEnabled,
 		&i.LastCommitSha,
 	)
 	return i, err
 }
 
-const findSpecDocumentByContentHash = `-- name: FindSpecDocumentByContentHash :one
-SELECT sd.id, sd.analysis_id, sd.content_hash, sd.language, sd.executive_summary, sd.model_id, sd.created_at, sd.updated_at, sd.version, sd.user_id, sd.retention_days_at_creation FROM spec_documents sd
-WHERE sd.user_id = $1
+const findCompletedAnalysisByCommit = `-- name: FindCompletedAnalysisByCommit :one
+SELECT a.id
+FROM analyses a
+JOIN codebases c ON c.id = a.codebase_id
+WHERE c.host = $1 AND c.owner = $2 AND c.name = $3 AND c.is_stale = false
+  AND a.commit_sha = $4
+  AND a.status = 'completed'
+ORDER BY a.completed_at DESC
+LIMIT 1
+`
+
+type FindCompletedAnalysisByCommitParams struct {
+	Host      string `json:"host"`
+	Owner     string `json:"owner"`
+	Name      string `json:"name"`
+	CommitSha string `json:"commit_sha"`
+}
+
+// Latest completed analysis of the commit on any branch.
+func (q *Queries) FindCompletedAnalysisByCommit(ctx context.Context, arg FindCompletedAnalysisByCommitParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, findCompletedAnalysisByCommit,
+		arg.Host,
+		arg.Owner,
+		arg.Name,
+		arg.CommitSha,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const findShareableSpecDocument = `-- name: FindShareableSpecDocument :one
+SELECT sd.id, sd.analysis_id, sd.content_hash, sd.language, sd.executive_summary, sd.model_id, sd.created_at, sd.updated_at, sd.version, sd.user_id, sd.retention_days_at_creation, sd.parent_document_id, sd.team, sd.project, sd.encryption_key_id, sd.prompt_version, sd.deleted_at, sd.deleted_by, sd.quality_score, sd.quality_flagged, sd.quality_report, sd.is_dry_run, sd.generator FROM spec_documents sd
+JOIN analyses a ON a.id = sd.analysis_id
+WHERE sd.user_id <> $1
   AND sd.content_hash = $2
   AND sd.language = $3
   AND sd.model_id = $4
-  AND sd.version = (
-    SELECT MAX(version)
-    FROM spec_documents
-    WHERE user_id = sd.user_id
-      AND analysis_id = sd.analysis_id
-      AND language = sd.language
-  )
+  AND sd.parent_document_id IS NULL
+  AND sd.encryption_key_id IS NULL
+  AND sd.deleted_at IS NULL
+  AND a.codebase_id = (SELECT codebase_id FROM analyses WHERE id = $5)
+ORDER BY sd.created_at DESC
+LIMIT 1
 `
 
-type FindSpecDocumentByContentHashParams struct {
+type FindShareableSpecDocumentParams struct {
 	UserID      pgtype.UUID `json:"user_id"`
 	ContentHash []byte      `json:"content_hash"`
 	Language    string      `json:"language"`
 	ModelID     string      `json:"model_id"`
+	AnalysisID  pgtype.UUID `json:"analysis_id"`
 }
 
-func (q *Queries) FindSpecDocumentByContentHash(ctx context.Context, arg FindSpecDocumentByContentHashParams) (SpecDocument, error) {
-	row := q.db.QueryRow(ctx, findSpecDocumentByContentHash,
+// Latest full document another user generated for the same codebase and content.
+// Encrypted and deleted documents are never shared.
+func (q *Queries) FindShareableSpecDocument(ctx context.Context, arg FindShareableSpecDocumentParams) (SpecDocument, error) {
+	row := q.db.QueryRow(ctx, findShareableSpecDocument,
 		arg.UserID,
 		arg.ContentHash,
 		arg.Language,
 		arg.ModelID,
+		arg.AnalysisID,
 	)
 	var i SpecDocument
 	err := row.Scan(
@@ -417,150 +1172,211 @@ func (q *Queries) FindSpecDocumentByContentHash(ctx context.Context, arg FindSpe
 		&i.Version,
 		&i.UserID,
 		&i.RetentionDaysAtCreation,
+		&i.ParentDocumentID,
+		&i.Team,
+		&i.Project,
+		&i.EncryptionKeyID,
+		&i.PromptVersion,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.QualityScore,
+		&i.QualityFlagged,
+		&i.QualityReport,
+		&i.IsDryRun,
+		&i.Generator,
 	)
 	return i, err
 }
 
-const getAnalysisContext = `-- name: GetAnalysisContext :one
-SELECT c.host, c.owner, c.name as repo
-FROM analyses a
-JOIN codebases c ON a.codebase_id = c.id
-WHERE a.id = $1
+const findSimilarBehaviors = `-- name: FindSimilarBehaviors :many
+SELECT q.idx::int AS query_index, n.converted_description, n.similarity::float8 AS similarity
+FROM unnest($1::text[]) WITH ORDINALITY AS q(embedding, idx)
+CROSS JOIN LATERAL (
+    SELECT bc.converted_description, 1 - (e.embedding <=> q.embedding::vector) AS similarity
+    FROM behavior_cache_embeddings e
+    JOIN behavior_caches bc ON bc.cache_key_hash = e.cache_key_hash
+    WHERE e.partition_hash = $2::bytea
+    ORDER BY e.embedding <=> q.embedding::vector
+    LIMIT 1
+) n
+WHERE n.similarity >= $3::float8
 `
 
-type GetAnalysisContextRow struct {
-	Host  string `json:"host"`
-	Owner string `json:"owner"`
-	Repo  string `json:"repo"`
+type FindSimilarBehaviorsParams struct {
+	Embeddings    []string `json:"embeddings"`
+	PartitionHash []byte   `json:"partition_hash"`
+	MinSimilarity float64  `json:"min_similarity"`
 }
 
-func (q *Queries) GetAnalysisContext(ctx context.Context, id pgtype.UUID) (GetAnalysisContextRow, error) {
-	row := q.db.QueryRow(ctx, getAnalysisContext, id)
-	var i GetAnalysisContextRow
-	err := row.Scan(&i.Host, &i.Owner, &i.Repo)
-	return i, err
+type FindSimilarBehaviorsRow struct {
+	QueryIndex           int32   `json:"query_index"`
+	ConvertedDescription string  `json:"converted_description"`
+	Similarity           float64 `json:"similarity"`
 }
 
-const getCodebaseByID = `-- name: GetCodebaseByID :one
-SELECT id, host, owner, name, default_branch, created_at, updated_at, last_viewed_at, external_repo_id, is_stale, is_private FROM codebases WHERE id = $1
+// Returns, per query embedding (1-based index), the cached behavior of the
+// nearest test in the partition by cosine distance, when similar enough.
+func (q *Queries) FindSimilarBehaviors(ctx context.Context, arg FindSimilarBehaviorsParams) ([]FindSimilarBehaviorsRow, error) {
+	rows, err := q.db.Query(ctx, findSimilarBehaviors, arg.Embeddings, arg.PartitionHash, arg.MinSimilarity)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FindSimilarBehaviorsRow{}
+	for rows.Next() {
+		var i FindSimilarBehaviorsRow
+		if err := rows.Scan(&i.QueryIndex, &i.ConvertedDescription, &i.Similarity); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findSpecDocumentAsOf = `-- name: FindSpecDocumentAsOf :one
+SELECT sd.id, sd.analysis_id, sd.content_hash, sd.language, sd.executive_summary, sd.model_id, sd.created_at, sd.updated_at, sd.version, sd.user_id, sd.retention_days_at_creation, sd.parent_document_id, sd.team, sd.project, sd.encryption_key_id, sd.prompt_version, sd.deleted_at, sd.deleted_by, sd.quality_score, sd.quality_flagged, sd.quality_report, sd.is_dry_run, sd.generator FROM spec_documents sd
+JOIN analyses a ON a.id = sd.analysis_id
+WHERE sd.user_id = $1
+  AND a.codebase_id = $2
+  AND sd.language = $3
+  AND sd.parent_document_id IS NULL
+  AND sd.project IS NULL
+  AND sd.deleted_at IS NULL
+  AND sd.created_at < $4
+ORDER BY sd.created_at DESC, sd.version DESC
+LIMIT 1
 `
 
-func (q *Queries) GetCodebaseByID(ctx context.Context, id pgtype.UUID) (Codebasis, error) {
-	row := q.db.QueryRow(ctx, getCodebaseByID, id)
-	var i Codebasis
+type FindSpecDocumentAsOfParams struct {
+	UserID     pgtype.UUID        `json:"user_id"`
+	CodebaseID pgtype.UUID        `json:"codebase_id"`
+	Language   string             `json:"language"`
+	AsOf       pgtype.Timestamptz `json:"as_of"`
+}
+
+// Latest full document the user had for the codebase before the given time.
+func (q *Queries) FindSpecDocumentAsOf(ctx context.Context, arg FindSpecDocumentAsOfParams) (SpecDocument, error) {
+	row := q.db.QueryRow(ctx, findSpecDocumentAsOf,
+		arg.UserID,
+		arg.CodebaseID,
+		arg.Language,
+		arg.AsOf,
+	)
+	var i SpecDocument
 	err := row.Scan(
 		&i.ID,
-		&i.Host,
-		&i.Owner,
-		&i.Name,
-		&i.DefaultBranch,
+		&i.AnalysisID,
+		&i.ContentHash,
+		&i.Language,
+		&i.ExecutiveSummary,
+		&i.ModelID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.LastViewedAt,
-		&i.ExternalRepoID,
-		&i.IsStale,
-		&i.IsPrivate,
+		&i.Version,
+		&i.UserID,
+		&i.RetentionDaysAtCreation,
+		&i.ParentDocumentID,
+		&i.Team,
+		&i.Project,
+		&i.EncryptionKeyID,
+		&i.PromptVersion,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.QualityScore,
+		&i.QualityFlagged,
+		&i.QualityReport,
+		&i.IsDryRun,
+		&i.Generator,
 	)
 	return i, err
 }
 
-const getMaxVersionByUserAnalysisAndLanguage = `-- name: GetMaxVersionByUserAnalysisAndLanguage :one
-
-SELECT COALESCE(MAX(version), 0)::int as max_version
-FROM spec_documents
-WHERE user_id = $1 AND analysis_id = $2 AND language = $3
+const findSpecDocumentByContentHash = `-- name: FindSpecDocumentByContentHash :one
+SELECT sd.id, sd.analysis_id, sd.content_hash, sd.language, sd.executive_summary, sd.model_id, sd.created_at, sd.updated_at, sd.version, sd.user_id, sd.retention_days_at_creation, sd.parent_document_id, sd.team, sd.project, sd.encryption_key_id, sd.prompt_version, sd.deleted_at, sd.deleted_by, sd.quality_score, sd.quality_flagged, sd.quality_report, sd.is_dry_run, sd.generator FROM spec_documents sd
+WHERE sd.user_id = $1
+  AND sd.content_hash = $2
+  AND sd.language = $3
+  AND sd.model_id = $4
+  AND sd.parent_document_id IS NULL
+  AND sd.deleted_at IS NULL
+  AND sd.version = (
+    SELECT MAX(version)
+    FROM spec_documents
+    WHERE user_id = sd.user_id
+      AND analysis_id = sd.analysis_id
+      AND language = sd.language
+      AND parent_document_id IS NULL
+      AND project IS NOT DISTINCT FROM sd.project
+  )
 `
 
-type GetMaxVersionByUserAnalysisAndLanguageParams struct {
-	UserID     pgtype.UUID `json:"user_id"`
-	AnalysisID pgtype.UUID `json:"analysis_id"`
-	Language   string      `json:"language"`
+type FindSpecDocumentByContentHashParams struct {
+	UserID      pgtype.UUID `json:"user_id"`
+	ContentHash []byte      `json:"content_hash"`
+	Language    string      `json:"language"`
+	ModelID     string      `json:"model_id"`
 }
 
-// =============================================================================
-// SPEC DOCUMENTS
-// =============================================================================
-func (q *Queries) GetMaxVersionByUserAnalysisAndLanguage(ctx context.Context, arg GetMaxVersionByUserAnalysisAndLanguageParams) (int32, error) {
-	row := q.db.QueryRow(ctx, getMaxVersionByUserAnalysisAndLanguage, arg.UserID, arg.AnalysisID, arg.Language)
-	var max_version int32
-	err := row.Scan(&max_version)
-	return max_version, err
-}
-
-const getMonthlySpecViewUsage = `-- name: GetMonthlySpecViewUsage :one
-SELECT COALESCE(SUM(quota_amount), 0)::int as total
-FROM usage_events
-WHERE user_id = $1
-  AND event_type = 'specview'
-  AND created_at >= date_trunc('month', CURRENT_DATE)
-`
-
-func (q *Queries) GetMonthlySpecViewUsage(ctx context.Context, userID pgtype.UUID) (int32, error) {
-	row := q.db.QueryRow(ctx, getMonthlySpecViewUsage, userID)
-	var total int32
-	err := row.Scan(&total)
-	return total, err
-}
-
-const getOAuthAccountByUserAndProvider = `-- name: GetOAuthAccountByUserAndProvider :one
-SELECT id, user_id, provider, provider_user_id, provider_username, access_token, scope, created_at, updated_at FROM oauth_accounts WHERE user_id = $1 AND provider = $2
-`
-
-type GetOAuthAccountByUserAndProviderParams struct {
-	UserID   pgtype.UUID   `json:"user_id"`
-	Provider OauthProvider `json:"provider"`
-}
-
-func (q *Queries) GetOAuthAccountByUserAndProvider(ctx context.Context, arg GetOAuthAccountByUserAndProviderParams) (OauthAccount, error) {
-	row := q.db.QueryRow(ctx, getOAuthAccountByUserAndProvider, arg.UserID, arg.Provider)
-	var i OauthAccount
+func (q *Queries) FindSpecDocumentByContentHash(ctx context.Context, arg FindSpecDocumentByContentHashParams) (SpecDocument, error) {
+	row := q.db.QueryRow(ctx, findSpecDocumentByContentHash,
+		arg.UserID,
+		arg.ContentHash,
+		arg.Language,
+		arg.ModelID,
+	)
+	var i SpecDocument
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
-		&i.Provider,
-		&i.ProviderUserID,
-		&i.ProviderUsername,
-		&i.AccessToken,
-		&i.Scope,
+		&i.AnalysisID,
+		&i.ContentHash,
+		&i.Language,
+		&i.ExecutiveSummary,
+		&i.ModelID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.UserID,
+		&i.RetentionDaysAtCreation,
+		&i.ParentDocumentID,
+		&i.Team,
+		&i.Project,
+		&i.EncryptionKeyID,
+		&i.PromptVersion,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.QualityScore,
+		&i.QualityFlagged,
+		&i.QualityReport,
+		&i.IsDryRun,
+		&i.Generator,
 	)
 	return i, err
 }
 
-const getSystemConfig = `-- name: GetSystemConfig :one
-SELECT value FROM system_config WHERE key = $1
+const findVerifiedBehaviorCachesByHashes = `-- name: FindVerifiedBehaviorCachesByHashes :many
+SELECT cache_key_hash, converted_description
+FROM behavior_caches
+WHERE cache_key_hash = ANY($1::bytea[]) AND human_verified
 `
 
-func (q *Queries) GetSystemConfig(ctx context.Context, key string) (string, error) {
-	row := q.db.QueryRow(ctx, getSystemConfig, key)
-	var value string
-	err := row.Scan(&value)
-	return value, err
+type FindVerifiedBehaviorCachesByHashesRow struct {
+	CacheKeyHash         []byte `json:"cache_key_hash"`
+	ConvertedDescription string `json:"converted_description"`
 }
 
-const getTestCasesBySuiteID = `-- name: GetTestCasesBySuiteID :many
-SELECT id, suite_id, name, line_number, status, tags, modifier FROM test_cases WHERE suite_id = $1 ORDER BY line_number
-`
-
-func (q *Queries) GetTestCasesBySuiteID(ctx context.Context, suiteID pgtype.UUID) ([]TestCase, error) {
-	rows, err := q.db.Query(ctx, getTestCasesBySuiteID, suiteID)
+func (q *Queries) FindVerifiedBehaviorCachesByHashes(ctx context.Context, dollar_1 [][]byte) ([]FindVerifiedBehaviorCachesByHashesRow, error) {
+	rows, err := q.db.Query(ctx, findVerifiedBehaviorCachesByHashes, dollar_1)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []TestCase{}
+	items := []FindVerifiedBehaviorCachesByHashesRow{}
 	for rows.Next() {
-		var i TestCase
-		if err := rows.Scan(
-			&i.ID,
-			&i.SuiteID,
-			&i.Name,
-			&i.LineNumber,
-			&i.Status,
-			&i.Tags,
-			&i.Modifier,
-		); err != nil {
+		var i FindVerifiedBehaviorCachesByHashesRow
+		if err := rows.Scan(&i.CacheKeyHash, &i.ConvertedDescription); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -571,89 +1387,51 @@ func (q *Queries) GetTestCasesBySuiteID(ctx context.Context, suiteID pgtype.UUID
 	return items, nil
 }
 
-const getTestDataByAnalysisID = `-- name: GetTestDataByAnalysisID :many
+const getAIUsageByUser = `-- name: GetAIUsageByUser :many
 SELECT
-    tf.id as file_id,
-    tf.file_path,
-    tf.framework,
-    tf.domain_hints,
-    ts.id as suite_id,
-    ts.parent_id as suite_parent_id,
-    ts.name as suite_name,
-    ts.depth as suite_depth,
-    tc.id as test_case_id,
-    tc.name as test_name
-FROM test_files tf
-JOIN test_suites ts ON ts.file_id = tf.id
-JOIN test_cases tc ON tc.suite_id = ts.id
-WHERE tf.analysis_id = $1
-ORDER BY tf.file_path, ts.depth, ts.name, tc.name
+    user_id,
+    COUNT(DISTINCT job_id)::bigint AS jobs,
+    COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+    COALESCE(SUM(candidates_tokens), 0)::bigint AS candidates_tokens,
+    COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens
+FROM ai_usage
+WHERE user_id IS NOT NULL
+  AND created_at >= $1
+  AND created_at < $2
+GROUP BY user_id
+ORDER BY total_tokens DESC, user_id
+LIMIT $3
 `
 
-type GetTestDataByAnalysisIDRow struct {
-	FileID        pgtype.UUID `json:"file_id"`
-	FilePath      string      `json:"file_path"`
-	Framework     pgtype.Text `json:"framework"`
-	DomainHints   []byte      `json:"domain_hints"`
-	SuiteID       pgtype.UUID `json:"suite_id"`
-	SuiteParentID pgtype.UUID `json:"suite_parent_id"`
-	SuiteName     string      `json:"suite_name"`
-	SuiteDepth    int32       `json:"suite_depth"`
-	TestCaseID    pgtype.UUID `json:"test_case_id"`
-	TestName      string      `json:"test_name"`
+type GetAIUsageByUserParams struct {
+	Since    pgtype.Timestamptz `json:"since"`
+	Until    pgtype.Timestamptz `json:"until"`
+	RowLimit int32              `json:"row_limit"`
 }
 
-func (q *Queries) GetTestDataByAnalysisID(ctx context.Context, analysisID pgtype.UUID) ([]GetTestDataByAnalysisIDRow, error) {
-	rows, err := q.db.Query(ctx, getTestDataByAnalysisID, analysisID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []GetTestDataByAnalysisIDRow{}
-	for rows.Next() {
-		var i GetTestDataByAnalysisIDRow
-		if err := rows.Scan(
-			&i.FileID,
-			&i.FilePath,
-			&i.Framework,
-			&i.DomainHints,
-			&i.SuiteID,
-			&i.SuiteParentID,
-			&i.SuiteName,
-			&i.SuiteDepth,
-			&i.TestCaseID,
-			&i.TestName,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type GetAIUsageByUserRow struct {
+	UserID           pgtype.UUID `json:"user_id"`
+	Jobs             int64       `json:"jobs"`
+	PromptTokens     int64       `json:"prompt_tokens"`
+	CandidatesTokens int64       `json:"candidates_tokens"`
+	TotalTokens      int64       `json:"total_tokens"`
 }
 
-const getTestSuitesByFileID = `-- name: GetTestSuitesByFileID :many
-SELECT id, parent_id, name, line_number, depth, file_id FROM test_suites WHERE file_id = $1 ORDER BY line_number
-`
-
-func (q *Queries) GetTestSuitesByFileID(ctx context.Context, fileID pgtype.UUID) ([]TestSuite, error) {
-	rows, err := q.db.Query(ctx, getTestSuitesByFileID, fileID)
+func (q *Queries) GetAIUsageByUser(ctx context.Context, arg GetAIUsageByUserParams) ([]GetAIUsageByUserRow, error) {
+	rows, err := q.db.Query(ctx, getAIUsageByUser, arg.Since, arg.Until, arg.RowLimit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []TestSuite{}
+	var items []GetAIUsageByUserRow
 	for rows.Next() {
-		var i TestSuite
+		var i GetAIUsageByUserRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.ParentID,
-			&i.Name,
-			&i.LineNumber,
-			&i.Depth,
-			&i.FileID,
+			&i.UserID,
+			&i.Jobs,
+			&i.PromptTokens,
+			&i.CandidatesTokens,
+			&i.TotalTokens,
 		); err != nil {
 			return nil, err
 		}
@@ -665,256 +1443,4384 @@ func (q *Queries) GetTestSuitesByFileID(ctx context.Context, fileID pgtype.UUID)
 	return items, nil
 }
 
-const getUserRetentionDays = `-- name: GetUserRetentionDays :one
-
-SELECT sp.retention_days
-FROM user_subscriptions us
-JOIN subscription_plans sp ON us.plan_id = sp.id
-WHERE us.user_id = $1 AND us.status = 'active'
+const getActiveTenantDocumentKey = `-- name: GetActiveTenantDocumentKey :one
+SELECT id, wrapped_key FROM tenant_document_keys
+WHERE tenant_id = $1 AND retired_at IS NULL
 `
 
-// =============================================================================
-// RETENTION
-// =============================================================================
-// Returns retention_days from user's active subscription plan.
-// NULL means unlimited (enterprise) or no active subscription.
-func (q *Queries) GetUserRetentionDays(ctx context.Context, userID pgtype.UUID) (pgtype.Int4, error) {
-	row := q.db.QueryRow(ctx, getUserRetentionDays, userID)
-	var retention_days pgtype.Int4
-	err := row.Scan(&retention_days)
-	return retention_days, err
+type GetActiveTenantDocumentKeyRow struct {
+	ID         pgtype.UUID `json:"id"`
+	WrappedKey string      `json:"wrapped_key"`
 }
 
-const getUserTier = `-- name: GetUserTier :one
-SELECT sp.tier
-FROM user_subscriptions us
-JOIN subscription_plans sp ON us.plan_id = sp.id
-WHERE us.user_id = $1 AND us.status = 'active'
-`
-
-// Returns tier from user's active subscription plan.
-// Returns no rows if user has no active subscription (caller should default to free).
-func (q *Queries) GetUserTier(ctx context.Context, userID pgtype.UUID) (PlanTier, error) {
-	row := q.db.QueryRow(ctx, getUserTier, userID)
-	var tier PlanTier
-	err := row.Scan(&tier)
-	return tier, err
+func (q *Queries) GetActiveTenantDocumentKey(ctx context.Context, tenantID pgtype.UUID) (GetActiveTenantDocumentKeyRow, error) {
+	row := q.db.QueryRow(ctx, getActiveTenantDocumentKey, tenantID)
+	var i GetActiveTenantDocumentKeyRow
+	err := row.Scan(&i.ID, &i.WrappedKey)
+	return i, err
 }
 
-const insertSpecDocument = `-- name: InsertSpecDocument :one
-INSERT INTO spec_documents (user_id, analysis_id, content_hash, language, executive_summary, model_id, version, retention_days_at_creation)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id
+const getAnalysisContext = `-- name: GetAnalysisContext :one
+SELECT c.host, c.owner, c.name as repo, c.is_private
+FROM analyses a
+JOIN codebases c ON a.codebase_id = c.id
+WHERE a.id = $1
 `
 
-type InsertSpecDocumentParams struct {
-	UserID                  pgtype.UUID `json:"user_id"`
-	AnalysisID              pgtype.UUID `json:"analysis_id"`
-	ContentHash             []byte      `json:"content_hash"`
-	Language                string      `json:"language"`
-	ExecutiveSummary        pgtype.Text `json:"executive_summary"`
-	ModelID                 string      `json:"model_id"`
-	Version                 int32       `json:"version"`
-	RetentionDaysAtCreation pgtype.Int4 `json:"retention_days_at_creation"`
+type GetAnalysisContextRow struct {
+	Host      string `json:"host"`
+	Owner     string `json:"owner"`
+	Repo      string `json:"repo"`
+	IsPrivate bool   `json:"is_private"`
 }
 
-func (q *Queries) InsertSpecDocument(ctx context.Context, arg InsertSpecDocumentParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, insertSpecDocument,
-		arg.UserID,
-		arg.AnalysisID,
-		arg.ContentHash,
-		arg.Language,
-		arg.ExecutiveSummary,
-		arg.ModelID,
-		arg.Version,
-		arg.RetentionDaysAtCreation,
+func (q *Queries) GetAnalysisContext(ctx context.Context, id pgtype.UUID) (GetAnalysisContextRow, error) {
+	row := q.db.QueryRow(ctx, getAnalysisContext, id)
+	var i GetAnalysisContextRow
+	err := row.Scan(
+		&i.Host,
+		&i.Owner,
+		&i.Repo,
+		&i.IsPrivate,
 	)
-	var id pgtype.UUID
-	err := row.Scan(&id)
-	return id, err
+	return i, err
 }
 
-const insertSpecDomain = `-- name: InsertSpecDomain :one
-INSERT INTO spec_domains (document_id, name, description, sort_order, classification_confidence)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id
+const getAnalysisCurationRules = `-- name: GetAnalysisCurationRules :one
+SELECT curation_rules FROM analyses WHERE id = $1
 `
 
-type InsertSpecDomainParams struct {
-	DocumentID               pgtype.UUID    `json:"document_id"`
-	Name                     string         `json:"name"`
-	Description              pgtype.Text    `json:"description"`
-	SortOrder                int32          `json:"sort_order"`
-	ClassificationConfidence pgtype.Numeric `json:"classification_confidence"`
-}
-
-func (q *Queries) InsertSpecDomain(ctx context.Context, arg InsertSpecDomainParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, insertSpecDomain,
-		arg.DocumentID,
-		arg.Name,
-		arg.Description,
-		arg.SortOrder,
-		arg.ClassificationConfidence,
-	)
-	var id pgtype.UUID
-	err := row.Scan(&id)
-	return id, err
+func (q *Queries) GetAnalysisCurationRules(ctx context.Context, id pgtype.UUID) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getAnalysisCurationRules, id)
+	var curation_rules []byte
+	err := row.Scan(&curation_rules)
+	return curation_rules, err
 }
 
-const insertSpecFeature = `-- name: InsertSpecFeature :one
-INSERT INTO spec_features (domain_id, name, description, sort_order)
-VALUES ($1, $2, $3, $4)
-RETURNING id
+const getAnalysisNotificationContext = `-- name: GetAnalysisNotificationContext :one
+SELECT c.id AS codebase_id, c.host, c.owner, c.name, a.id AS analysis_id, a.branch_name, a.total_tests
+FROM codebases c
+LEFT JOIN LATERAL (
+    SELECT id, branch_name, total_tests FROM analyses
+    WHERE codebase_id = c.id AND commit_sha = $1
+    ORDER BY created_at DESC
+    LIMIT 1
+) a ON true
+WHERE c.host = $2 AND c.owner = $3 AND c.name = $4 AND c.is_stale = false
 `
 
-type InsertSpecFeatureParams struct {
-	DomainID    pgtype.UUID `json:"domain_id"`
-	Name        string      `json:"name"`
-	Description pgtype.Text `json:"description"`
-	SortOrder   int32       `json:"sort_order"`
+type GetAnalysisNotificationContextParams struct {
+	CommitSha string `json:"commit_sha"`
+	Host      string `json:"host"`
+	Owner     string `json:"owner"`
+	Name      string `json:"name"`
 }
 
-func (q *Queries) InsertSpecFeature(ctx context.Context, arg InsertSpecFeatureParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, insertSpecFeature,
-		arg.DomainID,
-		arg.Name,
-		arg.Description,
-		arg.SortOrder,
-	)
-	var id pgtype.UUID
-	err := row.Scan(&id)
-	return id, err
+type GetAnalysisNotificationContextRow struct {
+	CodebaseID pgtype.UUID `json:"codebase_id"`
+	Host       string      `json:"host"`
+	Owner      string      `json:"owner"`
+	Name       string      `json:"name"`
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+	BranchName pgtype.Text `json:"branch_name"`
+	TotalTests pgtype.Int4 `json:"total_tests"`
 }
 
-const insertTestFile = `-- name: InsertTestFile :one
-INSERT INTO test_files (analysis_id, file_path, framework, domain_hints)
-VALUES ($1, $2, $3, $4)
-RETURNING id
+// The latest analysis of the commit, if any; a job failing before it
+// created one still resolves its codebase.
+func (q *Queries) GetAnalysisNotificationContext(ctx context.Context, arg GetAnalysisNotificationContextParams) (GetAnalysisNotificationContextRow, error) {
+	row := q.db.QueryRow(ctx, getAnalysisNotificationContext,
+		arg.CommitSha,
+		arg.Host,
+		arg.Owner,
+		arg.Name,
+	)
+	var i GetAnalysisNotificationContextRow
+	err := row.Scan(
+		&i.CodebaseID,
+		&i.Host,
+		&i.Owner,
+		&i.Name,
+		&i.AnalysisID,
+		&i.BranchName,
+		&i.TotalTests,
+	)
+	return i, err
+}
+
+const getBehaviorFeedback = `-- name: GetBehaviorFeedback :one
+
+SELECT bf.id, bf.user_id, bf.corrected_description, bf.status,
+       sb.cache_key_hash, sd.id AS document_id, sd.analysis_id
+FROM behavior_feedback bf
+JOIN spec_behaviors sb ON sb.id = bf.behavior_id
+JOIN spec_features sf ON sf.id = sb.feature_id
+JOIN spec_domains sdm ON sdm.id = sf.domain_id
+JOIN spec_documents sd ON sd.id = sdm.document_id
+WHERE bf.id = $1
 `
 
-type InsertTestFileParams struct {
+type GetBehaviorFeedbackRow struct {
+	ID                   pgtype.UUID `json:"id"`
+	UserID               pgtype.UUID `json:"user_id"`
+	CorrectedDescription string      `json:"corrected_description"`
+	Status               string      `json:"status"`
+	CacheKeyHash         []byte      `json:"cache_key_hash"`
+	DocumentID           pgtype.UUID `json:"document_id"`
+	AnalysisID           pgtype.UUID `json:"analysis_id"`
+}
+
+// =============================================================================
+// BEHAVIOR FEEDBACK
+// =============================================================================
+func (q *Queries) GetBehaviorFeedback(ctx context.Context, id pgtype.UUID) (GetBehaviorFeedbackRow, error) {
+	row := q.db.QueryRow(ctx, getBehaviorFeedback, id)
+	var i GetBehaviorFeedbackRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CorrectedDescription,
+		&i.Status,
+		&i.CacheKeyHash,
+		&i.DocumentID,
+		&i.AnalysisID,
+	)
+	return i, err
+}
+
+const getCodebaseByID = `-- name: GetCodebaseByID :one
+SELECT id, host, owner, name, default_branch, created_at, updated_at, last_viewed_at, external_repo_id, is_stale, is_private, region, fork_dedup_enabled FROM codebases WHERE id = $1
+`
+
+func (q *Queries) GetCodebaseByID(ctx context.Context, id pgtype.UUID) (Codebasis, error) {
+	row := q.db.QueryRow(ctx, getCodebaseByID, id)
+	var i Codebasis
+	err := row.Scan(
+		&i.ID,
+		&i.Host,
+		&i.Owner,
+		&i.Name,
+		&i.DefaultBranch,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastViewedAt,
+		&i.ExternalRepoID,
+		&i.IsStale,
+		&i.IsPrivate,
+		&i.Region,
+		&i.ForkDedupEnabled,
+	)
+	return i, err
+}
+
+const getCodebaseTaxonomy = `-- name: GetCodebaseTaxonomy :one
+
+SELECT t.domains, t.mode
+FROM codebase_taxonomies t
+WHERE t.codebase_id = (SELECT codebase_id FROM analyses WHERE id = $1)
+`
+
+type GetCodebaseTaxonomyRow struct {
+	Domains []byte `json:"domains"`
+	Mode    string `json:"mode"`
+}
+
+// =============================================================================
+// CODEBASE TAXONOMIES
+// =============================================================================
+// Canonical domains of the analysis's codebase; no rows when it has none.
+func (q *Queries) GetCodebaseTaxonomy(ctx context.Context, analysisID pgtype.UUID) (GetCodebaseTaxonomyRow, error) {
+	row := q.db.QueryRow(ctx, getCodebaseTaxonomy, analysisID)
+	var i GetCodebaseTaxonomyRow
+	err := row.Scan(&i.Domains, &i.Mode)
+	return i, err
+}
+
+const getCoverageUpload = `-- name: GetCoverageUpload :one
+
+SELECT id, analysis_id, format, content FROM coverage_uploads WHERE id = $1
+`
+
+type GetCoverageUploadRow struct {
+	ID         pgtype.UUID `json:"id"`
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+	Format     string      `json:"format"`
+	Content    []byte      `json:"content"`
+}
+
+// =============================================================================
+// CODE COVERAGE
+// =============================================================================
+func (q *Queries) GetCoverageUpload(ctx context.Context, id pgtype.UUID) (GetCoverageUploadRow, error) {
+	row := q.db.QueryRow(ctx, getCoverageUpload, id)
+	var i GetCoverageUploadRow
+	err := row.Scan(
+		&i.ID,
+		&i.AnalysisID,
+		&i.Format,
+		&i.Content,
+	)
+	return i, err
+}
+
+const getDailyAIUsage = `-- name: GetDailyAIUsage :many
+SELECT
+    date_trunc('day', created_at, 'UTC')::timestamptz AS day,
+    model,
+    phase,
+    COUNT(DISTINCT job_id)::bigint AS jobs,
+    COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+    COALESCE(SUM(candidates_tokens), 0)::bigint AS candidates_tokens,
+    COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens
+FROM ai_usage
+WHERE created_at >= $1
+  AND created_at < $2
+GROUP BY 1, model, phase
+ORDER BY 1, model, phase
+`
+
+type GetDailyAIUsageParams struct {
+	Since pgtype.Timestamptz `json:"since"`
+	Until pgtype.Timestamptz `json:"until"`
+}
+
+type GetDailyAIUsageRow struct {
+	Day              pgtype.Timestamptz `json:"day"`
+	Model            string             `json:"model"`
+	Phase            string             `json:"phase"`
+	Jobs             int64              `json:"jobs"`
+	PromptTokens     int64              `json:"prompt_tokens"`
+	CandidatesTokens int64              `json:"candidates_tokens"`
+	TotalTokens      int64              `json:"total_tokens"`
+}
+
+func (q *Queries) GetDailyAIUsage(ctx context.Context, arg GetDailyAIUsageParams) ([]GetDailyAIUsageRow, error) {
+	rows, err := q.db.Query(ctx, getDailyAIUsage, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDailyAIUsageRow
+	for rows.Next() {
+		var i GetDailyAIUsageRow
+		if err := rows.Scan(
+			&i.Day,
+			&i.Model,
+			&i.Phase,
+			&i.Jobs,
+			&i.PromptTokens,
+			&i.CandidatesTokens,
+			&i.TotalTokens,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFileTestCounts = `-- name: GetFileTestCounts :many
+SELECT f.file_path, COALESCE(SUM(tc.variant_count), 0)::int AS test_count
+FROM test_files f
+LEFT JOIN test_suites s ON s.file_id = f.id
+LEFT JOIN test_cases tc ON tc.suite_id = s.id
+WHERE f.analysis_id = $1
+GROUP BY f.file_path
+`
+
+type GetFileTestCountsRow struct {
+	FilePath  string `json:"file_path"`
+	TestCount int32  `json:"test_count"`
+}
+
+func (q *Queries) GetFileTestCounts(ctx context.Context, analysisID pgtype.UUID) ([]GetFileTestCountsRow, error) {
+	rows, err := q.db.Query(ctx, getFileTestCounts, analysisID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetFileTestCountsRow{}
+	for rows.Next() {
+		var i GetFileTestCountsRow
+		if err := rows.Scan(&i.FilePath, &i.TestCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getForkReusableFileHashes = `-- name: GetForkReusableFileHashes :many
+SELECT tf.analysis_id, tf.file_path, tf.content_hash
+FROM test_files tf
+WHERE tf.analysis_id = (
+    SELECT a.id FROM analyses a
+    JOIN codebases donor ON donor.id = a.codebase_id
+    JOIN codebases self ON self.id = $1
+    WHERE a.commit_sha = $2
+      AND a.parser_version = $3
+      AND a.status = 'completed'
+      AND a.codebase_id <> self.id
+      AND donor.fork_dedup_enabled
+      AND self.fork_dedup_enabled
+      AND donor.region IS NOT DISTINCT FROM self.region
+    ORDER BY a.completed_at DESC
+    LIMIT 1
+)
+  AND tf.content_hash IS NOT NULL
+`
+
+type GetForkReusableFileHashesParams struct {
+	CodebaseID    pgtype.UUID `json:"codebase_id"`
+	CommitSha     string      `json:"commit_sha"`
+	ParserVersion string      `json:"parser_version"`
+}
+
+type GetForkReusableFileHashesRow struct {
 	AnalysisID  pgtype.UUID `json:"analysis_id"`
 	FilePath    string      `json:"file_path"`
-	Framework   pgtype.Text `json:"framework"`
-	DomainHints []byte      `json:"domain_hints"`
+	ContentHash []byte      `json:"content_hash"`
 }
 
-func (q *Queries) InsertTestFile(ctx context.Context, arg InsertTestFileParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, insertTestFile,
+// Content hashes of the test files of the latest completed analysis of another
+// codebase in the same region at the same commit, typically the repository the
+// codebase was forked from, made with the given parser version. Codebases that
+// opted out of fork deduplication neither reuse nor lend files.
+func (q *Queries) GetForkReusableFileHashes(ctx context.Context, arg GetForkReusableFileHashesParams) ([]GetForkReusableFileHashesRow, error) {
+	rows, err := q.db.Query(ctx, getForkReusableFileHashes, arg.CodebaseID, arg.CommitSha, arg.ParserVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetForkReusableFileHashesRow{}
+	for rows.Next() {
+		var i GetForkReusableFileHashesRow
+		if err := rows.Scan(&i.AnalysisID, &i.FilePath, &i.ContentHash); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGlossaryEntries = `-- name: GetGlossaryEntries :many
+
+SELECT
+    (g.codebase_id IS NOT NULL)::boolean AS is_codebase,
+    g.term,
+    g.preferred
+FROM glossary_entries g
+WHERE g.language = $1
+  AND (
+    g.user_id = $2
+    OR g.codebase_id = (SELECT codebase_id FROM analyses WHERE id = $3)
+  )
+ORDER BY g.term
+`
+
+type GetGlossaryEntriesParams struct {
+	Language   string      `json:"language"`
+	UserID     pgtype.UUID `json:"user_id"`
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+}
+
+type GetGlossaryEntriesRow struct {
+	IsCodebase bool   `json:"is_codebase"`
+	Term       string `json:"term"`
+	Preferred  string `json:"preferred"`
+}
+
+// =============================================================================
+// GLOSSARY
+// =============================================================================
+// Entries of the user and of the analysis's codebase for the language; is_codebase marks the codebase scope.
+func (q *Queries) GetGlossaryEntries(ctx context.Context, arg GetGlossaryEntriesParams) ([]GetGlossaryEntriesRow, error) {
+	rows, err := q.db.Query(ctx, getGlossaryEntries, arg.Language, arg.UserID, arg.AnalysisID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetGlossaryEntriesRow{}
+	for rows.Next() {
+		var i GetGlossaryEntriesRow
+		if err := rows.Scan(&i.IsCodebase, &i.Term, &i.Preferred); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT
+    k.job_kind,
+    k.request_hash,
+    k.job_id,
+    j.state AS job_state,
+    (
+        SELECT e.analysis_id FROM analysis_events e
+        WHERE e.job_id = k.job_id AND e.analysis_id IS NOT NULL
+        ORDER BY e.created_at DESC
+        LIMIT 1
+    )::uuid AS analysis_id
+FROM enqueue_idempotency_keys k
+LEFT JOIN river_job j ON j.id = k.job_id
+WHERE k.idempotency_key = $1 AND k.expires_at > now()
+`
+
+type GetIdempotencyKeyRow struct {
+	JobKind     string            `json:"job_kind"`
+	RequestHash string            `json:"request_hash"`
+	JobID       pgtype.Int8       `json:"job_id"`
+	JobState    NullRiverJobState `json:"job_state"`
+	AnalysisID  pgtype.UUID       `json:"analysis_id"`
+}
+
+// The analysis comes from the job's progress events, so it is known once the
+// job has started.
+func (q *Queries) GetIdempotencyKey(ctx context.Context, idempotencyKey string) (GetIdempotencyKeyRow, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, idempotencyKey)
+	var i GetIdempotencyKeyRow
+	err := row.Scan(
+		&i.JobKind,
+		&i.RequestHash,
+		&i.JobID,
+		&i.JobState,
+		&i.AnalysisID,
+	)
+	return i, err
+}
+
+const getJobCodebase = `-- name: GetJobCodebase :one
+SELECT c.id, c.region
+FROM codebases c
+WHERE c.id = COALESCE(
+    (SELECT a.codebase_id FROM analyses a WHERE a.id = $1::uuid),
+    (SELECT a.codebase_id FROM spec_documents d JOIN analyses a ON a.id = d.analysis_id WHERE d.id = $2::uuid),
+    (SELECT cb.id FROM codebases cb
+     WHERE cb.host = 'github.com' AND cb.owner = $3::text AND cb.name = $4::text AND cb.is_stale = false)
+)
+`
+
+type GetJobCodebaseParams struct {
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+	DocumentID pgtype.UUID `json:"document_id"`
+	Owner      pgtype.Text `json:"owner"`
+	Repo       pgtype.Text `json:"repo"`
+}
+
+type GetJobCodebaseRow struct {
+	ID     pgtype.UUID `json:"id"`
+	Region pgtype.Text `json:"region"`
+}
+
+// Resolves the codebase a job refers to by analysis, spec document or owner/repo, in that order.
+func (q *Queries) GetJobCodebase(ctx context.Context, arg GetJobCodebaseParams) (GetJobCodebaseRow, error) {
+	row := q.db.QueryRow(ctx, getJobCodebase,
 		arg.AnalysisID,
-		arg.FilePath,
-		arg.Framework,
-		arg.DomainHints,
+		arg.DocumentID,
+		arg.Owner,
+		arg.Repo,
 	)
-	var id pgtype.UUID
-	err := row.Scan(&id)
-	return id, err
+	var i GetJobCodebaseRow
+	err := row.Scan(&i.ID, &i.Region)
+	return i, err
 }
 
-const insertTestSuite = `-- name: InsertTestSuite :one
-INSERT INTO test_suites (file_id, parent_id, name, line_number, depth)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id
+const getLatestAnalysisStatusByCommit = `-- name: GetLatestAnalysisStatusByCommit :one
+SELECT a.id, a.status, a.failure_class, a.error_message, a.created_at, a.completed_at
+FROM analyses a
+JOIN codebases c ON c.id = a.codebase_id
+WHERE c.host = $1 AND c.owner = $2 AND c.name = $3 AND c.is_stale = false
+  AND a.commit_sha = $4
+ORDER BY a.created_at DESC
+LIMIT 1
 `
 
-type InsertTestSuiteParams struct {
-	FileID     pgtype.UUID `json:"file_id"`
-	ParentID   pgtype.UUID `json:"parent_id"`
-	Name       string      `json:"name"`
-	LineNumber pgtype.Int4 `json:"line_number"`
-	Depth      int32       `json:"depth"`
+type GetLatestAnalysisStatusByCommitParams struct {
+	Host      string `json:"host"`
+	Owner     string `json:"owner"`
+	Name      string `json:"name"`
+	CommitSha string `json:"commit_sha"`
 }
 
-func (q *Queries) InsertTestSuite(ctx context.Context, arg InsertTestSuiteParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, insertTestSuite,
-		arg.FileID,
-		arg.ParentID,
+type GetLatestAnalysisStatusByCommitRow struct {
+	ID           pgtype.UUID              `json:"id"`
+	Status       AnalysisStatus           `json:"status"`
+	FailureClass NullAnalysisFailureClass `json:"failure_class"`
+	ErrorMessage pgtype.Text              `json:"error_message"`
+	CreatedAt    pgtype.Timestamptz       `json:"created_at"`
+	CompletedAt  pgtype.Timestamptz       `json:"completed_at"`
+}
+
+// Most recent analysis of the commit on any branch, whatever its status.
+func (q *Queries) GetLatestAnalysisStatusByCommit(ctx context.Context, arg GetLatestAnalysisStatusByCommitParams) (GetLatestAnalysisStatusByCommitRow, error) {
+	row := q.db.QueryRow(ctx, getLatestAnalysisStatusByCommit,
+		arg.Host,
+		arg.Owner,
 		arg.Name,
-		arg.LineNumber,
-		arg.Depth,
+		arg.CommitSha,
 	)
-	var id pgtype.UUID
-	err := row.Scan(&id)
-	return id, err
+	var i GetLatestAnalysisStatusByCommitRow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.FailureClass,
+		&i.ErrorMessage,
+		&i.CreatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
 }
 
-const markCodebaseStale = `-- name: MarkCodebaseStale :exec
-UPDATE codebases SET is_stale = true, updated_at = now() WHERE id = $1
+const getLatestCodebasePathFilters = `-- name: GetLatestCodebasePathFilters :one
+SELECT path_filters FROM analyses
+WHERE codebase_id = $1
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+// Path filters of the most recent analysis of the codebase, whatever its status.
+func (q *Queries) GetLatestCodebasePathFilters(ctx context.Context, codebaseID pgtype.UUID) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getLatestCodebasePathFilters, codebaseID)
+	var path_filters []byte
+	err := row.Scan(&path_filters)
+	return path_filters, err
+}
+
+const getLatestDocumentOutline = `-- name: GetLatestDocumentOutline :many
+SELECT
+    dom.slug AS domain_slug,
+    dom.name AS domain_name,
+    sf.slug AS feature_slug,
+    sf.name AS feature_name,
+    COALESCE(sb.original_name, '')::text AS original_name
+FROM spec_domains dom
+JOIN spec_features sf ON sf.domain_id = dom.id
+LEFT JOIN spec_behaviors sb ON sb.feature_id = sf.id
+WHERE dom.document_id = (
+    SELECT sd.id
+    FROM spec_documents sd
+    JOIN analyses a ON a.id = sd.analysis_id
+    WHERE sd.user_id = $1
+      AND sd.language = $2
+      AND sd.parent_document_id IS NULL
+      AND sd.project IS NULL
+      AND sd.deleted_at IS NULL
+      AND a.codebase_id = (SELECT codebase_id FROM analyses WHERE id = $3)
+    ORDER BY sd.created_at DESC, sd.version DESC
+    LIMIT 1
+)
+ORDER BY dom.sort_order, sf.sort_order, sb.sort_order
+`
+
+type GetLatestDocumentOutlineParams struct {
+	UserID     pgtype.UUID `json:"user_id"`
+	Language   string      `json:"language"`
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+}
+
+type GetLatestDocumentOutlineRow struct {
+	DomainSlug   string `json:"domain_slug"`
+	DomainName   string `json:"domain_name"`
+	FeatureSlug  string `json:"feature_slug"`
+	FeatureName  string `json:"feature_name"`
+	OriginalName string `json:"original_name"`
+}
+
+// Outline of the user's latest document for the codebase of the given analysis, in any version.
+func (q *Queries) GetLatestDocumentOutline(ctx context.Context, arg GetLatestDocumentOutlineParams) ([]GetLatestDocumentOutlineRow, error) {
+	rows, err := q.db.Query(ctx, getLatestDocumentOutline, arg.UserID, arg.Language, arg.AnalysisID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetLatestDocumentOutlineRow{}
+	for rows.Next() {
+		var i GetLatestDocumentOutlineRow
+		if err := rows.Scan(
+			&i.DomainSlug,
+			&i.DomainName,
+			&i.FeatureSlug,
+			&i.FeatureName,
+			&i.OriginalName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMappedTestFilePathsByDocumentID = `-- name: GetMappedTestFilePathsByDocumentID :many
+SELECT DISTINCT tf.file_path
+FROM spec_domains dom
+JOIN spec_features f ON f.domain_id = dom.id
+JOIN spec_behaviors b ON b.feature_id = f.id
+JOIN test_cases tc ON tc.id = b.source_test_case_id
+    OR tc.id IN (SELECT bt.test_case_id FROM spec_behavior_test_cases bt WHERE bt.behavior_id = b.id)
+JOIN test_suites ts ON ts.id = tc.suite_id
+JOIN test_files tf ON tf.id = ts.file_id
+WHERE dom.document_id = $1
+ORDER BY tf.file_path
+`
+
+func (q *Queries) GetMappedTestFilePathsByDocumentID(ctx context.Context, documentID pgtype.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, getMappedTestFilePathsByDocumentID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var file_path string
+		if err := rows.Scan(&file_path); err != nil {
+			return nil, err
+		}
+		items = append(items, file_path)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMaxVersionByUserAnalysisAndLanguage = `-- name: GetMaxVersionByUserAnalysisAndLanguage :one
+
+SELECT COALESCE(MAX(version), 0)::int as max_version
+FROM spec_documents
+WHERE user_id = $1 AND analysis_id = $2 AND language = $3 AND parent_document_id IS NULL
+  AND project IS NOT DISTINCT FROM $4
+`
+
+type GetMaxVersionByUserAnalysisAndLanguageParams struct {
+	UserID     pgtype.UUID `json:"user_id"`
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+	Language   string      `json:"language"`
+	Project    pgtype.Text `json:"project"`
+}
+
+// =============================================================================
+// SPEC DOCUMENTS
+// =============================================================================
+func (q *Queries) GetMaxVersionByUserAnalysisAndLanguage(ctx context.Context, arg GetMaxVersionByUserAnalysisAndLanguageParams) (int32, error) {
+	row := q.db.QueryRow(ctx, getMaxVersionByUserAnalysisAndLanguage,
+		arg.UserID,
+		arg.AnalysisID,
+		arg.Language,
+		arg.Project,
+	)
+	var max_version int32
+	err := row.Scan(&max_version)
+	return max_version, err
+}
+
+const getMonthlySpecViewUsage = `-- name: GetMonthlySpecViewUsage :one
+SELECT COALESCE(SUM(quota_amount), 0)::int as total
+FROM usage_events
+WHERE user_id = $1
+  AND event_type = 'specview'
+  AND created_at >= date_trunc('month', CURRENT_DATE)
+`
+
+func (q *Queries) GetMonthlySpecViewUsage(ctx context.Context, userID pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, getMonthlySpecViewUsage, userID)
+	var total int32
+	err := row.Scan(&total)
+	return total, err
+}
+
+const getNotificationChannel = `-- name: GetNotificationChannel :one
+SELECT id, codebase_id, user_id, kind, url, on_success, on_failure, created_at FROM notification_channels WHERE id = $1
+`
+
+func (q *Queries) GetNotificationChannel(ctx context.Context, id pgtype.UUID) (NotificationChannel, error) {
+	row := q.db.QueryRow(ctx, getNotificationChannel, id)
+	var i NotificationChannel
+	err := row.Scan(
+		&i.ID,
+		&i.CodebaseID,
+		&i.UserID,
+		&i.Kind,
+		&i.Url,
+		&i.OnSuccess,
+		&i.OnFailure,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOAuthAccountByUserAndProvider = `-- name: GetOAuthAccountByUserAndProvider :one
+SELECT id, user_id, provider, provider_user_id, provider_username, access_token, scope, created_at, updated_at FROM oauth_accounts WHERE user_id = $1 AND provider = $2
+`
+
+type GetOAuthAccountByUserAndProviderParams struct {
+	UserID   pgtype.UUID   `json:"user_id"`
+	Provider OauthProvider `json:"provider"`
+}
+
+func (q *Queries) GetOAuthAccountByUserAndProvider(ctx context.Context, arg GetOAuthAccountByUserAndProviderParams) (OauthAccount, error) {
+	row := q.db.QueryRow(ctx, getOAuthAccountByUserAndProvider, arg.UserID, arg.Provider)
+	var i OauthAccount
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.ProviderUserID,
+		&i.ProviderUsername,
+		&i.AccessToken,
+		&i.Scope,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getPhase2ChildStatus = `-- name: GetPhase2ChildStatus :one
+SELECT
+    count(*) FILTER (WHERE state NOT IN ('completed', 'cancelled', 'discarded'))::int AS active,
+    count(*) FILTER (WHERE state = 'completed')::int AS completed,
+    count(*) FILTER (WHERE state IN ('cancelled', 'discarded'))::int AS failed,
+    COALESCE(sum((args ->> 'uncached_tests')::int) FILTER (WHERE state = 'completed'), 0)::int AS converted_tests
+FROM river_job
+WHERE kind = 'specview:phase2_domain'
+  AND args @> jsonb_build_object('parent_job_id', $1::bigint)
+`
+
+type GetPhase2ChildStatusRow struct {
+	Active         int32 `json:"active"`
+	Completed      int32 `json:"completed"`
+	Failed         int32 `json:"failed"`
+	ConvertedTests int32 `json:"converted_tests"`
+}
+
+// Counts the Phase 2 domain jobs dispatched by a parent spec-view job.
+func (q *Queries) GetPhase2ChildStatus(ctx context.Context, parentJobID int64) (GetPhase2ChildStatusRow, error) {
+	row := q.db.QueryRow(ctx, getPhase2ChildStatus, parentJobID)
+	var i GetPhase2ChildStatusRow
+	err := row.Scan(
+		&i.Active,
+		&i.Completed,
+		&i.Failed,
+		&i.ConvertedTests,
+	)
+	return i, err
+}
+
+const getQueueIdleState = `-- name: GetQueueIdleState :one
+SELECT
+    count(*) FILTER (WHERE state IN ('available', 'running'))::bigint AS active,
+    min(scheduled_at) FILTER (WHERE state IN ('scheduled', 'retryable'))::timestamptz AS next_scheduled_at
+FROM river_job
+WHERE queue = ANY($1::text[])
+`
+
+type GetQueueIdleStateRow struct {
+	Active          int64              `json:"active"`
+	NextScheduledAt pgtype.Timestamptz `json:"next_scheduled_at"`
+}
+
+// Jobs of the queues that are workable now, and the earliest time a scheduled
+// job becomes workable. Snoozed jobs are scheduled until their snooze ends.
+func (q *Queries) GetQueueIdleState(ctx context.Context, queues []string) (GetQueueIdleStateRow, error) {
+	row := q.db.QueryRow(ctx, getQueueIdleState, queues)
+	var i GetQueueIdleStateRow
+	err := row.Scan(&i.Active, &i.NextScheduledAt)
+	return i, err
+}
+
+const getRegenCampaign = `-- name: GetRegenCampaign :one
+SELECT
+    c.id, c.name, c.status, c.rate_per_minute, c.max_in_flight, c.selection, c.created_at, c.updated_at,
+    COUNT(t.id) FILTER (WHERE t.status = 'pending')::int AS pending,
+    COUNT(t.id) FILTER (WHERE t.status = 'enqueued')::int AS enqueued,
+    COUNT(t.id) FILTER (WHERE t.status = 'succeeded')::int AS succeeded,
+    COUNT(t.id) FILTER (WHERE t.status = 'failed')::int AS failed
+FROM regen_campaigns c
+LEFT JOIN regen_campaign_targets t ON t.campaign_id = c.id
+WHERE c.id = $1
+GROUP BY c.id
+`
+
+type GetRegenCampaignRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	Name          string             `json:"name"`
+	Status        string             `json:"status"`
+	RatePerMinute int32              `json:"rate_per_minute"`
+	MaxInFlight   int32              `json:"max_in_flight"`
+	Selection     []byte             `json:"selection"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	Pending       int32              `json:"pending"`
+	Enqueued      int32              `json:"enqueued"`
+	Succeeded     int32              `json:"succeeded"`
+	Failed        int32              `json:"failed"`
+}
+
+func (q *Queries) GetRegenCampaign(ctx context.Context, id pgtype.UUID) (GetRegenCampaignRow, error) {
+	row := q.db.QueryRow(ctx, getRegenCampaign, id)
+	var i GetRegenCampaignRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Status,
+		&i.RatePerMinute,
+		&i.MaxInFlight,
+		&i.Selection,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Pending,
+		&i.Enqueued,
+		&i.Succeeded,
+		&i.Failed,
+	)
+	return i, err
+}
+
+const getRequirementSetByID = `-- name: GetRequirementSetByID :one
+SELECT id, codebase_id, name, source_format, created_at FROM requirement_sets WHERE id = $1
+`
+
+func (q *Queries) GetRequirementSetByID(ctx context.Context, id pgtype.UUID) (RequirementSet, error) {
+	row := q.db.QueryRow(ctx, getRequirementSetByID, id)
+	var i RequirementSet
+	err := row.Scan(
+		&i.ID,
+		&i.CodebaseID,
+		&i.Name,
+		&i.SourceFormat,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getRequirementsBySetID = `-- name: GetRequirementsBySetID :many
+SELECT id, external_key, title, description
+FROM requirements
+WHERE requirement_set_id = $1
+ORDER BY sort_order
+`
+
+type GetRequirementsBySetIDRow struct {
+	ID          pgtype.UUID `json:"id"`
+	ExternalKey string      `json:"external_key"`
+	Title       string      `json:"title"`
+	Description pgtype.Text `json:"description"`
+}
+
+func (q *Queries) GetRequirementsBySetID(ctx context.Context, requirementSetID pgtype.UUID) ([]GetRequirementsBySetIDRow, error) {
+	rows, err := q.db.Query(ctx, getRequirementsBySetID, requirementSetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetRequirementsBySetIDRow{}
+	for rows.Next() {
+		var i GetRequirementsBySetIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ExternalKey,
+			&i.Title,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReusableFileHashes = `-- name: GetReusableFileHashes :many
+SELECT tf.analysis_id, tf.file_path, tf.content_hash
+FROM test_files tf
+WHERE tf.analysis_id = (
+    SELECT a.id FROM analyses a
+    WHERE a.codebase_id = $1
+      AND a.status = 'completed'
+      AND a.parser_version = $2
+    ORDER BY a.completed_at DESC
+    LIMIT 1
+)
+  AND tf.content_hash IS NOT NULL
+`
+
+type GetReusableFileHashesParams struct {
+	CodebaseID    pgtype.UUID `json:"codebase_id"`
+	ParserVersion string      `json:"parser_version"`
+}
+
+type GetReusableFileHashesRow struct {
+	AnalysisID  pgtype.UUID `json:"analysis_id"`
+	FilePath    string      `json:"file_path"`
+	ContentHash []byte      `json:"content_hash"`
+}
+
+// Content hashes of the test files of the codebase's latest completed analysis
+// made with the given parser version. Files parsed by another version are never reused.
+func (q *Queries) GetReusableFileHashes(ctx context.Context, arg GetReusableFileHashesParams) ([]GetReusableFileHashesRow, error) {
+	rows, err := q.db.Query(ctx, getReusableFileHashes, arg.CodebaseID, arg.ParserVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetReusableFileHashesRow{}
+	for rows.Next() {
+		var i GetReusableFileHashesRow
+		if err := rows.Scan(&i.AnalysisID, &i.FilePath, &i.ContentHash); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRiverJob = `-- name: GetRiverJob :one
+SELECT id, state, attempt, max_attempts, attempted_at, created_at, finalized_at, scheduled_at, priority, args, attempted_by, errors, kind, metadata, queue, tags, unique_key, unique_states FROM river_job WHERE id = $1
+`
+
+func (q *Queries) GetRiverJob(ctx context.Context, id int64) (RiverJob, error) {
+	row := q.db.QueryRow(ctx, getRiverJob, id)
+	var i RiverJob
+	err := row.Scan(
+		&i.ID,
+		&i.State,
+		&i.Attempt,
+		&i.MaxAttempts,
+		&i.AttemptedAt,
+		&i.CreatedAt,
+		&i.FinalizedAt,
+		&i.ScheduledAt,
+		&i.Priority,
+		&i.Args,
+		&i.AttemptedBy,
+		&i.Errors,
+		&i.Kind,
+		&i.Metadata,
+		&i.Queue,
+		&i.Tags,
+		&i.UniqueKey,
+		&i.UniqueStates,
+	)
+	return i, err
+}
+
+const getServiceTokenByHash = `-- name: GetServiceTokenByHash :one
+SELECT id, name, token_hash, token_prefix, scopes, created_at, expires_at, last_used_at, revoked_at FROM service_tokens
+WHERE token_hash = $1
+`
+
+func (q *Queries) GetServiceTokenByHash(ctx context.Context, tokenHash []byte) (ServiceToken, error) {
+	row := q.db.QueryRow(ctx, getServiceTokenByHash, tokenHash)
+	var i ServiceToken
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TokenHash,
+		&i.TokenPrefix,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getSharedHelperTestCases = `-- name: GetSharedHelperTestCases :many
+
+SELECT h.helper_path, cardinality(h.test_files)::int AS test_file_count, tc.id AS test_case_id
+FROM analysis_shared_helpers h
+JOIN test_files tf ON tf.analysis_id = h.analysis_id AND tf.file_path = ANY(h.test_files)
+JOIN test_suites ts ON ts.file_id = tf.id
+JOIN test_cases tc ON tc.suite_id = ts.id
+WHERE h.analysis_id = $1
+ORDER BY cardinality(h.test_files) DESC, h.helper_path
+`
+
+type GetSharedHelperTestCasesRow struct {
+	HelperPath    string      `json:"helper_path"`
+	TestFileCount int32       `json:"test_file_count"`
+	TestCaseID    pgtype.UUID `json:"test_case_id"`
+}
+
+// =============================================================================
+// SHARED TEST HELPERS
+// =============================================================================
+// One row per test case of a test file importing the helper, most referenced helpers first.
+func (q *Queries) GetSharedHelperTestCases(ctx context.Context, analysisID pgtype.UUID) ([]GetSharedHelperTestCasesRow, error) {
+	rows, err := q.db.Query(ctx, getSharedHelperTestCases, analysisID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSharedHelperTestCasesRow{}
+	for rows.Next() {
+		var i GetSharedHelperTestCasesRow
+		if err := rows.Scan(&i.HelperPath, &i.TestFileCount, &i.TestCaseID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSourceFilePathsByDocumentID = `-- name: GetSourceFilePathsByDocumentID :many
+SELECT sf.file_path
+FROM analysis_source_files sf
+JOIN spec_documents sd ON sd.analysis_id = sf.analysis_id
+WHERE sd.id = $1
+ORDER BY sf.file_path
+`
+
+func (q *Queries) GetSourceFilePathsByDocumentID(ctx context.Context, documentID pgtype.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, getSourceFilePathsByDocumentID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var file_path string
+		if err := rows.Scan(&file_path); err != nil {
+			return nil, err
+		}
+		items = append(items, file_path)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSpecDocumentBehaviorTexts = `-- name: GetSpecDocumentBehaviorTexts :many
+SELECT sb.id, sb.converted_description, false AS is_merge
+FROM spec_domains dom
+JOIN spec_features sf ON sf.domain_id = dom.id
+JOIN spec_behaviors sb ON sb.feature_id = sf.id
+WHERE dom.document_id = $1
+UNION ALL
+SELECT mg.id, mg.converted_description, true AS is_merge
+FROM spec_domains dom
+JOIN spec_features sf ON sf.domain_id = dom.id
+JOIN spec_behaviors sb ON sb.feature_id = sf.id
+JOIN spec_behavior_merges mg ON mg.behavior_id = sb.id
+WHERE dom.document_id = $1
+`
+
+type GetSpecDocumentBehaviorTextsRow struct {
+	ID                   pgtype.UUID `json:"id"`
+	ConvertedDescription string      `json:"converted_description"`
+	IsMerge              bool        `json:"is_merge"`
+}
+
+// Behavior and merged test descriptions of the document; is_merge marks spec_behavior_merges rows.
+func (q *Queries) GetSpecDocumentBehaviorTexts(ctx context.Context, documentID pgtype.UUID) ([]GetSpecDocumentBehaviorTextsRow, error) {
+	rows, err := q.db.Query(ctx, getSpecDocumentBehaviorTexts, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSpecDocumentBehaviorTextsRow{}
+	for rows.Next() {
+		var i GetSpecDocumentBehaviorTextsRow
+		if err := rows.Scan(&i.ID, &i.ConvertedDescription, &i.IsMerge); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSpecDocumentByID = `-- name: GetSpecDocumentByID :one
+
+SELECT sd.id, sd.analysis_id, sd.content_hash, sd.language, sd.executive_summary, sd.model_id, sd.created_at, sd.updated_at, sd.version, sd.user_id, sd.retention_days_at_creation, sd.parent_document_id, sd.team, sd.project, sd.encryption_key_id, sd.prompt_version, sd.deleted_at, sd.deleted_by, sd.quality_score, sd.quality_flagged, sd.quality_report, sd.is_dry_run, sd.generator FROM spec_documents sd
+LEFT JOIN spec_documents parent ON parent.id = sd.parent_document_id
+WHERE sd.id = $1
+  AND sd.deleted_at IS NULL
+  AND parent.deleted_at IS NULL
+`
+
+// =============================================================================
+// SPEC DOCUMENT EXPORTS
+// =============================================================================
+// Deleted documents and the team slices of deleted documents are not found.
+func (q *Queries) GetSpecDocumentByID(ctx context.Context, id pgtype.UUID) (SpecDocument, error) {
+	row := q.db.QueryRow(ctx, getSpecDocumentByID, id)
+	var i SpecDocument
+	err := row.Scan(
+		&i.ID,
+		&i.AnalysisID,
+		&i.ContentHash,
+		&i.Language,
+		&i.ExecutiveSummary,
+		&i.ModelID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.UserID,
+		&i.RetentionDaysAtCreation,
+		&i.ParentDocumentID,
+		&i.Team,
+		&i.Project,
+		&i.EncryptionKeyID,
+		&i.PromptVersion,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.QualityScore,
+		&i.QualityFlagged,
+		&i.QualityReport,
+		&i.IsDryRun,
+		&i.Generator,
+	)
+	return i, err
+}
+
+const getSpecDocumentContent = `-- name: GetSpecDocumentContent :many
+SELECT
+    dom.id AS domain_id,
+    dom.name AS domain_name,
+    dom.description AS domain_description,
+    dom.classification_confidence,
+    dom.slug AS domain_slug,
+    sf.id AS feature_id,
+    sf.name AS feature_name,
+    sf.description AS feature_description,
+    sf.slug AS feature_slug,
+    sb.id AS behavior_id,
+    sb.original_name,
+    sb.converted_description,
+    sb.source_test_case_id,
+    ARRAY(
+        SELECT bt.test_case_id FROM spec_behavior_test_cases bt
+        WHERE bt.behavior_id = sb.id
+        ORDER BY bt.sort_order
+    )::uuid[] AS test_case_ids
+FROM spec_domains dom
+JOIN spec_features sf ON sf.domain_id = dom.id
+LEFT JOIN spec_behaviors sb ON sb.feature_id = sf.id
+WHERE dom.document_id = $1
+ORDER BY dom.sort_order, sf.sort_order, sb.sort_order
+`
+
+type GetSpecDocumentContentRow struct {
+	DomainID                 pgtype.UUID    `json:"domain_id"`
+	DomainName               string         `json:"domain_name"`
+	DomainDescription        pgtype.Text    `json:"domain_description"`
+	ClassificationConfidence pgtype.Numeric `json:"classification_confidence"`
+	DomainSlug               string         `json:"domain_slug"`
+	FeatureID                pgtype.UUID    `json:"feature_id"`
+	FeatureName              string         `json:"feature_name"`
+	FeatureDescription       pgtype.Text    `json:"feature_description"`
+	FeatureSlug              string         `json:"feature_slug"`
+	BehaviorID               pgtype.UUID    `json:"behavior_id"`
+	OriginalName             pgtype.Text    `json:"original_name"`
+	ConvertedDescription     pgtype.Text    `json:"converted_description"`
+	SourceTestCaseID         pgtype.UUID    `json:"source_test_case_id"`
+	TestCaseIds              []pgtype.UUID  `json:"test_case_ids"`
+}
+
+func (q *Queries) GetSpecDocumentContent(ctx context.Context, documentID pgtype.UUID) ([]GetSpecDocumentContentRow, error) {
+	rows, err := q.db.Query(ctx, getSpecDocumentContent, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSpecDocumentContentRow{}
+	for rows.Next() {
+		var i GetSpecDocumentContentRow
+		if err := rows.Scan(
+			&i.DomainID,
+			&i.DomainName,
+			&i.DomainDescription,
+			&i.ClassificationConfidence,
+			&i.DomainSlug,
+			&i.FeatureID,
+			&i.FeatureName,
+			&i.FeatureDescription,
+			&i.FeatureSlug,
+			&i.BehaviorID,
+			&i.OriginalName,
+			&i.ConvertedDescription,
+			&i.SourceTestCaseID,
+			&i.TestCaseIds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSpecDocumentNotificationContext = `-- name: GetSpecDocumentNotificationContext :one
+SELECT c.id AS codebase_id, c.host, c.owner, c.name, a.commit_sha, a.branch_name,
+    (SELECT count(*) FROM spec_domains WHERE document_id = $1::uuid) AS domain_count
+FROM analyses a
+JOIN codebases c ON c.id = a.codebase_id
+WHERE a.id = $2
+`
+
+type GetSpecDocumentNotificationContextParams struct {
+	DocumentID pgtype.UUID `json:"document_id"`
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+}
+
+type GetSpecDocumentNotificationContextRow struct {
+	CodebaseID  pgtype.UUID `json:"codebase_id"`
+	Host        string      `json:"host"`
+	Owner       string      `json:"owner"`
+	Name        string      `json:"name"`
+	CommitSha   string      `json:"commit_sha"`
+	BranchName  pgtype.Text `json:"branch_name"`
+	DomainCount int64       `json:"domain_count"`
+}
+
+func (q *Queries) GetSpecDocumentNotificationContext(ctx context.Context, arg GetSpecDocumentNotificationContextParams) (GetSpecDocumentNotificationContextRow, error) {
+	row := q.db.QueryRow(ctx, getSpecDocumentNotificationContext, arg.DocumentID, arg.AnalysisID)
+	var i GetSpecDocumentNotificationContextRow
+	err := row.Scan(
+		&i.CodebaseID,
+		&i.Host,
+		&i.Owner,
+		&i.Name,
+		&i.CommitSha,
+		&i.BranchName,
+		&i.DomainCount,
+	)
+	return i, err
+}
+
+const getSpecDocumentPin = `-- name: GetSpecDocumentPin :one
+SELECT document_id, release_tag, pinned_by, pinned_at FROM spec_document_pins WHERE document_id = $1
+`
+
+func (q *Queries) GetSpecDocumentPin(ctx context.Context, documentID pgtype.UUID) (SpecDocumentPin, error) {
+	row := q.db.QueryRow(ctx, getSpecDocumentPin, documentID)
+	var i SpecDocumentPin
+	err := row.Scan(
+		&i.DocumentID,
+		&i.ReleaseTag,
+		&i.PinnedBy,
+		&i.PinnedAt,
+	)
+	return i, err
+}
+
+const getSpecGenerationCheckpoint = `-- name: GetSpecGenerationCheckpoint :one
+SELECT job_id, analysis_id, content_hash, phase1_output, created_at, updated_at
+FROM spec_generation_checkpoints
+WHERE job_id = $1
+`
+
+func (q *Queries) GetSpecGenerationCheckpoint(ctx context.Context, jobID int64) (SpecGenerationCheckpoint, error) {
+	row := q.db.QueryRow(ctx, getSpecGenerationCheckpoint, jobID)
+	var i SpecGenerationCheckpoint
+	err := row.Scan(
+		&i.JobID,
+		&i.AnalysisID,
+		&i.ContentHash,
+		&i.Phase1Output,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getSpecSearchSourceByDocumentID = `-- name: GetSpecSearchSourceByDocumentID :many
+SELECT
+    sd.id as document_id,
+    a.codebase_id,
+    sd.version as document_version,
+    sd.language,
+    sb.id as behavior_id,
+    dom.name as domain_name,
+    sf.name as feature_name,
+    sb.converted_description as behavior_description
+FROM spec_documents sd
+JOIN analyses a ON a.id = sd.analysis_id
+JOIN spec_domains dom ON dom.document_id = sd.id
+JOIN spec_features sf ON sf.domain_id = dom.id
+JOIN spec_behaviors sb ON sb.feature_id = sf.id
+WHERE sd.id = $1
+  AND sd.encryption_key_id IS NULL
+  AND sd.deleted_at IS NULL
+ORDER BY dom.sort_order, sf.sort_order, sb.sort_order
+`
+
+type GetSpecSearchSourceByDocumentIDRow struct {
+	DocumentID          pgtype.UUID `json:"document_id"`
+	CodebaseID          pgtype.UUID `json:"codebase_id"`
+	DocumentVersion     int32       `json:"document_version"`
+	Language            string      `json:"language"`
+	BehaviorID          pgtype.UUID `json:"behavior_id"`
+	DomainName          string      `json:"domain_name"`
+	FeatureName         string      `json:"feature_name"`
+	BehaviorDescription string      `json:"behavior_description"`
+}
+
+// Encrypted and deleted documents are not indexed.
+func (q *Queries) GetSpecSearchSourceByDocumentID(ctx context.Context, id pgtype.UUID) ([]GetSpecSearchSourceByDocumentIDRow, error) {
+	rows, err := q.db.Query(ctx, getSpecSearchSourceByDocumentID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSpecSearchSourceByDocumentIDRow{}
+	for rows.Next() {
+		var i GetSpecSearchSourceByDocumentIDRow
+		if err := rows.Scan(
+			&i.DocumentID,
+			&i.CodebaseID,
+			&i.DocumentVersion,
+			&i.Language,
+			&i.BehaviorID,
+			&i.DomainName,
+			&i.FeatureName,
+			&i.BehaviorDescription,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSystemConfig = `-- name: GetSystemConfig :one
+SELECT value FROM system_config WHERE key = $1
+`
+
+func (q *Queries) GetSystemConfig(ctx context.Context, key string) (string, error) {
+	row := q.db.QueryRow(ctx, getSystemConfig, key)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const getTenantAIBudgetByUser = `-- name: GetTenantAIBudgetByUser :one
+
+SELECT b.tenant_id, b.monthly_token_limit, b.monthly_behavior_limit, b.downgrade_model,
+    (SELECT COALESCE(SUM(u.tokens), 0)
+     FROM tenant_ai_usage u
+     WHERE u.tenant_id = b.tenant_id
+       AND u.created_at >= date_trunc('month', CURRENT_DATE))::bigint AS tokens_used,
+    (SELECT COALESCE(SUM(e.quota_amount), 0)
+     FROM usage_events e
+     JOIN tenant_members tm ON tm.user_id = e.user_id
+     WHERE tm.tenant_id = b.tenant_id
+       AND e.event_type = 'specview'
+       AND e.created_at >= date_trunc('month', CURRENT_DATE))::bigint AS behaviors_used
+FROM tenant_ai_budgets b
+JOIN tenant_members m ON m.tenant_id = b.tenant_id
+WHERE m.user_id = $1
+`
+
+type GetTenantAIBudgetByUserRow struct {
+	TenantID             pgtype.UUID `json:"tenant_id"`
+	MonthlyTokenLimit    pgtype.Int8 `json:"monthly_token_limit"`
+	MonthlyBehaviorLimit pgtype.Int8 `json:"monthly_behavior_limit"`
+	DowngradeModel       pgtype.Text `json:"downgrade_model"`
+	TokensUsed           int64       `json:"tokens_used"`
+	BehaviorsUsed        int64       `json:"behaviors_used"`
+}
+
+// =============================================================================
+// TENANT AI BUDGETS
+// =============================================================================
+// Budget of the tenant the user belongs to, with this month's spend.
+// Behaviors are the specview quota of all tenant members.
+func (q *Queries) GetTenantAIBudgetByUser(ctx context.Context, userID pgtype.UUID) (GetTenantAIBudgetByUserRow, error) {
+	row := q.db.QueryRow(ctx, getTenantAIBudgetByUser, userID)
+	var i GetTenantAIBudgetByUserRow
+	err := row.Scan(
+		&i.TenantID,
+		&i.MonthlyTokenLimit,
+		&i.MonthlyBehaviorLimit,
+		&i.DowngradeModel,
+		&i.TokensUsed,
+		&i.BehaviorsUsed,
+	)
+	return i, err
+}
+
+const getTenantDocumentEncryption = `-- name: GetTenantDocumentEncryption :one
+SELECT document_encryption FROM tenants WHERE id = $1
+`
+
+func (q *Queries) GetTenantDocumentEncryption(ctx context.Context, id pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, getTenantDocumentEncryption, id)
+	var document_encryption bool
+	err := row.Scan(&document_encryption)
+	return document_encryption, err
+}
+
+const getTenantDocumentKey = `-- name: GetTenantDocumentKey :one
+SELECT wrapped_key FROM tenant_document_keys WHERE id = $1
+`
+
+func (q *Queries) GetTenantDocumentKey(ctx context.Context, id pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getTenantDocumentKey, id)
+	var wrapped_key string
+	err := row.Scan(&wrapped_key)
+	return wrapped_key, err
+}
+
+const getTenantTakeout = `-- name: GetTenantTakeout :one
+SELECT t.id, t.tenant_id, t.status, tn.name AS tenant_name
+FROM tenant_takeouts t
+JOIN tenants tn ON tn.id = t.tenant_id
+WHERE t.id = $1
+`
+
+type GetTenantTakeoutRow struct {
+	ID         pgtype.UUID `json:"id"`
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	Status     string      `json:"status"`
+	TenantName string      `json:"tenant_name"`
+}
+
+func (q *Queries) GetTenantTakeout(ctx context.Context, id pgtype.UUID) (GetTenantTakeoutRow, error) {
+	row := q.db.QueryRow(ctx, getTenantTakeout, id)
+	var i GetTenantTakeoutRow
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Status,
+		&i.TenantName,
+	)
+	return i, err
+}
+
+const getTestCasesByFileIDs = `-- name: GetTestCasesByFileIDs :many
+SELECT tc.suite_id, tc.name, tc.line_number, tc.status, tc.variant_count
+FROM test_cases tc
+JOIN test_suites s ON s.id = tc.suite_id
+WHERE s.file_id = ANY($1::uuid[])
+ORDER BY tc.line_number, tc.name
+`
+
+type GetTestCasesByFileIDsRow struct {
+	SuiteID      pgtype.UUID `json:"suite_id"`
+	Name         string      `json:"name"`
+	LineNumber   pgtype.Int4 `json:"line_number"`
+	Status       TestStatus  `json:"status"`
+	VariantCount int32       `json:"variant_count"`
+}
+
+func (q *Queries) GetTestCasesByFileIDs(ctx context.Context, fileIds []pgtype.UUID) ([]GetTestCasesByFileIDsRow, error) {
+	rows, err := q.db.Query(ctx, getTestCasesByFileIDs, fileIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTestCasesByFileIDsRow{}
+	for rows.Next() {
+		var i GetTestCasesByFileIDsRow
+		if err := rows.Scan(
+			&i.SuiteID,
+			&i.Name,
+			&i.LineNumber,
+			&i.Status,
+			&i.VariantCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTestCasesBySuiteID = `-- name: GetTestCasesBySuiteID :many
+SELECT id, suite_id, name, line_number, status, tags, modifier, variant_count FROM test_cases WHERE suite_id = $1 ORDER BY line_number
+`
+
+func (q *Queries) GetTestCasesBySuiteID(ctx context.Context, suiteID pgtype.UUID) ([]TestCase, error) {
+	rows, err := q.db.Query(ctx, getTestCasesBySuiteID, suiteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TestCase{}
+	for rows.Next() {
+		var i TestCase
+		if err := rows.Scan(
+			&i.ID,
+			&i.SuiteID,
+			&i.Name,
+			&i.LineNumber,
+			&i.Status,
+			&i.Tags,
+			&i.Modifier,
+			&i.VariantCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTestDataByAnalysisID = `-- name: GetTestDataByAnalysisID :many
+SELECT
+    tf.id as file_id,
+    tf.file_path,
+    tf.framework,
+    tf.domain_hints,
+    tf.project,
+    ts.id as suite_id,
+    ts.parent_id as suite_parent_id,
+    ts.name as suite_name,
+    ts.depth as suite_depth,
+    tc.id as test_case_id,
+    tc.name as test_name
+FROM test_files tf
+JOIN test_suites ts ON ts.file_id = tf.id
+JOIN test_cases tc ON tc.suite_id = ts.id
+WHERE tf.analysis_id = $1
+ORDER BY tf.file_path, ts.depth, ts.name, tc.name
+`
+
+type GetTestDataByAnalysisIDRow struct {
+	FileID        pgtype.UUID `json:"file_id"`
+	FilePath      string      `json:"file_path"`
+	Framework     pgtype.Text `json:"framework"`
+	DomainHints   []byte      `json:"domain_hints"`
+	Project       pgtype.Text `json:"project"`
+	SuiteID       pgtype.UUID `json:"suite_id"`
+	SuiteParentID pgtype.UUID `json:"suite_parent_id"`
+	SuiteName     string      `json:"suite_name"`
+	SuiteDepth    int32       `json:"suite_depth"`
+	TestCaseID    pgtype.UUID `json:"test_case_id"`
+	TestName      string      `json:"test_name"`
+}
+
+func (q *Queries) GetTestDataByAnalysisID(ctx context.Context, analysisID pgtype.UUID) ([]GetTestDataByAnalysisIDRow, error) {
+	rows, err := q.db.Query(ctx, getTestDataByAnalysisID, analysisID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTestDataByAnalysisIDRow{}
+	for rows.Next() {
+		var i GetTestDataByAnalysisIDRow
+		if err := rows.Scan(
+			&i.FileID,
+			&i.FilePath,
+			&i.Framework,
+			&i.DomainHints,
+			&i.Project,
+			&i.SuiteID,
+			&i.SuiteParentID,
+			&i.SuiteName,
+			&i.SuiteDepth,
+			&i.TestCaseID,
+			&i.TestName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTestFilePathsByAnalysisID = `-- name: GetTestFilePathsByAnalysisID :many
+SELECT id, file_path FROM test_files WHERE analysis_id = $1 ORDER BY file_path
+`
+
+type GetTestFilePathsByAnalysisIDRow struct {
+	ID       pgtype.UUID `json:"id"`
+	FilePath string      `json:"file_path"`
+}
+
+func (q *Queries) GetTestFilePathsByAnalysisID(ctx context.Context, analysisID pgtype.UUID) ([]GetTestFilePathsByAnalysisIDRow, error) {
+	rows, err := q.db.Query(ctx, getTestFilePathsByAnalysisID, analysisID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTestFilePathsByAnalysisIDRow{}
+	for rows.Next() {
+		var i GetTestFilePathsByAnalysisIDRow
+		if err := rows.Scan(&i.ID, &i.FilePath); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTestFilesByPaths = `-- name: GetTestFilesByPaths :many
+SELECT id, file_path, framework, domain_hints, project
+FROM test_files
+WHERE analysis_id = $1 AND file_path = ANY($2::text[])
+`
+
+type GetTestFilesByPathsParams struct {
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+	Paths      []string    `json:"paths"`
+}
+
+type GetTestFilesByPathsRow struct {
+	ID          pgtype.UUID `json:"id"`
+	FilePath    string      `json:"file_path"`
+	Framework   pgtype.Text `json:"framework"`
+	DomainHints []byte      `json:"domain_hints"`
+	Project     pgtype.Text `json:"project"`
+}
+
+func (q *Queries) GetTestFilesByPaths(ctx context.Context, arg GetTestFilesByPathsParams) ([]GetTestFilesByPathsRow, error) {
+	rows, err := q.db.Query(ctx, getTestFilesByPaths, arg.AnalysisID, arg.Paths)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTestFilesByPathsRow{}
+	for rows.Next() {
+		var i GetTestFilesByPathsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FilePath,
+			&i.Framework,
+			&i.DomainHints,
+			&i.Project,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTestRefsByAnalysisID = `-- name: GetTestRefsByAnalysisID :many
+WITH RECURSIVE suite_paths AS (
+    SELECT ts.id, ts.file_id, ts.name::text AS path
+    FROM test_suites ts
+    JOIN test_files tf ON tf.id = ts.file_id
+    WHERE tf.analysis_id = $1 AND ts.parent_id IS NULL
+    UNION ALL
+    SELECT ts.id, ts.file_id, (sp.path || ' > ' || ts.name)::text
+    FROM test_suites ts
+    JOIN suite_paths sp ON ts.parent_id = sp.id
+)
+SELECT tf.file_path, sp.path AS suite_path, tc.name AS test_name
+FROM suite_paths sp
+JOIN test_files tf ON tf.id = sp.file_id
+JOIN test_cases tc ON tc.suite_id = sp.id
+ORDER BY tf.file_path, sp.path, tc.name
+`
+
+type GetTestRefsByAnalysisIDRow struct {
+	FilePath  string `json:"file_path"`
+	SuitePath string `json:"suite_path"`
+	TestName  string `json:"test_name"`
+}
+
+// Every test of the analysis with its file and full suite path ("Outer > Inner").
+func (q *Queries) GetTestRefsByAnalysisID(ctx context.Context, analysisID pgtype.UUID) ([]GetTestRefsByAnalysisIDRow, error) {
+	rows, err := q.db.Query(ctx, getTestRefsByAnalysisID, analysisID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTestRefsByAnalysisIDRow{}
+	for rows.Next() {
+		var i GetTestRefsByAnalysisIDRow
+		if err := rows.Scan(&i.FilePath, &i.SuitePath, &i.TestName); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTestResultUpload = `-- name: GetTestResultUpload :one
+
+SELECT id, analysis_id, format, content FROM test_result_uploads WHERE id = $1
+`
+
+type GetTestResultUploadRow struct {
+	ID         pgtype.UUID `json:"id"`
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+	Format     string      `json:"format"`
+	Content    []byte      `json:"content"`
+}
+
+// =============================================================================
+// TEST RESULTS
+// =============================================================================
+func (q *Queries) GetTestResultUpload(ctx context.Context, id pgtype.UUID) (GetTestResultUploadRow, error) {
+	row := q.db.QueryRow(ctx, getTestResultUpload, id)
+	var i GetTestResultUploadRow
+	err := row.Scan(
+		&i.ID,
+		&i.AnalysisID,
+		&i.Format,
+		&i.Content,
+	)
+	return i, err
+}
+
+const getTestSuitesByFileID = `-- name: GetTestSuitesByFileID :many
+SELECT id, parent_id, name, line_number, depth, file_id FROM test_suites WHERE file_id = $1 ORDER BY line_number
+`
+
+func (q *Queries) GetTestSuitesByFileID(ctx context.Context, fileID pgtype.UUID) ([]TestSuite, error) {
+	rows, err := q.db.Query(ctx, getTestSuitesByFileID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TestSuite{}
+	for rows.Next() {
+		var i TestSuite
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentID,
+			&i.Name,
+			&i.LineNumber,
+			&i.Depth,
+			&i.FileID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTestSuitesByFileIDs = `-- name: GetTestSuitesByFileIDs :many
+SELECT id, file_id, parent_id, name, line_number
+FROM test_suites
+WHERE file_id = ANY($1::uuid[])
+ORDER BY depth, line_number, name
+`
+
+type GetTestSuitesByFileIDsRow struct {
+	ID         pgtype.UUID `json:"id"`
+	FileID     pgtype.UUID `json:"file_id"`
+	ParentID   pgtype.UUID `json:"parent_id"`
+	Name       string      `json:"name"`
+	LineNumber pgtype.Int4 `json:"line_number"`
+}
+
+func (q *Queries) GetTestSuitesByFileIDs(ctx context.Context, fileIds []pgtype.UUID) ([]GetTestSuitesByFileIDsRow, error) {
+	rows, err := q.db.Query(ctx, getTestSuitesByFileIDs, fileIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTestSuitesByFileIDsRow{}
+	for rows.Next() {
+		var i GetTestSuitesByFileIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileID,
+			&i.ParentID,
+			&i.Name,
+			&i.LineNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserDocumentEncryption = `-- name: GetUserDocumentEncryption :one
+SELECT t.id AS tenant_id, t.document_encryption
+FROM tenants t
+JOIN tenant_members m ON m.tenant_id = t.id
+WHERE m.user_id = $1
+`
+
+type GetUserDocumentEncryptionRow struct {
+	TenantID           pgtype.UUID `json:"tenant_id"`
+	DocumentEncryption bool        `json:"document_encryption"`
+}
+
+// Whether the tenant the user belongs to encrypts its documents.
+func (q *Queries) GetUserDocumentEncryption(ctx context.Context, userID pgtype.UUID) (GetUserDocumentEncryptionRow, error) {
+	row := q.db.QueryRow(ctx, getUserDocumentEncryption, userID)
+	var i GetUserDocumentEncryptionRow
+	err := row.Scan(&i.TenantID, &i.DocumentEncryption)
+	return i, err
+}
+
+const getUserRetentionDays = `-- name: GetUserRetentionDays :one
+
+SELECT sp.retention_days
+FROM user_subscriptions us
+JOIN subscription_plans sp ON us.plan_id = sp.id
+WHERE us.user_id = $1 AND us.status = 'active'
+`
+
+// =============================================================================
+// RETENTION
+// =============================================================================
+// Returns retention_days from user's active subscription plan.
+// NULL means unlimited (enterprise) or no active subscription.
+func (q *Queries) GetUserRetentionDays(ctx context.Context, userID pgtype.UUID) (pgtype.Int4, error) {
+	row := q.db.QueryRow(ctx, getUserRetentionDays, userID)
+	var retention_days pgtype.Int4
+	err := row.Scan(&retention_days)
+	return retention_days, err
+}
+
+const getUserSpecViewQuota = `-- name: GetUserSpecViewQuota :one
+SELECT
+    (SELECT sp.specview_monthly_limit
+     FROM subscription_plans sp
+     WHERE sp.id = COALESCE(
+         (SELECT us.plan_id FROM user_subscriptions us WHERE us.user_id = $1 AND us.status = 'active' LIMIT 1),
+         (SELECT fp.id FROM subscription_plans fp WHERE fp.tier = 'free' ORDER BY fp.created_at LIMIT 1)
+     )) AS specview_limit,
+    (SELECT COALESCE(SUM(e.quota_amount), 0)
+     FROM usage_events e
+     WHERE e.user_id = $1
+       AND e.event_type = 'specview'
+       AND e.created_at >= date_trunc('month', CURRENT_DATE))::bigint AS used
+`
+
+type GetUserSpecViewQuotaRow struct {
+	SpecviewLimit pgtype.Int4 `json:"specview_limit"`
+	Used          int64       `json:"used"`
+}
+
+// Spec-view limit of the user's active plan, or of the free plan without one,
+// with this month's usage. A NULL limit means unlimited.
+func (q *Queries) GetUserSpecViewQuota(ctx context.Context, userID pgtype.UUID) (GetUserSpecViewQuotaRow, error) {
+	row := q.db.QueryRow(ctx, getUserSpecViewQuota, userID)
+	var i GetUserSpecViewQuotaRow
+	err := row.Scan(&i.SpecviewLimit, &i.Used)
+	return i, err
+}
+
+const getUserTenantID = `-- name: GetUserTenantID :one
+SELECT tenant_id FROM tenant_members WHERE user_id = $1
+`
+
+func (q *Queries) GetUserTenantID(ctx context.Context, userID pgtype.UUID) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, getUserTenantID, userID)
+	var tenant_id pgtype.UUID
+	err := row.Scan(&tenant_id)
+	return tenant_id, err
+}
+
+const getUserTier = `-- name: GetUserTier :one
+SELECT sp.tier
+FROM user_subscriptions us
+JOIN subscription_plans sp ON us.plan_id = sp.id
+WHERE us.user_id = $1 AND us.status = 'active'
+`
+
+// Returns tier from user's active subscription plan.
+// Returns no rows if user has no active subscription (caller should default to free).
+func (q *Queries) GetUserTier(ctx context.Context, userID pgtype.UUID) (PlanTier, error) {
+	row := q.db.QueryRow(ctx, getUserTier, userID)
+	var tier PlanTier
+	err := row.Scan(&tier)
+	return tier, err
+}
+
+const getWebhookDeliveryOutcome = `-- name: GetWebhookDeliveryOutcome :one
+SELECT outcome FROM webhook_deliveries
+WHERE delivery_id = $1
+`
+
+func (q *Queries) GetWebhookDeliveryOutcome(ctx context.Context, deliveryID string) (pgtype.Text, error) {
+	row := q.db.QueryRow(ctx, getWebhookDeliveryOutcome, deliveryID)
+	var outcome pgtype.Text
+	err := row.Scan(&outcome)
+	return outcome, err
+}
+
+const hasAnalysisForCommit = `-- name: HasAnalysisForCommit :one
+SELECT EXISTS(
+    SELECT 1 FROM analyses
+    WHERE codebase_id = $1
+      AND COALESCE(branch_name, '') = $2::text
+      AND commit_sha = $3
+      AND status <> 'failed'
+) AS exists
+`
+
+type HasAnalysisForCommitParams struct {
+	CodebaseID pgtype.UUID `json:"codebase_id"`
+	Branch     string      `json:"branch"`
+	CommitSha  string      `json:"commit_sha"`
+}
+
+// Failed analyses are excluded so that a new push can retry them.
+func (q *Queries) HasAnalysisForCommit(ctx context.Context, arg HasAnalysisForCommitParams) (bool, error) {
+	row := q.db.QueryRow(ctx, hasAnalysisForCommit, arg.CodebaseID, arg.Branch, arg.CommitSha)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const insertAIOutputQuarantine = `-- name: InsertAIOutputQuarantine :exec
+
+INSERT INTO ai_output_quarantine (phase, schema_version, model_id, response, error)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type InsertAIOutputQuarantineParams struct {
+	Phase         string `json:"phase"`
+	SchemaVersion string `json:"schema_version"`
+	ModelID       string `json:"model_id"`
+	Response      string `json:"response"`
+	Error         string `json:"error"`
+}
+
+// =============================================================================
+// AI OUTPUT QUARANTINE
+// =============================================================================
+func (q *Queries) InsertAIOutputQuarantine(ctx context.Context, arg InsertAIOutputQuarantineParams) error {
+	_, err := q.db.Exec(ctx, insertAIOutputQuarantine,
+		arg.Phase,
+		arg.SchemaVersion,
+		arg.ModelID,
+		arg.Response,
+		arg.Error,
+	)
+	return err
+}
+
+const insertAIUsage = `-- name: InsertAIUsage :exec
+INSERT INTO ai_usage (job_id, analysis_id, user_id, document_id, phase, model, fallback_from, prompt_tokens, candidates_tokens, total_tokens)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+`
+
+type InsertAIUsageParams struct {
+	JobID            pgtype.Int8 `json:"job_id"`
+	AnalysisID       pgtype.UUID `json:"analysis_id"`
+	UserID           pgtype.UUID `json:"user_id"`
+	DocumentID       pgtype.UUID `json:"document_id"`
+	Phase            string      `json:"phase"`
+	Model            string      `json:"model"`
+	FallbackFrom     pgtype.Text `json:"fallback_from"`
+	PromptTokens     int32       `json:"prompt_tokens"`
+	CandidatesTokens int32       `json:"candidates_tokens"`
+	TotalTokens      int32       `json:"total_tokens"`
+}
+
+func (q *Queries) InsertAIUsage(ctx context.Context, arg InsertAIUsageParams) error {
+	_, err := q.db.Exec(ctx, insertAIUsage,
+		arg.JobID,
+		arg.AnalysisID,
+		arg.UserID,
+		arg.DocumentID,
+		arg.Phase,
+		arg.Model,
+		arg.FallbackFrom,
+		arg.PromptTokens,
+		arg.CandidatesTokens,
+		arg.TotalTokens,
+	)
+	return err
+}
+
+const insertAnalysisCompletedOutboxEvent = `-- name: InsertAnalysisCompletedOutboxEvent :exec
+
+INSERT INTO outbox_events (event_type, aggregate_id, payload)
+SELECT $1::text, a.id, jsonb_build_object(
+    'analysis_id', a.id,
+    'codebase_id', c.id,
+    'host', c.host,
+    'owner', c.owner,
+    'repo', c.name,
+    'branch', a.branch_name,
+    'commit_sha', a.commit_sha,
+    'total_suites', a.total_suites,
+    'total_tests', a.total_tests,
+    'completed_at', a.completed_at
+)
+FROM analyses a
+JOIN codebases c ON c.id = a.codebase_id
+WHERE a.id = $2
+`
+
+type InsertAnalysisCompletedOutboxEventParams struct {
+	EventType  string      `json:"event_type"`
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+}
+
+// =============================================================================
+// OUTBOX
+// =============================================================================
+func (q *Queries) InsertAnalysisCompletedOutboxEvent(ctx context.Context, arg InsertAnalysisCompletedOutboxEventParams) error {
+	_, err := q.db.Exec(ctx, insertAnalysisCompletedOutboxEvent, arg.EventType, arg.AnalysisID)
+	return err
+}
+
+const insertAnalysisEvent = `-- name: InsertAnalysisEvent :exec
+WITH inserted AS (
+    INSERT INTO analysis_events (job_id, analysis_id, owner, repo, commit_sha, stage, files_processed, files_total, created_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    RETURNING job_id, analysis_id, owner, repo, commit_sha, stage, files_processed, files_total, created_at
+)
+SELECT pg_notify('analysis_progress', row_to_json(inserted)::text) FROM inserted
+`
+
+type InsertAnalysisEventParams struct {
+	JobID          pgtype.Int8        `json:"job_id"`
+	AnalysisID     pgtype.UUID        `json:"analysis_id"`
+	Owner          string             `json:"owner"`
+	Repo           string             `json:"repo"`
+	CommitSha      string             `json:"commit_sha"`
+	Stage          string             `json:"stage"`
+	FilesProcessed int32              `json:"files_processed"`
+	FilesTotal     int32              `json:"files_total"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+// Saves a progress event and notifies LISTEN analysis_progress subscribers in one statement.
+func (q *Queries) InsertAnalysisEvent(ctx context.Context, arg InsertAnalysisEventParams) error {
+	_, err := q.db.Exec(ctx, insertAnalysisEvent,
+		arg.JobID,
+		arg.AnalysisID,
+		arg.Owner,
+		arg.Repo,
+		arg.CommitSha,
+		arg.Stage,
+		arg.FilesProcessed,
+		arg.FilesTotal,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const insertBehaviorEmbeddings = `-- name: InsertBehaviorEmbeddings :exec
+INSERT INTO behavior_cache_embeddings (cache_key_hash, partition_hash, embedding)
+SELECT t.cache_key_hash, $1::bytea, t.embedding::vector
+FROM unnest($2::bytea[], $3::text[]) AS t(cache_key_hash, embedding)
+ON CONFLICT (cache_key_hash) DO NOTHING
+`
+
+type InsertBehaviorEmbeddingsParams struct {
+	PartitionHash  []byte   `json:"partition_hash"`
+	CacheKeyHashes [][]byte `json:"cache_key_hashes"`
+	Embeddings     []string `json:"embeddings"`
+}
+
+// Embeddings are text in pgvector's '[x,y,...]' format. Keys already stored
+// keep their embedding.
+func (q *Queries) InsertBehaviorEmbeddings(ctx context.Context, arg InsertBehaviorEmbeddingsParams) error {
+	_, err := q.db.Exec(ctx, insertBehaviorEmbeddings, arg.PartitionHash, arg.CacheKeyHashes, arg.Embeddings)
+	return err
+}
+
+const insertNotificationChannel = `-- name: InsertNotificationChannel :one
+
+INSERT INTO notification_channels (codebase_id, user_id, kind, url, on_success, on_failure)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, codebase_id, user_id, kind, url, on_success, on_failure, created_at
+`
+
+type InsertNotificationChannelParams struct {
+	CodebaseID pgtype.UUID `json:"codebase_id"`
+	UserID     pgtype.UUID `json:"user_id"`
+	Kind       string      `json:"kind"`
+	Url        string      `json:"url"`
+	OnSuccess  bool        `json:"on_success"`
+	OnFailure  bool        `json:"on_failure"`
+}
+
+// =============================================================================
+// NOTIFICATIONS
+// =============================================================================
+func (q *Queries) InsertNotificationChannel(ctx context.Context, arg InsertNotificationChannelParams) (NotificationChannel, error) {
+	row := q.db.QueryRow(ctx, insertNotificationChannel,
+		arg.CodebaseID,
+		arg.UserID,
+		arg.Kind,
+		arg.Url,
+		arg.OnSuccess,
+		arg.OnFailure,
+	)
+	var i NotificationChannel
+	err := row.Scan(
+		&i.ID,
+		&i.CodebaseID,
+		&i.UserID,
+		&i.Kind,
+		&i.Url,
+		&i.OnSuccess,
+		&i.OnFailure,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertParseError = `-- name: InsertParseError :exec
+INSERT INTO parse_errors (analysis_id, file_path, message, panicked)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (analysis_id, file_path) DO NOTHING
+`
+
+type InsertParseErrorParams struct {
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+	FilePath   string      `json:"file_path"`
+	Message    string      `json:"message"`
+	Panicked   bool        `json:"panicked"`
+}
+
+func (q *Queries) InsertParseError(ctx context.Context, arg InsertParseErrorParams) error {
+	_, err := q.db.Exec(ctx, insertParseError,
+		arg.AnalysisID,
+		arg.FilePath,
+		arg.Message,
+		arg.Panicked,
+	)
+	return err
+}
+
+const insertParserCompatReport = `-- name: InsertParserCompatReport :one
+INSERT INTO parser_compat_reports (candidate_version, sample_size, matched, mismatched, skipped, failed, details)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, created_at
+`
+
+type InsertParserCompatReportParams struct {
+	CandidateVersion string `json:"candidate_version"`
+	SampleSize       int32  `json:"sample_size"`
+	Matched          int32  `json:"matched"`
+	Mismatched       int32  `json:"mismatched"`
+	Skipped          int32  `json:"skipped"`
+	Failed           int32  `json:"failed"`
+	Details          []byte `json:"details"`
+}
+
+type InsertParserCompatReportRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) InsertParserCompatReport(ctx context.Context, arg InsertParserCompatReportParams) (InsertParserCompatReportRow, error) {
+	row := q.db.QueryRow(ctx, insertParserCompatReport,
+		arg.CandidateVersion,
+		arg.SampleSize,
+		arg.Matched,
+		arg.Mismatched,
+		arg.Skipped,
+		arg.Failed,
+		arg.Details,
+	)
+	var i InsertParserCompatReportRow
+	err := row.Scan(&i.ID, &i.CreatedAt)
+	return i, err
+}
+
+const insertPoisonJob = `-- name: InsertPoisonJob :exec
+INSERT INTO poison_jobs (job_id, kind, queue, attempt, encoded_args, error)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (job_id) DO NOTHING
+`
+
+type InsertPoisonJobParams struct {
+	JobID       int64  `json:"job_id"`
+	Kind        string `json:"kind"`
+	Queue       string `json:"queue"`
+	Attempt     int32  `json:"attempt"`
+	EncodedArgs []byte `json:"encoded_args"`
+	Error       string `json:"error"`
+}
+
+// ==============================================================================
+// POISON JOBS
+// ==============================================================================
+// The job is cancelled right after, so a job is quarantined once; a retried
+// cancellation keeps the first record.
+func (q *Queries) InsertPoisonJob(ctx context.Context, arg InsertPoisonJobParams) error {
+	_, err := q.db.Exec(ctx, insertPoisonJob,
+		arg.JobID,
+		arg.Kind,
+		arg.Queue,
+		arg.Attempt,
+		arg.EncodedArgs,
+		arg.Error,
+	)
+	return err
+}
+
+const insertRegenCampaign = `-- name: InsertRegenCampaign :one
+INSERT INTO regen_campaigns (name, rate_per_minute, max_in_flight, selection)
+VALUES ($1, $2, $3, $4)
+RETURNING id
+`
+
+type InsertRegenCampaignParams struct {
+	Name          string `json:"name"`
+	RatePerMinute int32  `json:"rate_per_minute"`
+	MaxInFlight   int32  `json:"max_in_flight"`
+	Selection     []byte `json:"selection"`
+}
+
+func (q *Queries) InsertRegenCampaign(ctx context.Context, arg InsertRegenCampaignParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, insertRegenCampaign,
+		arg.Name,
+		arg.RatePerMinute,
+		arg.MaxInFlight,
+		arg.Selection,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertRegenCampaignTargets = `-- name: InsertRegenCampaignTargets :execrows
+INSERT INTO regen_campaign_targets (campaign_id, document_id, analysis_id, user_id, language, team_slices)
+SELECT $1, d.id, d.analysis_id, d.user_id, d.language,
+       EXISTS(SELECT 1 FROM spec_documents c WHERE c.parent_document_id = d.id)
+FROM (
+    SELECT DISTINCT ON (sd.user_id, sd.analysis_id, sd.language)
+        sd.id, sd.analysis_id, sd.user_id, sd.language, sd.model_id, sd.created_at
+    FROM spec_documents sd
+    WHERE sd.parent_document_id IS NULL
+      AND sd.project IS NULL
+      AND sd.deleted_at IS NULL
+    ORDER BY sd.user_id, sd.analysis_id, sd.language, sd.version DESC
+) d
+WHERE ($2::text = '' OR d.model_id = $2::text)
+  AND ($3::text = '' OR d.language = $3::text)
+  AND d.created_at < $4
+  AND NOT EXISTS (SELECT 1 FROM spec_document_pins p WHERE p.document_id = d.id)
+ORDER BY d.created_at
+LIMIT $5
+`
+
+type InsertRegenCampaignTargetsParams struct {
+	CampaignID    pgtype.UUID        `json:"campaign_id"`
+	ModelID       string             `json:"model_id"`
+	Language      string             `json:"language"`
+	CreatedBefore pgtype.Timestamptz `json:"created_before"`
+	MaxRows       int32              `json:"max_rows"`
+}
+
+// Targets the latest full document per user, analysis and language, so a
+// document already regenerated since the cutoff is not selected again.
+// Pinned and deleted documents are never targeted.
+func (q *Queries) InsertRegenCampaignTargets(ctx context.Context, arg InsertRegenCampaignTargetsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertRegenCampaignTargets,
+		arg.CampaignID,
+		arg.ModelID,
+		arg.Language,
+		arg.CreatedBefore,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const insertRequirementSet = `-- name: InsertRequirementSet :one
+INSERT INTO requirement_sets (codebase_id, name, source_format)
+VALUES ($1, $2, $3)
+RETURNING id
+`
+
+type InsertRequirementSetParams struct {
+	CodebaseID   pgtype.UUID `json:"codebase_id"`
+	Name         string      `json:"name"`
+	SourceFormat string      `json:"source_format"`
+}
+
+func (q *Queries) InsertRequirementSet(ctx context.Context, arg InsertRequirementSetParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, insertRequirementSet, arg.CodebaseID, arg.Name, arg.SourceFormat)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertSLAViolation = `-- name: InsertSLAViolation :exec
+INSERT INTO sla_violations (job_id, kind, queue, attempt, outcome, target_ms, elapsed_ms, slowest_phase, phases, analysis_id, document_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+`
+
+type InsertSLAViolationParams struct {
+	JobID        int64       `json:"job_id"`
+	Kind         string      `json:"kind"`
+	Queue        string      `json:"queue"`
+	Attempt      int32       `json:"attempt"`
+	Outcome      string      `json:"outcome"`
+	TargetMs     int64       `json:"target_ms"`
+	ElapsedMs    int64       `json:"elapsed_ms"`
+	SlowestPhase string      `json:"slowest_phase"`
+	Phases       []byte      `json:"phases"`
+	AnalysisID   pgtype.UUID `json:"analysis_id"`
+	DocumentID   pgtype.UUID `json:"document_id"`
+}
+
+// ==============================================================================
+// SLA VIOLATIONS
+// ==============================================================================
+// phases holds the milliseconds spent in each phase of the job.
+func (q *Queries) InsertSLAViolation(ctx context.Context, arg InsertSLAViolationParams) error {
+	_, err := q.db.Exec(ctx, insertSLAViolation,
+		arg.JobID,
+		arg.Kind,
+		arg.Queue,
+		arg.Attempt,
+		arg.Outcome,
+		arg.TargetMs,
+		arg.ElapsedMs,
+		arg.SlowestPhase,
+		arg.Phases,
+		arg.AnalysisID,
+		arg.DocumentID,
+	)
+	return err
+}
+
+const insertServiceToken = `-- name: InsertServiceToken :one
+
+INSERT INTO service_tokens (name, token_hash, token_prefix, scopes, expires_at)
+VALUES ($1, $2, $3, $4::text[], $5)
+RETURNING id, name, token_hash, token_prefix, scopes, created_at, expires_at, last_used_at, revoked_at
+`
+
+type InsertServiceTokenParams struct {
+	Name        string             `json:"name"`
+	TokenHash   []byte             `json:"token_hash"`
+	TokenPrefix string             `json:"token_prefix"`
+	Scopes      []string           `json:"scopes"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+}
+
+// =============================================================================
+// SERVICE TOKENS
+// =============================================================================
+func (q *Queries) InsertServiceToken(ctx context.Context, arg InsertServiceTokenParams) (ServiceToken, error) {
+	row := q.db.QueryRow(ctx, insertServiceToken,
+		arg.Name,
+		arg.TokenHash,
+		arg.TokenPrefix,
+		arg.Scopes,
+		arg.ExpiresAt,
+	)
+	var i ServiceToken
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TokenHash,
+		&i.TokenPrefix,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const insertSpecDocument = `-- name: InsertSpecDocument :one
+INSERT INTO spec_documents (user_id, analysis_id, content_hash, language, executive_summary, model_id, version, retention_days_at_creation, parent_document_id, team, project, encryption_key_id, prompt_version, quality_score, quality_flagged, quality_report, is_dry_run, generator)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+RETURNING id
+`
+
+type InsertSpecDocumentParams struct {
+	UserID                  pgtype.UUID   `json:"user_id"`
+	AnalysisID              pgtype.UUID   `json:"analysis_id"`
+	ContentHash             []byte        `json:"content_hash"`
+	Language                string        `json:"language"`
+	ExecutiveSummary        pgtype.Text   `json:"executive_summary"`
+	ModelID                 string        `json:"model_id"`
+	Version                 int32         `json:"version"`
+	RetentionDaysAtCreation pgtype.Int4   `json:"retention_days_at_creation"`
+	ParentDocumentID        pgtype.UUID   `json:"parent_document_id"`
+	Team                    pgtype.Text   `json:"team"`
+	Project                 pgtype.Text   `json:"project"`
+	EncryptionKeyID         pgtype.UUID   `json:"encryption_key_id"`
+	PromptVersion           string        `json:"prompt_version"`
+	QualityScore            pgtype.Float8 `json:"quality_score"`
+	QualityFlagged          bool          `json:"quality_flagged"`
+	QualityReport           []byte        `json:"quality_report"`
+	IsDryRun                bool          `json:"is_dry_run"`
+	Generator               string        `json:"generator"`
+}
+
+func (q *Queries) InsertSpecDocument(ctx context.Context, arg InsertSpecDocumentParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, insertSpecDocument,
+		arg.UserID,
+		arg.AnalysisID,
+		arg.ContentHash,
+		arg.Language,
+		arg.ExecutiveSummary,
+		arg.ModelID,
+		arg.Version,
+		arg.RetentionDaysAtCreation,
+		arg.ParentDocumentID,
+		arg.Team,
+		arg.Project,
+		arg.EncryptionKeyID,
+		arg.PromptVersion,
+		arg.QualityScore,
+		arg.QualityFlagged,
+		arg.QualityReport,
+		arg.IsDryRun,
+		arg.Generator,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertSpecDocumentDecisions = `-- name: InsertSpecDocumentDecisions :exec
+INSERT INTO spec_document_decisions (document_id, analysis_id, generation_id, sequence, kind, subject, detail, occurred_at)
+SELECT $1::uuid, $2::uuid, $3::uuid, t.sequence, t.kind, t.subject, t.detail, t.occurred_at
+FROM unnest($4::int[], $5::text[], $6::text[], $7::jsonb[], $8::timestamptz[]) AS t(sequence, kind, subject, detail, occurred_at)
+ON CONFLICT (generation_id, sequence) DO NOTHING
+`
+
+type InsertSpecDocumentDecisionsParams struct {
+	DocumentID   pgtype.UUID          `json:"document_id"`
+	AnalysisID   pgtype.UUID          `json:"analysis_id"`
+	GenerationID pgtype.UUID          `json:"generation_id"`
+	Sequences    []int32              `json:"sequences"`
+	Kinds        []string             `json:"kinds"`
+	Subjects     []string             `json:"subjects"`
+	Details      [][]byte             `json:"details"`
+	OccurredAts  []pgtype.Timestamptz `json:"occurred_ats"`
+}
+
+// Stores the decisions of a saved document. Those already written ahead are kept.
+func (q *Queries) InsertSpecDocumentDecisions(ctx context.Context, arg InsertSpecDocumentDecisionsParams) error {
+	_, err := q.db.Exec(ctx, insertSpecDocumentDecisions,
+		arg.DocumentID,
+		arg.AnalysisID,
+		arg.GenerationID,
+		arg.Sequences,
+		arg.Kinds,
+		arg.Subjects,
+		arg.Details,
+		arg.OccurredAts,
+	)
+	return err
+}
+
+const insertSpecDocumentOutboxEvent = `-- name: InsertSpecDocumentOutboxEvent :exec
+INSERT INTO outbox_events (event_type, aggregate_id, payload)
+SELECT $1::text, d.id, jsonb_build_object(
+    'document_id', d.id,
+    'analysis_id', a.id,
+    'user_id', d.user_id,
+    'language', d.language,
+    'version', d.version,
+    'project', d.project,
+    'model_id', d.model_id,
+    'host', c.host,
+    'owner', c.owner,
+    'repo', c.name,
+    'commit_sha', a.commit_sha,
+    'created_at', d.created_at
+)
+FROM spec_documents d
+JOIN analyses a ON a.id = d.analysis_id
+JOIN codebases c ON c.id = a.codebase_id
+WHERE d.id = $2
+`
+
+type InsertSpecDocumentOutboxEventParams struct {
+	EventType  string      `json:"event_type"`
+	DocumentID pgtype.UUID `json:"document_id"`
+}
+
+func (q *Queries) InsertSpecDocumentOutboxEvent(ctx context.Context, arg InsertSpecDocumentOutboxEventParams) error {
+	_, err := q.db.Exec(ctx, insertSpecDocumentOutboxEvent, arg.EventType, arg.DocumentID)
+	return err
+}
+
+const insertSpecDomain = `-- name: InsertSpecDomain :one
+INSERT INTO spec_domains (document_id, name, description, sort_order, classification_confidence, slug)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
+`
+
+type InsertSpecDomainParams struct {
+	DocumentID               pgtype.UUID    `json:"document_id"`
+	Name                     string         `json:"name"`
+	Description              pgtype.Text    `json:"description"`
+	SortOrder                int32          `json:"sort_order"`
+	ClassificationConfidence pgtype.Numeric `json:"classification_confidence"`
+	Slug                     string         `json:"slug"`
+}
+
+func (q *Queries) InsertSpecDomain(ctx context.Context, arg InsertSpecDomainParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, insertSpecDomain,
+		arg.DocumentID,
+		arg.Name,
+		arg.Description,
+		arg.SortOrder,
+		arg.ClassificationConfidence,
+		arg.Slug,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertSpecFeature = `-- name: InsertSpecFeature :one
+INSERT INTO spec_features (domain_id, name, description, sort_order, slug)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id
+`
+
+type InsertSpecFeatureParams struct {
+	DomainID    pgtype.UUID `json:"domain_id"`
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	SortOrder   int32       `json:"sort_order"`
+	Slug        string      `json:"slug"`
+}
+
+func (q *Queries) InsertSpecFeature(ctx context.Context, arg InsertSpecFeatureParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, insertSpecFeature,
+		arg.DomainID,
+		arg.Name,
+		arg.Description,
+		arg.SortOrder,
+		arg.Slug,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertSpecGenerationCheckpointFeature = `-- name: InsertSpecGenerationCheckpointFeature :exec
+INSERT INTO spec_generation_checkpoint_features (job_id, domain_index, feature_index, behaviors)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (job_id, domain_index, feature_index) DO UPDATE
+SET behaviors = EXCLUDED.behaviors
+`
+
+type InsertSpecGenerationCheckpointFeatureParams struct {
+	JobID        int64  `json:"job_id"`
+	DomainIndex  int32  `json:"domain_index"`
+	FeatureIndex int32  `json:"feature_index"`
+	Behaviors    []byte `json:"behaviors"`
+}
+
+func (q *Queries) InsertSpecGenerationCheckpointFeature(ctx context.Context, arg InsertSpecGenerationCheckpointFeatureParams) error {
+	_, err := q.db.Exec(ctx, insertSpecGenerationCheckpointFeature,
+		arg.JobID,
+		arg.DomainIndex,
+		arg.FeatureIndex,
+		arg.Behaviors,
+	)
+	return err
+}
+
+const insertTenantDocumentKey = `-- name: InsertTenantDocumentKey :one
+INSERT INTO tenant_document_keys (tenant_id, wrapped_key)
+VALUES ($1, $2)
+ON CONFLICT (tenant_id) WHERE retired_at IS NULL DO NOTHING
+RETURNING id
+`
+
+type InsertTenantDocumentKeyParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	WrappedKey string      `json:"wrapped_key"`
+}
+
+// Zero rows means another transaction created the active key first.
+func (q *Queries) InsertTenantDocumentKey(ctx context.Context, arg InsertTenantDocumentKeyParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, insertTenantDocumentKey, arg.TenantID, arg.WrappedKey)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertTestFile = `-- name: InsertTestFile :one
+INSERT INTO test_files (analysis_id, file_path, framework, domain_hints)
+VALUES ($1, $2, $3, $4)
+RETURNING id
+`
+
+type InsertTestFileParams struct {
+	AnalysisID  pgtype.UUID `json:"analysis_id"`
+	FilePath    string      `json:"file_path"`
+	Framework   pgtype.Text `json:"framework"`
+	DomainHints []byte      `json:"domain_hints"`
+}
+
+func (q *Queries) InsertTestFile(ctx context.Context, arg InsertTestFileParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, insertTestFile,
+		arg.AnalysisID,
+		arg.FilePath,
+		arg.Framework,
+		arg.DomainHints,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertTestSuite = `-- name: InsertTestSuite :one
+INSERT INTO test_suites (file_id, parent_id, name, line_number, depth)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id
+`
+
+type InsertTestSuiteParams struct {
+	FileID     pgtype.UUID `json:"file_id"`
+	ParentID   pgtype.UUID `json:"parent_id"`
+	Name       string      `json:"name"`
+	LineNumber pgtype.Int4 `json:"line_number"`
+	Depth      int32       `json:"depth"`
+}
+
+func (q *Queries) InsertTestSuite(ctx context.Context, arg InsertTestSuiteParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, insertTestSuite,
+		arg.FileID,
+		arg.ParentID,
+		arg.Name,
+		arg.LineNumber,
+		arg.Depth,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertUsageWarning = `-- name: InsertUsageWarning :exec
+
+WITH inserted AS (
+    INSERT INTO usage_warnings (user_id, period_start, threshold, document_id, quota_used, quota_limit)
+    VALUES ($1, date_trunc('month', CURRENT_DATE)::date, $2, $3, $4, $5)
+    ON CONFLICT (user_id, period_start, threshold) DO NOTHING
+    RETURNING user_id, threshold, document_id, quota_used, quota_limit, created_at
+)
+SELECT pg_notify('usage_warning', row_to_json(inserted)::text) FROM inserted
+`
+
+type InsertUsageWarningParams struct {
+	UserID     pgtype.UUID `json:"user_id"`
+	Threshold  int16       `json:"threshold"`
+	DocumentID pgtype.UUID `json:"document_id"`
+	QuotaUsed  int64       `json:"quota_used"`
+	QuotaLimit int64       `json:"quota_limit"`
+}
+
+// =============================================================================
+// USAGE WARNINGS
+// =============================================================================
+// Records a quota warning once per user, month and threshold, and publishes
+// new ones on the usage_warning channel.
+func (q *Queries) InsertUsageWarning(ctx context.Context, arg InsertUsageWarningParams) error {
+	_, err := q.db.Exec(ctx, insertUsageWarning,
+		arg.UserID,
+		arg.Threshold,
+		arg.DocumentID,
+		arg.QuotaUsed,
+		arg.QuotaLimit,
+	)
+	return err
+}
+
+const isCodebaseSharingOptedOut = `-- name: IsCodebaseSharingOptedOut :one
+SELECT EXISTS(
+    SELECT 1 FROM codebase_sharing_opt_outs o
+    JOIN analyses a ON a.codebase_id = o.codebase_id
+    WHERE a.id = $1
+) AS opted_out
+`
+
+func (q *Queries) IsCodebaseSharingOptedOut(ctx context.Context, analysisID pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isCodebaseSharingOptedOut, analysisID)
+	var opted_out bool
+	err := row.Scan(&opted_out)
+	return opted_out, err
+}
+
+const isSpecGenerationCancelled = `-- name: IsSpecGenerationCancelled :one
+SELECT EXISTS(
+    SELECT 1 FROM spec_generation_cancellations
+    WHERE analysis_id = $1
+      AND language = $2
+      AND requested_by = $3
+      AND requested_at >= $4
+) AS cancelled
+`
+
+type IsSpecGenerationCancelledParams struct {
+	AnalysisID  pgtype.UUID        `json:"analysis_id"`
+	Language    string             `json:"language"`
+	RequestedBy pgtype.UUID        `json:"requested_by"`
+	Since       pgtype.Timestamptz `json:"since"`
+}
+
+// The Web app upserts a row when a user cancels their generation; requests of
+// other users, or older than the job, left over from an earlier run, do not count.
+func (q *Queries) IsSpecGenerationCancelled(ctx context.Context, arg IsSpecGenerationCancelledParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isSpecGenerationCancelled,
+		arg.AnalysisID,
+		arg.Language,
+		arg.RequestedBy,
+		arg.Since,
+	)
+	var cancelled bool
+	err := row.Scan(&cancelled)
+	return cancelled, err
+}
+
+const listDiscardedJobs = `-- name: ListDiscardedJobs :many
+SELECT
+    j.id,
+    j.kind,
+    j.attempt,
+    j.max_attempts,
+    j.finalized_at,
+    COALESCE(j.errors[array_upper(j.errors, 1)] ->> 'error', '')::text AS last_error,
+    a.analysis_id,
+    COALESCE(a.status::text, '')::text AS analysis_status,
+    COALESCE(a.owner, j.args ->> 'owner', '')::text AS owner,
+    COALESCE(a.repo, j.args ->> 'repo', '')::text AS repo,
+    COALESCE(a.commit_sha, j.args ->> 'commit_sha', '')::text AS commit_sha
+FROM river_job j
+LEFT JOIN LATERAL (
+    SELECT an.id AS analysis_id, an.status, an.commit_sha, c.owner, c.name AS repo
+    FROM analyses an
+    JOIN codebases c ON c.id = an.codebase_id
+    WHERE (j.kind = 'specview:generate' AND an.id = (j.args ->> 'analysis_id')::uuid)
+       OR (j.kind = 'analysis:analyze'
+           AND c.owner = j.args ->> 'owner'
+           AND c.name = j.args ->> 'repo'
+           AND an.commit_sha = j.args ->> 'commit_sha')
+    ORDER BY an.created_at DESC
+    LIMIT 1
+) a ON true
+WHERE j.state = 'discarded'
+  AND j.kind = ANY($1::text[])
+  AND j.finalized_at >= $2::timestamptz
+  AND (cardinality($3::bigint[]) = 0 OR j.id = ANY($3::bigint[]))
+ORDER BY j.finalized_at DESC
+LIMIT $4
+`
+
+type ListDiscardedJobsParams struct {
+	Kinds          []string           `json:"kinds"`
+	DiscardedAfter pgtype.Timestamptz `json:"discarded_after"`
+	JobIds         []int64            `json:"job_ids"`
+	MaxRows        int32              `json:"max_rows"`
+}
+
+type ListDiscardedJobsRow struct {
+	ID             int64              `json:"id"`
+	Kind           string             `json:"kind"`
+	Attempt        int16              `json:"attempt"`
+	MaxAttempts    int16              `json:"max_attempts"`
+	FinalizedAt    pgtype.Timestamptz `json:"finalized_at"`
+	LastError      string             `json:"last_error"`
+	AnalysisID     pgtype.UUID        `json:"analysis_id"`
+	AnalysisStatus string             `json:"analysis_status"`
+	Owner          string             `json:"owner"`
+	Repo           string             `json:"repo"`
+	CommitSha      string             `json:"commit_sha"`
+}
+
+// Dead-letter view of discarded jobs joined with the analysis they refer to.
+// Specview jobs match by analysis_id; analyze jobs by owner/repo/commit (latest analysis).
+func (q *Queries) ListDiscardedJobs(ctx context.Context, arg ListDiscardedJobsParams) ([]ListDiscardedJobsRow, error) {
+	rows, err := q.db.Query(ctx, listDiscardedJobs,
+		arg.Kinds,
+		arg.DiscardedAfter,
+		arg.JobIds,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDiscardedJobsRow{}
+	for rows.Next() {
+		var i ListDiscardedJobsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Attempt,
+			&i.MaxAttempts,
+			&i.FinalizedAt,
+			&i.LastError,
+			&i.AnalysisID,
+			&i.AnalysisStatus,
+			&i.Owner,
+			&i.Repo,
+			&i.CommitSha,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobHistory = `-- name: ListJobHistory :many
+SELECT
+    jr.job_id,
+    jr.kind,
+    jr.queue,
+    jr.user_id,
+    jr.enqueued_at,
+    jr.started_at,
+    jr.completed_at,
+    a.codebase_id,
+    sp.tier
+FROM job_results jr
+LEFT JOIN analyses a ON a.id = jr.analysis_id
+LEFT JOIN user_subscriptions us ON us.user_id = jr.user_id AND us.status = 'active'
+LEFT JOIN subscription_plans sp ON sp.id = us.plan_id
+WHERE jr.enqueued_at >= $1
+  AND jr.enqueued_at < $2
+  AND jr.started_at IS NOT NULL
+ORDER BY jr.enqueued_at, jr.job_id
+`
+
+type ListJobHistoryParams struct {
+	Since pgtype.Timestamptz `json:"since"`
+	Until pgtype.Timestamptz `json:"until"`
+}
+
+type ListJobHistoryRow struct {
+	JobID       int64              `json:"job_id"`
+	Kind        string             `json:"kind"`
+	Queue       string             `json:"queue"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	EnqueuedAt  pgtype.Timestamptz `json:"enqueued_at"`
+	StartedAt   pgtype.Timestamptz `json:"started_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+	CodebaseID  pgtype.UUID        `json:"codebase_id"`
+	Tier        NullPlanTier       `json:"tier"`
+}
+
+// Jobs enqueued in [@since, @until) with the tier of their user's active
+// subscription today and the codebase of their analysis, oldest first. tier
+// is NULL for system jobs and users without an active subscription.
+func (q *Queries) ListJobHistory(ctx context.Context, arg ListJobHistoryParams) ([]ListJobHistoryRow, error) {
+	rows, err := q.db.Query(ctx, listJobHistory, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListJobHistoryRow
+	for rows.Next() {
+		var i ListJobHistoryRow
+		if err := rows.Scan(
+			&i.JobID,
+			&i.Kind,
+			&i.Queue,
+			&i.UserID,
+			&i.EnqueuedAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.CodebaseID,
+			&i.Tier,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotificationChannels = `-- name: ListNotificationChannels :many
+SELECT id, codebase_id, user_id, kind, url, on_success, on_failure, created_at FROM notification_channels
+WHERE ($1::uuid IS NULL OR codebase_id = $1)
+  AND ($2::uuid IS NULL OR user_id = $2)
+ORDER BY created_at, id
+`
+
+type ListNotificationChannelsParams struct {
+	CodebaseID pgtype.UUID `json:"codebase_id"`
+	UserID     pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) ListNotificationChannels(ctx context.Context, arg ListNotificationChannelsParams) ([]NotificationChannel, error) {
+	rows, err := q.db.Query(ctx, listNotificationChannels, arg.CodebaseID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotificationChannel{}
+	for rows.Next() {
+		var i NotificationChannel
+		if err := rows.Scan(
+			&i.ID,
+			&i.CodebaseID,
+			&i.UserID,
+			&i.Kind,
+			&i.Url,
+			&i.OnSuccess,
+			&i.OnFailure,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotificationChannelsForOutcome = `-- name: ListNotificationChannelsForOutcome :many
+SELECT id, codebase_id, user_id, kind, url, on_success, on_failure, created_at FROM notification_channels
+WHERE (codebase_id = $1 OR user_id = $2)
+  AND CASE WHEN $3::boolean THEN on_failure ELSE on_success END
+ORDER BY created_at, id
+`
+
+type ListNotificationChannelsForOutcomeParams struct {
+	CodebaseID pgtype.UUID `json:"codebase_id"`
+	UserID     pgtype.UUID `json:"user_id"`
+	Failed     bool        `json:"failed"`
+}
+
+// Channels of the codebase and of the user that want outcomes of this kind.
+func (q *Queries) ListNotificationChannelsForOutcome(ctx context.Context, arg ListNotificationChannelsForOutcomeParams) ([]NotificationChannel, error) {
+	rows, err := q.db.Query(ctx, listNotificationChannelsForOutcome, arg.CodebaseID, arg.UserID, arg.Failed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotificationChannel{}
+	for rows.Next() {
+		var i NotificationChannel
+		if err := rows.Scan(
+			&i.ID,
+			&i.CodebaseID,
+			&i.UserID,
+			&i.Kind,
+			&i.Url,
+			&i.OnSuccess,
+			&i.OnFailure,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOAuthAccessTokens = `-- name: ListOAuthAccessTokens :many
+SELECT id, access_token::text AS access_token
+FROM oauth_accounts
+WHERE access_token IS NOT NULL AND access_token <> '' AND id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListOAuthAccessTokensParams struct {
+	AfterID   pgtype.UUID `json:"after_id"`
+	BatchSize int32       `json:"batch_size"`
+}
+
+type ListOAuthAccessTokensRow struct {
+	ID          pgtype.UUID `json:"id"`
+	AccessToken string      `json:"access_token"`
+}
+
+// OAUTH TOKEN KEYS
+// Pages by id, so logins during a re-encryption neither skip nor repeat rows.
+func (q *Queries) ListOAuthAccessTokens(ctx context.Context, arg ListOAuthAccessTokensParams) ([]ListOAuthAccessTokensRow, error) {
+	rows, err := q.db.Query(ctx, listOAuthAccessTokens, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOAuthAccessTokensRow{}
+	for rows.Next() {
+		var i ListOAuthAccessTokensRow
+		if err := rows.Scan(&i.ID, &i.AccessToken); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listParseErrors = `-- name: ListParseErrors :many
+SELECT file_path, message, panicked
+FROM parse_errors
+WHERE analysis_id = $1
+ORDER BY file_path
+`
+
+type ListParseErrorsRow struct {
+	FilePath string `json:"file_path"`
+	Message  string `json:"message"`
+	Panicked bool   `json:"panicked"`
+}
+
+func (q *Queries) ListParseErrors(ctx context.Context, analysisID pgtype.UUID) ([]ListParseErrorsRow, error) {
+	rows, err := q.db.Query(ctx, listParseErrors, analysisID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListParseErrorsRow{}
+	for rows.Next() {
+		var i ListParseErrorsRow
+		if err := rows.Scan(&i.FilePath, &i.Message, &i.Panicked); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listParserCompatSamples = `-- name: ListParserCompatSamples :many
+SELECT s.analysis_id, s.commit_sha, s.parser_version, s.curation_rules, s.owner, s.repo
+FROM (
+    SELECT DISTINCT ON (c.id)
+        a.id AS analysis_id,
+        a.commit_sha,
+        a.parser_version,
+        a.curation_rules,
+        c.owner,
+        c.name AS repo
+    FROM codebases c
+    JOIN analyses a ON a.codebase_id = c.id
+    WHERE c.is_stale = false
+      AND c.is_private = false
+      AND a.status = 'completed'
+    ORDER BY c.id, a.completed_at DESC
+) s
+WHERE s.parser_version <> $1::text
+ORDER BY random()
+LIMIT $2
+`
+
+type ListParserCompatSamplesParams struct {
+	CandidateVersion string `json:"candidate_version"`
+	MaxRows          int32  `json:"max_rows"`
+}
+
+type ListParserCompatSamplesRow struct {
+	AnalysisID    pgtype.UUID `json:"analysis_id"`
+	CommitSha     string      `json:"commit_sha"`
+	ParserVersion string      `json:"parser_version"`
+	CurationRules []byte      `json:"curation_rules"`
+	Owner         string      `json:"owner"`
+	Repo          string      `json:"repo"`
+}
+
+// Random sample of public repositories whose latest completed analysis was
+// produced by a parser version other than the candidate.
+func (q *Queries) ListParserCompatSamples(ctx context.Context, arg ListParserCompatSamplesParams) ([]ListParserCompatSamplesRow, error) {
+	rows, err := q.db.Query(ctx, listParserCompatSamples, arg.CandidateVersion, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListParserCompatSamplesRow{}
+	for rows.Next() {
+		var i ListParserCompatSamplesRow
+		if err := rows.Scan(
+			&i.AnalysisID,
+			&i.CommitSha,
+			&i.ParserVersion,
+			&i.CurationRules,
+			&i.Owner,
+			&i.Repo,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRegenCampaigns = `-- name: ListRegenCampaigns :many
+SELECT
+    c.id, c.name, c.status, c.rate_per_minute, c.max_in_flight, c.selection, c.created_at, c.updated_at,
+    COUNT(t.id) FILTER (WHERE t.status = 'pending')::int AS pending,
+    COUNT(t.id) FILTER (WHERE t.status = 'enqueued')::int AS enqueued,
+    COUNT(t.id) FILTER (WHERE t.status = 'succeeded')::int AS succeeded,
+    COUNT(t.id) FILTER (WHERE t.status = 'failed')::int AS failed
+FROM regen_campaigns c
+LEFT JOIN regen_campaign_targets t ON t.campaign_id = c.id
+GROUP BY c.id
+ORDER BY c.created_at DESC
+`
+
+type ListRegenCampaignsRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	Name          string             `json:"name"`
+	Status        string             `json:"status"`
+	RatePerMinute int32              `json:"rate_per_minute"`
+	MaxInFlight   int32              `json:"max_in_flight"`
+	Selection     []byte             `json:"selection"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	Pending       int32              `json:"pending"`
+	Enqueued      int32              `json:"enqueued"`
+	Succeeded     int32              `json:"succeeded"`
+	Failed        int32              `json:"failed"`
+}
+
+func (q *Queries) ListRegenCampaigns(ctx context.Context) ([]ListRegenCampaignsRow, error) {
+	rows, err := q.db.Query(ctx, listRegenCampaigns)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRegenCampaignsRow{}
+	for rows.Next() {
+		var i ListRegenCampaignsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Status,
+			&i.RatePerMinute,
+			&i.MaxInFlight,
+			&i.Selection,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Pending,
+			&i.Enqueued,
+			&i.Succeeded,
+			&i.Failed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRegenTargetsByStatus = `-- name: ListRegenTargetsByStatus :many
+SELECT id, campaign_id, document_id, analysis_id, user_id, language, team_slices, status, job_id, error
+FROM regen_campaign_targets
+WHERE campaign_id = $1 AND status = $2
+ORDER BY created_at, id
+LIMIT $3
+`
+
+type ListRegenTargetsByStatusParams struct {
+	CampaignID pgtype.UUID `json:"campaign_id"`
+	Status     string      `json:"status"`
+	MaxRows    int32       `json:"max_rows"`
+}
+
+type ListRegenTargetsByStatusRow struct {
+	ID         pgtype.UUID `json:"id"`
+	CampaignID pgtype.UUID `json:"campaign_id"`
+	DocumentID pgtype.UUID `json:"document_id"`
+	AnalysisID pgtype.UUID `json:"analysis_id"`
+	UserID     pgtype.UUID `json:"user_id"`
+	Language   string      `json:"language"`
+	TeamSlices bool        `json:"team_slices"`
+	Status     string      `json:"status"`
+	JobID      pgtype.Int8 `json:"job_id"`
+	Error      pgtype.Text `json:"error"`
+}
+
+func (q *Queries) ListRegenTargetsByStatus(ctx context.Context, arg ListRegenTargetsByStatusParams) ([]ListRegenTargetsByStatusRow, error) {
+	rows, err := q.db.Query(ctx, listRegenTargetsByStatus, arg.CampaignID, arg.Status, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRegenTargetsByStatusRow{}
+	for rows.Next() {
+		var i ListRegenTargetsByStatusRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CampaignID,
+			&i.DocumentID,
+			&i.AnalysisID,
+			&i.UserID,
+			&i.Language,
+			&i.TeamSlices,
+			&i.Status,
+			&i.JobID,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRepoPoliciesByUser = `-- name: ListRepoPoliciesByUser :many
+
+SELECT p.effect, p.pattern
+FROM tenant_repo_policies p
+JOIN tenant_members m ON m.tenant_id = p.tenant_id
+WHERE m.user_id = $1
+ORDER BY p.effect, p.pattern
+`
+
+type ListRepoPoliciesByUserRow struct {
+	Effect  string `json:"effect"`
+	Pattern string `json:"pattern"`
+}
+
+// =============================================================================
+// TENANT REPOSITORY POLICIES
+// =============================================================================
+// Rules of the tenant the user belongs to.
+func (q *Queries) ListRepoPoliciesByUser(ctx context.Context, userID pgtype.UUID) ([]ListRepoPoliciesByUserRow, error) {
+	rows, err := q.db.Query(ctx, listRepoPoliciesByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRepoPoliciesByUserRow
+	for rows.Next() {
+		var i ListRepoPoliciesByUserRow
+		if err := rows.Scan(&i.Effect, &i.Pattern); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRiverJobs = `-- name: ListRiverJobs :many
+
+SELECT
+    id,
+    kind,
+    queue,
+    state::text AS state,
+    attempt,
+    max_attempts,
+    priority,
+    created_at,
+    scheduled_at,
+    attempted_at,
+    finalized_at,
+    errors,
+    COALESCE((metadata ->> 'snoozes')::int, 0)::int AS snoozes
+FROM river_job
+WHERE (state::text = ANY($1::text[])
+       OR ('snoozed' = ANY($1::text[]) AND state = 'scheduled' AND metadata ->> 'snoozes' IS NOT NULL))
+  AND (cardinality($2::text[]) = 0 OR kind = ANY($2::text[]))
+  AND (cardinality($3::text[]) = 0 OR queue = ANY($3::text[]))
+ORDER BY id DESC
+LIMIT $4
+`
+
+type ListRiverJobsParams struct {
+	States  []string `json:"states"`
+	Kinds   []string `json:"kinds"`
+	Queues  []string `json:"queues"`
+	MaxRows int32    `json:"max_rows"`
+}
+
+type ListRiverJobsRow struct {
+	ID          int64              `json:"id"`
+	Kind        string             `json:"kind"`
+	Queue       string             `json:"queue"`
+	State       string             `json:"state"`
+	Attempt     int16              `json:"attempt"`
+	MaxAttempts int16              `json:"max_attempts"`
+	Priority    int16              `json:"priority"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	ScheduledAt pgtype.Timestamptz `json:"scheduled_at"`
+	AttemptedAt pgtype.Timestamptz `json:"attempted_at"`
+	FinalizedAt pgtype.Timestamptz `json:"finalized_at"`
+	Errors      [][]byte           `json:"errors"`
+	Snoozes     int32              `json:"snoozes"`
+}
+
+// =============================================================================
+// JOB ADMINISTRATION
+// =============================================================================
+// 'snoozed' selects scheduled jobs whose metadata counts River snoozes.
+func (q *Queries) ListRiverJobs(ctx context.Context, arg ListRiverJobsParams) ([]ListRiverJobsRow, error) {
+	rows, err := q.db.Query(ctx, listRiverJobs,
+		arg.States,
+		arg.Kinds,
+		arg.Queues,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRiverJobsRow{}
+	for rows.Next() {
+		var i ListRiverJobsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Queue,
+			&i.State,
+			&i.Attempt,
+			&i.MaxAttempts,
+			&i.Priority,
+			&i.CreatedAt,
+			&i.ScheduledAt,
+			&i.AttemptedAt,
+			&i.FinalizedAt,
+			&i.Errors,
+			&i.Snoozes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceTokens = `-- name: ListServiceTokens :many
+SELECT id, name, token_hash, token_prefix, scopes, created_at, expires_at, last_used_at, revoked_at FROM service_tokens
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListServiceTokens(ctx context.Context) ([]ServiceToken, error) {
+	rows, err := q.db.Query(ctx, listServiceTokens)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ServiceToken
+	for rows.Next() {
+		var i ServiceToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.TokenHash,
+			&i.TokenPrefix,
+			&i.Scopes,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSpecGenerationCheckpointFeatures = `-- name: ListSpecGenerationCheckpointFeatures :many
+SELECT job_id, domain_index, feature_index, behaviors, created_at
+FROM spec_generation_checkpoint_features
+WHERE job_id = $1
+ORDER BY created_at, domain_index, feature_index
+`
+
+func (q *Queries) ListSpecGenerationCheckpointFeatures(ctx context.Context, jobID int64) ([]SpecGenerationCheckpointFeature, error) {
+	rows, err := q.db.Query(ctx, listSpecGenerationCheckpointFeatures, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SpecGenerationCheckpointFeature
+	for rows.Next() {
+		var i SpecGenerationCheckpointFeature
+		if err := rows.Scan(
+			&i.JobID,
+			&i.DomainIndex,
+			&i.FeatureIndex,
+			&i.Behaviors,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantAIUsage = `-- name: ListTenantAIUsage :many
+SELECT id, document_id, tokens, created_at
+FROM tenant_ai_usage
+WHERE tenant_id = $1
+ORDER BY created_at, id
+`
+
+type ListTenantAIUsageRow struct {
+	ID         pgtype.UUID        `json:"id"`
+	DocumentID pgtype.UUID        `json:"document_id"`
+	Tokens     int64              `json:"tokens"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) ListTenantAIUsage(ctx context.Context, tenantID pgtype.UUID) ([]ListTenantAIUsageRow, error) {
+	rows, err := q.db.Query(ctx, listTenantAIUsage, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTenantAIUsageRow{}
+	for rows.Next() {
+		var i ListTenantAIUsageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DocumentID,
+			&i.Tokens,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantAnalyses = `-- name: ListTenantAnalyses :many
+SELECT a.id, a.codebase_id, a.commit_sha, a.branch_name, a.status, a.parser_version,
+    a.total_suites, a.total_tests, a.created_at, a.completed_at
+FROM analyses a
+WHERE EXISTS (
+    SELECT 1 FROM user_analysis_history h
+    JOIN tenant_members m ON m.user_id = h.user_id
+    WHERE h.analysis_id = a.id AND m.tenant_id = $1
+)
+ORDER BY a.created_at, a.id
+`
+
+type ListTenantAnalysesRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	CodebaseID    pgtype.UUID        `json:"codebase_id"`
+	CommitSha     string             `json:"commit_sha"`
+	BranchName    pgtype.Text        `json:"branch_name"`
+	Status        AnalysisStatus     `json:"status"`
+	ParserVersion string             `json:"parser_version"`
+	TotalSuites   int32              `json:"total_suites"`
+	TotalTests    int32              `json:"total_tests"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	CompletedAt   pgtype.Timestamptz `json:"completed_at"`
+}
+
+// Analyses in the history of any member of the tenant.
+func (q *Queries) ListTenantAnalyses(ctx context.Context, tenantID pgtype.UUID) ([]ListTenantAnalysesRow, error) {
+	rows, err := q.db.Query(ctx, listTenantAnalyses, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTenantAnalysesRow{}
+	for rows.Next() {
+		var i ListTenantAnalysesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CodebaseID,
+			&i.CommitSha,
+			&i.BranchName,
+			&i.Status,
+			&i.ParserVersion,
+			&i.TotalSuites,
+			&i.TotalTests,
+			&i.CreatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantCodebases = `-- name: ListTenantCodebases :many
+SELECT c.id, c.host, c.owner, c.name, c.default_branch, c.is_private, c.created_at
+FROM codebases c
+WHERE EXISTS (
+    SELECT 1 FROM analyses a
+    JOIN user_analysis_history h ON h.analysis_id = a.id
+    JOIN tenant_members m ON m.user_id = h.user_id
+    WHERE a.codebase_id = c.id AND m.tenant_id = $1
+)
+ORDER BY c.host, c.owner, c.name
+`
+
+type ListTenantCodebasesRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	Host          string             `json:"host"`
+	Owner         string             `json:"owner"`
+	Name          string             `json:"name"`
+	DefaultBranch pgtype.Text        `json:"default_branch"`
+	IsPrivate     bool               `json:"is_private"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+// Codebases any member of the tenant has analyzed.
+func (q *Queries) ListTenantCodebases(ctx context.Context, tenantID pgtype.UUID) ([]ListTenantCodebasesRow, error) {
+	rows, err := q.db.Query(ctx, listTenantCodebases, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTenantCodebasesRow{}
+	for rows.Next() {
+		var i ListTenantCodebasesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Host,
+			&i.Owner,
+			&i.Name,
+			&i.DefaultBranch,
+			&i.IsPrivate,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantDocumentIDs = `-- name: ListTenantDocumentIDs :many
+SELECT sd.id
+FROM spec_documents sd
+JOIN tenant_members m ON m.user_id = sd.user_id
+WHERE m.tenant_id = $1
+ORDER BY sd.created_at, sd.id
+`
+
+func (q *Queries) ListTenantDocumentIDs(ctx context.Context, tenantID pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listTenantDocumentIDs, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantDocumentKeys = `-- name: ListTenantDocumentKeys :many
+SELECT id, wrapped_key FROM tenant_document_keys ORDER BY id
+`
+
+type ListTenantDocumentKeysRow struct {
+	ID         pgtype.UUID `json:"id"`
+	WrappedKey string      `json:"wrapped_key"`
+}
+
+func (q *Queries) ListTenantDocumentKeys(ctx context.Context) ([]ListTenantDocumentKeysRow, error) {
+	rows, err := q.db.Query(ctx, listTenantDocumentKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTenantDocumentKeysRow{}
+	for rows.Next() {
+		var i ListTenantDocumentKeysRow
+		if err := rows.Scan(&i.ID, &i.WrappedKey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantDocumentsToReseal = `-- name: ListTenantDocumentsToReseal :many
+SELECT sd.id
+FROM spec_documents sd
+JOIN tenant_members m ON m.user_id = sd.user_id
+WHERE m.tenant_id = $1
+  AND sd.encryption_key_id IS DISTINCT FROM $2::uuid
+ORDER BY sd.id
+LIMIT $3
+`
+
+type ListTenantDocumentsToResealParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	KeyID     pgtype.UUID `json:"key_id"`
+	BatchSize int32       `json:"batch_size"`
+}
+
+// Documents of the tenant's members not sealed with @key_id, plaintext ones included.
+func (q *Queries) ListTenantDocumentsToReseal(ctx context.Context, arg ListTenantDocumentsToResealParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listTenantDocumentsToReseal, arg.TenantID, arg.KeyID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantMembers = `-- name: ListTenantMembers :many
+SELECT user_id, created_at FROM tenant_members
+WHERE tenant_id = $1
+ORDER BY created_at, user_id
+`
+
+type ListTenantMembersRow struct {
+	UserID    pgtype.UUID        `json:"user_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) ListTenantMembers(ctx context.Context, tenantID pgtype.UUID) ([]ListTenantMembersRow, error) {
+	rows, err := q.db.Query(ctx, listTenantMembers, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTenantMembersRow{}
+	for rows.Next() {
+		var i ListTenantMembersRow
+		if err := rows.Scan(&i.UserID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantUsageEvents = `-- name: ListTenantUsageEvents :many
+SELECT e.id, e.user_id, e.event_type, e.analysis_id, e.document_id, e.quota_amount, e.created_at
+FROM usage_events e
+JOIN tenant_members m ON m.user_id = e.user_id
+WHERE m.tenant_id = $1
+ORDER BY e.created_at, e.id
+`
+
+func (q *Queries) ListTenantUsageEvents(ctx context.Context, tenantID pgtype.UUID) ([]UsageEvent, error) {
+	rows, err := q.db.Query(ctx, listTenantUsageEvents, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UsageEvent{}
+	for rows.Next() {
+		var i UsageEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.EventType,
+			&i.AnalysisID,
+			&i.DocumentID,
+			&i.QuotaAmount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const linkSpecDocumentDecisions = `-- name: LinkSpecDocumentDecisions :exec
+UPDATE spec_document_decisions SET document_id = $1
+WHERE generation_id = $2
+`
+
+type LinkSpecDocumentDecisionsParams struct {
+	DocumentID   pgtype.UUID `json:"document_id"`
+	GenerationID pgtype.UUID `json:"generation_id"`
+}
+
+func (q *Queries) LinkSpecDocumentDecisions(ctx context.Context, arg LinkSpecDocumentDecisionsParams) error {
+	_, err := q.db.Exec(ctx, linkSpecDocumentDecisions, arg.DocumentID, arg.GenerationID)
+	return err
+}
+
+const lockSpecDocumentText = `-- name: LockSpecDocumentText :one
+SELECT executive_summary, encryption_key_id FROM spec_documents
+WHERE id = $1
+FOR UPDATE
+`
+
+type LockSpecDocumentTextRow struct {
+	ExecutiveSummary pgtype.Text `json:"executive_summary"`
+	EncryptionKeyID  pgtype.UUID `json:"encryption_key_id"`
+}
+
+func (q *Queries) LockSpecDocumentText(ctx context.Context, id pgtype.UUID) (LockSpecDocumentTextRow, error) {
+	row := q.db.QueryRow(ctx, lockSpecDocumentText, id)
+	var i LockSpecDocumentTextRow
+	err := row.Scan(&i.ExecutiveSummary, &i.EncryptionKeyID)
+	return i, err
+}
+
+const markBehaviorFeedbackApplied = `-- name: MarkBehaviorFeedbackApplied :execrows
+UPDATE behavior_feedback
+SET status = 'applied', applied_at = now()
+WHERE id = $1 AND status = 'accepted'
+`
+
+func (q *Queries) MarkBehaviorFeedbackApplied(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, markBehaviorFeedbackApplied, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markCodebaseStale = `-- name: MarkCodebaseStale :exec
+UPDATE codebases SET is_stale = true, updated_at = now() WHERE id = $1
+`
+
+func (q *Queries) MarkCodebaseStale(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, markCodebaseStale, id)
+	return err
+}
+
+const markCoverageUploadProcessed = `-- name: MarkCoverageUploadProcessed :execrows
+UPDATE coverage_uploads SET processed_at = now()
+WHERE id = $1 AND processed_at IS NULL
+`
+
+// Claims an upload for ingestion; zero rows means it was already processed.
+func (q *Queries) MarkCoverageUploadProcessed(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, markCoverageUploadProcessed, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markOutboxEventFailed = `-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events
+SET next_attempt_at = $1,
+    last_error = $2
+WHERE id = $3
+`
+
+type MarkOutboxEventFailedParams struct {
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	LastError     pgtype.Text        `json:"last_error"`
+	ID            pgtype.UUID        `json:"id"`
+}
+
+func (q *Queries) MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error {
+	_, err := q.db.Exec(ctx, markOutboxEventFailed, arg.NextAttemptAt, arg.LastError, arg.ID)
+	return err
+}
+
+const markOutboxEventPublished = `-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+SET published_at = now(),
+    last_error = NULL
+WHERE id = $1
+`
+
+func (q *Queries) MarkOutboxEventPublished(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, markOutboxEventPublished, id)
+	return err
+}
+
+const markRegenTargetEnqueued = `-- name: MarkRegenTargetEnqueued :exec
+UPDATE regen_campaign_targets
+SET status = 'enqueued', job_id = $1, enqueued_at = now()
+WHERE id = $2
+`
+
+type MarkRegenTargetEnqueuedParams struct {
+	JobID pgtype.Int8 `json:"job_id"`
+	ID    pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) MarkRegenTargetEnqueued(ctx context.Context, arg MarkRegenTargetEnqueuedParams) error {
+	_, err := q.db.Exec(ctx, markRegenTargetEnqueued, arg.JobID, arg.ID)
+	return err
+}
+
+const markRegenTargetFailed = `-- name: MarkRegenTargetFailed :exec
+UPDATE regen_campaign_targets
+SET status = 'failed', error = $1, finished_at = now()
+WHERE id = $2
+`
+
+type MarkRegenTargetFailedParams struct {
+	Error pgtype.Text `json:"error"`
+	ID    pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) MarkRegenTargetFailed(ctx context.Context, arg MarkRegenTargetFailedParams) error {
+	_, err := q.db.Exec(ctx, markRegenTargetFailed, arg.Error, arg.ID)
+	return err
+}
+
+const markTestResultUploadProcessed = `-- name: MarkTestResultUploadProcessed :execrows
+UPDATE test_result_uploads SET processed_at = now()
+WHERE id = $1 AND processed_at IS NULL
+`
+
+// Claims an upload for ingestion; zero rows means it was already processed.
+func (q *Queries) MarkTestResultUploadProcessed(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, markTestResultUploadProcessed, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const mergeRiverJobMetadata = `-- name: MergeRiverJobMetadata :exec
+UPDATE river_job
+SET metadata = metadata || $1::jsonb
+WHERE id = $2
+`
+
+type MergeRiverJobMetadataParams struct {
+	Metadata []byte `json:"metadata"`
+	ID       int64  `json:"id"`
+}
+
+// Merges keys into a running job's metadata; River merges its own updates on completion.
+func (q *Queries) MergeRiverJobMetadata(ctx context.Context, arg MergeRiverJobMetadataParams) error {
+	_, err := q.db.Exec(ctx, mergeRiverJobMetadata, arg.Metadata, arg.ID)
+	return err
+}
+
+const pinSpecDocument = `-- name: PinSpecDocument :one
+INSERT INTO spec_document_pins (document_id, release_tag, pinned_by)
+SELECT sd.id, $1, $2
+FROM spec_documents sd
+WHERE sd.id = $3
+  AND sd.parent_document_id IS NULL
+  AND sd.deleted_at IS NULL
+ON CONFLICT (document_id) DO UPDATE SET release_tag = spec_document_pins.release_tag
+RETURNING document_id, release_tag, pinned_by, pinned_at
+`
+
+type PinSpecDocumentParams struct {
+	ReleaseTag string      `json:"release_tag"`
+	PinnedBy   pgtype.Text `json:"pinned_by"`
+	DocumentID pgtype.UUID `json:"document_id"`
+}
+
+// Only full, undeleted documents are pinned. Pinning a pinned document keeps
+// its pin and returns it, so the caller can tell a repeat from a conflicting
+// tag.
+func (q *Queries) PinSpecDocument(ctx context.Context, arg PinSpecDocumentParams) (SpecDocumentPin, error) {
+	row := q.db.QueryRow(ctx, pinSpecDocument, arg.ReleaseTag, arg.PinnedBy, arg.DocumentID)
+	var i SpecDocumentPin
+	err := row.Scan(
+		&i.DocumentID,
+		&i.ReleaseTag,
+		&i.PinnedBy,
+		&i.PinnedAt,
+	)
+	return i, err
+}
+
+const promoteStagedTestCases = `-- name: PromoteStagedTestCases :exec
+INSERT INTO test_cases (suite_id, name, line_number, status, tags, modifier, variant_count)
+SELECT suite_id, name, line_number, status, tags, modifier, variant_count
+FROM analysis_staging_test_cases
+WHERE analysis_id = $1
+`
+
+func (q *Queries) PromoteStagedTestCases(ctx context.Context, analysisID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, promoteStagedTestCases, analysisID)
+	return err
+}
+
+const promoteStagedTestFiles = `-- name: PromoteStagedTestFiles :exec
+
+INSERT INTO test_files (id, analysis_id, file_path, framework, domain_hints, project, content_hash)
+SELECT id, analysis_id, file_path, framework, domain_hints, project, content_hash
+FROM analysis_staging_test_files
+WHERE analysis_id = $1
+`
+
+// =============================================================================
+// ANALYSIS INVENTORY STAGING
+// =============================================================================
+func (q *Queries) PromoteStagedTestFiles(ctx context.Context, analysisID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, promoteStagedTestFiles, analysisID)
+	return err
+}
+
+const promoteStagedTestSuites = `-- name: PromoteStagedTestSuites :exec
+INSERT INTO test_suites (id, parent_id, name, line_number, depth, file_id)
+SELECT id, parent_id, name, line_number, depth, file_id
+FROM analysis_staging_test_suites
+WHERE analysis_id = $1
+`
+
+// A single statement, so parents staged after their children still satisfy the parent key.
+func (q *Queries) PromoteStagedTestSuites(ctx context.Context, analysisID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, promoteStagedTestSuites, analysisID)
+	return err
+}
+
+const rebuildSpecSearchEntries = `-- name: RebuildSpecSearchEntries :execrows
+INSERT INTO spec_search_entries (document_id, codebase_id, document_version, language, behavior_id, domain_name, feature_name, behavior_description)
+SELECT sd.id, a.codebase_id, sd.version, sd.language, sb.id, dom.name, sf.name, sb.converted_description
+FROM spec_documents sd
+JOIN analyses a ON a.id = sd.analysis_id
+JOIN spec_domains dom ON dom.document_id = sd.id
+JOIN spec_features sf ON sf.domain_id = dom.id
+JOIN spec_behaviors sb ON sb.feature_id = sf.id
+WHERE sd.id = $1
+  AND sd.encryption_key_id IS NULL
+  AND sd.deleted_at IS NULL
+`
+
+// Indexes the saved behaviors of the document; encrypted and deleted documents
+// are skipped.
+func (q *Queries) RebuildSpecSearchEntries(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, rebuildSpecSearchEntries, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recordAnalysisUsageEvent = `-- name: RecordAnalysisUsageEvent :exec
+INSERT INTO usage_events (user_id, event_type, analysis_id, quota_amount)
+VALUES ($1, 'analysis', $2, $3)
+`
+
+type RecordAnalysisUsageEventParams struct {
+	UserID      pgtype.UUID `json:"user_id"`
+	AnalysisID  pgtype.UUID `json:"analysis_id"`
+	QuotaAmount int32       `json:"quota_amount"`
+}
+
+func (q *Queries) RecordAnalysisUsageEvent(ctx context.Context, arg RecordAnalysisUsageEventParams) error {
+	_, err := q.db.Exec(ctx, recordAnalysisUsageEvent, arg.UserID, arg.AnalysisID, arg.QuotaAmount)
+	return err
+}
+
+const recordSpecViewUsageEvent = `-- name: RecordSpecViewUsageEvent :exec
+
+INSERT INTO usage_events (user_id, event_type, document_id, quota_amount)
+VALUES ($1, 'specview', $2, $3)
+`
+
+type RecordSpecViewUsageEventParams struct {
+	UserID      pgtype.UUID `json:"user_id"`
+	DocumentID  pgtype.UUID `json:"document_id"`
+	QuotaAmount int32       `json:"quota_amount"`
+}
+
+// =============================================================================
+// USAGE EVENTS
+// =============================================================================
+func (q *Queries) RecordSpecViewUsageEvent(ctx context.Context, arg RecordSpecViewUsageEventParams) error {
+	_, err := q.db.Exec(ctx, recordSpecViewUsageEvent, arg.UserID, arg.DocumentID, arg.QuotaAmount)
+	return err
+}
+
+const recordTenantAIUsage = `-- name: RecordTenantAIUsage :exec
+INSERT INTO tenant_ai_usage (tenant_id, job_id, tokens)
+VALUES ($1, $2, $3)
+`
+
+type RecordTenantAIUsageParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	JobID    pgtype.Int8 `json:"job_id"`
+	Tokens   int64       `json:"tokens"`
+}
+
+func (q *Queries) RecordTenantAIUsage(ctx context.Context, arg RecordTenantAIUsageParams) error {
+	_, err := q.db.Exec(ctx, recordTenantAIUsage, arg.TenantID, arg.JobID, arg.Tokens)
+	return err
+}
+
+const recordUserAnalysisHistory = `-- name: RecordUserAnalysisHistory :exec
+INSERT INTO user_analysis_history (user_id, analysis_id, retention_days_at_creation)
+VALUES ($1, $2, $3)
+ON CONFLICT ON CONSTRAINT uq_user_analysis_history_user_analysis
+DO UPDATE SET updated_at = now()
+`
+
+type RecordUserAnalysisHistoryParams struct {
+	UserID                  pgtype.UUID `json:"user_id"`
+	AnalysisID              pgtype.UUID `json:"analysis_id"`
+	RetentionDaysAtCreation pgtype.Int4 `json:"retention_days_at_creation"`
+}
+
+func (q *Queries) RecordUserAnalysisHistory(ctx context.Context, arg RecordUserAnalysisHistoryParams) error {
+	_, err := q.db.Exec(ctx, recordUserAnalysisHistory, arg.UserID, arg.AnalysisID, arg.RetentionDaysAtCreation)
+	return err
+}
+
+const recordUserSpecviewHistory = `-- name: RecordUserSpecviewHistory :exec
+INSERT INTO user_specview_history (user_id, document_id)
+VALUES ($1, $2)
+ON CONFLICT ON CONSTRAINT uq_user_specview_history_user_document
+DO UPDATE SET updated_at = now()
+`
+
+type RecordUserSpecviewHistoryParams struct {
+	UserID     pgtype.UUID `json:"user_id"`
+	DocumentID pgtype.UUID `json:"document_id"`
+}
+
+func (q *Queries) RecordUserSpecviewHistory(ctx context.Context, arg RecordUserSpecviewHistoryParams) error {
+	_, err := q.db.Exec(ctx, recordUserSpecviewHistory, arg.UserID, arg.DocumentID)
+	return err
+}
+
+const releaseSpecGenerationClaim = `-- name: ReleaseSpecGenerationClaim :exec
+DELETE FROM spec_generation_claims WHERE claim_key = $1 AND job_id = $2
+`
+
+type ReleaseSpecGenerationClaimParams struct {
+	ClaimKey []byte `json:"claim_key"`
+	JobID    int64  `json:"job_id"`
+}
+
+func (q *Queries) ReleaseSpecGenerationClaim(ctx context.Context, arg ReleaseSpecGenerationClaimParams) error {
+	_, err := q.db.Exec(ctx, releaseSpecGenerationClaim, arg.ClaimKey, arg.JobID)
+	return err
+}
+
+const releaseWebhookDelivery = `-- name: ReleaseWebhookDelivery :exec
+DELETE FROM webhook_deliveries
+WHERE delivery_id = $1 AND outcome IS NULL
+`
+
+// Forgets an unfinished delivery so that a redelivery is processed again.
+func (q *Queries) ReleaseWebhookDelivery(ctx context.Context, deliveryID string) error {
+	_, err := q.db.Exec(ctx, releaseWebhookDelivery, deliveryID)
+	return err
+}
+
+const replaceOAuthAccessToken = `-- name: ReplaceOAuthAccessToken :execrows
+UPDATE oauth_accounts
+SET access_token = $1::text
+WHERE id = $2 AND access_token = $3::text
+`
+
+type ReplaceOAuthAccessTokenParams struct {
+	NewToken string      `json:"new_token"`
+	ID       pgtype.UUID `json:"id"`
+	OldToken string      `json:"old_token"`
+}
+
+// Zero rows means the token changed since it was read, e.g. by a new login.
+func (q *Queries) ReplaceOAuthAccessToken(ctx context.Context, arg ReplaceOAuthAccessTokenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, replaceOAuthAccessToken, arg.NewToken, arg.ID, arg.OldToken)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreSpecDocument = `-- name: RestoreSpecDocument :execrows
+UPDATE spec_documents
+SET deleted_at = NULL,
+    deleted_by = NULL
+WHERE id = $1
+  AND parent_document_id IS NULL
+  AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreSpecDocument(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreSpecDocument, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const retireTenantDocumentKey = `-- name: RetireTenantDocumentKey :execrows
+UPDATE tenant_document_keys SET retired_at = now()
+WHERE tenant_id = $1 AND retired_at IS NULL
+`
+
+func (q *Queries) RetireTenantDocumentKey(ctx context.Context, tenantID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, retireTenantDocumentKey, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const returnRateLimitToken = `-- name: ReturnRateLimitToken :exec
+UPDATE rate_limit_buckets
+SET tokens = LEAST($1::float8, tokens + 1)
+WHERE bucket_key = $2
+`
+
+type ReturnRateLimitTokenParams struct {
+	Burst     float64 `json:"burst"`
+	BucketKey string  `json:"bucket_key"`
+}
+
+// Puts back a token taken by TakeRateLimitToken. The refill clock is left
+// alone, so the bucket refills as if the token had not been taken.
+func (q *Queries) ReturnRateLimitToken(ctx context.Context, arg ReturnRateLimitTokenParams) error {
+	_, err := q.db.Exec(ctx, returnRateLimitToken, arg.Burst, arg.BucketKey)
+	return err
+}
+
+const revokeServiceToken = `-- name: RevokeServiceToken :execrows
+UPDATE service_tokens SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeServiceToken(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeServiceToken, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setIdempotencyKeyJob = `-- name: SetIdempotencyKeyJob :exec
+UPDATE enqueue_idempotency_keys SET job_id = $1
+WHERE idempotency_key = $2
 `
 
-func (q *Queries) MarkCodebaseStale(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, markCodebaseStale, id)
+type SetIdempotencyKeyJobParams struct {
+	JobID          pgtype.Int8 `json:"job_id"`
+	IdempotencyKey string      `json:"idempotency_key"`
+}
+
+func (q *Queries) SetIdempotencyKeyJob(ctx context.Context, arg SetIdempotencyKeyJobParams) error {
+	_, err := q.db.Exec(ctx, setIdempotencyKeyJob, arg.JobID, arg.IdempotencyKey)
 	return err
 }
 
-const recordAnalysisUsageEvent = `-- name: RecordAnalysisUsageEvent :exec
-INSERT INTO usage_events (user_id, event_type, analysis_id, quota_amount)
-VALUES ($1, 'analysis', $2, $3)
+const setTenantDocumentEncryption = `-- name: SetTenantDocumentEncryption :execrows
+UPDATE tenants SET document_encryption = $1 WHERE id = $2
 `
 
-type RecordAnalysisUsageEventParams struct {
-	UserID      pgtype.UUID `json:"user_id"`
-	AnalysisID  pgtype.UUID `json:"analysis_id"`
-	QuotaAmount int32       `json:"quota_amount"`
+type SetTenantDocumentEncryptionParams struct {
+	Enabled  bool        `json:"enabled"`
+	TenantID pgtype.UUID `json:"tenant_id"`
 }
 
-func (q *Queries) RecordAnalysisUsageEvent(ctx context.Context, arg RecordAnalysisUsageEventParams) error {
-	_, err := q.db.Exec(ctx, recordAnalysisUsageEvent, arg.UserID, arg.AnalysisID, arg.QuotaAmount)
-	return err
+func (q *Queries) SetTenantDocumentEncryption(ctx context.Context, arg SetTenantDocumentEncryptionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setTenantDocumentEncryption, arg.Enabled, arg.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const recordSpecViewUsageEvent = `-- name: RecordSpecViewUsageEvent :exec
-
-INSERT INTO usage_events (user_id, event_type, document_id, quota_amount)
-VALUES ($1, 'specview', $2, $3)
+const softDeleteSpecDocument = `-- name: SoftDeleteSpecDocument :one
+UPDATE spec_documents
+SET deleted_at = COALESCE(deleted_at, now()),
+    deleted_by = CASE WHEN deleted_at IS NULL THEN $1 ELSE deleted_by END
+WHERE id = $2
+  AND parent_document_id IS NULL
+RETURNING id, deleted_at, deleted_by
 `
 
-type RecordSpecViewUsageEventParams struct {
-	UserID      pgtype.UUID `json:"user_id"`
-	DocumentID  pgtype.UUID `json:"document_id"`
-	QuotaAmount int32       `json:"quota_amount"`
+type SoftDeleteSpecDocumentParams struct {
+	DeletedBy  pgtype.Text `json:"deleted_by"`
+	DocumentID pgtype.UUID `json:"document_id"`
 }
 
-// =============================================================================
-// USAGE EVENTS
-// =============================================================================
-func (q *Queries) RecordSpecViewUsageEvent(ctx context.Context, arg RecordSpecViewUsageEventParams) error {
-	_, err := q.db.Exec(ctx, recordSpecViewUsageEvent, arg.UserID, arg.DocumentID, arg.QuotaAmount)
-	return err
+type SoftDeleteSpecDocumentRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+	DeletedBy pgtype.Text        `json:"deleted_by"`
 }
 
-const recordUserAnalysisHistory = `-- name: RecordUserAnalysisHistory :exec
-INSERT INTO user_analysis_history (user_id, analysis_id, retention_days_at_creation)
-VALUES ($1, $2, $3)
-ON CONFLICT ON CONSTRAINT uq_user_analysis_history_user_analysis
-DO UPDATE SET updated_at = now()
+// ==============================================================================
+// SPEC DOCUMENT LIFECYCLE
+// ==============================================================================
+// Only full documents are deleted; their team slices follow them. Deleting a
+// deleted document keeps its first deletion.
+func (q *Queries) SoftDeleteSpecDocument(ctx context.Context, arg SoftDeleteSpecDocumentParams) (SoftDeleteSpecDocumentRow, error) {
+	row := q.db.QueryRow(ctx, softDeleteSpecDocument, arg.DeletedBy, arg.DocumentID)
+	var i SoftDeleteSpecDocumentRow
+	err := row.Scan(&i.ID, &i.DeletedAt, &i.DeletedBy)
+	return i, err
+}
+
+const startTenantTakeout = `-- name: StartTenantTakeout :execrows
+UPDATE tenant_takeouts SET status = 'running', error_message = NULL
+WHERE id = $1 AND status <> 'completed'
 `
 
-type RecordUserAnalysisHistoryParams struct {
-	UserID                  pgtype.UUID `json:"user_id"`
-	AnalysisID              pgtype.UUID `json:"analysis_id"`
-	RetentionDaysAtCreation pgtype.Int4 `json:"retention_days_at_creation"`
+// Zero rows means the takeout is already completed.
+func (q *Queries) StartTenantTakeout(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, startTenantTakeout, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-func (q *Queries) RecordUserAnalysisHistory(ctx context.Context, arg RecordUserAnalysisHistoryParams) error {
-	_, err := q.db.Exec(ctx, recordUserAnalysisHistory, arg.UserID, arg.AnalysisID, arg.RetentionDaysAtCreation)
-	return err
+const syncRegenTargets = `-- name: SyncRegenTargets :execrows
+UPDATE regen_campaign_targets t
+SET status = CASE WHEN j.state = 'completed' THEN 'succeeded' ELSE 'failed' END,
+    error = CASE
+        WHEN j.id IS NULL THEN 'job no longer exists'
+        WHEN j.state = 'completed' THEN NULL
+        ELSE COALESCE(j.errors[array_upper(j.errors, 1)] ->> 'error', j.state::text)
+    END,
+    finished_at = now()
+FROM regen_campaign_targets s
+LEFT JOIN river_job j ON j.id = s.job_id
+WHERE t.id = s.id
+  AND s.campaign_id = $1
+  AND s.status = 'enqueued'
+  AND (j.id IS NULL OR j.state IN ('completed', 'cancelled', 'discarded'))
+`
+
+// Settles enqueued targets whose job is finalized. A job pruned from river_job
+// before it was seen counts as failed.
+func (q *Queries) SyncRegenTargets(ctx context.Context, campaignID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, syncRegenTargets, campaignID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const recordUserSpecviewHistory = `-- name: RecordUserSpecviewHistory :exec
-INSERT INTO user_specview_history (user_id, document_id)
-VALUES ($1, $2)
-ON CONFLICT ON CONSTRAINT uq_user_specview_history_user_document
-DO UPDATE SET updated_at = now()
+const takeRateLimitToken = `-- name: TakeRateLimitToken :one
+INSERT INTO rate_limit_buckets AS b (bucket_key, tokens, updated_at)
+VALUES ($1, $2::float8 - 1, now())
+ON CONFLICT (bucket_key) DO UPDATE
+SET tokens = LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $3::float8) - 1,
+    updated_at = now()
+WHERE LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $3::float8) >= 1
+RETURNING tokens
 `
 
-type RecordUserSpecviewHistoryParams struct {
-	UserID     pgtype.UUID `json:"user_id"`
-	DocumentID pgtype.UUID `json:"document_id"`
+type TakeRateLimitTokenParams struct {
+	BucketKey       string  `json:"bucket_key"`
+	Burst           float64 `json:"burst"`
+	RefillPerSecond float64 `json:"refill_per_second"`
 }
 
-func (q *Queries) RecordUserSpecviewHistory(ctx context.Context, arg RecordUserSpecviewHistoryParams) error {
-	_, err := q.db.Exec(ctx, recordUserSpecviewHistory, arg.UserID, arg.DocumentID)
+// Token bucket: refills for the time elapsed since the last take (capped at burst)
+// and takes one token. Returns no row when fewer than one token is available.
+func (q *Queries) TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (float64, error) {
+	row := q.db.QueryRow(ctx, takeRateLimitToken, arg.BucketKey, arg.Burst, arg.RefillPerSecond)
+	var tokens float64
+	err := row.Scan(&tokens)
+	return tokens, err
+}
+
+const touchServiceToken = `-- name: TouchServiceToken :exec
+UPDATE service_tokens SET last_used_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) TouchServiceToken(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, touchServiceToken, id)
 	return err
 }
 
@@ -922,7 +5828,7 @@ const unmarkCodebaseStale = `-- name: UnmarkCodebaseStale :one
 UPDATE codebases
 SET is_stale = false, owner = $2, name = $3, updated_at = now()
 WHERE id = $1
-RETURNING id, host, owner, name, default_branch, created_at, updated_at, last_viewed_at, external_repo_id, is_stale, is_private
+RETURNING id, host, owner, name, default_branch, created_at, updated_at, last_viewed_at, external_repo_id, is_stale, is_private, region, fork_dedup_enabled
 `
 
 type UnmarkCodebaseStaleParams struct {
@@ -946,10 +5852,24 @@ func (q *Queries) UnmarkCodebaseStale(ctx context.Context, arg UnmarkCodebaseSta
 		&i.ExternalRepoID,
 		&i.IsStale,
 		&i.IsPrivate,
+		&i.Region,
+		&i.ForkDedupEnabled,
 	)
 	return i, err
 }
 
+const unpinSpecDocument = `-- name: UnpinSpecDocument :execrows
+DELETE FROM spec_document_pins WHERE document_id = $1
+`
+
+func (q *Queries) UnpinSpecDocument(ctx context.Context, documentID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, unpinSpecDocument, documentID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateAnalysisCompleted = `-- name: UpdateAnalysisCompleted :exec
 UPDATE analyses
 SET status = 'completed', total_suites = $2, total_tests = $3, completed_at = $4, committed_at = $5
@@ -975,20 +5895,95 @@ func (q *Queries) UpdateAnalysisCompleted(ctx context.Context, arg UpdateAnalysi
 	return err
 }
 
+const updateAnalysisCurationRules = `-- name: UpdateAnalysisCurationRules :exec
+UPDATE analyses
+SET curation_rules = $2
+WHERE id = $1
+`
+
+type UpdateAnalysisCurationRulesParams struct {
+	ID            pgtype.UUID `json:"id"`
+	CurationRules []byte      `json:"curation_rules"`
+}
+
+func (q *Queries) UpdateAnalysisCurationRules(ctx context.Context, arg UpdateAnalysisCurationRulesParams) error {
+	_, err := q.db.Exec(ctx, updateAnalysisCurationRules, arg.ID, arg.CurationRules)
+	return err
+}
+
 const updateAnalysisFailed = `-- name: UpdateAnalysisFailed :exec
 UPDATE analyses
-SET status = 'failed', error_message = $2, completed_at = $3
+SET status = 'failed', error_message = $2, completed_at = $3, failure_class = $4
 WHERE id = $1
 `
 
 type UpdateAnalysisFailedParams struct {
-	ID           pgtype.UUID        `json:"id"`
-	ErrorMessage pgtype.Text        `json:"error_message"`
-	CompletedAt  pgtype.Timestamptz `json:"completed_at"`
+	ID           pgtype.UUID              `json:"id"`
+	ErrorMessage pgtype.Text              `json:"error_message"`
+	CompletedAt  pgtype.Timestamptz       `json:"completed_at"`
+	FailureClass NullAnalysisFailureClass `json:"failure_class"`
 }
 
 func (q *Queries) UpdateAnalysisFailed(ctx context.Context, arg UpdateAnalysisFailedParams) error {
-	_, err := q.db.Exec(ctx, updateAnalysisFailed, arg.ID, arg.ErrorMessage, arg.CompletedAt)
+	_, err := q.db.Exec(ctx, updateAnalysisFailed,
+		arg.ID,
+		arg.ErrorMessage,
+		arg.CompletedAt,
+		arg.FailureClass,
+	)
+	return err
+}
+
+const updateAnalysisFingerprint = `-- name: UpdateAnalysisFingerprint :exec
+UPDATE analyses
+SET test_fingerprint = $2, deduplicated_from = $3
+WHERE id = $1
+`
+
+type UpdateAnalysisFingerprintParams struct {
+	ID               pgtype.UUID `json:"id"`
+	TestFingerprint  []byte      `json:"test_fingerprint"`
+	DeduplicatedFrom pgtype.UUID `json:"deduplicated_from"`
+}
+
+func (q *Queries) UpdateAnalysisFingerprint(ctx context.Context, arg UpdateAnalysisFingerprintParams) error {
+	_, err := q.db.Exec(ctx, updateAnalysisFingerprint, arg.ID, arg.TestFingerprint, arg.DeduplicatedFrom)
+	return err
+}
+
+const updateAnalysisQuarantinedFiles = `-- name: UpdateAnalysisQuarantinedFiles :exec
+UPDATE analyses
+SET quarantined_files = (SELECT COUNT(*) FROM parse_errors WHERE analysis_id = $1)
+WHERE id = $1
+`
+
+func (q *Queries) UpdateAnalysisQuarantinedFiles(ctx context.Context, analysisID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, updateAnalysisQuarantinedFiles, analysisID)
+	return err
+}
+
+const updateAnalysisSource = `-- name: UpdateAnalysisSource :exec
+UPDATE analyses
+SET codebase_id = $2, commit_sha = $3, branch_name = $4, path_filters = $5
+WHERE id = $1
+`
+
+type UpdateAnalysisSourceParams struct {
+	ID          pgtype.UUID `json:"id"`
+	CodebaseID  pgtype.UUID `json:"codebase_id"`
+	CommitSha   string      `json:"commit_sha"`
+	BranchName  pgtype.Text `json:"branch_name"`
+	PathFilters []byte      `json:"path_filters"`
+}
+
+func (q *Queries) UpdateAnalysisSource(ctx context.Context, arg UpdateAnalysisSourceParams) error {
+	_, err := q.db.Exec(ctx, updateAnalysisSource,
+		arg.ID,
+		arg.CodebaseID,
+		arg.CommitSha,
+		arg.BranchName,
+		arg.PathFilters,
+	)
 	return err
 }
 
@@ -996,7 +5991,7 @@ const updateCodebaseOwnerName = `-- name: UpdateCodebaseOwnerName :one
 UPDATE codebases
 SET owner = $2, name = $3, updated_at = now()
 WHERE id = $1
-RETURNING id, host, owner, name, default_branch, created_at, updated_at, last_viewed_at, external_repo_id, is_stale, is_private
+RETURNING id, host, owner, name, default_branch, created_at, updated_at, last_viewed_at, external_repo_id, is_stale, is_private, region, fork_dedup_enabled
 `
 
 type UpdateCodebaseOwnerNameParams struct {
@@ -1020,6 +6015,8 @@ func (q *Queries) UpdateCodebaseOwnerName(ctx context.Context, arg UpdateCodebas
 		&i.ExternalRepoID,
 		&i.IsStale,
 		&i.IsPrivate,
+		&i.Region,
+		&i.ForkDedupEnabled,
 	)
 	return i, err
 }
@@ -1040,40 +6037,120 @@ func (q *Queries) UpdateCodebaseVisibility(ctx context.Context, arg UpdateCodeba
 	return err
 }
 
+const updatePendingRiverJobPriority = `-- name: UpdatePendingRiverJobPriority :execrows
+UPDATE river_job
+SET priority = $1
+WHERE id = $2
+  AND state IN ('available', 'pending', 'retryable', 'scheduled')
+`
+
+type UpdatePendingRiverJobPriorityParams struct {
+	Priority int16 `json:"priority"`
+	ID       int64 `json:"id"`
+}
+
+// Only jobs not yet fetched; River reads the priority when it fetches a job.
+func (q *Queries) UpdatePendingRiverJobPriority(ctx context.Context, arg UpdatePendingRiverJobPriorityParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updatePendingRiverJobPriority, arg.Priority, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateRegenCampaignStatus = `-- name: UpdateRegenCampaignStatus :execrows
+UPDATE regen_campaigns SET status = $1, updated_at = now()
+WHERE id = $2 AND status = $3
+`
+
+type UpdateRegenCampaignStatusParams struct {
+	ToStatus   string      `json:"to_status"`
+	ID         pgtype.UUID `json:"id"`
+	FromStatus string      `json:"from_status"`
+}
+
+// Only applies when the status is still @from_status, so concurrent
+// transitions cannot overwrite each other.
+func (q *Queries) UpdateRegenCampaignStatus(ctx context.Context, arg UpdateRegenCampaignStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateRegenCampaignStatus, arg.ToStatus, arg.ID, arg.FromStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateSpecDocumentText = `-- name: UpdateSpecDocumentText :exec
+UPDATE spec_documents
+SET executive_summary = $1, encryption_key_id = $2
+WHERE id = $3
+`
+
+type UpdateSpecDocumentTextParams struct {
+	ExecutiveSummary pgtype.Text `json:"executive_summary"`
+	EncryptionKeyID  pgtype.UUID `json:"encryption_key_id"`
+	ID               pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateSpecDocumentText(ctx context.Context, arg UpdateSpecDocumentTextParams) error {
+	_, err := q.db.Exec(ctx, updateSpecDocumentText, arg.ExecutiveSummary, arg.EncryptionKeyID, arg.ID)
+	return err
+}
+
+const updateTenantDocumentKeyWrap = `-- name: UpdateTenantDocumentKeyWrap :exec
+UPDATE tenant_document_keys SET wrapped_key = $1 WHERE id = $2
+`
+
+type UpdateTenantDocumentKeyWrapParams struct {
+	WrappedKey string      `json:"wrapped_key"`
+	ID         pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateTenantDocumentKeyWrap(ctx context.Context, arg UpdateTenantDocumentKeyWrapParams) error {
+	_, err := q.db.Exec(ctx, updateTenantDocumentKeyWrap, arg.WrappedKey, arg.ID)
+	return err
+}
+
 const upsertBehaviorCache = `-- name: UpsertBehaviorCache :exec
-INSERT INTO behavior_caches (cache_key_hash, converted_description)
-VALUES ($1, $2)
+INSERT INTO behavior_caches (cache_key_hash, converted_description, codebase_id)
+VALUES ($1, $2, (SELECT a.codebase_id FROM analyses a WHERE a.id = $3))
 ON CONFLICT (cache_key_hash) DO UPDATE
-SET converted_description = EXCLUDED.converted_description
+SET converted_description = EXCLUDED.converted_description,
+    codebase_id = COALESCE(EXCLUDED.codebase_id, behavior_caches.codebase_id)
+WHERE NOT behavior_caches.human_verified
 `
 
 type UpsertBehaviorCacheParams struct {
-	CacheKeyHash         []byte `json:"cache_key_hash"`
-	ConvertedDescription string `json:"converted_description"`
+	CacheKeyHash         []byte      `json:"cache_key_hash"`
+	ConvertedDescription string      `json:"converted_description"`
+	AnalysisID           pgtype.UUID `json:"analysis_id"`
 }
 
+// The entry belongs to the codebase of the analysis that last wrote it.
 func (q *Queries) UpsertBehaviorCache(ctx context.Context, arg UpsertBehaviorCacheParams) error {
-	_, err := q.db.Exec(ctx, upsertBehaviorCache, arg.CacheKeyHash, arg.ConvertedDescription)
+	_, err := q.db.Exec(ctx, upsertBehaviorCache, arg.CacheKeyHash, arg.ConvertedDescription, arg.AnalysisID)
 	return err
 }
 
 const upsertClassificationCache = `-- name: UpsertClassificationCache :exec
-INSERT INTO classification_caches (content_hash, language, model_id, phase1_output, test_index_map)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO classification_caches (content_hash, language, model_id, phase1_output, test_index_map, codebase_id)
+VALUES ($1, $2, $3, $4, $5, (SELECT a.codebase_id FROM analyses a WHERE a.id = $6))
 ON CONFLICT ON CONSTRAINT uq_classification_caches_key DO UPDATE
 SET phase1_output = EXCLUDED.phase1_output,
     test_index_map = EXCLUDED.test_index_map,
+    codebase_id = COALESCE(EXCLUDED.codebase_id, classification_caches.codebase_id),
     created_at = now()
 `
 
 type UpsertClassificationCacheParams struct {
-	ContentHash  []byte `json:"content_hash"`
-	Language     string `json:"language"`
-	ModelID      string `json:"model_id"`
-	Phase1Output []byte `json:"phase1_output"`
-	TestIndexMap []byte `json:"test_index_map"`
+	ContentHash  []byte      `json:"content_hash"`
+	Language     string      `json:"language"`
+	ModelID      string      `json:"model_id"`
+	Phase1Output []byte      `json:"phase1_output"`
+	TestIndexMap []byte      `json:"test_index_map"`
+	AnalysisID   pgtype.UUID `json:"analysis_id"`
 }
 
+// The entry belongs to the codebase of the analysis that last wrote it.
 func (q *Queries) UpsertClassificationCache(ctx context.Context, arg UpsertClassificationCacheParams) error {
 	_, err := q.db.Exec(ctx, upsertClassificationCache,
 		arg.ContentHash,
@@ -1081,13 +6158,14 @@ func (q *Queries) UpsertClassificationCache(ctx context.Context, arg UpsertClass
 		arg.ModelID,
 		arg.Phase1Output,
 		arg.TestIndexMap,
+		arg.AnalysisID,
 	)
 	return err
 }
 
 const upsertCodebase = `-- name: UpsertCodebase :one
-INSERT INTO codebases (host, owner, name, default_branch, external_repo_id, is_private)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO codebases (host, owner, name, default_branch, external_repo_id, is_private, region)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 ON CONFLICT (host, external_repo_id)
 DO UPDATE SET
     owner = EXCLUDED.owner,
@@ -1095,8 +6173,9 @@ DO UPDATE SET
     default_branch = COALESCE(EXCLUDED.default_branch, codebases.default_branch),
     is_stale = false,
     is_private = EXCLUDED.is_private,
+    region = COALESCE(codebases.region, EXCLUDED.region),
     updated_at = now()
-RETURNING id, host, owner, name, default_branch, created_at, updated_at, last_viewed_at, external_repo_id, is_stale, is_private
+RETURNING id, host, owner, name, default_branch, created_at, updated_at, last_viewed_at, external_repo_id, is_stale, is_private, region, fork_dedup_enabled
 `
 
 type UpsertCodebaseParams struct {
@@ -1106,8 +6185,10 @@ type UpsertCodebaseParams struct {
 	DefaultBranch  pgtype.Text `json:"default_branch"`
 	ExternalRepoID string      `json:"external_repo_id"`
 	IsPrivate      bool        `json:"is_private"`
+	Region         pgtype.Text `json:"region"`
 }
 
+// Region is set on creation and never changed by later analyses.
 func (q *Queries) UpsertCodebase(ctx context.Context, arg UpsertCodebaseParams) (Codebasis, error) {
 	row := q.db.QueryRow(ctx, upsertCodebase,
 		arg.Host,
@@ -1116,6 +6197,7 @@ func (q *Queries) UpsertCodebase(ctx context.Context, arg UpsertCodebaseParams)
 		arg.DefaultBranch,
 		arg.ExternalRepoID,
 		arg.IsPrivate,
+		arg.Region,
 	)
 	var i Codebasis
 	err := row.Scan(
@@ -1130,10 +6212,222 @@ func (q *Queries) UpsertCodebase(ctx context.Context, arg UpsertCodebaseParams)
 		&i.ExternalRepoID,
 		&i.IsStale,
 		&i.IsPrivate,
+		&i.Region,
+		&i.ForkDedupEnabled,
 	)
 	return i, err
 }
 
+const upsertJobResult = `-- name: UpsertJobResult :exec
+INSERT INTO job_results (job_id, kind, queue, status, attempt, analysis_id, document_id, error_code, stats, user_id, enqueued_at, started_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (job_id) DO UPDATE SET
+    status = EXCLUDED.status,
+    attempt = EXCLUDED.attempt,
+    analysis_id = EXCLUDED.analysis_id,
+    document_id = EXCLUDED.document_id,
+    error_code = EXCLUDED.error_code,
+    stats = EXCLUDED.stats,
+    user_id = EXCLUDED.user_id,
+    enqueued_at = EXCLUDED.enqueued_at,
+    started_at = EXCLUDED.started_at,
+    completed_at = now()
+`
+
+type UpsertJobResultParams struct {
+	JobID      int64              `json:"job_id"`
+	Kind       string             `json:"kind"`
+	Queue      string             `json:"queue"`
+	Status     string             `json:"status"`
+	Attempt    int32              `json:"attempt"`
+	AnalysisID pgtype.UUID        `json:"analysis_id"`
+	DocumentID pgtype.UUID        `json:"document_id"`
+	ErrorCode  pgtype.Text        `json:"error_code"`
+	Stats      []byte             `json:"stats"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	EnqueuedAt pgtype.Timestamptz `json:"enqueued_at"`
+	StartedAt  pgtype.Timestamptz `json:"started_at"`
+}
+
+// ==============================================================================
+// JOB RESULTS
+// ==============================================================================
+// Each attempt replaces the result of the previous one.
+func (q *Queries) UpsertJobResult(ctx context.Context, arg UpsertJobResultParams) error {
+	_, err := q.db.Exec(ctx, upsertJobResult,
+		arg.JobID,
+		arg.Kind,
+		arg.Queue,
+		arg.Status,
+		arg.Attempt,
+		arg.AnalysisID,
+		arg.DocumentID,
+		arg.ErrorCode,
+		arg.Stats,
+		arg.UserID,
+		arg.EnqueuedAt,
+		arg.StartedAt,
+	)
+	return err
+}
+
+const upsertSpecDocumentExport = `-- name: UpsertSpecDocumentExport :exec
+INSERT INTO spec_document_exports (document_id, format, content_type, file_name, content)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (document_id, format) DO UPDATE SET
+    content_type = EXCLUDED.content_type,
+    file_name = EXCLUDED.file_name,
+    content = EXCLUDED.content,
+    updated_at = now()
+`
+
+type UpsertSpecDocumentExportParams struct {
+	DocumentID  pgtype.UUID `json:"document_id"`
+	Format      string      `json:"format"`
+	ContentType string      `json:"content_type"`
+	FileName    string      `json:"file_name"`
+	Content     []byte      `json:"content"`
+}
+
+func (q *Queries) UpsertSpecDocumentExport(ctx context.Context, arg UpsertSpecDocumentExportParams) error {
+	_, err := q.db.Exec(ctx, upsertSpecDocumentExport,
+		arg.DocumentID,
+		arg.Format,
+		arg.ContentType,
+		arg.FileName,
+		arg.Content,
+	)
+	return err
+}
+
+const upsertSpecDocumentShare = `-- name: UpsertSpecDocumentShare :exec
+INSERT INTO spec_document_shares (document_id, user_id, basis)
+VALUES ($1, $2, $3)
+ON CONFLICT (document_id, user_id) DO UPDATE SET
+    basis = EXCLUDED.basis,
+    shared_at = now()
+`
+
+type UpsertSpecDocumentShareParams struct {
+	DocumentID pgtype.UUID `json:"document_id"`
+	UserID     pgtype.UUID `json:"user_id"`
+	Basis      string      `json:"basis"`
+}
+
+func (q *Queries) UpsertSpecDocumentShare(ctx context.Context, arg UpsertSpecDocumentShareParams) error {
+	_, err := q.db.Exec(ctx, upsertSpecDocumentShare, arg.DocumentID, arg.UserID, arg.Basis)
+	return err
+}
+
+const upsertSpecGapReport = `-- name: UpsertSpecGapReport :exec
+INSERT INTO spec_gap_reports (document_id, total_source_files, covered_source_files, uncovered_packages, uncovered_files)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (document_id) DO UPDATE
+SET total_source_files = EXCLUDED.total_source_files,
+    covered_source_files = EXCLUDED.covered_source_files,
+    uncovered_packages = EXCLUDED.uncovered_packages,
+    uncovered_files = EXCLUDED.uncovered_files,
+    created_at = now()
+`
+
+type UpsertSpecGapReportParams struct {
+	DocumentID         pgtype.UUID `json:"document_id"`
+	TotalSourceFiles   int32       `json:"total_source_files"`
+	CoveredSourceFiles int32       `json:"covered_source_files"`
+	UncoveredPackages  []byte      `json:"uncovered_packages"`
+	UncoveredFiles     []byte      `json:"uncovered_files"`
+}
+
+func (q *Queries) UpsertSpecGapReport(ctx context.Context, arg UpsertSpecGapReportParams) error {
+	_, err := q.db.Exec(ctx, upsertSpecGapReport,
+		arg.DocumentID,
+		arg.TotalSourceFiles,
+		arg.CoveredSourceFiles,
+		arg.UncoveredPackages,
+		arg.UncoveredFiles,
+	)
+	return err
+}
+
+const upsertSpecGenerationCheckpoint = `-- name: UpsertSpecGenerationCheckpoint :exec
+INSERT INTO spec_generation_checkpoints (job_id, analysis_id, content_hash, phase1_output)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (job_id) DO UPDATE
+SET analysis_id = EXCLUDED.analysis_id,
+    content_hash = EXCLUDED.content_hash,
+    phase1_output = EXCLUDED.phase1_output,
+    updated_at = now()
+`
+
+type UpsertSpecGenerationCheckpointParams struct {
+	JobID        int64       `json:"job_id"`
+	AnalysisID   pgtype.UUID `json:"analysis_id"`
+	ContentHash  []byte      `json:"content_hash"`
+	Phase1Output []byte      `json:"phase1_output"`
+}
+
+// Rows are removed with their river_job row when River prunes finalized jobs,
+// with their analysis, or when the generated document is saved.
+func (q *Queries) UpsertSpecGenerationCheckpoint(ctx context.Context, arg UpsertSpecGenerationCheckpointParams) error {
+	_, err := q.db.Exec(ctx, upsertSpecGenerationCheckpoint,
+		arg.JobID,
+		arg.AnalysisID,
+		arg.ContentHash,
+		arg.Phase1Output,
+	)
+	return err
+}
+
+const upsertSpecGenerationProgress = `-- name: UpsertSpecGenerationProgress :exec
+WITH upserted AS (
+    INSERT INTO spec_generation_progress (analysis_id, language, status, total_features, completed_features, failed_features, cache_hit_rate, eta_seconds, started_at, updated_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, now())
+    ON CONFLICT (analysis_id, language) DO UPDATE
+    SET status = EXCLUDED.status,
+        total_features = EXCLUDED.total_features,
+        completed_features = EXCLUDED.completed_features,
+        failed_features = EXCLUDED.failed_features,
+        cache_hit_rate = EXCLUDED.cache_hit_rate,
+        eta_seconds = EXCLUDED.eta_seconds,
+        started_at = EXCLUDED.started_at,
+        updated_at = now()
+    WHERE spec_generation_progress.started_at <> EXCLUDED.started_at
+       OR (spec_generation_progress.status = 'running'
+           AND spec_generation_progress.completed_features <= EXCLUDED.completed_features)
+    RETURNING analysis_id, language, status, total_features, completed_features, failed_features, cache_hit_rate, eta_seconds, started_at, updated_at
+)
+SELECT pg_notify('spec_generation_progress', row_to_json(upserted)::text) FROM upserted
+`
+
+type UpsertSpecGenerationProgressParams struct {
+	AnalysisID        pgtype.UUID        `json:"analysis_id"`
+	Language          string             `json:"language"`
+	Status            string             `json:"status"`
+	TotalFeatures     int32              `json:"total_features"`
+	CompletedFeatures int32              `json:"completed_features"`
+	FailedFeatures    int32              `json:"failed_features"`
+	CacheHitRate      float64            `json:"cache_hit_rate"`
+	EtaSeconds        int64              `json:"eta_seconds"`
+	StartedAt         pgtype.Timestamptz `json:"started_at"`
+}
+
+// Stores the latest Phase 2 snapshot and notifies LISTEN spec_generation_progress subscribers.
+// Within one run (same started_at), stale snapshots and updates after a terminal status are ignored.
+func (q *Queries) UpsertSpecGenerationProgress(ctx context.Context, arg UpsertSpecGenerationProgressParams) error {
+	_, err := q.db.Exec(ctx, upsertSpecGenerationProgress,
+		arg.AnalysisID,
+		arg.Language,
+		arg.Status,
+		arg.TotalFeatures,
+		arg.CompletedFeatures,
+		arg.FailedFeatures,
+		arg.CacheHitRate,
+		arg.EtaSeconds,
+		arg.StartedAt,
+	)
+	return err
+}
+
 const upsertSystemConfig = `-- name: UpsertSystemConfig :exec
 INSERT INTO system_config (key, value, updated_at)
 VALUES ($1, $2, now())
@@ -1150,3 +6444,71 @@ func (q *Queries) UpsertSystemConfig(ctx context.Context, arg UpsertSystemConfig
 	_, err := q.db.Exec(ctx, upsertSystemConfig, arg.Key, arg.Value)
 	return err
 }
+
+const upsertTestDelta = `-- name: UpsertTestDelta :exec
+INSERT INTO test_deltas (
+    codebase_id, base_analysis_id, head_analysis_id, pull_request_number,
+    tests_added, tests_removed, tests_renamed, suites_added, suites_removed, details
+)
+SELECT a.codebase_id, $1::uuid, a.id, $2::int,
+    $3::int, $4::int, $5::int, $6::int, $7::int, $8::jsonb
+FROM analyses a
+WHERE a.id = $9::uuid
+ON CONFLICT ON CONSTRAINT uq_test_deltas_base_head DO UPDATE
+SET pull_request_number = COALESCE(EXCLUDED.pull_request_number, test_deltas.pull_request_number),
+    tests_added = EXCLUDED.tests_added,
+    tests_removed = EXCLUDED.tests_removed,
+    tests_renamed = EXCLUDED.tests_renamed,
+    suites_added = EXCLUDED.suites_added,
+    suites_removed = EXCLUDED.suites_removed,
+    details = EXCLUDED.details,
+    updated_at = now()
+`
+
+type UpsertTestDeltaParams struct {
+	BaseAnalysisID    pgtype.UUID `json:"base_analysis_id"`
+	PullRequestNumber pgtype.Int4 `json:"pull_request_number"`
+	TestsAdded        int32       `json:"tests_added"`
+	TestsRemoved      int32       `json:"tests_removed"`
+	TestsRenamed      int32       `json:"tests_renamed"`
+	SuitesAdded       int32       `json:"suites_added"`
+	SuitesRemoved     int32       `json:"suites_removed"`
+	Details           []byte      `json:"details"`
+	HeadAnalysisID    pgtype.UUID `json:"head_analysis_id"`
+}
+
+func (q *Queries) UpsertTestDelta(ctx context.Context, arg UpsertTestDeltaParams) error {
+	_, err := q.db.Exec(ctx, upsertTestDelta,
+		arg.BaseAnalysisID,
+		arg.PullRequestNumber,
+		arg.TestsAdded,
+		arg.TestsRemoved,
+		arg.TestsRenamed,
+		arg.SuitesAdded,
+		arg.SuitesRemoved,
+		arg.Details,
+		arg.HeadAnalysisID,
+	)
+	return err
+}
+
+const upsertVerifiedBehaviorCache = `-- name: UpsertVerifiedBehaviorCache :exec
+INSERT INTO behavior_caches (cache_key_hash, converted_description, codebase_id, human_verified)
+VALUES ($1, $2, (SELECT a.codebase_id FROM analyses a WHERE a.id = $3), true)
+ON CONFLICT (cache_key_hash) DO UPDATE
+SET converted_description = EXCLUDED.converted_description,
+    codebase_id = COALESCE(EXCLUDED.codebase_id, behavior_caches.codebase_id),
+    human_verified = true
+`
+
+type UpsertVerifiedBehaviorCacheParams struct {
+	CacheKeyHash         []byte      `json:"cache_key_hash"`
+	ConvertedDescription string      `json:"converted_description"`
+	AnalysisID           pgtype.UUID `json:"analysis_id"`
+}
+
+// Verified entries are only ever replaced by another verified entry.
+func (q *Queries) UpsertVerifiedBehaviorCache(ctx context.Context, arg UpsertVerifiedBehaviorCacheParams) error {
+	_, err := q.db.Exec(ctx, upsertVerifiedBehaviorCache, arg.CacheKeyHash, arg.ConvertedDescription, arg.AnalysisID)
+	return err
+}
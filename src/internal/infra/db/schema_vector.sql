@@ -0,0 +1,53 @@
+--
+-- Objects of the semantic behavior cache, which need the pgvector extension.
+-- Applied after schema.sql, and only where the extension is available: the
+-- semantic cache is optional, and the rest of the schema must load without it.
+--
+
+
+--
+-- Name: vector; Type: EXTENSION; Schema: -; Owner: -
+--
+
+CREATE EXTENSION IF NOT EXISTS vector WITH SCHEMA public;
+
+
+--
+-- Name: EXTENSION vector; Type: COMMENT; Schema: -; Owner: -
+--
+
+COMMENT ON EXTENSION vector IS 'vector data type and ivfflat and hnsw access methods';
+
+
+--
+-- Name: behavior_cache_embeddings; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.behavior_cache_embeddings (
+    cache_key_hash bytea NOT NULL,
+    partition_hash bytea NOT NULL,
+    embedding public.vector(768) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: behavior_cache_embeddings behavior_cache_embeddings_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.behavior_cache_embeddings
+    ADD CONSTRAINT behavior_cache_embeddings_pkey PRIMARY KEY (cache_key_hash);
+
+
+--
+-- Name: idx_behavior_cache_embeddings_embedding; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_behavior_cache_embeddings_embedding ON public.behavior_cache_embeddings USING hnsw (embedding public.vector_cosine_ops);
+
+
+--
+-- Name: idx_behavior_cache_embeddings_partition; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_behavior_cache_embeddings_partition ON public.behavior_cache_embeddings USING btree (partition_hash);
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Prefill opens the pool's minimum connections up front and pings each one,
+// so the first jobs after a deploy don't wait on TLS handshakes.
+// Returns the number of connections warmed.
+func Prefill(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	n := int(pool.Config().MinConns)
+	if n < 1 {
+		n = 1
+	}
+
+	// Holding every acquired connection forces the pool to open new ones.
+	conns := make([]*pgxpool.Conn, 0, n)
+	defer func() {
+		for _, conn := range conns {
+			conn.Release()
+		}
+	}()
+
+	for range n {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			return len(conns), fmt.Errorf("acquire connection: %w", err)
+		}
+		conns = append(conns, conn)
+		if err := conn.Ping(ctx); err != nil {
+			return len(conns) - 1, fmt.Errorf("ping connection: %w", err)
+		}
+	}
+	return len(conns), nil
+}
@@ -0,0 +1,185 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/specvital/worker/internal/infra/queue"
+)
+
+// readinessTimeout bounds all readiness checks of one probe.
+const readinessTimeout = 3 * time.Second
+
+// CheckFunc reports a dependency as unavailable by returning an error.
+type CheckFunc func(ctx context.Context) error
+
+// JobLister lists the jobs the process is working.
+type JobLister interface {
+	InFlightJobs() []queue.InFlightJob
+}
+
+// IdleReporter reports whether the process can be scaled to zero.
+type IdleReporter interface {
+	IdleStatus(ctx context.Context) (queue.IdleStatus, error)
+}
+
+type namedCheck struct {
+	check CheckFunc
+	name  string
+}
+
+// Health backs the liveness, readiness and in-flight job endpoints.
+// Checks and the job source are added as the service starts, so the
+// endpoints can be served before the queue server exists.
+type Health struct {
+	checks    []namedCheck
+	drain     chan struct{}
+	drainOnce sync.Once
+	idle      IdleReporter
+	jobs      JobLister
+	mu        sync.RWMutex
+}
+
+func NewHealth() *Health {
+	return &Health{drain: make(chan struct{})}
+}
+
+// AddCheck makes readiness depend on check.
+func (h *Health) AddCheck(name string, check CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, namedCheck{check: check, name: name})
+}
+
+// SetJobs sets the source of /debug/jobs.
+func (h *Health) SetJobs(jobs JobLister) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.jobs = jobs
+}
+
+// SetIdle sets the source of /idle.
+func (h *Health) SetIdle(idle IdleReporter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.idle = idle
+}
+
+// RequestDrain asks the service to stop taking jobs and exit once the jobs
+// in flight finish. Readiness fails from then on. Repeated calls are no-ops.
+func (h *Health) RequestDrain() {
+	h.drainOnce.Do(func() { close(h.drain) })
+}
+
+// DrainRequested is closed by the first RequestDrain.
+func (h *Health) DrainRequested() <-chan struct{} {
+	return h.drain
+}
+
+func (h *Health) draining() bool {
+	select {
+	case <-h.drain:
+		return true
+	default:
+		return false
+	}
+}
+
+type readinessResponse struct {
+	Checks map[string]string `json:"checks"`
+	Status string            `json:"status"`
+}
+
+// LivenessHandler reports that the process is serving requests.
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+}
+
+// ReadinessHandler runs every check and answers 503 unless all pass.
+// A process without checks is still starting and is not ready, and a
+// draining process reports "draining" whatever its checks return.
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.RLock()
+		checks := append([]namedCheck(nil), h.checks...)
+		h.mu.RUnlock()
+
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		resp := readinessResponse{Checks: make(map[string]string, len(checks)), Status: "ok"}
+		if len(checks) == 0 {
+			resp.Status = "starting"
+		}
+		for _, c := range checks {
+			if err := c.check(ctx); err != nil {
+				resp.Checks[c.name] = err.Error()
+				resp.Status = "unavailable"
+				continue
+			}
+			resp.Checks[c.name] = "ok"
+		}
+		if h.draining() {
+			resp.Status = "draining"
+		}
+
+		status := http.StatusOK
+		if resp.Status != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, resp)
+	})
+}
+
+// DrainHandler starts a drain, the HTTP equivalent of SIGUSR1.
+func (h *Health) DrainHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.RequestDrain()
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "draining"})
+	})
+}
+
+// JobsHandler lists the jobs in flight, longest-running first.
+func (h *Health) JobsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.RLock()
+		source := h.jobs
+		h.mu.RUnlock()
+
+		jobs := []queue.InFlightJob{}
+		if source != nil {
+			jobs = source.InFlightJobs()
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"count": len(jobs), "jobs": jobs})
+	})
+}
+
+// IdleHandler reports whether the process can be scaled to zero, for
+// autoscalers of cost-sensitive deployments. It answers 404 when idle
+// detection is disabled.
+func (h *Health) IdleHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.RLock()
+		source := h.idle
+		h.mu.RUnlock()
+
+		if source == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "idle detection disabled"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		status, err := source.IdleStatus(ctx)
+		if err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	})
+}
@@ -0,0 +1,190 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/infra/queue"
+)
+
+type stubJobs []queue.InFlightJob
+
+func (s stubJobs) InFlightJobs() []queue.InFlightJob {
+	return s
+}
+
+func get(h http.Handler, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestHealth_Liveness(t *testing.T) {
+	if rec := get(NewMux(testReport(), NewHealth()), "/healthz"); rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestHealth_Readiness(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name       string
+		checks     map[string]CheckFunc
+		wantCode   int
+		wantStatus string
+	}{
+		{"starting", nil, http.StatusServiceUnavailable, "starting"},
+		{"all checks pass", map[string]CheckFunc{"postgres": ok, "queue": ok}, http.StatusOK, "ok"},
+		{"postgres down", map[string]CheckFunc{"postgres": down, "queue": ok}, http.StatusServiceUnavailable, "unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := NewHealth()
+			for name, check := range tt.checks {
+				health.AddCheck(name, check)
+			}
+
+			rec := get(NewMux(testReport(), health), "/readyz")
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			var got readinessResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if got.Status != tt.wantStatus || len(got.Checks) != len(tt.checks) {
+				t.Errorf("unexpected response: %+v", got)
+			}
+		})
+	}
+}
+
+func TestHealth_Jobs(t *testing.T) {
+	health := NewHealth()
+	if rec := get(NewMux(testReport(), health), "/debug/jobs"); rec.Body.String() != "{\"count\":0,\"jobs\":[]}\n" {
+		t.Errorf("unexpected body without a job source: %s", rec.Body)
+	}
+
+	health.SetJobs(stubJobs{{ID: 42, Kind: "analysis:analyze", Queue: "analysis_default", StartedAt: time.Now()}})
+	rec := get(NewMux(testReport(), health), "/debug/jobs")
+
+	var got struct {
+		Count int                 `json:"count"`
+		Jobs  []queue.InFlightJob `json:"jobs"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.Count != 1 || got.Jobs[0].ID != 42 || got.Jobs[0].Kind != "analysis:analyze" {
+		t.Errorf("unexpected jobs: %+v", got)
+	}
+}
+
+func TestHealth_Drain(t *testing.T) {
+	health := NewHealth()
+	health.AddCheck("queue", func(context.Context) error { return nil })
+	mux := NewMux(testReport(), health, WithAdminAuth(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer admin" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/drain", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated POST /admin/drain status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	if rec := get(mux, "/admin/drain"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /admin/drain status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	select {
+	case <-health.DrainRequested():
+		t.Fatal("drain requested before POST")
+	default:
+	}
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/drain", nil)
+		req.Header.Set("Authorization", "Bearer admin")
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("POST /admin/drain status = %d, want %d", rec.Code, http.StatusAccepted)
+		}
+	}
+	select {
+	case <-health.DrainRequested():
+	default:
+		t.Fatal("drain not requested after POST")
+	}
+
+	rec = get(mux, "/readyz")
+	var got readinessResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable || got.Status != "draining" {
+		t.Errorf("readiness while draining = %d %+v", rec.Code, got)
+	}
+}
+
+func TestNewMux_DrainRequiresAdminAuth(t *testing.T) {
+	health := NewHealth()
+	mux := NewMux(testReport(), health)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/drain", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("POST /admin/drain without admin auth status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	select {
+	case <-health.DrainRequested():
+		t.Fatal("drain requested without admin auth")
+	default:
+	}
+}
+
+type stubIdle struct {
+	err    error
+	status queue.IdleStatus
+}
+
+func (s stubIdle) IdleStatus(ctx context.Context) (queue.IdleStatus, error) {
+	return s.status, s.err
+}
+
+func TestHealth_Idle(t *testing.T) {
+	health := NewHealth()
+	if rec := get(NewMux(testReport(), health), "/idle"); rec.Code != http.StatusNotFound {
+		t.Errorf("status without idle detection = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	health.SetIdle(stubIdle{status: queue.IdleStatus{Idle: true}})
+	rec := get(NewMux(testReport(), health), "/idle")
+	var got queue.IdleStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if rec.Code != http.StatusOK || !got.Idle {
+		t.Errorf("status = %d, idle = %v, want idle", rec.Code, got.Idle)
+	}
+
+	health.SetIdle(stubIdle{err: errors.New("connection refused")})
+	if rec := get(NewMux(testReport(), health), "/idle"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status on a failed check = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,119 @@
+// Package httpserver serves the worker's operational HTTP endpoints.
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/specvital/worker/internal/infra/buildinfo"
+	"github.com/specvital/worker/internal/infra/metrics"
+)
+
+const (
+	DefaultShutdownTimeout = 5 * time.Second
+	readHeaderTimeout      = 5 * time.Second
+)
+
+// Server is a small HTTP server running alongside the queue server.
+type Server struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// MuxOption is a functional option for configuring NewMux.
+type MuxOption func(*muxConfig)
+
+type muxConfig struct {
+	adminAuth func(http.Handler) http.Handler
+}
+
+// WithAdminAuth serves the admin endpoints, such as POST /admin/drain, behind
+// auth. Without it they are not served at all: the probe listener is
+// reachable by anything that can reach the probes.
+func WithAdminAuth(auth func(http.Handler) http.Handler) MuxOption {
+	return func(cfg *muxConfig) {
+		cfg.adminAuth = auth
+	}
+}
+
+// NewMux returns the handler with all operational endpoints registered.
+func NewMux(report buildinfo.Report, health *Health, opts ...MuxOption) *http.ServeMux {
+	var cfg muxConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /version", VersionHandler(report))
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.Handle("GET /healthz", health.LivenessHandler())
+	mux.Handle("GET /readyz", health.ReadinessHandler())
+	mux.Handle("GET /debug/jobs", health.JobsHandler())
+	mux.Handle("GET /idle", health.IdleHandler())
+	if cfg.adminAuth != nil {
+		mux.Handle("POST /admin/drain", cfg.adminAuth(health.DrainHandler()))
+	}
+	return mux
+}
+
+// NewServer binds addr immediately so port conflicts fail at startup.
+func NewServer(addr string, handler http.Handler) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		listener: listener,
+		server: &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: readHeaderTimeout,
+		},
+	}, nil
+}
+
+// Addr returns the bound address, useful when addr used port 0.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Start serves requests in the background.
+func (s *Server) Start() {
+	go func() {
+		if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("http server failed", "addr", s.Addr(), "error", err)
+		}
+	}()
+}
+
+// Stop gracefully shuts down the server.
+func (s *Server) Stop(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultShutdownTimeout)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// VersionHandler reports the worker build and its capabilities as JSON.
+func VersionHandler(report buildinfo.Report) http.Handler {
+	body, err := json.Marshal(report)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			http.Error(w, "failed to encode build info", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write(body)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,76 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/specvital/worker/internal/infra/buildinfo"
+)
+
+func testReport() buildinfo.Report {
+	return buildinfo.Report{
+		Features:      map[string]bool{"fairness": true},
+		GitSHA:        "abc123",
+		Hostname:      "worker-1",
+		JobKinds:      []string{"analysis:analyze"},
+		ParserVersion: "v1.5.1",
+		Service:       "analyzer",
+		Version:       "v1.0.0",
+	}
+}
+
+func TestVersionHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewMux(testReport(), NewHealth()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+
+	var got buildinfo.Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.GitSHA != "abc123" || got.ParserVersion != "v1.5.1" || !got.Features["fairness"] {
+		t.Errorf("unexpected report: %+v", got)
+	}
+	if len(got.JobKinds) != 1 || got.JobKinds[0] != "analysis:analyze" {
+		t.Errorf("unexpected job kinds: %v", got.JobKinds)
+	}
+}
+
+func TestVersionHandler_RejectsOtherMethods(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewMux(testReport(), NewHealth()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/version", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestServer_StartStop(t *testing.T) {
+	srv, err := NewServer("127.0.0.1:0", NewMux(testReport(), NewHealth()))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	srv.Start()
+
+	resp, err := http.Get("http://" + srv.Addr() + "/version")
+	if err != nil {
+		t.Fatalf("GET /version: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	if err := srv.Stop(context.Background()); err != nil {
+		t.Errorf("Stop: %v", err)
+	}
+}
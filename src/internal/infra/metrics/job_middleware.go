@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// JobMiddleware counts processed jobs and times them per job kind.
+type JobMiddleware struct {
+	river.MiddlewareDefaults
+}
+
+var _ rivertype.WorkerMiddleware = (*JobMiddleware)(nil)
+
+func NewJobMiddleware() *JobMiddleware {
+	return &JobMiddleware{}
+}
+
+// Work implements river.WorkerMiddleware.
+func (m *JobMiddleware) Work(
+	ctx context.Context,
+	job *rivertype.JobRow,
+	doInner func(ctx context.Context) error,
+) error {
+	start := time.Now()
+	err := doInner(ctx)
+
+	JobDuration.WithLabelValues(job.Kind).Observe(time.Since(start).Seconds())
+	JobsProcessed.WithLabelValues(job.Kind, jobOutcome(err)).Inc()
+	return err
+}
+
+func jobOutcome(err error) string {
+	var cancelErr *rivertype.JobCancelError
+	var snoozeErr *rivertype.JobSnoozeError
+	switch {
+	case errors.As(err, &cancelErr):
+		return OutcomeCancelled
+	case errors.As(err, &snoozeErr):
+		return OutcomeSnoozed
+	default:
+		return ErrorOutcome(err)
+	}
+}
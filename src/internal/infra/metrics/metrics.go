@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Outcome label values shared by job and clone metrics.
+const (
+	OutcomeCancelled = "cancelled"
+	OutcomeFailed    = "failed"
+	OutcomeSnoozed   = "snoozed"
+	OutcomeSucceeded = "succeeded"
+)
+
+// Cache label values for CacheLookups.
+const (
+	CacheBehavior       = "behavior"
+	CacheClassification = "classification"
+	CacheResponse       = "ai_response"
+	ResultHit           = "hit"
+	ResultMiss          = "miss"
+)
+
+// Result label values for ParserFiles.
+const (
+	ParseFailed    = "failed"
+	ParseMatched   = "matched"
+	ParseUnmatched = "unmatched"
+)
+
+var (
+	// durationBuckets span quick jobs to the 15-minute analysis timeout and beyond.
+	durationBuckets = []float64{0.5, 1, 5, 15, 30, 60, 120, 300, 600, 900, 1800}
+
+	AIModelFallbacks = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "specvital_ai_model_fallbacks_total",
+		Help: "AI calls answered by a fallback model, by backend, configured model and fallback model.",
+	}, []string{"backend", "model", "fallback"})
+	AIOutputRepairs = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "specvital_ai_output_repairs_total",
+		Help: "AI response entries dropped or fixed by validation, by phase and issue.",
+	}, []string{"phase", "issue"})
+	AITokens = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "specvital_ai_tokens_total",
+		Help: "AI tokens used, by backend, model and type (prompt or candidates).",
+	}, []string{"backend", "model", "type"})
+	CacheLookups = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "specvital_cache_lookups_total",
+		Help: "Spec generation cache lookups, by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+	CloneDuration = promauto.With(Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "specvital_clone_duration_seconds",
+		Help:    "Time spent cloning repositories, by outcome.",
+		Buckets: durationBuckets,
+	}, []string{"outcome"})
+	JobDuration = promauto.With(Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "specvital_job_duration_seconds",
+		Help:    "Job execution time, by job kind.",
+		Buckets: durationBuckets,
+	}, []string{"kind"})
+	JobPhaseDuration = promauto.With(Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "specvital_job_phase_duration_seconds",
+		Help:    "Time jobs with an SLA target spend in each phase, by job kind and phase.",
+		Buckets: durationBuckets,
+	}, []string{"kind", "phase"})
+	JobsProcessed = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "specvital_jobs_processed_total",
+		Help: "Jobs processed, by job kind and outcome.",
+	}, []string{"kind", "outcome"})
+	ParserFiles = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "specvital_parser_files_total",
+		Help: "Test file candidates run through the parser strategies, by language and result (matched, unmatched or failed).",
+	}, []string{"language", "result"})
+	SLAViolations = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "specvital_sla_violations_total",
+		Help: "Jobs that finished past their SLA target, by job kind and slowest phase.",
+	}, []string{"kind", "phase"})
+)
+
+// ErrorOutcome maps an error to OutcomeSucceeded or OutcomeFailed.
+func ErrorOutcome(err error) string {
+	if err == nil {
+		return OutcomeSucceeded
+	}
+	return OutcomeFailed
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+// queueDepthTimeout bounds the count query so a slow database cannot stall scrapes.
+const queueDepthTimeout = 5 * time.Second
+
+// QueueDepthSource counts unfinished River jobs.
+type QueueDepthSource interface {
+	CountRiverJobsByQueueState(ctx context.Context) ([]db.CountRiverJobsByQueueStateRow, error)
+}
+
+// RegisterQueueDepth adds the specvital_queue_jobs gauge, counted from source on each scrape.
+func RegisterQueueDepth(r prometheus.Registerer, source QueueDepthSource) error {
+	return r.Register(&queueDepthCollector{
+		desc: prometheus.NewDesc("specvital_queue_jobs",
+			"River jobs waiting or running, by queue and state.",
+			[]string{"queue", "state"}, nil),
+		source: source,
+	})
+}
+
+type queueDepthCollector struct {
+	desc   *prometheus.Desc
+	source QueueDepthSource
+}
+
+func (c *queueDepthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect counts the jobs. A failing count is logged and the gauge left out
+// of that scrape.
+func (c *queueDepthCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueDepthTimeout)
+	defer cancel()
+
+	rows, err := c.source.CountRiverJobsByQueueState(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to collect metric (non-critical)",
+			"metric", "specvital_queue_jobs",
+			"error", err,
+		)
+		return
+	}
+	for _, row := range rows {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(row.Jobs), row.Queue, row.State)
+	}
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/riverqueue/river"
+
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+type fakeQueueDepthSource struct {
+	err  error
+	rows []db.CountRiverJobsByQueueStateRow
+}
+
+func (f *fakeQueueDepthSource) CountRiverJobsByQueueState(context.Context) ([]db.CountRiverJobsByQueueStateRow, error) {
+	return f.rows, f.err
+}
+
+func TestRegisterQueueDepth(t *testing.T) {
+	t.Run("reports jobs by queue and state", func(t *testing.T) {
+		r := prometheus.NewRegistry()
+		source := &fakeQueueDepthSource{rows: []db.CountRiverJobsByQueueStateRow{
+			{Queue: "analysis", State: "available", Jobs: 7},
+			{Queue: "specview", State: "running", Jobs: 2},
+		}}
+		if err := RegisterQueueDepth(r, source); err != nil {
+			t.Fatalf("RegisterQueueDepth failed: %v", err)
+		}
+		want := `# HELP specvital_queue_jobs River jobs waiting or running, by queue and state.
+# TYPE specvital_queue_jobs gauge
+specvital_queue_jobs{queue="analysis",state="available"} 7
+specvital_queue_jobs{queue="specview",state="running"} 2
+`
+		if err := testutil.GatherAndCompare(r, strings.NewReader(want), "specvital_queue_jobs"); err != nil {
+			t.Error(err)
+		}
+		if err := RegisterQueueDepth(r, source); err == nil {
+			t.Error("expected duplicate registration to fail")
+		}
+	})
+
+	t.Run("failing counts are left out of the scrape", func(t *testing.T) {
+		r := prometheus.NewRegistry()
+		if err := RegisterQueueDepth(r, &fakeQueueDepthSource{err: errors.New("connection refused")}); err != nil {
+			t.Fatalf("RegisterQueueDepth failed: %v", err)
+		}
+		if n, err := testutil.GatherAndCount(r, "specvital_queue_jobs"); err != nil || n != 0 {
+			t.Errorf("expected no samples, got %d, %v", n, err)
+		}
+	})
+}
+
+func TestHandler(t *testing.T) {
+	JobsProcessed.WithLabelValues("test:handler", OutcomeSucceeded).Inc()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("unexpected Content-Type %q", rec.Header().Get("Content-Type"))
+	}
+	for _, line := range []string{
+		`specvital_jobs_processed_total{kind="test:handler",outcome="succeeded"} 1`,
+		"# TYPE go_goroutines gauge",
+	} {
+		if !strings.Contains(rec.Body.String(), line+"\n") {
+			t.Errorf("output missing %q", line)
+		}
+	}
+}
+
+func TestJobOutcome(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"success", nil, OutcomeSucceeded},
+		{"failure", errors.New("boom"), OutcomeFailed},
+		{"cancel", river.JobCancel(errors.New("gone")), OutcomeCancelled},
+		{"snooze", river.JobSnooze(time.Minute), OutcomeSnoozed},
+		{"wrapped snooze", fmt.Errorf("fairness: %w", river.JobSnooze(time.Minute)), OutcomeSnoozed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jobOutcome(tt.err); got != tt.want {
+				t.Errorf("jobOutcome() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,28 +2,98 @@ package queue
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivertype"
 	"github.com/specvital/worker/internal/adapter/queue/analyze"
+	"github.com/specvital/worker/internal/adapter/queue/repopolicy"
+	"github.com/specvital/worker/internal/adapter/queue/requirement"
+	"github.com/specvital/worker/internal/adapter/queue/specview"
+	"github.com/specvital/worker/internal/adapter/repository/postgres"
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/deadletter"
+	"github.com/specvital/worker/internal/domain/hostpool"
+	"github.com/specvital/worker/internal/domain/regen"
+	"github.com/specvital/worker/internal/domain/region"
+	"github.com/specvital/worker/internal/domain/webhook"
+)
+
+var (
+	_ deadletter.Requeuer                = (*Client)(nil)
+	_ regen.Enqueuer                     = (*Client)(nil)
+	_ webhook.FilteredAnalysisEnqueuer   = (*Client)(nil)
+	_ webhook.IdempotentAnalysisEnqueuer = (*Client)(nil)
 )
 
 // Client is insert-only (no worker).
 type Client struct {
-	client *river.Client[pgx.Tx]
+	client         *river.Client[pgx.Tx]
+	hostPools      []hostpool.Pool
+	idempotencyTTL time.Duration
+	middleware     []rivertype.Middleware
+	pool           *pgxpool.Pool
+	region         string
+}
+
+// ClientOption is a functional option for configuring Client.
+type ClientOption func(*Client)
+
+// WithHostPools inserts analyses of the hosts of pools into the queues of their
+// pool, including those of github.com repositories when it has a pool.
+func WithHostPools(pools []hostpool.Pool) ClientOption {
+	return func(c *Client) {
+		c.hostPools = pools
+	}
+}
+
+// WithIdempotencyKeyTTL sets how long an idempotency key maps to its job.
+func WithIdempotencyKeyTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		if ttl > 0 {
+			c.idempotencyTTL = ttl
+		}
+	}
+}
+
+// WithMiddleware runs insert middleware around every enqueue.
+func WithMiddleware(middleware ...rivertype.Middleware) ClientOption {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
 }
 
-func NewClient(ctx context.Context, pool *pgxpool.Pool) (*Client, error) {
-	client, err := river.NewClient(riverpgxv5.New(pool), &river.Config{})
+// WithRegion inserts jobs into the queues of the given region.
+func WithRegion(r string) ClientOption {
+	return func(c *Client) {
+		c.region = r
+	}
+}
+
+// NewClient creates an insert-only client. Every insert is checked against the
+// repository policy of the requesting user's tenant first, so no enqueue path
+// (webhooks, the enqueue CLI, the API) can bypass it.
+func NewClient(ctx context.Context, pool *pgxpool.Pool, opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		idempotencyTTL: webhook.DefaultIdempotencyKeyTTL,
+		middleware: []rivertype.Middleware{
+			repopolicy.NewPolicyMiddleware(postgres.NewRepoPolicyRepository(pool)),
+		},
+		pool: pool,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	client, err := river.NewClient(riverpgxv5.New(pool), &river.Config{Middleware: c.middleware})
 	if err != nil {
 		return nil, err
 	}
-
-	return &Client{
-		client: client,
-	}, nil
+	c.client = client
+	return c, nil
 }
 
 func (c *Client) Close() error {
@@ -32,11 +102,67 @@ func (c *Client) Close() error {
 }
 
 func (c *Client) EnqueueAnalysis(ctx context.Context, owner, repo, commitSHA string) error {
-	_, err := c.client.Insert(ctx, analyze.AnalyzeArgs{
-		Owner:     owner,
-		Repo:      repo,
-		CommitSHA: commitSHA,
+	return c.EnqueueBranchAnalysis(ctx, owner, repo, "", commitSHA)
+}
+
+// EnqueueBranchAnalysis schedules an analysis of the branch, or of the default branch when empty.
+func (c *Client) EnqueueBranchAnalysis(ctx context.Context, owner, repo, branch, commitSHA string) error {
+	return c.EnqueueFilteredAnalysis(ctx, owner, repo, branch, commitSHA, nil)
+}
+
+// EnqueueFilteredAnalysis schedules a branch analysis limited by path filters.
+// Nil filters reuse those of the codebase's previous analysis.
+func (c *Client) EnqueueFilteredAnalysis(ctx context.Context, owner, repo, branch, commitSHA string, filters *analysis.PathFilters) error {
+	_, err := c.client.Insert(ctx, filteredAnalysisArgs(owner, repo, branch, commitSHA, filters), c.defaultAnalysisOpts(""))
+	return err
+}
+
+// EnqueueHostAnalysis schedules a branch analysis of a repository on host,
+// empty for github.com, on the queue of the host's pool when it has one.
+func (c *Client) EnqueueHostAnalysis(ctx context.Context, host, owner, repo, branch, commitSHA string) error {
+	args := filteredAnalysisArgs(owner, repo, branch, commitSHA, nil)
+	args.Host = host
+	_, err := c.client.Insert(ctx, args, c.defaultAnalysisOpts(host))
+	return err
+}
+
+func filteredAnalysisArgs(owner, repo, branch, commitSHA string, filters *analysis.PathFilters) analyze.AnalyzeArgs {
+	return analyze.AnalyzeArgs{
+		Branch:      branch,
+		Owner:       owner,
+		PathFilters: filters,
+		Repo:        repo,
+		CommitSHA:   commitSHA,
+	}
+}
+
+func (c *Client) defaultAnalysisOpts(host string) *river.InsertOpts {
+	return &river.InsertOpts{
+		Queue: c.analysisQueue(analyze.QueueDefault, host),
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+// analysisQueue returns the queue of base that analyses of host, empty for
+// github.com, are inserted into: that of the host's pool when it has one.
+func (c *Client) analysisQueue(base, host string) string {
+	return region.QueueName(hostpool.QueueName(base, hostpool.ForHost(c.hostPools, host)), c.region)
+}
+
+// EnqueueComparison schedules a test delta between a pull request's analyzed base
+// commit and its head commit, which is analyzed from headBranch when needed.
+func (c *Client) EnqueueComparison(ctx context.Context, owner, repo, baseCommitSHA, headBranch, headCommitSHA string, pullRequest int) error {
+	_, err := c.client.Insert(ctx, analyze.CompareArgs{
+		BaseCommitSHA: baseCommitSHA,
+		HeadBranch:    headBranch,
+		HeadCommitSHA: headCommitSHA,
+		Owner:         owner,
+		PullRequest:   pullRequest,
+		Repo:          repo,
 	}, &river.InsertOpts{
+		Queue: c.analysisQueue(analyze.QueueDefault, ""),
 		UniqueOpts: river.UniqueOpts{
 			ByArgs: true,
 		},
@@ -51,6 +177,7 @@ func (c *Client) EnqueueAnalysisWithUser(ctx context.Context, owner, repo, commi
 		CommitSHA: commitSHA,
 		UserID:    userID,
 	}, &river.InsertOpts{
+		Queue: c.analysisQueue(analyze.QueueDefault, ""),
 		UniqueOpts: river.UniqueOpts{
 			ByArgs: true,
 		},
@@ -64,10 +191,56 @@ func (c *Client) EnqueueScheduledAnalysis(ctx context.Context, owner, repo, comm
 		Repo:      repo,
 		CommitSHA: commitSHA,
 	}, &river.InsertOpts{
-		Queue: analyze.QueueScheduled,
+		Queue: c.analysisQueue(analyze.QueueScheduled, ""),
 		UniqueOpts: river.UniqueOpts{
 			ByArgs: true,
 		},
 	})
 	return err
 }
+
+// EnqueueRequirementMatch schedules tracing of a requirement set against a spec document.
+func (c *Client) EnqueueRequirementMatch(ctx context.Context, requirementSetID, documentID string) error {
+	_, err := c.client.Insert(ctx, requirement.Args{
+		DocumentID:       documentID,
+		RequirementSetID: requirementSetID,
+	}, &river.InsertOpts{
+		Queue: region.QueueName(specview.QueueScheduled, c.region),
+	})
+	return err
+}
+
+// EnqueueRegeneration schedules a forced regeneration of a campaign target on
+// the scheduled queue, so campaigns never delay user-triggered generation.
+func (c *Client) EnqueueRegeneration(ctx context.Context, target regen.Target) (int64, error) {
+	res, err := c.client.Insert(ctx, specview.Args{
+		AnalysisID:      target.AnalysisID,
+		ForceRegenerate: true,
+		Language:        target.Language,
+		TeamSlices:      target.TeamSlices,
+		UserID:          target.UserID,
+	}, &river.InsertOpts{
+		Queue: region.QueueName(specview.QueueScheduled, c.region),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.Job.ID, nil
+}
+
+// RequeueJob makes a discarded job available to run now.
+// River extends max attempts when the job has exhausted them.
+func (c *Client) RequeueJob(ctx context.Context, jobID int64) error {
+	_, err := c.client.JobRetry(ctx, jobID)
+	return err
+}
+
+// CancelJob cancels a job that has not finalized yet. A running job is
+// cancelled by its worker's context, once River's notifier reaches it.
+func (c *Client) CancelJob(ctx context.Context, jobID int64) (string, error) {
+	job, err := c.client.JobCancel(ctx, jobID)
+	if err != nil {
+		return "", err
+	}
+	return string(job.State), nil
+}
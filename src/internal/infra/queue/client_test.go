@@ -0,0 +1,57 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/repopolicy"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestClient_RepoPolicy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	var userID, tenantID string
+	if err := pool.QueryRow(ctx, "INSERT INTO users (email, username) VALUES ('member@example.com', 'member') RETURNING id::text").Scan(&userID); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if err := pool.QueryRow(ctx, "INSERT INTO tenants (name) VALUES ('acme') RETURNING id::text").Scan(&tenantID); err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "INSERT INTO tenant_members (tenant_id, user_id) VALUES ($1, $2)", tenantID, userID); err != nil {
+		t.Fatalf("failed to add member: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "INSERT INTO tenant_repo_policies (tenant_id, effect, pattern) VALUES ($1, 'allow', 'acme')", tenantID); err != nil {
+		t.Fatalf("failed to add rule: %v", err)
+	}
+
+	client, err := NewClient(ctx, pool)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	if err := client.EnqueueAnalysisWithUser(ctx, "acme", "api", "abc123", &userID); err != nil {
+		t.Errorf("allowed repository: unexpected error %v", err)
+	}
+	if err := client.EnqueueAnalysisWithUser(ctx, "octocat", "hello", "abc123", &userID); !errors.Is(err, repopolicy.ErrPolicyViolation) {
+		t.Errorf("denied repository: expected ErrPolicyViolation, got %v", err)
+	}
+	if err := client.EnqueueAnalysis(ctx, "octocat", "hello", "abc123"); err != nil {
+		t.Errorf("system job: unexpected error %v", err)
+	}
+
+	var jobs int
+	if err := pool.QueryRow(ctx, "SELECT count(*) FROM river_job WHERE args->>'owner' = 'octocat' AND args->>'user_id' IS NOT NULL").Scan(&jobs); err != nil {
+		t.Fatalf("failed to count jobs: %v", err)
+	}
+	if jobs != 0 {
+		t.Errorf("denied repository inserted %d jobs, want 0", jobs)
+	}
+}
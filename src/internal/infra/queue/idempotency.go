@@ -0,0 +1,135 @@
+package queue
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/riverqueue/river"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/webhook"
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+// EnqueueIdempotentAnalysis claims key and inserts the job in one transaction,
+// so a key never maps to a job that was not enqueued. A concurrent request with
+// the same key waits for the first one and then reports its job.
+func (c *Client) EnqueueIdempotentAnalysis(ctx context.Context, key, owner, repo, branch, commitSHA string, filters *analysis.PathFilters) (webhook.EnqueueResult, error) {
+	args := filteredAnalysisArgs(owner, repo, branch, commitSHA, filters)
+	hash, err := requestHash(args)
+	if err != nil {
+		return webhook.EnqueueResult{}, err
+	}
+
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return webhook.EnqueueResult{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "failed to rollback transaction",
+				"operation", "EnqueueIdempotentAnalysis",
+				"error", rbErr,
+				"owner", owner,
+				"repo", repo,
+			)
+		}
+	}()
+
+	queries := db.New(tx)
+	_, err = queries.ClaimIdempotencyKey(ctx, db.ClaimIdempotencyKeyParams{
+		IdempotencyKey: key,
+		JobKind:        args.Kind(),
+		RequestHash:    hash,
+		ExpiresAt:      pgtype.Timestamptz{Time: time.Now().Add(c.idempotencyTTL), Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return replay(ctx, queries, key, args.Kind(), hash)
+	}
+	if err != nil {
+		return webhook.EnqueueResult{}, fmt.Errorf("claim idempotency key: %w", err)
+	}
+
+	res, err := c.client.InsertTx(ctx, tx, args, c.defaultAnalysisOpts(""))
+	if err != nil {
+		return webhook.EnqueueResult{}, err
+	}
+	if err := queries.SetIdempotencyKeyJob(ctx, db.SetIdempotencyKeyJobParams{
+		IdempotencyKey: key,
+		JobID:          pgtype.Int8{Int64: res.Job.ID, Valid: true},
+	}); err != nil {
+		return webhook.EnqueueResult{}, fmt.Errorf("set idempotency key job: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return webhook.EnqueueResult{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	// River's unique args may have matched a job another request enqueued.
+	return webhook.EnqueueResult{
+		Duplicate: res.UniqueSkippedAsDuplicate,
+		JobID:     res.Job.ID,
+		JobState:  string(res.Job.State),
+	}, nil
+}
+
+// LookupIdempotencyKey reports the job an unexpired key maps to.
+func (c *Client) LookupIdempotencyKey(ctx context.Context, key string) (*webhook.EnqueueResult, error) {
+	row, err := db.New(c.pool).GetIdempotencyKey(ctx, key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+	return toEnqueueResult(row), nil
+}
+
+func replay(ctx context.Context, queries *db.Queries, key, kind, hash string) (webhook.EnqueueResult, error) {
+	row, err := queries.GetIdempotencyKey(ctx, key)
+	if err != nil {
+		// Expired right after the claim: a retry claims the key anew.
+		if errors.Is(err, pgx.ErrNoRows) {
+			return webhook.EnqueueResult{}, fmt.Errorf("idempotency key expired while claiming it")
+		}
+		return webhook.EnqueueResult{}, fmt.Errorf("get idempotency key: %w", err)
+	}
+	if row.JobKind != kind || row.RequestHash != hash {
+		return webhook.EnqueueResult{}, webhook.ErrIdempotencyKeyReused
+	}
+	result := toEnqueueResult(row)
+	result.Duplicate = true
+	return *result, nil
+}
+
+func toEnqueueResult(row db.GetIdempotencyKeyRow) *webhook.EnqueueResult {
+	result := &webhook.EnqueueResult{
+		JobID: row.JobID.Int64,
+	}
+	if row.JobState.Valid {
+		result.JobState = string(row.JobState.RiverJobState)
+	}
+	if row.AnalysisID.Valid {
+		id := analysis.UUID(row.AnalysisID.Bytes)
+		result.AnalysisID = &id
+	}
+	return result
+}
+
+// requestHash fingerprints the job a key was first used for, so reusing the key
+// for another request is detected instead of silently returning the wrong job.
+func requestHash(args river.JobArgs) (string, error) {
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("encode job args: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
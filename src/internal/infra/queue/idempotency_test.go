@@ -0,0 +1,87 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/adapter/queue/analyze"
+	"github.com/specvital/worker/internal/domain/hostpool"
+	"github.com/specvital/worker/internal/domain/webhook"
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+func TestClient_EnqueueIdempotentAnalysis(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	client, err := NewClient(ctx, pool)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	first, err := client.EnqueueIdempotentAnalysis(ctx, "web-retry-1", "octocat", "hello", "", "abc123", nil)
+	if err != nil {
+		t.Fatalf("first enqueue failed: %v", err)
+	}
+	if first.Duplicate || first.JobID == 0 {
+		t.Fatalf("unexpected first result: %+v", first)
+	}
+
+	retry, err := client.EnqueueIdempotentAnalysis(ctx, "web-retry-1", "octocat", "hello", "", "abc123", nil)
+	if err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if !retry.Duplicate || retry.JobID != first.JobID {
+		t.Errorf("retry = %+v, want duplicate of job %d", retry, first.JobID)
+	}
+
+	if _, err := client.EnqueueIdempotentAnalysis(ctx, "web-retry-1", "octocat", "hello", "", "def456", nil); !errors.Is(err, webhook.ErrIdempotencyKeyReused) {
+		t.Errorf("expected ErrIdempotencyKeyReused, got %v", err)
+	}
+
+	got, err := client.LookupIdempotencyKey(ctx, "web-retry-1")
+	if err != nil {
+		t.Fatalf("LookupIdempotencyKey failed: %v", err)
+	}
+	if got == nil || got.JobID != first.JobID || got.JobState != "available" || got.AnalysisID != nil {
+		t.Errorf("lookup = %+v, want available job %d without analysis", got, first.JobID)
+	}
+
+	if got, err := client.LookupIdempotencyKey(ctx, "unknown"); err != nil || got != nil {
+		t.Errorf("unknown key: got %+v, err %v", got, err)
+	}
+}
+
+func TestClient_EnqueueIdempotentAnalysis_HostPool(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	client, err := NewClient(ctx, pool, WithHostPools([]hostpool.Pool{{Host: hostpool.DefaultHost, Name: "dotcom"}}))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	result, err := client.EnqueueIdempotentAnalysis(ctx, "web-pool-1", "octocat", "hello", "", "abc123", nil)
+	if err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+
+	var queueName string
+	if err := pool.QueryRow(ctx, "SELECT queue FROM river_job WHERE id = $1", result.JobID).Scan(&queueName); err != nil {
+		t.Fatalf("query job queue: %v", err)
+	}
+	if want := hostpool.QueueName(analyze.QueueDefault, "dotcom"); queueName != want {
+		t.Errorf("queue = %q, want %q", queueName, want)
+	}
+}
@@ -0,0 +1,191 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+const (
+	// DefaultIdleGrace is how long the queues must stay without work before
+	// the process reports that it can be scaled to zero.
+	DefaultIdleGrace = 10 * time.Minute
+
+	// DefaultIdleWakeLead is how long before a scheduled job becomes workable
+	// the process stops reporting idle, leaving time to start a replica.
+	DefaultIdleWakeLead = 2 * time.Minute
+
+	// insertTopic is the channel River notifies on, prefixed with the schema,
+	// when jobs are inserted or become workable.
+	insertTopic         = "river_insert"
+	idleListenRetryWait = 5 * time.Second
+)
+
+// IdleStateSource reads the state of the River queues.
+type IdleStateSource interface {
+	GetQueueIdleState(ctx context.Context, queues []string) (db.GetQueueIdleStateRow, error)
+}
+
+// JobSource lists the jobs the process is working.
+type JobSource interface {
+	InFlightJobs() []InFlightJob
+}
+
+// IdleStatus reports whether the process has no work and none due soon.
+type IdleStatus struct {
+	ActiveJobs   int64      `json:"active_jobs"` // workable or running jobs of the queues
+	Idle         bool       `json:"idle"`        // safe to scale to zero
+	IdleSince    *time.Time `json:"idle_since,omitempty"`
+	InFlightJobs int        `json:"in_flight_jobs"`         // jobs this process is working
+	NextWakeAt   *time.Time `json:"next_wake_at,omitempty"` // when a replica should run again for scheduled jobs
+}
+
+// IdleDetector decides when a process whose queues only hold scheduled jobs,
+// such as snoozed polls, can be scaled to zero. Insert notifications for the
+// queues end an idle period at once instead of at the next check.
+type IdleDetector struct {
+	grace     time.Duration
+	idleSince time.Time // zero while there is work
+	jobs      JobSource
+	mu        sync.Mutex
+	now       func() time.Time
+	queues    []string
+	source    IdleStateSource
+	wakeLead  time.Duration
+}
+
+// IdleOption is a functional option for configuring IdleDetector.
+type IdleOption func(*IdleDetector)
+
+// WithIdleGrace sets how long the queues must stay without work.
+func WithIdleGrace(grace time.Duration) IdleOption {
+	return func(d *IdleDetector) {
+		if grace > 0 {
+			d.grace = grace
+		}
+	}
+}
+
+// WithIdleWakeLead sets how early a due scheduled job ends idleness.
+func WithIdleWakeLead(lead time.Duration) IdleOption {
+	return func(d *IdleDetector) {
+		if lead > 0 {
+			d.wakeLead = lead
+		}
+	}
+}
+
+// NewIdleDetector watches the given queues and the jobs in flight.
+func NewIdleDetector(source IdleStateSource, jobs JobSource, queues []string, opts ...IdleOption) *IdleDetector {
+	d := &IdleDetector{
+		grace:    DefaultIdleGrace,
+		jobs:     jobs,
+		now:      time.Now,
+		queues:   slices.Clone(queues),
+		source:   source,
+		wakeLead: DefaultIdleWakeLead,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// IdleStatus checks the queues and reports whether the process is idle.
+func (d *IdleDetector) IdleStatus(ctx context.Context) (IdleStatus, error) {
+	row, err := d.source.GetQueueIdleState(ctx, d.queues)
+	if err != nil {
+		return IdleStatus{}, fmt.Errorf("get queue idle state: %w", err)
+	}
+
+	now := d.now()
+	status := IdleStatus{ActiveJobs: row.Active}
+	if d.jobs != nil {
+		status.InFlightJobs = len(d.jobs.InFlightJobs())
+	}
+	busy := status.ActiveJobs > 0 || status.InFlightJobs > 0
+	if row.NextScheduledAt.Valid {
+		wakeAt := row.NextScheduledAt.Time.Add(-d.wakeLead)
+		status.NextWakeAt = &wakeAt
+		busy = busy || !now.Before(wakeAt)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if busy {
+		d.idleSince = time.Time{}
+		return status, nil
+	}
+	if d.idleSince.IsZero() {
+		d.idleSince = now
+	}
+	idleSince := d.idleSince
+	status.IdleSince = &idleSince
+	status.Idle = now.Sub(idleSince) >= d.grace
+	return status, nil
+}
+
+// Wake ends the current idle period.
+func (d *IdleDetector) Wake() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.idleSince = time.Time{}
+}
+
+// Listen wakes the detector on River insert notifications for its queues
+// until ctx is done. Connection failures are retried.
+func (d *IdleDetector) Listen(ctx context.Context, pool *pgxpool.Pool) {
+	for ctx.Err() == nil {
+		if err := d.listen(ctx, pool); err != nil && ctx.Err() == nil {
+			slog.WarnContext(ctx, "idle detector listen failed, retrying (non-critical)", "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(idleListenRetryWait):
+			}
+		}
+	}
+}
+
+func (d *IdleDetector) listen(ctx context.Context, pool *pgxpool.Pool) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	// LISTEN state stays with the connection, so it is not returned to the pool.
+	listenConn := conn.Hijack()
+	defer listenConn.Close(context.WithoutCancel(ctx))
+
+	var schema string
+	if err := listenConn.QueryRow(ctx, "SELECT current_schema()").Scan(&schema); err != nil {
+		return fmt.Errorf("get schema: %w", err)
+	}
+	channel := schema + "." + insertTopic
+	if _, err := listenConn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	for {
+		notification, err := listenConn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+		var payload struct {
+			Queue string `json:"queue"`
+		}
+		if err := json.Unmarshal([]byte(notification.Payload), &payload); err != nil {
+			continue
+		}
+		if slices.Contains(d.queues, payload.Queue) {
+			d.Wake()
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/specvital/worker/internal/infra/db"
+)
+
+type stubIdleSource struct {
+	row db.GetQueueIdleStateRow
+}
+
+func (s *stubIdleSource) GetQueueIdleState(ctx context.Context, queues []string) (db.GetQueueIdleStateRow, error) {
+	return s.row, nil
+}
+
+type stubJobSource []InFlightJob
+
+func (s stubJobSource) InFlightJobs() []InFlightJob {
+	return s
+}
+
+func TestIdleDetector_IdleStatus(t *testing.T) {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	later := func(d time.Duration) pgtype.Timestamptz {
+		return pgtype.Timestamptz{Time: start.Add(d), Valid: true}
+	}
+
+	tests := []struct {
+		name     string
+		row      db.GetQueueIdleStateRow
+		inFlight stubJobSource
+		wantIdle bool
+	}{
+		{"empty queues", db.GetQueueIdleStateRow{}, nil, true},
+		{"only snoozed jobs due later", db.GetQueueIdleStateRow{NextScheduledAt: later(time.Hour)}, nil, true},
+		{"scheduled job due within the wake lead", db.GetQueueIdleStateRow{NextScheduledAt: later(time.Minute)}, nil, false},
+		{"workable jobs", db.GetQueueIdleStateRow{Active: 2}, nil, false},
+		{"job in flight", db.GetQueueIdleStateRow{}, stubJobSource{{ID: 1}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := start
+			d := NewIdleDetector(&stubIdleSource{row: tt.row}, tt.inFlight, []string{"specview_default"},
+				WithIdleGrace(10*time.Minute),
+				WithIdleWakeLead(2*time.Minute),
+			)
+			d.now = func() time.Time { return now }
+
+			status, err := d.IdleStatus(context.Background())
+			if err != nil {
+				t.Fatalf("IdleStatus failed: %v", err)
+			}
+			if status.Idle {
+				t.Fatal("should not report idle before the grace period")
+			}
+
+			// Keep the scheduled job at the same distance so only the grace period elapses.
+			now = start.Add(10 * time.Minute)
+			if tt.row.NextScheduledAt.Valid {
+				tt.row.NextScheduledAt.Time = tt.row.NextScheduledAt.Time.Add(10 * time.Minute)
+				d.source = &stubIdleSource{row: tt.row}
+			}
+			status, err = d.IdleStatus(context.Background())
+			if err != nil {
+				t.Fatalf("IdleStatus failed: %v", err)
+			}
+			if status.Idle != tt.wantIdle {
+				t.Errorf("Idle = %v, want %v (status %+v)", status.Idle, tt.wantIdle, status)
+			}
+		})
+	}
+}
+
+func TestIdleDetector_Wake(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	d := NewIdleDetector(&stubIdleSource{}, nil, []string{"specview_default"}, WithIdleGrace(time.Minute))
+	d.now = func() time.Time { return now }
+
+	if _, err := d.IdleStatus(context.Background()); err != nil {
+		t.Fatalf("IdleStatus failed: %v", err)
+	}
+	now = now.Add(time.Minute)
+	d.Wake()
+
+	status, err := d.IdleStatus(context.Background())
+	if err != nil {
+		t.Fatalf("IdleStatus failed: %v", err)
+	}
+	if status.Idle || !status.IdleSince.Equal(now) {
+		t.Errorf("a wake should restart the idle period, got %+v", status)
+	}
+}
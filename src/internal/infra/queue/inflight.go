@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// InFlightJob summarizes a job currently being worked by this process.
+type InFlightJob struct {
+	Attempt   int       `json:"attempt"`
+	ID        int64     `json:"id"`
+	Kind      string    `json:"kind"`
+	Queue     string    `json:"queue"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// InFlightTracker records the jobs this process is working, for debugging
+// workers that appear stuck.
+type InFlightTracker struct {
+	river.MiddlewareDefaults
+	jobs map[int64]InFlightJob
+	mu   sync.Mutex
+}
+
+var _ rivertype.WorkerMiddleware = (*InFlightTracker)(nil)
+
+func NewInFlightTracker() *InFlightTracker {
+	return &InFlightTracker{jobs: make(map[int64]InFlightJob)}
+}
+
+// Work implements river.WorkerMiddleware.
+func (t *InFlightTracker) Work(
+	ctx context.Context,
+	job *rivertype.JobRow,
+	doInner func(ctx context.Context) error,
+) error {
+	t.mu.Lock()
+	t.jobs[job.ID] = InFlightJob{
+		Attempt:   job.Attempt,
+		ID:        job.ID,
+		Kind:      job.Kind,
+		Queue:     job.Queue,
+		StartedAt: time.Now(),
+	}
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.jobs, job.ID)
+		t.mu.Unlock()
+	}()
+	return doInner(ctx)
+}
+
+// InFlightJobs returns the jobs being worked, longest-running first.
+func (t *InFlightTracker) InFlightJobs() []InFlightJob {
+	t.mu.Lock()
+	jobs := make([]InFlightJob, 0, len(t.jobs))
+	for _, j := range t.jobs {
+		jobs = append(jobs, j)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].StartedAt.Before(jobs[k].StartedAt)
+	})
+	return jobs
+}
@@ -0,0 +1,28 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestInFlightTracker_Work(t *testing.T) {
+	tracker := NewInFlightTracker()
+	job := &rivertype.JobRow{Attempt: 2, ID: 7, Kind: "specview:generate", Queue: "specview_default"}
+
+	err := tracker.Work(context.Background(), job, func(ctx context.Context) error {
+		jobs := tracker.InFlightJobs()
+		if len(jobs) != 1 || jobs[0].ID != 7 || jobs[0].Attempt != 2 || jobs[0].StartedAt.IsZero() {
+			t.Errorf("unexpected in-flight jobs while working: %+v", jobs)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Work failed: %v", err)
+	}
+
+	if jobs := tracker.InFlightJobs(); len(jobs) != 0 {
+		t.Errorf("finished job still listed: %+v", jobs)
+	}
+}
@@ -2,6 +2,8 @@ package queue
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -14,8 +16,16 @@ import (
 const (
 	DefaultConcurrency     = 5
 	DefaultShutdownTimeout = 30 * time.Second
+
+	// DefaultRescueStuckJobsAfter is River's default, which suits services whose
+	// jobs finish within minutes. Services running longer jobs set
+	// ServerConfig.RescueStuckJobsAfter above their longest job timeout.
+	DefaultRescueStuckJobsAfter = time.Hour
 )
 
+// ErrNotRunning is reported by Ready before Start and after Stop.
+var ErrNotRunning = errors.New("queue server not running")
+
 // QueueAllocation defines worker count for a specific queue.
 type QueueAllocation struct {
 	Name       string
@@ -23,15 +33,19 @@ type QueueAllocation struct {
 }
 
 type ServerConfig struct {
-	Middleware      []rivertype.WorkerMiddleware
-	Pool            *pgxpool.Pool
-	Queues          []QueueAllocation
-	ShutdownTimeout time.Duration
-	Workers         *river.Workers
+	Middleware           []rivertype.WorkerMiddleware
+	Pool                 *pgxpool.Pool
+	Queues               []QueueAllocation
+	RescueStuckJobsAfter time.Duration           // default: DefaultRescueStuckJobsAfter
+	RetryPolicy          river.ClientRetryPolicy // nil uses River's default
+	ShutdownTimeout      time.Duration
+	Workers              *river.Workers
 }
 
 type Server struct {
 	client          *river.Client[pgx.Tx]
+	inFlight        *InFlightTracker
+	running         atomic.Bool
 	shutdownTimeout time.Duration
 }
 
@@ -41,22 +55,31 @@ func NewServer(ctx context.Context, cfg ServerConfig) (*Server, error) {
 		shutdownTimeout = DefaultShutdownTimeout
 	}
 
+	rescueAfter := cfg.RescueStuckJobsAfter
+	if rescueAfter <= 0 {
+		rescueAfter = DefaultRescueStuckJobsAfter
+	}
+
 	queues := buildQueueConfig(cfg)
+	inFlight := NewInFlightTracker()
 
-	riverConfig := &river.Config{
-		Queues:  queues,
-		Workers: cfg.Workers,
+	// Ensure WorkerMiddleware implements Middleware at compile time
+	var _ rivertype.Middleware = (rivertype.WorkerMiddleware)(nil)
+
+	// WorkerMiddleware embeds Middleware, assign to interface slice for River config.
+	// The in-flight tracker is outermost so it also lists jobs held up by other middleware.
+	middleware := make([]rivertype.Middleware, 0, len(cfg.Middleware)+1)
+	middleware = append(middleware, inFlight)
+	for _, m := range cfg.Middleware {
+		middleware = append(middleware, m)
 	}
-	if len(cfg.Middleware) > 0 {
-		// Ensure WorkerMiddleware implements Middleware at compile time
-		var _ rivertype.Middleware = (rivertype.WorkerMiddleware)(nil)
-
-		// WorkerMiddleware embeds Middleware, assign to interface slice for River config
-		middleware := make([]rivertype.Middleware, len(cfg.Middleware))
-		for i, m := range cfg.Middleware {
-			middleware[i] = m
-		}
-		riverConfig.Middleware = middleware
+
+	riverConfig := &river.Config{
+		Middleware:           middleware,
+		Queues:               queues,
+		RescueStuckJobsAfter: rescueAfter,
+		RetryPolicy:          cfg.RetryPolicy,
+		Workers:              cfg.Workers,
 	}
 
 	client, err := river.NewClient(riverpgxv5.New(cfg.Pool), riverConfig)
@@ -66,6 +89,7 @@ func NewServer(ctx context.Context, cfg ServerConfig) (*Server, error) {
 
 	return &Server{
 		client:          client,
+		inFlight:        inFlight,
 		shutdownTimeout: shutdownTimeout,
 	}, nil
 }
@@ -88,15 +112,47 @@ func buildQueueConfig(cfg ServerConfig) map[string]river.QueueConfig {
 }
 
 func (s *Server) Start(ctx context.Context) error {
-	return s.client.Start(ctx)
+	if err := s.client.Start(ctx); err != nil {
+		return err
+	}
+	s.running.Store(true)
+	return nil
 }
 
 func (s *Server) Stop(ctx context.Context) error {
+	s.running.Store(false)
 	ctx, cancel := context.WithTimeout(ctx, s.shutdownTimeout)
 	defer cancel()
 	return s.client.Stop(ctx)
 }
 
+// Drain stops fetching new jobs and waits for in-flight jobs to finish
+// without the shutdown timeout. When ctx ends first, the remaining jobs are
+// cancelled and given the shutdown timeout to return.
+func (s *Server) Drain(ctx context.Context) error {
+	s.running.Store(false)
+	err := s.client.Stop(ctx)
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.shutdownTimeout)
+	defer cancel()
+	return s.client.StopAndCancel(cancelCtx)
+}
+
+// Ready reports whether the server is subscribed to its queues.
+func (s *Server) Ready(context.Context) error {
+	if !s.running.Load() {
+		return ErrNotRunning
+	}
+	return nil
+}
+
+// InFlightJobs returns the jobs this server is working, longest-running first.
+func (s *Server) InFlightJobs() []InFlightJob {
+	return s.inFlight.InFlightJobs()
+}
+
 func (s *Server) Client() *river.Client[pgx.Tx] {
 	return s.client
 }
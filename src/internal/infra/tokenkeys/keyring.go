@@ -0,0 +1,144 @@
+// Package tokenkeys encrypts stored OAuth tokens with a rotating set of keys.
+//
+// Ciphertexts sealed by a Keyring carry the ID of their key in a versioned
+// envelope, "sk1:<key-id>:<ciphertext>", so rotated keys keep opening the
+// rows they sealed until those rows are re-encrypted. Ciphertexts without an
+// envelope were sealed with the legacy ENCRYPTION_KEY, as the Web app and
+// older workers write them.
+package tokenkeys
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/specvital/core/pkg/crypto"
+)
+
+// envelopePrefix marks a ciphertext sealed by a Keyring, version 1.
+const envelopePrefix = "sk1:"
+
+// LegacyKeyID is the key ID reported for ciphertexts without an envelope.
+const LegacyKeyID = ""
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+var (
+	// ErrUnknownKey indicates a ciphertext names a key the keyring does not hold.
+	ErrUnknownKey = errors.New("tokenkeys: unknown key")
+	// ErrNoKeys indicates neither a legacy key nor versioned keys were provided.
+	ErrNoKeys = errors.New("tokenkeys: no keys configured")
+)
+
+// Key is one versioned encryption key.
+type Key struct {
+	Encryptor crypto.Encryptor
+	ID        string
+}
+
+// Keyring seals with its current key and opens with any key it holds. It
+// implements crypto.Encryptor, so repositories use it like a single key.
+type Keyring struct {
+	current string // LegacyKeyID when only the legacy key is configured
+	keys    map[string]crypto.Encryptor
+}
+
+var _ crypto.Encryptor = (*Keyring)(nil)
+
+// New creates a keyring from the legacy key and versioned keys, current key
+// first. Either may be empty: a keyring with only the legacy key seals without
+// an envelope, exactly like a plain encryptor. The keyring owns the keys and
+// closes them on Close.
+func New(legacy crypto.Encryptor, keys []Key) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]crypto.Encryptor, len(keys)+1)}
+	if legacy != nil {
+		k.keys[LegacyKeyID] = legacy
+	}
+	for _, key := range keys {
+		if !keyIDPattern.MatchString(key.ID) {
+			return nil, fmt.Errorf("tokenkeys: invalid key ID %q (letters, digits, '-' and '_', up to 64)", key.ID)
+		}
+		if _, ok := k.keys[key.ID]; ok {
+			return nil, fmt.Errorf("tokenkeys: duplicate key ID %q", key.ID)
+		}
+		k.keys[key.ID] = key.Encryptor
+	}
+	if len(k.keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	if len(keys) > 0 {
+		k.current = keys[0].ID
+	}
+	return k, nil
+}
+
+// CurrentKeyID returns the ID of the key new ciphertexts are sealed with.
+func (k *Keyring) CurrentKeyID() string {
+	return k.current
+}
+
+// Encrypt seals plaintext with the current key.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	sealed, err := k.keys[k.current].Encrypt(plaintext)
+	if err != nil {
+		return "", err
+	}
+	if k.current == LegacyKeyID {
+		return sealed, nil
+	}
+	return envelopePrefix + k.current + ":" + sealed, nil
+}
+
+// Decrypt opens a ciphertext with the key it names.
+func (k *Keyring) Decrypt(ciphertext string) (string, error) {
+	id, sealed := splitEnvelope(ciphertext)
+	enc, ok := k.keys[id]
+	if !ok {
+		if id == LegacyKeyID {
+			return "", fmt.Errorf("%w: ciphertext has no envelope and no legacy ENCRYPTION_KEY is configured", ErrUnknownKey)
+		}
+		return "", fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	return enc.Decrypt(sealed)
+}
+
+// IsCurrent reports whether ciphertext is sealed with the current key, so it
+// needs no re-encryption.
+func (k *Keyring) IsCurrent(ciphertext string) bool {
+	return KeyID(ciphertext) == k.current
+}
+
+// Close releases every key.
+func (k *Keyring) Close() error {
+	var errs []error
+	for id, enc := range k.keys {
+		if err := enc.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(k.keys, id)
+	}
+	return errors.Join(errs...)
+}
+
+// KeyID returns the ID of the key that sealed ciphertext, LegacyKeyID for
+// ciphertexts without an envelope.
+func KeyID(ciphertext string) string {
+	id, _ := splitEnvelope(ciphertext)
+	return id
+}
+
+// splitEnvelope returns the key ID and the sealed value of a ciphertext. The
+// base64 alphabet has no ':', so a legacy ciphertext never looks like an
+// envelope.
+func splitEnvelope(ciphertext string) (string, string) {
+	rest, ok := strings.CutPrefix(ciphertext, envelopePrefix)
+	if !ok {
+		return LegacyKeyID, ciphertext
+	}
+	id, sealed, ok := strings.Cut(rest, ":")
+	if !ok {
+		return LegacyKeyID, ciphertext
+	}
+	return id, sealed
+}
@@ -0,0 +1,115 @@
+package tokenkeys
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/specvital/core/pkg/crypto"
+)
+
+func testKey(t *testing.T, b byte) string {
+	t.Helper()
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, crypto.KeyLength))
+}
+
+func testEncryptor(t *testing.T, b byte) crypto.Encryptor {
+	t.Helper()
+	enc, err := crypto.NewEncryptorFromBase64(testKey(t, b))
+	if err != nil {
+		t.Fatalf("create encryptor: %v", err)
+	}
+	return enc
+}
+
+func TestKeyring_LegacyOnly(t *testing.T) {
+	legacy := testEncryptor(t, 1)
+	k, err := New(testEncryptor(t, 1), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sealed, err := k.Encrypt("gho_token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.HasPrefix(sealed, envelopePrefix) {
+		t.Errorf("a legacy-only keyring should seal without an envelope, got %q", sealed)
+	}
+	if opened, err := legacy.Decrypt(sealed); err != nil || opened != "gho_token" {
+		t.Errorf("plain encryptor should open it, got %q, %v", opened, err)
+	}
+	if !k.IsCurrent(sealed) {
+		t.Error("legacy ciphertexts are current without versioned keys")
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	legacySealed, _ := testEncryptor(t, 1).Encrypt("legacy-token")
+
+	old, err := New(nil, []Key{{ID: "2025-01", Encryptor: testEncryptor(t, 2)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	oldSealed, _ := old.Encrypt("old-token")
+	if KeyID(oldSealed) != "2025-01" {
+		t.Fatalf("expected the envelope to name 2025-01, got %q", oldSealed)
+	}
+
+	k, err := New(testEncryptor(t, 1), []Key{
+		{ID: "2026-10", Encryptor: testEncryptor(t, 3)},
+		{ID: "2025-01", Encryptor: testEncryptor(t, 2)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if k.CurrentKeyID() != "2026-10" {
+		t.Errorf("expected the first key to be current, got %q", k.CurrentKeyID())
+	}
+
+	for sealed, want := range map[string]string{legacySealed: "legacy-token", oldSealed: "old-token"} {
+		opened, err := k.Decrypt(sealed)
+		if err != nil || opened != want {
+			t.Errorf("Decrypt = %q, %v; want %q", opened, err, want)
+		}
+		if k.IsCurrent(sealed) {
+			t.Errorf("%q should need re-encryption", sealed)
+		}
+	}
+
+	newSealed, err := k.Encrypt("new-token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !k.IsCurrent(newSealed) || KeyID(newSealed) != "2026-10" {
+		t.Errorf("expected a ciphertext of the current key, got %q", newSealed)
+	}
+}
+
+func TestKeyring_UnknownKey(t *testing.T) {
+	k, err := New(nil, []Key{{ID: "2026-10", Encryptor: testEncryptor(t, 3)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	legacySealed, _ := testEncryptor(t, 1).Encrypt("legacy-token")
+	for _, sealed := range []string{"sk1:retired:abc", legacySealed} {
+		if _, err := k.Decrypt(sealed); !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("Decrypt(%q) error = %v, want ErrUnknownKey", sealed, err)
+		}
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(nil, nil); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("expected ErrNoKeys, got %v", err)
+	}
+	if _, err := New(nil, []Key{{ID: "bad:id", Encryptor: testEncryptor(t, 1)}}); err == nil {
+		t.Error("expected an error for a key ID with ':'")
+	}
+	if _, err := New(nil, []Key{{ID: "a", Encryptor: testEncryptor(t, 1)}, {ID: "a", Encryptor: testEncryptor(t, 2)}}); err == nil {
+		t.Error("expected an error for duplicate key IDs")
+	}
+}
@@ -0,0 +1,158 @@
+package tokenkeys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/specvital/core/pkg/crypto"
+)
+
+// DefaultVaultField is the field of the Vault secret holding the key spec.
+const DefaultVaultField = "keys"
+
+// Source supplies a key spec: entries "<key-id>=<base64 key>" separated by
+// commas or newlines, current key first. Blank lines and lines starting with
+// '#' are ignored.
+type Source interface {
+	LoadSpec(ctx context.Context) (string, error)
+}
+
+// StaticSource is a key spec given directly, as in ENCRYPTION_KEYS.
+type StaticSource string
+
+func (s StaticSource) LoadSpec(context.Context) (string, error) {
+	return string(s), nil
+}
+
+// FileSource reads the key spec from a file, such as a secret mounted by a
+// secret manager's agent or CSI driver. The file is read on every load, so a
+// restart picks up a rotated secret.
+type FileSource string
+
+func (s FileSource) LoadSpec(context.Context) (string, error) {
+	data, err := os.ReadFile(string(s))
+	if err != nil {
+		return "", fmt.Errorf("read key file: %w", err)
+	}
+	return string(data), nil
+}
+
+// VaultSource reads the key spec from a field of a HashiCorp Vault secret.
+// Path is the API path below /v1, such as "secret/data/specvital/token-keys"
+// for a KV version 2 mount; KV version 1 paths work too.
+type VaultSource struct {
+	Addr       string
+	Field      string // empty uses DefaultVaultField
+	HTTPClient *http.Client
+	Path       string
+	Token      string
+}
+
+func (s VaultSource) LoadSpec(ctx context.Context) (string, error) {
+	if s.Addr == "" || s.Path == "" || s.Token == "" {
+		return "", errors.New("vault address, path and token are required")
+	}
+	field := s.Field
+	if field == "" {
+		field = DefaultVaultField
+	}
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	url := strings.TrimSuffix(s.Addr, "/") + "/v1/" + strings.TrimPrefix(s.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", s.Token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("read vault secret %s: %w", s.Path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("read vault secret %s: unexpected status %d", s.Path, resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode vault secret: %w", err)
+	}
+	fields := body.Data
+	// KV version 2 nests the secret one level deeper, next to its metadata.
+	if nested, ok := fields["data"]; ok {
+		if _, hasMeta := fields["metadata"]; hasMeta {
+			fields = nil
+			if err := json.Unmarshal(nested, &fields); err != nil {
+				return "", fmt.Errorf("decode vault secret: %w", err)
+			}
+		}
+	}
+	raw, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %q", s.Path, field)
+	}
+	var spec string
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return "", fmt.Errorf("vault secret field %q is not a string", field)
+	}
+	return spec, nil
+}
+
+// Load reads the key spec from src and parses it.
+func Load(ctx context.Context, src Source) ([]Key, error) {
+	spec, err := src.LoadSpec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeys(spec)
+}
+
+// ParseKeys parses a key spec. On error, keys parsed so far are closed.
+func ParseKeys(spec string) ([]Key, error) {
+	var keys []Key
+	fail := func(err error) ([]Key, error) {
+		for _, k := range keys {
+			k.Encryptor.Close()
+		}
+		return nil, err
+	}
+
+	for _, entry := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		// Base64 padding also uses '=', so only the first one separates the ID.
+		id, encoded, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fail(fmt.Errorf("tokenkeys: entry %q is not <key-id>=<base64 key>", redact(entry)))
+		}
+		id = strings.TrimSpace(id)
+		enc, err := crypto.NewEncryptorFromBase64(strings.TrimSpace(encoded))
+		if err != nil {
+			return fail(fmt.Errorf("tokenkeys: key %q: %w", id, err))
+		}
+		keys = append(keys, Key{Encryptor: enc, ID: id})
+	}
+	return keys, nil
+}
+
+// redact shortens a malformed entry, which may be a bare key, before it is
+// shown in an error.
+func redact(entry string) string {
+	if len(entry) > 8 {
+		return entry[:4] + "…"
+	}
+	return "…"
+}
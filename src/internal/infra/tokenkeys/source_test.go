@@ -0,0 +1,96 @@
+package tokenkeys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	spec := "# rotated 2026-10\n2026-10=" + testKey(t, 3) + "\n\n2025-01=" + testKey(t, 2) + "\n"
+
+	keys, err := ParseKeys(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != "2026-10" || keys[1].ID != "2025-01" {
+		t.Errorf("expected both keys in order, got %+v", keys)
+	}
+
+	if _, err := ParseKeys("a=" + testKey(t, 1) + ",b=short"); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+	if _, err := ParseKeys(testKey(t, 1)); err == nil {
+		t.Error("expected an error for an entry without an ID")
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token-keys")
+	if err := os.WriteFile(path, []byte("k1="+testKey(t, 1)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := Load(context.Background(), FileSource(path))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].ID != "k1" {
+		t.Errorf("got %+v", keys)
+	}
+}
+
+func TestVaultSource(t *testing.T) {
+	spec := "k2=" + testKey(t, 2) + ",k1=" + testKey(t, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/token-keys":
+			w.Write([]byte(`{"data": {"data": {"keys": "` + spec + `"}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/token-keys":
+			w.Write([]byte(`{"data": {"current": "` + spec + `"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	t.Run("kv version 2", func(t *testing.T) {
+		keys, err := Load(ctx, VaultSource{Addr: server.URL, Path: "secret/data/token-keys", Token: "s.token"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(keys) != 2 || keys[0].ID != "k2" {
+			t.Errorf("got %+v", keys)
+		}
+	})
+
+	t.Run("kv version 1 with a custom field", func(t *testing.T) {
+		keys, err := Load(ctx, VaultSource{Addr: server.URL, Field: "current", Path: "/kv/token-keys", Token: "s.token"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(keys) != 2 {
+			t.Errorf("got %+v", keys)
+		}
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		if _, err := Load(ctx, VaultSource{Addr: server.URL, Field: "other", Path: "kv/token-keys", Token: "s.token"}); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		if _, err := Load(ctx, VaultSource{Addr: server.URL, Path: "kv/token-keys", Token: "wrong"}); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
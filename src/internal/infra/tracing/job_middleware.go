@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/specvital/worker/internal/infra/tracing"
+
+// JobMiddleware starts a new trace for every worked job. Spans created by the
+// worker, its use case and the repositories it calls become children of it.
+type JobMiddleware struct {
+	river.MiddlewareDefaults
+	tracer trace.Tracer
+}
+
+var _ rivertype.WorkerMiddleware = (*JobMiddleware)(nil)
+
+func NewJobMiddleware() *JobMiddleware {
+	return &JobMiddleware{tracer: otel.Tracer(instrumentationName)}
+}
+
+// Work implements river.WorkerMiddleware.
+func (m *JobMiddleware) Work(
+	ctx context.Context,
+	job *rivertype.JobRow,
+	doInner func(ctx context.Context) error,
+) error {
+	ctx, span := m.tracer.Start(ctx, "job "+job.Kind,
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.Int("river.job.attempt", job.Attempt),
+			attribute.Int64("river.job.id", job.ID),
+			attribute.String("river.job.kind", job.Kind),
+			attribute.String("river.queue", job.Queue),
+		),
+	)
+
+	err := doInner(ctx)
+
+	// Cancelled and snoozed jobs are decisions, not failures.
+	var cancelErr *rivertype.JobCancelError
+	var snoozeErr *rivertype.JobSnoozeError
+	switch {
+	case errors.As(err, &cancelErr):
+		span.SetAttributes(attribute.String("river.job.outcome", "cancelled"))
+		span.End()
+	case errors.As(err, &snoozeErr):
+		span.SetAttributes(attribute.String("river.job.outcome", "snoozed"))
+		span.End()
+	default:
+		EndSpan(span, err)
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/specvital/worker/internal/infra/config"
+)
+
+func newRecorder() (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	return recorder, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+}
+
+func TestSetup_DisabledWithoutEndpoint(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{}, "analyzer", "v1")
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("no-op shutdown failed: %v", err)
+	}
+}
+
+func TestJobMiddleware_Work(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+	}{
+		{"success", nil, codes.Unset},
+		{"failure", errors.New("clone failed"), codes.Error},
+		{"cancelled", river.JobCancel(errors.New("policy violation")), codes.Unset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder, provider := newRecorder()
+			m := &JobMiddleware{tracer: provider.Tracer("test")}
+			job := &rivertype.JobRow{ID: 7, Kind: "analysis:analyze", Queue: "analysis_default", Attempt: 1}
+
+			err := m.Work(context.Background(), job, func(ctx context.Context) error {
+				_, child := provider.Tracer("test").Start(ctx, "analysis.Execute")
+				child.End()
+				return tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Work() error = %v, want %v", err, tt.err)
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != 2 {
+				t.Fatalf("expected 2 spans, got %d", len(spans))
+			}
+			child, root := spans[0], spans[1]
+			if root.Name() != "job analysis:analyze" || root.Status().Code != tt.wantStatus {
+				t.Errorf("unexpected job span %q with status %v", root.Name(), root.Status())
+			}
+			if child.Parent().SpanID() != root.SpanContext().SpanID() {
+				t.Error("use case span should be a child of the job span")
+			}
+		})
+	}
+}
+
+func TestQueryTracer(t *testing.T) {
+	recorder, provider := newRecorder()
+	qt := &QueryTracer{tracer: provider.Tracer("test")}
+	sql := "-- name: GetCodebaseByID :one\nSELECT id FROM codebases WHERE id = $1"
+
+	ctx := qt.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: sql})
+	qt.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	if got := len(recorder.Ended()); got != 0 {
+		t.Fatalf("queries outside a trace should not be recorded, got %d spans", got)
+	}
+
+	parentCtx, parent := provider.Tracer("test").Start(context.Background(), "job")
+	ctx = qt.TraceQueryStart(parentCtx, nil, pgx.TraceQueryStartData{SQL: sql})
+	qt.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1"), Err: pgx.ErrNoRows})
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected query and parent spans, got %d", len(spans))
+	}
+	if spans[0].Name() != "db GetCodebaseByID" || spans[0].Status().Code != codes.Unset {
+		t.Errorf("unexpected query span %q with status %v", spans[0].Name(), spans[0].Status())
+	}
+}
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"-- name: ClaimWebhookDelivery :one\n\nINSERT INTO webhook_deliveries", "ClaimWebhookDelivery"},
+		{"  select 1", "SELECT"},
+		{"\nUPDATE river_job\nSET state = 'available'", "UPDATE"},
+		{"", "query"},
+	}
+
+	for _, tt := range tests {
+		if got := queryName(tt.sql); got != tt.want {
+			t.Errorf("queryName(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
@@ -20,7 +20,7 @@ func SetupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
 	ctx := context.Background()
 
 	container, err := postgres.Run(ctx,
-		"postgres:16-alpine",
+		"pgvector/pgvector:pg16",
 		postgres.WithDatabase("testdb"),
 		postgres.WithUsername("test"),
 		postgres.WithPassword("test"),
@@ -85,6 +85,17 @@ func SetupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
 }
 
 func runMigrations(ctx context.Context, pool *pgxpool.Pool) error {
-	_, err := pool.Exec(ctx, Schema())
+	if _, err := pool.Exec(ctx, Schema()); err != nil {
+		return err
+	}
+
+	var vectorAvailable bool
+	if err := pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector')").Scan(&vectorAvailable); err != nil {
+		return err
+	}
+	if !vectorAvailable {
+		return nil
+	}
+	_, err := pool.Exec(ctx, VectorSchema())
 	return err
 }
@@ -9,6 +9,9 @@ import (
 //go:embed schema.sql
 var rawSchema string
 
+//go:embed schema_vector.sql
+var vectorSchema string
+
 // Schema returns the processed schema SQL ready for test database initialization.
 // It transforms pg_dump output into executable SQL for fresh PostgreSQL containers.
 func Schema() string {
@@ -33,3 +36,9 @@ func Schema() string {
 
 	return strings.Join(filtered, "\n")
 }
+
+// VectorSchema returns the objects that need the pgvector extension, to be
+// applied after Schema where the extension is available.
+func VectorSchema() string {
+	return vectorSchema
+}
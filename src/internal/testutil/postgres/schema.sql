@@ -14,6 +14,21 @@
 CREATE SCHEMA public;
 
 
+--
+-- Name: analysis_failure_class; Type: TYPE; Schema: public; Owner: -
+--
+
+CREATE TYPE public.analysis_failure_class AS ENUM (
+    'clone_auth_failed',
+    'clone_timeout',
+    'parser_panic',
+    'repo_too_large',
+    'disk_full',
+    'oom',
+    'unknown'
+);
+
+
 --
 -- Name: analysis_status; Type: TYPE; Schema: public; Owner: -
 --
@@ -130,6 +145,41 @@ $$;
 
 
 
+--
+-- Name: ai_output_quarantine; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.ai_output_quarantine (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    phase character varying(20) NOT NULL,
+    schema_version character varying(50) NOT NULL,
+    model_id character varying(100) NOT NULL,
+    response text NOT NULL,
+    error text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: ai_usage; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.ai_usage (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    job_id bigint,
+    analysis_id uuid,
+    user_id uuid,
+    document_id uuid,
+    phase character varying(20) NOT NULL,
+    model character varying(100) NOT NULL,
+    fallback_from character varying(100),
+    prompt_tokens integer NOT NULL,
+    candidates_tokens integer NOT NULL,
+    total_tokens integer NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
 --
 -- Name: analyses; Type: TABLE; Schema: public; Owner: -
 --
@@ -147,7 +197,113 @@ CREATE TABLE public.analyses (
     total_suites integer DEFAULT 0 NOT NULL,
     total_tests integer DEFAULT 0 NOT NULL,
     committed_at timestamp with time zone,
-    parser_version character varying(100) DEFAULT 'legacy'::character varying NOT NULL
+    parser_version character varying(100) DEFAULT 'legacy'::character varying NOT NULL,
+    curation_rules jsonb,
+    failure_class public.analysis_failure_class,
+    path_filters jsonb,
+    quarantined_files integer DEFAULT 0 NOT NULL,
+    test_fingerprint bytea,
+    deduplicated_from uuid
+);
+
+
+--
+-- Name: analysis_events; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.analysis_events (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    job_id bigint,
+    analysis_id uuid,
+    owner character varying(255) NOT NULL,
+    repo character varying(255) NOT NULL,
+    commit_sha character varying(40) NOT NULL,
+    stage character varying(30) NOT NULL,
+    files_processed integer DEFAULT 0 NOT NULL,
+    files_total integer DEFAULT 0 NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: analysis_languages; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.analysis_languages (
+    analysis_id uuid NOT NULL,
+    language character varying(20) NOT NULL,
+    files integer DEFAULT 0 NOT NULL,
+    tests integer DEFAULT 0 NOT NULL,
+    failed_files integer DEFAULT 0 NOT NULL,
+    unmatched_files integer DEFAULT 0 NOT NULL
+);
+
+
+--
+-- Name: analysis_shared_helpers; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.analysis_shared_helpers (
+    analysis_id uuid NOT NULL,
+    helper_path character varying(1000) NOT NULL,
+    test_files text[] NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: analysis_source_files; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.analysis_source_files (
+    analysis_id uuid NOT NULL,
+    file_path character varying(1000) NOT NULL
+);
+
+
+--
+-- Name: analysis_staging_test_cases; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE UNLOGGED TABLE public.analysis_staging_test_cases (
+    analysis_id uuid NOT NULL,
+    suite_id uuid NOT NULL,
+    name character varying(2000) NOT NULL,
+    line_number integer,
+    status public.test_status DEFAULT 'active'::public.test_status NOT NULL,
+    tags jsonb DEFAULT '[]'::jsonb NOT NULL,
+    modifier character varying(50),
+    variant_count integer DEFAULT 1 NOT NULL
+);
+
+
+--
+-- Name: analysis_staging_test_files; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE UNLOGGED TABLE public.analysis_staging_test_files (
+    id uuid NOT NULL,
+    analysis_id uuid NOT NULL,
+    file_path character varying(1000) NOT NULL,
+    framework character varying(50),
+    domain_hints jsonb,
+    project character varying(500),
+    content_hash bytea
+);
+
+
+--
+-- Name: analysis_staging_test_suites; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE UNLOGGED TABLE public.analysis_staging_test_suites (
+    id uuid NOT NULL,
+    analysis_id uuid NOT NULL,
+    file_id uuid NOT NULL,
+    parent_id uuid,
+    name character varying(500) NOT NULL,
+    line_number integer,
+    depth integer DEFAULT 0 NOT NULL
 );
 
 
@@ -179,7 +335,25 @@ CREATE TABLE public.behavior_caches (
     id uuid DEFAULT gen_random_uuid() NOT NULL,
     cache_key_hash bytea NOT NULL,
     converted_description text NOT NULL,
-    created_at timestamp with time zone DEFAULT now() NOT NULL
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    codebase_id uuid,
+    human_verified boolean DEFAULT false NOT NULL
+);
+
+
+--
+-- Name: behavior_feedback; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.behavior_feedback (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    behavior_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    corrected_description text NOT NULL,
+    status character varying(20) DEFAULT 'pending'::character varying NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    applied_at timestamp with time zone,
+    CONSTRAINT chk_behavior_feedback_status CHECK (((status)::text = ANY ((ARRAY['pending'::character varying, 'accepted'::character varying, 'rejected'::character varying, 'applied'::character varying])::text[])))
 );
 
 
@@ -194,10 +368,36 @@ CREATE TABLE public.classification_caches (
     model_id character varying(100) NOT NULL,
     phase1_output jsonb NOT NULL,
     test_index_map jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    codebase_id uuid
+);
+
+
+--
+-- Name: codebase_sharing_opt_outs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.codebase_sharing_opt_outs (
+    codebase_id uuid NOT NULL,
+    reason text,
     created_at timestamp with time zone DEFAULT now() NOT NULL
 );
 
 
+--
+-- Name: codebase_taxonomies; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.codebase_taxonomies (
+    codebase_id uuid NOT NULL,
+    domains jsonb NOT NULL,
+    mode character varying(10) DEFAULT 'remap'::character varying NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT chk_codebase_taxonomies_mode CHECK (((mode)::text = ANY ((ARRAY['remap'::character varying, 'reject'::character varying])::text[])))
+);
+
+
 --
 -- Name: codebases; Type: TABLE; Schema: public; Owner: -
 --
@@ -213,7 +413,37 @@ CREATE TABLE public.codebases (
     last_viewed_at timestamp with time zone,
     external_repo_id character varying(64) NOT NULL,
     is_stale boolean DEFAULT false NOT NULL,
-    is_private boolean DEFAULT false NOT NULL
+    is_private boolean DEFAULT false NOT NULL,
+    region character varying(32),
+    fork_dedup_enabled boolean DEFAULT true NOT NULL
+);
+
+
+--
+-- Name: coverage_uploads; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.coverage_uploads (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    analysis_id uuid NOT NULL,
+    format character varying(20) NOT NULL,
+    content bytea NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    processed_at timestamp with time zone
+);
+
+
+--
+-- Name: enqueue_idempotency_keys; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.enqueue_idempotency_keys (
+    idempotency_key character varying(255) NOT NULL,
+    job_kind character varying(100) NOT NULL,
+    request_hash character varying(64) NOT NULL,
+    job_id bigint,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    expires_at timestamp with time zone NOT NULL
 );
 
 
@@ -251,6 +481,63 @@ CREATE TABLE public.github_organizations (
 );
 
 
+--
+-- Name: glossary_entries; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.glossary_entries (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid,
+    codebase_id uuid,
+    language character varying(50) NOT NULL,
+    term character varying(255) NOT NULL,
+    preferred character varying(255) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: job_results; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.job_results (
+    job_id bigint NOT NULL,
+    kind character varying(100) NOT NULL,
+    queue character varying(100) NOT NULL,
+    status character varying(20) NOT NULL,
+    attempt integer NOT NULL,
+    analysis_id uuid,
+    document_id uuid,
+    error_code character varying(100),
+    stats jsonb DEFAULT '{}'::jsonb NOT NULL,
+    completed_at timestamp with time zone DEFAULT now() NOT NULL,
+    user_id uuid,
+    enqueued_at timestamp with time zone,
+    started_at timestamp with time zone,
+    CONSTRAINT chk_job_results_status CHECK (((status)::text = ANY ((ARRAY['completed'::character varying, 'cancelled'::character varying, 'discarded'::character varying, 'retryable'::character varying])::text[])))
+);
+
+
+--
+-- Name: notification_channels; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.notification_channels (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    codebase_id uuid,
+    user_id uuid,
+    kind character varying(20) NOT NULL,
+    url text NOT NULL,
+    on_success boolean DEFAULT true NOT NULL,
+    on_failure boolean DEFAULT true NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT chk_notification_channels_kind CHECK (((kind)::text = ANY ((ARRAY['discord'::character varying, 'slack'::character varying, 'webhook'::character varying])::text[]))),
+    CONSTRAINT chk_notification_channels_outcome CHECK ((on_success OR on_failure)),
+    CONSTRAINT chk_notification_channels_scope CHECK (((codebase_id IS NULL) <> (user_id IS NULL)))
+);
+
+
 --
 -- Name: oauth_accounts; Type: TABLE; Schema: public; Owner: -
 --
@@ -268,6 +555,69 @@ CREATE TABLE public.oauth_accounts (
 );
 
 
+--
+-- Name: outbox_events; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.outbox_events (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    event_type character varying(100) NOT NULL,
+    aggregate_id uuid NOT NULL,
+    payload jsonb NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    last_error text,
+    next_attempt_at timestamp with time zone DEFAULT now() NOT NULL,
+    published_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: parse_errors; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.parse_errors (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    analysis_id uuid NOT NULL,
+    file_path character varying(1000) NOT NULL,
+    message text NOT NULL,
+    panicked boolean DEFAULT false NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: parser_compat_reports; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.parser_compat_reports (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    candidate_version character varying(100) NOT NULL,
+    sample_size integer NOT NULL,
+    matched integer NOT NULL,
+    mismatched integer NOT NULL,
+    skipped integer NOT NULL,
+    failed integer NOT NULL,
+    details jsonb DEFAULT '[]'::jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: poison_jobs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.poison_jobs (
+    job_id bigint NOT NULL,
+    kind character varying(100) NOT NULL,
+    queue character varying(100) NOT NULL,
+    attempt integer NOT NULL,
+    encoded_args bytea NOT NULL,
+    error text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
 --
 -- Name: quota_reservations; Type: TABLE; Schema: public; Owner: -
 --
@@ -284,6 +634,17 @@ CREATE TABLE public.quota_reservations (
 );
 
 
+--
+-- Name: rate_limit_buckets; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.rate_limit_buckets (
+    bucket_key character varying(300) NOT NULL,
+    tokens double precision NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
 --
 -- Name: refresh_tokens; Type: TABLE; Schema: public; Owner: -
 --
@@ -300,6 +661,88 @@ CREATE TABLE public.refresh_tokens (
 );
 
 
+--
+-- Name: regen_campaign_targets; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.regen_campaign_targets (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    campaign_id uuid NOT NULL,
+    document_id uuid NOT NULL,
+    analysis_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    language character varying(10) NOT NULL,
+    team_slices boolean DEFAULT false NOT NULL,
+    status character varying(20) DEFAULT 'pending'::character varying NOT NULL,
+    job_id bigint,
+    error text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    enqueued_at timestamp with time zone,
+    finished_at timestamp with time zone,
+    CONSTRAINT chk_regen_campaign_targets_status CHECK (((status)::text = ANY ((ARRAY['pending'::character varying, 'enqueued'::character varying, 'succeeded'::character varying, 'failed'::character varying])::text[])))
+);
+
+
+--
+-- Name: regen_campaigns; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.regen_campaigns (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    name character varying(255) NOT NULL,
+    status character varying(20) DEFAULT 'running'::character varying NOT NULL,
+    rate_per_minute integer NOT NULL,
+    max_in_flight integer NOT NULL,
+    selection jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT chk_regen_campaigns_status CHECK (((status)::text = ANY ((ARRAY['running'::character varying, 'paused'::character varying, 'completed'::character varying, 'cancelled'::character varying])::text[]))),
+    CONSTRAINT chk_regen_campaigns_throttle CHECK (((rate_per_minute > 0) AND (max_in_flight > 0)))
+);
+
+
+--
+-- Name: requirement_coverage; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.requirement_coverage (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    requirement_id uuid NOT NULL,
+    document_id uuid NOT NULL,
+    behavior_id uuid NOT NULL,
+    score numeric(4,3) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: requirement_sets; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.requirement_sets (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    codebase_id uuid NOT NULL,
+    name character varying(255) NOT NULL,
+    source_format character varying(10) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: requirements; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.requirements (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    requirement_set_id uuid NOT NULL,
+    external_key character varying(255) NOT NULL,
+    title text NOT NULL,
+    description text,
+    sort_order integer DEFAULT 0 NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
 --
 -- Name: river_client; Type: TABLE; Schema: public; Owner: -
 --
@@ -411,7 +854,87 @@ CREATE TABLE public.river_queue (
 
 
 --
--- Name: spec_behaviors; Type: TABLE; Schema: public; Owner: -
+-- Name: service_tokens; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.service_tokens (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    name character varying(100) NOT NULL,
+    token_hash bytea NOT NULL,
+    token_prefix character varying(16) NOT NULL,
+    scopes text[] NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    expires_at timestamp with time zone,
+    last_used_at timestamp with time zone,
+    revoked_at timestamp with time zone,
+    CONSTRAINT chk_service_tokens_scopes CHECK (((cardinality(scopes) > 0) AND (scopes <@ ARRAY['enqueue'::text, 'read-status'::text, 'admin'::text])))
+);
+
+
+--
+-- Name: sla_violations; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.sla_violations (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    job_id bigint NOT NULL,
+    kind character varying(100) NOT NULL,
+    queue character varying(100) NOT NULL,
+    attempt integer NOT NULL,
+    outcome character varying(20) NOT NULL,
+    target_ms bigint NOT NULL,
+    elapsed_ms bigint NOT NULL,
+    slowest_phase character varying(20) NOT NULL,
+    phases jsonb DEFAULT '{}'::jsonb NOT NULL,
+    analysis_id uuid,
+    document_id uuid,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT chk_sla_violations_outcome CHECK (((outcome)::text = ANY ((ARRAY['completed'::character varying, 'cancelled'::character varying, 'failed'::character varying])::text[])))
+);
+
+
+--
+-- Name: spec_behavior_coverage; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_behavior_coverage (
+    behavior_id uuid NOT NULL,
+    lines_valid integer NOT NULL,
+    lines_covered integer NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT chk_spec_behavior_coverage_lines CHECK (((lines_covered >= 0) AND (lines_covered <= lines_valid)))
+);
+
+
+--
+-- Name: spec_behavior_merges; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_behavior_merges (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    behavior_id uuid NOT NULL,
+    source_test_case_id uuid,
+    original_name character varying(2000) NOT NULL,
+    converted_description text NOT NULL,
+    similarity numeric(3,2) NOT NULL,
+    sort_order integer DEFAULT 0 NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: spec_behavior_test_cases; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_behavior_test_cases (
+    behavior_id uuid NOT NULL,
+    test_case_id uuid NOT NULL,
+    sort_order integer DEFAULT 0 NOT NULL
+);
+
+
+--
+-- Name: spec_behaviors; Type: TABLE; Schema: public; Owner: -
 --
 
 CREATE TABLE public.spec_behaviors (
@@ -421,7 +944,65 @@ CREATE TABLE public.spec_behaviors (
     original_name character varying(2000) NOT NULL,
     converted_description text NOT NULL,
     sort_order integer DEFAULT 0 NOT NULL,
-    created_at timestamp with time zone DEFAULT now() NOT NULL
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    cache_key_hash bytea
+);
+
+
+--
+-- Name: spec_document_decisions; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_document_decisions (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    document_id uuid,
+    analysis_id uuid NOT NULL,
+    generation_id uuid NOT NULL,
+    sequence integer NOT NULL,
+    kind character varying(50) NOT NULL,
+    subject text NOT NULL,
+    detail jsonb DEFAULT '{}'::jsonb NOT NULL,
+    occurred_at timestamp with time zone NOT NULL
+);
+
+
+--
+-- Name: spec_document_exports; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_document_exports (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    document_id uuid NOT NULL,
+    format character varying(20) NOT NULL,
+    content_type character varying(100) NOT NULL,
+    file_name character varying(255) NOT NULL,
+    content bytea NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: spec_document_pins; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_document_pins (
+    document_id uuid NOT NULL,
+    release_tag character varying(255) NOT NULL,
+    pinned_by character varying(255),
+    pinned_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: spec_document_shares; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_document_shares (
+    document_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    basis character varying(100) NOT NULL,
+    shared_at timestamp with time zone DEFAULT now() NOT NULL
 );
 
 
@@ -441,7 +1022,21 @@ CREATE TABLE public.spec_documents (
     version integer DEFAULT 1 NOT NULL,
     user_id uuid NOT NULL,
     retention_days_at_creation integer,
-    CONSTRAINT chk_retention_days_positive CHECK (((retention_days_at_creation IS NULL) OR (retention_days_at_creation > 0)))
+    parent_document_id uuid,
+    team character varying(100),
+    project character varying(500),
+    encryption_key_id uuid,
+    prompt_version character varying(50) DEFAULT 'v1'::character varying NOT NULL,
+    deleted_at timestamp with time zone,
+    deleted_by character varying(255),
+    quality_score double precision,
+    quality_flagged boolean DEFAULT false NOT NULL,
+    quality_report jsonb,
+    is_dry_run boolean DEFAULT false NOT NULL,
+    generator character varying(20) DEFAULT 'ai'::character varying NOT NULL,
+    CONSTRAINT chk_retention_days_positive CHECK (((retention_days_at_creation IS NULL) OR (retention_days_at_creation > 0))),
+    CONSTRAINT chk_spec_documents_generator CHECK (((generator)::text = ANY ((ARRAY['ai'::character varying, 'heuristic'::character varying])::text[]))),
+    CONSTRAINT chk_spec_documents_team_slice CHECK (((parent_document_id IS NULL) = (team IS NULL)))
 );
 
 
@@ -457,7 +1052,8 @@ CREATE TABLE public.spec_domains (
     sort_order integer DEFAULT 0 NOT NULL,
     classification_confidence numeric(3,2),
     created_at timestamp with time zone DEFAULT now() NOT NULL,
-    updated_at timestamp with time zone DEFAULT now() NOT NULL
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    slug character varying(255) DEFAULT ''::character varying NOT NULL
 );
 
 
@@ -472,10 +1068,113 @@ CREATE TABLE public.spec_features (
     description text,
     sort_order integer DEFAULT 0 NOT NULL,
     created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    slug character varying(255) DEFAULT ''::character varying NOT NULL
+);
+
+
+--
+-- Name: spec_gap_reports; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_gap_reports (
+    document_id uuid NOT NULL,
+    total_source_files integer NOT NULL,
+    covered_source_files integer NOT NULL,
+    uncovered_packages jsonb DEFAULT '[]'::jsonb NOT NULL,
+    uncovered_files jsonb DEFAULT '[]'::jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: spec_generation_cancellations; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_generation_cancellations (
+    analysis_id uuid NOT NULL,
+    language character varying(10) NOT NULL,
+    requested_by uuid NOT NULL,
+    requested_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: spec_generation_checkpoint_features; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_generation_checkpoint_features (
+    job_id bigint NOT NULL,
+    domain_index integer NOT NULL,
+    feature_index integer NOT NULL,
+    behaviors jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: spec_generation_checkpoints; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_generation_checkpoints (
+    job_id bigint NOT NULL,
+    analysis_id uuid NOT NULL,
+    content_hash bytea NOT NULL,
+    phase1_output jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: spec_generation_claims; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_generation_claims (
+    claim_key bytea NOT NULL,
+    job_id bigint NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: spec_generation_progress; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_generation_progress (
+    analysis_id uuid NOT NULL,
+    language character varying(10) NOT NULL,
+    status character varying(20) NOT NULL,
+    total_features integer DEFAULT 0 NOT NULL,
+    completed_features integer DEFAULT 0 NOT NULL,
+    failed_features integer DEFAULT 0 NOT NULL,
+    cache_hit_rate double precision DEFAULT 0 NOT NULL,
+    eta_seconds bigint DEFAULT 0 NOT NULL,
+    started_at timestamp with time zone NOT NULL,
     updated_at timestamp with time zone DEFAULT now() NOT NULL
 );
 
 
+--
+-- Name: spec_search_entries; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.spec_search_entries (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    document_id uuid NOT NULL,
+    codebase_id uuid NOT NULL,
+    document_version integer NOT NULL,
+    language character varying(10) NOT NULL,
+    behavior_id uuid NOT NULL,
+    domain_name character varying(255) NOT NULL,
+    feature_name character varying(255) NOT NULL,
+    behavior_description text NOT NULL,
+    search_vector tsvector GENERATED ALWAYS AS (((setweight(to_tsvector('simple'::regconfig, (COALESCE(domain_name, ''::character varying))::text), 'B'::"char") || setweight(to_tsvector('simple'::regconfig, (COALESCE(feature_name, ''::character varying))::text), 'B'::"char")) || setweight(to_tsvector('simple'::regconfig, COALESCE(behavior_description, ''::text)), 'A'::"char"))) STORED,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
 --
 -- Name: subscription_plans; Type: TABLE; Schema: public; Owner: -
 --
@@ -502,6 +1201,119 @@ CREATE TABLE public.system_config (
 );
 
 
+--
+-- Name: tenant_ai_budgets; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.tenant_ai_budgets (
+    tenant_id uuid NOT NULL,
+    monthly_token_limit bigint,
+    monthly_behavior_limit bigint,
+    downgrade_model character varying(100),
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: tenant_ai_usage; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.tenant_ai_usage (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    tenant_id uuid NOT NULL,
+    document_id uuid,
+    tokens bigint NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    job_id bigint
+);
+
+
+--
+-- Name: tenant_document_keys; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.tenant_document_keys (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    tenant_id uuid NOT NULL,
+    wrapped_key text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    retired_at timestamp with time zone
+);
+
+
+--
+-- Name: tenant_members; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.tenant_members (
+    user_id uuid NOT NULL,
+    tenant_id uuid NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: tenant_repo_policies; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.tenant_repo_policies (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    tenant_id uuid NOT NULL,
+    effect character varying(10) NOT NULL,
+    pattern character varying(200) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT chk_tenant_repo_policies_effect CHECK (((effect)::text = ANY ((ARRAY['allow'::character varying, 'deny'::character varying])::text[])))
+);
+
+
+--
+-- Name: tenant_takeouts; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.tenant_takeouts (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    tenant_id uuid NOT NULL,
+    status character varying(20) DEFAULT 'pending'::character varying NOT NULL,
+    object_key text,
+    size_bytes bigint,
+    download_url text,
+    download_expires_at timestamp with time zone,
+    error_message text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    completed_at timestamp with time zone,
+    CONSTRAINT chk_tenant_takeouts_status CHECK (((status)::text = ANY ((ARRAY['pending'::character varying, 'running'::character varying, 'completed'::character varying, 'failed'::character varying])::text[])))
+);
+
+
+--
+-- Name: tenants; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.tenants (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    name character varying(100) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    document_encryption boolean DEFAULT false NOT NULL
+);
+
+
+--
+-- Name: test_case_results; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.test_case_results (
+    test_case_id uuid NOT NULL,
+    last_status character varying(20) NOT NULL,
+    last_duration_ms bigint DEFAULT 0 NOT NULL,
+    runs integer DEFAULT 0 NOT NULL,
+    passes integer DEFAULT 0 NOT NULL,
+    failures integer DEFAULT 0 NOT NULL,
+    last_upload_id uuid,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
 --
 -- Name: test_cases; Type: TABLE; Schema: public; Owner: -
 --
@@ -513,7 +1325,44 @@ CREATE TABLE public.test_cases (
     line_number integer,
     status public.test_status DEFAULT 'active'::public.test_status NOT NULL,
     tags jsonb DEFAULT '[]'::jsonb NOT NULL,
-    modifier character varying(50)
+    modifier character varying(50),
+    variant_count integer DEFAULT 1 NOT NULL
+);
+
+
+--
+-- Name: test_deltas; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.test_deltas (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    codebase_id uuid NOT NULL,
+    base_analysis_id uuid NOT NULL,
+    head_analysis_id uuid NOT NULL,
+    pull_request_number integer,
+    tests_added integer DEFAULT 0 NOT NULL,
+    tests_removed integer DEFAULT 0 NOT NULL,
+    tests_renamed integer DEFAULT 0 NOT NULL,
+    suites_added integer DEFAULT 0 NOT NULL,
+    suites_removed integer DEFAULT 0 NOT NULL,
+    details jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: test_file_coverage; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.test_file_coverage (
+    test_file_id uuid NOT NULL,
+    source_path character varying(1000) NOT NULL,
+    lines_valid integer NOT NULL,
+    lines_covered integer NOT NULL,
+    upload_id uuid,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT chk_test_file_coverage_lines CHECK (((lines_covered >= 0) AND (lines_covered <= lines_valid)))
 );
 
 
@@ -526,7 +1375,23 @@ CREATE TABLE public.test_files (
     analysis_id uuid NOT NULL,
     file_path character varying(1000) NOT NULL,
     framework character varying(50),
-    domain_hints jsonb
+    domain_hints jsonb,
+    project character varying(500),
+    content_hash bytea
+);
+
+
+--
+-- Name: test_result_uploads; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.test_result_uploads (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    analysis_id uuid NOT NULL,
+    format character varying(20) NOT NULL,
+    content bytea NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    processed_at timestamp with time zone
 );
 
 
@@ -546,1044 +1411,2590 @@ CREATE TABLE public.test_suites (
 
 
 --
--- Name: usage_events; Type: TABLE; Schema: public; Owner: -
+-- Name: unmatched_file_samples; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.unmatched_file_samples (
+    analysis_id uuid NOT NULL,
+    file_path character varying(1000) NOT NULL,
+    language character varying(20) NOT NULL,
+    snippet text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: usage_events; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.usage_events (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    event_type public.usage_event_type NOT NULL,
+    analysis_id uuid,
+    document_id uuid,
+    quota_amount integer NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT chk_usage_events_resource CHECK (((((analysis_id IS NOT NULL))::integer + ((document_id IS NOT NULL))::integer) = 1))
+);
+
+
+--
+-- Name: usage_warnings; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.usage_warnings (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    period_start date NOT NULL,
+    threshold smallint NOT NULL,
+    document_id uuid,
+    quota_used bigint NOT NULL,
+    quota_limit bigint NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: user_analysis_history; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.user_analysis_history (
+    user_id uuid NOT NULL,
+    analysis_id uuid NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    retention_days_at_creation integer,
+    CONSTRAINT chk_retention_days_positive CHECK (((retention_days_at_creation IS NULL) OR (retention_days_at_creation > 0)))
+);
+
+
+--
+-- Name: user_bookmarks; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.user_bookmarks (
+    user_id uuid NOT NULL,
+    codebase_id uuid NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    id uuid DEFAULT gen_random_uuid() NOT NULL
+);
+
+
+--
+-- Name: user_github_org_memberships; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.user_github_org_memberships (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    org_id uuid NOT NULL,
+    role character varying(50),
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: user_github_repositories; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.user_github_repositories (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    github_repo_id bigint NOT NULL,
+    name character varying(255) NOT NULL,
+    full_name character varying(500) NOT NULL,
+    html_url text NOT NULL,
+    description text,
+    default_branch character varying(100),
+    language character varying(50),
+    visibility character varying(20) DEFAULT 'public'::character varying NOT NULL,
+    is_private boolean DEFAULT false NOT NULL,
+    archived boolean DEFAULT false NOT NULL,
+    disabled boolean DEFAULT false NOT NULL,
+    fork boolean DEFAULT false NOT NULL,
+    stargazers_count integer DEFAULT 0 NOT NULL,
+    pushed_at timestamp with time zone,
+    source_type character varying(20) DEFAULT 'personal'::character varying NOT NULL,
+    org_id uuid,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: user_specview_history; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.user_specview_history (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    document_id uuid NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: user_subscriptions; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.user_subscriptions (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    plan_id uuid NOT NULL,
+    status public.subscription_status DEFAULT 'active'::public.subscription_status NOT NULL,
+    current_period_start timestamp with time zone NOT NULL,
+    current_period_end timestamp with time zone NOT NULL,
+    canceled_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT chk_canceled_at_status CHECK (((status = 'canceled'::public.subscription_status) = (canceled_at IS NOT NULL)))
+);
+
+
+--
+-- Name: users; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.users (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    email character varying(255),
+    username character varying(255) NOT NULL,
+    avatar_url text,
+    last_login_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    token_version integer DEFAULT 1 NOT NULL
+);
+
+
+--
+-- Name: webhook_deliveries; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.webhook_deliveries (
+    delivery_id character varying(100) NOT NULL,
+    event character varying(50) NOT NULL,
+    outcome character varying(30),
+    received_at timestamp with time zone DEFAULT now() NOT NULL,
+    expires_at timestamp with time zone NOT NULL
+);
+
+
+--
+-- Name: river_job id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.river_job ALTER COLUMN id SET DEFAULT nextval('public.river_job_id_seq'::regclass);
+
+
+--
+-- Name: ai_output_quarantine ai_output_quarantine_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.ai_output_quarantine
+    ADD CONSTRAINT ai_output_quarantine_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: ai_usage ai_usage_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.ai_usage
+    ADD CONSTRAINT ai_usage_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: analyses analyses_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.analyses
+    ADD CONSTRAINT analyses_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: analysis_events analysis_events_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.analysis_events
+    ADD CONSTRAINT analysis_events_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: analysis_languages analysis_languages_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.analysis_languages
+    ADD CONSTRAINT analysis_languages_pkey PRIMARY KEY (analysis_id, language);
+
+
+--
+-- Name: analysis_shared_helpers analysis_shared_helpers_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.analysis_shared_helpers
+    ADD CONSTRAINT analysis_shared_helpers_pkey PRIMARY KEY (analysis_id, helper_path);
+
+
+--
+-- Name: analysis_source_files analysis_source_files_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.analysis_source_files
+    ADD CONSTRAINT analysis_source_files_pkey PRIMARY KEY (analysis_id, file_path);
+
+
+--
+-- Name: analysis_staging_test_files analysis_staging_test_files_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.analysis_staging_test_files
+    ADD CONSTRAINT analysis_staging_test_files_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: analysis_staging_test_suites analysis_staging_test_suites_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.analysis_staging_test_suites
+    ADD CONSTRAINT analysis_staging_test_suites_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: atlas_schema_revisions atlas_schema_revisions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.atlas_schema_revisions
+    ADD CONSTRAINT atlas_schema_revisions_pkey PRIMARY KEY (version);
+
+
+--
+-- Name: behavior_caches behavior_caches_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.behavior_caches
+    ADD CONSTRAINT behavior_caches_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: behavior_feedback behavior_feedback_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.behavior_feedback
+    ADD CONSTRAINT behavior_feedback_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: coverage_uploads chk_coverage_uploads_format; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE public.coverage_uploads
+    ADD CONSTRAINT chk_coverage_uploads_format CHECK (((format)::text = ANY ((ARRAY['lcov'::character varying, 'cobertura'::character varying])::text[])));
+
+
+--
+-- Name: glossary_entries chk_glossary_entries_scope; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE public.glossary_entries
+    ADD CONSTRAINT chk_glossary_entries_scope CHECK (((user_id IS NULL) <> (codebase_id IS NULL)));
+
+
+--
+-- Name: tenant_ai_budgets chk_tenant_ai_budgets_limits; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE public.tenant_ai_budgets
+    ADD CONSTRAINT chk_tenant_ai_budgets_limits CHECK ((((monthly_token_limit IS NULL) OR (monthly_token_limit >= 0)) AND ((monthly_behavior_limit IS NULL) OR (monthly_behavior_limit >= 0))));
+
+
+--
+-- Name: test_case_results chk_test_case_results_status; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE public.test_case_results
+    ADD CONSTRAINT chk_test_case_results_status CHECK (((last_status)::text = ANY ((ARRAY['passed'::character varying, 'failed'::character varying, 'skipped'::character varying])::text[])));
+
+
+--
+-- Name: test_cases chk_test_cases_variant_count; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE public.test_cases
+    ADD CONSTRAINT chk_test_cases_variant_count CHECK ((variant_count >= 1));
+
+
+--
+-- Name: test_result_uploads chk_test_result_uploads_format; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE public.test_result_uploads
+    ADD CONSTRAINT chk_test_result_uploads_format CHECK (((format)::text = ANY ((ARRAY['junit'::character varying, 'go-test-json'::character varying])::text[])));
+
+
+--
+-- Name: classification_caches classification_caches_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.classification_caches
+    ADD CONSTRAINT classification_caches_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: codebase_sharing_opt_outs codebase_sharing_opt_outs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.codebase_sharing_opt_outs
+    ADD CONSTRAINT codebase_sharing_opt_outs_pkey PRIMARY KEY (codebase_id);
+
+
+--
+-- Name: codebase_taxonomies codebase_taxonomies_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.codebase_taxonomies
+    ADD CONSTRAINT codebase_taxonomies_pkey PRIMARY KEY (codebase_id);
+
+
+--
+-- Name: codebases codebases_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.codebases
+    ADD CONSTRAINT codebases_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: coverage_uploads coverage_uploads_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.coverage_uploads
+    ADD CONSTRAINT coverage_uploads_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: enqueue_idempotency_keys enqueue_idempotency_keys_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.enqueue_idempotency_keys
+    ADD CONSTRAINT enqueue_idempotency_keys_pkey PRIMARY KEY (idempotency_key);
+
+
+--
+-- Name: github_app_installations github_app_installations_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.github_app_installations
+    ADD CONSTRAINT github_app_installations_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: github_organizations github_organizations_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.github_organizations
+    ADD CONSTRAINT github_organizations_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: glossary_entries glossary_entries_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.glossary_entries
+    ADD CONSTRAINT glossary_entries_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: job_results job_results_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.job_results
+    ADD CONSTRAINT job_results_pkey PRIMARY KEY (job_id);
+
+
+--
+-- Name: notification_channels notification_channels_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_channels
+    ADD CONSTRAINT notification_channels_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: oauth_accounts oauth_accounts_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.oauth_accounts
+    ADD CONSTRAINT oauth_accounts_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: outbox_events outbox_events_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.outbox_events
+    ADD CONSTRAINT outbox_events_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: parse_errors parse_errors_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.parse_errors
+    ADD CONSTRAINT parse_errors_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: parser_compat_reports parser_compat_reports_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.parser_compat_reports
+    ADD CONSTRAINT parser_compat_reports_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: poison_jobs poison_jobs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.poison_jobs
+    ADD CONSTRAINT poison_jobs_pkey PRIMARY KEY (job_id);
+
+
+--
+-- Name: quota_reservations quota_reservations_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.quota_reservations
+    ADD CONSTRAINT quota_reservations_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: rate_limit_buckets rate_limit_buckets_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.rate_limit_buckets
+    ADD CONSTRAINT rate_limit_buckets_pkey PRIMARY KEY (bucket_key);
+
+
+--
+-- Name: refresh_tokens refresh_tokens_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.refresh_tokens
+    ADD CONSTRAINT refresh_tokens_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: regen_campaign_targets regen_campaign_targets_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.regen_campaign_targets
+    ADD CONSTRAINT regen_campaign_targets_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: regen_campaigns regen_campaigns_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.regen_campaigns
+    ADD CONSTRAINT regen_campaigns_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: requirement_coverage requirement_coverage_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.requirement_coverage
+    ADD CONSTRAINT requirement_coverage_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: requirement_sets requirement_sets_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.requirement_sets
+    ADD CONSTRAINT requirement_sets_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: requirements requirements_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.requirements
+    ADD CONSTRAINT requirements_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: river_client river_client_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.river_client
+    ADD CONSTRAINT river_client_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: river_client_queue river_client_queue_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.river_client_queue
+    ADD CONSTRAINT river_client_queue_pkey PRIMARY KEY (river_client_id, name);
+
+
+--
+-- Name: river_job river_job_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.river_job
+    ADD CONSTRAINT river_job_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: river_leader river_leader_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.river_leader
+    ADD CONSTRAINT river_leader_pkey PRIMARY KEY (name);
+
+
+--
+-- Name: river_queue river_queue_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.river_queue
+    ADD CONSTRAINT river_queue_pkey PRIMARY KEY (name);
+
+
+--
+-- Name: service_tokens service_tokens_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.service_tokens
+    ADD CONSTRAINT service_tokens_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: sla_violations sla_violations_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.sla_violations
+    ADD CONSTRAINT sla_violations_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: spec_behavior_coverage spec_behavior_coverage_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_behavior_coverage
+    ADD CONSTRAINT spec_behavior_coverage_pkey PRIMARY KEY (behavior_id);
+
+
+--
+-- Name: spec_behavior_merges spec_behavior_merges_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_behavior_merges
+    ADD CONSTRAINT spec_behavior_merges_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: spec_behavior_test_cases spec_behavior_test_cases_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_behavior_test_cases
+    ADD CONSTRAINT spec_behavior_test_cases_pkey PRIMARY KEY (behavior_id, test_case_id);
+
+
+--
+-- Name: spec_behaviors spec_behaviors_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_behaviors
+    ADD CONSTRAINT spec_behaviors_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: spec_document_decisions spec_document_decisions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_document_decisions
+    ADD CONSTRAINT spec_document_decisions_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: spec_document_exports spec_document_exports_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_document_exports
+    ADD CONSTRAINT spec_document_exports_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: spec_document_pins spec_document_pins_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_document_pins
+    ADD CONSTRAINT spec_document_pins_pkey PRIMARY KEY (document_id);
+
+
+--
+-- Name: spec_document_shares spec_document_shares_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_document_shares
+    ADD CONSTRAINT spec_document_shares_pkey PRIMARY KEY (document_id, user_id);
+
+
+--
+-- Name: spec_documents spec_documents_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_documents
+    ADD CONSTRAINT spec_documents_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: spec_domains spec_domains_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_domains
+    ADD CONSTRAINT spec_domains_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: spec_features spec_features_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_features
+    ADD CONSTRAINT spec_features_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: spec_gap_reports spec_gap_reports_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_gap_reports
+    ADD CONSTRAINT spec_gap_reports_pkey PRIMARY KEY (document_id);
+
+
+--
+-- Name: spec_generation_cancellations spec_generation_cancellations_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_generation_cancellations
+    ADD CONSTRAINT spec_generation_cancellations_pkey PRIMARY KEY (analysis_id, language, requested_by);
+
+
+--
+-- Name: spec_generation_checkpoint_features spec_generation_checkpoint_features_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_generation_checkpoint_features
+    ADD CONSTRAINT spec_generation_checkpoint_features_pkey PRIMARY KEY (job_id, domain_index, feature_index);
+
+
+--
+-- Name: spec_generation_checkpoints spec_generation_checkpoints_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_generation_checkpoints
+    ADD CONSTRAINT spec_generation_checkpoints_pkey PRIMARY KEY (job_id);
+
+
+--
+-- Name: spec_generation_claims spec_generation_claims_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_generation_claims
+    ADD CONSTRAINT spec_generation_claims_pkey PRIMARY KEY (claim_key);
+
+
+--
+-- Name: spec_generation_progress spec_generation_progress_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_generation_progress
+    ADD CONSTRAINT spec_generation_progress_pkey PRIMARY KEY (analysis_id, language);
+
+
+--
+-- Name: spec_search_entries spec_search_entries_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_search_entries
+    ADD CONSTRAINT spec_search_entries_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: subscription_plans subscription_plans_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.subscription_plans
+    ADD CONSTRAINT subscription_plans_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: system_config system_config_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.system_config
+    ADD CONSTRAINT system_config_pkey PRIMARY KEY (key);
+
+
+--
+-- Name: tenant_ai_budgets tenant_ai_budgets_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.tenant_ai_budgets
+    ADD CONSTRAINT tenant_ai_budgets_pkey PRIMARY KEY (tenant_id);
+
+
+--
+-- Name: tenant_ai_usage tenant_ai_usage_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.tenant_ai_usage
+    ADD CONSTRAINT tenant_ai_usage_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: tenant_document_keys tenant_document_keys_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.tenant_document_keys
+    ADD CONSTRAINT tenant_document_keys_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: tenant_members tenant_members_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.tenant_members
+    ADD CONSTRAINT tenant_members_pkey PRIMARY KEY (user_id);
+
+
+--
+-- Name: tenant_repo_policies tenant_repo_policies_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.tenant_repo_policies
+    ADD CONSTRAINT tenant_repo_policies_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: tenant_takeouts tenant_takeouts_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.tenant_takeouts
+    ADD CONSTRAINT tenant_takeouts_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: tenants tenants_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.tenants
+    ADD CONSTRAINT tenants_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: test_case_results test_case_results_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.test_case_results
+    ADD CONSTRAINT test_case_results_pkey PRIMARY KEY (test_case_id);
+
+
+--
+-- Name: test_cases test_cases_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.test_cases
+    ADD CONSTRAINT test_cases_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: test_deltas test_deltas_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.test_deltas
+    ADD CONSTRAINT test_deltas_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: test_file_coverage test_file_coverage_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.test_file_coverage
+    ADD CONSTRAINT test_file_coverage_pkey PRIMARY KEY (test_file_id, source_path);
+
+
+--
+-- Name: test_files test_files_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.test_files
+    ADD CONSTRAINT test_files_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: test_result_uploads test_result_uploads_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.test_result_uploads
+    ADD CONSTRAINT test_result_uploads_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: test_suites test_suites_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.test_suites
+    ADD CONSTRAINT test_suites_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: unmatched_file_samples unmatched_file_samples_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.unmatched_file_samples
+    ADD CONSTRAINT unmatched_file_samples_pkey PRIMARY KEY (analysis_id, file_path);
+
+
+--
+-- Name: behavior_caches uq_behavior_caches_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.behavior_caches
+    ADD CONSTRAINT uq_behavior_caches_key UNIQUE (cache_key_hash);
+
+
+--
+-- Name: classification_caches uq_classification_caches_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.classification_caches
+    ADD CONSTRAINT uq_classification_caches_key UNIQUE (content_hash, language, model_id);
+
+
+--
+-- Name: github_app_installations uq_github_app_installations_account; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.github_app_installations
+    ADD CONSTRAINT uq_github_app_installations_account UNIQUE (account_type, account_id);
+
+
+--
+-- Name: github_app_installations uq_github_app_installations_installation_id; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.github_app_installations
+    ADD CONSTRAINT uq_github_app_installations_installation_id UNIQUE (installation_id);
+
+
+--
+-- Name: github_organizations uq_github_organizations_github_org_id; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.github_organizations
+    ADD CONSTRAINT uq_github_organizations_github_org_id UNIQUE (github_org_id);
+
+
+--
+-- Name: oauth_accounts uq_oauth_provider_user; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.oauth_accounts
+    ADD CONSTRAINT uq_oauth_provider_user UNIQUE (provider, provider_user_id);
+
+
+--
+-- Name: parse_errors uq_parse_errors_analysis_file; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.parse_errors
+    ADD CONSTRAINT uq_parse_errors_analysis_file UNIQUE (analysis_id, file_path);
+
+
+--
+-- Name: quota_reservations uq_quota_reservations_job_id; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.quota_reservations
+    ADD CONSTRAINT uq_quota_reservations_job_id UNIQUE (job_id);
+
+
+--
+-- Name: refresh_tokens uq_refresh_tokens_hash; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.refresh_tokens
+    ADD CONSTRAINT uq_refresh_tokens_hash UNIQUE (token_hash);
+
+
+--
+-- Name: regen_campaign_targets uq_regen_campaign_targets_document; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.regen_campaign_targets
+    ADD CONSTRAINT uq_regen_campaign_targets_document UNIQUE (campaign_id, document_id);
+
+
+--
+-- Name: requirement_coverage uq_requirement_coverage_req_behavior; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.requirement_coverage
+    ADD CONSTRAINT uq_requirement_coverage_req_behavior UNIQUE (requirement_id, behavior_id);
+
+
+--
+-- Name: requirements uq_requirements_set_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.requirements
+    ADD CONSTRAINT uq_requirements_set_key UNIQUE (requirement_set_id, external_key);
+
+
+--
+-- Name: service_tokens uq_service_tokens_hash; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.service_tokens
+    ADD CONSTRAINT uq_service_tokens_hash UNIQUE (token_hash);
+
+
+--
+-- Name: spec_document_decisions uq_spec_document_decisions_generation_sequence; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_document_decisions
+    ADD CONSTRAINT uq_spec_document_decisions_generation_sequence UNIQUE (generation_id, sequence);
+
+
+--
+-- Name: spec_document_exports uq_spec_document_exports_document_format; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_document_exports
+    ADD CONSTRAINT uq_spec_document_exports_document_format UNIQUE (document_id, format);
+
+
+--
+-- Name: spec_search_entries uq_spec_search_entries_behavior; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.spec_search_entries
+    ADD CONSTRAINT uq_spec_search_entries_behavior UNIQUE (behavior_id);
+
+
+--
+-- Name: subscription_plans uq_subscription_plans_tier; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.subscription_plans
+    ADD CONSTRAINT uq_subscription_plans_tier UNIQUE (tier);
+
+
+--
+-- Name: tenant_repo_policies uq_tenant_repo_policies_rule; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.tenant_repo_policies
+    ADD CONSTRAINT uq_tenant_repo_policies_rule UNIQUE (tenant_id, effect, pattern);
+
+
+--
+-- Name: test_deltas uq_test_deltas_base_head; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.test_deltas
+    ADD CONSTRAINT uq_test_deltas_base_head UNIQUE (base_analysis_id, head_analysis_id);
+
+
+--
+-- Name: test_files uq_test_files_analysis_path; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.test_files
+    ADD CONSTRAINT uq_test_files_analysis_path UNIQUE (analysis_id, file_path);
+
+
+--
+-- Name: usage_warnings uq_usage_warnings_user_period_threshold; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.usage_warnings
+    ADD CONSTRAINT uq_usage_warnings_user_period_threshold UNIQUE (user_id, period_start, threshold);
+
+
+--
+-- Name: user_analysis_history uq_user_analysis_history_user_analysis; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_analysis_history
+    ADD CONSTRAINT uq_user_analysis_history_user_analysis UNIQUE (user_id, analysis_id);
+
+
+--
+-- Name: user_bookmarks uq_user_bookmarks_user_codebase; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_bookmarks
+    ADD CONSTRAINT uq_user_bookmarks_user_codebase UNIQUE (user_id, codebase_id);
+
+
+--
+-- Name: user_github_org_memberships uq_user_github_org_memberships_user_org; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_github_org_memberships
+    ADD CONSTRAINT uq_user_github_org_memberships_user_org UNIQUE (user_id, org_id);
+
+
+--
+-- Name: user_github_repositories uq_user_github_repositories_user_repo; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_github_repositories
+    ADD CONSTRAINT uq_user_github_repositories_user_repo UNIQUE (user_id, github_repo_id);
+
+
+--
+-- Name: user_specview_history uq_user_specview_history_user_document; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_specview_history
+    ADD CONSTRAINT uq_user_specview_history_user_document UNIQUE (user_id, document_id);
+
+
+--
+-- Name: usage_events usage_events_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.usage_events
+    ADD CONSTRAINT usage_events_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: usage_warnings usage_warnings_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.usage_warnings
+    ADD CONSTRAINT usage_warnings_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: user_analysis_history user_analysis_history_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_analysis_history
+    ADD CONSTRAINT user_analysis_history_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: user_bookmarks user_bookmarks_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_bookmarks
+    ADD CONSTRAINT user_bookmarks_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: user_github_org_memberships user_github_org_memberships_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_github_org_memberships
+    ADD CONSTRAINT user_github_org_memberships_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: user_github_repositories user_github_repositories_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_github_repositories
+    ADD CONSTRAINT user_github_repositories_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: user_specview_history user_specview_history_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_specview_history
+    ADD CONSTRAINT user_specview_history_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: user_subscriptions user_subscriptions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_subscriptions
+    ADD CONSTRAINT user_subscriptions_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: users users_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: webhook_deliveries webhook_deliveries_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_pkey PRIMARY KEY (delivery_id);
+
+
+--
+-- Name: idx_ai_output_quarantine_schema_created; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_ai_output_quarantine_schema_created ON public.ai_output_quarantine USING btree (schema_version, created_at);
+
+
+--
+-- Name: idx_ai_usage_created_at; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_ai_usage_created_at ON public.ai_usage USING btree (created_at);
+
+
+--
+-- Name: idx_ai_usage_job_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_ai_usage_job_id ON public.ai_usage USING btree (job_id) WHERE (job_id IS NOT NULL);
+
+
+--
+-- Name: idx_ai_usage_user_created; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_ai_usage_user_created ON public.ai_usage USING btree (user_id, created_at) WHERE (user_id IS NOT NULL);
+
+
+--
+-- Name: idx_analyses_codebase_status; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_analyses_codebase_status ON public.analyses USING btree (codebase_id, status);
+
+
+--
+-- Name: idx_analyses_completed_commit; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_analyses_completed_commit ON public.analyses USING btree (commit_sha, parser_version) WHERE (status = 'completed'::public.analysis_status);
+
+
+--
+-- Name: idx_analyses_created; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_analyses_created ON public.analyses USING btree (codebase_id, created_at);
+
+
+--
+-- Name: idx_analyses_failure_class; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_analyses_failure_class ON public.analyses USING btree (failure_class, completed_at) WHERE ((failure_class IS NOT NULL));
+
+
+--
+-- Name: idx_analysis_events_created_at; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_analysis_events_created_at ON public.analysis_events USING btree (created_at);
+
+
+--
+-- Name: idx_analysis_events_job_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_analysis_events_job_id ON public.analysis_events USING btree (job_id, created_at) WHERE (job_id IS NOT NULL);
+
+
+--
+-- Name: idx_analysis_events_repo_commit; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_analysis_events_repo_commit ON public.analysis_events USING btree (owner, repo, commit_sha, created_at);
+
+
+--
+-- Name: idx_analysis_staging_test_cases_analysis; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_analysis_staging_test_cases_analysis ON public.analysis_staging_test_cases USING btree (analysis_id);
+
+
+--
+-- Name: idx_analysis_staging_test_files_analysis; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_analysis_staging_test_files_analysis ON public.analysis_staging_test_files USING btree (analysis_id);
+
+
+--
+-- Name: idx_analysis_staging_test_suites_analysis; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_analysis_staging_test_suites_analysis ON public.analysis_staging_test_suites USING btree (analysis_id);
+
+
+--
+-- Name: idx_behavior_caches_codebase_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_behavior_caches_codebase_id ON public.behavior_caches USING btree (codebase_id) WHERE (codebase_id IS NOT NULL);
+
+
+--
+-- Name: idx_behavior_caches_created_at; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_behavior_caches_created_at ON public.behavior_caches USING btree (created_at);
+
+
+--
+-- Name: idx_behavior_feedback_behavior; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_behavior_feedback_behavior ON public.behavior_feedback USING btree (behavior_id);
+
+
+--
+-- Name: idx_classification_caches_codebase_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_classification_caches_codebase_id ON public.classification_caches USING btree (codebase_id) WHERE (codebase_id IS NOT NULL);
+
+
+--
+-- Name: idx_classification_caches_created_at; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_classification_caches_created_at ON public.classification_caches USING btree (created_at);
+
+
+--
+-- Name: idx_codebases_external_repo_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX idx_codebases_external_repo_id ON public.codebases USING btree (host, external_repo_id);
+
+
+--
+-- Name: idx_codebases_identity; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX idx_codebases_identity ON public.codebases USING btree (host, owner, name) WHERE (is_stale = false);
+
+
+--
+-- Name: idx_codebases_last_viewed; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_codebases_last_viewed ON public.codebases USING btree (last_viewed_at) WHERE (last_viewed_at IS NOT NULL);
+
+
+--
+-- Name: idx_codebases_owner_name; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_codebases_owner_name ON public.codebases USING btree (owner, name);
+
+
+--
+-- Name: idx_codebases_public; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_codebases_public ON public.codebases USING btree (is_private) WHERE (is_private = false);
+
+
+--
+-- Name: idx_coverage_uploads_analysis; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_coverage_uploads_analysis ON public.coverage_uploads USING btree (analysis_id, created_at);
+
+
+--
+-- Name: idx_enqueue_idempotency_keys_expires_at; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_enqueue_idempotency_keys_expires_at ON public.enqueue_idempotency_keys USING btree (expires_at);
+
+
+--
+-- Name: idx_github_app_installations_installer; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_github_app_installations_installer ON public.github_app_installations USING btree (installer_user_id) WHERE (installer_user_id IS NOT NULL);
+
+
+--
+-- Name: idx_github_organizations_login; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_github_organizations_login ON public.github_organizations USING btree (login);
+
+
+--
+-- Name: idx_job_results_analysis_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_job_results_analysis_id ON public.job_results USING btree (analysis_id) WHERE (analysis_id IS NOT NULL);
+
+
+--
+-- Name: idx_job_results_document_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_job_results_document_id ON public.job_results USING btree (document_id) WHERE (document_id IS NOT NULL);
+
+
+--
+-- Name: idx_job_results_enqueued_at; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_job_results_enqueued_at ON public.job_results USING btree (enqueued_at) WHERE (enqueued_at IS NOT NULL);
+
+
+--
+-- Name: idx_job_results_kind_completed; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_job_results_kind_completed ON public.job_results USING btree (kind, completed_at);
+
+
+--
+-- Name: idx_notification_channels_codebase; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_notification_channels_codebase ON public.notification_channels USING btree (codebase_id) WHERE (codebase_id IS NOT NULL);
+
+
+--
+-- Name: idx_notification_channels_user; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_notification_channels_user ON public.notification_channels USING btree (user_id) WHERE (user_id IS NOT NULL);
+
+
+--
+-- Name: idx_oauth_accounts_user_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_oauth_accounts_user_id ON public.oauth_accounts USING btree (user_id);
+
+
+--
+-- Name: idx_oauth_accounts_user_provider; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_oauth_accounts_user_provider ON public.oauth_accounts USING btree (user_id, provider);
+
+
+--
+-- Name: idx_outbox_events_pending; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_outbox_events_pending ON public.outbox_events USING btree (next_attempt_at) WHERE (published_at IS NULL);
+
+
+--
+-- Name: idx_outbox_events_published; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_outbox_events_published ON public.outbox_events USING btree (published_at) WHERE (published_at IS NOT NULL);
+
+
+--
+-- Name: idx_parser_compat_reports_version; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_parser_compat_reports_version ON public.parser_compat_reports USING btree (candidate_version, created_at DESC);
+
+
+--
+-- Name: idx_poison_jobs_kind_created; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_poison_jobs_kind_created ON public.poison_jobs USING btree (kind, created_at);
+
+
+--
+-- Name: idx_quota_reservations_expires; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_quota_reservations_expires ON public.quota_reservations USING btree (expires_at);
+
+
+--
+-- Name: idx_quota_reservations_user_event; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_quota_reservations_user_event ON public.quota_reservations USING btree (user_id, event_type);
+
+
+--
+-- Name: idx_refresh_tokens_expires; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_refresh_tokens_expires ON public.refresh_tokens USING btree (expires_at) WHERE (revoked_at IS NULL);
+
+
+--
+-- Name: idx_refresh_tokens_family_active; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_refresh_tokens_family_active ON public.refresh_tokens USING btree (family_id, created_at) WHERE (revoked_at IS NULL);
+
+
+--
+-- Name: idx_refresh_tokens_user; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_refresh_tokens_user ON public.refresh_tokens USING btree (user_id);
+
+
+--
+-- Name: idx_regen_campaign_targets_status; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_regen_campaign_targets_status ON public.regen_campaign_targets USING btree (campaign_id, status);
+
+
+--
+-- Name: idx_requirement_coverage_document; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_requirement_coverage_document ON public.requirement_coverage USING btree (document_id);
+
+
+--
+-- Name: idx_requirement_sets_codebase; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_requirement_sets_codebase ON public.requirement_sets USING btree (codebase_id);
+
+
+--
+-- Name: idx_sla_violations_kind_created; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_sla_violations_kind_created ON public.sla_violations USING btree (kind, created_at);
+
+
+--
+-- Name: idx_spec_behavior_merges_behavior; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_behavior_merges_behavior ON public.spec_behavior_merges USING btree (behavior_id, sort_order);
+
+
+--
+-- Name: idx_spec_behavior_test_cases_test_case; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_behavior_test_cases_test_case ON public.spec_behavior_test_cases USING btree (test_case_id);
+
+
+--
+-- Name: idx_spec_behaviors_feature_sort; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_behaviors_feature_sort ON public.spec_behaviors USING btree (feature_id, sort_order);
+
+
+--
+-- Name: idx_spec_behaviors_source; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_behaviors_source ON public.spec_behaviors USING btree (source_test_case_id) WHERE (source_test_case_id IS NOT NULL);
+
+
+--
+-- Name: idx_spec_document_decisions_analysis; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_document_decisions_analysis ON public.spec_document_decisions USING btree (analysis_id);
+
+
+--
+-- Name: idx_spec_document_decisions_document_kind; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_document_decisions_document_kind ON public.spec_document_decisions USING btree (document_id, kind);
+
+
+--
+-- Name: idx_spec_document_shares_user; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_document_shares_user ON public.spec_document_shares USING btree (user_id);
+
+
+--
+-- Name: idx_spec_documents_analysis; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_documents_analysis ON public.spec_documents USING btree (analysis_id);
+
+
+--
+-- Name: idx_spec_documents_content_hash_lang_model; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_documents_content_hash_lang_model ON public.spec_documents USING btree (content_hash, language, model_id);
+
+
+--
+-- Name: idx_spec_documents_deleted_at; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_documents_deleted_at ON public.spec_documents USING btree (deleted_at) WHERE (deleted_at IS NOT NULL);
+
+
+--
+-- Name: idx_spec_documents_encryption_key; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_documents_encryption_key ON public.spec_documents USING btree (encryption_key_id) WHERE ((encryption_key_id IS NOT NULL));
+
+
+--
+-- Name: idx_spec_documents_quality_flagged; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_documents_quality_flagged ON public.spec_documents USING btree (created_at) WHERE quality_flagged;
+
+
+--
+-- Name: idx_spec_documents_retention_cleanup; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_documents_retention_cleanup ON public.spec_documents USING btree (created_at) WHERE (retention_days_at_creation IS NOT NULL);
+
+
+--
+-- Name: idx_spec_documents_user_created; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_documents_user_created ON public.spec_documents USING btree (user_id, created_at);
+
+
+--
+-- Name: idx_spec_domains_document_sort; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_domains_document_sort ON public.spec_domains USING btree (document_id, sort_order);
+
+
+--
+-- Name: idx_spec_features_domain_sort; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_features_domain_sort ON public.spec_features USING btree (domain_id, sort_order);
+
+
+--
+-- Name: idx_spec_generation_claims_job_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idx_spec_generation_claims_job_id ON public.spec_generation_claims USING btree (job_id);
+
+
+--
+-- Name: idx_spec_search_entries_codebase_version; Type: INDEX; Schema: public; Owner: -
 --
 
-CREATE TABLE public.usage_events (
-    id uuid DEFAULT gen_random_uuid() NOT NULL,
-    user_id uuid NOT NULL,
-    event_type public.usage_event_type NOT NULL,
-    analysis_id uuid,
-    document_id uuid,
-    quota_amount integer NOT NULL,
-    created_at timestamp with time zone DEFAULT now() NOT NULL,
-    CONSTRAINT chk_usage_events_resource CHECK (((((analysis_id IS NOT NULL))::integer + ((document_id IS NOT NULL))::integer) = 1))
-);
+CREATE INDEX idx_spec_search_entries_codebase_version ON public.spec_search_entries USING btree (codebase_id, document_version);
 
 
 --
--- Name: user_analysis_history; Type: TABLE; Schema: public; Owner: -
+-- Name: idx_spec_search_entries_document; Type: INDEX; Schema: public; Owner: -
 --
 
-CREATE TABLE public.user_analysis_history (
-    user_id uuid NOT NULL,
-    analysis_id uuid NOT NULL,
-    created_at timestamp with time zone DEFAULT now() NOT NULL,
-    updated_at timestamp with time zone DEFAULT now() NOT NULL,
-    id uuid DEFAULT gen_random_uuid() NOT NULL,
-    retention_days_at_creation integer,
-    CONSTRAINT chk_retention_days_positive CHECK (((retention_days_at_creation IS NULL) OR (retention_days_at_creation > 0)))
-);
+CREATE INDEX idx_spec_search_entries_document ON public.spec_search_entries USING btree (document_id);
 
 
 --
--- Name: user_bookmarks; Type: TABLE; Schema: public; Owner: -
+-- Name: idx_spec_search_entries_vector; Type: INDEX; Schema: public; Owner: -
 --
 
-CREATE TABLE public.user_bookmarks (
-    user_id uuid NOT NULL,
-    codebase_id uuid NOT NULL,
-    created_at timestamp with time zone DEFAULT now() NOT NULL,
-    id uuid DEFAULT gen_random_uuid() NOT NULL
-);
+CREATE INDEX idx_spec_search_entries_vector ON public.spec_search_entries USING gin (search_vector);
 
 
 --
--- Name: user_github_org_memberships; Type: TABLE; Schema: public; Owner: -
+-- Name: idx_tenant_ai_usage_job_id; Type: INDEX; Schema: public; Owner: -
 --
 
-CREATE TABLE public.user_github_org_memberships (
-    id uuid DEFAULT gen_random_uuid() NOT NULL,
-    user_id uuid NOT NULL,
-    org_id uuid NOT NULL,
-    role character varying(50),
-    created_at timestamp with time zone DEFAULT now() NOT NULL,
-    updated_at timestamp with time zone DEFAULT now() NOT NULL
-);
+CREATE INDEX idx_tenant_ai_usage_job_id ON public.tenant_ai_usage USING btree (job_id) WHERE (job_id IS NOT NULL);
 
 
 --
--- Name: user_github_repositories; Type: TABLE; Schema: public; Owner: -
+-- Name: idx_tenant_ai_usage_tenant_created; Type: INDEX; Schema: public; Owner: -
 --
 
-CREATE TABLE public.user_github_repositories (
-    id uuid DEFAULT gen_random_uuid() NOT NULL,
-    user_id uuid NOT NULL,
-    github_repo_id bigint NOT NULL,
-    name character varying(255) NOT NULL,
-    full_name character varying(500) NOT NULL,
-    html_url text NOT NULL,
-    description text,
-    default_branch character varying(100),
-    language character varying(50),
-    visibility character varying(20) DEFAULT 'public'::character varying NOT NULL,
-    is_private boolean DEFAULT false NOT NULL,
-    archived boolean DEFAULT false NOT NULL,
-    disabled boolean DEFAULT false NOT NULL,
-    fork boolean DEFAULT false NOT NULL,
-    stargazers_count integer DEFAULT 0 NOT NULL,
-    pushed_at timestamp with time zone,
-    source_type character varying(20) DEFAULT 'personal'::character varying NOT NULL,
-    org_id uuid,
-    created_at timestamp with time zone DEFAULT now() NOT NULL,
-    updated_at timestamp with time zone DEFAULT now() NOT NULL
-);
+CREATE INDEX idx_tenant_ai_usage_tenant_created ON public.tenant_ai_usage USING btree (tenant_id, created_at);
 
 
 --
--- Name: user_specview_history; Type: TABLE; Schema: public; Owner: -
+-- Name: idx_tenant_members_tenant; Type: INDEX; Schema: public; Owner: -
 --
 
-CREATE TABLE public.user_specview_history (
-    id uuid DEFAULT gen_random_uuid() NOT NULL,
-    user_id uuid NOT NULL,
-    document_id uuid NOT NULL,
-    created_at timestamp with time zone DEFAULT now() NOT NULL,
-    updated_at timestamp with time zone DEFAULT now() NOT NULL
-);
+CREATE INDEX idx_tenant_members_tenant ON public.tenant_members USING btree (tenant_id);
 
 
 --
--- Name: user_subscriptions; Type: TABLE; Schema: public; Owner: -
+-- Name: idx_tenant_takeouts_tenant_created; Type: INDEX; Schema: public; Owner: -
 --
 
-CREATE TABLE public.user_subscriptions (
-    id uuid DEFAULT gen_random_uuid() NOT NULL,
-    user_id uuid NOT NULL,
-    plan_id uuid NOT NULL,
-    status public.subscription_status DEFAULT 'active'::public.subscription_status NOT NULL,
-    current_period_start timestamp with time zone NOT NULL,
-    current_period_end timestamp with time zone NOT NULL,
-    canceled_at timestamp with time zone,
-    created_at timestamp with time zone DEFAULT now() NOT NULL,
-    updated_at timestamp with time zone DEFAULT now() NOT NULL,
-    CONSTRAINT chk_canceled_at_status CHECK (((status = 'canceled'::public.subscription_status) = (canceled_at IS NOT NULL)))
-);
+CREATE INDEX idx_tenant_takeouts_tenant_created ON public.tenant_takeouts USING btree (tenant_id, created_at DESC);
 
 
 --
--- Name: users; Type: TABLE; Schema: public; Owner: -
+-- Name: idx_test_cases_status; Type: INDEX; Schema: public; Owner: -
 --
 
-CREATE TABLE public.users (
-    id uuid DEFAULT gen_random_uuid() NOT NULL,
-    email character varying(255),
-    username character varying(255) NOT NULL,
-    avatar_url text,
-    last_login_at timestamp with time zone,
-    created_at timestamp with time zone DEFAULT now() NOT NULL,
-    updated_at timestamp with time zone DEFAULT now() NOT NULL,
-    token_version integer DEFAULT 1 NOT NULL
-);
+CREATE INDEX idx_test_cases_status ON public.test_cases USING btree (suite_id, status);
 
 
 --
--- Name: river_job id; Type: DEFAULT; Schema: public; Owner: -
+-- Name: idx_test_cases_suite; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.river_job ALTER COLUMN id SET DEFAULT nextval('public.river_job_id_seq'::regclass);
+CREATE INDEX idx_test_cases_suite ON public.test_cases USING btree (suite_id);
 
 
 --
--- Name: analyses analyses_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_test_deltas_codebase_pr; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.analyses
-    ADD CONSTRAINT analyses_pkey PRIMARY KEY (id);
+CREATE INDEX idx_test_deltas_codebase_pr ON public.test_deltas USING btree (codebase_id, pull_request_number);
 
 
 --
--- Name: atlas_schema_revisions atlas_schema_revisions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_test_files_analysis; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.atlas_schema_revisions
-    ADD CONSTRAINT atlas_schema_revisions_pkey PRIMARY KEY (version);
+CREATE INDEX idx_test_files_analysis ON public.test_files USING btree (analysis_id);
 
 
 --
--- Name: behavior_caches behavior_caches_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_test_result_uploads_analysis; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.behavior_caches
-    ADD CONSTRAINT behavior_caches_pkey PRIMARY KEY (id);
+CREATE INDEX idx_test_result_uploads_analysis ON public.test_result_uploads USING btree (analysis_id, created_at);
 
 
 --
--- Name: classification_caches classification_caches_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_test_suites_file; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.classification_caches
-    ADD CONSTRAINT classification_caches_pkey PRIMARY KEY (id);
+CREATE INDEX idx_test_suites_file ON public.test_suites USING btree (file_id);
 
 
 --
--- Name: codebases codebases_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_test_suites_parent; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.codebases
-    ADD CONSTRAINT codebases_pkey PRIMARY KEY (id);
+CREATE INDEX idx_test_suites_parent ON public.test_suites USING btree (parent_id) WHERE (parent_id IS NOT NULL);
 
 
 --
--- Name: github_app_installations github_app_installations_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_usage_events_analysis; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.github_app_installations
-    ADD CONSTRAINT github_app_installations_pkey PRIMARY KEY (id);
+CREATE INDEX idx_usage_events_analysis ON public.usage_events USING btree (analysis_id) WHERE (analysis_id IS NOT NULL);
 
 
 --
--- Name: github_organizations github_organizations_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_usage_events_document; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.github_organizations
-    ADD CONSTRAINT github_organizations_pkey PRIMARY KEY (id);
+CREATE INDEX idx_usage_events_document ON public.usage_events USING btree (document_id) WHERE (document_id IS NOT NULL);
 
 
 --
--- Name: oauth_accounts oauth_accounts_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_usage_events_quota_lookup; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.oauth_accounts
-    ADD CONSTRAINT oauth_accounts_pkey PRIMARY KEY (id);
+CREATE INDEX idx_usage_events_quota_lookup ON public.usage_events USING btree (user_id, event_type, created_at);
 
 
 --
--- Name: quota_reservations quota_reservations_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_analysis_history_analysis; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.quota_reservations
-    ADD CONSTRAINT quota_reservations_pkey PRIMARY KEY (id);
+CREATE INDEX idx_user_analysis_history_analysis ON public.user_analysis_history USING btree (analysis_id);
 
 
 --
--- Name: refresh_tokens refresh_tokens_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_analysis_history_cursor; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.refresh_tokens
-    ADD CONSTRAINT refresh_tokens_pkey PRIMARY KEY (id);
+CREATE INDEX idx_user_analysis_history_cursor ON public.user_analysis_history USING btree (user_id, updated_at, id);
 
 
 --
--- Name: river_client river_client_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_analysis_history_retention_cleanup; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.river_client
-    ADD CONSTRAINT river_client_pkey PRIMARY KEY (id);
+CREATE INDEX idx_user_analysis_history_retention_cleanup ON public.user_analysis_history USING btree (created_at) WHERE (retention_days_at_creation IS NOT NULL);
 
 
 --
--- Name: river_client_queue river_client_queue_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_bookmarks_user; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.river_client_queue
-    ADD CONSTRAINT river_client_queue_pkey PRIMARY KEY (river_client_id, name);
+CREATE INDEX idx_user_bookmarks_user ON public.user_bookmarks USING btree (user_id, created_at);
 
 
 --
--- Name: river_job river_job_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_github_org_memberships_org; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.river_job
-    ADD CONSTRAINT river_job_pkey PRIMARY KEY (id);
+CREATE INDEX idx_user_github_org_memberships_org ON public.user_github_org_memberships USING btree (org_id);
 
 
 --
--- Name: river_leader river_leader_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_github_org_memberships_user; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.river_leader
-    ADD CONSTRAINT river_leader_pkey PRIMARY KEY (name);
+CREATE INDEX idx_user_github_org_memberships_user ON public.user_github_org_memberships USING btree (user_id);
 
 
 --
--- Name: river_queue river_queue_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_github_repositories_language; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.river_queue
-    ADD CONSTRAINT river_queue_pkey PRIMARY KEY (name);
+CREATE INDEX idx_user_github_repositories_language ON public.user_github_repositories USING btree (user_id, language) WHERE (language IS NOT NULL);
 
 
 --
--- Name: spec_behaviors spec_behaviors_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_github_repositories_org; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.spec_behaviors
-    ADD CONSTRAINT spec_behaviors_pkey PRIMARY KEY (id);
+CREATE INDEX idx_user_github_repositories_org ON public.user_github_repositories USING btree (user_id, org_id) WHERE (org_id IS NOT NULL);
 
 
 --
--- Name: spec_documents spec_documents_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_github_repositories_source; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.spec_documents
-    ADD CONSTRAINT spec_documents_pkey PRIMARY KEY (id);
+CREATE INDEX idx_user_github_repositories_source ON public.user_github_repositories USING btree (user_id, source_type);
 
 
 --
--- Name: spec_domains spec_domains_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_github_repositories_user; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.spec_domains
-    ADD CONSTRAINT spec_domains_pkey PRIMARY KEY (id);
+CREATE INDEX idx_user_github_repositories_user ON public.user_github_repositories USING btree (user_id, updated_at);
 
 
 --
--- Name: spec_features spec_features_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_specview_history_cursor; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.spec_features
-    ADD CONSTRAINT spec_features_pkey PRIMARY KEY (id);
+CREATE INDEX idx_user_specview_history_cursor ON public.user_specview_history USING btree (user_id, updated_at, id);
 
 
 --
--- Name: subscription_plans subscription_plans_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_specview_history_document; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.subscription_plans
-    ADD CONSTRAINT subscription_plans_pkey PRIMARY KEY (id);
+CREATE INDEX idx_user_specview_history_document ON public.user_specview_history USING btree (document_id);
 
 
 --
--- Name: system_config system_config_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_subscriptions_active; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.system_config
-    ADD CONSTRAINT system_config_pkey PRIMARY KEY (key);
+CREATE UNIQUE INDEX idx_user_subscriptions_active ON public.user_subscriptions USING btree (user_id) WHERE (status = 'active'::public.subscription_status);
 
 
 --
--- Name: test_cases test_cases_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_subscriptions_period_end; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.test_cases
-    ADD CONSTRAINT test_cases_pkey PRIMARY KEY (id);
+CREATE INDEX idx_user_subscriptions_period_end ON public.user_subscriptions USING btree (current_period_end) WHERE (status = 'active'::public.subscription_status);
 
 
 --
--- Name: test_files test_files_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_user_subscriptions_plan; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.test_files
-    ADD CONSTRAINT test_files_pkey PRIMARY KEY (id);
+CREATE INDEX idx_user_subscriptions_plan ON public.user_subscriptions USING btree (plan_id);
 
 
 --
--- Name: test_suites test_suites_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_users_email; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.test_suites
-    ADD CONSTRAINT test_suites_pkey PRIMARY KEY (id);
+CREATE UNIQUE INDEX idx_users_email ON public.users USING btree (email) WHERE (email IS NOT NULL);
 
 
 --
--- Name: behavior_caches uq_behavior_caches_key; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_users_username; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.behavior_caches
-    ADD CONSTRAINT uq_behavior_caches_key UNIQUE (cache_key_hash);
+CREATE INDEX idx_users_username ON public.users USING btree (username);
 
 
 --
--- Name: classification_caches uq_classification_caches_key; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: idx_webhook_deliveries_expires_at; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.classification_caches
-    ADD CONSTRAINT uq_classification_caches_key UNIQUE (content_hash, language, model_id);
+CREATE INDEX idx_webhook_deliveries_expires_at ON public.webhook_deliveries USING btree (expires_at);
 
 
 --
--- Name: github_app_installations uq_github_app_installations_account; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: uq_glossary_entries_codebase_term; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.github_app_installations
-    ADD CONSTRAINT uq_github_app_installations_account UNIQUE (account_type, account_id);
+CREATE UNIQUE INDEX uq_glossary_entries_codebase_term ON public.glossary_entries USING btree (codebase_id, language, lower((term)::text)) WHERE ((codebase_id IS NOT NULL));
 
 
 --
--- Name: github_app_installations uq_github_app_installations_installation_id; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: uq_glossary_entries_user_term; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.github_app_installations
-    ADD CONSTRAINT uq_github_app_installations_installation_id UNIQUE (installation_id);
+CREATE UNIQUE INDEX uq_glossary_entries_user_term ON public.glossary_entries USING btree (user_id, language, lower((term)::text)) WHERE ((user_id IS NOT NULL));
 
 
 --
--- Name: github_organizations uq_github_organizations_github_org_id; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: uq_spec_documents_parent_team; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.github_organizations
-    ADD CONSTRAINT uq_github_organizations_github_org_id UNIQUE (github_org_id);
+CREATE UNIQUE INDEX uq_spec_documents_parent_team ON public.spec_documents USING btree (parent_document_id, team) WHERE ((parent_document_id IS NOT NULL));
 
 
 --
--- Name: oauth_accounts uq_oauth_provider_user; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: uq_spec_documents_user_analysis_lang_version; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.oauth_accounts
-    ADD CONSTRAINT uq_oauth_provider_user UNIQUE (provider, provider_user_id);
+CREATE UNIQUE INDEX uq_spec_documents_user_analysis_lang_version ON public.spec_documents USING btree (user_id, analysis_id, language, version) WHERE ((parent_document_id IS NULL));
 
 
 --
--- Name: quota_reservations uq_quota_reservations_job_id; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: uq_spec_documents_user_hash_lang_model_version; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.quota_reservations
-    ADD CONSTRAINT uq_quota_reservations_job_id UNIQUE (job_id);
+CREATE UNIQUE INDEX uq_spec_documents_user_hash_lang_model_version ON public.spec_documents USING btree (user_id, content_hash, language, model_id, version) WHERE ((parent_document_id IS NULL));
 
 
 --
--- Name: refresh_tokens uq_refresh_tokens_hash; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: uq_tenant_document_keys_active; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.refresh_tokens
-    ADD CONSTRAINT uq_refresh_tokens_hash UNIQUE (token_hash);
+CREATE UNIQUE INDEX uq_tenant_document_keys_active ON public.tenant_document_keys USING btree (tenant_id) WHERE ((retired_at IS NULL));
 
 
 --
--- Name: spec_documents uq_spec_documents_user_analysis_lang_version; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: river_job_args_index; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.spec_documents
-    ADD CONSTRAINT uq_spec_documents_user_analysis_lang_version UNIQUE (user_id, analysis_id, language, version);
+CREATE INDEX river_job_args_index ON public.river_job USING gin (args);
 
 
 --
--- Name: spec_documents uq_spec_documents_user_hash_lang_model_version; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: river_job_kind; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.spec_documents
-    ADD CONSTRAINT uq_spec_documents_user_hash_lang_model_version UNIQUE (user_id, content_hash, language, model_id, version);
+CREATE INDEX river_job_kind ON public.river_job USING btree (kind);
 
 
 --
--- Name: subscription_plans uq_subscription_plans_tier; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: river_job_metadata_index; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.subscription_plans
-    ADD CONSTRAINT uq_subscription_plans_tier UNIQUE (tier);
+CREATE INDEX river_job_metadata_index ON public.river_job USING gin (metadata);
 
 
 --
--- Name: test_files uq_test_files_analysis_path; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: river_job_prioritized_fetching_index; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.test_files
-    ADD CONSTRAINT uq_test_files_analysis_path UNIQUE (analysis_id, file_path);
+CREATE INDEX river_job_prioritized_fetching_index ON public.river_job USING btree (state, queue, priority, scheduled_at, id);
 
 
 --
--- Name: user_analysis_history uq_user_analysis_history_user_analysis; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: river_job_state_and_finalized_at_index; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_analysis_history
-    ADD CONSTRAINT uq_user_analysis_history_user_analysis UNIQUE (user_id, analysis_id);
+CREATE INDEX river_job_state_and_finalized_at_index ON public.river_job USING btree (state, finalized_at) WHERE (finalized_at IS NOT NULL);
 
 
 --
--- Name: user_bookmarks uq_user_bookmarks_user_codebase; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: river_job_unique_idx; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_bookmarks
-    ADD CONSTRAINT uq_user_bookmarks_user_codebase UNIQUE (user_id, codebase_id);
+CREATE UNIQUE INDEX river_job_unique_idx ON public.river_job USING btree (unique_key) WHERE ((unique_key IS NOT NULL) AND (unique_states IS NOT NULL) AND public.river_job_state_in_bitmask(unique_states, state));
 
 
 --
--- Name: user_github_org_memberships uq_user_github_org_memberships_user_org; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: uq_analyses_completed_branch_commit_version; Type: INDEX; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_github_org_memberships
-    ADD CONSTRAINT uq_user_github_org_memberships_user_org UNIQUE (user_id, org_id);
+CREATE UNIQUE INDEX uq_analyses_completed_branch_commit_version ON public.analyses USING btree (codebase_id, COALESCE(branch_name, ''::character varying), commit_sha, parser_version) WHERE (status = 'completed'::public.analysis_status);
 
 
 --
--- Name: user_github_repositories uq_user_github_repositories_user_repo; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: ai_usage fk_ai_usage_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_github_repositories
-    ADD CONSTRAINT uq_user_github_repositories_user_repo UNIQUE (user_id, github_repo_id);
+ALTER TABLE ONLY public.ai_usage
+    ADD CONSTRAINT fk_ai_usage_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE SET NULL;
 
 
 --
--- Name: user_specview_history uq_user_specview_history_user_document; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: ai_usage fk_ai_usage_document; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_specview_history
-    ADD CONSTRAINT uq_user_specview_history_user_document UNIQUE (user_id, document_id);
+ALTER TABLE ONLY public.ai_usage
+    ADD CONSTRAINT fk_ai_usage_document FOREIGN KEY (document_id) REFERENCES public.spec_documents(id) ON DELETE SET NULL;
 
 
 --
--- Name: usage_events usage_events_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: ai_usage fk_ai_usage_user; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.usage_events
-    ADD CONSTRAINT usage_events_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.ai_usage
+    ADD CONSTRAINT fk_ai_usage_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
 --
--- Name: user_analysis_history user_analysis_history_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: analyses fk_analyses_codebase; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_analysis_history
-    ADD CONSTRAINT user_analysis_history_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.analyses
+    ADD CONSTRAINT fk_analyses_codebase FOREIGN KEY (codebase_id) REFERENCES public.codebases(id) ON DELETE CASCADE;
 
 
 --
--- Name: user_bookmarks user_bookmarks_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: analyses fk_analyses_deduplicated_from; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_bookmarks
-    ADD CONSTRAINT user_bookmarks_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.analyses
+    ADD CONSTRAINT fk_analyses_deduplicated_from FOREIGN KEY (deduplicated_from) REFERENCES public.analyses(id) ON DELETE SET NULL;
 
 
 --
--- Name: user_github_org_memberships user_github_org_memberships_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: analysis_events fk_analysis_events_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_github_org_memberships
-    ADD CONSTRAINT user_github_org_memberships_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.analysis_events
+    ADD CONSTRAINT fk_analysis_events_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: user_github_repositories user_github_repositories_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: analysis_languages fk_analysis_languages_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_github_repositories
-    ADD CONSTRAINT user_github_repositories_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.analysis_languages
+    ADD CONSTRAINT fk_analysis_languages_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: user_specview_history user_specview_history_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: analysis_shared_helpers fk_analysis_shared_helpers_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_specview_history
-    ADD CONSTRAINT user_specview_history_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.analysis_shared_helpers
+    ADD CONSTRAINT fk_analysis_shared_helpers_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: user_subscriptions user_subscriptions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: analysis_source_files fk_analysis_source_files_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.user_subscriptions
-    ADD CONSTRAINT user_subscriptions_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.analysis_source_files
+    ADD CONSTRAINT fk_analysis_source_files_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: users users_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: analysis_staging_test_cases fk_analysis_staging_test_cases_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.users
-    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.analysis_staging_test_cases
+    ADD CONSTRAINT fk_analysis_staging_test_cases_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_analyses_codebase_status; Type: INDEX; Schema: public; Owner: -
+-- Name: analysis_staging_test_files fk_analysis_staging_test_files_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_analyses_codebase_status ON public.analyses USING btree (codebase_id, status);
+ALTER TABLE ONLY public.analysis_staging_test_files
+    ADD CONSTRAINT fk_analysis_staging_test_files_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_analyses_created; Type: INDEX; Schema: public; Owner: -
+-- Name: analysis_staging_test_suites fk_analysis_staging_test_suites_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_analyses_created ON public.analyses USING btree (codebase_id, created_at);
+ALTER TABLE ONLY public.analysis_staging_test_suites
+    ADD CONSTRAINT fk_analysis_staging_test_suites_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_behavior_caches_created_at; Type: INDEX; Schema: public; Owner: -
+-- Name: behavior_caches fk_behavior_caches_codebase; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_behavior_caches_created_at ON public.behavior_caches USING btree (created_at);
+ALTER TABLE ONLY public.behavior_caches
+    ADD CONSTRAINT fk_behavior_caches_codebase FOREIGN KEY (codebase_id) REFERENCES public.codebases(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_classification_caches_created_at; Type: INDEX; Schema: public; Owner: -
+-- Name: behavior_feedback fk_behavior_feedback_behavior; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_classification_caches_created_at ON public.classification_caches USING btree (created_at);
+ALTER TABLE ONLY public.behavior_feedback
+    ADD CONSTRAINT fk_behavior_feedback_behavior FOREIGN KEY (behavior_id) REFERENCES public.spec_behaviors(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_codebases_external_repo_id; Type: INDEX; Schema: public; Owner: -
+-- Name: behavior_feedback fk_behavior_feedback_user; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE UNIQUE INDEX idx_codebases_external_repo_id ON public.codebases USING btree (host, external_repo_id);
+ALTER TABLE ONLY public.behavior_feedback
+    ADD CONSTRAINT fk_behavior_feedback_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_codebases_identity; Type: INDEX; Schema: public; Owner: -
+-- Name: classification_caches fk_classification_caches_codebase; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE UNIQUE INDEX idx_codebases_identity ON public.codebases USING btree (host, owner, name) WHERE (is_stale = false);
+ALTER TABLE ONLY public.classification_caches
+    ADD CONSTRAINT fk_classification_caches_codebase FOREIGN KEY (codebase_id) REFERENCES public.codebases(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_codebases_last_viewed; Type: INDEX; Schema: public; Owner: -
+-- Name: codebase_sharing_opt_outs fk_codebase_sharing_opt_outs_codebase; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_codebases_last_viewed ON public.codebases USING btree (last_viewed_at) WHERE (last_viewed_at IS NOT NULL);
+ALTER TABLE ONLY public.codebase_sharing_opt_outs
+    ADD CONSTRAINT fk_codebase_sharing_opt_outs_codebase FOREIGN KEY (codebase_id) REFERENCES public.codebases(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_codebases_owner_name; Type: INDEX; Schema: public; Owner: -
+-- Name: codebase_taxonomies fk_codebase_taxonomies_codebase; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_codebases_owner_name ON public.codebases USING btree (owner, name);
+ALTER TABLE ONLY public.codebase_taxonomies
+    ADD CONSTRAINT fk_codebase_taxonomies_codebase FOREIGN KEY (codebase_id) REFERENCES public.codebases(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_codebases_public; Type: INDEX; Schema: public; Owner: -
+-- Name: coverage_uploads fk_coverage_uploads_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_codebases_public ON public.codebases USING btree (is_private) WHERE (is_private = false);
+ALTER TABLE ONLY public.coverage_uploads
+    ADD CONSTRAINT fk_coverage_uploads_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_github_app_installations_installer; Type: INDEX; Schema: public; Owner: -
+-- Name: github_app_installations fk_github_app_installations_installer; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_github_app_installations_installer ON public.github_app_installations USING btree (installer_user_id) WHERE (installer_user_id IS NOT NULL);
+ALTER TABLE ONLY public.github_app_installations
+    ADD CONSTRAINT fk_github_app_installations_installer FOREIGN KEY (installer_user_id) REFERENCES public.users(id) ON DELETE SET NULL;
 
 
 --
--- Name: idx_github_organizations_login; Type: INDEX; Schema: public; Owner: -
+-- Name: glossary_entries fk_glossary_entries_codebase; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_github_organizations_login ON public.github_organizations USING btree (login);
+ALTER TABLE ONLY public.glossary_entries
+    ADD CONSTRAINT fk_glossary_entries_codebase FOREIGN KEY (codebase_id) REFERENCES public.codebases(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_oauth_accounts_user_id; Type: INDEX; Schema: public; Owner: -
+-- Name: glossary_entries fk_glossary_entries_user; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_oauth_accounts_user_id ON public.oauth_accounts USING btree (user_id);
+ALTER TABLE ONLY public.glossary_entries
+    ADD CONSTRAINT fk_glossary_entries_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_oauth_accounts_user_provider; Type: INDEX; Schema: public; Owner: -
+-- Name: notification_channels fk_notification_channels_codebase; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_oauth_accounts_user_provider ON public.oauth_accounts USING btree (user_id, provider);
+ALTER TABLE ONLY public.notification_channels
+    ADD CONSTRAINT fk_notification_channels_codebase FOREIGN KEY (codebase_id) REFERENCES public.codebases(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_quota_reservations_expires; Type: INDEX; Schema: public; Owner: -
+-- Name: notification_channels fk_notification_channels_user; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_quota_reservations_expires ON public.quota_reservations USING btree (expires_at);
+ALTER TABLE ONLY public.notification_channels
+    ADD CONSTRAINT fk_notification_channels_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_quota_reservations_user_event; Type: INDEX; Schema: public; Owner: -
+-- Name: oauth_accounts fk_oauth_accounts_user; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_quota_reservations_user_event ON public.quota_reservations USING btree (user_id, event_type);
+ALTER TABLE ONLY public.oauth_accounts
+    ADD CONSTRAINT fk_oauth_accounts_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_refresh_tokens_expires; Type: INDEX; Schema: public; Owner: -
+-- Name: parse_errors fk_parse_errors_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_refresh_tokens_expires ON public.refresh_tokens USING btree (expires_at) WHERE (revoked_at IS NULL);
+ALTER TABLE ONLY public.parse_errors
+    ADD CONSTRAINT fk_parse_errors_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_refresh_tokens_family_active; Type: INDEX; Schema: public; Owner: -
+-- Name: quota_reservations fk_quota_reservations_user; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_refresh_tokens_family_active ON public.refresh_tokens USING btree (family_id, created_at) WHERE (revoked_at IS NULL);
+ALTER TABLE ONLY public.quota_reservations
+    ADD CONSTRAINT fk_quota_reservations_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_refresh_tokens_user; Type: INDEX; Schema: public; Owner: -
+-- Name: refresh_tokens fk_refresh_tokens_replaces; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_refresh_tokens_user ON public.refresh_tokens USING btree (user_id);
+ALTER TABLE ONLY public.refresh_tokens
+    ADD CONSTRAINT fk_refresh_tokens_replaces FOREIGN KEY (replaces) REFERENCES public.refresh_tokens(id) ON DELETE SET NULL;
 
 
 --
--- Name: idx_spec_behaviors_feature_sort; Type: INDEX; Schema: public; Owner: -
+-- Name: refresh_tokens fk_refresh_tokens_user; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_spec_behaviors_feature_sort ON public.spec_behaviors USING btree (feature_id, sort_order);
+ALTER TABLE ONLY public.refresh_tokens
+    ADD CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_spec_behaviors_source; Type: INDEX; Schema: public; Owner: -
+-- Name: regen_campaign_targets fk_regen_campaign_targets_campaign; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_spec_behaviors_source ON public.spec_behaviors USING btree (source_test_case_id) WHERE (source_test_case_id IS NOT NULL);
+ALTER TABLE ONLY public.regen_campaign_targets
+    ADD CONSTRAINT fk_regen_campaign_targets_campaign FOREIGN KEY (campaign_id) REFERENCES public.regen_campaigns(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_spec_documents_analysis; Type: INDEX; Schema: public; Owner: -
+-- Name: requirement_coverage fk_requirement_coverage_behavior; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_spec_documents_analysis ON public.spec_documents USING btree (analysis_id);
+ALTER TABLE ONLY public.requirement_coverage
+    ADD CONSTRAINT fk_requirement_coverage_behavior FOREIGN KEY (behavior_id) REFERENCES public.spec_behaviors(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_spec_documents_content_hash_lang_model; Type: INDEX; Schema: public; Owner: -
+-- Name: requirement_coverage fk_requirement_coverage_document; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_spec_documents_content_hash_lang_model ON public.spec_documents USING btree (content_hash, language, model_id);
+ALTER TABLE ONLY public.requirement_coverage
+    ADD CONSTRAINT fk_requirement_coverage_document FOREIGN KEY (document_id) REFERENCES public.spec_documents(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_spec_documents_retention_cleanup; Type: INDEX; Schema: public; Owner: -
+-- Name: requirement_coverage fk_requirement_coverage_requirement; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_spec_documents_retention_cleanup ON public.spec_documents USING btree (created_at) WHERE (retention_days_at_creation IS NOT NULL);
+ALTER TABLE ONLY public.requirement_coverage
+    ADD CONSTRAINT fk_requirement_coverage_requirement FOREIGN KEY (requirement_id) REFERENCES public.requirements(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_spec_documents_user_created; Type: INDEX; Schema: public; Owner: -
+-- Name: requirement_sets fk_requirement_sets_codebase; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_spec_documents_user_created ON public.spec_documents USING btree (user_id, created_at);
+ALTER TABLE ONLY public.requirement_sets
+    ADD CONSTRAINT fk_requirement_sets_codebase FOREIGN KEY (codebase_id) REFERENCES public.codebases(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_spec_domains_document_sort; Type: INDEX; Schema: public; Owner: -
+-- Name: requirements fk_requirements_set; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_spec_domains_document_sort ON public.spec_domains USING btree (document_id, sort_order);
+ALTER TABLE ONLY public.requirements
+    ADD CONSTRAINT fk_requirements_set FOREIGN KEY (requirement_set_id) REFERENCES public.requirement_sets(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_spec_features_domain_sort; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_behavior_coverage fk_spec_behavior_coverage_behavior; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_spec_features_domain_sort ON public.spec_features USING btree (domain_id, sort_order);
+ALTER TABLE ONLY public.spec_behavior_coverage
+    ADD CONSTRAINT fk_spec_behavior_coverage_behavior FOREIGN KEY (behavior_id) REFERENCES public.spec_behaviors(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_test_cases_status; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_behavior_merges fk_spec_behavior_merges_behavior; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_test_cases_status ON public.test_cases USING btree (suite_id, status);
+ALTER TABLE ONLY public.spec_behavior_merges
+    ADD CONSTRAINT fk_spec_behavior_merges_behavior FOREIGN KEY (behavior_id) REFERENCES public.spec_behaviors(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_test_cases_suite; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_behavior_merges fk_spec_behavior_merges_test_case; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_test_cases_suite ON public.test_cases USING btree (suite_id);
+ALTER TABLE ONLY public.spec_behavior_merges
+    ADD CONSTRAINT fk_spec_behavior_merges_test_case FOREIGN KEY (source_test_case_id) REFERENCES public.test_cases(id) ON DELETE SET NULL;
 
 
 --
--- Name: idx_test_files_analysis; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_behavior_test_cases fk_spec_behavior_test_cases_behavior; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_test_files_analysis ON public.test_files USING btree (analysis_id);
+ALTER TABLE ONLY public.spec_behavior_test_cases
+    ADD CONSTRAINT fk_spec_behavior_test_cases_behavior FOREIGN KEY (behavior_id) REFERENCES public.spec_behaviors(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_test_suites_file; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_behavior_test_cases fk_spec_behavior_test_cases_test_case; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_test_suites_file ON public.test_suites USING btree (file_id);
+ALTER TABLE ONLY public.spec_behavior_test_cases
+    ADD CONSTRAINT fk_spec_behavior_test_cases_test_case FOREIGN KEY (test_case_id) REFERENCES public.test_cases(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_test_suites_parent; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_behaviors fk_spec_behaviors_feature; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_test_suites_parent ON public.test_suites USING btree (parent_id) WHERE (parent_id IS NOT NULL);
+ALTER TABLE ONLY public.spec_behaviors
+    ADD CONSTRAINT fk_spec_behaviors_feature FOREIGN KEY (feature_id) REFERENCES public.spec_features(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_usage_events_analysis; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_behaviors fk_spec_behaviors_test_case; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_usage_events_analysis ON public.usage_events USING btree (analysis_id) WHERE (analysis_id IS NOT NULL);
+ALTER TABLE ONLY public.spec_behaviors
+    ADD CONSTRAINT fk_spec_behaviors_test_case FOREIGN KEY (source_test_case_id) REFERENCES public.test_cases(id) ON DELETE SET NULL;
 
 
 --
--- Name: idx_usage_events_document; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_document_decisions fk_spec_document_decisions_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_usage_events_document ON public.usage_events USING btree (document_id) WHERE (document_id IS NOT NULL);
+ALTER TABLE ONLY public.spec_document_decisions
+    ADD CONSTRAINT fk_spec_document_decisions_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_usage_events_quota_lookup; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_document_decisions fk_spec_document_decisions_document; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_usage_events_quota_lookup ON public.usage_events USING btree (user_id, event_type, created_at);
+ALTER TABLE ONLY public.spec_document_decisions
+    ADD CONSTRAINT fk_spec_document_decisions_document FOREIGN KEY (document_id) REFERENCES public.spec_documents(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_analysis_history_analysis; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_document_exports fk_spec_document_exports_document; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_analysis_history_analysis ON public.user_analysis_history USING btree (analysis_id);
+ALTER TABLE ONLY public.spec_document_exports
+    ADD CONSTRAINT fk_spec_document_exports_document FOREIGN KEY (document_id) REFERENCES public.spec_documents(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_analysis_history_cursor; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_document_pins fk_spec_document_pins_document; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_analysis_history_cursor ON public.user_analysis_history USING btree (user_id, updated_at, id);
+ALTER TABLE ONLY public.spec_document_pins
+    ADD CONSTRAINT fk_spec_document_pins_document FOREIGN KEY (document_id) REFERENCES public.spec_documents(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_analysis_history_retention_cleanup; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_document_shares fk_spec_document_shares_document; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_analysis_history_retention_cleanup ON public.user_analysis_history USING btree (created_at) WHERE (retention_days_at_creation IS NOT NULL);
+ALTER TABLE ONLY public.spec_document_shares
+    ADD CONSTRAINT fk_spec_document_shares_document FOREIGN KEY (document_id) REFERENCES public.spec_documents(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_bookmarks_user; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_document_shares fk_spec_document_shares_user; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_bookmarks_user ON public.user_bookmarks USING btree (user_id, created_at);
+ALTER TABLE ONLY public.spec_document_shares
+    ADD CONSTRAINT fk_spec_document_shares_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_github_org_memberships_org; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_documents fk_spec_documents_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_github_org_memberships_org ON public.user_github_org_memberships USING btree (org_id);
+ALTER TABLE ONLY public.spec_documents
+    ADD CONSTRAINT fk_spec_documents_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_github_org_memberships_user; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_documents fk_spec_documents_encryption_key; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_github_org_memberships_user ON public.user_github_org_memberships USING btree (user_id);
+ALTER TABLE ONLY public.spec_documents
+    ADD CONSTRAINT fk_spec_documents_encryption_key FOREIGN KEY (encryption_key_id) REFERENCES public.tenant_document_keys(id);
 
 
 --
--- Name: idx_user_github_repositories_language; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_documents fk_spec_documents_parent; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_github_repositories_language ON public.user_github_repositories USING btree (user_id, language) WHERE (language IS NOT NULL);
+ALTER TABLE ONLY public.spec_documents
+    ADD CONSTRAINT fk_spec_documents_parent FOREIGN KEY (parent_document_id) REFERENCES public.spec_documents(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_github_repositories_org; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_documents fk_spec_documents_user; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_github_repositories_org ON public.user_github_repositories USING btree (user_id, org_id) WHERE (org_id IS NOT NULL);
+ALTER TABLE ONLY public.spec_documents
+    ADD CONSTRAINT fk_spec_documents_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_github_repositories_source; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_domains fk_spec_domains_document; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_github_repositories_source ON public.user_github_repositories USING btree (user_id, source_type);
+ALTER TABLE ONLY public.spec_domains
+    ADD CONSTRAINT fk_spec_domains_document FOREIGN KEY (document_id) REFERENCES public.spec_documents(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_github_repositories_user; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_features fk_spec_features_domain; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_github_repositories_user ON public.user_github_repositories USING btree (user_id, updated_at);
+ALTER TABLE ONLY public.spec_features
+    ADD CONSTRAINT fk_spec_features_domain FOREIGN KEY (domain_id) REFERENCES public.spec_domains(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_specview_history_cursor; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_gap_reports fk_spec_gap_reports_document; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_specview_history_cursor ON public.user_specview_history USING btree (user_id, updated_at, id);
+ALTER TABLE ONLY public.spec_gap_reports
+    ADD CONSTRAINT fk_spec_gap_reports_document FOREIGN KEY (document_id) REFERENCES public.spec_documents(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_specview_history_document; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_generation_cancellations fk_spec_generation_cancellations_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_specview_history_document ON public.user_specview_history USING btree (document_id);
+ALTER TABLE ONLY public.spec_generation_cancellations
+    ADD CONSTRAINT fk_spec_generation_cancellations_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_subscriptions_active; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_generation_cancellations fk_spec_generation_cancellations_user; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE UNIQUE INDEX idx_user_subscriptions_active ON public.user_subscriptions USING btree (user_id) WHERE (status = 'active'::public.subscription_status);
+ALTER TABLE ONLY public.spec_generation_cancellations
+    ADD CONSTRAINT fk_spec_generation_cancellations_user FOREIGN KEY (requested_by) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_subscriptions_period_end; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_generation_checkpoint_features fk_spec_generation_checkpoint_features_checkpoint; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_subscriptions_period_end ON public.user_subscriptions USING btree (current_period_end) WHERE (status = 'active'::public.subscription_status);
+ALTER TABLE ONLY public.spec_generation_checkpoint_features
+    ADD CONSTRAINT fk_spec_generation_checkpoint_features_checkpoint FOREIGN KEY (job_id) REFERENCES public.spec_generation_checkpoints(job_id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_user_subscriptions_plan; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_generation_checkpoints fk_spec_generation_checkpoints_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_user_subscriptions_plan ON public.user_subscriptions USING btree (plan_id);
+ALTER TABLE ONLY public.spec_generation_checkpoints
+    ADD CONSTRAINT fk_spec_generation_checkpoints_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_users_email; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_generation_checkpoints fk_spec_generation_checkpoints_job; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE UNIQUE INDEX idx_users_email ON public.users USING btree (email) WHERE (email IS NOT NULL);
+ALTER TABLE ONLY public.spec_generation_checkpoints
+    ADD CONSTRAINT fk_spec_generation_checkpoints_job FOREIGN KEY (job_id) REFERENCES public.river_job(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_users_username; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_generation_claims fk_spec_generation_claims_job; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX idx_users_username ON public.users USING btree (username);
+ALTER TABLE ONLY public.spec_generation_claims
+    ADD CONSTRAINT fk_spec_generation_claims_job FOREIGN KEY (job_id) REFERENCES public.river_job(id) ON DELETE CASCADE;
 
 
 --
--- Name: river_job_args_index; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_generation_progress fk_spec_generation_progress_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX river_job_args_index ON public.river_job USING gin (args);
+ALTER TABLE ONLY public.spec_generation_progress
+    ADD CONSTRAINT fk_spec_generation_progress_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: river_job_kind; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_search_entries fk_spec_search_entries_behavior; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX river_job_kind ON public.river_job USING btree (kind);
+ALTER TABLE ONLY public.spec_search_entries
+    ADD CONSTRAINT fk_spec_search_entries_behavior FOREIGN KEY (behavior_id) REFERENCES public.spec_behaviors(id) ON DELETE CASCADE;
 
 
 --
--- Name: river_job_metadata_index; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_search_entries fk_spec_search_entries_codebase; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX river_job_metadata_index ON public.river_job USING gin (metadata);
+ALTER TABLE ONLY public.spec_search_entries
+    ADD CONSTRAINT fk_spec_search_entries_codebase FOREIGN KEY (codebase_id) REFERENCES public.codebases(id) ON DELETE CASCADE;
 
 
 --
--- Name: river_job_prioritized_fetching_index; Type: INDEX; Schema: public; Owner: -
+-- Name: spec_search_entries fk_spec_search_entries_document; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX river_job_prioritized_fetching_index ON public.river_job USING btree (state, queue, priority, scheduled_at, id);
+ALTER TABLE ONLY public.spec_search_entries
+    ADD CONSTRAINT fk_spec_search_entries_document FOREIGN KEY (document_id) REFERENCES public.spec_documents(id) ON DELETE CASCADE;
 
 
 --
--- Name: river_job_state_and_finalized_at_index; Type: INDEX; Schema: public; Owner: -
+-- Name: tenant_ai_budgets fk_tenant_ai_budgets_tenant; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX river_job_state_and_finalized_at_index ON public.river_job USING btree (state, finalized_at) WHERE (finalized_at IS NOT NULL);
+ALTER TABLE ONLY public.tenant_ai_budgets
+    ADD CONSTRAINT fk_tenant_ai_budgets_tenant FOREIGN KEY (tenant_id) REFERENCES public.tenants(id) ON DELETE CASCADE;
 
 
 --
--- Name: river_job_unique_idx; Type: INDEX; Schema: public; Owner: -
+-- Name: tenant_ai_usage fk_tenant_ai_usage_document; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE UNIQUE INDEX river_job_unique_idx ON public.river_job USING btree (unique_key) WHERE ((unique_key IS NOT NULL) AND (unique_states IS NOT NULL) AND public.river_job_state_in_bitmask(unique_states, state));
+ALTER TABLE ONLY public.tenant_ai_usage
+    ADD CONSTRAINT fk_tenant_ai_usage_document FOREIGN KEY (document_id) REFERENCES public.spec_documents(id) ON DELETE SET NULL;
 
 
 --
--- Name: uq_analyses_completed_commit_version; Type: INDEX; Schema: public; Owner: -
+-- Name: tenant_ai_usage fk_tenant_ai_usage_tenant; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE UNIQUE INDEX uq_analyses_completed_commit_version ON public.analyses USING btree (codebase_id, commit_sha, parser_version) WHERE (status = 'completed'::public.analysis_status);
+ALTER TABLE ONLY public.tenant_ai_usage
+    ADD CONSTRAINT fk_tenant_ai_usage_tenant FOREIGN KEY (tenant_id) REFERENCES public.tenants(id) ON DELETE CASCADE;
 
 
 --
--- Name: analyses fk_analyses_codebase; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: tenant_document_keys fk_tenant_document_keys_tenant; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.analyses
-    ADD CONSTRAINT fk_analyses_codebase FOREIGN KEY (codebase_id) REFERENCES public.codebases(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.tenant_document_keys
+    ADD CONSTRAINT fk_tenant_document_keys_tenant FOREIGN KEY (tenant_id) REFERENCES public.tenants(id) ON DELETE CASCADE;
 
 
 --
--- Name: github_app_installations fk_github_app_installations_installer; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: tenant_members fk_tenant_members_tenant; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.github_app_installations
-    ADD CONSTRAINT fk_github_app_installations_installer FOREIGN KEY (installer_user_id) REFERENCES public.users(id) ON DELETE SET NULL;
+ALTER TABLE ONLY public.tenant_members
+    ADD CONSTRAINT fk_tenant_members_tenant FOREIGN KEY (tenant_id) REFERENCES public.tenants(id) ON DELETE CASCADE;
 
 
 --
--- Name: oauth_accounts fk_oauth_accounts_user; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: tenant_members fk_tenant_members_user; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.oauth_accounts
-    ADD CONSTRAINT fk_oauth_accounts_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.tenant_members
+    ADD CONSTRAINT fk_tenant_members_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
 --
--- Name: quota_reservations fk_quota_reservations_user; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: tenant_repo_policies fk_tenant_repo_policies_tenant; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.quota_reservations
-    ADD CONSTRAINT fk_quota_reservations_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.tenant_repo_policies
+    ADD CONSTRAINT fk_tenant_repo_policies_tenant FOREIGN KEY (tenant_id) REFERENCES public.tenants(id) ON DELETE CASCADE;
 
 
 --
--- Name: refresh_tokens fk_refresh_tokens_replaces; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: tenant_takeouts fk_tenant_takeouts_tenant; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.refresh_tokens
-    ADD CONSTRAINT fk_refresh_tokens_replaces FOREIGN KEY (replaces) REFERENCES public.refresh_tokens(id) ON DELETE SET NULL;
+ALTER TABLE ONLY public.tenant_takeouts
+    ADD CONSTRAINT fk_tenant_takeouts_tenant FOREIGN KEY (tenant_id) REFERENCES public.tenants(id) ON DELETE CASCADE;
 
 
 --
--- Name: refresh_tokens fk_refresh_tokens_user; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: test_case_results fk_test_case_results_test_case; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.refresh_tokens
-    ADD CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.test_case_results
+    ADD CONSTRAINT fk_test_case_results_test_case FOREIGN KEY (test_case_id) REFERENCES public.test_cases(id) ON DELETE CASCADE;
 
 
 --
--- Name: spec_behaviors fk_spec_behaviors_feature; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: test_case_results fk_test_case_results_upload; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.spec_behaviors
-    ADD CONSTRAINT fk_spec_behaviors_feature FOREIGN KEY (feature_id) REFERENCES public.spec_features(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.test_case_results
+    ADD CONSTRAINT fk_test_case_results_upload FOREIGN KEY (last_upload_id) REFERENCES public.test_result_uploads(id) ON DELETE SET NULL;
 
 
 --
--- Name: spec_behaviors fk_spec_behaviors_test_case; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: test_cases fk_test_cases_suite; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.spec_behaviors
-    ADD CONSTRAINT fk_spec_behaviors_test_case FOREIGN KEY (source_test_case_id) REFERENCES public.test_cases(id) ON DELETE SET NULL;
+ALTER TABLE ONLY public.test_cases
+    ADD CONSTRAINT fk_test_cases_suite FOREIGN KEY (suite_id) REFERENCES public.test_suites(id) ON DELETE CASCADE;
 
 
 --
--- Name: spec_documents fk_spec_documents_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: test_deltas fk_test_deltas_base_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.spec_documents
-    ADD CONSTRAINT fk_spec_documents_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.test_deltas
+    ADD CONSTRAINT fk_test_deltas_base_analysis FOREIGN KEY (base_analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: spec_documents fk_spec_documents_user; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: test_deltas fk_test_deltas_codebase; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.spec_documents
-    ADD CONSTRAINT fk_spec_documents_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.test_deltas
+    ADD CONSTRAINT fk_test_deltas_codebase FOREIGN KEY (codebase_id) REFERENCES public.codebases(id) ON DELETE CASCADE;
 
 
 --
--- Name: spec_domains fk_spec_domains_document; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: test_deltas fk_test_deltas_head_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.spec_domains
-    ADD CONSTRAINT fk_spec_domains_document FOREIGN KEY (document_id) REFERENCES public.spec_documents(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.test_deltas
+    ADD CONSTRAINT fk_test_deltas_head_analysis FOREIGN KEY (head_analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
 --
--- Name: spec_features fk_spec_features_domain; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: test_file_coverage fk_test_file_coverage_test_file; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.spec_features
-    ADD CONSTRAINT fk_spec_features_domain FOREIGN KEY (domain_id) REFERENCES public.spec_domains(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.test_file_coverage
+    ADD CONSTRAINT fk_test_file_coverage_test_file FOREIGN KEY (test_file_id) REFERENCES public.test_files(id) ON DELETE CASCADE;
 
 
 --
--- Name: test_cases fk_test_cases_suite; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: test_file_coverage fk_test_file_coverage_upload; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.test_cases
-    ADD CONSTRAINT fk_test_cases_suite FOREIGN KEY (suite_id) REFERENCES public.test_suites(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.test_file_coverage
+    ADD CONSTRAINT fk_test_file_coverage_upload FOREIGN KEY (upload_id) REFERENCES public.coverage_uploads(id) ON DELETE SET NULL;
 
 
 --
@@ -1594,6 +4005,14 @@ ALTER TABLE ONLY public.test_files
     ADD CONSTRAINT fk_test_files_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
 
 
+--
+-- Name: test_result_uploads fk_test_result_uploads_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.test_result_uploads
+    ADD CONSTRAINT fk_test_result_uploads_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
+
+
 --
 -- Name: test_suites fk_test_suites_file; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -1610,6 +4029,14 @@ ALTER TABLE ONLY public.test_suites
     ADD CONSTRAINT fk_test_suites_parent FOREIGN KEY (parent_id) REFERENCES public.test_suites(id) ON DELETE CASCADE;
 
 
+--
+-- Name: unmatched_file_samples fk_unmatched_file_samples_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.unmatched_file_samples
+    ADD CONSTRAINT fk_unmatched_file_samples_analysis FOREIGN KEY (analysis_id) REFERENCES public.analyses(id) ON DELETE CASCADE;
+
+
 --
 -- Name: usage_events fk_usage_events_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -1634,6 +4061,14 @@ ALTER TABLE ONLY public.usage_events
     ADD CONSTRAINT fk_usage_events_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
+--
+-- Name: usage_warnings fk_usage_warnings_user; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.usage_warnings
+    ADD CONSTRAINT fk_usage_warnings_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
 --
 -- Name: user_analysis_history fk_user_analysis_history_analysis; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -0,0 +1,49 @@
+package queue
+
+import (
+	"sync"
+	"time"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+var _ rivertype.TimeGenerator = (*Clock)(nil)
+
+// Clock is a River time source that only moves when the test moves it.
+// It decides when a snoozed or retried job becomes due and stamps attempts.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock creates a clock stopped at start.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start.UTC()}
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to t. Moving it backwards is allowed.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t.UTC()
+}
+
+func (c *Clock) NowUTC() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NowUTCOrNil always returns the stubbed time, so River never falls back to
+// the database clock.
+func (c *Clock) NowUTCOrNil() *time.Time {
+	now := c.NowUTC()
+	return &now
+}
@@ -0,0 +1,250 @@
+// Package queue runs real River workers against a test database.
+//
+// A Harness inserts a job and works it through River's own executor, so
+// middleware, snoozes, retries and cancellation behave as in production.
+// River's clock is stubbed: instead of sleeping, Run moves the clock to the
+// time a snoozed or retried job becomes due and works it again, until the job
+// is finalized. Multi-phase workers that snooze between polls, such as a batch
+// job going submit, poll, complete, are tested end to end this way.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivertest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// DefaultMaxSteps bounds the executions of one Run, so a worker that never
+// finalizes its job fails the test instead of looping forever.
+const DefaultMaxSteps = 50
+
+// Step is one execution of the job.
+type Step struct {
+	Attempt    int
+	At         time.Time       // clock time of the execution
+	Err        error           // error returned by the worker; nil for snoozes and cancellations it asked for
+	EventKind  river.EventKind // completed, snoozed, failed or cancelled
+	SnoozedFor time.Duration   // delay until the next execution, for snoozes and retries
+}
+
+// Result is the job row after its last execution and every step leading there.
+type Result struct {
+	Job   *rivertype.JobRow
+	Steps []Step
+}
+
+// Snoozes returns the number of executions that ended in a snooze.
+func (r *Result) Snoozes() int {
+	n := 0
+	for _, s := range r.Steps {
+		if s.EventKind == river.EventKindJobSnoozed {
+			n++
+		}
+	}
+	return n
+}
+
+// Harness works jobs of one kind with a real worker.
+type Harness[T river.JobArgs] struct {
+	Clock *Clock
+
+	beforeStep func(ctx context.Context, step int, job *rivertype.JobRow)
+	inserter   *river.Client[pgx.Tx]
+	maxSteps   int
+	pool       *pgxpool.Pool
+	worker     *rivertest.Worker[T, pgx.Tx]
+}
+
+type harnessConfig struct {
+	beforeStep func(ctx context.Context, step int, job *rivertype.JobRow)
+	configure  func(*river.Config)
+	maxSteps   int
+	start      time.Time
+}
+
+// Option configures a Harness.
+type Option func(*harnessConfig)
+
+// WithBeforeStep calls fn before every execution with the step index, starting
+// at 0, and the job row about to run. Tests inject failures here, such as
+// switching a fake provider to an error between a submit and its first poll.
+func WithBeforeStep(fn func(ctx context.Context, step int, job *rivertype.JobRow)) Option {
+	return func(cfg *harnessConfig) {
+		cfg.beforeStep = fn
+	}
+}
+
+// WithConfig adjusts the River config the job runs under, for example to add
+// the worker's production middleware.
+func WithConfig(fn func(*river.Config)) Option {
+	return func(cfg *harnessConfig) {
+		cfg.configure = fn
+	}
+}
+
+// WithMaxSteps sets the most executions a Run performs.
+// Zero or negative values are ignored and the default value is used.
+func WithMaxSteps(n int) Option {
+	return func(cfg *harnessConfig) {
+		if n > 0 {
+			cfg.maxSteps = n
+		}
+	}
+}
+
+// WithStartTime sets the initial clock time. The default is the current time.
+func WithStartTime(t time.Time) Option {
+	return func(cfg *harnessConfig) {
+		cfg.start = t
+	}
+}
+
+// NewHarness creates a harness working jobs with worker on the database behind
+// pool, typically one from postgres.SetupTestDB.
+func NewHarness[T river.JobArgs](t *testing.T, pool *pgxpool.Pool, worker river.Worker[T], opts ...Option) *Harness[T] {
+	t.Helper()
+
+	cfg := harnessConfig{
+		maxSteps: DefaultMaxSteps,
+		start:    time.Now(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	clock := NewClock(cfg.start)
+	riverConfig := &river.Config{Test: river.TestConfig{Time: clock}}
+	if cfg.configure != nil {
+		cfg.configure(riverConfig)
+	}
+
+	inserter, err := river.NewClient(riverpgxv5.New(nil), &river.Config{
+		Test: river.TestConfig{DisableUniqueEnforcement: true, Time: clock},
+	})
+	if err != nil {
+		t.Fatalf("create insert client: %v", err)
+	}
+
+	return &Harness[T]{
+		Clock:      clock,
+		beforeStep: cfg.beforeStep,
+		inserter:   inserter,
+		maxSteps:   cfg.maxSteps,
+		pool:       pool,
+		worker:     rivertest.NewWorker(t, riverpgxv5.New(nil), riverConfig, worker),
+	}
+}
+
+// Work inserts a job and executes it once.
+func (h *Harness[T]) Work(ctx context.Context, t *testing.T, args T, opts *river.InsertOpts) *Result {
+	t.Helper()
+
+	job := h.insert(ctx, t, args, opts)
+	result := &Result{}
+	h.step(ctx, t, job, result)
+	return result
+}
+
+// Run inserts a job and executes it until it is completed, cancelled or
+// discarded. Between executions the clock jumps to the time the snoozed or
+// retried job is scheduled at.
+func (h *Harness[T]) Run(ctx context.Context, t *testing.T, args T, opts *river.InsertOpts) *Result {
+	t.Helper()
+
+	job := h.insert(ctx, t, args, opts)
+	result := &Result{}
+	for {
+		if len(result.Steps) >= h.maxSteps {
+			t.Fatalf("job %d not finalized after %d steps, last state %s", job.ID, h.maxSteps, job.State)
+		}
+		job = h.step(ctx, t, job, result)
+		if job.FinalizedAt != nil {
+			return result
+		}
+		if job.ScheduledAt.After(h.Clock.NowUTC()) {
+			h.Clock.Set(job.ScheduledAt)
+		}
+	}
+}
+
+func (h *Harness[T]) insert(ctx context.Context, t *testing.T, args T, opts *river.InsertOpts) *rivertype.JobRow {
+	t.Helper()
+
+	var job *rivertype.JobRow
+	h.inTx(ctx, t, func(tx pgx.Tx) error {
+		inserted, err := h.inserter.InsertTx(ctx, tx, args, opts)
+		if err != nil {
+			return fmt.Errorf("insert job: %w", err)
+		}
+		job = inserted.Job
+		return nil
+	})
+	return job
+}
+
+// step executes job once in its own committed transaction, so the worker's
+// writes through other connections and the job state are both visible to the
+// next step and to the test.
+func (h *Harness[T]) step(ctx context.Context, t *testing.T, job *rivertype.JobRow, result *Result) *rivertype.JobRow {
+	t.Helper()
+
+	if h.beforeStep != nil {
+		h.beforeStep(ctx, len(result.Steps), job)
+	}
+
+	at := h.Clock.NowUTC()
+	var worked *rivertest.WorkResult
+	var workErr error
+	h.inTx(ctx, t, func(tx pgx.Tx) error {
+		var err error
+		worked, err = h.worker.WorkJob(ctx, t, tx, job)
+		if worked == nil {
+			return fmt.Errorf("work job %d: %w", job.ID, err)
+		}
+		workErr = err
+		return nil
+	})
+
+	step := Step{
+		Attempt:   worked.Job.Attempt,
+		At:        at,
+		Err:       workErr,
+		EventKind: worked.EventKind,
+	}
+	if worked.Job.FinalizedAt == nil {
+		step.SnoozedFor = worked.Job.ScheduledAt.Sub(at)
+	}
+	result.Steps = append(result.Steps, step)
+	result.Job = worked.Job
+	return worked.Job
+}
+
+func (h *Harness[T]) inTx(ctx context.Context, t *testing.T, fn func(tx pgx.Tx) error) {
+	t.Helper()
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin transaction: %v", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			t.Errorf("rollback transaction: %v", rbErr)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit transaction: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	testdb "github.com/specvital/worker/internal/testutil/postgres"
+)
+
+const pollInterval = time.Minute
+
+type batchArgs struct {
+	Name string `json:"name"`
+}
+
+func (batchArgs) Kind() string { return "test:batch" }
+
+// fakeBatchProvider finishes a batch after a number of polls.
+type fakeBatchProvider struct {
+	err       error
+	polls     int
+	pollsLeft int
+	submitted bool
+}
+
+// batchWorker submits a batch on its first run, then snoozes between polls
+// until the provider reports it finished.
+type batchWorker struct {
+	river.WorkerDefaults[batchArgs]
+	provider *fakeBatchProvider
+}
+
+func (w *batchWorker) Work(ctx context.Context, job *river.Job[batchArgs]) error {
+	if w.provider.err != nil {
+		return w.provider.err
+	}
+	if !w.provider.submitted {
+		w.provider.submitted = true
+		return river.JobSnooze(pollInterval)
+	}
+	w.provider.polls++
+	if w.provider.pollsLeft > 0 {
+		w.provider.pollsLeft--
+		return river.JobSnooze(pollInterval)
+	}
+	return nil
+}
+
+func TestClock(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("KST", 9*3600))
+	clock := NewClock(start)
+
+	if !clock.NowUTC().Equal(start) || clock.NowUTC().Location() != time.UTC {
+		t.Errorf("NowUTC = %v, want %v in UTC", clock.NowUTC(), start)
+	}
+	clock.Advance(time.Hour)
+	if got := *clock.NowUTCOrNil(); !got.Equal(start.Add(time.Hour)) {
+		t.Errorf("after Advance, NowUTCOrNil = %v", got)
+	}
+	clock.Set(start)
+	if !clock.NowUTC().Equal(start) {
+		t.Errorf("after Set, NowUTC = %v", clock.NowUTC())
+	}
+}
+
+func TestHarness(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, cleanup := testdb.SetupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should follow snoozes from submit to completion", func(t *testing.T) {
+		provider := &fakeBatchProvider{pollsLeft: 2}
+		h := NewHarness(t, pool, &batchWorker{provider: provider}, WithStartTime(start))
+
+		result := h.Run(ctx, t, batchArgs{Name: "phase1"}, nil)
+
+		if result.Job.State != rivertype.JobStateCompleted {
+			t.Fatalf("state = %s, want completed", result.Job.State)
+		}
+		if len(result.Steps) != 4 || result.Snoozes() != 3 || provider.polls != 3 {
+			t.Errorf("steps = %+v, polls = %d", result.Steps, provider.polls)
+		}
+		for _, s := range result.Steps[:3] {
+			if s.SnoozedFor != pollInterval {
+				t.Errorf("snoozed for %v, want %v", s.SnoozedFor, pollInterval)
+			}
+		}
+		if got := h.Clock.NowUTC(); !got.Equal(start.Add(3 * pollInterval)) {
+			t.Errorf("clock = %v, want %v", got, start.Add(3*pollInterval))
+		}
+	})
+
+	t.Run("should retry a failure injected between phases", func(t *testing.T) {
+		provider := &fakeBatchProvider{}
+		injected := errors.New("provider unavailable")
+		h := NewHarness(t, pool, &batchWorker{provider: provider},
+			WithStartTime(start),
+			WithBeforeStep(func(ctx context.Context, step int, job *rivertype.JobRow) {
+				provider.err = nil
+				if step == 1 {
+					provider.err = injected
+				}
+			}),
+		)
+
+		result := h.Run(ctx, t, batchArgs{Name: "phase2"}, nil)
+
+		if result.Job.State != rivertype.JobStateCompleted || len(result.Steps) != 3 {
+			t.Fatalf("state = %s, steps = %+v", result.Job.State, result.Steps)
+		}
+		failed := result.Steps[1]
+		if failed.EventKind != river.EventKindJobFailed || !errors.Is(failed.Err, injected) || failed.SnoozedFor <= 0 {
+			t.Errorf("unexpected failed step: %+v", failed)
+		}
+	})
+
+	t.Run("should execute a job once with Work", func(t *testing.T) {
+		provider := &fakeBatchProvider{pollsLeft: 10}
+		h := NewHarness(t, pool, &batchWorker{provider: provider})
+
+		result := h.Work(ctx, t, batchArgs{Name: "once"}, nil)
+
+		if len(result.Steps) != 1 || result.Job.State != rivertype.JobStateScheduled {
+			t.Errorf("state = %s, steps = %+v", result.Job.State, result.Steps)
+		}
+	})
+}
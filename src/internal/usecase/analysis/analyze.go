@@ -1,50 +1,97 @@
 package analysis
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/semaphore"
 
 	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/curation"
+	"github.com/specvital/worker/internal/domain/sla"
 )
 
 const (
 	DefaultAnalysisBatchSize   = 100
 	DefaultMaxConcurrentClones = 2
 	DefaultAnalysisTimeout     = 15 * time.Minute
+	DefaultMaxRepoSizeBytes    = 5 << 30
 	// DefaultOAuthProvider is the OAuth provider for VCS authentication.
 	// Currently only GitHub is supported as the VCS provider (see repoURL construction in Execute).
 	DefaultOAuthProvider = "github"
 	DefaultHost          = "github.com"
+
+	// maxCurationRulesBytes bounds the rules file read from the repository.
+	maxCurationRulesBytes = 64 * 1024
+	// maxUnmatchedSnippetBytes bounds an unmatched test file read for its snippet.
+	// Larger files are sampled without one.
+	maxUnmatchedSnippetBytes = 1 << 20
+	// maxHelperScanBytes bounds a test file read for the helpers it imports.
+	maxHelperScanBytes = 1 << 20
 )
 
 // AnalyzeUseCase orchestrates repository analysis workflow.
 type AnalyzeUseCase struct {
-	batchSize       int
-	cloneSem        *semaphore.Weighted
-	codebaseRepo    analysis.CodebaseRepository
-	parser          analysis.Parser
-	parserVersion   string
-	repository      analysis.Repository
-	streamingParser analysis.StreamingParser
-	streamingRepo   analysis.StreamingRepository
-	timeout         time.Duration
-	tokenLookup     analysis.TokenLookup
-	vcs             analysis.VCS
-	vcsAPIClient    analysis.VCSAPIClient
+	batchSize         int
+	cloneSem          *semaphore.Weighted
+	codebaseRepo      analysis.CodebaseRepository
+	curationRepo      analysis.CurationRulesRepository
+	fileReuseRepo     analysis.FileReuseRepository
+	forkReuseRepo     analysis.ForkReuseRepository
+	helperRepo        analysis.SharedHelperRepository
+	hostCloneSems     map[string]*semaphore.Weighted
+	hosts             map[string]bool
+	parseErrorRepo    analysis.ParseErrorRepository
+	filteringParser   analysis.FilteringParser
+	incrementalParser analysis.IncrementalParser
+	installTokens     analysis.InstallationTokenSource
+	languageRepo      analysis.LanguageStatsRepository
+	maxRepoSize       int64
+	minHelperRefs     int
+	minVariants       int
+	parser            analysis.Parser
+	parserVersion     string
+	pathFiltersRepo   analysis.PathFiltersRepository
+	progressRepo      analysis.ProgressRepository
+	region            string
+	repository        analysis.Repository
+	snippetLines      int
+	sourceFileRepo    analysis.SourceFileRepository
+	sourceLister      analysis.SourceFileLister
+	streamingParser   analysis.StreamingParser
+	streamingRepo     analysis.StreamingRepository
+	timeout           time.Duration
+	tokenLookup       analysis.TokenLookup
+	unmatchedRepo     analysis.UnmatchedSampleRepository
+	unmatchedSamples  int
+	vcs               analysis.VCS
+	vcsAPIClient      analysis.VCSAPIClient
 }
 
 // Config holds configuration for AnalyzeUseCase.
 type Config struct {
-	AnalysisTimeout     time.Duration
-	BatchSize           int
-	MaxConcurrentClones int64
-	ParserVersion       string
+	AnalysisTimeout       time.Duration
+	BatchSize             int
+	EnterpriseHosts       []string         // hosts analyzed besides DefaultHost
+	HostCloneLimits       map[string]int64 // hosts cloned apart from the rest; zero uses MaxConcurrentClones
+	InstallationTokens    analysis.InstallationTokenSource
+	MaxConcurrentClones   int64
+	MaxRepoSizeBytes      int64
+	MinHelperReferences   int
+	MinTestVariants       int
+	ParserVersion         string
+	Region                string
+	UnmatchedSamples      int
+	UnmatchedSnippetLines int
 }
 
 // Option is a functional option for configuring AnalyzeUseCase.
@@ -70,6 +117,60 @@ func WithMaxConcurrentClones(n int64) Option {
 	}
 }
 
+// WithEnterpriseHosts allows analyzing repositories of the given hosts besides
+// DefaultHost. Requests for any other host are rejected before they are cloned.
+func WithEnterpriseHosts(hosts ...string) Option {
+	return func(cfg *Config) {
+		for _, host := range hosts {
+			cfg.EnterpriseHosts = append(cfg.EnterpriseHosts, strings.ToLower(host))
+		}
+	}
+}
+
+// WithHostCloneLimit gives the repositories of host their own bound on
+// concurrent clones, so slow clones of one host cannot hold the clone slots
+// of every other. Zero or negative values use the MaxConcurrentClones bound.
+func WithHostCloneLimit(host string, n int64) Option {
+	return func(cfg *Config) {
+		if cfg.HostCloneLimits == nil {
+			cfg.HostCloneLimits = make(map[string]int64)
+		}
+		cfg.HostCloneLimits[strings.ToLower(host)] = max(n, 0)
+	}
+}
+
+// WithMaxRepoSize sets the largest repository, as reported by the VCS API, that is cloned.
+// Zero or negative values are ignored and the default value is used.
+func WithMaxRepoSize(bytes int64) Option {
+	return func(cfg *Config) {
+		if bytes > 0 {
+			cfg.MaxRepoSizeBytes = bytes
+		}
+	}
+}
+
+// WithTestVariantCollapse sets the smallest group of sibling tests differing
+// only in parameters that is collapsed into one test with a variant count.
+// Zero is ignored and the default is used; a negative value disables collapsing.
+func WithTestVariantCollapse(minVariants int) Option {
+	return func(cfg *Config) {
+		if minVariants != 0 {
+			cfg.MinTestVariants = minVariants
+		}
+	}
+}
+
+// WithSharedHelpers sets how many test files must import a helper or fixture
+// for the analysis to record it as shared. Zero is ignored and the default is
+// used; a negative value disables the detection.
+func WithSharedHelpers(minReferences int) Option {
+	return func(cfg *Config) {
+		if minReferences != 0 {
+			cfg.MinHelperReferences = minReferences
+		}
+	}
+}
+
 // WithParserVersion sets the parser version to be recorded with each analysis.
 // This should be set to the core module version extracted at startup.
 func WithParserVersion(v string) Option {
@@ -78,6 +179,38 @@ func WithParserVersion(v string) Option {
 	}
 }
 
+// WithRegion sets the data-residency region stamped on codebases this worker creates.
+// Existing codebases keep their region.
+func WithRegion(r string) Option {
+	return func(cfg *Config) {
+		cfg.Region = r
+	}
+}
+
+// WithUnmatchedSampling sets how many test file candidates no parser strategy
+// matched are sampled per analysis, and how many lines of each are kept as a
+// redacted snippet. Zero samples are ignored and the default is used; negative
+// samples disable sampling. Zero or negative lines store paths only. Only
+// streaming scans report the paths of unmatched candidates.
+func WithUnmatchedSampling(samples, snippetLines int) Option {
+	return func(cfg *Config) {
+		if samples != 0 {
+			cfg.UnmatchedSamples = samples
+		}
+		cfg.UnmatchedSnippetLines = max(snippetLines, 0)
+	}
+}
+
+// WithInstallationTokens sets the source of app installation tokens, used for
+// jobs without a user, and preferred over the user's OAuth token for
+// repositories the app is installed on and the user can read. A nil source
+// leaves only the OAuth lookup.
+func WithInstallationTokens(source analysis.InstallationTokenSource) Option {
+	return func(cfg *Config) {
+		cfg.InstallationTokens = source
+	}
+}
+
 // WithBatchSize sets the batch size for streaming analysis.
 // Zero or negative values are ignored and the default value is used.
 func WithBatchSize(size int) Option {
@@ -103,6 +236,10 @@ func NewAnalyzeUseCase(
 		AnalysisTimeout:     DefaultAnalysisTimeout,
 		BatchSize:           DefaultAnalysisBatchSize,
 		MaxConcurrentClones: DefaultMaxConcurrentClones,
+		MaxRepoSizeBytes:    DefaultMaxRepoSizeBytes,
+		MinHelperReferences: analysis.DefaultMinHelperReferences,
+		MinTestVariants:     analysis.DefaultMinTestVariants,
+		UnmatchedSamples:    analysis.DefaultUnmatchedSamples,
 	}
 
 	for _, opt := range opts {
@@ -110,16 +247,35 @@ func NewAnalyzeUseCase(
 	}
 
 	uc := &AnalyzeUseCase{
-		batchSize:     cfg.BatchSize,
-		cloneSem:      semaphore.NewWeighted(cfg.MaxConcurrentClones),
-		codebaseRepo:  codebaseRepo,
-		parser:        parser,
-		parserVersion: cfg.ParserVersion,
-		repository:    repository,
-		timeout:       cfg.AnalysisTimeout,
-		tokenLookup:   tokenLookup,
-		vcs:           vcs,
-		vcsAPIClient:  vcsAPIClient,
+		batchSize:        cfg.BatchSize,
+		cloneSem:         semaphore.NewWeighted(cfg.MaxConcurrentClones),
+		codebaseRepo:     codebaseRepo,
+		installTokens:    cfg.InstallationTokens,
+		maxRepoSize:      cfg.MaxRepoSizeBytes,
+		minHelperRefs:    cfg.MinHelperReferences,
+		minVariants:      cfg.MinTestVariants,
+		parser:           parser,
+		parserVersion:    cfg.ParserVersion,
+		region:           cfg.Region,
+		repository:       repository,
+		snippetLines:     cfg.UnmatchedSnippetLines,
+		timeout:          cfg.AnalysisTimeout,
+		tokenLookup:      tokenLookup,
+		unmatchedSamples: cfg.UnmatchedSamples,
+		vcs:              vcs,
+		vcsAPIClient:     vcsAPIClient,
+	}
+
+	uc.hosts = map[string]bool{DefaultHost: true}
+	for _, host := range cfg.EnterpriseHosts {
+		uc.hosts[host] = true
+	}
+	for host, n := range cfg.HostCloneLimits {
+		uc.hosts[host] = true
+		if uc.hostCloneSems == nil {
+			uc.hostCloneSems = make(map[string]*semaphore.Weighted)
+		}
+		uc.hostCloneSems[host] = semaphore.NewWeighted(cmp.Or(n, cfg.MaxConcurrentClones))
 	}
 
 	if streamingParser, ok := parser.(analysis.StreamingParser); ok {
@@ -128,6 +284,45 @@ func NewAnalyzeUseCase(
 	if streamingRepo, ok := repository.(analysis.StreamingRepository); ok {
 		uc.streamingRepo = streamingRepo
 	}
+	if filteringParser, ok := parser.(analysis.FilteringParser); ok {
+		uc.filteringParser = filteringParser
+	}
+	if lister, ok := parser.(analysis.SourceFileLister); ok {
+		uc.sourceLister = lister
+	}
+	if sourceFileRepo, ok := repository.(analysis.SourceFileRepository); ok {
+		uc.sourceFileRepo = sourceFileRepo
+	}
+	if curationRepo, ok := repository.(analysis.CurationRulesRepository); ok {
+		uc.curationRepo = curationRepo
+	}
+	if progressRepo, ok := repository.(analysis.ProgressRepository); ok {
+		uc.progressRepo = progressRepo
+	}
+	if pathFiltersRepo, ok := repository.(analysis.PathFiltersRepository); ok {
+		uc.pathFiltersRepo = pathFiltersRepo
+	}
+	if incrementalParser, ok := parser.(analysis.IncrementalParser); ok {
+		uc.incrementalParser = incrementalParser
+	}
+	if fileReuseRepo, ok := repository.(analysis.FileReuseRepository); ok {
+		uc.fileReuseRepo = fileReuseRepo
+	}
+	if forkReuseRepo, ok := repository.(analysis.ForkReuseRepository); ok {
+		uc.forkReuseRepo = forkReuseRepo
+	}
+	if parseErrorRepo, ok := repository.(analysis.ParseErrorRepository); ok {
+		uc.parseErrorRepo = parseErrorRepo
+	}
+	if languageRepo, ok := repository.(analysis.LanguageStatsRepository); ok {
+		uc.languageRepo = languageRepo
+	}
+	if unmatchedRepo, ok := repository.(analysis.UnmatchedSampleRepository); ok {
+		uc.unmatchedRepo = unmatchedRepo
+	}
+	if helperRepo, ok := repository.(analysis.SharedHelperRepository); ok {
+		uc.helperRepo = helperRepo
+	}
 
 	return uc
 }
@@ -137,79 +332,489 @@ func (uc *AnalyzeUseCase) Execute(ctx context.Context, req analysis.AnalyzeReque
 		return err
 	}
 
+	ctx, span := tracer.Start(ctx, "analysis.Execute", trace.WithAttributes(
+		attribute.String("vcs.branch", req.Branch),
+		attribute.String("vcs.owner", req.Owner),
+		attribute.String("vcs.repo", req.Repo),
+	))
+	defer func() { endSpan(span, err) }()
+
 	timeoutCtx, cancel := context.WithTimeout(ctx, uc.timeout)
 	defer cancel()
 
-	repoURL := fmt.Sprintf("https://github.com/%s/%s", req.Owner, req.Repo)
+	host := requestHost(req)
+	if !uc.hosts[host] {
+		return fmt.Errorf("%w: host %q is not configured", analysis.ErrInvalidInput, host)
+	}
+	repoURL := fmt.Sprintf("https://%s/%s/%s", host, req.Owner, req.Repo)
 
-	token, err := uc.lookupToken(timeoutCtx, req.UserID)
+	progress := &progressReporter{repo: uc.progressRepo, req: req}
+	defer func() {
+		if err != nil {
+			progress.report(context.Background(), analysis.StageFailed, 0, 0)
+		}
+	}()
+
+	token, err := uc.lookupToken(timeoutCtx, host, req.Owner, req.Repo, req.UserID)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrTokenLookupFailed, err)
 	}
 
-	commitInfo, err := uc.vcs.GetHeadCommit(timeoutCtx, repoURL, token)
+	analysisID := uc.startAnalysisRecord(timeoutCtx, req, host, token)
+	progress.analysisID = analysisID
+	defer func() {
+		if err != nil && analysisID != analysis.NilUUID {
+			class := ClassifyFailure(err)
+			if recordErr := uc.repository.RecordFailure(context.Background(), analysisID, class, err.Error()); recordErr != nil {
+				slog.ErrorContext(context.Background(), "failed to record analysis failure",
+					"error", recordErr,
+					"analysis_id", analysisID,
+					"failure_class", class,
+					"original_error", err,
+				)
+			}
+		}
+	}()
+
+	headCtx, headSpan := tracer.Start(timeoutCtx, "analysis.head_commit")
+	commitInfo, err := uc.vcs.GetHeadCommit(headCtx, repoURL, token, req.Branch)
+	endSpan(headSpan, err)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrHeadCommitFailed, err)
 	}
 
-	src, err := uc.cloneWithSemaphore(timeoutCtx, repoURL, token)
+	if err = uc.checkRepoSize(timeoutCtx, req, token); err != nil {
+		return err
+	}
+
+	progress.report(timeoutCtx, analysis.StageCloneStarted, 0, 0)
+	cloneCtx, cloneSpan := tracer.Start(timeoutCtx, "analysis.clone")
+	stopClone := sla.StartPhase(timeoutCtx, sla.PhaseClone)
+	src, err := uc.cloneWithSemaphore(cloneCtx, host, repoURL, token, req.Branch)
+	stopClone()
+	endSpan(cloneSpan, err)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrCloneFailed, err)
 	}
 	defer uc.closeSource(src, req.Owner, req.Repo)
+	progress.report(timeoutCtx, analysis.StageCloneCompleted, 0, 0)
+	span.SetAttributes(attribute.String("vcs.commit_sha", src.CommitSHA()))
 
-	codebase, err := uc.resolveCodebase(timeoutCtx, req, src, token, commitInfo.IsPrivate)
+	resolveCtx, resolveSpan := tracer.Start(timeoutCtx, "analysis.resolve_codebase")
+	codebase, err := uc.resolveCodebase(resolveCtx, req, src, token, commitInfo.IsPrivate)
+	endSpan(resolveSpan, err)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrCodebaseResolutionFailed, err)
 	}
 
-	createParams := analysis.CreateAnalysisRecordParams{
-		Branch:         src.Branch(),
-		CodebaseID:     &codebase.ID,
-		CommitSHA:      src.CommitSHA(),
-		ExternalRepoID: codebase.ExternalRepoID,
-		Owner:          codebase.Owner,
-		ParserVersion:  uc.parserVersion,
-		Repo:           codebase.Name,
+	filters, err := uc.resolvePathFilters(timeoutCtx, req, codebase.ID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCodebaseResolutionFailed, err)
 	}
-	if err = createParams.Validate(); err != nil {
-		return fmt.Errorf("%w: %w", ErrSaveFailed, err)
+
+	if analysisID == analysis.NilUUID {
+		createParams := analysis.CreateAnalysisRecordParams{
+			Branch:         src.Branch(),
+			CodebaseID:     &codebase.ID,
+			CommitSHA:      src.CommitSHA(),
+			ExternalRepoID: codebase.ExternalRepoID,
+			Owner:          codebase.Owner,
+			ParserVersion:  uc.parserVersion,
+			PathFilters:    filters,
+			Repo:           codebase.Name,
+		}
+		if err = createParams.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", ErrSaveFailed, err)
+		}
+
+		analysisID, err = uc.repository.CreateAnalysisRecord(timeoutCtx, createParams)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrSaveFailed, err)
+		}
+		progress.analysisID = analysisID
+	} else {
+		err = uc.repository.UpdateAnalysisSource(timeoutCtx, analysis.UpdateAnalysisSourceParams{
+			AnalysisID:  analysisID,
+			Branch:      src.Branch(),
+			CodebaseID:  codebase.ID,
+			CommitSHA:   src.CommitSHA(),
+			PathFilters: filters,
+		})
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrSaveFailed, err)
+		}
+		if visErr := uc.codebaseRepo.UpdateVisibility(timeoutCtx, codebase.ID, commitInfo.IsPrivate); visErr != nil {
+			slog.WarnContext(timeoutCtx, "failed to update visibility (non-critical)",
+				"error", visErr,
+				"codebase_id", codebase.ID,
+				"is_private", commitInfo.IsPrivate,
+			)
+		}
 	}
 
-	analysisID, err := uc.repository.CreateAnalysisRecord(timeoutCtx, createParams)
+	rules := uc.loadCurationRules(timeoutCtx, src, analysisID)
+	projects := newProjectDetector(timeoutCtx, src, analysisID)
+	reuse := uc.loadReusableFiles(timeoutCtx, codebase.ID, src.CommitSHA(), analysisID)
+
+	parseCtx, parseSpan := tracer.Start(timeoutCtx, "analysis.parse", trace.WithAttributes(
+		attribute.Bool("analysis.streaming", uc.canUseStreaming()),
+	))
+	var outcome *parseOutcome
+	stopScan := sla.StartPhase(parseCtx, sla.PhaseScan)
+	if uc.canUseStreaming() {
+		outcome, err = uc.executeStreaming(parseCtx, src, analysisID, req.UserID, filters, rules, projects, reuse, progress)
+	} else {
+		outcome, err = uc.executeBatch(parseCtx, src, analysisID, req, filters, rules, projects, reuse, progress)
+	}
+	stopScan()
+	endSpan(parseSpan, err)
 	if err != nil {
-		return fmt.Errorf("%w: %w", ErrSaveFailed, err)
+		return err
+	}
+	uc.quarantineFiles(timeoutCtx, analysisID, outcome.quarantined)
+	uc.recordFingerprint(timeoutCtx, analysisID, outcome, reuse)
+	uc.recordLanguages(timeoutCtx, analysisID, outcome.languages.Stats())
+	uc.recordUnmatchedSamples(timeoutCtx, src, analysisID, outcome.unmatched.Paths())
+	if n := projects.count(); n > 0 {
+		slog.InfoContext(timeoutCtx, "monorepo projects detected",
+			"analysis_id", analysisID,
+			"projects", n,
+		)
 	}
 
-	defer func() {
+	sourceFiles := uc.recordSourceFiles(timeoutCtx, src, analysisID, filters)
+	uc.recordSharedHelpers(timeoutCtx, src, analysisID, outcome.testFiles, sourceFiles)
+	progress.report(timeoutCtx, analysis.StageCompleted, 0, 0)
+	return nil
+}
+
+// startAnalysisRecord records the analysis as running before the HEAD lookup,
+// size check and clone, so their failures are persisted with a failure class.
+// The record starts on the codebase of the requested name, created through the
+// VCS API as private when none exists yet, at the requested commit; Execute
+// points it at the codebase and commit the clone resolved. A record that cannot
+// be started is non-critical and returns NilUUID: Execute then creates it after
+// the clone.
+func (uc *AnalyzeUseCase) startAnalysisRecord(ctx context.Context, req analysis.AnalyzeRequest, host string, token *string) analysis.UUID {
+	codebase, err := uc.codebaseRepo.FindWithLastCommit(ctx, host, req.Owner, req.Repo)
+	if errors.Is(err, analysis.ErrCodebaseNotFound) {
+		codebase, err = uc.resolveCodebaseWithAPI(ctx, host, req, nil, token, true)
+	}
+	if err == nil {
+		var analysisID analysis.UUID
+		analysisID, err = uc.repository.CreateAnalysisRecord(ctx, analysis.CreateAnalysisRecordParams{
+			Branch:         req.Branch,
+			CodebaseID:     &codebase.ID,
+			CommitSHA:      req.CommitSHA,
+			ExternalRepoID: codebase.ExternalRepoID,
+			Owner:          codebase.Owner,
+			ParserVersion:  uc.parserVersion,
+			Repo:           codebase.Name,
+		})
+		if err == nil {
+			return analysisID
+		}
+	}
+	slog.WarnContext(ctx, "failed to start analysis record before clone (non-critical)",
+		"owner", req.Owner,
+		"repo", req.Repo,
+		"error", err,
+	)
+	return analysis.NilUUID
+}
+
+// checkRepoSize fails fast with analysis.ErrRepoTooLarge when the VCS API reports
+// the repository above the size limit, before any clone time is spent. The reported
+// size covers the full history, so it overestimates a shallow clone. Failing to fetch
+// it is non-critical: the workspace quota still bounds the clone itself.
+func (uc *AnalyzeUseCase) checkRepoSize(ctx context.Context, req analysis.AnalyzeRequest, token *string) error {
+	stats, err := uc.vcsAPIClient.GetRepoStats(ctx, requestHost(req), req.Owner, req.Repo, token)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get repository stats (non-critical)",
+			"owner", req.Owner,
+			"repo", req.Repo,
+			"error", err,
+		)
+		return nil
+	}
+
+	if stats.SizeBytes > uc.maxRepoSize {
+		return fmt.Errorf("%w: %s/%s is %d MB, limit is %d MB",
+			analysis.ErrRepoTooLarge, req.Owner, req.Repo, stats.SizeBytes>>20, uc.maxRepoSize>>20)
+	}
+
+	slog.InfoContext(ctx, "repository stats",
+		"owner", req.Owner,
+		"repo", req.Repo,
+		"size_mb", stats.SizeBytes>>20,
+		"language", stats.Language,
+	)
+	return nil
+}
+
+// loadCurationRules reads the repository rules file and stores it with the analysis
+// so spec generation applies the same rules. A missing or invalid file is non-critical:
+// analysis proceeds without rules rather than failing on a user typo.
+func (uc *AnalyzeUseCase) loadCurationRules(ctx context.Context, src analysis.Source, analysisID analysis.UUID) *curation.Rules {
+	reader, ok := src.(analysis.FileReader)
+	if !ok {
+		return nil
+	}
+
+	for _, name := range curation.FileNames {
+		data, err := reader.ReadFile(ctx, name, maxCurationRulesBytes)
 		if err != nil {
-			if recordErr := uc.repository.RecordFailure(context.Background(), analysisID, err.Error()); recordErr != nil {
-				slog.ErrorContext(context.Background(), "failed to record analysis failure",
-					"error", recordErr,
+			if !errors.Is(err, fs.ErrNotExist) {
+				slog.WarnContext(ctx, "failed to read curation rules (non-critical)",
 					"analysis_id", analysisID,
-					"original_error", err,
+					"file", name,
+					"error", err,
 				)
 			}
+			continue
 		}
-	}()
 
-	if uc.canUseStreaming() {
-		return uc.executeStreaming(timeoutCtx, src, analysisID, req.UserID)
+		rules, err := curation.Parse(data)
+		if err != nil {
+			slog.WarnContext(ctx, "ignoring invalid curation rules (non-critical)",
+				"analysis_id", analysisID,
+				"file", name,
+				"error", err,
+			)
+			return nil
+		}
+
+		if uc.curationRepo != nil {
+			if err := uc.curationRepo.SaveCurationRules(ctx, analysisID, rules); err != nil {
+				slog.WarnContext(ctx, "failed to save curation rules (non-critical)",
+					"analysis_id", analysisID,
+					"error", err,
+				)
+			}
+		}
+
+		slog.InfoContext(ctx, "curation rules loaded",
+			"analysis_id", analysisID,
+			"file", name,
+		)
+		return rules
+	}
+
+	return nil
+}
+
+// resolvePathFilters returns the path filters of the request or, when it carries
+// none, the filters the codebase was last analyzed with.
+func (uc *AnalyzeUseCase) resolvePathFilters(ctx context.Context, req analysis.AnalyzeRequest, codebaseID analysis.UUID) (*analysis.PathFilters, error) {
+	filters := req.PathFilters
+	if filters == nil && uc.pathFiltersRepo != nil {
+		var err error
+		filters, err = uc.pathFiltersRepo.GetLatestPathFilters(ctx, codebaseID)
+		if err != nil {
+			return nil, fmt.Errorf("load path filters: %w", err)
+		}
+	}
+	if filters.IsEmpty() {
+		return nil, nil
+	}
+
+	slog.InfoContext(ctx, "path filters applied",
+		"owner", req.Owner,
+		"repo", req.Repo,
+		"include", filters.Include,
+		"exclude", filters.Exclude,
+		"reused", req.PathFilters == nil,
+	)
+	return filters, nil
+}
+
+// isExcluded reports whether the test file at path is left out of the analysis
+// by the path filters or the curation rules.
+func isExcluded(path string, filters *analysis.PathFilters, rules *curation.Rules) bool {
+	return !filters.Matches(path) || rules.ExcludesFile(path)
+}
+
+// filterExcludedFiles drops test files excluded by the path filters or the curation rules.
+func filterExcludedFiles(files []analysis.TestFile, filters *analysis.PathFilters, rules *curation.Rules) []analysis.TestFile {
+	if filters.IsEmpty() && rules.IsEmpty() {
+		return files
+	}
+	kept := make([]analysis.TestFile, 0, len(files))
+	for _, f := range files {
+		if !isExcluded(f.Path, filters, rules) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// recordSourceFiles stores the repository file inventory for spec gap analysis,
+// limited to the paths the analysis covers, and returns it.
+// Failure is non-critical: the analysis is already complete and gap reports are optional.
+func (uc *AnalyzeUseCase) recordSourceFiles(ctx context.Context, src analysis.Source, analysisID analysis.UUID, filters *analysis.PathFilters) []string {
+	if uc.sourceLister == nil || uc.sourceFileRepo == nil {
+		return nil
 	}
 
-	return uc.executeBatch(timeoutCtx, src, analysisID, req)
+	paths, err := uc.sourceLister.ListSourceFiles(ctx, src)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to list source files (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+		return nil
+	}
+	if !filters.IsEmpty() {
+		paths = slices.DeleteFunc(paths, func(p string) bool { return !filters.Matches(p) })
+	}
+
+	if err := uc.sourceFileRepo.SaveSourceFiles(ctx, analysisID, paths); err != nil {
+		slog.WarnContext(ctx, "failed to save source files (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+	}
+	return paths
+}
+
+// parseOutcome is what a parse run reports besides the test files it saved.
+type parseOutcome struct {
+	hashes      analysis.FileHashes
+	languages   analysis.LanguageTally
+	quarantined []analysis.ParseError
+	testFiles   []string
+	unhashed    int
+	unmatched   *analysis.UnmatchedSampler
+}
+
+// addFile counts a saved test file, reused ones included.
+func (o *parseOutcome) addFile(f analysis.TestFile) {
+	o.languages.AddFile(f)
+	o.testFiles = append(o.testFiles, f.Path)
+	if f.ContentHash == nil {
+		o.unhashed++
+		return
+	}
+	o.hashes[f.Path] = f.ContentHash
+}
+
+func (uc *AnalyzeUseCase) newParseOutcome() *parseOutcome {
+	return &parseOutcome{
+		hashes:    make(analysis.FileHashes),
+		languages: make(analysis.LanguageTally),
+		unmatched: analysis.NewUnmatchedSampler(uc.unmatchedSamples),
+	}
+}
+
+// recordLanguages stores the language distribution of the analysis' test files.
+// Failure is non-critical: the distribution only feeds parser coverage reports.
+func (uc *AnalyzeUseCase) recordLanguages(ctx context.Context, analysisID analysis.UUID, stats []analysis.LanguageStats) {
+	if uc.languageRepo == nil || len(stats) == 0 {
+		return
+	}
+	if err := uc.languageRepo.SaveLanguageStats(ctx, analysisID, stats); err != nil {
+		slog.WarnContext(ctx, "failed to save language stats (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+	}
+}
+
+// recordUnmatchedSamples stores the sampled test file candidates no parser
+// strategy matched, with a redacted snippet of each when enabled. Failure is
+// non-critical: the samples only guide which frameworks to support next.
+func (uc *AnalyzeUseCase) recordUnmatchedSamples(ctx context.Context, src analysis.Source, analysisID analysis.UUID, paths []string) {
+	if uc.unmatchedRepo == nil || len(paths) == 0 {
+		return
+	}
+
+	reader, _ := src.(analysis.FileReader)
+	samples := make([]analysis.UnmatchedSample, 0, len(paths))
+	for _, p := range paths {
+		sample := analysis.UnmatchedSample{FilePath: p, Language: analysis.LanguageOf(p)}
+		if uc.snippetLines > 0 && reader != nil {
+			content, err := reader.ReadFile(ctx, p, maxUnmatchedSnippetBytes)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to read unmatched test file (non-critical)",
+					"analysis_id", analysisID,
+					"file", p,
+					"error", err,
+				)
+			} else {
+				sample.Snippet = analysis.RedactSnippet(content, uc.snippetLines)
+			}
+		}
+		samples = append(samples, sample)
+	}
+
+	if err := uc.unmatchedRepo.SaveUnmatchedSamples(ctx, analysisID, samples); err != nil {
+		slog.WarnContext(ctx, "failed to save unmatched file samples (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+	}
+}
+
+// quarantineFiles records the test files skipped because they failed to parse,
+// marking the analysis a partial success. Failure is non-critical: the analysis
+// is already saved without them, and the parse errors are logged here.
+func (uc *AnalyzeUseCase) quarantineFiles(ctx context.Context, analysisID analysis.UUID, errs []analysis.ParseError) {
+	if len(errs) == 0 {
+		return
+	}
+
+	panicked := 0
+	for _, e := range errs {
+		if e.Panicked {
+			panicked++
+		}
+	}
+	slog.WarnContext(ctx, "test files quarantined after parse failures",
+		"analysis_id", analysisID,
+		"files", len(errs),
+		"panicked", panicked,
+		"first_file", errs[0].FilePath,
+		"first_error", errs[0].Message,
+	)
+
+	if uc.parseErrorRepo == nil {
+		return
+	}
+	if err := uc.parseErrorRepo.QuarantineFiles(ctx, analysisID, errs); err != nil {
+		slog.WarnContext(ctx, "failed to record quarantined files (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+	}
 }
 
 // executeBatch performs traditional batch analysis (full memory loading).
+// It returns the parse errors of the files left out of the analysis and the
+// language distribution of the test files.
 func (uc *AnalyzeUseCase) executeBatch(
 	ctx context.Context,
 	src analysis.Source,
 	analysisID analysis.UUID,
 	req analysis.AnalyzeRequest,
-) error {
-	inventory, err := uc.parser.Scan(ctx, src)
+	filters *analysis.PathFilters,
+	rules *curation.Rules,
+	projects *projectDetector,
+	reuse *reusableFiles,
+	progress *progressReporter,
+) (*parseOutcome, error) {
+	progress.report(ctx, analysis.StageScanStarted, 0, 0)
+	var inventory *analysis.Inventory
+	var err error
+	switch {
+	case reuse != nil:
+		inventory, err = uc.incrementalParser.ScanIncremental(ctx, src, filters, reuse.hashes)
+	case uc.filteringParser != nil && !filters.IsEmpty():
+		inventory, err = uc.filteringParser.ScanFiltered(ctx, src, filters)
+	default:
+		inventory, err = uc.parser.Scan(ctx, src)
+	}
 	if err != nil {
-		return fmt.Errorf("%w: %w", ErrScanFailed, err)
+		return nil, fmt.Errorf("%w: %w", ErrScanFailed, err)
 	}
 
 	if inventory == nil {
@@ -220,6 +825,27 @@ func (uc *AnalyzeUseCase) executeBatch(
 		)
 		inventory = &analysis.Inventory{Files: []analysis.TestFile{}}
 	}
+	inventory.Files = filterExcludedFiles(inventory.Files, filters, rules)
+	inventory.ParseErrors = slices.DeleteFunc(inventory.ParseErrors, func(e analysis.ParseError) bool {
+		return isExcluded(e.FilePath, filters, rules)
+	})
+	for i := range inventory.Files {
+		analysis.CollapseTestVariants(&inventory.Files[i], uc.minVariants)
+		projects.assign(ctx, &inventory.Files[i])
+	}
+	if inventory.Files, err = reuse.resolve(ctx, inventory.Files); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSaveFailed, err)
+	}
+	outcome := uc.newParseOutcome()
+	outcome.quarantined = inventory.ParseErrors
+	for _, f := range inventory.Files {
+		outcome.addFile(f)
+	}
+	for _, e := range inventory.ParseErrors {
+		outcome.languages.AddFailed(e.FilePath)
+	}
+	outcome.languages.AddUnmatched("", inventory.UnmatchedFiles)
+	progress.report(ctx, analysis.StageSaving, len(inventory.Files), len(inventory.Files))
 
 	saveParams := analysis.SaveAnalysisInventoryParams{
 		AnalysisID:  analysisID,
@@ -228,14 +854,17 @@ func (uc *AnalyzeUseCase) executeBatch(
 		UserID:      req.UserID,
 	}
 	if err = saveParams.Validate(); err != nil {
-		return fmt.Errorf("%w: %w", ErrSaveFailed, err)
+		return nil, fmt.Errorf("%w: %w", ErrSaveFailed, err)
 	}
 
-	if err = uc.repository.SaveAnalysisInventory(ctx, saveParams); err != nil {
-		return fmt.Errorf("%w: %w", ErrSaveFailed, err)
+	stopSave := sla.StartPhase(ctx, sla.PhaseSave)
+	err = uc.repository.SaveAnalysisInventory(ctx, saveParams)
+	stopSave()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSaveFailed, err)
 	}
 
-	return nil
+	return outcome, nil
 }
 
 // resolveCodebase determines which codebase to use for the analysis request.
@@ -253,7 +882,7 @@ func (uc *AnalyzeUseCase) resolveCodebase(
 	token *string,
 	isPrivate bool,
 ) (*analysis.Codebase, error) {
-	host := DefaultHost
+	host := requestHost(req)
 
 	codebase, err := uc.codebaseRepo.FindWithLastCommit(ctx, host, req.Owner, req.Repo)
 	if err != nil && !errors.Is(err, analysis.ErrCodebaseNotFound) {
@@ -373,6 +1002,7 @@ func (uc *AnalyzeUseCase) resolveCodebaseWithAPI(
 		Name:           req.Repo,
 		ExternalRepoID: externalRepoID,
 		IsPrivate:      isPrivate,
+		Region:         uc.region,
 	}
 
 	if codebaseByName != nil && codebaseByName.ExternalRepoID != externalRepoID {
@@ -404,43 +1034,74 @@ func (uc *AnalyzeUseCase) resolveCodebaseWithAPI(
 	return newCodebase, nil
 }
 
-func (uc *AnalyzeUseCase) cloneWithSemaphore(ctx context.Context, url string, token *string) (analysis.Source, error) {
-	if err := uc.cloneSem.Acquire(ctx, 1); err != nil {
+func (uc *AnalyzeUseCase) cloneWithSemaphore(ctx context.Context, host, url string, token *string, branch string) (analysis.Source, error) {
+	sem := uc.cloneSem
+	if hostSem, ok := uc.hostCloneSems[host]; ok {
+		sem = hostSem
+	}
+	if err := sem.Acquire(ctx, 1); err != nil {
 		return nil, err
 	}
-	defer uc.cloneSem.Release(1)
+	defer sem.Release(1)
 
-	return uc.vcs.Clone(ctx, url, token)
+	return uc.vcs.Clone(ctx, url, token, branch)
 }
 
-// lookupToken retrieves OAuth token for the given user.
+// lookupToken retrieves the token for cloning owner/repo. Jobs without a user
+// come from the app's webhooks or schedules and use its installation token.
+// A user's job uses the installation token only when the user's own OAuth
+// token can read the repository, so the app never opens a repository to a
+// user who could not read it; otherwise it uses the OAuth token. The app and
+// the OAuth provider both live on github.com, so repositories of other hosts
+// are always cloned without a token.
 //
 // Returns:
-//   - (nil, nil): no userID provided, tokenLookup not configured, or token not found (graceful degradation)
+//   - (nil, nil): host is not DefaultHost, no installation token for a job without a user, tokenLookup not configured, or token not found (graceful degradation)
 //   - (*token, nil): token found successfully
-//   - (nil, error): infrastructure error (should fail the operation)
+//   - (nil, error): infrastructure error of the OAuth lookup (should fail the operation)
 //
 // Token not found (analysis.ErrTokenNotFound) triggers graceful degradation and is logged at INFO level.
-// Infrastructure errors are returned to fail the operation.
-func (uc *AnalyzeUseCase) lookupToken(ctx context.Context, userID *string) (*string, error) {
-	if userID == nil || uc.tokenLookup == nil {
+// Installation token and access check failures never fail the operation: they are logged and the OAuth token is used.
+func (uc *AnalyzeUseCase) lookupToken(ctx context.Context, host, owner, repo string, userID *string) (*string, error) {
+	if host != DefaultHost {
 		return nil, nil
 	}
+	if userID == nil {
+		return uc.lookupInstallationToken(ctx, owner, repo), nil
+	}
 
-	token, err := uc.tokenLookup.GetOAuthToken(ctx, *userID, DefaultOAuthProvider)
+	token, err := uc.lookupOAuthToken(ctx, *userID)
+	if err != nil || token == nil {
+		return token, err
+	}
+	if uc.installTokens != nil && uc.canRead(ctx, host, owner, repo, token) {
+		if installToken := uc.lookupInstallationToken(ctx, owner, repo); installToken != nil {
+			return installToken, nil
+		}
+	}
+	return token, nil
+}
+
+// lookupOAuthToken returns the OAuth token of the user, or nil when there is none.
+func (uc *AnalyzeUseCase) lookupOAuthToken(ctx context.Context, userID string) (*string, error) {
+	if uc.tokenLookup == nil {
+		return nil, nil
+	}
+
+	token, err := uc.tokenLookup.GetOAuthToken(ctx, userID, DefaultOAuthProvider)
 	if err != nil {
 		if errors.Is(err, analysis.ErrTokenNotFound) {
 			slog.InfoContext(ctx, "no OAuth token found, using public access",
-				"user_id", *userID,
+				"user_id", userID,
 			)
 			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to lookup OAuth token for user %s: %w", *userID, err)
+		return nil, fmt.Errorf("failed to lookup OAuth token for user %s: %w", userID, err)
 	}
 
 	if token == "" {
 		slog.WarnContext(ctx, "empty token returned, using public access",
-			"user_id", *userID,
+			"user_id", userID,
 		)
 		return nil, nil
 	}
@@ -448,6 +1109,50 @@ func (uc *AnalyzeUseCase) lookupToken(ctx context.Context, userID *string) (*str
 	return &token, nil
 }
 
+// canRead reports whether token can read owner/repo. Failures other than the
+// repository not being found are logged and reported as no access.
+func (uc *AnalyzeUseCase) canRead(ctx context.Context, host, owner, repo string, token *string) bool {
+	_, err := uc.vcsAPIClient.GetRepoInfo(ctx, host, owner, repo, token)
+	if err != nil && !errors.Is(err, analysis.ErrRepoNotFound) {
+		slog.WarnContext(ctx, "failed to check repository access, using OAuth (non-critical)",
+			"owner", owner,
+			"repo", repo,
+			"error", err,
+		)
+	}
+	return err == nil
+}
+
+// lookupInstallationToken returns the app installation token of owner/repo,
+// or nil when no source is configured, the app is not installed, or minting
+// failed.
+func (uc *AnalyzeUseCase) lookupInstallationToken(ctx context.Context, owner, repo string) *string {
+	if uc.installTokens == nil {
+		return nil
+	}
+
+	token, err := uc.installTokens.GetInstallationToken(ctx, owner, repo)
+	if err != nil {
+		if !errors.Is(err, analysis.ErrAppNotInstalled) {
+			slog.WarnContext(ctx, "failed to mint installation token, falling back to OAuth (non-critical)",
+				"owner", owner,
+				"repo", repo,
+				"error", err,
+			)
+		}
+		return nil
+	}
+	if token == "" {
+		return nil
+	}
+	return &token
+}
+
+// requestHost returns the lowercase VCS host of req, DefaultHost when unset.
+func requestHost(req analysis.AnalyzeRequest) string {
+	return cmp.Or(strings.ToLower(req.Host), DefaultHost)
+}
+
 func (uc *AnalyzeUseCase) closeSource(src analysis.Source, owner, repo string) {
 	// Use background context for cleanup operations
 	ctx := context.Background()
@@ -467,50 +1172,95 @@ func (uc *AnalyzeUseCase) canUseStreaming() bool {
 }
 
 // executeStreaming performs streaming analysis with batch buffering.
-// Files are processed incrementally to minimize memory footprint.
+// Files are processed incrementally to minimize memory footprint. A file that
+// fails to parse is skipped; its parse error is returned with the others and
+// the language distribution of the test files.
 func (uc *AnalyzeUseCase) executeStreaming(
 	ctx context.Context,
 	src analysis.Source,
 	analysisID analysis.UUID,
 	userID *string,
-) error {
+	filters *analysis.PathFilters,
+	rules *curation.Rules,
+	projects *projectDetector,
+	reuse *reusableFiles,
+	progress *progressReporter,
+) (*parseOutcome, error) {
 	streamingStart := time.Now()
-
-	ch, err := uc.streamingParser.ScanStream(ctx, src)
+	progress.report(ctx, analysis.StageScanStarted, 0, 0)
+
+	var ch <-chan analysis.FileResult
+	var err error
+	switch {
+	case reuse != nil:
+		ch, err = uc.incrementalParser.ScanStreamIncremental(ctx, src, filters, reuse.hashes)
+	case uc.filteringParser != nil && !filters.IsEmpty():
+		ch, err = uc.filteringParser.ScanStreamFiltered(ctx, src, filters)
+	default:
+		ch, err = uc.streamingParser.ScanStream(ctx, src)
+	}
 	if err != nil {
-		return fmt.Errorf("%w: %w", ErrScanFailed, err)
+		return nil, fmt.Errorf("%w: %w", ErrScanFailed, err)
 	}
 
 	batch := make([]analysis.TestFile, 0, uc.batchSize)
-	var totalFiles, totalSuites, totalTests, chunkIndex int
+	var totalFiles, totalSuites, totalTests, reusedFiles, chunkIndex int
+	outcome := uc.newParseOutcome()
 
 	for result := range ch {
 		if err := ctx.Err(); err != nil {
-			return err
+			return nil, err
 		}
 
 		if result.Err != nil {
-			return fmt.Errorf("%w: %w", ErrScanFailed, result.Err)
+			if result.Path == "" || ctx.Err() != nil {
+				return nil, fmt.Errorf("%w: %w", ErrScanFailed, result.Err)
+			}
+			if !isExcluded(result.Path, filters, rules) {
+				outcome.quarantined = append(outcome.quarantined, analysis.NewParseError(result.Path, result.Err))
+				outcome.languages.AddFailed(result.Path)
+			}
+			continue
 		}
 
 		if result.File == nil {
+			if result.Path != "" && !isExcluded(result.Path, filters, rules) {
+				outcome.languages.AddUnmatched(result.Path, 1)
+				outcome.unmatched.Add(result.Path)
+			}
+			continue
+		}
+		if isExcluded(result.File.Path, filters, rules) {
 			continue
 		}
 
+		if result.File.Unchanged {
+			reusedFiles++
+		}
+		analysis.CollapseTestVariants(result.File, uc.minVariants)
+		projects.assign(ctx, result.File)
 		batch = append(batch, *result.File)
 
 		if len(batch) >= uc.batchSize {
 			chunkStart := time.Now()
+			if batch, err = reuse.resolve(ctx, batch); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrSaveFailed, err)
+			}
+			for _, f := range batch {
+				outcome.addFile(f)
+			}
 			batchParams := analysis.SaveAnalysisBatchParams{
 				AnalysisID: analysisID,
 				Files:      batch,
 			}
 			if err := batchParams.Validate(); err != nil {
-				return fmt.Errorf("%w: %w", ErrSaveFailed, err)
+				return nil, fmt.Errorf("%w: %w", ErrSaveFailed, err)
 			}
+			stopSave := sla.StartPhase(ctx, sla.PhaseSave)
 			stats, saveErr := uc.streamingRepo.SaveAnalysisBatch(ctx, batchParams)
+			stopSave()
 			if saveErr != nil {
-				return fmt.Errorf("%w: %w", ErrSaveFailed, saveErr)
+				return nil, fmt.Errorf("%w: %w", ErrSaveFailed, saveErr)
 			}
 			totalFiles += len(batch)
 			totalSuites += stats.SuitesProcessed
@@ -525,21 +1275,30 @@ func (uc *AnalyzeUseCase) executeStreaming(
 				"chunk_duration_ms", time.Since(chunkStart).Milliseconds(),
 			)
 			batch = batch[:0]
+			progress.report(ctx, analysis.StageScanProgress, totalFiles, 0)
 		}
 	}
 
 	if len(batch) > 0 {
 		chunkStart := time.Now()
+		if batch, err = reuse.resolve(ctx, batch); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSaveFailed, err)
+		}
+		for _, f := range batch {
+			outcome.addFile(f)
+		}
 		batchParams := analysis.SaveAnalysisBatchParams{
 			AnalysisID: analysisID,
 			Files:      batch,
 		}
 		if err := batchParams.Validate(); err != nil {
-			return fmt.Errorf("%w: %w", ErrSaveFailed, err)
+			return nil, fmt.Errorf("%w: %w", ErrSaveFailed, err)
 		}
+		stopSave := sla.StartPhase(ctx, sla.PhaseSave)
 		stats, saveErr := uc.streamingRepo.SaveAnalysisBatch(ctx, batchParams)
+		stopSave()
 		if saveErr != nil {
-			return fmt.Errorf("%w: %w", ErrSaveFailed, saveErr)
+			return nil, fmt.Errorf("%w: %w", ErrSaveFailed, saveErr)
 		}
 		totalFiles += len(batch)
 		totalSuites += stats.SuitesProcessed
@@ -555,6 +1314,8 @@ func (uc *AnalyzeUseCase) executeStreaming(
 		)
 	}
 
+	progress.report(ctx, analysis.StageSaving, totalFiles, totalFiles)
+
 	finalizeParams := analysis.FinalizeAnalysisParams{
 		AnalysisID:  analysisID,
 		CommittedAt: src.CommittedAt(),
@@ -563,10 +1324,13 @@ func (uc *AnalyzeUseCase) executeStreaming(
 		UserID:      userID,
 	}
 	if err := finalizeParams.Validate(); err != nil {
-		return fmt.Errorf("%w: %w", ErrSaveFailed, err)
+		return nil, fmt.Errorf("%w: %w", ErrSaveFailed, err)
 	}
-	if err := uc.streamingRepo.FinalizeAnalysis(ctx, finalizeParams); err != nil {
-		return fmt.Errorf("%w: %w", ErrSaveFailed, err)
+	stopSave := sla.StartPhase(ctx, sla.PhaseSave)
+	err = uc.streamingRepo.FinalizeAnalysis(ctx, finalizeParams)
+	stopSave()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSaveFailed, err)
 	}
 
 	slog.InfoContext(ctx, "streaming analysis completed",
@@ -575,8 +1339,10 @@ func (uc *AnalyzeUseCase) executeStreaming(
 		"total_files", totalFiles,
 		"total_suites", totalSuites,
 		"total_tests", totalTests,
+		"reused_files", reusedFiles,
+		"quarantined_files", len(outcome.quarantined),
 		"total_duration_ms", time.Since(streamingStart).Milliseconds(),
 	)
 
-	return nil
+	return outcome, nil
 }
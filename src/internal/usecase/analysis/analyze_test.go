@@ -1,12 +1,17 @@
 package analysis
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/curation"
 )
 
 const testParserVersion = "v1.0.0-test"
@@ -16,16 +21,18 @@ const testParserVersion = "v1.0.0-test"
 type mockVCS struct {
 	cloneFn         func(ctx context.Context, url string, token *string) (analysis.Source, error)
 	getHeadCommitFn func(ctx context.Context, url string, token *string) (analysis.CommitInfo, error)
+	clonedBranch    string
 }
 
-func (m *mockVCS) Clone(ctx context.Context, url string, token *string) (analysis.Source, error) {
+func (m *mockVCS) Clone(ctx context.Context, url string, token *string, branch string) (analysis.Source, error) {
+	m.clonedBranch = branch
 	if m.cloneFn != nil {
 		return m.cloneFn(ctx, url, token)
 	}
 	return nil, nil
 }
 
-func (m *mockVCS) GetHeadCommit(ctx context.Context, url string, token *string) (analysis.CommitInfo, error) {
+func (m *mockVCS) GetHeadCommit(ctx context.Context, url string, token *string, branch string) (analysis.CommitInfo, error) {
 	if m.getHeadCommitFn != nil {
 		return m.getHeadCommitFn(ctx, url, token)
 	}
@@ -98,8 +105,9 @@ func (m *mockStreamingParser) ScanStream(ctx context.Context, src analysis.Sourc
 
 type mockRepository struct {
 	createAnalysisRecordFn  func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error)
-	recordFailureFn         func(ctx context.Context, analysisID analysis.UUID, errMessage string) error
+	recordFailureFn         func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error
 	saveAnalysisInventoryFn func(ctx context.Context, params analysis.SaveAnalysisInventoryParams) error
+	updateAnalysisSourceFn  func(ctx context.Context, params analysis.UpdateAnalysisSourceParams) error
 }
 
 func (m *mockRepository) CreateAnalysisRecord(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
@@ -109,9 +117,9 @@ func (m *mockRepository) CreateAnalysisRecord(ctx context.Context, params analys
 	return analysis.NewUUID(), nil
 }
 
-func (m *mockRepository) RecordFailure(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+func (m *mockRepository) RecordFailure(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 	if m.recordFailureFn != nil {
-		return m.recordFailureFn(ctx, analysisID, errMessage)
+		return m.recordFailureFn(ctx, analysisID, class, errMessage)
 	}
 	return nil
 }
@@ -123,6 +131,13 @@ func (m *mockRepository) SaveAnalysisInventory(ctx context.Context, params analy
 	return nil
 }
 
+func (m *mockRepository) UpdateAnalysisSource(ctx context.Context, params analysis.UpdateAnalysisSourceParams) error {
+	if m.updateAnalysisSourceFn != nil {
+		return m.updateAnalysisSourceFn(ctx, params)
+	}
+	return nil
+}
+
 type mockStreamingRepository struct {
 	mockRepository
 	finalizeAnalysisFn  func(ctx context.Context, params analysis.FinalizeAnalysisParams) error
@@ -231,7 +246,8 @@ func (m *mockCodebaseRepository) Upsert(ctx context.Context, params analysis.Ups
 }
 
 type mockVCSAPIClient struct {
-	getRepoInfoFn func(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoInfo, error)
+	getRepoInfoFn  func(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoInfo, error)
+	getRepoStatsFn func(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoStats, error)
 }
 
 func (m *mockVCSAPIClient) GetRepoInfo(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoInfo, error) {
@@ -245,6 +261,13 @@ func (m *mockVCSAPIClient) GetRepoInfo(ctx context.Context, host, owner, repo st
 	}, nil
 }
 
+func (m *mockVCSAPIClient) GetRepoStats(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoStats, error) {
+	if m.getRepoStatsFn != nil {
+		return m.getRepoStatsFn(ctx, host, owner, repo, token)
+	}
+	return analysis.RepoStats{SizeBytes: 1 << 20}, nil
+}
+
 type mockTokenLookup struct {
 	getOAuthTokenFn func(ctx context.Context, userID string, provider string) (string, error)
 }
@@ -256,6 +279,17 @@ func (m *mockTokenLookup) GetOAuthToken(ctx context.Context, userID string, prov
 	return "", nil
 }
 
+type mockInstallationTokens struct {
+	getInstallationTokenFn func(ctx context.Context, owner, repo string) (string, error)
+}
+
+func (m *mockInstallationTokens) GetInstallationToken(ctx context.Context, owner, repo string) (string, error) {
+	if m.getInstallationTokenFn != nil {
+		return m.getInstallationTokenFn(ctx, owner, repo)
+	}
+	return "", analysis.ErrAppNotInstalled
+}
+
 // Mock helpers to reduce duplication
 
 func newSuccessfulSource() *mockSource {
@@ -407,11 +441,14 @@ func TestAnalyzeUseCase_Execute(t *testing.T) {
 					createAnalysisRecordFn: func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
 						return testAnalysisID, nil
 					},
-					recordFailureFn: func(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+					recordFailureFn: func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 						recordFailureCalled = true
 						if analysisID != testAnalysisID {
 							t.Errorf("RecordFailure called with wrong analysisID: got %v, want %v", analysisID, testAnalysisID)
 						}
+						if class != analysis.FailureUnknown {
+							t.Errorf("RecordFailure called with wrong class: got %q, want %q", class, analysis.FailureUnknown)
+						}
 						return nil
 					},
 				}
@@ -446,7 +483,7 @@ func TestAnalyzeUseCase_Execute(t *testing.T) {
 					createAnalysisRecordFn: func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
 						return testAnalysisID, nil
 					},
-					recordFailureFn: func(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+					recordFailureFn: func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 						recordFailureCalled = true
 						if analysisID != testAnalysisID {
 							t.Errorf("RecordFailure called with wrong analysisID: got %v, want %v", analysisID, testAnalysisID)
@@ -518,7 +555,7 @@ func TestAnalyzeUseCase_Execute(t *testing.T) {
 					createAnalysisRecordFn: func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
 						return testAnalysisID, nil
 					},
-					recordFailureFn: func(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+					recordFailureFn: func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 						recordFailureCalled = true
 						return errors.New("database connection lost")
 					},
@@ -609,6 +646,165 @@ func TestAnalyzeUseCase_Execute_Timeout(t *testing.T) {
 	})
 }
 
+func TestAnalyzeUseCase_Execute_RepoSizePreCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		statsFn     func(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoStats, error)
+		expectClone bool
+		expectedErr error
+	}{
+		{
+			name: "under the limit clones",
+			statsFn: func(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoStats, error) {
+				return analysis.RepoStats{SizeBytes: 10 << 20}, nil
+			},
+			expectClone: true,
+		},
+		{
+			name: "over the limit fails before cloning",
+			statsFn: func(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoStats, error) {
+				return analysis.RepoStats{SizeBytes: 200 << 20}, nil
+			},
+			expectedErr: analysis.ErrRepoTooLarge,
+		},
+		{
+			name: "stats failure is non-critical",
+			statsFn: func(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoStats, error) {
+				return analysis.RepoStats{}, errors.New("rate limited")
+			},
+			expectClone: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cloned := false
+			vcs := newSuccessfulVCS(newSuccessfulSource())
+			vcs.cloneFn = func(ctx context.Context, url string, token *string) (analysis.Source, error) {
+				cloned = true
+				return newSuccessfulSource(), nil
+			}
+			vcsAPI := newSuccessfulVCSAPIClient()
+			vcsAPI.getRepoStatsFn = tt.statsFn
+
+			uc := NewAnalyzeUseCase(newSuccessfulRepository(), newSuccessfulCodebaseRepository(), vcs, vcsAPI, newSuccessfulParser(), nil,
+				WithMaxRepoSize(100<<20), WithParserVersion(testParserVersion))
+			err := uc.Execute(context.Background(), newValidRequest())
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if cloned != tt.expectClone {
+				t.Errorf("cloned = %v, want %v", cloned, tt.expectClone)
+			}
+			if tt.expectedErr != nil && ClassifyFailure(err) != analysis.FailureRepoTooLarge {
+				t.Errorf("expected repo_too_large class, got %q", ClassifyFailure(err))
+			}
+		})
+	}
+}
+
+func TestAnalyzeUseCase_Execute_RecordsFailuresBeforeClone(t *testing.T) {
+	tests := []struct {
+		name      string
+		headErr   error
+		size      int64
+		cloneErr  error
+		wantClass analysis.FailureClass
+	}{
+		{
+			name:      "HEAD lookup timeout",
+			headErr:   context.DeadlineExceeded,
+			wantClass: analysis.FailureCloneTimeout,
+		},
+		{
+			name:      "repository too large",
+			size:      200 << 20,
+			wantClass: analysis.FailureRepoTooLarge,
+		},
+		{
+			name:      "clone authentication failure",
+			cloneErr:  analysis.ErrCloneAuthFailed,
+			wantClass: analysis.FailureCloneAuthFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var createdID, failedID analysis.UUID
+			var failedClass analysis.FailureClass
+			repo := newSuccessfulRepository()
+			repo.createAnalysisRecordFn = func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
+				if params.CommitSHA != newValidRequest().CommitSHA {
+					t.Errorf("record should start at the requested commit, got %q", params.CommitSHA)
+				}
+				createdID = analysis.NewUUID()
+				return createdID, nil
+			}
+			repo.recordFailureFn = func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
+				failedID, failedClass = analysisID, class
+				return nil
+			}
+
+			vcs := newSuccessfulVCS(newSuccessfulSource())
+			if tt.headErr != nil {
+				vcs.getHeadCommitFn = func(ctx context.Context, url string, token *string) (analysis.CommitInfo, error) {
+					return analysis.CommitInfo{}, tt.headErr
+				}
+			}
+			if tt.cloneErr != nil {
+				vcs.cloneFn = func(ctx context.Context, url string, token *string) (analysis.Source, error) {
+					return nil, tt.cloneErr
+				}
+			}
+			vcsAPI := newSuccessfulVCSAPIClient()
+			vcsAPI.getRepoStatsFn = func(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoStats, error) {
+				return analysis.RepoStats{SizeBytes: tt.size}, nil
+			}
+
+			uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), vcs, vcsAPI, newSuccessfulParser(), nil,
+				WithMaxRepoSize(100<<20), WithParserVersion(testParserVersion))
+			if err := uc.Execute(context.Background(), newValidRequest()); err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if createdID == analysis.NilUUID {
+				t.Fatal("analysis record should be created before the failure")
+			}
+			if failedID != createdID || failedClass != tt.wantClass {
+				t.Errorf("recorded failure %s/%q, want %s/%q", failedID, failedClass, createdID, tt.wantClass)
+			}
+		})
+	}
+
+	t.Run("successful clone points the record at the cloned commit", func(t *testing.T) {
+		var createdID analysis.UUID
+		var updated []analysis.UpdateAnalysisSourceParams
+		repo := newSuccessfulRepository()
+		repo.createAnalysisRecordFn = func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
+			if createdID != analysis.NilUUID {
+				t.Error("analysis record should be created once")
+			}
+			createdID = analysis.NewUUID()
+			return createdID, nil
+		}
+		repo.updateAnalysisSourceFn = func(ctx context.Context, params analysis.UpdateAnalysisSourceParams) error {
+			updated = append(updated, params)
+			return nil
+		}
+
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), newSuccessfulParser(), nil, WithParserVersion(testParserVersion))
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(updated) != 1 || updated[0].AnalysisID != createdID || updated[0].CommitSHA != "abc123" || updated[0].Branch != "main" {
+			t.Errorf("updated sources = %+v, want the cloned commit of %s", updated, createdID)
+		}
+	})
+}
+
 func TestAnalyzeUseCase_Options(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -696,6 +892,46 @@ func TestAnalyzeUseCase_MaxConcurrentClones(t *testing.T) {
 			t.Error("expected cloneSem to be initialized with default")
 		}
 	})
+
+	t.Run("host clone limit - slots of other hosts stay free", func(t *testing.T) {
+		var clonedURL, statsHost string
+		vcs := newSuccessfulVCS(newSuccessfulSource())
+		vcs.cloneFn = func(ctx context.Context, url string, token *string) (analysis.Source, error) {
+			clonedURL = url
+			return newSuccessfulSource(), nil
+		}
+		vcsAPI := newSuccessfulVCSAPIClient()
+		vcsAPI.getRepoStatsFn = func(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoStats, error) {
+			statsHost = host
+			return analysis.RepoStats{}, nil
+		}
+
+		uc := NewAnalyzeUseCase(newSuccessfulRepository(), newSuccessfulCodebaseRepository(), vcs, vcsAPI, newSuccessfulParser(), nil,
+			WithMaxConcurrentClones(1),
+			WithHostCloneLimit("GitHub.Example.com", 0),
+			WithParserVersion(testParserVersion),
+		)
+
+		// A stuck github.com clone holds the only shared slot.
+		if err := uc.cloneSem.Acquire(context.Background(), 1); err != nil {
+			t.Fatal(err)
+		}
+		defer uc.cloneSem.Release(1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		req := newValidRequest()
+		req.Host = "github.example.com"
+		if err := uc.Execute(ctx, req); err != nil {
+			t.Fatalf("analysis of a host with its own limit should not wait for github.com: %v", err)
+		}
+		if want := "https://github.example.com/" + req.Owner + "/" + req.Repo; clonedURL != want {
+			t.Errorf("cloned %q, want %q", clonedURL, want)
+		}
+		if statsHost != "github.example.com" {
+			t.Errorf("repository stats fetched from %q", statsHost)
+		}
+	})
 }
 
 func TestAnalyzeUseCase_SourceCleanup(t *testing.T) {
@@ -742,7 +978,7 @@ func TestAnalyzeUseCase_SourceCleanup(t *testing.T) {
 			createAnalysisRecordFn: func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
 				return testAnalysisID, nil
 			},
-			recordFailureFn: func(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+			recordFailureFn: func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 				return nil
 			},
 		}
@@ -1017,6 +1253,205 @@ func TestAnalyzeUseCase_TokenLookup(t *testing.T) {
 			t.Error("expected token to be nil when tokenLookup is nil")
 		}
 	})
+
+	t.Run("installation token preferred over OAuth", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			installFn func(ctx context.Context, owner, repo string) (string, error)
+			noOAuth   bool   // the user has no OAuth token
+			readErr   error  // error of reading the repository with the user's token
+			userID    string // empty for jobs without a user
+			want      string // empty for no token
+		}{
+			{
+				name: "app installed",
+				installFn: func(ctx context.Context, owner, repo string) (string, error) {
+					if owner != "testowner" || repo != "testrepo" {
+						t.Errorf("unexpected repository %s/%s", owner, repo)
+					}
+					return "ghs_install", nil
+				},
+				userID: "user-123",
+				want:   "ghs_install",
+			},
+			{
+				name: "app installed without user",
+				installFn: func(ctx context.Context, owner, repo string) (string, error) {
+					return "ghs_install", nil
+				},
+				want: "ghs_install",
+			},
+			{
+				name: "app not installed falls back to OAuth",
+				installFn: func(ctx context.Context, owner, repo string) (string, error) {
+					return "", analysis.ErrAppNotInstalled
+				},
+				userID: "user-123",
+				want:   "oauth-token",
+			},
+			{
+				name: "minting failure falls back to OAuth",
+				installFn: func(ctx context.Context, owner, repo string) (string, error) {
+					return "", errors.New("github unavailable")
+				},
+				userID: "user-123",
+				want:   "oauth-token",
+			},
+			{
+				name: "user without access keeps OAuth",
+				installFn: func(ctx context.Context, owner, repo string) (string, error) {
+					return "ghs_install", nil
+				},
+				readErr: analysis.ErrRepoNotFound,
+				userID:  "user-123",
+				want:    "oauth-token",
+			},
+			{
+				name: "failed access check keeps OAuth",
+				installFn: func(ctx context.Context, owner, repo string) (string, error) {
+					return "ghs_install", nil
+				},
+				readErr: errors.New("rate limited"),
+				userID:  "user-123",
+				want:    "oauth-token",
+			},
+			{
+				name: "user without OAuth token gets no installation token",
+				installFn: func(ctx context.Context, owner, repo string) (string, error) {
+					return "ghs_install", nil
+				},
+				noOAuth: true,
+				userID:  "user-123",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var capturedToken *string
+				src := newSuccessfulSource()
+				vcs := &mockVCS{
+					cloneFn: func(ctx context.Context, url string, token *string) (analysis.Source, error) {
+						capturedToken = token
+						return src, nil
+					},
+				}
+				tokenLookup := &mockTokenLookup{
+					getOAuthTokenFn: func(ctx context.Context, userID string, provider string) (string, error) {
+						if tt.noOAuth {
+							return "", analysis.ErrTokenNotFound
+						}
+						return "oauth-token", nil
+					},
+				}
+				vcsAPI := newSuccessfulVCSAPIClient()
+				accessChecked := false
+				vcsAPI.getRepoInfoFn = func(ctx context.Context, host, owner, repo string, token *string) (analysis.RepoInfo, error) {
+					// The first lookup with the user's token is the access check.
+					if !accessChecked && token != nil && *token == "oauth-token" {
+						accessChecked = true
+						if tt.readErr != nil {
+							return analysis.RepoInfo{}, tt.readErr
+						}
+					}
+					return (&mockVCSAPIClient{}).GetRepoInfo(ctx, host, owner, repo, token)
+				}
+
+				uc := NewAnalyzeUseCase(newSuccessfulRepository(), newSuccessfulCodebaseRepository(), vcs, vcsAPI, newSuccessfulParser(), tokenLookup,
+					WithParserVersion(testParserVersion),
+					WithInstallationTokens(&mockInstallationTokens{getInstallationTokenFn: tt.installFn}),
+				)
+
+				req := analysis.AnalyzeRequest{
+					Owner:     "testowner",
+					Repo:      "testrepo",
+					CommitSHA: "abc123",
+				}
+				if tt.userID != "" {
+					req.UserID = &tt.userID
+				}
+
+				err := uc.Execute(context.Background(), req)
+
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if tt.want == "" {
+					if capturedToken != nil {
+						t.Errorf("expected no token, got %q", *capturedToken)
+					}
+				} else if capturedToken == nil || *capturedToken != tt.want {
+					t.Errorf("expected token %q, got %v", tt.want, capturedToken)
+				}
+			})
+		}
+	})
+
+	t.Run("enterprise host - github.com tokens are never sent", func(t *testing.T) {
+		var headToken, cloneToken *string
+		vcs := newSuccessfulVCS(newSuccessfulSource())
+		vcs.getHeadCommitFn = func(ctx context.Context, url string, token *string) (analysis.CommitInfo, error) {
+			headToken = token
+			return analysis.CommitInfo{SHA: "abc123"}, nil
+		}
+		vcs.cloneFn = func(ctx context.Context, url string, token *string) (analysis.Source, error) {
+			cloneToken = token
+			return newSuccessfulSource(), nil
+		}
+		tokenLookup := &mockTokenLookup{
+			getOAuthTokenFn: func(ctx context.Context, userID string, provider string) (string, error) {
+				return "oauth-token", nil
+			},
+		}
+		installTokens := &mockInstallationTokens{getInstallationTokenFn: func(ctx context.Context, owner, repo string) (string, error) {
+			return "installation-token", nil
+		}}
+
+		uc := NewAnalyzeUseCase(newSuccessfulRepository(), newSuccessfulCodebaseRepository(), vcs, newSuccessfulVCSAPIClient(), newSuccessfulParser(), tokenLookup,
+			WithParserVersion(testParserVersion),
+			WithInstallationTokens(installTokens),
+			WithEnterpriseHosts("GitHub.Example.com"),
+		)
+
+		userID := "user-123"
+		req := newValidRequest()
+		req.Host = "github.example.com"
+		req.UserID = &userID
+		if err := uc.Execute(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if headToken != nil || cloneToken != nil {
+			t.Errorf("expected no token for %s, got head %v, clone %v", req.Host, headToken, cloneToken)
+		}
+	})
+
+	t.Run("unconfigured host - rejected before any lookup", func(t *testing.T) {
+		tokenLookupCalled := false
+		tokenLookup := &mockTokenLookup{
+			getOAuthTokenFn: func(ctx context.Context, userID string, provider string) (string, error) {
+				tokenLookupCalled = true
+				return "oauth-token", nil
+			},
+		}
+		vcs := newSuccessfulVCS(newSuccessfulSource())
+		vcs.getHeadCommitFn = func(ctx context.Context, url string, token *string) (analysis.CommitInfo, error) {
+			t.Errorf("HEAD of %s looked up", url)
+			return analysis.CommitInfo{}, nil
+		}
+
+		uc := NewAnalyzeUseCase(newSuccessfulRepository(), newSuccessfulCodebaseRepository(), vcs, newSuccessfulVCSAPIClient(), newSuccessfulParser(), tokenLookup,
+			WithParserVersion(testParserVersion),
+		)
+
+		userID := "user-123"
+		req := newValidRequest()
+		req.Host = "attacker.example.com"
+		req.UserID = &userID
+		if err := uc.Execute(context.Background(), req); !errors.Is(err, analysis.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+		if tokenLookupCalled {
+			t.Error("token looked up for an unconfigured host")
+		}
+	})
 }
 
 func TestResolveCodebase(t *testing.T) {
@@ -1500,7 +1935,7 @@ func TestAnalyzeUseCase_Streaming(t *testing.T) {
 				createAnalysisRecordFn: func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
 					return analysis.NewUUID(), nil
 				},
-				recordFailureFn: func(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+				recordFailureFn: func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 					recordFailureCalled = true
 					return nil
 				},
@@ -1538,7 +1973,7 @@ func TestAnalyzeUseCase_Streaming(t *testing.T) {
 				createAnalysisRecordFn: func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
 					return analysis.NewUUID(), nil
 				},
-				recordFailureFn: func(ctx context.Context, analysisID analysis.UUID, errMessage string) error {
+				recordFailureFn: func(ctx context.Context, analysisID analysis.UUID, class analysis.FailureClass, errMessage string) error {
 					return nil
 				},
 			},
@@ -1815,3 +2250,1072 @@ func TestAnalyzeUseCase_StreamingOptions(t *testing.T) {
 		}
 	})
 }
+
+type mockSourceFileParser struct {
+	mockParser
+	files   []string
+	listErr error
+}
+
+func (m *mockSourceFileParser) ListSourceFiles(ctx context.Context, src analysis.Source) ([]string, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	if m.files != nil {
+		return m.files, nil
+	}
+	return []string{"main.go", "pkg/auth/login.go"}, nil
+}
+
+type mockSourceFileRepository struct {
+	mockRepository
+	saved   []string
+	saveErr error
+}
+
+func (m *mockSourceFileRepository) SaveSourceFiles(ctx context.Context, analysisID analysis.UUID, paths []string) error {
+	m.saved = paths
+	return m.saveErr
+}
+
+func TestAnalyzeUseCase_SourceFiles(t *testing.T) {
+	newUseCase := func(parser analysis.Parser, repo analysis.Repository) *AnalyzeUseCase {
+		return NewAnalyzeUseCase(
+			repo,
+			newSuccessfulCodebaseRepository(),
+			newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(),
+			parser,
+			nil,
+			WithParserVersion("v1.0.0"),
+		)
+	}
+
+	t.Run("records source files after successful analysis", func(t *testing.T) {
+		parser := &mockSourceFileParser{mockParser: *newSuccessfulParser()}
+		repo := &mockSourceFileRepository{mockRepository: *newSuccessfulRepository()}
+
+		if err := newUseCase(parser, repo).Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.saved) != 2 {
+			t.Errorf("expected 2 saved source files, got %v", repo.saved)
+		}
+	})
+
+	t.Run("listing failure does not fail analysis", func(t *testing.T) {
+		parser := &mockSourceFileParser{mockParser: *newSuccessfulParser(), listErr: errors.New("walk failed")}
+		repo := &mockSourceFileRepository{mockRepository: *newSuccessfulRepository()}
+
+		if err := newUseCase(parser, repo).Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("expected analysis to succeed, got %v", err)
+		}
+		if repo.saved != nil {
+			t.Error("expected nothing to be saved")
+		}
+	})
+
+	t.Run("save failure does not fail analysis", func(t *testing.T) {
+		parser := &mockSourceFileParser{mockParser: *newSuccessfulParser()}
+		repo := &mockSourceFileRepository{mockRepository: *newSuccessfulRepository(), saveErr: errors.New("copy failed")}
+
+		if err := newUseCase(parser, repo).Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("expected analysis to succeed, got %v", err)
+		}
+	})
+
+	t.Run("skipped when analysis fails", func(t *testing.T) {
+		parser := &mockSourceFileParser{mockParser: mockParser{
+			scanFn: func(ctx context.Context, src analysis.Source) (*analysis.Inventory, error) {
+				return nil, errors.New("scan failed")
+			},
+		}}
+		repo := &mockSourceFileRepository{mockRepository: *newSuccessfulRepository()}
+
+		if err := newUseCase(parser, repo).Execute(context.Background(), newValidRequest()); err == nil {
+			t.Fatal("expected scan error")
+		}
+		if repo.saved != nil {
+			t.Error("expected source files not to be recorded for failed analysis")
+		}
+	})
+}
+
+type mockRulesSource struct {
+	mockSource
+	files map[string]string
+}
+
+func (m *mockRulesSource) ReadFile(ctx context.Context, path string, maxBytes int64) ([]byte, error) {
+	data, ok := m.files[path]
+	if !ok {
+		return nil, fmt.Errorf("open %s: %w", path, fs.ErrNotExist)
+	}
+	return []byte(data), nil
+}
+
+type mockCurationRulesRepository struct {
+	mockRepository
+	saved *curation.Rules
+}
+
+func (m *mockCurationRulesRepository) SaveCurationRules(ctx context.Context, analysisID analysis.UUID, rules *curation.Rules) error {
+	m.saved = rules
+	return nil
+}
+
+func TestAnalyzeUseCase_CurationRules(t *testing.T) {
+	inventoryParser := func() *mockParser {
+		return &mockParser{
+			scanFn: func(ctx context.Context, src analysis.Source) (*analysis.Inventory, error) {
+				return &analysis.Inventory{Files: []analysis.TestFile{
+					{Path: "src/auth.test.ts"},
+					{Path: "vendor/lib.test.ts"},
+				}}, nil
+			},
+		}
+	}
+
+	run := func(t *testing.T, files map[string]string) (*mockCurationRulesRepository, []analysis.TestFile) {
+		t.Helper()
+		var saved []analysis.TestFile
+		repo := &mockCurationRulesRepository{mockRepository: *newSuccessfulRepository()}
+		repo.saveAnalysisInventoryFn = func(ctx context.Context, params analysis.SaveAnalysisInventoryParams) error {
+			saved = params.Inventory.Files
+			return nil
+		}
+		uc := NewAnalyzeUseCase(
+			repo,
+			newSuccessfulCodebaseRepository(),
+			newSuccessfulVCS(&mockRulesSource{mockSource: *newSuccessfulSource(), files: files}),
+			newSuccessfulVCSAPIClient(),
+			inventoryParser(),
+			nil,
+			WithParserVersion("v1.0.0"),
+		)
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return repo, saved
+	}
+
+	t.Run("excludes files and stores rules", func(t *testing.T) {
+		repo, saved := run(t, map[string]string{".specvital.yml": "exclude:\n  - vendor/**\n"})
+
+		if len(saved) != 1 || saved[0].Path != "src/auth.test.ts" {
+			t.Errorf("expected vendor file to be excluded, got %+v", saved)
+		}
+		if repo.saved == nil || len(repo.saved.Exclude) != 1 {
+			t.Errorf("expected rules to be saved, got %+v", repo.saved)
+		}
+	})
+
+	t.Run("falls back to yaml extension", func(t *testing.T) {
+		repo, _ := run(t, map[string]string{".specvital.yaml": "skip_generated: true\n"})
+
+		if repo.saved == nil || !repo.saved.SkipGenerated {
+			t.Errorf("expected .specvital.yaml to be loaded, got %+v", repo.saved)
+		}
+	})
+
+	t.Run("invalid rules are ignored", func(t *testing.T) {
+		repo, saved := run(t, map[string]string{".specvital.yml": "exclud: [vendor/**]\n"})
+
+		if repo.saved != nil {
+			t.Errorf("expected invalid rules not to be saved, got %+v", repo.saved)
+		}
+		if len(saved) != 2 {
+			t.Errorf("expected all files to be kept, got %d", len(saved))
+		}
+	})
+
+	t.Run("missing rules file keeps all files", func(t *testing.T) {
+		_, saved := run(t, nil)
+
+		if len(saved) != 2 {
+			t.Errorf("expected all files to be kept, got %d", len(saved))
+		}
+	})
+}
+
+type mockProgressRepository struct {
+	mockRepository
+	events []analysis.ProgressEvent
+}
+
+func (m *mockProgressRepository) SaveProgressEvent(ctx context.Context, event analysis.ProgressEvent) error {
+	m.events = append(m.events, event)
+	return errors.New("notify failed")
+}
+
+func TestAnalyzeUseCase_Progress(t *testing.T) {
+	stages := func(events []analysis.ProgressEvent) []analysis.ProgressStage {
+		out := make([]analysis.ProgressStage, len(events))
+		for i, e := range events {
+			out[i] = e.Stage
+		}
+		return out
+	}
+
+	t.Run("reports each stage and tolerates reporting errors", func(t *testing.T) {
+		repo := &mockProgressRepository{mockRepository: *newSuccessfulRepository()}
+		req := newValidRequest()
+		req.JobID = 7
+
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), newSuccessfulParser(), nil, WithParserVersion("v1.0.0"))
+		if err := uc.Execute(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []analysis.ProgressStage{
+			analysis.StageCloneStarted,
+			analysis.StageCloneCompleted,
+			analysis.StageScanStarted,
+			analysis.StageSaving,
+			analysis.StageCompleted,
+		}
+		got := stages(repo.events)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("stages = %v, want %v", got, want)
+		}
+		if repo.events[0].AnalysisID == nil || repo.events[0].JobID != 7 {
+			t.Errorf("clone event should have the job ID and the analysis ID recorded before the clone: %+v", repo.events[0])
+		}
+		if repo.events[len(repo.events)-1].AnalysisID == nil {
+			t.Error("completed event should carry the analysis ID")
+		}
+	})
+
+	t.Run("reports failure", func(t *testing.T) {
+		repo := &mockProgressRepository{mockRepository: *newSuccessfulRepository()}
+		parser := &mockParser{scanFn: func(ctx context.Context, src analysis.Source) (*analysis.Inventory, error) {
+			return nil, errors.New("scan failed")
+		}}
+
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), parser, nil, WithParserVersion("v1.0.0"))
+		if err := uc.Execute(context.Background(), newValidRequest()); err == nil {
+			t.Fatal("expected scan error")
+		}
+
+		if last := repo.events[len(repo.events)-1]; last.Stage != analysis.StageFailed {
+			t.Errorf("expected final stage failed, got %s", last.Stage)
+		}
+	})
+}
+
+func TestAnalyzeUseCase_Region(t *testing.T) {
+	var captured analysis.UpsertCodebaseParams
+	codebaseRepo := &mockCodebaseRepository{
+		findWithLastCommitFn: func(ctx context.Context, host, owner, name string) (*analysis.Codebase, error) {
+			return nil, analysis.ErrCodebaseNotFound
+		},
+		findByExternalIDFn: func(ctx context.Context, host, externalRepoID string) (*analysis.Codebase, error) {
+			return nil, analysis.ErrCodebaseNotFound
+		},
+		upsertFn: func(ctx context.Context, params analysis.UpsertCodebaseParams) (*analysis.Codebase, error) {
+			captured = params
+			return &analysis.Codebase{ID: analysis.NewUUID(), Owner: params.Owner, Name: params.Name, Region: params.Region}, nil
+		},
+	}
+
+	uc := NewAnalyzeUseCase(
+		newSuccessfulRepository(), codebaseRepo, newSuccessfulVCS(newSuccessfulSource()),
+		newSuccessfulVCSAPIClient(), newSuccessfulParser(), nil,
+		WithParserVersion(testParserVersion),
+		WithRegion("eu"),
+	)
+	if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if captured.Region != "eu" {
+		t.Errorf("expected new codebase to be stamped with worker region, got %q", captured.Region)
+	}
+}
+
+func TestAnalyzeUseCase_Branch(t *testing.T) {
+	var created analysis.CreateAnalysisRecordParams
+	repo := newSuccessfulRepository()
+	repo.createAnalysisRecordFn = func(ctx context.Context, params analysis.CreateAnalysisRecordParams) (analysis.UUID, error) {
+		created = params
+		return analysis.NewUUID(), nil
+	}
+	src := newSuccessfulSource()
+	src.branchFn = func() string { return "release/v2" }
+	vcs := newSuccessfulVCS(src)
+
+	uc := NewAnalyzeUseCase(
+		repo, newSuccessfulCodebaseRepository(), vcs,
+		newSuccessfulVCSAPIClient(), newSuccessfulParser(), nil,
+		WithParserVersion(testParserVersion),
+	)
+	req := newValidRequest()
+	req.Branch = "release/v2"
+	if err := uc.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if vcs.clonedBranch != "release/v2" {
+		t.Errorf("expected branch to be cloned, got %q", vcs.clonedBranch)
+	}
+	if created.Branch != "release/v2" {
+		t.Errorf("expected analysis to record the branch, got %q", created.Branch)
+	}
+}
+
+func TestAnalyzeUseCase_TestVariantCollapse(t *testing.T) {
+	run := func(t *testing.T, opts ...Option) []analysis.Test {
+		t.Helper()
+		parser := &mockParser{
+			scanFn: func(ctx context.Context, src analysis.Source) (*analysis.Inventory, error) {
+				return &analysis.Inventory{Files: []analysis.TestFile{{
+					Path: "tests/test_math.py",
+					Tests: []analysis.Test{
+						{Name: "test_add[1-1]"}, {Name: "test_add[1-2]"}, {Name: "test_add[2-2]"}, {Name: "test_sub"},
+					},
+				}}}, nil
+			},
+		}
+		var saved []analysis.Test
+		repo := newSuccessfulRepository()
+		repo.saveAnalysisInventoryFn = func(ctx context.Context, params analysis.SaveAnalysisInventoryParams) error {
+			saved = params.Inventory.Files[0].Tests
+			return nil
+		}
+		opts = append(opts, WithParserVersion("v1.0.0"))
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), parser, nil, opts...)
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return saved
+	}
+
+	t.Run("collapses parameter variants by default", func(t *testing.T) {
+		saved := run(t)
+
+		if len(saved) != 2 || saved[0].Name != "test_add[1-1]" || saved[0].VariantCount() != 3 {
+			t.Errorf("unexpected tests: %+v", saved)
+		}
+	})
+
+	t.Run("zero value ignored", func(t *testing.T) {
+		if saved := run(t, WithTestVariantCollapse(0)); len(saved) != 2 {
+			t.Errorf("expected default collapsing, got %+v", saved)
+		}
+	})
+
+	t.Run("negative value disables collapsing", func(t *testing.T) {
+		if saved := run(t, WithTestVariantCollapse(-1)); len(saved) != 4 {
+			t.Errorf("expected all tests kept, got %+v", saved)
+		}
+	})
+
+	t.Run("larger minimum keeps small groups", func(t *testing.T) {
+		if saved := run(t, WithTestVariantCollapse(4)); len(saved) != 4 {
+			t.Errorf("expected all tests kept, got %+v", saved)
+		}
+	})
+}
+
+func TestAnalyzeUseCase_Projects(t *testing.T) {
+	paths := []string{
+		"tools/lint_test.go",
+		"services/api/internal/handler_test.go",
+		"packages/ui/src/button.test.ts",
+		"packages/legacy/index.test.js",
+		"java/core/src/test/java/CoreTest.java",
+	}
+	parser := &mockParser{
+		scanFn: func(ctx context.Context, src analysis.Source) (*analysis.Inventory, error) {
+			files := make([]analysis.TestFile, len(paths))
+			for i, p := range paths {
+				files[i] = analysis.TestFile{Path: p}
+			}
+			return &analysis.Inventory{Files: files}, nil
+		},
+	}
+	manifests := map[string]string{
+		"go.mod":                       "module example.com/tools\n",
+		"services/api/go.mod":          "module example.com/api\n",
+		"package.json":                 `{"workspaces": ["packages/*", "!packages/legacy"]}`,
+		"packages/ui/package.json":     `{"name": "ui"}`,
+		"packages/legacy/package.json": `{"name": "legacy"}`,
+		"pom.xml":                      "<project><modules><module>java</module></modules></project>",
+		"java/pom.xml":                 "<project><modules><module>core</module></modules></project>",
+	}
+
+	var saved []analysis.TestFile
+	repo := newSuccessfulRepository()
+	repo.saveAnalysisInventoryFn = func(ctx context.Context, params analysis.SaveAnalysisInventoryParams) error {
+		saved = params.Inventory.Files
+		return nil
+	}
+	uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(),
+		newSuccessfulVCS(&mockRulesSource{mockSource: *newSuccessfulSource(), files: manifests}),
+		newSuccessfulVCSAPIClient(), parser, nil, WithParserVersion("v1.0.0"))
+	if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"tools/lint_test.go":                    "",
+		"services/api/internal/handler_test.go": "services/api",
+		"packages/ui/src/button.test.ts":        "packages/ui",
+		"packages/legacy/index.test.js":         "",
+		"java/core/src/test/java/CoreTest.java": "java/core",
+	}
+	if len(saved) != len(want) {
+		t.Fatalf("saved %d files, want %d", len(saved), len(want))
+	}
+	for _, f := range saved {
+		if f.Project != want[f.Path] {
+			t.Errorf("project of %s = %q, want %q", f.Path, f.Project, want[f.Path])
+		}
+	}
+}
+
+type mockPathFiltersRepository struct {
+	mockRepository
+	persisted []*analysis.PathFilters
+	latest    *analysis.PathFilters
+}
+
+func (m *mockPathFiltersRepository) UpdateAnalysisSource(ctx context.Context, params analysis.UpdateAnalysisSourceParams) error {
+	m.persisted = append(m.persisted, params.PathFilters)
+	return nil
+}
+
+func (m *mockPathFiltersRepository) GetLatestPathFilters(ctx context.Context, codebaseID analysis.UUID) (*analysis.PathFilters, error) {
+	return m.latest, nil
+}
+
+type mockFilteringParser struct {
+	mockParser
+	filters []*analysis.PathFilters
+}
+
+func (m *mockFilteringParser) ScanFiltered(ctx context.Context, src analysis.Source, filters *analysis.PathFilters) (*analysis.Inventory, error) {
+	m.filters = append(m.filters, filters)
+	return m.Scan(ctx, src)
+}
+
+func (m *mockFilteringParser) ScanStreamFiltered(ctx context.Context, src analysis.Source, filters *analysis.PathFilters) (<-chan analysis.FileResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestAnalyzeUseCase_PathFilters(t *testing.T) {
+	newParser := func() *mockFilteringParser {
+		return &mockFilteringParser{mockParser: mockParser{
+			scanFn: func(ctx context.Context, src analysis.Source) (*analysis.Inventory, error) {
+				return &analysis.Inventory{Files: []analysis.TestFile{
+					{Path: "services/payments/charge_test.go"},
+					{Path: "services/payments/vendor/lib/lib_test.go"},
+					{Path: "services/users/user_test.go"},
+				}}, nil
+			},
+		}}
+	}
+	payments := &analysis.PathFilters{
+		Exclude: []string{"**/vendor/**"},
+		Include: []string{"services/payments/**"},
+	}
+
+	run := func(t *testing.T, previous *analysis.PathFilters, req analysis.AnalyzeRequest) (*mockPathFiltersRepository, *mockFilteringParser, []string) {
+		t.Helper()
+		var saved []string
+		repo := &mockPathFiltersRepository{latest: previous}
+		repo.saveAnalysisInventoryFn = func(ctx context.Context, params analysis.SaveAnalysisInventoryParams) error {
+			for _, f := range params.Inventory.Files {
+				saved = append(saved, f.Path)
+			}
+			return nil
+		}
+		parser := newParser()
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), parser, nil, WithParserVersion("v1.0.0"))
+		if err := uc.Execute(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return repo, parser, saved
+	}
+
+	t.Run("applies and persists request filters", func(t *testing.T) {
+		req := newValidRequest()
+		req.PathFilters = payments
+
+		repo, parser, saved := run(t, nil, req)
+		if !slices.Equal(saved, []string{"services/payments/charge_test.go"}) {
+			t.Errorf("saved = %v", saved)
+		}
+		if len(parser.filters) != 1 || parser.filters[0] != payments {
+			t.Errorf("parser filters = %v, want the request filters", parser.filters)
+		}
+		if len(repo.persisted) != 1 || repo.persisted[0] != payments {
+			t.Errorf("persisted filters = %v, want the request filters", repo.persisted)
+		}
+	})
+
+	t.Run("reuses previous filters when the request has none", func(t *testing.T) {
+		repo, _, saved := run(t, payments, newValidRequest())
+		if !slices.Equal(saved, []string{"services/payments/charge_test.go"}) {
+			t.Errorf("saved = %v", saved)
+		}
+		if len(repo.persisted) != 1 || repo.persisted[0] != payments {
+			t.Errorf("persisted filters = %v, want the previous filters", repo.persisted)
+		}
+	})
+
+	t.Run("empty request filters clear previous ones", func(t *testing.T) {
+		req := newValidRequest()
+		req.PathFilters = &analysis.PathFilters{}
+
+		repo, parser, saved := run(t, payments, req)
+		if len(saved) != 3 {
+			t.Errorf("saved = %v, want all files", saved)
+		}
+		if len(parser.filters) != 0 {
+			t.Errorf("expected an unfiltered scan, got %v", parser.filters)
+		}
+		if len(repo.persisted) != 1 || repo.persisted[0] != nil {
+			t.Errorf("persisted filters = %v, want none", repo.persisted)
+		}
+	})
+
+	t.Run("rejects invalid filters", func(t *testing.T) {
+		req := newValidRequest()
+		req.PathFilters = &analysis.PathFilters{Include: []string{"services/[payments"}}
+
+		uc := NewAnalyzeUseCase(newSuccessfulRepository(), newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), newParser(), nil, WithParserVersion("v1.0.0"))
+		if err := uc.Execute(context.Background(), req); !errors.Is(err, analysis.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+type mockIncrementalParser struct {
+	mockStreamingParser
+	known analysis.FileHashes
+}
+
+func (m *mockIncrementalParser) files() []analysis.TestFile {
+	files := []analysis.TestFile{
+		{Path: "a_test.go", ContentHash: []byte("a")},
+		{Path: "b_test.go", ContentHash: []byte("b2"), Framework: "go-testing", Tests: []analysis.Test{{Name: "TestB"}}},
+	}
+	for i, f := range files {
+		if bytes.Equal(m.known[f.Path], f.ContentHash) {
+			files[i] = analysis.TestFile{Path: f.Path, ContentHash: f.ContentHash, Unchanged: true}
+		}
+	}
+	return files
+}
+
+func (m *mockIncrementalParser) ScanIncremental(ctx context.Context, src analysis.Source, filters *analysis.PathFilters, known analysis.FileHashes) (*analysis.Inventory, error) {
+	m.known = known
+	return &analysis.Inventory{Files: m.files()}, nil
+}
+
+func (m *mockIncrementalParser) ScanStreamIncremental(ctx context.Context, src analysis.Source, filters *analysis.PathFilters, known analysis.FileHashes) (<-chan analysis.FileResult, error) {
+	m.known = known
+	files := m.files()
+	ch := make(chan analysis.FileResult, len(files))
+	for i := range files {
+		ch <- analysis.FileResult{File: &files[i]}
+	}
+	close(ch)
+	return ch, nil
+}
+
+type mockFileReuseRepository struct {
+	mockStreamingRepository
+	hashes     analysis.FileHashes
+	previousID analysis.UUID
+	requested  []string
+	saved      []analysis.TestFile
+}
+
+func (m *mockFileReuseRepository) GetReusableFiles(ctx context.Context, codebaseID analysis.UUID, parserVersion string) (analysis.UUID, analysis.FileHashes, error) {
+	return m.previousID, m.hashes, nil
+}
+
+func (m *mockFileReuseRepository) GetTestFiles(ctx context.Context, analysisID analysis.UUID, paths []string) ([]analysis.TestFile, error) {
+	if analysisID != m.previousID {
+		return nil, fmt.Errorf("unexpected analysis %s", analysisID)
+	}
+	m.requested = append(m.requested, paths...)
+	return []analysis.TestFile{{Path: "a_test.go", Framework: "go-testing", Tests: []analysis.Test{{Name: "TestA"}}}}, nil
+}
+
+func TestAnalyzeUseCase_FileReuse(t *testing.T) {
+	newRepo := func() *mockFileReuseRepository {
+		repo := &mockFileReuseRepository{
+			hashes:     analysis.FileHashes{"a_test.go": []byte("a"), "b_test.go": []byte("b1")},
+			previousID: analysis.NewUUID(),
+		}
+		repo.saveAnalysisInventoryFn = func(ctx context.Context, params analysis.SaveAnalysisInventoryParams) error {
+			repo.saved = append(repo.saved, params.Inventory.Files...)
+			return nil
+		}
+		repo.saveAnalysisBatchFn = func(ctx context.Context, params analysis.SaveAnalysisBatchParams) (*analysis.BatchStats, error) {
+			repo.saved = append(repo.saved, params.Files...)
+			return &analysis.BatchStats{FilesProcessed: len(params.Files)}, nil
+		}
+		return repo
+	}
+	check := func(t *testing.T, repo *mockFileReuseRepository) {
+		t.Helper()
+		if !slices.Equal(repo.requested, []string{"a_test.go"}) {
+			t.Errorf("requested previous files %v, want only the unchanged one", repo.requested)
+		}
+		if len(repo.saved) != 2 {
+			t.Fatalf("saved %d files, want 2", len(repo.saved))
+		}
+		wantTests := map[string]string{"a_test.go": "TestA", "b_test.go": "TestB"}
+		for _, f := range repo.saved {
+			if f.Unchanged || len(f.Tests) != 1 || f.Tests[0].Name != wantTests[f.Path] {
+				t.Errorf("saved %+v, want the parsed or reused file", f)
+			}
+			if f.Path == "a_test.go" && string(f.ContentHash) != "a" {
+				t.Errorf("reused file lost its content hash: %q", f.ContentHash)
+			}
+		}
+	}
+
+	t.Run("streaming", func(t *testing.T) {
+		repo := newRepo()
+		parser := &mockIncrementalParser{}
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), parser, nil, WithParserVersion("v1.0.0"))
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		check(t, repo)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		repo := newRepo()
+		parser := &mockIncrementalParser{}
+		uc := NewAnalyzeUseCase(&struct {
+			mockRepository
+			analysis.FileReuseRepository
+		}{repo.mockRepository, repo}, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), parser, nil, WithParserVersion("v1.0.0"))
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		check(t, repo)
+	})
+}
+
+type mockForkReuseRepository struct {
+	mockFileReuseRepository
+	commitSHA   string
+	duplicateOf analysis.UUID
+	fingerprint []byte
+}
+
+func (m *mockForkReuseRepository) GetReusableFiles(ctx context.Context, codebaseID analysis.UUID, parserVersion string) (analysis.UUID, analysis.FileHashes, error) {
+	return analysis.NilUUID, nil, nil
+}
+
+func (m *mockForkReuseRepository) GetForkReusableFiles(ctx context.Context, codebaseID analysis.UUID, commitSHA, parserVersion string) (analysis.UUID, analysis.FileHashes, error) {
+	m.commitSHA = commitSHA
+	return m.previousID, m.hashes, nil
+}
+
+func (m *mockForkReuseRepository) GetTestFiles(ctx context.Context, analysisID analysis.UUID, paths []string) ([]analysis.TestFile, error) {
+	if analysisID != m.previousID {
+		return nil, fmt.Errorf("unexpected analysis %s", analysisID)
+	}
+	m.requested = append(m.requested, paths...)
+	files := make([]analysis.TestFile, len(paths))
+	for i, path := range paths {
+		files[i] = analysis.TestFile{Path: path, Framework: "go-testing", Tests: []analysis.Test{{Name: "TestUpstream"}}}
+	}
+	return files, nil
+}
+
+func (m *mockForkReuseRepository) SaveFingerprint(ctx context.Context, analysisID analysis.UUID, fingerprint []byte, duplicateOf analysis.UUID) error {
+	m.fingerprint = fingerprint
+	m.duplicateOf = duplicateOf
+	return nil
+}
+
+func TestAnalyzeUseCase_ForkReuse(t *testing.T) {
+	run := func(t *testing.T, upstream analysis.FileHashes) *mockForkReuseRepository {
+		t.Helper()
+		repo := &mockForkReuseRepository{mockFileReuseRepository: mockFileReuseRepository{
+			hashes:     upstream,
+			previousID: analysis.NewUUID(),
+		}}
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), &mockIncrementalParser{}, nil, WithParserVersion("v1.0.0"))
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.commitSHA != newSuccessfulSource().CommitSHA() {
+			t.Errorf("looked up the fork at commit %q", repo.commitSHA)
+		}
+		return repo
+	}
+	parsed := analysis.FileHashes{"a_test.go": []byte("a"), "b_test.go": []byte("b2")}
+
+	t.Run("links an identical fork to its upstream", func(t *testing.T) {
+		repo := run(t, analysis.FileHashes{"a_test.go": []byte("a"), "b_test.go": []byte("b2")})
+		if !slices.Equal(repo.requested, []string{"a_test.go", "b_test.go"}) {
+			t.Errorf("requested upstream files %v, want every file", repo.requested)
+		}
+		if repo.duplicateOf != repo.previousID {
+			t.Errorf("duplicate of %s, want the upstream analysis %s", repo.duplicateOf, repo.previousID)
+		}
+		if !bytes.Equal(repo.fingerprint, parsed.Fingerprint()) {
+			t.Error("expected the fingerprint of the saved files")
+		}
+	})
+
+	t.Run("reuses unchanged files of a diverged fork without linking it", func(t *testing.T) {
+		repo := run(t, analysis.FileHashes{"a_test.go": []byte("a"), "b_test.go": []byte("b1")})
+		if !slices.Equal(repo.requested, []string{"a_test.go"}) {
+			t.Errorf("requested upstream files %v, want only the unchanged one", repo.requested)
+		}
+		if repo.duplicateOf != analysis.NilUUID {
+			t.Errorf("expected no duplicate link, got %s", repo.duplicateOf)
+		}
+		if !bytes.Equal(repo.fingerprint, parsed.Fingerprint()) {
+			t.Error("expected the fingerprint of the saved files")
+		}
+	})
+}
+
+type mockParseErrorRepository struct {
+	mockStreamingRepository
+	quarantined []analysis.ParseError
+	languages   []analysis.LanguageStats
+	unmatched   []analysis.UnmatchedSample
+	finalized   bool
+}
+
+func (m *mockParseErrorRepository) SaveUnmatchedSamples(ctx context.Context, analysisID analysis.UUID, samples []analysis.UnmatchedSample) error {
+	m.unmatched = samples
+	return nil
+}
+
+func (m *mockParseErrorRepository) SaveLanguageStats(ctx context.Context, analysisID analysis.UUID, stats []analysis.LanguageStats) error {
+	m.languages = stats
+	return nil
+}
+
+func (m *mockParseErrorRepository) FinalizeAnalysis(ctx context.Context, params analysis.FinalizeAnalysisParams) error {
+	m.finalized = true
+	return nil
+}
+
+func (m *mockParseErrorRepository) QuarantineFiles(ctx context.Context, analysisID analysis.UUID, errs []analysis.ParseError) error {
+	m.quarantined = errs
+	return nil
+}
+
+func TestAnalyzeUseCase_ParseErrorQuarantine(t *testing.T) {
+	panicErr := fmt.Errorf("%w: broken_test.go: index out of range", analysis.ErrParserPanic)
+
+	t.Run("streaming skips files that fail to parse", func(t *testing.T) {
+		repo := &mockParseErrorRepository{}
+		var saved []string
+		repo.saveAnalysisBatchFn = func(ctx context.Context, params analysis.SaveAnalysisBatchParams) (*analysis.BatchStats, error) {
+			for _, f := range params.Files {
+				saved = append(saved, f.Path)
+			}
+			return &analysis.BatchStats{}, nil
+		}
+		parser := &mockStreamingParser{
+			scanStreamFn: func(ctx context.Context, src analysis.Source) (<-chan analysis.FileResult, error) {
+				ch := make(chan analysis.FileResult, 3)
+				ch <- analysis.FileResult{File: &analysis.TestFile{Path: "ok_test.go"}}
+				ch <- analysis.FileResult{Err: panicErr, Path: "broken_test.go"}
+				ch <- analysis.FileResult{Err: errors.New("parse: syntax error"), Path: "invalid_test.go"}
+				close(ch)
+				return ch, nil
+			},
+		}
+
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), parser, nil, WithParserVersion(testParserVersion))
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("expected a partial success, got %v", err)
+		}
+		if !repo.finalized || !slices.Equal(saved, []string{"ok_test.go"}) {
+			t.Errorf("finalized = %v, saved = %v; want the parsed file saved and finalized", repo.finalized, saved)
+		}
+		want := []analysis.ParseError{
+			{FilePath: "broken_test.go", Message: panicErr.Error(), Panicked: true},
+			{FilePath: "invalid_test.go", Message: "parse: syntax error"},
+		}
+		if !slices.Equal(repo.quarantined, want) {
+			t.Errorf("quarantined = %+v, want %+v", repo.quarantined, want)
+		}
+	})
+
+	t.Run("batch records the inventory parse errors", func(t *testing.T) {
+		repo := &struct {
+			mockRepository
+			*mockParseErrorRepository
+		}{*newSuccessfulRepository(), &mockParseErrorRepository{}}
+		parser := &mockParser{
+			scanFn: func(ctx context.Context, src analysis.Source) (*analysis.Inventory, error) {
+				return &analysis.Inventory{
+					Files:       []analysis.TestFile{{Path: "ok_test.go"}},
+					ParseErrors: []analysis.ParseError{analysis.NewParseError("broken_test.go", panicErr)},
+				}, nil
+			},
+		}
+
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), parser, nil, WithParserVersion(testParserVersion))
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.quarantined) != 1 || !repo.quarantined[0].Panicked {
+			t.Errorf("quarantined = %+v, want the panicked file", repo.quarantined)
+		}
+	})
+
+	t.Run("streaming error without a file still fails the scan", func(t *testing.T) {
+		repo := &mockParseErrorRepository{}
+		parser := &mockStreamingParser{
+			scanStreamFn: func(ctx context.Context, src analysis.Source) (<-chan analysis.FileResult, error) {
+				ch := make(chan analysis.FileResult, 1)
+				ch <- analysis.FileResult{Err: errors.New("discovery failed")}
+				close(ch)
+				return ch, nil
+			},
+		}
+
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), parser, nil, WithParserVersion(testParserVersion))
+		if err := uc.Execute(context.Background(), newValidRequest()); !errors.Is(err, ErrScanFailed) {
+			t.Errorf("expected ErrScanFailed, got %v", err)
+		}
+		if repo.finalized || repo.quarantined != nil {
+			t.Error("expected the analysis neither finalized nor quarantined")
+		}
+	})
+}
+
+func TestAnalyzeUseCase_LanguageStats(t *testing.T) {
+	t.Run("streaming tallies parsed, failed and unmatched files", func(t *testing.T) {
+		repo := &mockParseErrorRepository{}
+		parser := &mockStreamingParser{
+			scanStreamFn: func(ctx context.Context, src analysis.Source) (<-chan analysis.FileResult, error) {
+				ch := make(chan analysis.FileResult, 4)
+				ch <- analysis.FileResult{File: &analysis.TestFile{Path: "auth_test.go", Tests: []analysis.Test{{Name: "TestLogin"}, {Name: "TestLogout"}}}}
+				ch <- analysis.FileResult{Err: errors.New("parse: syntax error"), Path: "broken.test.ts"}
+				ch <- analysis.FileResult{Path: "widget.test.ts"}
+				ch <- analysis.FileResult{Path: "lib/Spec.kt"}
+				close(ch)
+				return ch, nil
+			},
+		}
+
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), parser, nil, WithParserVersion(testParserVersion))
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []analysis.LanguageStats{
+			{Language: "go", Files: 1, Tests: 2},
+			{Language: "kotlin", UnmatchedFiles: 1},
+			{Language: "typescript", FailedFiles: 1, UnmatchedFiles: 1},
+		}
+		if !slices.Equal(repo.languages, want) {
+			t.Errorf("languages = %+v, want %+v", repo.languages, want)
+		}
+	})
+
+	t.Run("batch counts unmatched files as unknown", func(t *testing.T) {
+		repo := &struct {
+			mockRepository
+			*mockParseErrorRepository
+		}{*newSuccessfulRepository(), &mockParseErrorRepository{}}
+		parser := &mockParser{
+			scanFn: func(ctx context.Context, src analysis.Source) (*analysis.Inventory, error) {
+				return &analysis.Inventory{Files: []analysis.TestFile{{Path: "test_api.py"}}, UnmatchedFiles: 3}, nil
+			},
+		}
+
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(newSuccessfulSource()),
+			newSuccessfulVCSAPIClient(), parser, nil, WithParserVersion(testParserVersion))
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []analysis.LanguageStats{
+			{Language: "python", Files: 1},
+			{Language: analysis.LanguageUnknown, UnmatchedFiles: 3},
+		}
+		if !slices.Equal(repo.languages, want) {
+			t.Errorf("languages = %+v, want %+v", repo.languages, want)
+		}
+	})
+}
+
+func TestAnalyzeUseCase_UnmatchedSamples(t *testing.T) {
+	newParser := func() *mockStreamingParser {
+		return &mockStreamingParser{
+			scanStreamFn: func(ctx context.Context, src analysis.Source) (<-chan analysis.FileResult, error) {
+				ch := make(chan analysis.FileResult, 4)
+				ch <- analysis.FileResult{File: &analysis.TestFile{Path: "auth_test.go"}}
+				ch <- analysis.FileResult{Path: "spec/widget.spec.rb"}
+				ch <- analysis.FileResult{Path: "web/missing.test.js"}
+				ch <- analysis.FileResult{Path: "app/src/LoginTest.kt"}
+				close(ch)
+				return ch, nil
+			},
+		}
+	}
+	src := &mockRulesSource{mockSource: *newSuccessfulSource(), files: map[string]string{
+		"spec/widget.spec.rb":  "require 'minitest'\ntoken = 'abc'\nline three",
+		"app/src/LoginTest.kt": "import io.kotest.core.spec.style.StringSpec",
+	}}
+
+	t.Run("samples unmatched candidates with redacted snippets", func(t *testing.T) {
+		repo := &mockParseErrorRepository{}
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(src),
+			newSuccessfulVCSAPIClient(), newParser(), nil, WithParserVersion(testParserVersion), WithUnmatchedSampling(0, 2))
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []analysis.UnmatchedSample{
+			{FilePath: "app/src/LoginTest.kt", Language: "kotlin", Snippet: "import io.kotest.core.spec.style.StringSpec"},
+			{FilePath: "spec/widget.spec.rb", Language: "ruby", Snippet: "require 'minitest'\ntoken = '[REDACTED]'"},
+			{FilePath: "web/missing.test.js", Language: "javascript"}, // unreadable, sampled without a snippet
+		}
+		if !slices.Equal(repo.unmatched, want) {
+			t.Errorf("unmatched = %+v, want %+v", repo.unmatched, want)
+		}
+	})
+
+	t.Run("stores paths only by default and honors the sample size", func(t *testing.T) {
+		repo := &mockParseErrorRepository{}
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(src),
+			newSuccessfulVCSAPIClient(), newParser(), nil, WithParserVersion(testParserVersion), WithUnmatchedSampling(1, 0))
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.unmatched) != 1 || repo.unmatched[0].Snippet != "" {
+			t.Errorf("unmatched = %+v, want one sample without a snippet", repo.unmatched)
+		}
+	})
+
+	t.Run("negative sample size disables sampling", func(t *testing.T) {
+		repo := &mockParseErrorRepository{}
+		uc := NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(src),
+			newSuccessfulVCSAPIClient(), newParser(), nil, WithParserVersion(testParserVersion), WithUnmatchedSampling(-1, 5))
+		if err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.unmatched != nil {
+			t.Errorf("unmatched = %+v, want none", repo.unmatched)
+		}
+	})
+}
+
+type mockSharedHelperRepository struct {
+	mockSourceFileRepository
+	helpers []analysis.SharedHelper
+	saveErr error
+}
+
+func (m *mockSharedHelperRepository) SaveSharedHelpers(ctx context.Context, analysisID analysis.UUID, helpers []analysis.SharedHelper) error {
+	m.helpers = helpers
+	return m.saveErr
+}
+
+func TestAnalyzeUseCase_SharedHelpers(t *testing.T) {
+	testFiles := []string{"web/a.test.ts", "web/b.test.ts", "web/c.test.ts", "tests/test_api.py"}
+	newParser := func() *mockSourceFileParser {
+		return &mockSourceFileParser{
+			mockParser: mockParser{
+				scanFn: func(ctx context.Context, src analysis.Source) (*analysis.Inventory, error) {
+					files := make([]analysis.TestFile, len(testFiles))
+					for i, p := range testFiles {
+						files[i] = analysis.TestFile{Path: p}
+					}
+					return &analysis.Inventory{Files: files}, nil
+				},
+			},
+			files: append([]string{"web/fixtures/user.ts", "web/util.ts", "tests/conftest.py"}, testFiles...),
+		}
+	}
+	src := &mockRulesSource{mockSource: *newSuccessfulSource(), files: map[string]string{
+		"web/a.test.ts":     "import { user } from './fixtures/user'\nimport { fmt } from './util'",
+		"web/b.test.ts":     "import { user } from './fixtures/user'",
+		"web/c.test.ts":     "const { user } = require('./fixtures/user')\nimport React from 'react'",
+		"tests/test_api.py": "import pytest",
+	}}
+	newUseCase := func(repo analysis.Repository, opts ...Option) *AnalyzeUseCase {
+		opts = append([]Option{WithParserVersion(testParserVersion)}, opts...)
+		return NewAnalyzeUseCase(repo, newSuccessfulCodebaseRepository(), newSuccessfulVCS(src),
+			newSuccessfulVCSAPIClient(), newParser(), nil, opts...)
+	}
+	newRepo := func() *mockSharedHelperRepository {
+		return &mockSharedHelperRepository{mockSourceFileRepository: mockSourceFileRepository{mockRepository: *newSuccessfulRepository()}}
+	}
+
+	t.Run("records helpers imported by enough test files", func(t *testing.T) {
+		repo := newRepo()
+		if err := newUseCase(repo, WithSharedHelpers(3)).Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.helpers) != 1 || repo.helpers[0].Path != "web/fixtures/user.ts" {
+			t.Fatalf("helpers = %+v, want web/fixtures/user.ts only", repo.helpers)
+		}
+		if want := []string{"web/a.test.ts", "web/b.test.ts", "web/c.test.ts"}; !slices.Equal(repo.helpers[0].TestFiles, want) {
+			t.Errorf("test files = %v, want %v", repo.helpers[0].TestFiles, want)
+		}
+	})
+
+	t.Run("counts implicit conftest fixtures", func(t *testing.T) {
+		repo := newRepo()
+		if err := newUseCase(repo, WithSharedHelpers(1)).Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		paths := make([]string, len(repo.helpers))
+		for i, h := range repo.helpers {
+			paths[i] = h.Path
+		}
+		if want := []string{"web/fixtures/user.ts", "tests/conftest.py", "web/util.ts"}; !slices.Equal(paths, want) {
+			t.Errorf("helpers = %v, want %v", paths, want)
+		}
+	})
+
+	t.Run("nothing recorded below the default threshold", func(t *testing.T) {
+		repo := newRepo()
+		if err := newUseCase(repo).Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.helpers != nil {
+			t.Errorf("helpers = %+v, want none", repo.helpers)
+		}
+	})
+
+	t.Run("negative threshold disables detection", func(t *testing.T) {
+		repo := newRepo()
+		if err := newUseCase(repo, WithSharedHelpers(-1)).Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.helpers != nil {
+			t.Errorf("helpers = %+v, want none", repo.helpers)
+		}
+	})
+
+	t.Run("save failure does not fail analysis", func(t *testing.T) {
+		repo := newRepo()
+		repo.saveErr = errors.New("insert failed")
+		if err := newUseCase(repo, WithSharedHelpers(1)).Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("expected analysis to succeed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+// HeadAnalyzer analyzes the head commit of a comparison. AnalyzeUseCase implements it.
+type HeadAnalyzer interface {
+	Execute(ctx context.Context, req analysis.AnalyzeRequest) error
+}
+
+// CompareUseCase computes the test delta between a pull request's base and head commits.
+type CompareUseCase struct {
+	analyzer   HeadAnalyzer
+	repository analysis.TestDeltaRepository
+}
+
+// NewCompareUseCase creates a new CompareUseCase with given dependencies.
+func NewCompareUseCase(analyzer HeadAnalyzer, repository analysis.TestDeltaRepository) *CompareUseCase {
+	return &CompareUseCase{
+		analyzer:   analyzer,
+		repository: repository,
+	}
+}
+
+// Execute analyzes the head commit unless it already has a completed analysis,
+// diffs its inventory against the base commit's stored inventory and records the
+// delta. The base is never analyzed here: a missing base means the repository was
+// not tracked when the pull request branched, and comparing against a fresh
+// analysis of an old commit would be misleading.
+func (uc *CompareUseCase) Execute(ctx context.Context, req analysis.CompareRequest) (analysis.TestDelta, error) {
+	if err := req.Validate(); err != nil {
+		return analysis.TestDelta{}, err
+	}
+
+	baseID, err := uc.repository.FindCompletedAnalysis(ctx, req.Owner, req.Repo, req.BaseCommitSHA)
+	if err != nil {
+		return analysis.TestDelta{}, fmt.Errorf("%w: base: %w", ErrInventoryLoadFailed, err)
+	}
+	if baseID == analysis.NilUUID {
+		return analysis.TestDelta{}, fmt.Errorf("%w: %s", ErrBaseNotAnalyzed, req.BaseCommitSHA)
+	}
+
+	headID, err := uc.ensureHeadAnalysis(ctx, req)
+	if err != nil {
+		return analysis.TestDelta{}, err
+	}
+
+	baseRefs, err := uc.repository.GetTestRefs(ctx, baseID)
+	if err != nil {
+		return analysis.TestDelta{}, fmt.Errorf("%w: base: %w", ErrInventoryLoadFailed, err)
+	}
+	headRefs, err := uc.repository.GetTestRefs(ctx, headID)
+	if err != nil {
+		return analysis.TestDelta{}, fmt.Errorf("%w: head: %w", ErrInventoryLoadFailed, err)
+	}
+
+	delta := analysis.DiffInventories(baseRefs, headRefs)
+	params := analysis.SaveTestDeltaParams{
+		BaseAnalysisID: baseID,
+		Delta:          delta,
+		HeadAnalysisID: headID,
+		PullRequest:    req.PullRequest,
+	}
+	if err := uc.repository.SaveTestDelta(ctx, params); err != nil {
+		return analysis.TestDelta{}, fmt.Errorf("%w: %w", ErrSaveFailed, err)
+	}
+
+	slog.InfoContext(ctx, "test delta recorded",
+		"owner", req.Owner,
+		"repo", req.Repo,
+		"pull_request", req.PullRequest,
+		"tests_added", len(delta.AddedTests),
+		"tests_removed", len(delta.RemovedTests),
+		"tests_renamed", len(delta.RenamedTests),
+	)
+	return delta, nil
+}
+
+// ensureHeadAnalysis returns the completed analysis of the head commit, running
+// one first when needed. Analysis always clones the branch HEAD, so when the
+// branch moved on since the request the head commit itself stays unanalyzed.
+func (uc *CompareUseCase) ensureHeadAnalysis(ctx context.Context, req analysis.CompareRequest) (analysis.UUID, error) {
+	headID, err := uc.repository.FindCompletedAnalysis(ctx, req.Owner, req.Repo, req.HeadCommitSHA)
+	if err != nil {
+		return analysis.NilUUID, fmt.Errorf("%w: head: %w", ErrInventoryLoadFailed, err)
+	}
+	if headID != analysis.NilUUID {
+		return headID, nil
+	}
+
+	if err := uc.analyzer.Execute(ctx, req.HeadAnalyzeRequest()); err != nil && !errors.Is(err, analysis.ErrAlreadyCompleted) {
+		return analysis.NilUUID, err
+	}
+
+	headID, err = uc.repository.FindCompletedAnalysis(ctx, req.Owner, req.Repo, req.HeadCommitSHA)
+	if err != nil {
+		return analysis.NilUUID, fmt.Errorf("%w: head: %w", ErrInventoryLoadFailed, err)
+	}
+	if headID == analysis.NilUUID {
+		return analysis.NilUUID, fmt.Errorf("%w: %s", ErrHeadMoved, req.HeadCommitSHA)
+	}
+	return headID, nil
+}
@@ -0,0 +1,175 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+type mockHeadAnalyzer struct {
+	err      error
+	onRun    func()
+	requests []analysis.AnalyzeRequest
+}
+
+func (m *mockHeadAnalyzer) Execute(ctx context.Context, req analysis.AnalyzeRequest) error {
+	m.requests = append(m.requests, req)
+	if m.onRun != nil {
+		m.onRun()
+	}
+	return m.err
+}
+
+type mockTestDeltaRepository struct {
+	analyses map[string]analysis.UUID // commit SHA -> completed analysis
+	refs     map[analysis.UUID][]analysis.TestRef
+	saved    *analysis.SaveTestDeltaParams
+	saveErr  error
+}
+
+func (m *mockTestDeltaRepository) FindCompletedAnalysis(ctx context.Context, owner, repo, commitSHA string) (analysis.UUID, error) {
+	return m.analyses[commitSHA], nil
+}
+
+func (m *mockTestDeltaRepository) GetTestRefs(ctx context.Context, analysisID analysis.UUID) ([]analysis.TestRef, error) {
+	return m.refs[analysisID], nil
+}
+
+func (m *mockTestDeltaRepository) SaveTestDelta(ctx context.Context, params analysis.SaveTestDeltaParams) error {
+	m.saved = &params
+	return m.saveErr
+}
+
+func newCompareRequest() analysis.CompareRequest {
+	return analysis.CompareRequest{
+		BaseCommitSHA: "base123",
+		HeadBranch:    "feature/login",
+		HeadCommitSHA: "head456",
+		Owner:         "owner",
+		PullRequest:   7,
+		Repo:          "repo",
+	}
+}
+
+func TestCompareUseCase_Execute(t *testing.T) {
+	baseID, headID := analysis.NewUUID(), analysis.NewUUID()
+	baseRefs := []analysis.TestRef{
+		{FilePath: "a_test.go", SuitePath: "A", Name: "one"},
+		{FilePath: "a_test.go", SuitePath: "A", Name: "gone"},
+		{FilePath: "a_test.go", SuitePath: "A", Name: "also gone"},
+	}
+	headRefs := []analysis.TestRef{
+		{FilePath: "a_test.go", SuitePath: "A", Name: "one"},
+		{FilePath: "b_test.go", SuitePath: "B", Name: "new"},
+	}
+	newRepo := func() *mockTestDeltaRepository {
+		return &mockTestDeltaRepository{
+			analyses: map[string]analysis.UUID{"base123": baseID},
+			refs:     map[analysis.UUID][]analysis.TestRef{baseID: baseRefs, headID: headRefs},
+		}
+	}
+
+	t.Run("analyzes the head commit and records the delta", func(t *testing.T) {
+		repo := newRepo()
+		analyzer := &mockHeadAnalyzer{onRun: func() { repo.analyses["head456"] = headID }}
+		uc := NewCompareUseCase(analyzer, repo)
+
+		delta, err := uc.Execute(context.Background(), newCompareRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(analyzer.requests) != 1 || analyzer.requests[0].Branch != "feature/login" || analyzer.requests[0].CommitSHA != "head456" {
+			t.Errorf("unexpected analyze requests: %+v", analyzer.requests)
+		}
+		if len(delta.AddedTests) != 1 || len(delta.RemovedTests) != 2 || len(delta.AddedSuites) != 1 {
+			t.Errorf("unexpected delta: %+v", delta)
+		}
+		if repo.saved == nil || repo.saved.BaseAnalysisID != baseID || repo.saved.HeadAnalysisID != headID || repo.saved.PullRequest != 7 {
+			t.Errorf("unexpected saved params: %+v", repo.saved)
+		}
+	})
+
+	t.Run("reuses an existing head analysis", func(t *testing.T) {
+		repo := newRepo()
+		repo.analyses["head456"] = headID
+		analyzer := &mockHeadAnalyzer{}
+
+		if _, err := NewCompareUseCase(analyzer, repo).Execute(context.Background(), newCompareRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(analyzer.requests) != 0 {
+			t.Errorf("expected no analysis, got %d", len(analyzer.requests))
+		}
+	})
+
+	t.Run("tolerates a concurrently completed head analysis", func(t *testing.T) {
+		repo := newRepo()
+		analyzer := &mockHeadAnalyzer{
+			err:   analysis.ErrAlreadyCompleted,
+			onRun: func() { repo.analyses["head456"] = headID },
+		}
+
+		if _, err := NewCompareUseCase(analyzer, repo).Execute(context.Background(), newCompareRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("missing base analysis", func(t *testing.T) {
+		repo := newRepo()
+		delete(repo.analyses, "base123")
+		analyzer := &mockHeadAnalyzer{}
+
+		_, err := NewCompareUseCase(analyzer, repo).Execute(context.Background(), newCompareRequest())
+		if !errors.Is(err, ErrBaseNotAnalyzed) {
+			t.Fatalf("expected ErrBaseNotAnalyzed, got %v", err)
+		}
+		if len(analyzer.requests) != 0 {
+			t.Error("expected head not to be analyzed without a base")
+		}
+	})
+
+	t.Run("head branch moved past the commit", func(t *testing.T) {
+		repo := newRepo()
+
+		_, err := NewCompareUseCase(&mockHeadAnalyzer{}, repo).Execute(context.Background(), newCompareRequest())
+		if !errors.Is(err, ErrHeadMoved) {
+			t.Fatalf("expected ErrHeadMoved, got %v", err)
+		}
+		if repo.saved != nil {
+			t.Error("expected no delta to be saved")
+		}
+	})
+
+	t.Run("analysis failure", func(t *testing.T) {
+		analyzeErr := errors.New("clone failed")
+
+		_, err := NewCompareUseCase(&mockHeadAnalyzer{err: analyzeErr}, newRepo()).Execute(context.Background(), newCompareRequest())
+		if !errors.Is(err, analyzeErr) {
+			t.Fatalf("expected analysis error, got %v", err)
+		}
+	})
+
+	t.Run("save failure", func(t *testing.T) {
+		repo := newRepo()
+		repo.analyses["head456"] = headID
+		repo.saveErr = errors.New("connection reset")
+
+		_, err := NewCompareUseCase(&mockHeadAnalyzer{}, repo).Execute(context.Background(), newCompareRequest())
+		if !errors.Is(err, ErrSaveFailed) {
+			t.Fatalf("expected ErrSaveFailed, got %v", err)
+		}
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		req := newCompareRequest()
+		req.BaseCommitSHA = ""
+
+		_, err := NewCompareUseCase(&mockHeadAnalyzer{}, newRepo()).Execute(context.Background(), req)
+		if !errors.Is(err, analysis.ErrInvalidInput) {
+			t.Fatalf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
@@ -3,9 +3,12 @@ package analysis
 import "errors"
 
 var (
+	ErrBaseNotAnalyzed          = errors.New("base commit has no completed analysis")
 	ErrCloneFailed              = errors.New("clone failed")
 	ErrCodebaseResolutionFailed = errors.New("codebase resolution failed")
 	ErrHeadCommitFailed         = errors.New("head commit lookup failed")
+	ErrHeadMoved                = errors.New("head branch no longer points at the requested commit")
+	ErrInventoryLoadFailed      = errors.New("inventory load failed")
 	ErrRaceConditionDetected    = errors.New("race condition detected: repository state changed during analysis")
 	ErrSaveFailed               = errors.New("save failed")
 	ErrScanFailed               = errors.New("scan failed")
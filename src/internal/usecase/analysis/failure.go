@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"syscall"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+// Substrings git and the runtime print for failures that do not carry a typed error.
+var (
+	authFailureMarkers = []string{
+		"authentication failed",
+		"could not read username",
+		"invalid username or password",
+		"terminal prompts disabled",
+	}
+	diskFullMarkers = []string{"no space left on device", "disk quota exceeded"}
+	oomMarkers      = []string{"cannot allocate memory", "out of memory", "signal: killed"}
+)
+
+// ClassifyFailure maps an Execute error to a failure class.
+// Typed errors take precedence; message matching covers errors that cross process
+// boundaries (git, the parser) as plain text. Returns "" for a nil error.
+func ClassifyFailure(err error) analysis.FailureClass {
+	if err == nil {
+		return ""
+	}
+	msg := strings.ToLower(err.Error())
+
+	switch {
+	case errors.Is(err, analysis.ErrRepoTooLarge):
+		return analysis.FailureRepoTooLarge
+	case errors.Is(err, analysis.ErrParserPanic):
+		return analysis.FailureParserPanic
+	case errors.Is(err, analysis.ErrWorkspaceFull) || errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EDQUOT) || containsAny(msg, diskFullMarkers):
+		return analysis.FailureDiskFull
+	case errors.Is(err, syscall.ENOMEM) || containsAny(msg, oomMarkers):
+		return analysis.FailureOOM
+	case errors.Is(err, analysis.ErrCloneAuthFailed):
+		return analysis.FailureCloneAuthFailed
+	}
+
+	if errors.Is(err, ErrCloneFailed) || errors.Is(err, ErrHeadCommitFailed) {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return analysis.FailureCloneTimeout
+		}
+		if containsAny(msg, authFailureMarkers) {
+			return analysis.FailureCloneAuthFailed
+		}
+	}
+
+	return analysis.FailureUnknown
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
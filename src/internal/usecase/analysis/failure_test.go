@@ -0,0 +1,42 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want analysis.FailureClass
+	}{
+		{"nil", nil, ""},
+		{"clone timeout", fmt.Errorf("%w: %w", ErrCloneFailed, context.DeadlineExceeded), analysis.FailureCloneTimeout},
+		{"head commit timeout", fmt.Errorf("%w: %w", ErrHeadCommitFailed, context.DeadlineExceeded), analysis.FailureCloneTimeout},
+		{"typed auth failure", fmt.Errorf("%w: %w", ErrCloneFailed, analysis.ErrCloneAuthFailed), analysis.FailureCloneAuthFailed},
+		{"git auth stderr", fmt.Errorf("%w: fatal: could not read Username for 'https://github.com'", ErrHeadCommitFailed), analysis.FailureCloneAuthFailed},
+		{"auth text outside clone", errors.New("Authentication failed"), analysis.FailureUnknown},
+		{"parser panic", fmt.Errorf("%w: %w: index out of range", ErrScanFailed, analysis.ErrParserPanic), analysis.FailureParserPanic},
+		{"repo too large", fmt.Errorf("%w: %w", ErrCloneFailed, analysis.ErrRepoTooLarge), analysis.FailureRepoTooLarge},
+		{"disk full errno", fmt.Errorf("%w: %w", ErrCloneFailed, syscall.ENOSPC), analysis.FailureDiskFull},
+		{"workspace quota wait timed out", fmt.Errorf("%w: %w: %w", ErrCloneFailed, analysis.ErrWorkspaceFull, context.DeadlineExceeded), analysis.FailureDiskFull},
+		{"disk full stderr", fmt.Errorf("%w: write error: No space left on device", ErrCloneFailed), analysis.FailureDiskFull},
+		{"oom errno", fmt.Errorf("%w: %w", ErrScanFailed, syscall.ENOMEM), analysis.FailureOOM},
+		{"oom killed", fmt.Errorf("%w: git: signal: killed", ErrCloneFailed), analysis.FailureOOM},
+		{"generic", fmt.Errorf("%w: connection reset", ErrSaveFailed), analysis.FailureUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyFailure(tt.err); got != tt.want {
+				t.Errorf("ClassifyFailure() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
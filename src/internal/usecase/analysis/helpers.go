@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+// recordSharedHelpers stores the helpers and fixtures imported by at least
+// the configured number of the analysis' test files. Imports are resolved
+// against sourceFiles, so helpers outside the analyzed paths are not seen.
+// Failure is non-critical: shared helpers only annotate the spec view.
+func (uc *AnalyzeUseCase) recordSharedHelpers(ctx context.Context, src analysis.Source, analysisID analysis.UUID, testFiles, sourceFiles []string) {
+	if uc.helperRepo == nil || uc.minHelperRefs < 0 || len(testFiles) == 0 || len(sourceFiles) == 0 {
+		return
+	}
+	reader, ok := src.(analysis.FileReader)
+	if !ok {
+		return
+	}
+
+	var goModule string
+	if data, err := reader.ReadFile(ctx, analysis.GoModFile, maxProjectManifestBytes); err == nil {
+		goModule = analysis.ParseGoModule(data)
+	}
+	resolver := analysis.NewHelperResolver(sourceFiles, goModule)
+
+	tally := make(analysis.HelperTally)
+	for _, testFile := range testFiles {
+		if ctx.Err() != nil {
+			return
+		}
+		content, err := reader.ReadFile(ctx, testFile, maxHelperScanBytes)
+		if err != nil {
+			continue // removed or too large; its imports are not counted
+		}
+		tally.Add(testFile, resolver.References(testFile, content))
+	}
+
+	helpers := tally.Shared(uc.minHelperRefs, analysis.MaxSharedHelpers)
+	if len(helpers) == 0 {
+		return
+	}
+	if err := uc.helperRepo.SaveSharedHelpers(ctx, analysisID, helpers); err != nil {
+		slog.WarnContext(ctx, "failed to save shared test helpers (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+		return
+	}
+	slog.InfoContext(ctx, "shared test helpers detected",
+		"analysis_id", analysisID,
+		"helpers", len(helpers),
+		"most_referenced", helpers[0].Path,
+	)
+}
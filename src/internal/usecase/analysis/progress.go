@@ -0,0 +1,49 @@
+package analysis
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+// progressReporter publishes progress events for a single analysis run.
+// A nil repository makes every call a no-op.
+type progressReporter struct {
+	analysisID analysis.UUID
+	repo       analysis.ProgressRepository
+	req        analysis.AnalyzeRequest
+}
+
+// report saves a progress event. Failure is non-critical: progress is
+// informational and must never fail the analysis itself.
+func (p *progressReporter) report(ctx context.Context, stage analysis.ProgressStage, processed, total int) {
+	if p == nil || p.repo == nil {
+		return
+	}
+
+	event := analysis.ProgressEvent{
+		CommitSHA:      p.req.CommitSHA,
+		FilesProcessed: processed,
+		FilesTotal:     total,
+		JobID:          p.req.JobID,
+		OccurredAt:     time.Now(),
+		Owner:          p.req.Owner,
+		Repo:           p.req.Repo,
+		Stage:          stage,
+	}
+	if p.analysisID != analysis.NilUUID {
+		id := p.analysisID
+		event.AnalysisID = &id
+	}
+
+	if err := p.repo.SaveProgressEvent(ctx, event); err != nil {
+		slog.WarnContext(ctx, "failed to report analysis progress (non-critical)",
+			"owner", p.req.Owner,
+			"repo", p.req.Repo,
+			"stage", stage,
+			"error", err,
+		)
+	}
+}
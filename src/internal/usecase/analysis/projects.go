@@ -0,0 +1,157 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+const (
+	// maxProjectManifestBytes bounds a manifest read while detecting projects.
+	maxProjectManifestBytes = 1 << 20
+	// maxMavenModules bounds the module tree walked from the root pom.xml.
+	maxMavenModules = 2000
+)
+
+// projectDetector partitions a monorepo inventory: a test file belongs to the
+// nearest enclosing directory holding a go.mod, a package.json matched by the
+// root workspaces, or a pom.xml listed in the root module tree. Files outside
+// any such directory belong to the repository root. Results are cached per
+// directory, so each manifest is read at most once per analysis.
+type projectDetector struct {
+	analysisID   analysis.UUID
+	dirs         map[string]bool
+	mavenModules map[string]bool
+	reader       analysis.FileReader
+	workspaces   []string
+}
+
+// newProjectDetector reads the root manifests. It returns nil, which assigns
+// every file to the repository root, when the source cannot read files.
+func newProjectDetector(ctx context.Context, src analysis.Source, analysisID analysis.UUID) *projectDetector {
+	reader, ok := src.(analysis.FileReader)
+	if !ok {
+		return nil
+	}
+
+	d := &projectDetector{
+		analysisID:   analysisID,
+		dirs:         make(map[string]bool),
+		mavenModules: make(map[string]bool),
+		reader:       reader,
+	}
+
+	if data, ok := d.read(ctx, analysis.PackageJSONFile); ok {
+		workspaces, err := analysis.ParseNPMWorkspaces(data)
+		if err != nil {
+			slog.WarnContext(ctx, "ignoring invalid project manifest (non-critical)",
+				"analysis_id", analysisID,
+				"file", analysis.PackageJSONFile,
+				"error", err,
+			)
+		}
+		d.workspaces = workspaces
+	}
+	d.walkMavenModules(ctx)
+
+	return d
+}
+
+// assign sets the project of file. Safe to call on a nil detector.
+func (d *projectDetector) assign(ctx context.Context, file *analysis.TestFile) {
+	if d == nil {
+		return
+	}
+	for dir := path.Dir(file.Path); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if d.isProject(ctx, dir) {
+			file.Project = dir
+			return
+		}
+	}
+	file.Project = ""
+}
+
+// count returns the number of projects holding at least one assigned file,
+// the repository root excluded.
+func (d *projectDetector) count() int {
+	if d == nil {
+		return 0
+	}
+	n := 0
+	for _, isProject := range d.dirs {
+		if isProject {
+			n++
+		}
+	}
+	return n
+}
+
+func (d *projectDetector) isProject(ctx context.Context, dir string) bool {
+	if isProject, ok := d.dirs[dir]; ok {
+		return isProject
+	}
+
+	isProject := d.mavenModules[dir]
+	if !isProject {
+		_, isProject = d.read(ctx, path.Join(dir, analysis.GoModFile))
+	}
+	if !isProject && analysis.MatchWorkspaces(d.workspaces, dir) {
+		_, isProject = d.read(ctx, path.Join(dir, analysis.PackageJSONFile))
+	}
+
+	d.dirs[dir] = isProject
+	return isProject
+}
+
+// walkMavenModules collects the module directories reachable from the root pom.xml.
+func (d *projectDetector) walkMavenModules(ctx context.Context) {
+	queue := []string{"."}
+	for len(queue) > 0 && len(d.mavenModules) < maxMavenModules {
+		dir := queue[0]
+		queue = queue[1:]
+
+		data, ok := d.read(ctx, path.Join(dir, analysis.PomFile))
+		if !ok {
+			continue
+		}
+		modules, err := analysis.ParseMavenModules(data)
+		if err != nil {
+			slog.WarnContext(ctx, "ignoring invalid project manifest (non-critical)",
+				"analysis_id", d.analysisID,
+				"file", path.Join(dir, analysis.PomFile),
+				"error", err,
+			)
+			continue
+		}
+
+		for _, m := range modules {
+			module := path.Join(dir, m)
+			if module == "." || module == ".." || strings.HasPrefix(module, "../") || d.mavenModules[module] {
+				continue
+			}
+			d.mavenModules[module] = true
+			queue = append(queue, module)
+		}
+	}
+}
+
+// read returns the file content, or false when it is absent or unreadable.
+func (d *projectDetector) read(ctx context.Context, name string) ([]byte, bool) {
+	data, err := d.reader.ReadFile(ctx, name, maxProjectManifestBytes)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.WarnContext(ctx, "failed to read project manifest (non-critical)",
+				"analysis_id", d.analysisID,
+				"file", name,
+				"error", err,
+			)
+		}
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,149 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+)
+
+// reusableFiles are the test files of the codebase's previous analysis, or of
+// a fork's upstream at the same commit when the codebase has none. Files whose
+// content is unchanged since then are copied from it instead of parsed.
+type reusableFiles struct {
+	analysisID analysis.UUID
+	fork       bool // analysisID belongs to another codebase
+	hashes     analysis.FileHashes
+	repo       analysis.FileReuseRepository
+}
+
+// loadReusableFiles returns nil when the parser or the repository cannot reuse
+// files. Failing to load the previous analysis is non-critical: every file is
+// parsed, and hashed for the next analysis.
+func (uc *AnalyzeUseCase) loadReusableFiles(ctx context.Context, codebaseID analysis.UUID, commitSHA string, analysisID analysis.UUID) *reusableFiles {
+	if uc.incrementalParser == nil || uc.fileReuseRepo == nil {
+		return nil
+	}
+
+	reuse := &reusableFiles{repo: uc.fileReuseRepo}
+	previousID, hashes, err := uc.fileReuseRepo.GetReusableFiles(ctx, codebaseID, uc.parserVersion)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load reusable test files (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+		return reuse
+	}
+	reuse.analysisID = previousID
+	reuse.hashes = hashes
+	if previousID == analysis.NilUUID {
+		uc.loadForkReusableFiles(ctx, reuse, codebaseID, commitSHA, analysisID)
+	}
+	return reuse
+}
+
+// loadForkReusableFiles points reuse at the analysis of another codebase at the
+// same commit, typically the repository the codebase was forked from. Failure
+// is non-critical: the fork is parsed as a new codebase.
+func (uc *AnalyzeUseCase) loadForkReusableFiles(ctx context.Context, reuse *reusableFiles, codebaseID analysis.UUID, commitSHA string, analysisID analysis.UUID) {
+	if uc.forkReuseRepo == nil || commitSHA == "" {
+		return
+	}
+
+	donorID, hashes, err := uc.forkReuseRepo.GetForkReusableFiles(ctx, codebaseID, commitSHA, uc.parserVersion)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load fork reusable test files (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+		return
+	}
+	if donorID == analysis.NilUUID {
+		return
+	}
+
+	reuse.analysisID = donorID
+	reuse.fork = true
+	reuse.hashes = hashes
+	slog.InfoContext(ctx, "reusing test files of another codebase at the same commit",
+		"analysis_id", analysisID,
+		"donor_analysis_id", donorID,
+		"files", len(hashes),
+	)
+}
+
+// recordFingerprint stores the fingerprint of the analysis' test files and,
+// when they match those of a fork's upstream it reused, links the analysis to
+// it as a duplicate. Nothing is stored when a file was saved without a hash.
+// Failure is non-critical: the fingerprint only links duplicate inventories.
+func (uc *AnalyzeUseCase) recordFingerprint(ctx context.Context, analysisID analysis.UUID, outcome *parseOutcome, reuse *reusableFiles) {
+	if uc.forkReuseRepo == nil || outcome.unhashed > 0 {
+		return
+	}
+	fingerprint := outcome.hashes.Fingerprint()
+	if fingerprint == nil {
+		return
+	}
+
+	duplicateOf := analysis.NilUUID
+	if reuse != nil && reuse.fork && bytes.Equal(fingerprint, reuse.hashes.Fingerprint()) {
+		duplicateOf = reuse.analysisID
+	}
+	if err := uc.forkReuseRepo.SaveFingerprint(ctx, analysisID, fingerprint, duplicateOf); err != nil {
+		slog.WarnContext(ctx, "failed to save test fingerprint (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+		return
+	}
+	if duplicateOf != analysis.NilUUID {
+		slog.InfoContext(ctx, "analysis deduplicated from another codebase",
+			"analysis_id", analysisID,
+			"duplicate_of", duplicateOf,
+		)
+	}
+}
+
+// resolve replaces the unchanged placeholders among files with the test files
+// of the previous analysis, keeping the hash and project assigned during this
+// analysis. Safe to call on nil.
+func (r *reusableFiles) resolve(ctx context.Context, files []analysis.TestFile) ([]analysis.TestFile, error) {
+	if r == nil {
+		return files, nil
+	}
+
+	var paths []string
+	for _, f := range files {
+		if f.Unchanged {
+			paths = append(paths, f.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return files, nil
+	}
+
+	reused, err := r.repo.GetTestFiles(ctx, r.analysisID, paths)
+	if err != nil {
+		return nil, fmt.Errorf("load unchanged test files: %w", err)
+	}
+	byPath := make(map[string]analysis.TestFile, len(reused))
+	for _, f := range reused {
+		byPath[f.Path] = f
+	}
+
+	for i, f := range files {
+		if !f.Unchanged {
+			continue
+		}
+		previous, ok := byPath[f.Path]
+		if !ok {
+			return nil, fmt.Errorf("unchanged test file %q not found in analysis %s", f.Path, r.analysisID)
+		}
+		previous.ContentHash = f.ContentHash
+		previous.Project = f.Project
+		files[i] = previous
+	}
+	return files, nil
+}
@@ -0,0 +1,20 @@
+package analysis
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer resolves the global provider lazily, so spans are exported once
+// the binary has installed one and are no-ops otherwise (e.g. in tests).
+var tracer = otel.Tracer("github.com/specvital/worker/internal/usecase/analysis")
+
+// endSpan marks span failed when err is set and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
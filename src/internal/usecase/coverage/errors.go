@@ -0,0 +1,8 @@
+package coverage
+
+import "errors"
+
+var (
+	ErrAnalysisMismatch = errors.New("upload does not belong to analysis")
+	ErrIngestFailed     = errors.New("failed to ingest coverage")
+)
@@ -0,0 +1,77 @@
+package coverage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/specvital/worker/internal/domain/coverage"
+)
+
+// IngestRequest names an uploaded coverage report.
+type IngestRequest struct {
+	AnalysisID string // optional; when set, the upload must belong to it
+	UploadID   string
+}
+
+// IngestSummary reports what an ingestion linked.
+type IngestSummary struct {
+	AlreadyProcessed bool
+	LinkedSources    int // source files linked to at least one test file
+	LinkedTestFiles  int
+	Sources          int
+}
+
+// IngestCoverageUseCase links an uploaded coverage report to the test files
+// of its analysis and annotates the spec behaviors of those tests.
+type IngestCoverageUseCase struct {
+	linker     coverage.Linker
+	repository coverage.Repository
+}
+
+// NewIngestCoverageUseCase creates a new IngestCoverageUseCase.
+func NewIngestCoverageUseCase(repository coverage.Repository, linker coverage.Linker) *IngestCoverageUseCase {
+	return &IngestCoverageUseCase{linker: linker, repository: repository}
+}
+
+// Execute parses the upload and saves the source files it links to test files.
+func (uc *IngestCoverageUseCase) Execute(ctx context.Context, req IngestRequest) (*IngestSummary, error) {
+	if req.UploadID == "" {
+		return nil, fmt.Errorf("%w: upload ID is required", coverage.ErrInvalidInput)
+	}
+
+	upload, err := uc.repository.GetUpload(ctx, req.UploadID)
+	if err != nil {
+		return nil, err
+	}
+	if req.AnalysisID != "" && req.AnalysisID != upload.AnalysisID {
+		return nil, ErrAnalysisMismatch
+	}
+
+	sources, err := coverage.Parse(upload.Format, bytes.NewReader(upload.Content))
+	if err != nil {
+		return nil, err
+	}
+
+	testFiles, err := uc.repository.GetTestFiles(ctx, upload.AnalysisID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIngestFailed, err)
+	}
+
+	links := uc.linker.Link(sources, testFiles)
+	saved, err := uc.repository.SaveLinks(ctx, upload, links)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIngestFailed, err)
+	}
+
+	linkedSources := make(map[string]struct{}, len(links))
+	for _, l := range links {
+		linkedSources[l.Source.Path] = struct{}{}
+	}
+	return &IngestSummary{
+		AlreadyProcessed: !saved,
+		LinkedSources:    len(linkedSources),
+		LinkedTestFiles:  len(links),
+		Sources:          len(sources),
+	}, nil
+}
@@ -0,0 +1,117 @@
+package coverage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/coverage"
+)
+
+const lcovReport = `SF:src/auth.ts
+DA:1,1
+DA:2,0
+end_of_record
+SF:src/billing.ts
+DA:1,0
+end_of_record
+`
+
+type mockRepository struct {
+	saveLinksFn func(ctx context.Context, upload *coverage.Upload, links []coverage.Link) (bool, error)
+	upload      *coverage.Upload
+}
+
+func (m *mockRepository) GetUpload(_ context.Context, uploadID string) (*coverage.Upload, error) {
+	if m.upload == nil || m.upload.ID != uploadID {
+		return nil, coverage.ErrUploadNotFound
+	}
+	return m.upload, nil
+}
+
+func (m *mockRepository) GetTestFiles(_ context.Context, _ string) ([]coverage.TestFile, error) {
+	return []coverage.TestFile{{ID: "tf-1", Path: "src/auth.test.ts"}, {ID: "tf-2", Path: "src/auth.spec.ts"}}, nil
+}
+
+func (m *mockRepository) SaveLinks(ctx context.Context, upload *coverage.Upload, links []coverage.Link) (bool, error) {
+	if m.saveLinksFn != nil {
+		return m.saveLinksFn(ctx, upload, links)
+	}
+	return true, nil
+}
+
+type mockLinker struct{}
+
+func (mockLinker) Link(sources []coverage.SourceFile, testFiles []coverage.TestFile) []coverage.Link {
+	links := make([]coverage.Link, 0, len(testFiles))
+	for _, tf := range testFiles {
+		links = append(links, coverage.Link{Source: sources[0], TestFileID: tf.ID})
+	}
+	return links
+}
+
+func TestIngestCoverageUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	upload := &coverage.Upload{AnalysisID: "an-1", Content: []byte(lcovReport), Format: coverage.FormatLCOV, ID: "up-1"}
+
+	t.Run("saves links and summarizes them", func(t *testing.T) {
+		var saved []coverage.Link
+		repo := &mockRepository{upload: upload, saveLinksFn: func(_ context.Context, _ *coverage.Upload, links []coverage.Link) (bool, error) {
+			saved = links
+			return true, nil
+		}}
+
+		summary, err := NewIngestCoverageUseCase(repo, mockLinker{}).Execute(ctx, IngestRequest{AnalysisID: "an-1", UploadID: "up-1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := IngestSummary{LinkedSources: 1, LinkedTestFiles: 2, Sources: 2}
+		if *summary != want {
+			t.Errorf("summary = %+v, want %+v", *summary, want)
+		}
+		if len(saved) != 2 || saved[0].Source.LinesCovered != 1 || saved[0].Source.LinesValid != 2 {
+			t.Errorf("unexpected saved links: %+v", saved)
+		}
+	})
+
+	t.Run("reports an already processed upload", func(t *testing.T) {
+		repo := &mockRepository{upload: upload, saveLinksFn: func(context.Context, *coverage.Upload, []coverage.Link) (bool, error) {
+			return false, nil
+		}}
+
+		summary, err := NewIngestCoverageUseCase(repo, mockLinker{}).Execute(ctx, IngestRequest{UploadID: "up-1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !summary.AlreadyProcessed {
+			t.Error("expected AlreadyProcessed")
+		}
+	})
+
+	t.Run("rejects an upload of another analysis", func(t *testing.T) {
+		repo := &mockRepository{upload: upload}
+
+		_, err := NewIngestCoverageUseCase(repo, mockLinker{}).Execute(ctx, IngestRequest{AnalysisID: "an-2", UploadID: "up-1"})
+		if !errors.Is(err, ErrAnalysisMismatch) {
+			t.Errorf("expected ErrAnalysisMismatch, got %v", err)
+		}
+	})
+
+	t.Run("wraps save failures", func(t *testing.T) {
+		repo := &mockRepository{upload: upload, saveLinksFn: func(context.Context, *coverage.Upload, []coverage.Link) (bool, error) {
+			return false, errors.New("connection reset")
+		}}
+
+		_, err := NewIngestCoverageUseCase(repo, mockLinker{}).Execute(ctx, IngestRequest{UploadID: "up-1"})
+		if !errors.Is(err, ErrIngestFailed) {
+			t.Errorf("expected ErrIngestFailed, got %v", err)
+		}
+	})
+
+	t.Run("requires an upload ID", func(t *testing.T) {
+		_, err := NewIngestCoverageUseCase(&mockRepository{}, mockLinker{}).Execute(ctx, IngestRequest{})
+		if !errors.Is(err, coverage.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
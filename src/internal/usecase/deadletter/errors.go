@@ -0,0 +1,7 @@
+package deadletter
+
+import "errors"
+
+var (
+	ErrListFailed = errors.New("failed to list discarded jobs")
+)
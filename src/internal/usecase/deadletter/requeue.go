@@ -0,0 +1,68 @@
+package deadletter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/specvital/worker/internal/domain/deadletter"
+)
+
+// RequeueResult reports the outcome of a requeue run.
+type RequeueResult struct {
+	Failed   map[int64]error
+	Requeued []deadletter.Job
+}
+
+// RequeueUseCase lists discarded jobs and re-enqueues selected ones.
+type RequeueUseCase struct {
+	repository deadletter.Repository
+	requeuer   deadletter.Requeuer
+}
+
+// NewRequeueUseCase creates a new RequeueUseCase.
+func NewRequeueUseCase(repository deadletter.Repository, requeuer deadletter.Requeuer) *RequeueUseCase {
+	return &RequeueUseCase{
+		repository: repository,
+		requeuer:   requeuer,
+	}
+}
+
+// List returns discarded jobs matching the filter.
+func (uc *RequeueUseCase) List(ctx context.Context, filter deadletter.Filter) ([]deadletter.Job, error) {
+	filter, err := filter.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := uc.repository.ListDiscardedJobs(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListFailed, err)
+	}
+	return jobs, nil
+}
+
+// Requeue re-enqueues the discarded jobs matching the filter.
+// Only jobs still discarded are touched, so stale or mistyped IDs are ignored.
+// A failure on one job does not stop the others.
+func (uc *RequeueUseCase) Requeue(ctx context.Context, filter deadletter.Filter) (*RequeueResult, error) {
+	jobs, err := uc.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RequeueResult{Failed: make(map[int64]error)}
+	for _, job := range jobs {
+		if err := uc.requeuer.RequeueJob(ctx, job.ID); err != nil {
+			slog.WarnContext(ctx, "failed to requeue job",
+				"job_id", job.ID,
+				"kind", job.Kind,
+				"error", err,
+			)
+			result.Failed[job.ID] = err
+			continue
+		}
+		result.Requeued = append(result.Requeued, job)
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/deadletter"
+)
+
+type mockRepository struct {
+	err    error
+	filter deadletter.Filter
+	jobs   []deadletter.Job
+}
+
+func (m *mockRepository) ListDiscardedJobs(ctx context.Context, filter deadletter.Filter) ([]deadletter.Job, error) {
+	m.filter = filter
+	return m.jobs, m.err
+}
+
+type mockRequeuer struct {
+	failIDs  map[int64]bool
+	requeued []int64
+}
+
+func (m *mockRequeuer) RequeueJob(ctx context.Context, jobID int64) error {
+	if m.failIDs[jobID] {
+		return errors.New("unique conflict")
+	}
+	m.requeued = append(m.requeued, jobID)
+	return nil
+}
+
+func TestRequeueUseCase_List(t *testing.T) {
+	t.Run("applies default limit", func(t *testing.T) {
+		repo := &mockRepository{}
+		uc := NewRequeueUseCase(repo, &mockRequeuer{})
+
+		if _, err := uc.List(context.Background(), deadletter.Filter{Kinds: []string{"analysis:analyze"}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.filter.Limit != deadletter.DefaultLimit {
+			t.Errorf("expected default limit %d, got %d", deadletter.DefaultLimit, repo.filter.Limit)
+		}
+	})
+
+	t.Run("rejects filter without kinds", func(t *testing.T) {
+		uc := NewRequeueUseCase(&mockRepository{}, &mockRequeuer{})
+
+		_, err := uc.List(context.Background(), deadletter.Filter{})
+		if !errors.Is(err, deadletter.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("wraps repository error", func(t *testing.T) {
+		uc := NewRequeueUseCase(&mockRepository{err: errors.New("db down")}, &mockRequeuer{})
+
+		_, err := uc.List(context.Background(), deadletter.Filter{Kinds: []string{"analysis:analyze"}})
+		if !errors.Is(err, ErrListFailed) {
+			t.Errorf("expected ErrListFailed, got %v", err)
+		}
+	})
+}
+
+func TestRequeueUseCase_Requeue(t *testing.T) {
+	repo := &mockRepository{jobs: []deadletter.Job{
+		{ID: 1, Kind: "analysis:analyze"},
+		{ID: 2, Kind: "specview:generate"},
+		{ID: 3, Kind: "specview:generate"},
+	}}
+	requeuer := &mockRequeuer{failIDs: map[int64]bool{2: true}}
+	uc := NewRequeueUseCase(repo, requeuer)
+
+	result, err := uc.Requeue(context.Background(), deadletter.Filter{
+		JobIDs: []int64{1, 2, 3},
+		Kinds:  []string{"analysis:analyze", "specview:generate"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Requeued) != 2 || result.Requeued[0].ID != 1 || result.Requeued[1].ID != 3 {
+		t.Errorf("unexpected requeued jobs: %+v", result.Requeued)
+	}
+	if _, ok := result.Failed[2]; !ok || len(result.Failed) != 1 {
+		t.Errorf("expected job 2 to fail, got %v", result.Failed)
+	}
+	if len(repo.filter.JobIDs) != 3 {
+		t.Errorf("expected job IDs to be passed to repository, got %v", repo.filter.JobIDs)
+	}
+}
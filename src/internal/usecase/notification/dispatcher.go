@@ -0,0 +1,203 @@
+// Package notification tells the channels of a codebase or user about
+// finished jobs without holding up the jobs.
+package notification
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/notification"
+)
+
+const (
+	DefaultDrainTimeout = 10 * time.Second
+	DefaultQueueSize    = 256
+	DefaultSendTimeout  = 10 * time.Second
+	DefaultWorkers      = 2
+)
+
+// Dispatcher sends outcomes from a bounded queue. Notify never blocks: when
+// the queue is full the outcome is dropped, so a slow channel costs
+// notifications, never job throughput.
+type Dispatcher struct {
+	drainTimeout time.Duration
+	queueSize    int
+	repository   notification.Repository
+	sendTimeout  time.Duration
+	sender       notification.Sender
+	workers      int
+
+	cancel context.CancelFunc
+	closed bool
+	mu     sync.RWMutex
+	queue  chan notification.Outcome
+	wg     sync.WaitGroup
+}
+
+// Option configures Dispatcher.
+type Option func(*Dispatcher)
+
+// WithDrainTimeout sets how long Close waits for queued outcomes before
+// abandoning them. Non-positive values are ignored.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(dp *Dispatcher) {
+		if d > 0 {
+			dp.drainTimeout = d
+		}
+	}
+}
+
+// WithQueueSize sets how many outcomes wait to be sent before new ones are
+// dropped. Non-positive values are ignored.
+func WithQueueSize(n int) Option {
+	return func(dp *Dispatcher) {
+		if n > 0 {
+			dp.queueSize = n
+		}
+	}
+}
+
+// WithSendTimeout bounds resolving an outcome and each send to a channel.
+// Non-positive values are ignored.
+func WithSendTimeout(d time.Duration) Option {
+	return func(dp *Dispatcher) {
+		if d > 0 {
+			dp.sendTimeout = d
+		}
+	}
+}
+
+// WithWorkers sets how many outcomes are sent concurrently. Non-positive values are ignored.
+func WithWorkers(n int) Option {
+	return func(dp *Dispatcher) {
+		if n > 0 {
+			dp.workers = n
+		}
+	}
+}
+
+// NewDispatcher creates a dispatcher and starts its workers. Close stops them.
+func NewDispatcher(repository notification.Repository, sender notification.Sender, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		drainTimeout: DefaultDrainTimeout,
+		queueSize:    DefaultQueueSize,
+		repository:   repository,
+		sendTimeout:  DefaultSendTimeout,
+		sender:       sender,
+		workers:      DefaultWorkers,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.queue = make(chan notification.Outcome, d.queueSize)
+	for range d.workers {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for outcome := range d.queue {
+				d.dispatch(ctx, outcome)
+			}
+		}()
+	}
+	return d
+}
+
+// Notify queues an outcome and reports whether it was accepted. Outcomes
+// arriving after Close or while the queue is full are dropped.
+func (d *Dispatcher) Notify(ctx context.Context, outcome notification.Outcome) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return false
+	}
+	select {
+	case d.queue <- outcome:
+		return true
+	default:
+		slog.WarnContext(ctx, "notification queue full, dropping outcome (non-critical)",
+			"job_id", outcome.JobID,
+			"subject", outcome.Subject,
+		)
+		return false
+	}
+}
+
+// Close stops accepting outcomes and sends the queued ones, cancelling the
+// sends still running after the drain timeout.
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	close(d.queue)
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(d.drainTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		slog.Warn("notification drain timed out, abandoning queued outcomes", "queued", len(d.queue))
+		d.cancel()
+		<-done
+	}
+	d.cancel()
+}
+
+// dispatch resolves an outcome and sends it to every channel wanting it.
+// Failures are logged; a failed send is not retried.
+func (d *Dispatcher) dispatch(ctx context.Context, outcome notification.Outcome) {
+	if ctx.Err() != nil {
+		return
+	}
+	resolveCtx, cancel := context.WithTimeout(ctx, d.sendTimeout)
+	defer cancel()
+
+	resolved, err := d.repository.ResolveOutcome(resolveCtx, outcome)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to resolve job outcome for notifications (non-critical)",
+			"job_id", outcome.JobID,
+			"error", err,
+		)
+		resolved = outcome
+	}
+
+	channels, err := d.repository.ChannelsFor(resolveCtx, resolved)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to list notification channels (non-critical)",
+			"job_id", outcome.JobID,
+			"error", err,
+		)
+		return
+	}
+
+	for _, channel := range channels {
+		if ctx.Err() != nil {
+			return
+		}
+		sendCtx, cancelSend := context.WithTimeout(ctx, d.sendTimeout)
+		err := d.sender.Send(sendCtx, channel, resolved)
+		cancelSend()
+		if err != nil {
+			slog.WarnContext(ctx, "failed to send notification (non-critical)",
+				"job_id", outcome.JobID,
+				"channel_id", channel.ID,
+				"kind", channel.Kind,
+				"error", err,
+			)
+		}
+	}
+}
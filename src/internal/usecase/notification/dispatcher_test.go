@@ -0,0 +1,179 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/notification"
+)
+
+type fakeRepository struct {
+	channels   []notification.Channel
+	resolveErr error
+}
+
+func (f *fakeRepository) CreateChannel(context.Context, notification.Channel) (*notification.Channel, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeRepository) DeleteChannel(context.Context, string) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeRepository) GetChannel(context.Context, string) (*notification.Channel, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeRepository) ListChannels(context.Context, string, string) ([]notification.Channel, error) {
+	return f.channels, nil
+}
+
+func (f *fakeRepository) ChannelsFor(_ context.Context, outcome notification.Outcome) ([]notification.Channel, error) {
+	var matched []notification.Channel
+	for _, c := range f.channels {
+		if (c.CodebaseID != "" && c.CodebaseID == outcome.CodebaseID) || (c.UserID != "" && c.UserID == outcome.UserID) {
+			if (outcome.Failed() && c.OnFailure) || (!outcome.Failed() && c.OnSuccess) {
+				matched = append(matched, c)
+			}
+		}
+	}
+	return matched, nil
+}
+
+func (f *fakeRepository) ResolveOutcome(_ context.Context, outcome notification.Outcome) (notification.Outcome, error) {
+	if f.resolveErr != nil {
+		return outcome, f.resolveErr
+	}
+	outcome.CodebaseID = "c1"
+	outcome.TestCount = 10
+	return outcome, nil
+}
+
+type sent struct {
+	channelID string
+	outcome   notification.Outcome
+}
+
+type fakeSender struct {
+	block   chan struct{}
+	err     error
+	mu      sync.Mutex
+	sent    []sent
+	started chan struct{}
+}
+
+func (f *fakeSender) Send(ctx context.Context, channel notification.Channel, outcome notification.Outcome) error {
+	if f.started != nil {
+		f.started <- struct{}{}
+	}
+	if f.block != nil {
+		select {
+		case <-f.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, sent{channelID: channel.ID, outcome: outcome})
+	return f.err
+}
+
+func TestDispatcher_SendsToMatchingChannels(t *testing.T) {
+	repo := &fakeRepository{channels: []notification.Channel{
+		{ID: "codebase-all", CodebaseID: "c1", OnSuccess: true, OnFailure: true},
+		{ID: "codebase-failures", CodebaseID: "c1", OnFailure: true},
+		{ID: "user", UserID: "u1", OnSuccess: true},
+		{ID: "other-codebase", CodebaseID: "c2", OnSuccess: true},
+	}}
+	sender := &fakeSender{}
+	d := NewDispatcher(repo, sender)
+
+	if !d.Notify(context.Background(), notification.Outcome{JobID: 1, Subject: notification.SubjectAnalysis, UserID: "u1"}) {
+		t.Fatal("expected the outcome to be queued")
+	}
+	d.Close()
+
+	got := map[string]bool{}
+	for _, s := range sender.sent {
+		got[s.channelID] = true
+		if s.outcome.TestCount != 10 {
+			t.Errorf("expected the resolved outcome to be sent, got %+v", s.outcome)
+		}
+	}
+	if len(sender.sent) != 2 || !got["codebase-all"] || !got["user"] {
+		t.Errorf("sent to %v, want codebase-all and user", got)
+	}
+}
+
+func TestDispatcher_SendsUnresolvedOutcome(t *testing.T) {
+	repo := &fakeRepository{
+		channels:   []notification.Channel{{ID: "user", UserID: "u1", OnFailure: true}},
+		resolveErr: errors.New("db down"),
+	}
+	sender := &fakeSender{err: errors.New("channel down")}
+	d := NewDispatcher(repo, sender)
+
+	d.Notify(context.Background(), notification.Outcome{ErrorClass: "clone_failed", JobID: 1, UserID: "u1"})
+	d.Close()
+
+	if len(sender.sent) != 1 || sender.sent[0].outcome.CodebaseID != "" {
+		t.Errorf("expected the unresolved outcome to reach the user channel, got %+v", sender.sent)
+	}
+}
+
+func TestDispatcher_DropsWhenFull(t *testing.T) {
+	repo := &fakeRepository{channels: []notification.Channel{{ID: "user", UserID: "u1", OnSuccess: true}}}
+	sender := &fakeSender{block: make(chan struct{}), started: make(chan struct{}, 1)}
+	d := NewDispatcher(repo, sender, WithQueueSize(1), WithWorkers(1))
+
+	outcome := notification.Outcome{UserID: "u1"}
+	d.Notify(context.Background(), outcome)
+	<-sender.started // the worker holds the first outcome
+
+	if !d.Notify(context.Background(), outcome) {
+		t.Error("expected the second outcome to fill the queue")
+	}
+	done := make(chan bool)
+	go func() { done <- d.Notify(context.Background(), outcome) }()
+	select {
+	case accepted := <-done:
+		if accepted {
+			t.Error("expected the outcome to be dropped when the queue is full")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Notify blocked on a full queue")
+	}
+
+	close(sender.block)
+	d.Close()
+	if d.Notify(context.Background(), outcome) {
+		t.Error("expected outcomes after Close to be dropped")
+	}
+}
+
+func TestDispatcher_CloseAbandonsAfterDrainTimeout(t *testing.T) {
+	repo := &fakeRepository{channels: []notification.Channel{{ID: "user", UserID: "u1", OnSuccess: true}}}
+	sender := &fakeSender{block: make(chan struct{})}
+	d := NewDispatcher(repo, sender, WithDrainTimeout(20*time.Millisecond), WithWorkers(1))
+
+	d.Notify(context.Background(), notification.Outcome{UserID: "u1"})
+	d.Notify(context.Background(), notification.Outcome{UserID: "u1"})
+
+	closed := make(chan struct{})
+	go func() {
+		d.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not return after the drain timeout")
+	}
+	if len(sender.sent) != 0 {
+		t.Errorf("expected the queued outcomes to be abandoned, got %d sent", len(sender.sent))
+	}
+}
@@ -0,0 +1,232 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/outbox"
+)
+
+const (
+	DefaultBatchSize    = 50
+	DefaultLease        = 5 * time.Minute
+	DefaultPollInterval = 5 * time.Second
+	DefaultRetention    = 7 * 24 * time.Hour
+
+	// purgeInterval spaces the deletion of settled events; they are only
+	// kept for inspection, so deleting them late costs nothing.
+	purgeInterval = time.Hour
+	// maxErrorLength bounds the sink error stored with a failed event.
+	maxErrorLength = 1000
+)
+
+// RoundResult reports one relay round.
+type RoundResult struct {
+	Claimed   int
+	Failed    int // publishes that failed and were rescheduled
+	GaveUp    int // events that failed their last attempt
+	Published int
+}
+
+// RelayUseCase publishes outbox events to a sink. Relays may run
+// concurrently: each claims its events under a lease, so an event is
+// published by one relay at a time.
+type RelayUseCase struct {
+	batchSize    int
+	lease        time.Duration
+	maxAttempts  int
+	now          func() time.Time
+	pollInterval time.Duration
+	repository   outbox.Repository
+	retention    time.Duration
+	sink         outbox.Sink
+}
+
+// Option configures RelayUseCase.
+type Option func(*RelayUseCase)
+
+// WithBatchSize sets how many events a round claims. Non-positive values are ignored.
+func WithBatchSize(n int) Option {
+	return func(uc *RelayUseCase) {
+		if n > 0 {
+			uc.batchSize = n
+		}
+	}
+}
+
+// WithLease sets how long claimed events are held by the relay. It must
+// outlast publishing a whole batch, or another relay publishes the rest
+// again. Non-positive values are ignored.
+func WithLease(d time.Duration) Option {
+	return func(uc *RelayUseCase) {
+		if d > 0 {
+			uc.lease = d
+		}
+	}
+}
+
+// WithMaxAttempts sets how often an event is offered to the sink. Non-positive values are ignored.
+func WithMaxAttempts(n int) Option {
+	return func(uc *RelayUseCase) {
+		if n > 0 {
+			uc.maxAttempts = n
+		}
+	}
+}
+
+// WithPollInterval sets how long Run waits after a round that drained the
+// outbox. Non-positive values are ignored.
+func WithPollInterval(d time.Duration) Option {
+	return func(uc *RelayUseCase) {
+		if d > 0 {
+			uc.pollInterval = d
+		}
+	}
+}
+
+// WithRetention sets how long published and abandoned events are kept.
+// Non-positive values are ignored.
+func WithRetention(d time.Duration) Option {
+	return func(uc *RelayUseCase) {
+		if d > 0 {
+			uc.retention = d
+		}
+	}
+}
+
+// NewRelayUseCase creates a new RelayUseCase.
+func NewRelayUseCase(repository outbox.Repository, sink outbox.Sink, opts ...Option) *RelayUseCase {
+	uc := &RelayUseCase{
+		batchSize:    DefaultBatchSize,
+		lease:        DefaultLease,
+		maxAttempts:  outbox.DefaultMaxAttempts,
+		now:          time.Now,
+		pollInterval: DefaultPollInterval,
+		repository:   repository,
+		retention:    DefaultRetention,
+		sink:         sink,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// RunOnce claims a batch of due events and publishes them in order. An event
+// whose outcome cannot be recorded is published again once its lease ends.
+func (uc *RelayUseCase) RunOnce(ctx context.Context) (RoundResult, error) {
+	var result RoundResult
+
+	events, err := uc.repository.ClaimEvents(ctx, uc.batchSize, uc.maxAttempts, uc.now().Add(uc.lease))
+	if err != nil {
+		return result, err
+	}
+	result.Claimed = len(events)
+
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		publishErr := uc.sink.Publish(ctx, event)
+		// Record the outcome even when ctx ends mid-publish.
+		recordCtx := context.WithoutCancel(ctx)
+		if publishErr == nil {
+			if err := uc.repository.MarkPublished(recordCtx, event.ID); err != nil {
+				return result, err
+			}
+			result.Published++
+			continue
+		}
+
+		if event.Attempts >= uc.maxAttempts {
+			result.GaveUp++
+			slog.ErrorContext(ctx, "giving up on outbox event",
+				"event_id", event.ID,
+				"event_type", event.Type,
+				"attempts", event.Attempts,
+				"error", publishErr,
+			)
+		} else {
+			result.Failed++
+			slog.WarnContext(ctx, "failed to publish outbox event, will retry",
+				"event_id", event.ID,
+				"event_type", event.Type,
+				"attempts", event.Attempts,
+				"error", publishErr,
+			)
+		}
+		next := uc.now().Add(outbox.Backoff(event.Attempts))
+		if err := uc.repository.MarkFailed(recordCtx, event.ID, next, truncateError(publishErr)); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// Purge deletes the events published, or abandoned, longer than the
+// retention ago, and reports how many.
+func (uc *RelayUseCase) Purge(ctx context.Context) (int, error) {
+	before := uc.now().Add(-uc.retention)
+	total := 0
+	for {
+		n, err := uc.repository.DeleteSettled(ctx, before, uc.maxAttempts, uc.batchSize)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("purge outbox: %w", err)
+		}
+		if n < uc.batchSize {
+			return total, nil
+		}
+	}
+}
+
+// Run relays until ctx ends. A full batch is followed by the next round right
+// away; otherwise Run waits the poll interval. Failed rounds are logged and
+// retried, and settled events are purged every hour. onRound, if set, sees
+// every successful round.
+func (uc *RelayUseCase) Run(ctx context.Context, onRound func(RoundResult)) error {
+	ticker := time.NewTicker(uc.pollInterval)
+	defer ticker.Stop()
+
+	var lastPurge time.Time
+	for {
+		if now := uc.now(); now.Sub(lastPurge) >= purgeInterval {
+			if n, err := uc.Purge(ctx); err != nil {
+				slog.WarnContext(ctx, "failed to purge outbox (non-critical)", "error", err)
+			} else if n > 0 {
+				slog.InfoContext(ctx, "purged outbox events", "deleted", n)
+			}
+			lastPurge = now
+		}
+
+		result, err := uc.RunOnce(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.ErrorContext(ctx, "outbox relay round failed", "error", err)
+		} else if onRound != nil {
+			onRound(result)
+		}
+
+		if err == nil && result.Claimed == uc.batchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func truncateError(err error) string {
+	msg := err.Error()
+	if len(msg) > maxErrorLength {
+		return msg[:maxErrorLength]
+	}
+	return msg
+}
@@ -0,0 +1,172 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/outbox"
+)
+
+// fakeRepository keeps events in memory and claims them like the database:
+// due, unpublished and not out of attempts, oldest first.
+type fakeRepository struct {
+	events    []*fakeEvent
+	now       time.Time
+	published []string
+}
+
+type fakeEvent struct {
+	outbox.Event
+	lastError     string
+	nextAttemptAt time.Time
+	publishedAt   time.Time
+}
+
+func (f *fakeRepository) add(n int) {
+	for range n {
+		id := fmt.Sprintf("e%d", len(f.events)+1)
+		f.events = append(f.events, &fakeEvent{Event: outbox.Event{ID: id, Type: outbox.EventAnalysisCompleted, CreatedAt: f.now}})
+	}
+}
+
+func (f *fakeRepository) find(id string) *fakeEvent {
+	for _, e := range f.events {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) ClaimEvents(_ context.Context, limit, maxAttempts int, leaseUntil time.Time) ([]outbox.Event, error) {
+	var claimed []outbox.Event
+	for _, e := range f.events {
+		if len(claimed) == limit {
+			break
+		}
+		if e.publishedAt.IsZero() && !e.nextAttemptAt.After(f.now) && e.Attempts < maxAttempts {
+			e.Attempts++
+			e.nextAttemptAt = leaseUntil
+			claimed = append(claimed, e.Event)
+		}
+	}
+	return claimed, nil
+}
+
+func (f *fakeRepository) MarkPublished(_ context.Context, id string) error {
+	f.find(id).publishedAt = f.now
+	f.published = append(f.published, id)
+	return nil
+}
+
+func (f *fakeRepository) MarkFailed(_ context.Context, id string, nextAttemptAt time.Time, errMessage string) error {
+	e := f.find(id)
+	e.nextAttemptAt, e.lastError = nextAttemptAt, errMessage
+	return nil
+}
+
+func (f *fakeRepository) DeleteSettled(_ context.Context, before time.Time, maxAttempts, limit int) (int, error) {
+	kept := f.events[:0]
+	deleted := 0
+	for _, e := range f.events {
+		settled := (!e.publishedAt.IsZero() && e.publishedAt.Before(before)) ||
+			(e.publishedAt.IsZero() && e.Attempts >= maxAttempts && e.CreatedAt.Before(before))
+		if settled && deleted < limit {
+			deleted++
+			continue
+		}
+		kept = append(kept, e)
+	}
+	f.events = kept
+	return deleted, nil
+}
+
+type fakeSink struct {
+	fail map[string]bool
+	seen []string
+}
+
+func (s *fakeSink) Publish(_ context.Context, event outbox.Event) error {
+	s.seen = append(s.seen, event.ID)
+	if s.fail[event.ID] {
+		return errors.New("sink unavailable")
+	}
+	return nil
+}
+
+func TestRelayUseCase_RunOnce(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	repo := &fakeRepository{now: now}
+	repo.add(3)
+	sink := &fakeSink{fail: map[string]bool{"e2": true}}
+
+	uc := NewRelayUseCase(repo, sink, WithBatchSize(10), WithMaxAttempts(2))
+	uc.now = func() time.Time { return repo.now }
+
+	result, err := uc.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if result != (RoundResult{Claimed: 3, Failed: 1, Published: 2}) {
+		t.Errorf("result = %+v", result)
+	}
+	failed := repo.find("e2")
+	if failed.lastError != "sink unavailable" || !failed.nextAttemptAt.Equal(now.Add(outbox.Backoff(1))) {
+		t.Errorf("failed event = %+v", failed)
+	}
+
+	t.Run("retries after the backoff and then gives up", func(t *testing.T) {
+		if result, _ := uc.RunOnce(context.Background()); result.Claimed != 0 {
+			t.Errorf("expected nothing due before the backoff ends, got %+v", result)
+		}
+
+		repo.now = now.Add(outbox.Backoff(1))
+		result, err := uc.RunOnce(context.Background())
+		if err != nil {
+			t.Fatalf("RunOnce failed: %v", err)
+		}
+		if result != (RoundResult{Claimed: 1, GaveUp: 1}) {
+			t.Errorf("result = %+v", result)
+		}
+
+		repo.now = repo.now.Add(24 * time.Hour)
+		if result, _ := uc.RunOnce(context.Background()); result.Claimed != 0 {
+			t.Errorf("expected an abandoned event not to be claimed, got %+v", result)
+		}
+	})
+
+	t.Run("purges settled events past the retention", func(t *testing.T) {
+		repo.now = now.Add(DefaultRetention + time.Hour)
+		n, err := uc.Purge(context.Background())
+		if err != nil || n != 3 || len(repo.events) != 0 {
+			t.Errorf("purged %d (%v), %d left", n, err, len(repo.events))
+		}
+	})
+}
+
+func TestRelayUseCase_Run(t *testing.T) {
+	repo := &fakeRepository{now: time.Now()}
+	repo.add(5)
+	sink := &fakeSink{}
+
+	uc := NewRelayUseCase(repo, sink, WithBatchSize(2), WithPollInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var rounds []RoundResult
+	err := uc.Run(ctx, func(r RoundResult) {
+		rounds = append(rounds, r)
+		if r.Claimed < 2 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the run to end with ctx, got %v", err)
+	}
+	// Full batches are followed by the next round without waiting.
+	if len(rounds) != 3 || len(repo.published) != 5 {
+		t.Errorf("rounds = %+v, published = %v", rounds, repo.published)
+	}
+}
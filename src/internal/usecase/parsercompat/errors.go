@@ -0,0 +1,8 @@
+package parsercompat
+
+import "errors"
+
+var (
+	ErrSampleFailed = errors.New("failed to load samples")
+	ErrSaveFailed   = errors.New("failed to save compatibility report")
+)
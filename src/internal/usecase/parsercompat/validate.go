@@ -0,0 +1,173 @@
+package parsercompat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/parsercompat"
+)
+
+const (
+	DefaultSampleSize    = 20
+	DefaultSampleTimeout = 10 * time.Minute
+)
+
+// ValidateRequest configures a dual-parse validation run.
+type ValidateRequest struct {
+	CandidateVersion string // core version linked into this binary
+	SampleSize       int    // 0 means DefaultSampleSize
+}
+
+// Option is a functional option for configuring ValidateUseCase.
+type Option func(*ValidateUseCase)
+
+// WithSampleTimeout sets the clone+parse timeout per sampled repository.
+func WithSampleTimeout(d time.Duration) Option {
+	return func(uc *ValidateUseCase) {
+		if d > 0 {
+			uc.sampleTimeout = d
+		}
+	}
+}
+
+// ValidateUseCase re-parses sampled repositories with the current parser and
+// compares the result with the inventory stored by the previous parser.
+// Only one core version can be linked into a binary, so the stored inventory
+// stands in for the "old" parse; samples whose HEAD moved are skipped.
+type ValidateUseCase struct {
+	parser        analysis.Parser
+	repository    parsercompat.Repository
+	sampleTimeout time.Duration
+	vcs           analysis.VCS
+}
+
+// NewValidateUseCase creates a new ValidateUseCase.
+func NewValidateUseCase(
+	repository parsercompat.Repository,
+	vcs analysis.VCS,
+	parser analysis.Parser,
+	opts ...Option,
+) *ValidateUseCase {
+	uc := &ValidateUseCase{
+		parser:        parser,
+		repository:    repository,
+		sampleTimeout: DefaultSampleTimeout,
+		vcs:           vcs,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute validates a sample and persists the report.
+// Per-sample failures are recorded in the report rather than aborting the run.
+func (uc *ValidateUseCase) Execute(ctx context.Context, req ValidateRequest) (*parsercompat.Report, error) {
+	if req.CandidateVersion == "" {
+		return nil, fmt.Errorf("%w: candidate version is required", parsercompat.ErrInvalidInput)
+	}
+	sampleSize := req.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = DefaultSampleSize
+	}
+
+	samples, err := uc.repository.ListSamples(ctx, req.CandidateVersion, sampleSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSampleFailed, err)
+	}
+
+	report := &parsercompat.Report{
+		CandidateVersion: req.CandidateVersion,
+		CreatedAt:        time.Now(),
+	}
+	for _, sample := range samples {
+		result := uc.validateSample(ctx, sample)
+		slog.InfoContext(ctx, "parser compatibility sample",
+			"owner", sample.Owner,
+			"repo", sample.Repo,
+			"baseline_version", sample.ParserVersion,
+			"status", result.Status,
+			"baseline_tests", result.BaselineTests,
+			"candidate_tests", result.CandidateTests,
+		)
+		report.Results = append(report.Results, result)
+	}
+
+	if err := uc.repository.SaveReport(ctx, report); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSaveFailed, err)
+	}
+	return report, nil
+}
+
+func (uc *ValidateUseCase) validateSample(ctx context.Context, sample parsercompat.Sample) parsercompat.Result {
+	result := parsercompat.Result{Sample: sample}
+
+	sampleCtx, cancel := context.WithTimeout(ctx, uc.sampleTimeout)
+	defer cancel()
+
+	baseline, err := uc.repository.GetFileTestCounts(sampleCtx, sample.AnalysisID)
+	if err != nil {
+		return failed(result, "load baseline", err)
+	}
+	result.BaselineTests = parsercompat.Total(baseline)
+
+	repoURL := fmt.Sprintf("https://github.com/%s/%s", sample.Owner, sample.Repo)
+	head, err := uc.vcs.GetHeadCommit(sampleCtx, repoURL, nil, "")
+	if err != nil {
+		return failed(result, "get head commit", err)
+	}
+	if head.SHA != sample.CommitSHA {
+		result.Status = parsercompat.StatusSkipped
+		result.Reason = "HEAD moved since baseline analysis"
+		return result
+	}
+
+	src, err := uc.vcs.Clone(sampleCtx, repoURL, nil, "")
+	if err != nil {
+		return failed(result, "clone", err)
+	}
+	defer func() {
+		if err := src.Close(context.Background()); err != nil {
+			slog.WarnContext(ctx, "failed to close source", "owner", sample.Owner, "repo", sample.Repo, "error", err)
+		}
+	}()
+	if src.CommitSHA() != sample.CommitSHA {
+		result.Status = parsercompat.StatusSkipped
+		result.Reason = "HEAD moved during validation"
+		return result
+	}
+
+	inventory, err := uc.parser.Scan(sampleCtx, src)
+	if err != nil {
+		return failed(result, "parse", err)
+	}
+
+	files := inventory.Files
+	if !sample.Rules.IsEmpty() {
+		files = make([]analysis.TestFile, 0, len(inventory.Files))
+		for _, f := range inventory.Files {
+			if !sample.Rules.ExcludesFile(f.Path) {
+				files = append(files, f)
+			}
+		}
+	}
+	candidate := parsercompat.CountTests(files)
+	result.CandidateTests = parsercompat.Total(candidate)
+	result.FileDiffs = parsercompat.DiffTestCounts(baseline, candidate)
+
+	if len(result.FileDiffs) == 0 {
+		result.Status = parsercompat.StatusMatch
+	} else {
+		result.Status = parsercompat.StatusMismatch
+	}
+	return result
+}
+
+func failed(result parsercompat.Result, step string, err error) parsercompat.Result {
+	result.Status = parsercompat.StatusFailed
+	result.Reason = fmt.Sprintf("%s: %v", step, err)
+	return result
+}
@@ -0,0 +1,179 @@
+package parsercompat
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/curation"
+	"github.com/specvital/worker/internal/domain/parsercompat"
+)
+
+type mockRepository struct {
+	baseline    map[string]int
+	baselineErr error
+	samples     []parsercompat.Sample
+	saved       *parsercompat.Report
+	saveErr     error
+}
+
+func (m *mockRepository) ListSamples(ctx context.Context, candidateVersion string, limit int) ([]parsercompat.Sample, error) {
+	return m.samples, nil
+}
+
+func (m *mockRepository) GetFileTestCounts(ctx context.Context, analysisID analysis.UUID) (map[string]int, error) {
+	return m.baseline, m.baselineErr
+}
+
+func (m *mockRepository) SaveReport(ctx context.Context, report *parsercompat.Report) error {
+	m.saved = report
+	return m.saveErr
+}
+
+type mockVCS struct {
+	cloneErr error
+	headSHA  string
+}
+
+func (m *mockVCS) Clone(ctx context.Context, url string, token *string, branch string) (analysis.Source, error) {
+	if m.cloneErr != nil {
+		return nil, m.cloneErr
+	}
+	return &mockSource{sha: m.headSHA}, nil
+}
+
+func (m *mockVCS) GetHeadCommit(ctx context.Context, url string, token *string, branch string) (analysis.CommitInfo, error) {
+	return analysis.CommitInfo{SHA: m.headSHA}, nil
+}
+
+type mockSource struct {
+	closed bool
+	sha    string
+}
+
+func (m *mockSource) Branch() string                  { return "main" }
+func (m *mockSource) CommitSHA() string               { return m.sha }
+func (m *mockSource) CommittedAt() time.Time          { return time.Time{} }
+func (m *mockSource) Close(ctx context.Context) error { m.closed = true; return nil }
+func (m *mockSource) VerifyCommitExists(ctx context.Context, sha string) (bool, error) {
+	return true, nil
+}
+
+type mockParser struct {
+	files []analysis.TestFile
+}
+
+func (m *mockParser) Scan(ctx context.Context, src analysis.Source) (*analysis.Inventory, error) {
+	return &analysis.Inventory{Files: m.files}, nil
+}
+
+func testFile(path string, tests int) analysis.TestFile {
+	f := analysis.TestFile{Path: path}
+	for range tests {
+		f.Tests = append(f.Tests, analysis.Test{Name: "t"})
+	}
+	return f
+}
+
+func TestValidateUseCase_Execute(t *testing.T) {
+	sample := parsercompat.Sample{CommitSHA: "abc", Owner: "o", ParserVersion: "v1.0.0", Repo: "r"}
+
+	t.Run("match", func(t *testing.T) {
+		repo := &mockRepository{baseline: map[string]int{"a_test.go": 2}, samples: []parsercompat.Sample{sample}}
+		uc := NewValidateUseCase(repo, &mockVCS{headSHA: "abc"}, &mockParser{files: []analysis.TestFile{testFile("a_test.go", 2)}})
+
+		report, err := uc.Execute(context.Background(), ValidateRequest{CandidateVersion: "v1.1.0"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.saved != report {
+			t.Error("expected report to be saved")
+		}
+		if report.Results[0].Status != parsercompat.StatusMatch || !report.Compatible() {
+			t.Errorf("expected match, got %+v", report.Results[0])
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		repo := &mockRepository{baseline: map[string]int{"a_test.go": 2}, samples: []parsercompat.Sample{sample}}
+		uc := NewValidateUseCase(repo, &mockVCS{headSHA: "abc"}, &mockParser{files: []analysis.TestFile{testFile("a_test.go", 1)}})
+
+		report, _ := uc.Execute(context.Background(), ValidateRequest{CandidateVersion: "v1.1.0"})
+
+		res := report.Results[0]
+		if res.Status != parsercompat.StatusMismatch || report.Compatible() {
+			t.Errorf("expected mismatch, got %+v", res)
+		}
+		if len(res.FileDiffs) != 1 || res.FileDiffs[0].Candidate != 1 {
+			t.Errorf("unexpected diffs: %+v", res.FileDiffs)
+		}
+	})
+
+	t.Run("applies curation exclusions to candidate", func(t *testing.T) {
+		rules, err := curation.Parse([]byte("exclude:\n  - \"vendor/**\"\n"))
+		if err != nil {
+			t.Fatalf("parse rules: %v", err)
+		}
+		curated := sample
+		curated.Rules = rules
+		repo := &mockRepository{baseline: map[string]int{"a_test.go": 1}, samples: []parsercompat.Sample{curated}}
+		uc := NewValidateUseCase(repo, &mockVCS{headSHA: "abc"}, &mockParser{files: []analysis.TestFile{
+			testFile("a_test.go", 1),
+			testFile("vendor/x_test.go", 3),
+		}})
+
+		report, _ := uc.Execute(context.Background(), ValidateRequest{CandidateVersion: "v1.1.0"})
+
+		if report.Results[0].Status != parsercompat.StatusMatch {
+			t.Errorf("expected excluded files to be ignored, got %+v", report.Results[0])
+		}
+	})
+
+	t.Run("skips moved HEAD", func(t *testing.T) {
+		repo := &mockRepository{baseline: map[string]int{}, samples: []parsercompat.Sample{sample}}
+		uc := NewValidateUseCase(repo, &mockVCS{headSHA: "def"}, &mockParser{})
+
+		report, _ := uc.Execute(context.Background(), ValidateRequest{CandidateVersion: "v1.1.0"})
+
+		if report.Results[0].Status != parsercompat.StatusSkipped {
+			t.Errorf("expected skipped, got %+v", report.Results[0])
+		}
+		if report.Compatible() {
+			t.Error("a run without compared samples should not be compatible")
+		}
+	})
+
+	t.Run("records clone failure", func(t *testing.T) {
+		repo := &mockRepository{baseline: map[string]int{}, samples: []parsercompat.Sample{sample}}
+		uc := NewValidateUseCase(repo, &mockVCS{cloneErr: errors.New("boom"), headSHA: "abc"}, &mockParser{})
+
+		report, err := uc.Execute(context.Background(), ValidateRequest{CandidateVersion: "v1.1.0"})
+		if err != nil {
+			t.Fatalf("per-sample failure should not abort the run: %v", err)
+		}
+		if report.Results[0].Status != parsercompat.StatusFailed {
+			t.Errorf("expected failed, got %+v", report.Results[0])
+		}
+	})
+
+	t.Run("requires candidate version", func(t *testing.T) {
+		uc := NewValidateUseCase(&mockRepository{}, &mockVCS{}, &mockParser{})
+
+		_, err := uc.Execute(context.Background(), ValidateRequest{})
+		if !errors.Is(err, parsercompat.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("wraps save error", func(t *testing.T) {
+		repo := &mockRepository{saveErr: errors.New("db down")}
+		uc := NewValidateUseCase(repo, &mockVCS{}, &mockParser{})
+
+		_, err := uc.Execute(context.Background(), ValidateRequest{CandidateVersion: "v1.1.0"})
+		if !errors.Is(err, ErrSaveFailed) {
+			t.Errorf("expected ErrSaveFailed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package regen
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/regen"
+)
+
+// DefaultDispatchInterval is the period RatePerMinute applies to.
+const DefaultDispatchInterval = time.Minute
+
+// DispatchResult reports one dispatch round of a campaign.
+type DispatchResult struct {
+	Campaign *regen.Campaign // state before the round's enqueues
+	Enqueued int
+	Failed   int // targets that could not be enqueued
+	Settled  int // enqueued targets whose job finished since the last round
+}
+
+// CampaignUseCase creates bulk regeneration campaigns and feeds their targets
+// to the queue at the campaign's rate.
+type CampaignUseCase struct {
+	dispatchInterval time.Duration
+	enqueuer         regen.Enqueuer
+	now              func() time.Time
+	repository       regen.Repository
+}
+
+// Option configures CampaignUseCase.
+type Option func(*CampaignUseCase)
+
+// WithDispatchInterval sets how often Run dispatches. Non-positive values are ignored.
+func WithDispatchInterval(d time.Duration) Option {
+	return func(uc *CampaignUseCase) {
+		if d > 0 {
+			uc.dispatchInterval = d
+		}
+	}
+}
+
+// NewCampaignUseCase creates a new CampaignUseCase.
+func NewCampaignUseCase(repository regen.Repository, enqueuer regen.Enqueuer, opts ...Option) *CampaignUseCase {
+	uc := &CampaignUseCase{
+		dispatchInterval: DefaultDispatchInterval,
+		enqueuer:         enqueuer,
+		now:              time.Now,
+		repository:       repository,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Create stores a running campaign targeting the documents the selection
+// matches. Targets are fixed at creation; documents generated later are not added.
+func (uc *CampaignUseCase) Create(
+	ctx context.Context,
+	name string,
+	selection regen.Selection,
+	throttle regen.Throttle,
+) (*regen.Campaign, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", regen.ErrInvalidInput)
+	}
+	selection, err := selection.Normalize(uc.now())
+	if err != nil {
+		return nil, err
+	}
+	throttle, err = throttle.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	return uc.repository.CreateCampaign(ctx, name, selection, throttle)
+}
+
+// Get returns the campaign with its progress.
+func (uc *CampaignUseCase) Get(ctx context.Context, id string) (*regen.Campaign, error) {
+	return uc.repository.GetCampaign(ctx, id)
+}
+
+// List returns all campaigns, newest first.
+func (uc *CampaignUseCase) List(ctx context.Context) ([]regen.Campaign, error) {
+	return uc.repository.ListCampaigns(ctx)
+}
+
+// Failures returns up to limit failed targets with their errors.
+func (uc *CampaignUseCase) Failures(ctx context.Context, id string, limit int) ([]regen.Target, error) {
+	return uc.repository.ListTargets(ctx, id, regen.TargetFailed, limit)
+}
+
+// Pause stops enqueueing new targets. Jobs already enqueued still run.
+func (uc *CampaignUseCase) Pause(ctx context.Context, id string) error {
+	return uc.transition(ctx, id, regen.StatusPaused)
+}
+
+// Resume continues a paused campaign.
+func (uc *CampaignUseCase) Resume(ctx context.Context, id string) error {
+	return uc.transition(ctx, id, regen.StatusRunning)
+}
+
+// Cancel stops the campaign for good. Pending targets stay pending.
+func (uc *CampaignUseCase) Cancel(ctx context.Context, id string) error {
+	return uc.transition(ctx, id, regen.StatusCancelled)
+}
+
+func (uc *CampaignUseCase) transition(ctx context.Context, id string, to regen.Status) error {
+	campaign, err := uc.repository.GetCampaign(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !regen.CanTransition(campaign.Status, to) {
+		return fmt.Errorf("%w: %s campaign cannot become %s", regen.ErrInvalidTransition, campaign.Status, to)
+	}
+	return uc.repository.UpdateStatus(ctx, id, campaign.Status, to)
+}
+
+// Dispatch runs one round: it settles finished targets, then enqueues up to
+// RatePerMinute pending targets while keeping at most MaxInFlight enqueued.
+// A running campaign without unfinished targets is marked completed.
+func (uc *CampaignUseCase) Dispatch(ctx context.Context, id string) (*DispatchResult, error) {
+	settled, err := uc.repository.SyncTargets(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	campaign, err := uc.repository.GetCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := &DispatchResult{Campaign: campaign, Settled: settled}
+	if campaign.Status != regen.StatusRunning {
+		return result, nil
+	}
+
+	if campaign.Progress.Done() {
+		if err := uc.repository.UpdateStatus(ctx, id, regen.StatusRunning, regen.StatusCompleted); err != nil {
+			return nil, err
+		}
+		campaign.Status = regen.StatusCompleted
+		return result, nil
+	}
+
+	budget := min(campaign.Throttle.RatePerMinute, campaign.Throttle.MaxInFlight-campaign.Progress.Enqueued)
+	if budget <= 0 {
+		return result, nil
+	}
+
+	targets, err := uc.repository.ListTargets(ctx, id, regen.TargetPending, budget)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		jobID, err := uc.enqueuer.EnqueueRegeneration(ctx, target)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to enqueue regeneration",
+				"campaign_id", id,
+				"document_id", target.DocumentID,
+				"error", err,
+			)
+			if markErr := uc.repository.MarkFailed(ctx, target.ID, err.Error()); markErr != nil {
+				return nil, markErr
+			}
+			result.Failed++
+			continue
+		}
+		if err := uc.repository.MarkEnqueued(ctx, target.ID, jobID); err != nil {
+			return nil, err
+		}
+		result.Enqueued++
+	}
+	return result, nil
+}
+
+// Run dispatches every interval until the campaign completes or is cancelled,
+// or ctx ends. A paused campaign is still polled, so resuming it from another
+// process takes effect. onRound, if set, sees every round.
+func (uc *CampaignUseCase) Run(ctx context.Context, id string, onRound func(*DispatchResult)) error {
+	ticker := time.NewTicker(uc.dispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		result, err := uc.Dispatch(ctx, id)
+		if err != nil {
+			return err
+		}
+		if onRound != nil {
+			onRound(result)
+		}
+		switch result.Campaign.Status {
+		case regen.StatusCompleted, regen.StatusCancelled:
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package regen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/regen"
+)
+
+// fakeRepository keeps one campaign in memory.
+type fakeRepository struct {
+	campaign *regen.Campaign
+	finished map[string]regen.TargetStatus // job outcomes picked up by SyncTargets
+	targets  []regen.Target
+}
+
+func newFakeRepository(status regen.Status, throttle regen.Throttle, targets int) *fakeRepository {
+	repo := &fakeRepository{
+		campaign: &regen.Campaign{ID: "c1", Status: status, Throttle: throttle},
+		finished: make(map[string]regen.TargetStatus),
+	}
+	for i := range targets {
+		repo.targets = append(repo.targets, regen.Target{ID: fmt.Sprintf("t%d", i), Status: regen.TargetPending})
+	}
+	return repo
+}
+
+func (f *fakeRepository) CreateCampaign(ctx context.Context, name string, selection regen.Selection, throttle regen.Throttle) (*regen.Campaign, error) {
+	f.campaign = &regen.Campaign{ID: "c1", Name: name, Selection: selection, Status: regen.StatusRunning, Throttle: throttle}
+	return f.campaign, nil
+}
+
+func (f *fakeRepository) GetCampaign(ctx context.Context, id string) (*regen.Campaign, error) {
+	if f.campaign == nil || id != f.campaign.ID {
+		return nil, regen.ErrNotFound
+	}
+	c := *f.campaign
+	c.Progress = regen.Progress{}
+	for _, t := range f.targets {
+		switch t.Status {
+		case regen.TargetEnqueued:
+			c.Progress.Enqueued++
+		case regen.TargetFailed:
+			c.Progress.Failed++
+		case regen.TargetPending:
+			c.Progress.Pending++
+		case regen.TargetSucceeded:
+			c.Progress.Succeeded++
+		}
+	}
+	return &c, nil
+}
+
+func (f *fakeRepository) ListCampaigns(ctx context.Context) ([]regen.Campaign, error) {
+	return []regen.Campaign{*f.campaign}, nil
+}
+
+func (f *fakeRepository) ListTargets(ctx context.Context, campaignID string, status regen.TargetStatus, limit int) ([]regen.Target, error) {
+	var targets []regen.Target
+	for _, t := range f.targets {
+		if t.Status == status && len(targets) < limit {
+			targets = append(targets, t)
+		}
+	}
+	return targets, nil
+}
+
+func (f *fakeRepository) MarkEnqueued(ctx context.Context, targetID string, jobID int64) error {
+	return f.set(targetID, func(t *regen.Target) { t.Status, t.JobID = regen.TargetEnqueued, jobID })
+}
+
+func (f *fakeRepository) MarkFailed(ctx context.Context, targetID string, reason string) error {
+	return f.set(targetID, func(t *regen.Target) { t.Status, t.Error = regen.TargetFailed, reason })
+}
+
+func (f *fakeRepository) SyncTargets(ctx context.Context, campaignID string) (int, error) {
+	n := 0
+	for i := range f.targets {
+		if status, ok := f.finished[f.targets[i].ID]; ok && f.targets[i].Status == regen.TargetEnqueued {
+			f.targets[i].Status = status
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeRepository) UpdateStatus(ctx context.Context, id string, from, to regen.Status) error {
+	if f.campaign.Status != from {
+		return regen.ErrInvalidTransition
+	}
+	f.campaign.Status = to
+	return nil
+}
+
+func (f *fakeRepository) set(targetID string, update func(*regen.Target)) error {
+	for i := range f.targets {
+		if f.targets[i].ID == targetID {
+			update(&f.targets[i])
+			return nil
+		}
+	}
+	return errors.New("unknown target")
+}
+
+type fakeEnqueuer struct {
+	fail  map[string]bool
+	jobID int64
+}
+
+func (f *fakeEnqueuer) EnqueueRegeneration(ctx context.Context, target regen.Target) (int64, error) {
+	if f.fail[target.ID] {
+		return 0, errors.New("insert failed")
+	}
+	f.jobID++
+	return f.jobID, nil
+}
+
+func TestCampaignUseCase_Create(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeRepository{}
+	uc := NewCampaignUseCase(repo, &fakeEnqueuer{})
+	uc.now = func() time.Time { return now }
+
+	if _, err := uc.Create(context.Background(), "  ", regen.Selection{}, regen.Throttle{}); !errors.Is(err, regen.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for an empty name, got %v", err)
+	}
+
+	campaign, err := uc.Create(context.Background(), "prompt fix", regen.Selection{ModelID: "gemini-2.5-flash"}, regen.Throttle{RatePerMinute: 5})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !campaign.Selection.CreatedBefore.Equal(now) || campaign.Selection.Limit != regen.DefaultLimit {
+		t.Errorf("selection defaults not applied: %+v", campaign.Selection)
+	}
+	if campaign.Throttle != (regen.Throttle{MaxInFlight: regen.DefaultMaxInFlight, RatePerMinute: 5}) {
+		t.Errorf("throttle defaults not applied: %+v", campaign.Throttle)
+	}
+}
+
+func TestCampaignUseCase_Dispatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should enqueue at the rate and within the in-flight limit", func(t *testing.T) {
+		repo := newFakeRepository(regen.StatusRunning, regen.Throttle{MaxInFlight: 3, RatePerMinute: 2}, 5)
+		uc := NewCampaignUseCase(repo, &fakeEnqueuer{})
+
+		for round, want := range []int{2, 1, 0} {
+			result, err := uc.Dispatch(ctx, "c1")
+			if err != nil {
+				t.Fatalf("Dispatch failed: %v", err)
+			}
+			if result.Enqueued != want {
+				t.Errorf("round %d enqueued %d, want %d", round, result.Enqueued, want)
+			}
+		}
+
+		repo.finished["t0"] = regen.TargetSucceeded
+		repo.finished["t1"] = regen.TargetFailed
+		result, err := uc.Dispatch(ctx, "c1")
+		if err != nil {
+			t.Fatalf("Dispatch failed: %v", err)
+		}
+		if result.Settled != 2 || result.Enqueued != 2 {
+			t.Errorf("expected 2 settled and 2 enqueued, got %+v", result)
+		}
+	})
+
+	t.Run("should record enqueue failures and continue", func(t *testing.T) {
+		repo := newFakeRepository(regen.StatusRunning, regen.Throttle{MaxInFlight: 10, RatePerMinute: 10}, 3)
+		uc := NewCampaignUseCase(repo, &fakeEnqueuer{fail: map[string]bool{"t1": true}})
+
+		result, err := uc.Dispatch(ctx, "c1")
+		if err != nil {
+			t.Fatalf("Dispatch failed: %v", err)
+		}
+		if result.Enqueued != 2 || result.Failed != 1 {
+			t.Errorf("expected 2 enqueued and 1 failed, got %+v", result)
+		}
+		if repo.targets[1].Status != regen.TargetFailed || repo.targets[1].Error != "insert failed" {
+			t.Errorf("failure not recorded: %+v", repo.targets[1])
+		}
+	})
+
+	t.Run("should not enqueue while paused", func(t *testing.T) {
+		repo := newFakeRepository(regen.StatusPaused, regen.Throttle{MaxInFlight: 10, RatePerMinute: 10}, 3)
+		uc := NewCampaignUseCase(repo, &fakeEnqueuer{})
+
+		result, err := uc.Dispatch(ctx, "c1")
+		if err != nil {
+			t.Fatalf("Dispatch failed: %v", err)
+		}
+		if result.Enqueued != 0 {
+			t.Errorf("paused campaign enqueued %d targets", result.Enqueued)
+		}
+
+		if err := uc.Resume(ctx, "c1"); err != nil {
+			t.Fatalf("Resume failed: %v", err)
+		}
+		if result, _ := uc.Dispatch(ctx, "c1"); result.Enqueued != 3 {
+			t.Errorf("resumed campaign enqueued %d targets, want 3", result.Enqueued)
+		}
+	})
+
+	t.Run("should complete when every target finished", func(t *testing.T) {
+		repo := newFakeRepository(regen.StatusRunning, regen.Throttle{MaxInFlight: 10, RatePerMinute: 10}, 1)
+		repo.targets[0].Status = regen.TargetEnqueued
+		repo.finished["t0"] = regen.TargetSucceeded
+		uc := NewCampaignUseCase(repo, &fakeEnqueuer{})
+
+		result, err := uc.Dispatch(ctx, "c1")
+		if err != nil {
+			t.Fatalf("Dispatch failed: %v", err)
+		}
+		if result.Campaign.Status != regen.StatusCompleted || repo.campaign.Status != regen.StatusCompleted {
+			t.Errorf("expected completed campaign, got %s", repo.campaign.Status)
+		}
+	})
+}
+
+func TestCampaignUseCase_Transitions(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository(regen.StatusRunning, regen.Throttle{}, 0)
+	uc := NewCampaignUseCase(repo, &fakeEnqueuer{})
+
+	if err := uc.Resume(ctx, "c1"); !errors.Is(err, regen.ErrInvalidTransition) {
+		t.Errorf("resuming a running campaign should fail, got %v", err)
+	}
+	if err := uc.Pause(ctx, "c1"); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	if err := uc.Cancel(ctx, "c1"); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if err := uc.Resume(ctx, "c1"); !errors.Is(err, regen.ErrInvalidTransition) {
+		t.Errorf("resuming a cancelled campaign should fail, got %v", err)
+	}
+	if err := uc.Pause(ctx, "missing"); !errors.Is(err, regen.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCampaignUseCase_Run(t *testing.T) {
+	repo := newFakeRepository(regen.StatusRunning, regen.Throttle{MaxInFlight: 10, RatePerMinute: 1}, 2)
+	enqueuer := &fakeEnqueuer{}
+	uc := NewCampaignUseCase(repo, enqueuer, WithDispatchInterval(time.Millisecond))
+
+	rounds := 0
+	err := uc.Run(context.Background(), "c1", func(result *DispatchResult) {
+		rounds++
+		// Jobs finish right after they are enqueued.
+		for _, target := range repo.targets {
+			if target.Status == regen.TargetEnqueued {
+				repo.finished[target.ID] = regen.TargetSucceeded
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if repo.campaign.Status != regen.StatusCompleted || enqueuer.jobID != 2 {
+		t.Errorf("expected a completed campaign with 2 jobs, got %s with %d", repo.campaign.Status, enqueuer.jobID)
+	}
+	if rounds != 3 {
+		t.Errorf("expected 3 rounds, got %d", rounds)
+	}
+}
@@ -0,0 +1,9 @@
+package requirement
+
+import "errors"
+
+var (
+	ErrCodebaseMismatch = errors.New("document does not belong to requirement set codebase")
+	ErrImportFailed     = errors.New("failed to import requirements")
+	ErrMatchFailed      = errors.New("failed to match requirements")
+)
@@ -0,0 +1,51 @@
+package requirement
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/specvital/worker/internal/domain/requirement"
+)
+
+// ImportRequest describes an uploaded requirements list.
+type ImportRequest struct {
+	CodebaseID string
+	Format     requirement.Format
+	Name       string
+	Source     io.Reader
+}
+
+// ImportRequirementsUseCase parses and stores an uploaded requirements list.
+type ImportRequirementsUseCase struct {
+	repository requirement.Repository
+}
+
+// NewImportRequirementsUseCase creates a new ImportRequirementsUseCase.
+func NewImportRequirementsUseCase(repository requirement.Repository) *ImportRequirementsUseCase {
+	return &ImportRequirementsUseCase{repository: repository}
+}
+
+// Execute parses the upload and returns the stored set with assigned IDs.
+func (uc *ImportRequirementsUseCase) Execute(ctx context.Context, req ImportRequest) (*requirement.Set, error) {
+	reqs, err := requirement.Parse(req.Format, req.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &requirement.Set{
+		CodebaseID:   req.CodebaseID,
+		Name:         req.Name,
+		Requirements: reqs,
+		SourceFormat: req.Format,
+	}
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repository.SaveSet(ctx, set); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrImportFailed, err)
+	}
+
+	return set, nil
+}
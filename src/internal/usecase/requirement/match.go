@@ -0,0 +1,93 @@
+package requirement
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/specvital/worker/internal/domain/requirement"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// MatchRequest identifies the requirement set and spec document to trace.
+type MatchRequest struct {
+	DocumentID string
+	SetID      string
+}
+
+// MatchRequirementsUseCase links requirements to generated behaviors and stores the coverage matrix.
+type MatchRequirementsUseCase struct {
+	behaviors  specview.SearchSourceRepository
+	matcher    requirement.Matcher
+	repository requirement.Repository
+}
+
+// NewMatchRequirementsUseCase creates a new MatchRequirementsUseCase.
+func NewMatchRequirementsUseCase(
+	repository requirement.Repository,
+	behaviors specview.SearchSourceRepository,
+	matcher requirement.Matcher,
+) *MatchRequirementsUseCase {
+	return &MatchRequirementsUseCase{
+		behaviors:  behaviors,
+		matcher:    matcher,
+		repository: repository,
+	}
+}
+
+// Execute matches every requirement in the set against the document's behaviors.
+func (uc *MatchRequirementsUseCase) Execute(ctx context.Context, req MatchRequest) (*requirement.CoverageSummary, error) {
+	if req.SetID == "" || req.DocumentID == "" {
+		return nil, fmt.Errorf("%w: set ID and document ID are required", requirement.ErrInvalidInput)
+	}
+
+	set, err := uc.repository.GetSet(ctx, req.SetID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMatchFailed, err)
+	}
+
+	// Search entries carry domain/feature context, which gives the matcher more signal
+	// than the bare behavior description.
+	entries, err := uc.behaviors.GetSearchIndexEntries(ctx, req.DocumentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMatchFailed, err)
+	}
+
+	candidates := make([]requirement.Candidate, len(entries))
+	for i, e := range entries {
+		if e.CodebaseID != set.CodebaseID {
+			return nil, ErrCodebaseMismatch
+		}
+		candidates[i] = requirement.Candidate{
+			BehaviorID: e.BehaviorID,
+			Text:       e.DomainName + " " + e.FeatureName + " " + e.BehaviorDescription,
+		}
+	}
+
+	matches, err := uc.matcher.Match(ctx, set.Requirements, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMatchFailed, err)
+	}
+
+	if err := uc.repository.ReplaceCoverage(ctx, req.SetID, req.DocumentID, matches); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMatchFailed, err)
+	}
+
+	return summarize(set.Requirements, matches), nil
+}
+
+func summarize(reqs []requirement.Requirement, matches []requirement.Match) *requirement.CoverageSummary {
+	covered := make(map[string]struct{}, len(matches))
+	for _, m := range matches {
+		covered[m.RequirementID] = struct{}{}
+	}
+
+	summary := &requirement.CoverageSummary{TotalRequirements: len(reqs)}
+	for _, r := range reqs {
+		if _, ok := covered[r.ID]; ok {
+			summary.CoveredRequirements++
+			continue
+		}
+		summary.UntestedKeys = append(summary.UntestedKeys, r.ExternalKey)
+	}
+	return summary
+}
@@ -0,0 +1,192 @@
+package requirement
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/requirement"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockRepository struct {
+	getSetFn          func(ctx context.Context, setID string) (*requirement.Set, error)
+	replaceCoverageFn func(ctx context.Context, setID, documentID string, matches []requirement.Match) error
+	saveSetFn         func(ctx context.Context, set *requirement.Set) error
+}
+
+func (m *mockRepository) GetSet(ctx context.Context, setID string) (*requirement.Set, error) {
+	if m.getSetFn != nil {
+		return m.getSetFn(ctx, setID)
+	}
+	return &requirement.Set{
+		CodebaseID: "cb-1",
+		ID:         setID,
+		Requirements: []requirement.Requirement{
+			{ExternalKey: "REQ-1", ID: "r1", Title: "Refund"},
+			{ExternalKey: "REQ-2", ID: "r2", Title: "Invoice"},
+		},
+	}, nil
+}
+
+func (m *mockRepository) ReplaceCoverage(ctx context.Context, setID, documentID string, matches []requirement.Match) error {
+	if m.replaceCoverageFn != nil {
+		return m.replaceCoverageFn(ctx, setID, documentID, matches)
+	}
+	return nil
+}
+
+func (m *mockRepository) SaveSet(ctx context.Context, set *requirement.Set) error {
+	if m.saveSetFn != nil {
+		return m.saveSetFn(ctx, set)
+	}
+	set.ID = "set-1"
+	return nil
+}
+
+type mockBehaviorSource struct {
+	entries []specview.SearchIndexEntry
+	err     error
+}
+
+func (m *mockBehaviorSource) GetSearchIndexEntries(_ context.Context, _ string) ([]specview.SearchIndexEntry, error) {
+	return m.entries, m.err
+}
+
+type mockMatcher struct {
+	candidates []requirement.Candidate
+	matches    []requirement.Match
+	err        error
+}
+
+func (m *mockMatcher) Match(_ context.Context, _ []requirement.Requirement, candidates []requirement.Candidate) ([]requirement.Match, error) {
+	m.candidates = candidates
+	return m.matches, m.err
+}
+
+func TestMatchRequirementsUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	source := &mockBehaviorSource{entries: []specview.SearchIndexEntry{
+		{BehaviorDescription: "Issues refund", BehaviorID: "b1", CodebaseID: "cb-1", DomainName: "Payments", FeatureName: "Refunds"},
+	}}
+
+	t.Run("reports untested requirements", func(t *testing.T) {
+		matcher := &mockMatcher{matches: []requirement.Match{{BehaviorID: "b1", RequirementID: "r1", Score: 0.9}}}
+		var saved []requirement.Match
+		repo := &mockRepository{replaceCoverageFn: func(_ context.Context, _, _ string, matches []requirement.Match) error {
+			saved = matches
+			return nil
+		}}
+
+		summary, err := NewMatchRequirementsUseCase(repo, source, matcher).Execute(ctx, MatchRequest{DocumentID: "doc-1", SetID: "set-1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if summary.TotalRequirements != 2 || summary.CoveredRequirements != 1 {
+			t.Errorf("unexpected summary: %+v", summary)
+		}
+		if !reflect.DeepEqual(summary.UntestedKeys, []string{"REQ-2"}) {
+			t.Errorf("expected REQ-2 untested, got %v", summary.UntestedKeys)
+		}
+		if len(saved) != 1 {
+			t.Errorf("expected coverage to be saved, got %d rows", len(saved))
+		}
+		if !strings.Contains(matcher.candidates[0].Text, "Refunds") {
+			t.Errorf("expected feature name in candidate text, got %q", matcher.candidates[0].Text)
+		}
+	})
+
+	t.Run("rejects document from another codebase", func(t *testing.T) {
+		foreign := &mockBehaviorSource{entries: []specview.SearchIndexEntry{{BehaviorID: "b1", CodebaseID: "cb-other"}}}
+
+		_, err := NewMatchRequirementsUseCase(&mockRepository{}, foreign, &mockMatcher{}).Execute(ctx, MatchRequest{DocumentID: "doc-1", SetID: "set-1"})
+		if !errors.Is(err, ErrCodebaseMismatch) {
+			t.Errorf("expected ErrCodebaseMismatch, got %v", err)
+		}
+	})
+
+	t.Run("rejects missing IDs", func(t *testing.T) {
+		_, err := NewMatchRequirementsUseCase(&mockRepository{}, source, &mockMatcher{}).Execute(ctx, MatchRequest{SetID: "set-1"})
+		if !errors.Is(err, requirement.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("propagates set not found", func(t *testing.T) {
+		repo := &mockRepository{getSetFn: func(context.Context, string) (*requirement.Set, error) {
+			return nil, requirement.ErrSetNotFound
+		}}
+
+		_, err := NewMatchRequirementsUseCase(repo, source, &mockMatcher{}).Execute(ctx, MatchRequest{DocumentID: "doc-1", SetID: "missing"})
+		if !errors.Is(err, requirement.ErrSetNotFound) {
+			t.Errorf("expected ErrSetNotFound, got %v", err)
+		}
+	})
+
+	t.Run("wraps matcher errors", func(t *testing.T) {
+		matcher := &mockMatcher{err: errors.New("embedding service down")}
+
+		_, err := NewMatchRequirementsUseCase(&mockRepository{}, source, matcher).Execute(ctx, MatchRequest{DocumentID: "doc-1", SetID: "set-1"})
+		if !errors.Is(err, ErrMatchFailed) {
+			t.Errorf("expected ErrMatchFailed, got %v", err)
+		}
+	})
+}
+
+func TestImportRequirementsUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores parsed set", func(t *testing.T) {
+		set, err := NewImportRequirementsUseCase(&mockRepository{}).Execute(ctx, ImportRequest{
+			CodebaseID: "cb-1",
+			Format:     requirement.FormatCSV,
+			Name:       "PRD",
+			Source:     strings.NewReader("key,title\nREQ-1,Refunds\n"),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if set.ID != "set-1" || len(set.Requirements) != 1 {
+			t.Errorf("unexpected set: %+v", set)
+		}
+	})
+
+	t.Run("rejects duplicate keys before saving", func(t *testing.T) {
+		saved := false
+		repo := &mockRepository{saveSetFn: func(context.Context, *requirement.Set) error {
+			saved = true
+			return nil
+		}}
+
+		_, err := NewImportRequirementsUseCase(repo).Execute(ctx, ImportRequest{
+			CodebaseID: "cb-1",
+			Format:     requirement.FormatCSV,
+			Name:       "PRD",
+			Source:     strings.NewReader("key,title\nREQ-1,A\nREQ-1,B\n"),
+		})
+		if !errors.Is(err, requirement.ErrInvalidFormat) {
+			t.Errorf("expected ErrInvalidFormat, got %v", err)
+		}
+		if saved {
+			t.Error("invalid set should not be saved")
+		}
+	})
+
+	t.Run("wraps repository errors", func(t *testing.T) {
+		repo := &mockRepository{saveSetFn: func(context.Context, *requirement.Set) error {
+			return errors.New("unique violation")
+		}}
+
+		_, err := NewImportRequirementsUseCase(repo).Execute(ctx, ImportRequest{
+			CodebaseID: "cb-1",
+			Format:     requirement.FormatJSON,
+			Name:       "PRD",
+			Source:     strings.NewReader(`[{"key":"REQ-1","title":"A"}]`),
+		})
+		if !errors.Is(err, ErrImportFailed) {
+			t.Errorf("expected ErrImportFailed, got %v", err)
+		}
+	})
+}
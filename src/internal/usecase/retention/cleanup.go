@@ -15,9 +15,11 @@ const (
 
 // CleanupUseCase orchestrates retention-based data cleanup.
 type CleanupUseCase struct {
-	batchSize      int
-	batchSleep     time.Duration
-	cleanupRepo    retention.CleanupRepository
+	batchSize     int
+	batchSleep    time.Duration
+	cleanupRepo   retention.CleanupRepository
+	deletedGrace  time.Duration
+	versionPolicy retention.VersionPolicy
 }
 
 // Option configures CleanupUseCase.
@@ -41,12 +43,31 @@ func WithBatchSleep(d time.Duration) Option {
 	}
 }
 
+// WithVersionPolicy prunes superseded spec document versions by policy.
+// Without it no version is pruned.
+func WithVersionPolicy(policy retention.VersionPolicy) Option {
+	return func(uc *CleanupUseCase) {
+		uc.versionPolicy = policy
+	}
+}
+
+// WithDeletedDocumentGrace sets how long soft-deleted spec documents are kept
+// for restoring.
+func WithDeletedDocumentGrace(d time.Duration) Option {
+	return func(uc *CleanupUseCase) {
+		if d > 0 {
+			uc.deletedGrace = d
+		}
+	}
+}
+
 // NewCleanupUseCase creates a CleanupUseCase with the given repository.
 func NewCleanupUseCase(repo retention.CleanupRepository, opts ...Option) *CleanupUseCase {
 	uc := &CleanupUseCase{
-		batchSize:   retention.DefaultBatchSize,
-		batchSleep:  DefaultBatchSleepDuration,
-		cleanupRepo: repo,
+		batchSize:    retention.DefaultBatchSize,
+		batchSleep:   DefaultBatchSleepDuration,
+		cleanupRepo:  repo,
+		deletedGrace: retention.DefaultDeletedDocumentGrace,
 	}
 
 	for _, opt := range opts {
@@ -60,14 +81,23 @@ func NewCleanupUseCase(repo retention.CleanupRepository, opts ...Option) *Cleanu
 type CleanupResult struct {
 	UserAnalysisHistoryDeleted int64
 	SpecDocumentsDeleted       int64
+	SpecVersionsPruned         int64
+	DeletedDocumentsPurged     int64
 	OrphanedAnalysesDeleted    int64
+	StaleCacheEntriesDeleted   int64
+	WebhookDeliveriesDeleted   int64
+	IdempotencyKeysDeleted     int64
+	RateLimitBucketsDeleted    int64
+	AnalysisEventsDeleted      int64
 	StartedAt                  time.Time
 	CompletedAt                time.Time
 }
 
 // TotalDeleted returns the total number of records deleted.
 func (r CleanupResult) TotalDeleted() int64 {
-	return r.UserAnalysisHistoryDeleted + r.SpecDocumentsDeleted + r.OrphanedAnalysesDeleted
+	return r.UserAnalysisHistoryDeleted + r.SpecDocumentsDeleted + r.SpecVersionsPruned + r.DeletedDocumentsPurged +
+		r.OrphanedAnalysesDeleted + r.StaleCacheEntriesDeleted + r.WebhookDeliveriesDeleted + r.IdempotencyKeysDeleted +
+		r.RateLimitBucketsDeleted + r.AnalysisEventsDeleted
 }
 
 // Duration returns how long the cleanup took.
@@ -76,8 +106,13 @@ func (r CleanupResult) Duration() time.Duration {
 }
 
 // Execute performs the two-phase cleanup process.
-// Phase 1: Delete expired user data (user_analysis_history, spec_documents)
+// Phase 1: Delete expired user data (user_analysis_history, spec_documents),
+// superseded document versions and soft-deleted documents past their grace
 // Phase 2: Delete orphaned analyses (no references in user_analysis_history)
+// Phase 3: Delete cache entries of stale codebases (deleted and recreated repos)
+// and semantic cache embeddings left without a cached behavior
+// Expired webhook delivery records, enqueue idempotency keys, idle rate limit
+// buckets and analysis progress events are purged last.
 func (uc *CleanupUseCase) Execute(ctx context.Context) (CleanupResult, error) {
 	result := CleanupResult{
 		StartedAt: time.Now(),
@@ -101,6 +136,26 @@ func (uc *CleanupUseCase) Execute(ctx context.Context) (CleanupResult, error) {
 	}
 	result.SpecDocumentsDeleted = specDocsDeleted
 
+	// Phase 1: Prune superseded document versions
+	if uc.versionPolicy.Enabled() {
+		versionsPruned, err := uc.deleteInBatches(ctx, "superseded_spec_documents", func(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+			return uc.cleanupRepo.DeleteSupersededSpecDocuments(ctx, uc.versionPolicy, batchSize)
+		})
+		if err != nil {
+			return result, fmt.Errorf("delete superseded spec documents: %w", err)
+		}
+		result.SpecVersionsPruned = versionsPruned
+	}
+
+	// Phase 1: Purge soft-deleted documents
+	purged, err := uc.deleteInBatches(ctx, "soft_deleted_spec_documents", func(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+		return uc.cleanupRepo.DeleteSoftDeletedSpecDocuments(ctx, uc.deletedGrace, batchSize)
+	})
+	if err != nil {
+		return result, fmt.Errorf("delete soft-deleted spec documents: %w", err)
+	}
+	result.DeletedDocumentsPurged = purged
+
 	// Phase 2: Delete orphaned analyses
 	orphansDeleted, err := uc.deleteInBatches(ctx, "orphaned_analyses", uc.cleanupRepo.DeleteOrphanedAnalyses)
 	if err != nil {
@@ -108,12 +163,58 @@ func (uc *CleanupUseCase) Execute(ctx context.Context) (CleanupResult, error) {
 	}
 	result.OrphanedAnalysesDeleted = orphansDeleted
 
+	// Phase 3: Delete cache entries of stale codebases
+	behaviorCachesDeleted, err := uc.deleteInBatches(ctx, "stale_behavior_caches", uc.cleanupRepo.DeleteStaleBehaviorCaches)
+	if err != nil {
+		return result, fmt.Errorf("delete stale behavior caches: %w", err)
+	}
+	classificationCachesDeleted, err := uc.deleteInBatches(ctx, "stale_classification_caches", uc.cleanupRepo.DeleteStaleClassificationCaches)
+	if err != nil {
+		return result, fmt.Errorf("delete stale classification caches: %w", err)
+	}
+	embeddingsDeleted, err := uc.deleteInBatches(ctx, "orphan_behavior_embeddings", uc.cleanupRepo.DeleteOrphanBehaviorEmbeddings)
+	if err != nil {
+		return result, fmt.Errorf("delete orphan behavior embeddings: %w", err)
+	}
+	result.StaleCacheEntriesDeleted = behaviorCachesDeleted + classificationCachesDeleted + embeddingsDeleted
+
+	deliveriesDeleted, err := uc.deleteInBatches(ctx, "webhook_deliveries", uc.cleanupRepo.DeleteExpiredWebhookDeliveries)
+	if err != nil {
+		return result, fmt.Errorf("delete expired webhook deliveries: %w", err)
+	}
+	result.WebhookDeliveriesDeleted = deliveriesDeleted
+
+	keysDeleted, err := uc.deleteInBatches(ctx, "enqueue_idempotency_keys", uc.cleanupRepo.DeleteExpiredIdempotencyKeys)
+	if err != nil {
+		return result, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	result.IdempotencyKeysDeleted = keysDeleted
+
+	bucketsDeleted, err := uc.deleteInBatches(ctx, "rate_limit_buckets", uc.cleanupRepo.DeleteIdleRateLimitBuckets)
+	if err != nil {
+		return result, fmt.Errorf("delete idle rate limit buckets: %w", err)
+	}
+	result.RateLimitBucketsDeleted = bucketsDeleted
+
+	eventsDeleted, err := uc.deleteInBatches(ctx, "analysis_events", uc.cleanupRepo.DeleteExpiredAnalysisEvents)
+	if err != nil {
+		return result, fmt.Errorf("delete expired analysis events: %w", err)
+	}
+	result.AnalysisEventsDeleted = eventsDeleted
+
 	result.CompletedAt = time.Now()
 
 	slog.InfoContext(ctx, "retention cleanup completed",
 		"user_analysis_history_deleted", result.UserAnalysisHistoryDeleted,
 		"spec_documents_deleted", result.SpecDocumentsDeleted,
+		"spec_versions_pruned", result.SpecVersionsPruned,
+		"deleted_documents_purged", result.DeletedDocumentsPurged,
 		"orphaned_analyses_deleted", result.OrphanedAnalysesDeleted,
+		"stale_cache_entries_deleted", result.StaleCacheEntriesDeleted,
+		"webhook_deliveries_deleted", result.WebhookDeliveriesDeleted,
+		"idempotency_keys_deleted", result.IdempotencyKeysDeleted,
+		"rate_limit_buckets_deleted", result.RateLimitBucketsDeleted,
+		"analysis_events_deleted", result.AnalysisEventsDeleted,
 		"total_deleted", result.TotalDeleted(),
 		"duration", result.Duration(),
 	)
@@ -12,7 +12,16 @@ import (
 type mockCleanupRepository struct {
 	deleteExpiredUserAnalysisHistoryFn func(ctx context.Context, batchSize int) (retention.DeleteResult, error)
 	deleteExpiredSpecDocumentsFn       func(ctx context.Context, batchSize int) (retention.DeleteResult, error)
+	deleteSupersededSpecDocumentsFn    func(ctx context.Context, policy retention.VersionPolicy, batchSize int) (retention.DeleteResult, error)
+	deleteSoftDeletedSpecDocumentsFn   func(ctx context.Context, grace time.Duration, batchSize int) (retention.DeleteResult, error)
 	deleteOrphanedAnalysesFn           func(ctx context.Context, batchSize int) (retention.DeleteResult, error)
+	deleteStaleBehaviorCachesFn        func(ctx context.Context, batchSize int) (retention.DeleteResult, error)
+	deleteStaleClassificationCachesFn  func(ctx context.Context, batchSize int) (retention.DeleteResult, error)
+	deleteOrphanBehaviorEmbeddingsFn   func(ctx context.Context, batchSize int) (retention.DeleteResult, error)
+	deleteExpiredWebhookDeliveriesFn   func(ctx context.Context, batchSize int) (retention.DeleteResult, error)
+	deleteExpiredIdempotencyKeysFn     func(ctx context.Context, batchSize int) (retention.DeleteResult, error)
+	deleteIdleRateLimitBucketsFn       func(ctx context.Context, batchSize int) (retention.DeleteResult, error)
+	deleteExpiredAnalysisEventsFn      func(ctx context.Context, batchSize int) (retention.DeleteResult, error)
 }
 
 func (m *mockCleanupRepository) DeleteExpiredUserAnalysisHistory(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
@@ -29,6 +38,20 @@ func (m *mockCleanupRepository) DeleteExpiredSpecDocuments(ctx context.Context,
 	return retention.DeleteResult{}, nil
 }
 
+func (m *mockCleanupRepository) DeleteSupersededSpecDocuments(ctx context.Context, policy retention.VersionPolicy, batchSize int) (retention.DeleteResult, error) {
+	if m.deleteSupersededSpecDocumentsFn != nil {
+		return m.deleteSupersededSpecDocumentsFn(ctx, policy, batchSize)
+	}
+	return retention.DeleteResult{}, nil
+}
+
+func (m *mockCleanupRepository) DeleteSoftDeletedSpecDocuments(ctx context.Context, grace time.Duration, batchSize int) (retention.DeleteResult, error) {
+	if m.deleteSoftDeletedSpecDocumentsFn != nil {
+		return m.deleteSoftDeletedSpecDocumentsFn(ctx, grace, batchSize)
+	}
+	return retention.DeleteResult{}, nil
+}
+
 func (m *mockCleanupRepository) DeleteOrphanedAnalyses(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
 	if m.deleteOrphanedAnalysesFn != nil {
 		return m.deleteOrphanedAnalysesFn(ctx, batchSize)
@@ -36,6 +59,55 @@ func (m *mockCleanupRepository) DeleteOrphanedAnalyses(ctx context.Context, batc
 	return retention.DeleteResult{}, nil
 }
 
+func (m *mockCleanupRepository) DeleteStaleBehaviorCaches(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if m.deleteStaleBehaviorCachesFn != nil {
+		return m.deleteStaleBehaviorCachesFn(ctx, batchSize)
+	}
+	return retention.DeleteResult{}, nil
+}
+
+func (m *mockCleanupRepository) DeleteStaleClassificationCaches(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if m.deleteStaleClassificationCachesFn != nil {
+		return m.deleteStaleClassificationCachesFn(ctx, batchSize)
+	}
+	return retention.DeleteResult{}, nil
+}
+
+func (m *mockCleanupRepository) DeleteOrphanBehaviorEmbeddings(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if m.deleteOrphanBehaviorEmbeddingsFn != nil {
+		return m.deleteOrphanBehaviorEmbeddingsFn(ctx, batchSize)
+	}
+	return retention.DeleteResult{}, nil
+}
+
+func (m *mockCleanupRepository) DeleteExpiredWebhookDeliveries(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if m.deleteExpiredWebhookDeliveriesFn != nil {
+		return m.deleteExpiredWebhookDeliveriesFn(ctx, batchSize)
+	}
+	return retention.DeleteResult{}, nil
+}
+
+func (m *mockCleanupRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if m.deleteExpiredIdempotencyKeysFn != nil {
+		return m.deleteExpiredIdempotencyKeysFn(ctx, batchSize)
+	}
+	return retention.DeleteResult{}, nil
+}
+
+func (m *mockCleanupRepository) DeleteIdleRateLimitBuckets(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if m.deleteIdleRateLimitBucketsFn != nil {
+		return m.deleteIdleRateLimitBucketsFn(ctx, batchSize)
+	}
+	return retention.DeleteResult{}, nil
+}
+
+func (m *mockCleanupRepository) DeleteExpiredAnalysisEvents(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+	if m.deleteExpiredAnalysisEventsFn != nil {
+		return m.deleteExpiredAnalysisEventsFn(ctx, batchSize)
+	}
+	return retention.DeleteResult{}, nil
+}
+
 func TestNewCleanupUseCase(t *testing.T) {
 	repo := &mockCleanupRepository{}
 
@@ -83,6 +155,23 @@ func TestNewCleanupUseCase(t *testing.T) {
 			t.Errorf("batchSleep = %v, want %v", uc.batchSleep, DefaultBatchSleepDuration)
 		}
 	})
+
+	t.Run("version policy disabled by default", func(t *testing.T) {
+		uc := NewCleanupUseCase(repo)
+		if uc.versionPolicy.Enabled() {
+			t.Error("expected version policy to be disabled")
+		}
+		if uc.deletedGrace != retention.DefaultDeletedDocumentGrace {
+			t.Errorf("deletedGrace = %v, want %v", uc.deletedGrace, retention.DefaultDeletedDocumentGrace)
+		}
+	})
+
+	t.Run("non-positive deleted document grace ignored", func(t *testing.T) {
+		uc := NewCleanupUseCase(repo, WithDeletedDocumentGrace(0))
+		if uc.deletedGrace != retention.DefaultDeletedDocumentGrace {
+			t.Errorf("deletedGrace = %v, want %v", uc.deletedGrace, retention.DefaultDeletedDocumentGrace)
+		}
+	})
 }
 
 func TestCleanupUseCase_Execute(t *testing.T) {
@@ -97,6 +186,27 @@ func TestCleanupUseCase_Execute(t *testing.T) {
 			deleteOrphanedAnalysesFn: func(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
 				return retention.DeleteResult{DeletedCount: 3}, nil
 			},
+			deleteStaleBehaviorCachesFn: func(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+				return retention.DeleteResult{DeletedCount: 6}, nil
+			},
+			deleteStaleClassificationCachesFn: func(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+				return retention.DeleteResult{DeletedCount: 1}, nil
+			},
+			deleteOrphanBehaviorEmbeddingsFn: func(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+				return retention.DeleteResult{DeletedCount: 2}, nil
+			},
+			deleteExpiredWebhookDeliveriesFn: func(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+				return retention.DeleteResult{DeletedCount: 2}, nil
+			},
+			deleteExpiredIdempotencyKeysFn: func(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+				return retention.DeleteResult{DeletedCount: 4}, nil
+			},
+			deleteIdleRateLimitBucketsFn: func(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+				return retention.DeleteResult{DeletedCount: 3}, nil
+			},
+			deleteExpiredAnalysisEventsFn: func(ctx context.Context, batchSize int) (retention.DeleteResult, error) {
+				return retention.DeleteResult{DeletedCount: 8}, nil
+			},
 		}
 
 		uc := NewCleanupUseCase(repo, WithBatchSleep(0))
@@ -114,8 +224,79 @@ func TestCleanupUseCase_Execute(t *testing.T) {
 		if result.OrphanedAnalysesDeleted != 3 {
 			t.Errorf("OrphanedAnalysesDeleted = %d, want 3", result.OrphanedAnalysesDeleted)
 		}
-		if result.TotalDeleted() != 18 {
-			t.Errorf("TotalDeleted() = %d, want 18", result.TotalDeleted())
+		if result.StaleCacheEntriesDeleted != 9 {
+			t.Errorf("StaleCacheEntriesDeleted = %d, want 9", result.StaleCacheEntriesDeleted)
+		}
+		if result.WebhookDeliveriesDeleted != 2 {
+			t.Errorf("WebhookDeliveriesDeleted = %d, want 2", result.WebhookDeliveriesDeleted)
+		}
+		if result.IdempotencyKeysDeleted != 4 {
+			t.Errorf("IdempotencyKeysDeleted = %d, want 4", result.IdempotencyKeysDeleted)
+		}
+		if result.RateLimitBucketsDeleted != 3 {
+			t.Errorf("RateLimitBucketsDeleted = %d, want 3", result.RateLimitBucketsDeleted)
+		}
+		if result.AnalysisEventsDeleted != 8 {
+			t.Errorf("AnalysisEventsDeleted = %d, want 8", result.AnalysisEventsDeleted)
+		}
+		if result.TotalDeleted() != 44 {
+			t.Errorf("TotalDeleted() = %d, want 44", result.TotalDeleted())
+		}
+	})
+
+	t.Run("success - version policy and soft-deleted purge", func(t *testing.T) {
+		var gotPolicy retention.VersionPolicy
+		var gotGrace time.Duration
+		repo := &mockCleanupRepository{
+			deleteSupersededSpecDocumentsFn: func(ctx context.Context, policy retention.VersionPolicy, batchSize int) (retention.DeleteResult, error) {
+				gotPolicy = policy
+				return retention.DeleteResult{DeletedCount: 7}, nil
+			},
+			deleteSoftDeletedSpecDocumentsFn: func(ctx context.Context, grace time.Duration, batchSize int) (retention.DeleteResult, error) {
+				gotGrace = grace
+				return retention.DeleteResult{DeletedCount: 2}, nil
+			},
+		}
+
+		policy := retention.NewVersionPolicy(90, 5)
+		uc := NewCleanupUseCase(repo, WithBatchSleep(0), WithVersionPolicy(policy), WithDeletedDocumentGrace(time.Hour))
+		result, err := uc.Execute(context.Background())
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotPolicy != policy {
+			t.Errorf("policy = %+v, want %+v", gotPolicy, policy)
+		}
+		if gotGrace != time.Hour {
+			t.Errorf("grace = %v, want 1h", gotGrace)
+		}
+		if result.SpecVersionsPruned != 7 {
+			t.Errorf("SpecVersionsPruned = %d, want 7", result.SpecVersionsPruned)
+		}
+		if result.DeletedDocumentsPurged != 2 {
+			t.Errorf("DeletedDocumentsPurged = %d, want 2", result.DeletedDocumentsPurged)
+		}
+		if result.TotalDeleted() != 9 {
+			t.Errorf("TotalDeleted() = %d, want 9", result.TotalDeleted())
+		}
+	})
+
+	t.Run("version policy disabled - superseded versions kept", func(t *testing.T) {
+		called := false
+		repo := &mockCleanupRepository{
+			deleteSupersededSpecDocumentsFn: func(ctx context.Context, policy retention.VersionPolicy, batchSize int) (retention.DeleteResult, error) {
+				called = true
+				return retention.DeleteResult{}, nil
+			},
+		}
+
+		uc := NewCleanupUseCase(repo, WithBatchSleep(0))
+		if _, err := uc.Execute(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if called {
+			t.Error("superseded versions should not be pruned without a version policy")
 		}
 	})
 
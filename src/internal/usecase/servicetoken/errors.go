@@ -0,0 +1,8 @@
+package servicetoken
+
+import "errors"
+
+var (
+	ErrIssueFailed  = errors.New("failed to issue service token")
+	ErrLookupFailed = errors.New("failed to look up service token")
+)
@@ -0,0 +1,92 @@
+package servicetoken
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/servicetoken"
+)
+
+// IssuedToken is a newly issued token with its plaintext, shown once.
+type IssuedToken struct {
+	Plaintext string
+	Token     *servicetoken.Token
+}
+
+// ServiceTokenUseCase issues service tokens and authenticates callers presenting them.
+type ServiceTokenUseCase struct {
+	repository servicetoken.Repository
+}
+
+// NewServiceTokenUseCase creates a new ServiceTokenUseCase.
+func NewServiceTokenUseCase(repository servicetoken.Repository) *ServiceTokenUseCase {
+	return &ServiceTokenUseCase{repository: repository}
+}
+
+// Issue creates a token. Only its hash is stored, so the returned plaintext
+// cannot be recovered later.
+func (uc *ServiceTokenUseCase) Issue(ctx context.Context, params servicetoken.IssueParams) (*IssuedToken, error) {
+	if err := params.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+
+	plaintext, err := servicetoken.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIssueFailed, err)
+	}
+
+	token, err := uc.repository.CreateToken(ctx, servicetoken.CreateParams{
+		Hash:        servicetoken.Hash(plaintext),
+		IssueParams: params,
+		Prefix:      servicetoken.DisplayPrefix(plaintext),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIssueFailed, err)
+	}
+	return &IssuedToken{Plaintext: plaintext, Token: token}, nil
+}
+
+// Authenticate returns the token for plaintext if it is active and grants scope.
+// Unknown, revoked and expired tokens all fail with ErrInvalidToken so callers
+// learn nothing about which tokens exist.
+func (uc *ServiceTokenUseCase) Authenticate(ctx context.Context, plaintext string, scope servicetoken.Scope) (*servicetoken.Token, error) {
+	if !strings.HasPrefix(plaintext, servicetoken.TokenPrefix) {
+		return nil, servicetoken.ErrInvalidToken
+	}
+
+	token, err := uc.repository.FindTokenByHash(ctx, servicetoken.Hash(plaintext))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLookupFailed, err)
+	}
+	if token == nil || !token.ActiveAt(time.Now()) {
+		return nil, servicetoken.ErrInvalidToken
+	}
+	if !token.Allows(scope) {
+		return nil, fmt.Errorf("%w: %s requires %q", servicetoken.ErrInsufficientScope, token.Name, scope)
+	}
+
+	if err := uc.repository.TouchToken(ctx, token.ID); err != nil {
+		slog.WarnContext(ctx, "failed to record service token use (non-critical)",
+			"token_id", token.ID,
+			"error", err,
+		)
+	}
+	return token, nil
+}
+
+// List returns every token, newest first.
+func (uc *ServiceTokenUseCase) List(ctx context.Context) ([]servicetoken.Token, error) {
+	tokens, err := uc.repository.ListTokens(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLookupFailed, err)
+	}
+	return tokens, nil
+}
+
+// Revoke disables a token immediately.
+func (uc *ServiceTokenUseCase) Revoke(ctx context.Context, id string) error {
+	return uc.repository.RevokeToken(ctx, id)
+}
@@ -0,0 +1,163 @@
+package servicetoken
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/servicetoken"
+)
+
+type mockRepository struct {
+	hashes   map[string][]byte
+	tokens   map[string]*servicetoken.Token
+	touched  []string
+	touchErr error
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{
+		hashes: make(map[string][]byte),
+		tokens: make(map[string]*servicetoken.Token),
+	}
+}
+
+func (m *mockRepository) CreateToken(ctx context.Context, params servicetoken.CreateParams) (*servicetoken.Token, error) {
+	id := params.Name
+	token := &servicetoken.Token{
+		CreatedAt: time.Now(),
+		ExpiresAt: params.ExpiresAt,
+		ID:        id,
+		Name:      params.Name,
+		Prefix:    params.Prefix,
+		Scopes:    params.Scopes,
+	}
+	m.hashes[id] = params.Hash
+	m.tokens[id] = token
+	return token, nil
+}
+
+func (m *mockRepository) FindTokenByHash(ctx context.Context, hash []byte) (*servicetoken.Token, error) {
+	for id, h := range m.hashes {
+		if bytes.Equal(h, hash) {
+			return m.tokens[id], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) ListTokens(ctx context.Context) ([]servicetoken.Token, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) RevokeToken(ctx context.Context, id string) error {
+	token, ok := m.tokens[id]
+	if !ok || token.RevokedAt != nil {
+		return servicetoken.ErrTokenNotFound
+	}
+	now := time.Now()
+	token.RevokedAt = &now
+	return nil
+}
+
+func (m *mockRepository) TouchToken(ctx context.Context, id string) error {
+	m.touched = append(m.touched, id)
+	return m.touchErr
+}
+
+func TestServiceTokenUseCase_IssueAndAuthenticate(t *testing.T) {
+	repo := newMockRepository()
+	uc := NewServiceTokenUseCase(repo)
+	ctx := context.Background()
+
+	issued, err := uc.Issue(ctx, servicetoken.IssueParams{Name: "ci", Scopes: []servicetoken.Scope{servicetoken.ScopeEnqueue}})
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	if bytes.Contains(repo.hashes["ci"], []byte(issued.Plaintext)) || issued.Token.Prefix != servicetoken.DisplayPrefix(issued.Plaintext) {
+		t.Fatalf("expected only the hash and prefix to be stored, got %+v", issued.Token)
+	}
+
+	token, err := uc.Authenticate(ctx, issued.Plaintext, servicetoken.ScopeEnqueue)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if token.ID != "ci" || len(repo.touched) != 1 {
+		t.Errorf("expected token ci to be touched, got %+v (touched %v)", token, repo.touched)
+	}
+
+	if _, err := uc.Authenticate(ctx, issued.Plaintext, servicetoken.ScopeReadStatus); !errors.Is(err, servicetoken.ErrInsufficientScope) {
+		t.Errorf("expected ErrInsufficientScope, got %v", err)
+	}
+
+	if err := uc.Revoke(ctx, "ci"); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, err := uc.Authenticate(ctx, issued.Plaintext, servicetoken.ScopeEnqueue); !errors.Is(err, servicetoken.ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken after revoke, got %v", err)
+	}
+}
+
+func TestServiceTokenUseCase_Authenticate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		plaintext string
+		setup     func(repo *mockRepository, plaintext string)
+		wantErr   error
+	}{
+		{
+			name:      "missing prefix",
+			plaintext: "not-a-service-token",
+			wantErr:   servicetoken.ErrInvalidToken,
+		},
+		{
+			name:      "unknown token",
+			plaintext: "svt_unknown",
+			wantErr:   servicetoken.ErrInvalidToken,
+		},
+		{
+			name:      "expired token",
+			plaintext: "svt_expired",
+			setup: func(repo *mockRepository, plaintext string) {
+				repo.hashes["old"] = servicetoken.Hash(plaintext)
+				repo.tokens["old"] = &servicetoken.Token{ExpiresAt: &past, ID: "old", Scopes: []servicetoken.Scope{servicetoken.ScopeAdmin}}
+			},
+			wantErr: servicetoken.ErrInvalidToken,
+		},
+		{
+			name:      "admin token with failing touch",
+			plaintext: "svt_admin",
+			setup: func(repo *mockRepository, plaintext string) {
+				repo.hashes["admin"] = servicetoken.Hash(plaintext)
+				repo.tokens["admin"] = &servicetoken.Token{ID: "admin", Scopes: []servicetoken.Scope{servicetoken.ScopeAdmin}}
+				repo.touchErr = errors.New("connection reset")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			if tt.setup != nil {
+				tt.setup(repo, tt.plaintext)
+			}
+
+			_, err := NewServiceTokenUseCase(repo).Authenticate(context.Background(), tt.plaintext, servicetoken.ScopeReadStatus)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceTokenUseCase_Issue_InvalidParams(t *testing.T) {
+	_, err := NewServiceTokenUseCase(newMockRepository()).Issue(context.Background(), servicetoken.IssueParams{Name: "ci"})
+	if !errors.Is(err, servicetoken.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput, got %v", err)
+	}
+}
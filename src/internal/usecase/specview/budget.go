@@ -0,0 +1,148 @@
+package specview
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// loadBudget returns the budget of the user's organization, or nil when the
+// repository does not enforce budgets or the organization has none. Lookup
+// errors fail the attempt so the job is retried rather than run unchecked.
+func (uc *GenerateSpecViewUseCase) loadBudget(ctx context.Context, userID string) (*specview.Budget, error) {
+	if uc.budgetRepo == nil {
+		return nil, nil
+	}
+	budget, err := uc.budgetRepo.GetRemainingBudget(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("load AI budget: %w", err)
+	}
+	return budget, nil
+}
+
+// enforceBudget checks the estimated cost of a phase against the budget.
+// Phase 2 assumes every test misses the behavior cache, since the cache is
+// only probed during the phase. The phase is checked against the model it
+// runs on: the one models selects, else modelID. When the budget downgrades
+// the job, the returned ctx makes the provider use the downgrade model for
+// every phase, and the returned model ID, with no phase models, labels the
+// document and caches with it.
+func (uc *GenerateSpecViewUseCase) enforceBudget(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	budget *specview.Budget,
+	phase string,
+	testCount int,
+	modelID string,
+	models specview.PhaseModels,
+	decisions *specview.DecisionLog,
+) (context.Context, string, specview.PhaseModels, error) {
+	estimatedTokens := int64(testCount) * specview.EstimatedTokensPerTest
+	var estimatedBehaviors int64
+	phaseModel := cmp.Or(models.Phase1, modelID)
+	if phase == "phase2" {
+		estimatedBehaviors = int64(testCount)
+		phaseModel = cmp.Or(models.Phase2, modelID)
+	}
+
+	switch budget.Check(estimatedTokens, estimatedBehaviors, phaseModel) {
+	case specview.BudgetDowngrade:
+		slog.InfoContext(ctx, "AI budget low, downgrading model",
+			"analysis_id", req.AnalysisID,
+			"user_id", req.UserID,
+			"tenant_id", budget.TenantID,
+			"phase", phase,
+			"model", budget.DowngradeModel,
+		)
+		decisions.Record(specview.DecisionModelDowngrade, phase, map[string]any{
+			"estimated_behaviors": estimatedBehaviors,
+			"estimated_tokens":    estimatedTokens,
+			"from_model":          phaseModel,
+			"to_model":            budget.DowngradeModel,
+		})
+		return specview.WithModelOverride(ctx, budget.DowngradeModel), budget.DowngradeModel, specview.PhaseModels{}, nil
+	case specview.BudgetReject:
+		slog.WarnContext(ctx, "AI budget exceeded, rejecting job",
+			"analysis_id", req.AnalysisID,
+			"user_id", req.UserID,
+			"tenant_id", budget.TenantID,
+			"phase", phase,
+			"estimated_tokens", estimatedTokens,
+			"estimated_behaviors", estimatedBehaviors,
+		)
+		return ctx, modelID, models, fmt.Errorf("%w: %s needs about %d tokens", specview.ErrBudgetExceeded, phase, estimatedTokens)
+	default:
+		return ctx, modelID, models, nil
+	}
+}
+
+// recordTokenUsage charges the tokens the job just spent to the organization,
+// whether or not the job goes on to save a document. Failures are
+// non-critical: the tokens are already spent.
+func (uc *GenerateSpecViewUseCase) recordTokenUsage(
+	ctx context.Context,
+	budget *specview.Budget,
+	jobID int64,
+	usages ...*specview.TokenUsage,
+) {
+	if uc.budgetRepo == nil || budget == nil {
+		return
+	}
+	var tokens int64
+	for _, usage := range usages {
+		if usage != nil {
+			tokens += int64(usage.TotalTokens)
+		}
+	}
+	if tokens == 0 {
+		return
+	}
+	if err := uc.budgetRepo.RecordTokenUsage(ctx, budget.TenantID, jobID, tokens); err != nil {
+		slog.WarnContext(ctx, "failed to record AI token usage (non-critical)",
+			"tenant_id", budget.TenantID,
+			"job_id", jobID,
+			"tokens", tokens,
+			"error", err,
+		)
+	}
+}
+
+// recordAIUsage persists the token usage of each phase, keyed by phase name,
+// filling in the job and attribution fields from base. Phases without usage,
+// such as a fully cached Phase 2, are skipped. Failures are non-critical.
+func (uc *GenerateSpecViewUseCase) recordAIUsage(
+	ctx context.Context,
+	base specview.AIUsage,
+	usages map[string]*specview.TokenUsage,
+) {
+	if uc.aiUsageRecorder == nil {
+		return
+	}
+	var rows []specview.AIUsage
+	for _, phase := range slices.Sorted(maps.Keys(usages)) {
+		usage := usages[phase]
+		if usage == nil || *usage == (specview.TokenUsage{}) {
+			continue
+		}
+		row := base
+		row.Phase = phase
+		row.TokenUsage = *usage
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return
+	}
+	if err := uc.aiUsageRecorder.RecordAIUsage(ctx, rows); err != nil {
+		slog.WarnContext(ctx, "failed to persist AI token usage (non-critical)",
+			"analysis_id", base.AnalysisID,
+			"job_id", base.JobID,
+			"document_id", base.DocumentID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,244 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockBudgetRepository struct {
+	mockRepository
+	budget         *specview.Budget
+	chargedJobs    []int64
+	recordedTokens int64
+}
+
+func (m *mockBudgetRepository) GetRemainingBudget(ctx context.Context, userID string) (*specview.Budget, error) {
+	return m.budget, nil
+}
+
+func (m *mockBudgetRepository) RecordTokenUsage(ctx context.Context, tenantID string, jobID int64, tokens int64) error {
+	m.chargedJobs = append(m.chargedJobs, jobID)
+	m.recordedTokens += tokens
+	return nil
+}
+
+func int64Ptr(n int64) *int64 {
+	return &n
+}
+
+func newBudgetTestUseCase(budget *specview.Budget, aiProvider *mockAIProvider) (*GenerateSpecViewUseCase, *mockBudgetRepository, *string) {
+	repo := &mockBudgetRepository{budget: budget}
+	repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+		return newTestFiles(), nil
+	}
+	var savedModelID string
+	repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+		doc.ID = "doc-001"
+		savedModelID = doc.ModelID
+		return nil
+	}
+	return NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash"), repo, &savedModelID
+}
+
+func newBudgetTestProvider(models *[]string) *mockAIProvider {
+	record := func(ctx context.Context) {
+		model, _ := specview.ModelOverride(ctx)
+		*models = append(*models, model)
+	}
+	return &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			record(ctx)
+			return newPhase1Output(), &specview.TokenUsage{TotalTokens: 40}, nil
+		},
+		convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+			record(ctx)
+			return &specview.Phase2Output{}, &specview.TokenUsage{TotalTokens: 10}, nil
+		},
+	}
+}
+
+func TestGenerateSpecViewUseCase_Budget(t *testing.T) {
+	t.Run("rejects when the budget is exhausted", func(t *testing.T) {
+		var models []string
+		usecase, _, _ := newBudgetTestUseCase(&specview.Budget{RemainingTokens: int64Ptr(0), TenantID: "tenant-1"}, newBudgetTestProvider(&models))
+
+		_, err := usecase.Execute(context.Background(), newValidRequest())
+		if !errors.Is(err, specview.ErrBudgetExceeded) {
+			t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+		}
+		if len(models) != 0 {
+			t.Errorf("no AI call should be made, got %d", len(models))
+		}
+	})
+
+	t.Run("rejects when the job would overspend without a downgrade model", func(t *testing.T) {
+		var models []string
+		usecase, _, _ := newBudgetTestUseCase(&specview.Budget{RemainingTokens: int64Ptr(100), TenantID: "tenant-1"}, newBudgetTestProvider(&models))
+
+		if _, err := usecase.Execute(context.Background(), newValidRequest()); !errors.Is(err, specview.ErrBudgetExceeded) {
+			t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+		}
+	})
+
+	t.Run("downgrades when the job would overspend", func(t *testing.T) {
+		var models []string
+		budget := &specview.Budget{DowngradeModel: "small-model", RemainingTokens: int64Ptr(100), TenantID: "tenant-1"}
+		usecase, repo, savedModelID := newBudgetTestUseCase(budget, newBudgetTestProvider(&models))
+
+		if _, err := usecase.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, model := range models {
+			if model != "small-model" {
+				t.Fatalf("every AI call should use the downgrade model, got %v", models)
+			}
+		}
+		if *savedModelID != "small-model" {
+			t.Errorf("document model = %q, want small-model", *savedModelID)
+		}
+		if repo.recordedTokens == 0 {
+			t.Error("token usage should be charged to the organization")
+		}
+	})
+
+	t.Run("runs unchanged within the budget", func(t *testing.T) {
+		var models []string
+		budget := &specview.Budget{DowngradeModel: "small-model", RemainingTokens: int64Ptr(1_000_000), TenantID: "tenant-1"}
+		usecase, repo, savedModelID := newBudgetTestUseCase(budget, newBudgetTestProvider(&models))
+
+		if _, err := usecase.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *savedModelID != "gemini-2.5-flash" {
+			t.Errorf("document model = %q, want gemini-2.5-flash", *savedModelID)
+		}
+		if repo.recordedTokens != 40+10*int64(len(models)-1) {
+			t.Errorf("recorded tokens = %d, want the sum of phase usage", repo.recordedTokens)
+		}
+	})
+
+	t.Run("charges the phases a failed job ran", func(t *testing.T) {
+		var models []string
+		provider := newBudgetTestProvider(&models)
+		convert := provider.convertTestNamesFn
+		provider.convertTestNamesFn = func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+			if input.FeatureName == "Logout" {
+				return nil, nil, errors.New("model overloaded")
+			}
+			return convert(ctx, input)
+		}
+		budget := &specview.Budget{RemainingTokens: int64Ptr(1_000_000), TenantID: "tenant-1"}
+		usecase, repo, _ := newBudgetTestUseCase(budget, provider)
+		usecase.config.FailureThreshold = 0
+
+		req := newValidRequest()
+		req.JobID = 42
+		if _, err := usecase.Execute(context.Background(), req); !errors.Is(err, ErrPartialFeatureFailure) {
+			t.Fatalf("expected ErrPartialFeatureFailure, got %v", err)
+		}
+		if want := 40 + 10*int64(len(models)-1); repo.recordedTokens != want {
+			t.Errorf("recorded tokens = %d, want %d from Phase 1 and the converted features", repo.recordedTokens, want)
+		}
+		for _, jobID := range repo.chargedJobs {
+			if jobID != 42 {
+				t.Errorf("tokens should be charged under the job, got job %d", jobID)
+			}
+		}
+	})
+
+	t.Run("fan-out children charge the parent's budget", func(t *testing.T) {
+		var models []string
+		usecase, repo, _ := newBudgetTestUseCase(nil, newBudgetTestProvider(&models))
+
+		err := usecase.ConvertDomain(context.Background(), specview.Phase2DomainTask{
+			AnalysisID:     "analysis-1",
+			BudgetTenantID: "tenant-1",
+			Domain:         newPhase1Output().Domains[0],
+			Language:       "English",
+			ModelID:        "gemini-2.5-flash",
+			ParentJobID:    42,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.recordedTokens != 10*int64(len(models)) || len(repo.chargedJobs) != 1 || repo.chargedJobs[0] != 42 {
+			t.Errorf("child should charge %d tokens under the parent job, charged %d under %v",
+				10*len(models), repo.recordedTokens, repo.chargedJobs)
+		}
+	})
+}
+
+type mockAIUsageRecorder struct {
+	mockRepository
+	recorded []specview.AIUsage
+}
+
+func (m *mockAIUsageRecorder) RecordAIUsage(ctx context.Context, usages []specview.AIUsage) error {
+	m.recorded = append(m.recorded, usages...)
+	return nil
+}
+
+func TestGenerateSpecViewUseCase_RecordAIUsage(t *testing.T) {
+	t.Run("records each phase attributed to the job", func(t *testing.T) {
+		repo := &mockAIUsageRecorder{}
+		repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		}
+		repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+			doc.ID = "doc-001"
+			return nil
+		}
+		var models []string
+		usecase := NewGenerateSpecViewUseCase(repo, newBudgetTestProvider(&models), "gemini-2.5-flash")
+
+		req := newValidRequest()
+		req.JobID = 42
+		if _, err := usecase.Execute(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(repo.recorded) != 2 {
+			t.Fatalf("expected phase 1 and phase 2 usage, got %+v", repo.recorded)
+		}
+		for i, phase := range []string{"phase1", "phase2"} {
+			usage := repo.recorded[i]
+			if usage.Phase != phase || usage.JobID != 42 || usage.UserID != req.UserID ||
+				usage.DocumentID != "doc-001" || usage.AnalysisID != req.AnalysisID {
+				t.Errorf("unexpected %s usage: %+v", phase, usage)
+			}
+		}
+		if repo.recorded[0].TotalTokens != 40 {
+			t.Errorf("phase 1 tokens = %d, want 40", repo.recorded[0].TotalTokens)
+		}
+	})
+
+	t.Run("fan-out children record under the parent job", func(t *testing.T) {
+		repo := &mockAIUsageRecorder{}
+		repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		}
+		var models []string
+		usecase := NewGenerateSpecViewUseCase(repo, newBudgetTestProvider(&models), "gemini-2.5-flash")
+
+		err := usecase.ConvertDomain(context.Background(), specview.Phase2DomainTask{
+			AnalysisID:  "analysis-1",
+			Domain:      newPhase1Output().Domains[0],
+			Language:    "English",
+			ModelID:     "gemini-2.5-flash",
+			ParentJobID: 42,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(repo.recorded) != 1 {
+			t.Fatalf("expected the child's phase 2 usage, got %+v", repo.recorded)
+		}
+		if usage := repo.recorded[0]; usage.Phase != "phase2" || usage.JobID != 42 || usage.UserID != "" || usage.DocumentID != "" {
+			t.Errorf("unexpected child usage: %+v", usage)
+		}
+	})
+}
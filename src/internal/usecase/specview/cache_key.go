@@ -0,0 +1,128 @@
+package specview
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// behaviorKeyInput is the part of a behavior cache key that varies per test.
+type behaviorKeyInput struct {
+	filePath string
+	test     specview.TestInfo
+}
+
+// behaviorCacheKeyHex returns the hex-encoded behavior cache key of a test.
+func behaviorCacheKeyHex(algorithm specview.HashAlgorithm, filePath string, test specview.TestInfo, lang specview.Language, modelID, style, glossaryVersion, promptVersion, scope string) string {
+	hash := specview.GenerateCacheKeyHash(specview.BehaviorCacheKey{
+		Algorithm:       algorithm,
+		FilePath:        filePath,
+		GlossaryVersion: glossaryVersion,
+		Language:        lang,
+		ModelID:         modelID,
+		PromptVersion:   promptVersion,
+		Scope:           scope,
+		Style:           style,
+		SuitePath:       test.SuitePath,
+		TestName:        test.Name,
+	})
+	return hex.EncodeToString(hash)
+}
+
+// findLegacyBehaviors looks up the tests in missed, keyed by their current
+// hex key, under the keys of the legacy algorithm. Found descriptions are
+// returned by current hex key. Legacy keys carry the cache scope of ctx, so
+// the migration never crosses scopes.
+func findLegacyBehaviors(
+	ctx context.Context,
+	repo specview.Repository,
+	legacy specview.HashAlgorithm,
+	missed map[string]behaviorKeyInput,
+	lang specview.Language,
+	modelID string,
+	style string,
+	glossaryVersion string,
+	promptVersion string,
+) (map[string]string, error) {
+	if len(missed) == 0 {
+		return nil, nil
+	}
+
+	scope := specview.CacheScope(ctx)
+	currentByLegacy := make(map[string]string, len(missed))
+	hashes := make([][]byte, 0, len(missed))
+	for currentHex, input := range missed {
+		legacyHex := behaviorCacheKeyHex(legacy, input.filePath, input.test, lang, modelID, style, glossaryVersion, promptVersion, scope)
+		if _, exists := currentByLegacy[legacyHex]; exists {
+			continue
+		}
+		hash, err := hex.DecodeString(legacyHex)
+		if err != nil {
+			continue
+		}
+		currentByLegacy[legacyHex] = currentHex
+		hashes = append(hashes, hash)
+	}
+
+	cached, err := repo.FindCachedBehaviors(ctx, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("find legacy cached behaviors: %w", err)
+	}
+	found := make(map[string]string, len(cached))
+	for legacyHex, description := range cached {
+		found[currentByLegacy[legacyHex]] = description
+	}
+	return found, nil
+}
+
+// migrateLegacyBehaviors adds the behaviors cached under legacy keys for the
+// tests in missed to cached, and copies them to the current keys so the next
+// job finds them directly. Failures are non-critical: the tests are converted
+// again instead.
+func (uc *GenerateSpecViewUseCase) migrateLegacyBehaviors(
+	ctx context.Context,
+	cached map[string]string,
+	missed map[string]behaviorKeyInput,
+	lang specview.Language,
+	modelID string,
+	style string,
+	glossaryVersion string,
+	promptVersion string,
+) {
+	found, err := findLegacyBehaviors(ctx, uc.repository, uc.config.LegacyCacheKeyHash, missed, lang, modelID, style, glossaryVersion, promptVersion)
+	if err != nil {
+		slog.WarnContext(ctx, "legacy behavior cache lookup failed (non-critical)",
+			"legacy_hash", uc.config.LegacyCacheKeyHash,
+			"error", err,
+		)
+		return
+	}
+	if len(found) == 0 {
+		return
+	}
+
+	entries := make([]specview.BehaviorCacheEntry, 0, len(found))
+	for currentHex, description := range found {
+		hash, err := hex.DecodeString(currentHex)
+		if err != nil {
+			continue
+		}
+		cached[currentHex] = description
+		entries = append(entries, specview.BehaviorCacheEntry{CacheKeyHash: hash, Description: description})
+	}
+	if err := uc.repository.SaveBehaviorCache(ctx, entries); err != nil {
+		slog.WarnContext(ctx, "failed to migrate legacy behavior cache entries (non-critical)",
+			"entry_count", len(entries),
+			"error", err,
+		)
+		return
+	}
+	slog.InfoContext(ctx, "migrated legacy behavior cache entries",
+		"entry_count", len(entries),
+		"legacy_hash", uc.config.LegacyCacheKeyHash,
+		"hash", uc.config.CacheKeyHash,
+	)
+}
@@ -0,0 +1,62 @@
+package specview
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestLookupBehaviorCache_LegacyKeys(t *testing.T) {
+	files := newTestFiles()
+	test := files[0].Tests[0]
+	legacyKey := behaviorCacheKeyHex(specview.HashSHA256, files[0].Path, test, "Korean", "gemini-2.5-flash", "", "", "", "")
+	currentKey := behaviorCacheKeyHex(specview.HashBLAKE3, files[0].Path, test, "Korean", "gemini-2.5-flash", "", "", "", "")
+
+	var saved []specview.BehaviorCacheEntry
+	repo := &mockRepository{
+		findCachedBehaviorsFn: func(ctx context.Context, hashes [][]byte) (map[string]string, error) {
+			found := make(map[string]string)
+			for _, hash := range hashes {
+				if key := hex.EncodeToString(hash); key == legacyKey {
+					found[key] = "사용자가 로그인할 수 있다"
+				}
+			}
+			return found, nil
+		},
+		saveBehaviorCacheFn: func(ctx context.Context, entries []specview.BehaviorCacheEntry) error {
+			saved = append(saved, entries...)
+			return nil
+		},
+	}
+	lookup := func(opts ...Option) map[string]string {
+		uc := NewGenerateSpecViewUseCase(repo, &mockAIProvider{}, "gemini-2.5-flash", opts...)
+		cached, _, err := uc.lookupBehaviorCache(context.Background(), newPhase1Output(), buildTestIndexMap(files), buildTestFilePathMap(files), "Korean", "gemini-2.5-flash", "", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return cached
+	}
+
+	t.Run("migrates hits under legacy keys", func(t *testing.T) {
+		saved = nil
+
+		cached := lookup(WithCacheKeyHash(specview.HashBLAKE3, specview.HashSHA256))
+
+		if len(cached) != 1 || cached[currentKey] != "사용자가 로그인할 수 있다" {
+			t.Errorf("expected the legacy hit under the BLAKE3 key, got %v", cached)
+		}
+		if len(saved) != 1 || hex.EncodeToString(saved[0].CacheKeyHash) != currentKey {
+			t.Errorf("expected the hit copied to the BLAKE3 key, got %+v", saved)
+		}
+	})
+
+	t.Run("ignores legacy keys without a fallback", func(t *testing.T) {
+		saved = nil
+
+		if cached := lookup(WithCacheKeyHash(specview.HashBLAKE3, "")); len(cached) != 0 || len(saved) != 0 {
+			t.Errorf("expected no hits or migration, got %v and %+v", cached, saved)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package specview
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// withCacheScope sets the behavior cache scope of userID on ctx. A failed
+// tenant lookup scopes the cache to the user alone, so an outage never widens
+// reuse across tenants; it only costs cache hits.
+func withCacheScope(ctx context.Context, mode specview.CacheScopeMode, lookup specview.TenantLookup, userID string) context.Context {
+	if mode == specview.CacheScopeGlobal || mode == "" {
+		return ctx
+	}
+
+	var tenantID string
+	if mode == specview.CacheScopeOrganization && lookup != nil {
+		id, err := lookup.GetUserTenantID(ctx, userID)
+		if err != nil {
+			slog.WarnContext(ctx, "tenant lookup failed, scoping behavior cache to the user (non-critical)",
+				"user_id", userID,
+				"error", err,
+			)
+		} else {
+			tenantID = id
+		}
+	}
+	return specview.WithCacheScope(ctx, mode.Scope(userID, tenantID))
+}
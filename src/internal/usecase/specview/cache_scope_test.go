@@ -0,0 +1,102 @@
+package specview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockTenantRepository struct {
+	mockRepository
+	err      error
+	tenantID string
+}
+
+func (m *mockTenantRepository) GetUserTenantID(ctx context.Context, userID string) (string, error) {
+	return m.tenantID, m.err
+}
+
+func TestEstimateSpecViewUseCase_CacheScope(t *testing.T) {
+	files := newTestFiles()
+	keyIn := func(scope string) string {
+		return behaviorCacheKeyHex(specview.HashSHA256, files[0].Path, files[0].Tests[0], "Korean", "gemini-2.5-flash", "", "", specview.DefaultPromptVersion, scope)
+	}
+	newRepo := func(tenantID string, err error, cachedKey string) *mockTenantRepository {
+		return &mockTenantRepository{
+			mockRepository: mockRepository{
+				getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+					return newTestFiles(), nil
+				},
+				findCachedBehaviorsFn: func(ctx context.Context, cacheKeyHashes [][]byte) (map[string]string, error) {
+					return map[string]string{cachedKey: "Logs in"}, nil
+				},
+			},
+			err:      err,
+			tenantID: tenantID,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		mode      specview.CacheScopeMode
+		repo      *mockTenantRepository
+		wantCache int
+	}{
+		{"global mode reuses global entries", specview.CacheScopeGlobal, newRepo("acme", nil, keyIn("")), 1},
+		{"organization mode skips global entries", specview.CacheScopeOrganization, newRepo("acme", nil, keyIn("")), 0},
+		{"organization mode reuses the tenant's entries", specview.CacheScopeOrganization, newRepo("acme", nil, keyIn("tenant:acme")), 1},
+		{"organization mode skips other tenants' entries", specview.CacheScopeOrganization, newRepo("globex", nil, keyIn("tenant:acme")), 0},
+		{"tenantless users keep their own entries", specview.CacheScopeOrganization, newRepo("", nil, keyIn("user:test-user-001")), 1},
+		{"failed lookup scopes to the user", specview.CacheScopeOrganization, newRepo("acme", errors.New("db down"), keyIn("user:test-user-001")), 1},
+		{"user mode reuses the user's entries", specview.CacheScopeUser, newRepo("acme", nil, keyIn("user:test-user-001")), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewEstimateSpecViewUseCase(tt.repo, "gemini-2.5-flash", WithCacheScope(tt.mode))
+			estimate, err := uc.Execute(context.Background(), newValidRequest())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if estimate.CachedBehaviors != tt.wantCache {
+				t.Errorf("cached behaviors = %d, want %d", estimate.CachedBehaviors, tt.wantCache)
+			}
+		})
+	}
+}
+
+func TestGenerateSpecViewUseCase_CacheScope_Classification(t *testing.T) {
+	signatureIn := func(mode specview.CacheScopeMode) []byte {
+		var signature []byte
+		repo := &mockTenantRepository{tenantID: "acme"}
+		repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		}
+		repo.findClassificationCacheFn = func(ctx context.Context, fileSignature []byte, language specview.Language, modelID string) (*specview.ClassificationCache, error) {
+			signature = fileSignature
+			return nil, nil
+		}
+		uc := NewGenerateSpecViewUseCase(repo, newFanOutAIProvider(new(atomic.Int32)), "gemini-2.5-flash", WithCacheScope(mode))
+		if _, err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return signature
+	}
+
+	global := signatureIn(specview.CacheScopeGlobal)
+	organization := signatureIn(specview.CacheScopeOrganization)
+	if len(global) == 0 || bytes.Equal(global, organization) {
+		t.Errorf("scoped classifications should be looked up under their own signature, got %x and %x", global, organization)
+	}
+}
+
+func TestWithCacheScope_IgnoresUnknownModes(t *testing.T) {
+	cfg := Config{CacheScope: specview.CacheScopeUser}
+	WithCacheScope("team")(&cfg)
+	if cfg.CacheScope != specview.CacheScopeUser {
+		t.Errorf("cache scope = %q, want it unchanged", cfg.CacheScope)
+	}
+}
@@ -0,0 +1,76 @@
+package specview
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// watchCancellation derives a context that is cancelled with
+// specview.ErrGenerationCancelled once requestedBy cancels their generation of
+// the analysis in lang; other users requesting the same document cannot stop
+// it. Without a user nothing is watched. It checks right away, so a job
+// cancelled while queued stops before its first AI call, then every
+// CancelPollInterval. Check errors are logged and retried on the next poll. A
+// zero since only counts cancellations requested from now on. The returned
+// stop function must be called to release the poller.
+func (uc *GenerateSpecViewUseCase) watchCancellation(
+	ctx context.Context,
+	analysisID string,
+	lang specview.Language,
+	requestedBy string,
+	since time.Time,
+) (context.Context, func()) {
+	if uc.cancelReader == nil || requestedBy == "" {
+		return ctx, func() {}
+	}
+
+	if since.IsZero() {
+		since = time.Now()
+	}
+
+	watchCtx, cancel := context.WithCancelCause(ctx)
+	cancelled := func() bool {
+		ok, err := uc.cancelReader.IsGenerationCancelled(watchCtx, analysisID, lang, requestedBy, since)
+		if err != nil {
+			if watchCtx.Err() == nil {
+				slog.WarnContext(ctx, "failed to check generation cancellation, retrying",
+					"analysis_id", analysisID,
+					"error", err,
+				)
+			}
+			return false
+		}
+		if ok {
+			slog.InfoContext(ctx, "generation cancelled by user",
+				"analysis_id", analysisID,
+				"language", lang,
+			)
+			cancel(specview.ErrGenerationCancelled)
+		}
+		return ok
+	}
+
+	stop := func() { cancel(context.Canceled) }
+	if cancelled() {
+		return watchCtx, stop
+	}
+
+	go func() {
+		ticker := time.NewTicker(uc.config.CancelPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-watchCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			if cancelled() {
+				return
+			}
+		}
+	}()
+	return watchCtx, stop
+}
@@ -0,0 +1,190 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockCancellationRepository struct {
+	mockProgressRepository
+	cancelledAt atomic.Pointer[time.Time]
+	cancelledBy atomic.Pointer[string]
+}
+
+func (m *mockCancellationRepository) IsGenerationCancelled(_ context.Context, _ string, _ specview.Language, requestedBy string, since time.Time) (bool, error) {
+	at, by := m.cancelledAt.Load(), m.cancelledBy.Load()
+	return at != nil && by != nil && *by == requestedBy && !at.Before(since), nil
+}
+
+// cancel records a cancellation by the user of newValidRequest.
+func (m *mockCancellationRepository) cancel(at time.Time) {
+	m.cancelBy(newValidRequest().UserID, at)
+}
+
+func (m *mockCancellationRepository) cancelBy(userID string, at time.Time) {
+	m.cancelledBy.Store(&userID)
+	m.cancelledAt.Store(&at)
+}
+
+func TestGenerateSpecViewUseCase_Cancellation(t *testing.T) {
+	newRepo := func(saved *[]specview.BehaviorCacheEntry) *mockCancellationRepository {
+		repo := &mockCancellationRepository{}
+		var mu sync.Mutex
+		repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		}
+		repo.saveBehaviorCacheFn = func(ctx context.Context, entries []specview.BehaviorCacheEntry) error {
+			mu.Lock()
+			defer mu.Unlock()
+			*saved = append(*saved, entries...)
+			return nil
+		}
+		return repo
+	}
+	// The first feature converts and the user cancels meanwhile; later features
+	// only finish when their context is cancelled.
+	newProvider := func(onFirst func(), calls *atomic.Int32) *mockAIProvider {
+		return &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				return newPhase1Output(), &specview.TokenUsage{}, nil
+			},
+			convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+				if calls.Add(1) > 1 {
+					<-ctx.Done()
+					return nil, nil, ctx.Err()
+				}
+				onFirst()
+				behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+				for i, test := range input.Tests {
+					behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+				}
+				return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+			},
+		}
+	}
+	newUseCase := func(repo *mockCancellationRepository, provider *mockAIProvider) *GenerateSpecViewUseCase {
+		uc := NewGenerateSpecViewUseCase(repo, provider, "gemini-2.5-flash", WithPhase2Concurrency(1))
+		uc.config.CancelPollInterval = time.Millisecond
+		return uc
+	}
+
+	t.Run("stops Phase 2 and salvages the converted features", func(t *testing.T) {
+		var saved []specview.BehaviorCacheEntry
+		var calls atomic.Int32
+		repo := newRepo(&saved)
+		uc := newUseCase(repo, newProvider(func() { repo.cancel(time.Now()) }, &calls))
+
+		req := newValidRequest()
+		req.RequestedAt = time.Now().Add(-time.Minute)
+		_, err := uc.Execute(context.Background(), req)
+		if !errors.Is(err, specview.ErrGenerationCancelled) {
+			t.Fatalf("expected ErrGenerationCancelled, got %v", err)
+		}
+		if n := calls.Load(); n > 2 {
+			t.Errorf("expected Phase 2 to stop after the cancellation, got %d conversions", n)
+		}
+		if n := len(saved); n == 0 || n == 4 {
+			t.Errorf("expected only the converted feature's behaviors salvaged, got %d of 4 entries", n)
+		}
+		last := repo.snapshots[len(repo.snapshots)-1]
+		if last.Status != specview.GenerationCancelled {
+			t.Errorf("expected a cancelled snapshot, got %+v", last)
+		}
+	})
+
+	t.Run("stops a job cancelled while queued before converting", func(t *testing.T) {
+		var saved []specview.BehaviorCacheEntry
+		var calls atomic.Int32
+		repo := newRepo(&saved)
+		repo.cancel(time.Now())
+		uc := newUseCase(repo, newProvider(func() {}, &calls))
+
+		req := newValidRequest()
+		req.RequestedAt = time.Now().Add(-time.Minute)
+		if _, err := uc.Execute(context.Background(), req); !errors.Is(err, specview.ErrGenerationCancelled) {
+			t.Fatalf("expected ErrGenerationCancelled, got %v", err)
+		}
+		if n := calls.Load(); n > 1 {
+			t.Errorf("expected no conversion to complete, got %d calls", n)
+		}
+	})
+
+	t.Run("ignores a cancellation requested before the job", func(t *testing.T) {
+		var saved []specview.BehaviorCacheEntry
+		repo := newRepo(&saved)
+		repo.cancel(time.Now().Add(-time.Hour))
+		repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+			doc.ID = "doc-001"
+			return nil
+		}
+		uc := newUseCase(repo, &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				return newPhase1Output(), &specview.TokenUsage{}, nil
+			},
+			convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+				behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+				for i, test := range input.Tests {
+					behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+				}
+				return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+			},
+		})
+
+		req := newValidRequest()
+		req.RequestedAt = time.Now()
+		if _, err := uc.Execute(context.Background(), req); err != nil {
+			t.Fatalf("expected success, got %v", err)
+		}
+	})
+
+	t.Run("ignores another requester cancelling the same document", func(t *testing.T) {
+		var saved []specview.BehaviorCacheEntry
+		var calls atomic.Int32
+		repo := newRepo(&saved)
+		repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+			doc.ID = "doc-001"
+			return nil
+		}
+		// The other user cancels during the first feature; each feature takes
+		// long enough for the cancellation to be polled.
+		uc := newUseCase(repo, &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				return newPhase1Output(), &specview.TokenUsage{}, nil
+			},
+			convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+				if calls.Add(1) == 1 {
+					repo.cancelBy("other-user", time.Now())
+				}
+				select {
+				case <-ctx.Done():
+					return nil, nil, ctx.Err()
+				case <-time.After(20 * time.Millisecond):
+				}
+				behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+				for i, test := range input.Tests {
+					behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+				}
+				return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+			},
+		})
+
+		req := newValidRequest()
+		req.RequestedAt = time.Now().Add(-time.Minute)
+		if _, err := uc.Execute(context.Background(), req); err != nil {
+			t.Fatalf("expected the requester's generation to finish, got %v", err)
+		}
+
+		other := newValidRequest()
+		other.UserID = "other-user"
+		other.RequestedAt = time.Now().Add(-time.Minute)
+		if _, err := uc.Execute(context.Background(), other); !errors.Is(err, specview.ErrGenerationCancelled) {
+			t.Fatalf("expected the other requester's generation cancelled, got %v", err)
+		}
+	})
+}
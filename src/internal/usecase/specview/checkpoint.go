@@ -0,0 +1,179 @@
+package specview
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// checkpointSaveInterval is the number of converted features batched into one
+// checkpoint write during Phase 2.
+const checkpointSaveInterval = 10
+
+type featureKey struct {
+	domainIdx  int
+	featureIdx int
+}
+
+// checkpointWriter accumulates the progress of one job and persists it. Once
+// the checkpoint is saved, only features converted since the last write are
+// appended. A nil writer records nothing, so callers need no checks when
+// checkpointing is off.
+type checkpointWriter struct {
+	checkpoint specview.GenerationCheckpoint
+	completed  map[featureKey][]specview.BehaviorSpec
+	mu         sync.Mutex
+	repo       specview.CheckpointRepository
+	saved      bool
+	unsaved    []specview.FeatureCheckpoint
+}
+
+// loadCheckpoint returns the checkpoint an earlier attempt of the job left for
+// the same content, or nil. Lookup failures are non-critical and restart the
+// generation from Phase 1.
+func (uc *GenerateSpecViewUseCase) loadCheckpoint(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	contentHash []byte,
+) *specview.GenerationCheckpoint {
+	if uc.checkpointRepo == nil || req.JobID == 0 {
+		return nil
+	}
+
+	checkpoint, err := uc.checkpointRepo.LoadCheckpoint(ctx, req.JobID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load generation checkpoint (non-critical)",
+			"analysis_id", req.AnalysisID,
+			"job_id", req.JobID,
+			"error", err,
+		)
+		return nil
+	}
+	if checkpoint == nil {
+		return nil
+	}
+	if !checkpoint.Matches(contentHash) {
+		slog.InfoContext(ctx, "generation checkpoint ignored",
+			"analysis_id", req.AnalysisID,
+			"job_id", req.JobID,
+			"reason", "content_changed",
+		)
+		return nil
+	}
+	return checkpoint
+}
+
+// newCheckpointWriter starts recording the progress of the job on top of what
+// resumed already holds. phase1Output is the Phase 1 output before curation.
+func (uc *GenerateSpecViewUseCase) newCheckpointWriter(
+	req specview.SpecViewRequest,
+	contentHash []byte,
+	phase1Output *specview.Phase1Output,
+	resumed *specview.GenerationCheckpoint,
+) *checkpointWriter {
+	if uc.checkpointRepo == nil || req.JobID == 0 {
+		return nil
+	}
+
+	w := &checkpointWriter{
+		checkpoint: specview.GenerationCheckpoint{
+			AnalysisID:   req.AnalysisID,
+			ContentHash:  contentHash,
+			JobID:        req.JobID,
+			Phase1Output: phase1Output,
+		},
+		completed: make(map[featureKey][]specview.BehaviorSpec),
+		repo:      uc.checkpointRepo,
+	}
+	if resumed != nil {
+		w.saved = true
+		for _, f := range resumed.Features {
+			w.completed[featureKey{domainIdx: f.DomainIndex, featureIdx: f.FeatureIndex}] = f.Behaviors
+			w.checkpoint.Features = append(w.checkpoint.Features, f)
+		}
+	}
+	return w
+}
+
+// resumedFeature returns the behaviors an earlier attempt converted for the feature.
+func (w *checkpointWriter) resumedFeature(domainIdx, featureIdx int) ([]specview.BehaviorSpec, bool) {
+	if w == nil {
+		return nil, false
+	}
+	behaviors, ok := w.completed[featureKey{domainIdx: domainIdx, featureIdx: featureIdx}]
+	return behaviors, ok
+}
+
+// resumedCount returns the number of features an earlier attempt converted.
+func (w *checkpointWriter) resumedCount() int {
+	if w == nil {
+		return 0
+	}
+	return len(w.completed)
+}
+
+// recordFeature adds a converted feature, writing the checkpoint every
+// checkpointSaveInterval features.
+func (w *checkpointWriter) recordFeature(ctx context.Context, domainIdx, featureIdx int, behaviors []specview.BehaviorSpec) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	feature := specview.FeatureCheckpoint{
+		Behaviors:    behaviors,
+		DomainIndex:  domainIdx,
+		FeatureIndex: featureIdx,
+	}
+	w.checkpoint.Features = append(w.checkpoint.Features, feature)
+	w.unsaved = append(w.unsaved, feature)
+	if len(w.unsaved) >= checkpointSaveInterval {
+		w.saveLocked(ctx)
+	}
+}
+
+// save writes all progress recorded so far.
+func (w *checkpointWriter) save(ctx context.Context) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.saveLocked(ctx)
+}
+
+func (w *checkpointWriter) saveLocked(ctx context.Context) {
+	var err error
+	if w.saved {
+		err = w.repo.AppendCheckpointFeatures(ctx, w.checkpoint.JobID, w.unsaved)
+	} else {
+		err = w.repo.SaveCheckpoint(ctx, &w.checkpoint)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to save generation checkpoint (non-critical)",
+			"job_id", w.checkpoint.JobID,
+			"error", err,
+		)
+		return
+	}
+	w.saved = true
+	w.unsaved = nil
+}
+
+// DiscardCheckpoint removes the progress saved for the job. Workers call it
+// when the job will not be retried; saving the generated document deletes
+// the checkpoint of its job.
+func (uc *GenerateSpecViewUseCase) DiscardCheckpoint(ctx context.Context, jobID int64) {
+	if uc.checkpointRepo == nil || jobID == 0 {
+		return
+	}
+	if err := uc.checkpointRepo.DeleteCheckpoint(ctx, jobID); err != nil {
+		slog.WarnContext(ctx, "failed to delete generation checkpoint (non-critical)",
+			"job_id", jobID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,155 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockCheckpointRepository struct {
+	mockRepository
+	appended    []specview.FeatureCheckpoint
+	checkpoints map[int64]specview.GenerationCheckpoint
+	deleted     []int64
+}
+
+func (m *mockCheckpointRepository) AppendCheckpointFeatures(ctx context.Context, jobID int64, features []specview.FeatureCheckpoint) error {
+	checkpoint := m.checkpoints[jobID]
+	checkpoint.Features = append(slices.Clone(checkpoint.Features), features...)
+	m.checkpoints[jobID] = checkpoint
+	m.appended = append(m.appended, features...)
+	return nil
+}
+
+func (m *mockCheckpointRepository) DeleteCheckpoint(ctx context.Context, jobID int64) error {
+	delete(m.checkpoints, jobID)
+	m.deleted = append(m.deleted, jobID)
+	return nil
+}
+
+func (m *mockCheckpointRepository) LoadCheckpoint(ctx context.Context, jobID int64) (*specview.GenerationCheckpoint, error) {
+	checkpoint, ok := m.checkpoints[jobID]
+	if !ok {
+		return nil, nil
+	}
+	return &checkpoint, nil
+}
+
+func (m *mockCheckpointRepository) SaveCheckpoint(ctx context.Context, checkpoint *specview.GenerationCheckpoint) error {
+	saved := *checkpoint
+	saved.Features = slices.Clone(checkpoint.Features)
+	m.checkpoints[checkpoint.JobID] = saved
+	return nil
+}
+
+func TestGenerateSpecViewUseCase_ResumeFromCheckpoint(t *testing.T) {
+	repo := &mockCheckpointRepository{checkpoints: make(map[int64]specview.GenerationCheckpoint)}
+	repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+		return newTestFiles(), nil
+	}
+	repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+		doc.ID = "doc-001"
+		delete(repo.checkpoints, doc.JobID)
+		return nil
+	}
+
+	var classifyCalls int
+	var converted []string
+	failFeature := "Logout"
+	aiProvider := &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			classifyCalls++
+			return newPhase1Output(), &specview.TokenUsage{}, nil
+		},
+		convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+			converted = append(converted, input.FeatureName)
+			if input.FeatureName == failFeature {
+				return nil, nil, errors.New("model overloaded")
+			}
+			behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+			for i, test := range input.Tests {
+				behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+			}
+			return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+		},
+	}
+	// Concurrency 1 keeps the recorded conversion order free of races.
+	usecase := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash",
+		WithFailureThreshold(0.1),
+		WithPhase2Concurrency(1),
+	)
+
+	req := newValidRequest()
+	req.ForceRegenerate = true
+	req.JobID = 42
+
+	if _, err := usecase.Execute(context.Background(), req); !errors.Is(err, ErrPartialFeatureFailure) {
+		t.Fatalf("first attempt: expected ErrPartialFeatureFailure, got %v", err)
+	}
+	checkpoint, ok := repo.checkpoints[req.JobID]
+	if !ok {
+		t.Fatal("first attempt should leave a checkpoint")
+	}
+	firstConverted := len(converted)
+	if len(checkpoint.Features) != firstConverted-1 {
+		t.Fatalf("checkpoint should hold the %d converted features, got %d", firstConverted-1, len(checkpoint.Features))
+	}
+	if len(repo.appended) != len(checkpoint.Features) {
+		t.Errorf("converted features should be appended to the saved checkpoint, appended %d", len(repo.appended))
+	}
+	if checkpoint.AnalysisID != req.AnalysisID {
+		t.Errorf("checkpoint should belong to analysis %q, got %q", req.AnalysisID, checkpoint.AnalysisID)
+	}
+
+	failFeature = ""
+	converted = nil
+	result, err := usecase.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("retry: unexpected error: %v", err)
+	}
+	if classifyCalls != 1 {
+		t.Errorf("retry should resume at Phase 2, Phase 1 ran %d times", classifyCalls)
+	}
+	if len(converted) != 1 || converted[0] != "Logout" {
+		t.Errorf("retry should only convert the failed feature, converted %v", converted)
+	}
+	if result.DocumentID != "doc-001" {
+		t.Errorf("unexpected document ID %q", result.DocumentID)
+	}
+	if _, ok := repo.checkpoints[req.JobID]; ok {
+		t.Error("checkpoint should be deleted once the document is saved")
+	}
+}
+
+func TestGenerateSpecViewUseCase_CheckpointForOtherContent(t *testing.T) {
+	repo := &mockCheckpointRepository{checkpoints: map[int64]specview.GenerationCheckpoint{
+		42: {ContentHash: []byte("other content"), JobID: 42, Phase1Output: newPhase1Output()},
+	}}
+	repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+		return newTestFiles(), nil
+	}
+
+	var classifyCalls int
+	aiProvider := &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			classifyCalls++
+			return newPhase1Output(), &specview.TokenUsage{}, nil
+		},
+		convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+			return &specview.Phase2Output{}, &specview.TokenUsage{}, nil
+		},
+	}
+
+	req := newValidRequest()
+	req.ForceRegenerate = true
+	req.JobID = 42
+	if _, err := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash").Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if classifyCalls != 1 {
+		t.Errorf("a checkpoint made for other content must not skip Phase 1, classify ran %d times", classifyCalls)
+	}
+}
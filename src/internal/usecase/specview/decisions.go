@@ -0,0 +1,66 @@
+package specview
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/specvital/worker/internal/domain/curation"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// newDecisionLog returns the decision log of one generation. When the
+// repository can write decisions ahead, each one is stored as it is made, so
+// the log of a generation that fails before its document is saved survives.
+func (uc *GenerateSpecViewUseCase) newDecisionLog(ctx context.Context, analysisID string) *specview.DecisionLog {
+	if uc.decisionWriter == nil {
+		return specview.NewDecisionLog()
+	}
+	generationID := uuid.NewString()
+	return specview.NewWriteAheadDecisionLog(generationID, func(sequence int, event specview.DecisionEvent) {
+		// Saving the document stores events whose write failed.
+		if err := uc.decisionWriter.AppendDecision(ctx, analysisID, generationID, sequence, event); err != nil {
+			slog.WarnContext(ctx, "failed to write decision ahead (non-critical)",
+				"analysis_id", analysisID,
+				"generation_id", generationID,
+				"kind", event.Kind,
+				"error", err,
+			)
+		}
+	})
+}
+
+// recordCurationDecisions logs the files the rules exclude and the tests whose
+// placement they force. files must be the unfiltered inventory.
+func recordCurationDecisions(decisions *specview.DecisionLog, files []specview.FileInfo, rules *curation.Rules) {
+	if rules.IsEmpty() {
+		return
+	}
+	for _, f := range files {
+		if rules.ExcludesFile(f.Path) {
+			decisions.Record(specview.DecisionFileExcluded, f.Path, map[string]any{"tests": len(f.Tests)})
+			continue
+		}
+		for _, t := range f.Tests {
+			rule, ok := rules.ForcedPlacement(f.Path, t.SuitePath)
+			if !ok {
+				continue
+			}
+			decisions.Record(specview.DecisionForcedPlacement, t.Name, map[string]any{
+				"domain":    rule.Domain,
+				"feature":   rule.Feature,
+				"file_path": f.Path,
+			})
+		}
+	}
+}
+
+// recordClassificationCache logs how Phase 1 used the classification cache.
+func recordClassificationCache(decisions *specview.DecisionLog, outcome string, detail map[string]any) {
+	if detail == nil {
+		detail = make(map[string]any, 1)
+	}
+	detail["outcome"] = outcome
+	decisions.Record(specview.DecisionClassificationCache, "phase1", detail)
+}
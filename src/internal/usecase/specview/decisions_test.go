@@ -0,0 +1,197 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/curation"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestGenerateSpecViewUseCase_Decisions(t *testing.T) {
+	rules := &curation.Rules{
+		Domains: curation.DomainRules{Force: []curation.ForceRule{{Domain: "Security", Path: "test/auth_test.go"}}},
+		Exclude: []string{"test/user_test.go"},
+		Version: curation.SupportedVersion,
+	}
+
+	var savedDoc *specview.SpecDocument
+	repo := &mockCurationRepository{rules: rules}
+	repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+		return newTestFiles(), nil
+	}
+	repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+		savedDoc = doc
+		doc.ID = "doc-001"
+		return nil
+	}
+	aiProvider := &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			return newPhase1Output(), &specview.TokenUsage{}, nil
+		},
+		convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+			if input.FeatureName == "Logout" {
+				return nil, nil, errors.New("model overloaded")
+			}
+			behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+			for i, test := range input.Tests {
+				behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+			}
+			return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+		},
+	}
+
+	if _, err := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash").Execute(context.Background(), newValidRequest()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts := make(map[specview.DecisionKind]int)
+	subjects := make(map[specview.DecisionKind][]string)
+	for _, d := range savedDoc.Decisions {
+		counts[d.Kind]++
+		subjects[d.Kind] = append(subjects[d.Kind], d.Subject)
+		if d.OccurredAt.IsZero() {
+			t.Errorf("expected timestamp on %+v", d)
+		}
+	}
+
+	if got := subjects[specview.DecisionFileExcluded]; len(got) != 1 || got[0] != "test/user_test.go" {
+		t.Errorf("unexpected excluded files: %v", got)
+	}
+	if counts[specview.DecisionForcedPlacement] != 2 {
+		t.Errorf("expected 2 forced placements, got %v", subjects[specview.DecisionForcedPlacement])
+	}
+	if counts[specview.DecisionClassificationCache] != 1 || savedDoc.Decisions[3].Detail["outcome"] != "miss" {
+		t.Errorf("expected a classification cache miss, got %+v", savedDoc.Decisions)
+	}
+	if counts[specview.DecisionBehaviorCacheMiss] != 2 || counts[specview.DecisionBehaviorCacheHit] != 0 {
+		t.Errorf("unexpected behavior cache decisions: %+v", counts)
+	}
+	if got := subjects[specview.DecisionFeatureFallback]; len(got) != 1 || got[0] != "Logout" {
+		t.Errorf("unexpected feature fallbacks: %v", got)
+	}
+}
+
+func TestExecutePhase1WithCache_PlacementFallbackDecisions(t *testing.T) {
+	files := newTestFiles()
+	cachedOutput := &specview.Phase1Output{Domains: []specview.DomainGroup{{
+		Name:     "Authentication",
+		Features: []specview.FeatureGroup{{Name: "Login", TestIndices: []int{0, 1, 2}}},
+	}}}
+	repo := &mockRepository{
+		findClassificationCacheFn: func(ctx context.Context, fileSignature []byte, language specview.Language, modelID string) (*specview.ClassificationCache, error) {
+			return &specview.ClassificationCache{
+				ClassificationResult: cachedOutput,
+				TestIndexMap:         specview.BuildTestIndexMap(cachedOutput, files),
+			}, nil
+		},
+	}
+	aiProvider := &mockAIProvider{
+		placeNewTestsFn: func(ctx context.Context, input specview.PlacementInput) (*specview.PlacementOutput, *specview.TokenUsage, error) {
+			return nil, nil, errors.New("placement unavailable")
+		},
+	}
+	decisions := specview.NewDecisionLog()
+
+	uc := NewGenerateSpecViewUseCase(repo, aiProvider, "model")
+	if _, _, err := uc.executePhase1WithCache(context.Background(), files, "English", "model", "analysis-1", false, nil, nil, decisions); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var fallbacks []string
+	for _, d := range decisions.Events() {
+		if d.Kind == specview.DecisionPlacementFallback {
+			fallbacks = append(fallbacks, d.Subject)
+		}
+	}
+	if len(fallbacks) != 1 || fallbacks[0] != "TestDeleteUser" {
+		t.Errorf("expected placement fallback for the new test, got %v", fallbacks)
+	}
+}
+
+func TestGenerateSpecViewUseCase_ModelFallbackDecisions(t *testing.T) {
+	var savedDoc *specview.SpecDocument
+	repo := &mockRepository{
+		getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		},
+		saveDocumentFn: func(ctx context.Context, doc *specview.SpecDocument) error {
+			savedDoc = doc
+			doc.ID = "doc-001"
+			return nil
+		},
+	}
+	aiProvider := &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			return newPhase1Output(), &specview.TokenUsage{FallbackFrom: "gemini-2.5-pro", Model: "gemini-2.5-flash"}, nil
+		},
+		convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+			behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+			for i, test := range input.Tests {
+				behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+			}
+			return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{Model: "gemini-2.5-flash-lite"}, nil
+		},
+	}
+
+	if _, err := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-pro").Execute(context.Background(), newValidRequest()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var fallbacks []specview.DecisionEvent
+	for _, d := range savedDoc.Decisions {
+		if d.Kind == specview.DecisionModelFallback {
+			fallbacks = append(fallbacks, d)
+		}
+	}
+	if len(fallbacks) != 1 || fallbacks[0].Subject != "phase1" || fallbacks[0].Detail["to_model"] != "gemini-2.5-flash" {
+		t.Errorf("expected one Phase 1 fallback to gemini-2.5-flash, got %+v", fallbacks)
+	}
+}
+
+type mockDecisionWriterRepository struct {
+	mockRepository
+	mu       sync.Mutex
+	appended map[string][]specview.DecisionEvent
+}
+
+func (m *mockDecisionWriterRepository) AppendDecision(ctx context.Context, analysisID, generationID string, sequence int, event specview.DecisionEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.appended == nil {
+		m.appended = make(map[string][]specview.DecisionEvent)
+	}
+	m.appended[generationID] = append(m.appended[generationID], event)
+	return nil
+}
+
+func TestGenerateSpecViewUseCase_DecisionsWrittenAhead(t *testing.T) {
+	repo := &mockDecisionWriterRepository{}
+	repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+		return newTestFiles(), nil
+	}
+	repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+		t.Error("document saved although generation failed")
+		return nil
+	}
+	aiProvider := &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			return nil, nil, errors.New("model overloaded")
+		},
+	}
+
+	if _, err := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash").Execute(context.Background(), newValidRequest()); err == nil {
+		t.Fatal("expected Phase 1 failure")
+	}
+
+	if len(repo.appended) != 1 {
+		t.Fatalf("expected the decisions of one generation, got %d", len(repo.appended))
+	}
+	for _, events := range repo.appended {
+		if len(events) != 1 || events[0].Kind != specview.DecisionClassificationCache || events[0].Detail["outcome"] != "miss" {
+			t.Errorf("expected the classification cache miss to survive the failure, got %+v", events)
+		}
+	}
+}
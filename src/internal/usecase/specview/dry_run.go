@@ -0,0 +1,16 @@
+package specview
+
+import "github.com/specvital/worker/internal/domain/specview"
+
+// DryRunModelID labels dry-run documents and the cache entries they fill, so
+// neither is ever served to a real generation.
+const DryRunModelID = "dry-run"
+
+// forDryRun returns a copy of uc generating with provider under DryRunModelID.
+// Quota is read but not charged, so the result still reports the remaining
+// quota.
+func (uc *GenerateSpecViewUseCase) forDryRun(provider specview.AIProvider) *GenerateSpecViewUseCase {
+	dry := uc.withoutAI(provider, DryRunModelID)
+	dry.dryRun = dry
+	return dry
+}
@@ -0,0 +1,85 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestGenerateSpecViewUseCase_DryRun(t *testing.T) {
+	failing := &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			t.Error("the AI provider should not be called on a dry run")
+			return nil, nil, errors.New("unexpected call")
+		},
+	}
+	dryRun := &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			return newPhase1Output(), nil, nil
+		},
+		convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+			behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+			for i, test := range input.Tests {
+				behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+			}
+			return &specview.Phase2Output{Behaviors: behaviors}, nil, nil
+		},
+	}
+
+	t.Run("generates with the dry-run provider without charging quota", func(t *testing.T) {
+		var (
+			saved        *specview.SpecDocument
+			usageEvents  int
+			lookupModels []string
+		)
+		repo := &mockRepository{
+			findDocumentByContentHashFn: func(ctx context.Context, userID string, contentHash []byte, language specview.Language, modelID string) (*specview.SpecDocument, error) {
+				lookupModels = append(lookupModels, modelID)
+				return nil, nil
+			},
+			getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+				return newTestFiles(), nil
+			},
+			recordUsageEventFn: func(ctx context.Context, userID string, documentID string, quotaAmount int) error {
+				usageEvents++
+				return nil
+			},
+			saveDocumentFn: func(ctx context.Context, doc *specview.SpecDocument) error {
+				saved = doc
+				doc.ID = "doc-001"
+				return nil
+			},
+		}
+		req := newValidRequest()
+		req.DryRun = true
+		req.ModelID = "gemini-2.5-pro"
+
+		uc := NewGenerateSpecViewUseCase(repo, failing, "gemini-2.5-flash", WithDryRun(dryRun))
+		result, err := uc.Execute(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.DocumentID != "doc-001" || !saved.DryRun || saved.ModelID != DryRunModelID || saved.Generator != specview.GeneratorHeuristic {
+			t.Errorf("expected a dry-run document under %s, got %+v", DryRunModelID, saved)
+		}
+		if len(lookupModels) == 0 || lookupModels[0] != DryRunModelID {
+			t.Errorf("expected the document cache looked up under %s, got %v", DryRunModelID, lookupModels)
+		}
+		if usageEvents != 0 {
+			t.Errorf("expected no usage event, got %d", usageEvents)
+		}
+	})
+
+	t.Run("rejects dry runs when not enabled", func(t *testing.T) {
+		req := newValidRequest()
+		req.DryRun = true
+
+		_, err := NewGenerateSpecViewUseCase(&mockRepository{}, failing, "gemini-2.5-flash").Execute(context.Background(), req)
+		if !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
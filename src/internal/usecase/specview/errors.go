@@ -4,7 +4,14 @@ import "errors"
 
 var (
 	ErrAIProcessingFailed    = errors.New("AI processing failed")
+	ErrExportFailed          = errors.New("failed to export document")
+	ErrFeedbackFailed        = errors.New("failed to apply behavior feedback")
+	ErrGapAnalysisFailed     = errors.New("failed to analyze spec gaps")
+	ErrIndexFailed           = errors.New("failed to index document")
 	ErrLoadInventoryFailed   = errors.New("failed to load test inventory")
 	ErrPartialFeatureFailure = errors.New("partial feature conversion failure exceeds threshold")
+	ErrPhase2MaxDuration     = errors.New("phase 2 exceeded its maximum duration")
+	ErrPhase2Stalled         = errors.New("phase 2 made no progress within its timeout")
+	ErrReclassifyFailed      = errors.New("failed to reclassify uncategorized tests")
 	ErrSaveFailed            = errors.New("failed to save document")
 )
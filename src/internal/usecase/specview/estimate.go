@@ -0,0 +1,270 @@
+package specview
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	"github.com/specvital/worker/internal/domain/curation"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// EstimateSpecViewUseCase prices a generation request before it is enqueued,
+// so the Web app can ask the user to confirm the cost. It probes the same
+// caches generation uses but never calls the AI. Cache lookup failures count
+// as misses, which overstates rather than understates the cost.
+type EstimateSpecViewUseCase struct {
+	cacheKeyHash       specview.HashAlgorithm
+	cacheScope         specview.CacheScopeMode
+	curationReader     specview.CurationRulesReader
+	defaultModelID     string
+	glossaryReader     specview.GlossaryReader
+	legacyCacheKeyHash specview.HashAlgorithm
+	promptExperiment   specview.PromptExperiment
+	repository         specview.Repository
+	taxonomyReader     specview.TaxonomyReader
+	tenantLookup       specview.TenantLookup
+}
+
+// NewEstimateSpecViewUseCase creates a new EstimateSpecViewUseCase.
+// defaultModelID must match the generator's, since it is part of the cache
+// keys. Of the generator options, only WithCacheKeyHash, WithCacheScope and
+// WithPromptExperiment apply; they must match the generator's too.
+func NewEstimateSpecViewUseCase(repo specview.Repository, defaultModelID string, opts ...Option) *EstimateSpecViewUseCase {
+	cfg := Config{CacheKeyHash: specview.HashSHA256, CacheScope: specview.CacheScopeGlobal}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	uc := &EstimateSpecViewUseCase{
+		cacheKeyHash:       cfg.CacheKeyHash,
+		cacheScope:         cfg.CacheScope,
+		defaultModelID:     defaultModelID,
+		legacyCacheKeyHash: cfg.LegacyCacheKeyHash,
+		promptExperiment:   cfg.PromptExperiment,
+		repository:         repo,
+	}
+	if reader, ok := repo.(specview.CurationRulesReader); ok {
+		uc.curationReader = reader
+	}
+	if reader, ok := repo.(specview.GlossaryReader); ok {
+		uc.glossaryReader = reader
+	}
+	if reader, ok := repo.(specview.TaxonomyReader); ok {
+		uc.taxonomyReader = reader
+	}
+	if tenantLookup, ok := repo.(specview.TenantLookup); ok {
+		uc.tenantLookup = tenantLookup
+	}
+	return uc
+}
+
+// Execute estimates the cost of generating the requested document.
+func (uc *EstimateSpecViewUseCase) Execute(ctx context.Context, req specview.SpecViewRequest) (*specview.SpecViewEstimate, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	modelID := req.ModelID
+	if modelID == "" {
+		modelID = uc.defaultModelID
+	}
+	if err := req.PhaseModels().ValidateModelID(modelID); err != nil {
+		return nil, err
+	}
+	cacheModelIDs := req.PhaseModels().CacheKeys(modelID)
+
+	files, err := uc.repository.GetTestDataByAnalysisID(ctx, req.AnalysisID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLoadInventoryFailed, err)
+	}
+	rules := uc.loadCurationRules(ctx, req.AnalysisID)
+	files = specview.FilterProjectFiles(specview.FilterCuratedFiles(files, rules), req.Project)
+	if req.DefaultLanguage && rules.Language() != "" {
+		req.Language = specview.Language(rules.Language())
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no test files found for analysis", ErrLoadInventoryFailed)
+	}
+
+	estimate := &specview.SpecViewEstimate{TestCount: countTotalTestCases(files)}
+	glossary := uc.loadGlossary(ctx, req)
+	taxonomy := uc.loadTaxonomy(ctx, req.AnalysisID)
+	ctx = specview.WithPromptVersion(ctx, uc.promptExperiment.Version(req.AnalysisID, req.Language))
+	ctx = withCacheScope(ctx, uc.cacheScope, uc.tenantLookup, req.UserID)
+
+	if !req.ForceRegenerate {
+		contentHash := specview.PromptContentHash(
+			specview.TaxonomyHash(
+				specview.GlossaryContentHash(specview.CuratedContentHash(specview.GenerateContentHash(files, req.Language), rules), glossary),
+				taxonomy,
+			),
+			specview.PromptVersion(ctx),
+		)
+		doc, err := uc.repository.FindDocumentByContentHash(ctx, req.UserID, contentHash, req.Language, req.PhaseModels().DocumentModelID(modelID))
+		if err != nil {
+			uc.logLookupFailure(ctx, req.AnalysisID, "document", err)
+		}
+		if doc != nil {
+			estimate.CachedBehaviors = estimate.TestCount
+			estimate.DocumentCached = true
+			return estimate, nil
+		}
+	}
+
+	phase1Tests := estimate.TestCount
+	if !req.ForceRegenerate {
+		if newTests, ok := uc.probeClassificationCache(ctx, req, files, rules, taxonomy, cacheModelIDs.Phase1); ok {
+			estimate.ClassificationCached = true
+			estimate.NewTests = newTests
+			phase1Tests = newTests
+		}
+		estimate.CachedBehaviors = uc.countCachedBehaviors(ctx, req, files, rules, glossary, cacheModelIDs.Phase2)
+	}
+
+	estimate.EstimatedQuota = estimate.TestCount - estimate.CachedBehaviors
+	estimate.EstimatedTokens = int64(phase1Tests+estimate.EstimatedQuota) * specview.EstimatedTokensPerTest
+	return estimate, nil
+}
+
+// probeClassificationCache reports whether Phase 1 would start from the
+// classification cache, and how many new tests it would still place.
+func (uc *EstimateSpecViewUseCase) probeClassificationCache(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	files []specview.FileInfo,
+	rules *curation.Rules,
+	taxonomy *specview.Taxonomy,
+	modelID string,
+) (int, bool) {
+	signature := specview.PromptContentHash(
+		specview.TaxonomyHash(specview.TaxonomySignature(specview.GenerateFileSignature(files), specview.TaxonomyAnchors(rules)), taxonomy),
+		specview.PromptVersion(ctx),
+	)
+	fileSignature := specview.ScopedSignature(signature, specview.CacheScope(ctx))
+	cache, err := uc.repository.FindClassificationCache(ctx, fileSignature, req.Language, modelID)
+	if err != nil {
+		uc.logLookupFailure(ctx, req.AnalysisID, "classification", err)
+		return 0, false
+	}
+	if cache == nil {
+		return 0, false
+	}
+	return len(CalculateTestDiff(cache.TestIndexMap, files).NewTests), true
+}
+
+// countCachedBehaviors counts the tests whose behavior Phase 2 would take from
+// the behavior cache.
+func (uc *EstimateSpecViewUseCase) countCachedBehaviors(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	files []specview.FileInfo,
+	rules *curation.Rules,
+	glossary *specview.Glossary,
+	modelID string,
+) int {
+	style := string(rules.Style())
+	glossaryVersion := glossary.Version()
+	promptVersion := specview.PromptVersion(ctx)
+	scope := specview.CacheScope(ctx)
+	testKeys := make([]string, 0, countTotalTestCases(files))
+	var hashes [][]byte
+	inputs := make(map[string]behaviorKeyInput)
+	for _, file := range files {
+		for _, test := range file.Tests {
+			key := behaviorCacheKeyHex(uc.cacheKeyHash, file.Path, test, req.Language, modelID, style, glossaryVersion, promptVersion, scope)
+			testKeys = append(testKeys, key)
+			if _, seen := inputs[key]; seen {
+				continue
+			}
+			inputs[key] = behaviorKeyInput{filePath: file.Path, test: test}
+			if hash, err := hex.DecodeString(key); err == nil {
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+	if len(hashes) == 0 {
+		return 0
+	}
+
+	cached, err := uc.repository.FindCachedBehaviors(ctx, hashes)
+	if err != nil {
+		uc.logLookupFailure(ctx, req.AnalysisID, "behavior", err)
+		return 0
+	}
+	if uc.legacyCacheKeyHash != "" {
+		if cached == nil {
+			cached = make(map[string]string)
+		}
+		for key := range cached {
+			delete(inputs, key)
+		}
+		legacy, err := findLegacyBehaviors(ctx, uc.repository, uc.legacyCacheKeyHash, inputs, req.Language, modelID, style, glossaryVersion, promptVersion)
+		if err != nil {
+			uc.logLookupFailure(ctx, req.AnalysisID, "legacy behavior", err)
+		}
+		for key, description := range legacy {
+			cached[key] = description
+		}
+	}
+	count := 0
+	for _, key := range testKeys {
+		if _, ok := cached[key]; ok {
+			count++
+		}
+	}
+	return count
+}
+
+func (uc *EstimateSpecViewUseCase) loadCurationRules(ctx context.Context, analysisID string) *curation.Rules {
+	if uc.curationReader == nil {
+		return nil
+	}
+	rules, err := uc.curationReader.GetCurationRules(ctx, analysisID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load curation rules (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+		return nil
+	}
+	return rules
+}
+
+func (uc *EstimateSpecViewUseCase) loadGlossary(ctx context.Context, req specview.SpecViewRequest) *specview.Glossary {
+	if uc.glossaryReader == nil {
+		return nil
+	}
+	glossary, err := uc.glossaryReader.GetGlossary(ctx, req.UserID, req.AnalysisID, req.Language)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load glossary (non-critical)",
+			"analysis_id", req.AnalysisID,
+			"error", err,
+		)
+		return nil
+	}
+	return glossary
+}
+
+func (uc *EstimateSpecViewUseCase) loadTaxonomy(ctx context.Context, analysisID string) *specview.Taxonomy {
+	if uc.taxonomyReader == nil {
+		return nil
+	}
+	taxonomy, err := uc.taxonomyReader.GetTaxonomy(ctx, analysisID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load taxonomy (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+		return nil
+	}
+	return taxonomy
+}
+
+func (uc *EstimateSpecViewUseCase) logLookupFailure(ctx context.Context, analysisID, cache string, err error) {
+	slog.WarnContext(ctx, "estimate cache lookup failed, counting as miss (non-critical)",
+		"analysis_id", analysisID,
+		"cache", cache,
+		"error", err,
+	)
+}
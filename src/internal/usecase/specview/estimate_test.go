@@ -0,0 +1,125 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestEstimateSpecViewUseCase_Execute(t *testing.T) {
+	files := newTestFiles()
+	cachedKey := behaviorCacheKeyHex(specview.HashSHA256, files[0].Path, files[0].Tests[0], "Korean", "gemini-2.5-flash", "", "", "", "")
+
+	newRepo := func() *mockRepository {
+		return &mockRepository{
+			getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+				return newTestFiles(), nil
+			},
+		}
+	}
+
+	t.Run("cold caches", func(t *testing.T) {
+		estimate, err := NewEstimateSpecViewUseCase(newRepo(), "gemini-2.5-flash").Execute(context.Background(), newValidRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := specview.SpecViewEstimate{
+			EstimatedQuota:  4,
+			EstimatedTokens: 8 * specview.EstimatedTokensPerTest,
+			TestCount:       4,
+		}
+		if *estimate != want {
+			t.Errorf("estimate = %+v, want %+v", *estimate, want)
+		}
+	})
+
+	t.Run("warm caches", func(t *testing.T) {
+		repo := newRepo()
+		repo.findClassificationCacheFn = func(ctx context.Context, fileSignature []byte, language specview.Language, modelID string) (*specview.ClassificationCache, error) {
+			indexMap := make(map[string]specview.TestIdentity)
+			for _, f := range files {
+				for _, test := range f.Tests[:1] {
+					indexMap[specview.TestKey(f.Path, test.SuitePath, test.Name)] = specview.TestIdentity{FilePath: f.Path}
+				}
+			}
+			return &specview.ClassificationCache{ClassificationResult: newPhase1Output(), TestIndexMap: indexMap}, nil
+		}
+		repo.findCachedBehaviorsFn = func(ctx context.Context, cacheKeyHashes [][]byte) (map[string]string, error) {
+			return map[string]string{cachedKey: "Logs in"}, nil
+		}
+
+		estimate, err := NewEstimateSpecViewUseCase(repo, "gemini-2.5-flash").Execute(context.Background(), newValidRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := specview.SpecViewEstimate{
+			CachedBehaviors:      1,
+			ClassificationCached: true,
+			EstimatedQuota:       3,
+			EstimatedTokens:      (2 + 3) * specview.EstimatedTokensPerTest,
+			NewTests:             2,
+			TestCount:            4,
+		}
+		if *estimate != want {
+			t.Errorf("estimate = %+v, want %+v", *estimate, want)
+		}
+	})
+
+	t.Run("existing document costs nothing", func(t *testing.T) {
+		repo := newRepo()
+		repo.findDocumentByContentHashFn = func(ctx context.Context, userID string, contentHash []byte, language specview.Language, modelID string) (*specview.SpecDocument, error) {
+			return &specview.SpecDocument{ID: "doc-001"}, nil
+		}
+
+		estimate, err := NewEstimateSpecViewUseCase(repo, "gemini-2.5-flash").Execute(context.Background(), newValidRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !estimate.DocumentCached || estimate.EstimatedQuota != 0 || estimate.EstimatedTokens != 0 {
+			t.Errorf("unexpected estimate: %+v", *estimate)
+		}
+	})
+
+	t.Run("force regenerate ignores caches", func(t *testing.T) {
+		repo := newRepo()
+		repo.findCachedBehaviorsFn = func(ctx context.Context, cacheKeyHashes [][]byte) (map[string]string, error) {
+			t.Error("behavior cache should not be probed")
+			return nil, nil
+		}
+		req := newValidRequest()
+		req.ForceRegenerate = true
+
+		estimate, err := NewEstimateSpecViewUseCase(repo, "gemini-2.5-flash").Execute(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if estimate.EstimatedQuota != 4 {
+			t.Errorf("unexpected estimate: %+v", *estimate)
+		}
+	})
+
+	t.Run("lookup failures count as misses", func(t *testing.T) {
+		repo := newRepo()
+		repo.findCachedBehaviorsFn = func(ctx context.Context, cacheKeyHashes [][]byte) (map[string]string, error) {
+			return nil, errors.New("connection reset")
+		}
+
+		estimate, err := NewEstimateSpecViewUseCase(repo, "gemini-2.5-flash").Execute(context.Background(), newValidRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if estimate.EstimatedQuota != 4 {
+			t.Errorf("unexpected estimate: %+v", *estimate)
+		}
+	})
+
+	t.Run("no test files", func(t *testing.T) {
+		repo := &mockRepository{}
+		_, err := NewEstimateSpecViewUseCase(repo, "gemini-2.5-flash").Execute(context.Background(), newValidRequest())
+		if !errors.Is(err, ErrLoadInventoryFailed) {
+			t.Errorf("expected ErrLoadInventoryFailed, got %v", err)
+		}
+	})
+}
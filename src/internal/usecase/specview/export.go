@@ -0,0 +1,47 @@
+package specview
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// ExportSpecDocumentUseCase renders a saved spec document for consumers
+// outside SpecVital and stores the artifact.
+type ExportSpecDocumentUseCase struct {
+	repo specview.ExportRepository
+}
+
+// NewExportSpecDocumentUseCase creates a new ExportSpecDocumentUseCase.
+func NewExportSpecDocumentUseCase(repo specview.ExportRepository) *ExportSpecDocumentUseCase {
+	return &ExportSpecDocumentUseCase{repo: repo}
+}
+
+// Execute renders the document in the given format and stores the artifact,
+// replacing an earlier export of the same format.
+func (uc *ExportSpecDocumentUseCase) Execute(
+	ctx context.Context,
+	documentID string,
+	format specview.ExportFormat,
+) (*specview.ExportArtifact, error) {
+	if documentID == "" {
+		return nil, fmt.Errorf("%w: document ID is required", specview.ErrInvalidInput)
+	}
+
+	src, err := uc.repo.GetExportSource(ctx, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExportFailed, err)
+	}
+
+	artifact, err := specview.RenderDocument(*src, format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExportFailed, err)
+	}
+
+	if err := uc.repo.SaveExport(ctx, artifact); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExportFailed, err)
+	}
+
+	return artifact, nil
+}
@@ -0,0 +1,73 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockExportRepository struct {
+	err   error
+	saved *specview.ExportArtifact
+	src   *specview.ExportSource
+}
+
+func (m *mockExportRepository) GetExportSource(_ context.Context, _ string) (*specview.ExportSource, error) {
+	return m.src, m.err
+}
+
+func (m *mockExportRepository) SaveExport(_ context.Context, artifact *specview.ExportArtifact) error {
+	m.saved = artifact
+	return nil
+}
+
+func TestExportSpecDocumentUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("renders and stores the artifact", func(t *testing.T) {
+		repo := &mockExportRepository{src: &specview.ExportSource{
+			Document: &specview.SpecDocument{
+				Domains: []specview.Domain{{Name: "Billing", Features: []specview.Feature{{
+					Name:      "Settlement",
+					Behaviors: []specview.Behavior{{Description: "Settles daily"}},
+				}}}},
+				ID:      "doc-1",
+				Version: 2,
+			},
+			Repository: "acme/billing",
+		}}
+
+		artifact, err := NewExportSpecDocumentUseCase(repo).Execute(ctx, "doc-1", specview.ExportMarkdown)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.saved != artifact || artifact.FileName != "spec-v2.md" {
+			t.Errorf("expected the rendered artifact to be saved, got %+v", repo.saved)
+		}
+		if !strings.Contains(string(artifact.Data), "- Settles daily") {
+			t.Errorf("unexpected markdown:\n%s", artifact.Data)
+		}
+	})
+
+	t.Run("wraps missing documents", func(t *testing.T) {
+		repo := &mockExportRepository{err: specview.ErrDocumentNotFound}
+
+		_, err := NewExportSpecDocumentUseCase(repo).Execute(ctx, "doc-1", specview.ExportHTML)
+		if !errors.Is(err, ErrExportFailed) || !errors.Is(err, specview.ErrDocumentNotFound) {
+			t.Errorf("expected ErrExportFailed wrapping ErrDocumentNotFound, got %v", err)
+		}
+		if repo.saved != nil {
+			t.Error("nothing should be saved")
+		}
+	})
+
+	t.Run("rejects a missing document ID", func(t *testing.T) {
+		_, err := NewExportSpecDocumentUseCase(&mockExportRepository{}).Execute(ctx, "", specview.ExportHTML)
+		if !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package specview
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// fanOutPhase2 dispatches one child job per domain that needs conversion and
+// blocks until all of them are finalized. Returns nil when Phase 2 runs in-process:
+// fan-out is disabled, the request has no job ID, or too few domains need work.
+// A retried parent finds the children of its earlier attempt and waits for them
+// instead of dispatching again.
+func (uc *GenerateSpecViewUseCase) fanOutPhase2(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	budget *specview.Budget,
+	phase1Output *specview.Phase1Output,
+	modelID string,
+	style string,
+	glossary *specview.Glossary,
+	testIndexMap map[int]specview.TestInfo,
+	files []specview.FileInfo,
+) (*specview.Phase2ChildStatus, error) {
+	fanOut := uc.config.FanOut
+	if fanOut == nil || req.JobID == 0 || len(phase1Output.Domains) < uc.config.FanOutMinDomains {
+		return nil, nil
+	}
+
+	status, err := fanOut.ChildStatus(ctx, req.JobID)
+	if err != nil {
+		return nil, fmt.Errorf("child job status: %w", err)
+	}
+
+	if status.Total() > 0 {
+		slog.InfoContext(ctx, "phase 2 fan-out resumed",
+			"analysis_id", req.AnalysisID,
+			"job_id", req.JobID,
+			"child_count", status.Total(),
+		)
+	} else {
+		tasks := uc.buildDomainTasks(ctx, req, budget, phase1Output, modelID, style, glossary, testIndexMap, files)
+		if len(tasks) < uc.config.FanOutMinDomains {
+			return nil, nil
+		}
+		if err := fanOut.DispatchDomains(ctx, tasks); err != nil {
+			return nil, fmt.Errorf("dispatch domain jobs: %w", err)
+		}
+		slog.InfoContext(ctx, "phase 2 fanned out",
+			"analysis_id", req.AnalysisID,
+			"job_id", req.JobID,
+			"child_count", len(tasks),
+		)
+	}
+
+	status, err = uc.waitForChildren(ctx, req.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if status.Failed > 0 {
+		slog.WarnContext(ctx, "phase 2 child jobs failed, converting their domains in-process",
+			"analysis_id", req.AnalysisID,
+			"job_id", req.JobID,
+			"failed_count", status.Failed,
+		)
+	}
+	return &status, nil
+}
+
+// buildDomainTasks returns a task for every domain with tests missing from the behavior cache.
+func (uc *GenerateSpecViewUseCase) buildDomainTasks(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	budget *specview.Budget,
+	phase1Output *specview.Phase1Output,
+	modelID string,
+	style string,
+	glossary *specview.Glossary,
+	testIndexMap map[int]specview.TestInfo,
+	files []specview.FileInfo,
+) []specview.Phase2DomainTask {
+	testFilePathMap := buildTestFilePathMap(files)
+
+	var cachedBehaviors map[string]string
+	var testHashMap map[int]string
+	if !req.ForceRegenerate {
+		var err error
+		cachedBehaviors, testHashMap, err = uc.lookupBehaviorCache(
+			ctx, phase1Output, testIndexMap, testFilePathMap, req.Language, modelID, style, glossary,
+		)
+		if err != nil {
+			slog.WarnContext(ctx, "behavior cache lookup failed, dispatching all domains",
+				"analysis_id", req.AnalysisID,
+				"error", err,
+			)
+		}
+	}
+
+	modelOverride, _ := specview.ModelOverride(ctx)
+	var budgetTenantID string
+	if budget != nil {
+		budgetTenantID = budget.TenantID
+	}
+	var tasks []specview.Phase2DomainTask
+	for _, domain := range phase1Output.Domains {
+		uncached := 0
+		for _, feature := range domain.Features {
+			for _, idx := range feature.TestIndices {
+				if _, ok := testIndexMap[idx]; !ok {
+					continue
+				}
+				if _, cached := cachedBehaviors[testHashMap[idx]]; !cached {
+					uncached++
+				}
+			}
+		}
+		if uncached == 0 {
+			continue
+		}
+		tasks = append(tasks, specview.Phase2DomainTask{
+			AnalysisID:      req.AnalysisID,
+			BudgetTenantID:  budgetTenantID,
+			CacheScope:      specview.CacheScope(ctx),
+			Domain:          domain,
+			ForceRegenerate: req.ForceRegenerate,
+			Glossary:        glossary,
+			Language:        req.Language,
+			ModelID:         modelID,
+			ModelOverride:   modelOverride,
+			ParentJobID:     req.JobID,
+			Phase2Model:     specview.RequestedPhaseModels(ctx).Phase2,
+			PromptVersion:   specview.PromptVersion(ctx),
+			RequestedAt:     req.RequestedAt,
+			RequestedBy:     req.UserID,
+			Style:           style,
+			UncachedTests:   uncached,
+		})
+	}
+	return tasks
+}
+
+// waitForChildren polls the child jobs until none is active, bounded by the Phase 2 hard cap.
+// Status errors are retried on the next poll.
+func (uc *GenerateSpecViewUseCase) waitForChildren(ctx context.Context, parentJobID int64) (specview.Phase2ChildStatus, error) {
+	waitCtx, cancel := context.WithTimeoutCause(ctx, uc.config.Phase2MaxTimeout, ErrPhase2MaxDuration)
+	defer cancel()
+
+	ticker := time.NewTicker(uc.config.FanOutPollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := uc.config.FanOut.ChildStatus(waitCtx, parentJobID)
+		if err == nil && status.Active == 0 {
+			return status, nil
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "failed to check phase 2 child jobs, retrying",
+				"job_id", parentJobID,
+				"error", err,
+			)
+		}
+
+		select {
+		case <-waitCtx.Done():
+			return specview.Phase2ChildStatus{}, fmt.Errorf("wait for child jobs: %w", context.Cause(waitCtx))
+		case <-ticker.C:
+		}
+	}
+}
+
+// ConvertDomain runs Phase 2 for a single domain dispatched by a parent job.
+// The behaviors are saved to the behavior cache, where the parent picks them up;
+// features exceeding the failure threshold fail the child so its job retries.
+func (uc *GenerateSpecViewUseCase) ConvertDomain(ctx context.Context, task specview.Phase2DomainTask) (err error) {
+	ctx, span := tracer.Start(ctx, "specview.ConvertDomain", trace.WithAttributes(
+		attribute.String("specview.analysis_id", task.AnalysisID),
+		attribute.String("specview.domain", task.Domain.Name),
+	))
+	defer func() { endSpan(span, err) }()
+	if task.ModelOverride != "" {
+		ctx = specview.WithModelOverride(ctx, task.ModelOverride)
+	}
+	if task.Phase2Model != "" {
+		ctx = specview.WithPhaseModels(ctx, specview.PhaseModels{Phase2: task.Phase2Model})
+	}
+	if task.PromptVersion != "" {
+		ctx = specview.WithPromptVersion(ctx, task.PromptVersion)
+	}
+	if task.CacheScope != "" {
+		ctx = specview.WithCacheScope(ctx, task.CacheScope)
+	}
+
+	files, err := uc.loadTestData(ctx, task.AnalysisID)
+	if err != nil {
+		return err
+	}
+
+	// Progress snapshots describe the whole document and belong to the parent.
+	child := *uc
+	child.progressRepo = nil
+
+	phase1Output := &specview.Phase1Output{Domains: []specview.DomainGroup{task.Domain}}
+	_, stats, usage, err := child.executePhase2(
+		ctx,
+		task.AnalysisID,
+		phase1Output,
+		task.Language,
+		task.RequestedBy,
+		task.RequestedAt,
+		task.ModelID,
+		buildTestIndexMap(files),
+		files,
+		task.ForceRegenerate,
+		task.Style,
+		task.Glossary,
+		nil, // decisions belong to the parent's document
+		nil, // child jobs rely on the behavior cache, checkpoints belong to the parent
+	)
+	// Children charge the parent's budget under the parent's job, whose
+	// document claims the charges once saved.
+	if task.BudgetTenantID != "" {
+		uc.recordTokenUsage(context.WithoutCancel(ctx), &specview.Budget{TenantID: task.BudgetTenantID}, task.ParentJobID, usage)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: phase 2 domain %q: %w", ErrAIProcessingFailed, task.Domain.Name, err)
+	}
+	// Children carry no user; the parent attributes this usage when it
+	// records its own.
+	uc.recordAIUsage(ctx, specview.AIUsage{
+		AnalysisID: task.AnalysisID,
+		JobID:      task.ParentJobID,
+	}, map[string]*specview.TokenUsage{"phase2": usage})
+
+	slog.InfoContext(ctx, "phase 2 domain converted",
+		"analysis_id", task.AnalysisID,
+		"parent_job_id", task.ParentJobID,
+		"domain", task.Domain.Name,
+		"total_tests", stats.totalTests,
+		"cache_hits", stats.cacheHits,
+	)
+	return nil
+}
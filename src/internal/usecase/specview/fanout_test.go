@@ -0,0 +1,258 @@
+package specview
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// mockFanOut runs dispatched tasks synchronously through run, standing in for child workers.
+type mockFanOut struct {
+	mu         sync.Mutex
+	dispatched []specview.Phase2DomainTask
+	run        func(ctx context.Context, task specview.Phase2DomainTask) error
+	statuses   []specview.Phase2ChildStatus // returned in order once nothing was dispatched
+	polls      int
+}
+
+func (m *mockFanOut) ChildStatus(ctx context.Context, parentJobID int64) (specview.Phase2ChildStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.polls++
+	if len(m.statuses) > 0 {
+		status := m.statuses[0]
+		if len(m.statuses) > 1 {
+			m.statuses = m.statuses[1:]
+		}
+		return status, nil
+	}
+	var status specview.Phase2ChildStatus
+	for _, task := range m.dispatched {
+		status.Completed++
+		status.ConvertedTests += task.UncachedTests
+	}
+	return status, nil
+}
+
+func (m *mockFanOut) DispatchDomains(ctx context.Context, tasks []specview.Phase2DomainTask) error {
+	m.mu.Lock()
+	m.dispatched = append(m.dispatched, tasks...)
+	m.mu.Unlock()
+	for _, task := range tasks {
+		if m.run != nil {
+			if err := m.run(ctx, task); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newCachingRepository returns a repository whose behavior cache is kept in memory,
+// shared between the parent and its children like the real table.
+func newCachingRepository() (*mockRepository, *atomic.Int32) {
+	var (
+		mu    sync.Mutex
+		cache = make(map[string]string)
+		quota atomic.Int32
+	)
+	repo := &mockRepository{
+		findCachedBehaviorsFn: func(ctx context.Context, hashes [][]byte) (map[string]string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			found := make(map[string]string)
+			for _, h := range hashes {
+				if desc, ok := cache[hex.EncodeToString(h)]; ok {
+					found[hex.EncodeToString(h)] = desc
+				}
+			}
+			return found, nil
+		},
+		getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		},
+		recordUsageEventFn: func(ctx context.Context, userID string, documentID string, quotaAmount int) error {
+			quota.Store(int32(quotaAmount))
+			return nil
+		},
+		saveBehaviorCacheFn: func(ctx context.Context, entries []specview.BehaviorCacheEntry) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, e := range entries {
+				cache[hex.EncodeToString(e.CacheKeyHash)] = e.Description
+			}
+			return nil
+		},
+		saveDocumentFn: func(ctx context.Context, doc *specview.SpecDocument) error {
+			doc.ID = "doc-001"
+			return nil
+		},
+	}
+	return repo, &quota
+}
+
+func newFanOutAIProvider(convertCalls *atomic.Int32) *mockAIProvider {
+	return &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			return newPhase1Output(), &specview.TokenUsage{}, nil
+		},
+		convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+			convertCalls.Add(1)
+			behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+			for i, test := range input.Tests {
+				behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: "converted " + test.Name, Confidence: 0.9}
+			}
+			return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+		},
+	}
+}
+
+func TestGenerateSpecViewUseCase_FanOut(t *testing.T) {
+	t.Run("children convert domains and parent assembles from cache", func(t *testing.T) {
+		repo, quota := newCachingRepository()
+		var convertCalls atomic.Int32
+		fanOut := &mockFanOut{}
+		uc := NewGenerateSpecViewUseCase(repo, newFanOutAIProvider(&convertCalls), "model", WithPhase2FanOut(fanOut, 2))
+		fanOut.run = uc.ConvertDomain
+
+		req := newValidRequest()
+		req.JobID = 42
+		result, err := uc.Execute(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(fanOut.dispatched) != 2 {
+			t.Fatalf("expected one child per domain, got %d", len(fanOut.dispatched))
+		}
+		for _, task := range fanOut.dispatched {
+			if task.ParentJobID != 42 || task.ModelID != "model" || task.Language != req.Language {
+				t.Errorf("unexpected task: %+v", task)
+			}
+		}
+		if got := convertCalls.Load(); got != 3 {
+			t.Errorf("expected 3 conversions, all by children, got %d", got)
+		}
+		if got := quota.Load(); got != 4 {
+			t.Errorf("expected quota for 4 child-converted tests, got %d", got)
+		}
+		if result.BehaviorCacheStats.GeneratedBehaviors != 4 {
+			t.Errorf("expected child conversions counted as misses, got %+v", result.BehaviorCacheStats)
+		}
+	})
+
+	t.Run("runs in-process without a job ID", func(t *testing.T) {
+		repo, _ := newCachingRepository()
+		var convertCalls atomic.Int32
+		fanOut := &mockFanOut{}
+		uc := NewGenerateSpecViewUseCase(repo, newFanOutAIProvider(&convertCalls), "model", WithPhase2FanOut(fanOut, 2))
+
+		if _, err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if fanOut.polls != 0 || len(fanOut.dispatched) != 0 {
+			t.Errorf("expected fan-out to be skipped, got %d polls", fanOut.polls)
+		}
+		if convertCalls.Load() != 3 {
+			t.Errorf("expected in-process conversion, got %d calls", convertCalls.Load())
+		}
+	})
+
+	t.Run("runs in-process below the domain threshold", func(t *testing.T) {
+		repo, _ := newCachingRepository()
+		var convertCalls atomic.Int32
+		fanOut := &mockFanOut{}
+		uc := NewGenerateSpecViewUseCase(repo, newFanOutAIProvider(&convertCalls), "model", WithPhase2FanOut(fanOut, 3))
+
+		req := newValidRequest()
+		req.JobID = 42
+		if _, err := uc.Execute(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(fanOut.dispatched) != 0 {
+			t.Errorf("expected no children, got %d", len(fanOut.dispatched))
+		}
+	})
+
+	t.Run("retried parent waits for existing children", func(t *testing.T) {
+		repo, _ := newCachingRepository()
+		var convertCalls atomic.Int32
+		fanOut := &mockFanOut{statuses: []specview.Phase2ChildStatus{
+			{Active: 2},
+			{Active: 1, Completed: 1},
+			{Completed: 1, Failed: 1, ConvertedTests: 2},
+		}}
+		uc := NewGenerateSpecViewUseCase(repo, newFanOutAIProvider(&convertCalls), "model", WithPhase2FanOut(fanOut, 2))
+		uc.config.FanOutPollInterval = time.Millisecond
+
+		req := newValidRequest()
+		req.JobID = 42
+		if _, err := uc.Execute(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(fanOut.dispatched) != 0 {
+			t.Errorf("expected no new dispatch, got %d", len(fanOut.dispatched))
+		}
+		if fanOut.polls < 3 {
+			t.Errorf("expected polling until children finish, got %d polls", fanOut.polls)
+		}
+		// Nothing reached the cache, so the parent converts everything itself.
+		if convertCalls.Load() != 3 {
+			t.Errorf("expected in-process fallback for uncached tests, got %d calls", convertCalls.Load())
+		}
+	})
+
+	t.Run("times out waiting for children", func(t *testing.T) {
+		repo, _ := newCachingRepository()
+		var convertCalls atomic.Int32
+		fanOut := &mockFanOut{statuses: []specview.Phase2ChildStatus{{Active: 1}}}
+		uc := NewGenerateSpecViewUseCase(repo, newFanOutAIProvider(&convertCalls), "model",
+			WithPhase2FanOut(fanOut, 2), WithPhase2MaxTimeout(20*time.Millisecond))
+		uc.config.FanOutPollInterval = time.Millisecond
+
+		req := newValidRequest()
+		req.JobID = 42
+		_, err := uc.Execute(context.Background(), req)
+		if !errors.Is(err, ErrPhase2MaxDuration) {
+			t.Fatalf("expected ErrPhase2MaxDuration, got %v", err)
+		}
+	})
+}
+
+func TestGenerateSpecViewUseCase_ConvertDomain(t *testing.T) {
+	repo := &mockProgressRepository{}
+	var saved []specview.BehaviorCacheEntry
+	repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+		return newTestFiles(), nil
+	}
+	repo.saveBehaviorCacheFn = func(ctx context.Context, entries []specview.BehaviorCacheEntry) error {
+		saved = append(saved, entries...)
+		return nil
+	}
+	var convertCalls atomic.Int32
+	uc := NewGenerateSpecViewUseCase(repo, newFanOutAIProvider(&convertCalls), "model")
+
+	err := uc.ConvertDomain(context.Background(), specview.Phase2DomainTask{
+		AnalysisID:  "analysis-1",
+		Domain:      newPhase1Output().Domains[1],
+		Language:    "English",
+		ModelID:     "model",
+		ParentJobID: 42,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(saved) != 2 {
+		t.Errorf("expected 2 cache entries for the domain's tests, got %d", len(saved))
+	}
+	if len(repo.snapshots) != 0 {
+		t.Errorf("children must not overwrite the parent's progress, got %d snapshots", len(repo.snapshots))
+	}
+}
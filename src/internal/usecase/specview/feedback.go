@@ -0,0 +1,64 @@
+package specview
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// ApplyBehaviorFeedbackUseCase writes accepted user corrections to the
+// behavior cache as human-verified entries. AI conversions never overwrite a
+// verified entry and forced regenerations keep it, so later documents of the
+// same test reuse the correction. Applying feedback records no usage event
+// and is not counted against the user's quota.
+type ApplyBehaviorFeedbackUseCase struct {
+	repo specview.FeedbackRepository
+}
+
+// NewApplyBehaviorFeedbackUseCase creates a new ApplyBehaviorFeedbackUseCase.
+func NewApplyBehaviorFeedbackUseCase(repo specview.FeedbackRepository) *ApplyBehaviorFeedbackUseCase {
+	return &ApplyBehaviorFeedbackUseCase{repo: repo}
+}
+
+// Execute applies the feedback. Feedback applied before is a no-op; feedback
+// not accepted, or whose behavior was saved without a cache key, is invalid.
+func (uc *ApplyBehaviorFeedbackUseCase) Execute(ctx context.Context, feedbackID string) (result *specview.FeedbackResult, err error) {
+	if feedbackID == "" {
+		return nil, fmt.Errorf("%w: feedback ID is required", specview.ErrInvalidInput)
+	}
+
+	ctx, span := tracer.Start(ctx, "specview.feedback")
+	defer func() { endSpan(span, err) }()
+
+	feedback, err := uc.repo.GetFeedback(ctx, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFeedbackFailed, err)
+	}
+
+	result = &specview.FeedbackResult{DocumentID: feedback.DocumentID, FeedbackID: feedbackID}
+	switch {
+	case feedback.Status == specview.FeedbackApplied:
+		return result, nil
+	case feedback.Status != specview.FeedbackAccepted:
+		return nil, fmt.Errorf("%w: feedback %s is %s, not accepted", specview.ErrInvalidInput, feedbackID, feedback.Status)
+	case strings.TrimSpace(feedback.CorrectedDescription) == "":
+		return nil, fmt.Errorf("%w: feedback %s has an empty description", specview.ErrInvalidInput, feedbackID)
+	case len(feedback.CacheKeyHash) == 0:
+		return nil, fmt.Errorf("%w: behavior of feedback %s has no cache key", specview.ErrInvalidInput, feedbackID)
+	}
+
+	if err := uc.repo.ApplyFeedback(ctx, feedback); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFeedbackFailed, err)
+	}
+	result.Applied = true
+
+	slog.InfoContext(ctx, "behavior feedback applied",
+		"feedback_id", feedbackID,
+		"document_id", feedback.DocumentID,
+		"user_id", feedback.UserID,
+	)
+	return result, nil
+}
@@ -0,0 +1,194 @@
+package specview
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockFeedbackRepository struct {
+	applied  *specview.BehaviorFeedback
+	applyErr error
+	feedback *specview.BehaviorFeedback
+	getErr   error
+}
+
+func (m *mockFeedbackRepository) GetFeedback(ctx context.Context, feedbackID string) (*specview.BehaviorFeedback, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return m.feedback, nil
+}
+
+func (m *mockFeedbackRepository) ApplyFeedback(ctx context.Context, feedback *specview.BehaviorFeedback) error {
+	if m.applyErr != nil {
+		return m.applyErr
+	}
+	m.applied = feedback
+	return nil
+}
+
+func newAcceptedFeedback() *specview.BehaviorFeedback {
+	return &specview.BehaviorFeedback{
+		AnalysisID:           "550e8400-e29b-41d4-a716-446655440000",
+		CacheKeyHash:         []byte("key"),
+		CorrectedDescription: "Signs the user in",
+		DocumentID:           "doc-001",
+		ID:                   "fb-001",
+		Status:               specview.FeedbackAccepted,
+		UserID:               "test-user-001",
+	}
+}
+
+func TestApplyBehaviorFeedbackUseCase_Execute(t *testing.T) {
+	t.Run("applies accepted feedback", func(t *testing.T) {
+		repo := &mockFeedbackRepository{feedback: newAcceptedFeedback()}
+
+		result, err := NewApplyBehaviorFeedbackUseCase(repo).Execute(context.Background(), "fb-001")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.Applied || result.DocumentID != "doc-001" || repo.applied == nil {
+			t.Errorf("expected the feedback applied, got %+v", result)
+		}
+	})
+
+	t.Run("skips applied feedback", func(t *testing.T) {
+		feedback := newAcceptedFeedback()
+		feedback.Status = specview.FeedbackApplied
+		repo := &mockFeedbackRepository{feedback: feedback}
+
+		result, err := NewApplyBehaviorFeedbackUseCase(repo).Execute(context.Background(), "fb-001")
+		if err != nil || result.Applied || repo.applied != nil {
+			t.Errorf("expected a no-op, got %+v, %v", result, err)
+		}
+	})
+
+	invalid := map[string]func(*specview.BehaviorFeedback){
+		"pending":           func(f *specview.BehaviorFeedback) { f.Status = specview.FeedbackPending },
+		"rejected":          func(f *specview.BehaviorFeedback) { f.Status = specview.FeedbackRejected },
+		"empty description": func(f *specview.BehaviorFeedback) { f.CorrectedDescription = "  " },
+		"no cache key":      func(f *specview.BehaviorFeedback) { f.CacheKeyHash = nil },
+	}
+	for name, mutate := range invalid {
+		t.Run("rejects "+name, func(t *testing.T) {
+			feedback := newAcceptedFeedback()
+			mutate(feedback)
+			repo := &mockFeedbackRepository{feedback: feedback}
+
+			_, err := NewApplyBehaviorFeedbackUseCase(repo).Execute(context.Background(), "fb-001")
+			if !errors.Is(err, specview.ErrInvalidInput) || repo.applied != nil {
+				t.Errorf("expected ErrInvalidInput and nothing applied, got %v", err)
+			}
+		})
+	}
+
+	t.Run("keeps not found errors", func(t *testing.T) {
+		repo := &mockFeedbackRepository{getErr: specview.ErrFeedbackNotFound}
+
+		_, err := NewApplyBehaviorFeedbackUseCase(repo).Execute(context.Background(), "fb-001")
+		if !errors.Is(err, specview.ErrFeedbackNotFound) || !errors.Is(err, ErrFeedbackFailed) {
+			t.Errorf("expected ErrFeedbackNotFound, got %v", err)
+		}
+	})
+
+	t.Run("fails on apply errors", func(t *testing.T) {
+		repo := &mockFeedbackRepository{feedback: newAcceptedFeedback(), applyErr: errors.New("connection reset")}
+
+		_, err := NewApplyBehaviorFeedbackUseCase(repo).Execute(context.Background(), "fb-001")
+		if !errors.Is(err, ErrFeedbackFailed) {
+			t.Errorf("expected ErrFeedbackFailed, got %v", err)
+		}
+	})
+}
+
+type mockVerifiedRepository struct {
+	*mockRepository
+	findVerifiedBehaviorsFn func(ctx context.Context, cacheKeyHashes [][]byte) (map[string]string, error)
+}
+
+func (m *mockVerifiedRepository) FindVerifiedBehaviors(ctx context.Context, cacheKeyHashes [][]byte) (map[string]string, error) {
+	return m.findVerifiedBehaviorsFn(ctx, cacheKeyHashes)
+}
+
+func TestGenerateSpecViewUseCase_VerifiedBehaviors(t *testing.T) {
+	var (
+		saved       *specview.SpecDocument
+		quota       int
+		verifiedKey string
+		aiTests     int
+	)
+	repo := &mockVerifiedRepository{
+		mockRepository: &mockRepository{
+			getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+				return newTestFiles(), nil
+			},
+			findCachedBehaviorsFn: func(ctx context.Context, cacheKeyHashes [][]byte) (map[string]string, error) {
+				t.Error("expected the behavior cache to be bypassed")
+				return nil, nil
+			},
+			recordUsageEventFn: func(ctx context.Context, userID string, documentID string, quotaAmount int) error {
+				quota = quotaAmount
+				return nil
+			},
+			saveDocumentFn: func(ctx context.Context, doc *specview.SpecDocument) error {
+				saved = doc
+				doc.ID = "doc-001"
+				return nil
+			},
+		},
+		findVerifiedBehaviorsFn: func(ctx context.Context, cacheKeyHashes [][]byte) (map[string]string, error) {
+			verifiedKey = hex.EncodeToString(cacheKeyHashes[0])
+			return map[string]string{verifiedKey: "Verified by a user"}, nil
+		},
+	}
+	aiProvider := &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			return newPhase1Output(), nil, nil
+		},
+		convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+			aiTests += len(input.Tests)
+			behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+			for i, test := range input.Tests {
+				behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: "Regenerated: " + test.Name, Confidence: 0.9}
+			}
+			return &specview.Phase2Output{Behaviors: behaviors}, nil, nil
+		},
+	}
+
+	_, err := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash").Execute(context.Background(), specview.SpecViewRequest{
+		AnalysisID:      "550e8400-e29b-41d4-a716-446655440000",
+		ForceRegenerate: true,
+		Language:        "Korean",
+		UserID:          "test-user-001",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if aiTests != 3 || quota != 3 {
+		t.Errorf("expected 3 tests converted and billed, got %d converted and %d billed", aiTests, quota)
+	}
+	verified := 0
+	for _, d := range saved.Domains {
+		for _, f := range d.Features {
+			for _, b := range f.Behaviors {
+				if len(b.CacheKeyHash) == 0 {
+					t.Errorf("expected a cache key on %s", b.OriginalName)
+				}
+				if b.Description == "Verified by a user" {
+					verified++
+					if hex.EncodeToString(b.CacheKeyHash) != verifiedKey {
+						t.Errorf("expected the verified behavior under its cache key, got %x", b.CacheKeyHash)
+					}
+				}
+			}
+		}
+	}
+	if verified != 1 {
+		t.Errorf("expected 1 verified behavior kept, got %d", verified)
+	}
+}
@@ -0,0 +1,39 @@
+package specview
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// AnalyzeSpecGapsUseCase reports production code without behaviors in a spec document.
+type AnalyzeSpecGapsUseCase struct {
+	repo specview.GapRepository
+}
+
+// NewAnalyzeSpecGapsUseCase creates a new AnalyzeSpecGapsUseCase.
+func NewAnalyzeSpecGapsUseCase(repo specview.GapRepository) *AnalyzeSpecGapsUseCase {
+	return &AnalyzeSpecGapsUseCase{repo: repo}
+}
+
+// Execute computes and stores the gap report for the document.
+// Analyses recorded before source file inventories existed yield an empty report.
+func (uc *AnalyzeSpecGapsUseCase) Execute(ctx context.Context, documentID string) (*specview.GapReport, error) {
+	if documentID == "" {
+		return nil, fmt.Errorf("%w: document ID is required", specview.ErrInvalidInput)
+	}
+
+	inputs, err := uc.repo.GetGapInputs(ctx, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGapAnalysisFailed, err)
+	}
+
+	report := specview.BuildGapReport(documentID, *inputs)
+
+	if err := uc.repo.SaveGapReport(ctx, report); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGapAnalysisFailed, err)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,72 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockGapRepository struct {
+	getErr  error
+	inputs  *specview.GapInputs
+	saveErr error
+	saved   *specview.GapReport
+}
+
+func (m *mockGapRepository) GetGapInputs(_ context.Context, _ string) (*specview.GapInputs, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	if m.inputs == nil {
+		return &specview.GapInputs{}, nil
+	}
+	return m.inputs, nil
+}
+
+func (m *mockGapRepository) SaveGapReport(_ context.Context, report *specview.GapReport) error {
+	m.saved = report
+	return m.saveErr
+}
+
+func TestAnalyzeSpecGapsUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("saves computed report", func(t *testing.T) {
+		repo := &mockGapRepository{inputs: &specview.GapInputs{
+			MappedTestFiles: []string{"pkg/a_test.go"},
+			SourceFiles:     []string{"pkg/a.go", "other/b.go"},
+		}}
+
+		report, err := NewAnalyzeSpecGapsUseCase(repo).Execute(ctx, "doc-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.saved != report {
+			t.Error("expected computed report to be saved")
+		}
+		if report.CoveredSourceFiles != 1 || len(report.UncoveredPackages) != 1 {
+			t.Errorf("unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("rejects empty document ID", func(t *testing.T) {
+		_, err := NewAnalyzeSpecGapsUseCase(&mockGapRepository{}).Execute(ctx, "")
+		if !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("wraps repository errors", func(t *testing.T) {
+		for _, repo := range []*mockGapRepository{
+			{getErr: errors.New("db down")},
+			{saveErr: errors.New("upsert failed")},
+		} {
+			_, err := NewAnalyzeSpecGapsUseCase(repo).Execute(ctx, "doc-1")
+			if !errors.Is(err, ErrGapAnalysisFailed) {
+				t.Errorf("expected ErrGapAnalysisFailed, got %v", err)
+			}
+		}
+	})
+}
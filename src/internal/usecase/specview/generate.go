@@ -1,17 +1,25 @@
 package specview
 
 import (
+	"cmp"
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
 
+	"github.com/specvital/worker/internal/domain/curation"
+	"github.com/specvital/worker/internal/domain/sla"
 	"github.com/specvital/worker/internal/domain/specview"
 )
 
@@ -29,6 +37,14 @@ func (s *internalCacheStats) hitRate() float64 {
 	return float64(s.cacheHits) / float64(s.totalTests)
 }
 
+// attributeChildConversions counts behaviors converted by fan-out child jobs as
+// misses: the parent read them from the cache, but they were generated for this document.
+func (s *internalCacheStats) attributeChildConversions(n int) {
+	n = min(n, s.cacheHits)
+	s.cacheHits -= n
+	s.cacheMisses += n
+}
+
 func (s *internalCacheStats) toPublic() *specview.BehaviorCacheStats {
 	if s == nil || s.totalTests == 0 {
 		return nil
@@ -42,11 +58,15 @@ func (s *internalCacheStats) toPublic() *specview.BehaviorCacheStats {
 }
 
 const (
+	DefaultCancelPollInterval   = 5 * time.Second // how often Phase 2 checks for a user cancelling it
 	DefaultPhase1Timeout        = 60 * time.Minute
-	DefaultPhase2Timeout        = 25 * time.Minute
+	DefaultPhase2Timeout        = 25 * time.Minute // idle window: extended each time a feature completes
+	DefaultPhase2MaxTimeout     = 3 * time.Hour    // hard cap for Phase 2 regardless of progress
 	DefaultPhase2Concurrency    = int64(5)
 	DefaultFailureThreshold     = 0.5 // 50% feature failure threshold
 	DefaultPhase2FeatureTimeout = 90 * time.Second  // 1m30s for single feature conversion
+	DefaultFanOutMinDomains     = 4                 // domains needing conversion before Phase 2 fans out
+	DefaultFanOutPollInterval   = 15 * time.Second  // how often the parent checks its child jobs
 
 	// Progress logging thresholds for Phase 2
 	progressLogBatchSize     = 10               // Log every N completions
@@ -57,10 +77,34 @@ const (
 
 // Config holds configuration for GenerateSpecViewUseCase.
 type Config struct {
-	FailureThreshold  float64       // Threshold for partial failure (default: 0.5)
-	Phase1Timeout     time.Duration // Timeout for Phase 1 (default: 2 minutes)
-	Phase2Concurrency int64         // Max concurrent Phase 2 calls (default: 5)
-	Phase2Timeout     time.Duration // Timeout for Phase 2 (default: 7 minutes)
+	AllowedModels      []string                   // Models requests may select per phase; empty rejects per-phase selection
+	CacheKeyHash       specview.HashAlgorithm     // Behavior cache key hash (default: SHA-256)
+	CacheScope         specview.CacheScopeMode    // Who shares behavior cache entries (default: global)
+	CancelPollInterval time.Duration              // Cancellation polling interval during Phase 2 (default: 5 seconds)
+	DedupSimilarity    float64                    // Similarity at which behaviors of a feature are merged (default: 0.9)
+	DryRunProvider     specview.AIProvider        // nil rejects dry-run requests
+	Embedder           specview.Embedder          // nil disables the semantic behavior cache
+	ClaimPollInterval  time.Duration              // How often a job waiting for a generation claim retries it (default: 5 seconds)
+	ClaimTTL           time.Duration              // Lifetime of a generation claim, extended while the job runs (default: 2 minutes)
+	FailureThreshold   float64                    // Threshold for partial failure (default: 0.5)
+	FanOut             specview.Phase2FanOut      // nil runs Phase 2 in-process
+	FanOutMinDomains   int                        // Domains needing conversion before fanning out (default: 4)
+	FanOutPollInterval time.Duration              // Child job polling interval (default: 15 seconds)
+	GeneratorMode      specview.GeneratorMode     // When documents are generated heuristically (default: never)
+	HeuristicProvider  specview.AIProvider        // nil disables heuristic generation
+	InFlightWait       time.Duration              // How long a job waits for another generating the same content; zero disables
+	LegacyCacheKeyHash specview.HashAlgorithm     // Previous key hash still read while migrating; empty reads only CacheKeyHash keys
+	LicenseLookup      specview.RepoLicenseLookup // nil disables sharing documents across users
+	PromptExperiment   specview.PromptExperiment  // Candidate prompt routing; the zero value uses DefaultPromptVersion for every job
+	Quality            specview.QualityThresholds // Low-confidence behaviors and flagged documents (default: 0.5 and 0.7)
+	QualityRefine      bool                       // Retry the low-confidence features of flagged documents once
+	Phase1Timeout      time.Duration              // Timeout for Phase 1 (default: 2 minutes)
+	Phase2Concurrency  int64                      // Max concurrent Phase 2 calls (default: 5)
+	Phase2MaxTimeout   time.Duration              // Hard cap for Phase 2 (default: 3 hours)
+	Phase2Timeout      time.Duration              // Phase 2 fails when no feature completes within this window (default: 25 minutes)
+	QuotaThresholds    []int                      // Monthly quota shares, in percent, that trigger a usage warning (default: 80)
+	SemanticSimilarity float64                    // Similarity at which a near-identical test's cached behavior is reused (default: 0.95)
+	UsageNotifier      specview.UsageNotifier     // nil disables usage warnings
 }
 
 // Option is a functional option for configuring GenerateSpecViewUseCase.
@@ -84,6 +128,15 @@ func WithPhase2Timeout(d time.Duration) Option {
 	}
 }
 
+// WithPhase2MaxTimeout sets the hard cap for Phase 2.
+func WithPhase2MaxTimeout(d time.Duration) Option {
+	return func(cfg *Config) {
+		if d > 0 {
+			cfg.Phase2MaxTimeout = d
+		}
+	}
+}
+
 // WithPhase2Concurrency sets the max concurrent Phase 2 calls.
 func WithPhase2Concurrency(n int64) Option {
 	return func(cfg *Config) {
@@ -93,6 +146,169 @@ func WithPhase2Concurrency(n int64) Option {
 	}
 }
 
+// WithPhase2FanOut distributes Phase 2 across per-domain child jobs when at
+// least minDomains domains need conversion. A minDomains <= 0 keeps the default.
+func WithPhase2FanOut(fanOut specview.Phase2FanOut, minDomains int) Option {
+	return func(cfg *Config) {
+		cfg.FanOut = fanOut
+		if minDomains > 0 {
+			cfg.FanOutMinDomains = minDomains
+		}
+	}
+}
+
+// WithDocumentSharing serves a document another user generated for the same
+// codebase and content instead of generating it again, when lookup reports the
+// repository public with a license allowing it. Requires a repository
+// implementing specview.SharingRepository.
+func WithDocumentSharing(lookup specview.RepoLicenseLookup) Option {
+	return func(cfg *Config) {
+		if lookup != nil {
+			cfg.LicenseLookup = lookup
+		}
+	}
+}
+
+// WithQuotaWarnings notifies users whose spend crosses one of thresholds, in
+// percent of their monthly quota. Thresholds outside 1-100 are ignored, and
+// DefaultQuotaWarningThreshold is kept when none is valid. Requires a
+// repository implementing specview.QuotaReader.
+func WithQuotaWarnings(notifier specview.UsageNotifier, thresholds []int) Option {
+	return func(cfg *Config) {
+		if notifier == nil {
+			return
+		}
+		cfg.UsageNotifier = notifier
+		var valid []int
+		for _, t := range thresholds {
+			if t > 0 && t <= 100 {
+				valid = append(valid, t)
+			}
+		}
+		if len(valid) > 0 {
+			cfg.QuotaThresholds = valid
+		}
+	}
+}
+
+// WithAllowedModels sets the models a request may select for its phases.
+// Without it, requests selecting a phase model are rejected.
+func WithAllowedModels(models []string) Option {
+	return func(cfg *Config) {
+		cfg.AllowedModels = slices.DeleteFunc(slices.Clone(models), func(m string) bool { return m == "" })
+	}
+}
+
+// WithBehaviorDedup sets the similarity, in (0, 1], at or above which
+// behaviors of the same feature are merged after Phase 2. 1 merges only
+// behaviors that are equal apart from case, punctuation and spacing.
+func WithBehaviorDedup(similarity float64) Option {
+	return func(cfg *Config) {
+		if similarity > 0 && similarity <= 1 {
+			cfg.DedupSimilarity = similarity
+		}
+	}
+}
+
+// WithCacheKeyHash hashes behavior cache keys with algorithm. During a
+// migration, legacy names the previous algorithm: tests missing the cache
+// under the new keys are looked up under the legacy ones, and hits are copied
+// to the new keys. An empty legacy disables the fallback. Unknown algorithms,
+// and a legacy equal to algorithm, are ignored.
+func WithCacheKeyHash(algorithm, legacy specview.HashAlgorithm) Option {
+	return func(cfg *Config) {
+		current, err := specview.ParseHashAlgorithm(string(algorithm))
+		if err != nil {
+			return
+		}
+		cfg.CacheKeyHash = current
+		if previous, err := specview.ParseHashAlgorithm(string(legacy)); err == nil && legacy != "" && previous != current {
+			cfg.LegacyCacheKeyHash = previous
+		}
+	}
+}
+
+// WithCacheScope sets who shares behavior cache entries. Scoped keys differ
+// from global ones, so entries cached before scoping are not reused, and the
+// organization mode needs a repository implementing specview.TenantLookup to
+// tell tenants apart; without one every user keeps their own entries. Unknown
+// modes are ignored.
+func WithCacheScope(mode specview.CacheScopeMode) Option {
+	return func(cfg *Config) {
+		if parsed, err := specview.ParseCacheScopeMode(string(mode)); err == nil {
+			cfg.CacheScope = parsed
+		}
+	}
+}
+
+// WithSemanticCache embeds the names of tests missing the behavior cache and
+// reuses the cached behavior of the nearest test at least similarity alike,
+// e.g. a renamed or reworded test. A similarity outside (0, 1] keeps
+// specview.DefaultSemanticCacheSimilarity. Requires a repository implementing
+// specview.SemanticBehaviorCache.
+func WithSemanticCache(embedder specview.Embedder, similarity float64) Option {
+	return func(cfg *Config) {
+		if embedder == nil {
+			return
+		}
+		cfg.Embedder = embedder
+		if similarity > 0 && similarity <= 1 {
+			cfg.SemanticSimilarity = similarity
+		}
+	}
+}
+
+// WithInFlightDedup makes a job wait, up to wait, while another job generates
+// the same content in the same language, model and cache scope, then reuse
+// its document or the behavior and classification caches it filled instead of
+// making the same AI calls. Requires a repository implementing
+// specview.GenerationClaims and jobs with a queue job ID. A non-positive wait
+// is ignored.
+func WithInFlightDedup(wait time.Duration) Option {
+	return func(cfg *Config) {
+		if wait > 0 {
+			cfg.InFlightWait = wait
+		}
+	}
+}
+
+// WithPromptExperiment routes percent of jobs to the candidate prompt
+// version. Documents record the version they were generated with, so the
+// candidate's output can be compared with the default's offline. An empty
+// candidate, or a percent outside 1-100, is ignored.
+func WithPromptExperiment(candidate string, percent int) Option {
+	return func(cfg *Config) {
+		if candidate != "" && percent > 0 && percent <= 100 {
+			cfg.PromptExperiment = specview.PromptExperiment{Candidate: candidate, Percent: percent}
+		}
+	}
+}
+
+// WithDryRun serves dry-run requests with provider, typically one deriving
+// the document from test names without AI calls. Dry runs use DryRunModelID,
+// so they never share caches or documents with real generations.
+func WithDryRun(provider specview.AIProvider) Option {
+	return func(cfg *Config) {
+		if provider != nil {
+			cfg.DryRunProvider = provider
+		}
+	}
+}
+
+// WithHeuristic generates documents with provider, typically one deriving
+// them from test names without AI calls, as mode decides: always, or only
+// while the AI provider is unavailable. Heuristic documents use
+// HeuristicModelID, so the next generation after an outage is not served the
+// heuristic document and the AI runs again. Unknown modes are ignored.
+func WithHeuristic(provider specview.AIProvider, mode specview.GeneratorMode) Option {
+	return func(cfg *Config) {
+		if parsed, err := specview.ParseGeneratorMode(string(mode)); err == nil && provider != nil {
+			cfg.GeneratorMode = parsed
+			cfg.HeuristicProvider = provider
+		}
+	}
+}
+
 // WithFailureThreshold sets the failure threshold for partial failures.
 func WithFailureThreshold(t float64) Option {
 	return func(cfg *Config) {
@@ -104,10 +320,31 @@ func WithFailureThreshold(t float64) Option {
 
 // GenerateSpecViewUseCase orchestrates spec-view document generation.
 type GenerateSpecViewUseCase struct {
-	aiProvider     specview.AIProvider
-	config         Config
-	defaultModelID string
-	repository     specview.Repository
+	aiProvider      specview.AIProvider
+	aiUsageRecorder specview.AIUsageRecorder
+	budgetRepo      specview.BudgetRepository
+	cancelReader    specview.CancellationReader
+	checkpointRepo  specview.CheckpointRepository
+	claimRepo       specview.GenerationClaims
+	config          Config
+	curationReader  specview.CurationRulesReader
+	decisionWriter  specview.DecisionWriter
+	defaultModelID  string
+	documentReader  specview.DocumentReader
+	dryRun          *GenerateSpecViewUseCase // serves dry-run requests; nil when not configured
+	generator       specview.Generator
+	glossaryReader  specview.GlossaryReader
+	heuristic       *GenerateSpecViewUseCase // generates without AI; nil when not configured
+	outlineReader   specview.OutlineReader
+	progressRepo    specview.GenerationProgressRepository
+	quotaReader     specview.QuotaReader
+	repository      specview.Repository
+	semanticCache   specview.SemanticBehaviorCache
+	taxonomyReader  specview.TaxonomyReader
+	sharingRepo     specview.SharingRepository
+	tenantLookup    specview.TenantLookup
+	translator      specview.Translator
+	verifiedReader  specview.VerifiedBehaviorReader
 }
 
 // NewGenerateSpecViewUseCase creates a new GenerateSpecViewUseCase.
@@ -118,44 +355,175 @@ func NewGenerateSpecViewUseCase(
 	opts ...Option,
 ) *GenerateSpecViewUseCase {
 	cfg := Config{
-		FailureThreshold:  DefaultFailureThreshold,
-		Phase1Timeout:     DefaultPhase1Timeout,
-		Phase2Concurrency: DefaultPhase2Concurrency,
-		Phase2Timeout:     DefaultPhase2Timeout,
+		CacheKeyHash:       specview.HashSHA256,
+		CacheScope:         specview.CacheScopeGlobal,
+		CancelPollInterval: DefaultCancelPollInterval,
+		ClaimPollInterval:  DefaultClaimPollInterval,
+		ClaimTTL:           DefaultClaimTTL,
+		DedupSimilarity:    specview.DefaultDedupSimilarity,
+		FailureThreshold:   DefaultFailureThreshold,
+		FanOutMinDomains:   DefaultFanOutMinDomains,
+		FanOutPollInterval: DefaultFanOutPollInterval,
+		GeneratorMode:      specview.GeneratorModeAI,
+		Phase1Timeout:      DefaultPhase1Timeout,
+		Phase2Concurrency:  DefaultPhase2Concurrency,
+		Phase2MaxTimeout:   DefaultPhase2MaxTimeout,
+		Phase2Timeout:      DefaultPhase2Timeout,
+		Quality:            specview.DefaultQualityThresholds(),
+		QuotaThresholds:    []int{specview.DefaultQuotaWarningThreshold},
+		SemanticSimilarity: specview.DefaultSemanticCacheSimilarity,
 	}
 
 	for _, opt := range opts {
 		opt(&cfg)
 	}
 
-	return &GenerateSpecViewUseCase{
+	uc := &GenerateSpecViewUseCase{
 		aiProvider:     aiProvider,
 		config:         cfg,
 		defaultModelID: defaultModelID,
+		generator:      specview.GeneratorAI,
 		repository:     repo,
 	}
+	if recorder, ok := repo.(specview.AIUsageRecorder); ok {
+		uc.aiUsageRecorder = recorder
+	}
+	if budgetRepo, ok := repo.(specview.BudgetRepository); ok {
+		uc.budgetRepo = budgetRepo
+	}
+	if cancelReader, ok := repo.(specview.CancellationReader); ok {
+		uc.cancelReader = cancelReader
+	}
+	if checkpointRepo, ok := repo.(specview.CheckpointRepository); ok {
+		uc.checkpointRepo = checkpointRepo
+	}
+	if reader, ok := repo.(specview.CurationRulesReader); ok {
+		uc.curationReader = reader
+	}
+	if writer, ok := repo.(specview.DecisionWriter); ok {
+		uc.decisionWriter = writer
+	}
+	if claims, ok := repo.(specview.GenerationClaims); ok && cfg.InFlightWait > 0 {
+		uc.claimRepo = claims
+	}
+	if reader, ok := repo.(specview.DocumentReader); ok {
+		uc.documentReader = reader
+	}
+	if reader, ok := repo.(specview.GlossaryReader); ok {
+		uc.glossaryReader = reader
+	}
+	if progressRepo, ok := repo.(specview.GenerationProgressRepository); ok {
+		uc.progressRepo = progressRepo
+	}
+	if reader, ok := repo.(specview.TaxonomyReader); ok {
+		uc.taxonomyReader = reader
+	}
+	if outlineReader, ok := repo.(specview.OutlineReader); ok {
+		uc.outlineReader = outlineReader
+	}
+	if quotaReader, ok := repo.(specview.QuotaReader); ok {
+		uc.quotaReader = quotaReader
+	}
+	if semanticCache, ok := repo.(specview.SemanticBehaviorCache); ok && cfg.Embedder != nil {
+		uc.semanticCache = semanticCache
+	}
+	if sharingRepo, ok := repo.(specview.SharingRepository); ok && cfg.LicenseLookup != nil {
+		uc.sharingRepo = sharingRepo
+	}
+	if tenantLookup, ok := repo.(specview.TenantLookup); ok {
+		uc.tenantLookup = tenantLookup
+	}
+	if translator, ok := aiProvider.(specview.Translator); ok {
+		uc.translator = translator
+	}
+	if verifiedReader, ok := repo.(specview.VerifiedBehaviorReader); ok {
+		uc.verifiedReader = verifiedReader
+	}
+	if cfg.DryRunProvider != nil {
+		uc.dryRun = uc.forDryRun(cfg.DryRunProvider)
+	}
+	if cfg.HeuristicProvider != nil && cfg.GeneratorMode != specview.GeneratorModeAI {
+		uc.heuristic = uc.withoutAI(cfg.HeuristicProvider, HeuristicModelID)
+	}
+	return uc
 }
 
 // Execute generates a spec-view document for the given request.
 func (uc *GenerateSpecViewUseCase) Execute(
 	ctx context.Context,
 	req specview.SpecViewRequest,
+) (*specview.SpecViewResult, error) {
+	if req.DryRun {
+		if uc.dryRun == nil {
+			return nil, fmt.Errorf("%w: dry runs are not enabled", specview.ErrInvalidInput)
+		}
+		uc = uc.dryRun
+		req = withoutModels(req)
+	} else if uc.config.GeneratorMode == specview.GeneratorModeHeuristic && uc.heuristic != nil {
+		uc = uc.heuristic
+		req = withoutModels(req)
+	}
+
+	ctx, span := tracer.Start(ctx, "specview.Execute", trace.WithAttributes(
+		attribute.String("specview.analysis_id", req.AnalysisID),
+		attribute.Bool("specview.dry_run", req.DryRun),
+		attribute.Bool("specview.force_regenerate", req.ForceRegenerate),
+		attribute.String("specview.language", string(req.Language)),
+	))
+	result, err := uc.execute(ctx, req, nil)
+	if err != nil && uc.fallsBackToHeuristic(ctx, err) {
+		slog.WarnContext(ctx, "AI unavailable, generating heuristically",
+			"analysis_id", req.AnalysisID,
+			"error", err,
+		)
+		req = withoutModels(req)
+		result, err = uc.heuristic.execute(ctx, req, err)
+	}
+	if result != nil {
+		span.SetAttributes(
+			attribute.Bool("specview.cache_hit", result.CacheHit),
+			attribute.String("specview.document_id", result.DocumentID),
+			attribute.String("specview.generator", string(result.Generator)),
+		)
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+// execute generates the document of req. aiErr is the error of the AI
+// generation a heuristic one falls back from, recorded in the decision log.
+func (uc *GenerateSpecViewUseCase) execute(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	aiErr error,
 ) (*specview.SpecViewResult, error) {
 	startTime := time.Now()
 
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
+	phaseModels := req.PhaseModels()
+	if err := phaseModels.Validate(uc.config.AllowedModels); err != nil {
+		return nil, err
+	}
+	modelID := req.ModelID
+	if modelID == "" {
+		modelID = uc.defaultModelID
+	}
+	if err := phaseModels.ValidateModelID(modelID); err != nil {
+		return nil, err
+	}
 
 	analysisCtx, err := uc.repository.GetAnalysisContext(ctx, req.AnalysisID)
 	if err != nil {
 		return nil, err
 	}
+	ctx = specview.WithResponseCacheScope(ctx, specview.ResponseCacheScopeFor(analysisCtx, req.UserID))
 
-	modelID := req.ModelID
-	if modelID == "" {
-		modelID = uc.defaultModelID
+	if !phaseModels.IsZero() {
+		ctx = specview.WithPhaseModels(ctx, phaseModels)
 	}
+	documentModelID := phaseModels.DocumentModelID(modelID)
 
 	files, err := uc.loadTestData(ctx, req.AnalysisID)
 	if err != nil {
@@ -163,6 +531,17 @@ func (uc *GenerateSpecViewUseCase) Execute(
 		return nil, err
 	}
 
+	decisions := uc.newDecisionLog(ctx, req.AnalysisID)
+	if aiErr != nil {
+		decisions.Record(specview.DecisionHeuristicFallback, "document", map[string]any{"error": aiErr.Error()})
+	}
+	rules := uc.loadCurationRules(ctx, req.AnalysisID)
+	recordCurationDecisions(decisions, files, rules)
+	files = specview.FilterProjectFiles(specview.FilterCuratedFiles(files, rules), req.Project)
+	if req.DefaultLanguage && rules.Language() != "" {
+		req.Language = specview.Language(rules.Language())
+	}
+
 	if len(files) == 0 {
 		slog.WarnContext(ctx, "no test files found",
 			"analysis_id", req.AnalysisID,
@@ -172,64 +551,155 @@ func (uc *GenerateSpecViewUseCase) Execute(
 		return nil, fmt.Errorf("%w: no test files found for analysis", ErrLoadInventoryFailed)
 	}
 
-	contentHash := specview.GenerateContentHash(files, req.Language)
+	glossary := uc.loadGlossary(ctx, req)
+	taxonomy := uc.loadTaxonomy(ctx, req.AnalysisID)
+	promptVersion := uc.config.PromptExperiment.Version(req.AnalysisID, req.Language)
+	ctx = specview.WithPromptVersion(ctx, promptVersion)
+	ctx = withCacheScope(ctx, uc.config.CacheScope, uc.tenantLookup, req.UserID)
+	contentHash := documentContentHash(files, req.Language, rules, glossary, taxonomy, promptVersion)
 
 	if !req.ForceRegenerate {
-		existingDoc, err := uc.repository.FindDocumentByContentHash(ctx, req.UserID, contentHash, req.Language, modelID)
+		result, err := uc.findExistingResult(ctx, req, analysisCtx, contentHash, documentModelID)
 		if err != nil {
 			uc.logExecutionError(ctx, req.AnalysisID, "cache_check", startTime, err)
 			return nil, fmt.Errorf("check cache: %w", err)
 		}
+		if result != nil {
+			uc.addCachedTranslations(ctx, req, result, documentModelID, files, rules, taxonomy)
+			return result, nil
+		}
 
-		if existingDoc != nil {
-			slog.InfoContext(ctx, "cache hit",
-				"analysis_id", req.AnalysisID,
-				"user_id", req.UserID,
-				"owner", analysisCtx.Owner,
-				"repo", analysisCtx.Repo,
-				"document_id", existingDoc.ID,
-			)
+		// Another job generating the same content fills the caches this one
+		// would miss, so wait for it instead of paying for the same AI calls.
+		claim, waited, err := uc.awaitGeneration(ctx, req, contentHash, documentModelID)
+		if err != nil {
+			return nil, err
+		}
+		defer claim.release(ctx)
+		if waited {
+			result, err := uc.findExistingResult(ctx, req, analysisCtx, contentHash, documentModelID)
+			if err != nil {
+				uc.logExecutionError(ctx, req.AnalysisID, "cache_check", startTime, err)
+				return nil, fmt.Errorf("check cache: %w", err)
+			}
+			if result != nil {
+				uc.addCachedTranslations(ctx, req, result, documentModelID, files, rules, taxonomy)
+				return result, nil
+			}
+		}
+	}
+
+	budget, err := uc.loadBudget(ctx, req.UserID)
+	if err != nil {
+		uc.logExecutionError(ctx, req.AnalysisID, "budget", startTime, err)
+		return nil, err
+	}
+	testCount := countTotalTestCases(files)
 
-			uc.recordUserHistory(ctx, req.UserID, existingDoc.ID)
+	// A retried job resumes from the checkpoint of its earlier attempt.
+	checkpoint := uc.loadCheckpoint(ctx, req, contentHash)
 
-			return &specview.SpecViewResult{
-				AnalysisContext: analysisCtx,
-				CacheHit:        true,
-				ContentHash:     contentHash,
-				DocumentID:      existingDoc.ID,
-			}, nil
+	var phase1Output *specview.Phase1Output
+	var phase1Usage *specview.TokenUsage
+	if checkpoint != nil {
+		slog.InfoContext(ctx, "resuming generation from checkpoint",
+			"analysis_id", req.AnalysisID,
+			"job_id", req.JobID,
+			"completed_features", len(checkpoint.Features),
+		)
+		recordClassificationCache(decisions, "checkpoint", nil)
+		phase1Output = checkpoint.Phase1Output
+	} else {
+		ctx, modelID, phaseModels, err = uc.enforceBudget(ctx, req, budget, "phase1", testCount, modelID, phaseModels, decisions)
+		if err != nil {
+			return nil, err
 		}
+		phase1Ctx, phase1Span := tracer.Start(ctx, "specview.phase1", trace.WithAttributes(
+			attribute.Int("specview.file_count", len(files)),
+		))
+		stopClassify := sla.StartPhase(ctx, sla.PhaseClassify)
+		phase1Output, phase1Usage, err = uc.executePhase1WithCache(
+			phase1Ctx,
+			files,
+			req.Language,
+			phaseModels.CacheKeys(modelID).Phase1,
+			req.AnalysisID,
+			req.ForceRegenerate,
+			specview.TaxonomyAnchors(rules),
+			taxonomy,
+			decisions,
+		)
+		stopClassify()
+		endSpan(phase1Span, err)
+		if err != nil {
+			uc.logExecutionError(ctx, req.AnalysisID, "phase1", startTime, err)
+			return nil, fmt.Errorf("%w: phase 1: %w", ErrAIProcessingFailed, err)
+		}
+		uc.recordTokenUsage(ctx, budget, req.JobID, phase1Usage)
+		phase1Output = constrainToTaxonomy(ctx, req.AnalysisID, phase1Output, taxonomy, decisions)
 	}
 
-	phase1Output, phase1Usage, err := uc.executePhase1WithCache(
-		ctx,
-		files,
-		req.Language,
-		modelID,
-		req.AnalysisID,
-		req.ForceRegenerate,
-	)
-	if err != nil {
-		uc.logExecutionError(ctx, req.AnalysisID, "phase1", startTime, err)
-		return nil, fmt.Errorf("%w: phase 1: %w", ErrAIProcessingFailed, err)
+	checkpoints := uc.newCheckpointWriter(req, contentHash, phase1Output, checkpoint)
+	if checkpoint == nil {
+		checkpoints.save(ctx)
 	}
+	phase1Output = specview.ApplyCurationRules(phase1Output, files, rules)
 
 	testIndexMap := buildTestIndexMap(files)
+	style := string(rules.Style())
 
+	if phase1Usage != nil {
+		budget.Spend(int64(phase1Usage.TotalTokens))
+	}
+	ctx, modelID, phaseModels, err = uc.enforceBudget(ctx, req, budget, "phase2", testCount, modelID, phaseModels, decisions)
+	if err != nil {
+		return nil, err
+	}
+	// A downgrade relabels the document and caches with the downgrade model.
+	documentModelID = phaseModels.DocumentModelID(modelID)
+	phase2ModelID := phaseModels.CacheKeys(modelID).Phase2
+
+	stopConvert := sla.StartPhase(ctx, sla.PhaseConvert)
+	childStatus, err := uc.fanOutPhase2(ctx, req, budget, phase1Output, phase2ModelID, style, glossary, testIndexMap, files)
+	stopConvert()
+	if err != nil {
+		uc.logExecutionError(ctx, req.AnalysisID, "phase2_fanout", startTime, err)
+		return nil, fmt.Errorf("%w: phase 2: %w", ErrAIProcessingFailed, err)
+	}
+
+	// After a fan-out the children's behaviors are in the cache, so the
+	// in-process pass only converts what failed children left behind.
+	phase2Ctx, phase2Span := tracer.Start(ctx, "specview.phase2", trace.WithAttributes(
+		attribute.Int("specview.domain_count", len(phase1Output.Domains)),
+	))
+	stopConvert = sla.StartPhase(ctx, sla.PhaseConvert)
 	phase2Results, internalStats, phase2Usage, err := uc.executePhase2(
-		ctx,
+		phase2Ctx,
 		req.AnalysisID,
 		phase1Output,
 		req.Language,
-		modelID,
+		req.UserID,
+		req.RequestedAt,
+		phase2ModelID,
 		testIndexMap,
 		files,
-		req.ForceRegenerate,
+		req.ForceRegenerate && childStatus == nil,
+		style,
+		glossary,
+		decisions,
+		checkpoints,
 	)
+	stopConvert()
+	endSpan(phase2Span, err)
+	// Features converted before a failure are charged too.
+	uc.recordTokenUsage(context.WithoutCancel(ctx), budget, req.JobID, phase2Usage)
 	if err != nil {
 		uc.logExecutionError(ctx, req.AnalysisID, "phase2", startTime, err)
 		return nil, fmt.Errorf("%w: phase 2: %w", ErrAIProcessingFailed, err)
 	}
+	if childStatus != nil {
+		internalStats.attributeChildConversions(childStatus.ConvertedTests)
+	}
 
 	// Log behavior cache stats
 	if internalStats != nil && internalStats.totalTests > 0 {
@@ -242,19 +712,66 @@ func (uc *GenerateSpecViewUseCase) Execute(
 		)
 	}
 
-	doc := uc.assembleDocument(req, modelID, contentHash, phase1Output, phase2Results, testIndexMap)
+	doc := uc.assembleDocument(req, documentModelID, contentHash, phase1Output, phase2Results, testIndexMap)
+	doc, refineUsage := uc.assessQuality(ctx, req, doc, phase1Output, phase2Results, phase2ModelID, style, glossary, testIndexMap, files, decisions)
+	if refineUsage != nil {
+		uc.recordTokenUsage(ctx, budget, req.JobID, refineUsage)
+		sum := cmp.Or(phase2Usage, &specview.TokenUsage{}).Add(*refineUsage)
+		phase2Usage = &sum
+	}
+	doc.PromptVersion = promptVersion
+	attachCacheKeys(doc, uc.cacheKeysByTestCase(ctx, files, req.Language, phase2ModelID, style, glossary, promptVersion))
+	uc.dedupBehaviors(ctx, req.AnalysisID, doc, decisions)
+	uc.assignSlugs(ctx, req, doc)
 
 	// Phase 3: Executive summary generation (non-fatal)
-	phase3Usage := uc.executePhase3(ctx, req.AnalysisID, doc)
-
-	if err := uc.repository.SaveDocument(ctx, doc); err != nil {
+	phase3Ctx, phase3Span := tracer.Start(ctx, "specview.phase3")
+	stopSummarize := sla.StartPhase(ctx, sla.PhaseSummarize)
+	phase3Usage := uc.executePhase3(phase3Ctx, req.AnalysisID, doc)
+	stopSummarize()
+	phase3Span.End()
+	uc.recordTokenUsage(ctx, budget, req.JobID, phase3Usage)
+
+	recordModelFallback(decisions, "phase1", phase1Usage)
+	recordModelFallback(decisions, "phase2", phase2Usage)
+	recordModelFallback(decisions, "phase3", phase3Usage)
+	doc.Decisions = decisions.Events()
+	doc.GenerationID = decisions.GenerationID()
+	quality := uc.scoreQuality(doc, decisions)
+	doc.Quality = &quality
+
+	stopSave := sla.StartPhase(ctx, sla.PhaseSave)
+	err = uc.repository.SaveDocument(ctx, doc)
+	stopSave()
+	if err != nil {
 		uc.logExecutionError(ctx, req.AnalysisID, "save", startTime, err)
 		return nil, fmt.Errorf("%w: %w", ErrSaveFailed, err)
 	}
 
-	// Quota based on AI-generated behaviors only (cache hits are free)
+	var teamDocumentIDs map[string]string
+	if req.TeamSlices {
+		teamDocumentIDs = uc.saveTeamSlices(ctx, doc, files, rules)
+	}
+	translations := uc.saveTranslations(ctx, req, doc, "", documentModelID, files, rules, taxonomy, budget)
+
+	// Quota based on AI-generated behaviors only (cache hits, dry runs and heuristic documents are free)
 	quotaAmount := internalStats.cacheMisses
-	uc.recordUsageEvent(ctx, req.UserID, doc.ID, quotaAmount)
+	if uc.generator != specview.GeneratorAI {
+		quotaAmount = 0
+	} else {
+		uc.recordUsageEvent(ctx, req.UserID, doc.ID, quotaAmount)
+	}
+	remainingQuota := uc.checkQuota(ctx, req.UserID, doc.ID, quotaAmount)
+	uc.recordAIUsage(ctx, specview.AIUsage{
+		AnalysisID: req.AnalysisID,
+		DocumentID: doc.ID,
+		JobID:      req.JobID,
+		UserID:     req.UserID,
+	}, map[string]*specview.TokenUsage{
+		"phase1": phase1Usage,
+		"phase2": phase2Usage,
+		"phase3": phase3Usage,
+	})
 	uc.recordUserHistory(ctx, req.UserID, doc.ID)
 
 	// Log token usage summary
@@ -267,6 +784,8 @@ func (uc *GenerateSpecViewUseCase) Execute(
 		"repo", analysisCtx.Repo,
 		"document_id", doc.ID,
 		"domain_count", len(doc.Domains),
+		"quality_score", fmt.Sprintf("%.2f", quality.Score),
+		"quality_flagged", quality.Flagged,
 	)
 
 	return &specview.SpecViewResult{
@@ -275,9 +794,151 @@ func (uc *GenerateSpecViewUseCase) Execute(
 		CacheHit:           false,
 		ContentHash:        contentHash,
 		DocumentID:         doc.ID,
+		Generator:          uc.generator,
+		Quality:            doc.Quality,
+		RemainingQuota:     remainingQuota,
+		TeamDocumentIDs:    teamDocumentIDs,
+		Translations:       translations,
 	}, nil
 }
 
+// findExistingResult serves the user's document for the same content, or
+// another user's when it may be shared. It returns nil when the document
+// must be generated.
+func (uc *GenerateSpecViewUseCase) findExistingResult(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	analysisCtx *specview.AnalysisContext,
+	contentHash []byte,
+	modelID string,
+) (*specview.SpecViewResult, error) {
+	existingDoc, err := uc.repository.FindDocumentByContentHash(ctx, req.UserID, contentHash, req.Language, modelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingDoc != nil {
+		slog.InfoContext(ctx, "cache hit",
+			"analysis_id", req.AnalysisID,
+			"user_id", req.UserID,
+			"owner", analysisCtx.Owner,
+			"repo", analysisCtx.Repo,
+			"document_id", existingDoc.ID,
+		)
+
+		uc.recordUserHistory(ctx, req.UserID, existingDoc.ID)
+
+		return &specview.SpecViewResult{
+			AnalysisContext: analysisCtx,
+			CacheHit:        true,
+			ContentHash:     contentHash,
+			DocumentID:      existingDoc.ID,
+			Generator:       uc.generator,
+			RemainingQuota:  uc.checkQuota(ctx, req.UserID, existingDoc.ID, 0),
+		}, nil
+	}
+
+	if sharedDoc := uc.findSharedDocument(ctx, req, analysisCtx, contentHash, modelID); sharedDoc != nil {
+		uc.recordUserHistory(ctx, req.UserID, sharedDoc.ID)
+
+		return &specview.SpecViewResult{
+			AnalysisContext: analysisCtx,
+			CacheHit:        true,
+			ContentHash:     contentHash,
+			DocumentID:      sharedDoc.ID,
+			Generator:       uc.generator,
+			RemainingQuota:  uc.checkQuota(ctx, req.UserID, sharedDoc.ID, 0),
+			Shared:          true,
+		}, nil
+	}
+	return nil, nil
+}
+
+// findSharedDocument returns another user's document for the same codebase and
+// content when it may be shared: the repository is not opted out and the VCS
+// host currently reports it public with a shareable license. The basis is
+// recorded before the document is served. Team slices are per user, and a
+// scoped behavior cache must not be bypassed through another user's document,
+// so such requests always generate. Lookup failures are non-critical and fall
+// back to generating the document.
+func (uc *GenerateSpecViewUseCase) findSharedDocument(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	analysisCtx *specview.AnalysisContext,
+	contentHash []byte,
+	modelID string,
+) *specview.SpecDocument {
+	if uc.sharingRepo == nil || req.TeamSlices || specview.CacheScope(ctx) != "" {
+		return nil
+	}
+
+	doc, err := uc.sharingRepo.FindShareableDocument(ctx, req.UserID, req.AnalysisID, contentHash, req.Language, modelID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to look up shareable document (non-critical)",
+			"analysis_id", req.AnalysisID,
+			"error", err,
+		)
+		return nil
+	}
+	if doc == nil {
+		return nil
+	}
+
+	optedOut, err := uc.sharingRepo.IsSharingOptedOut(ctx, req.AnalysisID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check sharing opt-out (non-critical)",
+			"analysis_id", req.AnalysisID,
+			"error", err,
+		)
+		return nil
+	}
+	if optedOut {
+		slog.InfoContext(ctx, "document not shared",
+			"analysis_id", req.AnalysisID,
+			"reason", "opted_out",
+		)
+		return nil
+	}
+
+	license, err := uc.config.LicenseLookup.GetRepoLicense(ctx, analysisCtx.Host, analysisCtx.Owner, analysisCtx.Repo)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to look up repository license (non-critical)",
+			"owner", analysisCtx.Owner,
+			"repo", analysisCtx.Repo,
+			"error", err,
+		)
+		return nil
+	}
+	basis, ok := specview.SharingBasis(license)
+	if !ok {
+		slog.InfoContext(ctx, "document not shared",
+			"analysis_id", req.AnalysisID,
+			"reason", "license",
+			"private", license.Private,
+			"license", license.SPDXID,
+		)
+		return nil
+	}
+
+	if err := uc.sharingRepo.RecordDocumentShare(ctx, doc.ID, req.UserID, basis); err != nil {
+		slog.WarnContext(ctx, "failed to record document share (non-critical)",
+			"document_id", doc.ID,
+			"error", err,
+		)
+		return nil
+	}
+
+	slog.InfoContext(ctx, "shared document served",
+		"analysis_id", req.AnalysisID,
+		"user_id", req.UserID,
+		"owner", analysisCtx.Owner,
+		"repo", analysisCtx.Repo,
+		"document_id", doc.ID,
+		"basis", basis,
+	)
+	return doc
+}
+
 func (uc *GenerateSpecViewUseCase) recordUsageEvent(
 	ctx context.Context,
 	userID string,
@@ -294,6 +955,60 @@ func (uc *GenerateSpecViewUseCase) recordUsageEvent(
 	}
 }
 
+// checkQuota reads the user's quota after a spend of quotaAmount, warns the
+// user when the spend crossed a threshold, and returns the remaining quota.
+// Failures are non-critical: the result carries no remaining quota.
+func (uc *GenerateSpecViewUseCase) checkQuota(
+	ctx context.Context,
+	userID string,
+	documentID string,
+	quotaAmount int,
+) *int64 {
+	if uc.quotaReader == nil {
+		return nil
+	}
+	status, err := uc.quotaReader.GetQuotaStatus(ctx, userID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read quota (non-critical)",
+			"user_id", userID,
+			"error", err,
+		)
+		return nil
+	}
+	remaining := status.Remaining()
+
+	if uc.config.UsageNotifier == nil {
+		return remaining
+	}
+	threshold := status.CrossedThreshold(int64(quotaAmount), uc.config.QuotaThresholds)
+	if threshold == 0 {
+		return remaining
+	}
+	warning := specview.UsageWarning{
+		DocumentID: documentID,
+		Limit:      *status.Limit,
+		Remaining:  *remaining,
+		Threshold:  threshold,
+		Used:       status.Used,
+		UserID:     userID,
+	}
+	if err := uc.config.UsageNotifier.NotifyUsageWarning(ctx, warning); err != nil {
+		slog.WarnContext(ctx, "failed to send usage warning (non-critical)",
+			"user_id", userID,
+			"threshold", threshold,
+			"error", err,
+		)
+		return remaining
+	}
+	slog.InfoContext(ctx, "usage warning sent",
+		"user_id", userID,
+		"threshold", threshold,
+		"used", status.Used,
+		"limit", *status.Limit,
+	)
+	return remaining
+}
+
 func (uc *GenerateSpecViewUseCase) recordUserHistory(
 	ctx context.Context,
 	userID string,
@@ -308,6 +1023,87 @@ func (uc *GenerateSpecViewUseCase) recordUserHistory(
 	}
 }
 
+// loadCurationRules returns the rules stored with the analysis, or nil.
+// Failure is non-critical: generation falls back to the plain AI classification.
+func (uc *GenerateSpecViewUseCase) loadCurationRules(ctx context.Context, analysisID string) *curation.Rules {
+	if uc.curationReader == nil {
+		return nil
+	}
+	rules, err := uc.curationReader.GetCurationRules(ctx, analysisID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load curation rules (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+		return nil
+	}
+	return rules
+}
+
+// loadTaxonomy returns the taxonomy of the analysis's codebase.
+// Failures are non-critical: the domains are not constrained.
+func (uc *GenerateSpecViewUseCase) loadTaxonomy(ctx context.Context, analysisID string) *specview.Taxonomy {
+	if uc.taxonomyReader == nil {
+		return nil
+	}
+	taxonomy, err := uc.taxonomyReader.GetTaxonomy(ctx, analysisID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load taxonomy (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+		return nil
+	}
+	return taxonomy
+}
+
+// constrainToTaxonomy moves the Phase 1 domains outside the codebase taxonomy
+// into its canonical domains or Uncategorized, recording each move.
+func constrainToTaxonomy(
+	ctx context.Context,
+	analysisID string,
+	output *specview.Phase1Output,
+	taxonomy *specview.Taxonomy,
+	decisions *specview.DecisionLog,
+) *specview.Phase1Output {
+	if taxonomy.IsEmpty() {
+		return output
+	}
+	constrained, remapped := specview.ConstrainToTaxonomy(output, taxonomy)
+	for _, r := range remapped {
+		decisions.Record(specview.DecisionTaxonomyRemapped, r.From, map[string]any{
+			"domain": r.To,
+			"mode":   string(taxonomy.Mode),
+		})
+	}
+	if len(remapped) > 0 {
+		slog.InfoContext(ctx, "phase 1 domains constrained to taxonomy",
+			"analysis_id", analysisID,
+			"mode", taxonomy.Mode,
+			"remapped_count", len(remapped),
+		)
+	}
+	return constrained
+}
+
+// loadGlossary returns the glossary applied to the requested document.
+// Failures are non-critical: the document is generated without it.
+func (uc *GenerateSpecViewUseCase) loadGlossary(ctx context.Context, req specview.SpecViewRequest) *specview.Glossary {
+	if uc.glossaryReader == nil {
+		return nil
+	}
+	glossary, err := uc.glossaryReader.GetGlossary(ctx, req.UserID, req.AnalysisID, req.Language)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load glossary (non-critical)",
+			"analysis_id", req.AnalysisID,
+			"user_id", req.UserID,
+			"error", err,
+		)
+		return nil
+	}
+	return glossary
+}
+
 func (uc *GenerateSpecViewUseCase) loadTestData(
 	ctx context.Context,
 	analysisID string,
@@ -324,6 +1120,8 @@ func (uc *GenerateSpecViewUseCase) executePhase1(
 	files []specview.FileInfo,
 	lang specview.Language,
 	analysisID string,
+	taxonomy []specview.DomainGroup,
+	constraint *specview.Taxonomy,
 ) (*specview.Phase1Output, *specview.TokenUsage, error) {
 	startTime := time.Now()
 	fileCount := len(files)
@@ -339,9 +1137,14 @@ func (uc *GenerateSpecViewUseCase) executePhase1(
 	defer cancel()
 
 	input := specview.Phase1Input{
-		AnalysisID: analysisID,
-		Files:      files,
-		Language:   lang,
+		AnalysisID:  analysisID,
+		Constrained: !constraint.IsEmpty(),
+		Files:       files,
+		Language:    lang,
+		Taxonomy:    taxonomy,
+	}
+	if input.Constrained {
+		input.Taxonomy = constraint.Anchors()
 	}
 
 	output, usage, err := uc.aiProvider.ClassifyDomains(phase1Ctx, input)
@@ -366,6 +1169,9 @@ func (uc *GenerateSpecViewUseCase) executePhase1(
 // - cache hit + no changes: return cached output (zero token usage)
 // - cache hit + deletions only: remove indices, update cache
 // - cache hit + additions: call placement AI, fallback to Uncategorized on failure
+//
+// A non-empty constraint replaces taxonomy as the complete list of domains and
+// is part of the cache signature; the caller constrains the output to it.
 func (uc *GenerateSpecViewUseCase) executePhase1WithCache(
 	ctx context.Context,
 	files []specview.FileInfo,
@@ -373,15 +1179,23 @@ func (uc *GenerateSpecViewUseCase) executePhase1WithCache(
 	modelID string,
 	analysisID string,
 	forceRegenerate bool,
+	taxonomy []specview.DomainGroup,
+	constraint *specview.Taxonomy,
+	decisions *specview.DecisionLog,
 ) (*specview.Phase1Output, *specview.TokenUsage, error) {
-	fileSignature := specview.GenerateFileSignature(files)
+	signature := specview.PromptContentHash(
+		specview.TaxonomyHash(specview.TaxonomySignature(specview.GenerateFileSignature(files), taxonomy), constraint),
+		specview.PromptVersion(ctx),
+	)
+	fileSignature := specview.ScopedSignature(signature, specview.CacheScope(ctx))
 
 	// Skip cache lookup if forceRegenerate
 	if forceRegenerate {
 		slog.InfoContext(ctx, "classification cache bypassed (force regenerate)",
 			"analysis_id", analysisID,
 		)
-		return uc.executePhase1AndSaveCache(ctx, files, lang, modelID, analysisID, fileSignature)
+		recordClassificationCache(decisions, "bypassed", nil)
+		return uc.executePhase1AndSaveCache(ctx, files, lang, modelID, analysisID, fileSignature, taxonomy, constraint)
 	}
 
 	// Lookup classification cache
@@ -391,7 +1205,8 @@ func (uc *GenerateSpecViewUseCase) executePhase1WithCache(
 			"analysis_id", analysisID,
 			"error", err,
 		)
-		return uc.executePhase1AndSaveCache(ctx, files, lang, modelID, analysisID, fileSignature)
+		recordClassificationCache(decisions, "lookup_failed", map[string]any{"error": err.Error()})
+		return uc.executePhase1AndSaveCache(ctx, files, lang, modelID, analysisID, fileSignature, taxonomy, constraint)
 	}
 
 	// Cache miss
@@ -399,7 +1214,8 @@ func (uc *GenerateSpecViewUseCase) executePhase1WithCache(
 		slog.InfoContext(ctx, "classification cache miss",
 			"analysis_id", analysisID,
 		)
-		return uc.executePhase1AndSaveCache(ctx, files, lang, modelID, analysisID, fileSignature)
+		recordClassificationCache(decisions, "miss", nil)
+		return uc.executePhase1AndSaveCache(ctx, files, lang, modelID, analysisID, fileSignature, taxonomy, constraint)
 	}
 
 	// Cache hit - calculate diff
@@ -411,6 +1227,7 @@ func (uc *GenerateSpecViewUseCase) executePhase1WithCache(
 			"analysis_id", analysisID,
 			"domain_count", len(cache.ClassificationResult.Domains),
 		)
+		recordClassificationCache(decisions, "hit", nil)
 		return cache.ClassificationResult, &specview.TokenUsage{}, nil
 	}
 
@@ -420,8 +1237,9 @@ func (uc *GenerateSpecViewUseCase) executePhase1WithCache(
 			"analysis_id", analysisID,
 			"deleted_count", len(diff.DeletedTests),
 		)
+		recordClassificationCache(decisions, "hit_deletions_only", map[string]any{"deleted_tests": len(diff.DeletedTests)})
 		updatedOutput := RemoveDeletedTestIndices(cache.ClassificationResult, diff.DeletedTests)
-		uc.updateClassificationCache(ctx, cache, updatedOutput, files)
+		uc.updateClassificationCache(ctx, cache, updatedOutput, files, analysisID)
 		return updatedOutput, &specview.TokenUsage{}, nil
 	}
 
@@ -431,6 +1249,10 @@ func (uc *GenerateSpecViewUseCase) executePhase1WithCache(
 		"new_count", len(diff.NewTests),
 		"deleted_count", len(diff.DeletedTests),
 	)
+	recordClassificationCache(decisions, "hit_additions", map[string]any{
+		"deleted_tests": len(diff.DeletedTests),
+		"new_tests":     len(diff.NewTests),
+	})
 
 	// First apply deletions if any
 	baseOutput := cache.ClassificationResult
@@ -453,14 +1275,20 @@ func (uc *GenerateSpecViewUseCase) executePhase1WithCache(
 			"error", err,
 		)
 		// Fallback: place all new tests in Uncategorized
+		for _, t := range diff.NewTests {
+			decisions.Record(specview.DecisionPlacementFallback, t.Name, map[string]any{
+				"error":      err.Error(),
+				"suite_path": t.SuitePath,
+			})
+		}
 		updatedOutput := placeAllToUncategorized(baseOutput, diff.NewTests)
-		uc.updateClassificationCache(ctx, cache, updatedOutput, files)
+		uc.updateClassificationCache(ctx, cache, updatedOutput, files, analysisID)
 		return updatedOutput, &specview.TokenUsage{}, nil
 	}
 
 	// Apply placements
 	updatedOutput := applyPlacements(baseOutput, placementOutput.Placements)
-	uc.updateClassificationCache(ctx, cache, updatedOutput, files)
+	uc.updateClassificationCache(ctx, cache, updatedOutput, files, analysisID)
 
 	slog.InfoContext(ctx, "placement AI completed",
 		"analysis_id", analysisID,
@@ -478,14 +1306,17 @@ func (uc *GenerateSpecViewUseCase) executePhase1AndSaveCache(
 	modelID string,
 	analysisID string,
 	fileSignature []byte,
+	taxonomy []specview.DomainGroup,
+	constraint *specview.Taxonomy,
 ) (*specview.Phase1Output, *specview.TokenUsage, error) {
-	output, usage, err := uc.executePhase1(ctx, files, lang, analysisID)
+	output, usage, err := uc.executePhase1(ctx, files, lang, analysisID, taxonomy, constraint)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Build and save cache (non-blocking on error)
 	cache := &specview.ClassificationCache{
+		AnalysisID:           analysisID,
 		ClassificationResult: output,
 		FileSignature:        fileSignature,
 		Language:             lang,
@@ -509,7 +1340,9 @@ func (uc *GenerateSpecViewUseCase) updateClassificationCache(
 	cache *specview.ClassificationCache,
 	updatedOutput *specview.Phase1Output,
 	files []specview.FileInfo,
+	analysisID string,
 ) {
+	cache.AnalysisID = analysisID
 	cache.ClassificationResult = updatedOutput
 	cache.TestIndexMap = specview.BuildTestIndexMap(updatedOutput, files)
 
@@ -530,20 +1363,32 @@ type phase2Result struct {
 	newCacheEntries []specview.BehaviorCacheEntry
 }
 
+// executePhase2 converts the test names of every feature. The token usage is
+// returned on failure too, summing the features converted before it.
 func (uc *GenerateSpecViewUseCase) executePhase2(
 	ctx context.Context,
 	analysisID string,
 	phase1Output *specview.Phase1Output,
 	lang specview.Language,
+	requestedBy string,
+	requestedAt time.Time,
 	modelID string,
 	testIndexMap map[int]specview.TestInfo,
 	files []specview.FileInfo,
 	forceRegenerate bool,
+	style string,
+	glossary *specview.Glossary,
+	decisions *specview.DecisionLog,
+	checkpoints *checkpointWriter,
 ) ([]phase2Result, *internalCacheStats, *specview.TokenUsage, error) {
 	startTime := time.Now()
 
-	phase2Ctx, cancel := context.WithTimeout(ctx, uc.config.Phase2Timeout)
-	defer cancel()
+	// Giant repos run for as long as features keep completing; only a stalled
+	// run, the hard cap or the user cancelling ends Phase 2.
+	watchCtx, stopWatch := uc.watchCancellation(ctx, analysisID, lang, requestedBy, requestedAt)
+	defer stopWatch()
+	phase2Ctx, deadline, stopDeadline := withProgressDeadline(watchCtx, uc.config.Phase2Timeout, uc.config.Phase2MaxTimeout)
+	defer stopDeadline()
 
 	var featureTasks []featureTask
 	totalTests := 0
@@ -566,7 +1411,7 @@ func (uc *GenerateSpecViewUseCase) executePhase2(
 	// Build test -> filePath mapping for cache key generation
 	testFilePathMap := buildTestFilePathMap(files)
 
-	// Lookup behavior cache (skip if forceRegenerate)
+	// Lookup behavior cache; forceRegenerate only keeps human-verified entries
 	var cachedBehaviors map[string]string
 	var testHashMap map[int]string
 	cacheStats := &internalCacheStats{totalTests: totalTests}
@@ -580,6 +1425,8 @@ func (uc *GenerateSpecViewUseCase) executePhase2(
 			testFilePathMap,
 			lang,
 			modelID,
+			style,
+			glossary,
 		)
 		if err != nil {
 			slog.WarnContext(ctx, "behavior cache lookup failed, proceeding without cache",
@@ -591,9 +1438,10 @@ func (uc *GenerateSpecViewUseCase) executePhase2(
 		cacheStats.cacheHits = len(cachedBehaviors)
 		cacheStats.cacheMisses = totalTests - cacheStats.cacheHits
 	} else {
-		cachedBehaviors = make(map[string]string)
-		testHashMap = uc.buildTestHashMap(phase1Output, testIndexMap, testFilePathMap, lang, modelID)
-		cacheStats.cacheMisses = totalTests
+		testHashMap = uc.buildTestHashMap(phase1Output, testIndexMap, testFilePathMap, lang, modelID, style, glossary, specview.PromptVersion(ctx), specview.CacheScope(ctx))
+		cachedBehaviors = uc.lookupVerifiedBehaviors(ctx, testHashMap)
+		cacheStats.cacheHits = len(cachedBehaviors)
+		cacheStats.cacheMisses = totalTests - cacheStats.cacheHits
 	}
 
 	slog.InfoContext(ctx, "phase 2 started",
@@ -603,6 +1451,7 @@ func (uc *GenerateSpecViewUseCase) executePhase2(
 		"cache_hits", cacheStats.cacheHits,
 		"cache_misses", cacheStats.cacheMisses,
 		"force_regenerate", forceRegenerate,
+		"resumed_features", checkpoints.resumedCount(),
 	)
 
 	var (
@@ -611,12 +1460,32 @@ func (uc *GenerateSpecViewUseCase) executePhase2(
 		tracker   = newProgressTracker(len(featureTasks), analysisID)
 	)
 
+	// Features converted by an earlier attempt of the job are not converted again.
+	pending := make([]int, 0, len(featureTasks))
+	for i, task := range featureTasks {
+		if behaviors, ok := checkpoints.resumedFeature(task.domainIdx, task.featureIdx); ok {
+			results[i] = phase2Result{
+				behaviors:  behaviors,
+				domainIdx:  task.domainIdx,
+				featureIdx: task.featureIdx,
+			}
+			continue
+		}
+		pending = append(pending, i)
+	}
+	tracker.completed.Add(int32(len(featureTasks) - len(pending)))
+	tracker.cacheHitRate = cacheStats.hitRate()
+	tracker.language = lang
+	tracker.progressRepo = uc.progressRepo
+	tracker.saveSnapshot(ctx, specview.GenerationRunning)
+
 	// Per-job semaphore: prevents concurrent jobs from competing for shared slots
 	phase2Sem := semaphore.NewWeighted(uc.config.Phase2Concurrency)
 
 	g, gCtx := errgroup.WithContext(phase2Ctx)
 
-	for i, task := range featureTasks {
+	for _, i := range pending {
+		task := featureTasks[i]
 		g.Go(func() error {
 			if err := phase2Sem.Acquire(gCtx, 1); err != nil {
 				return err
@@ -627,9 +1496,12 @@ func (uc *GenerateSpecViewUseCase) executePhase2(
 				gCtx,
 				task,
 				lang,
+				style,
+				glossary,
 				testIndexMap,
 				testHashMap,
 				cachedBehaviors,
+				decisions,
 			)
 
 			resultsMu.Lock()
@@ -643,6 +1515,10 @@ func (uc *GenerateSpecViewUseCase) executePhase2(
 			}
 			resultsMu.Unlock()
 
+			if failed == 0 {
+				checkpoints.recordFeature(ctx, task.domainIdx, task.featureIdx, behaviors)
+			}
+			deadline.extend()
 			tracker.recordCompletion(ctx, failed > 0)
 
 			return nil
@@ -650,31 +1526,49 @@ func (uc *GenerateSpecViewUseCase) executePhase2(
 	}
 
 	waitErr := g.Wait()
+	// Features interrupted by the cancellation count as failed, so it is told
+	// apart from a partial failure by the cause rather than by waitErr.
+	if cause := context.Cause(phase2Ctx); errors.Is(cause, specview.ErrGenerationCancelled) && ctx.Err() == nil {
+		tracker.saveSnapshot(ctx, specview.GenerationCancelled)
+		uc.salvageBehaviorCache(ctx, analysisID, results)
+		slog.InfoContext(ctx, "phase 2 cancelled",
+			"analysis_id", analysisID,
+			"completed_count", tracker.completed.Load(),
+			"feature_count", len(featureTasks),
+		)
+		return nil, nil, sumPhase2Usage(results), cause
+	}
 	if waitErr != nil {
-		uc.salvageBehaviorCache(ctx, results)
-		return nil, nil, nil, waitErr
+		if cause := context.Cause(phase2Ctx); cause != nil && ctx.Err() == nil {
+			waitErr = fmt.Errorf("%w: %w", cause, waitErr)
+		}
+		tracker.saveSnapshot(context.WithoutCancel(ctx), specview.GenerationFailed)
+		uc.salvageBehaviorCache(ctx, analysisID, results)
+		checkpoints.save(context.WithoutCancel(ctx))
+		return nil, nil, sumPhase2Usage(results), waitErr
 	}
 
 	failedCount := int(tracker.failed.Load())
 	failureRate := float64(failedCount) / float64(len(featureTasks))
 	if failureRate > uc.config.FailureThreshold {
-		uc.salvageBehaviorCache(ctx, results)
-		return nil, nil, nil, fmt.Errorf("%w: %.0f%% features failed (threshold: %.0f%%)",
+		tracker.saveSnapshot(ctx, specview.GenerationFailed)
+		uc.salvageBehaviorCache(ctx, analysisID, results)
+		checkpoints.save(ctx)
+		return nil, nil, sumPhase2Usage(results), fmt.Errorf("%w: %.0f%% features failed (threshold: %.0f%%)",
 			ErrPartialFeatureFailure,
 			failureRate*100,
 			uc.config.FailureThreshold*100,
 		)
 	}
 
-	// Aggregate Phase 2 token usage and collect cache entries to save
-	var aggregateUsage specview.TokenUsage
+	// Collect cache entries to save
 	var allNewCacheEntries []specview.BehaviorCacheEntry
 	for _, r := range results {
-		if r.usage != nil {
-			aggregateUsage = aggregateUsage.Add(*r.usage)
-		}
 		allNewCacheEntries = append(allNewCacheEntries, r.newCacheEntries...)
 	}
+	for i := range allNewCacheEntries {
+		allNewCacheEntries[i].AnalysisID = analysisID
+	}
 
 	// Save new cache entries (non-blocking on error)
 	if len(allNewCacheEntries) > 0 {
@@ -686,6 +1580,8 @@ func (uc *GenerateSpecViewUseCase) executePhase2(
 		}
 	}
 
+	tracker.saveSnapshot(ctx, specview.GenerationCompleted)
+
 	durationMs := time.Since(startTime).Milliseconds()
 	slog.InfoContext(ctx, "phase 2 complete",
 		"analysis_id", analysisID,
@@ -694,16 +1590,30 @@ func (uc *GenerateSpecViewUseCase) executePhase2(
 		"duration_ms", durationMs,
 	)
 
-	return results, cacheStats, &aggregateUsage, nil
+	return results, cacheStats, sumPhase2Usage(results), nil
+}
+
+// sumPhase2Usage adds up the token usage of the features in results.
+func sumPhase2Usage(results []phase2Result) *specview.TokenUsage {
+	var usage specview.TokenUsage
+	for _, r := range results {
+		if r.usage != nil {
+			usage = usage.Add(*r.usage)
+		}
+	}
+	return &usage
 }
 
 // salvageBehaviorCache persists cache entries from successfully processed features even when
 // Phase 2 fails, enabling cache hits on subsequent retries.
-func (uc *GenerateSpecViewUseCase) salvageBehaviorCache(ctx context.Context, results []phase2Result) {
+func (uc *GenerateSpecViewUseCase) salvageBehaviorCache(ctx context.Context, analysisID string, results []phase2Result) {
 	var entries []specview.BehaviorCacheEntry
 	for _, r := range results {
 		entries = append(entries, r.newCacheEntries...)
 	}
+	for i := range entries {
+		entries[i].AnalysisID = analysisID
+	}
 	if len(entries) == 0 {
 		return
 	}
@@ -741,8 +1651,11 @@ func (uc *GenerateSpecViewUseCase) lookupBehaviorCache(
 	testFilePathMap map[int]string,
 	lang specview.Language,
 	modelID string,
+	style string,
+	glossary *specview.Glossary,
 ) (map[string]string, map[int]string, error) {
-	testHashMap := uc.buildTestHashMap(phase1Output, testIndexMap, testFilePathMap, lang, modelID)
+	promptVersion := specview.PromptVersion(ctx)
+	testHashMap := uc.buildTestHashMap(phase1Output, testIndexMap, testFilePathMap, lang, modelID, style, glossary, promptVersion, specview.CacheScope(ctx))
 
 	// Collect all hashes for batch lookup
 	var allHashes [][]byte
@@ -768,9 +1681,103 @@ func (uc *GenerateSpecViewUseCase) lookupBehaviorCache(
 		return nil, nil, err
 	}
 
+	if uc.config.LegacyCacheKeyHash != "" || uc.semanticCache != nil {
+		if cachedBehaviors == nil {
+			cachedBehaviors = make(map[string]string)
+		}
+		missed := make(map[string]behaviorKeyInput)
+		for testIdx, hexHash := range testHashMap {
+			if _, ok := cachedBehaviors[hexHash]; !ok {
+				missed[hexHash] = behaviorKeyInput{filePath: testFilePathMap[testIdx], test: testIndexMap[testIdx]}
+			}
+		}
+		if uc.config.LegacyCacheKeyHash != "" {
+			uc.migrateLegacyBehaviors(ctx, cachedBehaviors, missed, lang, modelID, style, glossary.Version(), promptVersion)
+			maps.DeleteFunc(missed, func(hexHash string, _ behaviorKeyInput) bool {
+				_, ok := cachedBehaviors[hexHash]
+				return ok
+			})
+		}
+		uc.reuseSimilarBehaviors(ctx, cachedBehaviors, missed, lang, modelID, style, glossary.Version(), promptVersion)
+	}
+
 	return cachedBehaviors, testHashMap, nil
 }
 
+// lookupVerifiedBehaviors returns the human-verified cache entries of the
+// tests in testHashMap, keyed by hex hash. A forced regeneration converts
+// every other test again. A failed lookup is logged and nothing is kept.
+func (uc *GenerateSpecViewUseCase) lookupVerifiedBehaviors(ctx context.Context, testHashMap map[int]string) map[string]string {
+	verified := make(map[string]string)
+	if uc.verifiedReader == nil || len(testHashMap) == 0 {
+		return verified
+	}
+
+	hashes := make([][]byte, 0, len(testHashMap))
+	seen := make(map[string]struct{}, len(testHashMap))
+	for _, hexHash := range testHashMap {
+		if _, dup := seen[hexHash]; dup {
+			continue
+		}
+		seen[hexHash] = struct{}{}
+		hash, err := hex.DecodeString(hexHash)
+		if err != nil {
+			continue
+		}
+		hashes = append(hashes, hash)
+	}
+
+	found, err := uc.verifiedReader.FindVerifiedBehaviors(ctx, hashes)
+	if err != nil {
+		slog.WarnContext(ctx, "verified behavior lookup failed, regenerating all tests (non-critical)",
+			"error", err,
+		)
+		return verified
+	}
+	maps.Copy(verified, found)
+	return verified
+}
+
+// cacheKeysByTestCase returns the behavior cache key of every test by test
+// case ID, for the cache scope of ctx.
+func (uc *GenerateSpecViewUseCase) cacheKeysByTestCase(
+	ctx context.Context,
+	files []specview.FileInfo,
+	lang specview.Language,
+	modelID string,
+	style string,
+	glossary *specview.Glossary,
+	promptVersion string,
+) map[string][]byte {
+	scope := specview.CacheScope(ctx)
+	keys := make(map[string][]byte)
+	for _, f := range files {
+		for _, test := range f.Tests {
+			if test.TestCaseID == "" {
+				continue
+			}
+			hexHash := behaviorCacheKeyHex(uc.config.CacheKeyHash, f.Path, test, lang, modelID, style, glossary.Version(), promptVersion, scope)
+			if hash, err := hex.DecodeString(hexHash); err == nil {
+				keys[test.TestCaseID] = hash
+			}
+		}
+	}
+	return keys
+}
+
+// attachCacheKeys sets the cache key of every behavior from its test, so user
+// feedback on a saved behavior can correct its cache entry.
+func attachCacheKeys(doc *specview.SpecDocument, keys map[string][]byte) {
+	for di := range doc.Domains {
+		for fi := range doc.Domains[di].Features {
+			behaviors := doc.Domains[di].Features[fi].Behaviors
+			for bi := range behaviors {
+				behaviors[bi].CacheKeyHash = keys[behaviors[bi].TestCaseID]
+			}
+		}
+	}
+}
+
 // buildTestHashMap generates hex-encoded cache key hashes for all tests.
 func (uc *GenerateSpecViewUseCase) buildTestHashMap(
 	phase1Output *specview.Phase1Output,
@@ -778,6 +1785,10 @@ func (uc *GenerateSpecViewUseCase) buildTestHashMap(
 	testFilePathMap map[int]string,
 	lang specview.Language,
 	modelID string,
+	style string,
+	glossary *specview.Glossary,
+	promptVersion string,
+	scope string,
 ) map[int]string {
 	// Pre-calculate total tests for efficient map allocation
 	totalTests := 0
@@ -795,17 +1806,7 @@ func (uc *GenerateSpecViewUseCase) buildTestHashMap(
 				if !ok {
 					continue
 				}
-				filePath := testFilePathMap[testIdx]
-
-				key := specview.BehaviorCacheKey{
-					FilePath:  filePath,
-					Language:  lang,
-					ModelID:   modelID,
-					SuitePath: testInfo.SuitePath,
-					TestName:  testInfo.Name,
-				}
-				hash := specview.GenerateCacheKeyHash(key)
-				result[testIdx] = hex.EncodeToString(hash)
+				result[testIdx] = behaviorCacheKeyHex(uc.config.CacheKeyHash, testFilePathMap[testIdx], testInfo, lang, modelID, style, glossary.Version(), promptVersion, scope)
 			}
 		}
 	}
@@ -820,13 +1821,18 @@ type featureTask struct {
 }
 
 // progressTracker tracks Phase 2 progress and handles batch logging.
+// When progressRepo is set, snapshots are persisted at the same points as the logs
+// (and on every completion for small jobs) so the Web app can show live progress.
 type progressTracker struct {
-	analysisID string
-	completed  atomic.Int32
-	failed     atomic.Int32
-	lastLogTime atomic.Int64 // unix nano
-	startTime  time.Time
-	total      int32
+	analysisID   string
+	cacheHitRate float64
+	completed    atomic.Int32
+	failed       atomic.Int32
+	language     specview.Language
+	lastLogTime  atomic.Int64 // unix nano
+	progressRepo specview.GenerationProgressRepository
+	startTime    time.Time
+	total        int32
 }
 
 func newProgressTracker(total int, analysisID string) *progressTracker {
@@ -847,6 +1853,9 @@ func (pt *progressTracker) recordCompletion(ctx context.Context, failed bool) {
 	}
 
 	if pt.total < progressLogMinFeatures {
+		if completed < pt.total {
+			pt.saveSnapshot(ctx, specview.GenerationRunning)
+		}
 		return
 	}
 
@@ -871,12 +1880,6 @@ func (pt *progressTracker) maybeLogProgress(ctx context.Context, completed int32
 
 	if pt.lastLogTime.CompareAndSwap(lastLog, now) {
 		progressPct := float64(completed) / float64(pt.total) * 100
-		elapsed := time.Since(pt.startTime)
-		etaSeconds := int64(0)
-		if completed > 0 {
-			remaining := pt.total - completed
-			etaSeconds = int64(elapsed.Seconds() / float64(completed) * float64(remaining))
-		}
 
 		slog.InfoContext(ctx, "phase 2 progress",
 			"analysis_id", pt.analysisID,
@@ -884,7 +1887,45 @@ func (pt *progressTracker) maybeLogProgress(ctx context.Context, completed int32
 			"total", pt.total,
 			"failed", pt.failed.Load(),
 			"progress_pct", int(progressPct),
-			"eta_seconds", etaSeconds,
+			"eta_seconds", pt.etaSeconds(completed),
+		)
+		pt.saveSnapshot(ctx, specview.GenerationRunning)
+	}
+}
+
+// etaSeconds extrapolates the remaining time from the average feature duration so far.
+func (pt *progressTracker) etaSeconds(completed int32) int64 {
+	if completed <= 0 || completed >= pt.total {
+		return 0
+	}
+	elapsed := time.Since(pt.startTime)
+	remaining := pt.total - completed
+	return int64(elapsed.Seconds() / float64(completed) * float64(remaining))
+}
+
+// saveSnapshot persists the current progress.
+// Failure is non-critical: progress is informational and must never fail generation.
+func (pt *progressTracker) saveSnapshot(ctx context.Context, status specview.GenerationStatus) {
+	if pt.progressRepo == nil {
+		return
+	}
+
+	completed := pt.completed.Load()
+	if err := pt.progressRepo.SaveGenerationProgress(ctx, specview.GenerationProgress{
+		AnalysisID:        pt.analysisID,
+		CacheHitRate:      pt.cacheHitRate,
+		CompletedFeatures: int(completed),
+		ETASeconds:        pt.etaSeconds(completed),
+		FailedFeatures:    int(pt.failed.Load()),
+		Language:          pt.language,
+		StartedAt:         pt.startTime,
+		Status:            status,
+		TotalFeatures:     int(pt.total),
+	}); err != nil {
+		slog.WarnContext(ctx, "failed to save generation progress (non-critical)",
+			"analysis_id", pt.analysisID,
+			"status", status,
+			"error", err,
 		)
 	}
 }
@@ -895,10 +1936,19 @@ func (uc *GenerateSpecViewUseCase) convertFeatureWithCache(
 	ctx context.Context,
 	task featureTask,
 	lang specview.Language,
+	style string,
+	glossary *specview.Glossary,
 	testIndexMap map[int]specview.TestInfo,
 	testHashMap map[int]string,
 	cachedBehaviors map[string]string,
+	decisions *specview.DecisionLog,
 ) ([]specview.BehaviorSpec, *specview.TokenUsage, int, []specview.BehaviorCacheEntry) {
+	ctx, span := tracer.Start(ctx, "specview.feature", trace.WithAttributes(
+		attribute.String("specview.feature", task.feature.Name),
+		attribute.Int("specview.test_count", len(task.feature.TestIndices)),
+	))
+	defer span.End()
+
 	featureCtx, cancel := context.WithTimeout(ctx, DefaultPhase2FeatureTimeout)
 	defer cancel()
 
@@ -916,6 +1966,7 @@ func (uc *GenerateSpecViewUseCase) convertFeatureWithCache(
 		if hasHash {
 			if cachedDesc, isCached := cachedBehaviors[hexHash]; isCached {
 				// Use cached result
+				decisions.Record(specview.DecisionBehaviorCacheHit, testInfo.Name, map[string]any{"feature": task.feature.Name})
 				cachedResults = append(cachedResults, specview.BehaviorSpec{
 					Confidence:  1.0, // cached results are trusted
 					Description: cachedDesc,
@@ -926,12 +1977,15 @@ func (uc *GenerateSpecViewUseCase) convertFeatureWithCache(
 		}
 
 		// Need AI call
+		decisions.Record(specview.DecisionBehaviorCacheMiss, testInfo.Name, map[string]any{"feature": task.feature.Name})
 		uncachedTests = append(uncachedTests, specview.TestForConversion{
 			Index: idx,
 			Name:  testInfo.Name,
 		})
 	}
 
+	span.SetAttributes(attribute.Int("specview.cache_hits", len(cachedResults)))
+
 	// If all tests are cached, return early (no AI call needed)
 	if len(uncachedTests) == 0 {
 		return cachedResults, nil, 0, nil
@@ -941,7 +1995,9 @@ func (uc *GenerateSpecViewUseCase) convertFeatureWithCache(
 	input := specview.Phase2Input{
 		DomainContext: task.domainContext,
 		FeatureName:   task.feature.Name,
+		Glossary:      glossary,
 		Language:      lang,
+		Style:         style,
 		Tests:         uncachedTests,
 	}
 
@@ -951,6 +2007,12 @@ func (uc *GenerateSpecViewUseCase) convertFeatureWithCache(
 			"feature", task.feature.Name,
 			"error", err,
 		)
+		decisions.Record(specview.DecisionFeatureFallback, task.feature.Name, map[string]any{
+			"error":          err.Error(),
+			"fallback_tests": len(uncachedTests),
+		})
+		span.RecordError(err)
+		span.SetAttributes(attribute.Bool("specview.fallback", true))
 		fallbackBehaviors := uc.generateFallbackBehaviors(uncachedTests)
 		allBehaviors := append(cachedResults, fallbackBehaviors...)
 		// Do not cache fallback behaviors (low quality)
@@ -1015,6 +2077,10 @@ func (uc *GenerateSpecViewUseCase) assembleDocument(
 			var behaviors []specview.Behavior
 
 			if featureBehaviors, ok := behaviorMap[di][fi]; ok {
+				// Cached and AI results arrive in either order; source order is stable.
+				featureBehaviors = slices.SortedStableFunc(slices.Values(featureBehaviors), func(a, b specview.BehaviorSpec) int {
+					return cmp.Compare(a.TestIndex, b.TestIndex)
+				})
 				behaviors = make([]specview.Behavior, len(featureBehaviors))
 				for bi, bs := range featureBehaviors {
 					testCaseID := ""
@@ -1029,6 +2095,9 @@ func (uc *GenerateSpecViewUseCase) assembleDocument(
 						OriginalName: originalName,
 						TestCaseID:   testCaseID,
 					}
+					if testCaseID != "" {
+						behaviors[bi].TestCaseIDs = []string{testCaseID}
+					}
 				}
 			}
 
@@ -1047,18 +2116,47 @@ func (uc *GenerateSpecViewUseCase) assembleDocument(
 			Name:        domainGroup.Name,
 		}
 	}
+	sortDocument(domains)
 
 	return &specview.SpecDocument{
 		AnalysisID:  req.AnalysisID,
 		ContentHash: contentHash,
 		CreatedAt:   time.Now().UTC(),
 		Domains:     domains,
+		DryRun:      req.DryRun,
+		Generator:   uc.generator,
+		JobID:       req.JobID,
 		Language:    req.Language,
 		ModelID:     modelID,
+		Project:     req.Project,
 		UserID:      req.UserID,
 	}
 }
 
+// dedupBehaviors collapses duplicate behaviors within each feature, typically
+// the variants of a parametrized test, and records each merge in the decision log.
+func (uc *GenerateSpecViewUseCase) dedupBehaviors(
+	ctx context.Context,
+	analysisID string,
+	doc *specview.SpecDocument,
+	decisions *specview.DecisionLog,
+) {
+	merges := specview.DedupBehaviors(doc, uc.config.DedupSimilarity)
+	if len(merges) == 0 {
+		return
+	}
+	for _, m := range merges {
+		decisions.Record(specview.DecisionBehaviorMerged, m.Merged.OriginalName, map[string]any{
+			"into":       m.Into.OriginalName,
+			"similarity": m.Merged.Similarity,
+		})
+	}
+	slog.InfoContext(ctx, "duplicate behaviors merged",
+		"analysis_id", analysisID,
+		"merged", len(merges),
+	)
+}
+
 const (
 	// DefaultPhase3Timeout is the timeout for Phase 3 executive summary generation.
 	DefaultPhase3Timeout = 2 * time.Minute
@@ -1106,6 +2204,25 @@ func (uc *GenerateSpecViewUseCase) executePhase3(
 	return usage
 }
 
+// documentContentHash returns the content hash of the document of files in
+// lang, covering every input that changes its content.
+func documentContentHash(
+	files []specview.FileInfo,
+	lang specview.Language,
+	rules *curation.Rules,
+	glossary *specview.Glossary,
+	taxonomy *specview.Taxonomy,
+	promptVersion string,
+) []byte {
+	return specview.PromptContentHash(
+		specview.TaxonomyHash(
+			specview.GlossaryContentHash(specview.CuratedContentHash(specview.GenerateContentHash(files, lang), rules), glossary),
+			taxonomy,
+		),
+		promptVersion,
+	)
+}
+
 func buildTestIndexMap(files []specview.FileInfo) map[int]specview.TestInfo {
 	m := make(map[int]specview.TestInfo)
 	for _, f := range files {
@@ -1140,6 +2257,18 @@ func (uc *GenerateSpecViewUseCase) logExecutionError(
 	)
 }
 
+// recordModelFallback records that the provider answered phase with a
+// fallback model because the configured one was rate limited or unavailable.
+func recordModelFallback(decisions *specview.DecisionLog, phase string, usage *specview.TokenUsage) {
+	if usage == nil || usage.FallbackFrom == "" {
+		return
+	}
+	decisions.Record(specview.DecisionModelFallback, phase, map[string]any{
+		"from_model": usage.FallbackFrom,
+		"to_model":   usage.Model,
+	})
+}
+
 func (uc *GenerateSpecViewUseCase) logTokenUsage(
 	ctx context.Context,
 	analysisID string,
@@ -1157,10 +2286,12 @@ func (uc *GenerateSpecViewUseCase) logTokenUsage(
 	}
 
 	var phase2Prompt, phase2Candidates, phase2Total int32
+	var phase2Model string
 	if phase2Usage != nil {
 		phase2Prompt = phase2Usage.PromptTokens
 		phase2Candidates = phase2Usage.CandidatesTokens
 		phase2Total = phase2Usage.TotalTokens
+		phase2Model = phase2Usage.Model
 	}
 
 	var phase3Prompt, phase3Candidates, phase3Total int32
@@ -1180,6 +2311,7 @@ func (uc *GenerateSpecViewUseCase) logTokenUsage(
 		"phase1_prompt_tokens", phase1Prompt,
 		"phase1_candidates_tokens", phase1Candidates,
 		"phase1_total_tokens", phase1Total,
+		"phase2_model", phase2Model,
 		"phase2_prompt_tokens", phase2Prompt,
 		"phase2_candidates_tokens", phase2Candidates,
 		"phase2_total_tokens", phase2Total,
@@ -6,11 +6,14 @@ import (
 	"encoding/hex"
 	"errors"
 	"log/slog"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/specvital/worker/internal/domain/curation"
 	"github.com/specvital/worker/internal/domain/specview"
 )
 
@@ -652,6 +655,119 @@ func TestGenerateSpecViewUseCase_Execute(t *testing.T) {
 	})
 }
 
+func TestGenerateSpecViewUseCase_PhaseModels(t *testing.T) {
+	newUseCase := func(repo *mockRepository, provider *mockAIProvider) *GenerateSpecViewUseCase {
+		repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		}
+		return NewGenerateSpecViewUseCase(repo, provider, "default-model", WithAllowedModels([]string{"pro", "lite"}))
+	}
+
+	t.Run("rejects models outside the allow-list", func(t *testing.T) {
+		called := false
+		uc := newUseCase(&mockRepository{}, &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				called = true
+				return newPhase1Output(), nil, nil
+			},
+		})
+		req := newValidRequest()
+		req.Phase2ModelID = "ultra"
+
+		if _, err := uc.Execute(context.Background(), req); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Fatalf("expected ErrInvalidInput, got %v", err)
+		}
+		if called {
+			t.Error("no AI call should be made")
+		}
+	})
+
+	t.Run("rejects model IDs too long to store", func(t *testing.T) {
+		called := false
+		uc := newUseCase(&mockRepository{}, &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				called = true
+				return newPhase1Output(), nil, nil
+			},
+		})
+		req := newValidRequest()
+		req.ModelID = strings.Repeat("m", specview.MaxModelIDLength-len(",phase1=pro")+1)
+		req.Phase1ModelID = "pro"
+
+		if _, err := uc.Execute(context.Background(), req); !errors.Is(err, specview.ErrInvalidInput) {
+			t.Fatalf("expected ErrInvalidInput, got %v", err)
+		}
+		if called {
+			t.Error("no AI call should be made")
+		}
+	})
+
+	t.Run("selects each phase's model and keys caches by it", func(t *testing.T) {
+		var (
+			mu               sync.Mutex
+			phase1, phase2   specview.PhaseModels
+			behaviorKeys     []string
+			classificationID string
+			documentLookupID string
+			saved            *specview.SpecDocument
+		)
+		repo := &mockRepository{
+			findCachedBehaviorsFn: func(ctx context.Context, cacheKeyHashes [][]byte) (map[string]string, error) {
+				for _, h := range cacheKeyHashes {
+					behaviorKeys = append(behaviorKeys, hex.EncodeToString(h))
+				}
+				return nil, nil
+			},
+			findClassificationCacheFn: func(ctx context.Context, fileSignature []byte, language specview.Language, modelID string) (*specview.ClassificationCache, error) {
+				classificationID = modelID
+				return nil, nil
+			},
+			findDocumentByContentHashFn: func(ctx context.Context, userID string, contentHash []byte, language specview.Language, modelID string) (*specview.SpecDocument, error) {
+				documentLookupID = modelID
+				return nil, nil
+			},
+			saveDocumentFn: func(ctx context.Context, doc *specview.SpecDocument) error {
+				doc.ID = "doc-001"
+				saved = doc
+				return nil
+			},
+		}
+		uc := newUseCase(repo, &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				phase1 = specview.RequestedPhaseModels(ctx)
+				return newPhase1Output(), nil, nil
+			},
+			convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+				mu.Lock()
+				phase2 = specview.RequestedPhaseModels(ctx)
+				mu.Unlock()
+				return &specview.Phase2Output{}, nil, nil
+			},
+		})
+		req := newValidRequest()
+		req.Phase1ModelID = "pro"
+		req.Phase2ModelID = "lite"
+
+		if _, err := uc.Execute(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := specview.PhaseModels{Phase1: "pro", Phase2: "lite"}
+		if phase1 != want || phase2 != want {
+			t.Errorf("provider saw %+v in phase 1 and %+v in phase 2, want %+v", phase1, phase2, want)
+		}
+		if classificationID != "phase1=pro" {
+			t.Errorf("classification cache model = %q, want phase1=pro", classificationID)
+		}
+		if documentLookupID != "default-model,phase1=pro,phase2=lite" || saved.ModelID != documentLookupID {
+			t.Errorf("document looked up as %q and saved as %q", documentLookupID, saved.ModelID)
+		}
+		wantKey := behaviorCacheKeyHex(specview.HashSHA256, "test/auth_test.go", specview.TestInfo{Name: "TestLogin"}, req.Language, "phase2=lite", "", "", specview.DefaultPromptVersion, "")
+		if !slices.Contains(behaviorKeys, wantKey) {
+			t.Errorf("behavior cache keys %v miss the phase 2 model's key %s", behaviorKeys, wantKey)
+		}
+	})
+}
+
 func TestGenerateSpecViewUseCase_Options(t *testing.T) {
 	t.Run("default options", func(t *testing.T) {
 		uc := NewGenerateSpecViewUseCase(&mockRepository{}, &mockAIProvider{}, "gemini-2.5-flash")
@@ -2307,9 +2423,9 @@ func TestTimeoutConstants(t *testing.T) {
 	})
 
 	t.Run("should have phase sum below River job timeout budget", func(t *testing.T) {
-		// Phase sum must leave buffer within the River job timeout (90min)
-		phaseSum := DefaultPhase1Timeout + DefaultPhase2Timeout + DefaultPhase3Timeout
-		riverJobBudget := 90 * time.Minute
+		// Phase sum must leave buffer within the River job timeout (5h)
+		phaseSum := DefaultPhase1Timeout + DefaultPhase2MaxTimeout + DefaultPhase3Timeout
+		riverJobBudget := 5 * time.Hour
 
 		if phaseSum >= riverJobBudget {
 			t.Errorf("phase sum %v must be less than River job timeout %v", phaseSum, riverJobBudget)
@@ -2322,3 +2438,598 @@ func TestTimeoutConstants(t *testing.T) {
 		}
 	})
 }
+
+type mockCurationRepository struct {
+	mockRepository
+	rules *curation.Rules
+}
+
+func (m *mockCurationRepository) GetCurationRules(ctx context.Context, analysisID string) (*curation.Rules, error) {
+	return m.rules, nil
+}
+
+func TestGenerateSpecViewUseCase_CurationRules(t *testing.T) {
+	rules := &curation.Rules{
+		Domains: curation.DomainRules{Rename: map[string]string{"User Management": "Accounts"}},
+		Generation: curation.GenerationRules{
+			Language: "Japanese",
+			Style:    curation.StyleConcise,
+			Taxonomy: []curation.TaxonomyDomain{{Name: "Accounts", Features: []string{"Signup"}}},
+		},
+		Version: curation.SupportedVersion,
+	}
+
+	run := func(t *testing.T, req specview.SpecViewRequest) (specview.Phase1Input, []specview.Phase2Input, *specview.SpecDocument) {
+		t.Helper()
+		var (
+			phase1Input  specview.Phase1Input
+			phase2Inputs []specview.Phase2Input
+			phase2Mu     sync.Mutex
+			savedDoc     *specview.SpecDocument
+		)
+		repo := &mockCurationRepository{rules: rules}
+		repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		}
+		repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+			savedDoc = doc
+			doc.ID = "doc-001"
+			return nil
+		}
+		aiProvider := &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				phase1Input = input
+				return newPhase1Output(), &specview.TokenUsage{}, nil
+			},
+			convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+				phase2Mu.Lock()
+				phase2Inputs = append(phase2Inputs, input)
+				phase2Mu.Unlock()
+				behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+				for i, test := range input.Tests {
+					behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+				}
+				return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+			},
+		}
+
+		if _, err := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash").Execute(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return phase1Input, phase2Inputs, savedDoc
+	}
+
+	t.Run("applies repository generation parameters", func(t *testing.T) {
+		req := newValidRequest()
+		req.DefaultLanguage = true
+
+		phase1Input, phase2Inputs, doc := run(t, req)
+
+		if phase1Input.Language != "Japanese" || doc.Language != "Japanese" {
+			t.Errorf("expected repository language, got phase1=%s doc=%s", phase1Input.Language, doc.Language)
+		}
+		if len(phase1Input.Taxonomy) != 1 || phase1Input.Taxonomy[0].Name != "Accounts" {
+			t.Errorf("expected taxonomy anchors, got %+v", phase1Input.Taxonomy)
+		}
+		for _, in := range phase2Inputs {
+			if in.Style != string(curation.StyleConcise) {
+				t.Errorf("expected concise style, got %q", in.Style)
+			}
+		}
+		if doc.Domains[0].Name != "Accounts" {
+			t.Errorf("expected renamed domain, sorted first, got %+v", doc.Domains)
+		}
+	})
+
+	t.Run("explicit language wins over repository default", func(t *testing.T) {
+		phase1Input, _, _ := run(t, newValidRequest())
+
+		if phase1Input.Language != "Korean" {
+			t.Errorf("expected requested language, got %s", phase1Input.Language)
+		}
+	})
+}
+
+type mockGlossaryRepository struct {
+	mockRepository
+	glossary *specview.Glossary
+}
+
+func (m *mockGlossaryRepository) GetGlossary(ctx context.Context, userID, analysisID string, lang specview.Language) (*specview.Glossary, error) {
+	return m.glossary, nil
+}
+
+func TestGenerateSpecViewUseCase_Glossary(t *testing.T) {
+	run := func(t *testing.T, glossary *specview.Glossary) ([]specview.Phase2Input, [][]byte, []byte) {
+		t.Helper()
+		var (
+			cacheKeys    [][]byte
+			phase2Inputs []specview.Phase2Input
+			phase2Mu     sync.Mutex
+			contentHash  []byte
+		)
+		repo := &mockGlossaryRepository{glossary: glossary}
+		repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		}
+		repo.saveBehaviorCacheFn = func(ctx context.Context, entries []specview.BehaviorCacheEntry) error {
+			for _, e := range entries {
+				cacheKeys = append(cacheKeys, e.CacheKeyHash)
+			}
+			return nil
+		}
+		repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+			contentHash = doc.ContentHash
+			doc.ID = "doc-001"
+			return nil
+		}
+		aiProvider := &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				return newPhase1Output(), &specview.TokenUsage{}, nil
+			},
+			convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+				phase2Mu.Lock()
+				phase2Inputs = append(phase2Inputs, input)
+				phase2Mu.Unlock()
+				behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+				for i, test := range input.Tests {
+					behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+				}
+				return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+			},
+		}
+
+		if _, err := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash").Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return phase2Inputs, cacheKeys, contentHash
+	}
+
+	glossary := specview.MergeGlossary([]specview.GlossaryEntry{{Preferred: "정산", Term: "settlement"}}, nil)
+	_, plainKeys, plainHash := run(t, nil)
+	phase2Inputs, glossaryKeys, glossaryHash := run(t, glossary)
+
+	for _, in := range phase2Inputs {
+		if in.Glossary != glossary {
+			t.Errorf("expected glossary in Phase 2 input, got %+v", in.Glossary)
+		}
+	}
+	if len(plainKeys) == 0 || len(plainKeys) != len(glossaryKeys) {
+		t.Fatalf("expected cached behaviors in both runs, got %d and %d", len(plainKeys), len(glossaryKeys))
+	}
+	for i := range plainKeys {
+		if bytes.Equal(plainKeys[i], glossaryKeys[i]) {
+			t.Errorf("cache key %d should change with the glossary", i)
+		}
+	}
+	if bytes.Equal(plainHash, glossaryHash) {
+		t.Error("document content hash should change with the glossary")
+	}
+}
+
+type mockTaxonomyRepository struct {
+	mockRepository
+	taxonomy *specview.Taxonomy
+}
+
+func (m *mockTaxonomyRepository) GetTaxonomy(ctx context.Context, analysisID string) (*specview.Taxonomy, error) {
+	return m.taxonomy, nil
+}
+
+func TestGenerateSpecViewUseCase_Taxonomy(t *testing.T) {
+	run := func(t *testing.T, taxonomy *specview.Taxonomy) (specview.Phase1Input, *specview.SpecDocument, []byte) {
+		t.Helper()
+		var (
+			phase1Input   specview.Phase1Input
+			saved         *specview.SpecDocument
+			fileSignature []byte
+		)
+		repo := &mockTaxonomyRepository{taxonomy: taxonomy}
+		repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		}
+		repo.saveClassificationCacheFn = func(ctx context.Context, cache *specview.ClassificationCache) error {
+			fileSignature = cache.FileSignature
+			return nil
+		}
+		repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+			saved = doc
+			doc.ID = "doc-001"
+			return nil
+		}
+		aiProvider := &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				phase1Input = input
+				return newPhase1Output(), &specview.TokenUsage{}, nil
+			},
+			convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+				behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+				for i, test := range input.Tests {
+					behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+				}
+				return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+			},
+		}
+
+		if _, err := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash").Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return phase1Input, saved, fileSignature
+	}
+
+	taxonomy := &specview.Taxonomy{
+		Domains: []specview.TaxonomyDomain{{Aliases: []string{"Auth"}, Description: "Sign-in", Name: "Authentication"}},
+		Mode:    specview.TaxonomyReject,
+	}
+	plainInput, plainDoc, plainSignature := run(t, nil)
+	input, doc, signature := run(t, taxonomy)
+
+	if plainInput.Constrained || !input.Constrained {
+		t.Errorf("expected only the taxonomy run constrained, got %v and %v", plainInput.Constrained, input.Constrained)
+	}
+	if len(input.Taxonomy) != 1 || input.Taxonomy[0].Name != "Authentication" {
+		t.Errorf("expected the taxonomy as Phase 1 anchors, got %+v", input.Taxonomy)
+	}
+
+	var names []string
+	for _, d := range doc.Domains {
+		names = append(names, d.Name)
+	}
+	if !slices.Equal(names, []string{"Authentication", UncategorizedName}) {
+		t.Errorf("expected User Management rejected into Uncategorized, got %v", names)
+	}
+	var remapped []specview.DecisionEvent
+	for _, e := range doc.Decisions {
+		if e.Kind == specview.DecisionTaxonomyRemapped {
+			remapped = append(remapped, e)
+		}
+	}
+	if len(remapped) != 1 || remapped[0].Subject != "User Management" || remapped[0].Detail["mode"] != "reject" {
+		t.Errorf("expected 1 taxonomy_remapped decision, got %+v", remapped)
+	}
+
+	if bytes.Equal(plainSignature, signature) {
+		t.Error("classification signature should change with the taxonomy")
+	}
+	if bytes.Equal(plainDoc.ContentHash, doc.ContentHash) {
+		t.Error("document content hash should change with the taxonomy")
+	}
+}
+
+type mockQuotaRepository struct {
+	mockRepository
+	limit int64
+	used  int64
+}
+
+func (m *mockQuotaRepository) RecordUsageEvent(ctx context.Context, userID string, documentID string, quotaAmount int) error {
+	m.used += int64(quotaAmount)
+	return nil
+}
+
+func (m *mockQuotaRepository) GetQuotaStatus(ctx context.Context, userID string) (*specview.QuotaStatus, error) {
+	limit := m.limit
+	return &specview.QuotaStatus{Limit: &limit, Used: m.used}, nil
+}
+
+type mockUsageNotifier struct {
+	warnings []specview.UsageWarning
+}
+
+func (m *mockUsageNotifier) NotifyUsageWarning(ctx context.Context, warning specview.UsageWarning) error {
+	m.warnings = append(m.warnings, warning)
+	return nil
+}
+
+func TestGenerateSpecViewUseCase_QuotaWarnings(t *testing.T) {
+	run := func(t *testing.T, used int64) (*specview.SpecViewResult, *mockUsageNotifier) {
+		t.Helper()
+		repo := &mockQuotaRepository{limit: 10, used: used}
+		repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		}
+		repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+			doc.ID = "doc-001"
+			return nil
+		}
+		aiProvider := &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				return newPhase1Output(), &specview.TokenUsage{}, nil
+			},
+			convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+				behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+				for i, test := range input.Tests {
+					behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+				}
+				return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+			},
+		}
+		notifier := &mockUsageNotifier{}
+
+		uc := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash", WithQuotaWarnings(notifier, []int{50, 0, 150}))
+		result, err := uc.Execute(context.Background(), newValidRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result, notifier
+	}
+
+	t.Run("warns when the spend crosses a threshold", func(t *testing.T) {
+		result, notifier := run(t, 2)
+
+		if len(notifier.warnings) != 1 {
+			t.Fatalf("expected one warning, got %+v", notifier.warnings)
+		}
+		warning := notifier.warnings[0]
+		if warning.Threshold != 50 || warning.Limit != 10 || warning.DocumentID != "doc-001" {
+			t.Errorf("unexpected warning: %+v", warning)
+		}
+		if result.RemainingQuota == nil || *result.RemainingQuota != warning.Remaining {
+			t.Errorf("expected remaining quota %d in result, got %v", warning.Remaining, result.RemainingQuota)
+		}
+	})
+
+	t.Run("does not warn past the threshold", func(t *testing.T) {
+		result, notifier := run(t, 6)
+
+		if len(notifier.warnings) != 0 {
+			t.Errorf("expected no warning, got %+v", notifier.warnings)
+		}
+		if result.RemainingQuota == nil {
+			t.Error("expected remaining quota in result")
+		}
+	})
+}
+
+type mockProgressRepository struct {
+	mockRepository
+	mu        sync.Mutex
+	snapshots []specview.GenerationProgress
+}
+
+func (m *mockProgressRepository) SaveGenerationProgress(ctx context.Context, progress specview.GenerationProgress) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshots = append(m.snapshots, progress)
+	return nil
+}
+
+func TestGenerateSpecViewUseCase_GenerationProgress(t *testing.T) {
+	repo := &mockProgressRepository{}
+	repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+		return newTestFiles(), nil
+	}
+	repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+		doc.ID = "doc-001"
+		return nil
+	}
+	aiProvider := &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			return newPhase1Output(), &specview.TokenUsage{}, nil
+		},
+		convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+			behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+			for i, test := range input.Tests {
+				behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+			}
+			return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+		},
+	}
+
+	req := newValidRequest()
+	if _, err := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash").Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(repo.snapshots) < 2 {
+		t.Fatalf("expected start and completion snapshots, got %d", len(repo.snapshots))
+	}
+	first, last := repo.snapshots[0], repo.snapshots[len(repo.snapshots)-1]
+	if first.Status != specview.GenerationRunning || first.CompletedFeatures != 0 {
+		t.Errorf("unexpected first snapshot: %+v", first)
+	}
+	if last.Status != specview.GenerationCompleted || last.CompletedFeatures != last.TotalFeatures {
+		t.Errorf("unexpected last snapshot: %+v", last)
+	}
+	if last.AnalysisID != req.AnalysisID || last.Language != req.Language || last.StartedAt.IsZero() {
+		t.Errorf("expected snapshot keyed by analysis and language, got %+v", last)
+	}
+}
+
+func TestExecutePhase2_ProgressExtendsTimeout(t *testing.T) {
+	files := []specview.FileInfo{
+		{
+			Path:      "test/auth_test.go",
+			Framework: "go",
+			Tests: []specview.TestInfo{
+				{Index: 0, Name: "TestLogin"},
+				{Index: 1, Name: "TestLogout"},
+				{Index: 2, Name: "TestRegister"},
+				{Index: 3, Name: "TestReset"},
+			},
+		},
+	}
+	phase1 := &specview.Phase1Output{
+		Domains: []specview.DomainGroup{
+			{
+				Name:       "Auth",
+				Confidence: 0.9,
+				Features: []specview.FeatureGroup{
+					{Name: "Login", Confidence: 0.9, TestIndices: []int{0}},
+					{Name: "Logout", Confidence: 0.9, TestIndices: []int{1}},
+					{Name: "Register", Confidence: 0.9, TestIndices: []int{2}},
+					{Name: "Reset", Confidence: 0.9, TestIndices: []int{3}},
+				},
+			},
+		},
+	}
+	repo := &mockRepository{
+		getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return files, nil
+		},
+		findDocumentByContentHashFn: func(ctx context.Context, userID string, contentHash []byte, language specview.Language, modelID string) (*specview.SpecDocument, error) {
+			return nil, nil
+		},
+	}
+
+	newProvider := func(featureDelay time.Duration, stallAfter int32) *mockAIProvider {
+		var calls atomic.Int32
+		return &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				return phase1, nil, nil
+			},
+			convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+				if stallAfter > 0 && calls.Add(1) > stallAfter {
+					<-ctx.Done()
+					return nil, nil, ctx.Err()
+				}
+				select {
+				case <-time.After(featureDelay):
+				case <-ctx.Done():
+					return nil, nil, ctx.Err()
+				}
+				behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+				for i, test := range input.Tests {
+					behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: "Converted: " + test.Name, Confidence: 0.9}
+				}
+				return &specview.Phase2Output{Behaviors: behaviors}, nil, nil
+			},
+		}
+	}
+
+	t.Run("should finish runs longer than the timeout while features complete", func(t *testing.T) {
+		// 4 sequential features x 60ms = 240ms, well past the 150ms window
+		uc := NewGenerateSpecViewUseCase(repo, newProvider(60*time.Millisecond, 0), "gemini-2.5-flash",
+			WithPhase2Timeout(150*time.Millisecond),
+			WithPhase2Concurrency(1),
+		)
+
+		if _, err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("expected success, got %v", err)
+		}
+	})
+
+	t.Run("should fail with ErrPhase2Stalled when progress stops", func(t *testing.T) {
+		uc := NewGenerateSpecViewUseCase(repo, newProvider(10*time.Millisecond, 1), "gemini-2.5-flash",
+			WithPhase2Timeout(100*time.Millisecond),
+			WithPhase2Concurrency(1),
+		)
+
+		_, err := uc.Execute(context.Background(), newValidRequest())
+		if !errors.Is(err, ErrPhase2Stalled) {
+			t.Errorf("expected ErrPhase2Stalled, got %v", err)
+		}
+	})
+
+	t.Run("should fail with ErrPhase2MaxDuration at the hard cap", func(t *testing.T) {
+		uc := NewGenerateSpecViewUseCase(repo, newProvider(60*time.Millisecond, 0), "gemini-2.5-flash",
+			WithPhase2Timeout(time.Second),
+			WithPhase2MaxTimeout(100*time.Millisecond),
+			WithPhase2Concurrency(1),
+		)
+
+		_, err := uc.Execute(context.Background(), newValidRequest())
+		if !errors.Is(err, ErrPhase2MaxDuration) {
+			t.Errorf("expected ErrPhase2MaxDuration, got %v", err)
+		}
+	})
+}
+
+func TestGenerateSpecViewUseCase_BehaviorDedup(t *testing.T) {
+	var saved *specview.SpecDocument
+	repo := &mockRepository{
+		getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		},
+		saveDocumentFn: func(ctx context.Context, doc *specview.SpecDocument) error {
+			saved = doc
+			doc.ID = "doc-001"
+			return nil
+		},
+	}
+	aiProvider := &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			return newPhase1Output(), &specview.TokenUsage{}, nil
+		},
+		convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+			behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+			for i, test := range input.Tests {
+				description := test.Name
+				if input.FeatureName == "User Creation" {
+					description = "Manages a user account"
+				}
+				behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: description, Confidence: 0.9}
+			}
+			return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+		},
+	}
+
+	uc := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash")
+	if _, err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	behaviors := saved.Domains[1].Features[0].Behaviors
+	if len(behaviors) != 1 {
+		t.Fatalf("expected duplicates merged into 1 behavior, got %d", len(behaviors))
+	}
+	if merged := behaviors[0].Merged; len(merged) != 1 || merged[0].TestCaseID != "tc-004" {
+		t.Errorf("unexpected merged test cases: %+v", merged)
+	}
+
+	var events int
+	for _, d := range saved.Decisions {
+		if d.Kind == specview.DecisionBehaviorMerged && d.Subject == "TestDeleteUser" {
+			events++
+		}
+	}
+	if events != 1 {
+		t.Errorf("expected 1 behavior_merged decision, got %d", events)
+	}
+}
+
+func TestGenerateSpecViewUseCase_PromptExperiment(t *testing.T) {
+	run := func(opts ...Option) (*specview.SpecDocument, string) {
+		var savedDoc *specview.SpecDocument
+		var phase1Version string
+		repo := &mockCurationRepository{}
+		repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		}
+		repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+			savedDoc = doc
+			doc.ID = "doc-001"
+			return nil
+		}
+		aiProvider := &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				phase1Version = specview.PromptVersion(ctx)
+				return newPhase1Output(), &specview.TokenUsage{}, nil
+			},
+			convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+				behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+				for i, test := range input.Tests {
+					behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+				}
+				return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+			},
+		}
+		if _, err := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash", opts...).Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return savedDoc, phase1Version
+	}
+
+	def, defVersion := run()
+	if def.PromptVersion != specview.DefaultPromptVersion || defVersion != specview.DefaultPromptVersion {
+		t.Errorf("expected the default version, got document %q and provider %q", def.PromptVersion, defVersion)
+	}
+
+	candidate, candidateVersion := run(WithPromptExperiment("v2", 100))
+	if candidate.PromptVersion != "v2" || candidateVersion != "v2" {
+		t.Errorf("expected the candidate version, got document %q and provider %q", candidate.PromptVersion, candidateVersion)
+	}
+	if bytes.Equal(candidate.ContentHash, def.ContentHash) {
+		t.Error("expected documents of different prompt versions to have different content hashes")
+	}
+}
@@ -0,0 +1,63 @@
+package specview
+
+import (
+	"context"
+	"errors"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// HeuristicModelID labels heuristic documents and the cache entries they
+// fill, so neither is ever served to an AI generation.
+const HeuristicModelID = "heuristic"
+
+// withoutAI returns a copy of uc generating with provider under modelID, for
+// providers deriving documents without AI calls. Such a generation spends
+// nothing on the user's behalf: the AI budget is not checked, Phase 2 runs
+// in-process, no names are embedded for the semantic cache, and no document
+// is shared with or from other users. Its documents are marked heuristic and
+// never fall back again.
+func (uc *GenerateSpecViewUseCase) withoutAI(provider specview.AIProvider, modelID string) *GenerateSpecViewUseCase {
+	copied := *uc
+	copied.aiProvider = provider
+	copied.budgetRepo = nil
+	copied.claimRepo = nil
+	copied.config.FanOut = nil
+	copied.defaultModelID = modelID
+	copied.generator = specview.GeneratorHeuristic
+	copied.heuristic = nil
+	copied.semanticCache = nil
+	copied.sharingRepo = nil
+	copied.translator = nil
+	if translator, ok := provider.(specview.Translator); ok {
+		copied.translator = translator
+	}
+	return &copied
+}
+
+// withoutModels clears the models req selects, which generations without AI
+// calls have no use for.
+func withoutModels(req specview.SpecViewRequest) specview.SpecViewRequest {
+	req.ModelID = ""
+	req.Phase1ModelID, req.Phase2ModelID, req.Phase3ModelID = "", "", ""
+	return req
+}
+
+// fallsBackToHeuristic reports whether a failed AI generation is retried
+// heuristically: the fallback mode is on, an AI phase failed and the AI
+// provider is unavailable, either by the error or by the provider's own
+// report, as when a Phase 2 outage fails features one by one. Cancelled jobs
+// do not fall back.
+func (uc *GenerateSpecViewUseCase) fallsBackToHeuristic(ctx context.Context, err error) bool {
+	if uc.heuristic == nil || uc.config.GeneratorMode != specview.GeneratorModeFallback || ctx.Err() != nil {
+		return false
+	}
+	if !errors.Is(err, ErrAIProcessingFailed) || errors.Is(err, specview.ErrGenerationCancelled) {
+		return false
+	}
+	if errors.Is(err, specview.ErrAIUnavailable) {
+		return true
+	}
+	reporter, ok := uc.aiProvider.(specview.AvailabilityReporter)
+	return ok && !reporter.Available()
+}
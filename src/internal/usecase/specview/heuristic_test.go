@@ -0,0 +1,139 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type unavailableAIProvider struct {
+	*mockAIProvider
+}
+
+func (p unavailableAIProvider) Available() bool {
+	return false
+}
+
+func newHeuristicMock() *mockAIProvider {
+	return &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			return newPhase1Output(), nil, nil
+		},
+		convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+			behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+			for i, test := range input.Tests {
+				behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.6}
+			}
+			return &specview.Phase2Output{Behaviors: behaviors}, nil, nil
+		},
+	}
+}
+
+func TestGenerateSpecViewUseCase_Heuristic(t *testing.T) {
+	newRepo := func(saved **specview.SpecDocument, usageEvents *int) *mockRepository {
+		return &mockRepository{
+			getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+				return newTestFiles(), nil
+			},
+			recordUsageEventFn: func(ctx context.Context, userID string, documentID string, quotaAmount int) error {
+				*usageEvents++
+				return nil
+			},
+			saveDocumentFn: func(ctx context.Context, doc *specview.SpecDocument) error {
+				*saved = doc
+				doc.ID = "doc-001"
+				return nil
+			},
+		}
+	}
+	outage := &mockAIProvider{
+		classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+			return nil, nil, fmt.Errorf("%w: circuit breaker open", specview.ErrAIUnavailable)
+		},
+	}
+
+	t.Run("falls back while the AI is unavailable", func(t *testing.T) {
+		var saved *specview.SpecDocument
+		var usageEvents int
+		uc := NewGenerateSpecViewUseCase(newRepo(&saved, &usageEvents), outage, "gemini-2.5-flash",
+			WithHeuristic(newHeuristicMock(), specview.GeneratorModeFallback))
+
+		result, err := uc.Execute(context.Background(), newValidRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Generator != specview.GeneratorHeuristic || saved.Generator != specview.GeneratorHeuristic {
+			t.Errorf("expected a heuristic document, got result %q and document %q", result.Generator, saved.Generator)
+		}
+		if saved.ModelID != HeuristicModelID {
+			t.Errorf("expected model %s, got %s", HeuristicModelID, saved.ModelID)
+		}
+		if len(saved.Decisions) == 0 || saved.Decisions[0].Kind != specview.DecisionHeuristicFallback {
+			t.Errorf("expected the fallback recorded first, got %+v", saved.Decisions)
+		}
+		if usageEvents != 0 {
+			t.Errorf("expected no usage event, got %d", usageEvents)
+		}
+	})
+
+	t.Run("falls back when the provider reports an outage", func(t *testing.T) {
+		var saved *specview.SpecDocument
+		var usageEvents int
+		failing := unavailableAIProvider{&mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				return nil, nil, errors.New("connection refused")
+			},
+		}}
+		uc := NewGenerateSpecViewUseCase(newRepo(&saved, &usageEvents), failing, "gemini-2.5-flash",
+			WithHeuristic(newHeuristicMock(), specview.GeneratorModeFallback))
+
+		result, err := uc.Execute(context.Background(), newValidRequest())
+		if err != nil || result.Generator != specview.GeneratorHeuristic {
+			t.Fatalf("expected a heuristic result, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("fails without the fallback mode", func(t *testing.T) {
+		var saved *specview.SpecDocument
+		var usageEvents int
+		uc := NewGenerateSpecViewUseCase(newRepo(&saved, &usageEvents), outage, "gemini-2.5-flash",
+			WithHeuristic(newHeuristicMock(), specview.GeneratorModeAI))
+
+		_, err := uc.Execute(context.Background(), newValidRequest())
+		if !errors.Is(err, specview.ErrAIUnavailable) {
+			t.Errorf("expected ErrAIUnavailable, got %v", err)
+		}
+		if saved != nil {
+			t.Error("expected no document saved")
+		}
+	})
+
+	t.Run("heuristic mode never calls the AI", func(t *testing.T) {
+		var saved *specview.SpecDocument
+		var usageEvents int
+		ai := &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				t.Error("the AI provider should not be called in heuristic mode")
+				return nil, nil, errors.New("unexpected call")
+			},
+		}
+		uc := NewGenerateSpecViewUseCase(newRepo(&saved, &usageEvents), ai, "gemini-2.5-flash",
+			WithHeuristic(newHeuristicMock(), specview.GeneratorModeHeuristic))
+
+		result, err := uc.Execute(context.Background(), newValidRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Generator != specview.GeneratorHeuristic || saved.ModelID != HeuristicModelID {
+			t.Errorf("expected a heuristic document, got %+v", saved)
+		}
+		for _, d := range saved.Decisions {
+			if d.Kind == specview.DecisionHeuristicFallback {
+				t.Error("expected no fallback decision in heuristic mode")
+			}
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package specview
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// IndexSpecDocumentUseCase pushes a saved spec document into the search backend.
+// Kept separate from generation so indexing failures never cost an AI retry.
+type IndexSpecDocumentUseCase struct {
+	indexer specview.SearchIndexer
+	source  specview.SearchSourceRepository
+}
+
+// NewIndexSpecDocumentUseCase creates a new IndexSpecDocumentUseCase.
+func NewIndexSpecDocumentUseCase(
+	source specview.SearchSourceRepository,
+	indexer specview.SearchIndexer,
+) *IndexSpecDocumentUseCase {
+	return &IndexSpecDocumentUseCase{
+		indexer: indexer,
+		source:  source,
+	}
+}
+
+// Execute indexes all behaviors of the document and returns the entry count.
+func (uc *IndexSpecDocumentUseCase) Execute(ctx context.Context, documentID string) (int, error) {
+	if documentID == "" {
+		return 0, fmt.Errorf("%w: document ID is required", specview.ErrInvalidInput)
+	}
+
+	entries, err := uc.source.GetSearchIndexEntries(ctx, documentID)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrIndexFailed, err)
+	}
+
+	if err := uc.indexer.ReplaceDocumentEntries(ctx, documentID, entries); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrIndexFailed, err)
+	}
+
+	return len(entries), nil
+}
@@ -0,0 +1,97 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockSearchSource struct {
+	entries []specview.SearchIndexEntry
+	err     error
+}
+
+func (m *mockSearchSource) GetSearchIndexEntries(_ context.Context, _ string) ([]specview.SearchIndexEntry, error) {
+	return m.entries, m.err
+}
+
+type mockSearchIndexer struct {
+	calls   int
+	entries []specview.SearchIndexEntry
+	err     error
+}
+
+func (m *mockSearchIndexer) ReplaceDocumentEntries(_ context.Context, _ string, entries []specview.SearchIndexEntry) error {
+	m.calls++
+	m.entries = entries
+	return m.err
+}
+
+func TestIndexSpecDocumentUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("indexes all entries", func(t *testing.T) {
+		source := &mockSearchSource{entries: []specview.SearchIndexEntry{
+			{BehaviorID: "b1", DocumentID: "doc-1"},
+			{BehaviorID: "b2", DocumentID: "doc-1"},
+		}}
+		indexer := &mockSearchIndexer{}
+
+		count, err := NewIndexSpecDocumentUseCase(source, indexer).Execute(ctx, "doc-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 2 {
+			t.Errorf("expected 2 indexed entries, got %d", count)
+		}
+		if len(indexer.entries) != 2 {
+			t.Errorf("expected indexer to receive 2 entries, got %d", len(indexer.entries))
+		}
+	})
+
+	t.Run("replaces with empty set when document has no behaviors", func(t *testing.T) {
+		indexer := &mockSearchIndexer{}
+
+		count, err := NewIndexSpecDocumentUseCase(&mockSearchSource{}, indexer).Execute(ctx, "doc-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("expected 0 indexed entries, got %d", count)
+		}
+		if indexer.calls != 1 {
+			t.Errorf("expected stale entries to be cleared, got %d calls", indexer.calls)
+		}
+	})
+
+	t.Run("rejects empty document ID", func(t *testing.T) {
+		_, err := NewIndexSpecDocumentUseCase(&mockSearchSource{}, &mockSearchIndexer{}).Execute(ctx, "")
+		if !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("wraps source errors", func(t *testing.T) {
+		source := &mockSearchSource{err: errors.New("db down")}
+		indexer := &mockSearchIndexer{}
+
+		_, err := NewIndexSpecDocumentUseCase(source, indexer).Execute(ctx, "doc-1")
+		if !errors.Is(err, ErrIndexFailed) {
+			t.Errorf("expected ErrIndexFailed, got %v", err)
+		}
+		if indexer.calls != 0 {
+			t.Error("indexer should not be called when source fails")
+		}
+	})
+
+	t.Run("wraps indexer errors", func(t *testing.T) {
+		indexer := &mockSearchIndexer{err: errors.New("copy failed")}
+
+		_, err := NewIndexSpecDocumentUseCase(&mockSearchSource{}, indexer).Execute(ctx, "doc-1")
+		if !errors.Is(err, ErrIndexFailed) {
+			t.Errorf("expected ErrIndexFailed, got %v", err)
+		}
+	})
+}
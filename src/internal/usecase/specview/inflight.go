@@ -0,0 +1,148 @@
+package specview
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+const (
+	DefaultClaimTTL          = 2 * time.Minute // generation claim lifetime, extended every third of it while the job runs
+	DefaultClaimPollInterval = 5 * time.Second // how often a job waiting for a generation claim tries it again
+)
+
+// generationClaim is the claim the running job holds on its generation key.
+// A nil claim holds nothing, so callers need no checks when deduplication is
+// off or the job generates without a claim.
+type generationClaim struct {
+	claims specview.GenerationClaims
+	done   chan struct{}
+	jobID  int64
+	key    []byte
+	stop   context.CancelFunc
+}
+
+// awaitGeneration claims the generation key of the job before its AI calls.
+// While another job holds the key, the job waits up to InFlightWait for it to
+// finish and reports waited, so the caller looks for that job's results
+// before generating from the caches it filled. A job that waited out the
+// limit, or whose claim failed, generates without a claim: deduplication is
+// non-critical. The only error is ctx's.
+func (uc *GenerateSpecViewUseCase) awaitGeneration(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	contentHash []byte,
+	modelID string,
+) (claim *generationClaim, waited bool, err error) {
+	if uc.claimRepo == nil || req.JobID == 0 {
+		return nil, false, nil
+	}
+
+	key := specview.GenerationClaimKey(contentHash, req.Language, modelID, specview.CacheScope(ctx))
+	deadline := time.Now().Add(uc.config.InFlightWait)
+	for {
+		claimed, err := uc.claimRepo.ClaimGeneration(ctx, key, req.JobID, time.Now().Add(uc.config.ClaimTTL))
+		if err != nil {
+			slog.WarnContext(ctx, "failed to claim generation (non-critical)",
+				"analysis_id", req.AnalysisID,
+				"job_id", req.JobID,
+				"error", err,
+			)
+			return nil, waited, ctx.Err()
+		}
+		if claimed {
+			return uc.holdGenerationClaim(ctx, key, req), waited, nil
+		}
+
+		if !waited {
+			slog.InfoContext(ctx, "waiting for in-flight generation of the same content",
+				"analysis_id", req.AnalysisID,
+				"job_id", req.JobID,
+				"max_wait", uc.config.InFlightWait,
+			)
+			waited = true
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			slog.WarnContext(ctx, "in-flight generation still running, generating without waiting further",
+				"analysis_id", req.AnalysisID,
+				"job_id", req.JobID,
+			)
+			return nil, waited, nil
+		}
+
+		timer := time.NewTimer(min(uc.config.ClaimPollInterval, remaining))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, waited, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// holdGenerationClaim extends the claim until it is released, so a job that
+// dies leaves a claim that expires soon after.
+func (uc *GenerateSpecViewUseCase) holdGenerationClaim(
+	ctx context.Context,
+	key []byte,
+	req specview.SpecViewRequest,
+) *generationClaim {
+	holdCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	c := &generationClaim{
+		claims: uc.claimRepo,
+		done:   make(chan struct{}),
+		jobID:  req.JobID,
+		key:    key,
+		stop:   stop,
+	}
+
+	ttl := uc.config.ClaimTTL
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-holdCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			held, err := c.claims.ExtendGenerationClaim(holdCtx, key, req.JobID, time.Now().Add(ttl))
+			if err != nil {
+				slog.WarnContext(holdCtx, "failed to extend generation claim (non-critical)",
+					"analysis_id", req.AnalysisID,
+					"job_id", req.JobID,
+					"error", err,
+				)
+				continue
+			}
+			if !held {
+				slog.WarnContext(holdCtx, "generation claim lost to another job",
+					"analysis_id", req.AnalysisID,
+					"job_id", req.JobID,
+				)
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// release stops extending the claim and removes it, letting waiting jobs in.
+func (c *generationClaim) release(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	c.stop()
+	<-c.done
+
+	if err := c.claims.ReleaseGeneration(context.WithoutCancel(ctx), c.key, c.jobID); err != nil {
+		slog.WarnContext(ctx, "failed to release generation claim (non-critical)",
+			"job_id", c.jobID,
+			"error", err,
+		)
+	}
+}
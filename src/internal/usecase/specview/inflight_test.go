@@ -0,0 +1,218 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockClaimRepository struct {
+	mockRepository
+	claimErr    error
+	mu          sync.Mutex
+	holders     map[string]int64
+	otherHolder int64 // when set, holds every key not claimed yet
+	refused     atomic.Int32
+	released    []int64
+}
+
+func (m *mockClaimRepository) ClaimGeneration(ctx context.Context, key []byte, jobID int64, expiresAt time.Time) (bool, error) {
+	if m.claimErr != nil {
+		return false, m.claimErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	holder, ok := m.holders[string(key)]
+	if !ok && m.otherHolder != 0 {
+		holder, ok = m.otherHolder, true
+	}
+	if ok && holder != jobID {
+		m.refused.Add(1)
+		return false, nil
+	}
+	m.holders[string(key)] = jobID
+	return true, nil
+}
+
+func (m *mockClaimRepository) ExtendGenerationClaim(ctx context.Context, key []byte, jobID int64, expiresAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.holders[string(key)] == jobID, nil
+}
+
+func (m *mockClaimRepository) ReleaseGeneration(ctx context.Context, key []byte, jobID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.holders[string(key)] == jobID {
+		delete(m.holders, string(key))
+	}
+	m.released = append(m.released, jobID)
+	return nil
+}
+
+func newClaimTestUseCase(t *testing.T, convertCalls *atomic.Int32, classify func()) (*GenerateSpecViewUseCase, *mockClaimRepository) {
+	t.Helper()
+	cachingRepo, _ := newCachingRepository()
+	repo := &mockClaimRepository{mockRepository: *cachingRepo, holders: make(map[string]int64)}
+	aiProvider := newFanOutAIProvider(convertCalls)
+	classifyDomains := aiProvider.classifyDomainsFn
+	aiProvider.classifyDomainsFn = func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+		classify()
+		return classifyDomains(ctx, input)
+	}
+	uc := NewGenerateSpecViewUseCase(repo, aiProvider, "model", WithInFlightDedup(5*time.Second))
+	uc.config.ClaimPollInterval = 10 * time.Millisecond
+	return uc, repo
+}
+
+func TestGenerateSpecViewUseCase_InFlightDedup(t *testing.T) {
+	t.Run("second job waits and reuses the first job's behaviors", func(t *testing.T) {
+		var convertCalls atomic.Int32
+		firstClassifying := make(chan struct{})
+		unblock := make(chan struct{})
+		var classifyCalls atomic.Int32
+		uc, repo := newClaimTestUseCase(t, &convertCalls, func() {
+			if classifyCalls.Add(1) == 1 {
+				close(firstClassifying)
+				<-unblock
+			}
+		})
+
+		first := newValidRequest()
+		first.JobID = 1
+		second := newValidRequest()
+		second.JobID = 2
+		second.UserID = "test-user-002"
+
+		var wg sync.WaitGroup
+		var firstErr error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, firstErr = uc.Execute(context.Background(), first)
+		}()
+		<-firstClassifying
+
+		var secondResult *specview.SpecViewResult
+		var secondErr error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			secondResult, secondErr = uc.Execute(context.Background(), second)
+		}()
+		for repo.refused.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		conversionsBefore := convertCalls.Load()
+		close(unblock)
+		wg.Wait()
+
+		if firstErr != nil || secondErr != nil {
+			t.Fatalf("unexpected errors: %v, %v", firstErr, secondErr)
+		}
+		if conversionsBefore != 0 {
+			t.Errorf("expected the second job to wait before Phase 2, got %d conversions", conversionsBefore)
+		}
+		if got := convertCalls.Load(); got != 3 {
+			t.Errorf("expected only the first job's 3 conversions, got %d", got)
+		}
+		if secondResult.BehaviorCacheStats == nil || secondResult.BehaviorCacheStats.GeneratedBehaviors != 0 {
+			t.Errorf("expected the second job to hit the cache for every test, got %+v", secondResult.BehaviorCacheStats)
+		}
+		if len(repo.holders) != 0 || len(repo.released) != 2 {
+			t.Errorf("expected both claims released, holders %v, released %v", repo.holders, repo.released)
+		}
+	})
+
+	t.Run("gives up waiting after the limit", func(t *testing.T) {
+		var convertCalls atomic.Int32
+		uc, repo := newClaimTestUseCase(t, &convertCalls, func() {})
+		uc.config.InFlightWait = 30 * time.Millisecond
+		repo.otherHolder = 99
+
+		req := newValidRequest()
+		req.JobID = 2
+		start := time.Now()
+		if _, err := uc.Execute(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if waited := time.Since(start); waited < 30*time.Millisecond {
+			t.Errorf("expected the job to wait for the limit, waited %v", waited)
+		}
+		if got := convertCalls.Load(); got != 3 {
+			t.Errorf("expected the job to generate after waiting, got %d conversions", got)
+		}
+		if len(repo.released) != 0 {
+			t.Errorf("a job without a claim should release nothing, got %v", repo.released)
+		}
+	})
+
+	t.Run("claim failure is non-critical", func(t *testing.T) {
+		var convertCalls atomic.Int32
+		uc, repo := newClaimTestUseCase(t, &convertCalls, func() {})
+		repo.claimErr = errors.New("connection refused")
+
+		req := newValidRequest()
+		req.JobID = 2
+		if _, err := uc.Execute(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := convertCalls.Load(); got != 3 {
+			t.Errorf("expected the job to generate, got %d conversions", got)
+		}
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		var convertCalls atomic.Int32
+		uc, repo := newClaimTestUseCase(t, &convertCalls, func() {})
+		repo.otherHolder = 99
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			for repo.refused.Load() == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+		}()
+		req := newValidRequest()
+		req.JobID = 2
+		if _, err := uc.Execute(ctx, req); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+		if got := convertCalls.Load(); got != 0 {
+			t.Errorf("expected no conversions, got %d", got)
+		}
+	})
+
+	t.Run("jobs without an ID and forced regenerations do not claim", func(t *testing.T) {
+		var convertCalls atomic.Int32
+		uc, repo := newClaimTestUseCase(t, &convertCalls, func() {})
+		repo.otherHolder = 99
+
+		if _, err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		forced := newValidRequest()
+		forced.ForceRegenerate = true
+		forced.JobID = 2
+		if _, err := uc.Execute(context.Background(), forced); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := repo.refused.Load(); got != 0 {
+			t.Errorf("expected no claims, got %d refused", got)
+		}
+	})
+
+	t.Run("disabled without a wait", func(t *testing.T) {
+		repo := &mockClaimRepository{holders: make(map[string]int64)}
+		uc := NewGenerateSpecViewUseCase(repo, &mockAIProvider{}, "model", WithInFlightDedup(0))
+		if uc.claimRepo != nil {
+			t.Error("expected in-flight deduplication to be disabled")
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package specview
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// sortDocument puts domains and features in a deterministic order, independent
+// of the order the AI returned them in: alphabetical by name, case-insensitive,
+// with Uncategorized last.
+func sortDocument(domains []specview.Domain) {
+	slices.SortStableFunc(domains, func(a, b specview.Domain) int {
+		return compareGroupNames(a.Name, b.Name)
+	})
+	for i := range domains {
+		slices.SortStableFunc(domains[i].Features, func(a, b specview.Feature) int {
+			return compareGroupNames(a.Name, b.Name)
+		})
+	}
+}
+
+func compareGroupNames(a, b string) int {
+	if (a == UncategorizedName) != (b == UncategorizedName) {
+		if a == UncategorizedName {
+			return 1
+		}
+		return -1
+	}
+	return cmp.Or(
+		strings.Compare(strings.ToLower(a), strings.ToLower(b)),
+		strings.Compare(a, b),
+	)
+}
+
+// assignSlugs gives the document's domains and features the slugs of the
+// user's previous document for the codebase where the entity persists.
+// Failing to load the previous outline is non-critical: slugs then derive from
+// names alone, which still matches every entity whose name did not change.
+func (uc *GenerateSpecViewUseCase) assignSlugs(ctx context.Context, req specview.SpecViewRequest, doc *specview.SpecDocument) {
+	var previous []specview.Domain
+	if uc.outlineReader != nil {
+		var err error
+		previous, err = uc.outlineReader.GetLatestDocumentOutline(ctx, req.UserID, req.AnalysisID, req.Language)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to load previous document outline (non-critical)",
+				"analysis_id", req.AnalysisID,
+				"error", err,
+			)
+			previous = nil
+		}
+	}
+	specview.AssignSlugs(doc.Domains, previous)
+}
@@ -0,0 +1,101 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockOutlineRepository struct {
+	mockRepository
+	outline []specview.Domain
+	err     error
+}
+
+func (m *mockOutlineRepository) GetLatestDocumentOutline(ctx context.Context, userID, analysisID string, language specview.Language) ([]specview.Domain, error) {
+	return m.outline, m.err
+}
+
+func TestSortDocument(t *testing.T) {
+	domains := []specview.Domain{
+		{Name: UncategorizedName},
+		{Name: "billing"},
+		{Name: "Auth", Features: []specview.Feature{{Name: "logout"}, {Name: "Login"}, {Name: UncategorizedName}, {Name: "Audit"}}},
+		{Name: "Billing"},
+	}
+
+	sortDocument(domains)
+
+	got := []string{domains[0].Name, domains[1].Name, domains[2].Name, domains[3].Name}
+	want := []string{"Auth", "Billing", "billing", UncategorizedName}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("domain order = %v, want %v", got, want)
+		}
+	}
+
+	features := domains[0].Features
+	if features[0].Name != "Audit" || features[1].Name != "Login" || features[2].Name != "logout" || features[3].Name != UncategorizedName {
+		t.Errorf("unexpected feature order: %+v", features)
+	}
+}
+
+func TestGenerateSpecViewUseCase_AssignSlugs(t *testing.T) {
+	newRepo := func(outline []specview.Domain, err error) (*mockOutlineRepository, **specview.SpecDocument) {
+		var saved *specview.SpecDocument
+		repo := &mockOutlineRepository{outline: outline, err: err}
+		repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		}
+		repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+			saved = doc
+			doc.ID = "doc-001"
+			return nil
+		}
+		return repo, &saved
+	}
+
+	t.Run("keeps slugs of the previous document", func(t *testing.T) {
+		repo, saved := newRepo([]specview.Domain{{
+			Name: "Auth",
+			Slug: "auth",
+			Features: []specview.Feature{
+				{Name: "Sign In", Slug: "sign-in", Behaviors: []specview.Behavior{{OriginalName: "TestLogin"}}},
+				{Name: "Sign Out", Slug: "sign-out", Behaviors: []specview.Behavior{{OriginalName: "TestLogout"}}},
+			},
+		}}, nil)
+		var convertCalls atomic.Int32
+		uc := NewGenerateSpecViewUseCase(repo, newFanOutAIProvider(&convertCalls), "model")
+
+		if _, err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		doc := *saved
+		if doc.Domains[0].Name != "Authentication" || doc.Domains[0].Slug != "auth" {
+			t.Fatalf("expected renamed domain to keep its slug, got %+v", doc.Domains[0])
+		}
+		if doc.Domains[0].Features[0].Slug != "sign-in" || doc.Domains[0].Features[1].Slug != "sign-out" {
+			t.Errorf("unexpected feature slugs: %+v", doc.Domains[0].Features)
+		}
+		if doc.Domains[1].Slug != "user-management" {
+			t.Errorf("expected new slug, got %q", doc.Domains[1].Slug)
+		}
+	})
+
+	t.Run("derives slugs from names when the outline fails to load", func(t *testing.T) {
+		repo, saved := newRepo(nil, errors.New("connection reset"))
+		var convertCalls atomic.Int32
+		uc := NewGenerateSpecViewUseCase(repo, newFanOutAIProvider(&convertCalls), "model")
+
+		if _, err := uc.Execute(context.Background(), newValidRequest()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if (*saved).Domains[0].Slug != "authentication" {
+			t.Errorf("expected name-derived slug, got %q", (*saved).Domains[0].Slug)
+		}
+	})
+}
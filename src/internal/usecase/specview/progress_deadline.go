@@ -0,0 +1,38 @@
+package specview
+
+import (
+	"context"
+	"time"
+)
+
+// progressDeadline cancels a context when no progress is reported within the
+// idle window, or when the hard cap elapses. Each extend call restarts the idle
+// window, so a large Phase 2 run keeps going for as long as features complete.
+type progressDeadline struct {
+	idle  time.Duration
+	timer *time.Timer
+}
+
+// withProgressDeadline derives a context bounded by idle and maxDuration.
+// The returned stop function must be called to release the timers.
+func withProgressDeadline(ctx context.Context, idle, maxDuration time.Duration) (context.Context, *progressDeadline, func()) {
+	maxCtx, cancelMax := context.WithTimeoutCause(ctx, maxDuration, ErrPhase2MaxDuration)
+	idleCtx, cancelIdle := context.WithCancelCause(maxCtx)
+
+	d := &progressDeadline{
+		idle:  idle,
+		timer: time.AfterFunc(idle, func() { cancelIdle(ErrPhase2Stalled) }),
+	}
+	stop := func() {
+		d.timer.Stop()
+		cancelIdle(context.Canceled)
+		cancelMax()
+	}
+	return idleCtx, d, stop
+}
+
+// extend restarts the idle window. Calls after the deadline fired are no-ops
+// since the context stays cancelled.
+func (d *progressDeadline) extend() {
+	d.timer.Reset(d.idle)
+}
@@ -0,0 +1,44 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProgressDeadline(t *testing.T) {
+	t.Run("cancels with ErrPhase2Stalled when idle", func(t *testing.T) {
+		ctx, _, stop := withProgressDeadline(context.Background(), 20*time.Millisecond, time.Minute)
+		defer stop()
+
+		<-ctx.Done()
+		if !errors.Is(context.Cause(ctx), ErrPhase2Stalled) {
+			t.Errorf("cause = %v, want ErrPhase2Stalled", context.Cause(ctx))
+		}
+	})
+
+	t.Run("extend keeps the context alive past the idle window", func(t *testing.T) {
+		ctx, deadline, stop := withProgressDeadline(context.Background(), 50*time.Millisecond, time.Minute)
+		defer stop()
+
+		for range 5 {
+			time.Sleep(20 * time.Millisecond)
+			deadline.extend()
+		}
+		if err := ctx.Err(); err != nil {
+			t.Errorf("context cancelled despite progress: %v", context.Cause(ctx))
+		}
+	})
+
+	t.Run("hard cap wins over progress", func(t *testing.T) {
+		ctx, deadline, stop := withProgressDeadline(context.Background(), time.Minute, 30*time.Millisecond)
+		defer stop()
+
+		deadline.extend()
+		<-ctx.Done()
+		if !errors.Is(context.Cause(ctx), ErrPhase2MaxDuration) {
+			t.Errorf("cause = %v, want ErrPhase2MaxDuration", context.Cause(ctx))
+		}
+	})
+}
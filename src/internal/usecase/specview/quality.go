@@ -0,0 +1,205 @@
+package specview
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// WithQualityScoring sets the confidence below which a behavior is
+// low-confidence and the quality score below which a document is flagged,
+// both in (0, 1]; values outside keep the defaults. With refine, a flagged
+// document's features holding low-confidence behaviors are converted once
+// more, bypassing the behavior cache, and a behavior is replaced when the new
+// conversion is more confident.
+func WithQualityScoring(lowConfidence, flagBelow float64, refine bool) Option {
+	return func(cfg *Config) {
+		if lowConfidence > 0 && lowConfidence <= 1 {
+			cfg.Quality.LowConfidence = lowConfidence
+		}
+		if flagBelow > 0 && flagBelow <= 1 {
+			cfg.Quality.FlagBelow = flagBelow
+		}
+		cfg.QualityRefine = refine
+	}
+}
+
+// scoreQuality scores doc against the decisions recorded so far.
+func (uc *GenerateSpecViewUseCase) scoreQuality(doc *specview.SpecDocument, decisions *specview.DecisionLog) specview.QualityReport {
+	return specview.ScoreDocument(doc, decisions.Events(), uc.config.Quality)
+}
+
+// refineLowConfidenceFeatures converts the low-confidence behaviors of each
+// feature again and keeps whichever conversion is more confident. Improved
+// behaviors replace their entries in the behavior cache. It returns the number
+// of improved behaviors and the token usage of the retries; failed retries
+// keep the original behaviors.
+func (uc *GenerateSpecViewUseCase) refineLowConfidenceFeatures(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	phase1Output *specview.Phase1Output,
+	results []phase2Result,
+	modelID string,
+	style string,
+	glossary *specview.Glossary,
+	testIndexMap map[int]specview.TestInfo,
+	files []specview.FileInfo,
+	decisions *specview.DecisionLog,
+) (int, *specview.TokenUsage) {
+	ctx, span := tracer.Start(ctx, "specview.refine")
+	defer span.End()
+
+	var (
+		improved   int
+		entries    []specview.BehaviorCacheEntry
+		mu         sync.Mutex
+		usage      specview.TokenUsage
+		usageFound bool
+	)
+	testHashMap := uc.buildTestHashMap(phase1Output, testIndexMap, buildTestFilePathMap(files), req.Language, modelID, style, glossary, specview.PromptVersion(ctx), specview.CacheScope(ctx))
+	sem := semaphore.NewWeighted(uc.config.Phase2Concurrency)
+	g, gCtx := errgroup.WithContext(ctx)
+
+	features := 0
+	for i := range results {
+		r := &results[i]
+		var tests []specview.TestForConversion
+		for _, b := range r.behaviors {
+			if b.Confidence >= uc.config.Quality.LowConfidence {
+				continue
+			}
+			if info, ok := testIndexMap[b.TestIndex]; ok {
+				tests = append(tests, specview.TestForConversion{Index: b.TestIndex, Name: info.Name})
+			}
+		}
+		if len(tests) == 0 {
+			continue
+		}
+		features++
+		domain := phase1Output.Domains[r.domainIdx]
+		feature := domain.Features[r.featureIdx]
+
+		g.Go(func() error {
+			if err := sem.Acquire(gCtx, 1); err != nil {
+				return nil
+			}
+			defer sem.Release(1)
+
+			featureCtx, cancel := context.WithTimeout(gCtx, DefaultPhase2FeatureTimeout)
+			defer cancel()
+			output, featureUsage, err := uc.aiProvider.ConvertTestNames(featureCtx, specview.Phase2Input{
+				DomainContext: domain.Name + ": " + domain.Description,
+				FeatureName:   feature.Name,
+				Glossary:      glossary,
+				Language:      req.Language,
+				Style:         style,
+				Tests:         tests,
+			})
+			if err != nil {
+				slog.WarnContext(ctx, "low-confidence feature retry failed (non-critical)",
+					"analysis_id", req.AnalysisID,
+					"feature", feature.Name,
+					"error", err,
+				)
+				return nil
+			}
+
+			retried := make(map[int]specview.BehaviorSpec, len(output.Behaviors))
+			for _, b := range output.Behaviors {
+				retried[b.TestIndex] = b
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if featureUsage != nil {
+				usage = usage.Add(*featureUsage)
+				usageFound = true
+			}
+			featureImproved := 0
+			for bi, b := range r.behaviors {
+				retry, ok := retried[b.TestIndex]
+				if !ok || retry.Confidence <= b.Confidence || retry.Description == "" {
+					continue
+				}
+				r.behaviors[bi] = retry
+				featureImproved++
+				if hexHash, ok := testHashMap[b.TestIndex]; ok {
+					if hash, err := hex.DecodeString(hexHash); err == nil {
+						entries = append(entries, specview.BehaviorCacheEntry{
+							AnalysisID:   req.AnalysisID,
+							CacheKeyHash: hash,
+							Description:  retry.Description,
+						})
+					}
+				}
+			}
+			improved += featureImproved
+			decisions.Record(specview.DecisionLowConfidenceRetry, feature.Name, map[string]any{
+				"improved": featureImproved,
+				"tests":    len(tests),
+			})
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	span.SetAttributes(
+		attribute.Int("specview.refined_features", features),
+		attribute.Int("specview.improved_behaviors", improved),
+	)
+
+	if len(entries) > 0 {
+		if err := uc.repository.SaveBehaviorCache(ctx, entries); err != nil {
+			slog.WarnContext(ctx, "failed to save refined behavior cache (non-critical)",
+				"entry_count", len(entries),
+				"error", err,
+			)
+		}
+	}
+
+	slog.InfoContext(ctx, "low-confidence features retried",
+		"analysis_id", req.AnalysisID,
+		"feature_count", features,
+		"improved_behaviors", improved,
+	)
+
+	if !usageFound {
+		return improved, nil
+	}
+	return improved, &usage
+}
+
+// assessQuality scores the assembled document and, when it is flagged and
+// refinement is enabled, retries its low-confidence features and assembles it
+// again. It returns the document and the token usage of the retries.
+func (uc *GenerateSpecViewUseCase) assessQuality(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	doc *specview.SpecDocument,
+	phase1Output *specview.Phase1Output,
+	results []phase2Result,
+	modelID string,
+	style string,
+	glossary *specview.Glossary,
+	testIndexMap map[int]specview.TestInfo,
+	files []specview.FileInfo,
+	decisions *specview.DecisionLog,
+) (*specview.SpecDocument, *specview.TokenUsage) {
+	report := uc.scoreQuality(doc, decisions)
+	if !report.Flagged || !uc.config.QualityRefine || report.LowConfidenceBehaviors == 0 {
+		return doc, nil
+	}
+
+	improved, usage := uc.refineLowConfidenceFeatures(ctx, req, phase1Output, results, modelID, style, glossary, testIndexMap, files, decisions)
+	if improved == 0 {
+		return doc, usage
+	}
+	return uc.assembleDocument(req, doc.ModelID, doc.ContentHash, phase1Output, results, testIndexMap), usage
+}
@@ -0,0 +1,124 @@
+package specview
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestGenerateSpecViewUseCase_QualityScoring(t *testing.T) {
+	run := func(opts ...Option) (*specview.SpecDocument, *specview.SpecViewResult, int, int) {
+		var (
+			mu       sync.Mutex
+			saved    *specview.SpecDocument
+			converts int
+			refined  int
+			seen     = make(map[string]bool)
+		)
+		repo := &mockRepository{
+			getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+				return newTestFiles(), nil
+			},
+			saveBehaviorCacheFn: func(ctx context.Context, entries []specview.BehaviorCacheEntry) error {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, e := range entries {
+					if strings.HasPrefix(e.Description, "Refined") {
+						refined++
+					}
+				}
+				return nil
+			},
+			saveDocumentFn: func(ctx context.Context, doc *specview.SpecDocument) error {
+				saved = doc
+				doc.ID = "doc-001"
+				return nil
+			},
+		}
+		aiProvider := &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				return newPhase1Output(), &specview.TokenUsage{}, nil
+			},
+			convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+				mu.Lock()
+				converts++
+				retry := seen[input.FeatureName]
+				seen[input.FeatureName] = true
+				mu.Unlock()
+				// The first conversion of each feature but Login is unsure; retries are not.
+				description, confidence := "Unsure behavior", 0.2
+				switch {
+				case input.FeatureName == "Login":
+					description, confidence = "Logs in", 0.95
+				case retry:
+					description, confidence = "Refined behavior", 0.9
+				}
+				behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+				for i, test := range input.Tests {
+					behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: description + " " + test.Name, Confidence: confidence}
+				}
+				return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{PromptTokens: 10}, nil
+			},
+		}
+		result, err := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash", opts...).Execute(context.Background(), newValidRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return saved, result, converts, refined
+	}
+
+	t.Run("flags a low-confidence document", func(t *testing.T) {
+		doc, result, converts, _ := run()
+
+		if doc.Quality == nil || result.Quality == nil {
+			t.Fatal("expected a quality report")
+		}
+		if !doc.Quality.Flagged || doc.Quality.Refined {
+			t.Errorf("expected a flagged, unrefined document, got %+v", doc.Quality)
+		}
+		if doc.Quality.LowConfidenceBehaviors != 3 || doc.Quality.Behaviors != 4 {
+			t.Errorf("unexpected counts %+v", doc.Quality)
+		}
+		if converts != 3 {
+			t.Errorf("expected 3 conversions without refinement, got %d", converts)
+		}
+	})
+
+	t.Run("retries low-confidence features when refining", func(t *testing.T) {
+		doc, _, converts, refined := run(WithQualityScoring(0, 0, true))
+
+		if converts != 5 {
+			t.Errorf("expected Logout and User Creation converted again, got %d conversions", converts)
+		}
+		if !doc.Quality.Refined || doc.Quality.Flagged || doc.Quality.LowConfidenceBehaviors != 0 {
+			t.Errorf("expected a refined, unflagged document, got %+v", doc.Quality)
+		}
+		if got := doc.Domains[1].Features[0].Behaviors[0].Description; got != "Refined behavior TestCreateUser" {
+			t.Errorf("expected the refined description, got %q", got)
+		}
+		if refined != 3 {
+			t.Errorf("expected 3 refined cache entries, got %d", refined)
+		}
+
+		var retries int
+		for _, d := range doc.Decisions {
+			if d.Kind == specview.DecisionLowConfidenceRetry {
+				retries++
+			}
+		}
+		if retries != 2 {
+			t.Errorf("expected 2 low_confidence_retry decisions, got %d", retries)
+		}
+	})
+
+	t.Run("leaves confident documents alone", func(t *testing.T) {
+		doc, _, converts, _ := run(WithQualityScoring(0.1, 0, true))
+
+		if doc.Quality.Flagged || doc.Quality.Refined || converts != 3 {
+			t.Errorf("expected no retries, got %d conversions and %+v", converts, doc.Quality)
+		}
+	})
+}
@@ -0,0 +1,275 @@
+package specview
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// ReclassifyUncategorizedUseCase places the Uncategorized tests of a saved
+// document into its other domains and saves the result as a new version.
+// Behaviors keep their descriptions; only Phase 1 placement runs again.
+type ReclassifyUncategorizedUseCase struct {
+	aiProvider specview.AIProvider
+	repo       specview.ReclassifyRepository
+}
+
+// NewReclassifyUncategorizedUseCase creates a new ReclassifyUncategorizedUseCase.
+func NewReclassifyUncategorizedUseCase(
+	repo specview.ReclassifyRepository,
+	aiProvider specview.AIProvider,
+) *ReclassifyUncategorizedUseCase {
+	return &ReclassifyUncategorizedUseCase{aiProvider: aiProvider, repo: repo}
+}
+
+// Execute sends the document's Uncategorized tests through placement with the
+// document's other domains and features as the structure. Placed behaviors are
+// appended to their feature, each recorded as an uncategorized_reclassified
+// decision; tests placed into Uncategorized or an unknown feature stay. Other
+// domains are copied unchanged. No version is saved when nothing moved.
+func (uc *ReclassifyUncategorizedUseCase) Execute(ctx context.Context, documentID string) (result *specview.ReclassifyResult, err error) {
+	if documentID == "" {
+		return nil, fmt.Errorf("%w: document ID is required", specview.ErrInvalidInput)
+	}
+
+	ctx, span := tracer.Start(ctx, "specview.reclassify")
+	defer func() { endSpan(span, err) }()
+
+	doc, err := uc.repo.GetDocument(ctx, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrReclassifyFailed, err)
+	}
+	if doc.ParentDocumentID != "" {
+		return nil, fmt.Errorf("%w: team slices are not reclassified, reclassify document %s", specview.ErrInvalidInput, doc.ParentDocumentID)
+	}
+
+	result = &specview.ReclassifyResult{SourceDocumentID: documentID}
+	uncategorized := uncategorizedBehaviors(doc)
+	structure := documentStructure(doc)
+	if len(uncategorized) == 0 || len(structure.Domains) == 0 {
+		result.Remaining = len(uncategorized)
+		return result, nil
+	}
+
+	tests := make([]specview.TestInfo, len(uncategorized))
+	suitePaths := uc.suitePaths(ctx, doc.AnalysisID)
+	for i, b := range uncategorized {
+		// Placements refer to tests by their position in NewTests.
+		tests[i] = specview.TestInfo{
+			Index:      i,
+			Name:       b.OriginalName,
+			SuitePath:  suitePaths[b.TestCaseID],
+			TestCaseID: b.TestCaseID,
+		}
+	}
+
+	output, usage, err := uc.aiProvider.PlaceNewTests(ctx, specview.PlacementInput{
+		ExistingStructure: structure,
+		Language:          doc.Language,
+		NewTests:          tests,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: placement: %w", ErrAIProcessingFailed, err)
+	}
+	result.TokenUsage = usage
+	var placements []specview.TestPlacement
+	if output != nil {
+		placements = output.Placements
+	}
+
+	decisions := specview.NewDecisionLog()
+	domains, moved := moveUncategorized(doc.Domains, uncategorized, placements, decisions)
+	result.Moved = moved
+	result.Remaining = len(uncategorized) - moved
+	span.SetAttributes(
+		attribute.Int("specview.uncategorized_tests", len(uncategorized)),
+		attribute.Int("specview.reclassified_tests", moved),
+	)
+	if moved == 0 {
+		slog.InfoContext(ctx, "no uncategorized tests reclassified",
+			"document_id", documentID,
+			"uncategorized_count", len(uncategorized),
+		)
+		return result, nil
+	}
+
+	reclassified := &specview.SpecDocument{
+		AnalysisID:       doc.AnalysisID,
+		ContentHash:      doc.ContentHash,
+		Decisions:        decisions.Events(),
+		Domains:          domains,
+		DryRun:           doc.DryRun,
+		ExecutiveSummary: doc.ExecutiveSummary,
+		Generator:        doc.Generator,
+		Language:         doc.Language,
+		ModelID:          doc.ModelID,
+		Project:          doc.Project,
+		PromptVersion:    doc.PromptVersion,
+		UserID:           doc.UserID,
+	}
+	if err := uc.repo.SaveDocument(ctx, reclassified); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSaveFailed, err)
+	}
+	result.DocumentID = reclassified.ID
+	result.Version = reclassified.Version
+
+	slog.InfoContext(ctx, "uncategorized tests reclassified",
+		"source_document_id", documentID,
+		"document_id", reclassified.ID,
+		"version", reclassified.Version,
+		"moved", result.Moved,
+		"remaining", result.Remaining,
+	)
+	return result, nil
+}
+
+// suitePaths maps test case IDs of the document's analysis to their suite
+// paths. A failed lookup is logged and placement runs on test names alone.
+func (uc *ReclassifyUncategorizedUseCase) suitePaths(ctx context.Context, analysisID string) map[string]string {
+	files, err := uc.repo.GetTestDataByAnalysisID(ctx, analysisID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load suite paths for reclassification (non-critical)",
+			"analysis_id", analysisID,
+			"error", err,
+		)
+		return nil
+	}
+
+	paths := make(map[string]string)
+	for _, f := range files {
+		for _, t := range f.Tests {
+			if t.SuitePath != "" {
+				paths[t.TestCaseID] = t.SuitePath
+			}
+		}
+	}
+	return paths
+}
+
+// uncategorizedBehaviors returns the behaviors of the document's Uncategorized
+// domain in document order.
+func uncategorizedBehaviors(doc *specview.SpecDocument) []specview.Behavior {
+	var behaviors []specview.Behavior
+	for _, d := range doc.Domains {
+		if d.Name != UncategorizedName {
+			continue
+		}
+		for _, f := range d.Features {
+			behaviors = append(behaviors, f.Behaviors...)
+		}
+	}
+	return behaviors
+}
+
+// documentStructure returns the domains and features of the document other
+// than Uncategorized, the structure placement may choose from.
+func documentStructure(doc *specview.SpecDocument) *specview.Phase1Output {
+	structure := &specview.Phase1Output{}
+	for _, d := range doc.Domains {
+		if d.Name == UncategorizedName || len(d.Features) == 0 {
+			continue
+		}
+		group := specview.DomainGroup{
+			Confidence:  d.Confidence,
+			Description: d.Description,
+			Name:        d.Name,
+		}
+		for _, f := range d.Features {
+			group.Features = append(group.Features, specview.FeatureGroup{
+				Confidence:  f.Confidence,
+				Description: f.Description,
+				Name:        f.Name,
+			})
+		}
+		structure.Domains = append(structure.Domains, group)
+	}
+	return structure
+}
+
+// moveUncategorized returns a copy of domains with the placed behaviors
+// appended to their features and removed from Uncategorized, and the number
+// moved. Uncategorized features, and the domain itself, are dropped once empty.
+func moveUncategorized(
+	domains []specview.Domain,
+	uncategorized []specview.Behavior,
+	placements []specview.TestPlacement,
+	decisions *specview.DecisionLog,
+) ([]specview.Domain, int) {
+	out := make([]specview.Domain, len(domains))
+	for i, d := range domains {
+		out[i] = d
+		out[i].Features = make([]specview.Feature, len(d.Features))
+		for j, f := range d.Features {
+			out[i].Features[j] = f
+			out[i].Features[j].Behaviors = append([]specview.Behavior(nil), f.Behaviors...)
+		}
+	}
+
+	placed := make(map[int]bool)
+	for _, p := range placements {
+		if p.TestIndex < 0 || p.TestIndex >= len(uncategorized) || p.DomainName == UncategorizedName {
+			continue
+		}
+		feature := findDocumentFeature(out, p.DomainName, p.FeatureName)
+		if feature == nil {
+			continue
+		}
+		if placed[p.TestIndex] {
+			continue
+		}
+		placed[p.TestIndex] = true
+		b := uncategorized[p.TestIndex]
+		feature.Behaviors = append(feature.Behaviors, b)
+		decisions.Record(specview.DecisionReclassified, b.OriginalName, map[string]any{
+			"domain":  p.DomainName,
+			"feature": p.FeatureName,
+		})
+	}
+	if len(placed) == 0 {
+		return out, 0
+	}
+
+	// Positions follow the order of uncategorizedBehaviors.
+	pos := 0
+	kept := out[:0]
+	for _, d := range out {
+		if d.Name == UncategorizedName {
+			features := d.Features[:0]
+			for _, f := range d.Features {
+				behaviors := f.Behaviors[:0]
+				for _, b := range f.Behaviors {
+					if !placed[pos] {
+						behaviors = append(behaviors, b)
+					}
+					pos++
+				}
+				if f.Behaviors = behaviors; len(behaviors) > 0 {
+					features = append(features, f)
+				}
+			}
+			if d.Features = features; len(features) == 0 {
+				continue
+			}
+		}
+		kept = append(kept, d)
+	}
+	return kept, len(placed)
+}
+
+func findDocumentFeature(domains []specview.Domain, domainName, featureName string) *specview.Feature {
+	for i := range domains {
+		if domains[i].Name != domainName {
+			continue
+		}
+		for j := range domains[i].Features {
+			if domains[i].Features[j].Name == featureName {
+				return &domains[i].Features[j]
+			}
+		}
+	}
+	return nil
+}
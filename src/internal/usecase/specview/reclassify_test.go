@@ -0,0 +1,207 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockReclassifyRepository struct {
+	doc    *specview.SpecDocument
+	getErr error
+	saved  *specview.SpecDocument
+}
+
+func (m *mockReclassifyRepository) GetDocument(ctx context.Context, documentID string) (*specview.SpecDocument, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return m.doc, nil
+}
+
+func (m *mockReclassifyRepository) GetTestDataByAnalysisID(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+	return []specview.FileInfo{{
+		Path:  "test/misc_test.go",
+		Tests: []specview.TestInfo{{Name: "TestRefreshToken", SuitePath: "Auth > Tokens", TestCaseID: "tc-010"}},
+	}}, nil
+}
+
+func (m *mockReclassifyRepository) SaveDocument(ctx context.Context, doc *specview.SpecDocument) error {
+	m.saved = doc
+	doc.ID = "doc-002"
+	doc.Version = 2
+	return nil
+}
+
+func newUncategorizedDocument() *specview.SpecDocument {
+	return &specview.SpecDocument{
+		AnalysisID:  "550e8400-e29b-41d4-a716-446655440000",
+		ContentHash: []byte("hash"),
+		Domains: []specview.Domain{
+			{
+				Name: "Authentication",
+				Slug: "authentication",
+				Features: []specview.Feature{
+					{Name: "Login", Slug: "login", Behaviors: []specview.Behavior{{Description: "Logs in", OriginalName: "TestLogin", TestCaseID: "tc-001"}}},
+					{Name: "Tokens", Slug: "tokens"},
+				},
+			},
+			{
+				Name: UncategorizedName,
+				Features: []specview.Feature{{
+					Name: UncategorizedName,
+					Behaviors: []specview.Behavior{
+						{Description: "Refreshes a token", OriginalName: "TestRefreshToken", TestCaseID: "tc-010"},
+						{Description: "Does something", OriginalName: "TestMisc", TestCaseID: "tc-011"},
+					},
+				}},
+			},
+		},
+		ExecutiveSummary: "summary",
+		ID:               "doc-001",
+		Language:         "English",
+		ModelID:          "gemini-2.5-flash",
+		UserID:           "test-user-001",
+		Version:          1,
+	}
+}
+
+func TestReclassifyUncategorizedUseCase_Execute(t *testing.T) {
+	t.Run("moves placed tests and keeps the rest", func(t *testing.T) {
+		repo := &mockReclassifyRepository{doc: newUncategorizedDocument()}
+		var input specview.PlacementInput
+		aiProvider := &mockAIProvider{
+			placeNewTestsFn: func(ctx context.Context, in specview.PlacementInput) (*specview.PlacementOutput, *specview.TokenUsage, error) {
+				input = in
+				return &specview.PlacementOutput{Placements: []specview.TestPlacement{
+					{DomainName: "Authentication", FeatureName: "Tokens", TestIndex: 0},
+					{DomainName: UncategorizedName, FeatureName: UncategorizedName, TestIndex: 1},
+				}}, &specview.TokenUsage{TotalTokens: 42}, nil
+			},
+		}
+
+		result, err := NewReclassifyUncategorizedUseCase(repo, aiProvider).Execute(context.Background(), "doc-001")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(input.ExistingStructure.Domains) != 1 || len(input.NewTests) != 2 {
+			t.Fatalf("expected only the Uncategorized tests placed into the other domains, got %+v", input)
+		}
+		if input.NewTests[0].SuitePath != "Auth > Tokens" {
+			t.Errorf("expected the suite path from the inventory, got %q", input.NewTests[0].SuitePath)
+		}
+		if result.Moved != 1 || result.Remaining != 1 || result.DocumentID != "doc-002" || result.Version != 2 {
+			t.Errorf("unexpected result %+v", result)
+		}
+
+		saved := repo.saved
+		if tokens := saved.Domains[0].Features[1].Behaviors; len(tokens) != 1 || tokens[0].TestCaseID != "tc-010" {
+			t.Errorf("expected the token test in Tokens, got %+v", tokens)
+		}
+		if login := saved.Domains[0].Features[0].Behaviors; len(login) != 1 {
+			t.Errorf("expected Login unchanged, got %+v", login)
+		}
+		if left := saved.Domains[1].Features[0].Behaviors; len(left) != 1 || left[0].OriginalName != "TestMisc" {
+			t.Errorf("expected TestMisc left in Uncategorized, got %+v", left)
+		}
+		if string(saved.ContentHash) != "hash" || saved.ExecutiveSummary != "summary" || saved.Quality != nil {
+			t.Errorf("unexpected document fields %+v", saved)
+		}
+		if len(saved.Decisions) != 1 || saved.Decisions[0].Kind != specview.DecisionReclassified {
+			t.Errorf("expected 1 uncategorized_reclassified decision, got %+v", saved.Decisions)
+		}
+		if behaviors := repo.doc.Domains[0].Features[1].Behaviors; len(behaviors) != 0 {
+			t.Error("expected the source document to be left unchanged")
+		}
+	})
+
+	t.Run("drops the Uncategorized domain once empty", func(t *testing.T) {
+		repo := &mockReclassifyRepository{doc: newUncategorizedDocument()}
+		aiProvider := &mockAIProvider{
+			placeNewTestsFn: func(ctx context.Context, in specview.PlacementInput) (*specview.PlacementOutput, *specview.TokenUsage, error) {
+				return &specview.PlacementOutput{Placements: []specview.TestPlacement{
+					{DomainName: "Authentication", FeatureName: "Tokens", TestIndex: 0},
+					{DomainName: "Authentication", FeatureName: "Login", TestIndex: 1},
+				}}, nil, nil
+			},
+		}
+
+		if _, err := NewReclassifyUncategorizedUseCase(repo, aiProvider).Execute(context.Background(), "doc-001"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.saved.Domains) != 1 {
+			t.Errorf("expected only Authentication left, got %d domains", len(repo.saved.Domains))
+		}
+	})
+
+	t.Run("saves nothing when no test moved", func(t *testing.T) {
+		repo := &mockReclassifyRepository{doc: newUncategorizedDocument()}
+		aiProvider := &mockAIProvider{
+			placeNewTestsFn: func(ctx context.Context, in specview.PlacementInput) (*specview.PlacementOutput, *specview.TokenUsage, error) {
+				return &specview.PlacementOutput{Placements: []specview.TestPlacement{
+					{DomainName: "Billing", FeatureName: "Invoices", TestIndex: 0},
+				}}, nil, nil
+			},
+		}
+
+		result, err := NewReclassifyUncategorizedUseCase(repo, aiProvider).Execute(context.Background(), "doc-001")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.saved != nil || result.DocumentID != "" || result.Remaining != 2 {
+			t.Errorf("expected no new version, got %+v", result)
+		}
+	})
+
+	t.Run("skips placement without Uncategorized tests", func(t *testing.T) {
+		doc := newUncategorizedDocument()
+		doc.Domains = doc.Domains[:1]
+		aiProvider := &mockAIProvider{
+			placeNewTestsFn: func(ctx context.Context, in specview.PlacementInput) (*specview.PlacementOutput, *specview.TokenUsage, error) {
+				t.Error("placement should not be called")
+				return nil, nil, nil
+			},
+		}
+
+		result, err := NewReclassifyUncategorizedUseCase(&mockReclassifyRepository{doc: doc}, aiProvider).Execute(context.Background(), "doc-001")
+		if err != nil || result.Moved != 0 {
+			t.Errorf("expected an empty result, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("rejects team slices", func(t *testing.T) {
+		doc := newUncategorizedDocument()
+		doc.ParentDocumentID = "doc-000"
+
+		_, err := NewReclassifyUncategorizedUseCase(&mockReclassifyRepository{doc: doc}, &mockAIProvider{}).Execute(context.Background(), "doc-001")
+		if !errors.Is(err, specview.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("keeps not found errors", func(t *testing.T) {
+		repo := &mockReclassifyRepository{getErr: specview.ErrDocumentNotFound}
+
+		_, err := NewReclassifyUncategorizedUseCase(repo, &mockAIProvider{}).Execute(context.Background(), "doc-001")
+		if !errors.Is(err, specview.ErrDocumentNotFound) || !errors.Is(err, ErrReclassifyFailed) {
+			t.Errorf("expected ErrDocumentNotFound, got %v", err)
+		}
+	})
+
+	t.Run("fails on placement errors", func(t *testing.T) {
+		repo := &mockReclassifyRepository{doc: newUncategorizedDocument()}
+		aiProvider := &mockAIProvider{
+			placeNewTestsFn: func(ctx context.Context, in specview.PlacementInput) (*specview.PlacementOutput, *specview.TokenUsage, error) {
+				return nil, nil, errors.New("rate limited")
+			},
+		}
+
+		_, err := NewReclassifyUncategorizedUseCase(repo, aiProvider).Execute(context.Background(), "doc-001")
+		if !errors.Is(err, ErrAIProcessingFailed) || repo.saved != nil {
+			t.Errorf("expected ErrAIProcessingFailed and nothing saved, got %v", err)
+		}
+	})
+}
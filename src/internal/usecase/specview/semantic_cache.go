@@ -0,0 +1,92 @@
+package specview
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// reuseSimilarBehaviors embeds the names of the tests in missed, keyed by
+// their hex key, and adds the cached behavior of the nearest similar test to
+// cached, copying it to the test's own key. The embeddings are stored either
+// way, so the behaviors generated for these tests become neighbors of later
+// lookups. Failures are non-critical: the tests are converted instead.
+func (uc *GenerateSpecViewUseCase) reuseSimilarBehaviors(
+	ctx context.Context,
+	cached map[string]string,
+	missed map[string]behaviorKeyInput,
+	lang specview.Language,
+	modelID string,
+	style string,
+	glossaryVersion string,
+	promptVersion string,
+) {
+	if uc.semanticCache == nil || len(missed) == 0 {
+		return
+	}
+
+	hexKeys := slices.Sorted(maps.Keys(missed))
+	texts := make([]string, len(hexKeys))
+	for i, hexKey := range hexKeys {
+		texts[i] = specview.SemanticCacheText(missed[hexKey].test)
+	}
+	embeddings, err := uc.config.Embedder.Embed(ctx, texts)
+	if err == nil && len(embeddings) != len(texts) {
+		err = fmt.Errorf("got %d embeddings for %d texts", len(embeddings), len(texts))
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to embed test names for the semantic behavior cache (non-critical)",
+			"test_count", len(texts),
+			"error", err,
+		)
+		return
+	}
+
+	partition := specview.SemanticCachePartition(lang, modelID, style, glossaryVersion, promptVersion, specview.CacheScope(ctx))
+	similar, err := uc.semanticCache.FindSimilarBehaviors(ctx, partition, embeddings, uc.config.SemanticSimilarity)
+	if err != nil {
+		slog.WarnContext(ctx, "semantic behavior cache lookup failed (non-critical)",
+			"test_count", len(texts),
+			"error", err,
+		)
+	}
+
+	stored := make([]specview.BehaviorEmbedding, 0, len(hexKeys))
+	var entries []specview.BehaviorCacheEntry
+	for i, hexKey := range hexKeys {
+		hash, err := hex.DecodeString(hexKey)
+		if err != nil {
+			continue
+		}
+		stored = append(stored, specview.BehaviorEmbedding{CacheKeyHash: hash, Embedding: embeddings[i]})
+		if behavior, ok := similar[i]; ok {
+			cached[hexKey] = behavior.Description
+			entries = append(entries, specview.BehaviorCacheEntry{CacheKeyHash: hash, Description: behavior.Description})
+		}
+	}
+	if err := uc.semanticCache.SaveBehaviorEmbeddings(ctx, partition, stored); err != nil {
+		slog.WarnContext(ctx, "failed to save test name embeddings (non-critical)",
+			"embedding_count", len(stored),
+			"error", err,
+		)
+	}
+	if len(entries) == 0 {
+		return
+	}
+	if err := uc.repository.SaveBehaviorCache(ctx, entries); err != nil {
+		slog.WarnContext(ctx, "failed to copy semantically cached behaviors (non-critical)",
+			"entry_count", len(entries),
+			"error", err,
+		)
+	}
+	slog.InfoContext(ctx, "reused cached behaviors of similar tests",
+		"entry_count", len(entries),
+		"missed_count", len(missed),
+		"min_similarity", uc.config.SemanticSimilarity,
+	)
+}
@@ -0,0 +1,125 @@
+package specview
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockEmbedder struct {
+	err   error
+	texts []string
+}
+
+func (m *mockEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.texts = append(m.texts, texts...)
+	embeddings := make([][]float32, len(texts))
+	for i := range texts {
+		embeddings[i] = make([]float32, specview.EmbeddingDimensions)
+	}
+	return embeddings, nil
+}
+
+type mockSemanticRepository struct {
+	*mockRepository
+	findSimilarFn func(ctx context.Context, partition []byte, embeddings [][]float32, minSimilarity float64) (map[int]specview.SimilarBehavior, error)
+	saved         []specview.BehaviorEmbedding
+}
+
+func (m *mockSemanticRepository) SaveBehaviorEmbeddings(ctx context.Context, partition []byte, embeddings []specview.BehaviorEmbedding) error {
+	m.saved = append(m.saved, embeddings...)
+	return nil
+}
+
+func (m *mockSemanticRepository) FindSimilarBehaviors(ctx context.Context, partition []byte, embeddings [][]float32, minSimilarity float64) (map[int]specview.SimilarBehavior, error) {
+	if m.findSimilarFn != nil {
+		return m.findSimilarFn(ctx, partition, embeddings, minSimilarity)
+	}
+	return nil, nil
+}
+
+func TestLookupBehaviorCache_SemanticCache(t *testing.T) {
+	files := newTestFiles()
+	loginKey := behaviorCacheKeyHex(specview.HashSHA256, files[0].Path, files[0].Tests[0], "English", "gemini-2.5-flash", "", "", specview.DefaultPromptVersion, "")
+	logoutKey := behaviorCacheKeyHex(specview.HashSHA256, files[0].Path, files[0].Tests[1], "English", "gemini-2.5-flash", "", "", specview.DefaultPromptVersion, "")
+
+	var copied []specview.BehaviorCacheEntry
+	newRepo := func(embedder *mockEmbedder) *mockSemanticRepository {
+		copied = nil
+		return &mockSemanticRepository{
+			mockRepository: &mockRepository{
+				findCachedBehaviorsFn: func(ctx context.Context, hashes [][]byte) (map[string]string, error) {
+					return map[string]string{loginKey: "User can log in"}, nil
+				},
+				saveBehaviorCacheFn: func(ctx context.Context, entries []specview.BehaviorCacheEntry) error {
+					copied = append(copied, entries...)
+					return nil
+				},
+			},
+			findSimilarFn: func(ctx context.Context, partition []byte, embeddings [][]float32, minSimilarity float64) (map[int]specview.SimilarBehavior, error) {
+				if minSimilarity != 0.9 {
+					t.Errorf("expected the configured similarity, got %v", minSimilarity)
+				}
+				found := make(map[int]specview.SimilarBehavior)
+				for i, text := range embedder.texts {
+					if text == "TestLogout" {
+						found[i] = specview.SimilarBehavior{Description: "User can log out", Similarity: 0.97}
+					}
+				}
+				return found, nil
+			},
+		}
+	}
+	lookup := func(repo specview.Repository, embedder specview.Embedder) map[string]string {
+		uc := NewGenerateSpecViewUseCase(repo, &mockAIProvider{}, "gemini-2.5-flash", WithSemanticCache(embedder, 0.9))
+		cached, _, err := uc.lookupBehaviorCache(context.Background(), newPhase1Output(), buildTestIndexMap(files), buildTestFilePathMap(files), "English", "gemini-2.5-flash", "", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return cached
+	}
+
+	t.Run("reuses behaviors of similar tests", func(t *testing.T) {
+		embedder := &mockEmbedder{}
+		repo := newRepo(embedder)
+
+		cached := lookup(repo, embedder)
+
+		if len(cached) != 2 || cached[logoutKey] != "User can log out" {
+			t.Errorf("expected the exact and the semantic hit, got %v", cached)
+		}
+		if len(embedder.texts) != 3 {
+			t.Errorf("expected only the 3 missed tests embedded, got %v", embedder.texts)
+		}
+		if len(repo.saved) != 3 {
+			t.Errorf("expected embeddings of the 3 missed tests stored, got %d", len(repo.saved))
+		}
+		if len(copied) != 1 || hex.EncodeToString(copied[0].CacheKeyHash) != logoutKey {
+			t.Errorf("expected the semantic hit copied to its exact key, got %+v", copied)
+		}
+	})
+
+	t.Run("embedding failures are non-critical", func(t *testing.T) {
+		embedder := &mockEmbedder{err: errors.New("quota exceeded")}
+		repo := newRepo(embedder)
+
+		if cached := lookup(repo, embedder); len(cached) != 1 || len(repo.saved) != 0 {
+			t.Errorf("expected only the exact hit, got %v and %d embeddings", cached, len(repo.saved))
+		}
+	})
+
+	t.Run("repositories without the capability use exact keys only", func(t *testing.T) {
+		embedder := &mockEmbedder{}
+		repo := newRepo(embedder)
+
+		if cached := lookup(repo.mockRepository, embedder); len(cached) != 1 || len(embedder.texts) != 0 {
+			t.Errorf("expected only the exact hit without embedding, got %v", cached)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockSharingRepository struct {
+	mockRepository
+	optedOut  bool
+	shared    *specview.SpecDocument
+	shares    []string
+	histories []string
+}
+
+func (m *mockSharingRepository) FindShareableDocument(ctx context.Context, userID, analysisID string, contentHash []byte, language specview.Language, modelID string) (*specview.SpecDocument, error) {
+	return m.shared, nil
+}
+
+func (m *mockSharingRepository) IsSharingOptedOut(ctx context.Context, analysisID string) (bool, error) {
+	return m.optedOut, nil
+}
+
+func (m *mockSharingRepository) RecordDocumentShare(ctx context.Context, documentID, userID, basis string) error {
+	m.shares = append(m.shares, documentID+" "+userID+" "+basis)
+	return nil
+}
+
+type stubLicenseLookup struct {
+	err     error
+	license specview.RepoLicense
+}
+
+func (s stubLicenseLookup) GetRepoLicense(ctx context.Context, host, owner, repo string) (specview.RepoLicense, error) {
+	return s.license, s.err
+}
+
+func TestGenerateSpecViewUseCase_DocumentSharing(t *testing.T) {
+	mit := specview.RepoLicense{SPDXID: "MIT"}
+
+	tests := []struct {
+		name       string
+		lookup     specview.RepoLicenseLookup
+		optedOut   bool
+		teamSlices bool
+		scope      specview.CacheScopeMode
+		wantShared bool
+	}{
+		{"public licensed repository", stubLicenseLookup{license: mit}, false, false, specview.CacheScopeGlobal, true},
+		{"opted out", stubLicenseLookup{license: mit}, true, false, specview.CacheScopeGlobal, false},
+		{"no license", stubLicenseLookup{}, false, false, specview.CacheScopeGlobal, false},
+		{"private repository", stubLicenseLookup{license: specview.RepoLicense{Private: true, SPDXID: "MIT"}}, false, false, specview.CacheScopeGlobal, false},
+		{"license lookup fails", stubLicenseLookup{err: errors.New("rate limited")}, false, false, specview.CacheScopeGlobal, false},
+		{"team slices requested", stubLicenseLookup{license: mit}, false, true, specview.CacheScopeGlobal, false},
+		{"scoped behavior cache", stubLicenseLookup{license: mit}, false, false, specview.CacheScopeUser, false},
+		{"sharing disabled", nil, false, false, specview.CacheScopeGlobal, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockSharingRepository{optedOut: tt.optedOut, shared: &specview.SpecDocument{ID: "doc-other-user"}}
+			repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+				return newTestFiles(), nil
+			}
+			repo.recordUserHistoryFn = func(ctx context.Context, userID string, documentID string) error {
+				repo.histories = append(repo.histories, documentID)
+				return nil
+			}
+			generated := false
+			repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+				generated = true
+				doc.ID = "doc-generated"
+				return nil
+			}
+			aiProvider := &mockAIProvider{
+				classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+					return newPhase1Output(), &specview.TokenUsage{}, nil
+				},
+				convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+					behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+					for i, test := range input.Tests {
+						behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+					}
+					return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+				},
+			}
+
+			req := newValidRequest()
+			req.TeamSlices = tt.teamSlices
+			opts := []Option{WithCacheScope(tt.scope)}
+			if tt.lookup != nil {
+				opts = append(opts, WithDocumentSharing(tt.lookup))
+			}
+
+			result, err := NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash", opts...).Execute(context.Background(), req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Shared != tt.wantShared || generated == tt.wantShared {
+				t.Fatalf("shared = %v, generated = %v, want shared %v", result.Shared, generated, tt.wantShared)
+			}
+			if !tt.wantShared {
+				if len(repo.shares) != 0 {
+					t.Errorf("no share should be recorded, got %v", repo.shares)
+				}
+				return
+			}
+			if result.DocumentID != "doc-other-user" || !result.CacheHit {
+				t.Errorf("unexpected result: %+v", result)
+			}
+			if len(repo.shares) != 1 || repo.shares[0] != "doc-other-user test-user-001 public:MIT" {
+				t.Errorf("unexpected shares: %v", repo.shares)
+			}
+			if len(repo.histories) != 1 || repo.histories[0] != "doc-other-user" {
+				t.Errorf("shared document should be added to the user's history, got %v", repo.histories)
+			}
+		})
+	}
+}
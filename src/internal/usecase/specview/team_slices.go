@@ -0,0 +1,62 @@
+package specview
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/specvital/worker/internal/domain/curation"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// saveTeamSlices saves a child document of doc for every team in the owner rules
+// and returns their IDs by team. Teams owning none of the document's tests get none.
+// Failure is non-critical: the full document is already saved.
+func (uc *GenerateSpecViewUseCase) saveTeamSlices(
+	ctx context.Context,
+	doc *specview.SpecDocument,
+	files []specview.FileInfo,
+	rules *curation.Rules,
+) map[string]string {
+	teams := rules.Teams()
+	if len(teams) == 0 {
+		return nil
+	}
+
+	filePaths := make(map[string]string)
+	for _, f := range files {
+		for _, t := range f.Tests {
+			filePaths[t.TestCaseID] = f.Path
+		}
+	}
+
+	ids := make(map[string]string, len(teams))
+	for _, team := range teams {
+		slice := specview.SliceForTeam(doc, team, func(b specview.Behavior) bool {
+			for _, id := range b.LinkedTestCaseIDs() {
+				if path, ok := filePaths[id]; ok && rules.OwnsFile(team, path) {
+					return true
+				}
+			}
+			return false
+		})
+		if slice == nil {
+			continue
+		}
+		if err := uc.repository.SaveDocument(ctx, slice); err != nil {
+			slog.WarnContext(ctx, "failed to save team document (non-critical)",
+				"document_id", doc.ID,
+				"team", team,
+				"error", err,
+			)
+			continue
+		}
+		ids[team] = slice.ID
+	}
+
+	slog.InfoContext(ctx, "team documents saved",
+		"document_id", doc.ID,
+		"teams", len(teams),
+		"saved", len(ids),
+	)
+	return ids
+}
@@ -0,0 +1,108 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/curation"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+func TestGenerateSpecViewUseCase_TeamSlices(t *testing.T) {
+	rules := &curation.Rules{
+		Owners: []curation.OwnerRule{
+			{Team: "identity", Paths: []string{"test/auth_test.go"}},
+			{Team: "billing", Paths: []string{"pkg/billing/**"}},
+			{Team: "accounts", Paths: []string{"test/user_test.go"}},
+		},
+		Version: curation.SupportedVersion,
+	}
+
+	newUseCase := func(saved *[]*specview.SpecDocument, failTeam string) *GenerateSpecViewUseCase {
+		repo := &mockCurationRepository{rules: rules}
+		repo.getTestDataByAnalysisIDFn = func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+			return newTestFiles(), nil
+		}
+		repo.saveDocumentFn = func(ctx context.Context, doc *specview.SpecDocument) error {
+			if doc.Team != "" && doc.Team == failTeam {
+				return errors.New("insert failed")
+			}
+			doc.ID = "doc-" + doc.Team
+			*saved = append(*saved, doc)
+			return nil
+		}
+		aiProvider := &mockAIProvider{
+			classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+				return newPhase1Output(), &specview.TokenUsage{}, nil
+			},
+			convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+				behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+				for i, test := range input.Tests {
+					behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+				}
+				return &specview.Phase2Output{Behaviors: behaviors}, &specview.TokenUsage{}, nil
+			},
+		}
+		return NewGenerateSpecViewUseCase(repo, aiProvider, "gemini-2.5-flash")
+	}
+
+	t.Run("saves a child document per owning team", func(t *testing.T) {
+		var saved []*specview.SpecDocument
+		req := newValidRequest()
+		req.TeamSlices = true
+
+		result, err := newUseCase(&saved, "").Execute(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(saved) != 3 {
+			t.Fatalf("expected the full document and 2 team documents, got %d", len(saved))
+		}
+		if len(result.TeamDocumentIDs) != 2 || result.TeamDocumentIDs["identity"] != "doc-identity" || result.TeamDocumentIDs["accounts"] != "doc-accounts" {
+			t.Errorf("unexpected team document IDs: %v", result.TeamDocumentIDs)
+		}
+		for _, child := range saved[1:] {
+			if child.ParentDocumentID != result.DocumentID {
+				t.Errorf("team %s: parent = %q, want %q", child.Team, child.ParentDocumentID, result.DocumentID)
+			}
+			for _, d := range child.Domains {
+				for _, f := range d.Features {
+					for _, b := range f.Behaviors {
+						inAuth := b.TestCaseID == "tc-001" || b.TestCaseID == "tc-002"
+						if inAuth != (child.Team == "identity") {
+							t.Errorf("team %s got behavior for %s", child.Team, b.TestCaseID)
+						}
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("team save failure keeps the full document", func(t *testing.T) {
+		var saved []*specview.SpecDocument
+		req := newValidRequest()
+		req.TeamSlices = true
+
+		result, err := newUseCase(&saved, "identity").Execute(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := result.TeamDocumentIDs["identity"]; ok || len(result.TeamDocumentIDs) != 1 {
+			t.Errorf("unexpected team document IDs: %v", result.TeamDocumentIDs)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		var saved []*specview.SpecDocument
+
+		result, err := newUseCase(&saved, "").Execute(context.Background(), newValidRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(saved) != 1 || result.TeamDocumentIDs != nil {
+			t.Errorf("expected only the full document, got %d saves", len(saved))
+		}
+	})
+}
@@ -0,0 +1,19 @@
+package specview
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the Execute, per-phase and per-feature spans.
+var tracer = otel.Tracer("github.com/specvital/worker/internal/usecase/specview")
+
+// endSpan records err, if any, before ending span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
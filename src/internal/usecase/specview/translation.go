@@ -0,0 +1,341 @@
+package specview
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/specvital/worker/internal/domain/curation"
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// translationBatchSize caps the texts sent in one translation call.
+const translationBatchSize = 200
+
+// addCachedTranslations fills the translations of a result served from an
+// existing document, translating it where no translation of the same content
+// was saved yet.
+func (uc *GenerateSpecViewUseCase) addCachedTranslations(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	result *specview.SpecViewResult,
+	modelID string,
+	files []specview.FileInfo,
+	rules *curation.Rules,
+	taxonomy *specview.Taxonomy,
+) {
+	if len(translationTargets(req)) == 0 {
+		return
+	}
+	result.Translations = uc.saveTranslations(ctx, req, nil, result.DocumentID, modelID, files, rules, taxonomy, nil)
+}
+
+// saveTranslations saves the source document translated into every language of
+// req.TranslateTo other than its own and returns them in request order. The
+// translations share the source's classification and slugs; only the texts are
+// translated. A translation of the same content saved earlier is reused unless
+// the request forces regeneration. A nil source is loaded from sourceID, with
+// the budget, once a language needs translating. Failures are non-critical:
+// the language is left out.
+func (uc *GenerateSpecViewUseCase) saveTranslations(
+	ctx context.Context,
+	req specview.SpecViewRequest,
+	source *specview.SpecDocument,
+	sourceID string,
+	modelID string,
+	files []specview.FileInfo,
+	rules *curation.Rules,
+	taxonomy *specview.Taxonomy,
+	budget *specview.Budget,
+) []specview.TranslatedDocument {
+	targets := translationTargets(req)
+	if len(targets) == 0 {
+		return nil
+	}
+	if source != nil {
+		sourceID = source.ID
+	}
+
+	var translations []specview.TranslatedDocument
+	for _, target := range targets {
+		targetReq := req
+		targetReq.Language = target
+		glossary := uc.loadGlossary(ctx, targetReq)
+		promptVersion := uc.config.PromptExperiment.Version(req.AnalysisID, target)
+		contentHash := documentContentHash(files, target, rules, glossary, taxonomy, promptVersion)
+
+		if !req.ForceRegenerate {
+			existing, err := uc.repository.FindDocumentByContentHash(ctx, req.UserID, contentHash, target, modelID)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to look up translated document (non-critical)",
+					"document_id", sourceID,
+					"language", target,
+					"error", err,
+				)
+				continue
+			}
+			if existing != nil {
+				uc.recordUserHistory(ctx, req.UserID, existing.ID)
+				translations = append(translations, specview.TranslatedDocument{CacheHit: true, DocumentID: existing.ID, Language: target})
+				continue
+			}
+		}
+
+		if source == nil {
+			var err error
+			source, budget, err = uc.loadTranslationSource(ctx, req.UserID, sourceID)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to load document for translation (non-critical)",
+					"document_id", sourceID,
+					"error", err,
+				)
+				return translations
+			}
+		}
+
+		doc, usage, err := uc.translateDocument(ctx, source, target, glossary, budget)
+		if usage != nil {
+			budget.Spend(int64(usage.TotalTokens))
+		}
+		uc.recordTokenUsage(ctx, budget, req.JobID, usage)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to translate document (non-critical)",
+				"document_id", sourceID,
+				"language", target,
+				"error", err,
+			)
+			continue
+		}
+		doc.ContentHash = contentHash
+		doc.JobID = req.JobID
+		doc.PromptVersion = promptVersion
+		doc.UserID = req.UserID
+		keys := uc.cacheKeysByTestCase(ctx, files, target, source.ModelID, string(rules.Style()), glossary, promptVersion)
+		attachCacheKeys(doc, keys)
+
+		if err := uc.repository.SaveDocument(ctx, doc); err != nil {
+			slog.WarnContext(ctx, "failed to save translated document (non-critical)",
+				"document_id", sourceID,
+				"language", target,
+				"error", err,
+			)
+			continue
+		}
+		uc.saveTranslatedBehaviors(ctx, req.AnalysisID, doc, keys)
+		uc.recordAIUsage(ctx, specview.AIUsage{
+			AnalysisID: req.AnalysisID,
+			DocumentID: doc.ID,
+			JobID:      req.JobID,
+			UserID:     req.UserID,
+		}, map[string]*specview.TokenUsage{"translation": usage})
+		uc.recordUserHistory(ctx, req.UserID, doc.ID)
+		translations = append(translations, specview.TranslatedDocument{DocumentID: doc.ID, Language: target})
+	}
+
+	slog.InfoContext(ctx, "translated documents saved",
+		"document_id", sourceID,
+		"languages", len(targets),
+		"saved", len(translations),
+	)
+	return translations
+}
+
+// loadTranslationSource loads a saved document to translate and the budget
+// its translations are charged to.
+func (uc *GenerateSpecViewUseCase) loadTranslationSource(
+	ctx context.Context,
+	userID string,
+	documentID string,
+) (*specview.SpecDocument, *specview.Budget, error) {
+	if uc.documentReader == nil {
+		return nil, nil, fmt.Errorf("%w: repository cannot load documents", specview.ErrInvalidInput)
+	}
+	doc, err := uc.documentReader.GetDocument(ctx, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	budget, err := uc.loadBudget(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, budget, nil
+}
+
+// translateDocument returns a copy of source with its texts translated into
+// target. Names of the Uncategorized domain and feature stay as they are, since
+// reclassification and ordering look them up by name. The token usage of the
+// chunks translated before a failure is returned with the error.
+func (uc *GenerateSpecViewUseCase) translateDocument(
+	ctx context.Context,
+	source *specview.SpecDocument,
+	target specview.Language,
+	glossary *specview.Glossary,
+	budget *specview.Budget,
+) (*specview.SpecDocument, *specview.TokenUsage, error) {
+	if uc.translator == nil {
+		return nil, nil, fmt.Errorf("%w: AI provider cannot translate", specview.ErrInvalidInput)
+	}
+
+	doc := copyForTranslation(source, target)
+	texts := translatableTexts(doc)
+	estimatedTokens := int64(len(texts)) * specview.EstimatedTokensPerTest
+	if budget.Check(estimatedTokens, 0, source.ModelID) == specview.BudgetReject {
+		return nil, nil, fmt.Errorf("%w: translation needs about %d tokens", specview.ErrBudgetExceeded, estimatedTokens)
+	}
+
+	var usage *specview.TokenUsage
+	for chunk := range slices.Chunk(texts, translationBatchSize) {
+		in := make([]string, len(chunk))
+		for i, text := range chunk {
+			in[i] = *text
+		}
+		output, chunkUsage, err := uc.translator.TranslateTexts(ctx, specview.TranslationInput{
+			Glossary:       glossary,
+			SourceLanguage: source.Language,
+			TargetLanguage: target,
+			Texts:          in,
+		})
+		if chunkUsage != nil {
+			sum := cmp.Or(usage, &specview.TokenUsage{}).Add(*chunkUsage)
+			usage = &sum
+		}
+		if err != nil {
+			return nil, usage, fmt.Errorf("%w: translation: %w", ErrAIProcessingFailed, err)
+		}
+		if output == nil || len(output.Texts) != len(in) {
+			return nil, usage, fmt.Errorf("%w: translation returned a different number of texts", ErrAIProcessingFailed)
+		}
+		for i, text := range output.Texts {
+			*chunk[i] = text
+		}
+	}
+
+	decisions := specview.NewDecisionLog()
+	decisions.Record(specview.DecisionDocumentTranslated, string(source.Language), map[string]any{
+		"source_document_id": source.ID,
+		"texts":              len(texts),
+	})
+	doc.Decisions = decisions.Events()
+	return doc, usage, nil
+}
+
+// translationTargets returns the languages of req.TranslateTo without
+// duplicates and without the request's own language.
+func translationTargets(req specview.SpecViewRequest) []specview.Language {
+	var targets []specview.Language
+	for _, lang := range req.TranslateTo {
+		if !lang.IsValid() || lang == req.Language || slices.Contains(targets, lang) {
+			continue
+		}
+		targets = append(targets, lang)
+	}
+	return targets
+}
+
+// copyForTranslation returns a copy of doc in lang without IDs, decisions or
+// cache keys. Its domains, features and behaviors can be changed without
+// touching doc.
+func copyForTranslation(doc *specview.SpecDocument, lang specview.Language) *specview.SpecDocument {
+	out := &specview.SpecDocument{
+		AnalysisID:       doc.AnalysisID,
+		DryRun:           doc.DryRun,
+		ExecutiveSummary: doc.ExecutiveSummary,
+		Generator:        doc.Generator,
+		Language:         lang,
+		ModelID:          doc.ModelID,
+		Project:          doc.Project,
+		Quality:          doc.Quality,
+		Domains:          make([]specview.Domain, len(doc.Domains)),
+	}
+	for i, d := range doc.Domains {
+		d.ID = ""
+		d.Features = slices.Clone(d.Features)
+		for j := range d.Features {
+			f := &d.Features[j]
+			f.ID = ""
+			f.Behaviors = slices.Clone(f.Behaviors)
+			for k := range f.Behaviors {
+				b := &f.Behaviors[k]
+				b.CacheKeyHash = nil
+				b.ID = ""
+				b.Merged = slices.Clone(b.Merged)
+			}
+		}
+		out.Domains[i] = d
+	}
+	return out
+}
+
+// translatableTexts returns pointers to the non-empty texts of doc, so
+// translations can be written back in place.
+func translatableTexts(doc *specview.SpecDocument) []*string {
+	var texts []*string
+	add := func(s *string) {
+		if *s != "" && *s != UncategorizedName {
+			texts = append(texts, s)
+		}
+	}
+	add(&doc.ExecutiveSummary)
+	for i := range doc.Domains {
+		d := &doc.Domains[i]
+		add(&d.Name)
+		add(&d.Description)
+		for j := range d.Features {
+			f := &d.Features[j]
+			add(&f.Name)
+			add(&f.Description)
+			for k := range f.Behaviors {
+				b := &f.Behaviors[k]
+				add(&b.Description)
+				for m := range b.Merged {
+					add(&b.Merged[m].Description)
+				}
+			}
+		}
+	}
+	return texts
+}
+
+// saveTranslatedBehaviors stores the translated descriptions under the cache
+// keys of the translation's language, so a later generation in that language
+// reuses them. Merged tests are stored under the key of their own test case.
+// Failure is non-critical: the translation is already saved.
+func (uc *GenerateSpecViewUseCase) saveTranslatedBehaviors(
+	ctx context.Context,
+	analysisID string,
+	doc *specview.SpecDocument,
+	keys map[string][]byte,
+) {
+	var entries []specview.BehaviorCacheEntry
+	add := func(testCaseID, description string) {
+		if key, ok := keys[testCaseID]; ok && description != "" {
+			entries = append(entries, specview.BehaviorCacheEntry{
+				AnalysisID:   analysisID,
+				CacheKeyHash: key,
+				Description:  description,
+			})
+		}
+	}
+	for _, d := range doc.Domains {
+		for _, f := range d.Features {
+			for _, b := range f.Behaviors {
+				add(b.TestCaseID, b.Description)
+				for _, m := range b.Merged {
+					add(m.TestCaseID, m.Description)
+				}
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return
+	}
+	if err := uc.repository.SaveBehaviorCache(ctx, entries); err != nil {
+		slog.WarnContext(ctx, "failed to save translated behavior cache (non-critical)",
+			"document_id", doc.ID,
+			"language", doc.Language,
+			"error", err,
+		)
+	}
+}
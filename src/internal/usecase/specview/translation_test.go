@@ -0,0 +1,234 @@
+package specview
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+type mockTranslator struct {
+	*mockAIProvider
+	translateTextsFn func(ctx context.Context, input specview.TranslationInput) (*specview.TranslationOutput, *specview.TokenUsage, error)
+}
+
+func (m *mockTranslator) TranslateTexts(ctx context.Context, input specview.TranslationInput) (*specview.TranslationOutput, *specview.TokenUsage, error) {
+	return m.translateTextsFn(ctx, input)
+}
+
+type mockDocumentRepository struct {
+	*mockRepository
+	doc *specview.SpecDocument
+}
+
+func (m *mockDocumentRepository) GetDocument(ctx context.Context, documentID string) (*specview.SpecDocument, error) {
+	if m.doc == nil || m.doc.ID != documentID {
+		return nil, specview.ErrDocumentNotFound
+	}
+	return m.doc, nil
+}
+
+func TestGenerateSpecViewUseCase_Translations(t *testing.T) {
+	newProvider := func(calls *[]specview.TranslationInput, err error) *mockTranslator {
+		return &mockTranslator{
+			mockAIProvider: &mockAIProvider{
+				classifyDomainsFn: func(ctx context.Context, input specview.Phase1Input) (*specview.Phase1Output, *specview.TokenUsage, error) {
+					return newPhase1Output(), nil, nil
+				},
+				convertTestNamesFn: func(ctx context.Context, input specview.Phase2Input) (*specview.Phase2Output, *specview.TokenUsage, error) {
+					behaviors := make([]specview.BehaviorSpec, len(input.Tests))
+					for i, test := range input.Tests {
+						behaviors[i] = specview.BehaviorSpec{TestIndex: test.Index, Description: test.Name, Confidence: 0.9}
+					}
+					return &specview.Phase2Output{Behaviors: behaviors}, nil, nil
+				},
+			},
+			translateTextsFn: func(ctx context.Context, input specview.TranslationInput) (*specview.TranslationOutput, *specview.TokenUsage, error) {
+				*calls = append(*calls, input)
+				if err != nil {
+					return nil, nil, err
+				}
+				texts := make([]string, len(input.Texts))
+				for i, text := range input.Texts {
+					texts[i] = "EN " + text
+				}
+				return &specview.TranslationOutput{Texts: texts}, &specview.TokenUsage{TotalTokens: 10}, nil
+			},
+		}
+	}
+	newRepo := func(saved *[]*specview.SpecDocument, cached *[]specview.BehaviorCacheEntry) *mockRepository {
+		return &mockRepository{
+			getTestDataByAnalysisIDFn: func(ctx context.Context, analysisID string) ([]specview.FileInfo, error) {
+				return newTestFiles(), nil
+			},
+			saveBehaviorCacheFn: func(ctx context.Context, entries []specview.BehaviorCacheEntry) error {
+				*cached = append(*cached, entries...)
+				return nil
+			},
+			saveDocumentFn: func(ctx context.Context, doc *specview.SpecDocument) error {
+				doc.ID = "doc-" + string(doc.Language)
+				*saved = append(*saved, doc)
+				return nil
+			},
+		}
+	}
+
+	t.Run("saves a translated document per language", func(t *testing.T) {
+		var (
+			saved  []*specview.SpecDocument
+			cached []specview.BehaviorCacheEntry
+			calls  []specview.TranslationInput
+		)
+		req := newValidRequest()
+		req.TranslateTo = []specview.Language{"English", "Korean", "English"}
+
+		uc := NewGenerateSpecViewUseCase(newRepo(&saved, &cached), newProvider(&calls, nil), "gemini-2.5-flash")
+		result, err := uc.Execute(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(saved) != 2 || len(calls) != 1 {
+			t.Fatalf("expected the Korean and English documents from 1 translation call, got %d saves and %d calls", len(saved), len(calls))
+		}
+		if len(result.Translations) != 1 || result.Translations[0] != (specview.TranslatedDocument{DocumentID: "doc-English", Language: "English"}) {
+			t.Errorf("unexpected translations %+v", result.Translations)
+		}
+		if calls[0].SourceLanguage != "Korean" || calls[0].TargetLanguage != "English" {
+			t.Errorf("unexpected translation languages %+v", calls[0])
+		}
+
+		source, translated := saved[0], saved[1]
+		if string(translated.ContentHash) == string(source.ContentHash) {
+			t.Error("expected the translation to have its own content hash")
+		}
+		if len(translated.Domains) != len(source.Domains) {
+			t.Fatalf("expected the classification of the source, got %d domains", len(translated.Domains))
+		}
+		sourceBehavior := source.Domains[0].Features[0].Behaviors[0]
+		behavior := translated.Domains[0].Features[0].Behaviors[0]
+		if behavior.Description != "EN "+sourceBehavior.Description || behavior.TestCaseID != sourceBehavior.TestCaseID {
+			t.Errorf("unexpected translated behavior %+v", behavior)
+		}
+		if string(behavior.CacheKeyHash) == string(sourceBehavior.CacheKeyHash) || behavior.CacheKeyHash == nil {
+			t.Error("expected a cache key of the translation's language")
+		}
+		if translated.Domains[0].Slug != source.Domains[0].Slug || !strings.HasPrefix(translated.Domains[0].Name, "EN ") {
+			t.Errorf("expected the slug kept and the name translated, got %+v", translated.Domains[0])
+		}
+		if !strings.HasPrefix(translated.ExecutiveSummary, "EN ") {
+			t.Errorf("expected the executive summary translated, got %q", translated.ExecutiveSummary)
+		}
+		if len(translated.Decisions) != 1 || translated.Decisions[0].Kind != specview.DecisionDocumentTranslated {
+			t.Errorf("expected 1 document_translated decision, got %+v", translated.Decisions)
+		}
+		if source.Domains[0].Features[0].Behaviors[0].Description == behavior.Description {
+			t.Error("expected the source document to be left unchanged")
+		}
+
+		var translatedEntries int
+		for _, e := range cached {
+			if strings.HasPrefix(e.Description, "EN ") {
+				translatedEntries++
+			}
+		}
+		if translatedEntries != countTotalTestCases(newTestFiles()) {
+			t.Errorf("expected a translated cache entry per test, got %d", translatedEntries)
+		}
+	})
+
+	t.Run("reuses an earlier translation", func(t *testing.T) {
+		var (
+			saved  []*specview.SpecDocument
+			cached []specview.BehaviorCacheEntry
+			calls  []specview.TranslationInput
+		)
+		repo := newRepo(&saved, &cached)
+		repo.findDocumentByContentHashFn = func(ctx context.Context, userID string, contentHash []byte, language specview.Language, modelID string) (*specview.SpecDocument, error) {
+			if language == "English" {
+				return &specview.SpecDocument{ID: "doc-en-001"}, nil
+			}
+			return nil, nil
+		}
+		req := newValidRequest()
+		req.TranslateTo = []specview.Language{"English"}
+
+		result, err := NewGenerateSpecViewUseCase(repo, newProvider(&calls, nil), "gemini-2.5-flash").Execute(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(calls) != 0 || len(saved) != 1 {
+			t.Errorf("expected no translation, got %d calls and %d saves", len(calls), len(saved))
+		}
+		if len(result.Translations) != 1 || !result.Translations[0].CacheHit || result.Translations[0].DocumentID != "doc-en-001" {
+			t.Errorf("unexpected translations %+v", result.Translations)
+		}
+	})
+
+	t.Run("translates a cached document", func(t *testing.T) {
+		var (
+			saved  []*specview.SpecDocument
+			cached []specview.BehaviorCacheEntry
+			calls  []specview.TranslationInput
+		)
+		repo := &mockDocumentRepository{
+			mockRepository: newRepo(&saved, &cached),
+			doc: &specview.SpecDocument{
+				Domains: []specview.Domain{{
+					Name: "인증",
+					Slug: "auth",
+					Features: []specview.Feature{{
+						Name:      UncategorizedName,
+						Behaviors: []specview.Behavior{{Description: "로그인한다", TestCaseID: "tc-001"}},
+					}},
+				}},
+				ID:       "doc-ko-001",
+				Language: "Korean",
+				ModelID:  "gemini-2.5-flash",
+			},
+		}
+		repo.findDocumentByContentHashFn = func(ctx context.Context, userID string, contentHash []byte, language specview.Language, modelID string) (*specview.SpecDocument, error) {
+			if language == "Korean" {
+				return &specview.SpecDocument{ID: "doc-ko-001"}, nil
+			}
+			return nil, nil
+		}
+		req := newValidRequest()
+		req.TranslateTo = []specview.Language{"English"}
+
+		result, err := NewGenerateSpecViewUseCase(repo, newProvider(&calls, nil), "gemini-2.5-flash").Execute(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.CacheHit || len(result.Translations) != 1 || result.Translations[0].DocumentID != "doc-English" {
+			t.Fatalf("unexpected result %+v", result)
+		}
+		if len(calls) != 1 || strings.Join(calls[0].Texts, ",") != "인증,로그인한다" {
+			t.Errorf("expected the Uncategorized name left untranslated, got %+v", calls)
+		}
+		if feature := saved[0].Domains[0].Features[0]; feature.Name != UncategorizedName || saved[0].UserID != req.UserID {
+			t.Errorf("unexpected translated document %+v", saved[0])
+		}
+	})
+
+	t.Run("translation failure keeps the document", func(t *testing.T) {
+		var (
+			saved  []*specview.SpecDocument
+			cached []specview.BehaviorCacheEntry
+			calls  []specview.TranslationInput
+		)
+		req := newValidRequest()
+		req.TranslateTo = []specview.Language{"English"}
+
+		uc := NewGenerateSpecViewUseCase(newRepo(&saved, &cached), newProvider(&calls, errors.New("rate limited")), "gemini-2.5-flash")
+		result, err := uc.Execute(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(saved) != 1 || result.DocumentID == "" || len(result.Translations) != 0 {
+			t.Errorf("expected only the Korean document, got %d saves and %+v", len(saved), result.Translations)
+		}
+	})
+}
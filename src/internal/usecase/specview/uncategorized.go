@@ -5,7 +5,7 @@ import (
 )
 
 const (
-	UncategorizedName        = "Uncategorized"
+	UncategorizedName        = specview.UncategorizedName
 	UncategorizedDescription = "Uncategorized tests"
 	UncategorizedConfidence  = 0.0
 )
@@ -0,0 +1,128 @@
+package takeout
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/specview"
+)
+
+// Archive layout. Record files are JSON Lines, one record per line.
+const (
+	aiUsageFile   = "usage/ai_tokens.jsonl"
+	analysesFile  = "analyses.jsonl"
+	codebasesFile = "codebases.jsonl"
+	documentsDir  = "documents/" // <id>.json and the rendered <id>.md
+	manifestFile  = "manifest.json"
+	membersFile   = "members.jsonl"
+	testsDir      = "tests/" // <analysis id>.jsonl
+	usageFile     = "usage/events.jsonl"
+)
+
+// archiveWriter writes the files of a takeout archive and counts their records for the manifest.
+type archiveWriter struct {
+	counts map[string]int
+	zw     *zip.Writer
+}
+
+func newArchiveWriter(zw *zip.Writer) *archiveWriter {
+	return &archiveWriter{counts: make(map[string]int), zw: zw}
+}
+
+func (w *archiveWriter) writeFile(name string, data []byte) error {
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+func (w *archiveWriter) writeJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", name, err)
+	}
+	return w.writeFile(name, data)
+}
+
+func writeLines[T any](w *archiveWriter, name string, records []T) error {
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+	enc := json.NewEncoder(f)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	w.counts[name] = len(records)
+	return nil
+}
+
+// documentRecord is the machine-readable form of a spec document, with stable
+// field names independent of the internal model.
+type documentRecord struct {
+	AnalysisID       string         `json:"analysis_id"`
+	CreatedAt        time.Time      `json:"created_at"`
+	Domains          []domainRecord `json:"domains"`
+	ExecutiveSummary string         `json:"executive_summary,omitempty"`
+	ID               string         `json:"id"`
+	Language         string         `json:"language"`
+	ModelID          string         `json:"model_id"`
+	Project          string         `json:"project,omitempty"`
+	Repository       string         `json:"repository,omitempty"`
+	Team             string         `json:"team,omitempty"`
+	Version          int32          `json:"version"`
+}
+
+type domainRecord struct {
+	Description string          `json:"description,omitempty"`
+	Features    []featureRecord `json:"features"`
+	Name        string          `json:"name"`
+}
+
+type featureRecord struct {
+	Behaviors   []behaviorRecord `json:"behaviors"`
+	Description string           `json:"description,omitempty"`
+	Name        string           `json:"name"`
+}
+
+type behaviorRecord struct {
+	Description  string `json:"description"`
+	OriginalName string `json:"original_name"`
+}
+
+func newDocumentRecord(src specview.ExportSource) documentRecord {
+	doc := src.Document
+	rec := documentRecord{
+		AnalysisID:       doc.AnalysisID,
+		CreatedAt:        doc.CreatedAt,
+		Domains:          make([]domainRecord, len(doc.Domains)),
+		ExecutiveSummary: doc.ExecutiveSummary,
+		ID:               doc.ID,
+		Language:         string(doc.Language),
+		ModelID:          doc.ModelID,
+		Project:          doc.Project,
+		Repository:       src.Repository,
+		Team:             doc.Team,
+		Version:          doc.Version,
+	}
+	for i, d := range doc.Domains {
+		dr := domainRecord{Description: d.Description, Features: make([]featureRecord, len(d.Features)), Name: d.Name}
+		for j, f := range d.Features {
+			fr := featureRecord{Behaviors: make([]behaviorRecord, len(f.Behaviors)), Description: f.Description, Name: f.Name}
+			for k, b := range f.Behaviors {
+				fr.Behaviors[k] = behaviorRecord{Description: b.Description, OriginalName: b.OriginalName}
+			}
+			dr.Features[j] = fr
+		}
+		rec.Domains[i] = dr
+	}
+	return rec
+}
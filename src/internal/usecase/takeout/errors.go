@@ -0,0 +1,8 @@
+package takeout
+
+import "errors"
+
+var (
+	ErrAlreadyCompleted = errors.New("takeout already completed")
+	ErrExportFailed     = errors.New("failed to export tenant data")
+)
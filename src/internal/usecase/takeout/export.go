@@ -0,0 +1,239 @@
+package takeout
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/domain/takeout"
+)
+
+// Option configures an ExportTenantUseCase.
+type Option func(*ExportTenantUseCase)
+
+// WithURLTTL sets how long the download link of an archive works.
+// Zero or negative values are ignored and the default value is used;
+// values above takeout.MaxURLTTL are capped.
+func WithURLTTL(d time.Duration) Option {
+	return func(uc *ExportTenantUseCase) {
+		if d > 0 {
+			uc.urlTTL = min(d, takeout.MaxURLTTL)
+		}
+	}
+}
+
+// ExportTenantUseCase builds the full export of a tenant's data, uploads it
+// to object storage and records a signed download link.
+type ExportTenantUseCase struct {
+	documents specview.ExportRepository
+	now       func() time.Time
+	repo      takeout.Repository
+	store     takeout.ObjectStore
+	urlTTL    time.Duration
+}
+
+// NewExportTenantUseCase creates a new ExportTenantUseCase. Documents are
+// read through the export repository, which opens encrypted documents.
+func NewExportTenantUseCase(
+	repo takeout.Repository,
+	documents specview.ExportRepository,
+	store takeout.ObjectStore,
+	opts ...Option,
+) *ExportTenantUseCase {
+	uc := &ExportTenantUseCase{
+		documents: documents,
+		now:       time.Now,
+		repo:      repo,
+		store:     store,
+		urlTTL:    takeout.DefaultURLTTL,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute exports the tenant of the takeout. The archive is built in a
+// temporary file, so its size is bounded by local disk rather than memory.
+// Returns ErrAlreadyCompleted when an earlier attempt finished the takeout.
+func (uc *ExportTenantUseCase) Execute(ctx context.Context, takeoutID string) (*takeout.Archive, error) {
+	if takeoutID == "" {
+		return nil, fmt.Errorf("%w: takeout ID is required", takeout.ErrInvalidInput)
+	}
+
+	t, err := uc.repo.GetTakeout(ctx, takeoutID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExportFailed, err)
+	}
+	started, err := uc.repo.StartTakeout(ctx, t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExportFailed, err)
+	}
+	if !started {
+		return nil, ErrAlreadyCompleted
+	}
+
+	file, err := os.CreateTemp("", "takeout-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("%w: create archive file: %w", ErrExportFailed, err)
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	if err := uc.writeArchive(ctx, file, t); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExportFailed, err)
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: rewind archive file: %w", ErrExportFailed, err)
+	}
+
+	archive := takeout.Archive{
+		ExpiresAt: uc.now().Add(uc.urlTTL),
+		ObjectKey: objectKey(t),
+		SizeBytes: size,
+	}
+	if err := uc.store.Put(ctx, archive.ObjectKey, file, size, "application/zip"); err != nil {
+		return nil, fmt.Errorf("%w: upload archive: %w", ErrExportFailed, err)
+	}
+	archive.DownloadURL, err = uc.store.SignedURL(ctx, archive.ObjectKey, uc.urlTTL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sign download link: %w", ErrExportFailed, err)
+	}
+
+	if err := uc.repo.CompleteTakeout(ctx, t.ID, archive); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExportFailed, err)
+	}
+	return &archive, nil
+}
+
+// Fail records that the takeout was given up on.
+func (uc *ExportTenantUseCase) Fail(ctx context.Context, takeoutID string, cause error) error {
+	return uc.repo.FailTakeout(ctx, takeoutID, cause.Error())
+}
+
+func objectKey(t *takeout.Takeout) string {
+	return "takeouts/" + t.TenantID + "/" + t.ID + ".zip"
+}
+
+func (uc *ExportTenantUseCase) writeArchive(ctx context.Context, w io.Writer, t *takeout.Takeout) error {
+	zw := zip.NewWriter(w)
+	aw := newArchiveWriter(zw)
+
+	members, err := uc.repo.ListMembers(ctx, t.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := writeLines(aw, membersFile, members); err != nil {
+		return err
+	}
+
+	codebases, err := uc.repo.ListCodebases(ctx, t.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := writeLines(aw, codebasesFile, codebases); err != nil {
+		return err
+	}
+
+	analyses, err := uc.repo.ListAnalyses(ctx, t.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := writeLines(aw, analysesFile, analyses); err != nil {
+		return err
+	}
+	for _, a := range analyses {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		tests, err := uc.repo.ListTests(ctx, a.ID)
+		if err != nil {
+			return err
+		}
+		if len(tests) == 0 {
+			continue
+		}
+		if err := writeLines(aw, testsDir+a.ID+".jsonl", tests); err != nil {
+			return err
+		}
+	}
+
+	if err := uc.writeDocuments(ctx, aw, t.TenantID); err != nil {
+		return err
+	}
+
+	events, err := uc.repo.ListUsageEvents(ctx, t.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := writeLines(aw, usageFile, events); err != nil {
+		return err
+	}
+
+	aiUsage, err := uc.repo.ListAIUsage(ctx, t.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := writeLines(aw, aiUsageFile, aiUsage); err != nil {
+		return err
+	}
+
+	if err := aw.writeJSON(manifestFile, takeout.Manifest{
+		Counts:        aw.counts,
+		FormatVersion: takeout.FormatVersion,
+		GeneratedAt:   uc.now().UTC(),
+		TakeoutID:     t.ID,
+		TenantID:      t.TenantID,
+		TenantName:    t.TenantName,
+	}); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeDocuments writes every document as JSON and as rendered Markdown.
+// A document deleted while the takeout runs is skipped.
+func (uc *ExportTenantUseCase) writeDocuments(ctx context.Context, aw *archiveWriter, tenantID string) error {
+	ids, err := uc.repo.ListDocumentIDs(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	written := 0
+	for _, id := range ids {
+		src, err := uc.documents.GetExportSource(ctx, id)
+		if errors.Is(err, specview.ErrDocumentNotFound) {
+			slog.WarnContext(ctx, "document deleted during takeout, skipping", "document_id", id)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("load document %s: %w", id, err)
+		}
+
+		if err := aw.writeJSON(documentsDir+id+".json", newDocumentRecord(*src)); err != nil {
+			return err
+		}
+		rendered, err := specview.RenderDocument(*src, specview.ExportMarkdown)
+		if err != nil {
+			return fmt.Errorf("render document %s: %w", id, err)
+		}
+		if err := aw.writeFile(documentsDir+id+".md", rendered.Data); err != nil {
+			return err
+		}
+		written++
+	}
+	aw.counts[documentsDir] = written
+	return nil
+}
@@ -0,0 +1,243 @@
+package takeout
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/specview"
+	"github.com/specvital/worker/internal/domain/takeout"
+)
+
+type mockRepository struct {
+	completed *takeout.Archive
+	status    takeout.Status
+}
+
+func (m *mockRepository) GetTakeout(_ context.Context, id string) (*takeout.Takeout, error) {
+	if id != "to-1" {
+		return nil, takeout.ErrNotFound
+	}
+	return &takeout.Takeout{ID: id, Status: m.status, TenantID: "tenant-1", TenantName: "Acme"}, nil
+}
+
+func (m *mockRepository) StartTakeout(_ context.Context, _ string) (bool, error) {
+	if m.status == takeout.StatusCompleted {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (m *mockRepository) CompleteTakeout(_ context.Context, _ string, archive takeout.Archive) error {
+	m.completed = &archive
+	return nil
+}
+
+func (m *mockRepository) FailTakeout(_ context.Context, _ string, _ string) error { return nil }
+
+func (m *mockRepository) ListMembers(_ context.Context, _ string) ([]takeout.Member, error) {
+	return []takeout.Member{{UserID: "user-1"}, {UserID: "user-2"}}, nil
+}
+
+func (m *mockRepository) ListCodebases(_ context.Context, _ string) ([]takeout.Codebase, error) {
+	return []takeout.Codebase{{ID: "cb-1", Host: "github.com", Name: "shop", Owner: "acme"}}, nil
+}
+
+func (m *mockRepository) ListAnalyses(_ context.Context, _ string) ([]takeout.Analysis, error) {
+	return []takeout.Analysis{{CodebaseID: "cb-1", ID: "an-1"}, {CodebaseID: "cb-1", ID: "an-2"}}, nil
+}
+
+func (m *mockRepository) ListTests(_ context.Context, analysisID string) ([]takeout.Test, error) {
+	if analysisID != "an-1" {
+		return nil, nil
+	}
+	return []takeout.Test{{FilePath: "cart_test.go", Name: "TestAdd", SuitePath: "Cart"}}, nil
+}
+
+func (m *mockRepository) ListDocumentIDs(_ context.Context, _ string) ([]string, error) {
+	return []string{"doc-1", "doc-deleted"}, nil
+}
+
+func (m *mockRepository) ListUsageEvents(_ context.Context, _ string) ([]takeout.UsageEvent, error) {
+	return []takeout.UsageEvent{{DocumentID: "doc-1", EventType: "specview", ID: "ev-1", QuotaAmount: 3}}, nil
+}
+
+func (m *mockRepository) ListAIUsage(_ context.Context, _ string) ([]takeout.AIUsage, error) {
+	return nil, nil
+}
+
+type mockDocuments struct{}
+
+func (mockDocuments) GetExportSource(_ context.Context, documentID string) (*specview.ExportSource, error) {
+	if documentID != "doc-1" {
+		return nil, specview.ErrDocumentNotFound
+	}
+	return &specview.ExportSource{
+		Document: &specview.SpecDocument{
+			ID: documentID,
+			Domains: []specview.Domain{{Name: "Cart", Features: []specview.Feature{{
+				Name:      "Add",
+				Behaviors: []specview.Behavior{{Description: "Adds an item", OriginalName: "TestAdd"}},
+			}}}},
+		},
+		Repository: "acme/shop",
+	}, nil
+}
+
+func (mockDocuments) SaveExport(_ context.Context, _ *specview.ExportArtifact) error { return nil }
+
+type memoryStore struct {
+	data    []byte
+	key     string
+	putErr  error
+	signTTL time.Duration
+}
+
+func (s *memoryStore) Put(_ context.Context, key string, body io.Reader, size int64, _ string) error {
+	if s.putErr != nil {
+		return s.putErr
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != size {
+		return errors.New("size mismatch")
+	}
+	s.data, s.key = data, key
+	return nil
+}
+
+func (s *memoryStore) SignedURL(_ context.Context, key string, ttl time.Duration) (string, error) {
+	s.signTTL = ttl
+	return "https://storage.example.com/" + key + "?sig", nil
+}
+
+func readArchive(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	files := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestExportTenantUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("uploads the archive and records the signed link", func(t *testing.T) {
+		repo := &mockRepository{status: takeout.StatusPending}
+		store := &memoryStore{}
+		uc := NewExportTenantUseCase(repo, mockDocuments{}, store, WithURLTTL(24*time.Hour))
+
+		archive, err := uc.Execute(ctx, "to-1")
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if store.key != "takeouts/tenant-1/to-1.zip" || archive.ObjectKey != store.key {
+			t.Errorf("object key = %q, archive key = %q", store.key, archive.ObjectKey)
+		}
+		if repo.completed == nil || repo.completed.DownloadURL != "https://storage.example.com/takeouts/tenant-1/to-1.zip?sig" {
+			t.Fatalf("expected the completed takeout recorded, got %+v", repo.completed)
+		}
+		if store.signTTL != 24*time.Hour || archive.SizeBytes != int64(len(store.data)) {
+			t.Errorf("ttl = %v, size = %d, uploaded %d bytes", store.signTTL, archive.SizeBytes, len(store.data))
+		}
+
+		files := readArchive(t, store.data)
+		for _, name := range []string{"manifest.json", "members.jsonl", "codebases.jsonl", "analyses.jsonl",
+			"tests/an-1.jsonl", "documents/doc-1.json", "documents/doc-1.md", "usage/events.jsonl", "usage/ai_tokens.jsonl"} {
+			if _, ok := files[name]; !ok {
+				t.Errorf("archive lacks %s", name)
+			}
+		}
+		if _, ok := files["tests/an-2.jsonl"]; ok {
+			t.Error("expected no test file for an analysis without tests")
+		}
+		if !strings.Contains(files["documents/doc-1.md"], "Adds an item") {
+			t.Errorf("rendered document lacks the behavior: %q", files["documents/doc-1.md"])
+		}
+
+		var manifest takeout.Manifest
+		if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil {
+			t.Fatalf("decode manifest: %v", err)
+		}
+		if manifest.TenantName != "Acme" || manifest.FormatVersion != takeout.FormatVersion {
+			t.Errorf("unexpected manifest %+v", manifest)
+		}
+		if manifest.Counts["members.jsonl"] != 2 || manifest.Counts["tests/an-1.jsonl"] != 1 || manifest.Counts["documents/"] != 1 {
+			t.Errorf("unexpected counts %v; the deleted document should be skipped", manifest.Counts)
+		}
+	})
+
+	t.Run("skips a completed takeout", func(t *testing.T) {
+		store := &memoryStore{}
+		uc := NewExportTenantUseCase(&mockRepository{status: takeout.StatusCompleted}, mockDocuments{}, store)
+
+		if _, err := uc.Execute(ctx, "to-1"); !errors.Is(err, ErrAlreadyCompleted) {
+			t.Errorf("expected ErrAlreadyCompleted, got %v", err)
+		}
+		if store.data != nil {
+			t.Error("expected no upload")
+		}
+	})
+
+	t.Run("does not complete the takeout when the upload fails", func(t *testing.T) {
+		repo := &mockRepository{status: takeout.StatusPending}
+		uc := NewExportTenantUseCase(repo, mockDocuments{}, &memoryStore{putErr: errors.New("bucket unavailable")})
+
+		if _, err := uc.Execute(ctx, "to-1"); !errors.Is(err, ErrExportFailed) {
+			t.Errorf("expected ErrExportFailed, got %v", err)
+		}
+		if repo.completed != nil {
+			t.Error("expected the takeout not completed")
+		}
+	})
+
+	t.Run("rejects an unknown takeout", func(t *testing.T) {
+		uc := NewExportTenantUseCase(&mockRepository{}, mockDocuments{}, &memoryStore{})
+
+		if _, err := uc.Execute(ctx, ""); !errors.Is(err, takeout.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+		if _, err := uc.Execute(ctx, "to-missing"); !errors.Is(err, takeout.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestWithURLTTL(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{"positive", time.Hour, time.Hour},
+		{"zero uses default", 0, takeout.DefaultURLTTL},
+		{"above the limit is capped", 30 * 24 * time.Hour, takeout.MaxURLTTL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewExportTenantUseCase(nil, nil, nil, WithURLTTL(tt.ttl))
+			if uc.urlTTL != tt.want {
+				t.Errorf("urlTTL = %v, want %v", uc.urlTTL, tt.want)
+			}
+		})
+	}
+}
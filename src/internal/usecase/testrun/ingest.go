@@ -0,0 +1,83 @@
+package testrun
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/specvital/worker/internal/domain/testrun"
+)
+
+// IngestRequest names an uploaded test report.
+type IngestRequest struct {
+	AnalysisID string // optional; when set, the upload must belong to it
+	UploadID   string
+}
+
+// IngestSummary reports what an ingestion matched.
+type IngestSummary struct {
+	AlreadyProcessed bool
+	Failing          int // matched test cases whose last run failed
+	Flaky            int // matched test cases that both passed and failed
+	Matched          int // test cases with results
+	Results          int
+	Unmatched        int // results that matched no test case
+}
+
+// IngestTestResultsUseCase matches an uploaded CI test report to the
+// inventory of its analysis and stores the last-run results.
+type IngestTestResultsUseCase struct {
+	repository testrun.Repository
+}
+
+// NewIngestTestResultsUseCase creates a new IngestTestResultsUseCase.
+func NewIngestTestResultsUseCase(repository testrun.Repository) *IngestTestResultsUseCase {
+	return &IngestTestResultsUseCase{repository: repository}
+}
+
+// Execute parses the upload and saves the results of the test cases it matches.
+func (uc *IngestTestResultsUseCase) Execute(ctx context.Context, req IngestRequest) (*IngestSummary, error) {
+	if req.UploadID == "" {
+		return nil, fmt.Errorf("%w: upload ID is required", testrun.ErrInvalidInput)
+	}
+
+	upload, err := uc.repository.GetUpload(ctx, req.UploadID)
+	if err != nil {
+		return nil, err
+	}
+	if req.AnalysisID != "" && req.AnalysisID != upload.AnalysisID {
+		return nil, ErrAnalysisMismatch
+	}
+
+	results, err := testrun.Parse(upload.Format, bytes.NewReader(upload.Content))
+	if err != nil {
+		return nil, err
+	}
+
+	cases, err := uc.repository.GetTestCases(ctx, upload.AnalysisID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIngestFailed, err)
+	}
+
+	matched, unmatched := testrun.Match(results, cases)
+	saved, err := uc.repository.SaveResults(ctx, upload.ID, matched)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIngestFailed, err)
+	}
+
+	summary := &IngestSummary{
+		AlreadyProcessed: !saved,
+		Matched:          len(matched),
+		Results:          len(results),
+		Unmatched:        unmatched,
+	}
+	for _, r := range matched {
+		if r.Status == testrun.StatusFailed {
+			summary.Failing++
+		}
+		if r.Flaky() {
+			summary.Flaky++
+		}
+	}
+	return summary, nil
+}
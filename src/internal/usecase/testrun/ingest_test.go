@@ -0,0 +1,105 @@
+package testrun
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/specvital/worker/internal/domain/testrun"
+)
+
+const junitReport = `<testsuite file="src/auth.test.ts">
+  <testcase classname="Auth" name="logs in"/>
+  <testcase classname="Auth" name="logs in"><failure/></testcase>
+  <testcase classname="Auth" name="logs out"><failure/></testcase>
+  <testcase classname="Billing" name="charges"/>
+</testsuite>`
+
+type mockRepository struct {
+	saveResultsFn func(ctx context.Context, uploadID string, results []testrun.CaseResult) (bool, error)
+	upload        *testrun.Upload
+}
+
+func (m *mockRepository) GetUpload(_ context.Context, uploadID string) (*testrun.Upload, error) {
+	if m.upload == nil || m.upload.ID != uploadID {
+		return nil, testrun.ErrUploadNotFound
+	}
+	return m.upload, nil
+}
+
+func (m *mockRepository) GetTestCases(_ context.Context, _ string) ([]testrun.TestCase, error) {
+	return []testrun.TestCase{
+		{FilePath: "src/auth.test.ts", ID: "tc-1", Name: "logs in", SuitePath: "Auth"},
+		{FilePath: "src/auth.test.ts", ID: "tc-2", Name: "logs out", SuitePath: "Auth"},
+	}, nil
+}
+
+func (m *mockRepository) SaveResults(ctx context.Context, uploadID string, results []testrun.CaseResult) (bool, error) {
+	if m.saveResultsFn != nil {
+		return m.saveResultsFn(ctx, uploadID, results)
+	}
+	return true, nil
+}
+
+func TestIngestTestResultsUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	upload := &testrun.Upload{AnalysisID: "an-1", Content: []byte(junitReport), Format: testrun.FormatJUnit, ID: "up-1"}
+
+	t.Run("saves matched results and summarizes them", func(t *testing.T) {
+		var saved []testrun.CaseResult
+		repo := &mockRepository{upload: upload, saveResultsFn: func(_ context.Context, _ string, results []testrun.CaseResult) (bool, error) {
+			saved = results
+			return true, nil
+		}}
+
+		summary, err := NewIngestTestResultsUseCase(repo).Execute(ctx, IngestRequest{AnalysisID: "an-1", UploadID: "up-1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := IngestSummary{Failing: 2, Flaky: 1, Matched: 2, Results: 4, Unmatched: 1}
+		if *summary != want {
+			t.Errorf("summary = %+v, want %+v", *summary, want)
+		}
+		if len(saved) != 2 || saved[0].TestCaseID != "tc-1" || saved[0].Runs != 2 {
+			t.Errorf("unexpected saved results: %+v", saved)
+		}
+	})
+
+	t.Run("reports an already processed upload", func(t *testing.T) {
+		repo := &mockRepository{upload: upload, saveResultsFn: func(context.Context, string, []testrun.CaseResult) (bool, error) {
+			return false, nil
+		}}
+
+		summary, err := NewIngestTestResultsUseCase(repo).Execute(ctx, IngestRequest{UploadID: "up-1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !summary.AlreadyProcessed {
+			t.Error("expected AlreadyProcessed")
+		}
+	})
+
+	t.Run("rejects an upload of another analysis", func(t *testing.T) {
+		_, err := NewIngestTestResultsUseCase(&mockRepository{upload: upload}).Execute(ctx, IngestRequest{AnalysisID: "an-2", UploadID: "up-1"})
+		if !errors.Is(err, ErrAnalysisMismatch) {
+			t.Errorf("expected ErrAnalysisMismatch, got %v", err)
+		}
+	})
+
+	t.Run("returns a missing upload as is", func(t *testing.T) {
+		_, err := NewIngestTestResultsUseCase(&mockRepository{}).Execute(ctx, IngestRequest{UploadID: "up-1"})
+		if !errors.Is(err, testrun.ErrUploadNotFound) {
+			t.Errorf("expected ErrUploadNotFound, got %v", err)
+		}
+	})
+
+	t.Run("wraps save failures", func(t *testing.T) {
+		repo := &mockRepository{upload: upload, saveResultsFn: func(context.Context, string, []testrun.CaseResult) (bool, error) {
+			return false, errors.New("connection reset")
+		}}
+		_, err := NewIngestTestResultsUseCase(repo).Execute(ctx, IngestRequest{UploadID: "up-1"})
+		if !errors.Is(err, ErrIngestFailed) {
+			t.Errorf("expected ErrIngestFailed, got %v", err)
+		}
+	})
+}
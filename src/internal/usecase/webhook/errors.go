@@ -0,0 +1,8 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrEnqueueFailed = errors.New("failed to enqueue analysis")
+	ErrLookupFailed  = errors.New("failed to look up codebase")
+)
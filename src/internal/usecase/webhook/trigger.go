@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/webhook"
+)
+
+// TriggerUseCase enqueues analyses for webhook events of registered codebases.
+type TriggerUseCase struct {
+	deliveries  webhook.DeliveryStore
+	deliveryTTL time.Duration
+	enqueuer    webhook.AnalysisEnqueuer
+	idempotent  webhook.IdempotentAnalysisEnqueuer
+	repository  webhook.Repository
+}
+
+// Option configures TriggerUseCase.
+type Option func(*TriggerUseCase)
+
+// WithDeliveryStore deduplicates triggers by delivery ID.
+func WithDeliveryStore(store webhook.DeliveryStore) Option {
+	return func(uc *TriggerUseCase) {
+		uc.deliveries = store
+	}
+}
+
+// WithDeliveryTTL sets how long a delivery ID is remembered.
+func WithDeliveryTTL(ttl time.Duration) Option {
+	return func(uc *TriggerUseCase) {
+		if ttl > 0 {
+			uc.deliveryTTL = ttl
+		}
+	}
+}
+
+// NewTriggerUseCase creates a new TriggerUseCase.
+func NewTriggerUseCase(repository webhook.Repository, enqueuer webhook.AnalysisEnqueuer, opts ...Option) *TriggerUseCase {
+	uc := &TriggerUseCase{
+		deliveryTTL: webhook.DefaultDeliveryTTL,
+		enqueuer:    enqueuer,
+		repository:  repository,
+	}
+	if idempotent, ok := enqueuer.(webhook.IdempotentAnalysisEnqueuer); ok {
+		uc.idempotent = idempotent
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute enqueues an analysis of the trigger commit.
+// Repositories never analyzed before are ignored: webhooks should not register
+// new codebases. Commits already analyzed or in progress on the branch are skipped; River's
+// unique args also drop duplicates of jobs still waiting in the queue.
+// With a delivery store, a redelivered trigger returns the outcome of the first delivery
+// without being processed again. When the enqueuer supports idempotency keys, the
+// delivery ID also keys the job, which covers a first attempt that enqueued but
+// crashed before completing its delivery.
+func (uc *TriggerUseCase) Execute(ctx context.Context, trigger webhook.Trigger) (webhook.Outcome, error) {
+	if err := trigger.Validate(); err != nil {
+		return "", err
+	}
+
+	if uc.deliveries == nil || trigger.DeliveryID == "" {
+		return uc.trigger(ctx, trigger)
+	}
+
+	claimed, previous, err := uc.deliveries.ClaimDelivery(ctx, trigger.DeliveryID, trigger.Event, time.Now().Add(uc.deliveryTTL))
+	if err != nil {
+		// The commit and queue checks still catch most duplicates.
+		slog.WarnContext(ctx, "failed to record webhook delivery (non-critical)",
+			"delivery", trigger.DeliveryID,
+			"error", err,
+		)
+		return uc.trigger(ctx, trigger)
+	}
+	if !claimed {
+		if previous == "" {
+			previous = webhook.OutcomeDeliveryInProgress
+		}
+		slog.InfoContext(ctx, "duplicate webhook delivery ignored",
+			"delivery", trigger.DeliveryID,
+			"event", trigger.Event,
+			"outcome", previous,
+		)
+		return previous, nil
+	}
+
+	outcome, err := uc.trigger(ctx, trigger)
+	if err != nil {
+		if releaseErr := uc.deliveries.ReleaseDelivery(ctx, trigger.DeliveryID); releaseErr != nil {
+			slog.WarnContext(ctx, "failed to release webhook delivery (non-critical)",
+				"delivery", trigger.DeliveryID,
+				"error", releaseErr,
+			)
+		}
+		return "", err
+	}
+	if err := uc.deliveries.CompleteDelivery(ctx, trigger.DeliveryID, outcome); err != nil {
+		slog.WarnContext(ctx, "failed to complete webhook delivery (non-critical)",
+			"delivery", trigger.DeliveryID,
+			"error", err,
+		)
+	}
+	return outcome, nil
+}
+
+func (uc *TriggerUseCase) trigger(ctx context.Context, trigger webhook.Trigger) (webhook.Outcome, error) {
+	codebase, err := uc.repository.FindCodebase(ctx, trigger.Host, trigger.ExternalRepoID)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrLookupFailed, err)
+	}
+	if codebase == nil {
+		return webhook.OutcomeUnknownCodebase, nil
+	}
+
+	analyzed, err := uc.repository.HasAnalysisForCommit(ctx, codebase.ID, trigger.Branch, trigger.CommitSHA)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrLookupFailed, err)
+	}
+	if analyzed {
+		return webhook.OutcomeAlreadyAnalyzed, nil
+	}
+
+	// The payload names the repository as it is now, which handles renames.
+	if err := uc.enqueue(ctx, trigger); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrEnqueueFailed, err)
+	}
+
+	slog.InfoContext(ctx, "analysis enqueued from webhook",
+		"delivery", trigger.DeliveryID,
+		"event", trigger.Event,
+		"owner", trigger.Owner,
+		"repo", trigger.Repo,
+		"branch", trigger.Branch,
+		"commit", trigger.CommitSHA,
+	)
+	return webhook.OutcomeEnqueued, nil
+}
+
+func (uc *TriggerUseCase) enqueue(ctx context.Context, trigger webhook.Trigger) error {
+	if uc.idempotent == nil || trigger.DeliveryID == "" {
+		return uc.enqueuer.EnqueueBranchAnalysis(ctx, trigger.Owner, trigger.Repo, trigger.Branch, trigger.CommitSHA)
+	}
+	_, err := uc.idempotent.EnqueueIdempotentAnalysis(ctx, webhook.DeliveryIdempotencyKey(trigger.DeliveryID),
+		trigger.Owner, trigger.Repo, trigger.Branch, trigger.CommitSHA, nil)
+	return err
+}
@@ -0,0 +1,266 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/specvital/worker/internal/domain/analysis"
+	"github.com/specvital/worker/internal/domain/webhook"
+)
+
+type mockRepository struct {
+	analyzed     bool
+	codebase     *webhook.Codebase
+	err          error
+	lookupBranch string
+	lookupSHA    string
+}
+
+func (m *mockRepository) FindCodebase(_ context.Context, _, _ string) (*webhook.Codebase, error) {
+	return m.codebase, m.err
+}
+
+func (m *mockRepository) HasAnalysisForCommit(_ context.Context, _ analysis.UUID, branch, commitSHA string) (bool, error) {
+	m.lookupBranch = branch
+	m.lookupSHA = commitSHA
+	return m.analyzed, nil
+}
+
+type mockEnqueuer struct {
+	calls []string
+	err   error
+}
+
+func (m *mockEnqueuer) EnqueueBranchAnalysis(_ context.Context, owner, repo, branch, commitSHA string) error {
+	m.calls = append(m.calls, owner+"/"+repo+":"+branch+"@"+commitSHA)
+	return m.err
+}
+
+type mockIdempotentEnqueuer struct {
+	mockEnqueuer
+	keys map[string]bool
+}
+
+func (m *mockIdempotentEnqueuer) EnqueueIdempotentAnalysis(ctx context.Context, key, owner, repo, branch, commitSHA string, _ *analysis.PathFilters) (webhook.EnqueueResult, error) {
+	if m.keys[key] {
+		return webhook.EnqueueResult{Duplicate: true, JobID: 1}, nil
+	}
+	if err := m.EnqueueBranchAnalysis(ctx, owner, repo, branch, commitSHA); err != nil {
+		return webhook.EnqueueResult{}, err
+	}
+	m.keys[key] = true
+	return webhook.EnqueueResult{JobID: 1}, nil
+}
+
+func (m *mockIdempotentEnqueuer) LookupIdempotencyKey(context.Context, string) (*webhook.EnqueueResult, error) {
+	return nil, nil
+}
+
+type mockDeliveryStore struct {
+	claimErr  error
+	completed map[string]webhook.Outcome
+	pending   map[string]bool
+	released  []string
+}
+
+func newMockDeliveryStore() *mockDeliveryStore {
+	return &mockDeliveryStore{completed: make(map[string]webhook.Outcome), pending: make(map[string]bool)}
+}
+
+func (m *mockDeliveryStore) ClaimDelivery(_ context.Context, deliveryID, _ string, _ time.Time) (bool, webhook.Outcome, error) {
+	if m.claimErr != nil {
+		return false, "", m.claimErr
+	}
+	if outcome, ok := m.completed[deliveryID]; ok {
+		return false, outcome, nil
+	}
+	if m.pending[deliveryID] {
+		return false, "", nil
+	}
+	m.pending[deliveryID] = true
+	return true, "", nil
+}
+
+func (m *mockDeliveryStore) CompleteDelivery(_ context.Context, deliveryID string, outcome webhook.Outcome) error {
+	delete(m.pending, deliveryID)
+	m.completed[deliveryID] = outcome
+	return nil
+}
+
+func (m *mockDeliveryStore) ReleaseDelivery(_ context.Context, deliveryID string) error {
+	delete(m.pending, deliveryID)
+	m.released = append(m.released, deliveryID)
+	return nil
+}
+
+func validTrigger() webhook.Trigger {
+	return webhook.Trigger{
+		Branch:         "main",
+		CommitSHA:      "abc123",
+		Event:          "push",
+		ExternalRepoID: "42",
+		Host:           "github.com",
+		Owner:          "octocat",
+		Repo:           "renamed",
+	}
+}
+
+func TestTriggerUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	codebase := &webhook.Codebase{ID: analysis.NewUUID()}
+
+	t.Run("enqueues new commits of registered codebases with payload names", func(t *testing.T) {
+		repo := &mockRepository{codebase: codebase}
+		enqueuer := &mockEnqueuer{}
+
+		outcome, err := NewTriggerUseCase(repo, enqueuer).Execute(ctx, validTrigger())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if outcome != webhook.OutcomeEnqueued {
+			t.Errorf("outcome = %q, want enqueued", outcome)
+		}
+		if len(enqueuer.calls) != 1 || enqueuer.calls[0] != "octocat/renamed:main@abc123" {
+			t.Errorf("unexpected enqueue calls: %v", enqueuer.calls)
+		}
+		if repo.lookupBranch != "main" || repo.lookupSHA != "abc123" {
+			t.Errorf("dedup lookup = %s@%s, want main@abc123", repo.lookupBranch, repo.lookupSHA)
+		}
+	})
+
+	t.Run("ignores unregistered repositories", func(t *testing.T) {
+		enqueuer := &mockEnqueuer{}
+
+		outcome, err := NewTriggerUseCase(&mockRepository{}, enqueuer).Execute(ctx, validTrigger())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if outcome != webhook.OutcomeUnknownCodebase || len(enqueuer.calls) != 0 {
+			t.Errorf("outcome = %q, calls = %v; want unknown_codebase without enqueue", outcome, enqueuer.calls)
+		}
+	})
+
+	t.Run("skips already analyzed commits", func(t *testing.T) {
+		enqueuer := &mockEnqueuer{}
+
+		outcome, err := NewTriggerUseCase(&mockRepository{analyzed: true, codebase: codebase}, enqueuer).Execute(ctx, validTrigger())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if outcome != webhook.OutcomeAlreadyAnalyzed || len(enqueuer.calls) != 0 {
+			t.Errorf("outcome = %q, calls = %v; want already_analyzed without enqueue", outcome, enqueuer.calls)
+		}
+	})
+
+	t.Run("rejects invalid triggers", func(t *testing.T) {
+		trigger := validTrigger()
+		trigger.CommitSHA = ""
+
+		_, err := NewTriggerUseCase(&mockRepository{}, &mockEnqueuer{}).Execute(ctx, trigger)
+		if !errors.Is(err, webhook.ErrInvalidTrigger) {
+			t.Errorf("expected ErrInvalidTrigger, got %v", err)
+		}
+
+		trigger = validTrigger()
+		trigger.Branch = "-x"
+		_, err = NewTriggerUseCase(&mockRepository{}, &mockEnqueuer{}).Execute(ctx, trigger)
+		if !errors.Is(err, webhook.ErrInvalidTrigger) {
+			t.Errorf("expected ErrInvalidTrigger for invalid branch, got %v", err)
+		}
+	})
+
+	t.Run("wraps lookup and enqueue failures", func(t *testing.T) {
+		_, err := NewTriggerUseCase(&mockRepository{err: errors.New("db down")}, &mockEnqueuer{}).Execute(ctx, validTrigger())
+		if !errors.Is(err, ErrLookupFailed) {
+			t.Errorf("expected ErrLookupFailed, got %v", err)
+		}
+
+		_, err = NewTriggerUseCase(&mockRepository{codebase: codebase}, &mockEnqueuer{err: errors.New("queue down")}).Execute(ctx, validTrigger())
+		if !errors.Is(err, ErrEnqueueFailed) {
+			t.Errorf("expected ErrEnqueueFailed, got %v", err)
+		}
+	})
+}
+
+func TestTriggerUseCase_Execute_DeliveryDedup(t *testing.T) {
+	ctx := context.Background()
+	codebase := &webhook.Codebase{ID: analysis.NewUUID()}
+	trigger := validTrigger()
+	trigger.DeliveryID = "72d3162e-cc78-11e3-81ab-4c9367dc0958"
+
+	t.Run("replays the first outcome for redeliveries", func(t *testing.T) {
+		store := newMockDeliveryStore()
+		enqueuer := &mockEnqueuer{}
+		uc := NewTriggerUseCase(&mockRepository{codebase: codebase}, enqueuer, WithDeliveryStore(store))
+
+		for i := 0; i < 2; i++ {
+			outcome, err := uc.Execute(ctx, trigger)
+			if err != nil {
+				t.Fatalf("delivery %d: unexpected error: %v", i, err)
+			}
+			if outcome != webhook.OutcomeEnqueued {
+				t.Errorf("delivery %d: outcome = %q, want enqueued", i, outcome)
+			}
+		}
+		if len(enqueuer.calls) != 1 {
+			t.Errorf("expected one enqueue, got %v", enqueuer.calls)
+		}
+	})
+
+	t.Run("reports deliveries still in progress", func(t *testing.T) {
+		store := newMockDeliveryStore()
+		store.pending[trigger.DeliveryID] = true
+		enqueuer := &mockEnqueuer{}
+
+		outcome, err := NewTriggerUseCase(&mockRepository{codebase: codebase}, enqueuer, WithDeliveryStore(store)).Execute(ctx, trigger)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if outcome != webhook.OutcomeDeliveryInProgress || len(enqueuer.calls) != 0 {
+			t.Errorf("outcome = %q, calls = %v; want delivery_in_progress without enqueue", outcome, enqueuer.calls)
+		}
+	})
+
+	t.Run("releases failed deliveries for retry", func(t *testing.T) {
+		store := newMockDeliveryStore()
+		enqueuer := &mockEnqueuer{err: errors.New("queue down")}
+		uc := NewTriggerUseCase(&mockRepository{codebase: codebase}, enqueuer, WithDeliveryStore(store))
+
+		if _, err := uc.Execute(ctx, trigger); !errors.Is(err, ErrEnqueueFailed) {
+			t.Fatalf("expected ErrEnqueueFailed, got %v", err)
+		}
+		if len(store.released) != 1 {
+			t.Fatalf("expected the delivery to be released, got %v", store.released)
+		}
+
+		enqueuer.err = nil
+		if outcome, err := uc.Execute(ctx, trigger); err != nil || outcome != webhook.OutcomeEnqueued {
+			t.Errorf("retry: outcome = %q, err = %v; want enqueued", outcome, err)
+		}
+	})
+
+	t.Run("processes triggers when the store fails", func(t *testing.T) {
+		store := newMockDeliveryStore()
+		store.claimErr = errors.New("db down")
+		enqueuer := &mockEnqueuer{}
+
+		outcome, err := NewTriggerUseCase(&mockRepository{codebase: codebase}, enqueuer, WithDeliveryStore(store)).Execute(ctx, trigger)
+		if err != nil || outcome != webhook.OutcomeEnqueued {
+			t.Errorf("outcome = %q, err = %v; want enqueued", outcome, err)
+		}
+	})
+	t.Run("keys the job by delivery when the first attempt never completed", func(t *testing.T) {
+		store := newMockDeliveryStore()
+		enqueuer := &mockIdempotentEnqueuer{keys: map[string]bool{webhook.DeliveryIdempotencyKey(trigger.DeliveryID): true}}
+
+		outcome, err := NewTriggerUseCase(&mockRepository{codebase: codebase}, enqueuer, WithDeliveryStore(store)).Execute(ctx, trigger)
+		if err != nil || outcome != webhook.OutcomeEnqueued {
+			t.Errorf("outcome = %q, err = %v; want enqueued", outcome, err)
+		}
+		if len(enqueuer.calls) != 0 {
+			t.Errorf("expected the keyed job to be reused, got %v", enqueuer.calls)
+		}
+	})
+}